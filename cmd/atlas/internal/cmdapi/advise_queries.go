@@ -0,0 +1,391 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/cmd/atlas/internal/querylog"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+// indexSuggestion describes an index that is suggested for the queries of a query log.
+type indexSuggestion struct {
+	Table   string   `json:"table"`
+	Index   string   `json:"index"`
+	Columns []string `json:"columns"`
+	// Queries is the number of distinct queries the index serves, Calls is their
+	// number of occurrences in the log, and Duration is their total logged duration.
+	Queries  int           `json:"queries"`
+	Calls    int           `json:"calls"`
+	Duration time.Duration `json:"duration"`
+	Example  string        `json:"example"` // one of the served queries
+	SQL      string        `json:"sql"`
+	HCL      string        `json:"hcl"`
+	t        *schema.Table
+	cols     []*schema.Column
+}
+
+// suggestIndexes suggests indexes for the logged queries whose plans in the dev database
+// fully scan the tables they filter. The realm is copied to the dev database for explaining
+// the queries, and the dev database is restored to its original state at the end.
+func suggestIndexes(ctx context.Context, client *sqlclient.Client, r *schema.Realm, report *indexReport) (err error) {
+	f, err := os.Open(AdviseFlags.Queries)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	queries, err := querylog.Parse(f)
+	if err != nil {
+		return fmt.Errorf("reading query log: %w", err)
+	}
+	dev, err := openDevClient(ctx, AdviseFlags.DevURL)
+	if err != nil {
+		return err
+	}
+	defer dev.Close()
+	explain, err := scansExplainer(dev)
+	if err != nil {
+		return err
+	}
+	snap, ok := dev.Driver.(migrate.Snapshoter)
+	if !ok {
+		return errors.New("driver does not support taking a database snapshot")
+	}
+	restore, err := snap.Snapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("taking database snapshot: %w", err)
+	}
+	defer func() {
+		if rerr := restore(ctx); rerr != nil && err == nil {
+			err = fmt.Errorf("restoring dev database: %w", rerr)
+		}
+	}()
+	if err := copyToDev(ctx, dev, r); err != nil {
+		return fmt.Errorf("copying schema to dev database: %w", err)
+	}
+	report.logged = true
+	byKey := make(map[string]*indexSuggestion)
+	for _, q := range queries {
+		refs, err := querylog.Analyze(q.Text)
+		if err != nil {
+			report.Skipped++
+			continue
+		}
+		scans, err := explain(ctx, q.Text)
+		if err != nil {
+			report.Skipped++
+			continue
+		}
+		for _, ref := range refs.Tables {
+			t := realmTable(r, ref)
+			if t == nil || !scans[strings.ToLower(ref.Ref())] {
+				continue
+			}
+			cols := queryColumns(r, refs, ref)
+			if len(cols) == 0 || indexedBy(t, cols) {
+				continue
+			}
+			names := make([]string, len(cols))
+			for i, c := range cols {
+				names[i] = c.Name
+			}
+			k := nodeID(t) + "(" + strings.Join(names, ",") + ")"
+			s, ok := byKey[k]
+			if !ok {
+				s = &indexSuggestion{Table: nodeID(t), Columns: names, Example: q.Text, t: t, cols: cols}
+				byKey[k] = s
+				report.Suggestions = append(report.Suggestions, s)
+			}
+			s.Queries++
+			s.Calls += q.Count
+			s.Duration += q.Duration
+		}
+	}
+	report.Suggestions = mergeSuggestions(report.Suggestions)
+	for _, s := range report.Suggestions {
+		if err := s.changes(ctx, client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyToDev copies the realm to the dev database. In case the dev database is bound
+// to a schema, the schema of the realm is copied into it.
+func copyToDev(ctx context.Context, dev *sqlclient.Client, r *schema.Realm) error {
+	s := dev.URL.Schema
+	if s == "" {
+		return migrateDev(ctx, dev, r)
+	}
+	if len(r.Schemas) != 1 {
+		return fmt.Errorf("cannot copy %d schemas to a dev database bound to schema %q", len(r.Schemas), s)
+	}
+	defer func(name string) { r.Schemas[0].Name = name }(r.Schemas[0].Name)
+	r.Schemas[0].Name = s
+	return migrateDev(ctx, dev, r)
+}
+
+// scansExplainer returns a function that explains a query in the dev database, and returns
+// the tables (or their aliases, in lowercase) that are fully scanned by the query plan.
+func scansExplainer(dev *sqlclient.Client) (func(context.Context, string) (map[string]bool, error), error) {
+	switch dev.Name {
+	case mysql.DriverName:
+		return func(ctx context.Context, q string) (map[string]bool, error) {
+			rows, err := dev.QueryContext(ctx, "EXPLAIN "+q)
+			if err != nil {
+				return nil, err
+			}
+			defer rows.Close()
+			names, err := rows.Columns()
+			if err != nil {
+				return nil, err
+			}
+			var (
+				scans  = make(map[string]bool)
+				values = make([]sql.NullString, len(names))
+				dest   = make([]any, len(names))
+			)
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			for rows.Next() {
+				if err := rows.Scan(dest...); err != nil {
+					return nil, err
+				}
+				var table, typ string
+				for i, n := range names {
+					switch strings.ToLower(n) {
+					case "table":
+						table = values[i].String
+					case "type":
+						typ = values[i].String
+					}
+				}
+				if typ == "ALL" {
+					scans[strings.ToLower(table)] = true
+				}
+			}
+			return scans, rows.Err()
+		}, nil
+	case postgres.DriverName:
+		return func(ctx context.Context, q string) (map[string]bool, error) {
+			rows, err := dev.QueryContext(ctx, "EXPLAIN (FORMAT JSON) "+q)
+			if err != nil {
+				return nil, err
+			}
+			defer rows.Close()
+			var out string
+			if !rows.Next() {
+				return nil, fmt.Errorf("no plan was returned: %v", rows.Err())
+			}
+			if err := rows.Scan(&out); err != nil {
+				return nil, err
+			}
+			type node struct {
+				Type  string  `json:"Node Type"`
+				Alias string  `json:"Alias"`
+				Plans []*node `json:"Plans"`
+			}
+			var plans []struct {
+				Plan *node `json:"Plan"`
+			}
+			if err := json.Unmarshal([]byte(out), &plans); err != nil {
+				return nil, err
+			}
+			var (
+				scans = make(map[string]bool)
+				walk  func(*node)
+			)
+			walk = func(n *node) {
+				if n.Type == "Seq Scan" {
+					scans[strings.ToLower(n.Alias)] = true
+				}
+				for _, c := range n.Plans {
+					walk(c)
+				}
+			}
+			for _, p := range plans {
+				walk(p.Plan)
+			}
+			return scans, nil
+		}, nil
+	case sqlite.DriverName:
+		return func(ctx context.Context, q string) (map[string]bool, error) {
+			rows, err := dev.QueryContext(ctx, "EXPLAIN QUERY PLAN "+q)
+			if err != nil {
+				return nil, err
+			}
+			defer rows.Close()
+			scans := make(map[string]bool)
+			for rows.Next() {
+				var (
+					id, parent, unused int
+					detail             string
+				)
+				if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+					return nil, err
+				}
+				// Full table scans are described as "SCAN t [AS a]", or as
+				// "SCAN TABLE t [AS a]" in versions prior to 3.36.0. Scans that
+				// use an index (e.g. "SCAN t USING COVERING INDEX i") are skipped.
+				f := strings.Fields(detail)
+				if len(f) < 2 || f[0] != "SCAN" || strings.Contains(detail, " USING ") {
+					continue
+				}
+				if f = f[1:]; f[0] == "TABLE" && len(f) > 1 {
+					f = f[1:]
+				}
+				name := f[0]
+				if len(f) > 2 && f[1] == "AS" {
+					name = f[2]
+				}
+				scans[strings.ToLower(name)] = true
+			}
+			return scans, rows.Err()
+		}, nil
+	default:
+		return nil, fmt.Errorf("explaining queries is not supported by the %q driver", dev.Name)
+	}
+}
+
+// realmTable returns the table of the realm that is referenced by the query, or nil if it does not exist.
+func realmTable(r *schema.Realm, ref *querylog.Table) *schema.Table {
+	for _, s := range r.Schemas {
+		if ref.Schema != "" && !strings.EqualFold(s.Name, ref.Schema) {
+			continue
+		}
+		for _, t := range s.Tables {
+			if strings.EqualFold(t.Name, ref.Name) {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// queryColumns returns the columns of the referenced table that are filtered by the query.
+func queryColumns(r *schema.Realm, refs *querylog.Refs, ref *querylog.Table) []*schema.Column {
+	has := func(ref *querylog.Table, name string) bool {
+		t := realmTable(r, ref)
+		return t != nil && tableColumn(t, name) != nil
+	}
+	var (
+		t    = realmTable(r, ref)
+		cols []*schema.Column
+	)
+	for _, name := range refs.Columns(ref, has) {
+		if c := tableColumn(t, name); c != nil {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// tableColumn returns the column of the table with the given name, ignoring case.
+func tableColumn(t *schema.Table, name string) *schema.Column {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// indexedBy reports if the columns are a prefix of an existing index of the table.
+func indexedBy(t *schema.Table, cols []*schema.Column) bool {
+	keys := make([]string, len(cols))
+	for i, c := range cols {
+		keys[i] = "c:" + c.Name
+	}
+	for _, idx := range t.Indexes {
+		if prefixParts(keys, indexParts(idx)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSuggestions merges suggestions whose columns are a prefix of another suggestion
+// of the same table, as the wider index serves the queries of both, and sorts them by
+// the total duration of their queries, and then by their number of occurrences.
+func mergeSuggestions(ss []*indexSuggestion) []*indexSuggestion {
+	merged := make([]*indexSuggestion, 0, len(ss))
+	for i, s := range ss {
+		var into *indexSuggestion
+		for j, o := range ss {
+			if i != j && o.t == s.t && len(s.Columns) < len(o.Columns) && prefixParts(s.Columns, o.Columns) {
+				into = o
+				break
+			}
+		}
+		if into == nil {
+			merged = append(merged, s)
+			continue
+		}
+		into.Queries += s.Queries
+		into.Calls += s.Calls
+		into.Duration += s.Duration
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Duration != merged[j].Duration {
+			return merged[i].Duration > merged[j].Duration
+		}
+		return merged[i].Calls > merged[j].Calls
+	})
+	return merged
+}
+
+// changes names the suggested index, and computes the SQL and HCL changes that add it.
+func (s *indexSuggestion) changes(ctx context.Context, client *sqlclient.Client) error {
+	idx := schema.NewIndex("").AddColumns(s.cols...)
+	// The index is named by the default naming strategy, using a copy of
+	// the table, to avoid conflicts with its existing indexes.
+	t := &schema.Table{Name: s.t.Name, Indexes: append(s.t.Indexes[:len(s.t.Indexes):len(s.t.Indexes)], idx)}
+	if err := nameObjects([]string{"hash"}, client.Name, schema.NewRealm(schema.New("").AddTables(t))); err != nil {
+		return err
+	}
+	s.Index, idx.Table = idx.Name, s.t
+	plan, err := client.PlanChanges(ctx, "", []schema.Change{
+		&schema.ModifyTable{T: s.t, Changes: []schema.Change{&schema.AddIndex{I: idx}}},
+	})
+	if err != nil {
+		return err
+	}
+	stmts := make([]string, len(plan.Changes))
+	for i, c := range plan.Changes {
+		stmts[i] = c.Cmd + ";"
+	}
+	s.SQL = strings.Join(stmts, "\n")
+	// The HCL change holds the table with only its new index.
+	t = schema.NewTable(s.t.Name).AddIndexes(idx)
+	var sn string
+	if s.t.Schema != nil {
+		sn = s.t.Schema.Name
+	}
+	spec, err := client.MarshalSpec(schema.New(sn).AddTables(t))
+	if err != nil {
+		return err
+	}
+	s.HCL = strings.TrimSpace(string(spec))
+	// The schema block is omitted, as the index is added to an existing table.
+	if i := strings.LastIndex(s.HCL, "\nschema "); i != -1 {
+		s.HCL = s.HCL[:i]
+	}
+	return nil
+}
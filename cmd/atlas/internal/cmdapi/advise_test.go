@@ -0,0 +1,173 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Advise(t *testing.T) {
+	t.Cleanup(func() { AdviseFlags.Format, AdviseFlags.Drop = adviseFormatText, "" })
+	u := openSQLite(t, `
+CREATE TABLE users (id int PRIMARY KEY, name text, email text, age int);
+CREATE UNIQUE INDEX users_email ON users (email);
+CREATE INDEX users_email_idx ON users (email);
+CREATE INDEX users_name ON users (name);
+CREATE INDEX users_name_age ON users (name, age);
+CREATE INDEX users_age ON users (age DESC);
+`)
+	_, err := runCmd(Root, "schema", "advise", "-u", u, "--format", "yaml")
+	require.EqualError(t, err, `unknown output format "yaml"`)
+
+	s, err := runCmd(Root, "schema", "advise", "-u", u, "--format", "text")
+	require.NoError(t, err)
+	require.Equal(t, `Index usage statistics are not available for this database, unused indexes are not reported.
+main.users: index "users_email_idx" duplicates index "users_email"
+main.users: index "users_name" is covered by index "users_name_age"
+`, s)
+
+	s, err = runCmd(Root, "schema", "advise", "-u", u, "--format", "json")
+	require.NoError(t, err)
+	var report struct {
+		UsageStats bool `json:"usage_stats"`
+		Indexes    []struct {
+			Table, Index, Reason, Other string
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(s), &report))
+	require.False(t, report.UsageStats)
+	require.Len(t, report.Indexes, 2)
+	require.Equal(t, "duplicate", report.Indexes[0].Reason)
+	require.Equal(t, "users_email", report.Indexes[0].Other)
+
+	p := t.TempDir()
+	s, err = runCmd(Root, "schema", "advise", "-u", u, "--format", "text", "--drop", "file://"+p)
+	require.NoError(t, err)
+	require.Contains(t, s, "A migration file dropping 2 indexes was written to file://")
+	files, err := filepath.Glob(filepath.Join(p, "*_drop_indexes.sql"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	buf, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(buf), "DROP INDEX `users_email_idx`;")
+	require.Contains(t, string(buf), "DROP INDEX `users_name`;")
+	require.FileExists(t, filepath.Join(p, "atlas.sum"))
+}
+
+func TestAdviseIndexes(t *testing.T) {
+	var (
+		s     = schema.New("public")
+		users = schema.NewTable("users").AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("name", "text"),
+			schema.NewIntColumn("age", "int"),
+		)
+	)
+	s.AddTables(users)
+	users.AddIndexes(
+		schema.NewIndex("users_name").AddColumns(users.Columns[1]),
+		schema.NewIndex("users_name_age").AddColumns(users.Columns[1], users.Columns[2]),
+		schema.NewIndex("users_age").AddColumns(users.Columns[2]),
+		schema.NewUniqueIndex("users_id").AddColumns(users.Columns[0]),
+		schema.NewIndex("users_id_idx").AddColumns(users.Columns[0]),
+	)
+	report := adviseIndexes(schema.NewRealm(s), map[string]int64{
+		"public.users.users_name":     10,
+		"public.users.users_name_age": 0,
+		"public.users.users_age":      0,
+		"public.users.users_id":       0,
+		"public.users.users_id_idx":   5,
+	})
+	require.True(t, report.UsageStats)
+	var got [][]string
+	for _, a := range report.Indexes {
+		got = append(got, []string{a.Index, a.Reason, a.Other})
+	}
+	// The unused "users_name_age" index is kept, as it covers the "users_name" index,
+	// and the unused "users_id" index is kept, as it enforces a constraint.
+	require.Equal(t, [][]string{
+		{"users_name", adviseRedundant, "users_name_age"},
+		{"users_age", adviseUnused, ""},
+		{"users_id_idx", adviseDuplicate, "users_id"},
+	}, got)
+	require.Len(t, report.changes(), 1)
+	require.Len(t, report.changes()[0].(*schema.ModifyTable).Changes, 3)
+}
+
+func TestSchema_AdviseQueries(t *testing.T) {
+	t.Cleanup(func() { AdviseFlags.Format, AdviseFlags.Queries, AdviseFlags.DevURL = adviseFormatText, "", "" })
+	u := openSQLite(t, `
+CREATE TABLE users (id integer PRIMARY KEY, name text, email text, age int);
+CREATE TABLE pets (id integer PRIMARY KEY, owner_id int, name text);
+CREATE INDEX users_name ON users (name);
+`)
+	log := filepath.Join(t.TempDir(), "queries.sql")
+	require.NoError(t, os.WriteFile(log, []byte(`
+SELECT * FROM users WHERE email = 'a8m@example.com';
+SELECT * FROM users WHERE email = 'rotemtam@example.com';
+SELECT * FROM users WHERE email = 'masseelch@example.com' AND age > 18;
+SELECT * FROM users WHERE id = 1 OR name = 'a8m';
+SELECT p.name FROM pets AS p JOIN users AS u ON u.id = p.owner_id WHERE u.name = 'a8m';
+SELECT * FROM owners WHERE name = 'a8m';
+`), 0600))
+	_, err := runCmd(Root, "schema", "advise", "-u", u, "--format", "text", "--queries", log)
+	require.EqualError(t, err, "flag --dev-url is required when --queries is set")
+
+	dev := openSQLite(t, "")
+	s, err := runCmd(Root, "schema", "advise", "-u", u, "--format", "text", "--queries", log, "--dev-url", dev)
+	require.NoError(t, err)
+	require.Equal(t, `Index usage statistics are not available for this database, unused indexes are not reported.
+No duplicate, redundant or unused indexes were found.
+main.users: index "idx_users_322ae87b" on (email, age) is suggested for 2 queries (3 calls)
+  SQL:
+    CREATE INDEX `+"`idx_users_322ae87b` ON `users` (`email`, `age`)"+`;
+  HCL:
+    table "users" {
+      schema = schema.main
+      index "idx_users_322ae87b" {
+        columns = [column.email, column.age]
+      }
+    }
+main.pets: index "idx_pets_14e78fde" on (owner_id) is suggested for 1 queries (1 calls)
+  SQL:
+    CREATE INDEX `+"`idx_pets_14e78fde` ON `pets` (`owner_id`)"+`;
+  HCL:
+    table "pets" {
+      schema = schema.main
+      index "idx_pets_14e78fde" {
+        columns = [column.owner_id]
+      }
+    }
+1 logged queries could not be explained in the dev database, and were skipped.
+`, s)
+
+	// The dev database was restored, and can be used again.
+	s, err = runCmd(Root, "schema", "advise", "-u", u, "--format", "json", "--queries", log, "--dev-url", dev)
+	require.NoError(t, err)
+	var report struct {
+		Suggestions []struct {
+			Table, Index, Example, SQL string
+			Columns                    []string
+			Queries, Calls             int
+		}
+		Skipped int `json:"skipped_queries"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(s), &report))
+	require.Len(t, report.Suggestions, 2)
+	require.Equal(t, []string{"email", "age"}, report.Suggestions[0].Columns)
+	require.Equal(t, "SELECT * FROM users WHERE email = 'masseelch@example.com' AND age > 18", report.Suggestions[0].Example)
+	require.Equal(t, 1, report.Skipped)
+
+	// A dev database that is not clean cannot be used.
+	_, err = runCmd(Root, "schema", "advise", "-u", u, "--format", "text", "--queries", log, "--dev-url", u)
+	require.EqualError(t, err, `taking database snapshot: sql/migrate: connected database is not clean: found table "users"`)
+}
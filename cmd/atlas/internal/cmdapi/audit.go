@@ -0,0 +1,160 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+type (
+	// auditRecord is a statement executed by Atlas, recorded in the audit log.
+	auditRecord struct {
+		Time      time.Time `json:"time"`
+		Target    string    `json:"target"`
+		Statement string    `json:"statement"`
+		Duration  float64   `json:"duration_ms"`
+		Outcome   string    `json:"outcome"`
+		Error     string    `json:"error,omitempty"`
+	}
+
+	// auditSink writes records to the audit log.
+	auditSink interface {
+		Write(context.Context, *auditRecord) error
+	}
+
+	// fileSink appends the records to a file in JSON lines format.
+	fileSink struct {
+		mu   sync.Mutex
+		path string
+	}
+
+	// httpSink posts the records to an HTTP endpoint in JSON format.
+	httpSink struct {
+		url    string
+		client *http.Client
+	}
+)
+
+// Outcomes of audited statements.
+const (
+	auditOutcomeSuccess = "success"
+	auditOutcomeError   = "error"
+)
+
+// auditSinks caches the audit sinks by their URL, to share
+// them between all clients opened by the same process.
+var auditSinks sync.Map
+
+// auditHook returns a sqlclient.ExecHook that records the statements
+// executed on the given target URL in the given audit log.
+func auditHook(audit, target string) (sqlclient.ExecHook, error) {
+	sink, err := openAuditSink(audit)
+	if err != nil {
+		return nil, err
+	}
+	// Credentials are never recorded.
+	target = redactURL(target)
+	return func(ctx context.Context, e *sqlclient.ExecEvent) error {
+		r := &auditRecord{
+			Time:      e.Start.UTC(),
+			Target:    target,
+			Statement: e.Stmt,
+			Duration:  float64(e.Duration.Microseconds()) / 1000,
+			Outcome:   auditOutcomeSuccess,
+		}
+		if e.Err != nil {
+			r.Outcome, r.Error = auditOutcomeError, e.Err.Error()
+		}
+		if err := sink.Write(ctx, r); err != nil {
+			return fmt.Errorf("writing audit log: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// openAuditSink opens the audit sink of the given URL.
+func openAuditSink(s string) (auditSink, error) {
+	if v, ok := auditSinks.Load(s); ok {
+		return v.(auditSink), nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse audit log url: %w", err)
+	}
+	var sink auditSink
+	switch u.Scheme {
+	case "file":
+		path := filepath.Join(u.Host, u.Path)
+		if path == "" {
+			return nil, fmt.Errorf("missing path in audit log url %q", s)
+		}
+		sink = &fileSink{path: path}
+	case "syslog":
+		if sink, err = newSyslogSink(u); err != nil {
+			return nil, err
+		}
+	case "http", "https":
+		sink = &httpSink{url: s, client: &http.Client{Timeout: 10 * time.Second}}
+	default:
+		return nil, fmt.Errorf("unsupported audit log scheme %q, expect one of: file, syslog, http, https", u.Scheme)
+	}
+	v, _ := auditSinks.LoadOrStore(s, sink)
+	return v.(auditSink), nil
+}
+
+// Write implements auditSink. The file is opened on every write,
+// to play well with external log rotation.
+func (s *fileSink) Write(_ context.Context, r *auditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write implements auditSink.
+func (s *httpSink) Write(ctx context.Context, r *auditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
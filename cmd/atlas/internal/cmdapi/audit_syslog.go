@@ -0,0 +1,51 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !windows
+
+package cmdapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"net/url"
+)
+
+// syslogSink writes the records to syslog in JSON format.
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// newSyslogSink returns a syslog sink for the given URL. An empty host connects
+// to the local syslog server, and the "network" (default "udp") and "tag"
+// (default "atlas") query parameters configure the connection to a remote one.
+// For example, "syslog://" or "syslog://logs:514?network=tcp".
+func newSyslogSink(u *url.URL) (auditSink, error) {
+	network, tag := u.Query().Get("network"), u.Query().Get("tag")
+	if tag == "" {
+		tag = "atlas"
+	}
+	if network == "" && u.Host != "" {
+		network = "udp"
+	}
+	w, err := syslog.Dial(network, u.Host, syslog.LOG_INFO|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to syslog: %w", err)
+	}
+	return &syslogSink{w: w}, nil
+}
+
+// Write implements auditSink.
+func (s *syslogSink) Write(_ context.Context, r *auditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if r.Outcome == auditOutcomeError {
+		return s.w.Err(string(b))
+	}
+	return s.w.Info(string(b))
+}
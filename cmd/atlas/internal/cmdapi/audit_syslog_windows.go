@@ -0,0 +1,14 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"errors"
+	"net/url"
+)
+
+func newSyslogSink(*url.URL) (auditSink, error) {
+	return nil, errors.New("syslog audit log is not supported on windows")
+}
@@ -0,0 +1,82 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_Baseline(t *testing.T) {
+	t.Cleanup(func() {
+		MigrateFlags.DevURL, MigrateFlags.URL, MigrateFlags.Baseline.FromDB = "", "", false
+	})
+	// Global flags are set from other tests ...
+	MigrateFlags.DirFormat = formatAtlas
+	MigrateFlags.Apply.DryRun, MigrateFlags.Apply.BaselineVersion = false, ""
+	var (
+		ctx = context.Background()
+		p   = filepath.Join(t.TempDir(), "migrations")
+		url = openSQLite(t, "CREATE TABLE users (id int PRIMARY KEY); CREATE TABLE posts (id int PRIMARY KEY, author_id int REFERENCES users(id));")
+	)
+	// The --from-db flag is required.
+	_, err := runCmd(Root, "migrate", "baseline", "--dir", "file://"+p, "--url", url, "--dev-url", openSQLite(t, ""))
+	require.ErrorContains(t, err, `required flag(s) "from-db" not set`)
+
+	s, err := runCmd(Root, "migrate", "baseline", "--from-db", "--dir", "file://"+p, "--url", url, "--dev-url", openSQLite(t, ""))
+	require.NoError(t, err)
+	require.Contains(t, s, "_baseline.sql\" was created")
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, migrate.Validate(d))
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.True(t, strings.HasSuffix(files[0].Name(), "_baseline.sql"))
+	require.Contains(t, string(files[0].Bytes()), "CREATE TABLE `users`")
+	require.Contains(t, string(files[0].Bytes()), "CREATE TABLE `posts`")
+	require.NotContains(t, string(files[0].Bytes()), "atlas_schema_revisions", "revisions table is not part of the baseline")
+
+	// The baseline file is recorded as applied.
+	c, err := sqlclient.Open(ctx, url)
+	require.NoError(t, err)
+	defer c.Close()
+	rrw, err := entRevisions(ctx, c)
+	require.NoError(t, err)
+	revs, err := rrw.ReadRevisions(ctx)
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Equal(t, files[0].Version(), revs[0].Version)
+	require.Equal(t, migrate.RevisionTypeBaseline, revs[0].Type)
+
+	// Files that are added after the baseline are executed.
+	require.NoError(t, d.WriteFile("99990101000000_comments.sql", []byte("CREATE TABLE comments (id int PRIMARY KEY);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", url)
+	require.NoError(t, err)
+	require.Contains(t, s, "(1 migrations in total)")
+
+	// Baselines are created only in empty directories.
+	_, err = runCmd(Root, "migrate", "baseline", "--from-db", "--dir", "file://"+p, "--url", url, "--dev-url", openSQLite(t, ""))
+	require.EqualError(t, err, "migration directory is not empty, a baseline file can only be created as its first file")
+
+	// Databases with revisions cannot be baselined.
+	_, err = runCmd(Root, "migrate", "baseline", "--from-db", "--dir", "file://"+filepath.Join(t.TempDir(), "other"), "--url", url, "--dev-url", openSQLite(t, ""))
+	require.EqualError(t, err, "connected database already has 2 revisions, and cannot be baselined")
+
+	// Empty databases have nothing to baseline.
+	s, err = runCmd(Root, "migrate", "baseline", "--from-db", "--dir", "file://"+filepath.Join(t.TempDir(), "empty"), "--url", openSQLite(t, ""), "--dev-url", openSQLite(t, ""))
+	require.NoError(t, err)
+	require.Contains(t, s, "The connected database is empty, no baseline file to create")
+}
@@ -0,0 +1,117 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"fmt"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/spf13/cobra"
+)
+
+// cdcBreak describes a planned change that breaks the change data capture
+// pipelines (e.g. Debezium) of a captured table, and their consumers.
+type cdcBreak struct {
+	Table  string // qualified table name
+	Reason string
+}
+
+func (b *cdcBreak) String() string {
+	return fmt.Sprintf("table %q: %s", b.Table, b.Reason)
+}
+
+// cdcBreaks returns the changes that break the pipelines of the captured tables.
+func cdcBreaks(c *CDC, changes []schema.Change) []*cdcBreak {
+	captured := &schema.Scope{Tables: c.Tables}
+	var breaks []*cdcBreak
+	add := func(t *schema.Table, format string, args ...any) {
+		if captured.OwnsTable(t) {
+			breaks = append(breaks, &cdcBreak{Table: qualifiedName(t), Reason: fmt.Sprintf(format, args...)})
+		}
+	}
+	var walk func([]schema.Change)
+	walk = func(changes []schema.Change) {
+		for _, c := range changes {
+			switch c := c.(type) {
+			case *schema.ModifySchema:
+				walk(c.Changes)
+			case *schema.DropTable:
+				add(c.T, "dropping the table")
+			case *schema.RenameTable:
+				add(c.From, "renaming the table to %q", c.To.Name)
+			case *schema.ModifyTable:
+				for _, tc := range c.Changes {
+					switch tc := tc.(type) {
+					case *schema.DropColumn:
+						add(c.T, "dropping column %q", tc.C.Name)
+					case *schema.RenameColumn:
+						add(c.T, "renaming column %q to %q", tc.From.Name, tc.To.Name)
+					case *schema.ModifyColumn:
+						switch {
+						case !tc.Change.Is(schema.ChangeType):
+						case inPrimaryKey(c.T, tc.To.Name):
+							add(c.T, "changing the type of primary key column %q", tc.To.Name)
+						default:
+							add(c.T, "changing the type of column %q", tc.To.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	walk(changes)
+	return breaks
+}
+
+// inPrimaryKey reports if the named column is part of the table primary key.
+func inPrimaryKey(t *schema.Table, name string) bool {
+	if t.PrimaryKey == nil {
+		return false
+	}
+	for _, p := range t.PrimaryKey.Parts {
+		if p.C != nil && p.C.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifiedName returns the qualified name of the table.
+func qualifiedName(t *schema.Table) string {
+	if t.Schema != nil && t.Schema.Name != "" {
+		return t.Schema.Name + "." + t.Name
+	}
+	return t.Name
+}
+
+// checkCDC reports the changes that break the pipelines of the tables captured by
+// change data capture, and fails unless they were acknowledged by the user. Breaking
+// changes are only reported in dry-runs.
+func checkCDC(cmd *cobra.Command, changes []schema.Change, dryRun bool) error {
+	env, err := selectEnv(GlobalFlags.SelectedEnv)
+	if err != nil {
+		return err
+	}
+	if env.CDC == nil || len(env.CDC.Tables) == 0 {
+		return nil
+	}
+	breaks := cdcBreaks(env.CDC, changes)
+	if len(breaks) == 0 {
+		return nil
+	}
+	cmd.Println("The following changes break the change data capture pipelines of captured tables:")
+	cmd.Println()
+	for _, b := range breaks {
+		cmd.Printf("  -- %s\n", b)
+	}
+	cmd.Println()
+	if dryRun || ApplyFlags.AckCDC {
+		return nil
+	}
+	// The breaking changes are already reported in the output.
+	cmd.SilenceErrors = true
+	return fmt.Errorf("%d changes break captured tables, use --%s to acknowledge and apply them", len(breaks), ackCDCFlag)
+}
@@ -0,0 +1,90 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCDC(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, projectFileName), []byte(`
+env "captured" {
+	cdc {
+		tables = ["public.users", "orders_*"]
+	}
+}
+
+env "local" {}
+`), 0600))
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		GlobalFlags.SelectedEnv, ApplyFlags.AckCDC = "", false
+		require.NoError(t, os.Chdir(wd))
+	})
+	var (
+		public = schema.New("public")
+		id     = schema.NewIntColumn("id", "bigint")
+		users  = schema.NewTable("users").SetSchema(public).AddColumns(id).SetPrimaryKey(schema.NewPrimaryKey(id))
+	)
+	changes := []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.ModifyColumn{From: schema.NewIntColumn("id", "int"), To: id, Change: schema.ChangeType},
+				&schema.ModifyColumn{From: schema.NewIntColumn("age", "int"), To: schema.NewIntColumn("age", "int"), Change: schema.ChangeNull},
+				&schema.RenameColumn{From: schema.NewColumn("name"), To: schema.NewColumn("full_name")},
+			},
+		},
+		&schema.DropTable{T: schema.NewTable("orders_2022").SetSchema(public)},
+		&schema.DropTable{T: schema.NewTable("posts").SetSchema(public)},
+		&schema.ModifyTable{
+			T: schema.NewTable("orders").SetSchema(public),
+			Changes: []schema.Change{
+				&schema.ModifyColumn{From: schema.NewIntColumn("total", "int"), To: schema.NewIntColumn("total", "bigint"), Change: schema.ChangeType},
+				&schema.DropColumn{C: schema.NewColumn("note")},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	GlobalFlags.SelectedEnv = "local"
+	require.NoError(t, checkCDC(cmd, changes, false))
+	require.Empty(t, out.String())
+
+	GlobalFlags.SelectedEnv = "captured"
+	err = checkCDC(cmd, changes, false)
+	require.EqualError(t, err, "3 changes break captured tables, use --ack-cdc to acknowledge and apply them")
+	require.True(t, cmd.SilenceErrors)
+	require.Equal(t, `The following changes break the change data capture pipelines of captured tables:
+
+  -- table "public.users": changing the type of primary key column "id"
+  -- table "public.users": renaming column "name" to "full_name"
+  -- table "public.orders_2022": dropping the table
+
+`, out.String())
+
+	// Breaking changes are reported, but not enforced, in dry-runs.
+	out.Reset()
+	require.NoError(t, checkCDC(cmd, changes, true))
+	require.NotEmpty(t, out.String())
+
+	out.Reset()
+	ApplyFlags.AckCDC = true
+	require.NoError(t, checkCDC(cmd, changes, false))
+	require.NotEmpty(t, out.String())
+}
@@ -0,0 +1,100 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_Checkpoint(t *testing.T) {
+	t.Cleanup(func() {
+		MigrateFlags.DevURL = ""
+	})
+	// Global flags are set from other tests ...
+	MigrateFlags.DirFormat = formatAtlas
+	MigrateFlags.Apply.DryRun, MigrateFlags.Apply.BaselineVersion = false, ""
+	var (
+		p    = t.TempDir()
+		ctx  = context.Background()
+		open = func(name string) string {
+			return fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), name))
+		}
+	)
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("20220101000000_t1.sql", []byte("CREATE TABLE t1 (c1 int);\n")))
+	require.NoError(t, d.WriteFile("20220102000000_t2.sql", []byte("CREATE TABLE t2 (c2 int);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	// Existing database, applied before the checkpoint was created.
+	existing := open("existing.db")
+	_, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", existing)
+	require.NoError(t, err)
+
+	s, err := runCmd(Root, "migrate", "checkpoint", "--dir", "file://"+p, "--dev-url", openSQLite(t, ""), "squash")
+	require.NoError(t, err)
+	require.Empty(t, s)
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.True(t, strings.HasSuffix(files[2].Name(), "_squash.sql"))
+	require.True(t, migrate.IsCheckpoint(files[2]))
+	require.Equal(t, "-- atlas:checkpoint\n-- create \"t1\" table\nCREATE TABLE `t1` (`c1` int NULL);\n-- create \"t2\" table\nCREATE TABLE `t2` (`c2` int NULL);\n", string(files[2].Bytes()))
+	require.NoError(t, migrate.Validate(d))
+
+	// New files are added after the checkpoint.
+	require.NoError(t, d.WriteFile("99990101000000_t3.sql", []byte("CREATE TABLE t3 (c3 int);\n")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	tables := func(u string) []string {
+		c, err := sqlclient.Open(ctx, u)
+		require.NoError(t, err)
+		defer c.Close()
+		s, err := c.InspectSchema(ctx, "", nil)
+		require.NoError(t, err)
+		var names []string
+		for _, t := range s.Tables {
+			names = append(names, t.Name)
+		}
+		return names
+	}
+
+	// Existing databases skip the checkpoint file.
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", existing)
+	require.NoError(t, err)
+	require.Contains(t, s, "(1 migrations in total)")
+	require.Equal(t, []string{"atlas_schema_revisions", "t1", "t2", "t3"}, tables(existing))
+
+	// New databases start from the checkpoint file.
+	fresh := open("fresh.db")
+	s, err = runCmd(Root, "migrate", "status", "--dir", "file://"+p, "--url", fresh)
+	require.NoError(t, err)
+	require.Contains(t, s, "Pending Files:    2")
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", fresh)
+	require.NoError(t, err)
+	require.Contains(t, s, "(2 migrations in total)")
+	require.Equal(t, []string{"atlas_schema_revisions", "t1", "t2", "t3"}, tables(fresh))
+	s, err = runCmd(Root, "migrate", "status", "--dir", "file://"+p, "--url", fresh)
+	require.NoError(t, err)
+	require.Contains(t, s, "Executed Files:   2")
+
+	// Hashes of the files before the checkpoint are still validated.
+	require.NoError(t, os.WriteFile(filepath.Join(p, "20220101000000_t1.sql"), []byte("CREATE TABLE t1 (c1 text);\n"), 0644))
+	_, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", open("other.db"))
+	require.ErrorIs(t, err, migrate.ErrChecksumMismatch)
+}
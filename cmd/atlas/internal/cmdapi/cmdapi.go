@@ -7,11 +7,18 @@
 package cmdapi
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"strings"
 
 	"ariga.io/atlas/cmd/atlas/internal/update"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/mod/semver"
@@ -33,6 +40,12 @@ var (
 		// Vars contains the input variables passed from the CLI to
 		// Atlas DDL or project files.
 		Vars map[string]string
+		// Session contains the statements executed on every
+		// connection opened to the database, such as "SET ROLE".
+		Session []string
+		// AuditLog is the URL of the audit log that records the
+		// statements executed on non-dev databases.
+		AuditLog string
 	}
 
 	// version holds Atlas version. When built with cloud packages
@@ -94,6 +107,74 @@ func init() {
 	Root.AddCommand(schemaCmd)
 	Root.AddCommand(versionCmd)
 	Root.AddCommand(licenseCmd)
+	Root.PersistentFlags().StringArrayVarP(&GlobalFlags.Session, sessionFlag, "", nil, "statement to execute on every new database connection (can be repeated)")
+	Root.PersistentFlags().StringVarP(&GlobalFlags.AuditLog, auditLogFlag, "", "", "[file://path | syslog://[host:port] | https://url] record the statements executed on non-dev databases")
+}
+
+// openClient opens a client to the given URL, that executes the session
+// statements (if any) on every connection it opens, and records the
+// executed statements in the audit log (if configured).
+func openClient(ctx context.Context, url string) (*sqlclient.Client, error) {
+	return openURL(ctx, url, GlobalFlags.Session, GlobalFlags.AuditLog)
+}
+
+// openDevClient opens a client to the given dev database URL.
+// Statements executed on dev databases are not audited.
+func openDevClient(ctx context.Context, url string) (*sqlclient.Client, error) {
+	return openURL(ctx, url, GlobalFlags.Session, "")
+}
+
+// openURL opens a client to the given URL with the given session statements and audit log.
+func openURL(ctx context.Context, s string, session []string, audit string) (*sqlclient.Client, error) {
+	var opts []sqlclient.OpenOption
+	if len(session) > 0 {
+		// Session statements are executed on server connections that
+		// are shared with other clients when pooled in transaction mode.
+		if u, err := url.Parse(s); err == nil && u.Query().Get("pool_mode") == postgres.PoolModeTransaction {
+			return nil, fmt.Errorf("session statements cannot be used with pool_mode=%s", postgres.PoolModeTransaction)
+		}
+		opts = append(opts, sqlclient.OpenSession(session...))
+	}
+	if audit != "" {
+		h, err := auditHook(audit, s)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sqlclient.OpenExecHook(h))
+	}
+	return sqlclient.Open(ctx, s, opts...)
+}
+
+// lockDatabase acquires the named advisory lock on the database, if supported
+// by its driver. Connections that cannot hold advisory locks (e.g., connections
+// pooled in transaction mode) are used without the lock, and a warning is
+// written to w.
+func lockDatabase(ctx context.Context, w io.Writer, c *sqlclient.Client, name string) (schema.UnlockFunc, error) {
+	nop := func() error { return nil }
+	l, ok := c.Driver.(schema.Locker)
+	if !ok {
+		return nop, nil
+	}
+	unlock, err := l.Lock(ctx, name, 0)
+	if errors.Is(err, schema.ErrLockUnsupported) {
+		fmt.Fprintf(w, "Warning: executing without acquiring a database lock (%v). Ensure no other Atlas process is executing against the database concurrently.\n", err)
+		return nop, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("acquiring database lock: %w", err)
+	}
+	return unlock, nil
+}
+
+// connFromEnv sets the session statements and the audit log from the active
+// environment, unless they were set by the user via the command line.
+func connFromEnv(cmd *cobra.Command, env *Env) {
+	if fl := cmd.Flag(sessionFlag); fl != nil && !fl.Changed && len(env.Session) > 0 {
+		GlobalFlags.Session = env.Session
+	}
+	if fl := cmd.Flag(auditLogFlag); fl != nil && !fl.Changed && env.AuditLog != "" {
+		GlobalFlags.AuditLog = env.AuditLog
+	}
 }
 
 // receivesEnv configures cmd to receive the common '--env' flag.
@@ -6,11 +6,18 @@ package cmdapi
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
+	"time"
 
 	"ariga.io/atlas/cmd/atlas/internal/update"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
 
 	"github.com/stretchr/testify/require"
 )
@@ -74,3 +81,67 @@ func TestCLI_Version(t *testing.T) {
 		})
 	}
 }
+
+func TestLSP(t *testing.T) {
+	t.Cleanup(func() {
+		Root.SetIn(nil)
+		LSPFlags.Dialect = ""
+	})
+	var in strings.Builder
+	for _, m := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+	Root.SetIn(strings.NewReader(in.String()))
+	s, err := runCmd(Root, "lsp", "--dialect", "mysql")
+	require.NoError(t, err)
+	require.Contains(t, s, `"serverInfo":{"name":"atlas"}`)
+	require.Contains(t, s, `{"jsonrpc":"2.0","id":2,"result":null}`)
+
+	_, err = runCmd(Root, "lsp", "--dialect", "oracle")
+	require.EqualError(t, err, `unknown dialect "oracle"`)
+}
+
+func TestLockDatabase(t *testing.T) {
+	var out bytes.Buffer
+	c := &sqlclient.Client{Driver: &lockDriver{err: fmt.Errorf("sql/postgres: %w", schema.ErrLockUnsupported)}}
+	unlock, err := lockDatabase(context.Background(), &out, c, "atlas_migrate_execute")
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+	require.Contains(t, out.String(), "Warning: executing without acquiring a database lock (sql/postgres: sql/schema: lock is not supported by the connection)")
+
+	out.Reset()
+	c.Driver = &lockDriver{err: schema.ErrLocked}
+	_, err = lockDatabase(context.Background(), &out, c, "atlas_migrate_execute")
+	require.EqualError(t, err, "acquiring database lock: sql/schema: lock is held by other session")
+	require.Empty(t, out.String())
+
+	c.Driver = &lockDriver{}
+	unlock, err = lockDatabase(context.Background(), &out, c, "atlas_migrate_execute")
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+	require.True(t, c.Driver.(*lockDriver).unlocked)
+	require.Empty(t, out.String())
+
+	_, err = openURL(context.Background(), "postgres://localhost:6432/db?pool_mode=transaction", []string{"SET ROLE migrator"}, "")
+	require.EqualError(t, err, "session statements cannot be used with pool_mode=transaction")
+}
+
+type lockDriver struct {
+	migrate.Driver
+	err      error
+	unlocked bool
+}
+
+func (d *lockDriver) Lock(context.Context, string, time.Duration) (schema.UnlockFunc, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return func() error {
+		d.unlocked = true
+		return nil
+	}, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+	"github.com/spf13/cobra"
+)
+
+const toDialectFlag = "to-dialect"
+
+type compileDialect struct {
+	schemahcl.Evaluator
+	schema.Differ
+	migrate.PlanApplier
+	// Reports if the dialect supports creating schemas.
+	schemas bool
+}
+
+var (
+	// compileDialects holds the dialects that schemas can be compiled to.
+	compileDialects = map[string]compileDialect{
+		"mysql":    {mysql.EvalHCL, mysql.DefaultDiff, mysql.DefaultPlan, true},
+		"postgres": {postgres.EvalHCL, postgres.DefaultDiff, postgres.DefaultPlan, true},
+		"sqlite":   {sqlite.EvalHCL, sqlite.DefaultDiff, sqlite.DefaultPlan, false},
+	}
+
+	// CompileFlags are the flags used in SchemaCompile command.
+	CompileFlags struct {
+		Dialect string
+		Version string
+	}
+
+	// SchemaCompile represents the 'atlas schema compile' subcommand.
+	SchemaCompile = &cobra.Command{
+		Use:   "compile [flags] path...",
+		Short: "Compile an Atlas HCL schema to SQL statements of the given dialect.",
+		Long: `'atlas schema compile' renders the schema described in the given HCL files (or directories)
+as an ordered list of SQL statements that create it from scratch, without connecting to any
+database. The output can be embedded in projects that initialize their own databases.
+
+Schemas are created only if they do not exist, and all other objects are assumed not to
+exist in the database. Use the "--target-version" flag to plan the statements for a specific
+version of the database.`,
+		Example: `  atlas schema compile --to-dialect postgres schema.hcl
+  atlas schema compile --to-dialect mysql --target-version 5.7 schema/
+  atlas schema compile --to-dialect sqlite schema.hcl > schema.sql`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: CmdCompileRun,
+	}
+)
+
+func init() {
+	schemaCmd.AddCommand(SchemaCompile)
+	SchemaCompile.Flags().StringVarP(&CompileFlags.Dialect, toDialectFlag, "", "", "The dialect to compile the schema to "+compileNames())
+	SchemaCompile.Flags().StringVarP(&CompileFlags.Version, migrateDiffTargetVersion, "", "", "Plan the statements for the given database version")
+	cobra.CheckErr(SchemaCompile.MarkFlagRequired(toDialectFlag))
+}
+
+// CmdCompileRun is the command executed when running the CLI with 'schema compile' args.
+func CmdCompileRun(cmd *cobra.Command, args []string) error {
+	d, ok := compileDialects[CompileFlags.Dialect]
+	if !ok {
+		return fmt.Errorf("unknown dialect %q, expect one of %s", CompileFlags.Dialect, compileNames())
+	}
+	parsed, err := parseHCLPaths(args...)
+	if err != nil {
+		return err
+	}
+	desired := &schema.Realm{}
+	if err := d.Eval(parsed, desired, GlobalFlags.Vars); err != nil {
+		return err
+	}
+	changes, err := d.RealmDiff(&schema.Realm{}, desired)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(changes); i++ {
+		c, ok := changes[i].(*schema.AddSchema)
+		switch {
+		case !ok:
+		case !d.schemas:
+			changes = append(changes[:i], changes[i+1:]...)
+			i--
+		// Schemas may already exist in the database (e.g. "public").
+		default:
+			c.Extra = append(c.Extra, &schema.IfNotExists{})
+		}
+	}
+	plan, err := d.PlanChanges(cmd.Context(), "compile", changes, func(o *migrate.PlanOptions) {
+		o.TargetVersion = CompileFlags.Version
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range plan.Changes {
+		if c.Comment != "" {
+			cmd.Println("--", strings.ToUpper(c.Comment[:1])+c.Comment[1:])
+		}
+		cmd.Printf("%s;\n", c.Cmd)
+	}
+	return nil
+}
+
+// compileNames returns the names of the supported dialects.
+func compileNames() string {
+	names := make([]string, 0, len(compileDialects))
+	for n := range compileDialects {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return "[" + strings.Join(names, ", ") + "]"
+}
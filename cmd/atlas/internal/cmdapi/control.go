@@ -0,0 +1,153 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+// errAborted is returned when the execution was aborted using the control socket.
+var errAborted = errors.New("aborted by operator")
+
+// controller allows an operator to pause, resume or abort a running migration
+// using a Unix socket. Commands are sent as lines of text, and every command is
+// answered with the current state of the execution. For example:
+//
+//	echo pause | nc -U /tmp/atlas.sock
+//
+// Pausing takes effect between migration files, and aborting takes effect after the
+// statement that is currently executed. Statements that were not executed yet are
+// rejected, and the transaction of the current file is rolled back (if there is one).
+type controller struct {
+	ln      net.Listener
+	mu      sync.Mutex
+	paused  bool
+	aborted bool
+	changed chan struct{} // closed on state change
+}
+
+// listenControl creates a controller that listens for commands on the given socket path.
+func listenControl(path string) (*controller, error) {
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listening on control socket: %w", err)
+	}
+	c := &controller{ln: ln, changed: make(chan struct{})}
+	go c.serve()
+	return c, nil
+}
+
+// Close stops listening on the control socket and removes it.
+func (c *controller) Close() error {
+	err := c.ln.Close()
+	if rerr := os.Remove(c.ln.Addr().String()); rerr != nil && !errors.Is(rerr, os.ErrNotExist) && err == nil {
+		err = rerr
+	}
+	return err
+}
+
+func (c *controller) serve() {
+	for {
+		conn, err := c.ln.Accept()
+		if err != nil {
+			return
+		}
+		go c.handle(conn)
+	}
+}
+
+func (c *controller) handle(conn net.Conn) {
+	defer conn.Close()
+	s := bufio.NewScanner(conn)
+	for s.Scan() {
+		switch cmd := strings.ToLower(strings.TrimSpace(s.Text())); cmd {
+		case "":
+			continue
+		case "pause":
+			c.set(func() { c.paused = true })
+		case "resume":
+			c.set(func() { c.paused = false })
+		case "abort":
+			c.set(func() { c.aborted = true })
+		case "status":
+		default:
+			fmt.Fprintf(conn, "error: unknown command %q\n", cmd)
+			continue
+		}
+		fmt.Fprintln(conn, c.state())
+	}
+}
+
+// set applies the given state change and notifies the waiters.
+func (c *controller) set(f func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f()
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// state returns the current state of the execution.
+func (c *controller) state() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.aborted:
+		return "aborted"
+	case c.paused:
+		return "paused"
+	default:
+		return "running"
+	}
+}
+
+// wait blocks while the execution is paused. It returns errAborted if the execution
+// was aborted, or the context error if it was canceled while waiting. A nil controller
+// never blocks.
+func (c *controller) wait(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	for {
+		c.mu.Lock()
+		aborted, paused, changed := c.aborted, c.paused, c.changed
+		c.mu.Unlock()
+		switch {
+		case aborted:
+			return errAborted
+		case !paused:
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// BeforeExec implements migrate.ExecHook by rejecting all statements after the execution was aborted.
+func (c *controller) BeforeExec(_ context.Context, s *migrate.HookStmt) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.aborted {
+		return nil, errAborted
+	}
+	return []string{s.Stmt}, nil
+}
+
+// AfterExec implements migrate.ExecHook.
+func (*controller) AfterExec(context.Context, *migrate.HookStmt, error) error {
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestController(t *testing.T) {
+	// Unix socket paths are limited in length.
+	d, err := os.MkdirTemp("", "atlas")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(d) })
+	p := filepath.Join(d, "ctl.sock")
+	ctl, err := listenControl(p)
+	require.NoError(t, err)
+
+	conn, err := net.Dial("unix", p)
+	require.NoError(t, err)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	send := func(cmd string) string {
+		_, err := fmt.Fprintln(conn, cmd)
+		require.NoError(t, err)
+		l, err := r.ReadString('\n')
+		require.NoError(t, err)
+		return l
+	}
+	require.Equal(t, "running\n", send("status"))
+	require.Equal(t, "error: unknown command \"stop\"\n", send("stop"))
+	require.NoError(t, ctl.wait(context.Background()))
+
+	// Paused executions are blocked until they are resumed.
+	require.Equal(t, "paused\n", send("pause"))
+	done := make(chan error)
+	go func() { done <- ctl.wait(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatal("expect wait to block while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.Equal(t, "running\n", send("RESUME"))
+	require.NoError(t, <-done)
+
+	// Canceled while paused.
+	send("pause")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, ctl.wait(ctx), context.DeadlineExceeded)
+
+	// Aborting releases paused executions, and rejects the remaining statements.
+	go func() { done <- ctl.wait(context.Background()) }()
+	require.Equal(t, "aborted\n", send("abort"))
+	require.ErrorIs(t, <-done, errAborted)
+	_, err = ctl.BeforeExec(context.Background(), &migrate.HookStmt{Stmt: "SELECT 1"})
+	require.ErrorIs(t, err, errAborted)
+
+	require.NoError(t, ctl.Close())
+	_, err = os.Stat(p)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMigrate_ApplyControlSocket(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	d, err := os.MkdirTemp("", "atlas")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(d)
+		MigrateFlags.Apply.ControlSocket = ""
+	})
+	p := filepath.Join(d, "ctl.sock")
+	u := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	// The socket is listening while migrating.
+	s, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", u,
+		"--control-socket", p,
+		"1",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "-- migrating version 20220318104614")
+	_, err = os.Stat(p)
+	require.ErrorIs(t, err, os.ErrNotExist, "socket is removed at exit")
+
+	// Socket path is in use.
+	ln, err := net.Listen("unix", p)
+	require.NoError(t, err)
+	_, err = runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", u,
+		"--control-socket", p,
+	)
+	require.ErrorContains(t, err, "listening on control socket")
+	require.NoError(t, ln.Close())
+
+	// Aborted executions leave the remaining files pending.
+	ctx := context.Background()
+	c, err := sqlclient.Open(ctx, u)
+	require.NoError(t, err)
+	defer c.Close()
+	rrw, err := entRevisions(ctx, c)
+	require.NoError(t, err)
+	dir, err := migrate.NewLocalDir("testdata/sqlite")
+	require.NoError(t, err)
+	ex, err := migrate.NewExecutor(c.Driver, dir, rrw)
+	require.NoError(t, err)
+	pending, err := ex.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	ctl := &controller{aborted: true, changed: make(chan struct{})}
+	left, err := executeFiles(ctx, c, dir, rrw, nil, pending, 0, ctl)
+	require.ErrorIs(t, err, errAborted)
+	require.Equal(t, pending, left)
+}
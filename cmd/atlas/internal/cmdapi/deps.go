@@ -0,0 +1,184 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+const (
+	inspectFlagFormat = "format"
+	inspectFormatHCL  = "hcl"
+	inspectFormatDeps = "deps"
+	inspectFormatDOT  = "dot"
+	inspectFormatJSON = "json"
+)
+
+type (
+	// depGraph describes the dependencies between the objects of a realm.
+	depGraph struct {
+		Nodes []*depNode `json:"nodes"`
+		Edges []*depEdge `json:"edges"`
+	}
+
+	// depNode is a database object in the dependency graph.
+	depNode struct {
+		ID     string `json:"id"`
+		Type   string `json:"type"`
+		Schema string `json:"schema,omitempty"`
+		Name   string `json:"name"`
+		// The definition of views, functions, procedures and
+		// triggers, used to resolve their references by name.
+		def string
+	}
+
+	// depEdge describes a dependency of the "From" object on the "To" object.
+	depEdge struct {
+		From       string   `json:"from"`
+		To         string   `json:"to"`
+		Type       string   `json:"type"`
+		Name       string   `json:"name,omitempty"`
+		Columns    []string `json:"columns,omitempty"`
+		RefColumns []string `json:"ref_columns,omitempty"`
+	}
+)
+
+// newDepGraph builds the dependency graph of the given realm. Foreign keys
+// are the dependencies of tables, and triggers depend on the tables they are
+// defined on. The definitions of views, functions, procedures and triggers are
+// not parsed, and their dependencies are the objects they reference by name.
+func newDepGraph(r *schema.Realm) *depGraph {
+	g := &depGraph{Nodes: []*depNode{}, Edges: []*depEdge{}}
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			g.Nodes = append(g.Nodes, &depNode{ID: nodeID(t), Type: "table", Schema: s.Name, Name: t.Name})
+			for _, fk := range t.ForeignKeys {
+				e := &depEdge{From: nodeID(t), To: nodeID(fk.RefTable), Type: "foreign_key", Name: fk.Symbol}
+				for _, c := range fk.Columns {
+					e.Columns = append(e.Columns, c.Name)
+				}
+				for _, c := range fk.RefColumns {
+					e.RefColumns = append(e.RefColumns, c.Name)
+				}
+				g.Edges = append(g.Edges, e)
+			}
+		}
+		for _, v := range s.Views {
+			g.Nodes = append(g.Nodes, &depNode{ID: qualifiedID(s, v.Name), Type: "view", Schema: s.Name, Name: v.Name, def: v.Def})
+		}
+		for _, f := range s.Funcs {
+			g.Nodes = append(g.Nodes, &depNode{ID: qualifiedID(s, f.Name), Type: "function", Schema: s.Name, Name: f.Name, def: f.Body})
+		}
+		for _, p := range s.Procs {
+			g.Nodes = append(g.Nodes, &depNode{ID: qualifiedID(s, p.Name), Type: "procedure", Schema: s.Name, Name: p.Name, def: p.Body})
+		}
+		for _, t := range s.Tables {
+			for _, tr := range t.Triggers {
+				n := &depNode{
+					ID:     nodeID(t) + "." + tr.Name,
+					Type:   "trigger",
+					Schema: s.Name,
+					Name:   tr.Name,
+					def:    strings.Join(append([]string{tr.When, tr.Body}, tr.Events...), " "),
+				}
+				g.Nodes = append(g.Nodes, n)
+				g.Edges = append(g.Edges, &depEdge{From: n.ID, To: nodeID(t), Type: "trigger", Name: tr.Name})
+			}
+		}
+	}
+	g.addReferences()
+	return g
+}
+
+// addReferences adds an edge from each view, function, procedure and trigger
+// to the objects whose names appear in its definition. Names that appear in
+// comments or string literals may add false edges, and the graph is expected
+// to be validated against a database when accuracy matters.
+func (g *depGraph) addReferences() {
+	linked := make(map[[2]string]bool, len(g.Edges))
+	for _, e := range g.Edges {
+		linked[[2]string{e.From, e.To}] = true
+	}
+	for _, n := range g.Nodes {
+		if n.def == "" {
+			continue
+		}
+		for _, ref := range g.Nodes {
+			// Triggers cannot be referenced by other objects.
+			if ref == n || ref.Type == "trigger" || linked[[2]string{n.ID, ref.ID}] {
+				continue
+			}
+			if nameRegexp(ref.Name).MatchString(n.def) {
+				g.Edges = append(g.Edges, &depEdge{From: n.ID, To: ref.ID, Type: "reference"})
+			}
+		}
+	}
+}
+
+// node returns the node identified by the given id.
+func (g *depGraph) node(id string) *depNode {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// nameRegexp returns a regular expression that matches the given name as a whole word.
+func nameRegexp(name string) *regexp.Regexp {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+}
+
+// nodeID returns the identifier of the table in the graph.
+func nodeID(t *schema.Table) string {
+	return qualifiedID(t.Schema, t.Name)
+}
+
+// qualifiedID returns the identifier of a schema object in the graph.
+func qualifiedID(s *schema.Schema, name string) string {
+	if s == nil || s.Name == "" {
+		return name
+	}
+	return s.Name + "." + name
+}
+
+// writeJSON writes the graph to w in JSON format.
+func (g *depGraph) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// writeDOT writes the graph to w in the Graphviz DOT format.
+func (g *depGraph) writeDOT(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph {"); err != nil {
+		return err
+	}
+	for _, n := range g.Nodes {
+		if _, err := fmt.Fprintf(w, "  %s;\n", strconv.Quote(n.ID)); err != nil {
+			return err
+		}
+	}
+	for _, e := range g.Edges {
+		// Edges without a name are labeled with their type.
+		label := e.Name
+		if label == "" {
+			label = e.Type
+		}
+		if _, err := fmt.Fprintf(w, "  %s -> %s [label=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(label)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
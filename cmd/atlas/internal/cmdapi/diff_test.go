@@ -37,6 +37,15 @@ func TestDiffCmd_Synced(t *testing.T) {
 	require.EqualValues(t, "Schemas are synced, no changes to be made.\n", s)
 }
 
+func TestDiffCmd_InspectCache(t *testing.T) {
+	from := openSQLite(t, "")
+	to := openSQLite(t, "create table t1 (id int);")
+	// SQLite does not support change detection, and tables are always inspected.
+	s, err := runCmd(newDiffCmd(), "schema", "diff", "--from", from, "--to", to, "--inspect-cache", t.TempDir())
+	require.NoError(t, err)
+	require.EqualValues(t, "-- Create \"t1\" table\nCREATE TABLE `t1` (`id` int NULL)\n", s)
+}
+
 // openSQLite creates a sqlite db, seeds it with the seed query and returns the url to it.
 func openSQLite(t *testing.T, seed string) string {
 	f, err := os.CreateTemp("", "sqlite.db")
@@ -0,0 +1,129 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_Down(t *testing.T) {
+	t.Cleanup(func() {
+		DownFlags.ToVersion, DownFlags.DryRun, DownFlags.TxMode = "", false, txModeFile
+		MigrateFlags.DevURL = ""
+	})
+	// Global flags are set from other tests ...
+	MigrateFlags.DirFormat = formatAtlas
+	MigrateFlags.Apply.DryRun, MigrateFlags.Apply.BaselineVersion = false, ""
+	var (
+		p   = t.TempDir()
+		u   = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(p, "test.db"))
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_t1.sql", []byte("CREATE TABLE t1 (c1 int);\n")))
+	require.NoError(t, d.WriteFile("2_t2.sql", []byte("CREATE TABLE t2 (c2 int);\n")))
+	require.NoError(t, d.WriteFile("2_t2.down.sql", []byte("-- atlas:reverse 2_t2.sql\nDROP TABLE t2;\n")))
+	require.NoError(t, d.WriteFile("3_t3.sql", []byte("CREATE TABLE t3 (c3 int);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	_, err = runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u)
+	require.EqualError(t, err, "revisions table was not found in the connected database")
+	_, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", u)
+	require.NoError(t, err)
+	tables := func() []string {
+		c, err := sqlclient.Open(ctx, u)
+		require.NoError(t, err)
+		defer c.Close()
+		s, err := c.InspectSchema(ctx, "", nil)
+		require.NoError(t, err)
+		var names []string
+		for _, t := range s.Tables {
+			names = append(names, t.Name)
+		}
+		return names
+	}
+	require.Equal(t, []string{"atlas_schema_revisions", "t1", "t2", "t3"}, tables())
+
+	_, err = runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u, "0")
+	require.EqualError(t, err, "cannot revert '0' migration files")
+	_, err = runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u, "4")
+	require.EqualError(t, err, "cannot revert 4 migration files, only 3 were applied")
+	_, err = runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u, "--to-version", "5")
+	require.EqualError(t, err, `version "5" was not found in the revisions table`)
+	DownFlags.ToVersion = ""
+
+	// The reverse of files without a reverse file is computed on the dev database.
+	_, err = runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u)
+	require.EqualError(t, err, `migration file "3_t3.sql" has no reverse file, and --dev-url is required for computing it`)
+	s, err := runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u, "--dev-url", openSQLite(t, ""))
+	require.NoError(t, err)
+	require.Equal(t, `Migrating down from version 3 to version 2 (1 migration files)
+
+  -- reverting version 3 (computed)
+     -- disable the enforcement of foreign-keys constraints
+     PRAGMA foreign_keys = off;
+     -- drop "t3" table
+     DROP TABLE `+"`t3`"+`;
+     -- enable back the enforcement of foreign-keys constraints
+     PRAGMA foreign_keys = on;
+
+  -- 1 migration files reverted
+`, s)
+	require.Equal(t, []string{"atlas_schema_revisions", "t1", "t2"}, tables())
+
+	// Dry-run does not execute the statements.
+	s, err = runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u, "--to-version", "1", "--dry-run")
+	require.NoError(t, err)
+	require.Equal(t, "Migrating down from version 2 to version 1 (1 migration files)\n\n  -- reverting version 2 (2_t2.down.sql)\n     DROP TABLE t2;\n\n  -- 1 migration files reverted\n", s)
+	require.Equal(t, []string{"atlas_schema_revisions", "t1", "t2"}, tables())
+	DownFlags.DryRun = false
+
+	s, err = runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u, "--to-version", "1")
+	require.NoError(t, err)
+	require.Contains(t, s, "DROP TABLE t2;")
+	require.Equal(t, []string{"atlas_schema_revisions", "t1"}, tables())
+	s, err = runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u, "--to-version", "1")
+	require.NoError(t, err)
+	require.Equal(t, "The database is already at version 1, no migration files to revert\n", s)
+	DownFlags.ToVersion = ""
+
+	// Reverted files are pending again.
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", u)
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating to version 3 from 1 (2 migrations in total)")
+	require.Equal(t, []string{"atlas_schema_revisions", "t1", "t2", "t3"}, tables())
+}
+
+func TestRevertedRevisions(t *testing.T) {
+	revs := []*migrate.Revision{
+		{Version: "1", Type: migrate.RevisionTypeBaseline},
+		{Version: "2", Type: migrate.RevisionTypeExecute, Applied: 1, Total: 1},
+		{Version: "3", Type: migrate.RevisionTypeExecute, Applied: 1, Total: 2},
+	}
+	_, err := revertedRevisions(nil, 1, "")
+	require.EqualError(t, err, "no migration files were applied on the connected database")
+	_, err = revertedRevisions(revs, 1, "")
+	require.EqualError(t, err, `cannot revert version "3", as it was partially applied (1 of 2 statements)`)
+	revs[2].Applied = 2
+	r, err := revertedRevisions(revs, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Revision{revs[2], revs[1]}, r)
+	r, err = revertedRevisions(revs, 0, "2")
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Revision{revs[2]}, r)
+	_, err = revertedRevisions(revs, 3, "")
+	require.EqualError(t, err, `cannot revert baseline version "1"`)
+}
@@ -0,0 +1,210 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// excludeExternal is the scheme of --exclude values that are executed
+// as external programs, instead of being matched as glob patterns.
+const excludeExternal = "external://"
+
+type (
+	// excludeProgram is an external program that filters the inspected objects.
+	// The program receives the list of inspected objects in JSON format on its
+	// standard input, and writes the list of objects to keep on its standard
+	// output. Objects that are absent from the output are excluded. Excluding a
+	// column also excludes the indexes and foreign keys that use it.
+	excludeProgram struct {
+		name string
+		args []string
+	}
+
+	// excludeObject describes an inspected object passed to exclude programs.
+	excludeObject struct {
+		Type   string `json:"type"`
+		Schema string `json:"schema,omitempty"`
+		Table  string `json:"table,omitempty"`
+		Name   string `json:"name"`
+	}
+)
+
+// Object types passed to exclude programs.
+const (
+	excludeTypeSchema     = "schema"
+	excludeTypeTable      = "table"
+	excludeTypeColumn     = "column"
+	excludeTypeIndex      = "index"
+	excludeTypeForeignKey = "foreign_key"
+)
+
+// splitExclude splits the --exclude values into glob patterns and external programs.
+func splitExclude(values []string) ([]string, []*excludeProgram, error) {
+	var (
+		globs []string
+		progs []*excludeProgram
+	)
+	for _, v := range values {
+		if !strings.HasPrefix(v, excludeExternal) {
+			globs = append(globs, v)
+			continue
+		}
+		parts := strings.Fields(strings.TrimPrefix(v, excludeExternal))
+		if len(parts) == 0 {
+			return nil, nil, fmt.Errorf("missing program in exclude value %q", v)
+		}
+		progs = append(progs, &excludeProgram{name: parts[0], args: parts[1:]})
+	}
+	return globs, progs, nil
+}
+
+// inspectWorkers is the number of schemas that are inspected concurrently
+// by drivers that support it, when inspecting a realm of a live database.
+const inspectWorkers = 4
+
+// inspectRealm inspects the realm using the given options, and filters
+// the result using the external programs given in the exclude list.
+func inspectRealm(ctx context.Context, insp schema.Inspector, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	globs, progs, err := splitExclude(opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	opts.Exclude = globs
+	if opts.Workers == 0 {
+		opts.Workers = inspectWorkers
+	}
+	r, err := insp.InspectRealm(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range progs {
+		if err := p.filter(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// filter runs the program on the objects of the realm and removes
+// the objects that were not returned by it.
+func (p *excludeProgram) filter(ctx context.Context, r *schema.Realm) error {
+	in, err := json.Marshal(excludeObjects(r))
+	if err != nil {
+		return err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.name, p.args...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return fmt.Errorf("exclude program %q: %w", p.name, err)
+	}
+	var keep []excludeObject
+	if err := json.Unmarshal(stdout.Bytes(), &keep); err != nil {
+		return fmt.Errorf("exclude program %q: invalid output: %w", p.name, err)
+	}
+	kept := make(map[excludeObject]bool, len(keep))
+	for _, o := range keep {
+		kept[o] = true
+	}
+	schemas := r.Schemas[:0]
+	for _, s := range r.Schemas {
+		if !kept[excludeObject{Type: excludeTypeSchema, Name: s.Name}] {
+			continue
+		}
+		tables := s.Tables[:0]
+		for _, t := range s.Tables {
+			if !kept[excludeObject{Type: excludeTypeTable, Schema: s.Name, Name: t.Name}] {
+				continue
+			}
+			excludeTable(t, func(typ, name string) bool {
+				return kept[excludeObject{Type: typ, Schema: s.Name, Table: t.Name, Name: name}]
+			})
+			tables = append(tables, t)
+		}
+		s.Tables = tables
+		schemas = append(schemas, s)
+	}
+	r.Schemas = schemas
+	return nil
+}
+
+// excludeTable removes the columns, indexes and foreign keys of
+// the table that are not kept. Indexes and foreign keys that use
+// an excluded column are removed as well.
+func excludeTable(t *schema.Table, kept func(typ, name string) bool) {
+	var (
+		columns  []*schema.Column
+		excluded = make(map[*schema.Column]bool)
+	)
+	for _, c := range t.Columns {
+		if kept(excludeTypeColumn, c.Name) {
+			columns = append(columns, c)
+		} else {
+			excluded[c] = true
+		}
+	}
+	t.Columns = columns
+	var indexes []*schema.Index
+	for _, idx := range t.Indexes {
+		if kept(excludeTypeIndex, idx.Name) && !usesColumn(idx, excluded) {
+			indexes = append(indexes, idx)
+		}
+	}
+	t.Indexes = indexes
+	var fks []*schema.ForeignKey
+	for _, fk := range t.ForeignKeys {
+		keep := kept(excludeTypeForeignKey, fk.Symbol)
+		for _, c := range fk.Columns {
+			keep = keep && !excluded[c]
+		}
+		if keep {
+			fks = append(fks, fk)
+		}
+	}
+	t.ForeignKeys = fks
+}
+
+// usesColumn reports if the index uses one of the given columns.
+func usesColumn(idx *schema.Index, columns map[*schema.Column]bool) bool {
+	for _, p := range idx.Parts {
+		if p.C != nil && columns[p.C] {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeObjects returns the objects of the realm passed to exclude programs.
+func excludeObjects(r *schema.Realm) []excludeObject {
+	objs := make([]excludeObject, 0)
+	for _, s := range r.Schemas {
+		objs = append(objs, excludeObject{Type: excludeTypeSchema, Name: s.Name})
+		for _, t := range s.Tables {
+			objs = append(objs, excludeObject{Type: excludeTypeTable, Schema: s.Name, Name: t.Name})
+			for _, c := range t.Columns {
+				objs = append(objs, excludeObject{Type: excludeTypeColumn, Schema: s.Name, Table: t.Name, Name: c.Name})
+			}
+			for _, idx := range t.Indexes {
+				objs = append(objs, excludeObject{Type: excludeTypeIndex, Schema: s.Name, Table: t.Name, Name: idx.Name})
+			}
+			for _, fk := range t.ForeignKeys {
+				objs = append(objs, excludeObject{Type: excludeTypeForeignKey, Schema: s.Name, Table: t.Name, Name: fk.Symbol})
+			}
+		}
+	}
+	return objs
+}
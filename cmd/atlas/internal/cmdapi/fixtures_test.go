@@ -0,0 +1,152 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Seed(t *testing.T) {
+	t.Cleanup(func() { ApplyFlags.Paths = nil })
+	var (
+		ctx = context.Background()
+		dir = t.TempDir()
+		u   = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+	require.NoError(t, os.WriteFile("schema.hcl", []byte(`
+schema "main" {}
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  column "name" {
+    type = text
+    null = true
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+table "posts" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  column "author_id" {
+    type = int
+  }
+  column "title" {
+    type = text
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+table "tags" {
+  schema = schema.main
+  column "name" {
+    type = text
+  }
+}
+`), 0600))
+	require.NoError(t, os.MkdirAll("fixtures", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("fixtures", "users.csv"), []byte("id,name\n1,a8m\n2,\"rotem, m\"\n3,\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join("fixtures", "tags.sql"), []byte("INSERT INTO tags VALUES ('go');\nINSERT INTO tags VALUES ('sql');\n"), 0600))
+	require.NoError(t, os.WriteFile(projectFileName, []byte(fmt.Sprintf(`
+env "local" {
+  url = %q
+  src = "schema.hcl"
+  fixture "users" {
+    src = "fixtures/users.csv"
+  }
+  fixture "main.posts" {
+    row {
+      id        = 1
+      author_id = 1
+      title     = "It's a post"
+    }
+    row {
+      id        = 2
+      author_id = 2
+      title     = "Second"
+    }
+  }
+  fixture "tags" {
+    src      = "file://fixtures/tags.sql"
+    truncate = "none"
+  }
+}
+`, u)), 0600))
+
+	ApplyFlags.Paths = nil
+	s, err := runCmd(Root, "schema", "apply", "--env", "local", "-u", u, "-f", "schema.hcl", "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "Seeded fixtures:\n  -- users: 3 statements\n  -- main.posts: 2 statements\n  -- tags: 2 statements\n")
+
+	c, err := sqlclient.Open(ctx, u)
+	require.NoError(t, err)
+	defer c.Close()
+	rows := func(query string) []string {
+		rows, err := c.QueryContext(ctx, query)
+		require.NoError(t, err)
+		defer rows.Close()
+		var vs []string
+		for rows.Next() {
+			var v string
+			require.NoError(t, rows.Scan(&v))
+			vs = append(vs, v)
+		}
+		return vs
+	}
+	require.Equal(t, []string{"1:a8m", "2:rotem, m", "3:<null>"}, rows("SELECT id || ':' || coalesce(name, '<null>') FROM users ORDER BY id"))
+	require.Equal(t, []string{"1:1:It's a post", "2:2:Second"}, rows("SELECT id || ':' || author_id || ':' || title FROM posts ORDER BY id"))
+	require.Equal(t, []string{"go", "sql"}, rows("SELECT name FROM tags ORDER BY name"))
+
+	// Deleted rows are restored, and rows of tables
+	// without a truncation policy are kept.
+	_, err = c.ExecContext(ctx, "DELETE FROM posts WHERE id = 2")
+	require.NoError(t, err)
+	s, err = runCmd(Root, "schema", "seed", "--env", "local", "-u", u, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "Seeded fixtures:")
+	require.Equal(t, []string{"1:1:It's a post", "2:2:Second"}, rows("SELECT id || ':' || author_id || ':' || title FROM posts ORDER BY id"))
+	require.Equal(t, []string{"go", "go", "sql", "sql"}, rows("SELECT name FROM tags ORDER BY name"))
+
+	// Failures roll back the seeding.
+	require.NoError(t, os.WriteFile(filepath.Join("fixtures", "users.csv"), []byte("id,name\n1,a8m\n1,dup\n"), 0600))
+	_, err = runCmd(Root, "schema", "seed", "--env", "local", "-u", u, "--auto-approve")
+	require.ErrorContains(t, err, `fixture "users": executing "INSERT INTO \"users\" (\"id\", \"name\") VALUES (?, ?)"`)
+	require.Equal(t, []string{"1:a8m", "2:rotem, m", "3:<null>"}, rows("SELECT id || ':' || coalesce(name, '<null>') FROM users ORDER BY id"))
+
+	// SQLite does not support TRUNCATE.
+	require.NoError(t, os.WriteFile(projectFileName, []byte(fmt.Sprintf(`
+env "local" {
+  url = %q
+  fixture "users" {
+    src      = "fixtures/users.csv"
+    truncate = "truncate"
+  }
+}
+`, u)), 0600))
+	_, err = runCmd(Root, "schema", "seed", "--env", "local", "-u", u, "--auto-approve")
+	require.EqualError(t, err, `fixture "users": truncate policy "truncate" is not supported by SQLite, use "delete" instead`)
+}
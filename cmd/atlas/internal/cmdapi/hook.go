@@ -0,0 +1,334 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	diffHookFlag      = "diff-hook"
+	execHookFlag      = "exec-hook"
+	detectRenamesFlag = "detect-renames"
+	namingFlag        = "naming"
+)
+
+// diffHooks holds the registered diff hooks by name.
+var diffHooks sync.Map
+
+// RegisterDiffHook allows custom Atlas distributions to register a schema.DiffHook
+// under the given name. Registered hooks are not enabled by default, and can be
+// selected by the user using the --diff-hook flag. For example:
+//
+//	func init() {
+//		cmdapi.RegisterDiffHook("no-drop-column", schema.SkipChanges(func(c schema.Change) bool {
+//			_, ok := c.(*schema.DropColumn)
+//			return ok
+//		}))
+//	}
+func RegisterDiffHook(name string, h schema.DiffHook) {
+	if h == nil {
+		panic("cmdapi: RegisterDiffHook hook is nil")
+	}
+	if _, ok := diffHooks.Load(name); ok {
+		panic("cmdapi: RegisterDiffHook called twice for " + name)
+	}
+	diffHooks.Store(name, h)
+}
+
+// diffOptions returns the schema.DiffOption for the given hook names.
+func diffOptions(names []string) ([]schema.DiffOption, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	hooks := make([]schema.DiffHook, 0, len(names))
+	for _, n := range names {
+		h, ok := diffHooks.Load(n)
+		if !ok {
+			return nil, fmt.Errorf("diff hook %q was not registered", n)
+		}
+		hooks = append(hooks, h.(schema.DiffHook))
+	}
+	return []schema.DiffOption{schema.DiffWithHooks(hooks...)}, nil
+}
+
+// renameOption returns the schema.DiffOption for detecting renames. Renames that
+// were declared explicitly in the desired state are always detected, and the
+// heuristics are used only if detect is true. If confirm is true, the user is
+// prompted to confirm each rename proposed by the heuristics.
+func renameOption(detect, confirm bool) schema.DiffOption {
+	opts := &schema.RenameOptions{ExplicitOnly: !detect}
+	if detect && confirm {
+		opts.Confirm = promptRename
+	}
+	return schema.DiffWithRenames(opts)
+}
+
+// nameObjects names the unnamed indexes, foreign keys and checks of the desired state, if the
+// --naming flag was given. Each value is either "hash", for using the default naming templates,
+// or a "kind=template" pair that overrides the template of the given kind.
+func nameObjects(values []string, drv string, r *schema.Realm) error {
+	if len(values) == 0 {
+		return nil
+	}
+	var opts []schema.NamerOption
+	for _, v := range values {
+		if v == "hash" {
+			continue
+		}
+		kind, text, ok := strings.Cut(v, "=")
+		if !ok {
+			return fmt.Errorf("invalid --%s value %q, expect \"hash\" or \"kind=template\"", namingFlag, v)
+		}
+		opts = append(opts, schema.WithNameTemplate(kind, text))
+	}
+	// Names are limited by the identifier length of the database.
+	switch drv {
+	case mysql.DriverName:
+		opts = append(opts, schema.WithNameMaxLen(64))
+	case postgres.DriverName:
+		opts = append(opts, schema.WithNameMaxLen(63))
+	}
+	n, err := schema.NewNamer(opts...)
+	if err != nil {
+		return err
+	}
+	return n.NameRealm(r)
+}
+
+const (
+	unmanagedWarn  = "warn"
+	unmanagedError = "error"
+)
+
+// diffPolicy returns the schema.DiffOption for the diff policy configured in the
+// project file for the selected environment, and the identifier case handling of
+// the database the driver is connected to.
+func diffPolicy(cmd *cobra.Command, drv migrate.Driver) ([]schema.DiffOption, error) {
+	env, err := selectEnv(GlobalFlags.SelectedEnv)
+	if err != nil {
+		return nil, err
+	}
+	var opts []schema.DiffOption
+	if env.Diff != nil {
+		opts = append(opts, schema.DiffWithPolicy(env.Diff.Policy()))
+		if u := env.Diff.Unmanaged; u != nil {
+			h, err := unmanagedHook(cmd, u)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, schema.DiffWithHooks(h))
+		}
+	}
+	identCase, err := identCaseOption(cmd.Context(), env, drv)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, identCase...)
+	// The scope is checked after the policy was applied.
+	if s, ok := env.OwnershipScope(); ok {
+		opts = append(opts, schema.DiffWithHooks(schema.RestrictToScope(s)))
+	}
+	return opts, nil
+}
+
+// identCaseOption returns the schema.DiffOption for comparing identifiers. The identifier
+// case handling is configured in the diff block of the env, or resolved from the settings
+// of the database the given driver is connected to.
+func identCaseOption(ctx context.Context, env *Env, drv migrate.Driver) ([]schema.DiffOption, error) {
+	var (
+		o   *schema.IdentCaseOptions
+		err error
+	)
+	switch ic, ok := drv.(schema.IdentCaser); {
+	case env.Diff != nil && env.Diff.IdentCase != nil:
+		o, err = env.Diff.IdentCase.Options()
+	case ok:
+		o, err = ic.IdentCase(ctx)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []schema.DiffOption{schema.DiffWithIdentCase(o)}, nil
+}
+
+// unmanagedHook returns the schema.DiffHook for protecting unmanaged objects.
+func unmanagedHook(cmd *cobra.Command, u *Unmanaged) (schema.DiffHook, error) {
+	var report func([]*schema.UnmanagedObject) error
+	switch u.Mode {
+	case "", unmanagedWarn:
+		report = func(objs []*schema.UnmanagedObject) error {
+			for _, o := range objs {
+				cmd.PrintErrf("Warning: %s %q exists in the database but is absent from the desired state, and it will not be dropped\n", o.Type, o.Name)
+			}
+			return nil
+		}
+	case unmanagedError:
+		report = func(objs []*schema.UnmanagedObject) error {
+			names := make([]string, len(objs))
+			for i, o := range objs {
+				names[i] = fmt.Sprintf("%s %q", o.Type, o.Name)
+			}
+			return fmt.Errorf("found %d unmanaged objects in the database: %s", len(objs), strings.Join(names, ", "))
+		}
+	default:
+		return nil, fmt.Errorf("unknown unmanaged mode %q", u.Mode)
+	}
+	return schema.ProtectUnmanaged(u.Allow, report), nil
+}
+
+// cmdExecHook is a migrate.ExecHook that bridges the statement execution
+// to an external command. The command is invoked twice for each statement,
+// once before and once after its execution, with the following environment
+// variables set:
+//
+//	ATLAS_HOOK_PHASE   "before" or "after"
+//	ATLAS_FILE         the name of the migration file
+//	ATLAS_VERSION      the version of the migration file
+//	ATLAS_STMT_INDEX   the index of the statement in the file
+//	ATLAS_STMT_ERROR   the execution error, if any ("after" phase only)
+//
+// The statement is passed to the command on its standard input. In the "before"
+// phase, a non-empty output replaces the statement with the statements it contains,
+// and an output without statements (e.g. only comments) skips the execution. An
+// empty output keeps the statement as is. A non-zero exit code vetoes the statement
+// in the "before" phase, and aborts the execution in the "after" phase. The command
+// is not invoked in dry-runs.
+type cmdExecHook struct {
+	name string
+	args []string
+}
+
+// newCmdExecHook returns a cmdExecHook from the given command line. The command
+// line is split into arguments using the quoting rules of POSIX shells.
+func newCmdExecHook(cmdline string) (*cmdExecHook, error) {
+	parts, err := shellSplit(cmdline)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s command: %w", execHookFlag, err)
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty %s command", execHookFlag)
+	}
+	return &cmdExecHook{name: parts[0], args: parts[1:]}, nil
+}
+
+// shellSplit splits the given command line into words, similar to POSIX shells.
+// Words are separated by unquoted whitespace. Single quotes preserve the literal
+// value of the characters they enclose, and a backslash escapes the next character,
+// or inside double quotes, only one of: $ ` " \ or newline. Other shell expansions
+// (e.g. variables or globs) are not supported.
+func shellSplit(s string) ([]string, error) {
+	var (
+		words []string
+		word  strings.Builder
+		in    bool // in word
+		quote rune
+		rs    = []rune(s)
+	)
+	for i := 0; i < len(rs); i++ {
+		switch r := rs[i]; {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(rs) && strings.ContainsRune("$`\"\\\n", rs[i+1]):
+				i++
+				word.WriteRune(rs[i])
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			in, quote = true, r
+		case r == '\\':
+			if i+1 == len(rs) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			in = true
+			word.WriteRune(rs[i])
+		case unicode.IsSpace(r):
+			if in {
+				words = append(words, word.String())
+				word.Reset()
+				in = false
+			}
+		default:
+			in = true
+			word.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed quote %q", quote)
+	}
+	if in {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// BeforeExec implements migrate.ExecHook.
+func (h *cmdExecHook) BeforeExec(ctx context.Context, s *migrate.HookStmt) ([]string, error) {
+	out, err := h.run(ctx, s, "before", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return []string{s.Stmt}, nil
+	}
+	return migrate.NewLocalFile(s.File.Name(), out).Stmts()
+}
+
+// AfterExec implements migrate.ExecHook.
+func (h *cmdExecHook) AfterExec(ctx context.Context, s *migrate.HookStmt, err error) error {
+	_, err = h.run(ctx, s, "after", err)
+	return err
+}
+
+func (h *cmdExecHook) run(ctx context.Context, s *migrate.HookStmt, phase string, serr error) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.name, h.args...)
+	cmd.Stdin = strings.NewReader(s.Stmt)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.Env = append(os.Environ(),
+		"ATLAS_HOOK_PHASE="+phase,
+		"ATLAS_FILE="+s.File.Name(),
+		"ATLAS_VERSION="+s.File.Version(),
+		"ATLAS_STMT_INDEX="+strconv.Itoa(s.Index),
+	)
+	if serr != nil {
+		cmd.Env = append(cmd.Env, "ATLAS_STMT_ERROR="+serr.Error())
+	}
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("%s command %q: %w", phase, h.name, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterDiffHook(t *testing.T) {
+	h := schema.SkipChanges(func(c schema.Change) bool {
+		_, ok := c.(*schema.DropColumn)
+		return ok
+	})
+	RegisterDiffHook("test-no-drop-column", h)
+	require.Panics(t, func() { RegisterDiffHook("test-no-drop-column", h) })
+
+	opts, err := diffOptions(nil)
+	require.NoError(t, err)
+	require.Empty(t, opts)
+
+	_, err = diffOptions([]string{"unknown"})
+	require.EqualError(t, err, `diff hook "unknown" was not registered`)
+
+	opts, err = diffOptions([]string{"test-no-drop-column"})
+	require.NoError(t, err)
+	changes, err := schema.NewDiffOptions(opts...).RunHooks([]schema.Change{
+		&schema.DropColumn{C: schema.NewColumn("c")},
+	})
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiffPolicy(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, projectFileName), []byte(`
+diff {
+	unmanaged {
+		allow = ["*.flyway_*"]
+	}
+}
+
+env "warn" {
+	diff {
+		skip {
+			drop_schema = true
+		}
+	}
+}
+
+env "ident" {
+	diff {
+		ident_case {
+			schemas = "lower"
+			tables  = "lower"
+			columns = "insensitive"
+		}
+	}
+}
+
+env "invalid" {
+	diff {
+		ident_case {
+			tables = "upper"
+		}
+	}
+}
+
+env "error" {
+	diff {
+		unmanaged {
+			mode  = "error"
+			allow = ["public.users.legacy"]
+		}
+	}
+}
+`), 0600))
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		GlobalFlags.SelectedEnv = ""
+		require.NoError(t, os.Chdir(wd))
+	})
+	public := schema.New("public")
+	changes := []schema.Change{
+		&schema.DropSchema{S: schema.New("other")},
+		&schema.DropTable{T: schema.NewTable("flyway_history").SetSchema(public)},
+		&schema.DropTable{T: schema.NewTable("posts").SetSchema(public)},
+		&schema.ModifyTable{
+			T:       schema.NewTable("users").SetSchema(public),
+			Changes: []schema.Change{&schema.DropColumn{C: schema.NewColumn("legacy")}},
+		},
+	}
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetErr(&out)
+	GlobalFlags.SelectedEnv = "warn"
+	opts, err := diffPolicy(cmd, nil)
+	require.NoError(t, err)
+	protected, err := schema.NewDiffOptions(opts...).RunHooks(changes)
+	require.NoError(t, err)
+	require.Empty(t, protected)
+	require.Equal(t, `Warning: table "public.posts" exists in the database but is absent from the desired state, and it will not be dropped
+Warning: column "public.users.legacy" exists in the database but is absent from the desired state, and it will not be dropped
+`, out.String())
+
+	GlobalFlags.SelectedEnv = "error"
+	opts, err = diffPolicy(cmd, nil)
+	require.NoError(t, err)
+	_, err = schema.NewDiffOptions(opts...).RunHooks(changes)
+	require.EqualError(t, err, `found 2 unmanaged objects in the database: schema "other", table "public.posts"`)
+	require.Nil(t, schema.NewDiffOptions(opts...).IdentCase)
+
+	GlobalFlags.SelectedEnv = "ident"
+	opts, err = diffPolicy(cmd, nil)
+	require.NoError(t, err)
+	require.Equal(t, &schema.IdentCaseOptions{
+		Schemas: schema.IdentCaseLower,
+		Tables:  schema.IdentCaseLower,
+		Columns: schema.IdentCaseInsensitive,
+	}, schema.NewDiffOptions(opts...).IdentCase)
+
+	// The identifier case is resolved from the connected
+	// database, unless it is configured in the project file.
+	drv := &identCaseDriver{opts: &schema.IdentCaseOptions{Columns: schema.IdentCaseInsensitive}}
+	opts, err = diffPolicy(cmd, drv)
+	require.NoError(t, err)
+	require.Equal(t, schema.IdentCaseLower, schema.NewDiffOptions(opts...).IdentCase.Schemas)
+	GlobalFlags.SelectedEnv = "error"
+	opts, err = diffPolicy(cmd, drv)
+	require.NoError(t, err)
+	require.Equal(t, drv.opts, schema.NewDiffOptions(opts...).IdentCase)
+
+	GlobalFlags.SelectedEnv = "invalid"
+	_, err = diffPolicy(cmd, nil)
+	require.EqualError(t, err, `sql/schema: unknown identifier case "upper"`)
+}
+
+func TestCmdExecHook(t *testing.T) {
+	_, err := newCmdExecHook(" ")
+	require.EqualError(t, err, "empty exec-hook command")
+
+	script := filepath.Join(t.TempDir(), "hook.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`
+stmt=$(cat)
+if [ "$ATLAS_HOOK_PHASE" = "after" ]; then
+  [ -z "$ATLAS_STMT_ERROR" ] || exit 1
+  exit 0
+fi
+case "$stmt" in
+  DROP*) echo "drop is not allowed in $ATLAS_FILE:$ATLAS_STMT_INDEX" >&2; exit 1 ;;
+  ALTER*) echo "SET lock_wait_timeout = 1;"; echo "$stmt" ;;
+  CREATE*) echo "-- skipped" ;;
+esac
+`), 0644))
+	h, err := newCmdExecHook("sh " + script)
+	require.NoError(t, err)
+	var (
+		ctx = context.Background()
+		f   = migrate.NewLocalFile("1_init.sql", nil)
+	)
+	stmts, err := h.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: "ALTER TABLE t ADD c int;"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"SET lock_wait_timeout = 1;", "ALTER TABLE t ADD c int;"}, stmts)
+
+	stmts, err = h.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: "CREATE TABLE t(c int);"})
+	require.NoError(t, err)
+	require.Empty(t, stmts)
+
+	stmts, err = h.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: "INSERT INTO t VALUES (1);"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"INSERT INTO t VALUES (1);"}, stmts)
+
+	_, err = h.BeforeExec(ctx, &migrate.HookStmt{File: f, Index: 2, Stmt: "DROP TABLE t;"})
+	require.EqualError(t, err, `before command "sh": exit status 1: drop is not allowed in 1_init.sql:2`)
+
+	require.NoError(t, h.AfterExec(ctx, &migrate.HookStmt{File: f, Stmt: "DROP TABLE t;"}, nil))
+	require.Error(t, h.AfterExec(ctx, &migrate.HookStmt{File: f, Stmt: "DROP TABLE t;"}, context.Canceled))
+
+	// Arguments are split using shell quoting rules.
+	h, err = newCmdExecHook(`notify --msg "x y" 'a "b"' c\ d`)
+	require.NoError(t, err)
+	require.Equal(t, "notify", h.name)
+	require.Equal(t, []string{"--msg", "x y", `a "b"`, "c d"}, h.args)
+	_, err = newCmdExecHook(`notify --msg "x y`)
+	require.EqualError(t, err, `parsing exec-hook command: unclosed quote '"'`)
+}
+
+func TestShellSplit(t *testing.T) {
+	for s, expected := range map[string][]string{
+		"":                    nil,
+		"  a  b\tc\n":         {"a", "b", "c"},
+		`a "" ''`:             {"a", "", ""},
+		`a"b c"'d e'`:         {"ab cd e"},
+		`"a \"b\" \$c \d"`:    {`a "b" $c \d`},
+		`'a \b'`:              {`a \b`},
+		"a\\ b \\\\":          {"a b", "\\"},
+		"sh -c 'echo \"$x\"'": {"sh", "-c", `echo "$x"`},
+	} {
+		words, err := shellSplit(s)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, words, s)
+	}
+	_, err := shellSplit(`a\`)
+	require.EqualError(t, err, "trailing backslash")
+	_, err = shellSplit(`'a`)
+	require.EqualError(t, err, `unclosed quote '\''`)
+}
+
+type identCaseDriver struct {
+	migrate.Driver
+	opts *schema.IdentCaseOptions
+}
+
+func (d *identCaseDriver) IdentCase(context.Context) (*schema.IdentCaseOptions, error) {
+	return d.opts, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Impact(t *testing.T) {
+	t.Cleanup(func() { ImpactFlags.Change, ImpactFlags.DevURL = "", "" })
+	var (
+		ctx    = context.Background()
+		u      = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+		c, err = sqlclient.Open(ctx, u)
+	)
+	require.NoError(t, err)
+	defer c.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE `users` (`id` int NOT NULL PRIMARY KEY, `email` text NOT NULL, CONSTRAINT `email_check` CHECK (length(email) > 3))",
+		"CREATE INDEX `users_email` ON `users` (`email`)",
+		"CREATE TABLE `posts` (`id` int NOT NULL PRIMARY KEY, `author_id` int NOT NULL, CONSTRAINT `author` FOREIGN KEY (`author_id`) REFERENCES `users` (`id`))",
+		"CREATE INDEX `posts_author` ON `posts` (`author_id`)",
+		"CREATE VIEW `user_emails` AS SELECT `id`, `email` FROM `users`",
+		"CREATE TRIGGER `users_delete` AFTER DELETE ON `users` BEGIN DELETE FROM `posts` WHERE `author_id` = OLD.`id`; END",
+	} {
+		_, err = c.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	for change, expected := range map[string]string{
+		"drop column users.email": `Objects that depend on column "email" of table "main.users":
+
+  -- view "main.user_emails": its definition uses the dropped column
+  -- index "users_email" of table "main.users": contains the dropped column
+  -- check "email_check" of table "main.users": its expression uses the dropped column
+`,
+		"drop column main.users.id": `Objects that depend on column "id" of table "main.users":
+
+  -- foreign key "author" of table "main.posts": references the dropped column
+  -- trigger "users_delete" of table "main.users": its definition uses the dropped column
+  -- view "main.user_emails": its definition uses the dropped column
+  -- primary key of table "main.users": contains the dropped column
+`,
+		"DROP TABLE users": `Objects that depend on table "main.users":
+
+  -- foreign key "author" of table "main.posts": references the dropped table
+  -- trigger "users_delete" of table "main.users": is dropped with the table
+  -- view "main.user_emails": references the dropped table
+`,
+		"drop index posts.posts_author": `Objects that depend on index "posts_author" of table "main.posts":
+
+  -- foreign key "author" of table "main.posts": its columns are covered by the dropped index
+`,
+		"drop table posts": `Objects that depend on table "main.posts":
+
+  -- trigger "users_delete" of table "main.users": references the dropped table
+`,
+	} {
+		s, err := runCmd(Root, "schema", "impact", "-u", u, "--change", change)
+		require.NoError(t, err)
+		require.Equal(t, expected, s, change)
+	}
+	for change, expected := range map[string]string{
+		"alter table users":      `unsupported change "alter table users", expect: drop (table|column|index) <name>`,
+		"drop view v":            `unsupported object type "view", expect: table, column or index`,
+		"drop table unknown":     `table "unknown" was not found`,
+		"drop column users":      `column name must be qualified with its table: "users"`,
+		"drop column users.name": `column "name" was not found in table "main.users"`,
+		"drop index a.b.c.d":     `invalid table name "a.b.c"`,
+	} {
+		_, err := runCmd(Root, "schema", "impact", "-u", u, "--change", change)
+		require.EqualError(t, err, expected, change)
+	}
+
+	// Validate the change on a dev database.
+	dev := "sqlite://dev?mode=memory&_fk=1"
+	s, err := runCmd(Root, "schema", "impact", "-u", u, "--change", "drop column users.email", "--dev-url", dev)
+	require.NoError(t, err)
+	require.Contains(t, s, "The change failed on the dev database: create \"new_users\" table: no such column: email")
+	s, err = runCmd(Root, "schema", "impact", "-u", u, "--change", "drop index posts.posts_author", "--dev-url", dev)
+	require.NoError(t, err)
+	require.Contains(t, s, "The change was applied successfully on the dev database")
+
+	// The inspected database is not changed.
+	r, err := c.InspectRealm(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, r.Schemas[0].Tables, 2)
+	users, ok := r.Schemas[0].Table("users")
+	require.True(t, ok)
+	require.Len(t, users.Columns, 2)
+}
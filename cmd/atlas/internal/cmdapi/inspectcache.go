@@ -0,0 +1,38 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/schemainspect"
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+// inspectCacheFlag is the flag used to set the directory of the inspection cache.
+const inspectCacheFlag = "inspect-cache"
+
+// cachedInspector returns an inspector that re-inspects only the tables that were changed in the
+// connected database since the last execution, and loads the rest from a cache stored in the given
+// directory. Each database has its own cache, keyed by the driver and the URL (without credentials).
+// If the directory is empty, the client is returned as is.
+func cachedInspector(client *sqlclient.Client, dir string) schema.Inspector {
+	if dir == "" {
+		return client
+	}
+	key := client.Name
+	if client.URL != nil && client.URL.URL != nil {
+		key += client.URL.Redacted()
+	}
+	h := sha256.Sum256([]byte(key))
+	return schemainspect.New(
+		client.Driver,
+		client,
+		schemainspect.WithCache(schemainspect.NewDirCache(filepath.Join(dir, hex.EncodeToString(h[:8])))),
+	)
+}
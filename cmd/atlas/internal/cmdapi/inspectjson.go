@@ -0,0 +1,84 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"encoding/json"
+	"io"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// inspectDoc is the JSON representation of an inspected realm. It describes
+	// the tables and the columns of the realm along with their annotations, and
+	// is meant to be consumed by external tools (e.g. data governance).
+	inspectDoc struct {
+		Schemas []*inspectSchema `json:"schemas"`
+	}
+
+	// inspectSchema is the JSON representation of an inspected schema.
+	inspectSchema struct {
+		Name   string          `json:"name"`
+		Tables []*inspectTable `json:"tables,omitempty"`
+	}
+
+	// inspectTable is the JSON representation of an inspected table.
+	inspectTable struct {
+		Name        string            `json:"name"`
+		Comment     string            `json:"comment,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+		Columns     []*inspectColumn  `json:"columns,omitempty"`
+	}
+
+	// inspectColumn is the JSON representation of an inspected column.
+	inspectColumn struct {
+		Name        string            `json:"name"`
+		Type        string            `json:"type"`
+		Null        bool              `json:"null,omitempty"`
+		Comment     string            `json:"comment,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+	}
+)
+
+// newInspectDoc builds the JSON document of the given realm.
+func newInspectDoc(r *schema.Realm) *inspectDoc {
+	d := &inspectDoc{Schemas: []*inspectSchema{}}
+	for _, s := range r.Schemas {
+		ds := &inspectSchema{Name: s.Name}
+		for _, t := range s.Tables {
+			dt := &inspectTable{Name: t.Name}
+			dt.Comment, dt.Annotations = splitComment(t.Attrs)
+			for _, c := range t.Columns {
+				dc := &inspectColumn{Name: c.Name}
+				if c.Type != nil {
+					dc.Type, dc.Null = c.Type.Raw, c.Type.Null
+				}
+				dc.Comment, dc.Annotations = splitComment(c.Attrs)
+				dt.Columns = append(dt.Columns, dc)
+			}
+			ds.Tables = append(ds.Tables, dt)
+		}
+		d.Schemas = append(d.Schemas, ds)
+	}
+	return d
+}
+
+// writeJSON writes the document to w in JSON format.
+func (d *inspectDoc) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// splitComment returns the comment text and the annotations of the element, if exist.
+func splitComment(attrs []schema.Attr) (string, map[string]string) {
+	for _, a := range attrs {
+		if c, ok := a.(*schema.Comment); ok {
+			return c.Split()
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+const logFormatJSON = "json"
+
+type (
+	// LogJSON is a migrate.Logger that writes the execution progress
+	// in JSON lines format, one JSON object per log entry.
+	LogJSON struct {
+		enc *json.Encoder
+	}
+
+	// jsonLogEntry is a single entry written by LogJSON.
+	jsonLogEntry struct {
+		Type       string               `json:"type"`
+		Time       time.Time            `json:"time"`
+		From       string               `json:"from,omitempty"`
+		To         string               `json:"to,omitempty"`
+		Files      []string             `json:"files,omitempty"`
+		Version    string               `json:"version,omitempty"`
+		Desc       string               `json:"desc,omitempty"`
+		Skip       int                  `json:"skip,omitempty"`
+		SQL        string               `json:"sql,omitempty"`
+		Error      string               `json:"error,omitempty"`
+		Position   *jsonErrorPos        `json:"position,omitempty"`
+		Constraint *jsonErrorConstraint `json:"constraint,omitempty"`
+		Warning    *jsonWarning         `json:"warning,omitempty"`
+	}
+
+	// jsonWarning is a warning reported by the database for an executed statement.
+	jsonWarning struct {
+		Level   string `json:"level"`
+		Code    string `json:"code,omitempty"`
+		Message string `json:"message"`
+	}
+
+	// jsonErrorPos is the position of an error in the migration file.
+	jsonErrorPos struct {
+		File   string `json:"file"`
+		Stmt   int    `json:"stmt"`
+		Offset int    `json:"offset"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	}
+
+	// jsonErrorConstraint is the constraint violated by a migration statement.
+	jsonErrorConstraint struct {
+		Kind    string   `json:"kind,omitempty"`
+		Table   string   `json:"table,omitempty"`
+		Name    string   `json:"name,omitempty"`
+		Columns []string `json:"columns,omitempty"`
+		Values  []string `json:"values,omitempty"`
+		File    string   `json:"file"`
+		Stmt    int      `json:"stmt"`
+		Line    int      `json:"line,omitempty"`
+	}
+)
+
+// NewLogJSON returns a LogJSON that writes to the given writer.
+func NewLogJSON(w io.Writer) *LogJSON {
+	return &LogJSON{enc: json.NewEncoder(w)}
+}
+
+// Log implements the migrate.Logger interface.
+func (l *LogJSON) Log(e migrate.LogEntry) {
+	r := &jsonLogEntry{Time: time.Now().UTC()}
+	switch e := e.(type) {
+	case migrate.LogExecution:
+		r.Type, r.From, r.To, r.Files = "execution", e.From, e.To, e.Files
+	case migrate.LogFile:
+		r.Type, r.Version, r.Desc, r.Skip = "file", e.Version, e.Desc, e.Skip
+	case migrate.LogStmt:
+		r.Type, r.SQL = "stmt", e.SQL
+	case migrate.LogWarning:
+		r.Type, r.SQL = "warning", e.SQL
+		r.Warning = &jsonWarning{Level: e.Warning.Level, Code: e.Warning.Code, Message: e.Warning.Message}
+	case migrate.LogDone:
+		r.Type = "done"
+	case migrate.LogError:
+		r.Type, r.Error = "error", e.Error.Error()
+		if perr := (*migrate.PosError)(nil); errors.As(e.Error, &perr) {
+			r.Position = &jsonErrorPos{File: perr.File, Stmt: perr.Stmt, Offset: perr.Offset, Line: perr.Line, Column: perr.Column}
+		}
+		if cerr := (*migrate.ConstraintError)(nil); errors.As(e.Error, &cerr) {
+			r.Constraint = &jsonErrorConstraint{
+				Kind: cerr.Kind, Table: cerr.Table, Name: cerr.Constraint, Columns: cerr.Columns,
+				Values: cerr.Values, File: cerr.File, Stmt: cerr.Stmt, Line: cerr.Line,
+			}
+		}
+	default:
+		return
+	}
+	// Logging errors are ignored, as in LogTTY.
+	_ = l.enc.Encode(r)
+}
@@ -0,0 +1,81 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogJSON(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		l   = NewLogJSON(&buf)
+	)
+	l.Log(migrate.LogExecution{From: "1", To: "2", Files: []string{"2_t.sql"}})
+	l.Log(migrate.LogFile{Version: "2", Desc: "t"})
+	l.Log(migrate.LogStmt{SQL: "SELECT 1 FORM t"})
+	l.Log(migrate.LogWarning{SQL: "SELECT 1 FORM t", Warning: migrate.Warning{Level: "notice", Code: "00000", Message: "unused"}})
+	l.Log(migrate.LogError{Error: &migrate.PosError{File: "2_t.sql", Stmt: 1, Offset: 9, Line: 1, Column: 10, Err: errors.New(`syntax error at or near "FORM"`)}})
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		require.NotEmpty(t, e["time"])
+		delete(e, "time")
+		entries = append(entries, e)
+	}
+	require.Equal(t, []map[string]any{
+		{"type": "execution", "from": "1", "to": "2", "files": []any{"2_t.sql"}},
+		{"type": "file", "version": "2", "desc": "t"},
+		{"type": "stmt", "sql": "SELECT 1 FORM t"},
+		{"type": "warning", "sql": "SELECT 1 FORM t", "warning": map[string]any{"level": "notice", "code": "00000", "message": "unused"}},
+		{
+			"type":     "error",
+			"error":    `syntax error at or near "FORM" [line 1, column 10 of "2_t.sql"]`,
+			"position": map[string]any{"file": "2_t.sql", "stmt": 1.0, "offset": 9.0, "line": 1.0, "column": 10.0},
+		},
+	}, entries)
+}
+
+func TestMigrate_ApplyLogJSON(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	t.Cleanup(func() { MigrateFlags.Apply.LogFormat = logFormatTTY })
+	p := t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c int NOT NULL);\nINSERT INTO t VALUES (NULL);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	db := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+
+	s, err := runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--log", "json")
+	require.Error(t, err)
+	// The error returned by the command is printed after the log entries.
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasPrefix(line, "{") {
+			lines = append(lines, line)
+		}
+	}
+	require.NotEmpty(t, lines)
+	var last struct {
+		Type       string
+		Constraint map[string]any
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &last))
+	require.Equal(t, "error", last.Type)
+	require.Equal(t, map[string]any{"kind": "not null", "table": "t", "columns": []any{"c"}, "file": "1_init.sql", "stmt": 2.0, "line": 2.0}, last.Constraint)
+}
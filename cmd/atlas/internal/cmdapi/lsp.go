@@ -0,0 +1,63 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"fmt"
+
+	"ariga.io/atlas/cmd/atlas/internal/lsp"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/spf13/cobra"
+)
+
+const lspFlagDialect = "dialect"
+
+var (
+	// LSPFlags are the flags used in LSPCmd command.
+	LSPFlags struct {
+		Dialect string // dialect of the schema files
+	}
+
+	// LSPCmd represents the 'atlas lsp' command.
+	LSPCmd = &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a language server for Atlas HCL schema files.",
+		Long: `'atlas lsp' runs a language server for Atlas HCL schema files over stdin and stdout, using the
+Language Server Protocol. It provides diagnostics, completion, hover information and go-to-definition
+of table, column and schema references to editors that support the protocol.
+
+The "--dialect" flag enables the completion of the column types of the given database, and the
+diagnostics reported by evaluating the schema files.`,
+		Example: `  atlas lsp
+  atlas lsp --dialect postgres`,
+		Args: cobra.NoArgs,
+		RunE: CmdLSPRun,
+	}
+)
+
+func init() {
+	Root.AddCommand(LSPCmd)
+	LSPCmd.Flags().StringVarP(&LSPFlags.Dialect, lspFlagDialect, "", "", "dialect of the schema files [mysql, postgres, sqlite]")
+}
+
+// CmdLSPRun is the command executed when running the CLI with 'lsp' args.
+func CmdLSPRun(cmd *cobra.Command, _ []string) error {
+	var opts []lsp.Option
+	switch d := LSPFlags.Dialect; d {
+	case "":
+	case mysql.DriverName:
+		opts = append(opts, lsp.WithEvaluator(mysql.EvalHCL), lsp.WithTypes(mysql.TypeRegistry.Specs()))
+	case postgres.DriverName:
+		opts = append(opts, lsp.WithEvaluator(postgres.EvalHCL), lsp.WithTypes(postgres.TypeRegistry.Specs()))
+	case sqlite.DriverName, "sqlite":
+		opts = append(opts, lsp.WithEvaluator(sqlite.EvalHCL), lsp.WithTypes(sqlite.TypeRegistry.Specs()))
+	default:
+		return fmt.Errorf("unknown dialect %q", d)
+	}
+	return lsp.NewServer(opts...).Serve(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout())
+}
@@ -6,6 +6,7 @@ package cmdapi
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io"
@@ -283,6 +284,410 @@ func TestMigrate_ApplyBaseline(t *testing.T) {
 	require.Contains(t, s, "Migrating to version 20220318104615 from 1 (2 migrations in total)")
 }
 
+func TestMigrate_ApplyMaxDuration(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	t.Cleanup(func() { MigrateFlags.Apply.MaxDuration = 0 })
+	db := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	_, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", db,
+		"--max-duration", "-1s",
+	)
+	require.EqualError(t, err, `invalid max-duration "-1s"`)
+
+	// The first file is always executed, and the budget
+	// is exhausted before starting the second one.
+	s, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", db,
+		"--max-duration", "1ns",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "-- migrating version 20220318104614")
+	require.NotContains(t, s, "-- migrating version 20220318104615")
+	require.Contains(t, s, "Max duration of 1ns was exceeded, 1 migration files are left pending (next version 20220318104615)")
+
+	// The next execution continues from the remaining files.
+	s, err = runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", db,
+		"--max-duration", "1h",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating to version 20220318104615 from 20220318104614 (1 migrations in total)")
+	require.NotContains(t, s, "Max duration")
+}
+
+func TestMigrate_ApplyDryRunHooks(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion = ""
+	t.Cleanup(func() {
+		MigrateFlags.Apply.OSC.Tool, MigrateFlags.Apply.DryRun = "", false
+		MigrateFlags.Apply.ExecHooks = nil
+	})
+	db := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	_, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", db,
+		"--osc", "gh-ost",
+	)
+	require.EqualError(t, err, `--osc is not supported by the "sqlite3" driver`)
+
+	// Hooks are not invoked in dry-runs, as they might execute the statements.
+	var (
+		marker = filepath.Join(t.TempDir(), "invoked")
+		script = filepath.Join(t.TempDir(), "hook.sh")
+	)
+	require.NoError(t, os.WriteFile(script, []byte("touch "+marker+"\n"), 0644))
+	s, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", db,
+		"--osc", "gh-ost",
+		"--exec-hook", "sh "+script,
+		"--dry-run",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "ALTER TABLE `tbl` ADD `col_2` bigint;")
+	require.NoFileExists(t, marker)
+}
+
+func TestMigrate_ApplyCanary(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	t.Cleanup(func() {
+		MigrateFlags.Apply.Canary.URL, MigrateFlags.Apply.Canary.Checks = "", nil
+	})
+	var (
+		p      = t.TempDir()
+		canary = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(p, "canary.db"))
+		target = func(name string) string {
+			return fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(p, name))
+		}
+		tables = func(name string) int {
+			db, err := sql.Open("sqlite3", filepath.Join(p, name))
+			require.NoError(t, err)
+			defer db.Close()
+			var n int
+			require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'tbl'").Scan(&n))
+			return n
+		}
+	)
+	_, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", target("t1.db"),
+		"--canary-check", "SELECT 1",
+	)
+	require.EqualError(t, err, "--canary-check requires --canary-url to be set")
+
+	// Canary is migrated from its start, and the target is migrated once the checks pass.
+	// Note, the values of array flags are accumulated between executions.
+	s, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", target("t1.db"),
+		"--canary-url", canary,
+		"--canary-check", "SELECT COUNT(*) = 2 FROM pragma_table_info('tbl')",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "Applying migration files on the canary database")
+	require.Contains(t, s, "Canary database was migrated successfully, applying migration files on the target database")
+	require.Equal(t, 2, strings.Count(s, "Migrating to version 20220318104615 (2 migrations in total)"))
+	require.Equal(t, 1, tables("t1.db"))
+
+	// A failing check stops the execution before the target is migrated.
+	s, err = runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", target("t2.db"),
+		"--canary-url", canary,
+		"--canary-check", "SELECT COUNT(*) > 0 FROM tbl",
+	)
+	require.EqualError(t, err, `canary: check "SELECT COUNT(*) > 0 FROM tbl" failed`)
+	require.Contains(t, s, "The canary database is already at version 20220318104615")
+	require.NotContains(t, s, "Migrating to version")
+	require.Zero(t, tables("t2.db"))
+}
+
+func TestMigrate_ApplyRehearse(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	t.Cleanup(func() {
+		MigrateFlags.Apply.Rehearse.Enabled, MigrateFlags.Apply.Rehearse.Brancher = false, ""
+	})
+	p := filepath.Join(t.TempDir(), "test.db")
+	u := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", p)
+	_, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", u,
+		"1",
+	)
+	require.NoError(t, err)
+
+	_, err = runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", u,
+		"--rehearse",
+		"--rehearse-branch", "unknown",
+	)
+	require.EqualError(t, err, `brancher "unknown" was not registered`)
+
+	var dropped bool
+	RegisterBrancher("test-rehearse", BrancherFunc(func(ctx context.Context, u *url.URL) (*Branch, error) {
+		b, err := vacuumBranch(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		drop := b.Drop
+		b.Drop = func(ctx context.Context) error {
+			dropped = true
+			return drop(ctx)
+		}
+		return b, nil
+	}))
+	s, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", u,
+		"--rehearse",
+		"--rehearse-branch", "test-rehearse",
+	)
+	require.NoError(t, err)
+	require.True(t, dropped)
+	require.Contains(t, s, "Rehearsing migration on branch")
+	require.Contains(t, s, "Migrating to version 20220318104615 from 20220318104614 (1 migrations in total)")
+	require.Contains(t, s, "Rehearsal report:")
+	require.Contains(t, s, "20220318104615_second.sql: ")
+	require.NotContains(t, s, "20220318104614_initial.sql: ")
+
+	// The target database was not migrated.
+	db, err := sql.Open("sqlite3", p)
+	require.NoError(t, err)
+	defer db.Close()
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('tbl')").Scan(&n))
+	require.Equal(t, 1, n)
+}
+
+func TestMigrate_StatusVerbose(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	t.Cleanup(func() { MigrateFlags.Status.Verbose = false })
+	u := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	_, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://testdata/sqlite",
+		"--url", u,
+	)
+	require.NoError(t, err)
+
+	s, err := runCmd(
+		Root, "migrate", "status",
+		"--dir", "file://testdata/sqlite",
+		"--url", u,
+	)
+	require.NoError(t, err)
+	require.NotContains(t, s, "Executed Revisions:")
+
+	s, err = runCmd(
+		Root, "migrate", "status",
+		"--dir", "file://testdata/sqlite",
+		"--url", u,
+		"--verbose",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "Executed Revisions:")
+	require.Contains(t, s, "20220318104614: ")
+	require.Contains(t, s, "statement 1: ")
+	require.Contains(t, s, "rows affected (CREATE TABLE tbl (`col` int NOT NULL);)")
+	require.Contains(t, s, "rows affected (ALTER TABLE `tbl` ADD `col_2` bigint;)")
+}
+
+func TestMigrate_ApplyTemplateVars(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	t.Cleanup(func() { GlobalFlags.Vars, MigrateFlags.DevURL, MigrateFlags.ToURLs = nil, "", nil })
+	p := t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("-- atlas:template\nCREATE TABLE t (c int DEFAULT {{ var \"default\" }});\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	db := filepath.Join(t.TempDir(), "test.db")
+
+	// Undefined variables fail the execution of files that are marked
+	// as templates, also when no variables were given.
+	_, err = runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://"+p,
+		"--url", fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", db),
+	)
+	require.ErrorContains(t, err, `variable "default" is not defined`)
+	_, err = runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://"+p,
+		"--url", fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", db),
+		"--var", "other=1",
+	)
+	require.ErrorContains(t, err, `variable "default" is not defined`)
+
+	s, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://"+p,
+		"--url", fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", db),
+		"--var", "default=10",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "CREATE TABLE t (c int DEFAULT 10);")
+
+	// Statements are rendered when they are replayed on the dev database.
+	GlobalFlags.Vars = make(map[string]string)
+	_, err = runCmd(Root, "migrate", "validate", "--dir", "file://"+p, "--dev-url", openSQLite(t, ""))
+	require.ErrorContains(t, err, `variable "default" is not defined`)
+	_, err = runCmd(Root, "migrate", "validate", "--dir", "file://"+p, "--dev-url", openSQLite(t, ""), "--var", "default=10")
+	require.NoError(t, err)
+	s, err = runCmd(Root, "migrate", "validate-file", "1_init.sql", "--dir", "file://"+p, "--dev-url", openSQLite(t, ""), "--var", "default=10")
+	require.NoError(t, err)
+	require.Contains(t, s, `Migration file "1_init.sql" is valid`)
+	to := filepath.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(to, []byte("CREATE TABLE t (c int DEFAULT 10);"), 0644))
+	s, err = runCmd(Root, "migrate", "diff", "--dir", "file://"+p, "--dev-url", openSQLite(t, ""), "--to", "file://"+to, "--var", "default=10")
+	require.NoError(t, err)
+	require.Contains(t, s, "The migration directory is synced with the desired state")
+
+	// Without variables, literals that look like template actions are executed as is.
+	GlobalFlags.Vars = nil
+	p = t.TempDir()
+	d, err = migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c text);\nINSERT INTO t VALUES ('{{1,2},{3,4}}');\n")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	s, err = runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://"+p,
+		"--url", fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db")),
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "INSERT INTO t VALUES ('{{1,2},{3,4}}');")
+	_, err = runCmd(Root, "migrate", "validate", "--dir", "file://"+p, "--dev-url", openSQLite(t, ""))
+	require.NoError(t, err)
+}
+
+func TestMigrate_ApplyConditional(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	p := t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte(`CREATE TABLE t (c int);
+-- atlas:dialect mysql
+ALTER TABLE t ADD COLUMN d int INVISIBLE;
+-- atlas:dialect sqlite>=3
+CREATE INDEX i ON t (c);
+`)))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	s, err := runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://"+p,
+		"--url", fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db")),
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "CREATE INDEX i ON t (c);")
+	require.NotContains(t, s, "INVISIBLE")
+	require.Contains(t, s, "2 sql statements")
+}
+
+func TestMigrate_ApplyPhase(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	t.Cleanup(func() { MigrateFlags.Apply.Phase = "" })
+	p := t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c int);\n")))
+	require.NoError(t, d.WriteFile("2_expand.sql", []byte("-- atlas:phase expand\nALTER TABLE t ADD COLUMN d int;\n")))
+	require.NoError(t, d.WriteFile("3_contract.sql", []byte("-- atlas:phase contract\nALTER TABLE t DROP COLUMN c;\n")))
+	require.NoError(t, d.WriteFile("4_expand.sql", []byte("-- atlas:phase expand\nCREATE INDEX i ON t (d);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	db := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+
+	_, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--phase", "migrate")
+	require.EqualError(t, err, `sql/migrate: unknown phase "migrate", expect "expand" or "contract"`)
+
+	// Untagged files are executed in both phases, and the execution
+	// stops at the first file of the other phase.
+	s, err := runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--phase", "expand")
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating to version 2 (2 migrations in total)")
+
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--phase", "expand")
+	require.NoError(t, err)
+	require.Contains(t, s, "No pending migration files of the expand phase to execute (next version 3 is not part of it)")
+
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--phase", "contract")
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating to version 3 from 2 (1 migrations in total)")
+}
+
+func TestMigrate_ApplyTxModeNone(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	t.Cleanup(func() { MigrateFlags.Apply.TxMode = txModeFile })
+	p := t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c int);\n")))
+	// VACUUM cannot be executed inside a transaction.
+	require.NoError(t, d.WriteFile("2_vacuum.sql", []byte("-- atlas:txmode none\nVACUUM;\n")))
+	require.NoError(t, d.WriteFile("3_index.sql", []byte("CREATE INDEX i ON t (c);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	db := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+
+	_, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--tx-mode", txModeAll)
+	require.EqualError(t, err, `migration file "2_vacuum.sql" cannot be executed in a transaction (atlas:txmode none). Use --tx-mode file or none`)
+
+	s, err := runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--tx-mode", txModeFile)
+	require.NoError(t, err)
+	require.Contains(t, s, "3 migrations")
+	c, err := sqlclient.Open(context.Background(), db)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, c.Close()) })
+	rrw, err := migrate2.NewEntRevisions(context.Background(), c)
+	require.NoError(t, err)
+	revs, err := rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+}
+
+func TestMigrate_ApplyConstraintError(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	p := t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c int);\nINSERT INTO t VALUES (1), (1);\n\n-- Add a unique index.\nCREATE UNIQUE INDEX i ON t (c);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	db := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+
+	s, err := runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db)
+	var cerr *migrate.ConstraintError
+	require.ErrorAs(t, err, &cerr)
+	require.Equal(t, "unique", cerr.Kind)
+	require.Equal(t, "t", cerr.Table)
+	require.Equal(t, []string{"c"}, cerr.Columns)
+	require.Contains(t, s, `UNIQUE constraint failed: t.c [unique constraint, table "t", columns (c), statement 3 (line 5) of "1_init.sql"]`)
+}
+
 func TestMigrate_Diff(t *testing.T) {
 	p := t.TempDir()
 	to := hclURL(t)
@@ -324,6 +729,145 @@ func TestMigrate_Diff(t *testing.T) {
 	require.FileExists(t, filepath.Join(p, fmt.Sprintf("%s_name.sql", time.Now().UTC().Format("20060102150405"))))
 	require.FileExists(t, filepath.Join(p, "atlas.sum"))
 
+	// Split the plan into multiple files.
+	p = t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE `old` (`c` int NOT NULL);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"name",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", to,
+		"--split", "safety",
+	)
+	require.NoError(t, err)
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, "name", files[1].Desc())
+	require.Contains(t, string(files[1].Bytes()), "CREATE TABLE `table`")
+	require.Equal(t, "name_destructive", files[2].Desc())
+	require.Contains(t, string(files[2].Bytes()), "DROP TABLE `old`")
+	require.NoError(t, migrate.Validate(d))
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", to,
+		"--split", "unknown",
+	)
+	require.EqualError(t, err, `unknown split policy "unknown"`)
+	MigrateFlags.Diff.Split = "" // global flags are set from other tests ...
+
+	// Write the reverse of the plan to a down file.
+	p = t.TempDir()
+	d, err = migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE `old` (`c` int NOT NULL);\n")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"name",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", to,
+		"--format", "down",
+	)
+	require.NoError(t, err)
+	files, err = d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	down, err := os.ReadFile(filepath.Join(p, strings.TrimSuffix(files[1].Name(), ".sql")+".down.sql"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(down), "-- atlas:reverse "+files[1].Name()+"\n"))
+	require.Contains(t, string(down), "DROP TABLE `table`")
+	require.Contains(t, string(down), "CREATE TABLE `old`")
+	require.Contains(t, string(down), `irreversible: the data of the dropped table "old" is not restored`)
+	require.NoError(t, migrate.Validate(d))
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+t.TempDir(),
+		"--dev-url", openSQLite(t, ""),
+		"--to", to,
+		"--format", "down",
+		"--split", "safety",
+	)
+	require.EqualError(t, err, "flag --format down cannot be used with --split")
+	MigrateFlags.Diff.Split = ""
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+t.TempDir(),
+		"--dev-url", openSQLite(t, ""),
+		"--to", to,
+		"--format", "sideways",
+	)
+	require.EqualError(t, err, `unknown --format "sideways", expect "up" or "down"`)
+	MigrateFlags.Diff.Format = diffFormatUp
+
+	// Plan the changes for a declared database version.
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+t.TempDir(),
+		"--dev-url", openSQLite(t, ""),
+		"--to", to,
+		"--target-version", "three",
+	)
+	require.EqualError(t, err, "sqlite: malformed target version: three")
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+t.TempDir(),
+		"--dev-url", openSQLite(t, ""),
+		"--to", to,
+		"--target-version", "3.31.0",
+	)
+	require.NoError(t, err)
+	MigrateFlags.Diff.Version = ""
+
+	// Write a report next to the migration file.
+	p = t.TempDir()
+	d, err = migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE `old` (`c` int NOT NULL);\n")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"name",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", to,
+		"--report", "md",
+	)
+	require.NoError(t, err)
+	files, err = d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	report, err := os.ReadFile(filepath.Join(p, strings.TrimSuffix(files[1].Name(), ".sql")+".md"))
+	require.NoError(t, err)
+	require.Contains(t, string(report), "# Migration report: `"+files[1].Name()+"`")
+	require.Contains(t, string(report), "**Risk:** high")
+	require.Contains(t, string(report), "| 2 | 4 | `` DROP TABLE `old`; `` | brief metadata lock |")
+	require.Contains(t, string(report), "| 4 | DS102 | Dropping table \"old\" |")
+	// Reports are not part of the directory integrity.
+	require.NoError(t, migrate.Validate(d))
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+t.TempDir(),
+		"--dev-url", openSQLite(t, ""),
+		"--to", to,
+		"--report", "html",
+	)
+	require.EqualError(t, err, `unknown report format "html", expect "md"`)
+	MigrateFlags.Diff.Report = ""
+
 	// A lock will prevent diffing.
 	sqlclient.Register("sqlitelockdiff", sqlclient.OpenerFunc(func(ctx context.Context, u *url.URL) (*sqlclient.Client, error) {
 		client, err := sqlclient.Open(ctx, strings.Replace(u.String(), u.Scheme, "sqlite", 1))
@@ -412,6 +956,92 @@ func TestMigrate_New(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestMigrate_NewVersionScheme(t *testing.T) {
+	t.Cleanup(func() {
+		// Reset global flags for other tests.
+		MigrateFlags.Version.Scheme, MigrateFlags.Version.Prefix, MigrateFlags.Version.Width = "", "", 0
+	})
+	p := t.TempDir()
+	for i := 0; i < 2; i++ {
+		s, err := runCmd(Root, "migrate", "new", "add", "--dir", "file://"+p, "--dir-format", formatAtlas, "--version-scheme", "sequence", "--version-prefix", "billing-", "--version-width", "3")
+		require.Zero(t, s)
+		require.NoError(t, err)
+	}
+	require.FileExists(t, filepath.Join(p, "billing-001_add.sql"))
+	require.FileExists(t, filepath.Join(p, "billing-002_add.sql"))
+	require.Equal(t, 3, countFiles(t, p))
+	MigrateFlags.DevURL = "" // global flags are set from other tests ...
+	s, err := runCmd(Root, "migrate", "validate", "--dir", "file://"+p)
+	require.Zero(t, s)
+	require.NoError(t, err)
+
+	// Files not matching the scheme are rejected.
+	require.NoError(t, os.WriteFile(filepath.Join(p, "1_init.sql"), nil, 0600))
+	_, err = runCmd(Root, "migrate", "hash", "--dir", "file://"+p)
+	require.EqualError(t, err, `sql/migrate: file "1_init.sql" does not match the version scheme`)
+
+	_, err = runCmd(Root, "migrate", "new", "--dir", "file://"+p, "--version-scheme", "sequence", "--dir-format", formatGoose)
+	require.EqualError(t, err, `flag --version-scheme is not supported by the "goose" dir format`)
+	MigrateFlags.DirFormat = formatAtlas
+	_, err = runCmd(Root, "migrate", "new", "--dir", "file://"+p, "--version-scheme", "unknown")
+	require.EqualError(t, err, `unknown version scheme "unknown"`)
+}
+
+func TestMigrate_ApplySequenceVersions(t *testing.T) {
+	t.Cleanup(func() {
+		// Reset global flags for other tests.
+		MigrateFlags.Version.Scheme, MigrateFlags.DevURL = "", ""
+		DownFlags.DryRun = false
+	})
+	MigrateFlags.DirFormat = formatAtlas
+	MigrateFlags.Apply.DryRun, MigrateFlags.Apply.BaselineVersion = false, ""
+	p := t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	for i := 1; i <= 11; i++ {
+		require.NoError(t, d.WriteFile(fmt.Sprintf("%d_t%d.sql", i, i), []byte(fmt.Sprintf("CREATE TABLE t%d (c int);\n", i))))
+	}
+	// Unpadded versions are ordered by their numeric value.
+	sum, err := migrate.NewSchemeDir(d, &migrate.SequenceScheme{}).Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	u := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	s, err := runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", u, "--version-scheme", "sequence")
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating to version 11 (11 migrations in total)")
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", u, "--version-scheme", "sequence")
+	require.NoError(t, err)
+	require.Equal(t, "The migration directory is synced with the database, no migration files to execute\n", s)
+	s, err = runCmd(Root, "migrate", "down", "--dir", "file://"+p, "--url", u, "--version-scheme", "sequence", "--dev-url", openSQLite(t, ""), "--dry-run")
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating down from version 11 to version 10 (1 migration files)")
+	require.Contains(t, s, "DROP TABLE `t11`;")
+}
+
+func TestMigrate_NewMultiDirVersionScheme(t *testing.T) {
+	t.Cleanup(func() {
+		// Reset global flags for other tests.
+		MigrateFlags.Version.Scheme, MigrateFlags.DirURL = "", ""
+	})
+	base, addon := t.TempDir(), t.TempDir()
+	d, err := migrate.NewLocalDir(addon)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("9_addon.sql", []byte("CREATE TABLE t(c int);")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	// New files are versioned after the files of all composed directories.
+	for i := 0; i < 2; i++ {
+		_, err := runCmd(Root, "migrate", "new", "add", "--dir", "file://"+base+",file://"+addon, "--dir-format", formatAtlas, "--version-scheme", "sequence")
+		require.NoError(t, err)
+	}
+	require.FileExists(t, filepath.Join(base, "10_add.sql"))
+	require.FileExists(t, filepath.Join(base, "11_add.sql"))
+	s, err := runCmd(Root, "migrate", "validate", "--dir", "file://"+base+",file://"+addon)
+	require.Zero(t, s)
+	require.NoError(t, err)
+}
+
 func TestMigrate_Validate(t *testing.T) {
 	// Without re-playing.
 	MigrateFlags.DevURL = ""         // global flags are set from other tests ...
@@ -447,6 +1077,44 @@ func TestMigrate_Validate(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestMigrate_ValidateFile(t *testing.T) {
+	MigrateFlags.DirFormat = "atlas" // global flags are set from other tests ...
+	p := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(p, "1_initial.sql"), []byte("create table t1 (c1 int);\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(p, "2_second.sql"), []byte("create table t2 (c2 int);\n-- comment\n  alter table t1 add column c2 int;\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(p, "3_third.sql"), []byte("create table t3 (c3 int);\ninsert into t4 values (1);\n"), 0644))
+	_, err := runCmd(Root, "migrate", "hash", "--dir", "file://"+p, "--force")
+	require.NoError(t, err)
+	dev := openSQLite(t, "")
+	s, err := runCmd(Root, "migrate", "validate-file", "2_second.sql", "--dir", "file://"+p, "--dev-url", dev)
+	require.NoError(t, err)
+	require.Equal(t, "Migration file \"2_second.sql\" is valid (2 statements executed)\n", s)
+
+	// Files can be referenced by their version.
+	_, err = runCmd(Root, "migrate", "validate-file", "3", "--dir", "file://"+p, "--dev-url", dev)
+	require.EqualError(t, err, `3_third.sql:2:1: executing statement "insert into t4 values (1);": no such table: t4`)
+
+	// All failing statements are reported.
+	p2 := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(p2, "1_initial.sql"), []byte("insert into t5 values (1);\ncreate table t4 (c4 int);\ninsert into t4 values (1);\n  insert into t6 values (1);\n"), 0644))
+	_, err = runCmd(Root, "migrate", "hash", "--dir", "file://"+p2, "--force")
+	require.NoError(t, err)
+	_, err = runCmd(Root, "migrate", "validate-file", "1", "--dir", "file://"+p2, "--dev-url", dev)
+	require.EqualError(t, err, `1_initial.sql:1:1: executing statement "insert into t5 values (1);": no such table: t5
+1_initial.sql:4:3: executing statement "insert into t6 values (1);": no such table: t6`)
+
+	// The dev database is restored.
+	c, err := sqlclient.Open(context.Background(), dev)
+	require.NoError(t, err)
+	defer c.Close()
+	var n int
+	require.NoError(t, c.DB.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&n))
+	require.Zero(t, n)
+
+	_, err = runCmd(Root, "migrate", "validate-file", "4_unknown.sql", "--dir", "file://"+p, "--dev-url", dev)
+	require.EqualError(t, err, `migration file "4_unknown.sql" was not found in the migration directory`)
+}
+
 func TestMigrate_Hash(t *testing.T) {
 	s, err := runCmd(Root, "migrate", "hash", "--dir", "file://testdata/mysql")
 	require.Zero(t, s)
@@ -622,6 +1290,61 @@ table "accounts" {
   }
 }`
 
+func TestMigrate_State(t *testing.T) {
+	MigrateFlags.DirFormat = formatAtlas
+	t.Cleanup(func() { StateFlags.File, MigrateFlags.Lint.State = "", "" })
+	p := t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1.sql", []byte("CREATE TABLE t(c int);")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	// Dump to the standard output.
+	s, err := runCmd(Root, "migrate", "state", "dump", "--dir", "file://"+p, "--dev-url", openSQLite(t, ""))
+	require.NoError(t, err)
+	require.Contains(t, s, `table \"t\"`)
+	state := filepath.Join(t.TempDir(), "atlas.state")
+	s, err = runCmd(Root, "migrate", "state", "dump", "--dir", "file://"+p, "--dev-url", openSQLite(t, ""), "--file", state)
+	require.NoError(t, err)
+	require.Empty(t, s)
+
+	// Restore the state on a clean database.
+	db := filepath.Join(t.TempDir(), "dev.db")
+	_, err = runCmd(Root, "migrate", "state", "restore", "--dev-url", fmt.Sprintf("sqlite://%s", db), "--file", state)
+	require.NoError(t, err)
+	s, err = runCmd(Root, "schema", "inspect", "-u", fmt.Sprintf("sqlite://%s", db))
+	require.NoError(t, err)
+	require.Contains(t, s, `table "t"`)
+
+	// Lint the new file on top of the cached state.
+	require.NoError(t, d.WriteFile("2.sql", []byte("DROP TABLE t;")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	s, err = runCmd(
+		Root, "migrate", "lint",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--latest", "1",
+		"--state-file", state,
+	)
+	require.Error(t, err)
+	require.Equal(t, "2.sql: destructive changes detected:\n\n\tL1: Dropping table \"t\"\n\n", s)
+
+	// A missing state file replays all files.
+	s, err = runCmd(
+		Root, "migrate", "lint",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--latest", "1",
+		"--state-file", filepath.Join(t.TempDir(), "missing.state"),
+	)
+	require.Error(t, err)
+	require.Equal(t, "2.sql: destructive changes detected:\n\n\tL1: Dropping table \"t\"\n\n", s)
+}
+
 func hclURL(t *testing.T) string {
 	p := t.TempDir()
 	require.NoError(t, os.WriteFile(filepath.Join(p, "schema.hcl"), []byte(testSchema), 0600))
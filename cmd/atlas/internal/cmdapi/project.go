@@ -10,6 +10,7 @@ import (
 	"os"
 
 	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/schema"
 )
 
 const projectFileName = "atlas.hcl"
@@ -31,8 +32,13 @@ func WithInput(vals map[string]string) LoadOption {
 type (
 	// Project represents an atlas.hcl project file.
 	Project struct {
-		Envs []*Env `spec:"env"`  // List of environments
-		Lint *Lint  `spec:"lint"` // Optional global lint config
+		Envs   []*Env   `spec:"env"`   // List of environments
+		Lint   *Lint    `spec:"lint"`  // Optional global lint config
+		Diff   *Diff    `spec:"diff"`  // Optional global diff policy
+		Scopes []*Scope `spec:"scope"` // Optional ownership scopes
+
+		// Optional configuration of the project in a workspace.
+		Workspace *Workspace `spec:"workspace"`
 	}
 
 	// Env represents an Atlas environment.
@@ -54,19 +60,88 @@ type (
 		// resources on inspection.
 		Exclude []string `spec:"exclude"`
 
+		// Session holds the statements executed on every connection
+		// opened to the databases of the environment, for example,
+		// "SET ROLE migrator" or "SET search_path TO app".
+		Session []string `spec:"session"`
+
+		// AuditLog is the URL of the audit log that records the statements
+		// executed on the database of the environment (not the dev database).
+		AuditLog string `spec:"audit_log"`
+
+		// Replicas are the URLs of the read replicas of the database, on which
+		// the applied changes are verified to be visible before completing.
+		Replicas []string `spec:"replicas"`
+
 		// Migration containing the migration configuration of the env.
 		Migration *Migration `spec:"migration"`
 
 		// Lint of the environment.
 		Lint *Lint `spec:"lint"`
+
+		// Diff policy of the environment.
+		Diff *Diff `spec:"diff"`
+
+		// CDC holds the tables captured by change data capture pipelines.
+		CDC *CDC `spec:"cdc"`
+
+		// Scope is the name of the ownership scope of the environment. If set,
+		// diffs, lints and applies refuse to touch objects outside the scope.
+		Scope string `spec:"scope"`
+
+		// Fixtures hold the rows seeded into the database of the environment
+		// after its schema is applied, for example, a local dev database.
+		Fixtures []*Fixture `spec:"fixture"`
+
+		// scopes holds all the scopes defined in the project file.
+		scopes []*Scope
 		schemahcl.DefaultExtension
 	}
 
+	// Scope represents an ownership scope of a monorepo. For example:
+	//
+	//	scope "billing" {
+	//	  tables = ["billing_*", "public.invoices"]
+	//	}
+	Scope struct {
+		Name    string   `spec:"name,name"`
+		Schemas []string `spec:"schemas"`
+		Tables  []string `spec:"tables"`
+	}
+
+	// Workspace represents the configuration of a project in a workspace
+	// with multiple projects (e.g. a monorepo). For example:
+	//
+	//	workspace {
+	//	  depends_on = ["../shared"]
+	//	}
+	Workspace struct {
+		// DependsOn holds the paths of the projects, relative to the project
+		// directory, that must be applied before this project. For example,
+		// a project that owns a schema shared with this project.
+		DependsOn []string `spec:"depends_on"`
+	}
+
 	// Migration represents the migration directory for the Env.
 	Migration struct {
-		Dir             string `spec:"dir"`
-		Format          string `spec:"format"`
-		RevisionsSchema string `spec:"revisions_schema"`
+		Dir string `spec:"dir"`
+		// Dirs holds a list of migration directories to compose
+		// with Dir (if set), e.g. migrations shipped by plugins.
+		Dirs            []string `spec:"dirs"`
+		Format          string   `spec:"format"`
+		RevisionsSchema string   `spec:"revisions_schema"`
+		// Version configures the versioning of migration files.
+		Version *MigrationVersion `spec:"version"`
+	}
+
+	// MigrationVersion represents the versioning of migration files.
+	MigrationVersion struct {
+		// Scheme configures the --version-scheme option.
+		Scheme string `spec:"scheme"`
+		// Prefix configures the --version-prefix option.
+		Prefix string `spec:"prefix"`
+		// Width configures the --version-width option.
+		Width int `spec:"width"`
 	}
 
 	// Lint represents the configuration of migration linting.
@@ -83,6 +158,99 @@ type (
 		} `spec:"git"`
 		schemahcl.DefaultExtension
 	}
+
+	// Diff represents the policy of the schema diffing process,
+	// honored by the 'migrate diff' and 'schema apply' commands.
+	Diff struct {
+		// Skip configures the changes to remove from the computed changes.
+		Skip *SkipChanges `spec:"skip"`
+		// Deny configures the changes that fail the diffing process.
+		Deny *DenyChanges `spec:"deny"`
+		// Unmanaged configures the protection of unmanaged objects.
+		Unmanaged *Unmanaged `spec:"unmanaged"`
+		// IdentCase configures how identifiers are compared.
+		IdentCase *IdentCase `spec:"ident_case"`
+	}
+
+	// CDC represents the tables that are captured by change data capture
+	// pipelines (e.g. Debezium). Changes that break the pipelines of these
+	// tables are reported by 'schema apply', and must be acknowledged.
+	//
+	//	cdc {
+	//	  tables = ["public.users", "orders_*"]
+	//	}
+	CDC struct {
+		Tables []string `spec:"tables"`
+	}
+
+	// Fixture represents the rows seeded into a table, read from a CSV
+	// or a SQL file, or defined inline using row blocks. For example:
+	//
+	//	fixture "users" {
+	//	  src      = "fixtures/users.csv"
+	//	  truncate = "delete"
+	//	}
+	//
+	//	fixture "public.posts" {
+	//	  row {
+	//	    id        = 1
+	//	    author_id = 1
+	//	    title     = "Hello"
+	//	  }
+	//	}
+	Fixture struct {
+		// Name of the seeded table, optionally qualified with its schema.
+		Name string `spec:"name,name"`
+		// Src is the path to a CSV file (with a header row) or a SQL file.
+		Src string `spec:"src"`
+		// Truncate is the policy for the existing rows of the table.
+		Truncate string        `spec:"truncate"`
+		Rows     []*FixtureRow `spec:"row"`
+	}
+
+	// FixtureRow holds the column values of an inline fixture row.
+	FixtureRow struct {
+		schemahcl.DefaultExtension
+	}
+
+	// SkipChanges represents the skip block of the diff policy.
+	SkipChanges struct {
+		DropSchema     bool `spec:"drop_schema"`
+		DropTable      bool `spec:"drop_table"`
+		DropColumn     bool `spec:"drop_column"`
+		DropIndex      bool `spec:"drop_index"`
+		DropForeignKey bool `spec:"drop_foreign_key"`
+	}
+
+	// DenyChanges represents the deny block of the diff policy.
+	DenyChanges struct {
+		ColumnTypeChange bool `spec:"column_type_change"`
+	}
+
+	// Unmanaged represents the protection of objects that exist in the
+	// database, but are absent from the desired state. Instead of being
+	// dropped, they are reported ("warn" mode), or fail the command
+	// ("error" mode), unless they match one of the allow patterns.
+	Unmanaged struct {
+		Mode  string   `spec:"mode"`
+		Allow []string `spec:"allow"`
+	}
+
+	// IdentCase represents how the differ compares the identifiers of schemas, tables
+	// and columns. Each one is either "sensitive", "insensitive" or "lower", and when the
+	// block is absent, they are resolved from the connected database. For example, on
+	// MySQL servers that run with lower_case_table_names=1:
+	//
+	//	ident_case {
+	//	  schemas = "lower"
+	//	  tables  = "lower"
+	//	  columns = "insensitive"
+	//	}
+	IdentCase struct {
+		Schemas string `spec:"schemas"`
+		Tables  string `spec:"tables"`
+		Columns string `spec:"columns"`
+	}
 )
 
 // Extend allows extending environment blocks with
@@ -136,6 +304,129 @@ func (l *Lint) Extend(global *Lint) *Lint {
 	return l
 }
 
+// Extend allows extending the diff policy of an environment with
+// a global one. Policies are additive, and changes that are skipped
+// or denied by the global policy cannot be allowed by the environment.
+//
+//	diff {
+//	  skip {
+//	    drop_schema = true
+//	  }
+//	}
+//
+//	env "prod" {
+//	  ...
+//	  diff {
+//	    skip {
+//	      drop_table = true
+//	    }
+//	    deny {
+//	      column_type_change = true
+//	    }
+//	  }
+//	}
+func (d *Diff) Extend(global *Diff) *Diff {
+	if d == nil {
+		return global
+	}
+	if global == nil {
+		return d
+	}
+	if s := global.Skip; s != nil {
+		if d.Skip == nil {
+			d.Skip = &SkipChanges{}
+		}
+		d.Skip.DropSchema = d.Skip.DropSchema || s.DropSchema
+		d.Skip.DropTable = d.Skip.DropTable || s.DropTable
+		d.Skip.DropColumn = d.Skip.DropColumn || s.DropColumn
+		d.Skip.DropIndex = d.Skip.DropIndex || s.DropIndex
+		d.Skip.DropForeignKey = d.Skip.DropForeignKey || s.DropForeignKey
+	}
+	if global.Deny != nil {
+		if d.Deny == nil {
+			d.Deny = &DenyChanges{}
+		}
+		d.Deny.ColumnTypeChange = d.Deny.ColumnTypeChange || global.Deny.ColumnTypeChange
+	}
+	if u := global.Unmanaged; u != nil {
+		if d.Unmanaged == nil {
+			d.Unmanaged = &Unmanaged{}
+		}
+		if d.Unmanaged.Mode == "" {
+			d.Unmanaged.Mode = u.Mode
+		}
+		d.Unmanaged.Allow = append(d.Unmanaged.Allow, u.Allow...)
+	}
+	return d
+}
+
+// Policy returns the schema.DiffPolicy defined by the diff block.
+func (d *Diff) Policy() schema.DiffPolicy {
+	var p schema.DiffPolicy
+	if s := d.Skip; s != nil {
+		p.SkipDropSchema, p.SkipDropTable, p.SkipDropColumn = s.DropSchema, s.DropTable, s.DropColumn
+		p.SkipDropIndex, p.SkipDropForeignKey = s.DropIndex, s.DropForeignKey
+	}
+	if d.Deny != nil {
+		p.DenyColumnTypeChange = d.Deny.ColumnTypeChange
+	}
+	return p
+}
+
+// Options returns the schema.IdentCaseOptions of the identifier case block.
+func (c *IdentCase) Options() (*schema.IdentCaseOptions, error) {
+	var (
+		err  error
+		opts = &schema.IdentCaseOptions{}
+	)
+	if opts.Schemas, err = schema.ParseIdentCase(c.Schemas); err != nil {
+		return nil, err
+	}
+	if opts.Tables, err = schema.ParseIdentCase(c.Tables); err != nil {
+		return nil, err
+	}
+	if opts.Columns, err = schema.ParseIdentCase(c.Columns); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// DirURLs returns the URLs of the migration directories configured for the env.
+// The directory set in "dir" comes first, followed by the ones set in "dirs".
+func (m *Migration) DirURLs() []string {
+	var urls []string
+	if m.Dir != "" {
+		urls = append(urls, m.Dir)
+	}
+	return append(urls, m.Dirs...)
+}
+
+// OwnershipScope returns the ownership scope of the environment, if set.
+func (e *Env) OwnershipScope() (*schema.Scope, bool) {
+	if e.Scope == "" {
+		return nil, false
+	}
+	for _, s := range e.scopes {
+		if s.Name == e.Scope {
+			return s.schemaScope(), true
+		}
+	}
+	return nil, false
+}
+
+// OwnershipScopes returns all ownership scopes defined in the project file.
+func (e *Env) OwnershipScopes() []*schema.Scope {
+	scopes := make([]*schema.Scope, len(e.scopes))
+	for i, s := range e.scopes {
+		scopes[i] = s.schemaScope()
+	}
+	return scopes
+}
+
+func (s *Scope) schemaScope() *schema.Scope {
+	return &schema.Scope{Name: s.Name, Schemas: s.Schemas, Tables: s.Tables}
+}
+
 // Sources returns the paths containing the Atlas schema.
 func (e *Env) Sources() ([]string, error) {
 	attr, exists := e.Attr("src")
@@ -172,6 +463,7 @@ func (e *Env) asMap() (map[string]string, error) {
 
 var hclState = schemahcl.New(
 	schemahcl.WithScopedEnums("env.migration.format", formatAtlas, formatFlyway, formatLiquibase, formatGoose, formatGolangMigrate),
+	schemahcl.WithFunctions(runtimeFuncs()),
 )
 
 // LoadEnv reads the project file in path, and loads the environment
@@ -206,10 +498,25 @@ func LoadEnv(path string, name string, opts ...LoadOption) (*Env, error) {
 	if !ok {
 		return nil, fmt.Errorf("env %q not defined in project file", name)
 	}
+	scopes := make(map[string]bool, len(project.Scopes))
+	for _, s := range project.Scopes {
+		if scopes[s.Name] {
+			return nil, fmt.Errorf("duplicate scope name %q", s.Name)
+		}
+		if err := s.schemaScope().Validate(); err != nil {
+			return nil, err
+		}
+		scopes[s.Name] = true
+	}
+	if selected.Scope != "" && !scopes[selected.Scope] {
+		return nil, fmt.Errorf("scope %q not defined in project file", selected.Scope)
+	}
+	selected.scopes = project.Scopes
 	if selected.Migration == nil {
 		selected.Migration = &Migration{}
 	}
 	selected.Lint = selected.Lint.Extend(project.Lint)
+	selected.Diff = selected.Diff.Extend(project.Diff)
 	return selected, nil
 }
 
@@ -0,0 +1,122 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"ariga.io/atlas/cmd/atlas/internal/oci"
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/spf13/cobra"
+)
+
+// Annotations of pushed migration directories.
+const (
+	annotationVersion      = "io.atlasgo.migrate.version"
+	annotationAtlasVersion = "io.atlasgo.version"
+)
+
+// CmdMigratePushRun is the command executed when running the CLI with 'migrate push' args.
+func CmdMigratePushRun(cmd *cobra.Command, args []string) error {
+	ref, err := oci.ParseReference(args[0])
+	if err != nil {
+		return err
+	}
+	dir, err := dir(false)
+	if err != nil {
+		return err
+	}
+	files, err := dir.Files()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("no migration files to push")
+	}
+	sum, err := readFile(dir, migrate.HashFileName)
+	if err != nil {
+		return err
+	}
+	v, _ := parse(version)
+	a := &oci.Artifact{
+		Annotations: map[string]string{
+			oci.AnnotationCreated:  time.Now().UTC().Format(time.RFC3339),
+			annotationVersion:      files[len(files)-1].Version(),
+			annotationAtlasVersion: v,
+		},
+	}
+	for _, f := range files {
+		a.Files = append(a.Files, &oci.File{Name: f.Name(), Data: f.Bytes()})
+	}
+	a.Files = append(a.Files, &oci.File{Name: migrate.HashFileName, Data: sum})
+	// Tagged states are distributed along with the directory.
+	tags, err := readTags(dir)
+	if err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		a.Files = append(a.Files, &oci.File{Name: tagsFileName, Data: formatTags(tags)})
+	}
+	d, err := (&oci.Client{}).Push(cmd.Context(), ref, a)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("Pushed %d migration files to %s (digest: %s)\n", len(files), ref, d)
+	return nil
+}
+
+// CmdMigratePullRun is the command executed when running the CLI with 'migrate pull' args.
+func CmdMigratePullRun(cmd *cobra.Command, args []string) error {
+	ref, err := oci.ParseReference(args[0])
+	if err != nil {
+		return err
+	}
+	dir, err := dir(true)
+	if err != nil {
+		return err
+	}
+	switch files, err := dir.Files(); {
+	case err != nil:
+		return err
+	case len(files) > 0:
+		return errors.New("migration directory is not empty, pull into an empty directory instead")
+	}
+	a, err := (&oci.Client{}).Pull(cmd.Context(), ref)
+	if err != nil {
+		return err
+	}
+	for _, f := range a.Files {
+		if err := dir.WriteFile(f.Name, f.Data); err != nil {
+			return err
+		}
+	}
+	if err := migrate.Validate(dir); err != nil {
+		return fmt.Errorf("pulled migration directory is invalid: %w", err)
+	}
+	files, err := dir.Files()
+	if err != nil {
+		return err
+	}
+	cmd.Printf("Pulled %d migration files from %s (version: %s)\n", len(files), ref, a.Annotations[annotationVersion])
+	return nil
+}
+
+// readFile reads the file with the given name from the migration directory.
+func readFile(dir migrate.Dir, name string) ([]byte, error) {
+	f, err := dir.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s file is missing, run 'atlas migrate hash' to create it", name)
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
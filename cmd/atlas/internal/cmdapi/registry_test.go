@@ -0,0 +1,75 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_PushPull(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		blobs = make(map[string][]byte)
+	)
+	// An in-memory registry that does not require authentication.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch path := strings.TrimPrefix(r.URL.Path, "/v2/migrations/"); {
+		case r.Method == http.MethodPost:
+			w.Header().Set("Location", "/v2/migrations/blobs/uploads/1")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut:
+			b, _ := io.ReadAll(r.Body)
+			h := sha256.Sum256(b)
+			blobs["sha256:"+hex.EncodeToString(h[:])] = b
+			blobs[strings.TrimPrefix(path, "manifests/")] = b
+			w.WriteHeader(http.StatusCreated)
+		default:
+			b, ok := blobs[strings.TrimPrefix(strings.TrimPrefix(path, "manifests/"), "blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(b)
+		}
+	}))
+	defer srv.Close()
+	ref := "oci://" + strings.TrimPrefix(srv.URL, "http://") + "/migrations:v1?plain_http=true"
+
+	_, err := runCmd(Root, "migrate", "push", "https://example.com/migrations", "--dir", "file://testdata/sqlite")
+	require.EqualError(t, err, `oci: invalid reference "https://example.com/migrations", expect oci://host/repository[:tag|@digest]`)
+
+	s, err := runCmd(Root, "migrate", "push", ref, "--dir", "file://testdata/sqlite")
+	require.NoError(t, err)
+	require.Contains(t, s, "Pushed 2 migration files to ")
+
+	_, err = runCmd(Root, "migrate", "pull", ref, "--dir", "file://testdata/sqlite")
+	require.EqualError(t, err, "migration directory is not empty, pull into an empty directory instead")
+
+	p := filepath.Join(t.TempDir(), "migrations")
+	s, err = runCmd(Root, "migrate", "pull", ref, "--dir", "file://"+p)
+	require.NoError(t, err)
+	require.Contains(t, s, "Pulled 2 migration files from ")
+	require.Contains(t, s, "(version: 20220318104615)")
+	for _, name := range []string{"20220318104614_initial.sql", "20220318104615_second.sql", "atlas.sum"} {
+		expected, err := os.ReadFile(filepath.Join("testdata/sqlite", name))
+		require.NoError(t, err)
+		actual, err := os.ReadFile(filepath.Join(p, name))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	}
+}
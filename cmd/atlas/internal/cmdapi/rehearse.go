@@ -0,0 +1,302 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"ariga.io/atlas/cmd/atlas/internal/migrate/ent/revision"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/spf13/cobra"
+)
+
+type (
+	// A Branch is a disposable copy of a database, used for rehearsing migrations.
+	Branch struct {
+		Name string                      // name of the branch, used for reporting
+		URL  string                      // URL for connecting to the branch
+		Drop func(context.Context) error // tears down the branch
+	}
+
+	// A Brancher creates branches of the database at the given URL. For example, by
+	// cloning it on the same server, or by using a database branching service.
+	Brancher interface {
+		Branch(context.Context, *url.URL) (*Branch, error)
+	}
+
+	// BrancherFunc allows using ordinary functions as Branchers.
+	BrancherFunc func(context.Context, *url.URL) (*Branch, error)
+)
+
+// Branch calls f(ctx, u).
+func (f BrancherFunc) Branch(ctx context.Context, u *url.URL) (*Branch, error) {
+	return f(ctx, u)
+}
+
+// branchers holds the registered branchers by name.
+var branchers sync.Map
+
+// RegisterBrancher allows custom Atlas distributions to register a Brancher under
+// the given name, e.g. for integrating with a database branching service. The
+// brancher is selected using the --rehearse-branch flag, and defaults to the one
+// registered under the scheme of the database URL. For example:
+//
+//	func init() {
+//		cmdapi.RegisterBrancher("neon", cmdapi.BrancherFunc(func(ctx context.Context, u *url.URL) (*cmdapi.Branch, error) {
+//			// Create a branch using the Neon API.
+//		}))
+//	}
+func RegisterBrancher(name string, b Brancher) {
+	if b == nil {
+		panic("cmdapi: RegisterBrancher brancher is nil")
+	}
+	if _, ok := branchers.Load(name); ok {
+		panic("cmdapi: RegisterBrancher called twice for " + name)
+	}
+	branchers.Store(name, b)
+}
+
+func init() {
+	for _, s := range []string{"postgres", "postgresql"} {
+		RegisterBrancher(s, BrancherFunc(templateBranch))
+	}
+	for _, s := range []string{"sqlite", "sqlite3"} {
+		RegisterBrancher(s, BrancherFunc(vacuumBranch))
+	}
+}
+
+// templateBranch creates a branch of a PostgreSQL database by creating a new
+// database that uses it as a template. Note, PostgreSQL requires that there
+// are no other connections to the template database while it is copied.
+func templateBranch(ctx context.Context, u *url.URL) (*Branch, error) {
+	src := strings.TrimPrefix(u.Path, "/")
+	if src == "" {
+		return nil, errors.New("postgres: database name is required for creating a branch")
+	}
+	name := fmt.Sprintf("%s_rehearse_%d", src, time.Now().Unix())
+	// Databases are created and dropped using a connection to the maintenance database.
+	exec := func(ctx context.Context, stmt string) error {
+		m := *u
+		m.Path = "/postgres"
+		c, err := openClient(ctx, m.String())
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		_, err = c.DB.ExecContext(ctx, stmt)
+		return err
+	}
+	if err := exec(ctx, fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s", pgIdent(name), pgIdent(src))); err != nil {
+		return nil, fmt.Errorf("postgres: creating branch of database %q: %w", src, err)
+	}
+	b := *u
+	b.Path = "/" + name
+	return &Branch{
+		Name: name,
+		URL:  b.String(),
+		Drop: func(ctx context.Context) error {
+			return exec(ctx, "DROP DATABASE "+pgIdent(name))
+		},
+	}, nil
+}
+
+// pgIdent quotes the given PostgreSQL identifier.
+func pgIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// vacuumBranch creates a branch of a SQLite database by copying
+// it into a temporary file using the VACUUM INTO command.
+func vacuumBranch(ctx context.Context, u *url.URL) (*Branch, error) {
+	c, err := openClient(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	dir, err := os.MkdirTemp("", "atlas-rehearse")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "rehearse.db")
+	if _, err := c.DB.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("sqlite: creating branch of database: %w", err)
+	}
+	// In-memory databases are copied into a file.
+	q := u.Query()
+	q.Del("mode")
+	return &Branch{
+		Name: path,
+		URL:  fmt.Sprintf("%s://file:%s?%s", u.Scheme, path, q.Encode()),
+		Drop: func(context.Context) error {
+			return os.RemoveAll(dir)
+		},
+	}, nil
+}
+
+// rehearsal holds the results of executing a single migration file on a branch.
+type rehearsal struct {
+	file  migrate.File
+	took  time.Duration
+	locks []string
+}
+
+// rehearseRun executes the pending migration files on a branch of the target database,
+// reports their execution time and the locks they acquired, and tears the branch down.
+// Each file is executed in its own transaction, in order to report its locks.
+func rehearseRun(cmd *cobra.Command, dir migrate.Dir, n int) (err error) {
+	ctx := cmd.Context()
+	u, err := url.Parse(MigrateFlags.URL)
+	if err != nil {
+		return err
+	}
+	name := MigrateFlags.Apply.Rehearse.Brancher
+	if name == "" {
+		name = u.Scheme
+	}
+	b, ok := branchers.Load(name)
+	if !ok {
+		return fmt.Errorf("brancher %q was not registered", name)
+	}
+	br, err := b.(Brancher).Branch(ctx, u)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("Rehearsing migration on branch %q\n", br.Name)
+	defer func() {
+		if derr := br.Drop(ctx); derr != nil && err == nil {
+			err = fmt.Errorf("dropping branch %q: %w", br.Name, derr)
+		}
+	}()
+	c, err := openClient(ctx, br.URL)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	l, err := logFormat(cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+	rrw, err := entRevisions(ctx, c)
+	if err != nil {
+		return err
+	}
+	if err := rrw.Migrate(ctx); err != nil {
+		return err
+	}
+	exOpts, err := executorOptions(ctx, c, l)
+	if err != nil {
+		return err
+	}
+	ex, err := migrate.NewExecutor(c.Driver, dir, rrw, exOpts...)
+	if err != nil {
+		return err
+	}
+	pending, err := ex.Pending(ctx)
+	if errors.Is(err, migrate.ErrNoPendingFiles) {
+		cmd.Println("The migration directory is synced with the database, no migration files to execute")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if n > 0 && n < len(pending) {
+		pending = pending[:n]
+	}
+	revs, err := rrw.ReadRevisions(ctx)
+	if err != nil {
+		return err
+	}
+	if err := migrate.LogIntro(l, revs, pending); err != nil {
+		return err
+	}
+	results := make([]*rehearsal, 0, len(pending))
+	for _, f := range pending {
+		r, err := rehearseFile(ctx, c, dir, exOpts, f)
+		if err != nil {
+			return err
+		}
+		results = append(results, r)
+	}
+	l.Log(migrate.LogDone{})
+	var total time.Duration
+	cmd.Println("\nRehearsal report:")
+	for _, r := range results {
+		total += r.took
+		cmd.Printf("  %s: %s\n", r.file.Name(), r.took)
+		for _, l := range r.locks {
+			cmd.Printf("    lock: %s\n", l)
+		}
+	}
+	cmd.Printf("  total: %s\n", total)
+	return nil
+}
+
+// rehearseFile executes the given file in a transaction, and
+// returns its execution time and the locks it acquired.
+func rehearseFile(ctx context.Context, c *sqlclient.Client, dir migrate.Dir, exOpts []migrate.ExecutorOption, f migrate.File) (*rehearsal, error) {
+	tx, err := c.Tx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	rrw, err := entRevisions(ctx, tx.Client)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	ex, err := migrate.NewExecutor(tx.Driver, dir, rrw, exOpts...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	start := time.Now()
+	if err := ex.Execute(ctx, f); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	r := &rehearsal{file: f, took: time.Since(start)}
+	if r.locks, err = heldLocks(ctx, tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return r, tx.Commit()
+}
+
+// heldLocks returns the table locks held by the given transaction. Locks are reported
+// only for PostgreSQL, as other databases do not support transactional DDL, or do not
+// expose the locks of the current transaction.
+func heldLocks(ctx context.Context, tx *sqlclient.TxClient) ([]string, error) {
+	if tx.Name != postgres.DriverName {
+		return nil, nil
+	}
+	rows, err := tx.Tx.QueryContext(ctx, locksQuery, revision.Table)
+	if err != nil {
+		return nil, fmt.Errorf("querying locks: %w", err)
+	}
+	defer rows.Close()
+	var locks []string
+	for rows.Next() {
+		var mode, schema, table string
+		if err := rows.Scan(&mode, &schema, &table); err != nil {
+			return nil, err
+		}
+		locks = append(locks, fmt.Sprintf("%s on %s.%s", mode, schema, table))
+	}
+	return locks, rows.Err()
+}
+
+// Query to list the table locks of the current transaction, excluding the revisions table.
+const locksQuery = `SELECT l.mode, n.nspname, c.relname FROM pg_catalog.pg_locks l JOIN pg_catalog.pg_class c ON c.oid = l.relation JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace WHERE l.pid = pg_backend_pid() AND l.locktype = 'relation' AND c.relkind IN ('r', 'p') AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND c.relname <> $1 ORDER BY n.nspname, c.relname, l.mode`
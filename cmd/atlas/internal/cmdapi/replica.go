@@ -0,0 +1,112 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	replicaURLFlag     = "replica-url"
+	replicaTimeoutFlag = "replica-timeout"
+)
+
+var (
+	// ReplicaFlags are the flags used for verifying that the
+	// applied changes are visible on the read replicas.
+	ReplicaFlags struct {
+		URLs    []string
+		Timeout time.Duration
+	}
+
+	// replicaPollInterval is the interval between two inspections of a replica.
+	replicaPollInterval = time.Second
+)
+
+// replicaFlags registers the replica verification flags on the given set.
+func replicaFlags(set *pflag.FlagSet) {
+	set.StringSliceVarP(&ReplicaFlags.URLs, replicaURLFlag, "", nil, "[urls...] read replicas to verify the applied changes on, before completing")
+	set.DurationVarP(&ReplicaFlags.Timeout, replicaTimeoutFlag, "", time.Minute, "max duration to wait for the applied changes to be visible on the replicas")
+}
+
+// verifyReplicas waits until the schema of the primary database, inspected using
+// the given options, is visible on all replicas, and reports their replication lag.
+// The lag is measured from the moment the verification started, and an error is
+// returned if a replica did not catch up before the timeout has elapsed.
+func verifyReplicas(cmd *cobra.Command, primary *sqlclient.Client, opts *schema.InspectRealmOption) error {
+	if len(ReplicaFlags.URLs) == 0 {
+		return nil
+	}
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(cmd.Context(), ReplicaFlags.Timeout)
+	defer cancel()
+	desired, err := inspectRealm(ctx, primary, copyInspectOpts(opts))
+	if err != nil {
+		return err
+	}
+	cmd.Println("\nVerifying the changes on the read replicas:")
+	for _, u := range ReplicaFlags.URLs {
+		if err := waitReplica(ctx, u, desired, opts); err != nil {
+			return fmt.Errorf("replica %s: %w", redactURL(u), err)
+		}
+		cmd.Printf("  -- %s is synced (lag: %s)\n", redactURL(u), time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// waitReplica polls the replica until its schema matches the desired one.
+func waitReplica(ctx context.Context, u string, desired *schema.Realm, opts *schema.InspectRealmOption) error {
+	replica, err := openClient(ctx, u)
+	if err != nil {
+		return err
+	}
+	defer replica.Close()
+	for {
+		current, err := inspectRealm(ctx, replica, copyInspectOpts(opts))
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("changes are not visible after %s", ReplicaFlags.Timeout)
+			}
+			return err
+		}
+		changes, err := replica.RealmDiff(current, desired)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("changes are not visible after %s (missing changes: %d)", ReplicaFlags.Timeout, len(changes))
+		case <-time.After(replicaPollInterval):
+		}
+	}
+}
+
+// copyInspectOpts returns a copy of the given options, as inspectRealm
+// modifies the exclude list it receives.
+func copyInspectOpts(opts *schema.InspectRealmOption) *schema.InspectRealmOption {
+	c := *opts
+	c.Exclude = append([]string(nil), opts.Exclude...)
+	return &c
+}
+
+// redactURL returns the given URL without its credentials.
+func redactURL(s string) string {
+	if u, err := url.Parse(s); err == nil {
+		return u.Redacted()
+	}
+	return s
+}
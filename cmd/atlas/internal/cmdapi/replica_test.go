@@ -0,0 +1,64 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_ApplyReplicas(t *testing.T) {
+	interval := replicaPollInterval
+	replicaPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		replicaPollInterval = interval
+		ApplyFlags.Paths, ApplyFlags.AutoApprove = nil, false
+		ReplicaFlags.URLs, ReplicaFlags.Timeout = nil, time.Minute
+	})
+	var (
+		dir = t.TempDir()
+		p   = filepath.Join(dir, "schema.hcl")
+		u   = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(dir, "test.db"))
+		r   = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(dir, "replica.db"))
+	)
+	require.NoError(t, os.WriteFile(p, []byte(`
+schema "main" {}
+
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+}
+`), 0600))
+	// The primary is its own replica.
+	s, err := runCmd(Root, "schema", "apply", "-u", u, "-f", p, "--auto-approve", "--replica-url", u)
+	require.NoError(t, err)
+	require.Contains(t, s, "Verifying the changes on the read replicas:")
+	require.Contains(t, s, fmt.Sprintf("-- %s is synced (lag: ", u))
+
+	// The replica never receives the changes.
+	ReplicaFlags.URLs = nil
+	require.NoError(t, os.WriteFile(p, []byte(`
+schema "main" {}
+
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  column "name" {
+    type = text
+  }
+}
+`), 0600))
+	_, err = runCmd(Root, "schema", "apply", "-u", u, "-f", p, "--auto-approve", "--replica-url", r, "--replica-timeout", "100ms")
+	require.EqualError(t, err, fmt.Sprintf("replica %s: changes are not visible after 100ms (missing changes: 1)", r))
+}
@@ -0,0 +1,189 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+const diffReportMD = "md"
+
+// Risk levels of a migration file.
+const (
+	riskLow    = "low"
+	riskMedium = "medium"
+	riskHigh   = "high"
+)
+
+// diffReport validates the report format given to 'migrate diff'.
+func diffReport() (string, error) {
+	switch r := MigrateFlags.Diff.Report; r {
+	case "", diffReportMD:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown report format %q, expect %q", r, diffReportMD)
+	}
+}
+
+// writePlanReport writes the plan to the migration directory, and a Markdown report next
+// to each of the written files. The report is meant to be committed along with the file
+// and reviewed in the pull request, and describes its statements, the findings of the
+// analyzers, the risk of the file and the locks that are estimated to be taken.
+func writePlanReport(ctx context.Context, dev *sqlclient.Client, dir migrate.Dir, pl *migrate.Planner, plan *migrate.Plan) error {
+	files, err := dir.Files()
+	if err != nil {
+		return err
+	}
+	if err := pl.WritePlan(plan); err != nil {
+		return err
+	}
+	all, err := dir.Files()
+	if err != nil {
+		return err
+	}
+	// New files are written with the latest versions.
+	n := len(all) - len(files)
+	if n <= 0 {
+		return nil
+	}
+	env, err := selectEnv(GlobalFlags.SelectedEnv)
+	if err != nil {
+		return err
+	}
+	az, err := sqlcheck.AnalyzerFor(dev.Name, env.Lint.Remain())
+	if err != nil {
+		return err
+	}
+	target, err := migrateTarget(ctx, dev)
+	if err != nil {
+		return err
+	}
+	reports, err := sqlcheck.Run(ctx, dir, dev, &sqlcheck.RunOptions{
+		Analyzers: az,
+		Latest:    n,
+		Target:    target,
+		Parser:    sqlparse.ParserFor(dev.Name),
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range reports {
+		b, err := markdownReport(r)
+		if err != nil {
+			return err
+		}
+		if err := dir.WriteFile(strings.TrimSuffix(r.Name, ".sql")+".md", b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownReport returns the Markdown report of the given file.
+func markdownReport(r *sqlcheck.FileReport) ([]byte, error) {
+	stmts, err := migrate.Stmts(r.Text)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		b     strings.Builder
+		diags = r.Diagnostics()
+	)
+	fmt.Fprintf(&b, "# Migration report: `%s`\n\n", r.Name)
+	fmt.Fprintf(&b, "**Risk:** %s\n\n", reportRisk(r, diags))
+	b.WriteString("## DDL summary\n\n")
+	if len(stmts) == 0 {
+		b.WriteString("The file contains no statements.\n\n")
+	} else {
+		b.WriteString("| # | Line | Statement | Estimated lock |\n|---|------|-----------|----------------|\n")
+		for i, s := range stmts {
+			fmt.Fprintf(&b, "| %d | %d | %s | %s |\n", i+1, r.Position(s.Pos).Line, mdCode(s.Text), stmtLock(s, diags))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("## Lint findings\n\n")
+	if len(diags) == 0 && r.Error == "" {
+		b.WriteString("No issues were found.\n")
+		return []byte(b.String()), nil
+	}
+	if len(diags) > 0 {
+		b.WriteString("| Line | Code | Finding |\n|------|------|---------|\n")
+		for _, d := range diags {
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", r.Position(d.Pos).Line, d.Code, mdEscape(d.Text))
+		}
+	}
+	if r.Error != "" {
+		fmt.Fprintf(&b, "\n**Lint errors:** %s\n", mdEscape(r.Error))
+	}
+	return []byte(b.String()), nil
+}
+
+// reportRisk classifies the risk of a migration file by its diagnostics. Files with
+// destructive changes or analysis errors are high risk, and files with other
+// findings (e.g. blocking or data-dependent changes) are medium risk.
+func reportRisk(r *sqlcheck.FileReport, diags []sqlcheck.Diagnostic) string {
+	if r.Error != "" {
+		return riskHigh
+	}
+	risk := riskLow
+	for _, d := range diags {
+		if strings.HasPrefix(d.Code, "DS") {
+			return riskHigh
+		}
+		risk = riskMedium
+	}
+	return risk
+}
+
+// stmtLock estimates the lock taken by the given statement. Statements reported by
+// the blocking analyzer rewrite the table or block writes to it until they are done.
+func stmtLock(s *migrate.Stmt, diags []sqlcheck.Diagnostic) string {
+	for _, d := range diags {
+		if strings.HasPrefix(d.Code, "BC") && d.Pos >= s.Pos && d.Pos < s.Pos+len(s.Text) {
+			return "blocks writes"
+		}
+	}
+	words := strings.Fields(strings.ToUpper(s.Text))
+	if len(words) < 2 {
+		return "-"
+	}
+	switch {
+	case words[0] == "CREATE" && !contains(words, "INDEX"):
+		return "none"
+	case words[0] == "CREATE" || words[0] == "ALTER" || words[0] == "DROP" || words[0] == "RENAME":
+		return "brief metadata lock"
+	case words[0] == "INSERT" || words[0] == "UPDATE" || words[0] == "DELETE":
+		return "row locks"
+	default:
+		return "-"
+	}
+}
+
+// mdCode formats the statement as inline code in a table cell.
+func mdCode(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:77]) + "..."
+	}
+	s = strings.ReplaceAll(s, "|", `\|`)
+	// Statements that contain backticks (e.g. quoted identifiers
+	// in MySQL) are wrapped with double backticks.
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+// mdEscape escapes text written in a table cell.
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,61 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_Revisions(t *testing.T) {
+	u := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	_, err := runCmd(Root, "migrate", "revisions", "export", "--url", u)
+	require.EqualError(t, err, "revisions table was not found in the connected database")
+
+	// Global flags are set from other tests ...
+	MigrateFlags.DirFormat = formatAtlas
+	MigrateFlags.Apply.DryRun, MigrateFlags.Apply.BaselineVersion = false, ""
+	_, err = runCmd(Root, "migrate", "apply", "--dir", "file://testdata/sqlite", "--url", u)
+	require.NoError(t, err)
+
+	s, err := runCmd(Root, "migrate", "revisions", "export", "--url", u)
+	require.NoError(t, err)
+	var revs []*migrate.Revision
+	require.NoError(t, json.Unmarshal([]byte(s), &revs))
+	require.Len(t, revs, 2)
+	require.Equal(t, "20220318104614", revs[0].Version)
+	require.Equal(t, "20220318104615", revs[1].Version)
+
+	s, err = runCmd(Root, "migrate", "revisions", "export", "--url", u, "--format", "csv", "--before", "20220318104615")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "version,description,type,"))
+	require.True(t, strings.HasPrefix(lines[1], "20220318104614,initial,2,"))
+
+	_, err = runCmd(Root, "migrate", "revisions", "prune", "--url", u, "--before", "1")
+	require.EqualError(t, err, `checkpoint version "1" was not found in the revisions table`)
+	s, err = runCmd(Root, "migrate", "revisions", "prune", "--url", u, "--before", "20220318104615")
+	require.NoError(t, err)
+	require.Equal(t, "1 revisions were pruned\n", s)
+
+	// Pending files are computed from the remaining revision.
+	s, err = runCmd(Root, "migrate", "revisions", "export", "--url", u, "--format", "json", "--before", "")
+	require.NoError(t, err)
+	revs = nil
+	require.NoError(t, json.Unmarshal([]byte(s), &revs))
+	require.Len(t, revs, 1)
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://testdata/sqlite", "--url", u)
+	require.NoError(t, err)
+	require.Contains(t, s, "no migration files to execute")
+	RevisionsFlags.Before, RevisionsFlags.Format = "", revisionsFormatJSON
+}
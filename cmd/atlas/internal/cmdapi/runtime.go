@@ -0,0 +1,131 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// runtimeFuncs returns the functions that expose the runtime context
+// (git, CI, host and time) to project files. For example:
+//
+//	env "preview" {
+//	  url = "postgres://localhost:5432/${git_branch()}?sslmode=disable"
+//	}
+//
+// Functions return an empty string if the value is not available, and their
+// results are computed once per process, to keep the evaluation deterministic.
+func runtimeFuncs() map[string]function.Function {
+	return map[string]function.Function{
+		"git_branch": stringFunc(gitBranch),
+		"git_commit": stringFunc(gitCommit),
+		"ci":         stringFunc(ciName),
+		"hostname": stringFunc(func() string {
+			h, _ := os.Hostname()
+			return h
+		}),
+		"timestamp": stringFunc(func() string {
+			return startTime().Format(time.RFC3339)
+		}),
+	}
+}
+
+// stringFunc returns an HCL function without arguments
+// that returns the (memoized) string computed by fn.
+func stringFunc(fn func() string) function.Function {
+	var (
+		once sync.Once
+		v    string
+	)
+	return function.New(&function.Spec{
+		Type: function.StaticReturnType(cty.String),
+		Impl: func([]cty.Value, cty.Type) (cty.Value, error) {
+			once.Do(func() { v = fn() })
+			return cty.StringVal(v), nil
+		},
+	})
+}
+
+// git executes the git command with the given arguments and returns its trimmed output.
+// It is a variable to allow stubbing it in tests.
+var git = func(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitBranch returns the name of the current branch. CI environments commonly check out
+// a detached HEAD, and therefore, the branch name is read from their variables in this case.
+func gitBranch() string {
+	if b, err := git("rev-parse", "--abbrev-ref", "HEAD"); err == nil && b != "HEAD" {
+		return b
+	}
+	return firstEnv("GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "CIRCLE_BRANCH", "BUILDKITE_BRANCH", "BRANCH_NAME", "GIT_BRANCH")
+}
+
+// gitCommit returns the hash of the current commit.
+func gitCommit() string {
+	if c, err := git("rev-parse", "HEAD"); err == nil {
+		return c
+	}
+	return firstEnv("GITHUB_SHA", "CI_COMMIT_SHA", "CIRCLE_SHA1", "BUILDKITE_COMMIT", "GIT_COMMIT")
+}
+
+// ciProviders maps the environment variables set by
+// CI providers to the names returned by the "ci" function.
+var ciProviders = []struct{ env, name string }{
+	{"GITHUB_ACTIONS", "github"},
+	{"GITLAB_CI", "gitlab"},
+	{"CIRCLECI", "circleci"},
+	{"BUILDKITE", "buildkite"},
+	{"TRAVIS", "travis"},
+	{"JENKINS_URL", "jenkins"},
+}
+
+// ciName returns the name of the CI provider the command runs on, "ci" for
+// unknown providers that set the common CI variable, or an empty string.
+func ciName() string {
+	for _, p := range ciProviders {
+		if os.Getenv(p.env) != "" {
+			return p.name
+		}
+	}
+	if ok, _ := strconv.ParseBool(os.Getenv("CI")); ok {
+		return "ci"
+	}
+	return ""
+}
+
+// startTime returns the time the command started at in UTC, or the time set
+// by the SOURCE_DATE_EPOCH variable to allow reproducible evaluations.
+var startTime = func() func() time.Time {
+	now := time.Now()
+	return func() time.Time {
+		if sec, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+			return time.Unix(sec, 0).UTC()
+		}
+		return now.UTC()
+	}
+}()
+
+// firstEnv returns the value of the first non-empty environment variable.
+func firstEnv(names ...string) string {
+	for _, n := range names {
+		if v := os.Getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -7,15 +7,19 @@ package cmdapi
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlclient"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
 
@@ -170,3 +174,887 @@ func setupFmtTest(t *testing.T, inputDir map[string]string) string {
 	require.NoError(t, err)
 	return dir
 }
+
+func TestSchema_ApplyRenames(t *testing.T) {
+	t.Cleanup(func() {
+		ApplyFlags.DryRun, ApplyFlags.DetectRenames = false, false
+	})
+	var (
+		p      = filepath.Join(t.TempDir(), "schema.hcl")
+		u      = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+		c, err = sqlclient.Open(context.Background(), u)
+	)
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.ExecContext(context.Background(), "CREATE TABLE `users` (`id` int NOT NULL, `user_name` text NOT NULL, `email` text NOT NULL)")
+	require.NoError(t, err)
+	err = os.WriteFile(p, []byte(`
+schema "main" {}
+
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  column "username" {
+    type = text
+  }
+  column "contact" {
+    type         = text
+    renamed_from = "email"
+  }
+}
+`), 0600)
+	require.NoError(t, err)
+
+	// Explicit renames are detected without the heuristics.
+	s, err := runCmd(Root, "schema", "apply", "-u", u, "-f", p, "--dry-run")
+	require.NoError(t, err)
+	require.Contains(t, s, "INSERT INTO `new_users` (`id`, `contact`) SELECT `id`, `email` FROM `users`")
+
+	s, err = runCmd(Root, "schema", "apply", "-u", u, "-f", p, "--dry-run", "--detect-renames")
+	require.NoError(t, err)
+	require.Contains(t, s, "ALTER TABLE `users` RENAME COLUMN `user_name` TO `username`")
+	require.Contains(t, s, "ALTER TABLE `users` RENAME COLUMN `email` TO `contact`")
+}
+
+func TestSchema_ApplyParallel(t *testing.T) {
+	t.Cleanup(func() { ApplyFlags.Parallel = 1 })
+	var (
+		ctx    = context.Background()
+		u      = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+		c, err = sqlclient.Open(ctx, u)
+	)
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = runCmd(Root, "schema", "apply", "-u", u, "-f", "schema.hcl", "--parallel", "0")
+	require.EqualError(t, err, "--parallel must be a positive number, got 0")
+
+	var (
+		main  = schema.New("main")
+		other = schema.New("other")
+		tbl   = func(s *schema.Schema, name string) *schema.Table {
+			return schema.NewTable(name).SetSchema(s).AddColumns(schema.NewIntColumn("id", "int"))
+		}
+	)
+	require.NoError(t, applyChanges(ctx, c, []schema.Change{
+		&schema.AddTable{T: tbl(main, "t1")},
+		&schema.AddTable{T: tbl(main, "t2")},
+	}, 4))
+	realm, err := c.InspectRealm(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, realm.Schemas[0].Tables, 2)
+
+	// Errors of failed groups are reported.
+	err = applyChanges(ctx, c, []schema.Change{
+		&schema.AddTable{T: tbl(main, "t1")},
+		&schema.AddTable{T: tbl(other, "t3")},
+	}, 4)
+	require.ErrorContains(t, err, "table `t1` already exists")
+}
+
+func TestSchema_InspectDeps(t *testing.T) {
+	t.Cleanup(func() { InspectFlags.Format = inspectFormatHCL })
+	var (
+		ctx    = context.Background()
+		u      = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+		c, err = sqlclient.Open(ctx, u)
+	)
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.ExecContext(ctx, "CREATE TABLE `users` (`id` int NOT NULL PRIMARY KEY)")
+	require.NoError(t, err)
+	_, err = c.ExecContext(ctx, "CREATE TABLE `posts` (`id` int NOT NULL PRIMARY KEY, `author_id` int NOT NULL, CONSTRAINT `author` FOREIGN KEY (`author_id`) REFERENCES `users` (`id`))")
+	require.NoError(t, err)
+	_, err = c.ExecContext(ctx, "CREATE VIEW `authors` AS SELECT DISTINCT `author_id` FROM `posts`")
+	require.NoError(t, err)
+	_, err = c.ExecContext(ctx, "CREATE TRIGGER `users_delete` AFTER DELETE ON `users` BEGIN DELETE FROM `posts` WHERE `author_id` = OLD.`id`; END")
+	require.NoError(t, err)
+
+	_, err = runCmd(Root, "schema", "inspect", "-u", u, "--format", "yaml")
+	require.EqualError(t, err, `unknown output format "yaml"`)
+
+	s, err := runCmd(Root, "schema", "inspect", "-u", u, "--format", "deps")
+	require.NoError(t, err)
+	var g depGraph
+	require.NoError(t, json.Unmarshal([]byte(s), &g))
+	require.Equal(t, []*depNode{
+		{ID: "main.users", Type: "table", Schema: "main", Name: "users"},
+		{ID: "main.posts", Type: "table", Schema: "main", Name: "posts"},
+		{ID: "main.authors", Type: "view", Schema: "main", Name: "authors"},
+		{ID: "main.users.users_delete", Type: "trigger", Schema: "main", Name: "users_delete"},
+	}, g.Nodes)
+	require.Equal(t, []*depEdge{
+		{From: "main.posts", To: "main.users", Type: "foreign_key", Name: "author", Columns: []string{"author_id"}, RefColumns: []string{"id"}},
+		{From: "main.users.users_delete", To: "main.users", Type: "trigger", Name: "users_delete"},
+		{From: "main.authors", To: "main.posts", Type: "reference"},
+		{From: "main.users.users_delete", To: "main.posts", Type: "reference"},
+	}, g.Edges)
+
+	s, err = runCmd(Root, "schema", "inspect", "-u", u, "--format", "dot")
+	require.NoError(t, err)
+	require.Equal(t, `digraph {
+  "main.users";
+  "main.posts";
+  "main.authors";
+  "main.users.users_delete";
+  "main.posts" -> "main.users" [label="author"];
+  "main.users.users_delete" -> "main.users" [label="users_delete"];
+  "main.authors" -> "main.posts" [label="reference"];
+  "main.users.users_delete" -> "main.posts" [label="reference"];
+}
+`, s)
+}
+
+func TestSchema_InspectJSON(t *testing.T) {
+	t.Cleanup(func() { InspectFlags.Format = inspectFormatHCL })
+	var (
+		ctx    = context.Background()
+		u      = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+		c, err = sqlclient.Open(ctx, u)
+	)
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.ExecContext(ctx, "CREATE TABLE `users` (`id` int NOT NULL PRIMARY KEY, `email` text NULL)")
+	require.NoError(t, err)
+	s, err := runCmd(Root, "schema", "inspect", "-u", u, "--format", "json")
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "schemas": [
+    {
+      "name": "main",
+      "tables": [
+        {
+          "name": "users",
+          "columns": [
+            {
+              "name": "email",
+              "type": "TEXT",
+              "null": true
+            },
+            {
+              "name": "id",
+              "type": "INT"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`, s)
+
+	// Annotations are extracted from the comments.
+	users := schema.NewTable("users").
+		AddColumns(
+			schema.NewStringColumn("email", "text").
+				AddAttrs(schema.AnnotatedComment("contact email", map[string]string{"pii": "email"})),
+		).
+		AddAttrs(schema.AnnotatedComment("", map[string]string{"owner": "identity"}))
+	users.Columns[0].Type.Raw = "text"
+	d := newInspectDoc(schema.NewRealm(schema.New("public").AddTables(users)))
+	require.Equal(t, &inspectDoc{
+		Schemas: []*inspectSchema{
+			{
+				Name: "public",
+				Tables: []*inspectTable{
+					{
+						Name:        "users",
+						Annotations: map[string]string{"owner": "identity"},
+						Columns: []*inspectColumn{
+							{Name: "email", Type: "text", Comment: "contact email", Annotations: map[string]string{"pii": "email"}},
+						},
+					},
+				},
+			},
+		},
+	}, d)
+}
+
+func TestSchema_InspectExcludeExternal(t *testing.T) {
+	t.Cleanup(func() { SchemaFlags.Exclude = nil })
+	var (
+		ctx    = context.Background()
+		dir    = t.TempDir()
+		u      = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(dir, "test.db"))
+		c, err = sqlclient.Open(ctx, u)
+	)
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.ExecContext(ctx, "CREATE TABLE `users` (`id` int NOT NULL PRIMARY KEY, `secret` text)")
+	require.NoError(t, err)
+	_, err = c.ExecContext(ctx, "CREATE INDEX `users_secret` ON `users` (`secret`)")
+	require.NoError(t, err)
+	_, err = c.ExecContext(ctx, "CREATE TABLE `audit` (`id` int NOT NULL)")
+	require.NoError(t, err)
+
+	// The program receives the inspected objects and keeps all objects except
+	// the "audit" table and the "secret" column (and the index that uses it).
+	in, filter := filepath.Join(dir, "in.json"), filepath.Join(dir, "filter.sh")
+	err = os.WriteFile(filter, []byte(fmt.Sprintf(`#!/bin/sh
+cat > %s
+echo '[{"type":"schema","name":"main"},{"type":"table","schema":"main","name":"users"},{"type":"column","schema":"main","table":"users","name":"id"},{"type":"index","schema":"main","table":"users","name":"users_secret"}]'
+`, in)), 0755)
+	require.NoError(t, err)
+	s, err := runCmd(Root, "schema", "inspect", "-u", u, "--exclude", "external://"+filter)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.main
+  column "id" {
+    null = false
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+schema "main" {
+}
+`, s)
+	b, err := os.ReadFile(in)
+	require.NoError(t, err)
+	var objs []excludeObject
+	require.NoError(t, json.Unmarshal(b, &objs))
+	require.ElementsMatch(t, []excludeObject{
+		{Type: "schema", Name: "main"},
+		{Type: "table", Schema: "main", Name: "users"},
+		{Type: "column", Schema: "main", Table: "users", Name: "id"},
+		{Type: "column", Schema: "main", Table: "users", Name: "secret"},
+		{Type: "index", Schema: "main", Table: "users", Name: "users_secret"},
+		{Type: "table", Schema: "main", Name: "audit"},
+		{Type: "column", Schema: "main", Table: "audit", Name: "id"},
+	}, objs)
+
+	// Glob patterns are applied before the programs.
+	s, err = runCmd(Root, "schema", "inspect", "-u", u, "--exclude", "main.users", "--exclude", "external://"+filter)
+	require.NoError(t, err)
+	require.Equal(t, "schema \"main\" {\n}\n", s)
+
+	require.NoError(t, os.WriteFile(filter, []byte("#!/bin/sh\necho 'permission denied' >&2\nexit 1\n"), 0755))
+	_, err = runCmd(Root, "schema", "inspect", "-u", u, "--exclude", "external://"+filter)
+	require.EqualError(t, err, fmt.Sprintf("exclude program %q: exit status 1: permission denied", filter))
+	_, err = runCmd(Root, "schema", "inspect", "-u", u, "--exclude", "external://")
+	require.EqualError(t, err, `missing program in exclude value "external://"`)
+}
+
+func TestSchema_InspectSession(t *testing.T) {
+	t.Cleanup(func() {
+		GlobalFlags.Session, GlobalFlags.SelectedEnv = nil, ""
+		Root.PersistentFlags().Lookup(sessionFlag).Changed = false
+	})
+	var (
+		ctx    = context.Background()
+		dir    = t.TempDir()
+		u      = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(dir, "test.db"))
+		c, err = sqlclient.Open(ctx, u)
+	)
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.ExecContext(ctx, "CREATE TABLE `sessions` (`source` text NOT NULL)")
+	require.NoError(t, err)
+	sources := func() []string {
+		rows, err := c.QueryContext(ctx, "SELECT DISTINCT `source` FROM `sessions` ORDER BY `source`")
+		require.NoError(t, err)
+		defer rows.Close()
+		var s []string
+		for rows.Next() {
+			var v string
+			require.NoError(t, rows.Scan(&v))
+			s = append(s, v)
+		}
+		return s
+	}
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+	require.NoError(t, os.WriteFile(projectFileName, []byte(fmt.Sprintf(`
+env "local" {
+  url     = %q
+  session = ["INSERT INTO sessions VALUES ('env')"]
+}
+`, u)), 0600))
+
+	_, err = runCmd(Root, "schema", "inspect", "--env", "local", "-u", u)
+	require.NoError(t, err)
+	require.Equal(t, []string{"env"}, sources())
+
+	// Flags take precedence over the project file.
+	_, err = c.ExecContext(ctx, "DELETE FROM `sessions`")
+	require.NoError(t, err)
+	_, err = runCmd(Root, "schema", "inspect", "--env", "local", "-u", u, "--session", "INSERT INTO sessions VALUES ('flag')")
+	require.NoError(t, err)
+	require.Equal(t, []string{"flag"}, sources())
+
+	_, err = runCmd(Root, "schema", "inspect", "-u", u, "--session", "SET ROLE migrator")
+	require.EqualError(t, err, `sql/sqlclient: executing session statement "SET ROLE migrator": near "SET": syntax error`)
+}
+
+func TestSchema_ApplyCheckPrivileges(t *testing.T) {
+	t.Cleanup(func() { ApplyFlags.Paths, ApplyFlags.CheckPrivs = nil, false })
+	var (
+		p = filepath.Join(t.TempDir(), "schema.hcl")
+		u = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	)
+	require.NoError(t, os.WriteFile(p, []byte(`
+schema "main" {}
+
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+}
+`), 0600))
+	// Array flags are accumulated between executions.
+	ApplyFlags.Paths = nil
+	_, err := runCmd(Root, "schema", "apply", "-u", u, "-f", p, "--dry-run", "--check-privileges")
+	require.EqualError(t, err, "sql/migrate: driver *sqlite.Driver does not support checking privileges")
+
+	var (
+		out bytes.Buffer
+		cmd = &cobra.Command{}
+		drv = &privDriver{missing: []*migrate.Privilege{{Name: "CREATE", Object: `schema "main"`, Reason: `create table "users"`}}}
+	)
+	cmd.SetOut(&out)
+	err = checkPrivileges(cmd, &sqlclient.Client{Driver: drv}, nil)
+	require.EqualError(t, err, "missing 1 privileges for applying the changes")
+	require.Equal(t, "The connected role is missing the following privileges for applying the changes:\n\n  -- CREATE on schema \"main\" (create table \"users\")\n\n", out.String())
+	drv.missing = nil
+	require.NoError(t, checkPrivileges(cmd, &sqlclient.Client{Driver: drv}, nil))
+}
+
+func TestSchema_ApplyCheckData(t *testing.T) {
+	t.Cleanup(func() { ApplyFlags.Paths, ApplyFlags.CheckData = nil, false })
+	var (
+		p = filepath.Join(t.TempDir(), "schema.hcl")
+		u = openSQLite(t, `
+CREATE TABLE users (id int PRIMARY KEY, email text);
+CREATE TABLE pets (id int PRIMARY KEY, owner_id int);
+INSERT INTO users VALUES (1, 'a8m'), (2, 'a8m'), (3, 'rotem'), (4, NULL), (5, NULL);
+INSERT INTO pets VALUES (1, 1), (2, 9), (3, NULL), (4, 8);
+`)
+	)
+	require.NoError(t, os.WriteFile(p, []byte(`
+schema "main" {}
+
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  column "email" {
+    type = text
+    null = true
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "users_email" {
+    unique  = true
+    columns = [column.email]
+  }
+}
+
+table "pets" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  column "owner_id" {
+    type = int
+    null = true
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  foreign_key "pets_owner" {
+    columns     = [column.owner_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+`), 0600))
+	// Array flags are accumulated between executions.
+	ApplyFlags.Paths = nil
+	out, err := runCmd(Root, "schema", "apply", "-u", u, "-f", p, "--auto-approve", "--check-data")
+	require.EqualError(t, err, "existing rows violate 2 constraints added by the changes")
+	require.Equal(t, `The existing rows violate the following constraints added by the changes:
+
+  -- unique "users_email" of table "users" (email): 2 offending rows
+     (a8m)
+  -- foreign key "pets_owner" of table "pets" (owner_id): 2 offending rows
+     (9)
+     (8)
+
+`, out)
+
+	// Fix the data, and apply the changes.
+	c, err := sqlclient.Open(context.Background(), u)
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.ExecContext(context.Background(), "UPDATE users SET email = 'a8m2' WHERE id = 2; DELETE FROM pets WHERE owner_id > 5")
+	require.NoError(t, err)
+	ApplyFlags.Paths = nil
+	out, err = runCmd(Root, "schema", "apply", "-u", u, "-f", p, "--auto-approve", "--check-data")
+	require.NoError(t, err)
+	require.NotContains(t, out, "violate")
+}
+
+type privDriver struct {
+	migrate.Driver
+	missing []*migrate.Privilege
+}
+
+func (d *privDriver) MissingPrivileges(context.Context, []schema.Change) ([]*migrate.Privilege, error) {
+	return d.missing, nil
+}
+
+func TestSchema_ApplyNaming(t *testing.T) {
+	t.Cleanup(func() { ApplyFlags.Paths, ApplyFlags.Naming = nil, nil })
+	var (
+		p = filepath.Join(t.TempDir(), "schema.hcl")
+		u = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	)
+	err := os.WriteFile(p, []byte(`
+schema "main" {}
+
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  check {
+    expr = "id > 0"
+  }
+}
+`), 0600)
+	require.NoError(t, err)
+	// Array flags are accumulated between executions.
+	apply := func(args ...string) (string, error) {
+		ApplyFlags.Paths, ApplyFlags.Naming = nil, nil
+		return runCmd(Root, append([]string{"schema", "apply", "-u", u, "-f", p, "--dry-run"}, args...)...)
+	}
+	s, err := apply()
+	require.NoError(t, err)
+	require.Contains(t, s, "CHECK (id > 0)")
+	require.NotContains(t, s, "CONSTRAINT")
+
+	_, err = apply("--naming", "check")
+	require.EqualError(t, err, `invalid --naming value "check", expect "hash" or "kind=template"`)
+
+	s, err = apply("--naming", "hash")
+	require.NoError(t, err)
+	require.Regexp(t, "CONSTRAINT `ck_users_[0-9a-f]{8}` CHECK \\(id > 0\\)", s)
+
+	s, err = apply("--naming", "check={{ .Table }}_positive")
+	require.NoError(t, err)
+	require.Contains(t, s, "CONSTRAINT `users_positive` CHECK (id > 0)")
+}
+
+func TestSchema_Unmanaged(t *testing.T) {
+	t.Cleanup(func() { ApplyFlags.Paths, ApplyFlags.AutoApprove = nil, false })
+	ApplyFlags.DryRun = false
+	var (
+		p    = filepath.Join(t.TempDir(), "schema.hcl")
+		from = openSQLite(t, "CREATE TABLE `users` (`id` int NOT NULL, `active` bool NOT NULL); CREATE VIEW `active_ids` (`id`) AS SELECT `id` FROM `users` WHERE `active`;")
+		to   = openSQLite(t, "")
+	)
+	s, err := runCmd(Root, "schema", "inspect", "-u", from)
+	require.NoError(t, err)
+	require.Contains(t, s, `unmanaged "active_ids" {`)
+	require.Contains(t, s, "DROP VIEW `active_ids`")
+	require.NoError(t, os.WriteFile(p, []byte(s), 0600))
+
+	// Array flags are accumulated between executions.
+	ApplyFlags.Paths = nil
+	s, err = runCmd(Root, "schema", "apply", "-u", to, "-f", p, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "CREATE VIEW `active_ids` (`id`) AS SELECT `id` FROM `users` WHERE `active`")
+
+	// The view is created after the table, and is not re-created on the next run.
+	ApplyFlags.Paths = nil
+	s, err = runCmd(Root, "schema", "apply", "-u", to, "-f", p, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "Schema is synced, no changes to be made")
+}
+
+func TestSchema_Views(t *testing.T) {
+	t.Cleanup(func() { ApplyFlags.Paths, ApplyFlags.AutoApprove = nil, false })
+	ApplyFlags.DryRun = false
+	var (
+		p    = filepath.Join(t.TempDir(), "schema.hcl")
+		from = openSQLite(t, "CREATE TABLE `users` (`id` int NOT NULL, `active` bool NOT NULL); CREATE VIEW `active_users` AS SELECT `id` FROM `users` WHERE `active`;")
+		to   = openSQLite(t, "")
+	)
+	s, err := runCmd(Root, "schema", "inspect", "-u", from)
+	require.NoError(t, err)
+	require.Contains(t, s, `view "active_users" {`)
+	require.Contains(t, s, "as     = \"SELECT `id` FROM `users` WHERE `active`\"")
+	require.NoError(t, os.WriteFile(p, []byte(s), 0600))
+	inspected := s
+
+	ApplyFlags.Paths = nil
+	s, err = runCmd(Root, "schema", "apply", "-u", to, "-f", p, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "CREATE VIEW `active_users` AS SELECT `id` FROM `users` WHERE `active`")
+
+	// The view is created after the table, and is not re-created on the next run.
+	ApplyFlags.Paths = nil
+	s, err = runCmd(Root, "schema", "apply", "-u", to, "-f", p, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "Schema is synced, no changes to be made")
+
+	// Modified views are recreated.
+	require.NoError(t, os.WriteFile(p, []byte(strings.Replace(inspected, "WHERE `active`", "WHERE NOT `active`", 1)), 0600))
+	ApplyFlags.Paths = nil
+	s, err = runCmd(Root, "schema", "apply", "-u", to, "-f", p, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "DROP VIEW `active_users`")
+	require.Contains(t, s, "CREATE VIEW `active_users` AS SELECT `id` FROM `users` WHERE NOT `active`")
+}
+
+func TestSchema_Triggers(t *testing.T) {
+	t.Cleanup(func() { ApplyFlags.Paths, ApplyFlags.AutoApprove = nil, false })
+	ApplyFlags.DryRun = false
+	var (
+		p    = filepath.Join(t.TempDir(), "schema.hcl")
+		from = openSQLite(t, "CREATE TABLE `users` (`id` int NOT NULL, `active` bool NOT NULL); CREATE TRIGGER `users_insert` AFTER INSERT ON `users` WHEN NEW.`active` BEGIN UPDATE `users` SET `active` = 0; SELECT 1; END;")
+		to   = openSQLite(t, "")
+	)
+	s, err := runCmd(Root, "schema", "inspect", "-u", from)
+	require.NoError(t, err)
+	require.Contains(t, s, `trigger "users_insert" {`)
+	require.Contains(t, s, "on     = table.users")
+	require.Contains(t, s, "when   = \"NEW.`active`\"")
+	require.NoError(t, os.WriteFile(p, []byte(s), 0600))
+	inspected := s
+
+	ApplyFlags.Paths = nil
+	s, err = runCmd(Root, "schema", "apply", "-u", to, "-f", p, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "CREATE TRIGGER `users_insert` AFTER INSERT ON `users` FOR EACH ROW WHEN NEW.`active` BEGIN UPDATE `users` SET `active` = 0; SELECT 1; END")
+
+	// The trigger is created after the table, and is not re-created on the next run.
+	ApplyFlags.Paths = nil
+	s, err = runCmd(Root, "schema", "apply", "-u", to, "-f", p, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "Schema is synced, no changes to be made")
+
+	// Modified triggers are recreated.
+	require.NoError(t, os.WriteFile(p, []byte(strings.Replace(inspected, "SELECT 1;", "SELECT 2;", 1)), 0600))
+	ApplyFlags.Paths = nil
+	s, err = runCmd(Root, "schema", "apply", "-u", to, "-f", p, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "DROP TRIGGER `users_insert`")
+	require.Contains(t, s, "SELECT 2; END")
+}
+
+func TestSchema_Capabilities(t *testing.T) {
+	t.Cleanup(func() { CapabilitiesFlags.Format, CapabilitiesFlags.Version = capabilitiesFormatText, "" })
+	u := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+
+	_, err := runCmd(Root, "schema", "capabilities", "-u", u, "--format", "yaml")
+	require.EqualError(t, err, `unknown output format "yaml"`)
+
+	s, err := runCmd(Root, "schema", "capabilities", "-u", u, "--format", "text")
+	require.NoError(t, err)
+	require.Contains(t, s, "Driver:   sqlite3\n")
+	require.Contains(t, s, "transactional_ddl   true\n")
+
+	s, err = runCmd(Root, "schema", "capabilities", "-u", u, "--version", "3.24.0", "--format", "json")
+	require.NoError(t, err)
+	var caps migrate.Capabilities
+	require.NoError(t, json.Unmarshal([]byte(s), &caps))
+	require.Equal(t, "sqlite3", caps.Driver)
+	require.Equal(t, "3.24.0", caps.Version)
+	require.True(t, caps.Is(migrate.CapTransactionalDDL))
+	require.False(t, caps.Is(migrate.CapRenameColumn))
+}
+
+func TestSchema_Stats(t *testing.T) {
+	t.Cleanup(func() { StatsFlags.Format, StatsFlags.Top, StatsFlags.Anonymize = statsFormatMarkdown, 10, false })
+	u := openSQLite(t, `
+CREATE TABLE users (id int PRIMARY KEY, name text, email text);
+CREATE INDEX users_name ON users (name);
+CREATE UNIQUE INDEX users_email ON users (email);
+CREATE TABLE posts (id int, user_id int REFERENCES users (id));
+CREATE INDEX posts_user_id ON posts (user_id);
+CREATE TABLE tags (name text);
+`)
+	_, err := runCmd(Root, "schema", "stats", "-u", u, "--format", "yaml")
+	require.EqualError(t, err, `unknown output format "yaml"`)
+
+	s, err := runCmd(Root, "schema", "stats", "-u", u, "--format", "markdown")
+	require.NoError(t, err)
+	require.Contains(t, s, "| Tables | 3 |\n| Columns | 6 |\n| Indexes | 3 |\n| Foreign Keys | 1 |\n")
+	require.Contains(t, s, "| 0 | 1 |\n| 1 | 1 |\n| 2 | 1 |\n")
+	require.Contains(t, s, "| main.users | 3 | 2 | 0 |\n| main.posts | 2 | 1 | 1 |\n")
+	require.Contains(t, s, "Index usage statistics are not available for this database.\n")
+
+	s, err = runCmd(Root, "schema", "stats", "-u", u, "--format", "json", "--top", "1", "--anonymize")
+	require.NoError(t, err)
+	var stats struct {
+		Tables       int
+		WidestTables []struct {
+			Table   string
+			Columns int
+		} `json:"widest_tables"`
+		UsageStats bool `json:"usage_stats"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(s), &stats))
+	require.Equal(t, 3, stats.Tables)
+	require.Len(t, stats.WidestTables, 1)
+	require.Equal(t, anonymize("table", "main.users"), stats.WidestTables[0].Table)
+	require.Equal(t, 3, stats.WidestTables[0].Columns)
+	require.False(t, stats.UsageStats)
+}
+
+func TestSchemaStats_UnusedIndexes(t *testing.T) {
+	var (
+		s     = schema.New("public")
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text"))
+	)
+	s.AddTables(users)
+	users.AddIndexes(
+		schema.NewIndex("users_name").AddColumns(users.Columns[1]),
+		schema.NewUniqueIndex("users_id").AddColumns(users.Columns[0]),
+		schema.NewIndex("users_id_name").AddColumns(users.Columns...),
+	)
+	stats := newSchemaStats(schema.NewRealm(s), map[string]int64{
+		"public.users.users_name":    0,
+		"public.users.users_id":      0,
+		"public.users.users_id_name": 10,
+	}, 10)
+	require.True(t, stats.UsageStats)
+	require.Equal(t, []*indexStats{{Table: "public.users", Index: "users_name"}}, stats.UnusedIndexes)
+	require.Equal(t, []*indexBucket{{Indexes: 3, Tables: 1}}, stats.IndexesPerTable)
+}
+
+func TestSchema_Validate(t *testing.T) {
+	var (
+		p   = t.TempDir()
+		dev = fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "dev.db"))
+	)
+	write := func(name, content string) string {
+		path := filepath.Join(p, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+	report := func(s string) (r struct {
+		Files       []string
+		Diagnostics []struct {
+			File, Severity, Summary string
+			Range                   *struct {
+				Start, End struct{ Line, Column, Byte int }
+			}
+		}
+	}) {
+		require.NoError(t, json.Unmarshal([]byte(s), &r))
+		return r
+	}
+	schemaF := write("schema.hcl", `schema "main" {}`)
+	usersF := write("users.hcl", `table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+`)
+	s, err := runCmd(Root, "schema", "validate", p, "--dev-url", dev)
+	require.NoError(t, err)
+	r := report(s)
+	require.Equal(t, []string{schemaF, usersF}, r.Files)
+	require.Empty(t, r.Diagnostics)
+
+	// References are resolved across the given files only.
+	s, err = runCmd(Root, "schema", "validate", usersF, "--dev-url", dev)
+	require.EqualError(t, err, "schema validation failed")
+	r = report(s)
+	require.Len(t, r.Diagnostics, 1)
+	require.Equal(t, usersF, r.Diagnostics[0].File)
+	require.Equal(t, "error", r.Diagnostics[0].Severity)
+	require.Equal(t, `unknown reference "schema.main"`, r.Diagnostics[0].Summary)
+	require.Equal(t, 2, r.Diagnostics[0].Range.Start.Line)
+	require.Equal(t, 27, r.Diagnostics[0].Range.Start.Byte)
+	require.Equal(t, 38, r.Diagnostics[0].Range.End.Byte)
+
+	// Syntax errors.
+	write("users.hcl", "table \"users\" {\n")
+	s, err = runCmd(Root, "schema", "validate", p, "--dev-url", dev)
+	require.Error(t, err)
+	r = report(s)
+	require.Len(t, r.Diagnostics, 1)
+	require.Equal(t, "Unclosed configuration block", r.Diagnostics[0].Summary)
+
+	// Unknown types.
+	write("users.hcl", `table "users" {
+  schema = schema.main
+  column "id" {
+    type = unknown
+  }
+}
+`)
+	s, err = runCmd(Root, "schema", "validate", p, "--dev-url", dev)
+	require.Error(t, err)
+	r = report(s)
+	require.Len(t, r.Diagnostics, 1)
+	require.Equal(t, "Unknown variable", r.Diagnostics[0].Summary)
+	require.Equal(t, 4, r.Diagnostics[0].Range.Start.Line)
+	require.Equal(t, 12, r.Diagnostics[0].Range.Start.Column)
+
+	_, err = runCmd(Root, "schema", "validate", t.TempDir(), "--dev-url", dev)
+	require.ErrorContains(t, err, "no schema files found in:")
+}
+
+func TestSchema_Compile(t *testing.T) {
+	t.Cleanup(func() {
+		CompileFlags.Dialect, CompileFlags.Version = "", ""
+	})
+	p := filepath.Join(t.TempDir(), "schema.hcl")
+	err := os.WriteFile(p, []byte(`
+schema "public" {}
+
+enum "status" {
+  schema = schema.public
+  values = ["active", "blocked"]
+}
+
+table "posts" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "author_id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "status" {
+    type = enum.status
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+`), 0600)
+	require.NoError(t, err)
+
+	_, err = runCmd(Root, "schema", "compile", "--to-dialect", "oracle", p)
+	require.EqualError(t, err, `unknown dialect "oracle", expect one of [mysql, postgres, sqlite]`)
+
+	s, err := runCmd(Root, "schema", "compile", "--to-dialect", "postgres", p)
+	require.NoError(t, err)
+	require.Equal(t, `-- Add new schema named "public"
+CREATE SCHEMA IF NOT EXISTS "public";
+-- Create enum type "status"
+CREATE TYPE "public"."status" AS ENUM ('active', 'blocked');
+-- Create "users" table
+CREATE TABLE "public"."users" ("id" integer NOT NULL, "status" "public"."status" NOT NULL, PRIMARY KEY ("id"));
+-- Create "posts" table
+CREATE TABLE "public"."posts" ("id" integer NOT NULL, "author_id" integer NOT NULL, PRIMARY KEY ("id"), CONSTRAINT "author" FOREIGN KEY ("author_id") REFERENCES "public"."users" ("id"));
+`, s)
+
+	// Schemas are not created in SQLite.
+	err = os.WriteFile(p, []byte(`
+schema "main" {}
+
+table "posts" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+`), 0600)
+	require.NoError(t, err)
+	s, err = runCmd(Root, "schema", "compile", "--to-dialect", "sqlite", p)
+	require.NoError(t, err)
+	require.Equal(t, "-- Create \"users\" table\nCREATE TABLE `users` (`id` int NOT NULL, PRIMARY KEY (`id`));\n-- Create \"posts\" table\nCREATE TABLE `posts` (`id` int NOT NULL, `author_id` int NOT NULL, CONSTRAINT `author` FOREIGN KEY (`author_id`) REFERENCES `users` (`id`));\n", s)
+
+	// The compiled statements are valid.
+	u := openSQLite(t, s)
+	s, err = runCmd(Root, "schema", "inspect", "-u", u)
+	require.NoError(t, err)
+	require.Contains(t, s, `table "posts"`)
+}
+
+func TestSchema_Convert(t *testing.T) {
+	t.Cleanup(func() {
+		ConvertFlags.From, ConvertFlags.Format, ConvertFlags.Out = nil, convertFormatHCL, ""
+		ApplyFlags.Paths, ApplyFlags.AutoApprove = nil, false
+	})
+	var (
+		dir = t.TempDir()
+		out = filepath.Join(t.TempDir(), "schema")
+		dev = openSQLite(t, "")
+	)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1_users.sql"), []byte("CREATE TABLE users (id int PRIMARY KEY, active bool NOT NULL);\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2_posts.sql"), []byte(`
+CREATE TABLE posts (id int PRIMARY KEY, author_id int REFERENCES users (id));
+CREATE INDEX posts_author ON posts (author_id);
+CREATE VIEW active_users AS SELECT id FROM users WHERE active;
+`), 0600))
+
+	_, err := runCmd(Root, "schema", "convert", "--from", "file://"+dir, "--dev-url", dev, "--format", "json")
+	require.EqualError(t, err, `unknown output format "json"`)
+	_, err = runCmd(Root, "schema", "convert", "--from", dev, "--dev-url", dev, "--format", "hcl")
+	require.EqualError(t, err, fmt.Sprintf("unsupported --from url %q, expect a 'file://' url", dev))
+	ConvertFlags.From = nil
+
+	s, err := runCmd(Root, "schema", "convert", "--from", "file://"+dir, "--dev-url", dev)
+	require.NoError(t, err)
+	require.Contains(t, s, `table "users" {`)
+	require.Contains(t, s, `index "posts_author" {`)
+	require.Contains(t, s, `view "active_users" {`)
+
+	// The dev database is restored.
+	s, err = runCmd(Root, "schema", "inspect", "-u", dev)
+	require.NoError(t, err)
+	require.NotContains(t, s, `table "users"`)
+
+	ConvertFlags.From = nil
+	s, err = runCmd(Root, "schema", "convert", "--from", "file://"+dir, "--dev-url", dev, "--out", out)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(out, "main.hcl")+"\n", s)
+	b, err := os.ReadFile(filepath.Join(out, "main.hcl"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(b), `schema "main" {`))
+
+	// The written schema can be used as the desired state.
+	ApplyFlags.Paths = nil
+	s, err = runCmd(Root, "schema", "apply", "-u", openSQLite(t, ""), "-f", out, "--auto-approve")
+	require.NoError(t, err)
+	require.Contains(t, s, "CREATE TABLE `posts`")
+	require.Contains(t, s, "CREATE VIEW `active_users` AS SELECT id FROM users WHERE active")
+}
@@ -0,0 +1,243 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+const (
+	// sqlOrderFile is the name of the manifest file that sets the
+	// order in which the SQL files of a directory are executed.
+	sqlOrderFile = "atlas.order"
+	// atlas:import directive.
+	directiveImport = "import"
+)
+
+// sqlState reports if the desired state in the given paths is described by SQL
+// files. That is, the paths contain SQL files and do not contain HCL files.
+func sqlState(paths []string) bool {
+	var found bool
+	for _, p := range paths {
+		stat, err := os.Stat(p)
+		if err != nil {
+			return false
+		}
+		names := []string{p}
+		if stat.IsDir() {
+			entries, err := os.ReadDir(p)
+			if err != nil {
+				return false
+			}
+			names = names[:0]
+			for _, e := range entries {
+				if !e.IsDir() {
+					names = append(names, e.Name())
+				}
+			}
+		}
+		for _, n := range names {
+			switch filepath.Ext(n) {
+			case ".hcl":
+				return false
+			case ".sql":
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// sqlFiles returns the SQL files in the given paths, in the order they should be
+// executed. Directories are read non-recursively, and their files are executed in
+// the order set by their atlas.order file, or in lexical order if there is none.
+// Then, files are reordered such that each file is executed after the files it
+// imports using the atlas:import directive.
+//
+//	-- atlas:import users.sql
+//	CREATE TABLE posts (id int PRIMARY KEY, author_id int REFERENCES users (id));
+func sqlFiles(paths []string) ([]migrate.File, error) {
+	var names []string
+	for _, p := range paths {
+		switch stat, err := os.Stat(p); {
+		case err != nil:
+			return nil, err
+		case stat.IsDir():
+			dir, err := dirSQLFiles(p)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, dir...)
+		default:
+			names = append(names, p)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no SQL files were found in %q", paths)
+	}
+	files := make([]migrate.File, 0, len(names))
+	for _, n := range names {
+		buf, err := os.ReadFile(n)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, migrate.NewLocalFile(n, buf))
+	}
+	return importOrder(files)
+}
+
+// dirSQLFiles returns the SQL files in the directory, ordered by its manifest file.
+func dirSQLFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".sql" {
+			names = append(names, e.Name())
+		}
+	}
+	order, err := os.ReadFile(filepath.Join(dir, sqlOrderFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		for i := range names {
+			names[i] = filepath.Join(dir, names[i])
+		}
+		return names, nil
+	case err != nil:
+		return nil, err
+	}
+	listed := make(map[string]bool, len(names))
+	for _, n := range names {
+		listed[n] = false
+	}
+	manifest := filepath.Join(dir, sqlOrderFile)
+	ordered := make([]string, 0, len(names))
+	sc := bufio.NewScanner(bytes.NewReader(order))
+	for sc.Scan() {
+		n := strings.TrimSpace(sc.Text())
+		if n == "" || strings.HasPrefix(n, "#") {
+			continue
+		}
+		n = filepath.Clean(n)
+		switch done, ok := listed[n]; {
+		case !ok:
+			return nil, fmt.Errorf("%s: file %q was not found in directory", manifest, n)
+		case done:
+			return nil, fmt.Errorf("%s: file %q is listed more than once", manifest, n)
+		}
+		listed[n] = true
+		ordered = append(ordered, filepath.Join(dir, n))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	// Files that are not listed in the manifest are not executed
+	// silently, as their place in the order cannot be inferred.
+	for _, n := range names {
+		if !listed[n] {
+			return nil, fmt.Errorf("%s: file %q is not listed", manifest, n)
+		}
+	}
+	return ordered, nil
+}
+
+// importOrder sorts the files such that each file comes after the files it imports,
+// and otherwise keeps their given order. Imported paths are relative to the file.
+func importOrder(files []migrate.File) ([]migrate.File, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	var (
+		sorted = make([]migrate.File, 0, len(files))
+		state  = make(map[string]int, len(files))
+		byName = make(map[string]migrate.File, len(files))
+		visit  func(migrate.File) error
+	)
+	for _, f := range files {
+		byName[filepath.Clean(f.Name())] = f
+	}
+	visit = func(f migrate.File) error {
+		name := filepath.Clean(f.Name())
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("import cycle was found in file %q", f.Name())
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, d := range migrate.HeaderDirectives(f, directiveImport) {
+			for _, imp := range strings.Fields(d) {
+				dep, ok := byName[filepath.Join(filepath.Dir(f.Name()), imp)]
+				if !ok {
+					return fmt.Errorf("%s: imported file %q was not found in the desired state", f.Name(), imp)
+				}
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+		sorted = append(sorted, f)
+		return nil
+	}
+	for _, f := range files {
+		if err := visit(f); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// convertSQL executes the statements of the given files on the dev database, and
+// returns its inspection, limited to the given schemas if the dev database is not
+// bound to a schema. The dev database is restored to its original state before
+// returning.
+func convertSQL(ctx context.Context, dev *sqlclient.Client, files []migrate.File, schemas []string) (_ *schema.Realm, err error) {
+	snap, ok := dev.Driver.(migrate.Snapshoter)
+	if !ok {
+		return nil, migrate.ErrSnapshotUnsupported
+	}
+	restore, err := snap.Snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("taking database snapshot: %w", err)
+	}
+	defer func() {
+		if rerr := restore(ctx); rerr != nil && err == nil {
+			err = fmt.Errorf("restoring dev database: %w", rerr)
+		}
+	}()
+	for _, f := range files {
+		stmts, err := f.Stmts()
+		if err != nil {
+			return nil, fmt.Errorf("scanning statements of %q: %w", f.Name(), err)
+		}
+		for _, s := range stmts {
+			if _, err := dev.ExecContext(ctx, s); err != nil {
+				return nil, fmt.Errorf("executing statement of %q: %w", f.Name(), err)
+			}
+		}
+	}
+	if dev.URL.Schema != "" {
+		schemas = []string{dev.URL.Schema}
+	}
+	return dev.InspectRealm(ctx, &schema.InspectRealmOption{
+		Schemas: schemas,
+		Mode:    schema.InspectSchemas | schema.InspectTables | schema.InspectObjects | schema.InspectViews | schema.InspectTriggers | schema.InspectFuncs | schema.InspectSequences,
+	})
+}
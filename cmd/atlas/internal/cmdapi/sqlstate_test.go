@@ -0,0 +1,109 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLFiles(t *testing.T) {
+	names := func(files []migrate.File) (ns []string) {
+		for _, f := range files {
+			ns = append(ns, filepath.Base(f.Name()))
+		}
+		return ns
+	}
+	dir := t.TempDir()
+	for n, c := range map[string]string{
+		"a_posts.sql": "-- atlas:import c_users.sql\nCREATE TABLE posts (id int, author_id int REFERENCES users (id));\n",
+		"b_tags.sql":  "CREATE TABLE tags (name text);\n",
+		"c_users.sql": "CREATE TABLE users (id int PRIMARY KEY);\n",
+		"schema.hcl":  "",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, n), []byte(c), 0600))
+	}
+	require.False(t, sqlState([]string{dir}))
+	require.NoError(t, os.Remove(filepath.Join(dir, "schema.hcl")))
+	require.True(t, sqlState([]string{dir}))
+	require.True(t, sqlState([]string{filepath.Join(dir, "b_tags.sql")}))
+	require.False(t, sqlState([]string{filepath.Join(dir, "missing.sql")}))
+
+	// Files are sorted by their imports.
+	files, err := sqlFiles([]string{dir})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c_users.sql", "a_posts.sql", "b_tags.sql"}, names(files))
+
+	// The manifest file sets the base order.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, sqlOrderFile), []byte("# Tags first.\nb_tags.sql\n\na_posts.sql\nc_users.sql\n"), 0600))
+	files, err = sqlFiles([]string{dir})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b_tags.sql", "c_users.sql", "a_posts.sql"}, names(files))
+
+	manifest := filepath.Join(dir, sqlOrderFile)
+	require.NoError(t, os.WriteFile(manifest, []byte("b_tags.sql\na_posts.sql\n"), 0600))
+	_, err = sqlFiles([]string{dir})
+	require.EqualError(t, err, manifest+`: file "c_users.sql" is not listed`)
+	require.NoError(t, os.WriteFile(manifest, []byte("b_tags.sql\na_posts.sql\nc_users.sql\nd.sql\n"), 0600))
+	_, err = sqlFiles([]string{dir})
+	require.EqualError(t, err, manifest+`: file "d.sql" was not found in directory`)
+	require.NoError(t, os.WriteFile(manifest, []byte("b_tags.sql\na_posts.sql\nc_users.sql\nb_tags.sql\n"), 0600))
+	_, err = sqlFiles([]string{dir})
+	require.EqualError(t, err, manifest+`: file "b_tags.sql" is listed more than once`)
+	require.NoError(t, os.Remove(manifest))
+
+	// Import errors.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c_users.sql"), []byte("-- atlas:import a_posts.sql\nCREATE TABLE users (id int PRIMARY KEY);\n"), 0600))
+	_, err = sqlFiles([]string{dir})
+	require.EqualError(t, err, `import cycle was found in file "`+filepath.Join(dir, "a_posts.sql")+`"`)
+	_, err = sqlFiles([]string{filepath.Join(dir, "a_posts.sql")})
+	require.EqualError(t, err, filepath.Join(dir, "a_posts.sql")+`: imported file "c_users.sql" was not found in the desired state`)
+}
+
+func TestMigrate_DiffSQLState(t *testing.T) {
+	t.Cleanup(func() { MigrateFlags.ToURLs = nil })
+	var (
+		p   = t.TempDir()
+		dir = t.TempDir()
+	)
+	// Triggers cannot be created before their tables, and
+	// therefore, the file order is resolved before execution.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1_triggers.sql"), []byte("-- atlas:import 2_users.sql\nCREATE TRIGGER users_insert AFTER INSERT ON users BEGIN SELECT 1; END;\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2_users.sql"), []byte("CREATE TABLE users (id int PRIMARY KEY, active bool NOT NULL);\n"), 0600))
+
+	MigrateFlags.ToURLs = nil
+	_, err := runCmd(
+		Root, "migrate", "diff",
+		"init",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", "file://"+dir,
+	)
+	require.NoError(t, err)
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Contains(t, string(files[0].Bytes()), "CREATE TABLE `users`")
+	require.Contains(t, string(files[0].Bytes()), "CREATE TRIGGER `users_insert` AFTER INSERT ON `users` FOR EACH ROW BEGIN SELECT 1; END;")
+
+	// Without the import, files are executed in lexical order.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1_triggers.sql"), []byte("CREATE TRIGGER users_insert AFTER INSERT ON users BEGIN SELECT 1; END;\n"), 0600))
+	MigrateFlags.ToURLs = nil
+	_, err = runCmd(
+		Root, "migrate", "diff",
+		"init",
+		"--dir", "file://"+t.TempDir(),
+		"--dev-url", openSQLite(t, ""),
+		"--to", "file://"+dir,
+	)
+	require.ErrorContains(t, err, "no such table: main.users")
+}
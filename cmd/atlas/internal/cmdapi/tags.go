@@ -0,0 +1,154 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+// tagsFileName is the name of the file that holds the tagged states of the migration
+// directory. It is stored alongside the migration files, and is not part of atlas.sum,
+// as tagging a state does not change the directory. Each line holds a tag name and the
+// version of the last migration file of the tagged state. For example:
+//
+//	v1.3.0 20220318104614
+//	v1.4.0 20220318104615
+const tagsFileName = "atlas.tags"
+
+// dirTag is a tagged state of the migration directory.
+type dirTag struct {
+	Name    string
+	Version string
+}
+
+var reTagName = regexp.MustCompile(`^[\w][\w.-]*$`)
+
+// readTags reads the tags of the migration directory. A directory without
+// a tags file has no tags.
+func readTags(dir migrate.Dir) ([]*dirTag, error) {
+	f, err := dir.Open(tagsFileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var (
+		tags []*dirTag
+		s    = bufio.NewScanner(f)
+	)
+	for i := 1; s.Scan(); i++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) != 2 || !reTagName.MatchString(parts[0]) {
+			return nil, fmt.Errorf("%s: invalid tag at line %d: %q", tagsFileName, i, line)
+		}
+		tags = append(tags, &dirTag{Name: parts[0], Version: parts[1]})
+	}
+	return tags, s.Err()
+}
+
+// writeTags writes the tags of the migration directory.
+func writeTags(dir migrate.Dir, tags []*dirTag) error {
+	return dir.WriteFile(tagsFileName, formatTags(tags))
+}
+
+// formatTags returns the content of the tags file.
+func formatTags(tags []*dirTag) []byte {
+	var b bytes.Buffer
+	for _, t := range tags {
+		fmt.Fprintf(&b, "%s %s\n", t.Name, t.Version)
+	}
+	return b.Bytes()
+}
+
+// tagVersion returns the version of the migration directory state with the given tag.
+func tagVersion(dir migrate.Dir, name string) (string, error) {
+	tags, err := readTags(dir)
+	if err != nil {
+		return "", err
+	}
+	i := slices.IndexFunc(tags, func(t *dirTag) bool { return t.Name == name })
+	if i == -1 {
+		return "", fmt.Errorf("tag %q was not found in the migration directory", name)
+	}
+	return tags[i].Version, nil
+}
+
+// filesToTag returns the pending files up to the state with the given tag (including it).
+func filesToTag(dir migrate.Dir, pending []migrate.File, name string) ([]migrate.File, error) {
+	v, err := tagVersion(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if i := slices.IndexFunc(pending, func(f migrate.File) bool { return f.Version() == v }); i != -1 {
+		return pending[:i+1], nil
+	}
+	files, err := dir.Files()
+	if err != nil {
+		return nil, err
+	}
+	if slices.IndexFunc(files, func(f migrate.File) bool { return f.Version() == v }) == -1 {
+		return nil, fmt.Errorf("tag %q points to version %s that does not exist in the migration directory", name, v)
+	}
+	// The tagged file was already applied.
+	return nil, nil
+}
+
+// CmdMigrateTagRun is the command executed when running the CLI with 'migrate tag' args.
+func CmdMigrateTagRun(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if !reTagName.MatchString(name) {
+		return fmt.Errorf("invalid tag name %q", name)
+	}
+	dir, err := dir(false)
+	if err != nil {
+		return err
+	}
+	files, err := dir.Files()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("cannot tag an empty migration directory")
+	}
+	// Tag the latest state, unless a version was given.
+	v := files[len(files)-1].Version()
+	if len(args) > 1 {
+		if v = args[1]; slices.IndexFunc(files, func(f migrate.File) bool { return f.Version() == v }) == -1 {
+			return fmt.Errorf("version %s does not exist in the migration directory", v)
+		}
+	}
+	tags, err := readTags(dir)
+	if err != nil {
+		return err
+	}
+	switch i := slices.IndexFunc(tags, func(t *dirTag) bool { return t.Name == name }); {
+	case i == -1:
+		tags = append(tags, &dirTag{Name: name, Version: v})
+	case tags[i].Version != v:
+		return fmt.Errorf("tag %q already exists and points to version %s", name, tags[i].Version)
+	}
+	if err := writeTags(dir, tags); err != nil {
+		return err
+	}
+	cmd.Printf("Tagged version %s as %q\n", v, name)
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_Tag(t *testing.T) {
+	MigrateFlags.Apply.BaselineVersion, MigrateFlags.Apply.DryRun = "", false
+	t.Cleanup(func() { MigrateFlags.Apply.ToTag = "" })
+	p := t.TempDir()
+	for _, name := range []string{"20220318104614_initial.sql", "20220318104615_second.sql", "atlas.sum"} {
+		b, err := os.ReadFile(filepath.Join("testdata/sqlite", name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(p, name), b, 0644))
+	}
+	s, err := runCmd(Root, "migrate", "tag", "v1.0.0", "20220318104614", "--dir", "file://"+p)
+	require.NoError(t, err)
+	require.Equal(t, "Tagged version 20220318104614 as \"v1.0.0\"\n", s)
+	_, err = runCmd(Root, "migrate", "tag", "v1.1.0", "1", "--dir", "file://"+p)
+	require.EqualError(t, err, "version 1 does not exist in the migration directory")
+	// The latest version is tagged by default.
+	_, err = runCmd(Root, "migrate", "tag", "v1.1.0", "--dir", "file://"+p)
+	require.NoError(t, err)
+	_, err = runCmd(Root, "migrate", "tag", "v1.0.0", "--dir", "file://"+p)
+	require.EqualError(t, err, `tag "v1.0.0" already exists and points to version 20220318104614`)
+	b, err := os.ReadFile(filepath.Join(p, tagsFileName))
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.0 20220318104614\nv1.1.0 20220318104615\n", string(b))
+
+	db := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	_, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--to-tag", "v2.0.0")
+	require.EqualError(t, err, `tag "v2.0.0" was not found in the migration directory`)
+
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--to-tag", "v1.0.0")
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating to version 20220318104614 (1 migrations in total)")
+
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--to-tag", "v1.0.0")
+	require.NoError(t, err)
+	require.Contains(t, s, `The database is already at or past tag "v1.0.0", no migration files to execute`)
+
+	s, err = runCmd(Root, "migrate", "apply", "--dir", "file://"+p, "--url", db, "--to-tag", "v1.1.0")
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating to version 20220318104615 from 20220318104614 (1 migrations in total)")
+}
@@ -0,0 +1,879 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"ariga.io/atlas/cmd/atlas/internal/lint"
+	entmigrate "ariga.io/atlas/cmd/atlas/internal/migrate"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlclient"
+	"ariga.io/atlas/sql/sqltest"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	workspaceFlagRoot    = "root"
+	workspaceFlagFormat  = "format"
+	workspaceFormatText  = "text"
+	workspaceFormatJSON  = "json"
+	workspaceCheckLint   = "lint"
+	workspaceCheckDiff   = "diff"
+	workspaceCheckStatus = "status"
+	workspaceCheckApply  = "apply"
+)
+
+// Results of a workspace check.
+const (
+	workspaceResultOK      = "ok"
+	workspaceResultFailed  = "failed"
+	workspaceResultError   = "error"
+	workspaceResultSkipped = "skipped"
+)
+
+var (
+	// WorkspaceFlags are the flags used in WorkspaceCmd commands.
+	WorkspaceFlags struct {
+		Root   string // root directory of the workspace
+		Format string // output format
+		Lint   struct {
+			Latest  uint   // default number of latest files to lint
+			GitBase string // default git base branch to lint against
+		}
+		Apply struct {
+			DryRun bool // do not execute any statements
+		}
+	}
+
+	// WorkspaceCmd represents the subcommand 'atlas workspace'.
+	WorkspaceCmd = &cobra.Command{
+		Use:   "workspace",
+		Short: "Work with multiple Atlas projects in a repository.",
+		Long: `'atlas workspace' groups subcommands for working with all Atlas projects of a repository (e.g., a monorepo)
+at once. Projects are discovered by searching the workspace root for "atlas.hcl" files, skipping hidden
+directories. Relative paths in a project file (e.g. migration directories and schema sources) are resolved
+relative to the directory of the project file.`,
+	}
+
+	// WorkspaceListCmd represents the 'atlas workspace list' subcommand.
+	WorkspaceListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the projects and environments found in the workspace.",
+		Example: `  atlas workspace list
+  atlas workspace list --root ./services --format json`,
+		Args: cobra.NoArgs,
+		RunE: CmdWorkspaceListRun,
+	}
+
+	// WorkspaceRunCmd represents the 'atlas workspace run' subcommand.
+	WorkspaceRunCmd = &cobra.Command{
+		Use:   "run [lint|diff|status ...]",
+		Short: "Run checks on all projects of the workspace and print an aggregated report.",
+		Long: `'atlas workspace run' runs the given checks (or all of them if none was given) on all environments of
+all projects found in the workspace, or only on the environments named by the "--env" flag, and prints an
+aggregated report. The following checks are supported:
+
+  lint    Lint the migration directory, like 'atlas migrate lint'. The changes to lint are configured by
+          the lint block of the env, or the "--latest" and "--git-base" flags otherwise.
+  diff    Check that the migration directory is in sync with the schema defined by the "src" of the env.
+  status  Report the pending migration files of the database defined by the "url" of the env.
+
+Dev databases are shared between projects: environments that use the same dev database URL reuse a single
+connection. The command exits with a non-zero code if any check failed.`,
+		Example: `  atlas workspace run
+  atlas workspace run lint diff --env ci --git-base origin/main
+  atlas workspace run status --root ./services --format json`,
+		ValidArgs: []string{workspaceCheckLint, workspaceCheckDiff, workspaceCheckStatus},
+		Args:      cobra.OnlyValidArgs,
+		RunE:      CmdWorkspaceRunRun,
+	}
+
+	// WorkspaceApplyCmd represents the 'atlas workspace apply' subcommand.
+	WorkspaceApplyCmd = &cobra.Command{
+		Use:   "apply",
+		Short: "Apply the pending migration files of all projects of the workspace in dependency order.",
+		Long: `'atlas workspace apply' executes the pending migration files of all environments of all projects found
+in the workspace, or only of the environments named by the "--env" flag, on the databases defined by the "url"
+of the environments, and prints an aggregated report.
+
+Projects are applied after the projects they depend on, as declared by the "workspace" block of their project
+files. For example, a service whose schema references a schema owned by another project:
+
+  workspace {
+    depends_on = ["../shared"]
+  }
+
+If a project fails to apply, the projects that depend on it are skipped. The command exits with a non-zero code
+if any project failed.`,
+		Example: `  atlas workspace apply --env prod
+  atlas workspace apply --env local --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: CmdWorkspaceApplyRun,
+	}
+)
+
+func init() {
+	Root.AddCommand(WorkspaceCmd)
+	receivesEnv(WorkspaceCmd)
+	WorkspaceCmd.PersistentFlags().StringVarP(&WorkspaceFlags.Root, workspaceFlagRoot, "", ".", "root directory of the workspace")
+	WorkspaceCmd.PersistentFlags().StringVarP(&WorkspaceFlags.Format, workspaceFlagFormat, "", workspaceFormatText, "Set the output format [text, json]")
+	WorkspaceCmd.AddCommand(WorkspaceListCmd)
+	WorkspaceCmd.AddCommand(WorkspaceRunCmd)
+	WorkspaceCmd.AddCommand(WorkspaceApplyCmd)
+	WorkspaceRunCmd.Flags().UintVarP(&WorkspaceFlags.Lint.Latest, migrateLintLatest, "", 0, "run analysis on the latest N migration files of envs without lint configuration")
+	WorkspaceRunCmd.Flags().StringVarP(&WorkspaceFlags.Lint.GitBase, migrateLintGitBase, "", "", "run analysis against the base Git branch of envs without lint configuration")
+	WorkspaceApplyCmd.Flags().BoolVarP(&WorkspaceFlags.Apply.DryRun, migrateFlagDryRun, "", false, "do not actually execute any SQL but show it on screen")
+}
+
+type (
+	// workspaceProject is an Atlas project found in the workspace.
+	workspaceProject struct {
+		Path      string   // Path to the project directory, relative to the root.
+		Envs      []string // Names of the selected environments.
+		DependsOn []string `json:",omitempty"` // Paths of the projects it depends on, relative to the root.
+		dir       string   // Path to the project directory.
+	}
+
+	// workspaceResult is the result of running a check on a project environment.
+	workspaceResult struct {
+		Project string   // Path to the project directory, relative to the root.
+		Env     string   // Name of the environment.
+		Check   string   // Name of the check, e.g. lint.
+		Result  string   // One of: ok, failed, error or skipped.
+		Summary string   // Summary of the result.
+		Details []string `json:",omitempty"` // Details of the result, e.g. lint diagnostics.
+	}
+
+	// devPool holds the dev database clients shared by the workspace
+	// projects, keyed by their URLs.
+	devPool struct {
+		clients map[string]*sqlclient.Client
+	}
+)
+
+// CmdWorkspaceListRun is the command executed when running the CLI with 'workspace list' args.
+func CmdWorkspaceListRun(cmd *cobra.Command, _ []string) error {
+	if err := workspaceFormat(); err != nil {
+		return err
+	}
+	projects, err := workspaceProjects(WorkspaceFlags.Root)
+	if err != nil {
+		return err
+	}
+	if WorkspaceFlags.Format == workspaceFormatJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		if projects == nil {
+			projects = []*workspaceProject{}
+		}
+		return enc.Encode(projects)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tENVS\tDEPENDS ON")
+	for _, p := range projects {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Path, strings.Join(p.Envs, ", "), strings.Join(p.DependsOn, ", "))
+	}
+	return w.Flush()
+}
+
+// CmdWorkspaceRunRun is the command executed when running the CLI with 'workspace run' args.
+func CmdWorkspaceRunRun(cmd *cobra.Command, args []string) error {
+	if err := workspaceFormat(); err != nil {
+		return err
+	}
+	checks := args
+	if len(checks) == 0 {
+		checks = []string{workspaceCheckLint, workspaceCheckDiff, workspaceCheckStatus}
+	}
+	projects, err := workspaceProjects(WorkspaceFlags.Root)
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		return fmt.Errorf("no projects found in %q", WorkspaceFlags.Root)
+	}
+	pool := &devPool{clients: make(map[string]*sqlclient.Client)}
+	defer pool.Close()
+	var results []*workspaceResult
+	for _, p := range projects {
+		for _, name := range p.Envs {
+			env, err := p.env(name)
+			for _, c := range checks {
+				r := &workspaceResult{Project: p.Path, Env: name, Check: c}
+				if err != nil {
+					r.Result, r.Summary = workspaceResultError, err.Error()
+				} else {
+					p.run(cmd.Context(), pool, env, r)
+				}
+				results = append(results, r)
+			}
+		}
+	}
+	return workspaceReport(cmd, results)
+}
+
+// CmdWorkspaceApplyRun is the command executed when running the CLI with 'workspace apply' args.
+func CmdWorkspaceApplyRun(cmd *cobra.Command, _ []string) error {
+	if err := workspaceFormat(); err != nil {
+		return err
+	}
+	projects, err := workspaceProjects(WorkspaceFlags.Root)
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		return fmt.Errorf("no projects found in %q", WorkspaceFlags.Root)
+	}
+	if projects, err = applyOrder(projects); err != nil {
+		return err
+	}
+	// Logs are printed only in text format, as they
+	// are not part of the JSON report.
+	out := io.Discard
+	if WorkspaceFlags.Format == workspaceFormatText {
+		out = cmd.OutOrStdout()
+	}
+	var (
+		results []*workspaceResult
+		failed  = make(map[string]bool)
+	)
+	for _, p := range projects {
+		for _, name := range p.Envs {
+			r := &workspaceResult{Project: p.Path, Env: name, Check: workspaceCheckApply}
+			results = append(results, r)
+			if dep := p.failedDep(failed); dep != "" {
+				r.Result, r.Summary = workspaceResultSkipped, fmt.Sprintf("dependency %q failed to apply", dep)
+				failed[p.Path] = true
+				continue
+			}
+			fmt.Fprintf(out, "Applying project %q (env %q)\n", p.Path, name)
+			env, err := p.env(name)
+			if err == nil {
+				err = p.apply(cmd.Context(), out, env, r)
+			}
+			if err != nil {
+				r.Result, r.Summary = workspaceResultError, err.Error()
+			}
+			if r.Result == workspaceResultError {
+				failed[p.Path] = true
+			}
+			fmt.Fprintln(out)
+		}
+	}
+	return workspaceReport(cmd, results)
+}
+
+// failedDep returns the first dependency of the project that failed, if any.
+func (p *workspaceProject) failedDep(failed map[string]bool) string {
+	for _, dep := range p.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+func workspaceFormat() error {
+	switch f := WorkspaceFlags.Format; f {
+	case workspaceFormatText, workspaceFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q", f)
+	}
+}
+
+// workspaceProjects returns the projects found in the given root directory, and their
+// environments. If an environment was selected, projects that do not define it are skipped.
+func workspaceProjects(root string) ([]*workspaceProject, error) {
+	var (
+		projects []*workspaceProject
+		all      = make(map[string]bool)
+	)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir() && path != root && strings.HasPrefix(d.Name(), "."):
+			return filepath.SkipDir
+		case d.IsDir() || d.Name() != projectFileName:
+			return nil
+		}
+		dir := filepath.Dir(path)
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return err
+		}
+		project, err := loadProject(path)
+		if err != nil {
+			return fmt.Errorf("loading project %q: %w", rel, err)
+		}
+		p := &workspaceProject{Path: filepath.ToSlash(rel), dir: dir}
+		all[p.Path] = true
+		for _, e := range project.Envs {
+			p.Envs = append(p.Envs, e.Name)
+		}
+		if w := project.Workspace; w != nil {
+			for _, dep := range w.DependsOn {
+				dp, err := filepath.Rel(root, filepath.Join(dir, dep))
+				if err != nil {
+					return err
+				}
+				p.DependsOn = append(p.DependsOn, filepath.ToSlash(dp))
+			}
+		}
+		if s := GlobalFlags.SelectedEnv; s != "" {
+			p.Envs = nil
+			if _, err := LoadEnv(path, s, WithInput(GlobalFlags.Vars)); err == nil {
+				p.Envs = []string{s}
+			}
+		}
+		if len(p.Envs) > 0 {
+			projects = append(projects, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		for _, dep := range p.DependsOn {
+			if !all[dep] {
+				return nil, fmt.Errorf("project %q depends on %q, which is not a project in the workspace", p.Path, dep)
+			}
+		}
+	}
+	return projects, nil
+}
+
+// loadProject evaluates the project file in the given path.
+func loadProject(path string) (*Project, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	project := &Project{Lint: &Lint{}}
+	if err := hclState.EvalBytes(b, project, GlobalFlags.Vars); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// applyOrder sorts the given projects such that each project comes after the projects it
+// depends on. Independent projects keep their (lexical) order. Dependencies on projects
+// that were not selected (e.g. they do not define the selected env) are ignored.
+func applyOrder(projects []*workspaceProject) ([]*workspaceProject, error) {
+	var (
+		order   []*workspaceProject
+		byPath  = make(map[string]*workspaceProject, len(projects))
+		visited = make(map[string]int) // 1: in progress, 2: done.
+		visit   func(*workspaceProject, []string) error
+	)
+	for _, p := range projects {
+		byPath[p.Path] = p
+	}
+	visit = func(p *workspaceProject, path []string) error {
+		switch visited[p.Path] {
+		case 1:
+			return fmt.Errorf("dependency cycle between projects: %s", strings.Join(append(path, p.Path), " -> "))
+		case 2:
+			return nil
+		}
+		visited[p.Path] = 1
+		for _, dep := range p.DependsOn {
+			if d, ok := byPath[dep]; ok {
+				if err := visit(d, append(path, p.Path)); err != nil {
+					return err
+				}
+			}
+		}
+		visited[p.Path] = 2
+		order = append(order, p)
+		return nil
+	}
+	for _, p := range projects {
+		if err := visit(p, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// env loads the environment with the given name, and resolves its
+// relative paths relative to the directory of the project.
+func (p *workspaceProject) env(name string) (*Env, error) {
+	env, err := LoadEnv(filepath.Join(p.dir, projectFileName), name, WithInput(GlobalFlags.Vars))
+	if err != nil {
+		return nil, err
+	}
+	m := env.Migration
+	if m.Dir != "" {
+		m.Dir = p.dirURL(m.Dir)
+	}
+	for i := range m.Dirs {
+		m.Dirs[i] = p.dirURL(m.Dirs[i])
+	}
+	// Git is executed in the project directory, if not configured otherwise.
+	env.Lint.Git.Dir = p.path(env.Lint.Git.Dir)
+	return env, nil
+}
+
+// path resolves the given path relative to the project directory.
+func (p *workspaceProject) path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.dir, path)
+}
+
+// dirURL resolves the path of the given directory URL relative to the project directory.
+func (p *workspaceProject) dirURL(u string) string {
+	if path := strings.TrimPrefix(u, "file://"); path != u {
+		return "file://" + p.path(path)
+	}
+	return u
+}
+
+// run runs the check on the given environment, and records its outcome in r.
+func (p *workspaceProject) run(ctx context.Context, pool *devPool, env *Env, r *workspaceResult) {
+	var err error
+	switch r.Check {
+	case workspaceCheckLint:
+		err = p.lint(ctx, pool, env, r)
+	case workspaceCheckDiff:
+		err = p.diff(ctx, pool, env, r)
+	case workspaceCheckStatus:
+		err = p.status(ctx, env, r)
+	}
+	if err != nil {
+		r.Result, r.Summary = workspaceResultError, err.Error()
+	}
+}
+
+// migrateDir opens the migration directory of the environment.
+func (p *workspaceProject) migrateDir(env *Env) (migrate.Dir, error) {
+	format := env.Migration.Format
+	if format == "" {
+		format = formatAtlas
+	}
+	urls := env.Migration.DirURLs()
+	if len(urls) == 0 {
+		urls = []string{p.dirURL("file://migrations")}
+	}
+	dirs := make([]migrate.Dir, len(urls))
+	for i, u := range urls {
+		d, err := formatDir(u, format, false)
+		if err != nil {
+			return nil, err
+		}
+		dirs[i] = d
+	}
+	d := dirs[0]
+	if len(dirs) > 1 {
+		md, err := migrate.NewMultiDir(dirs...)
+		if err != nil {
+			return nil, err
+		}
+		d = md
+	}
+	if v := env.Migration.Version; v != nil {
+		vs, err := newVersionScheme(v.Scheme, v.Prefix, v.Width)
+		if err != nil {
+			return nil, err
+		}
+		if vs != nil {
+			d = migrate.NewSchemeDir(d, vs)
+		}
+	}
+	return d, nil
+}
+
+func (p *workspaceProject) lint(ctx context.Context, pool *devPool, env *Env, r *workspaceResult) error {
+	if env.DevURL == "" {
+		r.Result, r.Summary = workspaceResultSkipped, "no dev database configured"
+		return nil
+	}
+	dir, err := p.migrateDir(env)
+	if err != nil {
+		return err
+	}
+	latest, base := env.Lint.Latest, env.Lint.Git.Base
+	if latest == 0 && base == "" {
+		latest, base = int(WorkspaceFlags.Lint.Latest), WorkspaceFlags.Lint.GitBase
+	}
+	var detect lint.ChangeDetector
+	switch {
+	case latest == 0 && base == "":
+		r.Result, r.Summary = workspaceResultSkipped, fmt.Sprintf("no changes to lint, set --%s or --%s", migrateLintLatest, migrateLintGitBase)
+		return nil
+	case latest > 0:
+		detect = lint.LatestChanges(dir, latest)
+	default:
+		ld, ok := dir.(interface{ Path() string })
+		if !ok {
+			return fmt.Errorf("git change detection is not supported by %T", dir)
+		}
+		detect, err = lint.NewGitChangeDetector(
+			dir,
+			lint.WithWorkDir(env.Lint.Git.Dir),
+			lint.WithBase(base),
+			lint.WithMigrationsPath(ld.Path()),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	dev, err := pool.Get(ctx, env.DevURL)
+	if err != nil {
+		return err
+	}
+	az, err := sqlcheck.AnalyzerFor(dev.Name, env.Lint.Remain())
+	if err != nil {
+		return err
+	}
+	if s, ok := env.OwnershipScope(); ok {
+		az = append(az, &lint.ScopeAnalyzer{Scope: s})
+	}
+	// Contract files must not be shipped with their expand counterpart.
+	az = append(az, &lint.PhaseAnalyzer{})
+	w := &lintCollector{}
+	vars, err := workspaceVars(env)
+	if err != nil {
+		return err
+	}
+	lr := &lint.Runner{Dev: dev, Dir: dir, ChangeDetector: detect, ReportWriter: w, Analyzers: az, Vars: vars}
+	if lr.Target, err = migrateTarget(ctx, dev); err != nil {
+		return err
+	}
+	if err := lr.Run(ctx); err != nil && !errors.As(err, &lint.SilentError{}) {
+		return err
+	}
+	if w.sum == nil {
+		return errors.New("no lint report was written")
+	}
+	var (
+		failed bool
+		diags  int
+	)
+	for _, f := range w.sum.Files {
+		if f.Error != "" {
+			failed = true
+			r.Details = append(r.Details, fmt.Sprintf("%s: %s", f.Name, f.Error))
+		}
+		for _, rp := range f.Reports {
+			for _, d := range rp.Diagnostics {
+				diags++
+				r.Details = append(r.Details, fmt.Sprintf("%s: %s (%s)", f.Name, d.Text, d.Code))
+			}
+		}
+	}
+	switch {
+	case failed:
+		r.Result = workspaceResultFailed
+	default:
+		r.Result = workspaceResultOK
+	}
+	r.Summary = fmt.Sprintf("%d files analyzed, %d diagnostics", len(w.sum.Files), diags)
+	return nil
+}
+
+func (p *workspaceProject) diff(ctx context.Context, pool *devPool, env *Env, r *workspaceResult) error {
+	srcs, err := env.Sources()
+	if err != nil {
+		return err
+	}
+	if env.DevURL == "" || len(srcs) == 0 {
+		r.Result, r.Summary = workspaceResultSkipped, "no dev database or schema source configured"
+		return nil
+	}
+	for i := range srcs {
+		srcs[i] = p.path(srcs[i])
+	}
+	dir, err := p.migrateDir(env)
+	if err != nil {
+		return err
+	}
+	dev, err := pool.Get(ctx, env.DevURL)
+	if err != nil {
+		return err
+	}
+	desired, err := p.desired(dev, env, srcs)
+	if err != nil {
+		return err
+	}
+	var opts []schema.DiffOption
+	if env.Diff != nil {
+		opts = append(opts, schema.DiffWithPolicy(env.Diff.Policy()))
+	}
+	stmts, err := sqltest.Diff(ctx, dev, dir, desired, opts...)
+	if err != nil {
+		return err
+	}
+	if len(stmts) == 0 {
+		r.Result, r.Summary = workspaceResultOK, "migration directory is synced with the desired state"
+		return nil
+	}
+	r.Result, r.Summary, r.Details = workspaceResultFailed, fmt.Sprintf("migration directory is not synced with the desired state, %d statements are missing", len(stmts)), stmts
+	return nil
+}
+
+// desired returns the state reader of the schema sources of the environment.
+func (p *workspaceProject) desired(dev *sqlclient.Client, env *Env, srcs []string) (migrate.StateReader, error) {
+	for _, s := range srcs {
+		if filepath.Ext(s) == ".sql" {
+			return sqltest.SQLPaths(dev, srcs...), nil
+		}
+	}
+	input, err := env.asMap()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.StateReaderFunc(func(ctx context.Context) (*schema.Realm, error) {
+		parsed, err := parseHCLPaths(srcs...)
+		if err != nil {
+			return nil, err
+		}
+		realm := &schema.Realm{}
+		if err := dev.Eval(parsed, realm, input); err != nil {
+			return nil, err
+		}
+		if norm, ok := dev.Driver.(schema.Normalizer); ok && len(realm.Schemas) > 0 {
+			return norm.NormalizeRealm(ctx, realm)
+		}
+		return realm, nil
+	}), nil
+}
+
+func (p *workspaceProject) status(ctx context.Context, env *Env, r *workspaceResult) error {
+	if env.URL == "" {
+		r.Result, r.Summary = workspaceResultSkipped, "no database url configured"
+		return nil
+	}
+	dir, err := p.migrateDir(env)
+	if err != nil {
+		return err
+	}
+	client, err := openURL(ctx, env.URL, env.Session, workspaceAuditLog(env))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	pending, revs, err := migrationStatus(ctx, client, dir, revisionSchema(env, client))
+	if err != nil {
+		return err
+	}
+	current := "No migration applied yet"
+	if len(revs) > 0 {
+		current = revs[len(revs)-1].Version
+	}
+	r.Result = workspaceResultOK
+	r.Summary = fmt.Sprintf("current version: %s, %d pending files", current, len(pending))
+	for _, f := range pending {
+		r.Details = append(r.Details, f.Name())
+	}
+	return nil
+}
+
+// workspaceVars returns the variables for rendering the migration files of the
+// project env. Variables given using the --var flag take precedence over the env ones.
+func workspaceVars(env *Env) (map[string]string, error) {
+	vars, err := env.asMap()
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range GlobalFlags.Vars {
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+func (p *workspaceProject) apply(ctx context.Context, out io.Writer, env *Env, r *workspaceResult) error {
+	if env.URL == "" {
+		r.Result, r.Summary = workspaceResultSkipped, "no database url configured"
+		return nil
+	}
+	dir, err := p.migrateDir(env)
+	if err != nil {
+		return err
+	}
+	c, err := openURL(ctx, env.URL, env.Session, workspaceAuditLog(env))
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	unlock, err := lockDatabase(ctx, out, c, "atlas_migrate_execute")
+	if err != nil {
+		return err
+	}
+	// If unlocking fails notify the user about it.
+	defer func() { cobra.CheckErr(unlock()) }()
+	revSchema := revisionSchema(env, c)
+	rrw, err := entmigrate.NewEntRevisions(ctx, c, entmigrate.WithSchema(revSchema))
+	if err != nil {
+		return err
+	}
+	if err := rrw.Migrate(ctx); err != nil {
+		return err
+	}
+	target, err := migrateTarget(ctx, c)
+	if err != nil {
+		return err
+	}
+	target.Env = env.Name
+	v, _ := parse(version)
+	l := &LogTTY{out: out}
+	vars, err := workspaceVars(env)
+	if err != nil {
+		return err
+	}
+	opts := []migrate.ExecutorOption{
+		migrate.WithLogger(l),
+		migrate.WithOperatorVersion("Atlas CLI - " + v),
+		migrate.WithTarget(target),
+		migrate.WithTemplateVars(vars),
+	}
+	ex, err := migrate.NewExecutor(c.Driver, dir, rrw, opts...)
+	if err != nil {
+		return err
+	}
+	pending, err := ex.Pending(ctx)
+	switch {
+	case errors.Is(err, migrate.ErrNoPendingFiles):
+		r.Result, r.Summary = workspaceResultOK, "no migration files to execute"
+		return nil
+	case err != nil:
+		return err
+	}
+	revs, err := rrw.ReadRevisions(ctx)
+	if err != nil {
+		return err
+	}
+	if err := migrate.LogIntro(l, revs, pending); err != nil {
+		return err
+	}
+	mux := &tx{c: c, rrw: rrw, mode: txModeFile, dryRun: WorkspaceFlags.Apply.DryRun, revSchema: revSchema}
+	if _, err := mux.execute(ctx, dir, opts, pending, 0, nil); err != nil {
+		return err
+	}
+	l.Log(migrate.LogDone{})
+	r.Result, r.Summary = workspaceResultOK, fmt.Sprintf("%d migration files applied, version %s", len(pending), pending[len(pending)-1].Version())
+	if WorkspaceFlags.Apply.DryRun {
+		r.Summary = fmt.Sprintf("%d migration files to apply (dry run), version %s", len(pending), pending[len(pending)-1].Version())
+	}
+	for _, f := range pending {
+		r.Details = append(r.Details, f.Name())
+	}
+	return nil
+}
+
+// workspaceAuditLog returns the audit log of the project environment.
+// The --audit-log flag takes precedence over the project files.
+func workspaceAuditLog(env *Env) string {
+	if GlobalFlags.AuditLog != "" {
+		return GlobalFlags.AuditLog
+	}
+	return env.AuditLog
+}
+
+// revisionSchema returns the schema of the revisions table of the environment.
+func revisionSchema(env *Env, c *sqlclient.Client) string {
+	switch {
+	case env.Migration.RevisionsSchema != "":
+		return env.Migration.RevisionsSchema
+	case c.URL.Schema != "":
+		return c.URL.Schema
+	default:
+		return defaultRevisionSchema
+	}
+}
+
+// workspaceReport writes the results of the workspace checks, and
+// returns an error if any of them failed.
+func workspaceReport(cmd *cobra.Command, results []*workspaceResult) error {
+	var failed int
+	for _, r := range results {
+		if r.Result == workspaceResultFailed || r.Result == workspaceResultError {
+			failed++
+		}
+	}
+	out := cmd.OutOrStdout()
+	switch WorkspaceFlags.Format {
+	case workspaceFormatJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	default:
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROJECT\tENV\tCHECK\tRESULT\tSUMMARY")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Project, r.Env, r.Check, r.Result, r.Summary)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		for _, r := range results {
+			if len(r.Details) == 0 {
+				continue
+			}
+			fmt.Fprintf(out, "\n%s (%s) %s:\n", r.Project, r.Env, r.Check)
+			for _, d := range r.Details {
+				fmt.Fprintf(out, "  %s\n", d)
+			}
+		}
+		fmt.Fprintf(out, "\n%d checks, %d failed\n", len(results), failed)
+	}
+	if failed > 0 {
+		// Failures are already reported in the output.
+		cmd.SilenceErrors = true
+		return errors.New("workspace checks failed")
+	}
+	return nil
+}
+
+// Get returns the dev database client of the given URL,
+// and opens it if it was not opened before.
+func (p *devPool) Get(ctx context.Context, url string) (*sqlclient.Client, error) {
+	if c, ok := p.clients[url]; ok {
+		return c, nil
+	}
+	c, err := openDevClient(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[url] = c
+	return c, nil
+}
+
+// Close closes all clients of the pool.
+func (p *devPool) Close() {
+	for _, c := range p.clients {
+		c.Close()
+	}
+}
+
+// lintCollector is a lint.ReportWriter that collects the summary report.
+type lintCollector struct {
+	sum *lint.SummaryReport
+}
+
+// WriteReport implements lint.ReportWriter.
+func (c *lintCollector) WriteReport(r *lint.SummaryReport) error {
+	c.sum = r
+	return nil
+}
@@ -0,0 +1,219 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	usersSchema = `
+schema "main" {}
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+}
+`
+	invoicesSchema = `
+schema "main" {}
+table "invoices" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+  column "total" {
+    type = int
+  }
+}
+`
+)
+
+func TestWorkspace(t *testing.T) {
+	t.Cleanup(resetWorkspaceFlags)
+	root := t.TempDir()
+	writeProject(t, root, "users", "", usersSchema, "CREATE TABLE `users` (`id` int NOT NULL);\n")
+	writeProject(t, root, "services/billing", "", invoicesSchema, "CREATE TABLE `invoices` (`id` int NOT NULL);\n", "DROP TABLE `invoices`;\n")
+	// Hidden directories are skipped.
+	writeProject(t, root, ".git/ignored", "", "")
+
+	s, err := runCmd(Root, "workspace", "list", "--root", root)
+	require.NoError(t, err)
+	require.Equal(t, "PROJECT           ENVS       DEPENDS ON\nservices/billing  local, ci  \nusers             local, ci  \n", s)
+
+	s, err = runCmd(Root, "workspace", "list", "--root", root, "--env", "local", "--format", "json")
+	require.NoError(t, err)
+	var projects []*workspaceProject
+	require.NoError(t, json.Unmarshal([]byte(s), &projects))
+	require.Len(t, projects, 2)
+	require.Equal(t, []string{"local"}, projects[0].Envs)
+
+	_, err = runCmd(Root, "workspace", "run", "unknown", "--root", root)
+	require.EqualError(t, err, `invalid argument "unknown" for "atlas workspace run"`)
+
+	s, err = runCmd(Root, "workspace", "run", "--root", root, "--env", "local", "--format", "json")
+	require.EqualError(t, err, "workspace checks failed")
+	var results []*workspaceResult
+	require.NoError(t, json.Unmarshal([]byte(s), &results))
+	require.Len(t, results, 6)
+	for _, r := range results {
+		require.Equal(t, "local", r.Env)
+	}
+	require.Equal(t, &workspaceResult{Project: "services/billing", Env: "local", Check: "lint", Result: "failed", Summary: "1 files analyzed, 1 diagnostics", Details: results[0].Details}, results[0])
+	require.Equal(t, []string{"2.sql: destructive changes detected", "2.sql: Dropping table \"invoices\" (DS102)"}, results[0].Details)
+	require.Equal(t, "diff", results[1].Check)
+	require.Equal(t, "failed", results[1].Result)
+	require.Len(t, results[1].Details, 1)
+	require.Equal(t, &workspaceResult{Project: "services/billing", Env: "local", Check: "status", Result: "ok", Summary: "current version: No migration applied yet, 2 pending files", Details: []string{"1.sql", "2.sql"}}, results[2])
+	require.Equal(t, &workspaceResult{Project: "users", Env: "local", Check: "lint", Result: "ok", Summary: "1 files analyzed, 0 diagnostics"}, results[3])
+	require.Equal(t, &workspaceResult{Project: "users", Env: "local", Check: "diff", Result: "ok", Summary: "migration directory is synced with the desired state"}, results[4])
+	require.Equal(t, "ok", results[5].Result)
+
+	// Envs without configuration are skipped.
+	s, err = runCmd(Root, "workspace", "run", "lint", "diff", "--root", filepath.Join(root, "users"), "--env", "ci", "--format", "text")
+	require.NoError(t, err)
+	require.Contains(t, s, ".        ci   lint   skipped  no changes to lint, set --latest or --git-base\n")
+	require.Contains(t, s, ".        ci   diff   skipped  no dev database or schema source configured\n")
+	require.Contains(t, s, "\n2 checks, 0 failed\n")
+
+	// Default lint configuration.
+	s, err = runCmd(Root, "workspace", "run", "lint", "--root", filepath.Join(root, "users"), "--env", "ci", "--format", "text", "--latest", "1")
+	require.NoError(t, err)
+	require.Contains(t, s, ".        ci   lint   ok      1 files analyzed, 0 diagnostics\n")
+
+	_, err = runCmd(Root, "workspace", "run", "--root", t.TempDir())
+	require.ErrorContains(t, err, "no projects found in")
+}
+
+func TestWorkspace_Apply(t *testing.T) {
+	t.Cleanup(resetWorkspaceFlags)
+	root := t.TempDir()
+	// The billing service references the users table, owned by the shared project.
+	writeProject(t, root, "services/billing", `workspace {
+  depends_on = ["../../shared"]
+}`, "", "CREATE TABLE `invoices` (`id` int NOT NULL, `user_id` int REFERENCES `users` (`id`));\n")
+	writeProject(t, root, "shared", "", "", "CREATE TABLE `users` (`id` int NOT NULL PRIMARY KEY);\n")
+	writeProject(t, root, "services/audit", "", "", "CREATE TABLE `audit` (`id` int NOT NULL);\n")
+
+	s, err := runCmd(Root, "workspace", "list", "--root", root, "--env", "local")
+	require.NoError(t, err)
+	require.Equal(t, "PROJECT           ENVS   DEPENDS ON\nservices/audit    local  \nservices/billing  local  shared\nshared            local  \n", s)
+
+	s, err = runCmd(Root, "workspace", "apply", "--root", root, "--env", "local", "--format", "json", "--dry-run")
+	require.NoError(t, err)
+	var results []*workspaceResult
+	require.NoError(t, json.Unmarshal([]byte(s), &results))
+	require.Equal(t, []*workspaceResult{
+		{Project: "services/audit", Env: "local", Check: "apply", Result: "ok", Summary: "1 migration files to apply (dry run), version 1", Details: []string{"1.sql"}},
+		{Project: "shared", Env: "local", Check: "apply", Result: "ok", Summary: "1 migration files to apply (dry run), version 1", Details: []string{"1.sql"}},
+		{Project: "services/billing", Env: "local", Check: "apply", Result: "ok", Summary: "1 migration files to apply (dry run), version 1", Details: []string{"1.sql"}},
+	}, results)
+
+	s, err = runCmd(Root, "workspace", "apply", "--root", root, "--env", "local", "--format", "text", "--dry-run=false")
+	require.NoError(t, err)
+	require.Contains(t, s, "Applying project \"shared\" (env \"local\")\nMigrating to version 1 (1 migrations in total):")
+	require.Contains(t, s, "services/billing  local  apply  ok      1 migration files applied, version 1\n")
+	require.Contains(t, s, "\n3 checks, 0 failed\n")
+
+	s, err = runCmd(Root, "workspace", "apply", "--root", root, "--env", "local", "--format", "text")
+	require.NoError(t, err)
+	require.Contains(t, s, "shared            local  apply  ok      no migration files to execute\n")
+
+	// Dependents of failed projects are skipped.
+	root = t.TempDir()
+	writeProject(t, root, "a", `workspace {
+  depends_on = ["../b"]
+}`, "", "CREATE TABLE `a` (`id` int);\n")
+	writeProject(t, root, "b", `workspace {
+  depends_on = ["../c"]
+}`, "", "CREATE TABLE `b` (`id` int);\n")
+	writeProject(t, root, "c", "", "", "CREATE TABLE `c` (`id` int);\n", "INVALID STATEMENT;\n")
+	s, err = runCmd(Root, "workspace", "apply", "--root", root, "--env", "local", "--format", "json")
+	require.EqualError(t, err, "workspace checks failed")
+	results = nil
+	require.NoError(t, json.Unmarshal([]byte(s), &results))
+	require.Len(t, results, 3)
+	require.Equal(t, "c", results[0].Project)
+	require.Equal(t, "error", results[0].Result)
+	require.Equal(t, &workspaceResult{Project: "b", Env: "local", Check: "apply", Result: "skipped", Summary: `dependency "c" failed to apply`}, results[1])
+	require.Equal(t, &workspaceResult{Project: "a", Env: "local", Check: "apply", Result: "skipped", Summary: `dependency "b" failed to apply`}, results[2])
+
+	// Cycles and unknown dependencies.
+	writeProject(t, root, "c", `workspace {
+  depends_on = ["../a"]
+}`, "", "CREATE TABLE `c` (`id` int);\n")
+	_, err = runCmd(Root, "workspace", "apply", "--root", root, "--env", "local")
+	require.EqualError(t, err, "dependency cycle between projects: a -> b -> c -> a")
+	writeProject(t, root, "c", `workspace {
+  depends_on = ["../d"]
+}`, "", "CREATE TABLE `c` (`id` int);\n")
+	_, err = runCmd(Root, "workspace", "apply", "--root", root, "--env", "local")
+	require.EqualError(t, err, `project "c" depends on "d", which is not a project in the workspace`)
+}
+
+func TestDevPool(t *testing.T) {
+	ctx := context.Background()
+	p := &devPool{clients: make(map[string]*sqlclient.Client)}
+	defer p.Close()
+	c1, err := p.Get(ctx, "sqlite://pool1?mode=memory&cache=shared")
+	require.NoError(t, err)
+	c2, err := p.Get(ctx, "sqlite://pool1?mode=memory&cache=shared")
+	require.NoError(t, err)
+	require.True(t, c1 == c2, "clients with the same URL should be shared")
+	c3, err := p.Get(ctx, "sqlite://pool2?mode=memory&cache=shared")
+	require.NoError(t, err)
+	require.False(t, c1 == c3)
+	require.Len(t, p.clients, 2)
+}
+
+func resetWorkspaceFlags() {
+	WorkspaceFlags.Root, WorkspaceFlags.Format, GlobalFlags.SelectedEnv = ".", workspaceFormatText, ""
+	WorkspaceFlags.Lint.Latest, WorkspaceFlags.Lint.GitBase = 0, ""
+	WorkspaceFlags.Apply.DryRun = false
+}
+
+// writeProject writes a project with a "local" env that has a schema, a migration directory
+// and a database, and a "ci" env that has only a dev database, into the given path of root.
+func writeProject(t *testing.T, root, path, extra, schema string, stmts ...string) {
+	dev := "sqlite://workspace?mode=memory&cache=shared&_fk=1"
+	p := filepath.Join(root, path)
+	require.NoError(t, os.MkdirAll(filepath.Join(p, "migrations"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(p, projectFileName), []byte(fmt.Sprintf(`
+env "local" {
+  src = "schema.hcl"
+  dev = %q
+  url = "sqlite://file:%s?cache=shared&_fk=1"
+  lint {
+    latest = 1
+  }
+}
+
+env "ci" {
+  dev = %q
+}
+
+%s
+`, dev, filepath.Join(p, "local.db"), dev, extra)), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(p, "schema.hcl"), []byte(schema), 0600))
+	d, err := migrate.NewLocalDir(filepath.Join(p, "migrations"))
+	require.NoError(t, err)
+	for i, s := range stmts {
+		require.NoError(t, d.WriteFile(fmt.Sprintf("%d.sql", i+1), []byte(s)))
+	}
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+}
@@ -7,6 +7,7 @@ package docker
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io"
@@ -30,7 +31,8 @@ const pass = "pass"
 type (
 	// Config is used to configure container creation.
 	Config struct {
-		setup []string // contains statements to execute once the service is up
+		setup  []string // contains statements to execute once the service is up
+		schema string   // optional schema to create and connect to
 		// Image is the name of the image to pull and run.
 		Image string
 		// Env vars to pass to the docker container.
@@ -42,8 +44,9 @@ type (
 	}
 	// A Container is an instance of a created container.
 	Container struct {
-		cfg Config    // Config used to create this container
-		out io.Writer // custom write to log status messages to
+		cfg     Config       // Config used to create this container
+		out     io.Writer    // custom write to log status messages to
+		release func() error // releases pooled containers instead of stopping them
 		// ID of the container.
 		ID string
 		// Passphrase of the root user.
@@ -143,6 +146,16 @@ func Out(w io.Writer) ConfigOption {
 	}
 }
 
+// Schema sets the schema to create once the service is ready, and to connect to. For example:
+//
+//	MySQL("8", Schema("dev"))
+func Schema(s string) ConfigOption {
+	return func(c *Config) error {
+		c.schema = s
+		return nil
+	}
+}
+
 // setup adds statements to execute once the service is ready. For example:
 //
 //	setup("DROP SCHEMA IF EXISTS public CASCADE;")
@@ -159,6 +172,11 @@ func (c *Config) Run(ctx context.Context) (*Container, error) {
 	if err := c.validate(); err != nil {
 		return nil, err
 	}
+	return c.run(ctx)
+}
+
+// run pulls and starts a new docker container with the given extra arguments.
+func (c *Config) run(ctx context.Context, extra ...string) (*Container, error) {
 	// Get a free host TCP port the container can bind its exposed service port on.
 	p, err := freePort()
 	if err != nil {
@@ -175,6 +193,7 @@ func (c *Config) Run(ctx context.Context) (*Container, error) {
 	for _, e := range c.Env {
 		args = append(args, "-e", e)
 	}
+	args = append(args, extra...)
 	args = append(args, "-p", fmt.Sprintf("%s:%s", p, c.Port), c.Image)
 	cmd = exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec
 	out := &bytes.Buffer{}
@@ -191,8 +210,12 @@ func (c *Config) Run(ctx context.Context) (*Container, error) {
 	}, nil
 }
 
-// Close stops and removes this container.
+// Close stops and removes this container. Pooled containers are
+// kept running, and are released back to the pool instead.
 func (c *Container) Close() error {
+	if c.release != nil {
+		return c.release()
+	}
 	return exec.Command("docker", "stop", c.ID).Run() //nolint:gosec
 }
 
@@ -206,7 +229,7 @@ func (c *Container) Wait(ctx context.Context, timeout time.Duration) error {
 	}
 	var (
 		done   = time.After(timeout)
-		u, err = c.URL()
+		u, err = c.url("")
 	)
 	if err != nil {
 		return err
@@ -222,7 +245,12 @@ func (c *Container) Wait(ctx context.Context, timeout time.Duration) error {
 			if err := db.PingContext(ctx); err != nil {
 				continue
 			}
-			for _, s := range c.cfg.setup {
+			stmts, err := c.prepare(ctx, db)
+			if err != nil {
+				_ = db.Close()
+				return err
+			}
+			for _, s := range append(stmts, c.cfg.setup...) {
 				if _, err := db.ExecContext(ctx, s); err != nil {
 					_ = db.Close()
 					return fmt.Errorf("%q: %w", s, err)
@@ -241,16 +269,90 @@ func (c *Container) Wait(ctx context.Context, timeout time.Duration) error {
 
 // URL returns a URL to connect to the Container.
 func (c *Container) URL() (string, error) {
-	switch img := strings.SplitN(c.cfg.Image, ":", 2)[0]; img {
+	return c.url(c.cfg.schema)
+}
+
+// url returns a URL to connect to the Container, scoped to the given schema, if not empty.
+func (c *Container) url(schema string) (string, error) {
+	switch img := c.cfg.family(); img {
 	case "postgres":
-		return fmt.Sprintf("postgres://postgres:%s@localhost:%s/postgres?sslmode=disable", c.Passphrase, c.Port), nil
+		u := fmt.Sprintf("postgres://postgres:%s@localhost:%s/postgres?sslmode=disable", c.Passphrase, c.Port)
+		if schema != "" {
+			u += "&search_path=" + url.QueryEscape(schema)
+		}
+		return u, nil
 	case "mysql", "mariadb":
-		return fmt.Sprintf("%s://root:%s@localhost:%s/", img, c.Passphrase, c.Port), nil
+		return fmt.Sprintf("%s://root:%s@localhost:%s/%s", img, c.Passphrase, c.Port, schema), nil
 	default:
 		return "", fmt.Errorf("unknown container image: %q", img)
 	}
 }
 
+// prepare returns the statements for bringing the database of the container to a clean
+// state. Pooled containers might have been left dirty by previous commands, and therefore,
+// all their user schemas are dropped before creating the schema of the configuration.
+func (c *Container) prepare(ctx context.Context, db *sql.DB) ([]string, error) {
+	var (
+		stmts []string
+		query string
+		fam   = c.cfg.family()
+	)
+	switch fam {
+	case "postgres":
+		query = "SELECT nspname FROM pg_namespace WHERE nspname <> 'information_schema' AND nspname NOT LIKE 'pg\\_%'"
+	case "mysql", "mariadb":
+		query = "SELECT `SCHEMA_NAME` FROM `INFORMATION_SCHEMA`.`SCHEMATA` WHERE `SCHEMA_NAME` NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')"
+	default:
+		return nil, fmt.Errorf("unknown container image: %q", fam)
+	}
+	if c.release != nil {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("querying schemas: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, dropSchema(fam, name))
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		// The default schema of PostgreSQL is expected to exist.
+		if fam == "postgres" {
+			stmts = append(stmts, createSchema(fam, "public"))
+		}
+	}
+	if s := c.cfg.schema; s != "" {
+		stmts = append(stmts, createSchema(fam, s))
+	}
+	return stmts, nil
+}
+
+// dropSchema returns the statement for dropping the given schema.
+func dropSchema(fam, name string) string {
+	if fam == "postgres" {
+		return fmt.Sprintf("DROP SCHEMA IF EXISTS %q CASCADE", name)
+	}
+	return fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", name)
+}
+
+// createSchema returns the statement for creating the given schema, if it does not exist.
+func createSchema(fam, name string) string {
+	if fam == "postgres" {
+		return fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %q", name)
+	}
+	return fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", name)
+}
+
+// family returns the name of the image without its tag.
+func (c *Config) family() string {
+	return strings.SplitN(c.Image, ":", 2)[0]
+}
+
 // validate that no empty values are given.
 func (c *Config) validate() error {
 	if c == nil || c.Image == "" || c.Port == "" || c.Out == nil {
@@ -278,27 +380,58 @@ func init() {
 	sqlclient.Register("docker", sqlclient.OpenerFunc(client))
 }
 
-func client(ctx context.Context, u *url.URL) (client *sqlclient.Client, err error) {
-	var cfg *Config
-	switch img, tag := u.Host, strings.TrimPrefix(u.Path, "/"); img {
+// config returns the container configuration of the given URL, and reports if the
+// container should be acquired from the pool. The URL format is as follows:
+//
+//	docker://<image>[/<tag>[/<schema>]][?reuse=true]
+func config(u *url.URL) (*Config, bool, error) {
+	var (
+		err   error
+		cfg   *Config
+		parts = strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+		tag   = parts[0]
+		opts  []ConfigOption
+	)
+	if len(parts) == 2 && parts[1] != "" {
+		opts = append(opts, Schema(parts[1]))
+	}
+	if u.Query().Has("v") || u.Query().Has("verbose") {
+		opts = append(opts, Out(os.Stdout))
+	}
+	switch img := u.Host; img {
 	case "mysql":
-		cfg, err = MySQL(tag)
+		cfg, err = MySQL(tag, opts...)
 	case "mariadb":
-		cfg, err = MariaDB(tag)
+		cfg, err = MariaDB(tag, opts...)
 	case "postgres":
-		cfg, err = PostgreSQL(tag)
+		cfg, err = PostgreSQL(tag, opts...)
 	default:
-		return nil, fmt.Errorf("unsupported docker image %q", img)
+		return nil, false, fmt.Errorf("unsupported docker image %q", img)
 	}
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	if u.Query().Has("v") || u.Query().Has("verbose") {
-		if err := Out(os.Stdout)(cfg); err != nil {
-			return nil, err
+	var reuse bool
+	if u.Query().Has("reuse") {
+		v := u.Query().Get("reuse")
+		if reuse, err = strconv.ParseBool(v); v != "" && err != nil {
+			return nil, false, fmt.Errorf("invalid reuse parameter %q: %w", v, err)
 		}
+		reuse = reuse || v == ""
+	}
+	return cfg, reuse, nil
+}
+
+func client(ctx context.Context, u *url.URL) (client *sqlclient.Client, err error) {
+	cfg, reuse, err := config(u)
+	if err != nil {
+		return nil, err
+	}
+	run := cfg.Run
+	if reuse {
+		run = cfg.Acquire
 	}
-	c, err := cfg.Run(ctx)
+	c, err := run(ctx)
 	if err != nil {
 		return nil, err
 	}
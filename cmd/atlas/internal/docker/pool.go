@@ -0,0 +1,166 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// poolLabel is the label of pooled containers, set to their pool key.
+	poolLabel = "io.atlasgo.pool"
+	// poolSize is the maximum number of pooled containers per key.
+	poolSize = 4
+	// lockTTL is the duration after which the lock of a pooled container
+	// is considered abandoned (e.g. by a killed process), and is released.
+	lockTTL = time.Hour
+)
+
+var (
+	// poolDir holds the lock files of the pooled containers.
+	poolDir = filepath.Join(os.TempDir(), "atlas-docker-pool")
+	// errPoolBusy is returned by lockSlot in case all slots are in use.
+	errPoolBusy = errors.New("docker: all pooled containers are in use")
+)
+
+// PoolKey returns the key of the pool the containers of this configuration are
+// acquired from. Containers are pooled by their image and schema, as containers
+// created from the same configuration are interchangeable once they are cleaned.
+func (c *Config) PoolKey() string {
+	h := sha256.Sum256([]byte(c.Image + "\x00" + c.schema))
+	return fmt.Sprintf("%x", h[:6])
+}
+
+// Acquire returns a container from the pool of containers created from this Config,
+// and starts a new one in case there is no pooled container to reuse. Pooled containers
+// are kept running when they are closed, and are cleaned once they are ready (see Wait),
+// to avoid paying the startup cost of the database on every invocation. For example:
+//
+//	cfg, _ := MySQL("8", Schema("dev"))
+//	c, _ := cfg.Acquire(ctx)
+//	defer c.Close() // Releases c back to the pool.
+//
+// In case all pooled containers are in use, an ephemeral container is started instead.
+// Pooled containers can be stopped using Prune.
+func (c *Config) Acquire(ctx context.Context) (*Container, error) {
+	// Make sure the configuration is not missing critical values.
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	key := c.PoolKey()
+	slot, release, err := lockSlot(key)
+	switch {
+	case errors.Is(err, errPoolBusy):
+		return c.run(ctx)
+	case err != nil:
+		return nil, err
+	}
+	name := fmt.Sprintf("atlas-%s-%d", key, slot)
+	ct, err := c.running(ctx, name)
+	if err == nil && ct == nil {
+		ct, err = c.run(ctx, "--name", name, "--label", poolLabel+"="+key)
+	}
+	if err != nil {
+		if rerr := release(); rerr != nil {
+			err = fmt.Errorf("%w: %v", err, rerr)
+		}
+		return nil, err
+	}
+	ct.release = release
+	return ct, nil
+}
+
+// running returns the running container with the given name, or nil if there is no such container.
+func (c *Config) running(ctx context.Context, name string) (*Container, error) {
+	out, err := exec.CommandContext(ctx, "docker", "port", name, c.Port+"/tcp").Output() //nolint:gosec
+	if err != nil {
+		// Container does not exist or is not running.
+		return nil, nil
+	}
+	p, err := hostPort(string(out))
+	if err != nil {
+		return nil, err
+	}
+	return &Container{
+		cfg:        *c,
+		ID:         name,
+		Passphrase: pass,
+		Port:       p,
+		out:        c.Out,
+	}, nil
+}
+
+// hostPort extracts the host port from the output of 'docker port'. For example:
+//
+//	0.0.0.0:49153
+//	[::]:49153
+func hostPort(out string) (string, error) {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(out), "\n", 2)[0])
+	i := strings.LastIndexByte(line, ':')
+	if i == -1 {
+		return "", fmt.Errorf("docker: unexpected port mapping %q", line)
+	}
+	if _, err := strconv.Atoi(line[i+1:]); err != nil {
+		return "", fmt.Errorf("docker: unexpected port mapping %q", line)
+	}
+	return line[i+1:], nil
+}
+
+// lockSlot locks a free slot of the pool with the given key, and returns
+// its number and a function for releasing it. Slots are locked using lock
+// files, to guard pooled containers from concurrent invocations.
+func lockSlot(key string) (int, func() error, error) {
+	if err := os.MkdirAll(poolDir, 0755); err != nil {
+		return 0, nil, fmt.Errorf("docker: creating pool directory: %w", err)
+	}
+	for i := 0; i < poolSize; i++ {
+		name := filepath.Join(poolDir, fmt.Sprintf("%s-%d.lock", key, i))
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		// Lock files of crashed processes are released once they expire.
+		if errors.Is(err, fs.ErrExist) {
+			if st, serr := os.Stat(name); serr == nil && time.Since(st.ModTime()) > lockTTL && os.Remove(name) == nil {
+				f, err = os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			}
+		}
+		switch {
+		case errors.Is(err, fs.ErrExist):
+			continue
+		case err != nil:
+			return 0, nil, fmt.Errorf("docker: locking pooled container: %w", err)
+		}
+		_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("docker: locking pooled container: %w", err)
+		}
+		return i, func() error { return os.Remove(name) }, nil
+	}
+	return 0, nil, errPoolBusy
+}
+
+// Prune stops and removes all pooled containers.
+func Prune(ctx context.Context) error {
+	out, err := exec.CommandContext(ctx, "docker", "ps", "--quiet", "--filter", "label="+poolLabel).Output()
+	if err != nil {
+		return fmt.Errorf("docker: listing pooled containers: %w", err)
+	}
+	ids := strings.Fields(string(out))
+	if len(ids) == 0 {
+		return nil
+	}
+	return exec.CommandContext(ctx, "docker", append([]string{"stop"}, ids...)...).Run() //nolint:gosec
+}
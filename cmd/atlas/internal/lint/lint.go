@@ -166,6 +166,20 @@ func (d *latestChange) DetectChanges(context.Context) ([]migrate.File, []migrate
 type DevLoader struct {
 	// Dev environment used as a sandbox instantiated to the starting point (e.g. base branch).
 	Dev *sqlclient.Client
+
+	// Target is used for evaluating the conditional directives of statements
+	// (e.g. atlas:only env=prod). Statements that do not match it are not replayed.
+	// If nil, all statements are replayed.
+	Target *migrate.Target
+
+	// State is an optional cached state of the first base files. If it matches the
+	// base files, it is restored on the dev database instead of replaying them.
+	State *ReplayState
+
+	// Vars holds the input variables for rendering the statements of the migration
+	// files before they are executed (see migrate.WithTemplateVars). If nil, only
+	// files marked with the atlas:template directive are rendered.
+	Vars map[string]string
 }
 
 // LoadChanges implements the ChangesLoader interface.
@@ -176,13 +190,9 @@ func (d *DevLoader) LoadChanges(ctx context.Context, base, files []migrate.File)
 	}
 	defer unlock()
 	// Clean up after ourselves.
-	snap, ok := d.Dev.Driver.(migrate.Snapshoter)
-	if !ok {
-		return nil, errors.New("driver does not implement migrate.Snapshoter")
-	}
-	restore, err := snap.Snapshot(ctx)
+	restore, err := d.snapshot(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("taking database snapshot: %w", err)
+		return nil, err
 	}
 	defer func() {
 		if err2 := restore(ctx); err2 != nil {
@@ -192,17 +202,16 @@ func (d *DevLoader) LoadChanges(ctx context.Context, base, files []migrate.File)
 			err = err2
 		}
 	}()
+	// Checkpoint files are executed only on new databases, and
+	// represent the state of all files that precede them.
+	fresh := len(base) == 0
+	base = migrate.FilesFromCheckpoint(base)
 	// Bring the dev environment to the base point.
-	for _, f := range base {
-		stmt, err := f.Stmts()
-		if err != nil {
-			return nil, &FileError{File: f.Name(), Err: fmt.Errorf("scanning statements: %w", err)}
-		}
-		for _, s := range stmt {
-			if _, err := d.Dev.ExecContext(ctx, s); err != nil {
-				return nil, &FileError{File: f.Name(), Err: fmt.Errorf("executing statement: %q: %w", s, err)}
-			}
-		}
+	if base, err = d.restoreState(ctx, base); err != nil {
+		return nil, err
+	}
+	if err := d.replay(ctx, base); err != nil {
+		return nil, err
 	}
 	current, err := d.inspect(ctx)
 	if err != nil {
@@ -217,13 +226,21 @@ func (d *DevLoader) LoadChanges(ctx context.Context, base, files []migrate.File)
 			File:   f,
 			Parser: sqlparse.ParserFor(d.Dev.Name),
 		}
-		stmts, err := parseutil.StmtDecls(f)
+		// Existing databases skip checkpoint files.
+		if migrate.IsCheckpoint(f) && (!fresh || i > 0) {
+			continue
+		}
+		stmts, err := d.stmts(f)
 		if err != nil {
-			return nil, &FileError{File: f.Name(), Err: fmt.Errorf("scanning statements: %w", err)}
+			return nil, err
 		}
 		start := current
 		for _, s := range stmts {
-			if _, err := d.Dev.ExecContext(ctx, s.Text); err != nil {
+			text, err := d.render(f, s)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := d.Dev.ExecContext(ctx, text); err != nil {
 				return nil, &FileError{File: f.Name(), Err: fmt.Errorf("executing statement: %w", err)}
 			}
 			target, err := d.inspect(ctx)
@@ -240,6 +257,8 @@ func (d *DevLoader) LoadChanges(ctx context.Context, base, files []migrate.File)
 				Changes: d.mayFix(s.Text, changes),
 			})
 		}
+		// Constraints that were re-created across statements are reported as modified.
+		sqlparse.FixChecks(diff.Files[i].Changes)
 		if diff.Files[i].Sum, err = d.Dev.RealmDiff(start, current); err != nil {
 			return nil, err
 		}
@@ -248,6 +267,79 @@ func (d *DevLoader) LoadChanges(ctx context.Context, base, files []migrate.File)
 	return diff, nil
 }
 
+// snapshot takes a snapshot of the dev database, to restore it once the loading is done.
+func (d *DevLoader) snapshot(ctx context.Context) (migrate.RestoreFunc, error) {
+	snap, ok := d.Dev.Driver.(migrate.Snapshoter)
+	if !ok {
+		return nil, errors.New("driver does not implement migrate.Snapshoter")
+	}
+	restore, err := snap.Snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("taking database snapshot: %w", err)
+	}
+	return restore, nil
+}
+
+// replay executes the statements of the given files on the dev database.
+func (d *DevLoader) replay(ctx context.Context, files []migrate.File) error {
+	for _, f := range files {
+		stmts, err := d.stmts(f)
+		if err != nil {
+			return err
+		}
+		for _, s := range stmts {
+			text, err := d.render(f, s)
+			if err != nil {
+				return err
+			}
+			if _, err := d.Dev.ExecContext(ctx, text); err != nil {
+				return &FileError{File: f.Name(), Err: fmt.Errorf("executing statement: %q: %w", text, err)}
+			}
+		}
+	}
+	return nil
+}
+
+// render returns the text of the given statement, rendered with the configured
+// variables, or as is if none were configured and the file is not marked as a
+// template. Undefined variables fail the rendering.
+func (d *DevLoader) render(f migrate.File, s *migrate.Stmt) (string, error) {
+	if d.Vars == nil && !migrate.IsTemplate(f) {
+		return s.Text, nil
+	}
+	text, err := migrate.RenderStmt(f.Name(), s.Text, d.Vars)
+	if err != nil {
+		return "", &FileError{File: f.Name(), Err: fmt.Errorf("rendering statement: %w", err)}
+	}
+	return text, nil
+}
+
+// stmts returns the statements of the given file that should be replayed.
+// Seed files do not change the schema, and therefore, they are not replayed.
+func (d *DevLoader) stmts(f migrate.File) ([]*migrate.Stmt, error) {
+	if migrate.IsSeed(f) {
+		return nil, nil
+	}
+	stmts, err := parseutil.StmtDecls(f)
+	if err != nil {
+		return nil, &FileError{File: f.Name(), Err: fmt.Errorf("scanning statements: %w", err)}
+	}
+	if d.Target == nil {
+		return stmts, nil
+	}
+	matched := make([]*migrate.Stmt, 0, len(stmts))
+	for _, s := range stmts {
+		ok, err := d.Target.Match(s)
+		if err != nil {
+			return nil, &FileError{File: f.Name(), Err: err}
+		}
+		if ok {
+			matched = append(matched, s)
+		}
+	}
+	return matched, nil
+}
+
 // mayFix uses the sqlparse package for fixing or attaching more info to the changes.
 func (d *DevLoader) mayFix(stmt string, changes schema.Changes) schema.Changes {
 	p := sqlparse.ParserFor(d.Dev.Name)
@@ -288,6 +380,122 @@ func (d *DevLoader) lock(ctx context.Context) (schema.UnlockFunc, error) {
 	return unlock, nil
 }
 
+// ScopeAnalyzer is a sqlcheck.Analyzer that reports the statements touching
+// objects outside an ownership scope, and fails the analysis in case there are any.
+type ScopeAnalyzer struct {
+	Scope *schema.Scope
+}
+
+// codeScope is the diagnostic code of the ScopeAnalyzer.
+const codeScope = "OS101"
+
+// Name implements sqlcheck.NamedAnalyzer.
+func (*ScopeAnalyzer) Name() string {
+	return "scope"
+}
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *ScopeAnalyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	var diags []sqlcheck.Diagnostic
+	for _, c := range p.File.Changes {
+		serr := &schema.ScopeError{}
+		if err := a.Scope.Check(c.Changes); errors.As(err, &serr) {
+			diags = append(diags, sqlcheck.Diagnostic{
+				Pos:  c.Stmt.Pos,
+				Text: fmt.Sprintf("Statement touches %s %q that is outside of the ownership scope", serr.Type, serr.Name),
+				Code: codeScope,
+			})
+		}
+	}
+	if len(diags) == 0 {
+		return nil
+	}
+	p.Reporter.WriteReport(sqlcheck.Report{
+		Text:        fmt.Sprintf("Changes outside of the %q ownership scope were detected", a.Scope.Name),
+		Diagnostics: diags,
+	})
+	return fmt.Errorf("changes outside of the %q ownership scope were detected", a.Scope.Name)
+}
+
+// PhaseAnalyzer is a sqlcheck.Analyzer that reports contract files shipped in the
+// same release as their expand counterpart, and fails the analysis in case there
+// are any. A contract file is the counterpart of the expand files that change the
+// same tables. The analyzer is stateful, and expects the files of the release in
+// order. Hence, a new instance should be used for each run.
+type PhaseAnalyzer struct {
+	expanded map[string]string // tables changed by expand files
+}
+
+// codePhase is the diagnostic code of the PhaseAnalyzer.
+const codePhase = "PH101"
+
+// Name implements sqlcheck.NamedAnalyzer.
+func (*PhaseAnalyzer) Name() string {
+	return "phase"
+}
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *PhaseAnalyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	phase, err := migrate.FilePhase(p.File)
+	if err != nil {
+		return err
+	}
+	if a.expanded == nil {
+		a.expanded = make(map[string]string)
+	}
+	var diags []sqlcheck.Diagnostic
+	for _, c := range p.File.Changes {
+		for _, t := range changedTables(c.Changes) {
+			switch name, ok := a.expanded[t]; {
+			case phase == migrate.PhaseExpand && !ok:
+				a.expanded[t] = p.File.Name()
+			case phase == migrate.PhaseContract && ok:
+				diags = append(diags, sqlcheck.Diagnostic{
+					Pos:  c.Stmt.Pos,
+					Text: fmt.Sprintf("Contract statement changes table %q that was expanded in the same release by file %q", t, name),
+					Code: codePhase,
+				})
+			}
+		}
+	}
+	if len(diags) == 0 {
+		return nil
+	}
+	p.Reporter.WriteReport(sqlcheck.Report{
+		Text:        "Contract changes shipped with their expand counterpart were detected",
+		Diagnostics: diags,
+	})
+	return errors.New("contract changes must be shipped in a later release than their expand counterpart")
+}
+
+// changedTables returns the qualified names of the tables changed by the given changes.
+func changedTables(changes schema.Changes) []string {
+	var names []string
+	add := func(t *schema.Table) {
+		if t.Schema != nil && t.Schema.Name != "" {
+			names = append(names, t.Schema.Name+"."+t.Name)
+		} else {
+			names = append(names, t.Name)
+		}
+	}
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			add(c.T)
+		case *schema.DropTable:
+			add(c.T)
+		case *schema.ModifyTable:
+			add(c.T)
+		case *schema.RenameTable:
+			add(c.From)
+			add(c.To)
+		case *schema.ModifySchema:
+			names = append(names, changedTables(c.Changes)...)
+		}
+	}
+	return names
+}
+
 // FileError represents an error that occurred while processing a file.
 type FileError struct {
 	File string
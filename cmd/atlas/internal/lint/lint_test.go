@@ -183,6 +183,79 @@ func TestDevLoader_LoadChanges(t *testing.T) {
 	require.ErrorAs(t, err, &migrate.NotCleanError{})
 }
 
+func TestDevLoader_Target(t *testing.T) {
+	ctx := context.Background()
+	c, err := sqlclient.Open(ctx, "sqlite://target?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	defer c.Close()
+	l := &lint.DevLoader{Dev: c, Target: &migrate.Target{Env: "dev", Dialect: "sqlite", Version: "3.39.2"}}
+	base := []migrate.File{
+		migrate.NewLocalFile("base.sql", []byte("CREATE TABLE users (id INT, name TEXT);\n-- atlas:only env=prod\nCREATE TABLE prod (id INT);\n")),
+	}
+	files := []migrate.File{
+		migrate.NewLocalFile("1.sql", []byte("-- atlas:dialect mysql\nALTER TABLE users ADD COLUMN age int INVISIBLE;\n-- atlas:dialect sqlite>=3.35\nALTER TABLE users DROP COLUMN id;\n")),
+	}
+	diff, err := l.LoadChanges(ctx, base, files)
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+	require.Len(t, diff.Files[0].Changes, 1)
+	require.Equal(t, "ALTER TABLE users DROP COLUMN id;", diff.Files[0].Changes[0].Stmt.Text)
+	_, ok := diff.From.Schemas[0].Table("prod")
+	require.False(t, ok)
+
+	l.Target.Env = "prod"
+	diff, err = l.LoadChanges(ctx, base, files)
+	require.NoError(t, err)
+	_, ok = diff.From.Schemas[0].Table("prod")
+	require.True(t, ok)
+}
+
+func TestDevLoader_Seeds(t *testing.T) {
+	ctx := context.Background()
+	c, err := sqlclient.Open(ctx, "sqlite://seeds?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	defer c.Close()
+	l := &lint.DevLoader{Dev: c}
+	base := []migrate.File{
+		migrate.NewLocalFile("1.sql", []byte("CREATE TABLE users (id INT PRIMARY KEY, role_id INT REFERENCES roles (id));\n")),
+		// Seed files that reference data that does not exist on the dev database are not replayed.
+		migrate.NewLocalFile("2.seed.sql", []byte("INSERT INTO users VALUES (1, 1);\n")),
+	}
+	files := []migrate.File{
+		migrate.NewLocalFile("3.sql", []byte("-- atlas:seed\nINSERT INTO users VALUES (2, 2);\n")),
+		migrate.NewLocalFile("4.sql", []byte("ALTER TABLE users ADD COLUMN name TEXT;\n")),
+	}
+	diff, err := l.LoadChanges(ctx, base, files)
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 2)
+	require.Empty(t, diff.Files[0].Changes)
+	require.Len(t, diff.Files[1].Changes, 1)
+}
+
+func TestDevLoader_Vars(t *testing.T) {
+	ctx := context.Background()
+	c, err := sqlclient.Open(ctx, "sqlite://vars?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	defer c.Close()
+	base := []migrate.File{
+		migrate.NewLocalFile("1.sql", []byte("CREATE TABLE {{ var \"table\" }} (id INT PRIMARY KEY);\n")),
+	}
+	files := []migrate.File{
+		migrate.NewLocalFile("2.sql", []byte("ALTER TABLE {{ var \"table\" }} ADD COLUMN name TEXT;\n")),
+	}
+	l := &lint.DevLoader{Dev: c, Vars: map[string]string{"table": "users"}}
+	diff, err := l.LoadChanges(ctx, base, files)
+	require.NoError(t, err)
+	_, ok := diff.From.Schemas[0].Table("users")
+	require.True(t, ok)
+	require.Len(t, diff.Files[0].Changes, 1)
+
+	// Undefined variables fail the loading.
+	l.Vars = map[string]string{}
+	_, err = l.LoadChanges(ctx, base, files)
+	require.ErrorContains(t, err, `variable "table" is not defined`)
+}
+
 type testDir struct {
 	migrate.Dir
 	files []migrate.File
@@ -216,3 +289,68 @@ func (f testFile) Bytes() []byte {
 func (f testFile) Stmts() ([]string, error) {
 	return strings.Split(string(f.Bytes()), "\n"), nil
 }
+
+func TestDevLoader_State(t *testing.T) {
+	ctx := context.Background()
+	c, err := sqlclient.Open(ctx, "sqlite://state?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	defer c.Close()
+	base := []migrate.File{
+		migrate.NewLocalFile("1.sql", []byte("CREATE TABLE users (id INT, name TEXT);\n-- atlas:only env=prod\nCREATE TABLE prod (id INT);\n")),
+		migrate.NewLocalFile("2.sql", []byte("CREATE INDEX users_name ON users (name);\n")),
+	}
+	l := &lint.DevLoader{Dev: c, Target: &migrate.Target{Env: "prod", Dialect: "sqlite"}}
+	state, err := l.DumpState(ctx, base)
+	require.NoError(t, err)
+	require.Equal(t, "sqlite3", state.Driver)
+	require.Len(t, state.Files, 2)
+	require.Contains(t, state.Schema, `table "prod"`)
+	// The dev database is restored after dumping.
+	s, err := c.InspectSchema(ctx, "", nil)
+	require.NoError(t, err)
+	require.Empty(t, s.Tables)
+
+	// Encoding round trip.
+	var buf strings.Builder
+	require.NoError(t, state.Write(&buf))
+	state, err = lint.ReadState(strings.NewReader(buf.String()))
+	require.NoError(t, err)
+	n, ok := state.Prefix(append(base, testFile{name: "3.sql"}))
+	require.True(t, ok)
+	require.Equal(t, 2, n)
+	_, ok = state.Prefix(base[:1])
+	require.False(t, ok)
+
+	// The "prod" table exists only in the cached state, which
+	// means the state was restored instead of replaying the files.
+	l = &lint.DevLoader{Dev: c, Target: &migrate.Target{Env: "dev", Dialect: "sqlite"}, State: state}
+	files := []migrate.File{
+		migrate.NewLocalFile("3.sql", []byte("DROP TABLE prod;\n")),
+	}
+	diff, err := l.LoadChanges(ctx, base, files)
+	require.NoError(t, err)
+	users, ok := diff.From.Schemas[0].Table("users")
+	require.True(t, ok)
+	_, ok = users.Index("users_name")
+	require.True(t, ok)
+	_, ok = diff.From.Schemas[0].Table("prod")
+	require.True(t, ok)
+	require.Len(t, diff.Files[0].Changes, 1)
+
+	// A stale state is ignored.
+	base[1] = migrate.NewLocalFile("2.sql", []byte("CREATE INDEX users_id ON users (id);\n"))
+	_, ok = state.Prefix(base)
+	require.False(t, ok)
+	diff, err = l.LoadChanges(ctx, base, nil)
+	require.NoError(t, err)
+	_, ok = diff.From.Schemas[0].Table("prod")
+	require.False(t, ok)
+
+	// Restore the state explicitly.
+	require.NoError(t, l.RestoreState(ctx, state))
+	s, err = c.InspectSchema(ctx, "", nil)
+	require.NoError(t, err)
+	require.Len(t, s.Tables, 2)
+	state.Driver = "mysql"
+	require.EqualError(t, l.RestoreState(ctx, state), `replay state was created by driver "mysql", but the dev database driver is "sqlite3"`)
+}
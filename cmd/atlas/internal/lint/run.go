@@ -15,6 +15,7 @@ import (
 
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/nolint"
 	"ariga.io/atlas/sql/sqlclient"
 
 	"golang.org/x/exp/slices"
@@ -39,6 +40,18 @@ type Runner struct {
 	// ReportWriter writes the summary report.
 	ReportWriter ReportWriter
 
+	// Target is used for evaluating the conditional directives
+	// of statements when replaying them on the dev database.
+	Target *migrate.Target
+
+	// State is an optional cached state of the base files, used
+	// to avoid replaying them on the dev database. See ReplayState.
+	State *ReplayState
+
+	// Vars holds the input variables for rendering the statements
+	// when replaying them on the dev database. See DevLoader.Vars.
+	Vars map[string]string
+
 	// summary report. reset on each run.
 	sum *SummaryReport
 }
@@ -89,6 +102,13 @@ func (r *Runner) summary(ctx context.Context) error {
 		r.sum.StepResult(stepIntegrityCheck, fmt.Sprintf("File %s is valid", migrate.HashFileName), nil)
 	}
 
+	// Collect the active suppressions of the directory.
+	ss, err := nolint.Suppressions(r.Dir)
+	if err != nil {
+		return err
+	}
+	r.sum.Suppressions = ss
+
 	// Detect new migration files.
 	base, feat, err := r.ChangeDetector.DetectChanges(ctx)
 	if err != nil {
@@ -97,7 +117,7 @@ func (r *Runner) summary(ctx context.Context) error {
 	r.sum.StepResult(stepDetectChanges, fmt.Sprintf("Found %d new migration files (from %d total)", len(feat), len(base)+len(feat)), nil)
 
 	// Load files into changes.
-	l := &DevLoader{Dev: r.Dev}
+	l := &DevLoader{Dev: r.Dev, Target: r.Target, State: r.State, Vars: r.Vars}
 	diff, err := l.LoadChanges(ctx, base, feat)
 	if err != nil {
 		if fr := (&FileError{}); errors.As(err, &fr) {
@@ -197,6 +217,9 @@ type (
 
 		// Files reports. Non-empty in case there are findings.
 		Files []*FileReport `json:"Files,omitempty"`
+
+		// Suppressions lists the atlas:nolint directives of all files in the directory.
+		Suppressions []*nolint.Suppression `json:"Suppressions,omitempty"`
 	}
 
 	// FileReport contains a summary of the analysis of a single file.
@@ -291,6 +314,11 @@ func (f *FileReport) Line(pos int) int {
 	return strings.Count(f.Text[:pos], "\n") + 1
 }
 
+// Location returns the location of a position in the "file:line:column" format.
+func (f *FileReport) Location(pos int) string {
+	return fmt.Sprintf("%s:%s", f.Name, migrate.PositionOf([]byte(f.Text), pos))
+}
+
 // WriteReport implements sqlcheck.ReportWriter.
 func (f *FileReport) WriteReport(r sqlcheck.Report) {
 	f.Reports = append(f.Reports, r)
@@ -305,7 +333,11 @@ func nolintRules(f *sqlcheck.File) *skipRules {
 	s := &skipRules{pos2rules: make(map[int][]string)}
 	for _, c := range f.Changes {
 		for _, d := range c.Stmt.Directive("nolint") {
-			s.pos2rules[c.Stmt.Pos] = append(s.pos2rules[c.Stmt.Pos], strings.Split(d, " ")...)
+			rules := sqlcheck.ParseNoLint(d).Rules
+			if len(rules) == 0 {
+				rules = []string{""}
+			}
+			s.pos2rules[c.Stmt.Pos] = append(s.pos2rules[c.Stmt.Pos], rules...)
 		}
 	}
 	return s
@@ -324,6 +356,13 @@ func (s *skipRules) reporterFor(rw sqlcheck.ReportWriter, az sqlcheck.Analyzer)
 		)
 		for _, d := range r.Diagnostics {
 			switch rules := s.pos2rules[d.Pos]; {
+			// Diagnostics of the nolint analyzer report on the directives
+			// themselves, and therefore cannot be suppressed by them.
+			case ok && az.Name() == "nolint":
+				ds = append(ds, d)
+			// Changes to PII columns can be approved only explicitly.
+			case ok && az.Name() == "pii":
+				ds = append(ds, d)
 			case
 				// A directive without specific classes/codes
 				// (e.g. atlas:nolint) ignore all diagnostics.
@@ -13,8 +13,11 @@ import (
 	"text/template"
 
 	"ariga.io/atlas/cmd/atlas/internal/lint"
+	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/nolint"
 	"ariga.io/atlas/sql/sqlclient"
 
 	"github.com/stretchr/testify/require"
@@ -124,6 +127,110 @@ schema "main" {
 `, b.String())
 }
 
+func TestRunner_Scope(t *testing.T) {
+	ctx := context.Background()
+	b := &bytes.Buffer{}
+	c, err := sqlclient.Open(ctx, "sqlite://scope?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	r := &lint.Runner{
+		Dir: testDir{},
+		Dev: c,
+		ChangeDetector: testDetector{
+			base: []migrate.File{
+				testFile{name: "1.sql", content: "CREATE TABLE users (id INT)"},
+			},
+			feat: []migrate.File{
+				testFile{name: "2.sql", content: "CREATE TABLE billing_accounts (id INT)\nDROP TABLE users"},
+			},
+		},
+		Analyzers: []sqlcheck.Analyzer{
+			&lint.ScopeAnalyzer{Scope: &schema.Scope{Name: "billing", Tables: []string{"billing_*"}}},
+		},
+		ReportWriter: &lint.TemplateWriter{
+			T: lint.DefaultTemplate,
+			W: b,
+		},
+	}
+	require.ErrorAs(t, r.Run(ctx), &lint.SilentError{})
+	require.Equal(t, `2.sql: Changes outside of the "billing" ownership scope were detected:
+
+	L2: Statement touches table "main.users" that is outside of the ownership scope
+
+`, b.String())
+}
+
+func TestRunner_Phase(t *testing.T) {
+	ctx := context.Background()
+	b := &bytes.Buffer{}
+	c, err := sqlclient.Open(ctx, "sqlite://phase?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	r := &lint.Runner{
+		Dir: testDir{},
+		Dev: c,
+		ChangeDetector: testDetector{
+			base: []migrate.File{
+				testFile{name: "1.sql", content: "CREATE TABLE users (id INT, name TEXT);\nCREATE TABLE pets (id INT, name TEXT);"},
+			},
+			feat: []migrate.File{
+				testFile{name: "2.sql", content: "-- atlas:phase expand\nALTER TABLE users ADD COLUMN full_name TEXT;"},
+				testFile{name: "3.sql", content: "-- atlas:phase contract\nALTER TABLE pets DROP COLUMN name;\nALTER TABLE users DROP COLUMN name;"},
+			},
+		},
+		Analyzers: []sqlcheck.Analyzer{
+			&lint.PhaseAnalyzer{},
+		},
+		ReportWriter: &lint.TemplateWriter{
+			T: lint.DefaultTemplate,
+			W: b,
+		},
+	}
+	require.ErrorAs(t, r.Run(ctx), &lint.SilentError{})
+	require.Equal(t, `3.sql: Contract changes shipped with their expand counterpart were detected:
+
+	L3: Contract statement changes table "main.users" that was expanded in the same release by file "2.sql"
+
+`, b.String())
+}
+
+func TestRunner_NoLint(t *testing.T) {
+	ctx := context.Background()
+	b := &bytes.Buffer{}
+	c, err := sqlclient.Open(ctx, "sqlite://nolint?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	az, err := nolint.New(&schemahcl.Resource{
+		Children: []*schemahcl.Resource{{Type: "nolint"}},
+	})
+	require.NoError(t, err)
+	var (
+		base = []migrate.File{
+			migrate.NewLocalFile("1.sql", []byte("CREATE TABLE users (id INT);\nCREATE TABLE pets (id INT);\n")),
+		}
+		feat = []migrate.File{
+			migrate.NewLocalFile("2.sql", []byte("-- atlas:nolint\nDROP TABLE users;\n-- atlas:nolint DS102 -- unused since v2\nDROP TABLE pets;\n")),
+		}
+	)
+	r := &lint.Runner{
+		Dir: testDir{files: append(base, feat...)},
+		Dev: c,
+		ChangeDetector: testDetector{
+			base: base,
+			feat: feat,
+		},
+		Analyzers: []sqlcheck.Analyzer{az},
+		ReportWriter: &lint.TemplateWriter{
+			T: template.Must(template.New("").
+				Funcs(lint.TemplateFuncs).
+				Parse(`{{ range .Files }}{{ json .Reports }}{{ end }}
+{{ json .Suppressions }}`)),
+			W: b,
+		},
+	}
+	require.NoError(t, r.Run(ctx))
+	// Diagnostics of the analyzer cannot be suppressed by the directives they report.
+	require.Equal(t, `[{"Text":"unjustified lint suppressions detected","Diagnostics":[{"Pos":16,"Text":"Directive \"atlas:nolint\" is missing a justification","Code":"NL101"}]}]
+[{"File":"2.sql","Pos":16,"Line":2},{"File":"2.sql","Pos":75,"Line":4,"Rules":["DS102"],"Reason":"unused since v2"}]`, b.String())
+}
+
 type testAnalyzer struct {
 	passes []*sqlcheck.Pass
 }
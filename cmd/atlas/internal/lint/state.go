@@ -0,0 +1,175 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package lint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// A ReplayState holds the state of the dev database after replaying the first files of
+// a migration directory. It allows caching the replay of files that were not changed
+// between runs (e.g. in CI), and replaying only the new files on top of the restored state.
+type ReplayState struct {
+	// Driver that produced the state.
+	Driver string `json:"Driver"`
+	// Files holds the cumulative hashes of the replayed files. A state can be restored
+	// only if these files are also the first files of the directory being replayed.
+	Files migrate.HashFile `json:"Files"`
+	// Schema holds the inspected schema in the driver's HCL format.
+	Schema string `json:"Schema"`
+}
+
+// ReadState reads a ReplayState encoded in JSON from the given reader.
+func ReadState(r io.Reader) (*ReplayState, error) {
+	s := &ReplayState{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, fmt.Errorf("decoding replay state: %w", err)
+	}
+	return s, nil
+}
+
+// Write writes the ReplayState encoded in JSON to the given writer.
+func (s *ReplayState) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Prefix reports the number of leading files the state was created from, if they
+// are the first files of the given list. Otherwise, false is returned.
+func (s *ReplayState) Prefix(files []migrate.File) (int, bool) {
+	if len(s.Files) > len(files) {
+		return 0, false
+	}
+	hs := replayHashes(files[:len(s.Files)])
+	for i := range hs {
+		if hs[i] != s.Files[i] {
+			return 0, false
+		}
+	}
+	return len(hs), true
+}
+
+// DumpState replays the given files on the dev database and returns its state.
+// The dev database is restored to its original state afterwards.
+func (d *DevLoader) DumpState(ctx context.Context, files []migrate.File) (state *ReplayState, err error) {
+	unlock, err := d.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	restore, err := d.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err2 := restore(ctx); err2 != nil {
+			if err != nil {
+				err2 = fmt.Errorf("%w: %v", err, err2)
+			}
+			err = err2
+		}
+	}()
+	files = migrate.FilesFromCheckpoint(files)
+	if err := d.replay(ctx, files); err != nil {
+		return nil, err
+	}
+	current, err := d.inspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := d.Dev.MarshalSpec(current)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling dev database state: %w", err)
+	}
+	return &ReplayState{
+		Driver: d.Dev.Name,
+		Files:  replayHashes(files),
+		Schema: string(spec),
+	}, nil
+}
+
+// RestoreState brings the (clean) dev database to the given state.
+func (d *DevLoader) RestoreState(ctx context.Context, state *ReplayState) error {
+	if state.Driver != d.Dev.Name {
+		return fmt.Errorf("replay state was created by driver %q, but the dev database driver is %q", state.Driver, d.Dev.Name)
+	}
+	p := hclparse.NewParser()
+	if _, diag := p.ParseHCL([]byte(state.Schema), "state.hcl"); diag.HasErrors() {
+		return fmt.Errorf("parsing replay state schema: %w", diag)
+	}
+	desired := &schema.Realm{}
+	if err := d.Dev.Eval(p, desired, nil); err != nil {
+		return fmt.Errorf("evaluating replay state schema: %w", err)
+	}
+	var opts []migrate.PlanOption
+	// In case the dev database is bound to a schema, the state
+	// is restored on it, regardless of the schema name it was
+	// created from.
+	if s := d.Dev.URL.Schema; s != "" {
+		if len(desired.Schemas) > 1 {
+			return fmt.Errorf("replay state contains %d schemas, but the dev database is bound to schema %q", len(desired.Schemas), s)
+		}
+		for _, ds := range desired.Schemas {
+			ds.Name = s
+		}
+		opts = append(opts, func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) })
+	}
+	current, err := d.inspect(ctx)
+	if err != nil {
+		return err
+	}
+	changes, err := d.Dev.RealmDiff(current, desired)
+	if err != nil {
+		return err
+	}
+	if err := d.Dev.ApplyChanges(ctx, changes, opts...); err != nil {
+		return fmt.Errorf("restoring replay state: %w", err)
+	}
+	return nil
+}
+
+// restoreState restores the cached state of the loader, if it was
+// created from the first base files, and returns the remaining files.
+func (d *DevLoader) restoreState(ctx context.Context, base []migrate.File) ([]migrate.File, error) {
+	if d.State == nil {
+		return base, nil
+	}
+	// A stale state is ignored, and all files are replayed.
+	n, ok := d.State.Prefix(base)
+	if !ok {
+		return base, nil
+	}
+	if err := d.RestoreState(ctx, d.State); err != nil {
+		return nil, err
+	}
+	return base[n:], nil
+}
+
+// replayHashes returns the cumulative hashes of the given files.
+// Unlike the atlas.sum file, all files (and statements) are hashed
+// as any change to them may affect the state of the dev database.
+func replayHashes(files []migrate.File) migrate.HashFile {
+	var (
+		hs migrate.HashFile
+		h  = sha256.New()
+	)
+	for _, f := range files {
+		h.Write([]byte(f.Name()))
+		h.Write(f.Bytes())
+		hs = append(hs, struct{ N, H string }{f.Name(), base64.StdEncoding.EncodeToString(h.Sum(nil))})
+	}
+	return hs
+}
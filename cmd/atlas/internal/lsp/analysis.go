@@ -0,0 +1,727 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type (
+	// document is an open HCL document and its index.
+	document struct {
+		uri, text string
+		lines     []int // start offset of each line
+		body      *hclsyntax.Body
+		diags     hcl.Diagnostics
+		symbols   []*symbol
+		refs      []*ref
+	}
+
+	// symbol is a named object defined in a document.
+	symbol struct {
+		uri  string
+		kind string
+		keys []string  // reference paths, e.g. table.users.column.id
+		rng  hcl.Range // range of the name label
+		doc  string    // hover content
+	}
+
+	// ref is a reference to a symbol, e.g. column.id.
+	ref struct {
+		key string
+		rng hcl.Range
+	}
+
+	// keyword describes a block or an attribute allowed in a block.
+	keyword struct {
+		name  string
+		block bool
+		doc   string
+	}
+)
+
+// keywords holds the blocks and attributes allowed in each
+// block type. The empty key holds the top-level blocks.
+var keywords = map[string][]keyword{
+	"": {
+		{name: "schema", block: true, doc: "A `schema` block describes a database schema (a named database in MySQL)."},
+		{name: "table", block: true, doc: "A `table` block describes a table and its columns, indexes and constraints."},
+		{name: "enum", block: true, doc: "An `enum` block describes an enum type (PostgreSQL)."},
+	},
+	"schema": {
+		{name: "charset", doc: "The default character set of the schema (MySQL)."},
+		{name: "collate", doc: "The default collation of the schema (MySQL)."},
+		{name: "comment", doc: "The comment of the schema."},
+	},
+	"table": {
+		{name: "schema", doc: "A reference to the schema of the table, e.g. `schema.public`."},
+		{name: "column", block: true, doc: "A `column` block describes a table column and its type."},
+		{name: "primary_key", block: true, doc: "A `primary_key` block describes the primary key of the table."},
+		{name: "index", block: true, doc: "An `index` block describes a table index."},
+		{name: "foreign_key", block: true, doc: "A `foreign_key` block describes a foreign-key constraint."},
+		{name: "check", block: true, doc: "A `check` block describes a CHECK constraint."},
+		{name: "comment", doc: "The comment of the table."},
+	},
+	"column": {
+		{name: "type", doc: "The type of the column, e.g. `int` or `varchar(255)`."},
+		{name: "null", doc: "Whether the column is nullable. Defaults to `false`."},
+		{name: "default", doc: "The default value of the column. Use `sql(\"expr\")` for expressions."},
+		{name: "comment", doc: "The comment of the column."},
+		{name: "as", block: true, doc: "An `as` block describes a generated column expression."},
+	},
+	"primary_key": {
+		{name: "columns", doc: "The columns of the primary key, e.g. `[column.id]`."},
+	},
+	"index": {
+		{name: "columns", doc: "The columns of the index, e.g. `[column.name]`."},
+		{name: "unique", doc: "Whether the index is unique."},
+		{name: "on", block: true, doc: "An `on` block describes an index part, e.g. an expression or a column with a sort order."},
+		{name: "comment", doc: "The comment of the index."},
+	},
+	"foreign_key": {
+		{name: "columns", doc: "The referencing columns, e.g. `[column.user_id]`."},
+		{name: "ref_columns", doc: "The referenced columns, e.g. `[table.users.column.id]`."},
+		{name: "on_update", doc: "The action taken on update of the referenced row, e.g. `CASCADE`."},
+		{name: "on_delete", doc: "The action taken on delete of the referenced row, e.g. `SET_NULL`."},
+	},
+	"check": {
+		{name: "expr", doc: "The expression of the CHECK constraint."},
+	},
+	"enum": {
+		{name: "schema", doc: "A reference to the schema of the enum, e.g. `schema.public`."},
+		{name: "values", doc: "The values of the enum."},
+	},
+}
+
+// refKinds are the roots of the traversals that reference symbols.
+var refKinds = map[string]bool{
+	"schema":      true,
+	"table":       true,
+	"enum":        true,
+	"column":      true,
+	"index":       true,
+	"foreign_key": true,
+	"check":       true,
+}
+
+// newDocument parses and indexes the given document.
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, text: text, lines: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	f, diags := hclsyntax.ParseConfig([]byte(text), uri, hcl.InitialPos)
+	d.diags = diags
+	if f != nil {
+		d.body, _ = f.Body.(*hclsyntax.Body)
+	}
+	if d.body != nil {
+		d.index()
+	}
+	return d
+}
+
+// syntaxErr reports if the document has syntax errors.
+func (d *document) syntaxErr() bool {
+	return d.diags.HasErrors()
+}
+
+// index collects the symbols and references of the document.
+func (d *document) index() {
+	for _, b := range d.body.Blocks {
+		if len(b.Labels) == 0 {
+			continue
+		}
+		name := b.Labels[0]
+		switch b.Type {
+		case "table":
+			s := traversalName(b.Body, "schema")
+			t := d.define(b, keys("table", s, name), d.tableDoc(b, s))
+			for _, c := range b.Body.Blocks {
+				if len(c.Labels) == 0 || !refKinds[c.Type] {
+					continue
+				}
+				var k []string
+				for _, tk := range t.keys {
+					k = append(k, tk+"."+c.Type+"."+c.Labels[0])
+				}
+				d.define(c, k, d.childDoc(c, name))
+			}
+			d.collectRefs(b, func(root string) string {
+				switch root {
+				case "column", "index", "foreign_key", "check":
+					return t.keys[len(t.keys)-1] + "."
+				}
+				return ""
+			})
+		case "enum":
+			s := traversalName(b.Body, "schema")
+			d.define(b, keys("enum", s, name), fmt.Sprintf("**enum** `%s`\n\nvalues: `%s`", name, d.attrSrc(b.Body, "values")))
+			d.collectRefs(b, nil)
+		case "schema":
+			d.define(b, []string{"schema." + name}, fmt.Sprintf("**schema** `%s`%s", name, d.commentDoc(b.Body)))
+			d.collectRefs(b, nil)
+		default:
+			d.define(b, []string{b.Type + "." + name}, fmt.Sprintf("**%s** `%s`", b.Type, name))
+			d.collectRefs(b, nil)
+		}
+	}
+}
+
+// keys returns the reference keys of a schema object. The last key is
+// the qualified one, if the schema of the object is known.
+func keys(kind, schema, name string) []string {
+	k := []string{kind + "." + name}
+	if schema != "" {
+		k = append(k, kind+"."+schema+"."+name)
+	}
+	return k
+}
+
+func (d *document) define(b *hclsyntax.Block, keys []string, doc string) *symbol {
+	s := &symbol{uri: d.uri, kind: b.Type, keys: keys, rng: b.LabelRanges[0], doc: doc}
+	d.symbols = append(d.symbols, s)
+	return s
+}
+
+// collectRefs collects the references in the given block. The prefix function
+// returns the prefix of relative references (e.g. column.id in a table).
+func (d *document) collectRefs(b *hclsyntax.Block, prefix func(root string) string) {
+	hclsyntax.VisitAll(b.Body, func(n hclsyntax.Node) hcl.Diagnostics {
+		e, ok := n.(*hclsyntax.ScopeTraversalExpr)
+		if !ok {
+			return nil
+		}
+		p := traversalPath(e.Traversal)
+		if len(p) < 2 || !refKinds[p[0]] {
+			return nil
+		}
+		key := strings.Join(p, ".")
+		if prefix != nil && len(p) == 2 {
+			key = prefix(p[0]) + key
+		}
+		d.refs = append(d.refs, &ref{key: key, rng: e.SrcRange})
+		return nil
+	})
+}
+
+func (d *document) tableDoc(b *hclsyntax.Block, schema string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**table** `%s`", b.Labels[0])
+	if schema != "" {
+		fmt.Fprintf(&sb, "\n\nschema: `%s`", schema)
+	}
+	var cols []string
+	for _, c := range b.Body.Blocks {
+		if c.Type == "column" && len(c.Labels) > 0 {
+			cols = append(cols, c.Labels[0])
+		}
+	}
+	if len(cols) > 0 {
+		fmt.Fprintf(&sb, "\n\ncolumns: `%s`", strings.Join(cols, "`, `"))
+	}
+	sb.WriteString(d.commentDoc(b.Body))
+	return sb.String()
+}
+
+func (d *document) childDoc(b *hclsyntax.Block, table string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**%s** `%s.%s`", b.Type, table, b.Labels[0])
+	switch b.Type {
+	case "column":
+		if t := d.attrSrc(b.Body, "type"); t != "" {
+			fmt.Fprintf(&sb, "\n\ntype: `%s`", t)
+		}
+		null := d.attrSrc(b.Body, "null")
+		if null == "" {
+			null = "false"
+		}
+		fmt.Fprintf(&sb, "\n\nnull: `%s`", null)
+		if v := d.attrSrc(b.Body, "default"); v != "" {
+			fmt.Fprintf(&sb, "\n\ndefault: `%s`", v)
+		}
+	case "index":
+		if v := d.attrSrc(b.Body, "unique"); v != "" {
+			fmt.Fprintf(&sb, "\n\nunique: `%s`", v)
+		}
+		fallthrough
+	case "foreign_key":
+		if v := d.attrSrc(b.Body, "columns"); v != "" {
+			fmt.Fprintf(&sb, "\n\ncolumns: `%s`", v)
+		}
+		if v := d.attrSrc(b.Body, "ref_columns"); v != "" {
+			fmt.Fprintf(&sb, "\n\nref_columns: `%s`", v)
+		}
+	case "check":
+		if v := d.attrSrc(b.Body, "expr"); v != "" {
+			fmt.Fprintf(&sb, "\n\nexpr: `%s`", v)
+		}
+	}
+	sb.WriteString(d.commentDoc(b.Body))
+	return sb.String()
+}
+
+func (d *document) commentDoc(b *hclsyntax.Body) string {
+	if c := d.attrSrc(b, "comment"); c != "" {
+		return "\n\n" + strings.Trim(c, `"`)
+	}
+	return ""
+}
+
+// attrSrc returns the source text of the attribute expression.
+func (d *document) attrSrc(b *hclsyntax.Body, name string) string {
+	a, ok := b.Attributes[name]
+	if !ok {
+		return ""
+	}
+	return d.src(a.Expr.Range())
+}
+
+func (d *document) src(r hcl.Range) string {
+	if r.Start.Byte < 0 || r.End.Byte > len(d.text) || r.Start.Byte > r.End.Byte {
+		return ""
+	}
+	return d.text[r.Start.Byte:r.End.Byte]
+}
+
+// offset returns the byte offset of the given LSP position.
+func (d *document) offset(p position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	off, n := d.lines[p.Line], 0
+	for off < len(d.text) && d.text[off] != '\n' && n < p.Character {
+		r, w := utf8.DecodeRuneInString(d.text[off:])
+		n += len(utf16.Encode([]rune{r}))
+		off += w
+	}
+	return off
+}
+
+// position returns the LSP position of the given byte offset.
+func (d *document) position(off int) position {
+	if off > len(d.text) {
+		off = len(d.text)
+	}
+	line := sort.Search(len(d.lines), func(i int) bool { return d.lines[i] > off }) - 1
+	if line < 0 {
+		line = 0
+	}
+	return position{Line: line, Character: len(utf16.Encode([]rune(d.text[d.lines[line]:off])))}
+}
+
+func (d *document) lspRange(r hcl.Range) lspRange {
+	return lspRange{Start: d.position(r.Start.Byte), End: d.position(r.End.Byte)}
+}
+
+// enclosing returns the chain of blocks that contain the given offset.
+func (d *document) enclosing(off int) []*hclsyntax.Block {
+	var (
+		chain []*hclsyntax.Block
+		body  = d.body
+	)
+	for body != nil {
+		var next *hclsyntax.Body
+		for _, b := range body.Blocks {
+			if b.OpenBraceRange.End.Byte <= off && off <= b.CloseBraceRange.Start.Byte {
+				chain = append(chain, b)
+				next = b.Body
+				break
+			}
+		}
+		body = next
+	}
+	return chain
+}
+
+// group returns the open documents that are in the same directory as the given one.
+func (s *Server) group(uri string) []*document {
+	var docs []*document
+	for u, d := range s.docs {
+		if path.Dir(u) == path.Dir(uri) {
+			docs = append(docs, d)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].uri < docs[j].uri })
+	return docs
+}
+
+// symbols returns the symbols of the given documents by their keys.
+func symbols(docs []*document) map[string]*symbol {
+	m := make(map[string]*symbol)
+	for _, d := range docs {
+		for _, s := range d.symbols {
+			for _, k := range s.keys {
+				if _, ok := m[k]; !ok {
+					m[k] = s
+				}
+			}
+		}
+	}
+	return m
+}
+
+// publish computes and sends the diagnostics of the documents
+// that are in the same directory as the given document.
+func (s *Server) publish(uri string) error {
+	docs := s.group(uri)
+	diags := make(map[string][]diagnostic, len(docs))
+	for _, d := range docs {
+		diags[d.uri] = []diagnostic{}
+	}
+	for _, dg := range check(docs, s.eval) {
+		u := docs[0].uri
+		if dg.Subject != nil && diags[dg.Subject.Filename] != nil {
+			u = dg.Subject.Filename
+		}
+		diags[u] = append(diags[u], s.docs[u].diagnostic(dg))
+	}
+	for _, d := range docs {
+		if err := s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: d.uri, Diagnostics: diags[d.uri]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check returns the syntax errors, the unresolved references and, if an evaluator is
+// given, the evaluation errors of the given files, keyed by their names. Unlike the
+// server, which groups the open documents by their directories, all files are checked
+// together. The subjects of the returned diagnostics hold the byte ranges of the errors.
+func Check(files map[string][]byte, eval schemahcl.Evaluator) hcl.Diagnostics {
+	docs := make([]*document, 0, len(files))
+	for name, b := range files {
+		docs = append(docs, newDocument(name, string(b)))
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].uri < docs[j].uri })
+	return check(docs, eval)
+}
+
+// check returns the syntax, reference and evaluation errors of the given documents.
+// Errors that are not attached to a specific range have no subject.
+func check(docs []*document, eval schemahcl.Evaluator) hcl.Diagnostics {
+	var (
+		diags  hcl.Diagnostics
+		failed bool
+	)
+	for _, d := range docs {
+		diags = append(diags, d.diags...)
+		failed = failed || d.syntaxErr()
+	}
+	// References are checked only if all documents were parsed, as
+	// their symbols may be missing otherwise.
+	if !failed {
+		syms := symbols(docs)
+		for _, d := range docs {
+			for _, r := range d.refs {
+				if _, ok := syms[r.key]; !ok {
+					failed = true
+					rng := r.rng
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  fmt.Sprintf("unknown reference %q", d.src(r.rng)),
+						Subject:  &rng,
+					})
+				}
+			}
+		}
+	}
+	if !failed && eval != nil && len(docs) > 0 {
+		p := hclparse.NewParser()
+		for _, d := range docs {
+			p.ParseHCL([]byte(d.text), d.uri)
+		}
+		if err := eval.Eval(p, &schema.Realm{}, nil); err != nil {
+			var hdiags hcl.Diagnostics
+			switch {
+			case errors.As(err, &hdiags):
+				diags = append(diags, hdiags...)
+			default:
+				diags = append(diags, &hcl.Diagnostic{Severity: hcl.DiagError, Summary: err.Error()})
+			}
+		}
+	}
+	return diags
+}
+
+func (d *document) diagnostic(dg *hcl.Diagnostic) diagnostic {
+	lsp := diagnostic{Severity: severityError, Source: "atlas", Message: dg.Summary}
+	if dg.Severity == hcl.DiagWarning {
+		lsp.Severity = severityWarning
+	}
+	if dg.Detail != "" {
+		lsp.Message += ": " + dg.Detail
+	}
+	if dg.Subject != nil && dg.Subject.Filename == d.uri {
+		lsp.Range = d.lspRange(*dg.Subject)
+	}
+	return lsp
+}
+
+var (
+	reRefPrefix = regexp.MustCompile(`([a-z_][a-z0-9_]*(?:\.[\w-]+)*)\.([\w-]*)$`)
+	reTypeAttr  = regexp.MustCompile(`^\s*type\s*=\s*(\w*)$`)
+	reNewLine   = regexp.MustCompile(`^\s*(\w*)$`)
+)
+
+// completion returns the completion items at the given position.
+func (s *Server) completion(p textDocumentPositionParams) []completionItem {
+	items := []completionItem{}
+	d, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return items
+	}
+	off := d.offset(p.Position)
+	line := d.text[d.lines[d.position(off).Line]:off]
+	chain := d.enclosing(off)
+	switch {
+	case reRefPrefix.MatchString(line):
+		m := reRefPrefix.FindStringSubmatch(line)
+		prefix := m[1] + "."
+		if root := strings.SplitN(m[1], ".", 2)[0]; root != "table" && root != "schema" && root != "enum" {
+			if t := d.tableAt(off, chain); t != "" {
+				prefix = "table." + t + "." + prefix
+			}
+		}
+		seen := make(map[string]bool)
+		for k, sym := range symbols(s.group(d.uri)) {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			next := strings.SplitN(strings.TrimPrefix(k, prefix), ".", 2)
+			if seen[next[0]] || !strings.HasPrefix(next[0], m[2]) {
+				continue
+			}
+			seen[next[0]] = true
+			item := completionItem{Label: next[0], Kind: kindRef}
+			if len(next) == 1 {
+				item.Kind, item.Detail = kindField, sym.kind
+			}
+			items = append(items, item)
+		}
+	case reTypeAttr.MatchString(line) && len(chain) > 0 && chain[len(chain)-1].Type == "column":
+		seen := make(map[string]bool)
+		for _, t := range s.types {
+			if !seen[t.Name] {
+				seen[t.Name] = true
+				items = append(items, completionItem{Label: t.Name, Kind: kindType, Detail: t.T})
+			}
+		}
+	case reNewLine.MatchString(line):
+		var parent string
+		if len(chain) > 0 {
+			parent = chain[len(chain)-1].Type
+		}
+		for _, k := range keywords[parent] {
+			item := completionItem{Label: k.name, Kind: kindProperty, Detail: k.doc}
+			if k.block {
+				item.Kind = kindClass
+			}
+			items = append(items, item)
+		}
+		if parent == "" {
+			items = append(items, completionItem{Label: "variable", Kind: kindKeyword})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items
+}
+
+// hover returns the hover information at the given position.
+func (s *Server) hover(p textDocumentPositionParams) *hoverResult {
+	d, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	off := d.offset(p.Position)
+	result := func(doc string, r hcl.Range) *hoverResult {
+		lr := d.lspRange(r)
+		return &hoverResult{Contents: markupContent{Kind: "markdown", Value: doc}, Range: &lr}
+	}
+	if r := d.refAt(off); r != nil {
+		if sym, ok := symbols(s.group(d.uri))[r.key]; ok {
+			return result(sym.doc, r.rng)
+		}
+		return nil
+	}
+	for _, sym := range d.symbols {
+		if contains(sym.rng, off) {
+			return result(sym.doc, sym.rng)
+		}
+	}
+	if d.body == nil {
+		return nil
+	}
+	var parent string
+	for _, b := range append([]*hclsyntax.Block{nil}, d.enclosing(off)...) {
+		body := d.body
+		if b != nil {
+			parent, body = b.Type, b.Body
+		}
+		for _, c := range body.Blocks {
+			if contains(c.TypeRange, off) {
+				if k, ok := keywordOf(parent, c.Type); ok {
+					return result(k.doc, c.TypeRange)
+				}
+			}
+		}
+		for _, a := range body.Attributes {
+			switch {
+			case contains(a.NameRange, off):
+				if k, ok := keywordOf(parent, a.Name); ok {
+					return result(k.doc, a.NameRange)
+				}
+			case a.Name == "type" && parent == "column" && contains(a.Expr.Range(), off):
+				if doc := s.typeDoc(a.Expr); doc != "" {
+					return result(doc, a.Expr.Range())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// definition returns the location of the symbol referenced at the given position.
+func (s *Server) definition(p textDocumentPositionParams) *location {
+	d, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	r := d.refAt(d.offset(p.Position))
+	if r == nil {
+		return nil
+	}
+	sym, ok := symbols(s.group(d.uri))[r.key]
+	if !ok {
+		return nil
+	}
+	return &location{URI: sym.uri, Range: s.docs[sym.uri].lspRange(sym.rng)}
+}
+
+// typeDoc returns the documentation of the column type expression.
+func (s *Server) typeDoc(e hclsyntax.Expression) string {
+	var name string
+	switch e := e.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		name = e.Traversal.RootName()
+	case *hclsyntax.FunctionCallExpr:
+		name = e.Name
+	}
+	for _, t := range s.types {
+		if t.Name != name {
+			continue
+		}
+		doc := fmt.Sprintf("**type** `%s`\n\ndatabase type: `%s`", t.Name, t.T)
+		if len(t.Attributes) > 0 {
+			attrs := make([]string, len(t.Attributes))
+			for i, a := range t.Attributes {
+				attrs[i] = a.Name
+			}
+			doc += fmt.Sprintf("\n\nattributes: `%s`", strings.Join(attrs, "`, `"))
+		}
+		return doc
+	}
+	return ""
+}
+
+func (d *document) refAt(off int) *ref {
+	for _, r := range d.refs {
+		if contains(r.rng, off) {
+			return r
+		}
+	}
+	return nil
+}
+
+func keywordOf(parent, name string) (keyword, bool) {
+	for _, k := range keywords[parent] {
+		if k.name == name {
+			return k, true
+		}
+	}
+	return keyword{}, false
+}
+
+var reTable = regexp.MustCompile(`(?m)^table\s+"([^"]+)"`)
+
+// tableAt returns the name of the table block that contains the given offset. In case
+// the document cannot be parsed (e.g. while typing), the last table defined before the
+// offset is returned.
+func (d *document) tableAt(off int, chain []*hclsyntax.Block) string {
+	for _, b := range chain {
+		if b.Type == "table" && len(b.Labels) > 0 {
+			return b.Labels[0]
+		}
+	}
+	if !d.syntaxErr() {
+		return ""
+	}
+	if m := reTable.FindAllStringSubmatch(d.text[:off], -1); len(m) > 0 {
+		return m[len(m)-1][1]
+	}
+	return ""
+}
+
+func contains(r hcl.Range, off int) bool {
+	return r.Start.Byte <= off && off <= r.End.Byte
+}
+
+// traversalName returns the name of the object referenced
+// by the given attribute, e.g. "public" for schema.public.
+func traversalName(b *hclsyntax.Body, attr string) string {
+	a, ok := b.Attributes[attr]
+	if !ok {
+		return ""
+	}
+	e, ok := a.Expr.(*hclsyntax.ScopeTraversalExpr)
+	if !ok {
+		return ""
+	}
+	if p := traversalPath(e.Traversal); len(p) == 2 {
+		return p[1]
+	}
+	return ""
+}
+
+// traversalPath returns the names of the traversal steps.
+func traversalPath(t hcl.Traversal) []string {
+	p := make([]string, 0, len(t))
+	for _, s := range t {
+		switch s := s.(type) {
+		case hcl.TraverseRoot:
+			p = append(p, s.Name)
+		case hcl.TraverseAttr:
+			p = append(p, s.Name)
+		case hcl.TraverseIndex:
+			if s.Key.Type() != cty.String || !s.Key.IsKnown() || s.Key.IsNull() {
+				return nil
+			}
+			p = append(p, s.Key.AsString())
+		default:
+			return nil
+		}
+	}
+	return p
+}
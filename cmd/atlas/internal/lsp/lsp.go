@@ -0,0 +1,376 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package lsp implements a language server for Atlas HCL schema files, using
+// the Language Server Protocol (LSP) over a stream (e.g. stdin and stdout).
+//
+// The server supports the following features:
+//
+//   - Diagnostics for syntax errors, unresolved references and, if an evaluator
+//     is configured, errors reported by the schema evaluation.
+//   - Completion of block types, attributes, column types and references.
+//   - Hover information for blocks, attributes and references.
+//   - Go-to-definition for table, column, index, enum and schema references.
+//
+// Documents are synced in full, and references are resolved across all
+// documents opened in the same directory.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/schemahcl"
+)
+
+type (
+	// Server is an LSP server for Atlas HCL schema files.
+	Server struct {
+		eval  schemahcl.Evaluator
+		types []*schemahcl.TypeSpec
+		mu    sync.Mutex
+		docs  map[string]*document
+		wmu   sync.Mutex
+		w     io.Writer
+		down  bool
+	}
+
+	// Option configures a Server.
+	Option func(*Server)
+)
+
+// WithEvaluator configures the evaluator used for reporting the diagnostics
+// of the schema evaluation, e.g. mysql.EvalHCL. If not set, only syntax and
+// reference errors are reported.
+func WithEvaluator(e schemahcl.Evaluator) Option {
+	return func(s *Server) {
+		s.eval = e
+	}
+}
+
+// WithTypes configures the column types offered by the completion, and
+// documented by the hover, e.g. mysql.TypeRegistry.Specs().
+func WithTypes(types []*schemahcl.TypeSpec) Option {
+	return func(s *Server) {
+		s.types = types
+	}
+}
+
+// NewServer returns a new Server configured with the given options.
+func NewServer(opts ...Option) *Server {
+	s := &Server{docs: make(map[string]*document)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ErrExit is returned by Serve if the client sent the "exit"
+// notification without sending the "shutdown" request first.
+var ErrExit = errors.New("lsp: exit notification received before shutdown")
+
+// Serve reads LSP messages from r and writes the responses and notifications
+// to w until the client exits, the reader is closed or the context is canceled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.w = w
+	br := bufio.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m, err := readMessage(br)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if m.Method == "exit" {
+			if !s.down {
+				return ErrExit
+			}
+			return nil
+		}
+		res, err := s.handle(m)
+		// Notifications have no responses.
+		if m.ID == nil {
+			continue
+		}
+		if err := s.respond(m.ID, res, err); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches the message to its handler.
+func (s *Server) handle(m *message) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch m.Method {
+	case "initialize":
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:   1, // Full.
+				HoverProvider:      true,
+				DefinitionProvider: true,
+				CompletionProvider: &completionOptions{TriggerCharacters: []string{"."}},
+			},
+			ServerInfo: serverInfo{Name: "atlas"},
+		}, nil
+	case "shutdown":
+		s.down = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		s.docs[p.TextDocument.URI] = newDocument(p.TextDocument.URI, p.TextDocument.Text)
+		return nil, s.publish(p.TextDocument.URI)
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		if n := len(p.ContentChanges); n > 0 {
+			d := newDocument(p.TextDocument.URI, p.ContentChanges[n-1].Text)
+			// Keep the last valid index while the document is edited.
+			if old, ok := s.docs[d.uri]; ok && d.syntaxErr() && len(d.symbols) == 0 {
+				d.symbols = old.symbols
+			}
+			s.docs[d.uri] = d
+		}
+		return nil, s.publish(p.TextDocument.URI)
+	case "textDocument/didClose":
+		var p textDocumentParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		delete(s.docs, p.TextDocument.URI)
+		if err := s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []diagnostic{}}); err != nil {
+			return nil, err
+		}
+		return nil, s.publish(p.TextDocument.URI)
+	case "textDocument/completion":
+		var p textDocumentPositionParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		return s.completion(p), nil
+	case "textDocument/hover":
+		var p textDocumentPositionParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		return s.hover(p), nil
+	case "textDocument/definition":
+		var p textDocumentPositionParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		return s.definition(p), nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q is not supported", m.Method)}
+	}
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params any) error {
+	return s.write(struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params"`
+	}{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// respond sends the response of a request to the client.
+func (s *Server) respond(id json.RawMessage, res any, err error) error {
+	if err != nil {
+		rerr := &rpcError{}
+		if !errors.As(err, &rerr) {
+			rerr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		return s.write(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Error   *rpcError       `json:"error"`
+		}{JSONRPC: "2.0", ID: id, Error: rerr})
+	}
+	return s.write(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  any             `json:"result"`
+	}{JSONRPC: "2.0", ID: id, Result: res})
+}
+
+func (s *Server) write(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	_, err = fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	return err
+}
+
+// readMessage reads a single message from the stream.
+func readMessage(r *bufio.Reader) (*message, error) {
+	h, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(h.Get("Content-Length")))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("lsp: invalid Content-Length header: %q", h.Get("Content-Length"))
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	m := &message{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("lsp: decoding message: %w", err)
+	}
+	return m, nil
+}
+
+// JSON-RPC error codes.
+const (
+	codeMethodNotFound = -32601
+	codeInternalError  = -32603
+)
+
+// LSP diagnostic severities and completion item kinds.
+const (
+	severityError   = 1
+	severityWarning = 2
+
+	kindField    = 5
+	kindClass    = 7
+	kindProperty = 10
+	kindKeyword  = 14
+	kindRef      = 18
+	kindType     = 25
+)
+
+type (
+	message struct {
+		ID     json.RawMessage `json:"id,omitempty"`
+		Method string          `json:"method,omitempty"`
+		Params json.RawMessage `json:"params,omitempty"`
+	}
+
+	rpcError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	initializeResult struct {
+		Capabilities serverCapabilities `json:"capabilities"`
+		ServerInfo   serverInfo         `json:"serverInfo"`
+	}
+
+	serverCapabilities struct {
+		TextDocumentSync   int                `json:"textDocumentSync"`
+		HoverProvider      bool               `json:"hoverProvider"`
+		DefinitionProvider bool               `json:"definitionProvider"`
+		CompletionProvider *completionOptions `json:"completionProvider,omitempty"`
+	}
+
+	completionOptions struct {
+		TriggerCharacters []string `json:"triggerCharacters,omitempty"`
+	}
+
+	serverInfo struct {
+		Name string `json:"name"`
+	}
+
+	textDocumentItem struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	}
+
+	textDocumentIdentifier struct {
+		URI string `json:"uri"`
+	}
+
+	didOpenParams struct {
+		TextDocument textDocumentItem `json:"textDocument"`
+	}
+
+	didChangeParams struct {
+		TextDocument   textDocumentIdentifier `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+	}
+
+	textDocumentParams struct {
+		TextDocument textDocumentIdentifier `json:"textDocument"`
+	}
+
+	textDocumentPositionParams struct {
+		TextDocument textDocumentIdentifier `json:"textDocument"`
+		Position     position               `json:"position"`
+	}
+
+	position struct {
+		Line      int `json:"line"`
+		Character int `json:"character"`
+	}
+
+	lspRange struct {
+		Start position `json:"start"`
+		End   position `json:"end"`
+	}
+
+	location struct {
+		URI   string   `json:"uri"`
+		Range lspRange `json:"range"`
+	}
+
+	diagnostic struct {
+		Range    lspRange `json:"range"`
+		Severity int      `json:"severity"`
+		Source   string   `json:"source"`
+		Message  string   `json:"message"`
+	}
+
+	publishDiagnosticsParams struct {
+		URI         string       `json:"uri"`
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}
+
+	completionItem struct {
+		Label  string `json:"label"`
+		Kind   int    `json:"kind,omitempty"`
+		Detail string `json:"detail,omitempty"`
+	}
+
+	hoverResult struct {
+		Contents markupContent `json:"contents"`
+		Range    *lspRange     `json:"range,omitempty"`
+	}
+
+	markupContent struct {
+		Kind  string `json:"kind"`
+		Value string `json:"value"`
+	}
+)
+
+// Error implements the error interface.
+func (e *rpcError) Error() string {
+	return e.Message
+}
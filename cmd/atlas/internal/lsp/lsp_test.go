@@ -0,0 +1,340 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package lsp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/cmd/atlas/internal/lsp"
+	"ariga.io/atlas/sql/mysql"
+
+	"github.com/stretchr/testify/require"
+)
+
+const usersHCL = `schema "app" {}
+
+table "users" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+  column "name" {
+    type    = varchar(255)
+    null    = true
+    comment = "the user name"
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+
+table "posts" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+`
+
+func TestServer_Lifecycle(t *testing.T) {
+	c := newClient(t)
+	var init struct {
+		Capabilities struct {
+			TextDocumentSync   int  `json:"textDocumentSync"`
+			HoverProvider      bool `json:"hoverProvider"`
+			DefinitionProvider bool `json:"definitionProvider"`
+		} `json:"capabilities"`
+	}
+	c.call("initialize", map[string]any{}, &init)
+	require.Equal(t, 1, init.Capabilities.TextDocumentSync)
+	require.True(t, init.Capabilities.HoverProvider)
+	require.True(t, init.Capabilities.DefinitionProvider)
+	c.notify("initialized", map[string]any{})
+
+	err := c.callErr("textDocument/unknown", map[string]any{})
+	require.Equal(t, `method "textDocument/unknown" is not supported`, err)
+
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil)
+	require.NoError(t, <-c.done)
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	c := newClient(t, lsp.WithEvaluator(mysql.EvalHCL))
+	c.open("file:///project/schema.hcl", usersHCL)
+	require.Empty(t, c.diagnostics("file:///project/schema.hcl"))
+
+	// Syntax errors.
+	c.change("file:///project/schema.hcl", "table \"users\" {\n  column \"id\" {\n")
+	diags := c.diagnostics("file:///project/schema.hcl")
+	require.Len(t, diags, 1)
+	require.Equal(t, 1, diags[0].Range.Start.Line)
+	require.Contains(t, diags[0].Message, "Unclosed configuration block")
+
+	// Unknown references.
+	c.change("file:///project/schema.hcl", strings.Replace(usersHCL, "table.users.column.id", "table.users.column.uid", 1))
+	diags = c.diagnostics("file:///project/schema.hcl")
+	require.Len(t, diags, 1)
+	require.Equal(t, `unknown reference "table.users.column.uid"`, diags[0].Message)
+	require.Equal(t, position{Line: 27, Character: 19}, diags[0].Range.Start)
+
+	// References are resolved across documents of the same directory.
+	c.change("file:///project/schema.hcl", usersHCL[:strings.Index(usersHCL, `table "posts"`)])
+	require.Empty(t, c.diagnostics("file:///project/schema.hcl"))
+	c.open("file:///project/posts.hcl", usersHCL[strings.Index(usersHCL, `table "posts"`):])
+	require.Empty(t, c.diagnostics("file:///project/posts.hcl"))
+	require.Empty(t, c.diagnostics("file:///project/schema.hcl"))
+	c.open("file:///other/posts.hcl", usersHCL[strings.Index(usersHCL, `table "posts"`):])
+	require.Len(t, c.diagnostics("file:///other/posts.hcl"), 2)
+
+	// Evaluation errors.
+	c.change("file:///project/posts.hcl", strings.Replace(usersHCL[strings.Index(usersHCL, `table "posts"`):], "type = int", "type = unknown", 1))
+	diags = c.diagnostics("file:///project/posts.hcl")
+	require.Empty(t, c.diagnostics("file:///project/schema.hcl"))
+	require.NotEmpty(t, diags)
+}
+
+func TestServer_Completion(t *testing.T) {
+	c := newClient(t, lsp.WithTypes(mysql.TypeRegistry.Specs()))
+	uri := "file:///project/schema.hcl"
+	c.open(uri, usersHCL)
+	labels := func(line, char int) []string {
+		var items []struct {
+			Label string `json:"label"`
+		}
+		c.call("textDocument/completion", positionParams(uri, line, char), &items)
+		l := make([]string, len(items))
+		for i := range items {
+			l[i] = items[i].Label
+		}
+		return l
+	}
+	// Top-level blocks.
+	require.Equal(t, []string{"enum", "schema", "table", "variable"}, labels(1, 0))
+	// Table blocks and attributes.
+	require.Equal(t, []string{"check", "column", "comment", "foreign_key", "index", "primary_key", "schema"}, labels(18, 2))
+
+	// Column types.
+	src := strings.Replace(usersHCL, "type = int\n", "type = \n", 1)
+	c.change(uri, src)
+	types := labels(5, 11)
+	require.Contains(t, types, "int")
+	require.Contains(t, types, "varchar")
+
+	// References.
+	src = strings.Replace(usersHCL, "columns = [column.id]", "columns = [column.]", 1)
+	c.change(uri, src)
+	require.Equal(t, []string{"id", "name"}, labels(13, 22))
+	src = strings.Replace(usersHCL, "ref_columns = [table.users.column.id]", "ref_columns = [table.]", 1)
+	c.change(uri, src)
+	require.Equal(t, []string{"app", "posts", "users"}, labels(27, 25))
+	src = strings.Replace(usersHCL, "ref_columns = [table.users.column.id]", "ref_columns = [table.users.column.n]", 1)
+	c.change(uri, src)
+	require.Equal(t, []string{"name"}, labels(27, 39))
+}
+
+func TestServer_HoverDefinition(t *testing.T) {
+	c := newClient(t, lsp.WithTypes(mysql.TypeRegistry.Specs()))
+	uri := "file:///project/schema.hcl"
+	c.open(uri, usersHCL)
+	hover := func(line, char int) string {
+		var h *struct {
+			Contents struct {
+				Value string `json:"value"`
+			} `json:"contents"`
+		}
+		c.call("textDocument/hover", positionParams(uri, line, char), &h)
+		if h == nil {
+			return ""
+		}
+		return h.Contents.Value
+	}
+	// Reference.
+	require.Equal(t, "**column** `users.id`\n\ntype: `int`\n\nnull: `false`", hover(27, 35))
+	// Symbol.
+	require.Equal(t, "**column** `users.name`\n\ntype: `varchar(255)`\n\nnull: `true`\n\nthe user name", hover(7, 11))
+	require.Equal(t, "**table** `users`\n\nschema: `app`\n\ncolumns: `id`, `name`", hover(2, 9))
+	// Keywords.
+	require.Equal(t, "A `primary_key` block describes the primary key of the table.", hover(12, 4))
+	require.Equal(t, "Whether the column is nullable. Defaults to `false`.", hover(9, 5))
+	// Types.
+	require.Equal(t, "**type** `varchar`\n\ndatabase type: `varchar`\n\nattributes: `size`", hover(8, 15))
+	require.Equal(t, "A `schema` block describes a database schema (a named database in MySQL).", hover(0, 0))
+	require.Empty(t, hover(1, 0))
+
+	definition := func(line, char int) *location {
+		var l *location
+		c.call("textDocument/definition", positionParams(uri, line, char), &l)
+		return l
+	}
+	// Absolute reference.
+	l := definition(27, 35)
+	require.Equal(t, uri, l.URI)
+	require.Equal(t, position{Line: 4, Character: 9}, l.Range.Start)
+	require.Equal(t, position{Line: 4, Character: 13}, l.Range.End)
+	// Relative reference.
+	l = definition(26, 20)
+	require.Equal(t, position{Line: 22, Character: 9}, l.Range.Start)
+	// Schema reference.
+	l = definition(3, 13)
+	require.Equal(t, position{Line: 0, Character: 7}, l.Range.Start)
+	require.Nil(t, definition(0, 0))
+}
+
+type (
+	client struct {
+		t     *testing.T
+		w     io.Writer
+		id    int
+		msgs  chan *rawMessage
+		diags map[string][]diagnostic
+		done  chan error
+	}
+
+	rawMessage struct {
+		ID     *int            `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	position struct {
+		Line      int `json:"line"`
+		Character int `json:"character"`
+	}
+
+	location struct {
+		URI   string `json:"uri"`
+		Range struct {
+			Start position `json:"start"`
+			End   position `json:"end"`
+		} `json:"range"`
+	}
+
+	diagnostic struct {
+		Range struct {
+			Start position `json:"start"`
+		} `json:"range"`
+		Message string `json:"message"`
+	}
+)
+
+func newClient(t *testing.T, opts ...lsp.Option) *client {
+	sr, cw := io.Pipe()
+	cr, sw := io.Pipe()
+	c := &client{t: t, w: cw, msgs: make(chan *rawMessage, 100), diags: make(map[string][]diagnostic), done: make(chan error, 1)}
+	go func() {
+		c.done <- lsp.NewServer(opts...).Serve(context.Background(), sr, sw)
+		sw.Close()
+	}()
+	go func() {
+		defer close(c.msgs)
+		r := bufio.NewReader(cr)
+		for {
+			h, err := textproto.NewReader(r).ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(h.Get("Content-Length"))
+			b := make([]byte, n)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return
+			}
+			m := &rawMessage{}
+			if err := json.Unmarshal(b, m); err != nil {
+				return
+			}
+			c.msgs <- m
+		}
+	}()
+	t.Cleanup(func() { cw.Close() })
+	return c
+}
+
+func (c *client) send(v any) {
+	b, err := json.Marshal(v)
+	require.NoError(c.t, err)
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	require.NoError(c.t, err)
+}
+
+func (c *client) notify(method string, params any) {
+	c.send(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// wait returns the response of the last request, and collects the notifications.
+func (c *client) wait() *rawMessage {
+	for m := range c.msgs {
+		if m.Method == "textDocument/publishDiagnostics" {
+			var p struct {
+				URI         string       `json:"uri"`
+				Diagnostics []diagnostic `json:"diagnostics"`
+			}
+			require.NoError(c.t, json.Unmarshal(m.Params, &p))
+			c.diags[p.URI] = p.Diagnostics
+			continue
+		}
+		require.NotNil(c.t, m.ID)
+		require.Equal(c.t, c.id, *m.ID)
+		return m
+	}
+	c.t.Fatal("server closed the connection")
+	return nil
+}
+
+func (c *client) call(method string, params, result any) {
+	c.id++
+	c.send(map[string]any{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+	m := c.wait()
+	require.Nil(c.t, m.Error)
+	if result != nil {
+		require.NoError(c.t, json.Unmarshal(m.Result, result))
+	}
+}
+
+func (c *client) callErr(method string, params any) string {
+	c.id++
+	c.send(map[string]any{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+	m := c.wait()
+	require.NotNil(c.t, m.Error)
+	return m.Error.Message
+}
+
+func (c *client) open(uri, text string) {
+	c.notify("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "languageId": "hcl", "version": 1, "text": text}})
+}
+
+func (c *client) change(uri, text string) {
+	c.notify("textDocument/didChange", map[string]any{"textDocument": map[string]any{"uri": uri}, "contentChanges": []map[string]any{{"text": text}}})
+}
+
+// diagnostics returns the last diagnostics published for the given document.
+func (c *client) diagnostics(uri string) []diagnostic {
+	// Diagnostics are published before responding to the next request.
+	c.call("textDocument/hover", positionParams("file:///sync.hcl", 0, 0), nil)
+	return c.diags[uri]
+}
+
+func positionParams(uri string, line, char int) map[string]any {
+	return map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": line, "character": char}}
+}
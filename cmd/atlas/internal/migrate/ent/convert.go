@@ -21,6 +21,7 @@ func (rc *RevisionCreate) SetRevision(rev *migrate.Revision) *RevisionCreate {
 	rc.SetHash(rev.Hash)
 	rc.SetPartialHashes(rev.PartialHashes)
 	rc.SetOperatorVersion(rev.OperatorVersion)
+	rc.SetStmtStats(rev.StmtStats)
 	return rc
 }
 
@@ -38,5 +39,6 @@ func (r *Revision) AtlasRevision() *migrate.Revision {
 		Hash:            r.Hash,
 		PartialHashes:   r.PartialHashes,
 		OperatorVersion: r.OperatorVersion,
+		StmtStats:       r.StmtStats,
 	}
 }
@@ -52,6 +52,7 @@ type RevisionMutation struct {
 	hash              *string
 	partial_hashes    *[]string
 	operator_version  *string
+	stmt_stats        *[]migrate.StmtStat
 	clearedFields     map[string]struct{}
 	done              bool
 	oldValue          func(context.Context) (*Revision, error)
@@ -628,6 +629,55 @@ func (m *RevisionMutation) ResetOperatorVersion() {
 	m.operator_version = nil
 }
 
+// SetStmtStats sets the "stmt_stats" field.
+func (m *RevisionMutation) SetStmtStats(ms []migrate.StmtStat) {
+	m.stmt_stats = &ms
+}
+
+// StmtStats returns the value of the "stmt_stats" field in the mutation.
+func (m *RevisionMutation) StmtStats() (r []migrate.StmtStat, exists bool) {
+	v := m.stmt_stats
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStmtStats returns the old "stmt_stats" field's value of the Revision entity.
+// If the Revision object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RevisionMutation) OldStmtStats(ctx context.Context) (v []migrate.StmtStat, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStmtStats is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStmtStats requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStmtStats: %w", err)
+	}
+	return oldValue.StmtStats, nil
+}
+
+// ClearStmtStats clears the value of the "stmt_stats" field.
+func (m *RevisionMutation) ClearStmtStats() {
+	m.stmt_stats = nil
+	m.clearedFields[revision.FieldStmtStats] = struct{}{}
+}
+
+// StmtStatsCleared returns if the "stmt_stats" field was cleared in this mutation.
+func (m *RevisionMutation) StmtStatsCleared() bool {
+	_, ok := m.clearedFields[revision.FieldStmtStats]
+	return ok
+}
+
+// ResetStmtStats resets all changes to the "stmt_stats" field.
+func (m *RevisionMutation) ResetStmtStats() {
+	m.stmt_stats = nil
+	delete(m.clearedFields, revision.FieldStmtStats)
+}
+
 // Where appends a list predicates to the RevisionMutation builder.
 func (m *RevisionMutation) Where(ps ...predicate.Revision) {
 	m.predicates = append(m.predicates, ps...)
@@ -647,7 +697,7 @@ func (m *RevisionMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *RevisionMutation) Fields() []string {
-	fields := make([]string, 0, 10)
+	fields := make([]string, 0, 11)
 	if m.description != nil {
 		fields = append(fields, revision.FieldDescription)
 	}
@@ -678,6 +728,9 @@ func (m *RevisionMutation) Fields() []string {
 	if m.operator_version != nil {
 		fields = append(fields, revision.FieldOperatorVersion)
 	}
+	if m.stmt_stats != nil {
+		fields = append(fields, revision.FieldStmtStats)
+	}
 	return fields
 }
 
@@ -706,6 +759,8 @@ func (m *RevisionMutation) Field(name string) (ent.Value, bool) {
 		return m.PartialHashes()
 	case revision.FieldOperatorVersion:
 		return m.OperatorVersion()
+	case revision.FieldStmtStats:
+		return m.StmtStats()
 	}
 	return nil, false
 }
@@ -735,6 +790,8 @@ func (m *RevisionMutation) OldField(ctx context.Context, name string) (ent.Value
 		return m.OldPartialHashes(ctx)
 	case revision.FieldOperatorVersion:
 		return m.OldOperatorVersion(ctx)
+	case revision.FieldStmtStats:
+		return m.OldStmtStats(ctx)
 	}
 	return nil, fmt.Errorf("unknown Revision field %s", name)
 }
@@ -814,6 +871,13 @@ func (m *RevisionMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetOperatorVersion(v)
 		return nil
+	case revision.FieldStmtStats:
+		v, ok := value.([]migrate.StmtStat)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStmtStats(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Revision field %s", name)
 }
@@ -901,6 +965,9 @@ func (m *RevisionMutation) ClearedFields() []string {
 	if m.FieldCleared(revision.FieldPartialHashes) {
 		fields = append(fields, revision.FieldPartialHashes)
 	}
+	if m.FieldCleared(revision.FieldStmtStats) {
+		fields = append(fields, revision.FieldStmtStats)
+	}
 	return fields
 }
 
@@ -921,6 +988,9 @@ func (m *RevisionMutation) ClearField(name string) error {
 	case revision.FieldPartialHashes:
 		m.ClearPartialHashes()
 		return nil
+	case revision.FieldStmtStats:
+		m.ClearStmtStats()
+		return nil
 	}
 	return fmt.Errorf("unknown Revision nullable field %s", name)
 }
@@ -959,6 +1029,9 @@ func (m *RevisionMutation) ResetField(name string) error {
 	case revision.FieldOperatorVersion:
 		m.ResetOperatorVersion()
 		return nil
+	case revision.FieldStmtStats:
+		m.ResetStmtStats()
+		return nil
 	}
 	return fmt.Errorf("unknown Revision field %s", name)
 }
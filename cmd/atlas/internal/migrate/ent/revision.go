@@ -42,6 +42,8 @@ type Revision struct {
 	PartialHashes []string `json:"partial_hashes,omitempty"`
 	// OperatorVersion holds the value of the "operator_version" field.
 	OperatorVersion string `json:"operator_version,omitempty"`
+	// StmtStats holds the value of the "stmt_stats" field.
+	StmtStats []migrate.StmtStat `json:"stmt_stats,omitempty"`
 }
 
 // scanValues returns the types for scanning values from sql.Rows.
@@ -49,7 +51,7 @@ func (*Revision) scanValues(columns []string) ([]interface{}, error) {
 	values := make([]interface{}, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case revision.FieldPartialHashes:
+		case revision.FieldPartialHashes, revision.FieldStmtStats:
 			values[i] = new([]byte)
 		case revision.FieldType, revision.FieldApplied, revision.FieldTotal, revision.FieldExecutionTime:
 			values[i] = new(sql.NullInt64)
@@ -140,6 +142,14 @@ func (r *Revision) assignValues(columns []string, values []interface{}) error {
 			} else if value.Valid {
 				r.OperatorVersion = value.String
 			}
+		case revision.FieldStmtStats:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field stmt_stats", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &r.StmtStats); err != nil {
+					return fmt.Errorf("unmarshal field stmt_stats: %w", err)
+				}
+			}
 		}
 	}
 	return nil
@@ -197,6 +207,9 @@ func (r *Revision) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("operator_version=")
 	builder.WriteString(r.OperatorVersion)
+	builder.WriteString(", ")
+	builder.WriteString("stmt_stats=")
+	builder.WriteString(fmt.Sprintf("%v", r.StmtStats))
 	builder.WriteByte(')')
 	return builder.String()
 }
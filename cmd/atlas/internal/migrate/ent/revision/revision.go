@@ -35,6 +35,8 @@ const (
 	FieldPartialHashes = "partial_hashes"
 	// FieldOperatorVersion holds the string denoting the operator_version field in the database.
 	FieldOperatorVersion = "operator_version"
+	// FieldStmtStats holds the string denoting the stmt_stats field in the database.
+	FieldStmtStats = "stmt_stats"
 	// Table holds the table name of the revision in the database.
 	Table = "atlas_schema_revisions"
 )
@@ -52,6 +54,7 @@ var Columns = []string{
 	FieldHash,
 	FieldPartialHashes,
 	FieldOperatorVersion,
+	FieldStmtStats,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -1014,6 +1014,20 @@ func OperatorVersionContainsFold(v string) predicate.Revision {
 	})
 }
 
+// StmtStatsIsNil applies the IsNil predicate on the "stmt_stats" field.
+func StmtStatsIsNil() predicate.Revision {
+	return predicate.Revision(func(s *sql.Selector) {
+		s.Where(sql.IsNull(s.C(FieldStmtStats)))
+	})
+}
+
+// StmtStatsNotNil applies the NotNil predicate on the "stmt_stats" field.
+func StmtStatsNotNil() predicate.Revision {
+	return predicate.Revision(func(s *sql.Selector) {
+		s.Where(sql.NotNull(s.C(FieldStmtStats)))
+	})
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.Revision) predicate.Revision {
 	return predicate.Revision(func(s *sql.Selector) {
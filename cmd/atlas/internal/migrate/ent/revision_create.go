@@ -120,6 +120,12 @@ func (rc *RevisionCreate) SetOperatorVersion(s string) *RevisionCreate {
 	return rc
 }
 
+// SetStmtStats sets the "stmt_stats" field.
+func (rc *RevisionCreate) SetStmtStats(ms []migrate.StmtStat) *RevisionCreate {
+	rc.mutation.SetStmtStats(ms)
+	return rc
+}
+
 // SetID sets the "id" field.
 func (rc *RevisionCreate) SetID(s string) *RevisionCreate {
 	rc.mutation.SetID(s)
@@ -371,6 +377,14 @@ func (rc *RevisionCreate) createSpec() (*Revision, *sqlgraph.CreateSpec) {
 		})
 		_node.OperatorVersion = value
 	}
+	if value, ok := rc.mutation.StmtStats(); ok {
+		_spec.Fields = append(_spec.Fields, &sqlgraph.FieldSpec{
+			Type:   field.TypeJSON,
+			Value:  value,
+			Column: revision.FieldStmtStats,
+		})
+		_node.StmtStats = value
+	}
 	return _node, _spec
 }
 
@@ -579,6 +593,24 @@ func (u *RevisionUpsert) UpdateOperatorVersion() *RevisionUpsert {
 	return u
 }
 
+// SetStmtStats sets the "stmt_stats" field.
+func (u *RevisionUpsert) SetStmtStats(v []migrate.StmtStat) *RevisionUpsert {
+	u.Set(revision.FieldStmtStats, v)
+	return u
+}
+
+// UpdateStmtStats sets the "stmt_stats" field to the value that was provided on create.
+func (u *RevisionUpsert) UpdateStmtStats() *RevisionUpsert {
+	u.SetExcluded(revision.FieldStmtStats)
+	return u
+}
+
+// ClearStmtStats clears the value of the "stmt_stats" field.
+func (u *RevisionUpsert) ClearStmtStats() *RevisionUpsert {
+	u.SetNull(revision.FieldStmtStats)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create except the ID field.
 // Using this option is equivalent to using:
 //
@@ -815,6 +847,27 @@ func (u *RevisionUpsertOne) UpdateOperatorVersion() *RevisionUpsertOne {
 	})
 }
 
+// SetStmtStats sets the "stmt_stats" field.
+func (u *RevisionUpsertOne) SetStmtStats(v []migrate.StmtStat) *RevisionUpsertOne {
+	return u.Update(func(s *RevisionUpsert) {
+		s.SetStmtStats(v)
+	})
+}
+
+// UpdateStmtStats sets the "stmt_stats" field to the value that was provided on create.
+func (u *RevisionUpsertOne) UpdateStmtStats() *RevisionUpsertOne {
+	return u.Update(func(s *RevisionUpsert) {
+		s.UpdateStmtStats()
+	})
+}
+
+// ClearStmtStats clears the value of the "stmt_stats" field.
+func (u *RevisionUpsertOne) ClearStmtStats() *RevisionUpsertOne {
+	return u.Update(func(s *RevisionUpsert) {
+		s.ClearStmtStats()
+	})
+}
+
 // Exec executes the query.
 func (u *RevisionUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1215,6 +1268,27 @@ func (u *RevisionUpsertBulk) UpdateOperatorVersion() *RevisionUpsertBulk {
 	})
 }
 
+// SetStmtStats sets the "stmt_stats" field.
+func (u *RevisionUpsertBulk) SetStmtStats(v []migrate.StmtStat) *RevisionUpsertBulk {
+	return u.Update(func(s *RevisionUpsert) {
+		s.SetStmtStats(v)
+	})
+}
+
+// UpdateStmtStats sets the "stmt_stats" field to the value that was provided on create.
+func (u *RevisionUpsertBulk) UpdateStmtStats() *RevisionUpsertBulk {
+	return u.Update(func(s *RevisionUpsert) {
+		s.UpdateStmtStats()
+	})
+}
+
+// ClearStmtStats clears the value of the "stmt_stats" field.
+func (u *RevisionUpsertBulk) ClearStmtStats() *RevisionUpsertBulk {
+	return u.Update(func(s *RevisionUpsert) {
+		s.ClearStmtStats()
+	})
+}
+
 // Exec executes the query.
 func (u *RevisionUpsertBulk) Exec(ctx context.Context) error {
 	for i, b := range u.create.builders {
@@ -154,6 +154,18 @@ func (ru *RevisionUpdate) SetOperatorVersion(s string) *RevisionUpdate {
 	return ru
 }
 
+// SetStmtStats sets the "stmt_stats" field.
+func (ru *RevisionUpdate) SetStmtStats(ms []migrate.StmtStat) *RevisionUpdate {
+	ru.mutation.SetStmtStats(ms)
+	return ru
+}
+
+// ClearStmtStats clears the value of the "stmt_stats" field.
+func (ru *RevisionUpdate) ClearStmtStats() *RevisionUpdate {
+	ru.mutation.ClearStmtStats()
+	return ru
+}
+
 // Mutation returns the RevisionMutation object of the builder.
 func (ru *RevisionUpdate) Mutation() *RevisionMutation {
 	return ru.mutation
@@ -348,6 +360,19 @@ func (ru *RevisionUpdate) sqlSave(ctx context.Context) (n int, err error) {
 			Column: revision.FieldOperatorVersion,
 		})
 	}
+	if value, ok := ru.mutation.StmtStats(); ok {
+		_spec.Fields.Set = append(_spec.Fields.Set, &sqlgraph.FieldSpec{
+			Type:   field.TypeJSON,
+			Value:  value,
+			Column: revision.FieldStmtStats,
+		})
+	}
+	if ru.mutation.StmtStatsCleared() {
+		_spec.Fields.Clear = append(_spec.Fields.Clear, &sqlgraph.FieldSpec{
+			Type:   field.TypeJSON,
+			Column: revision.FieldStmtStats,
+		})
+	}
 	_spec.Node.Schema = ru.schemaConfig.Revision
 	ctx = internal.NewSchemaConfigContext(ctx, ru.schemaConfig)
 	if n, err = sqlgraph.UpdateNodes(ctx, ru.driver, _spec); err != nil {
@@ -489,6 +514,18 @@ func (ruo *RevisionUpdateOne) SetOperatorVersion(s string) *RevisionUpdateOne {
 	return ruo
 }
 
+// SetStmtStats sets the "stmt_stats" field.
+func (ruo *RevisionUpdateOne) SetStmtStats(ms []migrate.StmtStat) *RevisionUpdateOne {
+	ruo.mutation.SetStmtStats(ms)
+	return ruo
+}
+
+// ClearStmtStats clears the value of the "stmt_stats" field.
+func (ruo *RevisionUpdateOne) ClearStmtStats() *RevisionUpdateOne {
+	ruo.mutation.ClearStmtStats()
+	return ruo
+}
+
 // Mutation returns the RevisionMutation object of the builder.
 func (ruo *RevisionUpdateOne) Mutation() *RevisionMutation {
 	return ruo.mutation
@@ -713,6 +750,19 @@ func (ruo *RevisionUpdateOne) sqlSave(ctx context.Context) (_node *Revision, err
 			Column: revision.FieldOperatorVersion,
 		})
 	}
+	if value, ok := ruo.mutation.StmtStats(); ok {
+		_spec.Fields.Set = append(_spec.Fields.Set, &sqlgraph.FieldSpec{
+			Type:   field.TypeJSON,
+			Value:  value,
+			Column: revision.FieldStmtStats,
+		})
+	}
+	if ruo.mutation.StmtStatsCleared() {
+		_spec.Fields.Clear = append(_spec.Fields.Clear, &sqlgraph.FieldSpec{
+			Type:   field.TypeJSON,
+			Column: revision.FieldStmtStats,
+		})
+	}
 	_spec.Node.Schema = ruo.schemaConfig.Revision
 	ctx = internal.NewSchemaConfigContext(ctx, ruo.schemaConfig)
 	_node = &Revision{config: ruo.config}
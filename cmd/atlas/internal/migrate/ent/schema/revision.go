@@ -50,6 +50,8 @@ func (Revision) Fields() []ent.Field {
 		field.Strings("partial_hashes").
 			Optional(),
 		field.String("operator_version"),
+		field.JSON("stmt_stats", []migrate.StmtStat{}).
+			Optional(),
 	}
 }
 
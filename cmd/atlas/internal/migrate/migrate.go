@@ -114,6 +114,11 @@ func (r *EntRevisions) WriteRevision(ctx context.Context, rev *migrate.Revision)
 		Exec(ctx)
 }
 
+// DeleteRevisions deletes the revisions with the given versions from the revisions table.
+func (r *EntRevisions) DeleteRevisions(ctx context.Context, versions ...string) (int, error) {
+	return r.ec.Revision.Delete().Where(revision.IDIn(versions...)).Exec(ctx)
+}
+
 // Migrate attempts to create / update the revisions table. This is separated since Ent attempts to wrap the migration
 // execution in a transaction and assumes the underlying connection is of type *sql.DB, which is not true for actually
 // reading and writing revisions.
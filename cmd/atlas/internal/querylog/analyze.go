@@ -0,0 +1,250 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package querylog
+
+import (
+	"strings"
+)
+
+type (
+	// Refs describes the tables a query reads, and the columns it filters them by.
+	Refs struct {
+		Tables  []*Table
+		Filters []*Filter
+	}
+
+	// Table is a table referenced by a query.
+	Table struct {
+		Schema, Name string
+		Alias        string // optional
+	}
+
+	// Filter is a column used by a query to filter rows, either in
+	// the WHERE clause or in the join condition (ON) of a table.
+	Filter struct {
+		Table  string // qualifier of the column, if it is qualified
+		Column string
+		// Eq indicates if the column is compared using equality (e.g. =, IN
+		// or IS NULL), or otherwise, using a range (e.g. <, BETWEEN or LIKE 'a%').
+		Eq bool
+	}
+)
+
+// Ref returns the name that is used by the query to refer to the table.
+func (t *Table) Ref() string {
+	if t.Alias != "" {
+		return t.Alias
+	}
+	return t.Name
+}
+
+// Columns returns the columns the query filters the given table by, ordered as the parts of an index
+// that serves the query: equality columns first, in order of appearance, followed by at most one range
+// column. Unqualified columns are attributed to t if has reports that t is the only table of the query
+// that has the column.
+func (r *Refs) Columns(t *Table, has func(*Table, string) bool) []string {
+	var (
+		eq, rg []string
+		seen   = make(map[string]bool)
+	)
+	for _, f := range r.Filters {
+		if seen[f.Column] || !r.filters(t, f, has) {
+			continue
+		}
+		seen[f.Column] = true
+		if f.Eq {
+			eq = append(eq, f.Column)
+		} else {
+			rg = append(rg, f.Column)
+		}
+	}
+	if len(rg) > 0 {
+		eq = append(eq, rg[0])
+	}
+	return eq
+}
+
+// filters reports if the filter applies to the given table.
+func (r *Refs) filters(t *Table, f *Filter, has func(*Table, string) bool) bool {
+	if f.Table != "" {
+		return strings.EqualFold(f.Table, t.Ref())
+	}
+	if !has(t, f.Column) {
+		return false
+	}
+	for _, o := range r.Tables {
+		if o != t && has(o, f.Column) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clause kinds.
+const (
+	clauseOther  = iota // select list, SET, GROUP BY, ORDER BY, etc.
+	clauseTables        // FROM, JOIN and UPDATE table references
+	clausePreds         // WHERE and ON predicates
+)
+
+// Analyze returns the tables the query reads and the columns it filters them by. The query is
+// analyzed lexically, and therefore, the analysis is best-effort: predicates that cannot be used
+// by an index, such as those that are combined using OR or wrapped by functions, are skipped.
+func Analyze(q string) (*Refs, error) {
+	toks, err := lex(q)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		r       Refs
+		clause  = clauseOther
+		group   = -1 // index of the token that opened the predicates group
+		expect  bool // expect a table reference
+		stack   [][2]int
+		groupOr = make(map[int]bool)
+		groups  []int // group of each filter
+	)
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch {
+		case t.kind == kindPunct && t.text == "(":
+			stack = append(stack, [2]int{clause, group})
+			group, expect = i, false
+		case t.kind == kindPunct && t.text == ")":
+			if n := len(stack); n > 0 {
+				clause, group, stack = stack[n-1][0], stack[n-1][1], stack[:n-1]
+			}
+			expect = false
+		// IS DISTINCT FROM and FOR UPDATE are not followed by tables.
+		case t.is("FROM") && !prevIs(toks, i, "DISTINCT"), t.is("UPDATE") && !prevIs(toks, i, "FOR"), t.is("JOIN"), t.is("STRAIGHT_JOIN"):
+			clause, expect = clauseTables, true
+		case t.is("WHERE"), t.is("ON"):
+			clause, group = clausePreds, i
+		case t.is("SELECT"), t.is("SET"), t.is("GROUP"), t.is("ORDER"), t.is("HAVING"), t.is("LIMIT"), t.is("UNION"), t.is("USING"), t.is("RETURNING"), t.is("WINDOW"), t.is("FOR"):
+			clause = clauseOther
+		case clause == clauseTables:
+			switch {
+			case t.kind == kindPunct && t.text == ",":
+				expect = true
+			case expect && t.isName():
+				tb, n := tableRef(toks[i:])
+				r.Tables = append(r.Tables, tb)
+				i, expect = i+n-1, false
+			}
+		case clause == clausePreds:
+			if t.is("OR") {
+				groupOr[group] = true
+				continue
+			}
+			fs, n := filters(toks[i:])
+			for _, f := range fs {
+				r.Filters = append(r.Filters, f)
+				groups = append(groups, group)
+			}
+			i += n
+		}
+	}
+	// Predicates that are combined using OR cannot be served by a single index.
+	fs := r.Filters[:0]
+	for i, f := range r.Filters {
+		if !groupOr[groups[i]] {
+			fs = append(fs, f)
+		}
+	}
+	r.Filters = fs
+	return &r, nil
+}
+
+// tableRef parses a table reference, and returns the number of tokens it spans.
+func tableRef(toks []*token) (*Table, int) {
+	names, n := qualified(toks)
+	t := &Table{Name: names[len(names)-1]}
+	if len(names) > 1 {
+		t.Schema = names[len(names)-2]
+	}
+	if n < len(toks) && toks[n].is("AS") {
+		n++
+	}
+	if n < len(toks) && toks[n].isName() {
+		t.Alias = toks[n].text
+		n++
+	}
+	return t, n
+}
+
+// filters parses the filters that start at the first token, and returns the number of tokens
+// to skip. Both sides of an equality are parsed, as join conditions (e.g. a.id = b.a_id) can
+// be served by indexes on either table.
+func filters(toks []*token) ([]*Filter, int) {
+	// Literal on the left side of an equality (e.g. 1 = c).
+	if len(toks) > 2 && toks[0].isValue() && toks[1].text == "=" {
+		if f, n := column(toks[2:]); f != nil {
+			f.Eq = true
+			return []*Filter{f}, n + 1
+		}
+		return nil, 0
+	}
+	f, n := column(toks)
+	if f == nil || n >= len(toks) {
+		return nil, 0
+	}
+	switch op, rest := toks[n], toks[n+1:]; {
+	case op.text == "=" || op.text == "<=>" || op.text == "==":
+		f.Eq = true
+		if g, _ := column(rest); g != nil {
+			g.Eq = true
+			return []*Filter{f, g}, n
+		}
+	case op.is("IN"):
+		f.Eq = true
+	case op.is("IS") && len(rest) > 0 && rest[0].is("NULL"):
+		f.Eq = true
+	case op.text == "<" || op.text == ">" || op.text == "<=" || op.text == ">=" || op.is("BETWEEN"):
+	// Only prefix patterns (e.g. LIKE 'abc%') can be served by indexes.
+	case op.is("LIKE") && len(rest) > 0 && rest[0].kind == kindString && rest[0].text != "" && !strings.ContainsAny(rest[0].text[:1], "%_"):
+	default:
+		return nil, n - 1
+	}
+	return []*Filter{f}, n - 1
+}
+
+// column parses a column reference that is not a function call, and returns the number of tokens it spans.
+func column(toks []*token) (*Filter, int) {
+	names, n := qualified(toks)
+	if len(names) == 0 || n < len(toks) && (toks[n].text == "(" || toks[n].text == "::") {
+		return nil, 0
+	}
+	f := &Filter{Column: names[len(names)-1]}
+	if len(names) > 1 {
+		f.Table = names[len(names)-2]
+	}
+	return f, n
+}
+
+// qualified parses a possibly qualified name (e.g. a.b.c), and returns its parts
+// and the number of tokens it spans.
+func qualified(toks []*token) ([]string, int) {
+	var (
+		names []string
+		n     int
+	)
+	for n < len(toks) && toks[n].isName() {
+		names = append(names, toks[n].text)
+		n++
+		if n+1 >= len(toks) || toks[n].text != "." {
+			break
+		}
+		n++
+	}
+	if len(names) > 0 && toks[n-1].text == "." {
+		n--
+	}
+	return names, n
+}
+
+// prevIs reports if the token that precedes the i-th token is the given keyword.
+func prevIs(toks []*token, i int, kw string) bool {
+	return i > 0 && toks[i-1].is(kw)
+}
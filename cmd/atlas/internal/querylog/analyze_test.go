@@ -0,0 +1,89 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package querylog_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/cmd/atlas/internal/querylog"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	for _, tt := range []struct {
+		query   string
+		tables  []*querylog.Table
+		filters []*querylog.Filter
+	}{
+		{
+			query:   "SELECT * FROM users WHERE email = 'a@b.c'",
+			tables:  []*querylog.Table{{Name: "users"}},
+			filters: []*querylog.Filter{{Column: "email", Eq: true}},
+		},
+		{
+			query:  "SELECT u.name FROM `app`.`users` AS u WHERE u.age >= 18 AND u.active IS NULL AND 'x' = u.name AND lower(u.email) = 'a' AND u.bio LIKE '%a'",
+			tables: []*querylog.Table{{Schema: "app", Name: "users", Alias: "u"}},
+			filters: []*querylog.Filter{
+				{Table: "u", Column: "age"},
+				{Table: "u", Column: "active", Eq: true},
+				{Table: "u", Column: "name", Eq: true},
+			},
+		},
+		{
+			query:  `SELECT * FROM users u LEFT JOIN pets p ON p.owner_id = u.id WHERE u.name LIKE 'a%' ORDER BY p.name`,
+			tables: []*querylog.Table{{Name: "users", Alias: "u"}, {Name: "pets", Alias: "p"}},
+			filters: []*querylog.Filter{
+				{Table: "p", Column: "owner_id", Eq: true},
+				{Table: "u", Column: "id", Eq: true},
+				{Table: "u", Column: "name"},
+			},
+		},
+		{
+			query:   "SELECT * FROM users WHERE (name = 'a' OR name = 'b') AND age IN (SELECT age FROM ages WHERE kind = $1)",
+			tables:  []*querylog.Table{{Name: "users"}, {Name: "ages"}},
+			filters: []*querylog.Filter{{Column: "age", Eq: true}, {Column: "kind", Eq: true}},
+		},
+		{
+			query:   `UPDATE "users" SET "name" = 'a' WHERE "id" BETWEEN 1 AND 5 FOR UPDATE SKIP LOCKED`,
+			tables:  []*querylog.Table{{Name: "users"}},
+			filters: []*querylog.Filter{{Column: "id"}},
+		},
+		{
+			query:  "DELETE FROM users WHERE name IS DISTINCT FROM 'a' OR id = 1",
+			tables: []*querylog.Table{{Name: "users"}},
+		},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			r, err := querylog.Analyze(tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.tables, r.Tables)
+			if len(tt.filters) == 0 {
+				require.Empty(t, r.Filters)
+			} else {
+				require.Equal(t, tt.filters, r.Filters)
+			}
+		})
+	}
+}
+
+func TestRefs_Columns(t *testing.T) {
+	r, err := querylog.Analyze("SELECT * FROM users u JOIN pets p ON p.owner_id = u.id WHERE created_at > '2023' AND name = 'a' AND p.kind = 'dog' AND u.id = 1")
+	require.NoError(t, err)
+	columns := map[string][]string{
+		"users": {"id", "name"},
+		"pets":  {"owner_id", "kind", "created_at"},
+	}
+	has := func(t *querylog.Table, c string) bool {
+		for _, n := range columns[t.Name] {
+			if n == c {
+				return true
+			}
+		}
+		return false
+	}
+	require.Equal(t, []string{"id", "name"}, r.Columns(r.Tables[0], has))
+	require.Equal(t, []string{"owner_id", "kind", "created_at"}, r.Columns(r.Tables[1], has))
+}
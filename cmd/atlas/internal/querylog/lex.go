@@ -0,0 +1,144 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package querylog
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token kinds.
+const (
+	kindIdent  = iota // bare identifier or keyword
+	kindQuoted        // quoted identifier
+	kindString        // string literal
+	kindNumber        // numeric literal
+	kindParam         // bind parameter (e.g. ?, $1)
+	kindPunct         // punctuation or operator
+)
+
+// token is a single lexical token of a query.
+type token struct {
+	kind int
+	text string // unquoted text
+}
+
+// is reports if the token is the given keyword.
+func (t *token) is(kw string) bool {
+	return t.kind == kindIdent && strings.EqualFold(t.text, kw)
+}
+
+// isName reports if the token may be used as an object name.
+func (t *token) isName() bool {
+	return t.kind == kindQuoted || t.kind == kindIdent && !reserved[strings.ToUpper(t.text)]
+}
+
+// isValue reports if the token is a literal or a bind parameter.
+func (t *token) isValue() bool {
+	return t.kind == kindString || t.kind == kindNumber || t.kind == kindParam
+}
+
+// reserved holds the keywords that cannot be used as bare object names or aliases.
+var reserved = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, kw := range strings.Fields(`
+		ALL AND ANY AS ASC BETWEEN BY CASE COLLATE CROSS DELETE DESC DISTINCT ELSE END ESCAPE
+		EXCEPT EXISTS FALSE FETCH FOR FORCE FROM FULL GROUP HAVING IGNORE ILIKE IN INNER INSERT
+		INTERSECT INTERVAL INTO IS JOIN LATERAL LEFT LIKE LIMIT NATURAL NOT NULL OFFSET ON ONLY
+		OR ORDER OUTER RETURNING RIGHT SELECT SET SOME STRAIGHT_JOIN TABLESAMPLE THEN TRUE UNION
+		UPDATE USE USING VALUES WHEN WHERE WINDOW WITH`) {
+		m[kw] = true
+	}
+	return m
+}()
+
+// operators holds the multi-character operators, longest first.
+var operators = []string{"<=>", "<=", ">=", "<>", "!=", "==", "::", "||"}
+
+// lex splits the query into tokens. Comments are skipped.
+func lex(s string) ([]*token, error) {
+	var (
+		toks []*token
+		rs   = []rune(s)
+	)
+	for i := 0; i < len(rs); {
+		switch r := rs[i]; {
+		case unicode.IsSpace(r) || r == ';':
+			i++
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-', r == '#':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			end := strings.Index(string(rs[i+2:]), "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("querylog: unclosed comment at position %d", i)
+			}
+			i += 2 + len([]rune(string(rs[i+2:])[:end])) + 2
+		case r == '`' || r == '"' || r == '\'':
+			var (
+				b   strings.Builder
+				end = -1
+			)
+			for j := i + 1; j < len(rs) && end == -1; j++ {
+				switch {
+				case rs[j] == '\\' && r == '\'' && j+1 < len(rs):
+					j++
+					b.WriteRune(rs[j])
+				case rs[j] == r && j+1 < len(rs) && rs[j+1] == r:
+					j++
+					b.WriteRune(r)
+				case rs[j] == r:
+					end = j
+				default:
+					b.WriteRune(rs[j])
+				}
+			}
+			if end == -1 {
+				return nil, fmt.Errorf("querylog: unclosed quote at position %d", i)
+			}
+			kind := kindQuoted
+			if r == '\'' {
+				kind = kindString
+			}
+			toks = append(toks, &token{kind: kind, text: b.String()})
+			i = end + 1
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(rs) && (rs[j] == '_' || rs[j] == '$' || unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			toks = append(toks, &token{kind: kindIdent, text: string(rs[i:j])})
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(rs) && (rs[j] == '.' || rs[j] == '_' || unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			toks = append(toks, &token{kind: kindNumber, text: string(rs[i:j])})
+			i = j
+		case r == '?', r == '$' && i+1 < len(rs) && unicode.IsDigit(rs[i+1]):
+			j := i + 1
+			for j < len(rs) && unicode.IsDigit(rs[j]) {
+				j++
+			}
+			toks = append(toks, &token{kind: kindParam, text: string(rs[i:j])})
+			i = j
+		default:
+			t := &token{kind: kindPunct, text: string(r)}
+			for _, op := range operators {
+				if i+len(op) <= len(rs) && string(rs[i:i+len(op)]) == op {
+					t.text = op
+					break
+				}
+			}
+			toks = append(toks, t)
+			i += utf8.RuneCountInString(t.text)
+		}
+	}
+	return toks, nil
+}
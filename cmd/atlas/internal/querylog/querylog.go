@@ -0,0 +1,207 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package querylog reads the queries recorded in database query logs, and extracts the
+// tables they read and the columns they filter them by. The following formats are supported:
+//
+//   - MySQL slow query logs (slow_query_log).
+//   - PostgreSQL server logs with logged statements (log_min_duration_statement or log_statement).
+//   - Plain SQL files, with one or more queries separated by semicolons.
+package querylog
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+// Query is a distinct query read from a log. Queries that differ only
+// in their literal values (e.g. WHERE id = 1 and WHERE id = 2) are merged.
+type Query struct {
+	Text     string        // text of the first occurrence
+	Count    int           // number of occurrences
+	Duration time.Duration // total duration of all occurrences, if logged
+}
+
+var (
+	// rePostgres matches a PostgreSQL log line that records a statement.
+	rePostgres = regexp.MustCompile(`\bLOG:\s+(?:duration: ([0-9.]+) ms\s+)?(?:statement|execute [^:]*): (.*)$`)
+	// reParams matches the bind parameters in a PostgreSQL DETAIL log line.
+	reParams = regexp.MustCompile(`\$(\d+) = ('(?:[^']|'')*'|NULL)`)
+	// reQueryTime matches the duration of a query in a MySQL slow log entry.
+	reQueryTime = regexp.MustCompile(`^# Query_time: ([0-9.]+)`)
+)
+
+// Parse reads the queries from the given log. Only queries that read tables, and therefore, may benefit
+// from indexes (SELECT, UPDATE and DELETE) are returned, ordered by their total duration, and then by
+// their number of occurrences. Log entries that cannot be split into statements (e.g. truncated entries)
+// are skipped.
+func Parse(r io.Reader) ([]*Query, error) {
+	var (
+		lines []string
+		pg    bool
+		sc    = bufio.NewScanner(r)
+	)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+		pg = pg || rePostgres.MatchString(sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	c := &collector{seen: make(map[string]*Query)}
+	if pg {
+		c.postgres(lines)
+	} else {
+		c.mysql(lines)
+	}
+	sort.SliceStable(c.queries, func(i, j int) bool {
+		qi, qj := c.queries[i], c.queries[j]
+		if qi.Duration != qj.Duration {
+			return qi.Duration > qj.Duration
+		}
+		return qi.Count > qj.Count
+	})
+	return c.queries, nil
+}
+
+// collector collects the distinct queries of a log.
+type collector struct {
+	queries []*Query
+	seen    map[string]*Query
+}
+
+// mysql collects the queries of a MySQL slow log. Entries are separated by comment
+// lines (e.g. "# Time:" and "# Query_time:"), and plain SQL files are read as a single
+// entry. The server headers that are written on startup are skipped along with the
+// "use" and "SET timestamp" statements, as only queries are collected.
+func (c *collector) mysql(lines []string) {
+	var (
+		b strings.Builder
+		d time.Duration
+	)
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "#") {
+			b.WriteString(l)
+			b.WriteByte('\n')
+			continue
+		}
+		if b.Len() > 0 {
+			c.add(b.String(), d)
+			b.Reset()
+			d = 0
+		}
+		if m := reQueryTime.FindStringSubmatch(l); m != nil {
+			d = parseDuration(m[1], time.Second)
+		}
+	}
+	if b.Len() > 0 {
+		c.add(b.String(), d)
+	}
+}
+
+// postgres collects the queries of a PostgreSQL log. Statements that span multiple
+// lines are continued by lines that start with a tab, and the bind parameters of
+// prepared statements are substituted from the DETAIL line that follows them.
+func (c *collector) postgres(lines []string) {
+	var (
+		b strings.Builder
+		d time.Duration
+	)
+	flush := func() {
+		if b.Len() > 0 {
+			c.add(b.String(), d)
+			b.Reset()
+		}
+	}
+	for _, l := range lines {
+		switch m := rePostgres.FindStringSubmatch(l); {
+		case m != nil:
+			flush()
+			b.WriteString(m[2])
+			d = parseDuration(m[1], time.Millisecond)
+		case strings.HasPrefix(l, "\t") && b.Len() > 0:
+			b.WriteByte('\n')
+			b.WriteString(l[1:])
+		case strings.Contains(l, "DETAIL:  parameters: ") && b.Len() > 0:
+			s := b.String()
+			params := reParams.FindAllStringSubmatch(l, -1)
+			// Substitute the parameters in reverse order, to avoid replacing $1 in $10.
+			for i := len(params) - 1; i >= 0; i-- {
+				s = strings.ReplaceAll(s, "$"+params[i][1], params[i][2])
+			}
+			b.Reset()
+			b.WriteString(s)
+			flush()
+		default:
+			flush()
+		}
+	}
+	flush()
+}
+
+// add adds the queries of the given log entry. The duration
+// is attributed to the last query of the entry.
+func (c *collector) add(entry string, d time.Duration) {
+	stmts, err := migrate.Stmts(entry)
+	if err != nil {
+		return
+	}
+	var last *Query
+	for _, s := range stmts {
+		toks, err := lex(s.Text)
+		if err != nil || len(toks) == 0 || !(toks[0].is("SELECT") || toks[0].is("WITH") || toks[0].is("UPDATE") || toks[0].is("DELETE")) {
+			continue
+		}
+		k := fingerprint(toks)
+		q, ok := c.seen[k]
+		if !ok {
+			q = &Query{Text: strings.TrimSuffix(s.Text, ";")}
+			c.seen[k] = q
+			c.queries = append(c.queries, q)
+		}
+		q.Count++
+		last = q
+	}
+	if last != nil {
+		last.Duration += d
+	}
+}
+
+// fingerprint returns the normalized form of the query tokens, where literal values are
+// replaced with placeholders, and lists of values (e.g. IN (1, 2, 3)) are collapsed.
+func fingerprint(toks []*token) string {
+	out := make([]string, 0, len(toks))
+	for _, t := range toks {
+		switch n := len(out); {
+		case t.isValue() && n > 1 && out[n-1] == "," && out[n-2] == "?":
+			out = out[:n-1]
+		case t.isValue():
+			out = append(out, "?")
+		case t.kind == kindIdent:
+			out = append(out, strings.ToLower(t.text))
+		case t.kind == kindQuoted:
+			out = append(out, strconv.Quote(t.text))
+		default:
+			out = append(out, t.text)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// parseDuration parses the given decimal duration in the given unit.
+func parseDuration(s string, unit time.Duration) time.Duration {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(f * float64(unit))
+}
@@ -0,0 +1,62 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package querylog_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/cmd/atlas/internal/querylog"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		file string
+		want []*querylog.Query
+	}{
+		{
+			file: "testdata/mysql-slow.log",
+			want: []*querylog.Query{
+				{Text: "SELECT * FROM users WHERE email = 'a8m@example.com'", Count: 2, Duration: 5500 * time.Millisecond},
+				{Text: "SELECT p.name\nFROM pets AS p\nWHERE p.owner_id IN (1, 2, 3) AND p.created_at > '2023-01-01'", Count: 1, Duration: time.Second},
+			},
+		},
+		{
+			file: "testdata/postgres.log",
+			want: []*querylog.Query{
+				{Text: "SELECT * FROM users WHERE email = 'a8m@example.com'", Count: 2, Duration: 5500 * time.Millisecond},
+				{Text: "SELECT p.name\nFROM pets AS p\nWHERE p.owner_id = '1' AND p.created_at > '2023-01-01'", Count: 1, Duration: time.Second},
+			},
+		},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(tt.file)
+			require.NoError(t, err)
+			defer f.Close()
+			qs, err := querylog.Parse(f)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, qs)
+		})
+	}
+}
+
+func TestParse_SQL(t *testing.T) {
+	qs, err := querylog.Parse(strings.NewReader(`
+-- Queries of the users page.
+SELECT * FROM users WHERE id IN (1, 2);
+SELECT * FROM users WHERE id IN (3, 4, 5);
+UPDATE users SET name = 'a8m' WHERE id = 1;
+INSERT INTO users (id) VALUES (1);
+`))
+	require.NoError(t, err)
+	require.Equal(t, []*querylog.Query{
+		{Text: "SELECT * FROM users WHERE id IN (1, 2)", Count: 2},
+		{Text: "UPDATE users SET name = 'a8m' WHERE id = 1", Count: 1},
+	}, qs)
+}
@@ -0,0 +1,244 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package chparse implements the sqlparse.Parser for the ClickHouse dialect. As there is
+// no ClickHouse grammar available for Go, statements are split into tokens, and only the
+// statements that are relevant for fixing changes and linting are recognized:
+//
+//	ALTER TABLE [db.]t [ON CLUSTER c] RENAME COLUMN [IF EXISTS] a TO b[, ...]
+//	ALTER TABLE [db.]t [ON CLUSTER c] UPDATE c = expr[, ...] WHERE cond
+//	RENAME TABLE [db.]a TO [db.]b[, ...] [ON CLUSTER c]
+//	UPDATE [db.]t SET c = expr[, ...] [WHERE cond]
+package chparse
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/parseutil"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// DriverName holds the name used for registering the parser.
+const DriverName = "clickhouse"
+
+// Parser implements the sqlparse.Parser
+type Parser struct{}
+
+// ColumnFilledBefore checks if the column was filled before the given position.
+func (p *Parser) ColumnFilledBefore(f migrate.File, t *schema.Table, c *schema.Column, pos int) (bool, error) {
+	return parseutil.MatchStmtBefore(f, pos, func(s *migrate.Stmt) (bool, error) {
+		toks, err := lex(s.Text)
+		if err != nil {
+			return false, err
+		}
+		u, ok := parseUpdate(toks)
+		// Ensure the table was updated.
+		if !ok || !u.table.matches(t) {
+			return false, nil
+		}
+		// Accept updates that fill all rows or those with NULL values as we cannot
+		// determine if NULL values were filled in case there is a custom filtering.
+		affectC := func() bool {
+			w := u.where
+			switch {
+			case w == nil:
+				return true
+			// WHERE 1, or WHERE true.
+			case len(w) == 1:
+				return w[0].text == "1" || w[0].is("TRUE")
+			// WHERE c IS NULL.
+			case len(w) == 3:
+				return w[0].ident() == c.Name && w[1].is("IS") && w[2].is("NULL")
+			// WHERE isNull(c).
+			case len(w) == 4:
+				return strings.EqualFold(w[0].text, "isNull") && w[1].text == "(" && w[2].ident() == c.Name && w[3].text == ")"
+			default:
+				return false
+			}
+		}()
+		for _, a := range u.assigns {
+			// Ensure the column was filled.
+			if affectC && a.column == c.Name && !(len(a.expr) == 1 && a.expr[0].is("NULL")) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// FixChange fixes the changes according to the given statement.
+func (p *Parser) FixChange(_ migrate.Driver, s string, changes schema.Changes) (schema.Changes, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case matchWords(toks, "ALTER", "TABLE"):
+		rs := renameColumns(toks)
+		if len(rs) == 0 {
+			break
+		}
+		modify, err := expectModify(changes)
+		if err != nil {
+			return nil, err
+		}
+		parseutil.RenameColumns(modify, rs)
+	case matchWords(toks, "RENAME", "TABLE"):
+		for _, r := range renameTables(toks) {
+			changes = parseutil.RenameTable(changes, r)
+		}
+	}
+	return changes, nil
+}
+
+// renameColumns returns all renamed columns that exist in the ALTER TABLE statement.
+func renameColumns(toks []*token) (rename []*parseutil.Rename) {
+	_, cmds, ok := alterTable(toks)
+	if !ok {
+		return nil
+	}
+	for _, c := range split(cmds, ",") {
+		if !matchWords(c, "RENAME", "COLUMN") {
+			continue
+		}
+		c = c[2:]
+		if matchWords(c, "IF", "EXISTS") {
+			c = c[2:]
+		}
+		if len(c) == 3 && c[1].is("TO") {
+			rename = append(rename, &parseutil.Rename{From: c[0].ident(), To: c[2].ident()})
+		}
+	}
+	return rename
+}
+
+// renameTables returns all renamed tables that exist in the RENAME TABLE statement.
+func renameTables(toks []*token) (rename []*parseutil.Rename) {
+	toks = toks[2:]
+	if i := indexWords(toks, "ON", "CLUSTER"); i != -1 {
+		toks = toks[:i]
+	}
+	for _, part := range split(toks, ",") {
+		i := indexWords(part, "TO")
+		if i == -1 {
+			continue
+		}
+		from, ok1 := parseName(part[:i])
+		to, ok2 := parseName(part[i+1:])
+		if ok1 && ok2 {
+			rename = append(rename, &parseutil.Rename{From: from.name, To: to.name})
+		}
+	}
+	return rename
+}
+
+type (
+	// name is a possibly qualified object name.
+	name struct {
+		schema, name string
+	}
+
+	// update describes an UPDATE statement, or an ALTER TABLE ... UPDATE mutation.
+	update struct {
+		table   *name
+		assigns []*assign
+		where   []*token
+	}
+
+	// assign is a column assignment in an update.
+	assign struct {
+		column string
+		expr   []*token
+	}
+)
+
+// matches reports if the name refers to the given table.
+func (n *name) matches(t *schema.Table) bool {
+	return n.name == t.Name && (n.schema == "" || t.Schema != nil && n.schema == t.Schema.Name)
+}
+
+// parseUpdate parses the tokens of an update statement.
+func parseUpdate(toks []*token) (*update, bool) {
+	var (
+		u    update
+		rest []*token
+	)
+	switch {
+	case matchWords(toks, "UPDATE"):
+		i := indexWords(toks, "SET")
+		if i == -1 {
+			return nil, false
+		}
+		t, ok := parseName(toks[1:i])
+		if !ok {
+			return nil, false
+		}
+		u.table, rest = t, toks[i+1:]
+	case matchWords(toks, "ALTER", "TABLE"):
+		t, cmds, ok := alterTable(toks)
+		// Mutations cannot be combined with other commands.
+		if !ok || !matchWords(cmds, "UPDATE") {
+			return nil, false
+		}
+		u.table, rest = t, cmds[1:]
+	default:
+		return nil, false
+	}
+	if i := indexWords(rest, "WHERE"); i != -1 {
+		rest, u.where = rest[:i], rest[i+1:]
+	}
+	for _, a := range split(rest, ",") {
+		if len(a) < 3 || a[1].text != "=" {
+			return nil, false
+		}
+		u.assigns = append(u.assigns, &assign{column: a[0].ident(), expr: a[2:]})
+	}
+	return &u, len(u.assigns) > 0
+}
+
+// alterTable returns the table name of an ALTER TABLE statement, and the tokens of its commands.
+func alterTable(toks []*token) (*name, []*token, bool) {
+	toks = toks[2:]
+	n := 1
+	if len(toks) > 2 && toks[1].text == "." {
+		n = 3
+	}
+	if len(toks) < n {
+		return nil, nil, false
+	}
+	t, ok := parseName(toks[:n])
+	if !ok {
+		return nil, nil, false
+	}
+	toks = toks[n:]
+	if matchWords(toks, "ON", "CLUSTER") && len(toks) > 2 {
+		toks = toks[3:]
+	}
+	return t, toks, true
+}
+
+// parseName parses a possibly qualified name.
+func parseName(toks []*token) (*name, bool) {
+	switch {
+	case len(toks) == 1 && toks[0].isName():
+		return &name{name: toks[0].ident()}, true
+	case len(toks) == 3 && toks[0].isName() && toks[1].text == "." && toks[2].isName():
+		return &name{schema: toks[0].ident(), name: toks[2].ident()}, true
+	default:
+		return nil, false
+	}
+}
+
+func expectModify(changes schema.Changes) (*schema.ModifyTable, error) {
+	if len(changes) != 1 {
+		return nil, fmt.Errorf("unexpected number of changes: %d", len(changes))
+	}
+	modify, ok := changes[0].(*schema.ModifyTable)
+	if !ok {
+		return nil, fmt.Errorf("expected modify-table change for alter-table statement, but got: %T", changes[0])
+	}
+	return modify, nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package chparse_test
+
+import (
+	"strconv"
+	"testing"
+
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/chparse"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixChange_RenameColumns(t *testing.T) {
+	var p chparse.Parser
+	_, err := p.FixChange(
+		nil,
+		"ALTER TABLE t RENAME COLUMN c1 TO c2",
+		nil,
+	)
+	require.Error(t, err)
+
+	_, err = p.FixChange(
+		nil,
+		"ALTER TABLE t RENAME COLUMN c1 TO c2",
+		schema.Changes{&schema.AddTable{}},
+	)
+	require.Error(t, err)
+
+	changes, err := p.FixChange(
+		nil,
+		"ALTER TABLE db.`t` ON CLUSTER c RENAME COLUMN IF EXISTS `c1` TO c2, RENAME COLUMN c3 TO c4, RENAME COLUMN c4 TO c5",
+		schema.Changes{
+			&schema.ModifyTable{
+				Changes: schema.Changes{
+					&schema.DropColumn{C: schema.NewColumn("c1")},
+					&schema.AddColumn{C: schema.NewColumn("c2")},
+					&schema.DropColumn{C: schema.NewColumn("c3")},
+					&schema.AddColumn{C: schema.NewColumn("c5")},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		schema.Changes{
+			&schema.ModifyTable{
+				Changes: schema.Changes{
+					&schema.RenameColumn{From: schema.NewColumn("c1"), To: schema.NewColumn("c2")},
+					&schema.RenameColumn{From: schema.NewColumn("c3"), To: schema.NewColumn("c5")},
+				},
+			},
+		},
+		changes,
+	)
+}
+
+func TestFixChange_RenameTable(t *testing.T) {
+	var p chparse.Parser
+	changes, err := p.FixChange(
+		nil,
+		"RENAME TABLE db.t1 TO db.t2, t3 TO t4 ON CLUSTER c",
+		schema.Changes{
+			&schema.DropTable{T: schema.NewTable("t1")},
+			&schema.AddTable{T: schema.NewTable("t2")},
+			&schema.DropTable{T: schema.NewTable("t3")},
+			&schema.AddTable{T: schema.NewTable("t4")},
+			&schema.AddTable{T: schema.NewTable("t5")},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		schema.Changes{
+			&schema.RenameTable{From: schema.NewTable("t1"), To: schema.NewTable("t2")},
+			&schema.RenameTable{From: schema.NewTable("t3"), To: schema.NewTable("t4")},
+			&schema.AddTable{T: schema.NewTable("t5")},
+		},
+		changes,
+	)
+
+	_, err = p.FixChange(nil, "ALTER TABLE t COMMENT COLUMN c 'unclosed", nil)
+	require.EqualError(t, err, "chparse: unclosed quote at position 31")
+}
+
+func TestColumnFilledBefore(t *testing.T) {
+	for i, tt := range []struct {
+		file       string
+		pos        int
+		wantFilled bool
+		wantErr    bool
+	}{
+		{
+			file: `ALTER TABLE t UPDATE c = NULL WHERE 1;`,
+			pos:  100,
+		},
+		{
+			file: `ALTER TABLE t UPDATE c = 2 WHERE 1;`,
+		},
+		{
+			file:       `ALTER TABLE t UPDATE c = 2 WHERE 1;`,
+			pos:        100,
+			wantFilled: true,
+		},
+		{
+			file:       `ALTER TABLE t ON CLUSTER c UPDATE c = toInt32(2, 1), d = 1 WHERE c IS NULL;`,
+			pos:        100,
+			wantFilled: true,
+		},
+		{
+			file:       `ALTER TABLE t UPDATE c = 2 WHERE isNull(c);`,
+			pos:        100,
+			wantFilled: true,
+		},
+		{
+			file:       `ALTER TABLE t UPDATE c = 2 WHERE c IS NOT NULL;`,
+			pos:        100,
+			wantFilled: false,
+		},
+		{
+			file:       `ALTER TABLE t2 UPDATE c = 2 WHERE 1;`,
+			pos:        100,
+			wantFilled: false,
+		},
+		{
+			file:       `UPDATE t SET c = 2 WHERE c IS NULL;`,
+			pos:        100,
+			wantFilled: true,
+		},
+		{
+			file: `
+		ALTER TABLE t MODIFY COLUMN c Int32;
+		ALTER TABLE t UPDATE c = 2 WHERE c IS NULL;
+		`,
+			pos:        2,
+			wantFilled: false,
+		},
+		{
+			file: `
+		ALTER TABLE t UPDATE c = 2 WHERE c IS NULL;
+		ALTER TABLE t MODIFY COLUMN c Int32;
+		`,
+			pos:        50,
+			wantFilled: true,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var (
+				p chparse.Parser
+				f = migrate.NewLocalFile("file", []byte(tt.file))
+			)
+			filled, err := p.ColumnFilledBefore(f, schema.NewTable("t"), schema.NewColumn("c"), tt.pos)
+			require.Equal(t, err != nil, tt.wantErr, err)
+			require.Equal(t, filled, tt.wantFilled)
+		})
+	}
+}
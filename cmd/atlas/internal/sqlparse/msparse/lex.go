@@ -0,0 +1,183 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package msparse
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Token kinds.
+const (
+	kindIdent  = iota // bare identifier or keyword
+	kindQuoted        // quoted identifier
+	kindString        // string literal
+	kindNumber        // numeric literal
+	kindPunct         // punctuation or operator
+)
+
+// token is a single lexical token of a statement.
+type token struct {
+	kind int
+	text string // unquoted text
+}
+
+// is reports if the token is the given keyword.
+func (t *token) is(kw string) bool {
+	return t.kind == kindIdent && strings.EqualFold(t.text, kw)
+}
+
+// isName reports if the token may be used as an object name.
+func (t *token) isName() bool {
+	return t.kind == kindIdent || t.kind == kindQuoted
+}
+
+// ident returns the identifier the token represents.
+func (t *token) ident() string {
+	if !t.isName() {
+		return ""
+	}
+	return t.text
+}
+
+// lex splits the statement into tokens. Comments are skipped.
+func lex(s string) ([]*token, error) {
+	var (
+		toks []*token
+		rs   = []rune(s)
+	)
+	for i := 0; i < len(rs); {
+		switch r := rs[i]; {
+		case unicode.IsSpace(r) || r == ';':
+			i++
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			end := strings.Index(string(rs[i+2:]), "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("msparse: unclosed comment at position %d", i)
+			}
+			i += 2 + len([]rune(string(rs[i+2:])[:end])) + 2
+		// Unicode string literals, such as N'text'.
+		case (r == 'N' || r == 'n') && i+1 < len(rs) && rs[i+1] == '\'':
+			i++
+		case r == '[' || r == '"' || r == '\'':
+			var (
+				b      strings.Builder
+				end    = -1
+				closer = r
+			)
+			if r == '[' {
+				closer = ']'
+			}
+			for j := i + 1; j < len(rs); j++ {
+				switch {
+				case rs[j] == closer && j+1 < len(rs) && rs[j+1] == closer:
+					j++
+					b.WriteRune(closer)
+				case rs[j] == closer:
+					end = j
+				default:
+					b.WriteRune(rs[j])
+				}
+				if end != -1 {
+					break
+				}
+			}
+			if end == -1 {
+				return nil, fmt.Errorf("msparse: unclosed quote at position %d", i)
+			}
+			kind := kindQuoted
+			if r == '\'' {
+				kind = kindString
+			}
+			toks = append(toks, &token{kind: kind, text: b.String()})
+			i = end + 1
+		// Variables (@v) and temporary tables (#t) are lexed as identifiers.
+		case r == '_' || r == '@' || r == '#' || unicode.IsLetter(r):
+			j := i + 1
+			for j < len(rs) && (rs[j] == '_' || rs[j] == '$' || rs[j] == '@' || rs[j] == '#' || unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			toks = append(toks, &token{kind: kindIdent, text: string(rs[i:j])})
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(rs) && (rs[j] == '.' || rs[j] == '_' || unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			toks = append(toks, &token{kind: kindNumber, text: string(rs[i:j])})
+			i = j
+		default:
+			toks = append(toks, &token{kind: kindPunct, text: string(r)})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+// matchWords reports if the tokens start with the given keywords.
+func matchWords(toks []*token, words ...string) bool {
+	if len(toks) < len(words) {
+		return false
+	}
+	for i, w := range words {
+		if !toks[i].is(w) {
+			return false
+		}
+	}
+	return true
+}
+
+// indexWords returns the index of the first occurrence of the given
+// keywords outside of parentheses, or -1 if they are not present.
+func indexWords(toks []*token, words ...string) int {
+	depth := 0
+	for i, t := range toks {
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth == 0 && matchWords(toks[i:], words...) {
+			return i
+		}
+	}
+	return -1
+}
+
+// split splits the tokens by the given separator, ignoring
+// separators that appear inside parentheses.
+func split(toks []*token, sep string) [][]*token {
+	var (
+		parts [][]*token
+		depth int
+		start int
+	)
+	for i, t := range toks {
+		if t.kind != kindPunct {
+			continue
+		}
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, toks[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(toks) {
+		parts = append(parts, toks[start:])
+	}
+	return parts
+}
@@ -0,0 +1,222 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package msparse implements the sqlparse.Parser for the SQL Server (T-SQL) dialect. As
+// there is no T-SQL grammar available for Go, statements are split into tokens, and only
+// the statements that are relevant for fixing changes and linting are recognized:
+//
+//	EXEC[UTE] sp_rename 'obj', 'new'[, 'COLUMN' | 'INDEX' | 'OBJECT']
+//	UPDATE [s.]t SET c = expr[, ...] [WHERE cond]
+package msparse
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/parseutil"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// DriverName holds the name used for registering the parser.
+const DriverName = "sqlserver"
+
+// Parser implements the sqlparse.Parser
+type Parser struct{}
+
+// ColumnFilledBefore checks if the column was filled before the given position.
+func (p *Parser) ColumnFilledBefore(f migrate.File, t *schema.Table, c *schema.Column, pos int) (bool, error) {
+	return parseutil.MatchStmtBefore(f, pos, func(s *migrate.Stmt) (bool, error) {
+		toks, err := lex(s.Text)
+		if err != nil {
+			return false, err
+		}
+		u, ok := parseUpdate(toks)
+		// Ensure the table was updated.
+		if !ok || !u.table.matches(t) {
+			return false, nil
+		}
+		// Accept updates that fill all rows or those with NULL values as we cannot
+		// determine if NULL values were filled in case there is a custom filtering.
+		affectC := func() bool {
+			w := u.where
+			switch {
+			case w == nil:
+				return true
+			// WHERE 1 = 1.
+			case len(w) == 3 && w[1].text == "=":
+				return w[0].kind == kindNumber && w[0].text == w[2].text
+			// WHERE c IS NULL.
+			case len(w) == 3:
+				return w[0].ident() == c.Name && w[1].is("IS") && w[2].is("NULL")
+			default:
+				return false
+			}
+		}()
+		for _, a := range u.assigns {
+			// Ensure the column was filled.
+			if affectC && a.column == c.Name && !(len(a.expr) == 1 && a.expr[0].is("NULL")) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// FixChange fixes the changes according to the given statement.
+func (p *Parser) FixChange(_ migrate.Driver, s string, changes schema.Changes) (schema.Changes, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := parseRename(toks)
+	if !ok {
+		return changes, nil
+	}
+	switch strings.ToUpper(r.objtype) {
+	case "":
+		changes = parseutil.RenameTable(changes, &parseutil.Rename{From: r.from, To: r.to})
+	case "COLUMN":
+		modify, err := expectModify(changes)
+		if err != nil {
+			return nil, err
+		}
+		parseutil.RenameColumn(modify, &parseutil.Rename{From: r.from, To: r.to})
+	case "INDEX":
+		modify, err := expectModify(changes)
+		if err != nil {
+			return nil, err
+		}
+		parseutil.RenameIndex(modify, &parseutil.Rename{From: r.from, To: r.to})
+	case "OBJECT":
+		modify, err := expectModify(changes)
+		if err != nil {
+			return nil, err
+		}
+		parseutil.RenameForeignKey(modify, &parseutil.Rename{From: r.from, To: r.to})
+	}
+	return changes, nil
+}
+
+type (
+	// name is a possibly qualified object name.
+	name struct {
+		schema, name string
+	}
+
+	// rename describes a call to the sp_rename procedure.
+	rename struct {
+		from, to, objtype string
+	}
+
+	// update describes an UPDATE statement.
+	update struct {
+		table   *name
+		assigns []*assign
+		where   []*token
+	}
+
+	// assign is a column assignment in an update.
+	assign struct {
+		column string
+		expr   []*token
+	}
+)
+
+// matches reports if the name refers to the given table.
+func (n *name) matches(t *schema.Table) bool {
+	return n.name == t.Name && (n.schema == "" || t.Schema != nil && n.schema == t.Schema.Name)
+}
+
+// parseRename parses the tokens of an sp_rename call. The returned
+// names are unqualified, as the new name of an object cannot be qualified.
+func parseRename(toks []*token) (*rename, bool) {
+	if len(toks) == 0 || !toks[0].is("EXEC") && !toks[0].is("EXECUTE") {
+		return nil, false
+	}
+	toks = toks[1:]
+	if matchWords(toks, "sys") && len(toks) > 2 && toks[1].text == "." {
+		toks = toks[2:]
+	}
+	if !matchWords(toks, "sp_rename") {
+		return nil, false
+	}
+	var args []string
+	for _, a := range split(toks[1:], ",") {
+		// Named arguments, such as @objname = 'name'.
+		if len(a) == 3 && strings.HasPrefix(a[0].text, "@") && a[1].text == "=" {
+			a = a[2:]
+		}
+		if len(a) != 1 || a[0].kind != kindString {
+			return nil, false
+		}
+		args = append(args, a[0].text)
+	}
+	if len(args) < 2 || len(args) > 3 {
+		return nil, false
+	}
+	parts, err := lex(args[0])
+	if err != nil || len(parts) == 0 || !parts[len(parts)-1].isName() {
+		return nil, false
+	}
+	r := &rename{from: parts[len(parts)-1].ident(), to: args[1]}
+	if len(args) == 3 {
+		r.objtype = args[2]
+	}
+	return r, true
+}
+
+// parseUpdate parses the tokens of an update statement.
+func parseUpdate(toks []*token) (*update, bool) {
+	if !matchWords(toks, "UPDATE") {
+		return nil, false
+	}
+	i := indexWords(toks, "SET")
+	if i == -1 {
+		return nil, false
+	}
+	t, ok := parseName(toks[1:i])
+	if !ok {
+		return nil, false
+	}
+	u, rest := &update{table: t}, toks[i+1:]
+	if i := indexWords(rest, "WHERE"); i != -1 {
+		rest, u.where = rest[:i], rest[i+1:]
+	}
+	// Updates that join other tables are not supported, as
+	// the target table may be referenced by its alias.
+	if indexWords(rest, "FROM") != -1 {
+		return nil, false
+	}
+	for _, a := range split(rest, ",") {
+		if len(a) < 3 || a[1].text != "=" {
+			return nil, false
+		}
+		u.assigns = append(u.assigns, &assign{column: a[0].ident(), expr: a[2:]})
+	}
+	return u, len(u.assigns) > 0
+}
+
+// parseName parses a possibly qualified name.
+func parseName(toks []*token) (*name, bool) {
+	switch {
+	case len(toks) == 1 && toks[0].isName():
+		return &name{name: toks[0].ident()}, true
+	case len(toks) == 3 && toks[0].isName() && toks[1].text == "." && toks[2].isName():
+		return &name{schema: toks[0].ident(), name: toks[2].ident()}, true
+	default:
+		return nil, false
+	}
+}
+
+func expectModify(changes schema.Changes) (*schema.ModifyTable, error) {
+	if len(changes) != 1 {
+		return nil, fmt.Errorf("unexpected number of changes: %d", len(changes))
+	}
+	modify, ok := changes[0].(*schema.ModifyTable)
+	if !ok {
+		return nil, fmt.Errorf("expected modify-table change for sp_rename statement, but got: %T", changes[0])
+	}
+	return modify, nil
+}
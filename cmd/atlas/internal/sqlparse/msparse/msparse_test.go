@@ -0,0 +1,184 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package msparse_test
+
+import (
+	"strconv"
+	"testing"
+
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/msparse"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixChange_RenameColumn(t *testing.T) {
+	var p msparse.Parser
+	_, err := p.FixChange(
+		nil,
+		"EXEC sp_rename 'dbo.t.c1', 'c2', 'COLUMN'",
+		nil,
+	)
+	require.Error(t, err)
+
+	_, err = p.FixChange(
+		nil,
+		"EXEC sp_rename 'dbo.t.c1', 'c2', 'COLUMN'",
+		schema.Changes{&schema.AddTable{}},
+	)
+	require.Error(t, err)
+
+	changes, err := p.FixChange(
+		nil,
+		"EXECUTE sys.sp_rename @objname = N'[dbo].[t].[c1]', @newname = N'c2', @objtype = N'COLUMN';",
+		schema.Changes{
+			&schema.ModifyTable{
+				Changes: schema.Changes{
+					&schema.DropColumn{C: schema.NewColumn("c1")},
+					&schema.AddColumn{C: schema.NewColumn("c2")},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		schema.Changes{
+			&schema.ModifyTable{
+				Changes: schema.Changes{
+					&schema.RenameColumn{From: schema.NewColumn("c1"), To: schema.NewColumn("c2")},
+				},
+			},
+		},
+		changes,
+	)
+}
+
+func TestFixChange_RenameIndex(t *testing.T) {
+	var p msparse.Parser
+	changes, err := p.FixChange(
+		nil,
+		"EXEC sp_rename 'dbo.t.i1', 'i2', 'INDEX'",
+		schema.Changes{
+			&schema.ModifyTable{
+				Changes: schema.Changes{
+					&schema.DropIndex{I: schema.NewIndex("i1")},
+					&schema.AddIndex{I: schema.NewIndex("i2")},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		schema.Changes{
+			&schema.ModifyTable{
+				Changes: schema.Changes{
+					&schema.RenameIndex{From: schema.NewIndex("i1"), To: schema.NewIndex("i2")},
+				},
+			},
+		},
+		changes,
+	)
+}
+
+func TestFixChange_RenameTable(t *testing.T) {
+	var p msparse.Parser
+	changes, err := p.FixChange(
+		nil,
+		"EXEC sp_rename 'dbo.t1', 't2'",
+		schema.Changes{
+			&schema.DropTable{T: schema.NewTable("t1")},
+			&schema.AddTable{T: schema.NewTable("t2")},
+			&schema.AddTable{T: schema.NewTable("t3")},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		schema.Changes{
+			&schema.RenameTable{From: schema.NewTable("t1"), To: schema.NewTable("t2")},
+			&schema.AddTable{T: schema.NewTable("t3")},
+		},
+		changes,
+	)
+
+	_, err = p.FixChange(nil, "EXEC sp_rename 'unclosed", nil)
+	require.EqualError(t, err, "msparse: unclosed quote at position 15")
+}
+
+func TestColumnFilledBefore(t *testing.T) {
+	for i, tt := range []struct {
+		file       string
+		pos        int
+		wantFilled bool
+		wantErr    bool
+	}{
+		{
+			file: `UPDATE t SET c = NULL;`,
+			pos:  100,
+		},
+		{
+			file: `UPDATE t SET c = 2;`,
+		},
+		{
+			file:       `UPDATE t SET c = 2;`,
+			pos:        100,
+			wantFilled: true,
+		},
+		{
+			file:       `UPDATE [t] SET [c] = N'text', d = 1 WHERE 1 = 1;`,
+			pos:        100,
+			wantFilled: true,
+		},
+		{
+			file:       `UPDATE t SET c = 2 WHERE [c] IS NULL;`,
+			pos:        100,
+			wantFilled: true,
+		},
+		{
+			file:       `UPDATE t SET c = 2 WHERE c IS NOT NULL;`,
+			pos:        100,
+			wantFilled: false,
+		},
+		{
+			file:       `UPDATE t SET c = 2 FROM t JOIN t2 ON t.id = t2.id;`,
+			pos:        100,
+			wantFilled: false,
+		},
+		{
+			file:       `UPDATE t2 SET c = 2;`,
+			pos:        100,
+			wantFilled: false,
+		},
+		{
+			file: `
+		ALTER TABLE t ALTER COLUMN c int NOT NULL;
+		UPDATE t SET c = 2 WHERE c IS NULL;
+		`,
+			pos:        2,
+			wantFilled: false,
+		},
+		{
+			file: `
+		UPDATE t SET c = 2 WHERE c IS NULL;
+		ALTER TABLE t ALTER COLUMN c int NOT NULL;
+		`,
+			pos:        50,
+			wantFilled: true,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var (
+				p msparse.Parser
+				f = migrate.NewLocalFile("file", []byte(tt.file))
+			)
+			filled, err := p.ColumnFilledBefore(f, schema.NewTable("t"), schema.NewColumn("c"), tt.pos)
+			require.Equal(t, err != nil, tt.wantErr, err)
+			require.Equal(t, filled, tt.wantFilled)
+		})
+	}
+}
@@ -78,6 +78,9 @@ func (p *Parser) FixChange(d migrate.Driver, s string, changes schema.Changes) (
 		for _, r := range renameIndexes(stmt) {
 			parseutil.RenameIndex(modify, r)
 		}
+		for _, r := range renameForeignKeys(stmt) {
+			parseutil.RenameForeignKey(modify, r)
+		}
 	case *ast.RenameTableStmt:
 		for _, t := range stmt.TableToTables {
 			changes = parseutil.RenameTable(
@@ -91,6 +94,27 @@ func (p *Parser) FixChange(d migrate.Driver, s string, changes schema.Changes) (
 	return changes, nil
 }
 
+// OnlineStmt reports if the statement explicitly uses the INPLACE or INSTANT
+// algorithm, and therefore, it is not executed by copying the table.
+func (p *Parser) OnlineStmt(s string) (bool, error) {
+	stmt, err := parser.New().ParseOneStmt(s, "", "")
+	if err != nil {
+		return false, err
+	}
+	online := func(a ast.AlgorithmType) bool {
+		return a == ast.AlgorithmTypeInplace || a == ast.AlgorithmTypeInstant
+	}
+	switch stmt := stmt.(type) {
+	case *ast.AlterTableStmt:
+		return slices.IndexFunc(stmt.Specs, func(s *ast.AlterTableSpec) bool {
+			return s.Tp == ast.AlterTableAlgorithm && online(s.Algorithm)
+		}) != -1, nil
+	case *ast.CreateIndexStmt:
+		return stmt.LockAlg != nil && online(stmt.LockAlg.AlgorithmTp), nil
+	}
+	return false, nil
+}
+
 // renameColumns returns all renamed columns that exist in the statement.
 func renameColumns(stmt *ast.AlterTableStmt) (rename []*parseutil.Rename) {
 	for _, s := range stmt.Specs {
@@ -117,6 +141,27 @@ func renameIndexes(stmt *ast.AlterTableStmt) (rename []*parseutil.Rename) {
 	return
 }
 
+// renameForeignKeys returns the foreign keys that might be renamed in the statement.
+// MySQL does not support renaming foreign keys, and they are renamed by dropping and
+// re-creating them under a new name.
+func renameForeignKeys(stmt *ast.AlterTableStmt) (rename []*parseutil.Rename) {
+	var added, drop []string
+	for _, s := range stmt.Specs {
+		switch {
+		case s.Tp == ast.AlterTableDropForeignKey:
+			drop = append(drop, s.Name)
+		case s.Tp == ast.AlterTableAddConstraint && s.Constraint != nil && s.Constraint.Tp == ast.ConstraintForeignKey && s.Constraint.Name != "":
+			added = append(added, s.Constraint.Name)
+		}
+	}
+	for _, from := range drop {
+		for _, to := range added {
+			rename = append(rename, &parseutil.Rename{From: from, To: to})
+		}
+	}
+	return
+}
+
 // renameTable fixes the changes from ALTER command with RENAME into ModifyTable and RenameTable.
 func renameTable(drv migrate.Driver, stmt *ast.AlterTableStmt, changes schema.Changes) (schema.Changes, error) {
 	var r *ast.AlterTableSpec
@@ -109,6 +109,40 @@ func TestFixChange_RenameIndexes(t *testing.T) {
 	)
 }
 
+func TestFixChange_RenameForeignKeys(t *testing.T) {
+	var (
+		p   myparse.Parser
+		c1  = schema.NewIntColumn("owner_id", "int")
+		ref = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		fk  = func(name string) *schema.ForeignKey {
+			return schema.NewForeignKey(name).AddColumns(c1).SetRefTable(ref).AddRefColumns(ref.Columns[0])
+		}
+		drop = &schema.DropForeignKey{F: fk("f1")}
+		add  = &schema.AddForeignKey{F: fk("f2")}
+	)
+	changes, err := p.FixChange(
+		nil,
+		"ALTER TABLE t DROP FOREIGN KEY f1, ADD CONSTRAINT f2 FOREIGN KEY (owner_id) REFERENCES users (id)",
+		schema.Changes{&schema.ModifyTable{Changes: schema.Changes{drop, add}}},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		schema.Changes{&schema.ModifyTable{Changes: schema.Changes{&schema.RenameForeignKey{From: drop.F, To: add.F}}}},
+		changes,
+	)
+
+	// Re-creating a foreign key with a different definition.
+	add = &schema.AddForeignKey{F: fk("f2").SetOnDelete(schema.Cascade)}
+	changes, err = p.FixChange(
+		nil,
+		"ALTER TABLE t DROP FOREIGN KEY f1, ADD CONSTRAINT f2 FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE",
+		schema.Changes{&schema.ModifyTable{Changes: schema.Changes{drop, add}}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, schema.Changes{&schema.ModifyTable{Changes: schema.Changes{drop, add}}}, changes)
+}
+
 func TestFixChange_RenameTable(t *testing.T) {
 	var p myparse.Parser
 	changes, err := p.FixChange(
@@ -249,3 +283,40 @@ type mockDriver struct {
 func (d mockDriver) TableDiff(_, _ *schema.Table) ([]schema.Change, error) {
 	return d.changes, nil
 }
+
+func FuzzFixChange(f *testing.F) {
+	for _, s := range []string{
+		"ALTER TABLE t RENAME COLUMN c1 TO c2",
+		"ALTER TABLE t RENAME TO t2",
+		"UPDATE t SET c = 1 WHERE c IS NULL",
+		"CREATE TABLE t (c int)",
+		"SELECT (1",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		var p myparse.Parser
+		// Parsing arbitrary statements must not panic.
+		p.FixChange(nil, s, nil)
+		p.FixChange(nil, s, schema.Changes{&schema.ModifyTable{T: schema.NewTable("t")}})
+	})
+}
+
+func TestOnlineStmt(t *testing.T) {
+	var p myparse.Parser
+	for stmt, want := range map[string]bool{
+		"ALTER TABLE t MODIFY COLUMN c bigint":                        false,
+		"ALTER TABLE t MODIFY COLUMN c bigint, ALGORITHM=COPY":        false,
+		"ALTER TABLE t MODIFY COLUMN c bigint, ALGORITHM=INPLACE":     true,
+		"ALTER TABLE t ADD COLUMN c int, ALGORITHM=INSTANT":           true,
+		"CREATE FULLTEXT INDEX i ON t (c)":                            false,
+		"CREATE INDEX i ON t (c) ALGORITHM=INPLACE LOCK=NONE":         true,
+		"ALTER TABLE t ADD INDEX i (c), ALGORITHM=INPLACE, LOCK=NONE": true,
+	} {
+		online, err := p.OnlineStmt(stmt)
+		require.NoError(t, err)
+		require.Equal(t, want, online, stmt)
+	}
+	_, err := p.OnlineStmt("ALTER TABLE")
+	require.Error(t, err)
+}
@@ -35,6 +35,21 @@ func RenameColumn(modify *schema.ModifyTable, r *Rename) {
 	}
 }
 
+// RenameColumns patches DROP/ADD column commands to RENAME for statements that rename
+// multiple columns at once. Chained renames, such as "a TO b, b TO c", are collapsed
+// to a single rename ("a TO c"), as the intermediate names do not exist in the changes.
+func RenameColumns(modify *schema.ModifyTable, rs []*Rename) {
+	for i := 0; i < len(rs); i++ {
+		r := &Rename{From: rs[i].From, To: rs[i].To}
+		for j := i + 1; j < len(rs); j++ {
+			if rs[j].From == r.To {
+				r.To = rs[j].To
+			}
+		}
+		RenameColumn(modify, r)
+	}
+}
+
 // RenameIndex patches DROP/ADD index commands to RENAME.
 func RenameIndex(modify *schema.ModifyTable, r *Rename) {
 	changes := schema.Changes(modify.Changes)
@@ -50,6 +65,45 @@ func RenameIndex(modify *schema.ModifyTable, r *Rename) {
 	}
 }
 
+// RenameForeignKey patches DROP/ADD foreign-key commands to RENAME. Unlike columns
+// and indexes, foreign keys are also renamed by re-creating them under a new name
+// (e.g. on MySQL). Hence, the commands are patched only if both foreign keys share
+// the same definition.
+func RenameForeignKey(modify *schema.ModifyTable, r *Rename) {
+	changes := schema.Changes(modify.Changes)
+	i := changes.IndexDropForeignKey(r.From)
+	j := changes.IndexAddForeignKey(r.To)
+	if i == -1 || j == -1 {
+		return
+	}
+	from, to := changes[i].(*schema.DropForeignKey).F, changes[j].(*schema.AddForeignKey).F
+	if sameForeignKey(from, to) {
+		changes[max(i, j)] = &schema.RenameForeignKey{From: from, To: to}
+		changes.RemoveIndex(min(i, j))
+		modify.Changes = changes
+	}
+}
+
+// sameForeignKey reports if the two foreign keys share the same definition, ignoring their names.
+func sameForeignKey(f1, f2 *schema.ForeignKey) bool {
+	if len(f1.Columns) != len(f2.Columns) || len(f1.RefColumns) != len(f2.RefColumns) ||
+		f1.OnUpdate != f2.OnUpdate || f1.OnDelete != f2.OnDelete ||
+		(f1.RefTable == nil) != (f2.RefTable == nil) || f1.RefTable != nil && f1.RefTable.Name != f2.RefTable.Name {
+		return false
+	}
+	for i := range f1.Columns {
+		if f1.Columns[i].Name != f2.Columns[i].Name {
+			return false
+		}
+	}
+	for i := range f1.RefColumns {
+		if f1.RefColumns[i].Name != f2.RefColumns[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
 // RenameTable patches DROP/ADD table commands to RENAME.
 func RenameTable(changes schema.Changes, r *Rename) schema.Changes {
 	i := changes.IndexDropTable(r.From)
@@ -99,26 +153,23 @@ func StmtDecls(f migrate.File) ([]*migrate.Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-	s2 := make([]*migrate.Stmt, len(s1))
+	var (
+		p  int
+		s2 = make([]*migrate.Stmt, len(s1))
+	)
 	for i := range s1 {
-		p, err := pos(f, s1[i])
-		if err != nil {
-			return nil, err
+		// Search each statement after its predecessor, as statements
+		// may repeat in a file (e.g. "END;" of procedural blocks).
+		j := bytes.Index(f.Bytes()[p:], []byte(s1[i]))
+		if j == -1 {
+			return nil, fmt.Errorf("statement %q was not found in %q", s1[i], f.Bytes())
 		}
-		s2[i] = &migrate.Stmt{Pos: p, Text: s1[i]}
+		s2[i] = &migrate.Stmt{Pos: p + j, Text: s1[i]}
+		p += j + len(s1[i])
 	}
 	return s2, nil
 }
 
-// pos returns the position of a statement in migration file.
-func pos(f migrate.File, stmt string) (int, error) {
-	i := bytes.Index(f.Bytes(), []byte(stmt))
-	if i == -1 {
-		return 0, fmt.Errorf("statement %q was not found in %q", stmt, f.Bytes())
-	}
-	return i, nil
-}
-
 func max(i, j int) int {
 	if i > j {
 		return i
@@ -65,6 +65,15 @@ func (p *Parser) FixChange(_ migrate.Driver, s string, changes schema.Changes) (
 			}
 			parseutil.RenameColumn(modify, r)
 		}
+		if rs := renameForeignKeys(stmt); len(rs) > 0 {
+			modify, err := expectModify(changes)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range rs {
+				parseutil.RenameForeignKey(modify, r)
+			}
+		}
 	case *tree.RenameIndex:
 		modify, err := expectModify(changes)
 		if err != nil {
@@ -83,6 +92,23 @@ func (p *Parser) FixChange(_ migrate.Driver, s string, changes schema.Changes) (
 	return changes, nil
 }
 
+// OnlineStmt reports if the statement creates or drops an
+// index concurrently, without blocking writes to the table.
+func (p *Parser) OnlineStmt(s string) (bool, error) {
+	stmt, err := parser.ParseOne(s)
+	if err != nil {
+		return false, err
+	}
+	switch stmt := stmt.AST.(type) {
+	case *tree.CreateIndex:
+		return stmt.Concurrently, nil
+	case *tree.DropIndex:
+		return stmt.Concurrently, nil
+	default:
+		return false, nil
+	}
+}
+
 // renameColumn returns the renamed column exists in the statement, is any.
 func renameColumn(stmt *tree.AlterTable) (*parseutil.Rename, bool) {
 	for _, c := range stmt.Cmds {
@@ -96,6 +122,36 @@ func renameColumn(stmt *tree.AlterTable) (*parseutil.Rename, bool) {
 	return nil, false
 }
 
+// renameForeignKeys returns the foreign keys that might be renamed in the statement,
+// either using RENAME CONSTRAINT, or by dropping and re-creating them under a new name.
+func renameForeignKeys(stmt *tree.AlterTable) []*parseutil.Rename {
+	var (
+		rename      []*parseutil.Rename
+		added, drop []string
+	)
+	for _, c := range stmt.Cmds {
+		switch c := c.(type) {
+		case *tree.AlterTableRenameConstraint:
+			rename = append(rename, &parseutil.Rename{
+				From: c.Constraint.String(),
+				To:   c.NewName.String(),
+			})
+		case *tree.AlterTableDropConstraint:
+			drop = append(drop, c.Constraint.String())
+		case *tree.AlterTableAddConstraint:
+			if fk, ok := c.ConstraintDef.(*tree.ForeignKeyConstraintTableDef); ok && fk.Name != "" {
+				added = append(added, fk.Name.String())
+			}
+		}
+	}
+	for _, from := range drop {
+		for _, to := range added {
+			rename = append(rename, &parseutil.Rename{From: from, To: to})
+		}
+	}
+	return rename
+}
+
 func expectModify(changes schema.Changes) (*schema.ModifyTable, error) {
 	if len(changes) != 1 {
 		return nil, fmt.Errorf("unexected number fo changes: %d", len(changes))
@@ -85,6 +85,53 @@ func TestFixChange_RenameIndexes(t *testing.T) {
 	)
 }
 
+func TestFixChange_RenameForeignKeys(t *testing.T) {
+	var (
+		p   pgparse.Parser
+		c1  = schema.NewIntColumn("owner_id", "int")
+		ref = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		fk  = func(name string) *schema.ForeignKey {
+			return schema.NewForeignKey(name).AddColumns(c1).SetRefTable(ref).AddRefColumns(ref.Columns[0])
+		}
+		drop = &schema.DropForeignKey{F: fk("f1")}
+		add  = &schema.AddForeignKey{F: fk("f2")}
+	)
+	changes, err := p.FixChange(
+		nil,
+		"ALTER TABLE t RENAME CONSTRAINT f1 TO f2",
+		schema.Changes{&schema.ModifyTable{Changes: schema.Changes{drop, add}}},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		schema.Changes{&schema.ModifyTable{Changes: schema.Changes{&schema.RenameForeignKey{From: drop.F, To: add.F}}}},
+		changes,
+	)
+
+	// Re-creating a foreign key under a new name.
+	changes, err = p.FixChange(
+		nil,
+		"ALTER TABLE t DROP CONSTRAINT f1, ADD CONSTRAINT f2 FOREIGN KEY (owner_id) REFERENCES users (id)",
+		schema.Changes{&schema.ModifyTable{Changes: schema.Changes{drop, add}}},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		schema.Changes{&schema.ModifyTable{Changes: schema.Changes{&schema.RenameForeignKey{From: drop.F, To: add.F}}}},
+		changes,
+	)
+
+	// Re-creating a foreign key with a different definition.
+	add = &schema.AddForeignKey{F: fk("f2").SetOnDelete(schema.Cascade)}
+	changes, err = p.FixChange(
+		nil,
+		"ALTER TABLE t DROP CONSTRAINT f1, ADD CONSTRAINT f2 FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE",
+		schema.Changes{&schema.ModifyTable{Changes: schema.Changes{drop, add}}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, schema.Changes{&schema.ModifyTable{Changes: schema.Changes{drop, add}}}, changes)
+}
+
 func TestFixChange_RenameTable(t *testing.T) {
 	var p pgparse.Parser
 	changes, err := p.FixChange(
@@ -172,3 +219,38 @@ func TestColumnFilledBefore(t *testing.T) {
 		})
 	}
 }
+
+func FuzzFixChange(f *testing.F) {
+	for _, s := range []string{
+		"ALTER TABLE t RENAME COLUMN c1 TO c2",
+		"ALTER TABLE t RENAME TO t2",
+		"UPDATE t SET c = 1 WHERE c IS NULL",
+		"CREATE TABLE t (c int)",
+		"SELECT (1",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		var p pgparse.Parser
+		// Parsing arbitrary statements must not panic.
+		p.FixChange(nil, s, nil)
+		p.FixChange(nil, s, schema.Changes{&schema.ModifyTable{T: schema.NewTable("t")}})
+	})
+}
+
+func TestOnlineStmt(t *testing.T) {
+	var p pgparse.Parser
+	for stmt, want := range map[string]bool{
+		"CREATE INDEX i ON t (c)":                     false,
+		"CREATE UNIQUE INDEX CONCURRENTLY i ON t (c)": true,
+		"ALTER TABLE t ALTER COLUMN c TYPE bigint":    false,
+		"DROP INDEX i":                         false,
+		`DROP INDEX CONCURRENTLY "public"."i"`: true,
+	} {
+		online, err := p.OnlineStmt(stmt)
+		require.NoError(t, err)
+		require.Equal(t, want, online, stmt)
+	}
+	_, err := p.OnlineStmt("CREATE INDEX")
+	require.Error(t, err)
+}
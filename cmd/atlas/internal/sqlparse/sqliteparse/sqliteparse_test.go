@@ -143,3 +143,21 @@ UPDATE t1 SET c = 2 WHERE c IS NULL;
 		})
 	}
 }
+
+func FuzzFixChange(f *testing.F) {
+	for _, s := range []string{
+		"ALTER TABLE t RENAME COLUMN c1 TO c2",
+		"ALTER TABLE t RENAME TO t2",
+		"UPDATE t SET c = 1 WHERE c IS NULL",
+		"CREATE TABLE t (c int)",
+		"SELECT (1",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		var p sqliteparse.FileParser
+		// Parsing arbitrary statements must not panic.
+		p.FixChange(nil, s, nil)
+		p.FixChange(nil, s, schema.Changes{&schema.ModifyTable{T: schema.NewTable("t")}})
+	})
+}
@@ -7,6 +7,8 @@ package sqlparse
 import (
 	"sync"
 
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/chparse"
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/msparse"
 	"ariga.io/atlas/cmd/atlas/internal/sqlparse/myparse"
 	"ariga.io/atlas/cmd/atlas/internal/sqlparse/pgparse"
 	"ariga.io/atlas/cmd/atlas/internal/sqlparse/sqliteparse"
@@ -14,6 +16,7 @@ import (
 	"ariga.io/atlas/sql/mysql"
 	"ariga.io/atlas/sql/postgres"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
 	"ariga.io/atlas/sql/sqlite"
 )
 
@@ -49,7 +52,96 @@ func ParserFor(name string) Parser {
 }
 
 func init() {
+	Register(chparse.DriverName, &chparse.Parser{})
+	Register(msparse.DriverName, &msparse.Parser{})
 	Register(mysql.DriverName, &myparse.Parser{})
 	Register(postgres.DriverName, &pgparse.Parser{})
 	Register(sqlite.DriverName, &sqliteparse.FileParser{})
 }
+
+// FixChecks patches DROP and ADD CHECK constraint changes of the same table to MODIFY
+// or RENAME changes. Unlike FixChange, it works on the changes of all statements in
+// a file, as constraints are usually modified by dropping them in one statement and
+// re-creating them in a later one. A constraint that is re-created under the same name
+// is modified, and a constraint that is re-created with the same expression under a
+// new name is renamed. The DROP changes are removed from the statements they belong to.
+func FixChecks(changes []*sqlcheck.Change) {
+	type drop struct {
+		name string // qualified table name
+		c    *schema.DropCheck
+	}
+	var (
+		drops   []*drop
+		removed = make(map[*schema.DropCheck]bool)
+	)
+	for _, c := range changes {
+		for _, m := range c.Changes {
+			m, ok := m.(*schema.ModifyTable)
+			if !ok {
+				continue
+			}
+			name := tableName(m.T)
+			for i, tc := range m.Changes {
+				switch tc := tc.(type) {
+				case *schema.DropCheck:
+					drops = append(drops, &drop{name: name, c: tc})
+				case *schema.AddCheck:
+					byName, byExpr := -1, -1
+					for j, d := range drops {
+						switch {
+						case d.name != name:
+						case d.c.C.Name != "" && d.c.C.Name == tc.C.Name:
+							byName = j
+						case d.c.C.Expr == tc.C.Expr && byExpr == -1:
+							byExpr = j
+						}
+					}
+					j := byName
+					if j == -1 {
+						j = byExpr
+					}
+					if j == -1 {
+						continue
+					}
+					if from := drops[j].c.C; from.Name == tc.C.Name {
+						m.Changes[i] = &schema.ModifyCheck{From: from, To: tc.C}
+					} else {
+						m.Changes[i] = &schema.RenameCheck{From: from, To: tc.C}
+					}
+					removed[drops[j].c] = true
+					drops = append(drops[:j], drops[j+1:]...)
+				}
+			}
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+	for _, c := range changes {
+		fixed := make(schema.Changes, 0, len(c.Changes))
+		for _, m := range c.Changes {
+			if m, ok := m.(*schema.ModifyTable); ok {
+				tcs := make([]schema.Change, 0, len(m.Changes))
+				for _, tc := range m.Changes {
+					if d, ok := tc.(*schema.DropCheck); !ok || !removed[d] {
+						tcs = append(tcs, tc)
+					}
+				}
+				// Skip tables that were left without changes.
+				if m.Changes = tcs; len(tcs) == 0 {
+					continue
+				}
+			}
+			fixed = append(fixed, m)
+		}
+		c.Changes = fixed
+	}
+}
+
+// tableName returns the qualified name of the table.
+func tableName(t *schema.Table) string {
+	if t.Schema != nil && t.Schema.Name != "" {
+		return t.Schema.Name + "." + t.Name
+	}
+	return t.Name
+}
@@ -0,0 +1,61 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlparse_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixChecks(t *testing.T) {
+	var (
+		public = schema.New("public")
+		users  = schema.NewTable("users").SetSchema(public)
+		pets   = schema.NewTable("pets").SetSchema(public)
+		// Tables are inspected again after each statement.
+		users2 = schema.NewTable("users").SetSchema(public)
+		c1     = &schema.Check{Name: "positive_age", Expr: "(age > 0)"}
+		c2     = &schema.Check{Name: "positive_age", Expr: "(age >= 0)"}
+		c3     = &schema.Check{Name: "name_not_empty", Expr: "(name <> '')"}
+		c4     = &schema.Check{Name: "non_empty_name", Expr: "(name <> '')"}
+		c5     = &schema.Check{Name: "positive_id", Expr: "(id > 0)"}
+		drop   = &sqlcheck.Change{
+			Changes: schema.Changes{
+				&schema.ModifyTable{T: users, Changes: schema.Changes{&schema.DropCheck{C: c1}, &schema.DropCheck{C: c3}}},
+				&schema.ModifyTable{T: pets, Changes: schema.Changes{&schema.DropCheck{C: c5}}},
+			},
+		}
+		add = &sqlcheck.Change{
+			Changes: schema.Changes{
+				&schema.ModifyTable{T: users2, Changes: schema.Changes{&schema.AddCheck{C: c2}, &schema.AddCheck{C: c4}, &schema.AddCheck{C: c5}}},
+			},
+		}
+	)
+	sqlparse.FixChecks([]*sqlcheck.Change{drop, add})
+	// The check of the "pets" table was dropped, and
+	// a check with the same name was added to "users".
+	require.Equal(t, schema.Changes{
+		&schema.ModifyTable{T: pets, Changes: schema.Changes{&schema.DropCheck{C: c5}}},
+	}, drop.Changes)
+	require.Equal(t, schema.Changes{
+		&schema.ModifyTable{T: users2, Changes: schema.Changes{
+			&schema.ModifyCheck{From: c1, To: c2},
+			&schema.RenameCheck{From: c3, To: c4},
+			&schema.AddCheck{C: c5},
+		}},
+	}, add.Changes)
+
+	// Checks that are added before they are dropped are left as is.
+	add = &sqlcheck.Change{Changes: schema.Changes{&schema.ModifyTable{T: users, Changes: schema.Changes{&schema.AddCheck{C: c4}}}}}
+	drop = &sqlcheck.Change{Changes: schema.Changes{&schema.ModifyTable{T: users, Changes: schema.Changes{&schema.DropCheck{C: c3}}}}}
+	sqlparse.FixChecks([]*sqlcheck.Change{add, drop})
+	require.IsType(t, &schema.AddCheck{}, add.Changes[0].(*schema.ModifyTable).Changes[0])
+	require.IsType(t, &schema.DropCheck{}, drop.Changes[0].(*schema.ModifyTable).Changes[0])
+}
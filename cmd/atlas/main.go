@@ -6,23 +6,43 @@ package main
 
 import (
 	"context"
+	"database/sql/driver"
 	"os"
 	"os/signal"
+	"strings"
 
 	"ariga.io/atlas/cmd/atlas/internal/cmdapi"
 	_ "ariga.io/atlas/cmd/atlas/internal/docker"
+	_ "ariga.io/atlas/sql/clickhouse"
+	"ariga.io/atlas/sql/migrate"
 	_ "ariga.io/atlas/sql/mysql"
 	_ "ariga.io/atlas/sql/mysql/mysqlcheck"
-	_ "ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/postgres"
 	_ "ariga.io/atlas/sql/postgres/postgrescheck"
 	_ "ariga.io/atlas/sql/sqlite"
 	_ "ariga.io/atlas/sql/sqlite/sqlitecheck"
+	_ "ariga.io/atlas/sql/sqlserver"
 
+	_ "github.com/ClickHouse/clickhouse-go/v2"
 	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
+	_ "github.com/microsoft/go-mssqldb"
 )
 
+func init() {
+	// Collect PostgreSQL notices and report them as warnings.
+	postgres.RegisterNoticeConnector(func(dsn string, h func(migrate.Warning)) (driver.Connector, error) {
+		c, err := pq.NewConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return pq.ConnectorWithNoticeHandler(c, func(e *pq.Error) {
+			h(migrate.Warning{Level: strings.ToLower(e.Severity), Code: string(e.Code), Message: e.Message})
+		}), nil
+	})
+}
+
 func main() {
 	ctx, _ := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	cmdapi.Root.SetOut(os.Stdout)
@@ -27,6 +27,9 @@ module.exports = {
                 {type: 'doc', id: 'declarative/inspect', label: 'Schema Inspection'},
                 {type: 'doc', id: 'declarative/apply', label: 'Applying Changes'},
                 {type: 'doc', id: 'declarative/diff', label: 'Calculating Diffs'},
+                {type: 'doc', id: 'declarative/compile', label: 'Compiling to SQL'},
+                {type: 'doc', id: 'declarative/convert', label: 'Converting SQL to HCL'},
+                {type: 'doc', id: 'declarative/seed', label: 'Seeding Fixtures'},
             ]
         },
         {
@@ -38,6 +41,7 @@ module.exports = {
                 {type: 'doc', id: 'versioned/lint', label: 'Migration Linting'},
                 {type: 'doc', id: 'versioned/new', label: 'Manual Migrations'},
                 {type: 'doc', id: 'versioned/apply', label: 'Migration Applying'},
+                {type: 'doc', id: 'versioned/registry', label: 'Migration Registries'},
             ]
         },
         {
@@ -70,6 +74,7 @@ module.exports = {
                 {type: 'doc', id: 'integrations/github-actions', label: 'GitHub Actions'},
                 {type: 'doc', id: 'integrations/terraform-provider', label: 'Terraform Provider'},
                 {type: 'doc', id: 'integrations/go-api', label: 'Go API'},
+                {type: 'doc', id: 'integrations/editors', label: 'Editors (LSP)'},
             ]
         },
         {
@@ -12,6 +12,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
 )
 
 func TestAttributes(t *testing.T) {
@@ -40,6 +42,25 @@ bl = [true, false]
 	require.EqualValues(t, f, string(marshal))
 }
 
+func TestWithFunctions(t *testing.T) {
+	f := `s = upper("hello")
+t = "${lower("HELLO")}, world"
+`
+	var test struct {
+		S string `spec:"s"`
+		T string `spec:"t"`
+	}
+	err := New().EvalBytes([]byte(f), &test, nil)
+	require.Error(t, err, "functions are not registered by default")
+	err = New(WithFunctions(map[string]function.Function{
+		"upper": stdlib.UpperFunc,
+		"lower": stdlib.LowerFunc,
+	})).EvalBytes([]byte(f), &test, nil)
+	require.NoError(t, err)
+	require.Equal(t, "HELLO", test.S)
+	require.Equal(t, "hello, world", test.T)
+}
+
 func TestResource(t *testing.T) {
 	f := `endpoint "/hello" {
   description = "the hello handler"
@@ -97,6 +97,30 @@ func WithTypes(typeSpecs []*TypeSpec) Option {
 	}
 }
 
+// WithFunctions registers the given functions in the evaluation context of the
+// documents. Note, this option should be set after WithTypes, as it extends the
+// context configured by the options set before it. For example:
+//
+//	WithFunctions(map[string]function.Function{
+//		"upper": stdlib.UpperFunc,
+//	})
+//
+//	env "local" {
+//		name = upper("local")
+//	}
+func WithFunctions(funcs map[string]function.Function) Option {
+	return func(c *Config) {
+		newCtx := c.newCtx
+		c.newCtx = func() *hcl.EvalContext {
+			ctx := newCtx()
+			for n, f := range funcs {
+				ctx.Functions[n] = f
+			}
+			return ctx
+		}
+	}
+}
+
 func rawExprImpl() function.Function {
 	return function.New(&function.Spec{
 		Params: []function.Parameter{
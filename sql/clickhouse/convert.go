@@ -0,0 +1,253 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package clickhouse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Default attributes of types, when they are declared without a precision or scale.
+const (
+	defaultDecimalPrecision = 10
+	defaultTimePrecision    = 3
+)
+
+// Precision of the DecimalN(S) aliases.
+var decimalPrecision = map[string]int{
+	"Decimal32":  9,
+	"Decimal64":  18,
+	"Decimal128": 38,
+	"Decimal256": 76,
+}
+
+// FormatType converts schema type to its column form in the database.
+// An error is returned if the type cannot be recognized.
+func FormatType(t schema.Type) (string, error) {
+	var f string
+	switch t := t.(type) {
+	case *schema.BoolType:
+		f = t.T
+	case *schema.IntegerType:
+		f = t.T
+	case *schema.FloatType:
+		f = t.T
+	case *schema.DecimalType:
+		if t.T != TypeDecimal {
+			return "", fmt.Errorf("clickhouse: unexpected decimal type: %q", t.T)
+		}
+		p := t.Precision
+		if p == 0 {
+			p = defaultDecimalPrecision
+		}
+		if t.Scale < 0 || t.Scale > p {
+			return "", fmt.Errorf("clickhouse: decimal type must have scale between 0 and its precision: %d", t.Scale)
+		}
+		f = fmt.Sprintf("%s(%d, %d)", t.T, p, t.Scale)
+	case *schema.StringType:
+		switch f = t.T; f {
+		case TypeString:
+		case TypeFixedString:
+			if t.Size <= 0 {
+				return "", fmt.Errorf("clickhouse: size for type %s must be greater than 0: %d", t.T, t.Size)
+			}
+			f = fmt.Sprintf("%s(%d)", f, t.Size)
+		default:
+			return "", fmt.Errorf("clickhouse: unexpected string type: %q", t.T)
+		}
+	case *schema.TimeType:
+		switch f = t.T; f {
+		case TypeDate, TypeDate32, TypeDateTime:
+		case TypeDateTime64:
+			p := defaultTimePrecision
+			if t.Precision != nil {
+				p = *t.Precision
+			}
+			f = fmt.Sprintf("%s(%d)", f, p)
+		default:
+			return "", fmt.Errorf("clickhouse: unexpected time type: %q", t.T)
+		}
+	case *schema.JSONType:
+		f = t.T
+	case *UUIDType:
+		f = t.T
+	case *IPType:
+		f = t.T
+	case *NativeType:
+		f = t.T
+	case *schema.UnsupportedType:
+		return "", fmt.Errorf("clickhouse: unsupported type: %q", t.T)
+	default:
+		return "", fmt.Errorf("clickhouse: invalid schema type: %T", t)
+	}
+	return f, nil
+}
+
+// ParseType returns the schema.Type value represented by the given raw type.
+// The raw value is expected to follow the format of system.columns, e.g.
+// "Decimal(10, 2)", "FixedString(16)" or "DateTime64(3)". Nullable types
+// are rejected, as nullability is defined on the column (see ColumnType.Null).
+func ParseType(raw string) (schema.Type, error) {
+	t, args, err := parseColumn(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case "Nullable":
+		return nil, fmt.Errorf("clickhouse: unexpected nullable type %q, use the null attribute of the column instead", raw)
+	case TypeBool:
+		return &schema.BoolType{T: t}, nil
+	case TypeInt8, TypeInt16, TypeInt32, TypeInt64, TypeInt128, TypeInt256:
+		return &schema.IntegerType{T: t}, nil
+	case TypeUInt8, TypeUInt16, TypeUInt32, TypeUInt64, TypeUInt128, TypeUInt256:
+		return &schema.IntegerType{T: t, Unsigned: true}, nil
+	case TypeFloat32, TypeFloat64:
+		return &schema.FloatType{T: t}, nil
+	case TypeDecimal:
+		dt := &schema.DecimalType{T: t, Precision: defaultDecimalPrecision}
+		if len(args) > 0 {
+			if dt.Precision, err = strconv.Atoi(args[0]); err != nil {
+				return nil, fmt.Errorf("clickhouse: parse precision %q: %w", args[0], err)
+			}
+		}
+		if len(args) > 1 {
+			if dt.Scale, err = strconv.Atoi(args[1]); err != nil {
+				return nil, fmt.Errorf("clickhouse: parse scale %q: %w", args[1], err)
+			}
+		}
+		return dt, nil
+	// DecimalN(S) types are aliases of Decimal(P, S) with fixed precision.
+	case "Decimal32", "Decimal64", "Decimal128", "Decimal256":
+		dt := &schema.DecimalType{T: TypeDecimal, Precision: decimalPrecision[t]}
+		if len(args) != 1 {
+			return nil, fmt.Errorf("clickhouse: missing scale for type %q", raw)
+		}
+		if dt.Scale, err = strconv.Atoi(args[0]); err != nil {
+			return nil, fmt.Errorf("clickhouse: parse scale %q: %w", args[0], err)
+		}
+		return dt, nil
+	case TypeString:
+		return &schema.StringType{T: t}, nil
+	case TypeFixedString:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("clickhouse: missing size for type %q", raw)
+		}
+		st := &schema.StringType{T: t}
+		if st.Size, err = strconv.Atoi(args[0]); err != nil {
+			return nil, fmt.Errorf("clickhouse: parse size %q: %w", args[0], err)
+		}
+		return st, nil
+	case TypeDate, TypeDate32:
+		return &schema.TimeType{T: t}, nil
+	// Time types with a timezone, e.g. DateTime('UTC'), are kept as-is.
+	case TypeDateTime:
+		if len(args) > 0 {
+			return &NativeType{T: raw}, nil
+		}
+		return &schema.TimeType{T: t}, nil
+	case TypeDateTime64:
+		if len(args) > 1 {
+			return &NativeType{T: raw}, nil
+		}
+		p := defaultTimePrecision
+		if len(args) > 0 {
+			if p, err = strconv.Atoi(args[0]); err != nil {
+				return nil, fmt.Errorf("clickhouse: parse precision %q: %w", args[0], err)
+			}
+		}
+		return &schema.TimeType{T: t, Precision: &p}, nil
+	case TypeUUID:
+		return &UUIDType{T: t}, nil
+	case TypeIPv4, TypeIPv6:
+		return &IPType{T: t}, nil
+	case TypeJSON:
+		return &schema.JSONType{T: t}, nil
+	default:
+		// Types such as Array(T), Map(K, V), LowCardinality(T) or Enum8(...).
+		return &NativeType{T: raw}, nil
+	}
+}
+
+// parseColumn splits the raw type into its name and its top-level arguments.
+// Unlike other dialects, type names in ClickHouse are case-sensitive.
+func parseColumn(raw string) (string, []string, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", nil, fmt.Errorf("clickhouse: missing type")
+	}
+	i := strings.IndexByte(s, '(')
+	if i == -1 {
+		return s, nil, nil
+	}
+	if !strings.HasSuffix(s, ")") {
+		return "", nil, fmt.Errorf("clickhouse: malformed type %q", raw)
+	}
+	return strings.TrimSpace(s[:i]), splitArgs(s[i+1 : len(s)-1]), nil
+}
+
+// splitArgs splits the given expression list by its top-level commas.
+// Commas in nested parentheses or string literals are ignored.
+func splitArgs(s string) []string {
+	var (
+		args   []string
+		depth  int
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" || len(args) > 0 {
+		args = append(args, rest)
+	}
+	return args
+}
+
+// nullable wraps the formatted type with Nullable. LowCardinality types are
+// wrapped from the inside, as Nullable(LowCardinality(T)) is not allowed.
+func nullable(t string) string {
+	if inner, ok := unwrapType(t, "LowCardinality"); ok {
+		return "LowCardinality(" + nullable(inner) + ")"
+	}
+	return "Nullable(" + t + ")"
+}
+
+// nonNullable returns the type without its Nullable wrapper, and
+// reports if it was wrapped. See nullable for the reverse operation.
+func nonNullable(t string) (string, bool) {
+	if inner, ok := unwrapType(t, "LowCardinality"); ok {
+		if inner, ok := unwrapType(inner, "Nullable"); ok {
+			return "LowCardinality(" + inner + ")", true
+		}
+		return t, false
+	}
+	return unwrapType(t, "Nullable")
+}
+
+// unwrapType returns the argument of the given wrapper type, e.g. "String" for
+// Nullable(String), and reports if the type was wrapped with the given name.
+func unwrapType(t, name string) (string, bool) {
+	t = strings.TrimSpace(t)
+	if !strings.HasPrefix(t, name+"(") || !strings.HasSuffix(t, ")") {
+		return t, false
+	}
+	return strings.TrimSpace(t[len(name)+1 : len(t)-1]), true
+}
@@ -0,0 +1,318 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package clickhouse
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A diff provides a ClickHouse implementation for sqlx.DiffDriver.
+type diff struct{ conn }
+
+// SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
+func (*diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
+	// Databases in ClickHouse have no attributes that can be altered.
+	return nil
+}
+
+// TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
+func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
+	if e1, e2 := engine(from.Attrs), engine(to.Attrs); exprKey(e1) != exprKey(e2) {
+		return nil, fmt.Errorf("clickhouse: changing the engine of table %q from %q to %q is not supported", to.Name, e1, e2)
+	}
+	var p1, p2 PartitionBy
+	if sqlx.Has(from.Attrs, &p1) != sqlx.Has(to.Attrs, &p2) || exprKey(p1.X) != exprKey(p2.X) {
+		return nil, fmt.Errorf("clickhouse: changing the partition key of table %q is not supported", to.Name)
+	}
+	var changes []schema.Change
+	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	var o1, o2 OrderBy
+	switch ok1, ok2 := sqlx.Has(from.Attrs, &o1), sqlx.Has(to.Attrs, &o2); {
+	case ok1 && !ok2:
+		return nil, fmt.Errorf("clickhouse: dropping the sorting key of table %q is not supported", to.Name)
+	case !ok1 && ok2:
+		changes = append(changes, &schema.AddAttr{A: &o2})
+	case ok1 && exprKey(o1.X) != exprKey(o2.X):
+		changes = append(changes, &schema.ModifyAttr{From: &o1, To: &o2})
+	}
+	var s1, s2 SampleBy
+	switch ok1, ok2 := sqlx.Has(from.Attrs, &s1), sqlx.Has(to.Attrs, &s2); {
+	case ok1 && !ok2:
+		changes = append(changes, &schema.DropAttr{A: &s1})
+	case !ok1 && ok2:
+		changes = append(changes, &schema.AddAttr{A: &s2})
+	case ok1 && exprKey(s1.X) != exprKey(s2.X):
+		changes = append(changes, &schema.ModifyAttr{From: &s1, To: &s2})
+	}
+	if change := sqlx.RetentionDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// ColumnChange returns the schema changes (if any) for migrating one column to the other.
+func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column) (schema.ChangeKind, error) {
+	change := sqlx.CommentChange(from.Attrs, to.Attrs)
+	if from.Type.Null != to.Type.Null {
+		change |= schema.ChangeNull
+	}
+	changed, err := d.typeChanged(from, to)
+	if err != nil {
+		return schema.NoChange, err
+	}
+	if changed {
+		change |= schema.ChangeType
+	}
+	if d.defaultChanged(from, to) {
+		change |= schema.ChangeDefault
+	}
+	if d.generatedChanged(from, to) {
+		change |= schema.ChangeGenerated
+	}
+	if exprKey(codec(from.Attrs)) != exprKey(codec(to.Attrs)) {
+		change |= schema.ChangeAttr
+	}
+	return change, nil
+}
+
+// typeChanged reports if the column type was changed.
+func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
+	fromT, toT := from.Type.Type, to.Type.Type
+	if fromT == nil || toT == nil {
+		return false, fmt.Errorf("clickhouse: missing type information for column %q", from.Name)
+	}
+	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
+		return true, nil
+	}
+	ft, err := FormatType(fromT)
+	if err != nil {
+		return false, err
+	}
+	tt, err := FormatType(toT)
+	if err != nil {
+		return false, err
+	}
+	return exprKey(ft) != exprKey(tt), nil
+}
+
+// defaultChanged reports if the default value of a column was changed.
+func (d *diff) defaultChanged(from, to *schema.Column) bool {
+	d1, ok1 := sqlx.DefaultValue(from)
+	d2, ok2 := sqlx.DefaultValue(to)
+	if ok1 != ok2 {
+		return true
+	}
+	if !ok1 || exprKey(d1) == exprKey(d2) {
+		return false
+	}
+	x1, err1 := sqlx.Unquote(d1)
+	x2, err2 := sqlx.Unquote(d2)
+	return err1 != nil || err2 != nil || x1 != x2
+}
+
+// generatedChanged reports if the MATERIALIZED or ALIAS expression of a column was changed.
+func (*diff) generatedChanged(from, to *schema.Column) bool {
+	var (
+		fromX, toX     schema.GeneratedExpr
+		fromHas, toHas = sqlx.Has(from.Attrs, &fromX), sqlx.Has(to.Attrs, &toX)
+	)
+	return fromHas != toHas || fromHas && (exprKey(fromX.Expr) != exprKey(toX.Expr) || generatedType(fromX.Type) != generatedType(toX.Type))
+}
+
+// IsGeneratedIndexName reports if the index name was generated by the database.
+// Data skipping indexes in ClickHouse are always named.
+func (*diff) IsGeneratedIndexName(*schema.Table, *schema.Index) bool {
+	return false
+}
+
+// IndexAttrChanged reports if the index attributes were changed.
+func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
+	return exprKey(indexType(from)) != exprKey(indexType(to)) || granularity(from) != granularity(to)
+}
+
+// IndexPartAttrChanged reports if the index-part attributes were changed.
+func (*diff) IndexPartAttrChanged(from, to *schema.IndexPart) bool {
+	x1, ok1 := from.X.(*schema.RawExpr)
+	x2, ok2 := to.X.(*schema.RawExpr)
+	return ok1 != ok2 || ok1 && exprKey(x1.X) != exprKey(x2.X)
+}
+
+// ReferenceChanged reports if the foreign key referential action was changed.
+func (*diff) ReferenceChanged(_, _ schema.ReferenceOption) bool {
+	// Foreign keys are not supported by ClickHouse.
+	return false
+}
+
+// Normalize implements the sqlx.Normalizer interface.
+func (*diff) Normalize(from, to *schema.Table) error {
+	for _, t := range []*schema.Table{from, to} {
+		normalizeKeys(t)
+	}
+	return nil
+}
+
+// ViewChanged implements the sqlx.ViewChanger interface.
+func (*diff) ViewChanged(from, to *schema.View) bool {
+	if sqlx.ViewDef(from.Def) != sqlx.ViewDef(to.Def) {
+		return true
+	}
+	var m1, m2 MaterializedView
+	if sqlx.Has(from.Attrs, &m1) != sqlx.Has(to.Attrs, &m2) || unquote(m1.To) != unquote(m2.To) {
+		return true
+	}
+	// The inner table of materialized views that are
+	// not targeting a table is defined by the view.
+	if !sqlx.Has(to.Attrs, &m2) || m2.To != "" {
+		return false
+	}
+	var o1, o2 OrderBy
+	sqlx.Has(from.Attrs, &o1)
+	sqlx.Has(to.Attrs, &o2)
+	return exprKey(engine(from.Attrs)) != exprKey(engine(to.Attrs)) || exprKey(o1.X) != exprKey(o2.X)
+}
+
+// normalizeKeys brings the sorting and primary keys of the table to their normal
+// form. A primary key that is defined without a sorting key is also used as the
+// sorting key, and a primary key that is identical to the sorting key is dropped.
+func normalizeKeys(t *schema.Table) {
+	pk := t.PrimaryKey
+	if pk == nil {
+		return
+	}
+	x := keyOf(pk)
+	if o := (OrderBy{}); !sqlx.Has(t.Attrs, &o) {
+		t.AddAttrs(&OrderBy{X: x})
+	} else if exprKey(o.X) != exprKey(x) {
+		return
+	}
+	for _, p := range pk.Parts {
+		if p.C == nil {
+			continue
+		}
+		for i := range p.C.Indexes {
+			if p.C.Indexes[i] == pk {
+				p.C.Indexes = append(p.C.Indexes[:i], p.C.Indexes[i+1:]...)
+				break
+			}
+		}
+	}
+	t.PrimaryKey = nil
+}
+
+// keyOf returns the key expression of the given index parts, e.g. "(a, b)".
+func keyOf(idx *schema.Index) string {
+	parts := make([]string, 0, len(idx.Parts))
+	for _, p := range idx.Parts {
+		switch {
+		case p.C != nil:
+			parts = append(parts, p.C.Name)
+		case p.X != nil:
+			if x, ok := p.X.(*schema.RawExpr); ok {
+				parts = append(parts, x.X)
+			}
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// engine returns the engine of the given attributes, or
+// the MergeTree engine in case no engine was defined.
+func engine(attrs []schema.Attr) string {
+	if e := (Engine{}); sqlx.Has(attrs, &e) && e.V != "" {
+		return e.V
+	}
+	return EngineMergeTree
+}
+
+// codec returns the compression codecs of the given column attributes.
+func codec(attrs []schema.Attr) string {
+	var c Codec
+	sqlx.Has(attrs, &c)
+	return c.V
+}
+
+// indexType returns the type of the data skipping index, or
+// the minmax type in case the index was defined without a type.
+func indexType(attrs []schema.Attr) string {
+	if t := (IndexType{}); sqlx.Has(attrs, &t) && t.T != "" {
+		return t.T
+	}
+	return "minmax"
+}
+
+// granularity returns the granularity of the data skipping
+// index, or 1 in case the index was defined without it.
+func granularity(attrs []schema.Attr) int {
+	if g := (IndexGranularity{}); sqlx.Has(attrs, &g) && g.V > 0 {
+		return g.V
+	}
+	return 1
+}
+
+// generatedType returns the MATERIALIZED or ALIAS generated type option based on
+// the given string. Columns are MATERIALIZED by default, and the STORED and VIRTUAL
+// types of other dialects are mapped to MATERIALIZED and ALIAS respectively.
+func generatedType(s string) string {
+	if s = strings.ToUpper(s); s == defaultKindAlias || s == "VIRTUAL" {
+		return defaultKindAlias
+	}
+	return defaultKindMaterialized
+}
+
+// exprKey returns the normal form of the given expression for comparison, as
+// ClickHouse formats the keys and expressions it stores, e.g. "(a, b)" is stored
+// as "a, b", and identifiers are quoted with backticks only if required.
+func exprKey(x string) string {
+	return strings.NewReplacer("`", "", " ", "", "\n", "", "\t", "").Replace(trimParens(x))
+}
+
+// unquote returns the given (possibly qualified) name without its quotes.
+func unquote(name string) string {
+	return strings.NewReplacer("`", "", `"`, "").Replace(name)
+}
+
+// trimParens removes the outer parentheses that wrap the whole expression.
+func trimParens(x string) string {
+	for x = strings.TrimSpace(x); wrapped(x); x = strings.TrimSpace(x[1 : len(x)-1]) {
+	}
+	return x
+}
+
+// wrapped reports if the opening parenthesis at the start of the
+// expression is closed by the one at its end, e.g. "(a + b)", but
+// not "(a) + (b)". Parentheses in string literals are ignored.
+func wrapped(x string) bool {
+	if len(x) < 2 || x[0] != '(' || x[len(x)-1] != ')' {
+		return false
+	}
+	var (
+		depth  int
+		quoted bool
+	)
+	for i := 0; i < len(x); i++ {
+		switch c := x[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 && i < len(x)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
@@ -0,0 +1,155 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package clickhouse
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_TableDiff(t *testing.T) {
+	type testcase struct {
+		name        string
+		from, to    *schema.Table
+		wantChanges []schema.Change
+		wantErr     bool
+	}
+	tests := []testcase{
+		{
+			name: "no changes",
+			from: schema.NewTable("events").SetSchema(schema.New("default")),
+			to:   schema.NewTable("events"),
+		},
+		{
+			name: "default engine",
+			from: schema.NewTable("events").AddAttrs(&Engine{V: "MergeTree"}, &OrderBy{X: "id, ts"}),
+			to:   schema.NewTable("events").AddAttrs(&OrderBy{X: "(id, `ts`)"}),
+		},
+		{
+			name:    "change engine",
+			from:    schema.NewTable("events").AddAttrs(&Engine{V: "MergeTree"}),
+			to:      schema.NewTable("events").AddAttrs(&Engine{V: "ReplacingMergeTree(ver)"}),
+			wantErr: true,
+		},
+		{
+			name:    "change partition key",
+			from:    schema.NewTable("events").AddAttrs(&PartitionBy{X: "toYYYYMM(ts)"}),
+			to:      schema.NewTable("events").AddAttrs(&PartitionBy{X: "toDate(ts)"}),
+			wantErr: true,
+		},
+		{
+			name:    "drop sorting key",
+			from:    schema.NewTable("events").AddAttrs(&OrderBy{X: "id"}),
+			to:      schema.NewTable("events"),
+			wantErr: true,
+		},
+		{
+			name: "table attributes",
+			from: schema.NewTable("events").AddAttrs(&OrderBy{X: "id"}, &SampleBy{X: "id"}),
+			to:   schema.NewTable("events").AddAttrs(&OrderBy{X: "id, ts"}).SetComment("events"),
+			wantChanges: []schema.Change{
+				&schema.AddAttr{A: &schema.Comment{Text: "events"}},
+				&schema.ModifyAttr{From: &OrderBy{X: "id"}, To: &OrderBy{X: "id, ts"}},
+				&schema.DropAttr{A: &SampleBy{X: "id"}},
+			},
+		},
+		{
+			name: "retention",
+			from: schema.NewTable("events").AddAttrs(&schema.Retention{C: schema.NewTimeColumn("ts", TypeDateTime), Period: 30, Unit: "DAY"}),
+			to:   schema.NewTable("events").AddAttrs(&schema.Retention{C: schema.NewTimeColumn("ts", TypeDateTime), Period: 1, Unit: "month"}),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &schema.Retention{C: schema.NewTimeColumn("ts", TypeDateTime), Period: 30, Unit: "DAY"},
+					To:   &schema.Retention{C: schema.NewTimeColumn("ts", TypeDateTime), Period: 1, Unit: "month"},
+				},
+			},
+		},
+		// A primary key without a sorting key is used as the sorting key.
+		func() testcase {
+			from := schema.NewTable("events").
+				AddColumns(schema.NewIntColumn("id", TypeUInt64), schema.NewTimeColumn("ts", TypeDateTime)).
+				AddAttrs(&OrderBy{X: "id, ts"})
+			to := schema.NewTable("events").
+				AddColumns(schema.NewIntColumn("id", TypeUInt64), schema.NewTimeColumn("ts", TypeDateTime))
+			to.SetPrimaryKey(schema.NewPrimaryKey(to.Columns...))
+			return testcase{
+				name: "primary key as sorting key",
+				from: from,
+				to:   to,
+			}
+		}(),
+		func() testcase {
+			from := schema.NewTable("events").
+				AddColumns(
+					schema.NewIntColumn("id", TypeUInt64),
+					schema.NewStringColumn("name", TypeString).SetDefault(&schema.Literal{V: "'a'"}),
+					schema.NewTimeColumn("day", TypeDate).SetGeneratedExpr(&schema.GeneratedExpr{Expr: "toDate(ts)"}),
+					schema.NewTimeColumn("ts", TypeDateTime).AddAttrs(&Codec{V: "Delta, ZSTD(1)"}),
+				)
+			to := schema.NewTable("events").
+				AddColumns(
+					schema.NewIntColumn("id", TypeUInt32),
+					schema.NewNullStringColumn("name", TypeString).SetDefault(&schema.Literal{V: "a"}),
+					schema.NewTimeColumn("day", TypeDate).SetGeneratedExpr(&schema.GeneratedExpr{Expr: "toDate(ts)", Type: defaultKindAlias}),
+					schema.NewTimeColumn("ts", TypeDateTime),
+				)
+			return testcase{
+				name: "modify columns",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeType},
+					&schema.ModifyColumn{From: from.Columns[1], To: to.Columns[1], Change: schema.ChangeNull},
+					&schema.ModifyColumn{From: from.Columns[2], To: to.Columns[2], Change: schema.ChangeGenerated},
+					&schema.ModifyColumn{From: from.Columns[3], To: to.Columns[3], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
+		func() testcase {
+			from := schema.NewTable("events").AddColumns(schema.NewStringColumn("name", TypeString))
+			to := schema.NewTable("events").AddColumns(schema.NewStringColumn("name", TypeString))
+			from.AddIndexes(
+				schema.NewIndex("idx_1").AddColumns(from.Columns...).AddAttrs(&IndexType{T: "minmax"}, &IndexGranularity{V: 1}),
+				schema.NewIndex("idx_2").AddColumns(from.Columns...).AddAttrs(&IndexType{T: "bloom_filter"}),
+			)
+			to.AddIndexes(
+				schema.NewIndex("idx_1").AddColumns(to.Columns...),
+				schema.NewIndex("idx_2").AddColumns(to.Columns...).AddAttrs(&IndexType{T: "bloom_filter"}, &IndexGranularity{V: 4}),
+			)
+			return testcase{
+				name: "indexes",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyIndex{From: from.Indexes[1], To: to.Indexes[1], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := DefaultDiff.TableDiff(tt.from, tt.to)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.EqualValues(t, tt.wantChanges, changes)
+		})
+	}
+}
+
+func TestDiff_ViewChanged(t *testing.T) {
+	d := &diff{}
+	v := func(def string, attrs ...schema.Attr) *schema.View {
+		return schema.NewView("v", def).AddAttrs(attrs...)
+	}
+	require.False(t, d.ViewChanged(v("SELECT 1"), v("SELECT 1")))
+	require.True(t, d.ViewChanged(v("SELECT 1"), v("SELECT 2")))
+	require.True(t, d.ViewChanged(v("SELECT 1"), v("SELECT 1", &MaterializedView{To: "t"})))
+	require.False(t, d.ViewChanged(v("SELECT 1", &MaterializedView{To: "`t`"}), v("SELECT 1", &MaterializedView{To: "t"})))
+	require.True(t, d.ViewChanged(v("SELECT 1", &MaterializedView{To: "t"}), v("SELECT 1", &MaterializedView{To: "u"})))
+	require.False(t, d.ViewChanged(v("SELECT 1", &MaterializedView{}, &Engine{V: "MergeTree"}), v("SELECT 1", &MaterializedView{})))
+	require.True(t, d.ViewChanged(v("SELECT 1", &MaterializedView{}, &OrderBy{X: "a"}), v("SELECT 1", &MaterializedView{}, &OrderBy{X: "b"})))
+}
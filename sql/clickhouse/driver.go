@@ -0,0 +1,259 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package clickhouse implements the Atlas driver for ClickHouse. Note, the package does
+// not register a database/sql driver. Programs that open connections using the
+// "clickhouse://" scheme should import one, e.g. github.com/ClickHouse/clickhouse-go/v2.
+//
+// ClickHouse databases are mapped to Atlas schemas, and table engines, sorting keys and
+// partitioning expressions are represented as table attributes. Tables of a cluster can
+// be managed by planning the changes with the migrate.PlanWithCluster option, that adds
+// the ON CLUSTER clause to the generated DDL statements.
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+type (
+	// Driver represents a ClickHouse driver for introspecting database schemas,
+	// generating diff between schema elements and apply migrations changes.
+	Driver struct {
+		conn
+		schema.Differ
+		schema.Inspector
+		migrate.PlanApplier
+	}
+
+	// database connection and its information.
+	conn struct {
+		schema.ExecQuerier
+		// System variables that are set on `Open`.
+		version  string
+		database string
+	}
+)
+
+var (
+	// DefaultDiff provides basic diffing capabilities for ClickHouse dialects.
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// Differ when a database connection is available.
+	DefaultDiff schema.Differ = &sqlx.Diff{DiffDriver: &diff{}}
+
+	// DefaultPlan provides basic planning capabilities for ClickHouse dialects.
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// migrate.PlanApplier when a database connection is available.
+	DefaultPlan migrate.PlanApplier = &planApply{conn{ExecQuerier: sqlx.NoRows, version: "23.8", database: defaultDatabase}}
+)
+
+// DriverName holds the name used for registration.
+const DriverName = "clickhouse"
+
+func init() {
+	sqlclient.Register(
+		DriverName,
+		sqlclient.DriverOpener(Open),
+		sqlclient.RegisterCodec(MarshalHCL, EvalHCL),
+		sqlclient.RegisterURLParser(parser{}),
+	)
+}
+
+// Open opens a new ClickHouse driver.
+func Open(db schema.ExecQuerier) (migrate.Driver, error) {
+	c := conn{ExecQuerier: db}
+	rows, err := db.QueryContext(context.Background(), variablesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse: query system variables: %w", err)
+	}
+	if err := sqlx.ScanOne(rows, &c.version, &c.database); err != nil {
+		return nil, fmt.Errorf("clickhouse: scan system variables: %w", err)
+	}
+	return &Driver{
+		conn:        c,
+		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
+		Inspector:   &inspect{c},
+		PlanApplier: &planApply{c},
+	}, nil
+}
+
+func (d *Driver) dev() *sqlx.DevDriver {
+	return &sqlx.DevDriver{Driver: d, MaxNameLen: 206}
+}
+
+// NormalizeRealm returns the normal representation of the given database.
+func (d *Driver) NormalizeRealm(ctx context.Context, r *schema.Realm) (*schema.Realm, error) {
+	return d.dev().NormalizeRealm(ctx, r)
+}
+
+// NormalizeSchema returns the normal representation of the given database.
+func (d *Driver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*schema.Schema, error) {
+	return d.dev().NormalizeSchema(ctx, s)
+}
+
+// Capabilities implements the migrate.CapabilitiesReporter interface.
+func (d *Driver) Capabilities(version string) (*migrate.Capabilities, error) {
+	c := d.conn
+	if version != "" {
+		if !validVersion(version) {
+			return nil, fmt.Errorf("clickhouse: malformed version: %s", version)
+		}
+		c.version = version
+	}
+	return c.capabilities(), nil
+}
+
+// capabilities returns the capabilities of the connection version.
+func (c *conn) capabilities() *migrate.Capabilities {
+	return &migrate.Capabilities{
+		Driver:  DriverName,
+		Version: c.version,
+		Supports: map[migrate.Capability]bool{
+			// DDL statements are not transactional, and the
+			// Atomic engine applies each statement on its own.
+			migrate.CapTransactionalDDL: false,
+			migrate.CapConcurrentIndex:  false,
+			// Adding a column is a metadata-only operation, as
+			// existing parts are rewritten lazily on merges.
+			migrate.CapInstantAddColumn: true,
+			migrate.CapRenameColumn:     true,
+			migrate.CapCheckConstraint:  false,
+			// Data skipping indexes are defined on expressions.
+			migrate.CapIndexExpr: true,
+			// MATERIALIZED and ALIAS columns.
+			migrate.CapGeneratedColumn:  true,
+			migrate.CapInvisibleIndex:   false,
+			migrate.CapIndexInclude:     false,
+			migrate.CapNullsNotDistinct: false,
+		},
+	}
+}
+
+// Snapshot implements migrate.Snapshoter.
+func (d *Driver) Snapshot(ctx context.Context) (migrate.RestoreFunc, error) {
+	// The connection is always bound to a database (the "default" one, if it was
+	// not set), and we can restore the state if the database has no tables.
+	s, err := d.InspectSchema(ctx, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Tables) > 0 {
+		return nil, migrate.NotCleanError{Reason: fmt.Sprintf("found table %q in schema %q", s.Tables[0].Name, s.Name)}
+	}
+	if len(s.Views) > 0 {
+		return nil, migrate.NotCleanError{Reason: fmt.Sprintf("found view %q in schema %q", s.Views[0].Name, s.Name)}
+	}
+	return func(ctx context.Context) error {
+		current, err := d.InspectSchema(ctx, s.Name, nil)
+		if err != nil {
+			return err
+		}
+		changes, err := d.SchemaDiff(current, s)
+		if err != nil {
+			return err
+		}
+		return d.ApplyChanges(ctx, changes)
+	}, nil
+}
+
+// CheckClean implements migrate.CleanChecker.
+func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error {
+	s, err := d.InspectSchema(ctx, "", nil)
+	if err != nil {
+		return err
+	}
+	if len(s.Views) > 0 {
+		return &migrate.NotCleanError{Reason: fmt.Sprintf("found view %q in schema %q", s.Views[0].Name, s.Name)}
+	}
+	if len(s.Tables) == 0 || ((revT.Schema == "" || s.Name == revT.Schema) && len(s.Tables) == 1 && s.Tables[0].Name == revT.Name) {
+		return nil
+	}
+	return &migrate.NotCleanError{Reason: fmt.Sprintf("found table %q in schema %q", s.Tables[0].Name, s.Name)}
+}
+
+// parser implements the sqlclient.URLParser and the sqlclient.SchemaChanger
+// interfaces. Like MySQL, the connected database is configured using the
+// URL path, e.g. "clickhouse://localhost:9000/analytics".
+type parser struct{}
+
+// ParseURL implements the sqlclient.URLParser interface.
+func (parser) ParseURL(u *url.URL) *sqlclient.URL {
+	return &sqlclient.URL{URL: u, DSN: u.String(), Schema: strings.TrimPrefix(u.Path, "/")}
+}
+
+// ChangeSchema implements the sqlclient.SchemaChanger interface.
+func (parser) ChangeSchema(u *url.URL, s string) *url.URL {
+	nu := *u
+	nu.Path = "/" + s
+	return &nu
+}
+
+// validVersion reports if the given server version is valid, e.g. "23.8.2.7".
+func validVersion(v string) bool {
+	parts := strings.Split(v, ".")
+	if len(parts) > 4 {
+		return false
+	}
+	for _, p := range parts {
+		if _, err := strconv.ParseUint(p, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// The database that connections are bound to, if it was not set.
+const defaultDatabase = "default"
+
+// ClickHouse data types. See: https://clickhouse.com/docs/en/sql-reference/data-types.
+const (
+	TypeBool        = "Bool"
+	TypeInt8        = "Int8"
+	TypeInt16       = "Int16"
+	TypeInt32       = "Int32"
+	TypeInt64       = "Int64"
+	TypeInt128      = "Int128"
+	TypeInt256      = "Int256"
+	TypeUInt8       = "UInt8"
+	TypeUInt16      = "UInt16"
+	TypeUInt32      = "UInt32"
+	TypeUInt64      = "UInt64"
+	TypeUInt128     = "UInt128"
+	TypeUInt256     = "UInt256"
+	TypeFloat32     = "Float32"
+	TypeFloat64     = "Float64"
+	TypeDecimal     = "Decimal"
+	TypeString      = "String"
+	TypeFixedString = "FixedString"
+	TypeUUID        = "UUID"
+	TypeDate        = "Date"
+	TypeDate32      = "Date32"
+	TypeDateTime    = "DateTime"
+	TypeDateTime64  = "DateTime64"
+	TypeIPv4        = "IPv4"
+	TypeIPv6        = "IPv6"
+	TypeJSON        = "JSON"
+)
+
+// Table engines. See: https://clickhouse.com/docs/en/engines/table-engines.
+const (
+	EngineMergeTree        = "MergeTree"
+	EngineView             = "View"
+	EngineMaterializedView = "MaterializedView"
+)
+
+// Column default kinds, as reported by system.columns.
+const (
+	defaultKindDefault      = "DEFAULT"
+	defaultKindMaterialized = "MATERIALIZED"
+	defaultKindAlias        = "ALIAS"
+)
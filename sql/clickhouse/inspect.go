@@ -0,0 +1,651 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// An inspect provides a ClickHouse implementation for schema.Inspector.
+type inspect struct{ conn }
+
+var _ schema.Inspector = (*inspect)(nil)
+
+// InspectRealm returns schema descriptions of all resources in the given realm.
+func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	schemas, err := i.schemas(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &schema.InspectRealmOption{}
+	}
+	r := schema.NewRealm(schemas...)
+	mode := sqlx.ModeInspectRealm(opts)
+	if len(schemas) == 0 || !mode.Is(schema.InspectTables) && !mode.Is(schema.InspectViews) {
+		return sqlx.ExcludeRealm(r, opts.Exclude)
+	}
+	if err := i.inspectTables(ctx, r, mode, nil); err != nil {
+		return nil, err
+	}
+	sqlx.LinkSchemaTables(schemas)
+	return sqlx.ExcludeRealm(r, opts.Exclude)
+}
+
+// InspectSchema returns schema descriptions of the tables in the given schema.
+// If the schema name is empty, the result will be the connected database.
+func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (s *schema.Schema, err error) {
+	schemas, err := i.schemas(ctx, &schema.InspectRealmOption{Schemas: []string{name}})
+	if err != nil {
+		return nil, err
+	}
+	switch n := len(schemas); {
+	case n == 0:
+		return nil, &schema.NotExistError{Err: fmt.Errorf("clickhouse: schema %q was not found", name)}
+	case n > 1:
+		return nil, fmt.Errorf("clickhouse: %d schemas were found for %q", n, name)
+	}
+	if opts == nil {
+		opts = &schema.InspectOptions{}
+	}
+	r := schema.NewRealm(schemas...)
+	if mode := sqlx.ModeInspectSchema(opts); mode.Is(schema.InspectTables) || mode.Is(schema.InspectViews) {
+		if err := i.inspectTables(ctx, r, mode, opts); err != nil {
+			return nil, err
+		}
+		sqlx.LinkSchemaTables(schemas)
+	}
+	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
+}
+
+func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, mode schema.InspectMode, opts *schema.InspectOptions) error {
+	if err := i.tables(ctx, r, mode, opts); err != nil {
+		return err
+	}
+	for _, s := range r.Schemas {
+		if len(s.Tables) == 0 {
+			continue
+		}
+		if err := i.columns(ctx, s); err != nil {
+			return err
+		}
+		if err := i.indexes(ctx, s); err != nil {
+			return err
+		}
+		// Link the retention policies to the inspected columns.
+		for _, t := range s.Tables {
+			for _, a := range t.Attrs {
+				if r, ok := a.(*schema.Retention); ok {
+					if c, ok := t.Column(r.C.Name); ok {
+						r.C = c
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// schemas returns the list of the schemas (databases) in the server.
+func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
+	var (
+		args  []any
+		query = schemasQuery
+	)
+	if opts != nil {
+		switch n := len(opts.Schemas); {
+		case n == 1 && opts.Schemas[0] == "":
+			query = fmt.Sprintf(schemasQueryArgs, "= currentDatabase()")
+		case n == 1 && opts.Schemas[0] != "":
+			query = fmt.Sprintf(schemasQueryArgs, "= ?")
+			args = append(args, opts.Schemas[0])
+		case n > 0:
+			query = fmt.Sprintf(schemasQueryArgs, "IN ("+nArgs(len(opts.Schemas))+")")
+			for _, s := range opts.Schemas {
+				args = append(args, s)
+			}
+		}
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse: querying schemas: %w", err)
+	}
+	names, err := sqlx.ScanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse: scanning schemas: %w", err)
+	}
+	schemas := make([]*schema.Schema, 0, len(names))
+	for _, name := range names {
+		schemas = append(schemas, schema.New(name))
+	}
+	return schemas, nil
+}
+
+// tables queries and adds the tables and the views of the realm schemas.
+func (i *inspect) tables(ctx context.Context, realm *schema.Realm, mode schema.InspectMode, opts *schema.InspectOptions) error {
+	var (
+		args  []any
+		query = fmt.Sprintf(tablesQuery, nArgs(len(realm.Schemas)))
+	)
+	for _, s := range realm.Schemas {
+		args = append(args, s.Name)
+	}
+	if opts != nil && len(opts.Tables) > 0 {
+		for _, t := range opts.Tables {
+			args = append(args, t)
+		}
+		query = fmt.Sprintf(tablesQueryArgs, nArgs(len(realm.Schemas)), nArgs(len(opts.Tables)))
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("clickhouse: querying tables: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			tSchema, name, engine, engineFull, sortingKey, partitionKey string
+			primaryKey, samplingKey, comment, asSelect, createQuery     string
+		)
+		if err := rows.Scan(&tSchema, &name, &engine, &engineFull, &sortingKey, &partitionKey, &primaryKey, &samplingKey, &comment, &asSelect, &createQuery); err != nil {
+			return fmt.Errorf("clickhouse: scan table information: %w", err)
+		}
+		s, ok := realm.Schema(tSchema)
+		if !ok {
+			return fmt.Errorf("clickhouse: schema %q was not found in realm", tSchema)
+		}
+		switch engine {
+		case EngineView, EngineMaterializedView:
+			if !mode.Is(schema.InspectViews) {
+				continue
+			}
+			v := schema.NewView(name, asSelect)
+			if engine == EngineMaterializedView {
+				v.AddAttrs(materializedView(s.Name, createQuery)...)
+			}
+			s.AddViews(v)
+		default:
+			if !mode.Is(schema.InspectTables) {
+				continue
+			}
+			t := schema.NewTable(name)
+			t.AddAttrs(&Engine{V: engineOf(engineFull, engine)})
+			if sortingKey != "" {
+				t.AddAttrs(&OrderBy{X: sortingKey})
+			}
+			if partitionKey != "" {
+				t.AddAttrs(&PartitionBy{X: partitionKey})
+			}
+			if samplingKey != "" {
+				t.AddAttrs(&SampleBy{X: samplingKey})
+			}
+			if r, ok := retention(engineClause(engineFull, "TTL")); ok {
+				t.AddAttrs(r)
+			}
+			if comment != "" {
+				t.SetComment(comment)
+			}
+			s.AddTables(t)
+			// The primary key is kept only if it is not implied
+			// by the sorting key, as it is optional in that case.
+			if primaryKey != "" && exprKey(primaryKey) != exprKey(sortingKey) {
+				t.SetPrimaryKey(&schema.Index{Table: t, Attrs: []schema.Attr{&keyExpr{X: primaryKey}}})
+			}
+		}
+	}
+	return rows.Close()
+}
+
+// columns queries and appends the columns of the given table.
+func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, columnsQuery, s)
+	if err != nil {
+		return fmt.Errorf("clickhouse: query schema %q columns: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := i.addColumn(s, rows); err != nil {
+			return fmt.Errorf("clickhouse: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// Primary keys are linked to the columns after they were added.
+	for _, t := range s.Tables {
+		if err := linkPK(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumn scans the current row and adds a new column from it to the table.
+func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) error {
+	var table, name, typ, defKind, defExpr, comment, codec string
+	if err := rows.Scan(&table, &name, &typ, &defKind, &defExpr, &comment, &codec); err != nil {
+		return err
+	}
+	t, ok := s.Table(table)
+	if !ok {
+		return fmt.Errorf("table %q was not found in schema", table)
+	}
+	raw, null := nonNullable(typ)
+	c := &schema.Column{
+		Name: name,
+		Type: &schema.ColumnType{
+			Raw:  typ,
+			Null: null,
+		},
+	}
+	ct, err := ParseType(raw)
+	if err != nil {
+		return err
+	}
+	c.Type.Type = ct
+	switch defKind {
+	case defaultKindDefault:
+		c.Default = defaultExpr(defExpr)
+	case defaultKindMaterialized, defaultKindAlias:
+		c.SetGeneratedExpr(&schema.GeneratedExpr{Expr: defExpr, Type: defKind})
+	}
+	if comment != "" {
+		c.SetComment(comment)
+	}
+	// Codecs are reported in their declaration form, e.g. "CODEC(ZSTD(1))".
+	if codec != "" {
+		if x, ok := unwrapType(codec, "CODEC"); ok {
+			codec = x
+		}
+		c.Attrs = append(c.Attrs, &Codec{V: codec})
+	}
+	t.AddColumns(c)
+	return nil
+}
+
+// defaultExpr returns the default value of a column from its expression.
+func defaultExpr(x string) schema.Expr {
+	switch {
+	case sqlx.IsLiteralNumber(x), sqlx.IsQuoted(x, '\''):
+		return &schema.Literal{V: x}
+	default:
+		return &schema.RawExpr{X: x}
+	}
+}
+
+// indexes queries and appends the data skipping indexes of the given table.
+func (i *inspect) indexes(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, indexesQuery, s)
+	if err != nil {
+		return fmt.Errorf("clickhouse: query schema %q indexes: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			granularity            int
+			table, name, expr, typ string
+		)
+		if err := rows.Scan(&table, &name, &expr, &typ, &granularity); err != nil {
+			return fmt.Errorf("clickhouse: scanning indexes for schema %q: %w", s.Name, err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("clickhouse: table %q was not found in schema", table)
+		}
+		idx := &schema.Index{Name: name, Table: t}
+		idx.AddAttrs(&IndexType{T: typ}, &IndexGranularity{V: granularity})
+		part := &schema.IndexPart{SeqNo: 1}
+		if c, ok := t.Column(strings.Trim(expr, "`")); ok {
+			part.C = c
+			c.Indexes = append(c.Indexes, idx)
+		} else {
+			part.X = &schema.RawExpr{X: expr}
+		}
+		idx.Parts = append(idx.Parts, part)
+		t.AddIndexes(idx)
+	}
+	return rows.Err()
+}
+
+// linkPK replaces the key expression of the table primary
+// key (if exists) with its parts, once columns were added.
+func linkPK(t *schema.Table) error {
+	var k keyExpr
+	if t.PrimaryKey == nil || !sqlx.Has(t.PrimaryKey.Attrs, &k) {
+		return nil
+	}
+	pk := t.PrimaryKey
+	pk.Attrs = nil
+	for i, x := range splitArgs(trimParens(k.X)) {
+		part := &schema.IndexPart{SeqNo: i + 1}
+		if c, ok := t.Column(strings.Trim(x, "`")); ok {
+			part.C = c
+			c.Indexes = append(c.Indexes, pk)
+		} else {
+			part.X = &schema.RawExpr{X: x}
+		}
+		pk.Parts = append(pk.Parts, part)
+	}
+	if len(pk.Parts) == 0 {
+		return fmt.Errorf("clickhouse: empty primary key for table %q", t.Name)
+	}
+	return nil
+}
+
+// materializedView returns the attributes of a materialized view from its
+// CREATE statement. The TO clause is used for views that write to an existing
+// table, and the ENGINE clause for views that store their data in an inner table.
+func materializedView(db, create string) []schema.Attr {
+	mv := &MaterializedView{}
+	head, _, _ := strings.Cut(create, " AS SELECT ")
+	if _, to, ok := strings.Cut(head, " TO "); ok {
+		to, _, _ = strings.Cut(strings.TrimSpace(to), " ")
+		to, _, _ = strings.Cut(to, "(")
+		// Targets in the same database are unqualified.
+		if q, n, ok := strings.Cut(to, "."); ok && strings.Trim(q, "`") == db {
+			to = n
+		}
+		mv.To = strings.ReplaceAll(to, "`", "")
+		return []schema.Attr{mv}
+	}
+	_, full, ok := strings.Cut(head, " ENGINE = ")
+	if !ok {
+		return []schema.Attr{mv}
+	}
+	attrs := []schema.Attr{mv, &Engine{V: engineOf(full, "")}}
+	if x := engineClause(full, "ORDER BY"); x != "" {
+		attrs = append(attrs, &OrderBy{X: x})
+	}
+	if x := engineClause(full, "PARTITION BY"); x != "" {
+		attrs = append(attrs, &PartitionBy{X: x})
+	}
+	return attrs
+}
+
+// reTTL matches table TTL expressions that can be represented as retention policies.
+// e.g. "created_at + toIntervalDay(30)" or "created_at + INTERVAL 30 DAY".
+var reTTL = regexp.MustCompile("^`?(\\w+)`? \\+ (?:toInterval(Hour|Day|Week|Month|Year)\\((\\d+)\\)|INTERVAL (\\d+) (HOUR|DAY|WEEK|MONTH|YEAR))$")
+
+// retention returns the retention policy described by the given TTL expression, if
+// it can be represented as such. The column of the policy is linked after the table
+// columns were inspected.
+func retention(x string) (*schema.Retention, bool) {
+	m := reTTL.FindStringSubmatch(strings.Join(strings.Fields(x), " "))
+	if m == nil {
+		return nil, false
+	}
+	n, u := m[3], m[2]
+	if n == "" {
+		n, u = m[4], m[5]
+	}
+	p, err := strconv.Atoi(n)
+	if err != nil || p <= 0 {
+		return nil, false
+	}
+	return &schema.Retention{C: &schema.Column{Name: m[1]}, Period: p, Unit: strings.ToUpper(u)}, true
+}
+
+// Clauses that may follow the engine definition.
+var engineClauses = []string{"PARTITION BY", "ORDER BY", "PRIMARY KEY", "SAMPLE BY", "TTL", "SETTINGS"}
+
+// engineOf returns the engine definition (i.e. its name and arguments) from the
+// full engine description, e.g. "ReplacingMergeTree(ver)" from "ReplacingMergeTree(ver)
+// ORDER BY id SETTINGS index_granularity = 8192". The def is returned if full is empty.
+func engineOf(full, def string) string {
+	if full = strings.TrimSpace(full); full == "" {
+		return def
+	}
+	end := len(full)
+	for _, c := range engineClauses {
+		if i := clauseIndex(full, c); i != -1 && i < end {
+			end = i
+		}
+	}
+	return strings.TrimSpace(full[:end])
+}
+
+// engineClause returns the expression of the given clause from the full engine
+// description, e.g. "(a, b)" for the ORDER BY clause of "MergeTree ORDER BY (a, b)".
+func engineClause(full, clause string) string {
+	i := clauseIndex(full, clause)
+	if i == -1 {
+		return ""
+	}
+	x := full[i+len(clause):]
+	end := len(x)
+	for _, c := range engineClauses {
+		if j := clauseIndex(x, c); j != -1 && j < end {
+			end = j
+		}
+	}
+	return strings.TrimSpace(x[:end])
+}
+
+// clauseIndex returns the index of the given clause keyword in s,
+// ignoring keywords in parentheses or string literals, or -1.
+func clauseIndex(s, clause string) int {
+	var (
+		depth  int
+		quoted bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && (i == 0 || s[i-1] == ' ') && strings.HasPrefix(s[i:], clause+" "):
+			return i
+		}
+	}
+	return -1
+}
+
+// querySchema queries the rows of the given schema tables. The schema name
+// is passed as the first argument, followed by the table names.
+func (i *inspect) querySchema(ctx context.Context, query string, s *schema.Schema) (*sql.Rows, error) {
+	args := []any{s.Name}
+	for _, t := range s.Tables {
+		args = append(args, t.Name)
+	}
+	return i.QueryContext(ctx, fmt.Sprintf(query, nArgs(len(s.Tables))), args...)
+}
+
+// nArgs returns n positional placeholders, e.g. "?, ?".
+func nArgs(n int) string { return strings.Repeat("?, ", n-1) + "?" }
+
+const (
+	// Query to list system variables.
+	variablesQuery = "SELECT version(), currentDatabase()"
+
+	// Query to list databases. System databases are excluded.
+	schemasQuery = "SELECT name FROM system.databases WHERE name NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema') ORDER BY name"
+
+	// Query to list specific databases.
+	schemasQueryArgs = "SELECT name FROM system.databases WHERE name NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema') AND name %s ORDER BY name"
+
+	// Query to list schema tables and views. Temporary tables and the
+	// inner tables of materialized views (i.e. ".inner" tables) are skipped.
+	tablesQuery = `
+SELECT
+	database,
+	name,
+	engine,
+	engine_full,
+	sorting_key,
+	partition_key,
+	primary_key,
+	sampling_key,
+	comment,
+	as_select,
+	create_table_query
+FROM
+	system.tables
+WHERE
+	database IN (%s)
+	AND NOT is_temporary
+	AND NOT startsWith(name, '.inner')
+ORDER BY
+	database, name
+`
+
+	// Query to list specific schema tables and views.
+	tablesQueryArgs = `
+SELECT
+	database,
+	name,
+	engine,
+	engine_full,
+	sorting_key,
+	partition_key,
+	primary_key,
+	sampling_key,
+	comment,
+	as_select,
+	create_table_query
+FROM
+	system.tables
+WHERE
+	database IN (%s)
+	AND name IN (%s)
+	AND NOT is_temporary
+ORDER BY
+	database, name
+`
+
+	// Query to list table columns.
+	columnsQuery = `
+SELECT
+	table,
+	name,
+	type,
+	default_kind,
+	default_expression,
+	comment,
+	compression_codec
+FROM
+	system.columns
+WHERE
+	database = ?
+	AND table IN (%s)
+ORDER BY
+	table, position
+`
+
+	// Query to list table data skipping indexes.
+	indexesQuery = `
+SELECT
+	table,
+	name,
+	expr,
+	type_full,
+	granularity
+FROM
+	system.data_skipping_indices
+WHERE
+	database = ?
+	AND table IN (%s)
+ORDER BY
+	table, name
+`
+)
+
+type (
+	// Engine describes the engine of a table, including its arguments. For
+	// example, "MergeTree" or "ReplacingMergeTree(version)". Tables that are
+	// defined without an engine are created using the MergeTree engine.
+	// https://clickhouse.com/docs/en/engines/table-engines
+	Engine struct {
+		schema.Attr
+		V string
+	}
+
+	// OrderBy describes the sorting key of a MergeTree table, e.g. "(id, ts)".
+	// The sorting key is also used as the primary key, unless it was set.
+	OrderBy struct {
+		schema.Attr
+		X string
+	}
+
+	// PartitionBy describes the partition key of a MergeTree table.
+	PartitionBy struct {
+		schema.Attr
+		X string
+	}
+
+	// SampleBy describes the sampling expression of a MergeTree table.
+	SampleBy struct {
+		schema.Attr
+		X string
+	}
+
+	// Codec describes the compression codecs of a column, e.g. "Delta, ZSTD(1)".
+	// https://clickhouse.com/docs/en/sql-reference/statements/create/table#column-compression-codecs
+	Codec struct {
+		schema.Attr
+		V string
+	}
+
+	// IndexType describes the type of a data skipping index, including its
+	// arguments. For example, "minmax", "set(100)" or "bloom_filter(0.01)".
+	// https://clickhouse.com/docs/en/optimize/skipping-indexes
+	IndexType struct {
+		schema.Attr
+		T string
+	}
+
+	// IndexGranularity describes the granularity of a data skipping index.
+	IndexGranularity struct {
+		schema.Attr
+		V int
+	}
+
+	// MaterializedView marks a view as materialized. To holds the table the
+	// view writes to, or is empty in case the view stores its data in an inner
+	// table that is defined by the Engine and OrderBy attributes of the view.
+	// https://clickhouse.com/docs/en/sql-reference/statements/create/view#materialized-view
+	MaterializedView struct {
+		schema.Attr
+		To string
+	}
+
+	// keyExpr holds the primary key expression until the columns are inspected.
+	keyExpr struct {
+		schema.Attr
+		X string
+	}
+
+	// UUIDType represents the UUID type.
+	UUIDType struct {
+		schema.Type
+		T string
+	}
+
+	// IPType represents the IPv4 and IPv6 types.
+	IPType struct {
+		schema.Type
+		T string
+	}
+
+	// NativeType represents a ClickHouse type that has no equivalent in the schema
+	// package, such as Array(T), Map(K, V), LowCardinality(T) or Enum8('a' = 1).
+	// The type is kept and formatted as it was defined.
+	NativeType struct {
+		schema.Type
+		T string
+	}
+)
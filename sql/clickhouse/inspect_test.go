@@ -0,0 +1,204 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+// ClickHouse reports missing values as empty strings rather than NULLs.
+var (
+	tablesColumns  = []string{"database", "name", "engine", "engine_full", "sorting_key", "partition_key", "primary_key", "sampling_key", "comment", "as_select", "create_table_query"}
+	columnsColumns = []string{"table", "name", "type", "default_kind", "default_expression", "comment", "compression_codec"}
+	indexesColumns = []string{"table", "name", "expr", "type_full", "granularity"}
+)
+
+func TestDriver_InspectTable(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("23.8.1.1")
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= ?"))).
+		WithArgs("default").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("default"))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQueryArgs, "?", "?"))).
+		WithArgs("default", "events").
+		WillReturnRows(sqlmock.NewRows(tablesColumns).
+			AddRow("default", "events", "ReplacingMergeTree", "ReplacingMergeTree(ts) PARTITION BY toYYYYMM(ts) PRIMARY KEY id ORDER BY (id, ts) SETTINGS index_granularity = 8192", "id, ts", "toYYYYMM(ts)", "id", "", "user events", "", ""))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "?"))).
+		WithArgs("default", "events").
+		WillReturnRows(sqlmock.NewRows(columnsColumns).
+			AddRow("events", "id", "UInt64", "", "", "", "").
+			AddRow("events", "ts", "DateTime64(3)", "DEFAULT", "now64()", "", "CODEC(Delta(8), ZSTD(1))").
+			AddRow("events", "name", "LowCardinality(Nullable(String))", "", "", "event name", "").
+			AddRow("events", "price", "Decimal(18, 2)", "DEFAULT", "0", "", "").
+			AddRow("events", "day", "Date", "MATERIALIZED", "toDate(ts)", "", "").
+			AddRow("events", "tags", "Array(String)", "ALIAS", "splitByChar(',', name)", "", ""))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "?"))).
+		WithArgs("default", "events").
+		WillReturnRows(sqlmock.NewRows(indexesColumns).
+			AddRow("events", "idx_name", "name", "bloom_filter(0.01)", 4).
+			AddRow("events", "idx_price", "price * 2", "minmax", 1))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "default", &schema.InspectOptions{Tables: []string{"events"}})
+	require.NoError(t, err)
+	tbl, ok := s.Table("events")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{
+		&Engine{V: "ReplacingMergeTree(ts)"},
+		&OrderBy{X: "id, ts"},
+		&PartitionBy{X: "toYYYYMM(ts)"},
+		&schema.Comment{Text: "user events"},
+	}, tbl.Attrs)
+	p := 3
+	require.EqualValues(t, []*schema.Column{
+		{Name: "id", Type: &schema.ColumnType{Raw: "UInt64", Type: &schema.IntegerType{T: TypeUInt64, Unsigned: true}}},
+		{Name: "ts", Type: &schema.ColumnType{Raw: "DateTime64(3)", Type: &schema.TimeType{T: TypeDateTime64, Precision: &p}}, Default: &schema.RawExpr{X: "now64()"}, Attrs: []schema.Attr{&Codec{V: "Delta(8), ZSTD(1)"}}},
+		{Name: "name", Type: &schema.ColumnType{Raw: "LowCardinality(Nullable(String))", Type: &NativeType{T: "LowCardinality(String)"}, Null: true}, Attrs: []schema.Attr{&schema.Comment{Text: "event name"}}},
+		{Name: "price", Type: &schema.ColumnType{Raw: "Decimal(18, 2)", Type: &schema.DecimalType{T: TypeDecimal, Precision: 18, Scale: 2}}, Default: &schema.Literal{V: "0"}},
+		{Name: "day", Type: &schema.ColumnType{Raw: "Date", Type: &schema.TimeType{T: TypeDate}}, Attrs: []schema.Attr{&schema.GeneratedExpr{Expr: "toDate(ts)", Type: defaultKindMaterialized}}},
+		{Name: "tags", Type: &schema.ColumnType{Raw: "Array(String)", Type: &NativeType{T: "Array(String)"}}, Attrs: []schema.Attr{&schema.GeneratedExpr{Expr: "splitByChar(',', name)", Type: defaultKindAlias}}},
+	}, columnsOnly(tbl.Columns))
+	// The primary key differs from the sorting key.
+	require.NotNil(t, tbl.PrimaryKey)
+	require.Len(t, tbl.PrimaryKey.Parts, 1)
+	require.Equal(t, "id", tbl.PrimaryKey.Parts[0].C.Name)
+	require.Len(t, tbl.Indexes, 2)
+	require.Equal(t, "idx_name", tbl.Indexes[0].Name)
+	require.Equal(t, "name", tbl.Indexes[0].Parts[0].C.Name)
+	require.Equal(t, []schema.Attr{&IndexType{T: "bloom_filter(0.01)"}, &IndexGranularity{V: 4}}, tbl.Indexes[0].Attrs)
+	require.Equal(t, &schema.RawExpr{X: "price * 2"}, tbl.Indexes[1].Parts[0].X)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_InspectSchema(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("23.8.1.1")
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= currentDatabase()"))).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("default"))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "?"))).
+		WithArgs("default").
+		WillReturnRows(sqlmock.NewRows(tablesColumns).
+			AddRow("default", "totals", "SummingMergeTree", "SummingMergeTree ORDER BY day SETTINGS index_granularity = 8192", "day", "", "day", "", "", "", "").
+			AddRow("default", "daily", "MaterializedView", "", "", "", "", "", "", "SELECT toDate(ts) AS day, count() AS c FROM default.events GROUP BY day", "CREATE MATERIALIZED VIEW default.daily TO default.totals (`day` Date, `c` UInt64) AS SELECT toDate(ts) AS day, count() AS c FROM default.events GROUP BY day").
+			AddRow("default", "hourly", "MaterializedView", "", "", "", "", "", "", "SELECT toStartOfHour(ts) AS h FROM default.events", "CREATE MATERIALIZED VIEW default.hourly (`h` DateTime) ENGINE = MergeTree ORDER BY h SETTINGS index_granularity = 8192 AS SELECT toStartOfHour(ts) AS h FROM default.events").
+			AddRow("default", "names", "View", "", "", "", "", "", "", "SELECT name FROM default.events", "CREATE VIEW default.names (`name` String) AS SELECT name FROM default.events"))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "?"))).
+		WithArgs("default", "totals").
+		WillReturnRows(sqlmock.NewRows(columnsColumns).
+			AddRow("totals", "day", "Date", "", "", "", "").
+			AddRow("totals", "c", "UInt64", "", "", "", ""))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "?"))).
+		WithArgs("default", "totals").
+		WillReturnRows(sqlmock.NewRows(indexesColumns))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{Mode: schema.InspectTables | schema.InspectViews})
+	require.NoError(t, err)
+	require.Equal(t, "default", s.Name)
+	tbl, ok := s.Table("totals")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&Engine{V: "SummingMergeTree"}, &OrderBy{X: "day"}}, tbl.Attrs)
+	// A primary key that is implied by the sorting key is omitted.
+	require.Nil(t, tbl.PrimaryKey)
+	require.Len(t, s.Views, 3)
+	v, ok := s.View("daily")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&MaterializedView{To: "totals"}}, v.Attrs)
+	v, ok = s.View("hourly")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&MaterializedView{}, &Engine{V: "MergeTree"}, &OrderBy{X: "h"}}, v.Attrs)
+	v, ok = s.View("names")
+	require.True(t, ok)
+	require.Empty(t, v.Attrs)
+	require.Equal(t, "SELECT name FROM default.events", v.Def)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_InspectRealm(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("23.8.1.1")
+	m.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("analytics").AddRow("default"))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "?, ?"))).
+		WithArgs("analytics", "default").
+		WillReturnRows(sqlmock.NewRows(tablesColumns).
+			AddRow("analytics", "logs", "Log", "Log", "", "", "", "", "", "", ""))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "?"))).
+		WithArgs("analytics", "logs").
+		WillReturnRows(sqlmock.NewRows(columnsColumns).
+			AddRow("logs", "msg", "String", "", "", "", ""))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "?"))).
+		WithArgs("analytics", "logs").
+		WillReturnRows(sqlmock.NewRows(indexesColumns))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	r, err := drv.InspectRealm(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 2)
+	require.Empty(t, r.Schemas[1].Tables)
+	tbl, ok := r.Schemas[0].Table("logs")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&Engine{V: "Log"}}, tbl.Attrs)
+	require.Len(t, tbl.Columns, 1)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestMaterializedView(t *testing.T) {
+	require.Equal(t, []schema.Attr{&MaterializedView{To: "other.t"}}, materializedView("default", "CREATE MATERIALIZED VIEW default.mv TO other.t (`a` Int32) AS SELECT a FROM default.src"))
+	require.Equal(t, []schema.Attr{&MaterializedView{To: "t"}}, materializedView("default", "CREATE MATERIALIZED VIEW default.mv TO `default`.`t` AS SELECT a FROM default.src"))
+	require.Equal(t, []schema.Attr{
+		&MaterializedView{},
+		&Engine{V: "AggregatingMergeTree"},
+		&OrderBy{X: "(a, b)"},
+		&PartitionBy{X: "toYYYYMM(d)"},
+	}, materializedView("default", "CREATE MATERIALIZED VIEW default.mv (`a` Int32) ENGINE = AggregatingMergeTree PARTITION BY toYYYYMM(d) ORDER BY (a, b) SETTINGS index_granularity = 8192 AS SELECT a FROM default.src"))
+}
+
+func TestRetention(t *testing.T) {
+	for x, want := range map[string]*schema.Retention{
+		"ts + toIntervalDay(30)":         {C: &schema.Column{Name: "ts"}, Period: 30, Unit: "DAY"},
+		"`ts` + INTERVAL 1 MONTH":        {C: &schema.Column{Name: "ts"}, Period: 1, Unit: "MONTH"},
+		"created  +  toIntervalHour(12)": {C: &schema.Column{Name: "created"}, Period: 12, Unit: "HOUR"},
+	} {
+		r, ok := retention(x)
+		require.True(t, ok, x)
+		require.Equal(t, want, r, x)
+	}
+	for _, x := range []string{"", "ts + toIntervalDay(0)", "ts + toIntervalDay(30) DELETE WHERE a = 1", "ts + toIntervalDay(1) TO VOLUME 'cold'", "toDate(ts) + toIntervalDay(1)"} {
+		_, ok := retention(x)
+		require.False(t, ok, x)
+	}
+	require.Equal(t, "ts + toIntervalDay(30)", engineClause("MergeTree ORDER BY id TTL ts + toIntervalDay(30) SETTINGS index_granularity = 8192", "TTL"))
+}
+
+type mock struct {
+	sqlmock.Sqlmock
+}
+
+func (m mock) version(version string) {
+	m.ExpectQuery(sqltest.Escape(variablesQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "database"}).AddRow(version, "default"))
+}
+
+func columnsOnly(columns []*schema.Column) []*schema.Column {
+	for _, c := range columns {
+		c.Indexes = nil
+	}
+	return columns
+}
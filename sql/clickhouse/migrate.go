@@ -0,0 +1,708 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A planApply provides migration capabilities for schema elements.
+type planApply struct{ conn }
+
+// PlanChanges returns a migration plan for the given schema changes.
+func (p *planApply) PlanChanges(_ context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
+	s := &state{
+		conn: p.conn,
+		Plan: migrate.Plan{
+			Name: name,
+			// A plan is reversible, if all
+			// its changes are reversible.
+			Reversible: true,
+		},
+	}
+	for _, o := range opts {
+		o(&s.PlanOptions)
+	}
+	// Plan the changes for the target version, instead of the connected one.
+	if s.TargetVersion != "" {
+		if !validVersion(s.TargetVersion) {
+			return nil, fmt.Errorf("clickhouse: malformed target version: %s", s.TargetVersion)
+		}
+		s.version = s.TargetVersion
+	}
+	s.Transactional = s.capabilities().Is(migrate.CapTransactionalDDL)
+	if err := s.plan(changes); err != nil {
+		return nil, err
+	}
+	for _, c := range s.Changes {
+		if c.Reverse == "" {
+			s.Reversible = false
+			break
+		}
+	}
+	return &s.Plan, nil
+}
+
+// ApplyChanges applies the changes on the database. An error is returned
+// if the driver is unable to produce a plan to it, or one of the statements
+// is failed or unsupported.
+func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change, opts ...migrate.PlanOption) error {
+	return sqlx.ApplyChanges(ctx, changes, p, opts...)
+}
+
+// state represents the state of a planning. It is not part of
+// planApply so that multiple planning/applying can be called
+// in parallel.
+type state struct {
+	conn
+	migrate.Plan
+	migrate.PlanOptions
+}
+
+// plan builds the migration plan for applying the
+// given changes on the attached connection.
+func (s *state) plan(changes []schema.Change) error {
+	if s.SchemaQualifier != nil {
+		if err := sqlx.CheckChangesScope(changes); err != nil {
+			return err
+		}
+	}
+	// Views may depend on tables (e.g. the target table of a materialized view).
+	// Therefore, they are dropped first, and created after all other changes.
+	drop, changes, add := sqlx.SplitViews(changes)
+	if err := s.views(drop); err != nil {
+		return err
+	}
+	planned, err := s.topLevel(changes)
+	if err != nil {
+		return err
+	}
+	for _, c := range planned {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			err = s.addTable(c)
+		case *schema.DropTable:
+			s.dropTable(c)
+		case *schema.ModifyTable:
+			err = s.modifyTable(c)
+		case *schema.RenameTable:
+			s.renameTable(c)
+		default:
+			err = fmt.Errorf("unsupported change %T", c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.views(add)
+}
+
+// topLevel appends first the changes for creating or dropping schemas (top-level schema elements).
+func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			b := s.Build("CREATE DATABASE")
+			if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
+				b.P("IF NOT EXISTS")
+			}
+			s.append(&migrate.Change{
+				Cmd:     s.onCluster(b.Ident(c.S.Name)).String(),
+				Source:  c,
+				Reverse: s.onCluster(s.Build("DROP DATABASE").Ident(c.S.Name)).String(),
+				Comment: fmt.Sprintf("add new schema named %q", c.S.Name),
+			})
+		case *schema.DropSchema:
+			b := s.Build("DROP DATABASE")
+			if sqlx.Has(c.Extra, &schema.IfExists{}) {
+				b.P("IF EXISTS")
+			}
+			s.append(&migrate.Change{
+				Cmd:     s.onCluster(b.Ident(c.S.Name)).String(),
+				Source:  c,
+				Comment: fmt.Sprintf("drop schema named %q", c.S.Name),
+			})
+		case *schema.ModifySchema:
+			// Databases in ClickHouse have no attributes that can be altered.
+			if len(c.Changes) > 0 {
+				return nil, fmt.Errorf("unsupported schema change %T", c.Changes[0])
+			}
+		default:
+			planned = append(planned, c)
+		}
+	}
+	return planned, nil
+}
+
+// addTable builds and appends a migration change
+// for creating a table in a schema.
+func (s *state) addTable(add *schema.AddTable) error {
+	var (
+		errs []string
+		b    = s.onCluster(s.Build("CREATE TABLE").Table(add.T))
+	)
+	if len(add.T.Columns) == 0 {
+		return fmt.Errorf("table %q has no columns", add.T.Name)
+	}
+	if err := checkTable(add.T); err != nil {
+		return err
+	}
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
+			if err := s.column(b, add.T.Columns[i]); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+		for _, idx := range add.T.Indexes {
+			b.Comma()
+			if err := s.index(b, idx); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
+	}
+	s.engine(b, add.T.Attrs, add.T.PrimaryKey)
+	if c := (schema.Comment{}); sqlx.Has(add.T.Attrs, &c) && c.Text != "" {
+		b.P("COMMENT", quote(c.Text))
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  add,
+		Reverse: s.onCluster(s.Build("DROP TABLE").Table(add.T)).String(),
+		Comment: fmt.Sprintf("create %q table", add.T.Name),
+	})
+	return nil
+}
+
+// engine writes the engine of a table (or a materialized view) and its
+// clauses to the builder. MergeTree tables that are defined without a
+// sorting key (or a primary key) are created with an empty sorting key.
+func (s *state) engine(b *sqlx.Builder, attrs []schema.Attr, pk *schema.Index) {
+	e := engine(attrs)
+	b.P("ENGINE =", e)
+	var o OrderBy
+	switch {
+	case sqlx.Has(attrs, &o):
+		b.P("ORDER BY", sortKey(o.X))
+	case pk != nil:
+		b.P("ORDER BY", sortKey(keyOf(pk)))
+	case strings.Contains(e, EngineMergeTree):
+		b.P("ORDER BY tuple()")
+	}
+	if p := (PartitionBy{}); sqlx.Has(attrs, &p) {
+		b.P("PARTITION BY", p.X)
+	}
+	if pk != nil && o.X != "" && exprKey(o.X) != exprKey(keyOf(pk)) {
+		b.P("PRIMARY KEY", sortKey(keyOf(pk)))
+	}
+	if x := (SampleBy{}); sqlx.Has(attrs, &x) {
+		b.P("SAMPLE BY", x.X)
+	}
+	if r := (schema.Retention{}); sqlx.Has(attrs, &r) {
+		b.P("TTL", s.ttl(&r))
+	}
+}
+
+// ttl returns the TTL expression of the given retention policy.
+func (s *state) ttl(r *schema.Retention) string {
+	return fmt.Sprintf("%s + INTERVAL %d %s", s.ident(r.C.Name), r.Period, strings.ToUpper(r.Unit))
+}
+
+// dropTable builds and appends the migrate.Change
+// for dropping a table from a schema.
+func (s *state) dropTable(drop *schema.DropTable) {
+	b := s.Build("DROP TABLE")
+	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+		b.P("IF EXISTS")
+	}
+	s.append(&migrate.Change{
+		Cmd:     s.onCluster(b.Table(drop.T)).String(),
+		Source:  drop,
+		Comment: fmt.Sprintf("drop %q table", drop.T.Name),
+	})
+}
+
+// alterCmd is a single command of an ALTER TABLE statement, and its reverse.
+type alterCmd struct {
+	cmd, reverse string
+}
+
+// modifyTable builds and appends the migration changes for bringing the table
+// into its modified state. Commands are combined into one ALTER TABLE statement,
+// as columns can be added to the sorting key only in the statement that adds
+// them. Commands that cannot be combined with other commands on the same column
+// (i.e. removing a column property) are planned as separate statements.
+func (s *state) modifyTable(modify *schema.ModifyTable) error {
+	if len(modify.T.Columns) == 0 {
+		return fmt.Errorf("table %q has no columns; drop the table instead", modify.T.Name)
+	}
+	var (
+		t               = modify.T
+		pre, cmds, post []*alterCmd
+	)
+	for _, change := range modify.Changes {
+		switch change := change.(type) {
+		case *schema.AddColumn:
+			def, err := s.columnDef(change.C)
+			if err != nil {
+				return err
+			}
+			cmds = append(cmds, &alterCmd{
+				cmd:     "ADD COLUMN " + def,
+				reverse: "DROP COLUMN " + s.ident(change.C.Name),
+			})
+		case *schema.DropColumn:
+			def, err := s.columnDef(change.C)
+			if err != nil {
+				return err
+			}
+			cmds = append(cmds, &alterCmd{
+				cmd:     "DROP COLUMN " + s.ident(change.C.Name),
+				reverse: "ADD COLUMN " + def,
+			})
+		case *schema.ModifyColumn:
+			c, p, err := s.modifyColumn(change)
+			if err != nil {
+				return fmt.Errorf("alter table %q: %w", t.Name, err)
+			}
+			cmds, post = append(cmds, c...), append(post, p...)
+		case *schema.RenameColumn:
+			cmds = append(cmds, &alterCmd{
+				cmd:     fmt.Sprintf("RENAME COLUMN %s TO %s", s.ident(change.From.Name), s.ident(change.To.Name)),
+				reverse: fmt.Sprintf("RENAME COLUMN %s TO %s", s.ident(change.To.Name), s.ident(change.From.Name)),
+			})
+		case *schema.AddIndex:
+			c, err := s.addIndex(change.I)
+			if err != nil {
+				return fmt.Errorf("alter table %q: %w", t.Name, err)
+			}
+			cmds = append(cmds, c)
+		case *schema.DropIndex:
+			c, err := s.addIndex(change.I)
+			if err != nil {
+				return fmt.Errorf("alter table %q: %w", t.Name, err)
+			}
+			cmds = append(cmds, &alterCmd{cmd: c.reverse, reverse: c.cmd})
+		// Indexes are modified by dropping and recreating them, and as
+		// an index name cannot be reused in the same statement, the
+		// existing index is dropped in a separate statement.
+		case *schema.ModifyIndex:
+			from, err := s.addIndex(change.From)
+			if err != nil {
+				return fmt.Errorf("alter table %q: %w", t.Name, err)
+			}
+			to, err := s.addIndex(change.To)
+			if err != nil {
+				return fmt.Errorf("alter table %q: %w", t.Name, err)
+			}
+			pre = append(pre, &alterCmd{cmd: from.reverse, reverse: from.cmd})
+			cmds = append(cmds, to)
+		case *schema.AddAttr, *schema.ModifyAttr, *schema.DropAttr:
+			c, err := s.tableAttr(change)
+			if err != nil {
+				return fmt.Errorf("alter table %q: %w", t.Name, err)
+			}
+			cmds = append(cmds, c)
+		case *schema.AddForeignKey, *schema.DropForeignKey, *schema.ModifyForeignKey:
+			return fmt.Errorf("alter table %q: foreign keys are not supported by ClickHouse", t.Name)
+		case *schema.AddCheck, *schema.DropCheck, *schema.ModifyCheck:
+			return fmt.Errorf("alter table %q: check constraints are not supported", t.Name)
+		default:
+			return fmt.Errorf("alter table %q: unsupported change %T", t.Name, change)
+		}
+	}
+	for _, cs := range [][]*alterCmd{pre, cmds} {
+		if len(cs) > 0 {
+			s.alterTable(modify, cs)
+		}
+	}
+	for _, c := range post {
+		s.alterTable(modify, []*alterCmd{c})
+	}
+	return nil
+}
+
+// alterTable appends the migration change for the given ALTER TABLE commands.
+func (s *state) alterTable(modify *schema.ModifyTable, cmds []*alterCmd) {
+	var (
+		reverse = make([]string, 0, len(cmds))
+		b       = s.onCluster(s.Build("ALTER TABLE").Table(modify.T))
+		r       = b.Clone()
+	)
+	b.MapComma(cmds, func(i int, b *sqlx.Builder) {
+		b.WriteString(cmds[i].cmd)
+	})
+	// Reverse commands are executed in reverse order.
+	for i := len(cmds) - 1; i >= 0; i-- {
+		if cmds[i].reverse == "" {
+			reverse = nil
+			break
+		}
+		reverse = append(reverse, cmds[i].reverse)
+	}
+	c := &migrate.Change{
+		Cmd:     b.String(),
+		Source:  modify,
+		Comment: fmt.Sprintf("modify %q table", modify.T.Name),
+	}
+	if len(reverse) > 0 {
+		c.Reverse = r.P(strings.Join(reverse, ", ")).String()
+	}
+	s.append(c)
+}
+
+// modifyColumn returns the commands for modifying a column. The returned post
+// commands remove column properties (e.g. a default value), and are executed
+// separately, as ClickHouse does not allow multiple commands on the same column.
+func (s *state) modifyColumn(change *schema.ModifyColumn) (cmds, post []*alterCmd, err error) {
+	from, to := change.From, change.To
+	// Changing only the column comment does not rewrite the column.
+	if change.Change == schema.ChangeComment {
+		var c1, c2 schema.Comment
+		sqlx.Has(from.Attrs, &c1)
+		sqlx.Has(to.Attrs, &c2)
+		return []*alterCmd{{
+			cmd:     fmt.Sprintf("COMMENT COLUMN %s %s", s.ident(to.Name), quote(c2.Text)),
+			reverse: fmt.Sprintf("COMMENT COLUMN %s %s", s.ident(from.Name), quote(c1.Text)),
+		}}, nil, nil
+	}
+	fromDef, err := s.columnDef(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	toDef, err := s.columnDef(to)
+	if err != nil {
+		return nil, nil, err
+	}
+	cmds = append(cmds, &alterCmd{cmd: "MODIFY COLUMN " + toDef, reverse: "MODIFY COLUMN " + fromDef})
+	var (
+		x1, x2       schema.GeneratedExpr
+		fromX, hasX2 = sqlx.Has(from.Attrs, &x1), sqlx.Has(to.Attrs, &x2)
+	)
+	for _, p := range []struct {
+		property string
+		removed  bool
+	}{
+		{"DEFAULT", from.Default != nil && to.Default == nil},
+		{generatedType(x1.Type), fromX && !hasX2},
+		{"CODEC", codec(from.Attrs) != "" && codec(to.Attrs) == ""},
+	} {
+		if p.removed {
+			post = append(post, &alterCmd{
+				cmd:     fmt.Sprintf("MODIFY COLUMN %s REMOVE %s", s.ident(to.Name), p.property),
+				reverse: "MODIFY COLUMN " + fromDef,
+			})
+		}
+	}
+	return cmds, post, nil
+}
+
+// tableAttr returns the command for changing a table attribute.
+func (s *state) tableAttr(change schema.Change) (*alterCmd, error) {
+	switch change := change.(type) {
+	case *schema.AddAttr:
+		switch a := change.A.(type) {
+		case *schema.Comment:
+			return &alterCmd{cmd: "MODIFY COMMENT " + quote(a.Text), reverse: "MODIFY COMMENT ''"}, nil
+		// Sorting keys cannot be dropped, and therefore, adding one is not reversible.
+		case *OrderBy:
+			return &alterCmd{cmd: "MODIFY ORDER BY " + sortKey(a.X)}, nil
+		case *SampleBy:
+			return &alterCmd{cmd: "MODIFY SAMPLE BY " + a.X, reverse: "REMOVE SAMPLE BY"}, nil
+		case *schema.Retention:
+			return &alterCmd{cmd: "MODIFY TTL " + s.ttl(a), reverse: "REMOVE TTL"}, nil
+		}
+	case *schema.ModifyAttr:
+		switch to := change.To.(type) {
+		case *schema.Comment:
+			from, ok := change.From.(*schema.Comment)
+			if !ok {
+				return nil, fmt.Errorf("mismatch ModifyAttr attributes: %T != %T", change.To, change.From)
+			}
+			return &alterCmd{cmd: "MODIFY COMMENT " + quote(to.Text), reverse: "MODIFY COMMENT " + quote(from.Text)}, nil
+		case *OrderBy:
+			from, ok := change.From.(*OrderBy)
+			if !ok {
+				return nil, fmt.Errorf("mismatch ModifyAttr attributes: %T != %T", change.To, change.From)
+			}
+			return &alterCmd{cmd: "MODIFY ORDER BY " + sortKey(to.X), reverse: "MODIFY ORDER BY " + sortKey(from.X)}, nil
+		case *SampleBy:
+			from, ok := change.From.(*SampleBy)
+			if !ok {
+				return nil, fmt.Errorf("mismatch ModifyAttr attributes: %T != %T", change.To, change.From)
+			}
+			return &alterCmd{cmd: "MODIFY SAMPLE BY " + to.X, reverse: "MODIFY SAMPLE BY " + from.X}, nil
+		case *schema.Retention:
+			from, ok := change.From.(*schema.Retention)
+			if !ok {
+				return nil, fmt.Errorf("mismatch ModifyAttr attributes: %T != %T", change.To, change.From)
+			}
+			return &alterCmd{cmd: "MODIFY TTL " + s.ttl(to), reverse: "MODIFY TTL " + s.ttl(from)}, nil
+		}
+	case *schema.DropAttr:
+		switch a := change.A.(type) {
+		case *SampleBy:
+			return &alterCmd{cmd: "REMOVE SAMPLE BY", reverse: "MODIFY SAMPLE BY " + a.X}, nil
+		case *schema.Retention:
+			return &alterCmd{cmd: "REMOVE TTL", reverse: "MODIFY TTL " + s.ttl(a)}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported table attribute change %T", change)
+}
+
+// addIndex returns the command for adding a data skipping index, and its reverse.
+func (s *state) addIndex(idx *schema.Index) (*alterCmd, error) {
+	b := s.Build("ADD")
+	if err := s.index(b, idx); err != nil {
+		return nil, err
+	}
+	return &alterCmd{cmd: b.String(), reverse: "DROP INDEX " + s.ident(idx.Name)}, nil
+}
+
+// renameTable builds and appends the migration change for renaming a table.
+func (s *state) renameTable(c *schema.RenameTable) {
+	s.append(&migrate.Change{
+		Source:  c,
+		Comment: fmt.Sprintf("rename a table from %q to %q", c.From.Name, c.To.Name),
+		Cmd:     s.onCluster(s.Build("RENAME TABLE").Table(c.From).P("TO").Table(c.To)).String(),
+		Reverse: s.onCluster(s.Build("RENAME TABLE").Table(c.To).P("TO").Table(c.From)).String(),
+	})
+}
+
+// views plans the given view changes. Materialized views cannot be replaced,
+// and therefore, they are modified by dropping and recreating them.
+func (s *state) views(changes []schema.Change) error {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddView:
+			create, err := s.createView(c.V, false)
+			if err != nil {
+				return err
+			}
+			s.append(&migrate.Change{
+				Cmd:     create,
+				Source:  c,
+				Reverse: s.dropView(c.V),
+				Comment: fmt.Sprintf("create %q view", c.V.Name),
+			})
+		case *schema.DropView:
+			create, err := s.createView(c.V, false)
+			if err != nil {
+				return err
+			}
+			s.append(&migrate.Change{
+				Cmd:     s.dropView(c.V),
+				Source:  c,
+				Reverse: create,
+				Comment: fmt.Sprintf("drop %q view", c.V.Name),
+			})
+		case *schema.ModifyView:
+			if !sqlx.Has(c.From.Attrs, &MaterializedView{}) && !sqlx.Has(c.To.Attrs, &MaterializedView{}) {
+				to, err := s.createView(c.To, true)
+				if err != nil {
+					return err
+				}
+				from, err := s.createView(c.From, true)
+				if err != nil {
+					return err
+				}
+				s.append(&migrate.Change{
+					Cmd:     to,
+					Source:  c,
+					Reverse: from,
+					Comment: fmt.Sprintf("modify %q view", c.To.Name),
+				})
+				continue
+			}
+			if err := s.views([]schema.Change{&schema.DropView{V: c.From}, &schema.AddView{V: c.To}}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createView returns the CREATE statement of the given view.
+func (s *state) createView(v *schema.View, replace bool) (string, error) {
+	var (
+		mv MaterializedView
+		b  = s.Build("CREATE")
+	)
+	if replace {
+		b.P("OR REPLACE")
+	}
+	if !sqlx.Has(v.Attrs, &mv) {
+		return s.onCluster(b.P("VIEW").View(v)).P("AS", v.Def).String(), nil
+	}
+	s.onCluster(b.P("MATERIALIZED VIEW").View(v))
+	switch {
+	case mv.To != "":
+		b.P("TO")
+		// Unqualified targets are resolved in the schema of the view.
+		if db, name, ok := strings.Cut(unquote(mv.To), "."); ok {
+			b.Table(&schema.Table{Name: name, Schema: schema.New(db)})
+		} else {
+			b.Table(&schema.Table{Name: db, Schema: v.Schema})
+		}
+	// The engine of the inner table defaults to MergeTree.
+	case sqlx.Has(v.Attrs, &Engine{}), sqlx.Has(v.Attrs, &OrderBy{}):
+		s.engine(b, v.Attrs, nil)
+	default:
+		return "", fmt.Errorf("materialized view %q must define either its target table or its engine", v.Name)
+	}
+	return b.P("AS", v.Def).String(), nil
+}
+
+// sortKey returns the given key expression wrapped with parentheses,
+// in case it is a tuple that is not already wrapped, e.g. "a, b".
+func sortKey(x string) string {
+	if x = strings.TrimSpace(x); !wrapped(x) && len(splitArgs(x)) > 1 {
+		return "(" + x + ")"
+	}
+	return x
+}
+
+// dropView returns the DROP statement of the given view.
+func (s *state) dropView(v *schema.View) string {
+	return s.onCluster(s.Build("DROP VIEW").View(v)).String()
+}
+
+// column writes the column definition to the builder.
+func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
+	typ, err := FormatType(c.Type.Type)
+	if err != nil {
+		return fmt.Errorf("format type for column %q: %w", c.Name, err)
+	}
+	if c.Type.Null {
+		typ = nullable(typ)
+	}
+	b.Ident(c.Name).P(typ)
+	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
+		b.P(generatedType(x.Type), x.Expr)
+	} else if c.Default != nil {
+		columnDefault(b, c)
+	}
+	if x := (schema.Comment{}); sqlx.Has(c.Attrs, &x) && x.Text != "" {
+		b.P("COMMENT", quote(x.Text))
+	}
+	if x := codec(c.Attrs); x != "" {
+		b.P("CODEC(" + x + ")")
+	}
+	return nil
+}
+
+// columnDef returns the definition of the given column.
+func (s *state) columnDef(c *schema.Column) (string, error) {
+	b := s.Build()
+	if err := s.column(b, c); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// index writes the data skipping index definition to the builder.
+func (s *state) index(b *sqlx.Builder, idx *schema.Index) error {
+	switch {
+	case idx.Unique:
+		return fmt.Errorf("unique index %q is not supported by ClickHouse", idx.Name)
+	case idx.Name == "":
+		return fmt.Errorf("data skipping indexes must be named")
+	case len(idx.Parts) == 0:
+		return fmt.Errorf("index %q has no parts", idx.Name)
+	}
+	parts := make([]string, 0, len(idx.Parts))
+	for _, p := range idx.Parts {
+		switch {
+		case p.C != nil:
+			parts = append(parts, s.ident(p.C.Name))
+		case p.X != nil:
+			x, ok := p.X.(*schema.RawExpr)
+			if !ok {
+				return fmt.Errorf("unexpected expression %T for index %q", p.X, idx.Name)
+			}
+			parts = append(parts, x.X)
+		}
+	}
+	x := parts[0]
+	if len(parts) > 1 {
+		x = "(" + strings.Join(parts, ", ") + ")"
+	}
+	b.P("INDEX").Ident(idx.Name).P(x, "TYPE", indexType(idx.Attrs), "GRANULARITY", fmt.Sprint(granularity(idx.Attrs)))
+	return nil
+}
+
+// onCluster writes the ON CLUSTER clause to the builder, if a cluster was
+// set. Cluster names that use macros, e.g. "{cluster}", are quoted as string
+// literals, and the rest are quoted as identifiers.
+func (s *state) onCluster(b *sqlx.Builder) *sqlx.Builder {
+	switch {
+	case s.Cluster == "":
+	case strings.ContainsAny(s.Cluster, "{}"):
+		b.P("ON CLUSTER", quote(s.Cluster))
+	default:
+		b.P("ON CLUSTER").Ident(s.Cluster)
+	}
+	return b
+}
+
+// ident returns the given name quoted as an identifier.
+func (s *state) ident(name string) string {
+	return s.Build().Ident(name).String()
+}
+
+func (s *state) append(c *migrate.Change) {
+	s.Changes = append(s.Changes, c)
+}
+
+// Build instantiates a new builder and writes the given phrase to it.
+func (s *state) Build(phrases ...string) *sqlx.Builder {
+	b := &sqlx.Builder{QuoteChar: '`', Schema: s.SchemaQualifier}
+	return b.P(phrases...)
+}
+
+// checkTable reports an error if the table uses features that are not supported.
+func checkTable(t *schema.Table) error {
+	if len(t.ForeignKeys) > 0 {
+		return fmt.Errorf("create table %q: foreign keys are not supported by ClickHouse", t.Name)
+	}
+	for _, a := range t.Attrs {
+		if _, ok := a.(*schema.Check); ok {
+			return fmt.Errorf("create table %q: check constraints are not supported", t.Name)
+		}
+	}
+	return nil
+}
+
+// columnDefault writes the default value of column to the builder.
+func columnDefault(b *sqlx.Builder, c *schema.Column) {
+	switch x := c.Default.(type) {
+	case *schema.Literal:
+		v := x.V
+		switch c.Type.Type.(type) {
+		case *schema.StringType, *schema.TimeType, *UUIDType, *IPType:
+			v = quote(v)
+		}
+		b.P("DEFAULT", v)
+	case *schema.RawExpr:
+		b.P("DEFAULT", x.X)
+	}
+}
+
+// quote returns the given string as a single-quoted literal, unless it is already quoted.
+func quote(s string) string {
+	if sqlx.IsQuoted(s, '\'') {
+		return s
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
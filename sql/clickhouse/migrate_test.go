@@ -0,0 +1,330 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package clickhouse
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges(t *testing.T) {
+	var (
+		def    = schema.New("default")
+		events = func() *schema.Table {
+			t := schema.NewTable("events").
+				SetSchema(def).
+				SetComment("user events").
+				AddColumns(
+					schema.NewIntColumn("id", TypeUInt64),
+					schema.NewTimeColumn("ts", TypeDateTime).AddAttrs(&Codec{V: "Delta, ZSTD(1)"}),
+					schema.NewNullStringColumn("name", TypeString).SetDefault(&schema.Literal{V: "unknown"}),
+					schema.NewTimeColumn("day", TypeDate).SetGeneratedExpr(&schema.GeneratedExpr{Expr: "toDate(ts)"}),
+				).
+				AddAttrs(&OrderBy{X: "(id, ts)"}, &PartitionBy{X: "toYYYYMM(ts)"})
+			t.AddIndexes(schema.NewIndex("idx_name").AddColumns(t.Columns[2]).AddAttrs(&IndexType{T: "bloom_filter"}, &IndexGranularity{V: 4}))
+			return t
+		}()
+		logs = schema.NewTable("logs").
+			SetSchema(def).
+			AddColumns(schema.NewStringColumn("msg", TypeString)).
+			AddAttrs(&Engine{V: "Log"})
+	)
+	tests := []struct {
+		changes []schema.Change
+		options []migrate.PlanOption
+		wantErr bool
+		plan    *migrate.Plan
+	}{
+		{
+			changes: []schema.Change{&schema.AddSchema{S: schema.New("analytics"), Extra: []schema.Clause{&schema.IfNotExists{}}}},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "CREATE DATABASE IF NOT EXISTS `analytics`", Reverse: "DROP DATABASE `analytics`"}},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.DropSchema{S: schema.New("analytics")}},
+			plan: &migrate.Plan{
+				Changes: []*migrate.Change{{Cmd: "DROP DATABASE `analytics`"}},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: events}},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "CREATE TABLE `default`.`events` (`id` UInt64, `ts` DateTime CODEC(Delta, ZSTD(1)), `name` Nullable(String) DEFAULT 'unknown', `day` Date MATERIALIZED toDate(ts), INDEX `idx_name` `name` TYPE bloom_filter GRANULARITY 4) ENGINE = MergeTree ORDER BY (id, ts) PARTITION BY toYYYYMM(ts) COMMENT 'user events'",
+						Reverse: "DROP TABLE `default`.`events`",
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: logs}},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE TABLE `default`.`logs` (`msg` String) ENGINE = Log", Reverse: "DROP TABLE `default`.`logs`"},
+				},
+			},
+		},
+		// Tables without a sorting key are sorted by their primary key, or by nothing.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{T: func() *schema.Table {
+					t := schema.NewTable("t").SetSchema(def).AddColumns(schema.NewIntColumn("a", TypeInt32), schema.NewIntColumn("b", TypeInt32))
+					t.SetPrimaryKey(schema.NewPrimaryKey(t.Columns...))
+					return t
+				}()},
+				&schema.AddTable{T: schema.NewTable("u").SetSchema(def).AddColumns(schema.NewIntColumn("a", TypeInt32))},
+			},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE TABLE `default`.`t` (`a` Int32, `b` Int32) ENGINE = MergeTree ORDER BY (a, b)", Reverse: "DROP TABLE `default`.`t`"},
+					{Cmd: "CREATE TABLE `default`.`u` (`a` Int32) ENGINE = MergeTree ORDER BY tuple()", Reverse: "DROP TABLE `default`.`u`"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: logs}},
+			options: []migrate.PlanOption{withCluster("main")},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE TABLE `default`.`logs` ON CLUSTER `main` (`msg` String) ENGINE = Log", Reverse: "DROP TABLE `default`.`logs` ON CLUSTER `main`"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddSchema{S: schema.New("analytics")}},
+			options: []migrate.PlanOption{withCluster("{cluster}")},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "CREATE DATABASE `analytics` ON CLUSTER '{cluster}'", Reverse: "DROP DATABASE `analytics` ON CLUSTER '{cluster}'"}},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.RenameTable{From: logs, To: schema.NewTable("messages").SetSchema(def)}},
+			options: []migrate.PlanOption{withCluster("main")},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "RENAME TABLE `default`.`logs` TO `default`.`messages` ON CLUSTER `main`", Reverse: "RENAME TABLE `default`.`messages` TO `default`.`logs` ON CLUSTER `main`"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: events,
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewNullStringColumn("country", TypeFixedString, schema.StringSize(2))},
+						&schema.DropColumn{C: events.Columns[3]},
+						&schema.RenameColumn{From: events.Columns[2], To: schema.NewColumn("title")},
+						&schema.AddIndex{I: schema.NewIndex("idx_ts").AddExprs(&schema.RawExpr{X: "toHour(ts)"}).AddAttrs(&IndexType{T: "set(24)"})},
+						&schema.ModifyAttr{From: &OrderBy{X: "(id, ts)"}, To: &OrderBy{X: "(id, ts, country)"}},
+						&schema.AddAttr{A: &SampleBy{X: "id"}},
+					},
+				},
+			},
+			options: []migrate.PlanOption{withCluster("main")},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `default`.`events` ON CLUSTER `main` ADD COLUMN `country` Nullable(FixedString(2)), DROP COLUMN `day`, RENAME COLUMN `name` TO `title`, ADD INDEX `idx_ts` toHour(ts) TYPE set(24) GRANULARITY 1, MODIFY ORDER BY (id, ts, country), MODIFY SAMPLE BY id",
+						Reverse: "ALTER TABLE `default`.`events` ON CLUSTER `main` REMOVE SAMPLE BY, MODIFY ORDER BY (id, ts), DROP INDEX `idx_ts`, RENAME COLUMN `title` TO `name`, ADD COLUMN `day` Date MATERIALIZED toDate(ts), DROP COLUMN `country`",
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("hits").
+						SetSchema(def).
+						AddColumns(schema.NewTimeColumn("ts", TypeDateTime)).
+						AddAttrs(&OrderBy{X: "ts"}, &schema.Retention{C: schema.NewTimeColumn("ts", TypeDateTime), Period: 30, Unit: "DAY"}),
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE TABLE `default`.`hits` (`ts` DateTime) ENGINE = MergeTree ORDER BY ts TTL `ts` + INTERVAL 30 DAY", Reverse: "DROP TABLE `default`.`hits`"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: events,
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &schema.Retention{C: events.Columns[1], Period: 30, Unit: "DAY"},
+							To:   &schema.Retention{C: events.Columns[1], Period: 1, Unit: "month"},
+						},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "ALTER TABLE `default`.`events` MODIFY TTL `ts` + INTERVAL 1 MONTH", Reverse: "ALTER TABLE `default`.`events` MODIFY TTL `ts` + INTERVAL 30 DAY"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T:       events,
+					Changes: []schema.Change{&schema.DropAttr{A: &schema.Retention{C: events.Columns[1], Period: 30, Unit: "DAY"}}},
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "ALTER TABLE `default`.`events` REMOVE TTL", Reverse: "ALTER TABLE `default`.`events` MODIFY TTL `ts` + INTERVAL 30 DAY"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: events,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   events.Columns[2],
+							To:     schema.NewNullStringColumn("name", TypeString).SetComment("event name"),
+							Change: schema.ChangeComment,
+						},
+						&schema.ModifyColumn{
+							From:   events.Columns[1],
+							To:     schema.NewTimeColumn("ts", TypeDateTime64, schema.TimePrecision(6)),
+							Change: schema.ChangeType | schema.ChangeAttr,
+						},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `default`.`events` COMMENT COLUMN `name` 'event name', MODIFY COLUMN `ts` DateTime64(6)",
+						Reverse: "ALTER TABLE `default`.`events` MODIFY COLUMN `ts` DateTime CODEC(Delta, ZSTD(1)), COMMENT COLUMN `name` ''",
+					},
+					{
+						Cmd:     "ALTER TABLE `default`.`events` MODIFY COLUMN `ts` REMOVE CODEC",
+						Reverse: "ALTER TABLE `default`.`events` MODIFY COLUMN `ts` DateTime CODEC(Delta, ZSTD(1))",
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddView{V: schema.NewView("daily", "SELECT toDate(ts) AS day, count() AS c FROM events GROUP BY day").SetSchema(def).AddAttrs(&MaterializedView{To: "totals"})},
+				&schema.AddView{V: schema.NewView("hourly", "SELECT toStartOfHour(ts) AS h FROM events").SetSchema(def).AddAttrs(&MaterializedView{}, &OrderBy{X: "h"})},
+				&schema.AddView{V: schema.NewView("names", "SELECT name FROM events").SetSchema(def)},
+			},
+			options: []migrate.PlanOption{withCluster("main")},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE MATERIALIZED VIEW `default`.`daily` ON CLUSTER `main` TO `default`.`totals` AS SELECT toDate(ts) AS day, count() AS c FROM events GROUP BY day", Reverse: "DROP VIEW `default`.`daily` ON CLUSTER `main`"},
+					{Cmd: "CREATE MATERIALIZED VIEW `default`.`hourly` ON CLUSTER `main` ENGINE = MergeTree ORDER BY h AS SELECT toStartOfHour(ts) AS h FROM events", Reverse: "DROP VIEW `default`.`hourly` ON CLUSTER `main`"},
+					{Cmd: "CREATE VIEW `default`.`names` ON CLUSTER `main` AS SELECT name FROM events", Reverse: "DROP VIEW `default`.`names` ON CLUSTER `main`"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyView{
+					From: schema.NewView("names", "SELECT name FROM events").SetSchema(def),
+					To:   schema.NewView("names", "SELECT name, ts FROM events").SetSchema(def),
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE OR REPLACE VIEW `default`.`names` AS SELECT name, ts FROM events", Reverse: "CREATE OR REPLACE VIEW `default`.`names` AS SELECT name FROM events"},
+				},
+			},
+		},
+		// Changing the engine or the partition key requires recreating the table.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T:       events,
+					Changes: []schema.Change{&schema.DropAttr{A: &OrderBy{X: "(id, ts)"}}},
+				},
+			},
+			wantErr: true,
+		},
+		// Foreign keys are not supported.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{T: schema.NewTable("pets").SetSchema(def).AddColumns(schema.NewIntColumn("owner_id", TypeInt64)).
+					AddForeignKeys(schema.NewForeignKey("owner").AddColumns(schema.NewIntColumn("owner_id", TypeInt64)).SetRefTable(logs))},
+			},
+			wantErr: true,
+		},
+		// Custom schema qualifier.
+		{
+			changes: []schema.Change{&schema.AddTable{T: logs}},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
+			},
+			plan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE TABLE `logs` (`msg` String) ENGINE = Log", Reverse: "DROP TABLE `logs`"},
+				},
+			},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			db, mk, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{mk}.version("23.8.1.1")
+			drv, err := Open(db)
+			require.NoError(t, err)
+			plan, err := drv.PlanChanges(context.Background(), "plan", tt.changes, tt.options...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.plan.Reversible, plan.Reversible)
+			require.Equal(t, tt.plan.Transactional, plan.Transactional)
+			require.Len(t, plan.Changes, len(tt.plan.Changes))
+			for i, c := range plan.Changes {
+				require.Equal(t, tt.plan.Changes[i].Cmd, c.Cmd)
+				require.Equal(t, tt.plan.Changes[i].Reverse, c.Reverse)
+			}
+		})
+	}
+}
+
+func TestDefaultPlan(t *testing.T) {
+	changes, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: schema.NewTable("t").AddColumns(schema.NewIntColumn("a", TypeInt32))},
+	})
+	require.NoError(t, err)
+	require.Len(t, changes.Changes, 1)
+	require.Equal(t, "CREATE TABLE `t` (`a` Int32) ENGINE = MergeTree ORDER BY tuple()", changes.Changes[0].Cmd)
+}
+
+func withCluster(name string) migrate.PlanOption {
+	return func(o *migrate.PlanOptions) { o.Cluster = name }
+}
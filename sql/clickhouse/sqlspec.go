@@ -0,0 +1,366 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package clickhouse
+
+import (
+	"fmt"
+	"reflect"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/specutil"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlspec"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+type doc struct {
+	Tables    []*sqlspec.Table     `spec:"table"`
+	Views     []*sqlspec.View      `spec:"view"`
+	Schemas   []*sqlspec.Schema    `spec:"schema"`
+	Unmanaged []*sqlspec.Unmanaged `spec:"unmanaged"`
+}
+
+// evalSpec evaluates an Atlas DDL document into v using the input.
+func evalSpec(p *hclparse.Parser, v any, input map[string]string) error {
+	var d doc
+	if err := hclState.Eval(p, &d, input); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *schema.Realm:
+		if err := specutil.Scan(v, d.Schemas, d.Tables, convertTable); err != nil {
+			return fmt.Errorf("specutil: failed converting to *schema.Realm: %w", err)
+		}
+		if err := convertViews(v, d.Views); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(v, d.Unmanaged); err != nil {
+			return err
+		}
+	case *schema.Schema:
+		if len(d.Schemas) != 1 {
+			return fmt.Errorf("specutil: expecting document to contain a single schema, got %d", len(d.Schemas))
+		}
+		var r schema.Realm
+		if err := specutil.Scan(&r, d.Schemas, d.Tables, convertTable); err != nil {
+			return err
+		}
+		if err := convertViews(&r, d.Views); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(&r, d.Unmanaged); err != nil {
+			return err
+		}
+		r.Schemas[0].Realm = nil
+		*v = *r.Schemas[0]
+	default:
+		return fmt.Errorf("specutil: failed unmarshaling spec. %T is not supported", v)
+	}
+	return nil
+}
+
+// MarshalSpec marshals v into an Atlas DDL document using a schemahcl.Marshaler.
+func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
+	var d doc
+	switch s := v.(type) {
+	case *schema.Schema:
+		spec, tables, err := schemaSpec(s)
+		if err != nil {
+			return nil, fmt.Errorf("specutil: failed converting schema to spec: %w", err)
+		}
+		d.Tables = tables
+		d.Schemas = []*sqlspec.Schema{spec}
+		d.Views = viewsSpec(s)
+		d.Unmanaged = specutil.FromUnmanaged(s)
+	case *schema.Realm:
+		for _, s := range s.Schemas {
+			spec, tables, err := schemaSpec(s)
+			if err != nil {
+				return nil, fmt.Errorf("specutil: failed converting schema to spec: %w", err)
+			}
+			d.Tables = append(d.Tables, tables...)
+			d.Schemas = append(d.Schemas, spec)
+			d.Views = append(d.Views, viewsSpec(s)...)
+			d.Unmanaged = append(d.Unmanaged, specutil.FromUnmanaged(s)...)
+		}
+		if err := specutil.QualifyDuplicates(d.Tables); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("specutil: failed marshaling spec. %T is not supported", v)
+	}
+	return marshaler.MarshalSpec(&d)
+}
+
+// convertTable converts a sqlspec.Table to a schema.Table.
+func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, error) {
+	t, err := specutil.Table(spec, parent, convertColumn, specutil.PrimaryKey, convertIndex, specutil.Check)
+	if err != nil {
+		return nil, err
+	}
+	if err := convertEngine(spec.Extra, &t.Attrs); err != nil {
+		return nil, err
+	}
+	if attr, ok := spec.Attr("sample_by"); ok {
+		x, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		t.AddAttrs(&SampleBy{X: x})
+	}
+	return t, nil
+}
+
+// convertEngine converts the engine attributes of a table or a materialized view.
+func convertEngine(spec schemahcl.Resource, attrs *[]schema.Attr) error {
+	for _, a := range []struct {
+		name string
+		attr func(string) schema.Attr
+	}{
+		{"engine", func(v string) schema.Attr { return &Engine{V: v} }},
+		{"order_by", func(x string) schema.Attr { return &OrderBy{X: x} }},
+		{"partition_by", func(x string) schema.Attr { return &PartitionBy{X: x} }},
+	} {
+		attr, ok := spec.Attr(a.name)
+		if !ok {
+			continue
+		}
+		v, err := attr.String()
+		if err != nil {
+			return err
+		}
+		*attrs = append(*attrs, a.attr(v))
+	}
+	return nil
+}
+
+// convertIndex converts a sqlspec.Index into a schema.Index.
+func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
+	idx, err := specutil.Index(spec, t)
+	if err != nil {
+		return nil, err
+	}
+	if attr, ok := spec.Attr("type"); ok {
+		v, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		idx.AddAttrs(&IndexType{T: v})
+	}
+	if attr, ok := spec.Attr("granularity"); ok {
+		v, err := attr.Int()
+		if err != nil {
+			return nil, err
+		}
+		idx.AddAttrs(&IndexGranularity{V: v})
+	}
+	return idx, nil
+}
+
+// convertColumn converts a sqlspec.Column into a schema.Column.
+func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error) {
+	c, err := specutil.Column(spec, convertColumnType)
+	if err != nil {
+		return nil, err
+	}
+	if attr, ok := spec.Attr("codec"); ok {
+		v, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		c.Attrs = append(c.Attrs, &Codec{V: v})
+	}
+	if err := specutil.ConvertGenExpr(spec.Remain(), c, generatedType); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// convertColumnType converts a sqlspec.Column into a concrete ClickHouse schema.Type.
+func convertColumnType(spec *sqlspec.Column) (schema.Type, error) {
+	return TypeRegistry.Type(spec.Type, spec.Extra.Attrs)
+}
+
+// convertViews converts the view specs, and adds them to their schemas.
+// Materialized views are marked with the "materialized" attribute.
+func convertViews(r *schema.Realm, specs []*sqlspec.View) error {
+	if err := specutil.Views(r, specs); err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		attr, ok := spec.Attr("materialized")
+		if !ok {
+			continue
+		}
+		if m, err := attr.Bool(); err != nil || !m {
+			return fmt.Errorf("view %q: materialized attribute must be true if set", spec.Name)
+		}
+		name, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return err
+		}
+		s, _ := r.Schema(name)
+		v, _ := s.View(spec.Name)
+		mv := &MaterializedView{}
+		if attr, ok := spec.Attr("to"); ok {
+			if mv.To, err = attr.String(); err != nil {
+				return err
+			}
+		}
+		v.AddAttrs(mv)
+		if err := convertEngine(spec.Extra, &v.Attrs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaSpec converts from a concrete ClickHouse schema to Atlas specification.
+func schemaSpec(s *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error) {
+	return specutil.FromSchema(s, tableSpec)
+}
+
+// tableSpec converts from a concrete ClickHouse sqlspec.Table to a schema.Table.
+func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
+	spec, err := specutil.FromTable(
+		t,
+		columnSpec,
+		specutil.FromPrimaryKey,
+		indexSpec,
+		specutil.FromForeignKey,
+		specutil.FromCheck,
+	)
+	if err != nil {
+		return nil, err
+	}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, engineSpec(t.Attrs)...)
+	if x := (SampleBy{}); sqlx.Has(t.Attrs, &x) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.StrAttr("sample_by", x.X))
+	}
+	return spec, nil
+}
+
+// engineSpec returns the spec attributes of the engine of a table or a materialized view.
+func engineSpec(attrs []schema.Attr) []*schemahcl.Attr {
+	var specs []*schemahcl.Attr
+	if e := (Engine{}); sqlx.Has(attrs, &e) {
+		specs = append(specs, specutil.StrAttr("engine", e.V))
+	}
+	if o := (OrderBy{}); sqlx.Has(attrs, &o) {
+		specs = append(specs, specutil.StrAttr("order_by", o.X))
+	}
+	if p := (PartitionBy{}); sqlx.Has(attrs, &p) {
+		specs = append(specs, specutil.StrAttr("partition_by", p.X))
+	}
+	return specs
+}
+
+func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
+	spec, err := specutil.FromIndex(idx)
+	if err != nil {
+		return nil, err
+	}
+	if t := (IndexType{}); sqlx.Has(idx.Attrs, &t) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.StrAttr("type", t.T))
+	}
+	// Avoid printing the granularity if it is the default.
+	if g := granularity(idx.Attrs); g != 1 {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.IntAttr("granularity", g))
+	}
+	return spec, nil
+}
+
+// columnSpec converts from a concrete ClickHouse schema.Column into a sqlspec.Column.
+func columnSpec(c *schema.Column, _ *schema.Table) (*sqlspec.Column, error) {
+	s, err := specutil.FromColumn(c, columnTypeSpec)
+	if err != nil {
+		return nil, err
+	}
+	if x := codec(c.Attrs); x != "" {
+		s.Extra.Attrs = append(s.Extra.Attrs, specutil.StrAttr("codec", x))
+	}
+	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
+		s.Extra.Children = append(s.Extra.Children, specutil.FromGenExpr(x, generatedType))
+	}
+	return s, nil
+}
+
+// columnTypeSpec converts from a concrete ClickHouse schema.Type into sqlspec.Column Type.
+func columnTypeSpec(t schema.Type) (*sqlspec.Column, error) {
+	st, err := TypeRegistry.Convert(t)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlspec.Column{Type: st}, nil
+}
+
+// viewsSpec converts the views of a schema to specs.
+func viewsSpec(s *schema.Schema) []*sqlspec.View {
+	specs := specutil.FromViews(s)
+	for i, v := range s.Views {
+		if mv := (MaterializedView{}); sqlx.Has(v.Attrs, &mv) {
+			specs[i].Extra.Attrs = append(specs[i].Extra.Attrs, specutil.BoolAttr("materialized", true))
+			if mv.To != "" {
+				specs[i].Extra.Attrs = append(specs[i].Extra.Attrs, specutil.StrAttr("to", mv.To))
+			}
+			specs[i].Extra.Attrs = append(specs[i].Extra.Attrs, engineSpec(v.Attrs)...)
+		}
+	}
+	return specs
+}
+
+// TypeRegistry contains the supported TypeSpecs for the clickhouse driver.
+var TypeRegistry = schemahcl.NewRegistry(
+	schemahcl.WithFormatter(FormatType),
+	schemahcl.WithParser(ParseType),
+	schemahcl.WithSpecs(
+		schemahcl.NewTypeSpec(TypeBool),
+		schemahcl.NewTypeSpec(TypeInt8),
+		schemahcl.NewTypeSpec(TypeInt16),
+		schemahcl.NewTypeSpec(TypeInt32),
+		schemahcl.NewTypeSpec(TypeInt64),
+		schemahcl.NewTypeSpec(TypeInt128),
+		schemahcl.NewTypeSpec(TypeInt256),
+		schemahcl.NewTypeSpec(TypeUInt8),
+		schemahcl.NewTypeSpec(TypeUInt16),
+		schemahcl.NewTypeSpec(TypeUInt32),
+		schemahcl.NewTypeSpec(TypeUInt64),
+		schemahcl.NewTypeSpec(TypeUInt128),
+		schemahcl.NewTypeSpec(TypeUInt256),
+		schemahcl.NewTypeSpec(TypeFloat32),
+		schemahcl.NewTypeSpec(TypeFloat64),
+		schemahcl.NewTypeSpec(TypeDecimal, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
+		schemahcl.NewTypeSpec(TypeString),
+		schemahcl.NewTypeSpec(TypeFixedString, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(true))),
+		schemahcl.NewTypeSpec(TypeUUID),
+		schemahcl.NewTypeSpec(TypeDate),
+		schemahcl.NewTypeSpec(TypeDate32),
+		schemahcl.NewTypeSpec(TypeDateTime),
+		schemahcl.NewTypeSpec(TypeDateTime64, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
+		schemahcl.NewTypeSpec(TypeIPv4),
+		schemahcl.NewTypeSpec(TypeIPv6),
+		schemahcl.NewTypeSpec(TypeJSON),
+	),
+)
+
+var (
+	hclState = schemahcl.New(
+		schemahcl.WithTypes(TypeRegistry.Specs()),
+		schemahcl.WithScopedEnums("table.column.as.type", defaultKindMaterialized, defaultKindAlias),
+		schemahcl.WithScopedEnums("table.retention.unit", specutil.RetentionUnits...),
+	)
+	// MarshalHCL marshals v into an Atlas HCL DDL document.
+	MarshalHCL = schemahcl.MarshalerFunc(func(v any) ([]byte, error) {
+		return MarshalSpec(v, hclState)
+	})
+	// EvalHCL implements the schemahcl.Evaluator interface.
+	EvalHCL = schemahcl.EvalFunc(evalSpec)
+
+	// EvalHCLBytes is a helper that evaluates an HCL document from a byte slice instead
+	// of from an hclparse.Parser instance.
+	EvalHCLBytes = specutil.HCLBytesFunc(EvalHCL)
+)
@@ -0,0 +1,216 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package clickhouse
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLSpec(t *testing.T) {
+	f := `
+schema "default" {
+}
+
+table "events" {
+	schema = schema.default
+	column "id" {
+		type = UInt64
+	}
+	column "ts" {
+		type = DateTime64(6)
+		codec = "Delta, ZSTD(1)"
+	}
+	column "name" {
+		type = sql("LowCardinality(String)")
+		null = true
+	}
+	column "price" {
+		type = Decimal(18,2)
+		default = 0
+	}
+	column "day" {
+		type = Date
+		as {
+			expr = "toDate(ts)"
+			type = MATERIALIZED
+		}
+	}
+	primary_key {
+		columns = [column.id]
+	}
+	index "idx_name" {
+		columns = [column.name]
+		type = "bloom_filter"
+		granularity = 4
+	}
+	engine = "ReplacingMergeTree(ts)"
+	order_by = "(id, ts)"
+	partition_by = "toYYYYMM(ts)"
+	sample_by = "id"
+	retention {
+		column = column.ts
+		period = 90
+	}
+}
+
+view "daily" {
+	schema = schema.default
+	as = "SELECT day, count() AS c FROM events GROUP BY day"
+	materialized = true
+	to = "totals"
+}
+
+view "hourly" {
+	schema = schema.default
+	as = "SELECT toStartOfHour(ts) AS h FROM events"
+	materialized = true
+	engine = "MergeTree"
+	order_by = "h"
+}
+`
+	var s schema.Schema
+	err := EvalHCLBytes([]byte(f), &s, nil)
+	require.NoError(t, err)
+	events, ok := s.Table("events")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{
+		&schema.Retention{C: events.Columns[1], Period: 90, Unit: schema.RetentionDay},
+		&Engine{V: "ReplacingMergeTree(ts)"},
+		&OrderBy{X: "(id, ts)"},
+		&PartitionBy{X: "toYYYYMM(ts)"},
+		&SampleBy{X: "id"},
+	}, events.Attrs)
+	p := 6
+	require.Equal(t, &schema.IntegerType{T: TypeUInt64, Unsigned: true}, events.Columns[0].Type.Type)
+	require.Equal(t, &schema.TimeType{T: TypeDateTime64, Precision: &p}, events.Columns[1].Type.Type)
+	require.Equal(t, []schema.Attr{&Codec{V: "Delta, ZSTD(1)"}}, events.Columns[1].Attrs)
+	require.Equal(t, &NativeType{T: "LowCardinality(String)"}, events.Columns[2].Type.Type)
+	require.Equal(t, &schema.DecimalType{T: TypeDecimal, Precision: 18, Scale: 2}, events.Columns[3].Type.Type)
+	require.Equal(t, []schema.Attr{&schema.GeneratedExpr{Expr: "toDate(ts)", Type: "MATERIALIZED"}}, events.Columns[4].Attrs)
+	require.Equal(t, []schema.Attr{&IndexType{T: "bloom_filter"}, &IndexGranularity{V: 4}}, events.Indexes[0].Attrs)
+	daily, ok := s.View("daily")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&MaterializedView{To: "totals"}}, daily.Attrs)
+	hourly, ok := s.View("hourly")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&MaterializedView{}, &Engine{V: "MergeTree"}, &OrderBy{X: "h"}}, hourly.Attrs)
+
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	require.EqualValues(t, s.Tables[0].Attrs, after.Tables[0].Attrs)
+	require.EqualValues(t, s.Tables[0].Indexes[0].Attrs, after.Tables[0].Indexes[0].Attrs)
+	for i, c := range s.Tables[0].Columns {
+		require.EqualValues(t, c.Type.Type, after.Tables[0].Columns[i].Type.Type)
+		require.EqualValues(t, c.Attrs, after.Tables[0].Columns[i].Attrs)
+	}
+	for i, v := range s.Views {
+		require.Equal(t, v.Def, after.Views[i].Def)
+		require.EqualValues(t, v.Attrs, after.Views[i].Attrs)
+	}
+}
+
+func TestMarshalSpec_MaterializedView(t *testing.T) {
+	s := schema.New("default").
+		AddTables(
+			schema.NewTable("totals").
+				AddColumns(schema.NewTimeColumn("day", TypeDate)).
+				AddAttrs(&Engine{V: "SummingMergeTree"}, &OrderBy{X: "day"}),
+		)
+	s.AddViews(schema.NewView("daily", "SELECT toDate(ts) AS day FROM events").AddAttrs(&MaterializedView{To: "totals"}))
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "totals" {
+  schema   = schema.default
+  engine   = "SummingMergeTree"
+  order_by = "day"
+  column "day" {
+    null = false
+    type = Date
+  }
+}
+view "daily" {
+  schema       = schema.default
+  as           = "SELECT toDate(ts) AS day FROM events"
+  materialized = true
+  to           = "totals"
+}
+schema "default" {
+}
+`, string(buf))
+}
+
+func TestTypes(t *testing.T) {
+	p := func(i int) *int { return &i }
+	for _, tt := range []struct {
+		typeExpr string
+		expected schema.Type
+	}{
+		{typeExpr: "Bool", expected: &schema.BoolType{T: TypeBool}},
+		{typeExpr: "Int8", expected: &schema.IntegerType{T: TypeInt8}},
+		{typeExpr: "UInt256", expected: &schema.IntegerType{T: TypeUInt256, Unsigned: true}},
+		{typeExpr: "Float64", expected: &schema.FloatType{T: TypeFloat64}},
+		{typeExpr: "Decimal(10,2)", expected: &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}},
+		{typeExpr: "String", expected: &schema.StringType{T: TypeString}},
+		{typeExpr: "FixedString(16)", expected: &schema.StringType{T: TypeFixedString, Size: 16}},
+		{typeExpr: "UUID", expected: &UUIDType{T: TypeUUID}},
+		{typeExpr: "Date32", expected: &schema.TimeType{T: TypeDate32}},
+		{typeExpr: "DateTime", expected: &schema.TimeType{T: TypeDateTime}},
+		{typeExpr: "DateTime64(3)", expected: &schema.TimeType{T: TypeDateTime64, Precision: p(3)}},
+		{typeExpr: "IPv6", expected: &IPType{T: TypeIPv6}},
+		{typeExpr: "JSON", expected: &schema.JSONType{T: TypeJSON}},
+		{typeExpr: `sql("Array(String)")`, expected: &NativeType{T: "Array(String)"}},
+		{typeExpr: `sql("Map(String, UInt64)")`, expected: &NativeType{T: "Map(String, UInt64)"}},
+	} {
+		t.Run(tt.typeExpr, func(t *testing.T) {
+			var test schema.Schema
+			doc := `table "test" {
+	schema = schema.default
+	column "c" {
+		type = ` + tt.typeExpr + `
+	}
+}
+schema "default" {
+}
+`
+			err := EvalHCLBytes([]byte(doc), &test, nil)
+			require.NoError(t, err)
+			colspec := test.Tables[0].Columns[0]
+			require.EqualValues(t, tt.expected, colspec.Type.Type)
+			spec, err := MarshalHCL(&test)
+			require.NoError(t, err)
+			var after schema.Schema
+			err = EvalHCLBytes(spec, &after, nil)
+			require.NoError(t, err)
+			require.EqualValues(t, tt.expected, after.Tables[0].Columns[0].Type.Type)
+		})
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for raw, want := range map[string]schema.Type{
+		"Decimal64(4)":                   &schema.DecimalType{T: TypeDecimal, Precision: 18, Scale: 4},
+		"DateTime('UTC')":                &NativeType{T: "DateTime('UTC')"},
+		"DateTime64(3, 'UTC')":           &NativeType{T: "DateTime64(3, 'UTC')"},
+		"Enum8('a' = 1, 'b, c' = 2)":     &NativeType{T: "Enum8('a' = 1, 'b, c' = 2)"},
+		"Tuple(a String, b UInt8)":       &NativeType{T: "Tuple(a String, b UInt8)"},
+		"LowCardinality(FixedString(2))": &NativeType{T: "LowCardinality(FixedString(2))"},
+	} {
+		got, err := ParseType(raw)
+		require.NoError(t, err, raw)
+		require.Equal(t, want, got, raw)
+	}
+	_, err := ParseType("Nullable(String)")
+	require.Error(t, err)
+	require.Equal(t, "LowCardinality(Nullable(String))", nullable("LowCardinality(String)"))
+	typ, null := nonNullable("LowCardinality(Nullable(String))")
+	require.True(t, null)
+	require.Equal(t, "LowCardinality(String)", typ)
+}
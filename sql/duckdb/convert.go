@@ -0,0 +1,175 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Default attributes of the DECIMAL type, when it is declared without a precision.
+const (
+	defaultDecimalPrecision = 18
+	defaultDecimalScale     = 3
+)
+
+// FormatType converts schema type to its column form in the database.
+// An error is returned if the type cannot be recognized.
+func FormatType(t schema.Type) (string, error) {
+	var f string
+	switch t := t.(type) {
+	case *schema.BoolType:
+		f = TypeBoolean
+	case *schema.BinaryType:
+		f = TypeBlob
+	case *schema.IntegerType:
+		switch f = strings.ToLower(t.T); f {
+		case TypeTinyInt, TypeSmallInt, TypeInteger, TypeBigInt, TypeHugeInt:
+			if t.Unsigned {
+				f = "u" + f
+			}
+		case TypeUTinyInt, TypeUSmallInt, TypeUInteger, TypeUBigInt, TypeUHugeInt:
+		default:
+			return "", fmt.Errorf("duckdb: unexpected integer type: %q", t.T)
+		}
+	case *schema.DecimalType:
+		switch f = strings.ToLower(t.T); f {
+		case TypeDecimal, "numeric":
+		default:
+			return "", fmt.Errorf("duckdb: unexpected decimal type: %q", t.T)
+		}
+		p, s := t.Precision, t.Scale
+		if p == 0 {
+			p, s = defaultDecimalPrecision, defaultDecimalScale
+		}
+		if p < 1 || p > 38 {
+			return "", fmt.Errorf("duckdb: decimal type must have precision between 1 and 38: %d", p)
+		}
+		if s < 0 || s > p {
+			return "", fmt.Errorf("duckdb: decimal type must have scale between 0 and its precision: %d", s)
+		}
+		f = fmt.Sprintf("%s(%d,%d)", TypeDecimal, p, s)
+	case *schema.FloatType:
+		switch f = strings.ToLower(t.T); f {
+		case TypeFloat, TypeDouble:
+		default:
+			return "", fmt.Errorf("duckdb: unexpected float type: %q", t.T)
+		}
+	case *schema.StringType:
+		// The length of VARCHAR columns is accepted
+		// by DuckDB for compatibility, but ignored.
+		f = TypeVarchar
+	case *schema.TimeType:
+		switch f = strings.ToLower(t.T); f {
+		case TypeDate, TypeTime, TypeTimestamp, TypeTimestampTZ:
+		default:
+			return "", fmt.Errorf("duckdb: unexpected time type: %q", t.T)
+		}
+	case *schema.JSONType:
+		f = TypeJSON
+	case *IntervalType:
+		f = TypeInterval
+	case *UUIDType:
+		f = TypeUUID
+	case *UserDefinedType:
+		f = t.T
+	case *schema.UnsupportedType:
+		return "", fmt.Errorf("duckdb: unsupported type: %q", t.T)
+	default:
+		return "", fmt.Errorf("duckdb: invalid schema type: %T", t)
+	}
+	return f, nil
+}
+
+// ParseType returns the schema.Type value represented by the given raw type.
+// The raw value is expected to follow the format of the CREATE TABLE statement,
+// or the one reported by the duckdb_columns() function, e.g. "DECIMAL(10,2)".
+func ParseType(raw string) (schema.Type, error) {
+	t, args, err := parseColumn(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case TypeBoolean, "bool", "logical":
+		return &schema.BoolType{T: TypeBoolean}, nil
+	case TypeTinyInt, "int1":
+		return &schema.IntegerType{T: TypeTinyInt}, nil
+	case TypeSmallInt, "int2", "short":
+		return &schema.IntegerType{T: TypeSmallInt}, nil
+	case TypeInteger, "int", "int4", "signed":
+		return &schema.IntegerType{T: TypeInteger}, nil
+	case TypeBigInt, "int8", "long":
+		return &schema.IntegerType{T: TypeBigInt}, nil
+	case TypeHugeInt, "int128":
+		return &schema.IntegerType{T: TypeHugeInt}, nil
+	case TypeUTinyInt, TypeUSmallInt, TypeUInteger, TypeUBigInt, TypeUHugeInt:
+		return &schema.IntegerType{T: t, Unsigned: true}, nil
+	case TypeFloat, "float4", "real":
+		return &schema.FloatType{T: TypeFloat, Precision: 24}, nil
+	case TypeDouble, "float8":
+		return &schema.FloatType{T: TypeDouble, Precision: 53}, nil
+	case TypeDecimal, "numeric":
+		dt := &schema.DecimalType{T: TypeDecimal, Precision: defaultDecimalPrecision, Scale: defaultDecimalScale}
+		if len(args) > 0 {
+			if dt.Precision, err = strconv.Atoi(args[0]); err != nil {
+				return nil, fmt.Errorf("duckdb: parse precision %q: %w", args[0], err)
+			}
+			dt.Scale = 0
+		}
+		if len(args) > 1 {
+			if dt.Scale, err = strconv.Atoi(args[1]); err != nil {
+				return nil, fmt.Errorf("duckdb: parse scale %q: %w", args[1], err)
+			}
+		}
+		return dt, nil
+	case TypeVarchar, "char", "bpchar", "text", "string":
+		return &schema.StringType{T: TypeVarchar}, nil
+	case TypeBlob, "bytea", "binary", "varbinary":
+		return &schema.BinaryType{T: TypeBlob}, nil
+	case TypeDate, TypeTime:
+		return &schema.TimeType{T: t}, nil
+	case TypeTimestamp, "datetime", "timestamp without time zone":
+		return &schema.TimeType{T: TypeTimestamp}, nil
+	case TypeTimestampTZ, "timestamp with time zone":
+		return &schema.TimeType{T: TypeTimestampTZ}, nil
+	case TypeInterval:
+		return &IntervalType{T: t}, nil
+	case TypeUUID:
+		return &UUIDType{T: t}, nil
+	case TypeJSON:
+		return &schema.JSONType{T: t}, nil
+	default:
+		// Nested types (e.g. "INTEGER[]", "STRUCT(a INTEGER)" or "MAP(VARCHAR, INTEGER)"),
+		// and user-defined types, such as enums, are kept as they were defined.
+		return &UserDefinedType{T: strings.TrimSpace(raw)}, nil
+	}
+}
+
+// parseColumn splits the raw type into its lowered name and arguments.
+func parseColumn(raw string) (string, []string, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if s == "" {
+		return "", nil, fmt.Errorf("duckdb: missing type")
+	}
+	// Nested types are returned as-is.
+	if strings.HasSuffix(s, "]") || strings.HasPrefix(s, "struct") || strings.HasPrefix(s, "map") || strings.HasPrefix(s, "union") {
+		return s, nil, nil
+	}
+	i := strings.IndexByte(s, '(')
+	if i == -1 {
+		return s, nil, nil
+	}
+	if !strings.HasSuffix(s, ")") {
+		return "", nil, fmt.Errorf("duckdb: malformed type %q", raw)
+	}
+	args := strings.Split(s[i+1:len(s)-1], ",")
+	for j := range args {
+		args[j] = strings.TrimSpace(args[j])
+	}
+	return strings.TrimSpace(s[:i]), args, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A diff provides a DuckDB implementation for sqlx.DiffDriver.
+type diff struct{ conn }
+
+// SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
+func (*diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
+	// Schemas in DuckDB have no attributes that can be altered.
+	return nil
+}
+
+// TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
+func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
+	var changes []schema.Change
+	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	return append(changes, sqlx.CheckDiff(from, to, func(c1, c2 *schema.Check) bool {
+		return normalizeExpr(c1.Expr) == normalizeExpr(c2.Expr)
+	})...), nil
+}
+
+// ColumnChange returns the schema changes (if any) for migrating one column to the other.
+func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column) (schema.ChangeKind, error) {
+	change := sqlx.CommentChange(from.Attrs, to.Attrs)
+	if from.Type.Null != to.Type.Null {
+		change |= schema.ChangeNull
+	}
+	changed, err := d.typeChanged(from, to)
+	if err != nil {
+		return schema.NoChange, err
+	}
+	if changed {
+		change |= schema.ChangeType
+	}
+	if d.defaultChanged(from, to) {
+		change |= schema.ChangeDefault
+	}
+	return change, nil
+}
+
+// typeChanged reports if the column type was changed.
+func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
+	fromT, toT := from.Type.Type, to.Type.Type
+	if fromT == nil || toT == nil {
+		return false, fmt.Errorf("duckdb: missing type information for column %q", from.Name)
+	}
+	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
+		return true, nil
+	}
+	ft, err := FormatType(fromT)
+	if err != nil {
+		return false, err
+	}
+	tt, err := FormatType(toT)
+	if err != nil {
+		return false, err
+	}
+	return !strings.EqualFold(ft, tt), nil
+}
+
+// defaultChanged reports if the default value of a column was changed.
+func (d *diff) defaultChanged(from, to *schema.Column) bool {
+	d1, ok1 := sqlx.DefaultValue(from)
+	d2, ok2 := sqlx.DefaultValue(to)
+	if ok1 != ok2 {
+		return true
+	}
+	if !ok1 || normalizeExpr(d1) == normalizeExpr(d2) {
+		return false
+	}
+	x1, err1 := sqlx.Unquote(d1)
+	x2, err2 := sqlx.Unquote(d2)
+	return err1 != nil || err2 != nil || x1 != x2
+}
+
+// IsGeneratedIndexName reports if the index name was generated by the database.
+// DuckDB ignores the names of constraints, and names UNIQUE constraints as
+// "<table>_<columns>_key".
+func (*diff) IsGeneratedIndexName(_ *schema.Table, idx *schema.Index) bool {
+	return sqlx.Has(idx.Attrs, &UniqueConstraint{})
+}
+
+// IndexAttrChanged reports if the index attributes were changed.
+func (*diff) IndexAttrChanged(_, _ []schema.Attr) bool {
+	// UNIQUE constraints and unique indexes are equivalent.
+	return false
+}
+
+// IndexPartAttrChanged reports if the index-part attributes were changed.
+func (*diff) IndexPartAttrChanged(_, _ *schema.IndexPart) bool {
+	return false
+}
+
+// ReferenceChanged reports if the foreign key referential action was changed.
+func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
+	// DuckDB supports only the "NO ACTION" and "RESTRICT" actions, which
+	// are equivalent, as constraints are checked when the statement ends.
+	norm := func(o schema.ReferenceOption) schema.ReferenceOption {
+		if o == "" || o == schema.Restrict {
+			return schema.NoAction
+		}
+		return o
+	}
+	return norm(from) != norm(to)
+}
+
+// normalizeExpr returns the normal form of the given expression for comparison,
+// as DuckDB stores the definitions of checks wrapped with parentheses, e.g. the
+// "price > 0" check is stored as "(price > 0)".
+func normalizeExpr(x string) string {
+	x = strings.TrimSpace(x)
+	for len(x) > 1 && x[0] == '(' && x[len(x)-1] == ')' && balanced(x[1:len(x)-1]) {
+		x = strings.TrimSpace(x[1 : len(x)-1])
+	}
+	return x
+}
+
+// balanced reports if the parentheses in the given expression are balanced.
+func balanced(x string) bool {
+	var depth int
+	for i := 0; i < len(x); i++ {
+		switch x[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
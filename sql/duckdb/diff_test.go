@@ -0,0 +1,118 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_TableDiff(t *testing.T) {
+	type testcase struct {
+		name        string
+		from, to    *schema.Table
+		wantChanges []schema.Change
+		wantErr     bool
+	}
+	tests := []testcase{
+		{
+			name: "no changes",
+			from: schema.NewTable("users").SetSchema(schema.New("main")),
+			to:   schema.NewTable("users"),
+		},
+		func() testcase {
+			var (
+				from = schema.NewTable("users").
+					AddColumns(
+						schema.NewIntColumn("id", TypeBigInt),
+						schema.NewDecimalColumn("price", TypeDecimal, schema.DecimalPrecision(10), schema.DecimalScale(2)).
+							SetDefault(&schema.Literal{V: "0"}),
+						schema.NewStringColumn("name", TypeVarchar).SetDefault(&schema.Literal{V: "'unknown'"}),
+					).
+					AddChecks(schema.NewCheck().SetName("ck_price").SetExpr("(price >= 0)"))
+				to = schema.NewTable("users").
+					AddColumns(
+						schema.NewIntColumn("id", TypeBigInt),
+						schema.NewDecimalColumn("price", TypeDecimal, schema.DecimalPrecision(10), schema.DecimalScale(2)).
+							SetDefault(&schema.Literal{V: "(0)"}),
+						schema.NewStringColumn("name", TypeVarchar).SetDefault(&schema.Literal{V: `"unknown"`}),
+					).
+					AddChecks(schema.NewCheck().SetName("ck_price").SetExpr("price >= 0"))
+			)
+			from.Columns[1].Type.Type = &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}
+			return testcase{
+				name: "normalized expressions",
+				from: from,
+				to:   to,
+			}
+		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("users").
+					AddColumns(
+						schema.NewIntColumn("id", TypeInteger),
+						schema.NewNullStringColumn("bio", TypeVarchar),
+					)
+				to = schema.NewTable("users").
+					AddColumns(
+						schema.NewIntColumn("id", TypeBigInt),
+						schema.NewStringColumn("bio", TypeVarchar).SetComment("about"),
+					)
+			)
+			return testcase{
+				name: "modify columns",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeType},
+					&schema.ModifyColumn{From: from.Columns[1], To: to.Columns[1], Change: schema.ChangeNull | schema.ChangeComment},
+				},
+			}
+		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("users").
+					AddColumns(schema.NewStringColumn("email", TypeVarchar))
+				to = schema.NewTable("users").
+					AddColumns(schema.NewStringColumn("email", TypeVarchar))
+			)
+			from.AddIndexes(schema.NewUniqueIndex("users_email_key").AddColumns(from.Columns[0]).AddAttrs(&UniqueConstraint{}))
+			to.AddIndexes(schema.NewUniqueIndex("").AddColumns(to.Columns[0]))
+			return testcase{
+				name: "generated unique constraint name",
+				from: from,
+				to:   to,
+			}
+		}(),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := DefaultDiff.TableDiff(tt.from, tt.to)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.EqualValues(t, tt.wantChanges, changes)
+		})
+	}
+}
+
+func TestDiff_ReferenceChanged(t *testing.T) {
+	d := &diff{}
+	require.False(t, d.ReferenceChanged("", schema.NoAction))
+	require.False(t, d.ReferenceChanged(schema.Restrict, schema.NoAction))
+	require.True(t, d.ReferenceChanged(schema.Cascade, schema.NoAction))
+}
+
+func TestNormalizeExpr(t *testing.T) {
+	for x, want := range map[string]string{
+		"a > 0":           "a > 0",
+		"(a > 0)":         "a > 0",
+		"((a > 0))":       "a > 0",
+		"(a > 0) AND (b)": "(a > 0) AND (b)",
+	} {
+		require.Equal(t, want, normalizeExpr(x), x)
+	}
+}
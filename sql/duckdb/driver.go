@@ -0,0 +1,249 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package duckdb implements the Atlas driver for DuckDB. Note, the package does not
+// register a database/sql driver. Programs that open connections using the "duckdb://"
+// scheme should import one, e.g. github.com/marcboeker/go-duckdb, that is built with
+// DuckDB v1.1.0 or above, as the inspection relies on its catalog functions.
+//
+// Database files are opened using the URL path, e.g. "duckdb://analytics.db" or
+// "duckdb:///var/lib/analytics.db", and in-memory databases are opened using the
+// "duckdb://" or "duckdb://?mode=memory" URLs.
+package duckdb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+type (
+	// Driver represents a DuckDB driver for introspecting database schemas,
+	// generating diff between schema elements and apply migrations changes.
+	Driver struct {
+		conn
+		schema.Differ
+		schema.Inspector
+		migrate.PlanApplier
+	}
+
+	// database connection and its information.
+	conn struct {
+		schema.ExecQuerier
+		// System variables that are set on `Open`.
+		version string
+		schema  string
+	}
+)
+
+var (
+	// DefaultDiff provides basic diffing capabilities for DuckDB dialects.
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// Differ when a database connection is available.
+	DefaultDiff schema.Differ = &sqlx.Diff{DiffDriver: &diff{}}
+
+	// DefaultPlan provides basic planning capabilities for DuckDB dialects.
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// migrate.PlanApplier when a database connection is available.
+	DefaultPlan migrate.PlanApplier = &planApply{conn{ExecQuerier: sqlx.NoRows, version: "1.1.0", schema: defaultSchema}}
+)
+
+// DriverName holds the name used for registration.
+const DriverName = "duckdb"
+
+func init() {
+	sqlclient.Register(
+		DriverName,
+		sqlclient.DriverOpener(Open),
+		sqlclient.RegisterCodec(MarshalHCL, EvalHCL),
+		sqlclient.RegisterURLParser(parser{}),
+	)
+}
+
+// Open opens a new DuckDB driver.
+func Open(db schema.ExecQuerier) (migrate.Driver, error) {
+	c := conn{ExecQuerier: db}
+	rows, err := db.QueryContext(context.Background(), variablesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: query system variables: %w", err)
+	}
+	if err := sqlx.ScanOne(rows, &c.version, &c.schema); err != nil {
+		return nil, fmt.Errorf("duckdb: scan system variables: %w", err)
+	}
+	// Versions are reported with the "v" prefix, e.g. "v1.1.3".
+	c.version = strings.TrimPrefix(c.version, "v")
+	return &Driver{
+		conn:        c,
+		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
+		Inspector:   &inspect{c},
+		PlanApplier: &planApply{c},
+	}, nil
+}
+
+func (d *Driver) dev() *sqlx.DevDriver {
+	return &sqlx.DevDriver{Driver: d, MaxNameLen: 255}
+}
+
+// NormalizeRealm returns the normal representation of the given database.
+func (d *Driver) NormalizeRealm(ctx context.Context, r *schema.Realm) (*schema.Realm, error) {
+	return d.dev().NormalizeRealm(ctx, r)
+}
+
+// NormalizeSchema returns the normal representation of the given database.
+func (d *Driver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*schema.Schema, error) {
+	return d.dev().NormalizeSchema(ctx, s)
+}
+
+// Capabilities implements the migrate.CapabilitiesReporter interface.
+func (d *Driver) Capabilities(version string) (*migrate.Capabilities, error) {
+	c := d.conn
+	if version != "" {
+		if !validVersion(version) {
+			return nil, fmt.Errorf("duckdb: malformed version: %s", version)
+		}
+		c.version = version
+	}
+	return c.capabilities(), nil
+}
+
+// capabilities returns the capabilities of the connection version.
+func (c *conn) capabilities() *migrate.Capabilities {
+	return &migrate.Capabilities{
+		Driver:  DriverName,
+		Version: c.version,
+		Supports: map[migrate.Capability]bool{
+			migrate.CapTransactionalDDL: true,
+			migrate.CapConcurrentIndex:  false,
+			// Columns are added without rewriting the table,
+			// as DuckDB stores its data in columnar row groups.
+			migrate.CapInstantAddColumn: true,
+			migrate.CapRenameColumn:     true,
+			migrate.CapCheckConstraint:  true,
+			migrate.CapIndexExpr:        true,
+			migrate.CapGeneratedColumn:  false,
+			migrate.CapInvisibleIndex:   false,
+			migrate.CapIndexInclude:     false,
+			migrate.CapNullsNotDistinct: false,
+		},
+	}
+}
+
+// Snapshot implements migrate.Snapshoter.
+func (d *Driver) Snapshot(ctx context.Context) (migrate.RestoreFunc, error) {
+	// The connection is always bound to a schema (the "main" one, if it was
+	// not changed), and we can restore the state if the schema has no tables.
+	s, err := d.InspectSchema(ctx, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Tables) > 0 {
+		return nil, migrate.NotCleanError{Reason: fmt.Sprintf("found table %q in schema %q", s.Tables[0].Name, s.Name)}
+	}
+	return func(ctx context.Context) error {
+		current, err := d.InspectSchema(ctx, s.Name, nil)
+		if err != nil {
+			return err
+		}
+		changes, err := d.SchemaDiff(current, s)
+		if err != nil {
+			return err
+		}
+		return d.ApplyChanges(ctx, changes)
+	}, nil
+}
+
+// CheckClean implements migrate.CleanChecker.
+func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error {
+	s, err := d.InspectSchema(ctx, "", nil)
+	if err != nil {
+		return err
+	}
+	if len(s.Tables) == 0 || ((revT.Schema == "" || s.Name == revT.Schema) && len(s.Tables) == 1 && s.Tables[0].Name == revT.Name) {
+		return nil
+	}
+	return &migrate.NotCleanError{Reason: fmt.Sprintf("found table %q in schema %q", s.Tables[0].Name, s.Name)}
+}
+
+// parser implements the sqlclient.URLParser and the sqlclient.SchemaChanger
+// interfaces. The database file is configured using the URL path, and the
+// connected schema is configured using the "schema" query parameter, e.g.
+// "duckdb://analytics.db?schema=staging".
+type parser struct{}
+
+// ParseURL implements the sqlclient.URLParser interface.
+func (parser) ParseURL(u *url.URL) *sqlclient.URL {
+	v := u.Query()
+	s := v.Get("schema")
+	v.Del("schema")
+	// An empty path opens an in-memory database.
+	dsn := u.Host + u.Path
+	if v.Get("mode") == "memory" {
+		v.Del("mode")
+		dsn = ""
+	}
+	if len(v) > 0 {
+		dsn += "?" + v.Encode()
+	}
+	return &sqlclient.URL{URL: u, DSN: dsn, Schema: s}
+}
+
+// ChangeSchema implements the sqlclient.SchemaChanger interface.
+func (parser) ChangeSchema(u *url.URL, s string) *url.URL {
+	nu := *u
+	v := nu.Query()
+	v.Set("schema", s)
+	nu.RawQuery = v.Encode()
+	return &nu
+}
+
+// validVersion reports if the given library version is valid, e.g. "1.1.3".
+func validVersion(v string) bool {
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		return false
+	}
+	for _, p := range parts {
+		if _, err := strconv.ParseUint(p, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// The default schema of DuckDB databases.
+const defaultSchema = "main"
+
+// DuckDB data types. See: https://duckdb.org/docs/sql/data_types/overview.
+const (
+	TypeBoolean     = "boolean"
+	TypeTinyInt     = "tinyint"
+	TypeSmallInt    = "smallint"
+	TypeInteger     = "integer"
+	TypeBigInt      = "bigint"
+	TypeHugeInt     = "hugeint"
+	TypeUTinyInt    = "utinyint"
+	TypeUSmallInt   = "usmallint"
+	TypeUInteger    = "uinteger"
+	TypeUBigInt     = "ubigint"
+	TypeUHugeInt    = "uhugeint"
+	TypeFloat       = "float"
+	TypeDouble      = "double"
+	TypeDecimal     = "decimal"
+	TypeVarchar     = "varchar"
+	TypeBlob        = "blob"
+	TypeDate        = "date"
+	TypeTime        = "time"
+	TypeTimestamp   = "timestamp"
+	TypeTimestampTZ = "timestamptz"
+	TypeInterval    = "interval"
+	TypeUUID        = "uuid"
+	TypeJSON        = "json"
+)
@@ -0,0 +1,50 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"net/url"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_ParseURL(t *testing.T) {
+	for u, want := range map[string]struct{ dsn, schema string }{
+		"duckdb://analytics.db":                         {dsn: "analytics.db"},
+		"duckdb:///var/lib/analytics.db?schema=staging": {dsn: "/var/lib/analytics.db", schema: "staging"},
+		"duckdb://analytics.db?access_mode=read_only":   {dsn: "analytics.db?access_mode=read_only"},
+		"duckdb://":                         {dsn: ""},
+		"duckdb://?mode=memory&schema=main": {dsn: "", schema: "main"},
+	} {
+		pu, err := url.Parse(u)
+		require.NoError(t, err)
+		ur := parser{}.ParseURL(pu)
+		require.Equal(t, want.dsn, ur.DSN, u)
+		require.Equal(t, want.schema, ur.Schema, u)
+	}
+	u, err := url.Parse("duckdb://analytics.db")
+	require.NoError(t, err)
+	u = parser{}.ChangeSchema(u, "staging")
+	require.Equal(t, "duckdb://analytics.db?schema=staging", u.String())
+}
+
+func TestDriver_Capabilities(t *testing.T) {
+	drv := &Driver{conn: conn{version: "1.1.3"}}
+	caps, err := drv.Capabilities("")
+	require.NoError(t, err)
+	require.Equal(t, DriverName, caps.Driver)
+	require.True(t, caps.Is(migrate.CapTransactionalDDL))
+	require.True(t, caps.Is(migrate.CapIndexExpr))
+	require.False(t, caps.Is(migrate.CapConcurrentIndex))
+
+	caps, err = drv.Capabilities("1.0")
+	require.NoError(t, err)
+	require.Equal(t, "1.0", caps.Version)
+	_, err = drv.Capabilities("v1.0")
+	require.Error(t, err)
+}
@@ -0,0 +1,588 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// An inspect provides a DuckDB implementation for schema.Inspector.
+type inspect struct{ conn }
+
+var _ schema.Inspector = (*inspect)(nil)
+
+// InspectRealm returns schema descriptions of all resources in the given realm.
+func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	schemas, err := i.schemas(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &schema.InspectRealmOption{}
+	}
+	r := schema.NewRealm(schemas...)
+	if len(schemas) == 0 || !sqlx.ModeInspectRealm(opts).Is(schema.InspectTables) {
+		return sqlx.ExcludeRealm(r, opts.Exclude)
+	}
+	if err := i.inspectTables(ctx, r, nil); err != nil {
+		return nil, err
+	}
+	sqlx.LinkSchemaTables(schemas)
+	return sqlx.ExcludeRealm(r, opts.Exclude)
+}
+
+// InspectSchema returns schema descriptions of the tables in the given schema.
+// If the schema name is empty, the result will be the current schema of the connection.
+func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (s *schema.Schema, err error) {
+	schemas, err := i.schemas(ctx, &schema.InspectRealmOption{Schemas: []string{name}})
+	if err != nil {
+		return nil, err
+	}
+	switch n := len(schemas); {
+	case n == 0:
+		return nil, &schema.NotExistError{Err: fmt.Errorf("duckdb: schema %q was not found", name)}
+	case n > 1:
+		return nil, fmt.Errorf("duckdb: %d schemas were found for %q", n, name)
+	}
+	if opts == nil {
+		opts = &schema.InspectOptions{}
+	}
+	r := schema.NewRealm(schemas...)
+	if sqlx.ModeInspectSchema(opts).Is(schema.InspectTables) {
+		if err := i.inspectTables(ctx, r, opts); err != nil {
+			return nil, err
+		}
+		sqlx.LinkSchemaTables(schemas)
+	}
+	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
+}
+
+func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
+	if err := i.tables(ctx, r, opts); err != nil {
+		return err
+	}
+	for _, s := range r.Schemas {
+		if len(s.Tables) == 0 {
+			continue
+		}
+		if err := i.columns(ctx, s); err != nil {
+			return err
+		}
+		if err := i.constraints(ctx, s); err != nil {
+			return err
+		}
+		if err := i.indexes(ctx, s); err != nil {
+			return err
+		}
+		if err := i.fks(ctx, s); err != nil {
+			return err
+		}
+		if err := i.checks(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemas returns the list of the schemas in the database.
+func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
+	var (
+		args  []any
+		query = schemasQuery
+	)
+	if opts != nil {
+		switch n := len(opts.Schemas); {
+		case n == 1 && opts.Schemas[0] == "":
+			query = fmt.Sprintf(schemasQueryArgs, "= current_schema()")
+		case n == 1 && opts.Schemas[0] != "":
+			query = fmt.Sprintf(schemasQueryArgs, "= $1")
+			args = append(args, opts.Schemas[0])
+		case n > 0:
+			query = fmt.Sprintf(schemasQueryArgs, "IN ("+nArgs(0, len(opts.Schemas))+")")
+			for _, s := range opts.Schemas {
+				args = append(args, s)
+			}
+		}
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: querying schemas: %w", err)
+	}
+	names, err := sqlx.ScanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: scanning schemas: %w", err)
+	}
+	schemas := make([]*schema.Schema, 0, len(names))
+	for _, name := range names {
+		schemas = append(schemas, schema.New(name))
+	}
+	return schemas, nil
+}
+
+// tables queries and adds the tables of the realm schemas.
+func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.InspectOptions) error {
+	var (
+		args  []any
+		query = fmt.Sprintf(tablesQuery, nArgs(0, len(realm.Schemas)))
+	)
+	for _, s := range realm.Schemas {
+		args = append(args, s.Name)
+	}
+	if opts != nil && len(opts.Tables) > 0 {
+		for _, t := range opts.Tables {
+			args = append(args, t)
+		}
+		query = fmt.Sprintf(tablesQueryArgs, nArgs(0, len(realm.Schemas)), nArgs(len(realm.Schemas), len(opts.Tables)))
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("duckdb: querying tables: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			tSchema, name string
+			comment       sql.NullString
+		)
+		if err := rows.Scan(&tSchema, &name, &comment); err != nil {
+			return fmt.Errorf("duckdb: scan table information: %w", err)
+		}
+		s, ok := realm.Schema(tSchema)
+		if !ok {
+			return fmt.Errorf("duckdb: schema %q was not found in realm", tSchema)
+		}
+		t := schema.NewTable(name)
+		s.AddTables(t)
+		if sqlx.ValidString(comment) {
+			t.SetComment(comment.String)
+		}
+	}
+	return rows.Close()
+}
+
+// columns queries and appends the columns of the given table.
+func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, columnsQuery, s)
+	if err != nil {
+		return fmt.Errorf("duckdb: query schema %q columns: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := i.addColumn(s, rows); err != nil {
+			return fmt.Errorf("duckdb: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// addColumn scans the current row and adds a new column from it to the table.
+func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) error {
+	var (
+		nullable         bool
+		table, name, typ string
+		defExpr, comment sql.NullString
+	)
+	if err := rows.Scan(&table, &name, &typ, &nullable, &defExpr, &comment); err != nil {
+		return err
+	}
+	t, ok := s.Table(table)
+	if !ok {
+		return fmt.Errorf("table %q was not found in schema", table)
+	}
+	c := &schema.Column{
+		Name: name,
+		Type: &schema.ColumnType{
+			Raw:  typ,
+			Null: nullable,
+		},
+	}
+	ct, err := ParseType(c.Type.Raw)
+	if err != nil {
+		return err
+	}
+	c.Type.Type = ct
+	if sqlx.ValidString(defExpr) {
+		c.Default = defaultExpr(defExpr.String)
+	}
+	if sqlx.ValidString(comment) {
+		c.SetComment(comment.String)
+	}
+	t.AddColumns(c)
+	return nil
+}
+
+// defaultExpr returns the default value of a column from its definition in the catalog.
+func defaultExpr(x string) schema.Expr {
+	switch {
+	case sqlx.IsLiteralNumber(x), sqlx.IsLiteralBool(x), sqlx.IsQuoted(x, '\''):
+		return &schema.Literal{V: x}
+	default:
+		return &schema.RawExpr{X: x}
+	}
+}
+
+// constraints queries and appends the primary keys and the
+// unique constraints of the given table.
+func (i *inspect) constraints(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, constraintsQuery, s)
+	if err != nil {
+		return fmt.Errorf("duckdb: query schema %q constraints: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			seqno                    int
+			table, name, typ, column string
+		)
+		if err := rows.Scan(&table, &name, &typ, &column, &seqno); err != nil {
+			return fmt.Errorf("duckdb: scanning constraints for schema %q: %w", s.Name, err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("duckdb: table %q was not found in schema", table)
+		}
+		primary := typ == "PRIMARY KEY"
+		idx, ok := t.Index(name)
+		if primary && t.PrimaryKey != nil {
+			idx, ok = t.PrimaryKey, true
+		}
+		if !ok {
+			idx = &schema.Index{Name: name, Unique: true, Table: t}
+			if primary {
+				t.SetPrimaryKey(idx)
+			} else {
+				idx.AddAttrs(&UniqueConstraint{})
+				t.AddIndexes(idx)
+			}
+		}
+		part := &schema.IndexPart{SeqNo: seqno}
+		if part.C, ok = t.Column(column); !ok {
+			return fmt.Errorf("duckdb: column %q was not found for constraint %q", column, idx.Name)
+		}
+		part.C.Indexes = append(part.C.Indexes, idx)
+		idx.Parts = append(idx.Parts, part)
+	}
+	return rows.Err()
+}
+
+// indexes queries and appends the indexes of the given table.
+func (i *inspect) indexes(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, indexesQuery, s)
+	if err != nil {
+		return fmt.Errorf("duckdb: query schema %q indexes: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			unique            bool
+			table, name, stmt string
+		)
+		if err := rows.Scan(&table, &name, &unique, &stmt); err != nil {
+			return fmt.Errorf("duckdb: scanning indexes for schema %q: %w", s.Name, err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("duckdb: table %q was not found in schema", table)
+		}
+		idx := &schema.Index{Name: name, Unique: unique, Table: t}
+		// The index columns and expressions are reported only
+		// as part of its definition, e.g. "CREATE INDEX ... (a, b DESC)".
+		for j, x := range parseIndexParts(stmt) {
+			part := &schema.IndexPart{SeqNo: j + 1}
+			if d := strings.ToUpper(x); strings.HasSuffix(d, " DESC") {
+				part.Desc, x = true, strings.TrimSpace(x[:len(x)-5])
+			} else if strings.HasSuffix(d, " ASC") {
+				x = strings.TrimSpace(x[:len(x)-4])
+			}
+			if c, ok := t.Column(unquote(x)); ok {
+				part.C = c
+				c.Indexes = append(c.Indexes, idx)
+			} else {
+				part.X = &schema.RawExpr{X: x}
+			}
+			idx.Parts = append(idx.Parts, part)
+		}
+		t.AddIndexes(idx)
+	}
+	return rows.Err()
+}
+
+// parseIndexParts returns the key parts of the given CREATE INDEX statement.
+func parseIndexParts(stmt string) []string {
+	i := strings.Index(strings.ToUpper(stmt), " ON ")
+	if i == -1 {
+		return nil
+	}
+	stmt = stmt[i:]
+	if i = strings.IndexByte(stmt, '('); i == -1 {
+		return nil
+	}
+	var (
+		parts  []string
+		depth  int
+		quoted byte
+		start  = i + 1
+	)
+	for j := start; j < len(stmt); j++ {
+		switch c := stmt[j]; {
+		case quoted != 0:
+			if c == quoted {
+				quoted = 0
+			}
+		case c == '\'' || c == '"':
+			quoted = c
+		case c == '(':
+			depth++
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(stmt[start:j]))
+			start = j + 1
+		case c == ')':
+			if depth == 0 {
+				return append(parts, strings.TrimSpace(stmt[start:j]))
+			}
+			depth--
+		}
+	}
+	return parts
+}
+
+// unquote returns the unquoted form of the given identifier, if it is quoted.
+func unquote(s string) string {
+	if len(s) > 1 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+	}
+	return s
+}
+
+// fks queries and appends the foreign keys of the given table.
+func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, fksQuery, s)
+	if err != nil {
+		return fmt.Errorf("duckdb: querying schema %q foreign keys: %w", s.Name, err)
+	}
+	defer rows.Close()
+	if err := sqlx.SchemaFKs(s, rows); err != nil {
+		return fmt.Errorf("duckdb: %w", err)
+	}
+	return rows.Err()
+}
+
+// checks queries and appends the check constraints of the given table.
+func (i *inspect) checks(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, checksQuery, s)
+	if err != nil {
+		return fmt.Errorf("duckdb: querying schema %q check constraints: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, name, expr string
+		if err := rows.Scan(&table, &name, &expr); err != nil {
+			return fmt.Errorf("duckdb: scanning check: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("duckdb: table %q was not found in schema", table)
+		}
+		t.AddChecks(&schema.Check{Name: name, Expr: expr})
+	}
+	return rows.Err()
+}
+
+// querySchema queries the rows of the given schema tables. The schema name
+// is passed as the first argument, followed by the table names.
+func (i *inspect) querySchema(ctx context.Context, query string, s *schema.Schema) (*sql.Rows, error) {
+	args := []any{s.Name}
+	for _, t := range s.Tables {
+		args = append(args, t.Name)
+	}
+	return i.QueryContext(ctx, fmt.Sprintf(query, nArgs(1, len(s.Tables))), args...)
+}
+
+// nArgs returns n placeholders starting after the given offset, e.g. "$2, $3".
+func nArgs(offset, n int) string {
+	ps := make([]string, n)
+	for i := range ps {
+		ps[i] = "$" + strconv.Itoa(offset+i+1)
+	}
+	return strings.Join(ps, ", ")
+}
+
+const (
+	// Query to list system variables.
+	variablesQuery = "SELECT version(), current_schema()"
+
+	// Query to list database schemas. The schemas of the system catalogs are excluded.
+	schemasQuery = "SELECT schema_name FROM information_schema.schemata WHERE catalog_name = current_database() AND schema_name NOT IN ('information_schema', 'pg_catalog') ORDER BY schema_name"
+
+	// Query to list specific database schemas.
+	schemasQueryArgs = "SELECT schema_name FROM information_schema.schemata WHERE catalog_name = current_database() AND schema_name NOT IN ('information_schema', 'pg_catalog') AND schema_name %s ORDER BY schema_name"
+
+	// Query to list schema tables.
+	tablesQuery = `
+SELECT
+	schema_name,
+	table_name,
+	comment
+FROM
+	duckdb_tables()
+WHERE
+	database_name = current_database()
+	AND NOT internal
+	AND NOT temporary
+	AND schema_name IN (%s)
+ORDER BY
+	schema_name, table_name
+`
+
+	// Query to list specific schema tables.
+	tablesQueryArgs = `
+SELECT
+	schema_name,
+	table_name,
+	comment
+FROM
+	duckdb_tables()
+WHERE
+	database_name = current_database()
+	AND NOT internal
+	AND NOT temporary
+	AND schema_name IN (%s)
+	AND table_name IN (%s)
+ORDER BY
+	schema_name, table_name
+`
+
+	// Query to list table columns.
+	columnsQuery = `
+SELECT
+	table_name,
+	column_name,
+	data_type,
+	is_nullable,
+	column_default,
+	comment
+FROM
+	duckdb_columns()
+WHERE
+	database_name = current_database()
+	AND schema_name = $1
+	AND table_name IN (%s)
+ORDER BY
+	table_name, column_index
+`
+
+	// Query to list the columns of table primary keys and unique constraints.
+	constraintsQuery = `
+SELECT
+	table_name,
+	constraint_name,
+	constraint_type,
+	unnest(constraint_column_names) AS column_name,
+	generate_subscripts(constraint_column_names, 1) AS seqno
+FROM
+	duckdb_constraints()
+WHERE
+	database_name = current_database()
+	AND schema_name = $1
+	AND table_name IN (%s)
+	AND constraint_type IN ('PRIMARY KEY', 'UNIQUE')
+ORDER BY
+	table_name, constraint_name, seqno
+`
+
+	// Query to list table indexes. Indexes that back constraints are not
+	// reported by duckdb_indexes(), and are inspected as constraints.
+	indexesQuery = `
+SELECT
+	table_name,
+	index_name,
+	is_unique,
+	sql
+FROM
+	duckdb_indexes()
+WHERE
+	database_name = current_database()
+	AND schema_name = $1
+	AND table_name IN (%s)
+ORDER BY
+	table_name, index_name
+`
+
+	// Query to list table foreign keys. DuckDB does not support referential
+	// actions, and foreign keys cannot reference tables in other schemas.
+	fksQuery = `
+SELECT
+	constraint_name,
+	table_name,
+	unnest(constraint_column_names) AS column_name,
+	schema_name AS table_schema,
+	referenced_table AS referenced_table_name,
+	unnest(referenced_column_names) AS referenced_column_name,
+	schema_name AS referenced_schema_name,
+	'NO ACTION' AS update_rule,
+	'NO ACTION' AS delete_rule
+FROM
+	duckdb_constraints()
+WHERE
+	database_name = current_database()
+	AND schema_name = $1
+	AND table_name IN (%s)
+	AND constraint_type = 'FOREIGN KEY'
+ORDER BY
+	constraint_name
+`
+
+	// Query to list table check constraints.
+	checksQuery = `
+SELECT
+	table_name,
+	constraint_name,
+	expression
+FROM
+	duckdb_constraints()
+WHERE
+	database_name = current_database()
+	AND schema_name = $1
+	AND table_name IN (%s)
+	AND constraint_type = 'CHECK'
+ORDER BY
+	table_name, constraint_name
+`
+)
+
+type (
+	// UniqueConstraint marks unique indexes that were created using a UNIQUE
+	// constraint. Unlike indexes created with CREATE INDEX, constraints can
+	// be defined only when the table is created.
+	UniqueConstraint struct {
+		schema.Attr
+	}
+
+	// IntervalType represents the INTERVAL type.
+	IntervalType struct {
+		schema.Type
+		T string
+	}
+
+	// UUIDType represents the UUID type.
+	UUIDType struct {
+		schema.Type
+		T string
+	}
+
+	// UserDefinedType represents a type that is not known to the driver, such
+	// as nested types (e.g. LIST, STRUCT or MAP) or user-defined enum types.
+	UserDefinedType struct {
+		schema.Type
+		T string
+	}
+)
@@ -0,0 +1,184 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectTable(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("v1.1.3")
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("main").
+		WillReturnRows(sqltest.Rows(`
++-------------+
+| schema_name |
++-------------+
+| main        |
++-------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQueryArgs, "$1", "$2"))).
+		WithArgs("main", "users").
+		WillReturnRows(sqltest.Rows(`
++-------------+------------+----------------+
+| schema_name | table_name | comment        |
++-------------+------------+----------------+
+| main        | users      | registered app |
++-------------+------------+----------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "$2"))).
+		WithArgs("main", "users").
+		WillReturnRows(sqltest.Rows(`
++------------+-------------+--------------------------+-------------+-------------------+---------+
+| table_name | column_name | data_type                | is_nullable | column_default    | comment |
++------------+-------------+--------------------------+-------------+-------------------+---------+
+| users      | id          | BIGINT                   | false       | NULL              | NULL    |
+| users      | email       | VARCHAR                  | false       | NULL              | login   |
+| users      | score       | DECIMAL(10,2)            | true        | 0                 | NULL    |
+| users      | tags        | VARCHAR[]                | true        | NULL              | NULL    |
+| users      | created_at  | TIMESTAMP WITH TIME ZONE | false       | CURRENT_TIMESTAMP | NULL    |
+| users      | team_id     | INTEGER                  | true        | NULL              | NULL    |
++------------+-------------+--------------------------+-------------+-------------------+---------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(constraintsQuery, "$2"))).
+		WithArgs("main", "users").
+		WillReturnRows(sqltest.Rows(`
++------------+-----------------+-----------------+-------------+-------+
+| table_name | constraint_name | constraint_type | column_name | seqno |
++------------+-----------------+-----------------+-------------+-------+
+| users      | users_id_pkey   | PRIMARY KEY     | id          | 1     |
+| users      | users_email_key | UNIQUE          | email       | 1     |
++------------+-----------------+-----------------+-------------+-------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "$2"))).
+		WithArgs("main", "users").
+		WillReturnRows(sqltest.Rows(`
++------------+------------+-----------+--------------------------------------------------------------------------+
+| table_name | index_name | is_unique | sql                                                                      |
++------------+------------+-----------+--------------------------------------------------------------------------+
+| users      | ix_score   | false     | CREATE INDEX ix_score ON users(score DESC, "created_at", lower(email));  |
++------------+------------+-----------+--------------------------------------------------------------------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "$2"))).
+		WithArgs("main", "users").
+		WillReturnRows(sqltest.Rows(`
++-------------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+
+| constraint_name         | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | update_rule | delete_rule |
++-------------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+
+| users_team_id_fkey      | users      | team_id     | main         | teams                 | id                     | main                   | NO ACTION   | NO ACTION   |
++-------------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2"))).
+		WithArgs("main", "users").
+		WillReturnRows(sqltest.Rows(`
++------------+-------------------+--------------+
+| table_name | constraint_name   | expression   |
++------------+-------------------+--------------+
+| users      | users_score_check | (score >= 0) |
++------------+-------------------+--------------+
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "main", &schema.InspectOptions{Tables: []string{"users"}})
+	require.NoError(t, err)
+	tbl, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "registered app"}, &schema.Check{Name: "users_score_check", Expr: "(score >= 0)"}}, tbl.Attrs)
+	require.EqualValues(t, []*schema.Column{
+		{Name: "id", Type: &schema.ColumnType{Raw: "BIGINT", Type: &schema.IntegerType{T: "bigint"}}},
+		{Name: "email", Type: &schema.ColumnType{Raw: "VARCHAR", Type: &schema.StringType{T: "varchar"}}, Attrs: []schema.Attr{&schema.Comment{Text: "login"}}},
+		{Name: "score", Type: &schema.ColumnType{Raw: "DECIMAL(10,2)", Type: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}, Null: true}, Default: &schema.Literal{V: "0"}},
+		{Name: "tags", Type: &schema.ColumnType{Raw: "VARCHAR[]", Type: &UserDefinedType{T: "VARCHAR[]"}, Null: true}},
+		{Name: "created_at", Type: &schema.ColumnType{Raw: "TIMESTAMP WITH TIME ZONE", Type: &schema.TimeType{T: "timestamptz"}}, Default: &schema.RawExpr{X: "CURRENT_TIMESTAMP"}},
+		{Name: "team_id", Type: &schema.ColumnType{Raw: "INTEGER", Type: &schema.IntegerType{T: "integer"}, Null: true}},
+	}, columnsOnly(tbl.Columns))
+	require.Equal(t, "users_id_pkey", tbl.PrimaryKey.Name)
+	require.Equal(t, "id", tbl.PrimaryKey.Parts[0].C.Name)
+	require.Len(t, tbl.Indexes, 2)
+	require.Equal(t, "users_email_key", tbl.Indexes[0].Name)
+	require.True(t, tbl.Indexes[0].Unique)
+	require.Equal(t, []schema.Attr{&UniqueConstraint{}}, tbl.Indexes[0].Attrs)
+	idx := tbl.Indexes[1]
+	require.Equal(t, "ix_score", idx.Name)
+	require.False(t, idx.Unique)
+	require.Len(t, idx.Parts, 3)
+	require.Equal(t, "score", idx.Parts[0].C.Name)
+	require.True(t, idx.Parts[0].Desc)
+	require.Equal(t, "created_at", idx.Parts[1].C.Name)
+	require.Equal(t, &schema.RawExpr{X: "lower(email)"}, idx.Parts[2].X)
+	require.Len(t, tbl.ForeignKeys, 1)
+	require.Equal(t, "teams", tbl.ForeignKeys[0].RefTable.Name)
+	require.Equal(t, schema.NoAction, tbl.ForeignKeys[0].OnDelete)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_InspectSchema(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("v1.1.3")
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= current_schema()"))).
+		WillReturnRows(sqltest.Rows(`
++-------------+
+| schema_name |
++-------------+
+| main        |
++-------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
+		WithArgs("main").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "table_name", "comment"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "main", s.Name)
+	require.Empty(t, s.Tables)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestParseIndexParts(t *testing.T) {
+	for stmt, want := range map[string][]string{
+		"CREATE INDEX i ON t(a);":                           {"a"},
+		"CREATE UNIQUE INDEX i ON main.t (a, b DESC);":      {"a", "b DESC"},
+		`CREATE INDEX "on" ON "t"("a,b", coalesce(c, 'x'))`: {`"a,b"`, "coalesce(c, 'x')"},
+		"CREATE INDEX i":                                    nil,
+	} {
+		require.Equal(t, want, parseIndexParts(stmt), stmt)
+	}
+}
+
+type mock struct {
+	sqlmock.Sqlmock
+}
+
+func (m mock) version(version string) {
+	m.ExpectQuery(sqltest.Escape(variablesQuery)).
+		WillReturnRows(sqltest.Rows(`
++-----------------+------------------+
+| version         | current_schema   |
++-----------------+------------------+
+| ` + version + ` | main             |
++-----------------+------------------+
+`))
+}
+
+func columnsOnly(columns []*schema.Column) []*schema.Column {
+	for _, c := range columns {
+		c.Indexes, c.ForeignKeys = nil, nil
+	}
+	return columns
+}
@@ -0,0 +1,622 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A planApply provides migration capabilities for schema elements.
+type planApply struct{ conn }
+
+// PlanChanges returns a migration plan for the given schema changes.
+func (p *planApply) PlanChanges(_ context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
+	s := &state{
+		conn: p.conn,
+		Plan: migrate.Plan{
+			Name: name,
+			// A plan is reversible, if all
+			// its changes are reversible.
+			Reversible: true,
+		},
+	}
+	for _, o := range opts {
+		o(&s.PlanOptions)
+	}
+	// Plan the changes for the target version, instead of the connected one.
+	if s.TargetVersion != "" {
+		if !validVersion(s.TargetVersion) {
+			return nil, fmt.Errorf("duckdb: malformed target version: %s", s.TargetVersion)
+		}
+		s.version = s.TargetVersion
+	}
+	s.Transactional = s.capabilities().Is(migrate.CapTransactionalDDL)
+	if err := s.plan(changes); err != nil {
+		return nil, err
+	}
+	for _, c := range s.Changes {
+		if c.Reverse == "" {
+			s.Reversible = false
+			break
+		}
+	}
+	return &s.Plan, nil
+}
+
+// ApplyChanges applies the changes on the database. An error is returned
+// if the driver is unable to produce a plan to it, or one of the statements
+// is failed or unsupported.
+func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change, opts ...migrate.PlanOption) error {
+	return sqlx.ApplyChanges(ctx, changes, p, opts...)
+}
+
+// state represents the state of a planning. It is not part of
+// planApply so that multiple planning/applying can be called
+// in parallel.
+type state struct {
+	conn
+	migrate.Plan
+	migrate.PlanOptions
+}
+
+// plan builds the migration plan for applying the
+// given changes on the attached connection.
+func (s *state) plan(changes []schema.Change) error {
+	if s.SchemaQualifier != nil {
+		if err := sqlx.CheckChangesScope(changes); err != nil {
+			return err
+		}
+	}
+	planned, err := s.topLevel(changes)
+	if err != nil {
+		return err
+	}
+	planned, err = sqlx.DetachCycles(planned)
+	if err != nil {
+		return err
+	}
+	for _, c := range planned {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			err = s.addTable(c)
+		case *schema.DropTable:
+			s.dropTable(c)
+		case *schema.ModifyTable:
+			err = s.modifyTable(c)
+		case *schema.RenameTable:
+			s.renameTable(c)
+		default:
+			err = fmt.Errorf("unsupported change %T", c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topLevel appends first the changes for creating or dropping schemas (top-level schema elements).
+func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			b := s.Build("CREATE SCHEMA")
+			if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
+				b.P("IF NOT EXISTS")
+			}
+			s.append(&migrate.Change{
+				Cmd:     b.Ident(c.S.Name).String(),
+				Source:  c,
+				Reverse: s.Build("DROP SCHEMA").Ident(c.S.Name).P("CASCADE").String(),
+				Comment: fmt.Sprintf("add new schema named %q", c.S.Name),
+			})
+		case *schema.DropSchema:
+			b := s.Build("DROP SCHEMA")
+			if sqlx.Has(c.Extra, &schema.IfExists{}) {
+				b.P("IF EXISTS")
+			}
+			s.append(&migrate.Change{
+				Cmd:     b.Ident(c.S.Name).P("CASCADE").String(),
+				Source:  c,
+				Comment: fmt.Sprintf("drop schema named %q", c.S.Name),
+			})
+		case *schema.ModifySchema:
+			// Schemas in DuckDB have no attributes that can be altered.
+			if len(c.Changes) > 0 {
+				return nil, fmt.Errorf("unsupported schema change %T", c.Changes[0])
+			}
+		default:
+			planned = append(planned, c)
+		}
+	}
+	return planned, nil
+}
+
+// addTable builds and appends a migration change
+// for creating a table in a schema.
+func (s *state) addTable(add *schema.AddTable) error {
+	var (
+		errs []string
+		b    = s.Build("CREATE TABLE")
+	)
+	if len(add.T.Columns) == 0 {
+		return fmt.Errorf("table %q has no columns", add.T.Name)
+	}
+	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
+		b.P("IF NOT EXISTS")
+	}
+	b.Table(add.T)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
+			if err := s.column(b, add.T.Columns[i]); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+		if pk := add.T.PrimaryKey; pk != nil {
+			b.Comma().P("PRIMARY KEY")
+			indexParts(b, pk.Parts)
+		}
+		// Indexes that were defined as UNIQUE constraints are created
+		// inline, and the rest are created using CREATE INDEX.
+		for _, idx := range add.T.Indexes {
+			if sqlx.Has(idx.Attrs, &UniqueConstraint{}) {
+				b.Comma().P("UNIQUE")
+				indexParts(b, idx.Parts)
+			}
+		}
+		if len(add.T.ForeignKeys) > 0 {
+			b.Comma()
+			if err := s.fks(b, add.T.ForeignKeys...); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		for _, attr := range add.T.Attrs {
+			if c, ok := attr.(*schema.Check); ok {
+				b.Comma()
+				check(b, c)
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  add,
+		Reverse: s.Build("DROP TABLE").Table(add.T).String(),
+		Comment: fmt.Sprintf("create %q table", add.T.Name),
+	})
+	for _, idx := range add.T.Indexes {
+		if !sqlx.Has(idx.Attrs, &UniqueConstraint{}) {
+			s.addIndex(add, add.T, idx)
+		}
+	}
+	s.addComments(add, add.T)
+	return nil
+}
+
+// dropTable builds and appends the migrate.Change
+// for dropping a table from a schema.
+func (s *state) dropTable(drop *schema.DropTable) {
+	b := s.Build("DROP TABLE")
+	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+		b.P("IF EXISTS")
+	}
+	b.Table(drop.T)
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  drop,
+		Comment: fmt.Sprintf("drop %q table", drop.T.Name),
+	})
+}
+
+// modifyTable builds and appends the migration changes for bringing the table
+// into its modified state. DuckDB does not support adding or dropping table
+// constraints after the table was created, and each ALTER TABLE statement
+// may contain only a single change.
+func (s *state) modifyTable(modify *schema.ModifyTable) error {
+	if len(modify.T.Columns) == 0 {
+		return fmt.Errorf("table %q has no columns; drop the table instead", modify.T.Name)
+	}
+	var changes [2][]schema.Change
+	for _, change := range modify.Changes {
+		switch change := change.(type) {
+		// Index modifications are translated into 2 steps.
+		// Dropping the current index and creating a new one.
+		case *schema.ModifyIndex:
+			changes[0] = append(changes[0], &schema.DropIndex{I: change.From})
+			changes[1] = append(changes[1], &schema.AddIndex{I: change.To})
+		case *schema.RenameIndex:
+			changes[0] = append(changes[0], &schema.DropIndex{I: change.From})
+			changes[1] = append(changes[1], &schema.AddIndex{I: change.To})
+		case *schema.DropIndex:
+			changes[0] = append(changes[0], change)
+		default:
+			changes[1] = append(changes[1], change)
+		}
+	}
+	for _, c := range append(changes[0], changes[1]...) {
+		if err := s.alterTable(modify, c); err != nil {
+			return fmt.Errorf("alter table %q: %w", modify.T.Name, err)
+		}
+	}
+	return nil
+}
+
+// alterTable builds and appends the migration change(s) for the given table change.
+func (s *state) alterTable(modify *schema.ModifyTable, change schema.Change) error {
+	t := modify.T
+	switch change := change.(type) {
+	case *schema.AddColumn:
+		return s.addColumn(modify, change.C)
+	case *schema.DropColumn:
+		// Columns are added back without their constraints, and
+		// therefore, the change is reversible only if it is nullable.
+		c := &migrate.Change{
+			Cmd:     s.Build("ALTER TABLE").Table(t).P("DROP COLUMN").Ident(change.C.Name).String(),
+			Source:  modify,
+			Comment: fmt.Sprintf("drop column %q from table %q", change.C.Name, t.Name),
+		}
+		if change.C.Type.Null {
+			b := s.Build("ALTER TABLE").Table(t).P("ADD COLUMN")
+			if err := s.column(b, change.C); err != nil {
+				return err
+			}
+			c.Reverse = b.String()
+		}
+		s.append(c)
+	case *schema.ModifyColumn:
+		return s.modifyColumn(modify, change)
+	case *schema.RenameColumn:
+		s.append(&migrate.Change{
+			Cmd:     s.Build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
+			Source:  modify,
+			Reverse: s.Build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
+			Comment: fmt.Sprintf("rename column %q of table %q to %q", change.From.Name, t.Name, change.To.Name),
+		})
+	case *schema.AddIndex:
+		// UNIQUE constraints cannot be added to existing
+		// tables, and they are created as unique indexes.
+		s.addIndex(modify, t, change.I)
+	case *schema.DropIndex:
+		if sqlx.Has(change.I.Attrs, &UniqueConstraint{}) {
+			return fmt.Errorf("dropping UNIQUE constraint %q is not supported (recreating the table is required)", change.I.Name)
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.dropIndex(t, change.I),
+			Source:  modify,
+			Reverse: s.createIndex(t, change.I),
+			Comment: fmt.Sprintf("drop index %q from table %q", change.I.Name, t.Name),
+		})
+	case *schema.AddAttr, *schema.ModifyAttr:
+		from, to, err := commentChange(change)
+		if err != nil {
+			return err
+		}
+		s.append(s.tableComment(modify, t, to, from))
+	case *schema.AddForeignKey, *schema.DropForeignKey, *schema.ModifyForeignKey, *schema.RenameForeignKey:
+		return fmt.Errorf("changing the foreign keys of an existing table is not supported (recreating the table is required)")
+	case *schema.AddCheck, *schema.DropCheck, *schema.ModifyCheck, *schema.RenameCheck:
+		return fmt.Errorf("changing the check constraints of an existing table is not supported (recreating the table is required)")
+	default:
+		return fmt.Errorf("unsupported change %T", change)
+	}
+	return nil
+}
+
+// addColumn builds and appends the migration changes for adding a column. Columns
+// cannot be added with constraints, and therefore, NOT NULL is set afterwards.
+func (s *state) addColumn(modify *schema.ModifyTable, c *schema.Column) error {
+	t := modify.T
+	b := s.Build("ALTER TABLE").Table(t).P("ADD COLUMN")
+	nc := *c
+	nc.Type = &schema.ColumnType{Type: c.Type.Type, Raw: c.Type.Raw, Null: true}
+	if err := s.column(b, &nc); err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  modify,
+		Reverse: s.Build("ALTER TABLE").Table(t).P("DROP COLUMN").Ident(c.Name).String(),
+		Comment: fmt.Sprintf("add column %q to table %q", c.Name, t.Name),
+	})
+	if !c.Type.Null {
+		s.append(&migrate.Change{
+			Cmd:     s.Build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.Name).P("SET NOT NULL").String(),
+			Source:  modify,
+			Reverse: s.Build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.Name).P("DROP NOT NULL").String(),
+			Comment: fmt.Sprintf("set column %q of table %q as NOT NULL", c.Name, t.Name),
+		})
+	}
+	if x := (schema.Comment{}); sqlx.Has(c.Attrs, &x) && x.Text != "" {
+		s.append(s.columnComment(modify, t, c, x.Text, ""))
+	}
+	return nil
+}
+
+// modifyColumn builds and appends the migration changes for modifying a column.
+// Each attribute of the column is changed using a separate statement.
+func (s *state) modifyColumn(modify *schema.ModifyTable, change *schema.ModifyColumn) error {
+	from, to, t := change.From, change.To, modify.T
+	alter := func() *sqlx.Builder {
+		return s.Build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(to.Name)
+	}
+	if change.Change.Is(schema.ChangeType) {
+		fromT, err := FormatType(from.Type.Type)
+		if err != nil {
+			return fmt.Errorf("format type for column %q: %w", from.Name, err)
+		}
+		toT, err := FormatType(to.Type.Type)
+		if err != nil {
+			return fmt.Errorf("format type for column %q: %w", to.Name, err)
+		}
+		s.append(&migrate.Change{
+			Cmd:     alter().P("TYPE", toT).String(),
+			Source:  modify,
+			Reverse: alter().P("TYPE", fromT).String(),
+			Comment: fmt.Sprintf("modify type of column %q of table %q", to.Name, t.Name),
+		})
+	}
+	if change.Change.Is(schema.ChangeNull) {
+		set, drop := alter().P("SET NOT NULL").String(), alter().P("DROP NOT NULL").String()
+		c := &migrate.Change{Cmd: set, Reverse: drop, Source: modify, Comment: fmt.Sprintf("set column %q of table %q as NOT NULL", to.Name, t.Name)}
+		if to.Type.Null {
+			c.Cmd, c.Reverse, c.Comment = drop, set, fmt.Sprintf("set column %q of table %q as nullable", to.Name, t.Name)
+		}
+		s.append(c)
+	}
+	if change.Change.Is(schema.ChangeDefault) {
+		s.append(&migrate.Change{
+			Cmd:     s.alterDefault(alter(), to),
+			Source:  modify,
+			Reverse: s.alterDefault(alter(), from),
+			Comment: fmt.Sprintf("modify default value of column %q of table %q", to.Name, t.Name),
+		})
+	}
+	if change.Change.Is(schema.ChangeComment) {
+		fromC, toC, err := commentChange(sqlx.CommentDiff(from.Attrs, to.Attrs))
+		if err != nil {
+			return err
+		}
+		s.append(s.columnComment(modify, t, to, toC, fromC))
+	}
+	return nil
+}
+
+// alterDefault returns the statement for setting or dropping the default value of the column.
+func (s *state) alterDefault(b *sqlx.Builder, c *schema.Column) string {
+	if c.Default == nil {
+		return b.P("DROP DEFAULT").String()
+	}
+	b.P("SET")
+	columnDefault(b, c)
+	return b.String()
+}
+
+// addIndex appends the migration change for creating the given index.
+func (s *state) addIndex(source schema.Change, t *schema.Table, idx *schema.Index) {
+	s.append(&migrate.Change{
+		Cmd:     s.createIndex(t, idx),
+		Source:  source,
+		Reverse: s.dropIndex(t, idx),
+		Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
+	})
+}
+
+// createIndex returns the statement for creating the given index.
+func (s *state) createIndex(t *schema.Table, idx *schema.Index) string {
+	b := s.Build("CREATE")
+	if idx.Unique {
+		b.P("UNIQUE")
+	}
+	b.P("INDEX").Ident(idx.Name).P("ON").Table(t)
+	indexParts(b, idx.Parts)
+	return b.String()
+}
+
+// dropIndex returns the statement for dropping the given index. Unlike tables,
+// indexes are dropped using their qualified name, because the connection that
+// executes the statements may not be attached to this schema.
+func (s *state) dropIndex(t *schema.Table, idx *schema.Index) string {
+	b := s.Build("DROP INDEX")
+	b.WriteString(s.schemaPrefix(t.Schema))
+	return b.Ident(idx.Name).String()
+}
+
+// schemaPrefix returns the quoted schema qualifier of the given schema,
+// or an empty string in case it should be omitted.
+func (s *state) schemaPrefix(ns *schema.Schema) string {
+	switch {
+	case s.SchemaQualifier != nil:
+		// In case the qualifier is empty, ignore.
+		if *s.SchemaQualifier != "" {
+			return fmt.Sprintf("%q.", *s.SchemaQualifier)
+		}
+	case ns != nil && ns.Name != "":
+		return fmt.Sprintf("%q.", ns.Name)
+	}
+	return ""
+}
+
+// renameTable builds and appends the migration change for renaming a table.
+func (s *state) renameTable(c *schema.RenameTable) {
+	s.append(&migrate.Change{
+		Source:  c,
+		Comment: fmt.Sprintf("rename a table from %q to %q", c.From.Name, c.To.Name),
+		Cmd:     s.Build("ALTER TABLE").Table(c.From).P("RENAME TO").Ident(c.To.Name).String(),
+		Reverse: s.Build("ALTER TABLE").Table(c.To).P("RENAME TO").Ident(c.From.Name).String(),
+	})
+}
+
+// addComments appends the changes for setting the comments of a new table and its columns.
+func (s *state) addComments(source schema.Change, t *schema.Table) {
+	var c schema.Comment
+	if sqlx.Has(t.Attrs, &c) && c.Text != "" {
+		s.append(s.tableComment(source, t, c.Text, ""))
+	}
+	for i := range t.Columns {
+		if sqlx.Has(t.Columns[i].Attrs, &c) && c.Text != "" {
+			s.append(s.columnComment(source, t, t.Columns[i], c.Text, ""))
+		}
+	}
+}
+
+func (s *state) tableComment(source schema.Change, t *schema.Table, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON TABLE").Table(t).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(commentValue(to)).String(),
+		Source:  source,
+		Comment: fmt.Sprintf("set comment to table: %q", t.Name),
+		Reverse: b.Clone().P(commentValue(from)).String(),
+	}
+}
+
+func (s *state) columnComment(source schema.Change, t *schema.Table, c *schema.Column, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON COLUMN").Table(t)
+	b.WriteByte('.')
+	b.Ident(c.Name).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(commentValue(to)).String(),
+		Source:  source,
+		Comment: fmt.Sprintf("set comment to column: %q on table: %q", c.Name, t.Name),
+		Reverse: b.Clone().P(commentValue(from)).String(),
+	}
+}
+
+// commentValue returns the value of a COMMENT ON statement.
+// Empty comments are removed by setting them to NULL.
+func commentValue(c string) string {
+	if c == "" {
+		return "NULL"
+	}
+	return quote(c)
+}
+
+// column writes the column definition to the builder.
+func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
+	typ, err := FormatType(c.Type.Type)
+	if err != nil {
+		return fmt.Errorf("format type for column %q: %w", c.Name, err)
+	}
+	b.Ident(c.Name).P(typ)
+	if !c.Type.Null {
+		b.P("NOT NULL")
+	}
+	if c.Default != nil {
+		columnDefault(b, c)
+	}
+	return nil
+}
+
+// fks writes the foreign key constraints to the builder.
+func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) error {
+	return b.MapCommaErr(fks, func(i int, b *sqlx.Builder) error {
+		fk := fks[i]
+		if fk.OnUpdate == schema.Cascade || fk.OnUpdate == schema.SetNull || fk.OnUpdate == schema.SetDefault ||
+			fk.OnDelete == schema.Cascade || fk.OnDelete == schema.SetNull || fk.OnDelete == schema.SetDefault {
+			return fmt.Errorf("foreign key %q: referential actions are not supported", fk.Symbol)
+		}
+		b.P("FOREIGN KEY")
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(fk.Columns, func(i int, b *sqlx.Builder) {
+				b.Ident(fk.Columns[i].Name)
+			})
+		})
+		b.P("REFERENCES").Table(fk.RefTable)
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(fk.RefColumns, func(i int, b *sqlx.Builder) {
+				b.Ident(fk.RefColumns[i].Name)
+			})
+		})
+		return nil
+	})
+}
+
+func (s *state) append(c *migrate.Change) {
+	s.Changes = append(s.Changes, c)
+}
+
+// Build instantiates a new builder and writes the given phrase to it.
+func (s *state) Build(phrases ...string) *sqlx.Builder {
+	b := &sqlx.Builder{QuoteChar: '"', Schema: s.SchemaQualifier}
+	return b.P(phrases...)
+}
+
+func indexParts(b *sqlx.Builder, parts []*schema.IndexPart) {
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(parts, func(i int, b *sqlx.Builder) {
+			switch part := parts[i]; {
+			case part.C != nil:
+				b.Ident(part.C.Name)
+			case part.X != nil:
+				b.WriteString(sqlx.MayWrap(part.X.(*schema.RawExpr).X))
+			}
+			// Ignore default collation (i.e. "ASC")
+			if parts[i].Desc {
+				b.P("DESC")
+			}
+		})
+	})
+}
+
+// check writes the CHECK constraint to the builder. Constraint
+// names are ignored by DuckDB, and therefore, are not written.
+func check(b *sqlx.Builder, c *schema.Check) {
+	b.P("CHECK", sqlx.MayWrap(c.Expr))
+}
+
+// columnDefault writes the default value of column to the builder.
+func columnDefault(b *sqlx.Builder, c *schema.Column) {
+	switch x := c.Default.(type) {
+	case *schema.Literal:
+		v := x.V
+		switch c.Type.Type.(type) {
+		case *schema.StringType, *schema.TimeType, *schema.JSONType, *IntervalType, *UUIDType:
+			v = quote(v)
+		}
+		b.P("DEFAULT", v)
+	case *schema.RawExpr:
+		b.P("DEFAULT", x.X)
+	}
+}
+
+// commentChange extracts the comment texts from the given attribute change.
+func commentChange(c schema.Change) (from, to string, err error) {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		toC, ok := c.A.(*schema.Comment)
+		if ok {
+			to = toC.Text
+			return
+		}
+		err = fmt.Errorf("unexpected AddAttr.(%T) for comment change", c.A)
+	case *schema.ModifyAttr:
+		fromC, ok1 := c.From.(*schema.Comment)
+		toC, ok2 := c.To.(*schema.Comment)
+		if ok1 && ok2 {
+			from, to = fromC.Text, toC.Text
+			return
+		}
+		err = fmt.Errorf("unsupported ModifyAttr(%T, %T) change", c.From, c.To)
+	default:
+		err = fmt.Errorf("unexpected change %T", c)
+	}
+	return
+}
+
+// quote returns the given string as a single-quoted literal, unless it is already quoted.
+func quote(s string) string {
+	if sqlx.IsQuoted(s, '\'') {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
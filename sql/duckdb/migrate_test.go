@@ -0,0 +1,262 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges(t *testing.T) {
+	var (
+		main  = schema.New("main")
+		users = func() *schema.Table {
+			t := schema.NewTable("users").
+				SetSchema(main).
+				SetComment("app users").
+				AddColumns(
+					schema.NewIntColumn("id", TypeBigInt),
+					schema.NewStringColumn("email", TypeVarchar).SetComment("login"),
+					schema.NewNullColumn("bio").SetType(&schema.StringType{T: TypeVarchar}),
+					schema.NewDecimalColumn("score", TypeDecimal, schema.DecimalPrecision(10), schema.DecimalScale(2)).
+						SetDefault(&schema.Literal{V: "0"}),
+				)
+			t.SetPrimaryKey(schema.NewPrimaryKey(t.Columns[0]))
+			t.AddIndexes(schema.NewUniqueIndex("users_email_key").AddColumns(t.Columns[1]).AddAttrs(&UniqueConstraint{}))
+			return t
+		}()
+		events = func() *schema.Table {
+			t := schema.NewTable("events").
+				SetSchema(main).
+				AddColumns(
+					schema.NewIntColumn("id", TypeInteger),
+					schema.NewNullIntColumn("user_id", TypeBigInt),
+					schema.NewColumn("at").SetType(&schema.TimeType{T: TypeTimestampTZ}).SetDefault(&schema.RawExpr{X: "now()"}),
+				)
+			t.SetPrimaryKey(schema.NewPrimaryKey(t.Columns[0]))
+			t.AddForeignKeys(schema.NewForeignKey("events_user").AddColumns(t.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+			t.AddIndexes(schema.NewIndex("ix_at").AddParts(schema.NewColumnPart(t.Columns[2]).SetDesc(true)))
+			t.AddChecks(schema.NewCheck().SetName("ck_id").SetExpr("id > 0"))
+			return t
+		}()
+	)
+	tests := []struct {
+		changes []schema.Change
+		options []migrate.PlanOption
+		wantErr bool
+		plan    *migrate.Plan
+	}{
+		{
+			changes: []schema.Change{&schema.AddSchema{S: schema.New("staging"), Extra: []schema.Clause{&schema.IfNotExists{}}}},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: `CREATE SCHEMA IF NOT EXISTS "staging"`, Reverse: `DROP SCHEMA "staging" CASCADE`}},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.DropSchema{S: schema.New("staging")}},
+			plan: &migrate.Plan{
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: `DROP SCHEMA "staging" CASCADE`}},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: users}},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "main"."users" ("id" bigint NOT NULL, "email" varchar NOT NULL, "bio" varchar, "score" decimal(10,2) NOT NULL DEFAULT 0, PRIMARY KEY ("id"), UNIQUE ("email"))`,
+						Reverse: `DROP TABLE "main"."users"`,
+					},
+					{Cmd: `COMMENT ON TABLE "main"."users" IS 'app users'`, Reverse: `COMMENT ON TABLE "main"."users" IS NULL`},
+					{Cmd: `COMMENT ON COLUMN "main"."users" ."email" IS 'login'`, Reverse: `COMMENT ON COLUMN "main"."users" ."email" IS NULL`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: events}},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "main"."events" ("id" integer NOT NULL, "user_id" bigint, "at" timestamptz NOT NULL DEFAULT now(), PRIMARY KEY ("id"), FOREIGN KEY ("user_id") REFERENCES "main"."users" ("id"), CHECK (id > 0))`,
+						Reverse: `DROP TABLE "main"."events"`,
+					},
+					{Cmd: `CREATE INDEX "ix_at" ON "main"."events" ("at" DESC)`, Reverse: `DROP INDEX "main"."ix_at"`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.RenameTable{From: users, To: schema.NewTable("people").SetSchema(main)}},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TABLE "main"."users" RENAME TO "people"`, Reverse: `ALTER TABLE "main"."people" RENAME TO "users"`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewIntColumn("age", TypeUTinyInt).SetDefault(&schema.Literal{V: "0"})},
+						&schema.DropColumn{C: users.Columns[2]},
+						&schema.RenameColumn{From: users.Columns[3], To: schema.NewColumn("rank")},
+						&schema.AddIndex{I: schema.NewIndex("ix_email").AddParts(schema.NewExprPart(&schema.RawExpr{X: "lower(email)"}))},
+						&schema.RenameIndex{From: schema.NewIndex("a").AddColumns(users.Columns[0]), To: schema.NewIndex("b").AddColumns(users.Columns[0])},
+						&schema.ModifyAttr{From: &schema.Comment{Text: "app users"}, To: &schema.Comment{Text: "users"}},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `DROP INDEX "main"."a"`, Reverse: `CREATE INDEX "a" ON "main"."users" ("id")`},
+					{Cmd: `ALTER TABLE "main"."users" ADD COLUMN "age" utinyint DEFAULT 0`, Reverse: `ALTER TABLE "main"."users" DROP COLUMN "age"`},
+					{Cmd: `ALTER TABLE "main"."users" ALTER COLUMN "age" SET NOT NULL`, Reverse: `ALTER TABLE "main"."users" ALTER COLUMN "age" DROP NOT NULL`},
+					{Cmd: `ALTER TABLE "main"."users" DROP COLUMN "bio"`, Reverse: `ALTER TABLE "main"."users" ADD COLUMN "bio" varchar`},
+					{Cmd: `ALTER TABLE "main"."users" RENAME COLUMN "score" TO "rank"`, Reverse: `ALTER TABLE "main"."users" RENAME COLUMN "rank" TO "score"`},
+					{Cmd: `CREATE INDEX "ix_email" ON "main"."users" ((lower(email)))`, Reverse: `DROP INDEX "main"."ix_email"`},
+					{Cmd: `CREATE INDEX "b" ON "main"."users" ("id")`, Reverse: `DROP INDEX "main"."b"`},
+					{Cmd: `COMMENT ON TABLE "main"."users" IS 'users'`, Reverse: `COMMENT ON TABLE "main"."users" IS 'app users'`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   users.Columns[3],
+							To:     schema.NewNullDecimalColumn("score", TypeDecimal, schema.DecimalPrecision(12), schema.DecimalScale(2)),
+							Change: schema.ChangeType | schema.ChangeNull | schema.ChangeDefault,
+						},
+						&schema.ModifyColumn{
+							From:   users.Columns[1],
+							To:     schema.NewStringColumn("email", TypeVarchar),
+							Change: schema.ChangeComment,
+						},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TABLE "main"."users" ALTER COLUMN "score" TYPE decimal(12,2)`, Reverse: `ALTER TABLE "main"."users" ALTER COLUMN "score" TYPE decimal(10,2)`},
+					{Cmd: `ALTER TABLE "main"."users" ALTER COLUMN "score" DROP NOT NULL`, Reverse: `ALTER TABLE "main"."users" ALTER COLUMN "score" SET NOT NULL`},
+					{Cmd: `ALTER TABLE "main"."users" ALTER COLUMN "score" DROP DEFAULT`, Reverse: `ALTER TABLE "main"."users" ALTER COLUMN "score" SET DEFAULT 0`},
+					{Cmd: `COMMENT ON COLUMN "main"."users" ."email" IS NULL`, Reverse: `COMMENT ON COLUMN "main"."users" ."email" IS 'login'`},
+				},
+			},
+		},
+		// Constraints cannot be changed on existing tables.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T:       events,
+					Changes: []schema.Change{&schema.DropForeignKey{F: events.ForeignKeys[0]}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T:       events,
+					Changes: []schema.Change{&schema.AddCheck{C: schema.NewCheck().SetExpr("user_id > 0")}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T:       users,
+					Changes: []schema.Change{&schema.DropIndex{I: users.Indexes[0]}},
+				},
+			},
+			wantErr: true,
+		},
+		// Referential actions are not supported.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: func() *schema.Table {
+						t := schema.NewTable("t").SetSchema(main).AddColumns(schema.NewIntColumn("id", TypeInteger))
+						t.AddForeignKeys(schema.NewForeignKey("fk").AddColumns(t.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]).SetOnDelete(schema.Cascade))
+						return t
+					}(),
+				},
+			},
+			wantErr: true,
+		},
+		// Custom schema qualifier.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T:       events,
+					Changes: []schema.Change{&schema.DropIndex{I: events.Indexes[0]}},
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `DROP INDEX "ix_at"`, Reverse: `CREATE INDEX "ix_at" ON "events" ("at" DESC)`},
+				},
+			},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			db, mk, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{mk}.version("v1.1.3")
+			drv, err := Open(db)
+			require.NoError(t, err)
+			plan, err := drv.PlanChanges(context.Background(), "plan", tt.changes, tt.options...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.plan.Reversible, plan.Reversible)
+			require.Equal(t, tt.plan.Transactional, plan.Transactional)
+			require.Len(t, plan.Changes, len(tt.plan.Changes))
+			for i, c := range plan.Changes {
+				require.Equal(t, tt.plan.Changes[i].Cmd, c.Cmd)
+				require.Equal(t, tt.plan.Changes[i].Reverse, c.Reverse)
+			}
+		})
+	}
+}
+
+func TestDefaultPlan(t *testing.T) {
+	changes, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: schema.NewTable("t").AddColumns(schema.NewIntColumn("a", TypeInteger))},
+	})
+	require.NoError(t, err)
+	require.Len(t, changes.Changes, 1)
+	require.Equal(t, `CREATE TABLE "t" ("a" integer NOT NULL)`, changes.Changes[0].Cmd)
+}
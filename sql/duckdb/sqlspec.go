@@ -0,0 +1,168 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"fmt"
+	"reflect"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/specutil"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlspec"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// evalSpec evaluates an Atlas DDL document into v using the input.
+func evalSpec(p *hclparse.Parser, v any, input map[string]string) error {
+	var d doc
+	if err := hclState.Eval(p, &d, input); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *schema.Realm:
+		if err := specutil.Scan(v, d.Schemas, d.Tables, convertTable); err != nil {
+			return fmt.Errorf("specutil: failed converting to *schema.Realm: %w", err)
+		}
+		if err := specutil.Unmanaged(v, d.Unmanaged); err != nil {
+			return err
+		}
+	case *schema.Schema:
+		if len(d.Schemas) != 1 {
+			return fmt.Errorf("specutil: expecting document to contain a single schema, got %d", len(d.Schemas))
+		}
+		var r schema.Realm
+		if err := specutil.Scan(&r, d.Schemas, d.Tables, convertTable); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(&r, d.Unmanaged); err != nil {
+			return err
+		}
+		r.Schemas[0].Realm = nil
+		*v = *r.Schemas[0]
+	default:
+		return fmt.Errorf("specutil: failed unmarshaling spec. %T is not supported", v)
+	}
+	return nil
+}
+
+// MarshalSpec marshals v into an Atlas DDL document using a schemahcl.Marshaler.
+func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
+	return specutil.Marshal(v, marshaler, schemaSpec)
+}
+
+// convertTable converts a sqlspec.Table to a schema.Table. Table conversion is done without converting
+// ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
+// at this point. Instead, the linking is done by the convertSchema function.
+func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, error) {
+	return specutil.Table(spec, parent, convertColumn, specutil.PrimaryKey, convertIndex, specutil.Check)
+}
+
+// convertIndex converts a sqlspec.Index into a schema.Index.
+func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
+	return specutil.Index(spec, t)
+}
+
+// convertColumn converts a sqlspec.Column into a schema.Column.
+func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error) {
+	return specutil.Column(spec, convertColumnType)
+}
+
+// convertColumnType converts a sqlspec.Column into a concrete DuckDB schema.Type.
+func convertColumnType(spec *sqlspec.Column) (schema.Type, error) {
+	return TypeRegistry.Type(spec.Type, spec.Extra.Attrs)
+}
+
+// schemaSpec converts from a concrete DuckDB schema to Atlas specification.
+func schemaSpec(s *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error) {
+	return specutil.FromSchema(s, tableSpec)
+}
+
+// tableSpec converts from a concrete DuckDB sqlspec.Table to a schema.Table.
+func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
+	return specutil.FromTable(
+		t,
+		columnSpec,
+		specutil.FromPrimaryKey,
+		indexSpec,
+		specutil.FromForeignKey,
+		specutil.FromCheck,
+	)
+}
+
+// indexSpec converts from a concrete DuckDB schema.Index into a sqlspec.Index.
+func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
+	return specutil.FromIndex(idx)
+}
+
+// columnSpec converts from a concrete DuckDB schema.Column into a sqlspec.Column.
+func columnSpec(c *schema.Column, _ *schema.Table) (*sqlspec.Column, error) {
+	return specutil.FromColumn(c, columnTypeSpec)
+}
+
+// columnTypeSpec converts from a concrete DuckDB schema.Type into sqlspec.Column Type.
+func columnTypeSpec(t schema.Type) (*sqlspec.Column, error) {
+	st, err := TypeRegistry.Convert(t)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlspec.Column{Type: st}, nil
+}
+
+// TypeRegistry contains the supported TypeSpecs for the duckdb driver.
+var TypeRegistry = schemahcl.NewRegistry(
+	schemahcl.WithFormatter(FormatType),
+	schemahcl.WithParser(ParseType),
+	schemahcl.WithSpecs(
+		schemahcl.NewTypeSpec(TypeBoolean),
+		schemahcl.NewTypeSpec(TypeTinyInt),
+		schemahcl.NewTypeSpec(TypeSmallInt),
+		schemahcl.NewTypeSpec(TypeInteger),
+		schemahcl.NewTypeSpec(TypeBigInt),
+		schemahcl.NewTypeSpec(TypeHugeInt),
+		schemahcl.NewTypeSpec(TypeUTinyInt),
+		schemahcl.NewTypeSpec(TypeUSmallInt),
+		schemahcl.NewTypeSpec(TypeUInteger),
+		schemahcl.NewTypeSpec(TypeUBigInt),
+		schemahcl.NewTypeSpec(TypeUHugeInt),
+		schemahcl.NewTypeSpec(TypeFloat),
+		schemahcl.NewTypeSpec(TypeDouble),
+		schemahcl.NewTypeSpec(TypeDecimal, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
+		schemahcl.NewTypeSpec(TypeVarchar),
+		schemahcl.NewTypeSpec(TypeBlob),
+		schemahcl.NewTypeSpec(TypeDate),
+		schemahcl.NewTypeSpec(TypeTime),
+		schemahcl.NewTypeSpec(TypeTimestamp),
+		schemahcl.NewTypeSpec(TypeTimestampTZ),
+		schemahcl.NewTypeSpec(TypeInterval),
+		schemahcl.NewTypeSpec(TypeUUID),
+		schemahcl.NewTypeSpec(TypeJSON),
+	),
+)
+
+var (
+	hclState = schemahcl.New(
+		schemahcl.WithTypes(TypeRegistry.Specs()),
+		schemahcl.WithScopedEnums("table.foreign_key.on_update", specutil.ReferenceVars...),
+		schemahcl.WithScopedEnums("table.foreign_key.on_delete", specutil.ReferenceVars...),
+	)
+	// MarshalHCL marshals v into an Atlas HCL DDL document.
+	MarshalHCL = schemahcl.MarshalerFunc(func(v any) ([]byte, error) {
+		return MarshalSpec(v, hclState)
+	})
+	// EvalHCL implements the schemahcl.Evaluator interface.
+	EvalHCL = schemahcl.EvalFunc(evalSpec)
+
+	// EvalHCLBytes is a helper that evaluates an HCL document from a byte slice instead
+	// of from an hclparse.Parser instance.
+	EvalHCLBytes = specutil.HCLBytesFunc(EvalHCL)
+)
+
+type doc struct {
+	Tables    []*sqlspec.Table     `spec:"table"`
+	Schemas   []*sqlspec.Schema    `spec:"schema"`
+	Unmanaged []*sqlspec.Unmanaged `spec:"unmanaged"`
+}
@@ -0,0 +1,117 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLSpec(t *testing.T) {
+	f := `
+schema "main" {
+}
+
+table "users" {
+	schema = schema.main
+	comment = "app users"
+	column "id" {
+		type = bigint
+	}
+	column "email" {
+		type = varchar
+		comment = "login"
+	}
+	column "score" {
+		type = decimal(10,2)
+		null = true
+		default = 0
+	}
+	primary_key {
+		columns = [column.id]
+	}
+	index "ix_email" {
+		unique = true
+		columns = [column.email]
+	}
+	check "positive_score" {
+		expr = "score >= 0"
+	}
+}
+`
+	var s schema.Schema
+	err := EvalHCLBytes([]byte(f), &s, nil)
+	require.NoError(t, err)
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, &schema.IntegerType{T: TypeBigInt}, users.Columns[0].Type.Type)
+	require.Equal(t, &schema.StringType{T: TypeVarchar}, users.Columns[1].Type.Type)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "login"}}, users.Columns[1].Attrs)
+	require.Equal(t, &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}, users.Columns[2].Type.Type)
+	require.Equal(t, &schema.Literal{V: "0"}, users.Columns[2].Default)
+	require.True(t, users.Indexes[0].Unique)
+
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	require.EqualValues(t, s.Tables[0].Attrs, after.Tables[0].Attrs)
+	for i, c := range s.Tables[0].Columns {
+		require.EqualValues(t, c.Type.Type, after.Tables[0].Columns[i].Type.Type)
+		require.EqualValues(t, c.Attrs, after.Tables[0].Columns[i].Attrs)
+	}
+}
+
+func TestTypes(t *testing.T) {
+	for _, tt := range []struct {
+		typeExpr string
+		expected schema.Type
+	}{
+		{typeExpr: "boolean", expected: &schema.BoolType{T: TypeBoolean}},
+		{typeExpr: "tinyint", expected: &schema.IntegerType{T: TypeTinyInt}},
+		{typeExpr: "integer", expected: &schema.IntegerType{T: TypeInteger}},
+		{typeExpr: "hugeint", expected: &schema.IntegerType{T: TypeHugeInt}},
+		{typeExpr: "ubigint", expected: &schema.IntegerType{T: TypeUBigInt, Unsigned: true}},
+		{typeExpr: "float", expected: &schema.FloatType{T: TypeFloat, Precision: 24}},
+		{typeExpr: "double", expected: &schema.FloatType{T: TypeDouble, Precision: 53}},
+		{typeExpr: "decimal(10,2)", expected: &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}},
+		{typeExpr: "varchar", expected: &schema.StringType{T: TypeVarchar}},
+		{typeExpr: "blob", expected: &schema.BinaryType{T: TypeBlob}},
+		{typeExpr: "date", expected: &schema.TimeType{T: TypeDate}},
+		{typeExpr: "timestamptz", expected: &schema.TimeType{T: TypeTimestampTZ}},
+		{typeExpr: "interval", expected: &IntervalType{T: TypeInterval}},
+		{typeExpr: "uuid", expected: &UUIDType{T: TypeUUID}},
+		{typeExpr: "json", expected: &schema.JSONType{T: TypeJSON}},
+		{typeExpr: `sql("INTEGER[]")`, expected: &UserDefinedType{T: "INTEGER[]"}},
+	} {
+		t.Run(tt.typeExpr, func(t *testing.T) {
+			var test schema.Schema
+			doc := fmt.Sprintf(`table "test" {
+	schema = schema.test
+	column "test" {
+		null = false
+		type = %s
+	}
+}
+schema "test" {
+}
+`, tt.typeExpr)
+			err := EvalHCLBytes([]byte(doc), &test, nil)
+			require.NoError(t, err)
+			colspec := test.Tables[0].Columns[0]
+			require.EqualValues(t, tt.expected, colspec.Type.Type)
+			spec, err := MarshalSpec(&test, hclState)
+			require.NoError(t, err)
+			var after schema.Schema
+			err = EvalHCLBytes(spec, &after, nil)
+			require.NoError(t, err)
+			require.EqualValues(t, tt.expected, after.Tables[0].Columns[0].Type.Type)
+		})
+	}
+}
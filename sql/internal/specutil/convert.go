@@ -7,6 +7,7 @@ package specutil
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -122,6 +123,15 @@ func Table(spec *sqlspec.Table, parent *schema.Schema, convertColumn ConvertColu
 	if err := convertCommentFromSpec(spec, &tbl.Attrs); err != nil {
 		return nil, err
 	}
+	if err := convertAnnotationsFromSpec(spec.Remain(), &tbl.Attrs); err != nil {
+		return nil, err
+	}
+	if err := convertRetentionFromSpec(spec.Remain(), tbl); err != nil {
+		return nil, err
+	}
+	if err := convertPrevNameFromSpec(spec, &tbl.Attrs); err != nil {
+		return nil, err
+	}
 	return tbl, nil
 }
 
@@ -151,6 +161,12 @@ func Column(spec *sqlspec.Column, conv ConvertTypeFunc) (*schema.Column, error)
 	if err := convertCommentFromSpec(spec, &out.Attrs); err != nil {
 		return nil, err
 	}
+	if err := convertAnnotationsFromSpec(spec.Remain(), &out.Attrs); err != nil {
+		return nil, err
+	}
+	if err := convertPrevNameFromSpec(spec, &out.Attrs); err != nil {
+		return nil, err
+	}
 	return out, err
 }
 
@@ -338,7 +354,8 @@ func FromTable(t *schema.Table, colFn ColumnSpecFunc, pkFn PrimaryKeySpecFunc, i
 			spec.Checks = append(spec.Checks, ckFn(c))
 		}
 	}
-	convertCommentFromSchema(t.Attrs, &spec.Extra.Attrs)
+	convertAnnotatedCommentFromSchema(t.Attrs, &spec.Extra)
+	convertRetentionFromSchema(t.Attrs, &spec.Extra)
 	return spec, nil
 }
 
@@ -374,7 +391,7 @@ func FromColumn(col *schema.Column, columnTypeSpec ColumnTypeSpecFunc) (*sqlspec
 		}
 		spec.Default = lv
 	}
-	convertCommentFromSchema(col.Attrs, &spec.Extra.Attrs)
+	convertAnnotatedCommentFromSchema(col.Attrs, &spec.Extra)
 	return spec, nil
 }
 
@@ -657,6 +674,397 @@ func convertCommentFromSpec(spec Attrer, attrs *[]schema.Attr) error {
 	return nil
 }
 
+// Settings converts the given setting specs to schema attributes.
+func Settings(specs []*sqlspec.Setting) []schema.Attr {
+	attrs := make([]schema.Attr, 0, len(specs))
+	for _, s := range specs {
+		attrs = append(attrs, &schema.Setting{Name: s.Name, V: s.Value})
+	}
+	return attrs
+}
+
+// FromSettings converts the setting attributes of a realm to specs.
+func FromSettings(attrs []schema.Attr) []*sqlspec.Setting {
+	var specs []*sqlspec.Setting
+	for _, a := range attrs {
+		if s, ok := a.(*schema.Setting); ok {
+			specs = append(specs, &sqlspec.Setting{Name: s.Name, Value: s.V})
+		}
+	}
+	return specs
+}
+
+// Unmanaged converts the given unmanaged object specs, and adds them to their schemas.
+func Unmanaged(r *schema.Realm, specs []*sqlspec.Unmanaged) error {
+	for _, spec := range specs {
+		name, err := SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("unmanaged %q: %w", spec.Name, err)
+		}
+		s, ok := r.Schema(name)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for unmanaged %q", name, spec.Name)
+		}
+		if spec.Type == "" {
+			return fmt.Errorf("missing attribute unmanaged.%s.type", spec.Name)
+		}
+		u := schema.NewUnmanaged(spec.Type, spec.Name, spec.Create).SetDrop(spec.Drop)
+		if spec.Fingerprint != "" && spec.Fingerprint != u.Fingerprint {
+			return fmt.Errorf("fingerprint of unmanaged %q does not match its CREATE statement", spec.Name)
+		}
+		s.AddAttrs(u)
+	}
+	return nil
+}
+
+// FromUnmanaged converts the unmanaged objects of a schema to specs.
+func FromUnmanaged(s *schema.Schema) []*sqlspec.Unmanaged {
+	var specs []*sqlspec.Unmanaged
+	for _, a := range s.Attrs {
+		if u, ok := a.(*schema.Unmanaged); ok {
+			specs = append(specs, &sqlspec.Unmanaged{
+				Name:        u.Name,
+				Schema:      SchemaRef(s.Name),
+				Type:        u.Type,
+				Create:      u.Create,
+				Drop:        u.Drop,
+				Fingerprint: u.Fingerprint,
+			})
+		}
+	}
+	return specs
+}
+
+// Views converts the given view specs, and adds them to their schemas.
+func Views(r *schema.Realm, specs []*sqlspec.View) error {
+	for _, spec := range specs {
+		name, err := SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("view %q: %w", spec.Name, err)
+		}
+		s, ok := r.Schema(name)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for view %q", name, spec.Name)
+		}
+		if spec.As == "" {
+			return fmt.Errorf("missing attribute view.%s.as", spec.Name)
+		}
+		v := schema.NewView(spec.Name, spec.As)
+		switch m := strings.ToLower(spec.Compare); m {
+		case "":
+		case schema.ViewCompareExact, schema.ViewCompareNormalized, schema.ViewCompareIgnore:
+			v.Attrs = append(v.Attrs, &schema.ViewCompare{Mode: m})
+		default:
+			return fmt.Errorf("unexpected view.%s.compare %q, expect %q, %q or %q", spec.Name, spec.Compare, schema.ViewCompareExact, schema.ViewCompareNormalized, schema.ViewCompareIgnore)
+		}
+		s.AddViews(v)
+	}
+	return nil
+}
+
+// FromViews converts the views of a schema to specs.
+func FromViews(s *schema.Schema) []*sqlspec.View {
+	var specs []*sqlspec.View
+	for _, v := range s.Views {
+		spec := &sqlspec.View{
+			Name:   v.Name,
+			Schema: SchemaRef(s.Name),
+			As:     v.Def,
+		}
+		for _, a := range v.Attrs {
+			if c, ok := a.(*schema.ViewCompare); ok {
+				spec.Compare = c.Mode
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// Funcs converts the given function specs, and adds them to their schemas.
+func Funcs(r *schema.Realm, specs []*sqlspec.Func) error {
+	for _, spec := range specs {
+		name, err := SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("function %q: %w", spec.Name, err)
+		}
+		s, ok := r.Schema(name)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for function %q", name, spec.Name)
+		}
+		switch {
+		case spec.Returns == "":
+			return fmt.Errorf("missing attribute function.%s.returns", spec.Name)
+		case spec.As == "":
+			return fmt.Errorf("missing attribute function.%s.as", spec.Name)
+		}
+		s.AddFuncs(schema.NewFunc(spec.Name, spec.Args, spec.Returns, spec.As).SetLang(spec.Lang))
+	}
+	return nil
+}
+
+// FromFuncs converts the functions of a schema to specs.
+func FromFuncs(s *schema.Schema) []*sqlspec.Func {
+	var specs []*sqlspec.Func
+	for _, f := range s.Funcs {
+		specs = append(specs, &sqlspec.Func{
+			Name:    f.Name,
+			Schema:  SchemaRef(s.Name),
+			Args:    f.Args,
+			Returns: f.Ret,
+			Lang:    f.Lang,
+			As:      f.Body,
+		})
+	}
+	return specs
+}
+
+// Procs converts the given procedure specs, and adds them to their schemas.
+func Procs(r *schema.Realm, specs []*sqlspec.Proc) error {
+	for _, spec := range specs {
+		name, err := SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("procedure %q: %w", spec.Name, err)
+		}
+		s, ok := r.Schema(name)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for procedure %q", name, spec.Name)
+		}
+		if spec.As == "" {
+			return fmt.Errorf("missing attribute procedure.%s.as", spec.Name)
+		}
+		s.AddProcs(schema.NewProc(spec.Name, spec.Args, spec.As).SetLang(spec.Lang))
+	}
+	return nil
+}
+
+// FromProcs converts the procedures of a schema to specs.
+func FromProcs(s *schema.Schema) []*sqlspec.Proc {
+	var specs []*sqlspec.Proc
+	for _, p := range s.Procs {
+		specs = append(specs, &sqlspec.Proc{
+			Name:   p.Name,
+			Schema: SchemaRef(s.Name),
+			Args:   p.Args,
+			Lang:   p.Lang,
+			As:     p.Body,
+		})
+	}
+	return specs
+}
+
+// Trigger levels, as defined in the "level" attribute of trigger specs.
+const (
+	triggerRow       = "ROW"
+	triggerStatement = "STATEMENT"
+)
+
+// Sequences converts the given sequence specs, and adds them to their schemas.
+// Sequences are converted after the tables, as they may be owned by columns.
+func Sequences(r *schema.Realm, specs []*sqlspec.Sequence) error {
+	for _, spec := range specs {
+		name, err := SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("sequence %q: %w", spec.Name, err)
+		}
+		s, ok := r.Schema(name)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for sequence %q", name, spec.Name)
+		}
+		seq := schema.NewSequence(spec.Name).SetType(spec.Type).SetCycle(spec.Cycle)
+		for _, o := range []struct {
+			k   string
+			set func(int64) *schema.Sequence
+		}{
+			{"start", seq.SetStart},
+			{"increment", seq.SetIncrement},
+			{"min_value", seq.SetMin},
+			{"max_value", seq.SetMax},
+			{"cache", seq.SetCache},
+		} {
+			a, ok := spec.Attr(o.k)
+			if !ok {
+				continue
+			}
+			v, err := a.Int64()
+			if err != nil {
+				return fmt.Errorf("sequence %q: reading attribute %q: %w", spec.Name, o.k, err)
+			}
+			o.set(v)
+		}
+		if spec.Owner != nil {
+			t, c, err := externalRef(spec.Owner, s)
+			if err != nil {
+				return fmt.Errorf("sequence %q: owner: %w", spec.Name, err)
+			}
+			seq.SetOwner(t, c)
+		}
+		s.AddSequences(seq)
+	}
+	return nil
+}
+
+// FromSequences converts the sequences of a schema to specs. Owner columns
+// are referenced by their table name, or by their schema and table name in
+// case the table was qualified.
+func FromSequences(s *schema.Schema, tables []*sqlspec.Table) []*sqlspec.Sequence {
+	var specs []*sqlspec.Sequence
+	for _, sq := range s.Sequences {
+		spec := &sqlspec.Sequence{
+			Name:   sq.Name,
+			Schema: SchemaRef(s.Name),
+		}
+		// Types are written as variables (e.g. bigint), as they are
+		// used in column definitions.
+		if sq.Type != "" {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, VarAttr("type", sq.Type))
+		}
+		if sq.Start != 0 {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, Int64Attr("start", sq.Start))
+		}
+		if sq.Increment != 0 {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, Int64Attr("increment", sq.Increment))
+		}
+		if sq.Min != nil {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, Int64Attr("min_value", *sq.Min))
+		}
+		if sq.Max != nil {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, Int64Attr("max_value", *sq.Max))
+		}
+		if sq.Cache != 0 {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, Int64Attr("cache", sq.Cache))
+		}
+		if sq.Cycle {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, BoolAttr("cycle", true))
+		}
+		if o := sq.Owner; o != nil && o.T != nil && o.C != nil {
+			spec.Owner = externalColRef(o.C.Name, o.T.Name)
+			for _, ts := range tables {
+				if ts.Name == o.T.Name && ts.Qualifier == s.Name {
+					spec.Owner = qualifiedExternalColRef(o.C.Name, o.T.Name, s.Name)
+				}
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// Triggers converts the given trigger specs, and adds them to their tables.
+// Tables are referenced by their name, or by their schema and name in case
+// the table name is not unique in the realm.
+func Triggers(r *schema.Realm, specs []*sqlspec.Trigger) error {
+	for _, spec := range specs {
+		t, err := triggerTable(r, spec)
+		if err != nil {
+			return err
+		}
+		switch {
+		case spec.Timing == "":
+			return fmt.Errorf("missing attribute trigger.%s.timing", spec.Name)
+		case len(spec.Events) == 0:
+			return fmt.Errorf("missing attribute trigger.%s.events", spec.Name)
+		case spec.As == "":
+			return fmt.Errorf("missing attribute trigger.%s.as", spec.Name)
+		}
+		level := strings.ToUpper(spec.Level)
+		if level != "" && level != triggerRow && level != triggerStatement {
+			return fmt.Errorf("unexpected trigger.%s.level %q, expect %q or %q", spec.Name, spec.Level, triggerRow, triggerStatement)
+		}
+		t.AddTriggers(
+			schema.NewTrigger(spec.Name, spec.Timing, spec.Events, spec.As).
+				SetForEachRow(level != triggerStatement).
+				SetWhen(spec.When),
+		)
+	}
+	return nil
+}
+
+// triggerTable returns the table referenced by the given trigger spec.
+func triggerTable(r *schema.Realm, spec *sqlspec.Trigger) (*schema.Table, error) {
+	if spec.On == nil {
+		return nil, fmt.Errorf("missing attribute trigger.%s.on", spec.Name)
+	}
+	var (
+		tables []*schema.Table
+		parts  = strings.Split(spec.On.V, ".")
+	)
+	switch {
+	case parts[0] != "$table" || len(parts) < 2 || len(parts) > 3:
+		return nil, fmt.Errorf("trigger %q: expected ref format of $table.name or $table.schema.name", spec.Name)
+	case len(parts) == 3:
+		if s, ok := r.Schema(parts[1]); ok {
+			if t, ok := s.Table(parts[2]); ok {
+				tables = append(tables, t)
+			}
+		}
+	default:
+		for _, s := range r.Schemas {
+			if t, ok := s.Table(parts[1]); ok {
+				tables = append(tables, t)
+			}
+		}
+	}
+	switch len(tables) {
+	case 0:
+		return nil, fmt.Errorf("table %q was not found in realm for trigger %q", strings.TrimPrefix(spec.On.V, "$table."), spec.Name)
+	case 1:
+		return tables[0], nil
+	default:
+		return nil, fmt.Errorf("trigger %q: table %q is ambiguous, qualify it with its schema", spec.Name, parts[1])
+	}
+}
+
+// FromTriggers converts the triggers of a schema to specs. The table references
+// are qualified with the schema name if their table specs are qualified.
+func FromTriggers(s *schema.Schema, tables []*sqlspec.Table) []*sqlspec.Trigger {
+	var specs []*sqlspec.Trigger
+	for _, t := range s.Tables {
+		ref := &schemahcl.Ref{V: "$table." + t.Name}
+		for _, ts := range tables {
+			if ts.Name == t.Name && ts.Qualifier == s.Name {
+				ref.V = "$table." + s.Name + "." + t.Name
+			}
+		}
+		for _, tr := range t.Triggers {
+			level := triggerRow
+			if !tr.ForEachRow {
+				level = triggerStatement
+			}
+			specs = append(specs, &sqlspec.Trigger{
+				Name:   tr.Name,
+				On:     ref,
+				Timing: tr.ActionTime,
+				Events: tr.Events,
+				Level:  level,
+				When:   tr.When,
+				As:     tr.Body,
+			})
+		}
+	}
+	return specs
+}
+
+// convertPrevNameFromSpec converts a spec renamed_from attribute (or its
+// prev_name alias) to a schema element attribute.
+func convertPrevNameFromSpec(spec Attrer, attrs *[]schema.Attr) error {
+	c, ok := spec.Attr("renamed_from")
+	if p, ok1 := spec.Attr("prev_name"); ok1 {
+		if ok {
+			return errors.New("renamed_from and prev_name attributes cannot be used together")
+		}
+		c, ok = p, ok1
+	}
+	if ok {
+		s, err := c.String()
+		if err != nil {
+			return err
+		}
+		*attrs = append(*attrs, &schema.PrevName{Name: s})
+	}
+	return nil
+}
+
 // convertCommentFromSchema converts a schema element comment attribute to a spec comment attribute.
 func convertCommentFromSchema(src []schema.Attr, trgt *[]*schemahcl.Attr) {
 	var c schema.Comment
@@ -665,6 +1073,138 @@ func convertCommentFromSchema(src []schema.Attr, trgt *[]*schemahcl.Attr) {
 	}
 }
 
+// convertAnnotationsFromSpec converts the annotations block of a table or a column
+// spec, and stores them in the comment attribute of the element. For example:
+//
+//	annotations {
+//	  pii       = "email"
+//	  retention = "30d"
+//	}
+func convertAnnotationsFromSpec(spec *schemahcl.Resource, attrs *[]schema.Attr) error {
+	r, ok := spec.Resource("annotations")
+	if !ok {
+		return nil
+	}
+	annotations := make(map[string]string, len(r.Attrs))
+	for _, a := range r.Attrs {
+		v, err := a.String()
+		if err != nil {
+			return fmt.Errorf("annotation %q: %w", a.K, err)
+		}
+		annotations[a.K] = v
+	}
+	for i := range *attrs {
+		if c, ok := (*attrs)[i].(*schema.Comment); ok {
+			(*attrs)[i] = schema.AnnotatedComment(c.Text, annotations)
+			return nil
+		}
+	}
+	*attrs = append(*attrs, schema.AnnotatedComment("", annotations))
+	return nil
+}
+
+// convertAnnotatedCommentFromSchema converts the comment of a table or a column to
+// a spec comment attribute, and the annotations it carries to an annotations block.
+func convertAnnotatedCommentFromSchema(src []schema.Attr, trgt *schemahcl.Resource) {
+	var c schema.Comment
+	if !sqlx.Has(src, &c) {
+		return
+	}
+	text, annotations := c.Split()
+	if annotations == nil {
+		trgt.Attrs = append(trgt.Attrs, StrAttr("comment", c.Text))
+		return
+	}
+	if text != "" {
+		trgt.Attrs = append(trgt.Attrs, StrAttr("comment", text))
+	}
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	r := &schemahcl.Resource{Type: "annotations"}
+	for _, k := range keys {
+		r.Attrs = append(r.Attrs, StrAttr(k, annotations[k]))
+	}
+	trgt.Children = append(trgt.Children, r)
+}
+
+// RetentionUnits holds the HCL variables for the units of the retention period.
+var RetentionUnits = []string{
+	schema.RetentionHour,
+	schema.RetentionDay,
+	schema.RetentionWeek,
+	schema.RetentionMonth,
+	schema.RetentionYear,
+}
+
+// convertRetentionFromSpec converts the retention block of a table spec
+// into a schema.Retention attribute. The unit defaults to DAY. For example:
+//
+//	retention {
+//	  column = column.created_at
+//	  period = 30
+//	  unit   = DAY
+//	}
+func convertRetentionFromSpec(spec *schemahcl.Resource, t *schema.Table) error {
+	r, ok := spec.Resource("retention")
+	if !ok {
+		return nil
+	}
+	ret := &schema.Retention{Unit: schema.RetentionDay}
+	a, ok := r.Attr("column")
+	if !ok {
+		return fmt.Errorf("retention of table %q: missing column attribute", t.Name)
+	}
+	ref, err := a.Ref()
+	if err != nil {
+		return fmt.Errorf("retention of table %q: %w", t.Name, err)
+	}
+	if ret.C, err = ColumnByRef(t, &schemahcl.Ref{V: ref}); err != nil {
+		return fmt.Errorf("retention of table %q: %w", t.Name, err)
+	}
+	if a, ok := r.Attr("period"); ok {
+		if ret.Period, err = a.Int(); err != nil {
+			return fmt.Errorf("retention of table %q: %w", t.Name, err)
+		}
+	}
+	if ret.Period <= 0 {
+		return fmt.Errorf("retention of table %q: period must be positive, got %d", t.Name, ret.Period)
+	}
+	if a, ok := r.Attr("unit"); ok {
+		if ret.Unit, err = a.String(); err != nil {
+			return fmt.Errorf("retention of table %q: %w", t.Name, err)
+		}
+		ret.Unit = strings.ToUpper(ret.Unit)
+	}
+	var valid bool
+	for _, u := range RetentionUnits {
+		valid = valid || u == ret.Unit
+	}
+	if !valid {
+		return fmt.Errorf("retention of table %q: unknown unit %q", t.Name, ret.Unit)
+	}
+	t.Attrs = append(t.Attrs, ret)
+	return nil
+}
+
+// convertRetentionFromSchema converts the retention policy of a table to a retention block.
+func convertRetentionFromSchema(src []schema.Attr, trgt *schemahcl.Resource) {
+	var r schema.Retention
+	if !sqlx.Has(src, &r) || r.C == nil {
+		return
+	}
+	trgt.Children = append(trgt.Children, &schemahcl.Resource{
+		Type: "retention",
+		Attrs: []*schemahcl.Attr{
+			RefAttr("column", ColumnRef(r.C.Name)),
+			IntAttr("period", r.Period),
+			VarAttr("unit", strings.ToUpper(r.Unit)),
+		},
+	})
+}
+
 // ReferenceVars holds the HCL variables
 // for foreign keys' referential-actions.
 var ReferenceVars = []string{
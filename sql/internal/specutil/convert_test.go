@@ -89,3 +89,64 @@ func TestFromForeignKey(t *testing.T) {
 		},
 	}, key)
 }
+
+func TestColumn_RenamedFrom(t *testing.T) {
+	conv := func(*sqlspec.Column) (schema.Type, error) {
+		return &schema.StringType{T: "text"}, nil
+	}
+	spec := &sqlspec.Column{Name: "contact"}
+	spec.Extra.Attrs = []*schemahcl.Attr{StrAttr("renamed_from", "email")}
+	c, err := Column(spec, conv)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&schema.PrevName{Name: "email"}}, c.Attrs)
+
+	spec.Extra.Attrs = []*schemahcl.Attr{StrAttr("prev_name", "email")}
+	c, err = Column(spec, conv)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&schema.PrevName{Name: "email"}}, c.Attrs)
+
+	spec.Extra.Attrs = append(spec.Extra.Attrs, StrAttr("renamed_from", "mail"))
+	_, err = Column(spec, conv)
+	require.EqualError(t, err, "renamed_from and prev_name attributes cannot be used together")
+}
+
+func TestColumn_Annotations(t *testing.T) {
+	conv := func(*sqlspec.Column) (schema.Type, error) {
+		return &schema.StringType{T: "text"}, nil
+	}
+	typeSpec := func(schema.Type) (*sqlspec.Column, error) {
+		return &sqlspec.Column{Type: &schemahcl.Type{T: "text"}}, nil
+	}
+	spec := &sqlspec.Column{Name: "email"}
+	spec.Extra.Attrs = []*schemahcl.Attr{StrAttr("comment", "contact email")}
+	spec.Extra.Children = []*schemahcl.Resource{
+		{Type: "annotations", Attrs: []*schemahcl.Attr{StrAttr("retention", "1y"), StrAttr("pii", "email")}},
+	}
+	c, err := Column(spec, conv)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "contact email\natlas:annotations={\"pii\":\"email\",\"retention\":\"1y\"}"}}, c.Attrs)
+
+	// Annotations are printed in a separate block, sorted by their keys.
+	c.Type = &schema.ColumnType{Type: &schema.StringType{T: "text"}}
+	s, err := FromColumn(c, typeSpec)
+	require.NoError(t, err)
+	require.Equal(t, []*schemahcl.Attr{StrAttr("comment", "contact email")}, s.Extra.Attrs)
+	require.Equal(t, []*schemahcl.Resource{
+		{Type: "annotations", Attrs: []*schemahcl.Attr{StrAttr("pii", "email"), StrAttr("retention", "1y")}},
+	}, s.Extra.Children)
+
+	// Annotations without a comment.
+	spec.Extra.Attrs = nil
+	c, err = Column(spec, conv)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "atlas:annotations={\"pii\":\"email\",\"retention\":\"1y\"}"}}, c.Attrs)
+	c.Type = &schema.ColumnType{Type: &schema.StringType{T: "text"}}
+	s, err = FromColumn(c, typeSpec)
+	require.NoError(t, err)
+	require.Empty(t, s.Extra.Attrs)
+	require.Len(t, s.Extra.Children, 1)
+
+	spec.Extra.Children[0].Attrs = []*schemahcl.Attr{IntAttr("pii", 1)}
+	_, err = Column(spec, conv)
+	require.Error(t, err)
+}
@@ -88,8 +88,14 @@ func ListAttr(k string, litValues ...string) *schemahcl.Attr {
 }
 
 type doc struct {
-	Tables  []*sqlspec.Table  `spec:"table"`
-	Schemas []*sqlspec.Schema `spec:"schema"`
+	Tables    []*sqlspec.Table     `spec:"table"`
+	Views     []*sqlspec.View      `spec:"view"`
+	Triggers  []*sqlspec.Trigger   `spec:"trigger"`
+	Funcs     []*sqlspec.Func      `spec:"function"`
+	Procs     []*sqlspec.Proc      `spec:"procedure"`
+	Schemas   []*sqlspec.Schema    `spec:"schema"`
+	Settings  []*sqlspec.Setting   `spec:"setting"`
+	Unmanaged []*sqlspec.Unmanaged `spec:"unmanaged"`
 }
 
 // Marshal marshals v into an Atlas DDL document using a schemahcl.Marshaler. Marshal uses the given
@@ -104,6 +110,11 @@ func Marshal(v any, marshaler schemahcl.Marshaler, schemaSpec func(schem *schema
 		}
 		d.Tables = tables
 		d.Schemas = []*sqlspec.Schema{spec}
+		d.Views = FromViews(s)
+		d.Funcs = FromFuncs(s)
+		d.Procs = FromProcs(s)
+		d.Triggers = FromTriggers(s, tables)
+		d.Unmanaged = FromUnmanaged(s)
 	case *schema.Realm:
 		for _, s := range s.Schemas {
 			spec, tables, err := schemaSpec(s)
@@ -112,6 +123,10 @@ func Marshal(v any, marshaler schemahcl.Marshaler, schemaSpec func(schem *schema
 			}
 			d.Tables = append(d.Tables, tables...)
 			d.Schemas = append(d.Schemas, spec)
+			d.Views = append(d.Views, FromViews(s)...)
+			d.Funcs = append(d.Funcs, FromFuncs(s)...)
+			d.Procs = append(d.Procs, FromProcs(s)...)
+			d.Unmanaged = append(d.Unmanaged, FromUnmanaged(s)...)
 		}
 		if err := QualifyDuplicates(d.Tables); err != nil {
 			return nil, err
@@ -119,6 +134,11 @@ func Marshal(v any, marshaler schemahcl.Marshaler, schemaSpec func(schem *schema
 		if err := QualifyReferences(d.Tables, s); err != nil {
 			return nil, err
 		}
+		// Triggers are converted after the tables were qualified.
+		for _, s := range s.Schemas {
+			d.Triggers = append(d.Triggers, FromTriggers(s, d.Tables)...)
+		}
+		d.Settings = FromSettings(s.Attrs)
 	default:
 		return nil, fmt.Errorf("specutil: failed marshaling spec. %T is not supported", v)
 	}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"regexp"
+	"strings"
 	"time"
 
 	"ariga.io/atlas/sql/migrate"
@@ -32,6 +34,11 @@ type DevDriver struct {
 	// PatchColumn allows providing a custom function to patch
 	// columns that hold a schema reference.
 	PatchColumn func(*schema.Schema, *schema.Column)
+
+	// KeepAttr reports if the given schema or table attribute is kept as-is
+	// instead of being created in the dev database. For example, privileges
+	// that are granted to roles that do not exist in the dev database.
+	KeepAttr func(schema.Attr) bool
 }
 
 // NormalizeRealm implements the schema.Normalizer interface.
@@ -40,11 +47,21 @@ type DevDriver struct {
 // to their "normal presentation" in the database, by creating them temporarily in
 // a "dev database", and then inspects them from there.
 func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *schema.Realm, err error) {
+	type tref struct{ s, t string }
 	var (
-		names   = make(map[string]string)
-		changes = make([]schema.Change, 0, len(r.Schemas))
-		reverse = make([]schema.Change, 0, len(r.Schemas))
-		opts    = &schema.InspectRealmOption{
+		names     = make(map[string]string)
+		unmanaged = make(map[string][]schema.Attr)
+		kept      = make(map[tref][]schema.Attr)
+		restore   = make(map[*schema.Table][]schema.Attr)
+		views     = make(map[string][]*schema.View)
+		normviews []*schema.View
+		funcs     = make(map[string][]*schema.Func)
+		procs     = make(map[string][]*schema.Proc)
+		seqs      = make(map[string][]*schema.Sequence)
+		triggers  = make(map[tref][]*schema.Trigger)
+		changes   = make([]schema.Change, 0, len(r.Schemas))
+		reverse   = make([]schema.Change, 0, len(r.Schemas))
+		opts      = &schema.InspectRealmOption{
 			Schemas: make([]string, 0, len(r.Schemas)),
 		}
 	)
@@ -58,8 +75,39 @@ func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *sc
 		opts.Schemas = append(opts.Schemas, s.Name)
 		// Skip adding the schema.IfNotExists clause
 		// to fail if the schema exists.
-		st := schema.New(dev).AddAttrs(s.Attrs...)
+		st := schema.New(dev)
+		for _, a := range s.Attrs {
+			// Unmanaged objects are kept as-is, as they
+			// are not inspected from the dev database.
+			if _, ok := a.(*schema.Unmanaged); ok || d.KeepAttr != nil && d.KeepAttr(a) {
+				unmanaged[names[dev]] = append(unmanaged[names[dev]], a)
+			} else {
+				st.AddAttrs(a)
+			}
+		}
+		// Views are kept as-is, as their definitions may reference the schema
+		// by its name, unless they are compared in their normalized form.
+		for _, v := range s.Views {
+			if viewCompareMode(v) == schema.ViewCompareNormalized {
+				if v.Schema != s {
+					v.Schema = s
+				}
+				normviews = append(normviews, v)
+			} else {
+				views[names[dev]] = append(views[names[dev]], v)
+			}
+		}
+		// Functions and procedures are not created in the
+		// dev database either, for the same reason.
+		funcs[names[dev]], procs[names[dev]] = s.Funcs, s.Procs
 		changes = append(changes, &schema.AddSchema{S: st})
+		// Sequences are created in the dev database, as they may be used by
+		// column defaults, but are kept as-is, as their options are compared
+		// using the defaults of the driver.
+		seqs[names[dev]] = s.Sequences
+		for _, sq := range s.Sequences {
+			changes = append(changes, &schema.AddSequence{S: sq})
+		}
 		reverse = append(reverse, &schema.DropSchema{S: st, Extra: append(d.DropClause, &schema.IfExists{})})
 		for _, t := range s.Tables {
 			// If objects are not strongly connected.
@@ -74,6 +122,23 @@ func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *sc
 					d.PatchColumn(s, c)
 				}
 			}
+			// Triggers are not created in the dev database, similar to views.
+			if len(t.Triggers) > 0 {
+				triggers[tref{s: names[dev], t: t.Name}] = t.Triggers
+			}
+			if d.KeepAttr != nil {
+				k, attrs := tref{s: names[dev], t: t.Name}, make([]schema.Attr, 0, len(t.Attrs))
+				for _, a := range t.Attrs {
+					if d.KeepAttr(a) {
+						kept[k] = append(kept[k], a)
+					} else {
+						attrs = append(attrs, a)
+					}
+				}
+				if len(attrs) < len(t.Attrs) {
+					restore[t], t.Attrs = t.Attrs, attrs
+				}
+			}
 			changes = append(changes, &schema.AddTable{T: t})
 		}
 	}
@@ -86,6 +151,9 @@ func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *sc
 	// the source realm to its initial state.
 	defer func() {
 		patch(r)
+		for t, attrs := range restore {
+			t.Attrs = attrs
+		}
 		if rerr := d.ApplyChanges(ctx, reverse); rerr != nil {
 			if err != nil {
 				rerr = fmt.Errorf("%w: %v", err, rerr)
@@ -93,6 +161,18 @@ func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *sc
 			err = rerr
 		}
 	}()
+	// Views that are compared in their normalized form are created after all
+	// tables, and their references to the schemas are renamed to the dev schemas.
+	for _, v := range normviews {
+		cp := *v
+		for dev, name := range names {
+			cp.Def = renameSchemaRefs(cp.Def, name, dev)
+		}
+		changes = append(changes, &schema.AddView{V: &cp})
+	}
+	if len(normviews) > 0 {
+		opts.Mode = ModeInspectRealm(opts) | schema.InspectViews
+	}
 	if err := d.ApplyChanges(ctx, changes); err != nil {
 		return nil, err
 	}
@@ -100,6 +180,55 @@ func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *sc
 		return nil, err
 	}
 	patch(nr)
+	for _, v := range normviews {
+		// Source schemas are renamed back on return.
+		s, ok := nr.Schema(names[v.Schema.Name])
+		if !ok {
+			return nil, fmt.Errorf("missing normalized schema %q for view %q", names[v.Schema.Name], v.Name)
+		}
+		nv, ok := s.View(v.Name)
+		if !ok {
+			return nil, fmt.Errorf("missing normalized view %q", v.Name)
+		}
+		for dev, name := range names {
+			nv.Def = strings.ReplaceAll(nv.Def, dev, name)
+		}
+		nv.Attrs = v.Attrs
+	}
+	for _, s := range nr.Schemas {
+		s.Attrs = append(s.Attrs, unmanaged[s.Name]...)
+		for _, v := range views[s.Name] {
+			cp := *v
+			s.AddViews(&cp)
+		}
+		for _, f := range funcs[s.Name] {
+			cp := *f
+			s.AddFuncs(&cp)
+		}
+		for _, p := range procs[s.Name] {
+			cp := *p
+			s.AddProcs(&cp)
+		}
+		for _, sq := range seqs[s.Name] {
+			cp := *sq
+			// Owners are linked to the normalized tables.
+			if o := sq.Owner; o != nil && o.T != nil && o.C != nil {
+				if t, ok := s.Table(o.T.Name); ok {
+					if c, ok := t.Column(o.C.Name); ok {
+						cp.Owner = &schema.SequenceOwner{T: t, C: c}
+					}
+				}
+			}
+			s.AddSequences(&cp)
+		}
+		for _, t := range s.Tables {
+			for _, tr := range triggers[tref{s: s.Name, t: t.Name}] {
+				cp := *tr
+				t.AddTriggers(&cp)
+			}
+			t.Attrs = append(t.Attrs, kept[tref{s: s.Name, t: t.Name}]...)
+		}
+	}
 	return nr, nil
 }
 
@@ -121,6 +250,20 @@ func (d *DevDriver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*sch
 	return ns, nil
 }
 
+// viewCompareMode returns the comparison mode of the view, if it was set.
+func viewCompareMode(v *schema.View) string {
+	var c schema.ViewCompare
+	Has(v.Attrs, &c)
+	return c.Mode
+}
+
+// renameSchemaRefs renames the qualified references to the schema
+// in the given definition (e.g. "s"."t" or `s`.`t`) to the new name.
+func renameSchemaRefs(def, from, to string) string {
+	re := regexp.MustCompile("(^|[^\\w.])([\"`\\[]?)" + regexp.QuoteMeta(from) + "([\"`\\]]?)\\.")
+	return re.ReplaceAllString(def, "${1}${2}"+to+"${3}.")
+}
+
 func (d *DevDriver) formatName(name string) string {
 	dev := fmt.Sprintf("atlas_dev_%s_%d", name, time.Now().Unix())
 	if d.MaxNameLen == 0 || len(dev) <= d.MaxNameLen {
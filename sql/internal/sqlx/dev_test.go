@@ -6,6 +6,7 @@ package sqlx
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -39,6 +40,162 @@ func TestDriver_NormalizeRealm(t *testing.T) {
 	require.Equal(t, &schema.DropSchema{S: schema.New(drv.schemas[0]), Extra: []schema.Clause{&schema.IfExists{}}}, drv.changes[1][0])
 }
 
+func TestDriver_NormalizeRealm_KeepAttr(t *testing.T) {
+	type grant struct {
+		schema.Attr
+		role string
+	}
+	var (
+		drv = &inspectDriver{mockDriver: &mockDriver{}}
+		dev = &DevDriver{
+			Driver: drv,
+			KeepAttr: func(a schema.Attr) bool {
+				_, ok := a.(*grant)
+				return ok
+			},
+		}
+		c  = &schema.Comment{Text: "users"}
+		g1 = &grant{role: "app"}
+		g2 = &grant{role: "admin"}
+		r  = schema.NewRealm(
+			schema.New("test").
+				AddAttrs(g1).
+				AddTables(schema.NewTable("users").AddAttrs(c, g2)),
+		)
+	)
+	normal, err := dev.NormalizeRealm(context.Background(), r)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{g1}, normal.Schemas[0].Attrs)
+	require.Equal(t, []schema.Attr{g2}, normal.Schemas[0].Tables[0].Attrs)
+
+	// Kept attributes are not created in the dev database.
+	require.Empty(t, drv.created.Attrs)
+	require.Equal(t, []schema.Attr{c}, drv.created.Tables[0].Attrs)
+	// The source realm is returned to its initial state.
+	require.Equal(t, "test", r.Schemas[0].Name)
+	require.Equal(t, []schema.Attr{c, g2}, r.Schemas[0].Tables[0].Attrs)
+}
+
+func TestDriver_NormalizeRealm_Sequences(t *testing.T) {
+	var (
+		drv   = &inspectDriver{mockDriver: &mockDriver{}}
+		dev   = &DevDriver{Driver: drv}
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		r     = schema.NewRealm(
+			schema.New("test").
+				AddTables(users).
+				AddSequences(
+					schema.NewSequence("counter").SetIncrement(5),
+					schema.NewSequence("users_ids").SetOwner(users, users.Columns[0]),
+				),
+		)
+	)
+	normal, err := dev.NormalizeRealm(context.Background(), r)
+	require.NoError(t, err)
+	// Sequences are created in the dev database, as they may be used by column defaults.
+	require.Len(t, drv.changes[0], 4)
+	require.Equal(t, &schema.AddSequence{S: r.Schemas[0].Sequences[0]}, drv.changes[0][1])
+	require.Equal(t, &schema.AddSequence{S: r.Schemas[0].Sequences[1]}, drv.changes[0][2])
+
+	s := normal.Schemas[0]
+	require.Len(t, s.Sequences, 2)
+	require.Equal(t, "counter", s.Sequences[0].Name)
+	require.EqualValues(t, 5, s.Sequences[0].Increment)
+	require.Equal(t, s, s.Sequences[0].Schema)
+	// Owners are linked to the normalized tables.
+	require.Equal(t, s.Tables[0], s.Sequences[1].Owner.T)
+	require.Equal(t, s.Tables[0].Columns[0], s.Sequences[1].Owner.C)
+	require.NotSame(t, users, s.Sequences[1].Owner.T)
+}
+
+func TestDriver_NormalizeRealm_Views(t *testing.T) {
+	var (
+		drv   = &viewsDriver{mockDriver: &mockDriver{}}
+		dev   = &DevDriver{Driver: drv}
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		r     = schema.NewRealm(
+			schema.New("test").
+				AddTables(users).
+				AddViews(
+					schema.NewView("kept", "SELECT id FROM test.users"),
+					schema.NewView("normal", `SELECT id FROM "test".users`).AddAttrs(&schema.ViewCompare{Mode: schema.ViewCompareNormalized}),
+				),
+		)
+	)
+	normal, err := dev.NormalizeRealm(context.Background(), r)
+	require.NoError(t, err)
+	// Only views that are compared in their normalized form are created in the dev database.
+	require.Len(t, drv.changes[0], 3)
+	add := drv.changes[0][2].(*schema.AddView)
+	require.Equal(t, "normal", add.V.Name)
+	require.Equal(t, `SELECT id FROM "`+drv.opts.Schemas[0]+`".users`, add.V.Def)
+	require.True(t, drv.opts.Mode.Is(schema.InspectViews))
+
+	s := normal.Schemas[0]
+	require.Len(t, s.Views, 2)
+	require.Equal(t, "normal", s.Views[0].Name)
+	require.Equal(t, `select "test"."users"."id" from "test"."users"`, s.Views[0].Def)
+	require.Equal(t, r.Schemas[0].Views[1].Attrs, s.Views[0].Attrs)
+	require.Equal(t, "kept", s.Views[1].Name)
+	require.Equal(t, "SELECT id FROM test.users", s.Views[1].Def)
+	// The source realm is returned to its initial state.
+	require.Equal(t, `SELECT id FROM "test".users`, r.Schemas[0].Views[1].Def)
+}
+
+func TestRenameSchemaRefs(t *testing.T) {
+	for def, expected := range map[string]string{
+		"SELECT * FROM s.t":                  "SELECT * FROM dev.t",
+		"SELECT * FROM `s`.`t`":              "SELECT * FROM `dev`.`t`",
+		`SELECT * FROM "s"."t" JOIN [s].[u]`: `SELECT * FROM "dev"."t" JOIN [dev].[u]`,
+		"SELECT ss.id, x.s.id FROM ss, x.s":  "SELECT ss.id, x.s.id FROM ss, x.s",
+	} {
+		require.Equal(t, expected, renameSchemaRefs(def, "s", "dev"), def)
+	}
+}
+
+// viewsDriver returns the created views in their normalized form.
+type viewsDriver struct {
+	*mockDriver
+	opts *schema.InspectRealmOption
+}
+
+func (d *viewsDriver) InspectRealm(_ context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	d.opts = opts
+	s := schema.New(opts.Schemas[0]).AddTables(schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")))
+	for _, c := range d.changes[0] {
+		if add, ok := c.(*schema.AddView); ok {
+			s.AddViews(schema.NewView(add.V.Name, fmt.Sprintf(`select "%[1]s"."users"."id" from "%[1]s"."users"`, s.Name)))
+		}
+	}
+	return schema.NewRealm(s), nil
+}
+
+// inspectDriver records the schema that was created in the dev database
+// and returns the inspected schemas without attributes, with their tables.
+type inspectDriver struct {
+	*mockDriver
+	created *schema.Schema
+}
+
+func (d *inspectDriver) InspectRealm(_ context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	add := d.changes[0][0].(*schema.AddSchema)
+	d.created = &schema.Schema{Attrs: add.S.Attrs}
+	for _, c := range d.changes[0][1:] {
+		add, ok := c.(*schema.AddTable)
+		if !ok {
+			continue
+		}
+		t := *add.T
+		t.Attrs = append([]schema.Attr(nil), t.Attrs...)
+		d.created.Tables = append(d.created.Tables, &t)
+	}
+	r := schema.NewRealm()
+	for _, n := range opts.Schemas {
+		r.AddSchemas(schema.New(n).AddTables(schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))))
+	}
+	return r, nil
+}
+
 type mockDriver struct {
 	migrate.Driver
 	// Inspect.
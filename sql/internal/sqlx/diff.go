@@ -7,6 +7,7 @@ package sqlx
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -66,12 +67,35 @@ type (
 	Normalizer interface {
 		Normalize(from, to *schema.Table) error
 	}
+
+	// A SequenceChanger wraps the SequenceChanged method for reporting if the options
+	// of a sequence were changed. If the DiffDriver implements the SequenceChanger
+	// interface, SchemaDiff uses it instead of comparing the sequence options as-is,
+	// as drivers usually fill the options that were not set with their defaults.
+	SequenceChanger interface {
+		SequenceChanged(from, to *schema.Sequence) bool
+	}
+
+	// A ViewChanger wraps the ViewChanged method for reporting if a view was changed.
+	// If the DiffDriver implements the ViewChanger interface, SchemaDiff uses it instead
+	// of comparing the view definitions, for drivers that also support view attributes.
+	// For example, the target table of a materialized view.
+	ViewChanger interface {
+		ViewChanged(from, to *schema.View) bool
+	}
 )
 
 // RealmDiff implements the schema.Differ for Realm objects and returns a list of changes
 // that need to be applied in order to move a database from the current state to the desired.
 func (d *Diff) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
 	var changes []schema.Change
+	// Settings are modified before the schema elements.
+	if change := SettingsDiff(from.Attrs, to.Attrs); len(change) > 0 {
+		changes = append(changes, &schema.ModifyRealm{
+			R:       to,
+			Changes: change,
+		})
+	}
 	// Drop or modify schema.
 	for _, s1 := range from.Schemas {
 		s2, ok := to.Schema(s1.Name)
@@ -91,9 +115,26 @@ func (d *Diff) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
 			continue
 		}
 		changes = append(changes, &schema.AddSchema{S: s1})
+		for _, sq := range s1.Sequences {
+			changes = append(changes, &schema.AddSequence{S: sq})
+		}
 		for _, t := range s1.Tables {
 			changes = append(changes, &schema.AddTable{T: t})
 		}
+		for _, v := range s1.Views {
+			changes = append(changes, &schema.AddView{V: v})
+		}
+		for _, f := range s1.Funcs {
+			changes = append(changes, &schema.AddFunc{F: f})
+		}
+		for _, p := range s1.Procs {
+			changes = append(changes, &schema.AddProc{P: p})
+		}
+		for _, t := range s1.Tables {
+			for _, tr := range t.Triggers {
+				changes = append(changes, &schema.AddTrigger{T: tr})
+			}
+		}
 	}
 	return changes, nil
 }
@@ -105,14 +146,40 @@ func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
 		return nil, fmt.Errorf("mismatched schema names: %q != %q", from.Name, to.Name)
 	}
 	var changes []schema.Change
-	// Drop or modify attributes (collations, charset, etc).
-	if change := d.SchemaAttrDiff(from, to); len(change) > 0 {
+	// Drop or modify attributes (collations, charset, etc),
+	// and create or replace the unmanaged objects.
+	if change := append(d.SchemaAttrDiff(from, to), UnmanagedDiff(from.Attrs, to.Attrs)...); len(change) > 0 {
 		changes = append(changes, &schema.ModifySchema{
 			S:       to,
 			Changes: change,
 		})
 	}
 
+	// Views are dropped before the tables they may depend on.
+	for _, v1 := range from.Views {
+		if _, ok := to.View(v1.Name); !ok {
+			changes = append(changes, &schema.DropView{V: v1})
+		}
+	}
+	// Functions and procedures are matched by their names and arguments, as
+	// they can be overloaded in some databases. Changing the arguments of a
+	// routine means dropping it and creating a new one.
+	for _, f1 := range from.Funcs {
+		if _, ok := findFunc(to.Funcs, f1); !ok {
+			changes = append(changes, &schema.DropFunc{F: f1})
+		}
+	}
+	for _, p1 := range from.Procs {
+		if _, ok := findProc(to.Procs, p1); !ok {
+			changes = append(changes, &schema.DropProc{P: p1})
+		}
+	}
+	for _, s1 := range from.Sequences {
+		if _, ok := to.Sequence(s1.Name); !ok {
+			changes = append(changes, &schema.DropSequence{S: s1})
+		}
+	}
+
 	// Drop or modify tables.
 	for _, t1 := range from.Tables {
 		t2, ok := to.Table(t1.Name)
@@ -137,9 +204,148 @@ func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
 			changes = append(changes, &schema.AddTable{T: t1})
 		}
 	}
+	// Add or modify sequences.
+	for _, s2 := range to.Sequences {
+		switch s1, ok := from.Sequence(s2.Name); {
+		case !ok:
+			changes = append(changes, &schema.AddSequence{S: s2})
+		case d.sequenceChanged(s1, s2):
+			changes = append(changes, &schema.ModifySequence{From: s1, To: s2})
+		}
+	}
+	// Add or modify views.
+	for _, v2 := range to.Views {
+		switch v1, ok := from.View(v2.Name); {
+		case !ok:
+			changes = append(changes, &schema.AddView{V: v2})
+		case d.viewChanged(v1, v2):
+			changes = append(changes, &schema.ModifyView{From: v1, To: v2})
+		}
+	}
+	// Add or modify functions and procedures.
+	for _, f2 := range to.Funcs {
+		switch f1, ok := findFunc(from.Funcs, f2); {
+		case !ok:
+			changes = append(changes, &schema.AddFunc{F: f2})
+		case ViewDef(f1.Ret) != ViewDef(f2.Ret) || !strings.EqualFold(f1.Lang, f2.Lang) || ViewDef(f1.Body) != ViewDef(f2.Body):
+			changes = append(changes, &schema.ModifyFunc{From: f1, To: f2})
+		}
+	}
+	for _, p2 := range to.Procs {
+		switch p1, ok := findProc(from.Procs, p2); {
+		case !ok:
+			changes = append(changes, &schema.AddProc{P: p2})
+		case !strings.EqualFold(p1.Lang, p2.Lang) || ViewDef(p1.Body) != ViewDef(p2.Body):
+			changes = append(changes, &schema.ModifyProc{From: p1, To: p2})
+		}
+	}
+	// Triggers of dropped tables are dropped along with them.
+	for _, t2 := range to.Tables {
+		t1, ok := from.Table(t2.Name)
+		if !ok {
+			t1 = &schema.Table{}
+		}
+		for _, tr1 := range t1.Triggers {
+			if _, ok := t2.Trigger(tr1.Name); !ok {
+				changes = append(changes, &schema.DropTrigger{T: tr1})
+			}
+		}
+		for _, tr2 := range t2.Triggers {
+			switch tr1, ok := t1.Trigger(tr2.Name); {
+			case !ok:
+				changes = append(changes, &schema.AddTrigger{T: tr2})
+			case triggerChanged(tr1, tr2):
+				changes = append(changes, &schema.ModifyTrigger{From: tr1, To: tr2})
+			}
+		}
+	}
 	return changes, nil
 }
 
+// triggerChanged reports if the definition of the trigger was changed.
+// Definitions are compared in their normalized forms.
+func triggerChanged(from, to *schema.Trigger) bool {
+	events := func(t *schema.Trigger) string {
+		return strings.ToUpper(ViewDef(strings.Join(t.Events, ",")))
+	}
+	return !strings.EqualFold(ViewDef(from.ActionTime), ViewDef(to.ActionTime)) ||
+		events(from) != events(to) ||
+		from.ForEachRow != to.ForEachRow ||
+		ViewDef(from.When) != ViewDef(to.When) ||
+		ViewDef(from.Body) != ViewDef(to.Body)
+}
+
+// findFunc returns the function that matches the name and the arguments of f.
+func findFunc(funcs []*schema.Func, f *schema.Func) (*schema.Func, bool) {
+	for _, f2 := range funcs {
+		if f2.Name == f.Name && strings.EqualFold(ViewDef(f2.Args), ViewDef(f.Args)) {
+			return f2, true
+		}
+	}
+	return nil, false
+}
+
+// viewChanged reports if the view was changed. Views are compared using
+// the comparison mode of the desired view, or the inspected one, if set.
+func (d *Diff) viewChanged(from, to *schema.View) bool {
+	var c schema.ViewCompare
+	if !Has(to.Attrs, &c) {
+		Has(from.Attrs, &c)
+	}
+	switch c.Mode {
+	case schema.ViewCompareIgnore:
+		return false
+	case schema.ViewCompareExact:
+		return from.Def != to.Def
+	}
+	if c, ok := d.DiffDriver.(ViewChanger); ok {
+		return c.ViewChanged(from, to)
+	}
+	return ViewDef(from.Def) != ViewDef(to.Def)
+}
+
+// sequenceChanged reports if the options of the sequence were changed.
+func (d *Diff) sequenceChanged(from, to *schema.Sequence) bool {
+	if c, ok := d.DiffDriver.(SequenceChanger); ok {
+		return c.SequenceChanged(from, to)
+	}
+	int64Changed := func(v1, v2 *int64) bool {
+		return (v1 == nil) != (v2 == nil) || v1 != nil && *v1 != *v2
+	}
+	return !strings.EqualFold(from.Type, to.Type) || from.Start != to.Start || from.Increment != to.Increment ||
+		int64Changed(from.Min, to.Min) || int64Changed(from.Max, to.Max) || from.Cache != to.Cache ||
+		from.Cycle != to.Cycle || SequenceOwnerChanged(from.Owner, to.Owner)
+}
+
+// SequenceOwnerChanged reports if the owner of the sequence was changed.
+// Owners are compared by the names of their tables and columns.
+func SequenceOwnerChanged(from, to *schema.SequenceOwner) bool {
+	switch {
+	case from == nil || from.T == nil || from.C == nil:
+		return to != nil && to.T != nil && to.C != nil
+	case to == nil || to.T == nil || to.C == nil:
+		return true
+	default:
+		return from.T.Name != to.T.Name || from.C.Name != to.C.Name
+	}
+}
+
+// findProc returns the procedure that matches the name and the arguments of p.
+func findProc(procs []*schema.Proc, p *schema.Proc) (*schema.Proc, bool) {
+	for _, p2 := range procs {
+		if p2.Name == p.Name && strings.EqualFold(ViewDef(p2.Args), ViewDef(p.Args)) {
+			return p2, true
+		}
+	}
+	return nil, false
+}
+
+// ViewDef returns the normalized form of a view definition, used for comparing
+// definitions. Whitespace is collapsed, and trailing semicolons are removed.
+func ViewDef(def string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(def), " "), "; ")
+}
+
 // TableDiff implements the schema.TableDiffer interface and returns a list of
 // changes that need to be applied in order to move from one state to the other.
 func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
@@ -407,6 +613,94 @@ func (e UnsupportedTypeError) Error() string {
 	return fmt.Sprintf("unsupported type %T", e.Type)
 }
 
+// SettingsDiff computes the diff of the database-level settings between the
+// 2 attribute lists. Settings are managed only if they are declared in the
+// desired state, and therefore, settings that exist only in the current
+// state are ignored.
+func SettingsDiff(from, to []schema.Attr) []schema.Change {
+	var changes []schema.Change
+	for _, a := range to {
+		s2, ok := a.(*schema.Setting)
+		if !ok {
+			continue
+		}
+		s1, ok := setting(from, s2.Name)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.AddAttr{A: s2})
+		case s1.V != s2.V:
+			changes = append(changes, &schema.ModifyAttr{From: s1, To: s2})
+		}
+	}
+	return changes
+}
+
+// reSettingName matches valid setting names. e.g. "sql_mode" or "app.tenant".
+var reSettingName = regexp.MustCompile(`^[A-Za-z_][\w.]*$`)
+
+// SettingChange returns the setting that is set by the given nested change of
+// a ModifyRealm, and its previous value, if it was set before.
+func SettingChange(c schema.Change) (to, from *schema.Setting, err error) {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		to, _ = c.A.(*schema.Setting)
+	case *schema.ModifyAttr:
+		to, _ = c.To.(*schema.Setting)
+		if from, _ = c.From.(*schema.Setting); from == nil {
+			to = nil
+		}
+	}
+	switch {
+	case to == nil:
+		return nil, nil, fmt.Errorf("unsupported ModifyRealm change %T", c)
+	case !reSettingName.MatchString(to.Name):
+		return nil, nil, fmt.Errorf("invalid setting name %q", to.Name)
+	}
+	return to, from, nil
+}
+
+// setting returns the setting with the given name from the attribute list.
+func setting(attrs []schema.Attr, name string) (*schema.Setting, bool) {
+	for _, a := range attrs {
+		if s, ok := a.(*schema.Setting); ok && strings.EqualFold(s.Name, name) {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// UnmanagedDiff computes the diff of the unmanaged objects between the 2
+// attribute lists. Objects are created if they are missing, and replaced
+// if their fingerprints are different. Objects that exist only in the
+// current state are not dropped, as they are not managed by Atlas.
+func UnmanagedDiff(from, to []schema.Attr) []schema.Change {
+	var changes []schema.Change
+	for _, a := range to {
+		u2, ok := a.(*schema.Unmanaged)
+		if !ok {
+			continue
+		}
+		u1, ok := unmanaged(from, u2)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.AddAttr{A: u2})
+		case u1.Fingerprint != u2.Fingerprint:
+			changes = append(changes, &schema.ModifyAttr{From: u1, To: u2})
+		}
+	}
+	return changes
+}
+
+// unmanaged returns the unmanaged object that matches u from the attribute list.
+func unmanaged(attrs []schema.Attr, u *schema.Unmanaged) (*schema.Unmanaged, bool) {
+	for _, a := range attrs {
+		if u1, ok := a.(*schema.Unmanaged); ok && strings.EqualFold(u1.Type, u.Type) && u1.Name == u.Name {
+			return u1, true
+		}
+	}
+	return nil, false
+}
+
 // CommentDiff computes the comment diff between the 2 attribute list.
 // Note that, the implementation relies on the fact that both PostgreSQL
 // and MySQL treat empty comment as "no comment" and a way to clear comments.
@@ -440,6 +734,33 @@ func CommentDiff(from, to []schema.Attr) schema.Change {
 	return nil
 }
 
+// RetentionDiff computes the retention policy diff between the 2 attribute lists.
+func RetentionDiff(from, to []schema.Attr) schema.Change {
+	var fromR, toR schema.Retention
+	switch fromHas, toHas := Has(from, &fromR), Has(to, &toR); {
+	case !fromHas && !toHas:
+	case !fromHas:
+		return &schema.AddAttr{A: &toR}
+	case !toHas:
+		return &schema.DropAttr{A: &fromR}
+	case !RetentionEqual(&fromR, &toR):
+		return &schema.ModifyAttr{From: &fromR, To: &toR}
+	}
+	return nil
+}
+
+// RetentionEqual reports if the two retention policies are equal.
+func RetentionEqual(r1, r2 *schema.Retention) bool {
+	var c1, c2 string
+	if r1.C != nil {
+		c1 = r1.C.Name
+	}
+	if r2.C != nil {
+		c2 = r2.C.Name
+	}
+	return c1 == c2 && r1.Period == r2.Period && strings.EqualFold(r1.Unit, r2.Unit)
+}
+
 // CheckDiff computes the change diff between the 2 tables. A compare
 // function is provided to check if a Check object was modified.
 func CheckDiff(from, to *schema.Table, compare ...func(c1, c2 *schema.Check) bool) []schema.Change {
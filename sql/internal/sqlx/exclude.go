@@ -109,6 +109,38 @@ func excludeS(s *schema.Schema, glob []string) error {
 		tables = append(tables, t)
 	}
 	s.Tables = tables
+	// Views, functions, procedures and sequences are matched like
+	// tables, but have no child resources.
+	if len(glob) == 1 {
+		views, err := filter(s.Views, func(v *schema.View) (bool, error) {
+			return filepath.Match(glob[0], v.Name)
+		})
+		if err != nil {
+			return err
+		}
+		s.Views = views
+		funcs, err := filter(s.Funcs, func(f *schema.Func) (bool, error) {
+			return filepath.Match(glob[0], f.Name)
+		})
+		if err != nil {
+			return err
+		}
+		s.Funcs = funcs
+		procs, err := filter(s.Procs, func(p *schema.Proc) (bool, error) {
+			return filepath.Match(glob[0], p.Name)
+		})
+		if err != nil {
+			return err
+		}
+		s.Procs = procs
+		seqs, err := filter(s.Sequences, func(sq *schema.Sequence) (bool, error) {
+			return filepath.Match(glob[0], sq.Name)
+		})
+		if err != nil {
+			return err
+		}
+		s.Sequences = seqs
+	}
 	return nil
 }
 
@@ -140,6 +172,12 @@ func excludeT(t *schema.Table, pattern string) (err error) {
 		}
 		return filepath.Match(pattern, fk.Symbol)
 	})
+	if err != nil {
+		return err
+	}
+	t.Triggers, err = filter(t.Triggers, func(tr *schema.Trigger) (bool, error) {
+		return filepath.Match(pattern, tr.Name)
+	})
 	return
 }
 
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
@@ -37,6 +38,120 @@ func ApplyChanges(ctx context.Context, changes []schema.Change, p execPlanner, o
 	return nil
 }
 
+// unmanagedOrder defines the order in which unmanaged objects are created,
+// as views may use functions, and triggers may execute them. Objects of
+// other types are created after the views.
+var unmanagedOrder = map[string]int{
+	"FUNCTION":          1,
+	"PROCEDURE":         1,
+	"VIEW":              2,
+	"MATERIALIZED VIEW": 3,
+	"TRIGGER":           4,
+}
+
+// UnmanagedChanges splits the unmanaged objects changes from the other changes
+// of a ModifySchema (or the attributes of an AddSchema), and returns the raw
+// statements for creating or replacing them. Since unmanaged objects may depend
+// on tables (e.g. views or triggers), callers should plan the returned statements
+// after all other changes.
+func UnmanagedChanges(c schema.Change) ([]schema.Change, []*migrate.Change, error) {
+	var rest, changes []schema.Change
+	switch c := c.(type) {
+	case *schema.AddSchema:
+		for _, a := range c.S.Attrs {
+			if u, ok := a.(*schema.Unmanaged); ok {
+				changes = append(changes, &schema.AddAttr{A: u})
+			}
+		}
+	case *schema.ModifySchema:
+		for _, c := range c.Changes {
+			switch c := c.(type) {
+			case *schema.AddAttr:
+				if _, ok := c.A.(*schema.Unmanaged); ok {
+					changes = append(changes, c)
+					continue
+				}
+			case *schema.ModifyAttr:
+				if _, ok := c.To.(*schema.Unmanaged); ok {
+					changes = append(changes, c)
+					continue
+				}
+			}
+			rest = append(rest, c)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unexpected change %T", c)
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return unmanagedRank(changes[i]) < unmanagedRank(changes[j])
+	})
+	planned := make([]*migrate.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddAttr:
+			u := c.A.(*schema.Unmanaged)
+			planned = append(planned, &migrate.Change{
+				Cmd:     u.Create,
+				Reverse: u.Drop,
+				Comment: fmt.Sprintf("create %s %q", strings.ToLower(u.Type), u.Name),
+			})
+		case *schema.ModifyAttr:
+			from, ok := c.From.(*schema.Unmanaged)
+			to := c.To.(*schema.Unmanaged)
+			switch {
+			case !ok:
+				return nil, nil, fmt.Errorf("mismatch ModifyAttr attributes: %T != %T", c.To, c.From)
+			case from.Drop == "":
+				return nil, nil, fmt.Errorf("missing DROP statement for replacing %s %q", strings.ToLower(from.Type), from.Name)
+			}
+			planned = append(planned, &migrate.Change{
+				Cmd:     from.Drop,
+				Reverse: from.Create,
+				Comment: fmt.Sprintf("drop %s %q", strings.ToLower(from.Type), from.Name),
+			}, &migrate.Change{
+				Cmd:     to.Create,
+				Reverse: to.Drop,
+				Comment: fmt.Sprintf("create %s %q", strings.ToLower(to.Type), to.Name),
+			})
+		}
+	}
+	return rest, planned, nil
+}
+
+// onlyUnmanaged reports if all changes are unmanaged object changes.
+func onlyUnmanaged(changes []schema.Change) bool {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddAttr:
+			if _, ok := c.A.(*schema.Unmanaged); !ok {
+				return false
+			}
+		case *schema.ModifyAttr:
+			if _, ok := c.To.(*schema.Unmanaged); !ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return len(changes) > 0
+}
+
+// unmanagedRank returns the creation rank of the changed unmanaged object.
+func unmanagedRank(c schema.Change) int {
+	var u *schema.Unmanaged
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		u = c.A.(*schema.Unmanaged)
+	case *schema.ModifyAttr:
+		u = c.To.(*schema.Unmanaged)
+	}
+	if r, ok := unmanagedOrder[strings.ToUpper(u.Type)]; ok {
+		return r
+	}
+	return unmanagedOrder["VIEW"]
+}
+
 // DetachCycles takes a list of schema changes, and detaches
 // references between changes if there is at least one circular
 // reference in the changeset. More explicitly, it postpones fks
@@ -57,6 +172,99 @@ func DetachCycles(changes []schema.Change) ([]schema.Change, error) {
 	return planned, nil
 }
 
+// SplitViews splits the view changes from the other changes. Since views may depend
+// on tables, callers should plan the dropped views before all other changes, and the
+// created or modified views after them.
+func SplitViews(changes []schema.Change) (drop, rest, add []schema.Change) {
+	for _, c := range changes {
+		switch c.(type) {
+		case *schema.DropView:
+			drop = append(drop, c)
+		case *schema.AddView, *schema.ModifyView:
+			add = append(add, c)
+		default:
+			rest = append(rest, c)
+		}
+	}
+	return drop, rest, add
+}
+
+// SplitFuncs splits the function and procedure changes from the other changes. Like
+// views, callers should plan the dropped routines before all other changes, and the
+// created or modified routines after them.
+func SplitFuncs(changes []schema.Change) (drop, rest, add []schema.Change) {
+	for _, c := range changes {
+		switch c.(type) {
+		case *schema.DropFunc, *schema.DropProc:
+			drop = append(drop, c)
+		case *schema.AddFunc, *schema.ModifyFunc, *schema.AddProc, *schema.ModifyProc:
+			add = append(add, c)
+		default:
+			rest = append(rest, c)
+		}
+	}
+	return drop, rest, add
+}
+
+// SplitSequences splits the sequence changes from the other changes. Since column
+// defaults may use sequences, and sequences may be owned by table columns, callers
+// should plan the created sequences before the table changes, and the dropped or
+// modified sequences after them.
+func SplitSequences(changes []schema.Change) (add, rest, after []schema.Change) {
+	for _, c := range changes {
+		switch c.(type) {
+		case *schema.AddSequence:
+			add = append(add, c)
+		case *schema.DropSequence, *schema.ModifySequence:
+			after = append(after, c)
+		default:
+			rest = append(rest, c)
+		}
+	}
+	return add, rest, after
+}
+
+// SplitTriggers splits the trigger changes from the other changes. Since triggers
+// depend on their tables, and their bodies may reference other tables or views,
+// callers should plan the dropped triggers before all other changes, and the
+// created triggers after them. Modified triggers are returned in both lists, as
+// they are dropped and created again.
+func SplitTriggers(changes []schema.Change) (drop, rest, add []schema.Change) {
+	for _, c := range changes {
+		switch c.(type) {
+		case *schema.DropTrigger:
+			drop = append(drop, c)
+		case *schema.AddTrigger:
+			add = append(add, c)
+		case *schema.ModifyTrigger:
+			drop = append(drop, c)
+			add = append(add, c)
+		default:
+			rest = append(rest, c)
+		}
+	}
+	return drop, rest, add
+}
+
+// SortChanges sorts the given changes by the references between their tables,
+// such that referenced tables are created before the tables that reference them.
+// In case of a circular reference, the changes are returned in their given order.
+func SortChanges(changes []schema.Change) ([]schema.Change, error) {
+	sorted, err := sortMap(changes)
+	if err == errCycle {
+		return changes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	planned := make([]schema.Change, len(changes))
+	copy(planned, changes)
+	sort.SliceStable(planned, func(i, j int) bool {
+		return sorted[table(planned[i])] < sorted[table(planned[j])]
+	})
+	return planned, nil
+}
+
 // detachReferences detaches all table references.
 func detachReferences(changes []schema.Change) []schema.Change {
 	var planned, deferred []schema.Change
@@ -256,7 +464,14 @@ func CheckChangesScope(changes []schema.Change) error {
 	for _, c := range changes {
 		var t *schema.Table
 		switch c := c.(type) {
-		case *schema.AddSchema, *schema.ModifySchema, *schema.DropSchema:
+		case *schema.ModifySchema:
+			// Unmanaged objects are created using their raw
+			// statements, and therefore, are allowed in scope.
+			if onlyUnmanaged(c.Changes) {
+				continue
+			}
+			return fmt.Errorf("%T is not allowed when migration plan is scoped to one schema", c)
+		case *schema.AddSchema, *schema.DropSchema:
 			return fmt.Errorf("%T is not allowed when migration plan is scoped to one schema", c)
 		case *schema.AddTable:
 			t = c.T
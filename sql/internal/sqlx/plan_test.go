@@ -7,6 +7,7 @@ package sqlx
 import (
 	"testing"
 
+	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/stretchr/testify/require"
@@ -93,6 +94,23 @@ func TestDetachCycles(t *testing.T) {
 	require.Equal(t, deletion, planned[2:])
 }
 
+func TestSortChanges(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	posts := schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("author_id", "int"))
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	changes := []schema.Change{&schema.AddTable{T: posts}, &schema.AddTable{T: users}}
+	sorted, err := SortChanges(changes)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{changes[1], changes[0]}, sorted)
+
+	// Circular references are kept in their order.
+	users.AddColumns(schema.NewIntColumn("post_id", "int"))
+	users.AddForeignKeys(schema.NewForeignKey("post").AddColumns(users.Columns[1]).SetRefTable(posts).AddRefColumns(posts.Columns[0]))
+	sorted, err = SortChanges(changes)
+	require.NoError(t, err)
+	require.Equal(t, changes, sorted)
+}
+
 func TestCheckChangesScope(t *testing.T) {
 	err := CheckChangesScope([]schema.Change{
 		&schema.AddSchema{},
@@ -102,6 +120,10 @@ func TestCheckChangesScope(t *testing.T) {
 		&schema.ModifySchema{},
 	})
 	require.EqualError(t, err, "*schema.ModifySchema is not allowed when migration plan is scoped to one schema")
+	err = CheckChangesScope([]schema.Change{
+		&schema.ModifySchema{Changes: []schema.Change{&schema.AddAttr{A: schema.NewUnmanaged("view", "v", "CREATE VIEW v AS SELECT 1")}}},
+	})
+	require.NoError(t, err, "unmanaged objects are allowed in schema scope")
 	err = CheckChangesScope([]schema.Change{
 		&schema.DropSchema{},
 	})
@@ -112,3 +134,56 @@ func TestCheckChangesScope(t *testing.T) {
 	})
 	require.EqualError(t, err, "found 2 schemas when migration plan is scoped to one")
 }
+
+func TestUnmanagedDiff(t *testing.T) {
+	var (
+		v1 = schema.NewUnmanaged("view", "v", "CREATE VIEW v AS SELECT 1").SetDrop("DROP VIEW v")
+		v2 = schema.NewUnmanaged("VIEW", "v", "CREATE VIEW v AS  SELECT 1")
+		v3 = schema.NewUnmanaged("VIEW", "v", "CREATE VIEW v AS SELECT 2")
+		tr = schema.NewUnmanaged("TRIGGER", "t", "CREATE TRIGGER t AFTER INSERT ON t1 BEGIN SELECT 1; END")
+	)
+	require.Equal(t, "VIEW", v1.Type)
+	require.Equal(t, v1.Fingerprint, v2.Fingerprint, "whitespace does not affect the fingerprint")
+	require.Empty(t, UnmanagedDiff([]schema.Attr{v1}, []schema.Attr{v2}))
+	require.Empty(t, UnmanagedDiff([]schema.Attr{v1, tr}, nil), "unmanaged objects are never dropped")
+	require.Equal(t, []schema.Change{
+		&schema.AddAttr{A: tr},
+		&schema.ModifyAttr{From: v1, To: v3},
+	}, UnmanagedDiff([]schema.Attr{v1}, []schema.Attr{tr, v3}))
+}
+
+func TestUnmanagedChanges(t *testing.T) {
+	var (
+		fn = schema.NewUnmanaged("FUNCTION", "f()", "CREATE FUNCTION f() ...").SetDrop("DROP FUNCTION f()")
+		v1 = schema.NewUnmanaged("VIEW", "v", "CREATE VIEW v AS SELECT 1").SetDrop("DROP VIEW v")
+		v2 = schema.NewUnmanaged("VIEW", "v", "CREATE VIEW v AS SELECT 2").SetDrop("DROP VIEW v")
+		tr = schema.NewUnmanaged("TRIGGER", "t", "CREATE TRIGGER t ...")
+	)
+	rest, planned, err := UnmanagedChanges(&schema.AddSchema{S: schema.New("s").AddAttrs(&schema.Charset{V: "utf8"}, tr, v1, fn)})
+	require.NoError(t, err)
+	require.Empty(t, rest)
+	require.Equal(t, []string{"CREATE FUNCTION f() ...", "CREATE VIEW v AS SELECT 1", "CREATE TRIGGER t ..."}, cmds(planned))
+
+	charset := &schema.ModifyAttr{From: &schema.Charset{V: "latin1"}, To: &schema.Charset{V: "utf8"}}
+	rest, planned, err = UnmanagedChanges(&schema.ModifySchema{Changes: []schema.Change{
+		&schema.ModifyAttr{From: v1, To: v2},
+		charset,
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{charset}, rest)
+	require.Equal(t, []string{"DROP VIEW v", "CREATE VIEW v AS SELECT 2"}, cmds(planned))
+	require.Equal(t, "CREATE VIEW v AS SELECT 1", planned[0].Reverse)
+
+	_, _, err = UnmanagedChanges(&schema.ModifySchema{Changes: []schema.Change{
+		&schema.ModifyAttr{From: tr, To: schema.NewUnmanaged("TRIGGER", "t", "CREATE TRIGGER t2 ...")},
+	}})
+	require.EqualError(t, err, `missing DROP statement for replacing trigger "t"`)
+}
+
+func cmds(changes []*migrate.Change) []string {
+	s := make([]string, len(changes))
+	for i := range changes {
+		s[i] = changes[i].Cmd
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"fmt"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// PrivAction describes an action on a database object, performed when
+	// applying a change, that requires privileges. Drivers map the actions
+	// to the privileges (or ownership) required by their database.
+	PrivAction struct {
+		Kind   PrivActionKind
+		Schema string // Schema of the object. Empty for the connected schema.
+		Table  string // Table of the object. Empty for schema-level actions.
+		Reason string // Description of the change that performs the action.
+	}
+
+	// PrivActionKind describes the kind of PrivAction.
+	PrivActionKind uint
+)
+
+// List of privilege action kinds.
+const (
+	PrivCreateSchema PrivActionKind = iota // Create the schema.
+	PrivDropSchema                         // Drop the schema.
+	PrivAlterSchema                        // Alter the schema attributes.
+	PrivCreateTable                        // Create a table in the schema.
+	PrivDropTable                          // Drop the table.
+	PrivAlterTable                         // Alter the table (e.g. columns and checks).
+	PrivIndex                              // Create or drop indexes of the table.
+	PrivReferences                         // Reference the table in a foreign key.
+	PrivRenameFrom                         // Rename the table.
+	PrivRenameTo                           // Create the table by renaming another one.
+)
+
+// PrivActions returns the actions performed by the given changes that require privileges.
+func PrivActions(changes []schema.Change) []*PrivAction {
+	var acts []*PrivAction
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			acts = append(acts, &PrivAction{Kind: PrivCreateSchema, Schema: c.S.Name, Reason: fmt.Sprintf("create schema %q", c.S.Name)})
+		case *schema.DropSchema:
+			acts = append(acts, &PrivAction{Kind: PrivDropSchema, Schema: c.S.Name, Reason: fmt.Sprintf("drop schema %q", c.S.Name)})
+		case *schema.ModifySchema:
+			acts = append(acts, &PrivAction{Kind: PrivAlterSchema, Schema: c.S.Name, Reason: fmt.Sprintf("modify schema %q", c.S.Name)})
+		case *schema.AddTable:
+			reason := fmt.Sprintf("create table %q", c.T.Name)
+			acts = append(acts, &PrivAction{Kind: PrivCreateTable, Schema: schemaName(c.T), Reason: reason})
+			for _, fk := range c.T.ForeignKeys {
+				acts = references(acts, fk, reason)
+			}
+		case *schema.DropTable:
+			acts = append(acts, &PrivAction{Kind: PrivDropTable, Schema: schemaName(c.T), Table: c.T.Name, Reason: fmt.Sprintf("drop table %q", c.T.Name)})
+		case *schema.RenameTable:
+			reason := fmt.Sprintf("rename table %q to %q", c.From.Name, c.To.Name)
+			acts = append(acts,
+				&PrivAction{Kind: PrivRenameFrom, Schema: schemaName(c.From), Table: c.From.Name, Reason: reason},
+				&PrivAction{Kind: PrivRenameTo, Schema: schemaName(c.To), Table: c.To.Name, Reason: reason},
+			)
+		case *schema.ModifyTable:
+			acts = append(acts, modifyActions(c)...)
+		}
+	}
+	return acts
+}
+
+// modifyActions returns the actions performed by the table modification.
+func modifyActions(m *schema.ModifyTable) []*PrivAction {
+	var (
+		alter bool
+		acts  []*PrivAction
+		s     = schemaName(m.T)
+	)
+	for _, c := range m.Changes {
+		switch c := c.(type) {
+		case *schema.AddIndex:
+			acts = append(acts, &PrivAction{Kind: PrivIndex, Schema: s, Table: m.T.Name, Reason: fmt.Sprintf("add index %q to table %q", c.I.Name, m.T.Name)})
+		case *schema.DropIndex:
+			acts = append(acts, &PrivAction{Kind: PrivIndex, Schema: s, Table: m.T.Name, Reason: fmt.Sprintf("drop index %q of table %q", c.I.Name, m.T.Name)})
+		case *schema.ModifyIndex:
+			acts = append(acts, &PrivAction{Kind: PrivIndex, Schema: s, Table: m.T.Name, Reason: fmt.Sprintf("modify index %q of table %q", c.To.Name, m.T.Name)})
+		case *schema.AddForeignKey:
+			alter = true
+			acts = references(acts, c.F, fmt.Sprintf("add foreign key %q to table %q", c.F.Symbol, m.T.Name))
+		case *schema.ModifyForeignKey:
+			alter = true
+			acts = references(acts, c.To, fmt.Sprintf("modify foreign key %q of table %q", c.To.Symbol, m.T.Name))
+		default:
+			alter = true
+		}
+	}
+	if alter {
+		acts = append([]*PrivAction{{Kind: PrivAlterTable, Schema: s, Table: m.T.Name, Reason: fmt.Sprintf("modify table %q", m.T.Name)}}, acts...)
+	}
+	return acts
+}
+
+// references appends the action of referencing the parent table of the foreign key.
+// Self-references do not require privileges other than the ones on the table itself.
+func references(acts []*PrivAction, fk *schema.ForeignKey, reason string) []*PrivAction {
+	if fk.RefTable == nil || fk.RefTable == fk.Table {
+		return acts
+	}
+	return append(acts, &PrivAction{Kind: PrivReferences, Schema: schemaName(fk.RefTable), Table: fk.RefTable.Name, Reason: reason})
+}
+
+func schemaName(t *schema.Table) string {
+	if t != nil && t.Schema != nil {
+		return t.Schema.Name
+	}
+	return ""
+}
@@ -14,6 +14,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 
 	"ariga.io/atlas/sql/schema"
 )
@@ -28,11 +29,28 @@ type (
 	nopCloser struct {
 		schema.ExecQuerier
 	}
+	noRows struct{}
 )
 
 // Close implements the io.Closer interface.
 func (nopCloser) Close() error { return nil }
 
+// NoRows is an ExecQuerier that is not connected to a database, and fails all
+// statements with sql.ErrNoRows. It is used by drivers for diffing and planning
+// changes without a database connection, in which case nothing is assumed to
+// exist in the database.
+var NoRows schema.ExecQuerier = noRows{}
+
+// ExecContext implements the schema.ExecQuerier interface.
+func (noRows) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, sql.ErrNoRows
+}
+
+// QueryContext implements the schema.ExecQuerier interface.
+func (noRows) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, sql.ErrNoRows
+}
+
 // SingleConn returns a closable single connection from the given ExecQuerier.
 // If the ExecQuerier is already bound to a single connection (e.g. Tx, Conn),
 // the connection will return as-is with a NopCloser.
@@ -149,6 +167,84 @@ func SchemaFKs(s *schema.Schema, rows *sql.Rows) error {
 	return nil
 }
 
+// LinkTriggers links the given triggers to their tables. Triggers may be inspected
+// before their tables, and therefore, they are created with stub tables that hold
+// the table name and schema. Triggers of tables that were not inspected are skipped.
+func LinkTriggers(triggers []*schema.Trigger) {
+	for _, tr := range triggers {
+		if tr.Table == nil || tr.Table.Schema == nil {
+			continue
+		}
+		if t, ok := tr.Table.Schema.Table(tr.Table.Name); ok {
+			t.AddTriggers(tr)
+		}
+	}
+}
+
+// InspectSchemas calls fn for each of the given schemas that contain tables. If workers is greater
+// than 1 and conn is a connection pool, schemas are inspected concurrently by up to workers goroutines.
+// Otherwise, they are inspected serially. fn must modify only the schema it was called with. The first
+// error cancels the inspection of the rest of the schemas, and it is returned.
+func InspectSchemas(ctx context.Context, conn schema.ExecQuerier, workers int, schemas []*schema.Schema, fn func(context.Context, *schema.Schema) error) error {
+	pending := make([]*schema.Schema, 0, len(schemas))
+	for _, s := range schemas {
+		if len(s.Tables) > 0 {
+			pending = append(pending, s)
+		}
+	}
+	// Transactions and single connections cannot execute queries concurrently.
+	if _, ok := conn.(*sql.DB); !ok || workers <= 1 || len(pending) <= 1 {
+		for _, s := range pending {
+			if err := fn(ctx, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if workers > len(pending) {
+		workers = len(pending)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		err  error
+		once sync.Once
+		wg   sync.WaitGroup
+		ch   = make(chan *schema.Schema)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range ch {
+				if ctx.Err() != nil {
+					continue
+				}
+				if fnErr := fn(ctx, s); fnErr != nil {
+					once.Do(func() {
+						err = fnErr
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+send:
+	for _, s := range pending {
+		select {
+		case ch <- s:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(ch)
+	wg.Wait()
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
 // LinkSchemaTables links foreign-key stub tables/columns to actual elements.
 func LinkSchemaTables(schemas []*schema.Schema) {
 	byName := make(map[string]map[string]*schema.Table)
@@ -214,7 +310,7 @@ func ModeInspectRealm(o *schema.InspectRealmOption) schema.InspectMode {
 // A Builder provides a syntactic sugar API for writing SQL statements.
 type Builder struct {
 	bytes.Buffer
-	QuoteChar byte    // quoting identifiers
+	QuoteChar byte    // quoting identifiers ('[' is closed with ']')
 	Schema    *string // schema qualifier
 }
 
@@ -241,7 +337,11 @@ func (b *Builder) Ident(s string) *Builder {
 	if s != "" {
 		b.WriteByte(b.QuoteChar)
 		b.WriteString(s)
-		b.WriteByte(b.QuoteChar)
+		if b.QuoteChar == '[' {
+			b.WriteByte(']')
+		} else {
+			b.WriteByte(b.QuoteChar)
+		}
 		b.WriteByte(' ')
 	}
 	return b
@@ -267,6 +367,36 @@ func (b *Builder) Table(t *schema.Table) *Builder {
 	return b
 }
 
+// View writes the view identifier to the builder, prefixed
+// with the schema name if exists.
+func (b *Builder) View(v *schema.View) *Builder {
+	return b.Table(&schema.Table{Name: v.Name, Schema: v.Schema})
+}
+
+// Func writes the function identifier to the builder,
+// prefixed with the schema name if exists.
+func (b *Builder) Func(f *schema.Func) *Builder {
+	return b.Table(&schema.Table{Name: f.Name, Schema: f.Schema})
+}
+
+// Proc writes the procedure identifier to the builder,
+// prefixed with the schema name if exists.
+func (b *Builder) Proc(p *schema.Proc) *Builder {
+	return b.Table(&schema.Table{Name: p.Name, Schema: p.Schema})
+}
+
+// Sequence writes the sequence identifier to the builder,
+// prefixed with the schema name if exists.
+func (b *Builder) Sequence(s *schema.Sequence) *Builder {
+	return b.Table(&schema.Table{Name: s.Name, Schema: s.Schema})
+}
+
+// Trigger writes the trigger identifier to the builder, prefixed
+// with the schema name of its table if exists.
+func (b *Builder) Trigger(t *schema.Trigger) *Builder {
+	return b.Table(&schema.Table{Name: t.Name, Schema: t.Table.Schema})
+}
+
 // Comma writes a comma in case the buffer is not empty, or
 // replaces the last char if it is a whitespace.
 func (b *Builder) Comma() *Builder {
@@ -5,8 +5,13 @@
 package sqlx
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"ariga.io/atlas/sql/schema"
 
@@ -41,6 +46,49 @@ func TestModeInspectSchema(t *testing.T) {
 	require.False(t, m.Is(schema.InspectTables))
 }
 
+func TestInspectSchemas(t *testing.T) {
+	var schemas []*schema.Schema
+	for i := 0; i < 10; i++ {
+		schemas = append(schemas, schema.New("s"+strconv.Itoa(i)).AddTables(schema.NewTable("t")))
+	}
+	// Schemas without tables are skipped.
+	schemas = append(schemas, schema.New("empty"))
+	var (
+		running, peak, calls int32
+		inspect              = func(context.Context, *schema.Schema) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for p := atomic.LoadInt32(&peak); n > p && !atomic.CompareAndSwapInt32(&peak, p, n); p = atomic.LoadInt32(&peak) {
+			}
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}
+	)
+	// Connections that are not pools are inspected serially.
+	require.NoError(t, InspectSchemas(context.Background(), NoRows, 4, schemas, inspect))
+	require.EqualValues(t, 10, calls)
+	require.EqualValues(t, 1, peak)
+
+	calls, peak = 0, 0
+	require.NoError(t, InspectSchemas(context.Background(), new(sql.DB), 4, schemas, inspect))
+	require.EqualValues(t, 10, calls)
+	require.True(t, peak > 1 && peak <= 4, "unexpected number of concurrent workers: %d", peak)
+
+	// The first error is returned, and the rest of the schemas are skipped.
+	calls = 0
+	err := InspectSchemas(context.Background(), new(sql.DB), 2, schemas, func(ctx context.Context, s *schema.Schema) error {
+		atomic.AddInt32(&calls, 1)
+		if s.Name == "s1" {
+			return errors.New("boom")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.EqualError(t, err, "boom")
+	require.EqualValues(t, 2, calls)
+}
+
 func TestBuilder(t *testing.T) {
 	var (
 		b       = &Builder{QuoteChar: '"'}
@@ -59,6 +107,11 @@ func TestBuilder(t *testing.T) {
 			})
 		})
 	require.Equal(t, `CREATE TABLE "users" ("a" int NOT NULL, "b" int NOT NULL, "c" int NOT NULL, PRIMARY KEY ("a", "b", "c"))`, b.String())
+
+	// Brackets are closed with the matching character.
+	b = &Builder{QuoteChar: '['}
+	b.P("DROP TABLE").Table(schema.NewTable("users").SetSchema(schema.New("dbo")))
+	require.Equal(t, `DROP TABLE [dbo].[users]`, b.String())
 }
 
 func TestBuilder_Qualifier(t *testing.T) {
@@ -201,3 +254,36 @@ func TestReverseChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestPrivActions(t *testing.T) {
+	var (
+		s     = schema.New("app")
+		users = schema.NewTable("users").SetSchema(s)
+		posts = schema.NewTable("posts").SetSchema(s)
+	)
+	posts.AddForeignKeys(
+		schema.NewForeignKey("author").SetRefTable(users),
+		schema.NewForeignKey("parent").SetRefTable(posts),
+	)
+	acts := PrivActions([]schema.Change{
+		&schema.AddSchema{S: s},
+		&schema.AddTable{T: posts},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.AddIndex{I: schema.NewIndex("name")},
+		}},
+		&schema.ModifyTable{T: posts, Changes: []schema.Change{
+			&schema.AddColumn{C: schema.NewColumn("title")},
+			&schema.DropIndex{I: schema.NewIndex("title")},
+		}},
+		&schema.DropTable{T: schema.NewTable("logs")},
+	})
+	require.Equal(t, []*PrivAction{
+		{Kind: PrivCreateSchema, Schema: "app", Reason: `create schema "app"`},
+		{Kind: PrivCreateTable, Schema: "app", Reason: `create table "posts"`},
+		{Kind: PrivReferences, Schema: "app", Table: "users", Reason: `create table "posts"`},
+		{Kind: PrivIndex, Schema: "app", Table: "users", Reason: `add index "name" to table "users"`},
+		{Kind: PrivAlterTable, Schema: "app", Table: "posts", Reason: `modify table "posts"`},
+		{Kind: PrivIndex, Schema: "app", Table: "posts", Reason: `drop index "title" of table "posts"`},
+		{Kind: PrivDropTable, Table: "logs", Reason: `drop table "logs"`},
+	}, acts)
+}
@@ -0,0 +1,246 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// ViolationCheck holds the read-only queries that check if the
+// existing rows of a table violate a constraint added by a change.
+type ViolationCheck struct {
+	V      *migrate.Violation // Checked constraint. Rows and Sample are set by CheckViolations.
+	Index  *schema.Index      // Unique index, or nil for foreign keys.
+	FK     *schema.ForeignKey // Foreign key, or nil for unique indexes.
+	Count  string             // Counts the offending rows.
+	Sample string             // Selects the offending values, up to the limit.
+}
+
+// ViolationChecks returns the checks of the foreign keys and unique indexes that are added (or modified)
+// by the given changes to existing tables. Constraints that cannot be checked before the changes are
+// applied, such as those defined on expressions, or on columns that are added or renamed by the changes,
+// are skipped. Identifiers are quoted using the given quote character.
+func ViolationChecks(changes []schema.Change, quote byte, limit int) []*ViolationCheck {
+	var (
+		checks  []*ViolationCheck
+		newT    = make(map[*schema.Table]bool)
+		renamed = make(map[*schema.Table]bool)
+		newC    = make(map[*schema.Column]bool)
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			newT[c.T] = true
+		case *schema.RenameTable:
+			renamed[c.To] = true
+		case *schema.ModifyTable:
+			for _, c := range c.Changes {
+				switch c := c.(type) {
+				case *schema.AddColumn:
+					newC[c.C] = true
+				case *schema.RenameColumn:
+					newC[c.To] = true
+				}
+			}
+		}
+	}
+	exist := func(cs []*schema.Column) bool {
+		for _, c := range cs {
+			if newC[c] {
+				return false
+			}
+		}
+		return true
+	}
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok || renamed[m.T] {
+			continue
+		}
+		for _, c := range m.Changes {
+			switch c := c.(type) {
+			case *schema.AddIndex:
+				if c.I.Unique {
+					checks = uniqueCheck(checks, m.T, c.I, quote, limit, exist)
+				}
+			case *schema.ModifyIndex:
+				if c.To.Unique && (c.Change.Is(schema.ChangeUnique) || c.Change.Is(schema.ChangeParts)) {
+					checks = uniqueCheck(checks, m.T, c.To, quote, limit, exist)
+				}
+			case *schema.AddForeignKey:
+				checks = fkCheck(checks, m.T, c.F, quote, limit, exist, newT[c.F.RefTable])
+			case *schema.ModifyForeignKey:
+				if c.Change.Is(schema.ChangeColumn) || c.Change.Is(schema.ChangeRefColumn) || c.Change.Is(schema.ChangeRefTable) {
+					checks = fkCheck(checks, m.T, c.To, quote, limit, exist, newT[c.To.RefTable])
+				}
+			}
+		}
+	}
+	return checks
+}
+
+// uniqueCheck appends the check of the unique index. Rows with NULL values in
+// one of the index columns do not violate the index, and therefore, are excluded.
+func uniqueCheck(checks []*ViolationCheck, t *schema.Table, idx *schema.Index, quote byte, limit int, exist func([]*schema.Column) bool) []*ViolationCheck {
+	cs := make([]*schema.Column, 0, len(idx.Parts))
+	for _, p := range idx.Parts {
+		if p.C == nil {
+			return checks
+		}
+		cs = append(cs, p.C)
+	}
+	if len(cs) == 0 || !exist(cs) {
+		return checks
+	}
+	dups := func(b *Builder) {
+		b.P("FROM").Table(t).P("WHERE")
+		notNull(b, "", cs)
+		b.P("GROUP BY")
+		b.MapComma(cs, func(i int, b *Builder) { b.Ident(cs[i].Name) })
+		b.P("HAVING COUNT(*) > 1")
+	}
+	count := &Builder{QuoteChar: quote}
+	count.P("SELECT COALESCE(SUM(n), 0) FROM").Wrap(func(b *Builder) {
+		b.P("SELECT COUNT(*) AS n")
+		dups(b)
+	}).P("AS t")
+	sample := &Builder{QuoteChar: quote}
+	sample.P("SELECT")
+	sample.MapComma(cs, func(i int, b *Builder) { b.Ident(cs[i].Name) })
+	dups(sample)
+	sample.P("LIMIT", strconv.Itoa(limit))
+	return append(checks, &ViolationCheck{
+		V:      &migrate.Violation{Table: t.Name, Constraint: idx.Name, Kind: "unique", Columns: columnNames(cs)},
+		Index:  idx,
+		Count:  count.String(),
+		Sample: sample.String(),
+	})
+}
+
+// fkCheck appends the check of the foreign key. Rows with NULL values in one of the
+// foreign key columns are not checked by the database (MATCH SIMPLE), and therefore,
+// are excluded. If the referenced table is created by the changes, all other rows are
+// offending, as the table is empty.
+func fkCheck(checks []*ViolationCheck, t *schema.Table, fk *schema.ForeignKey, quote byte, limit int, exist func([]*schema.Column) bool, newRef bool) []*ViolationCheck {
+	if fk.RefTable == nil || len(fk.Columns) == 0 || len(fk.Columns) != len(fk.RefColumns) || !exist(fk.Columns) || !newRef && !exist(fk.RefColumns) {
+		return checks
+	}
+	orphans := func(b *Builder) {
+		b.P("FROM").Table(t).P("AS c WHERE")
+		notNull(b, "c", fk.Columns)
+		if newRef {
+			return
+		}
+		b.P("AND NOT EXISTS").Wrap(func(b *Builder) {
+			b.P("SELECT 1 FROM").Table(fk.RefTable).P("AS p WHERE")
+			for i, c := range fk.Columns {
+				if i > 0 {
+					b.P("AND")
+				}
+				b.WriteString("p.")
+				b.Ident(fk.RefColumns[i].Name).P("=")
+				b.WriteString("c.")
+				b.Ident(c.Name)
+			}
+		})
+	}
+	count := &Builder{QuoteChar: quote}
+	count.P("SELECT COUNT(*)")
+	orphans(count)
+	sample := &Builder{QuoteChar: quote}
+	sample.P("SELECT")
+	sample.MapComma(fk.Columns, func(i int, b *Builder) {
+		b.WriteString("c.")
+		b.Ident(fk.Columns[i].Name)
+	})
+	orphans(sample)
+	sample.P("LIMIT", strconv.Itoa(limit))
+	return append(checks, &ViolationCheck{
+		V:      &migrate.Violation{Table: t.Name, Constraint: fk.Symbol, Kind: "foreign key", Columns: columnNames(fk.Columns)},
+		FK:     fk,
+		Count:  count.String(),
+		Sample: sample.String(),
+	})
+}
+
+// CheckViolations executes the given checks, and returns the violations of the constraints
+// that have offending rows. The checks are read-only, and the samples are limited by their
+// queries.
+func CheckViolations(ctx context.Context, conn schema.ExecQuerier, checks []*ViolationCheck) ([]*migrate.Violation, error) {
+	var vs []*migrate.Violation
+	for _, c := range checks {
+		rows, err := conn.QueryContext(ctx, c.Count)
+		if err != nil {
+			return nil, fmt.Errorf("checking %s of table %q: %w", c.V.Kind, c.V.Table, err)
+		}
+		v := *c.V
+		if err := ScanOne(rows, &v.Rows); err != nil {
+			return nil, fmt.Errorf("scanning %s violations of table %q: %w", c.V.Kind, c.V.Table, err)
+		}
+		if v.Rows == 0 {
+			continue
+		}
+		if v.Sample, err = sampleRows(ctx, conn, c.Sample, len(v.Columns)); err != nil {
+			return nil, fmt.Errorf("sampling %s violations of table %q: %w", c.V.Kind, c.V.Table, err)
+		}
+		vs = append(vs, &v)
+	}
+	return vs, nil
+}
+
+// sampleRows scans the rows returned by the sample query as strings.
+func sampleRows(ctx context.Context, conn schema.ExecQuerier, query string, n int) ([][]string, error) {
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sample [][]string
+	for rows.Next() {
+		vs, ptr := make([]sql.NullString, n), make([]any, n)
+		for i := range vs {
+			ptr[i] = &vs[i]
+		}
+		if err := rows.Scan(ptr...); err != nil {
+			return nil, err
+		}
+		row := make([]string, n)
+		for i, v := range vs {
+			row[i] = v.String
+			if !v.Valid {
+				row[i] = "NULL"
+			}
+		}
+		sample = append(sample, row)
+	}
+	return sample, rows.Err()
+}
+
+// notNull writes the condition that all the given columns are not NULL.
+func notNull(b *Builder, alias string, cs []*schema.Column) {
+	for i, c := range cs {
+		if i > 0 {
+			b.P("AND")
+		}
+		if alias != "" {
+			b.WriteString(alias + ".")
+		}
+		b.Ident(c.Name).P("IS NOT NULL")
+	}
+}
+
+func columnNames(cs []*schema.Column) []string {
+	names := make([]string, len(cs))
+	for i, c := range cs {
+		names[i] = c.Name
+	}
+	return names
+}
@@ -0,0 +1,91 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViolationChecks(t *testing.T) {
+	var (
+		app      = schema.New("app")
+		users    = schema.NewTable("users").SetSchema(app).AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("email", "text"), schema.NewIntColumn("org_id", "int"))
+		orgs     = schema.NewTable("orgs").SetSchema(app).AddColumns(schema.NewIntColumn("id", "int"))
+		teams    = schema.NewTable("teams").SetSchema(app).AddColumns(schema.NewIntColumn("id", "int"))
+		pets     = schema.NewTable("pets").SetSchema(app).AddColumns(schema.NewIntColumn("owner_id", "int"), schema.NewIntColumn("team_id", "int"), schema.NewIntColumn("tag", "int"))
+		newCol   = schema.NewIntColumn("code", "int")
+		email    = schema.NewUniqueIndex("email").AddColumns(users.Columns[1])
+		expr     = schema.NewUniqueIndex("lower_email").AddExprs(&schema.RawExpr{X: "lower(email)"})
+		code     = schema.NewUniqueIndex("code").AddColumns(newCol)
+		nonUniq  = schema.NewIndex("org").AddColumns(users.Columns[2])
+		ownerFK  = schema.NewForeignKey("owner").AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+		teamFK   = schema.NewForeignKey("team").AddColumns(pets.Columns[1]).SetRefTable(teams).AddRefColumns(teams.Columns[0])
+		tagFK    = schema.NewForeignKey("tag").AddColumns(pets.Columns[2]).SetRefTable(orgs).AddRefColumns(orgs.Columns[0])
+		renamed  = schema.NewTable("people").SetSchema(app)
+		renameFK = schema.NewForeignKey("org").AddColumns(users.Columns[2]).SetRefTable(orgs).AddRefColumns(orgs.Columns[0])
+	)
+	checks := ViolationChecks([]schema.Change{
+		&schema.AddTable{T: teams},
+		&schema.RenameTable{From: schema.NewTable("humans"), To: renamed},
+		&schema.ModifyTable{T: renamed, Changes: []schema.Change{&schema.AddForeignKey{F: renameFK}}},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.AddColumn{C: newCol},
+			&schema.AddIndex{I: email},
+			&schema.AddIndex{I: expr},
+			&schema.AddIndex{I: code},
+			&schema.AddIndex{I: nonUniq},
+		}},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{
+			&schema.AddForeignKey{F: ownerFK},
+			&schema.AddForeignKey{F: teamFK},
+			&schema.ModifyForeignKey{From: tagFK, To: tagFK, Change: schema.ChangeDeleteAction},
+		}},
+	}, '"', 5)
+	require.Len(t, checks, 3)
+	require.Equal(t, &migrate.Violation{Table: "users", Constraint: "email", Kind: "unique", Columns: []string{"email"}}, checks[0].V)
+	require.Equal(t, email, checks[0].Index)
+	require.Equal(t, `SELECT COALESCE(SUM(n), 0) FROM (SELECT COUNT(*) AS n FROM "app"."users" WHERE "email" IS NOT NULL GROUP BY "email" HAVING COUNT(*) > 1) AS t`, checks[0].Count)
+	require.Equal(t, `SELECT "email" FROM "app"."users" WHERE "email" IS NOT NULL GROUP BY "email" HAVING COUNT(*) > 1 LIMIT 5`, checks[0].Sample)
+
+	require.Equal(t, &migrate.Violation{Table: "pets", Constraint: "owner", Kind: "foreign key", Columns: []string{"owner_id"}}, checks[1].V)
+	require.Equal(t, ownerFK, checks[1].FK)
+	require.Equal(t, `SELECT COUNT(*) FROM "app"."pets" AS c WHERE c."owner_id" IS NOT NULL AND NOT EXISTS (SELECT 1 FROM "app"."users" AS p WHERE p."id" = c."owner_id")`, checks[1].Count)
+	require.Equal(t, `SELECT c."owner_id" FROM "app"."pets" AS c WHERE c."owner_id" IS NOT NULL AND NOT EXISTS (SELECT 1 FROM "app"."users" AS p WHERE p."id" = c."owner_id") LIMIT 5`, checks[1].Sample)
+
+	// The referenced table is created by the changes.
+	require.Equal(t, `SELECT COUNT(*) FROM "app"."pets" AS c WHERE c."team_id" IS NOT NULL`, checks[2].Count)
+	require.Equal(t, `SELECT c."team_id" FROM "app"."pets" AS c WHERE c."team_id" IS NOT NULL LIMIT 5`, checks[2].Sample)
+}
+
+func TestCheckViolations(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	checks := []*ViolationCheck{
+		{V: &migrate.Violation{Table: "users", Kind: "unique", Columns: []string{"email"}}, Count: "SELECT count_users", Sample: "SELECT sample_users"},
+		{V: &migrate.Violation{Table: "pets", Kind: "foreign key", Columns: []string{"owner_id", "kind"}}, Count: "SELECT count_pets", Sample: "SELECT sample_pets"},
+	}
+	m.ExpectQuery(sqltest.Escape("SELECT count_users")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	m.ExpectQuery(sqltest.Escape("SELECT count_pets")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	m.ExpectQuery(sqltest.Escape("SELECT sample_pets")).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id", "kind"}).AddRow(1, "dog").AddRow(2, nil))
+	vs, err := CheckViolations(context.Background(), db, checks)
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []*migrate.Violation{
+		{Table: "pets", Kind: "foreign key", Columns: []string{"owner_id", "kind"}, Rows: 3, Sample: [][]string{{"1", "dog"}, {"2", "NULL"}}},
+	}, vs)
+	// Checks are not modified.
+	require.Zero(t, checks[1].V.Rows)
+}
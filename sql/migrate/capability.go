@@ -0,0 +1,93 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A Capability names a database feature that affects how changes are planned
+// and executed, and whose availability depends on the driver and its version.
+type Capability string
+
+// List of capabilities reported by the builtin drivers.
+const (
+	// CapTransactionalDDL indicates that DDL statements can be executed in
+	// a transaction, and rolled back together with the rest of its statements.
+	CapTransactionalDDL Capability = "transactional_ddl"
+
+	// CapConcurrentIndex indicates that indexes can be created and dropped
+	// without blocking writes to the table (e.g. CREATE INDEX CONCURRENTLY).
+	CapConcurrentIndex Capability = "concurrent_index"
+
+	// CapInstantAddColumn indicates that columns (with constant defaults)
+	// can be added by changing only the table metadata, without rewriting it.
+	CapInstantAddColumn Capability = "instant_add_column"
+
+	// CapRenameColumn indicates that columns can be renamed in place.
+	CapRenameColumn Capability = "rename_column"
+
+	// CapCheckConstraint indicates that CHECK constraints are enforced.
+	CapCheckConstraint Capability = "check_constraint"
+
+	// CapIndexExpr indicates that indexes can be defined on expressions.
+	CapIndexExpr Capability = "index_expr"
+
+	// CapGeneratedColumn indicates that generated columns are supported.
+	CapGeneratedColumn Capability = "generated_column"
+
+	// CapInvisibleIndex indicates that indexes can be hidden from the optimizer.
+	CapInvisibleIndex Capability = "invisible_index"
+
+	// CapIndexInclude indicates that indexes can include non-key columns.
+	CapIndexInclude Capability = "index_include"
+
+	// CapNullsNotDistinct indicates that unique indexes can treat NULLs as equal.
+	CapNullsNotDistinct Capability = "nulls_not_distinct"
+)
+
+type (
+	// Capabilities describes the capabilities of a specific driver and version.
+	Capabilities struct {
+		Driver   string              `json:"driver"`
+		Version  string              `json:"version"`
+		Supports map[Capability]bool `json:"capabilities"`
+	}
+
+	// CapabilitiesReporter is the interface implemented by drivers that
+	// expose their capability matrix to tools, analyzers and planners.
+	CapabilitiesReporter interface {
+		// Capabilities returns the capabilities of the given database version.
+		// An empty version means the version of the connected database.
+		Capabilities(version string) (*Capabilities, error)
+	}
+)
+
+// Is reports if the capability is supported. Capabilities
+// that are not reported by the driver are not supported.
+func (c *Capabilities) Is(cp Capability) bool {
+	return c != nil && c.Supports[cp]
+}
+
+// List returns the capabilities names in sorted order.
+func (c *Capabilities) List() []Capability {
+	list := make([]Capability, 0, len(c.Supports))
+	for cp := range c.Supports {
+		list = append(list, cp)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
+
+// DriverCapabilities returns the capabilities of the given driver for
+// the given version, or an error if the driver does not report them.
+func DriverCapabilities(drv Driver, version string) (*Capabilities, error) {
+	r, ok := drv.(CapabilitiesReporter)
+	if !ok {
+		return nil, fmt.Errorf("sql/migrate: driver %T does not report its capabilities", drv)
+	}
+	return r.Capabilities(version)
+}
@@ -0,0 +1,106 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// atlas:checkpoint directive.
+const directiveCheckpoint = "checkpoint"
+
+// IsCheckpoint reports if the migration file is a checkpoint file, as marked by
+// the atlas:checkpoint directive in its header. A checkpoint file represents the
+// state of all files that precede it, and therefore, new databases start their
+// migration from the last checkpoint file, while existing databases skip it.
+//
+//	-- atlas:checkpoint
+//	CREATE TABLE `users` (`id` int NOT NULL, PRIMARY KEY (`id`));
+func IsCheckpoint(f File) bool {
+	return len(HeaderDirectives(f, directiveCheckpoint)) > 0
+}
+
+// HeaderDirectives returns the values of all directives with the given name
+// in the header of the file, which ends at the first non-comment line.
+//
+//	-- atlas:import users.sql
+//	CREATE TABLE `posts` (`id` int NOT NULL, `author_id` int REFERENCES `users` (`id`));
+func HeaderDirectives(f File, name string) (ds []string) {
+	for _, line := range strings.Split(text(f.Bytes()), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// The header ends at the first non-comment line.
+		if !strings.HasPrefix(line, "--") && !strings.HasPrefix(line, "#") {
+			return ds
+		}
+		for _, p := range []string{"#", "--", "-- "} {
+			if d, ok := directive(line, name, p); ok {
+				ds = append(ds, d)
+				break
+			}
+		}
+	}
+	return ds
+}
+
+// FilesFromCheckpoint returns the files that are executed on new databases,
+// which start from the last checkpoint file, or all files if there is none.
+func FilesFromCheckpoint(files []File) []File {
+	if i := FilesLastIndex(files, IsCheckpoint); i != -1 {
+		return files[i:]
+	}
+	return files
+}
+
+// Checkpoint plans a checkpoint file that represents the state of the migration
+// directory, by replaying it on the dev database and planning the changes from
+// an empty realm to its state. Use the WriteCheckpoint method to write it.
+func (p *Planner) Checkpoint(ctx context.Context, name string) (*Plan, error) {
+	return p.checkpoint(ctx, name, true)
+}
+
+// CheckpointSchema is like Checkpoint but limits its scope to the schema connection.
+// Note, the operation fails in case the connection was not set to a schema.
+func (p *Planner) CheckpointSchema(ctx context.Context, name string) (*Plan, error) {
+	return p.checkpoint(ctx, name, false)
+}
+
+func (p *Planner) checkpoint(ctx context.Context, name string, realmScope bool) (*Plan, error) {
+	desired, err := p.replay(ctx, realmScope)
+	if err != nil {
+		return nil, err
+	}
+	current := schema.NewRealm()
+	// In schema scope, the schema itself (and its attributes)
+	// is not created by the migration files, but only its resources.
+	if !realmScope && len(desired.Schemas) == 1 {
+		current.AddSchemas(schema.New(desired.Schemas[0].Name).AddAttrs(desired.Schemas[0].Attrs...))
+	}
+	// Checkpoint files are not reverted, as reverting them means dropping everything.
+	cp := *p
+	cp.rev = false
+	return cp.planChanges(ctx, name, current, desired, realmScope)
+}
+
+// WriteCheckpoint writes the given checkpoint Plan to the Dir as a single file
+// marked with the atlas:checkpoint directive, and updates the sum file. Files that
+// precede the checkpoint are kept in the directory, as existing databases may
+// still need them, and their hashes are still validated.
+func (p *Planner) WriteCheckpoint(plan *Plan) error {
+	if plan.Reverse != nil {
+		return errors.New("sql/migrate: checkpoint plans cannot have a reverse plan")
+	}
+	// Checkpoint files represent all files before
+	// them, and therefore, are not split or phased.
+	cp := *p
+	cp.split, cp.phase, cp.ckpt = nil, "", true
+	return cp.WritePlan(plan)
+}
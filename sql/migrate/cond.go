@@ -0,0 +1,171 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// atlas:only directive.
+	directiveOnly = "only"
+	// atlas:dialect directive.
+	directiveDialect = "dialect"
+)
+
+// Target describes the environment migration files are executed on, and is
+// used for evaluating the conditional directives of statements. A statement
+// that is preceded by conditional directives is executed only if all of its
+// directives match the target. For example:
+//
+//	-- atlas:only env=prod,staging
+//	CREATE TABLESPACE fast LOCATION '/mnt/ssd';
+//
+//	-- atlas:dialect postgres>=15
+//	ALTER TABLE t ADD CONSTRAINT u UNIQUE NULLS NOT DISTINCT (c);
+//
+// The "only" directive accepts one or more space-separated key=values pairs,
+// where key is either "env" or "dialect", and values is a comma-separated list
+// of alternatives. The "dialect" directive accepts a comma-separated list of
+// alternatives, where each holds a dialect name, optionally followed by a
+// version constraint using one of the =, !=, <, <=, > or >= operators.
+type Target struct {
+	Env     string // environment name, e.g. "prod"
+	Dialect string // database dialect, e.g. "postgres"
+	Version string // database version, e.g. "15.2"
+}
+
+// Match reports whether the conditional directives of the given
+// statement match the target. Statements without conditional
+// directives always match.
+func (t *Target) Match(s *Stmt) (bool, error) {
+	for _, d := range s.Directive(directiveOnly) {
+		ok, err := t.matchOnly(d)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	for _, d := range s.Directive(directiveDialect) {
+		ok, err := t.matchDialect(d)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchOnly matches the arguments of the "only" directive.
+func (t *Target) matchOnly(args string) (bool, error) {
+	pairs := strings.Fields(args)
+	if len(pairs) == 0 {
+		return false, fmt.Errorf("sql/migrate: missing arguments for atlas:%s directive", directiveOnly)
+	}
+	for _, p := range pairs {
+		k, vs, ok := strings.Cut(p, "=")
+		if !ok || vs == "" {
+			return false, fmt.Errorf("sql/migrate: invalid atlas:%s argument %q", directiveOnly, p)
+		}
+		var match bool
+		for _, v := range strings.Split(vs, ",") {
+			switch k {
+			case "env":
+				match = v == t.Env
+			case "dialect":
+				m, err := t.dialect(v)
+				if err != nil {
+					return false, err
+				}
+				match = m
+			default:
+				return false, fmt.Errorf("sql/migrate: unknown atlas:%s key %q", directiveOnly, k)
+			}
+			if match {
+				break
+			}
+		}
+		if !match {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchDialect matches the arguments of the "dialect" directive.
+func (t *Target) matchDialect(args string) (bool, error) {
+	if strings.TrimSpace(args) == "" {
+		return false, fmt.Errorf("sql/migrate: missing arguments for atlas:%s directive", directiveDialect)
+	}
+	for _, v := range strings.Split(args, ",") {
+		switch ok, err := t.dialect(strings.TrimSpace(v)); {
+		case err != nil:
+			return false, err
+		case ok:
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// dialect reports if the target matches the given dialect
+// expression. e.g. "mysql", "postgres>=15" or "sqlite<3.35".
+func (t *Target) dialect(expr string) (bool, error) {
+	i := strings.IndexAny(expr, "=!<>")
+	if i == -1 {
+		return expr == t.Dialect, nil
+	}
+	name, op := strings.TrimSpace(expr[:i]), expr[i:i+1]
+	if len(expr) > i+1 && expr[i+1] == '=' {
+		op = expr[i : i+2]
+	}
+	v := strings.TrimSpace(expr[i+len(op):])
+	if name == "" || op == "!" || op == "==" || !semver.IsValid(canonicalVersion(v)) {
+		return false, fmt.Errorf("sql/migrate: invalid dialect expression %q", expr)
+	}
+	if name != t.Dialect {
+		return false, nil
+	}
+	current := canonicalVersion(t.Version)
+	if !semver.IsValid(current) {
+		return false, fmt.Errorf("sql/migrate: unknown %s version for evaluating dialect expression %q", t.Dialect, expr)
+	}
+	c := semver.Compare(current, canonicalVersion(v))
+	switch op {
+	case "=":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default: // >=
+		return c >= 0, nil
+	}
+}
+
+// canonicalVersion converts a database version to its semver form by
+// trimming its non-numeric suffix. e.g. "8.0.28-debug" => "v8.0.28".
+func canonicalVersion(v string) string {
+	i := strings.IndexFunc(v, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i != -1 {
+		v = v[:i]
+	}
+	return "v" + strings.TrimSuffix(v, ".")
+}
+
+// fileStmtDecls returns the statement declarations of the given file.
+func fileStmtDecls(f File) ([]*Stmt, error) {
+	if s, ok := f.(interface{ StmtDecls() ([]*Stmt, error) }); ok {
+		return s.StmtDecls()
+	}
+	return Stmts(string(f.Bytes()))
+}
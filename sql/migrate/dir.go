@@ -7,6 +7,7 @@ package migrate
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
@@ -18,6 +19,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 )
@@ -36,6 +38,14 @@ type (
 		Checksum() (HashFile, error)
 	}
 
+	// DirLocker is an optional interface implemented by Dirs that can be locked
+	// against concurrent mutations, including mutations made by other processes.
+	DirLocker interface {
+		// Lock blocks until the lock is acquired or the context is done,
+		// and returns a function for releasing the lock.
+		Lock(context.Context) (func() error, error)
+	}
+
 	// Formatter wraps the Format method.
 	Formatter interface {
 		// Format formats the given Plan into one or more migration files.
@@ -67,7 +77,7 @@ var _ Dir = (*LocalDir)(nil)
 
 // NewLocalDir returns a new the Dir used by a Planner to work on the given local path.
 func NewLocalDir(path string) (*LocalDir, error) {
-	fi, err := os.Stat(path)
+	fi, err := os.Stat(longPath(path))
 	if err != nil {
 		return nil, fmt.Errorf("sql/migrate: %w", err)
 	}
@@ -84,12 +94,84 @@ func (d *LocalDir) Path() string {
 
 // Open implements fs.FS.
 func (d *LocalDir) Open(name string) (fs.File, error) {
-	return os.Open(filepath.Join(d.path, name))
+	return os.Open(longPath(filepath.Join(d.path, name)))
 }
 
-// WriteFile implements Dir.WriteFile.
+// WriteFile implements Dir.WriteFile. The file is written atomically, by writing its
+// content to a temporary file in the directory and renaming it to the given name, to
+// ensure concurrent readers never observe partially written files.
 func (d *LocalDir) WriteFile(name string, b []byte) error {
-	return os.WriteFile(filepath.Join(d.path, name), b, 0644)
+	f, err := os.CreateTemp(longPath(d.path), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// Cleanup is a no-op if the file was renamed.
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), longPath(filepath.Join(d.path, name)))
+}
+
+const (
+	// dirLockName is the name of the lock file created in locked directories.
+	dirLockName = ".atlas.lock"
+	// dirLockStale is the age after which the lock file is considered to be
+	// abandoned (e.g. its process was killed), and it can be taken over.
+	dirLockStale = time.Minute
+	// dirLockPoll is the interval for checking if a held lock was released.
+	dirLockPoll = 50 * time.Millisecond
+)
+
+// Lock implements DirLocker. The lock is held by creating a lock file in the directory,
+// and therefore, it guards mutations made by processes that share the directory.
+func (d *LocalDir) Lock(ctx context.Context) (func() error, error) {
+	path := longPath(filepath.Join(d.path, dirLockName))
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("sql/migrate: write lock file: %w", err)
+			}
+			return func() error { return os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("sql/migrate: create lock file: %w", err)
+		}
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) > dirLockStale {
+			// Take over the abandoned lock in the next iteration.
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("sql/migrate: remove stale lock file: %w", err)
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("sql/migrate: acquire lock on directory %q: %w", d.path, ctx.Err())
+		case <-time.After(dirLockPoll):
+		}
+	}
+}
+
+// LockDir locks the given Dir, if it implements the DirLocker interface,
+// and returns a function for releasing the lock. Otherwise, it is a no-op.
+func LockDir(ctx context.Context, dir Dir) (func() error, error) {
+	if l, ok := dir.(DirLocker); ok {
+		return l.Lock(ctx)
+	}
+	return func() error { return nil }, nil
 }
 
 // Files implements Dir.Files. It looks for all files with .sql suffix and orders them by filename.
@@ -102,36 +184,66 @@ func (d *LocalDir) Files() ([]File, error) {
 	sort.Slice(names, func(i, j int) bool {
 		return names[i] < names[j]
 	})
-	ret := make([]File, len(names))
-	for i, n := range names {
+	ret := make([]File, 0, len(names))
+	for _, n := range names {
 		b, err := fs.ReadFile(d, n)
 		if err != nil {
 			return nil, fmt.Errorf("sql/migrate: read file %q: %w", n, err)
 		}
-		ret[i] = NewLocalFile(n, b)
+		// Reverse files (see PlanWithReverse) are not executed.
+		if _, ok := directive(text(b), directiveReverse, directivePrefixSQL); ok {
+			continue
+		}
+		ret = append(ret, NewLocalFile(n, b))
 	}
 	return ret, nil
 }
 
 // Checksum implements Dir.Checksum. By default, it calls Files() and creates a checksum from them.
 func (d *LocalDir) Checksum() (HashFile, error) {
-	var (
-		hs HashFile
-		h  = sha256.New()
-	)
 	files, err := d.Files()
 	if err != nil {
 		return nil, err
 	}
+	return checksum(files)
+}
+
+// checksum creates a HashFile from the given migration files.
+func checksum(files []File) (HashFile, error) {
+	var (
+		hs       HashFile
+		h        = sha256.New()
+		names    = make(map[string]string, len(files))
+		versions = make(map[string]File, len(files))
+	)
 	for _, f := range files {
-		if _, err = h.Write([]byte(f.Name())); err != nil {
+		// Files or versions that differ only in case cannot coexist on case-insensitive
+		// file systems (e.g. Windows and macOS), or in revision tables with case-insensitive
+		// collations, and therefore, they are rejected on all platforms.
+		if prev, ok := names[strings.ToLower(f.Name())]; ok {
+			return nil, &CaseConflictError{Files: [2]string{prev, f.Name()}}
+		}
+		names[strings.ToLower(f.Name())] = f.Name()
+		v := strings.ToLower(f.Version())
+		if prev, ok := versions[v]; ok && prev.Version() != f.Version() {
+			return nil, &CaseConflictError{Files: [2]string{prev.Name(), f.Name()}}
+		}
+		versions[v] = f
+		// Seed files are hashed separately, as they do not change the schema.
+		// Hence, changing their data does not affect the hashes of later files.
+		if IsSeed(f) {
+			sh := sha256.Sum256(append([]byte(f.Name()), f.Bytes()...))
+			hs = append(hs, struct{ N, H string }{f.Name(), base64.StdEncoding.EncodeToString(sh[:])})
+			continue
+		}
+		if _, err := h.Write([]byte(f.Name())); err != nil {
 			return nil, err
 		}
 		// Check if this file contains an "atlas:sum" directive and if so, act to it.
-		if mode, ok := directive(string(f.Bytes()), directiveSum); ok && mode == sumModeIgnore {
+		if mode, ok := directive(text(f.Bytes()), directiveSum); ok && mode == sumModeIgnore {
 			continue
 		}
-		if _, err = h.Write(f.Bytes()); err != nil {
+		if _, err := h.Write(f.Bytes()); err != nil {
 			return nil, err
 		}
 		hs = append(hs, struct{ N, H string }{f.Name(), base64.StdEncoding.EncodeToString(h.Sum(nil))})
@@ -139,6 +251,17 @@ func (d *LocalDir) Checksum() (HashFile, error) {
 	return hs, nil
 }
 
+// CaseConflictError is returned when computing the checksum of a migration directory
+// with two files whose names or versions differ only in case. For example, "1_init.sql"
+// and "1_INIT.sql", or "v1_init.sql" and "V1_users.sql".
+type CaseConflictError struct {
+	Files [2]string
+}
+
+func (e *CaseConflictError) Error() string {
+	return fmt.Sprintf("sql/migrate: files %q and %q conflict on case-insensitive file systems", e.Files[0], e.Files[1])
+}
+
 // LocalFile is used by LocalDir to implement the Scanner interface.
 type LocalFile struct {
 	n string
@@ -163,7 +286,7 @@ func (f LocalFile) Desc() string {
 	if len(parts) == 1 {
 		return ""
 	}
-	return strings.TrimSuffix(parts[1], ".sql")
+	return strings.TrimSuffix(strings.TrimSuffix(parts[1], SeedFileSuffix), ".sql")
 }
 
 // Version implements File.Version.
@@ -173,7 +296,7 @@ func (f LocalFile) Version() string {
 
 // Stmts returns the SQL statement exists in the local file.
 func (f LocalFile) Stmts() ([]string, error) {
-	s, err := stmts(string(f.b))
+	s, err := Stmts(string(f.b))
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +310,7 @@ func (f LocalFile) Stmts() ([]string, error) {
 // StmtDecls returns the all statement declarations exist
 // in the local file.
 func (f LocalFile) StmtDecls() ([]*Stmt, error) {
-	return stmts(string(f.b))
+	return Stmts(string(f.b))
 }
 
 // Bytes returns local file data.
@@ -195,6 +318,356 @@ func (f LocalFile) Bytes() []byte {
 	return f.b
 }
 
+// OverlayDir implements Dir by layering an in-memory writable directory over
+// a read-only fs.FS, such as an embed.FS. Files written to an OverlayDir are
+// kept in memory and shadow the files with the same name in the base FS. It
+// allows programs that embed their migration directory to generate and test
+// new migration files at runtime, before committing them to disk.
+//
+//	//go:embed migrations
+//	var migrations embed.FS
+//
+//	base, err := fs.Sub(migrations, "migrations")
+//	if err != nil {
+//		return err
+//	}
+//	dir := migrate.NewOverlayDir(base)
+type OverlayDir struct {
+	base fs.FS
+	mu   sync.RWMutex
+	mem  map[string][]byte
+}
+
+var (
+	_ Dir           = (*OverlayDir)(nil)
+	_ fs.ReadDirFS  = (*OverlayDir)(nil)
+	_ fs.ReadFileFS = (*OverlayDir)(nil)
+)
+
+// NewOverlayDir returns a new OverlayDir over the given read-only base FS.
+func NewOverlayDir(base fs.FS) *OverlayDir {
+	return &OverlayDir{base: base, mem: make(map[string][]byte)}
+}
+
+// Open implements fs.FS.
+func (d *OverlayDir) Open(name string) (fs.File, error) {
+	if name == "/" {
+		name = "."
+	}
+	d.mu.RLock()
+	b, ok := d.mem[name]
+	d.mu.RUnlock()
+	if !ok {
+		return d.base.Open(name)
+	}
+	return &memFile{Reader: bytes.NewReader(b), info: memFileInfo{name: name, size: int64(len(b))}}, nil
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (d *OverlayDir) ReadFile(name string) ([]byte, error) {
+	d.mu.RLock()
+	b, ok := d.mem[name]
+	d.mu.RUnlock()
+	if !ok {
+		return fs.ReadFile(d.base, name)
+	}
+	return append([]byte(nil), b...), nil
+}
+
+// ReadDir implements fs.ReadDirFS. Only the root directory is merged
+// with the in-memory files, as migration directories are flat.
+func (d *OverlayDir) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == "/" {
+		name = "."
+	}
+	entries, err := fs.ReadDir(d.base, name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if name != "." {
+		return entries, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	merged := make([]fs.DirEntry, 0, len(entries)+len(d.mem))
+	for _, e := range entries {
+		if _, ok := d.mem[e.Name()]; !ok {
+			merged = append(merged, e)
+		}
+	}
+	for n, b := range d.mem {
+		merged = append(merged, fs.FileInfoToDirEntry(memFileInfo{name: n, size: int64(len(b))}))
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Name() < merged[j].Name()
+	})
+	return merged, nil
+}
+
+// WriteFile implements Dir.WriteFile. The file is written
+// to memory, and the base FS is left untouched.
+func (d *OverlayDir) WriteFile(name string, b []byte) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mem[name] = append([]byte(nil), b...)
+	return nil
+}
+
+// Files implements Dir.Files. It returns the .sql files of the base FS
+// and the in-memory files, ordered by their name.
+func (d *OverlayDir) Files() ([]File, error) {
+	entries, err := d.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		b, err := d.ReadFile(e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: read file %q: %w", e.Name(), err)
+		}
+		files = append(files, NewLocalFile(e.Name(), b))
+	}
+	return files, nil
+}
+
+// Checksum implements Dir.Checksum.
+func (d *OverlayDir) Checksum() (HashFile, error) {
+	files, err := d.Files()
+	if err != nil {
+		return nil, err
+	}
+	return checksum(files)
+}
+
+// Overlay returns the names of the files that were written
+// to the in-memory layer, ordered by their name.
+func (d *OverlayDir) Overlay() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	names := make([]string, 0, len(d.mem))
+	for n := range d.mem {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Commit writes the in-memory files to the given Dir (e.g. a LocalDir
+// pointing to the source of the embedded directory) and resets the
+// in-memory layer.
+func (d *OverlayDir) Commit(to Dir) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	names := make([]string, 0, len(d.mem))
+	for n := range d.mem {
+		names = append(names, n)
+	}
+	// Write the sum file last.
+	sort.Slice(names, func(i, j int) bool {
+		return names[j] == HashFileName || names[i] != HashFileName && names[i] < names[j]
+	})
+	for _, n := range names {
+		if err := to.WriteFile(n, d.mem[n]); err != nil {
+			return fmt.Errorf("sql/migrate: commit file %q: %w", n, err)
+		}
+		delete(d.mem, n)
+	}
+	return nil
+}
+
+// Reset discards all files written to the in-memory layer.
+func (d *OverlayDir) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mem = make(map[string][]byte)
+}
+
+// MultiDir implements Dir by composing several migration directories into one. For
+// example, a host application can compose its own migration directory with the
+// directories shipped by its plugins or extensions. The files of all directories are
+// merged and ordered by the VersionScheme of the first directory (see VersionedDir),
+// or by their names if it has none, and two files with the same version in different
+// directories are reported as a VersionConflictError.
+//
+// Each of the composed directories keeps its own sum file. Reading the sum file of a
+// MultiDir validates the composed directories and returns the checksum of the merged
+// files. New files are written to the first directory, and writing the sum file of the
+// merged files writes the sum file of the first directory.
+type MultiDir struct {
+	dirs []Dir
+}
+
+var _ VersionedDir = (*MultiDir)(nil)
+
+// NewMultiDir returns a new MultiDir composed of the given directories.
+func NewMultiDir(dirs ...Dir) (*MultiDir, error) {
+	if len(dirs) == 0 {
+		return nil, errors.New("sql/migrate: no dirs given")
+	}
+	d := &MultiDir{dirs: dirs}
+	if _, err := d.Files(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// VersionConflictError is returned when two composed directories
+// contain a migration file with the same version.
+type VersionConflictError struct {
+	Version string
+	Files   [2]string
+	Dirs    [2]int // Index of the directories.
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("sql/migrate: version %q is defined in multiple dirs: %q (dir %d) and %q (dir %d)", e.Version, e.Files[0], e.Dirs[0], e.Files[1], e.Dirs[1])
+}
+
+// Dirs returns the composed directories.
+func (d *MultiDir) Dirs() []Dir {
+	return d.dirs
+}
+
+// Scheme returns the VersionScheme of the first directory, in case it implements
+// VersionedDir. New files are written to the first directory, and therefore, they
+// are versioned using its scheme.
+func (d *MultiDir) Scheme() VersionScheme {
+	if sd, ok := d.dirs[0].(VersionedDir); ok {
+		return sd.Scheme()
+	}
+	return nil
+}
+
+// Open implements fs.FS. The sum file of a MultiDir is computed from the
+// merged files, after validating the sum files of all composed directories.
+// Other files are looked up in the composed directories by their order.
+func (d *MultiDir) Open(name string) (fs.File, error) {
+	if name == HashFileName {
+		for _, dir := range d.dirs {
+			if err := Validate(dir); err != nil {
+				return nil, err
+			}
+		}
+		sum, err := d.Checksum()
+		if err != nil {
+			return nil, err
+		}
+		b, err := sum.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return &memFile{Reader: bytes.NewReader(b), info: memFileInfo{name: name, size: int64(len(b))}}, nil
+	}
+	for _, dir := range d.dirs {
+		f, err := dir.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// WriteFile implements Dir.WriteFile. Files are written to the first directory. The sum
+// file of a MultiDir is computed from the merged files, and therefore, the written sum file
+// must match their checksum. If it does, the sum file of the first directory is written.
+func (d *MultiDir) WriteFile(name string, b []byte) error {
+	if name != HashFileName {
+		return d.dirs[0].WriteFile(name, b)
+	}
+	var sum HashFile
+	if err := sum.UnmarshalText(b); err != nil {
+		return fmt.Errorf("sql/migrate: parsing sum file: %w", err)
+	}
+	merged, err := d.Checksum()
+	if err != nil {
+		return err
+	}
+	if sum.Sum() != merged.Sum() {
+		return fmt.Errorf("sql/migrate: sum file does not match the files of the composed dirs: %w", ErrChecksumMismatch)
+	}
+	first, err := d.dirs[0].Checksum()
+	if err != nil {
+		return err
+	}
+	return WriteSumFile(d.dirs[0], first)
+}
+
+// Files implements Dir.Files. It returns the files of all composed directories
+// ordered by the VersionScheme of the directory, or by their names if it has none.
+func (d *MultiDir) Files() ([]File, error) {
+	var (
+		files []File
+		seen  = make(map[string]int)
+		names = make(map[string]string)
+	)
+	for i, dir := range d.dirs {
+		dirFiles, err := dir.Files()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range dirFiles {
+			v := f.Version()
+			if j, ok := seen[v]; ok {
+				return nil, &VersionConflictError{Version: v, Files: [2]string{names[v], f.Name()}, Dirs: [2]int{j, i}}
+			}
+			seen[v], names[v] = i, f.Name()
+			files = append(files, f)
+		}
+	}
+	less := func(i, j int) bool {
+		return files[i].Name() < files[j].Name()
+	}
+	if vs := d.Scheme(); vs != nil {
+		less = func(i, j int) bool {
+			return vs.Less(files[i].Version(), files[j].Version())
+		}
+	}
+	sort.SliceStable(files, less)
+	return files, nil
+}
+
+// Checksum implements Dir.Checksum.
+func (d *MultiDir) Checksum() (HashFile, error) {
+	files, err := d.Files()
+	if err != nil {
+		return nil, err
+	}
+	return checksum(files)
+}
+
+type (
+	// memFile implements fs.File for in-memory files.
+	memFile struct {
+		*bytes.Reader
+		info memFileInfo
+	}
+	// memFileInfo implements fs.FileInfo for in-memory files.
+	memFileInfo struct {
+		name string
+		size int64
+	}
+)
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+func (i memFileInfo) Name() string       { return filepath.Base(i.name) }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() fs.FileMode  { return 0444 }
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memFileInfo) IsDir() bool        { return false }
+func (i memFileInfo) Sys() any           { return nil }
+
 var (
 	// templateFuncs contains the template.FuncMap for the DefaultFormatter.
 	templateFuncs = template.FuncMap{"now": func() string { return time.Now().UTC().Format("20060102150405") }}
@@ -203,7 +676,7 @@ var (
 		templates: []struct{ N, C *template.Template }{
 			{
 				N: template.Must(template.New("").Funcs(templateFuncs).Parse(
-					"{{ now }}{{ with .Name }}_{{ . }}{{ end }}.sql",
+					"{{ with .Version }}{{ . }}{{ else }}{{ now }}{{ end }}{{ with .Name }}_{{ . }}{{ end }}.sql",
 				)),
 				C: template.Must(template.New("").Funcs(templateFuncs).Parse(
 					`{{ range .Changes }}{{ with .Comment }}-- {{ println . }}{{ end }}{{ printf "%s;\n" .Cmd }}{{ end }}`,
@@ -394,6 +867,13 @@ func directive(content, name string, prefix ...string) (string, bool) {
 	return "", false
 }
 
+// text returns the given file data as a string, without the UTF-8 byte order mark
+// that is added by some editors (e.g. on Windows). The mark is kept in the file data,
+// as it is part of the file checksum, but must be skipped when reading directives.
+func text(b []byte) string {
+	return strings.TrimPrefix(string(b), bom)
+}
+
 // readHashFile reads the HashFile from the given Dir.
 func readHashFile(dir Dir) (HashFile, error) {
 	f, err := dir.Open(HashFileName)
@@ -0,0 +1,13 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build !windows
+
+package migrate
+
+// longPath returns the given path as is, as the path length
+// limit (MAX_PATH) applies only to the Windows API.
+func longPath(path string) string {
+	return path
+}
@@ -5,11 +5,17 @@
 package migrate_test
 
 import (
-	_ "embed"
+	"context"
+	"embed"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"ariga.io/atlas/sql/migrate"
@@ -154,7 +160,8 @@ func TestLocalDir(t *testing.T) {
 
 	// Does not create a dir for you.
 	d, err = migrate.NewLocalDir("foo/bar")
-	require.EqualError(t, err, "sql/migrate: stat foo/bar: no such file or directory")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.ErrorContains(t, err, "sql/migrate: ")
 	require.Nil(t, d)
 
 	// Open and WriteFile work.
@@ -195,3 +202,361 @@ func TestLocalDir(t *testing.T) {
 	require.Equal(t, "2.10.x-20", files[1].Version())
 	require.Equal(t, "description", files[1].Desc())
 }
+
+func TestLocalDir_LongPath(t *testing.T) {
+	// Deeply nested directories exceed the MAX_PATH limit on Windows.
+	path := t.TempDir()
+	for i := 0; i < 10; i++ {
+		path = filepath.Join(path, strings.Repeat(strconv.Itoa(i), 30))
+	}
+	require.NoError(t, os.MkdirAll(path, 0755))
+	d, err := migrate.NewLocalDir(path)
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t(c int);")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	require.NoError(t, migrate.Validate(d))
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	unlock, err := d.Lock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
+
+func TestLocalDir_BOM(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("\ufeff-- atlas:reverse\nDROP TABLE t;")))
+	require.NoError(t, d.WriteFile("2_init.sql", []byte("\ufeffCREATE TABLE t(c int);\r\nCREATE TABLE t2(c int);\r\n")))
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 1, "reverse files are detected after the byte order mark")
+	stmts, err := files[0].Stmts()
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE t(c int);", "CREATE TABLE t2(c int);"}, stmts)
+
+	// The mark is part of the file data, and therefore, the checksum
+	// of existing directories does not change.
+	require.Equal(t, "\ufeffCREATE TABLE t(c int);\r\nCREATE TABLE t2(c int);\r\n", string(files[0].Bytes()))
+	sum1, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("2_init.sql", []byte("CREATE TABLE t(c int);\r\nCREATE TABLE t2(c int);\r\n")))
+	sum2, err := d.Checksum()
+	require.NoError(t, err)
+	require.NotEqual(t, sum1.Sum(), sum2.Sum())
+}
+
+func TestChecksum_CaseConflict(t *testing.T) {
+	for _, names := range [][2]string{
+		{"1_INIT.sql", "1_init.sql"},
+		{"V1_users.sql", "v1_init.sql"},
+	} {
+		m := migrate.NewOverlayDir(fstest.MapFS{})
+		require.NoError(t, m.WriteFile(names[0], []byte("CREATE TABLE t(c int);")))
+		require.NoError(t, m.WriteFile(names[1], []byte("CREATE TABLE t(c int);")))
+		_, err := m.Checksum()
+		var cerr *migrate.CaseConflictError
+		require.ErrorAs(t, err, &cerr)
+		require.Equal(t, names, cerr.Files)
+	}
+	// Versions that are equal are not conflicting.
+	m := migrate.NewOverlayDir(fstest.MapFS{})
+	require.NoError(t, m.WriteFile("1_a.sql", []byte("CREATE TABLE t(c int);")))
+	require.NoError(t, m.WriteFile("1_b.sql", []byte("CREATE TABLE t(c int);")))
+	_, err := m.Checksum()
+	require.NoError(t, err)
+}
+
+func TestLocalDir_Lock(t *testing.T) {
+	p := t.TempDir()
+	d, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+
+	// Files are written atomically, without leaving temporary files.
+	require.NoError(t, d.WriteFile("1.sql", []byte("CREATE TABLE t(c int);")))
+	require.NoError(t, d.WriteFile("1.sql", []byte("CREATE TABLE t(c int, d int);")))
+	entries, err := os.ReadDir(p)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	fi, err := entries[0].Info()
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0644), fi.Mode().Perm())
+	b, err := os.ReadFile(filepath.Join(p, "1.sql"))
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE t(c int, d int);", string(b))
+
+	unlock, err := d.Lock(context.Background())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = d.Lock(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	// Lock files are not migration files.
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.NoError(t, unlock())
+	unlock, err = migrate.LockDir(context.Background(), migrate.NewSchemeDir(d, &migrate.TimestampScheme{}))
+	require.NoError(t, err)
+
+	// Abandoned locks are taken over.
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(p, ".atlas.lock"), old, old))
+	unlock2, err := d.Lock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, unlock2())
+	require.Error(t, unlock(), "lock was taken over")
+
+	// Dirs that do not support locking are not locked.
+	unlock, err = migrate.LockDir(context.Background(), migrate.NewOverlayDir(subFS))
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
+
+func TestLocalDir_ConcurrentWritePlan(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pl := migrate.NewPlanner(nil, d, migrate.PlanWithVersionScheme(&migrate.TimestampScheme{}))
+			require.NoError(t, pl.WritePlan(&migrate.Plan{Name: "p" + strconv.Itoa(i), Changes: []*migrate.Change{{Cmd: "SELECT " + strconv.Itoa(i)}}}))
+		}(i)
+	}
+	wg.Wait()
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 5)
+	// The sum file covers all files.
+	require.NoError(t, migrate.Validate(d))
+}
+
+//go:embed testdata/migrate/sub
+var subFS embed.FS
+
+func TestOverlayDir(t *testing.T) {
+	base, err := fs.Sub(subFS, "testdata/migrate/sub")
+	require.NoError(t, err)
+	d := migrate.NewOverlayDir(base)
+	require.NoError(t, migrate.Validate(d))
+
+	// Base files are read from the embedded FS.
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, "3_partly.sql", files[2].Name())
+
+	// Writes are kept in memory and shadow base files.
+	require.NoError(t, d.WriteFile("4_new.sql", []byte("CREATE TABLE t(c int);")))
+	require.NoError(t, d.WriteFile("1.a_sub.up.sql", []byte("CREATE TABLE t_sub(c int);")))
+	require.ErrorIs(t, d.WriteFile("../x.sql", nil), fs.ErrInvalid)
+	files, err = d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+	require.Equal(t, "CREATE TABLE t_sub(c int);", string(files[0].Bytes()))
+	require.Equal(t, "4_new.sql", files[3].Name())
+	require.Equal(t, []string{"1.a_sub.up.sql", "4_new.sql"}, d.Overlay())
+	f, err := d.Open("4_new.sql")
+	require.NoError(t, err)
+	info, err := f.Stat()
+	require.NoError(t, err)
+	require.Equal(t, "4_new.sql", info.Name())
+	require.NoError(t, f.Close())
+	require.ErrorIs(t, migrate.Validate(d), migrate.ErrChecksumMismatch)
+
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	require.NoError(t, migrate.Validate(d))
+
+	// Committing writes the files to the target directory and resets the layer.
+	local, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.Commit(local))
+	require.Empty(t, d.Overlay())
+	files, err = local.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	_, err = os.Stat(filepath.Join(local.Path(), migrate.HashFileName))
+	require.NoError(t, err)
+
+	// Reset discards the in-memory layer.
+	require.NoError(t, d.WriteFile("5_new.sql", nil))
+	d.Reset()
+	files, err = d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+}
+
+func TestMultiDir(t *testing.T) {
+	_, err := migrate.NewMultiDir()
+	require.EqualError(t, err, "sql/migrate: no dirs given")
+
+	base, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	addon, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	for d, files := range map[*migrate.LocalDir][]string{
+		base:  {"1_init.sql", "3_users.sql"},
+		addon: {"2_addon.sql"},
+	} {
+		for _, f := range files {
+			require.NoError(t, d.WriteFile(f, []byte("CREATE TABLE "+f+"(c int);")))
+		}
+		sum, err := d.Checksum()
+		require.NoError(t, err)
+		require.NoError(t, migrate.WriteSumFile(d, sum))
+	}
+	d, err := migrate.NewMultiDir(base, addon)
+	require.NoError(t, err)
+	require.NoError(t, migrate.Validate(d))
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, "1_init.sql", files[0].Name())
+	require.Equal(t, "2_addon.sql", files[1].Name())
+	require.Equal(t, "3_users.sql", files[2].Name())
+	b, err := fs.ReadFile(d, "2_addon.sql")
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE 2_addon.sql(c int);", string(b))
+
+	// Changes to one of the composed directories are detected.
+	require.NoError(t, addon.WriteFile("4_addon.sql", nil))
+	require.ErrorIs(t, migrate.Validate(d), migrate.ErrChecksumMismatch)
+	require.NoError(t, os.Remove(filepath.Join(addon.Path(), "4_addon.sql")))
+
+	// New files and sum files are written to the first directory.
+	require.NoError(t, d.WriteFile("4_new.sql", nil))
+	require.ErrorIs(t, d.WriteFile(migrate.HashFileName, []byte("h1:invalid\n")), migrate.ErrChecksumMismatch)
+	require.ErrorIs(t, migrate.Validate(base), migrate.ErrChecksumMismatch)
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	require.NoError(t, migrate.Validate(base))
+	require.NoError(t, migrate.Validate(d))
+	files, err = base.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	// Version conflicts.
+	require.NoError(t, addon.WriteFile("3_other.sql", nil))
+	_, err = migrate.NewMultiDir(base, addon)
+	var verr *migrate.VersionConflictError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "3", verr.Version)
+	require.Equal(t, [2]string{"3_users.sql", "3_other.sql"}, verr.Files)
+	_, err = d.Files()
+	require.ErrorAs(t, err, &verr)
+}
+
+func TestMultiDir_Scheme(t *testing.T) {
+	var (
+		scheme = &migrate.SequenceScheme{}
+		dirs   = make([]migrate.Dir, 2)
+	)
+	for i, name := range []string{"2_init.sql", "10_addon.sql"} {
+		d, err := migrate.NewLocalDir(t.TempDir())
+		require.NoError(t, err)
+		require.NoError(t, d.WriteFile(name, []byte("CREATE TABLE t(c int);")))
+		dirs[i] = migrate.NewSchemeDir(d, scheme)
+	}
+	d, err := migrate.NewMultiDir(dirs...)
+	require.NoError(t, err)
+	require.Equal(t, scheme, d.Scheme())
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "2_init.sql", files[0].Name(), "files are ordered by the version scheme")
+	require.Equal(t, "10_addon.sql", files[1].Name())
+
+	// Without a scheme, files are ordered by their names.
+	d, err = migrate.NewMultiDir(dirs[0].(*migrate.SchemeDir).Dir, dirs[1].(*migrate.SchemeDir).Dir)
+	require.NoError(t, err)
+	require.Nil(t, d.Scheme())
+	files, err = d.Files()
+	require.NoError(t, err)
+	require.Equal(t, "10_addon.sql", files[0].Name())
+}
+
+func TestSortRevisions(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	revs := []*migrate.Revision{{Version: "1"}, {Version: "10"}, {Version: "2"}, {Version: "9"}}
+	migrate.SortRevisions(d, revs)
+	require.Equal(t, "9", revs[3].Version, "directories without a scheme are not sorted")
+	migrate.SortRevisions(migrate.NewSchemeDir(d, &migrate.SequenceScheme{}), revs)
+	require.Equal(t, []*migrate.Revision{{Version: "1"}, {Version: "2"}, {Version: "9"}, {Version: "10"}}, revs)
+}
+
+func TestSchemeDir(t *testing.T) {
+	p := t.TempDir()
+	local, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	scheme := &migrate.SequenceScheme{Prefix: "billing-"}
+	d := migrate.NewSchemeDir(local, scheme)
+	pl := migrate.NewPlanner(nil, d, migrate.PlanWithVersionScheme(scheme))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, pl.WritePlan(&migrate.Plan{Name: "add", Changes: []*migrate.Change{{Cmd: "cmd"}}}))
+	}
+	require.NoError(t, pl.WritePlan(&migrate.Plan{Changes: []*migrate.Change{{Cmd: "cmd"}}}))
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 11)
+	// Versions are ordered by their numeric value.
+	require.Equal(t, "billing-1_add.sql", files[0].Name())
+	require.Equal(t, "billing-9", files[8].Version())
+	require.Equal(t, "billing-10", files[9].Version())
+	require.Equal(t, "add", files[9].Desc())
+	require.Equal(t, "billing-11.sql", files[10].Name())
+	require.Equal(t, "", files[10].Desc())
+	require.NoError(t, migrate.Validate(d))
+
+	// Files that do not match the scheme are rejected.
+	require.NoError(t, os.WriteFile(filepath.Join(p, "20220101000000_init.sql"), nil, 0644))
+	_, err = d.Files()
+	require.EqualError(t, err, `sql/migrate: file "20220101000000_init.sql" does not match the version scheme`)
+}
+
+func TestVersionScheme(t *testing.T) {
+	seq := &migrate.SequenceScheme{Width: 4}
+	v, err := seq.Next("")
+	require.NoError(t, err)
+	require.Equal(t, "0001", v)
+	v, err = seq.Next("0099")
+	require.NoError(t, err)
+	require.Equal(t, "0100", v)
+	_, err = seq.Next("abc")
+	require.EqualError(t, err, `sql/migrate: invalid sequential version "abc"`)
+	require.True(t, seq.Less("9", "0010"))
+	v, desc, ok := seq.Split("0001_init.sql")
+	require.True(t, ok)
+	require.Equal(t, "0001", v)
+	require.Equal(t, "init", desc)
+	for _, n := range []string{"init.sql", "_init.sql", "1.a_init.sql", "1-init.sql"} {
+		_, _, ok = seq.Split(n)
+		require.False(t, ok, n)
+	}
+
+	ts := &migrate.TimestampScheme{Prefix: "auth_", Precision: time.Millisecond}
+	v, err = ts.Next("")
+	require.NoError(t, err)
+	require.Regexp(t, `^auth_\d{17}$`, v)
+	_, err = ts.Next("auth_99999999999999999")
+	require.EqualError(t, err, `sql/migrate: invalid timestamp version "auth_99999999999999999"`)
+	// Versions in the future are followed by the next time unit.
+	v, err = ts.Next("auth_30000101000000999")
+	require.NoError(t, err)
+	require.Equal(t, "auth_30000101000001000", v)
+	v, desc, ok = ts.Split("auth_20220101120000123_add_users.sql")
+	require.True(t, ok)
+	require.Equal(t, "auth_20220101120000123", v)
+	require.Equal(t, "add_users", desc)
+	_, _, ok = ts.Split("auth_20220101120000_add_users.sql")
+	require.False(t, ok)
+	_, err = (&migrate.TimestampScheme{Precision: time.Minute}).Next("")
+	require.EqualError(t, err, "sql/migrate: unsupported timestamp precision 1m0s")
+}
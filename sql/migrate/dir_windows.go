@@ -0,0 +1,32 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build windows
+
+package migrate
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxPath is the maximum length of paths in the Windows API,
+// unless they are prefixed with the extended-length prefix.
+const maxPath = 260
+
+// longPath returns the extended-length form of the given path, if it exceeds
+// the MAX_PATH limit of Windows (e.g. deeply nested migration directories).
+// Unlike the os package, which does it only for absolute paths, relative paths
+// are resolved first.
+// See: https://learn.microsoft.com/en-us/windows/win32/fileio/maximum-file-path-limitation
+func longPath(path string) string {
+	if len(path) < maxPath || strings.HasPrefix(path, `\\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return `\\?\` + abs
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -41,9 +42,30 @@ func (s *Stmt) Directive(name string) (ds []string) {
 	return
 }
 
-// stmts provides a generic implementation for extracting
-// SQL statements from the given file contents.
-func stmts(input string) ([]*Stmt, error) {
+// A ScanError is returned by Stmts when the input cannot be split into
+// statements. Its position points to the start of the offending token
+// (e.g. the opening quote of an unclosed string).
+type ScanError struct {
+	Pos    int    // byte offset in the input
+	Line   int    // line number, starting at 1
+	Column int    // column number in runes, starting at 1
+	Msg    string // error message
+}
+
+// Error implements the error interface.
+func (e *ScanError) Error() string {
+	return fmt.Sprintf("%s at line %d, column %d", e.Msg, e.Line, e.Column)
+}
+
+// Stmts splits the given SQL input into statements. It is the scanner used for
+// reading migration files, and supports the syntax shared by the builtin dialects:
+// quoted strings and identifiers, comments, dollar-quoted strings (PostgreSQL), custom
+// delimiters set by the atlas:delimiter directive or the DELIMITER command (MySQL), and
+// BEGIN ... END bodies of routines and triggers that are not wrapped by a delimiter.
+//
+// The position of each statement is its byte offset in the input, and a *ScanError
+// is returned if the input cannot be scanned. Stmts is safe to use with any input.
+func Stmts(input string) ([]*Stmt, error) {
 	var stmts []*Stmt
 	l, err := newLex(input)
 	if err != nil {
@@ -62,11 +84,13 @@ func stmts(input string) ([]*Stmt, error) {
 }
 
 type lex struct {
+	src      string // original input
 	input    string
 	pos      int      // current phase position
 	total    int      // total bytes scanned so far
 	width    int      // size of latest rune
 	delim    string   // configured delimiter
+	crlf     bool     // input uses CRLF line endings
 	comments []string // collected comments
 }
 
@@ -74,27 +98,45 @@ const (
 	eos          = -1
 	delimiter    = ";"
 	delimiterCmd = "delimiter"
+	// bom is the UTF-8 byte order mark that is added
+	// by some editors (e.g. on Windows) to text files.
+	bom = "\ufeff"
 )
 
+// reRoutine matches the beginning of statements that create stored routines
+// or triggers, whose bodies may contain BEGIN ... END compound statements.
+var reRoutine = regexp.MustCompile(`(?i)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:DEFINER\s*=\s*\S+\s+)?(?:AGGREGATE\s+|TEMP\s+|TEMPORARY\s+)?(?:PROCEDURE|FUNCTION|TRIGGER|EVENT)\b`)
+
 func newLex(input string) (*lex, error) {
-	l := &lex{input: input, delim: delimiter}
-	if d, ok := directive(input, directiveDelimiter, directivePrefixSQL); ok {
+	l := &lex{src: input, input: input, delim: delimiter, crlf: strings.Contains(input, "\r\n")}
+	// Positions are reported relative to the original input.
+	if strings.HasPrefix(input, bom) {
+		l.input = input[len(bom):]
+		l.total = len(bom)
+	}
+	if d, ok := directive(l.input, directiveDelimiter, directivePrefixSQL); ok {
 		if err := l.setDelim(d); err != nil {
-			return nil, err
+			return nil, l.errorf(0, "%v", err)
 		}
-		parts := strings.SplitN(input, "\n", 2)
+		parts := strings.SplitN(l.input, "\n", 2)
 		if len(parts) == 1 {
-			return nil, fmt.Errorf("not input found after delimiter %q", d)
+			return nil, l.errorf(0, "not input found after delimiter %q", d)
 		}
 		l.input = parts[1]
+		l.total += len(parts[0]) + 1
 	}
 	return l, nil
 }
 
 func (l *lex) stmt() (*Stmt, error) {
 	var (
-		depth int
-		text  string
+		depth   int
+		open    int // position of the outermost unclosed '('
+		block   int // depth of BEGIN ... END blocks
+		begin   int // position of the outermost unclosed block
+		checked bool
+		routine bool // statement creates a routine or a trigger
+		text    string
 	)
 	l.skipSpaces()
 Scan:
@@ -103,7 +145,9 @@ Scan:
 		case r == eos:
 			switch {
 			case depth > 0:
-				return nil, errors.New("unclosed parentheses")
+				return nil, l.errorf(open, "unclosed parentheses")
+			case block > 0:
+				return nil, l.errorf(begin, "unclosed BEGIN ... END block")
 			case l.pos > 0:
 				text = l.input
 				break Scan
@@ -111,10 +155,13 @@ Scan:
 				return nil, io.EOF
 			}
 		case r == '(':
+			if depth == 0 {
+				open = l.total - l.width
+			}
 			depth++
 		case r == ')':
 			if depth == 0 {
-				return nil, fmt.Errorf("unexpected ')' at position %d", l.pos)
+				return nil, l.errorf(l.total-l.width, "unexpected ')'")
 			}
 			depth--
 		case r == '\'', r == '"', r == '`':
@@ -129,10 +176,28 @@ Scan:
 				return nil, err
 			}
 		// Delimiters take precedence over comments.
-		case depth == 0 && strings.HasPrefix(l.input[l.pos-l.width:], l.delim):
+		case depth == 0 && block == 0 && strings.HasPrefix(l.input[l.pos-l.width:], l.delim):
 			l.addPos(len(l.delim) - l.width)
 			text = l.input[:l.pos]
 			break Scan
+		// Dollar-quoted strings (e.g. PostgreSQL function bodies) may contain
+		// the default delimiter, but custom delimiters take precedence.
+		case r == '$' && l.delim == delimiter:
+			if err := l.skipDollarQuote(); err != nil {
+				return nil, err
+			}
+		// Routine bodies may contain the default delimiter, but similar
+		// to dollar-quoted strings, custom delimiters take precedence.
+		case unicode.IsLetter(r) && l.delim == delimiter:
+			if !checked {
+				checked, routine = true, reRoutine.MatchString(l.input)
+			}
+			if routine && !l.inWord() {
+				if block == 0 {
+					begin = l.total - l.width
+				}
+				block = l.block(block)
+			}
 		case r == '#':
 			l.comment("#", "\n")
 		case r == '-' && l.next() == '-':
@@ -155,9 +220,9 @@ func (l *lex) next() rune {
 }
 
 func (l *lex) pick() rune {
-	p, w := l.pos, l.width
+	p, t, w := l.pos, l.total, l.width
 	r := l.next()
-	l.pos, l.width = p, w
+	l.pos, l.total, l.width = p, t, w
 	return r
 }
 
@@ -167,10 +232,11 @@ func (l *lex) addPos(p int) {
 }
 
 func (l *lex) skipQuote(quote rune) error {
+	start := l.total - l.width
 	for {
 		switch r := l.next(); {
 		case r == eos:
-			return fmt.Errorf("unclosed quote %q", quote)
+			return l.errorf(start, "unclosed quote %q", quote)
 		case r == '\\':
 			l.next()
 		case r == quote:
@@ -179,6 +245,79 @@ func (l *lex) skipQuote(quote rune) error {
 	}
 }
 
+// skipDollarQuote skips a dollar-quoted string, if the current '$' rune
+// starts one. e.g. $$body$$ or $tag$body$tag$.
+func (l *lex) skipDollarQuote() error {
+	start := l.pos - l.width
+	// A '$' that is part of an identifier (e.g. a$b) does not start a string.
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(l.input[:start]); r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return nil
+		}
+	}
+	i := strings.IndexFunc(l.input[l.pos:], func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if i == -1 || l.input[l.pos+i] != '$' || i > 0 && unicode.IsDigit(rune(l.input[l.pos])) {
+		return nil
+	}
+	tag := l.input[start : l.pos+i+1]
+	end := strings.Index(l.input[l.pos+i+1:], tag)
+	if end == -1 {
+		return l.errorf(l.total-l.width, "unclosed dollar-quoted string %q", tag)
+	}
+	l.addPos(i + 1 + end + len(tag))
+	return nil
+}
+
+// inWord reports if the current rune continues a word (e.g. an identifier).
+func (l *lex) inWord() bool {
+	start := l.pos - l.width
+	if start == 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(l.input[:start])
+	return r == '_' || r == '$' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// word scans the word that starts at the current rune.
+func (l *lex) word() string {
+	start := l.pos - l.width
+	for r := l.pick(); r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r); r = l.pick() {
+		l.next()
+	}
+	return l.input[start:l.pos]
+}
+
+// block scans the word that starts at the current rune, and returns the
+// depth of the BEGIN ... END blocks after it. CASE expressions and statements
+// are counted as well, as they are also terminated by END. Other flow control
+// statements (e.g. IF ... END IF) are not counted, as they can only appear
+// inside blocks.
+func (l *lex) block(depth int) int {
+	switch w := l.word(); {
+	case strings.EqualFold(w, "BEGIN"), strings.EqualFold(w, "CASE"):
+		return depth + 1
+	case strings.EqualFold(w, "END") && depth > 0:
+		rest := strings.TrimLeftFunc(l.input[l.pos:], unicode.IsSpace)
+		i := strings.IndexFunc(rest, func(r rune) bool {
+			return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if i == -1 {
+			i = len(rest)
+		}
+		switch next := strings.ToUpper(rest[:i]); next {
+		case "IF", "LOOP", "WHILE", "REPEAT", "FOR":
+			return depth
+		// Skip the CASE keyword of END CASE, as it does not start a new block.
+		case "CASE":
+			l.addPos(len(l.input[l.pos:]) - len(rest) + i)
+		}
+		return depth - 1
+	}
+	return depth
+}
+
 func (l *lex) comment(left, right string) {
 	i := strings.Index(l.input[l.pos:], right)
 	// Not a comment.
@@ -197,7 +336,7 @@ func (l *lex) comment(left, right string) {
 	l.input = l.input[l.pos:]
 	l.pos = 0
 	// Double \n separate the comments group from the statement.
-	if strings.HasPrefix(l.input, "\n\n") || right == "\n" && strings.HasPrefix(l.input, "\n") {
+	if hasNewlines(l.input, 2) || right == "\n" && hasNewlines(l.input, 1) {
 		l.comments = nil
 	}
 	l.skipSpaces()
@@ -218,7 +357,10 @@ func (l *lex) emit(text string) *Stmt {
 	if l.delim != delimiter {
 		s.Text = strings.TrimSuffix(s.Text, l.delim)
 	}
-	s.Text = strings.TrimSpace(s.Text)
+	// Leading spaces are not skipped after the DELIMITER command.
+	t := strings.TrimLeftFunc(s.Text, unicode.IsSpace)
+	s.Pos += len(s.Text) - len(t)
+	s.Text = strings.TrimRightFunc(t, unicode.IsSpace)
 	return s
 }
 
@@ -234,11 +376,11 @@ func (l *lex) delimCmd() error {
 	}
 	delim := strings.TrimSpace(l.input[len(delimiterCmd):l.pos])
 	// MySQL client allows quoting delimiters.
-	if strings.HasPrefix(delim, "'") && strings.HasSuffix(delim, "'") {
+	if len(delim) > 1 && strings.HasPrefix(delim, "'") && strings.HasSuffix(delim, "'") {
 		delim = strings.ReplaceAll(delim[1:len(delim)-1], "''", "'")
 	}
 	if err := l.setDelim(delim); err != nil {
-		return err
+		return l.errorf(l.total-l.pos, "%v", err)
 	}
 	// Skip all we saw until now.
 	l.emit(l.input[:l.pos])
@@ -251,5 +393,29 @@ func (l *lex) setDelim(d string) error {
 	}
 	// Unescape delimiters. e.g. "\\n" => "\n".
 	l.delim = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t").Replace(d)
+	// Delimiters that are made of newlines (e.g. "\n\n") match the
+	// line endings of the input, in case it uses CRLF line endings.
+	if l.crlf && !strings.Contains(l.delim, "\r") {
+		l.delim = strings.ReplaceAll(l.delim, "\n", "\r\n")
+	}
 	return nil
 }
+
+// hasNewlines reports if s starts with n line breaks. CRLF
+// line endings are counted the same as LF line endings.
+func hasNewlines(s string, n int) bool {
+	for ; n > 0; n-- {
+		s = strings.TrimPrefix(s, "\r")
+		if !strings.HasPrefix(s, "\n") {
+			return false
+		}
+		s = s[1:]
+	}
+	return true
+}
+
+// errorf returns a ScanError for the given position in the original input.
+func (l *lex) errorf(pos int, format string, args ...any) error {
+	p := PositionOf([]byte(l.src), pos)
+	return &ScanError{Pos: pos, Line: p.Line, Column: p.Column, Msg: fmt.Sprintf(format, args...)}
+}
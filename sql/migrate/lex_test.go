@@ -99,3 +99,95 @@ cmd6;
 	require.Equal(t, []string{"error"}, stmts[6].Directive("lint"))
 	require.Equal(t, []string{"DS101"}, stmts[6].Directive("nolint"))
 }
+
+func TestStmts_ScanError(t *testing.T) {
+	for _, tt := range []struct {
+		input   string
+		wantErr string
+		wantPos int
+	}{
+		{input: "SELECT 1;\nSELECT (1", wantErr: "unclosed parentheses at line 2, column 8", wantPos: 17},
+		{input: "SELECT 1);", wantErr: "unexpected ')' at line 1, column 9", wantPos: 8},
+		{input: "SELECT 1;\n  SELECT 'a;", wantErr: "unclosed quote '\\'' at line 2, column 10", wantPos: 19},
+		{input: "SELECT $$a;", wantErr: `unclosed dollar-quoted string "$$" at line 1, column 8`, wantPos: 7},
+		{input: "CREATE TRIGGER t AFTER INSERT ON t\nBEGIN\n  SELECT 1;", wantErr: "unclosed BEGIN ... END block at line 2, column 1", wantPos: 35},
+		{input: "-- atlas:delimiter \\n\\n", wantErr: `not input found after delimiter "\\n\\n" at line 1, column 1`},
+		{input: "-- atlas:delimiter //\n\nSELECT 'ü'//\nSELECT `a//", wantErr: "unclosed quote '`' at line 4, column 8", wantPos: 44},
+	} {
+		_, err := Stmts(tt.input)
+		require.EqualError(t, err, tt.wantErr)
+		var serr *ScanError
+		require.ErrorAs(t, err, &serr)
+		require.Equal(t, tt.wantPos, serr.Pos)
+	}
+	// Positions are relative to the original input.
+	stmts, err := Stmts("-- atlas:delimiter //\n\nSELECT 1//\n")
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+	require.Equal(t, 23, stmts[0].Pos)
+}
+
+func TestStmts_CRLF(t *testing.T) {
+	lf := "-- atlas:delimiter \\n\\n\n\n-- comment\n\nCREATE TABLE t(c int);\n\n-- nolint\n/*atlas:nolint DS101*/\nDROP TABLE t;\n"
+	want, err := Stmts(lf)
+	require.NoError(t, err)
+	require.Len(t, want, 2)
+	crlf := strings.ReplaceAll(lf, "\n", "\r\n")
+	got, err := Stmts(crlf)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for i := range want {
+		require.Equal(t, want[i].Text, got[i].Text)
+		require.Equal(t, len(want[i].Comments), len(got[i].Comments))
+		require.Equal(t, strings.Index(crlf, got[i].Text), got[i].Pos)
+	}
+	require.Equal(t, []string{"DS101"}, got[1].Directive("nolint"))
+
+	// Lines and columns are counted the same for both line endings.
+	_, err = Stmts("SELECT 1;\r\n\r\n  SELECT 'a;")
+	require.EqualError(t, err, "unclosed quote '\\'' at line 3, column 10")
+}
+
+func TestStmts_BOM(t *testing.T) {
+	input := bom + "-- atlas:delimiter //\n\nSELECT 1//\nSELECT 2//\n"
+	stmts, err := Stmts(input)
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "SELECT 1", stmts[0].Text)
+	require.Equal(t, strings.Index(input, "SELECT 1"), stmts[0].Pos, "positions are relative to the original input")
+
+	stmts, err = Stmts(bom + "SELECT 1;")
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+	require.Equal(t, "SELECT 1;", stmts[0].Text)
+	require.Equal(t, len(bom), stmts[0].Pos)
+
+	// The mark is kept in the file data, but skipped by the directives.
+	f := NewLocalFile("1.sql", []byte(bom+"-- atlas:txmode none\nSELECT 1;"))
+	require.Equal(t, bom+"-- atlas:txmode none\nSELECT 1;", string(f.Bytes()))
+	require.Equal(t, TxModeNone, FileTxMode(f))
+}
+
+func FuzzStmts(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("testdata", "lex", "*.sql"))
+	require.NoError(f, err)
+	for _, name := range files {
+		buf, err := os.ReadFile(name)
+		require.NoError(f, err)
+		f.Add(string(buf))
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		stmts, err := Stmts(input)
+		if err != nil {
+			var serr *ScanError
+			require.ErrorAs(t, err, &serr)
+			require.True(t, serr.Pos >= 0 && serr.Pos <= len(input), "error position out of range")
+			require.True(t, serr.Line >= 1 && serr.Column >= 1)
+			return
+		}
+		for _, s := range stmts {
+			require.True(t, s.Pos >= 0 && s.Pos <= len(input), "statement position out of range")
+			require.True(t, strings.HasPrefix(input[s.Pos:], s.Text), "statement text does not match its position")
+		}
+	})
+}
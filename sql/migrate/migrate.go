@@ -7,10 +7,12 @@ package migrate
 import (
 	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
+	"text/template"
 	"time"
 
 	"ariga.io/atlas/sql/schema"
@@ -25,6 +27,10 @@ type (
 		// Name of the plan. Provided by the user or auto-generated.
 		Name string
 
+		// Version of the plan. Set by the Planner in case it
+		// was configured with a VersionScheme.
+		Version string
+
 		// Reversible describes if the changeset is reversible.
 		Reversible bool
 
@@ -33,6 +39,10 @@ type (
 
 		// Changes defines the list of changeset in the plan.
 		Changes []*Change
+
+		// Reverse holds the plan that reverts this plan, if it was
+		// computed by the Planner. See PlanWithReverse for more info.
+		Reverse *Plan
 	}
 
 	// A Change of migration.
@@ -52,6 +62,11 @@ type (
 
 		// The Source that caused this change, or nil.
 		Source schema.Change
+
+		// NoTx indicates the statement cannot be executed inside a transaction
+		// block, e.g. CREATE INDEX CONCURRENTLY in PostgreSQL. The Planner writes
+		// such changes to their own files, marked with the atlas:txmode directive.
+		NoTx bool
 	}
 )
 
@@ -85,6 +100,22 @@ type (
 		// PlanWithSchemaQualifier allows setting a custom schema to prefix
 		// tables and other resources. An empty string indicates no qualifier.
 		SchemaQualifier *string
+		// ZeroDowntime instructs the driver to decompose risky changes into
+		// their safe multi-step equivalents, that avoid holding long locks
+		// on the modified tables (e.g. CREATE INDEX CONCURRENTLY in PostgreSQL).
+		// Changes that cannot be executed inside a transaction are marked with
+		// Change.NoTx. Drivers that do not support this mode ignore it.
+		ZeroDowntime bool
+		// TargetVersion defines the version of the database the plan is going to
+		// be executed on, and allows planning changes for databases other than the
+		// connected one (e.g. a dev database). If empty, drivers use the version of
+		// the connected database. Planning changes that rely on features that are not
+		// available in the target version fails with an error.
+		TargetVersion string
+		// Cluster defines the cluster the changes are executed on, for databases
+		// that replicate DDL statements across a cluster (e.g. ON CLUSTER in
+		// ClickHouse). Drivers that do not support clusters ignore it.
+		Cluster string
 	}
 
 	// PlanOption allows configuring a drivers' plan using functional arguments.
@@ -151,11 +182,18 @@ type (
 	// Planner can plan the steps to take to migrate from one state to another. It uses the enclosed Dir to write
 	// those changes to versioned migration files.
 	Planner struct {
-		drv  Driver       // driver to use
-		dir  Dir          // where migration files are stored and read from
-		fmt  Formatter    // how to format a plan to migration files
-		sum  bool         // whether to create a sum file for the migration directory
-		opts []PlanOption // driver options
+		drv   Driver              // driver to use
+		dir   Dir                 // where migration files are stored and read from
+		fmt   Formatter           // how to format a plan to migration files
+		sum   bool                // whether to create a sum file for the migration directory
+		opts  []PlanOption        // driver options
+		diff  []schema.DiffOption // diff options
+		vs    VersionScheme       // how to version new migration files
+		split PlanSplitter        // how to split plans into multiple files
+		phase string              // expand/contract phase of written files
+		rev   bool                // whether to plan and write reverse files
+		ckpt  bool                // whether written files are checkpoint files
+		vars  map[string]string   // variables for rendering replayed statements, if enabled
 	}
 
 	// PlannerOption allows managing a Planner using functional arguments.
@@ -198,9 +236,23 @@ type (
 		// Hash is the check-sum of this migration as stated by the migration directories HashFile.
 		Hash string
 		// PartialHashes contains one hash per statement that has been applied on the database.
+		// In case a statement that was expanded by ExecHooks was partially applied, the hashes
+		// are followed by a record of its executed statements, prefixed with "p<n>:".
 		PartialHashes []string
 		// OperatorVersion holds a string representation of the Atlas operator managing this database migration.
 		OperatorVersion string
+		// StmtStats holds the execution statistics of the executed statements.
+		StmtStats []StmtStat
+	}
+
+	// StmtStat holds the execution statistics of a single migration statement.
+	StmtStat struct {
+		// Index of the statement in the migration file.
+		Index int `json:"index"`
+		// Duration it took to execute the statement.
+		Duration time.Duration `json:"duration"`
+		// RowsAffected by the statement, or -1 if it is not reported by the database.
+		RowsAffected int64 `json:"rows_affected"`
 	}
 
 	// RevisionType defines the type of the revision record in the history table.
@@ -216,6 +268,11 @@ type (
 		baselineVer string             // Start the first migration after the given baseline version.
 		allowDirty  bool               // Allow start working on a non-clean database.
 		operator    string             // Revision.OperatorVersion
+		hooks       []ExecHook         // Hooks to run around each statement.
+		vars        map[string]string  // Variables for rendering statements, if enabled.
+		target      *Target            // Target for evaluating conditional directives, if enabled.
+		skipSeeds   bool               // Skip the execution of seed files.
+		failOn      []string           // Warning classes that fail the execution.
 	}
 
 	// ExecutorOption allows configuring an Executor using functional arguments.
@@ -267,6 +324,73 @@ func PlanWithSchemaQualifier(q string) PlannerOption {
 	}
 }
 
+// PlanWithZeroDowntime allows enabling the zero-downtime planning
+// mode of the driver. See PlanOptions.ZeroDowntime for more info.
+func PlanWithZeroDowntime(b bool) PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.ZeroDowntime = b
+		})
+	}
+}
+
+// PlanWithTargetVersion configures the Planner to plan the changes for the
+// given database version. See PlanOptions.TargetVersion for more info.
+func PlanWithTargetVersion(v string) PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.TargetVersion = v
+		})
+	}
+}
+
+// PlanWithCluster configures the Planner to plan the changes for
+// the given cluster. See PlanOptions.Cluster for more info.
+func PlanWithCluster(name string) PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.Cluster = name
+		})
+	}
+}
+
+// PlanWithVersionScheme configures the Planner to version the
+// written migration files using the given VersionScheme.
+func PlanWithVersionScheme(s VersionScheme) PlannerOption {
+	return func(p *Planner) {
+		p.vs = s
+	}
+}
+
+// PlanWithSplitter configures the Planner to split the written plans
+// into multiple migration files using the given PlanSplitter. Note, the
+// configured Formatter is expected to name the files by Plan.Version,
+// as the DefaultFormatter does.
+func PlanWithSplitter(s PlanSplitter) PlannerOption {
+	return func(p *Planner) {
+		p.split = s
+	}
+}
+
+// PlanWithReverse configures the Planner to plan the reverse of each planned changeset
+// (see PlanReverse), and write it next to its migration file, with the same version and
+// the ".down.sql" suffix. Reverse files start with the "atlas:reverse" directive, and they
+// are neither executed nor part of the directory checksum. Note, the configured Formatter
+// is expected to name the files by Plan.Version, as the DefaultFormatter does.
+func PlanWithReverse(b bool) PlannerOption {
+	return func(p *Planner) {
+		p.rev = b
+	}
+}
+
+// PlanWithDiffOptions allows passing options, such as hooks,
+// to the Differ before the changes are planned.
+func PlanWithDiffOptions(opts ...schema.DiffOption) PlannerOption {
+	return func(p *Planner) {
+		p.diff = append(p.diff, opts...)
+	}
+}
+
 // PlanFormat sets the Formatter of a Planner.
 func PlanFormat(fmt Formatter) PlannerOption {
 	return func(p *Planner) {
@@ -274,6 +398,21 @@ func PlanFormat(fmt Formatter) PlannerOption {
 	}
 }
 
+// PlanWithTemplateVars configures the Planner to render the statements of the migration
+// files with the given variables when they are replayed on the dev database. See the
+// WithTemplateVars option of the Executor for more info.
+func PlanWithTemplateVars(vars map[string]string) PlannerOption {
+	return func(p *Planner) {
+		if len(vars) == 0 {
+			return
+		}
+		p.vars = make(map[string]string, len(vars))
+		for k, v := range vars {
+			p.vars[k] = v
+		}
+	}
+}
+
 // PlanWithChecksum allows setting if the hash-sum functionality
 // for the migration directory is enabled or not.
 func PlanWithChecksum(b bool) PlannerOption {
@@ -304,29 +443,52 @@ func (p *Planner) PlanSchema(ctx context.Context, name string, to StateReader) (
 }
 
 func (p *Planner) plan(ctx context.Context, name string, to StateReader, realmScope bool) (*Plan, error) {
-	from, err := NewExecutor(p.drv, p.dir, NopRevisionReadWriter{})
+	current, err := p.replay(ctx, realmScope)
 	if err != nil {
 		return nil, err
 	}
-	current, err := from.Replay(ctx, func() StateReader {
-		if realmScope {
-			return RealmConn(p.drv, nil)
-		}
-		// In case the scope is the schema connection,
-		// inspect it and return its connected realm.
-		return SchemaConn(p.drv, "", nil)
-	}())
+	desired, err := to.ReadState(ctx)
 	if err != nil {
 		return nil, err
 	}
-	desired, err := to.ReadState(ctx)
+	return p.planChanges(ctx, name, current, desired, realmScope)
+}
+
+// replay replays the migration directory on the dev database and returns its state.
+func (p *Planner) replay(ctx context.Context, realmScope bool) (*schema.Realm, error) {
+	var opts []ExecutorOption
+	if p.vars != nil {
+		opts = append(opts, WithTemplateVars(p.vars))
+	}
+	from, err := NewExecutor(p.drv, p.dir, NopRevisionReadWriter{}, opts...)
 	if err != nil {
 		return nil, err
 	}
-	var changes []schema.Change
+	return from.Replay(ctx, func() StateReader {
+		if realmScope {
+			// Settings and objects are inspected as well,
+			// as they may be modified by the migration files.
+			return RealmConn(p.drv, &schema.InspectRealmOption{
+				Mode: schema.InspectSchemas | schema.InspectTables | schema.InspectSettings | schema.InspectObjects | schema.InspectViews | schema.InspectTriggers | schema.InspectFuncs | schema.InspectSequences,
+			})
+		}
+		// In case the scope is the schema connection,
+		// inspect it and return its connected realm.
+		return SchemaConn(p.drv, "", &schema.InspectOptions{
+			Mode: schema.InspectSchemas | schema.InspectTables | schema.InspectObjects | schema.InspectViews | schema.InspectTriggers | schema.InspectFuncs | schema.InspectSequences,
+		})
+	}())
+}
+
+// planChanges plans the changes required to move from the current state to the desired one.
+func (p *Planner) planChanges(ctx context.Context, name string, current, desired *schema.Realm, realmScope bool) (*Plan, error) {
+	var (
+		err     error
+		changes []schema.Change
+	)
 	switch {
 	case realmScope:
-		changes, err = p.drv.RealmDiff(current, desired)
+		changes, err = schema.NewDiffer(p.drv, p.diff...).RealmDiff(current, desired)
 	default:
 		switch n, m := len(current.Schemas), len(desired.Schemas); {
 		case n == 0:
@@ -344,7 +506,7 @@ func (p *Planner) plan(ctx context.Context, name string, to StateReader, realmSc
 			if s1.Name != s2.Name {
 				s1.Name = s2.Name
 			}
-			changes, err = p.drv.SchemaDiff(&s1, &s2)
+			changes, err = schema.NewDiffer(p.drv, p.diff...).SchemaDiff(&s1, &s2)
 		}
 	}
 	if err != nil {
@@ -353,19 +515,74 @@ func (p *Planner) plan(ctx context.Context, name string, to StateReader, realmSc
 	if len(changes) == 0 {
 		return nil, ErrNoPlan
 	}
-	return p.drv.PlanChanges(ctx, name, changes, p.opts...)
+	plan, err := p.drv.PlanChanges(ctx, name, changes, p.opts...)
+	if err != nil || !p.rev {
+		return plan, err
+	}
+	if plan.Reverse, err = PlanReverse(ctx, p.drv, name, changes, p.opts...); err != nil {
+		return nil, err
+	}
+	return plan, nil
 }
 
 // WritePlan writes the given Plan to the Dir based on the configured Formatter.
-func (p *Planner) WritePlan(plan *Plan) error {
-	// Format the plan into files.
-	files, err := p.fmt.Format(plan)
+// In case the Planner was configured with a PlanSplitter, the plan is split and
+// each part is written with its own version, in order. Dirs that implement the
+// DirLocker interface are locked while the plan is written.
+func (p *Planner) WritePlan(plan *Plan) (err error) {
+	// The directory is locked while the next versions are
+	// computed, and the files and the sum file are written.
+	unlock, err := LockDir(context.Background(), p.dir)
 	if err != nil {
 		return err
 	}
-	// Store the files in the migration directory.
-	for _, f := range files {
-		if err := p.dir.WriteFile(f.Name(), f.Bytes()); err != nil {
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+	plans := []*Plan{plan}
+	if p.split != nil {
+		if plan.Reverse != nil {
+			return errors.New("sql/migrate: plans with a reverse plan cannot be split")
+		}
+		var err error
+		if plans, err = p.split(plan); err != nil {
+			return err
+		}
+	}
+	// Changes that cannot be executed in a transaction are split out of the
+	// transactional ones. Plans with a reverse plan are written as is.
+	if plan.Reverse == nil {
+		var split []*Plan
+		for _, pl := range plans {
+			split = append(split, splitByTx(pl)...)
+		}
+		plans = split
+	}
+	vs := p.vs
+	// Split plans must be written with different versions, and
+	// reverse plans must be written with the version of their plan.
+	if vs == nil && (len(plans) > 1 || plan.Reverse != nil) {
+		vs = &TimestampScheme{}
+	}
+	var last string
+	for i, plan := range plans {
+		if vs != nil && plan.Version == "" {
+			v, err := func() (string, error) {
+				if i == 0 {
+					return nextVersion(p.dir, vs)
+				}
+				return vs.Next(last)
+			}()
+			if err != nil {
+				return err
+			}
+			cp := *plan
+			cp.Version, plan = v, &cp
+		}
+		last = plan.Version
+		if err := p.writePlan(plan); err != nil {
 			return err
 		}
 	}
@@ -380,6 +597,49 @@ func (p *Planner) WritePlan(plan *Plan) error {
 	return nil
 }
 
+// writePlan formats the given plan and stores its files in the Dir.
+func (p *Planner) writePlan(plan *Plan) error {
+	files, err := p.fmt.Format(plan)
+	if err != nil {
+		return err
+	}
+	notx := isNoTx(plan)
+	for _, f := range files {
+		b := f.Bytes()
+		if notx {
+			b = append([]byte(directivePrefixSQL+"atlas:"+directiveTxMode+" "+TxModeNone+"\n"), b...)
+		}
+		if p.phase != "" {
+			b = append([]byte(directivePrefixSQL+"atlas:"+directivePhase+" "+p.phase+"\n"), b...)
+		}
+		if p.ckpt {
+			b = append([]byte(directivePrefixSQL+"atlas:"+directiveCheckpoint+"\n"), b...)
+		}
+		if err := p.dir.WriteFile(f.Name(), b); err != nil {
+			return err
+		}
+	}
+	if plan.Reverse == nil {
+		return nil
+	}
+	rev := *plan.Reverse
+	rev.Name, rev.Version = plan.Name, plan.Version
+	if files, err = p.fmt.Format(&rev); err != nil {
+		return err
+	}
+	for _, f := range files {
+		b := f.Bytes()
+		if notx {
+			b = append([]byte(directivePrefixSQL+"atlas:"+directiveTxMode+" "+TxModeNone+"\n"), b...)
+		}
+		b = append([]byte(directivePrefixSQL+"atlas:"+directiveReverse+" "+f.Name()+"\n"), b...)
+		if err := p.dir.WriteFile(strings.TrimSuffix(f.Name(), ".sql")+downSuffix, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 var (
 	// ErrNoPendingFiles is returned if there are no pending migration files to execute on the managed database.
 	ErrNoPendingFiles = errors.New("sql/migrate: execute: nothing to do")
@@ -467,6 +727,205 @@ func WithOperatorVersion(v string) ExecutorOption {
 	}
 }
 
+// WithExecHooks appends the given hooks to the Executor. Hooks are called
+// in order before and after each statement is executed.
+func WithExecHooks(hooks ...ExecHook) ExecutorOption {
+	return func(ex *Executor) error {
+		for _, h := range hooks {
+			if h == nil {
+				return errors.New("sql/migrate: execute: nil hook given")
+			}
+		}
+		ex.hooks = append(ex.hooks, hooks...)
+		return nil
+	}
+}
+
+// WithTemplateVars configures the Executor to render the statements of the
+// migration files as templates before they are executed. Statements reference
+// the given variables using the "var" function, e.g. {{ var "retention_days" }}.
+// If no variables were given, only files that are marked with the atlas:template
+// directive are rendered (see IsTemplate), and the rest are executed as is.
+//
+// Rendering is strict: referencing an undefined variable, or a statement that
+// fails to render, aborts the execution before any of the pending files is
+// executed. Note, the checksums of the files and statements are computed on
+// their unrendered content.
+func WithTemplateVars(vars map[string]string) ExecutorOption {
+	return func(ex *Executor) error {
+		if len(vars) == 0 {
+			return nil
+		}
+		ex.vars = make(map[string]string, len(vars))
+		for k, v := range vars {
+			ex.vars[k] = v
+		}
+		return nil
+	}
+}
+
+// WithTarget configures the Executor to evaluate the conditional directives of
+// statements (e.g. atlas:only or atlas:dialect) against the given Target, and to
+// skip the statements that do not match it. Skipped statements are recorded as
+// applied in the revisions table, but are not executed on the database.
+func WithTarget(t *Target) ExecutorOption {
+	return func(ex *Executor) error {
+		ex.target = t
+		return nil
+	}
+}
+
+type (
+	// ExecHook is the interface implemented by hooks that are invoked by the Executor
+	// around the execution of each statement. Hooks can veto a statement, rewrite it,
+	// or inject additional statements before or after it. For example, a hook can
+	// route every ALTER TABLE statement through an online schema change tool.
+	ExecHook interface {
+		// BeforeExec is called before the statement is executed. The returned statements
+		// are executed in place of the given one, which allows rewriting the statement or
+		// injecting additional statements around it. Returning no statements skips the
+		// execution, and returning an error vetoes it and aborts the migration.
+		BeforeExec(context.Context, *HookStmt) ([]string, error)
+		// AfterExec is called after the statement was executed (or skipped), with its
+		// execution error (if any). Returning an error aborts the migration.
+		AfterExec(context.Context, *HookStmt, error) error
+	}
+
+	// HookStmt describes the statement passed to an ExecHook.
+	HookStmt struct {
+		// File holds the migration file of the statement.
+		File File
+		// Index of the statement in the file.
+		Index int
+		// Stmt holds the statement text as written in the migration file.
+		Stmt string
+	}
+
+	// ExecHookFuncs allows using ordinary functions as an ExecHook.
+	// A nil function is a no-op and keeps the statement as is.
+	ExecHookFuncs struct {
+		Before func(context.Context, *HookStmt) ([]string, error)
+		After  func(context.Context, *HookStmt, error) error
+	}
+)
+
+// BeforeExec calls f.Before, if it is not nil.
+func (f ExecHookFuncs) BeforeExec(ctx context.Context, s *HookStmt) ([]string, error) {
+	if f.Before == nil {
+		return []string{s.Stmt}, nil
+	}
+	return f.Before(ctx, s)
+}
+
+// AfterExec calls f.After, if it is not nil.
+func (f ExecHookFuncs) AfterExec(ctx context.Context, s *HookStmt, err error) error {
+	if f.After == nil {
+		return nil
+	}
+	return f.After(ctx, s, err)
+}
+
+// hookError wraps errors returned by ExecHooks.
+type hookError struct{ error }
+
+// execStmt executes the given statement using the driver, after passing it through
+// all configured ExecHooks. The progress argument holds the progress of a previous
+// attempt that failed in the middle of the statements returned by the hooks, and in
+// case it is set, the statements that were already executed are skipped. In case
+// of failure, the progress of the execution is returned, if there is one.
+func (e *Executor) execStmt(ctx context.Context, hs *HookStmt, progress string) (stmt string, rows int64, next string, err error) {
+	stmts := []string{hs.Stmt}
+	for _, h := range e.hooks {
+		var rewritten []string
+		for _, s := range stmts {
+			rs, err := h.BeforeExec(ctx, &HookStmt{File: hs.File, Index: hs.Index, Stmt: s})
+			if err != nil {
+				return s, 0, progress, &hookError{fmt.Errorf("sql/migrate: execute: hook rejected statement %q from %q: %w", s, hs.File.Name(), err)}
+			}
+			rewritten = append(rewritten, rs...)
+		}
+		stmts = rewritten
+	}
+	var done int
+	if progress != "" {
+		if _, err := fmt.Sscanf(progress, "p%d:", &done); err != nil || done > len(stmts) || stmtsProgress(hs.Stmt, stmts[:done]) != progress {
+			return hs.Stmt, 0, progress, &hookError{fmt.Errorf("sql/migrate: execute: statement %d from %q was partially applied, and cannot be resumed as the statements returned by the hooks were changed", hs.Index+1, hs.File.Name())}
+		}
+	}
+	for i := done; i < len(stmts); i++ {
+		stmt = stmts[i]
+		e.log.Log(LogStmt{stmt})
+		var res sql.Result
+		if res, err = e.drv.ExecContext(ctx, stmt); err != nil {
+			break
+		}
+		if err = e.warnings(ctx, hs, stmt); err != nil {
+			break
+		}
+		done++
+		// Rows are summed for statements that were rewritten by hooks.
+		if n, rerr := rowsAffected(res); rerr != nil || n < 0 || rows == -1 {
+			rows = -1
+		} else {
+			rows += n
+		}
+	}
+	// Record the statements that were executed before the failure,
+	// to avoid executing them again when the migration is resumed.
+	if err != nil && done > 0 {
+		next = stmtsProgress(hs.Stmt, stmts[:done])
+	}
+	for _, h := range e.hooks {
+		switch herr := h.AfterExec(ctx, hs, err); {
+		// Hooks may pass the statement error through.
+		case herr == nil || err != nil && errors.Is(herr, err):
+		case err == nil:
+			return hs.Stmt, rows, "", &hookError{fmt.Errorf("sql/migrate: execute: hook failed after statement %q from %q: %w", hs.Stmt, hs.File.Name(), herr)}
+		default:
+			err = &afterExecError{err: err, hook: herr}
+		}
+	}
+	return stmt, rows, next, err
+}
+
+// afterExecError wraps the error of a statement that failed, and the error
+// returned by a hook that was called after it. The statement error is kept
+// as the unwrapped error, and the hook error is matched by errors.Is and As.
+type afterExecError struct {
+	err, hook error
+}
+
+func (e *afterExecError) Error() string {
+	return fmt.Sprintf("%v (hook failed after the statement: %v)", e.err, e.hook)
+}
+
+func (e *afterExecError) Unwrap() error { return e.err }
+
+func (e *afterExecError) Is(target error) bool { return errors.Is(e.hook, target) }
+
+func (e *afterExecError) As(target any) bool { return errors.As(e.hook, target) }
+
+// stmtsProgress returns the progress record of a statement that was expanded by
+// hooks, of which the given statements were executed. The record is stored in the
+// PartialHashes of the revision, following the hashes of the applied statements.
+func stmtsProgress(stmt string, executed []string) string {
+	h := sha256.New()
+	h.Write([]byte(stmt))
+	for _, s := range executed {
+		h.Write([]byte(s))
+	}
+	return fmt.Sprintf("p%d:%s", len(executed), base64.StdEncoding.EncodeToString(h.Sum(nil)))
+}
+
+// rowsAffected returns the number of rows affected by a
+// statement, or an error if it is not reported by the driver.
+func rowsAffected(res sql.Result) (int64, error) {
+	if res == nil {
+		return 0, errors.New("sql/migrate: missing statement result")
+	}
+	return res.RowsAffected()
+}
+
 // Pending returns all pending (not fully applied) migration files in the migration directory.
 func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 	// Don't operate with a broken migration directory.
@@ -478,6 +937,7 @@ func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 	if err != nil {
 		return nil, fmt.Errorf("sql/migrate: execute: read revisions: %w", err)
 	}
+	SortRevisions(e.dir, revs)
 	// Select the correct migration files.
 	migrations, err := e.dir.Files()
 	if err != nil {
@@ -490,15 +950,18 @@ func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 	switch {
 	// If it is the first time we run.
 	case len(revs) == 0:
-		var cerr *NotCleanError
-		if err = e.drv.(CleanChecker).CheckClean(ctx, e.rrw.Ident()); err != nil && !errors.As(err, &cerr) {
+		// Drivers return the NotCleanError either as a value or as a pointer.
+		var cerr NotCleanError
+		if err = e.drv.(CleanChecker).CheckClean(ctx, e.rrw.Ident()); err != nil && !errors.As(err, &cerr) && !errors.As(err, new(*NotCleanError)) {
 			return nil, err
 		}
 		// In case the workspace is not clean one of the flags is required.
-		if cerr != nil && !e.allowDirty && e.baselineVer == "" {
-			return nil, fmt.Errorf("%w. baseline version or allow-dirty is required", cerr)
+		if err != nil && !e.allowDirty && e.baselineVer == "" {
+			return nil, fmt.Errorf("%w. baseline version or allow-dirty is required", err)
 		}
-		pending = migrations
+		// New databases start from the last checkpoint, as it
+		// represents the state of all files that precede it.
+		pending = FilesFromCheckpoint(migrations)
 		if e.baselineVer != "" {
 			baseline := FilesLastIndex(migrations, func(f File) bool {
 				return f.Version() == e.baselineVer
@@ -511,7 +974,7 @@ func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 			if err := e.writeRevision(ctx, &Revision{Version: f.Version(), Description: f.Desc(), Type: RevisionTypeBaseline}); err != nil {
 				return nil, err
 			}
-			pending = migrations[baseline+1:]
+			pending = skipCheckpoints(migrations[baseline+1:], "")
 		}
 	// Not the first time we execute and a custom starting point was provided.
 	case e.fromVer != "":
@@ -521,7 +984,7 @@ func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 		if idx == -1 {
 			return nil, fmt.Errorf("starting point version %q not found in the migration directory", e.fromVer)
 		}
-		pending = migrations[idx:]
+		pending = skipCheckpoints(migrations[idx:], e.fromVer)
 	default:
 		last := revs[len(revs)-1]
 		idx := FilesLastIndex(migrations, func(f File) bool {
@@ -534,7 +997,7 @@ func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 		if last.Applied == last.Total {
 			idx++
 		}
-		pending = migrations[idx:]
+		pending = skipCheckpoints(migrations[idx:], last.Version)
 	}
 	if len(pending) == 0 {
 		return nil, ErrNoPendingFiles
@@ -542,6 +1005,19 @@ func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 	return pending, nil
 }
 
+// skipCheckpoints filters out the checkpoint files from the pending files of an existing
+// database, as their changes were already applied by the files that precede them. The
+// checkpoint file with the given version is kept, as it was partially applied.
+func skipCheckpoints(files []File, keep string) []File {
+	pending := make([]File, 0, len(files))
+	for _, f := range files {
+		if !IsCheckpoint(f) || f.Version() == keep {
+			pending = append(pending, f)
+		}
+	}
+	return pending
+}
+
 // Execute executes the given migration file on the database. If it sees a file, that has been partially applied, it
 // will continue with the next statement in line.
 func (e *Executor) Execute(ctx context.Context, m File) (err error) {
@@ -557,6 +1033,14 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 	if err != nil {
 		return fmt.Errorf("sql/migrate: execute: scanning statements from %q: %w", m.Name(), err)
 	}
+	rendered, err := e.render(m, stmts)
+	if err != nil {
+		return err
+	}
+	skip, err := e.skipped(m, len(stmts))
+	if err != nil {
+		return err
+	}
 	// Create checksums for the statements.
 	var (
 		sums = make([]string, len(stmts))
@@ -606,13 +1090,37 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 			}
 		}
 	}
+	// A statement that was expanded by hooks might be partially applied.
+	var progress string
+	if len(r.PartialHashes) > r.Applied {
+		progress = r.PartialHashes[r.Applied]
+		r.PartialHashes = r.PartialHashes[:r.Applied]
+	}
 	e.log.Log(LogFile{r.Version, r.Description, r.Applied})
-	for _, stmt := range stmts[r.Applied:] {
-		e.log.Log(LogStmt{stmt})
-		if _, err = e.drv.ExecContext(ctx, stmt); err != nil {
-			e.log.Log(LogError{Error: err})
-			r.setSQLErr(stmt, err)
-			return fmt.Errorf("sql/migrate: execute: executing statement %q from version %q: %w", stmt, r.Version, err)
+	for i := r.Applied; i < len(rendered); i, progress = i+1, "" {
+		// Statements that do not match the target
+		// are recorded as applied, but not executed.
+		if !skip[i] {
+			var (
+				rows  int64
+				stmt  = rendered[i]
+				start = time.Now()
+				hs    = &HookStmt{File: m, Index: i, Stmt: stmt}
+			)
+			if stmt, rows, progress, err = e.execStmt(ctx, hs, progress); err != nil {
+				if progress != "" {
+					r.PartialHashes = append(r.PartialHashes, progress)
+				}
+				err = e.posErr(m, i, stmt, e.constraintErr(m, i, err))
+				e.log.Log(LogError{Error: err})
+				if herr := (*hookError)(nil); errors.As(err, &herr) {
+					r.setGoErr(herr.error)
+					return herr.error
+				}
+				r.setSQLErr(stmt, err)
+				return fmt.Errorf("sql/migrate: execute: executing statement %q from version %q: %w", stmt, r.Version, err)
+			}
+			r.StmtStats = append(r.StmtStats, StmtStat{Index: i, Duration: time.Since(start), RowsAffected: rows})
 		}
 		r.PartialHashes = append(r.PartialHashes, "h1:"+sums[r.Applied])
 		r.Applied++
@@ -624,6 +1132,108 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 	return
 }
 
+// constraintErr wraps the given statement error with the details of the violated
+// constraint, in case the driver is able to extract them from the database error.
+func (e *Executor) constraintErr(m File, i int, err error) error {
+	p, ok := e.drv.(ConstraintErrorParser)
+	if herr := (*hookError)(nil); !ok || errors.As(err, &herr) {
+		return err
+	}
+	cerr, ok := p.ConstraintError(err)
+	if !ok {
+		return err
+	}
+	cerr.File, cerr.Stmt, cerr.Err = m.Name(), i+1, err
+	if decls, err := fileStmtDecls(m); err == nil && i < len(decls) && decls[i].Pos <= len(m.Bytes()) {
+		cerr.Line = PositionOf(m.Bytes(), decls[i].Pos).Line
+	}
+	return cerr
+}
+
+// render renders the given statements in case the Executor was configured with
+// template variables, or the file is marked as a template. Otherwise, the statements
+// are returned as is.
+func (e *Executor) render(m File, stmts []string) ([]string, error) {
+	if e.vars == nil && !IsTemplate(m) {
+		return stmts, nil
+	}
+	name := m.Name()
+	rendered := make([]string, len(stmts))
+	for i, s := range stmts {
+		r, err := RenderStmt(name, s, e.vars)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: execute: rendering statement %d from %q: %w", i+1, name, err)
+		}
+		rendered[i] = r
+	}
+	return rendered, nil
+}
+
+// RenderStmt renders the given statement of the named file as a template. Statements
+// reference the given variables using the "var" function, e.g. {{ var "retention_days" }},
+// and referencing an undefined variable is an error. Statements without template actions
+// are returned as is.
+func RenderStmt(name, stmt string, vars map[string]string) (string, error) {
+	// Skip parsing statements without actions.
+	if !strings.Contains(stmt, "{{") {
+		return stmt, nil
+	}
+	t, err := template.New(name).Funcs(template.FuncMap{
+		"var": func(k string) (string, error) {
+			v, ok := vars[k]
+			if !ok {
+				return "", fmt.Errorf("variable %q is not defined", k)
+			}
+			return v, nil
+		},
+	}).Parse(stmt)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// skipped reports for each statement in the given file if it should be skipped,
+// because its conditional directives do not match the target of the Executor,
+// or because it belongs to a seed file that should not be executed.
+func (e *Executor) skipped(m File, n int) ([]bool, error) {
+	skip := make([]bool, n)
+	if IsSeed(m) {
+		ok, err := matchSeed(e.target, m)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: execute: seed file %q: %w", m.Name(), err)
+		}
+		if e.skipSeeds || !ok {
+			for i := range skip {
+				skip[i] = true
+			}
+			return skip, nil
+		}
+	}
+	if e.target == nil {
+		return skip, nil
+	}
+	decls, err := fileStmtDecls(m)
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: execute: scanning statements from %q: %w", m.Name(), err)
+	}
+	if len(decls) != n {
+		return nil, fmt.Errorf("sql/migrate: execute: unexpected number of statement declarations in %q: %d != %d", m.Name(), len(decls), n)
+	}
+	for i, s := range decls {
+		ok, err := e.target.Match(s)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: execute: statement %d from %q: %w", i+1, m.Name(), err)
+		}
+		skip[i] = !ok
+	}
+	return skip, nil
+}
+
 func (e *Executor) writeRevision(ctx context.Context, r *Revision) error {
 	r.ExecutedAt = time.Now()
 	r.OperatorVersion = e.operator
@@ -659,6 +1269,24 @@ func (e *Executor) ExecuteN(ctx context.Context, n int) (err error) {
 	if err != nil {
 		return fmt.Errorf("sql/migrate: execute: read revisions: %w", err)
 	}
+	SortRevisions(e.dir, revs)
+	// Make sure all pending files can be rendered, and their
+	// directives evaluated, before executing any of them.
+	for _, m := range pending {
+		if e.vars == nil && e.target == nil && !IsTemplate(m) {
+			continue
+		}
+		stmts, err := m.Stmts()
+		if err != nil {
+			return fmt.Errorf("sql/migrate: execute: scanning statements from %q: %w", m.Name(), err)
+		}
+		if _, err := e.render(m, stmts); err != nil {
+			return err
+		}
+		if _, err := e.skipped(m, len(stmts)); err != nil {
+			return err
+		}
+	}
 	if err := LogIntro(e.log, revs, pending); err != nil {
 		return err
 	}
@@ -683,6 +1311,10 @@ func (e *Executor) Replay(ctx context.Context, r StateReader) (_ *schema.Realm,
 			err = wrap(err2, err)
 		}
 	}()
+	// Seed files do not change the schema, and therefore, they are not replayed.
+	skip := e.skipSeeds
+	e.skipSeeds = true
+	defer func() { e.skipSeeds = skip }()
 	// Replay the migration directory on the database.
 	if err := e.ExecuteN(ctx, 0); err != nil && !errors.Is(err, ErrNoPendingFiles) {
 		return nil, fmt.Errorf("sql/migrate: read migration directory state: %w", err)
@@ -757,6 +1389,11 @@ func (r *Revision) done() {
 	r.ExecutionTime = time.Now().Sub(r.ExecutedAt)
 }
 
+func (r *Revision) setGoErr(err error) {
+	r.done()
+	r.Error = fmt.Sprintf("Go:\n%s", err)
+}
+
 func (r *Revision) setSQLErr(stmt string, err error) {
 	r.done()
 	r.Error = fmt.Sprintf("Statement:\n%s\n\nError:\n%s", stmt, err)
@@ -9,8 +9,10 @@ import (
 	"database/sql"
 	_ "embed"
 	"errors"
+	"fmt"
 	"io/fs"
 	"path/filepath"
+	"strings"
 	"testing"
 	"text/template"
 	"time"
@@ -57,6 +59,154 @@ func TestPlanner_WritePlan(t *testing.T) {
 	requireFileEqual(t, d, "add_t1_and_t2.down.sql", "DROP TABLE t1 IF EXISTS\nDROP TABLE t2\n")
 }
 
+func TestPlanner_WritePlanSplit(t *testing.T) {
+	var (
+		users = schema.NewTable("users")
+		pets  = schema.NewTable("pets")
+		plan  = &migrate.Plan{
+			Name: "init",
+			Changes: []*migrate.Change{
+				{Cmd: "CREATE TABLE users", Source: &schema.AddTable{T: users}},
+				{Cmd: "ALTER TABLE pets DROP COLUMN c", Source: &schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.DropColumn{C: schema.NewColumn("c")}}}},
+				{Cmd: "CREATE INDEX i ON pets"},
+				{Cmd: "ALTER TABLE users ADD COLUMN c", Source: &schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: schema.NewColumn("c")}}}},
+			},
+		}
+		read = func(d migrate.Dir) (names, content []string) {
+			files, err := d.Files()
+			require.NoError(t, err)
+			for _, f := range files {
+				names = append(names, f.Desc())
+				content = append(content, string(f.Bytes()))
+			}
+			return names, content
+		}
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	pl := migrate.NewPlanner(nil, d, migrate.PlanWithSplitter(migrate.SplitBySafety))
+	require.NoError(t, pl.WritePlan(plan))
+	names, content := read(d)
+	require.Equal(t, []string{"init", "init_destructive"}, names)
+	require.Equal(t, []string{"CREATE TABLE users;\nCREATE INDEX i ON pets;\nALTER TABLE users ADD COLUMN c;\n", "ALTER TABLE pets DROP COLUMN c;\n"}, content)
+	require.NoError(t, migrate.Validate(d))
+
+	d, err = migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	seq := &migrate.SequenceScheme{Width: 2}
+	pl = migrate.NewPlanner(nil, migrate.NewSchemeDir(d, seq), migrate.PlanWithSplitter(migrate.SplitByResource), migrate.PlanWithVersionScheme(seq))
+	require.NoError(t, pl.WritePlan(plan))
+	names, content = read(d)
+	require.Equal(t, []string{"init_users", "init_pets", "init_users"}, names)
+	require.Equal(t, []string{"CREATE TABLE users;\n", "ALTER TABLE pets DROP COLUMN c;\nCREATE INDEX i ON pets;\n", "ALTER TABLE users ADD COLUMN c;\n"}, content)
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Equal(t, "01_init_users.sql", files[0].Name())
+	require.Equal(t, "03_init_users.sql", files[2].Name())
+
+	d, err = migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	pl = migrate.NewPlanner(nil, migrate.NewSchemeDir(d, seq), migrate.PlanWithSplitter(migrate.SplitByScope(&schema.Scope{Name: "pets", Tables: []string{"pets"}})), migrate.PlanWithVersionScheme(seq))
+	require.NoError(t, pl.WritePlan(plan))
+	names, content = read(d)
+	require.Equal(t, []string{"init", "init_pets", "init"}, names)
+	require.Equal(t, []string{"CREATE TABLE users;\n", "ALTER TABLE pets DROP COLUMN c;\nCREATE INDEX i ON pets;\n", "ALTER TABLE users ADD COLUMN c;\n"}, content)
+
+	// Plans that cannot be split are written as is.
+	d, err = migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	pl = migrate.NewPlanner(nil, d, migrate.PlanWithSplitter(migrate.SplitBySafety))
+	require.NoError(t, pl.WritePlan(&migrate.Plan{Name: "init", Changes: plan.Changes[:1]}))
+	names, _ = read(d)
+	require.Equal(t, []string{"init"}, names)
+}
+
+func TestPlanner_WritePlanNoTx(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	seq := &migrate.SequenceScheme{}
+	pl := migrate.NewPlanner(nil, migrate.NewSchemeDir(d, seq), migrate.PlanWithVersionScheme(seq))
+	require.NoError(t, pl.WritePlan(&migrate.Plan{
+		Name: "users",
+		Changes: []*migrate.Change{
+			{Cmd: "ALTER TABLE users ADD COLUMN name text"},
+			{Cmd: "CREATE INDEX CONCURRENTLY i1 ON users (name)", NoTx: true},
+			{Cmd: "DROP INDEX CONCURRENTLY i2", NoTx: true},
+			{Cmd: "ALTER TABLE users DROP COLUMN age"},
+		},
+	}))
+	requireFileEqual(t, d, "1_users.sql", "ALTER TABLE users ADD COLUMN name text;\n")
+	requireFileEqual(t, d, "2_users_notx.sql", "-- atlas:txmode none\nCREATE INDEX CONCURRENTLY i1 ON users (name);\nDROP INDEX CONCURRENTLY i2;\n")
+	requireFileEqual(t, d, "3_users.sql", "ALTER TABLE users DROP COLUMN age;\n")
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Empty(t, migrate.FileTxMode(files[0]))
+	require.Equal(t, migrate.TxModeNone, migrate.FileTxMode(files[1]))
+	require.NoError(t, migrate.Validate(d))
+
+	// Plans with only non-transactional changes are not split.
+	d, err = migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	pl = migrate.NewPlanner(nil, d)
+	require.NoError(t, pl.WritePlan(&migrate.Plan{
+		Version: "1",
+		Name:    "index",
+		Changes: []*migrate.Change{{Cmd: "CREATE INDEX CONCURRENTLY i1 ON users (name)", NoTx: true}},
+	}))
+	requireFileEqual(t, d, "1_index.sql", "-- atlas:txmode none\nCREATE INDEX CONCURRENTLY i1 ON users (name);\n")
+}
+
+func TestFileTxMode(t *testing.T) {
+	for _, tt := range []struct {
+		content, mode string
+	}{
+		{content: "CREATE TABLE t(c int);\n"},
+		{content: "-- atlas:txmode none\nCREATE INDEX CONCURRENTLY i ON t (c);\n", mode: migrate.TxModeNone},
+		{content: "-- atlas:phase expand\n#atlas:txmode NONE\nCREATE INDEX CONCURRENTLY i ON t (c);\n", mode: migrate.TxModeNone},
+		// Directives after the header are ignored.
+		{content: "DROP TABLE t;\n-- atlas:txmode none\n"},
+	} {
+		require.Equal(t, tt.mode, migrate.FileTxMode(migrate.NewLocalFile("1.sql", []byte(tt.content))))
+	}
+}
+
+func TestPlanner_WritePlanPhase(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	pl := migrate.NewPlanner(nil, d, migrate.PlanWithPhase(migrate.PhaseContract), migrate.PlanWithVersionScheme(&migrate.SequenceScheme{}))
+	require.NoError(t, pl.WritePlan(&migrate.Plan{Name: "drop_name", Changes: []*migrate.Change{{Cmd: "ALTER TABLE users DROP COLUMN name"}}}))
+	requireFileEqual(t, d, "1_drop_name.sql", "-- atlas:phase contract\nALTER TABLE users DROP COLUMN name;\n")
+	files, err := d.Files()
+	require.NoError(t, err)
+	phase, err := migrate.FilePhase(files[0])
+	require.NoError(t, err)
+	require.Equal(t, migrate.PhaseContract, phase)
+	require.NoError(t, migrate.Validate(d))
+}
+
+func TestFilePhase(t *testing.T) {
+	for _, tt := range []struct {
+		content, phase, err string
+	}{
+		{content: "CREATE TABLE t(c int);\n"},
+		{content: "-- atlas:phase expand\nCREATE TABLE t(c int);\n", phase: migrate.PhaseExpand},
+		{content: "#atlas:phase contract\nDROP TABLE t;\n", phase: migrate.PhaseContract},
+		{content: "-- atlas:delimiter \\n\\n\n-- atlas:phase Contract\n\nDROP TABLE t;\n", phase: migrate.PhaseContract},
+		// Directives after the header are ignored.
+		{content: "DROP TABLE t;\n-- atlas:phase contract\n"},
+		{content: "-- atlas:phase migrate\nDROP TABLE t;\n", err: `sql/migrate: unknown phase "migrate", expect "expand" or "contract"`},
+	} {
+		phase, err := migrate.FilePhase(migrate.NewLocalFile("1.sql", []byte(tt.content)))
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tt.phase, phase)
+	}
+}
+
 func TestPlanner_Plan(t *testing.T) {
 	var (
 		drv = &mockDriver{}
@@ -85,6 +235,52 @@ func TestPlanner_Plan(t *testing.T) {
 	plan, err = pl.Plan(ctx, "", migrate.Realm(nil))
 	require.NoError(t, err)
 	require.Equal(t, drv.plan, plan)
+
+	// all changes were filtered by the diff hooks.
+	pl = migrate.NewPlanner(drv, d, migrate.PlanWithDiffOptions(
+		schema.DiffWithHooks(schema.SkipChanges(func(c schema.Change) bool {
+			_, ok := c.(*schema.AddTable)
+			return ok
+		})),
+	))
+	plan, err = pl.Plan(ctx, "", migrate.Realm(nil))
+	require.ErrorIs(t, err, migrate.ErrNoPlan)
+	require.Nil(t, plan)
+}
+
+func TestPlanner_PlanTemplateVars(t *testing.T) {
+	var (
+		drv = &mockDriver{}
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c int) TABLESPACE {{ var \"tablespace\" }};\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	// Statements are rendered when they are replayed.
+	pl := migrate.NewPlanner(drv, d, migrate.PlanWithTemplateVars(map[string]string{"tablespace": "fast"}))
+	_, err = pl.Plan(ctx, "", migrate.Realm(nil))
+	require.ErrorIs(t, err, migrate.ErrNoPlan)
+	require.Equal(t, []string{"CREATE TABLE t (c int) TABLESPACE fast;"}, drv.executed)
+
+	// Without variables, statements are replayed as is.
+	drv = &mockDriver{}
+	pl = migrate.NewPlanner(drv, d, migrate.PlanWithTemplateVars(nil))
+	_, err = pl.Plan(ctx, "", migrate.Realm(nil))
+	require.ErrorIs(t, err, migrate.ErrNoPlan)
+	require.Equal(t, []string{"CREATE TABLE t (c int) TABLESPACE {{ var \"tablespace\" }};"}, drv.executed)
+
+	// Undefined variables fail the replay of files that are marked as templates.
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("-- atlas:template\nCREATE TABLE t (c int) TABLESPACE {{ var \"tablespace\" }};\n")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	pl = migrate.NewPlanner(drv, d, migrate.PlanWithTemplateVars(nil))
+	_, err = pl.Plan(ctx, "", migrate.Realm(nil))
+	require.ErrorContains(t, err, `variable "tablespace" is not defined`)
 }
 
 func TestPlanner_PlanSchema(t *testing.T) {
@@ -339,6 +535,350 @@ func TestExecutor(t *testing.T) {
 	require.ErrorIs(t, ex.ExecuteN(context.Background(), 0), migrate.ErrNoPendingFiles)
 }
 
+func TestExecutor_ExecHooks(t *testing.T) {
+	var (
+		drv   = &mockDriver{}
+		rrw   = &mockRevisionReadWriter{}
+		after []string
+	)
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata/migrate", "sub"))
+	require.NoError(t, err)
+	_, err = migrate.NewExecutor(drv, dir, rrw, migrate.WithExecHooks(nil))
+	require.EqualError(t, err, "sql/migrate: execute: nil hook given")
+
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithExecHooks(
+		migrate.ExecHookFuncs{
+			Before: func(_ context.Context, s *migrate.HookStmt) ([]string, error) {
+				switch {
+				case strings.HasPrefix(s.Stmt, "CREATE"):
+					// Skip.
+					return nil, nil
+				case strings.Contains(s.Stmt, "c1"):
+					// Inject.
+					return []string{"SET x = 1;", s.Stmt}, nil
+				case strings.Contains(s.Stmt, "c4"):
+					// Veto.
+					return nil, errors.New("c4 is not allowed")
+				}
+				return []string{s.Stmt}, nil
+			},
+		},
+		migrate.ExecHookFuncs{
+			Before: func(_ context.Context, s *migrate.HookStmt) ([]string, error) {
+				// Rewrite.
+				return []string{strings.ReplaceAll(s.Stmt, "ALTER", "ALTER ONLINE")}, nil
+			},
+			After: func(_ context.Context, s *migrate.HookStmt, err error) error {
+				after = append(after, fmt.Sprintf("%s:%d", s.File.Version(), s.Index))
+				return err
+			},
+		},
+	))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 2))
+	require.Equal(t, []string{"SET x = 1;", "ALTER ONLINE TABLE t_sub ADD c1 int;", "ALTER ONLINE TABLE t_sub ADD c2 int;"}, drv.executed)
+	require.Equal(t, []string{"1.a:0", "1.a:1", "2.10.x-20:0"}, after)
+	require.Equal(t, 2, (*rrw)[0].Applied)
+	// Statements dropped by hooks affect no rows, and rows are unknown if the driver returns no result.
+	stats := (*rrw)[0].StmtStats
+	require.Len(t, stats, 2)
+	require.Equal(t, []int{0, 1}, []int{stats[0].Index, stats[1].Index})
+	require.Equal(t, []int64{0, -1}, []int64{stats[0].RowsAffected, stats[1].RowsAffected})
+	require.Len(t, (*rrw)[1].StmtStats, 1)
+
+	err = ex.ExecuteN(context.Background(), 1)
+	require.EqualError(t, err, `sql/migrate: execute: hook rejected statement "ALTER TABLE t_sub ADD c4 int;" from "3_partly.sql": c4 is not allowed`)
+	rev := (*rrw)[len(*rrw)-1]
+	require.Equal(t, 1, rev.Applied)
+	require.Equal(t, "Go:\n"+err.Error(), rev.Error)
+
+	// Errors of hooks that are called after a failed statement are wrapped with its error.
+	var (
+		herr = errors.New("notify failed")
+		serr = errors.New("duplicate column")
+	)
+	drv, *rrw = &mockDriver{failCounter: 1, failWith: serr}, nil
+	ex, err = migrate.NewExecutor(drv, dir, rrw, migrate.WithExecHooks(
+		migrate.ExecHookFuncs{
+			After: func(context.Context, *migrate.HookStmt, error) error { return herr },
+		},
+	))
+	require.NoError(t, err)
+	err = ex.ExecuteN(context.Background(), 1)
+	require.EqualError(t, err, `sql/migrate: execute: executing statement "CREATE TABLE t_sub(c int);" from version "1.a": duplicate column (hook failed after the statement: notify failed)`)
+	require.ErrorIs(t, err, serr)
+	require.ErrorIs(t, err, herr)
+}
+
+func TestExecutor_ExecHooksResume(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_a.sql", []byte("ALTER TABLE t ADD c int;\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	var (
+		injected = []string{"SET x = 1;", "SET y = 2;"}
+		hook     = migrate.ExecHookFuncs{
+			Before: func(_ context.Context, s *migrate.HookStmt) ([]string, error) {
+				return append(injected, s.Stmt), nil
+			},
+		}
+		drv = &mockDriver{failCounter: 2, failWith: errors.New("failed")}
+		rrw = &mockRevisionReadWriter{}
+	)
+	ex, err := migrate.NewExecutor(drv, d, rrw, migrate.WithExecHooks(hook))
+	require.NoError(t, err)
+	require.Error(t, ex.ExecuteN(context.Background(), 0))
+	require.Equal(t, []string{"SET x = 1;"}, drv.executed)
+	rev := (*rrw)[0]
+	require.Equal(t, 0, rev.Applied)
+	require.Len(t, rev.PartialHashes, 1)
+	require.True(t, strings.HasPrefix(rev.PartialHashes[0], "p1:"))
+
+	// Statements returned by the hooks that were executed are not executed again.
+	*drv = mockDriver{}
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.Equal(t, []string{"SET y = 2;", "ALTER TABLE t ADD c int;"}, drv.executed)
+	require.Equal(t, 1, rev.Applied)
+	require.Len(t, rev.PartialHashes, 1)
+	require.True(t, strings.HasPrefix(rev.PartialHashes[0], "h1:"))
+
+	// Partially applied statements are not resumed in case the hooks returned other statements.
+	*drv, *rrw = mockDriver{failCounter: 2, failWith: errors.New("failed")}, nil
+	require.Error(t, ex.ExecuteN(context.Background(), 0))
+	*drv, injected = mockDriver{}, []string{"SET z = 1;", "SET y = 2;"}
+	err = ex.ExecuteN(context.Background(), 0)
+	require.EqualError(t, err, `sql/migrate: execute: statement 1 from "1_a.sql" was partially applied, and cannot be resumed as the statements returned by the hooks were changed`)
+	require.Empty(t, drv.executed)
+	require.True(t, strings.HasPrefix((*rrw)[0].PartialHashes[0], "p1:"))
+}
+
+func TestExecutor_TemplateVars(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c int) TABLESPACE {{ var \"tablespace\" }};\nALTER TABLE t ADD d int;\n")))
+	require.NoError(t, d.WriteFile("2_next.sql", []byte("SELECT '{{ var \"retention_days\" }}';\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	// Undefined variables fail the execution before any file is executed.
+	drv, rrw := &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err := migrate.NewExecutor(drv, d, rrw, migrate.WithTemplateVars(map[string]string{"tablespace": "fast"}))
+	require.NoError(t, err)
+	err = ex.ExecuteN(context.Background(), 0)
+	require.ErrorContains(t, err, `sql/migrate: execute: rendering statement 1 from "2_next.sql"`)
+	require.ErrorContains(t, err, `variable "retention_days" is not defined`)
+	require.Empty(t, drv.executed)
+	require.Empty(t, *rrw)
+
+	ex, err = migrate.NewExecutor(drv, d, rrw, migrate.WithTemplateVars(map[string]string{"tablespace": "fast", "retention_days": "30"}))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.Equal(t, []string{"CREATE TABLE t (c int) TABLESPACE fast;", "ALTER TABLE t ADD d int;", "SELECT '30';"}, drv.executed)
+
+	// Without variables, statements are executed as is.
+	drv, rrw = &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, d, rrw)
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.Equal(t, "CREATE TABLE t (c int) TABLESPACE {{ var \"tablespace\" }};", drv.executed[0])
+
+	// Literals that look like template actions are not rendered without variables.
+	d, err = migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_array.sql", []byte("INSERT INTO t VALUES ('{{1,2},{3,4}}');\n")))
+	require.NoError(t, d.WriteFile("2_template.sql", []byte("-- atlas:template\nSELECT '{{ var \"retention_days\" }}';\n")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	drv, rrw = &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, d, rrw, migrate.WithTemplateVars(nil))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.Equal(t, []string{"INSERT INTO t VALUES ('{{1,2},{3,4}}');"}, drv.executed)
+
+	// Files that are marked as templates are rendered also without variables.
+	err = ex.ExecuteN(context.Background(), 0)
+	require.ErrorContains(t, err, `variable "retention_days" is not defined`)
+	require.True(t, migrate.IsTemplate(migrate.NewLocalFile("1.sql", []byte("-- atlas:template\nSELECT 1;"))))
+	require.False(t, migrate.IsTemplate(migrate.NewLocalFile("1.sql", []byte("SELECT 1;\n-- atlas:template"))))
+}
+
+func TestTarget_Match(t *testing.T) {
+	target := &migrate.Target{Env: "prod", Dialect: "postgres", Version: "15.2 (Debian 15.2-1.pgdg110+1)"}
+	for _, tt := range []struct {
+		comment string
+		match   bool
+		err     string
+	}{
+		{comment: "-- comment", match: true},
+		{comment: "-- atlas:only env=prod", match: true},
+		{comment: "-- atlas:only env=dev,staging", match: false},
+		{comment: "-- atlas:only env=dev,prod dialect=postgres", match: true},
+		{comment: "-- atlas:only env=prod dialect=mysql", match: false},
+		{comment: "-- atlas:only dialect=postgres>=15", match: true},
+		{comment: "-- atlas:dialect postgres", match: true},
+		{comment: "-- atlas:dialect mysql, sqlite", match: false},
+		{comment: "-- atlas:dialect mysql, postgres>=15", match: true},
+		{comment: "-- atlas:dialect postgres>15.2", match: false},
+		{comment: "-- atlas:dialect postgres<16", match: true},
+		{comment: "-- atlas:dialect postgres!=15.2", match: false},
+		{comment: "-- atlas:dialect postgres=15.2", match: true},
+		{comment: "-- atlas:dialect mysql>=8", match: false},
+		{comment: "-- atlas:only", err: "sql/migrate: missing arguments for atlas:only directive"},
+		{comment: "-- atlas:only env", err: `sql/migrate: invalid atlas:only argument "env"`},
+		{comment: "-- atlas:only region=us", err: `sql/migrate: unknown atlas:only key "region"`},
+		{comment: "-- atlas:dialect postgres=>15", err: `sql/migrate: invalid dialect expression "postgres=>15"`},
+	} {
+		t.Run(tt.comment, func(t *testing.T) {
+			match, err := target.Match(&migrate.Stmt{Text: "SELECT 1;", Comments: []string{tt.comment}})
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.match, match)
+		})
+	}
+	_, err := (&migrate.Target{Dialect: "postgres"}).Match(&migrate.Stmt{Comments: []string{"-- atlas:dialect postgres>=15"}})
+	require.EqualError(t, err, `sql/migrate: unknown postgres version for evaluating dialect expression "postgres>=15"`)
+}
+
+func TestExecutor_Target(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte(`CREATE TABLE t (c int);
+
+-- atlas:only env=prod
+CREATE INDEX i ON t (c) TABLESPACE fast;
+
+-- atlas:dialect postgres>=15
+ALTER TABLE t ADD CONSTRAINT u UNIQUE NULLS NOT DISTINCT (c);
+`)))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	drv, rrw := &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err := migrate.NewExecutor(drv, d, rrw, migrate.WithTarget(&migrate.Target{Env: "dev", Dialect: "postgres", Version: "15.2"}))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.Equal(t, []string{"CREATE TABLE t (c int);", "ALTER TABLE t ADD CONSTRAINT u UNIQUE NULLS NOT DISTINCT (c);"}, drv.executed)
+	// Skipped statements are recorded as applied.
+	require.Len(t, *rrw, 1)
+	require.Equal(t, 3, (*rrw)[0].Applied)
+	require.Equal(t, 3, (*rrw)[0].Total)
+
+	drv, rrw = &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, d, rrw, migrate.WithTarget(&migrate.Target{Env: "prod", Dialect: "postgres", Version: "14.6"}))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.Equal(t, []string{"CREATE TABLE t (c int);", "CREATE INDEX i ON t (c) TABLESPACE fast;"}, drv.executed)
+
+	// Without a target, all statements are executed.
+	drv, rrw = &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, d, rrw)
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.Len(t, drv.executed, 3)
+}
+
+func TestIsSeed(t *testing.T) {
+	for _, tt := range []struct {
+		name, content string
+		seed          bool
+	}{
+		{name: "1_init.sql", content: "CREATE TABLE t(c int);\n"},
+		{name: "2_roles.seed.sql", content: "INSERT INTO t VALUES (1);\n", seed: true},
+		{name: "2_roles.sql", content: "-- atlas:seed\nINSERT INTO t VALUES (1);\n", seed: true},
+		{name: "2_roles.sql", content: "-- atlas:txmode none\n#atlas:seed env=dev\nINSERT INTO t VALUES (1);\n", seed: true},
+		// Directives after the header are ignored.
+		{name: "2_roles.sql", content: "INSERT INTO t VALUES (1);\n-- atlas:seed\n"},
+	} {
+		require.Equal(t, tt.seed, migrate.IsSeed(migrate.NewLocalFile(tt.name, []byte(tt.content))), tt.content)
+	}
+	require.Equal(t, "roles", migrate.NewLocalFile("2_roles.seed.sql", nil).Desc())
+}
+
+func TestExecutor_Seeds(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c int);\n")))
+	require.NoError(t, d.WriteFile("2_values.seed.sql", []byte("INSERT INTO t VALUES (1);\n")))
+	require.NoError(t, d.WriteFile("3_fixtures.sql", []byte("-- atlas:seed env=dev,test\nINSERT INTO t VALUES (2);\n")))
+	require.NoError(t, d.WriteFile("4_index.sql", []byte("CREATE INDEX i ON t (c);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	drv, rrw := &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err := migrate.NewExecutor(drv, d, rrw, migrate.WithTarget(&migrate.Target{Env: "dev"}))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.Equal(t, []string{"CREATE TABLE t (c int);", "INSERT INTO t VALUES (1);", "INSERT INTO t VALUES (2);", "CREATE INDEX i ON t (c);"}, drv.executed)
+
+	// Seed files scoped to other environments are recorded as applied.
+	drv, rrw = &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, d, rrw, migrate.WithTarget(&migrate.Target{Env: "prod"}))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.Equal(t, []string{"CREATE TABLE t (c int);", "INSERT INTO t VALUES (1);", "CREATE INDEX i ON t (c);"}, drv.executed)
+	require.Len(t, *rrw, 4)
+	require.Equal(t, 1, (*rrw)[2].Applied)
+
+	// Seed files are skipped explicitly.
+	drv, rrw = &mockDriver{}, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(drv, d, rrw, migrate.WithSkipSeeds(true))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.Equal(t, []string{"CREATE TABLE t (c int);", "CREATE INDEX i ON t (c);"}, drv.executed)
+
+	// Seed files are not replayed.
+	drv = &mockDriver{}
+	ex, err = migrate.NewExecutor(drv, d, migrate.NopRevisionReadWriter{}, migrate.WithTarget(&migrate.Target{Env: "dev"}))
+	require.NoError(t, err)
+	_, err = ex.Replay(context.Background(), migrate.Realm(schema.NewRealm()))
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE t (c int);", "CREATE INDEX i ON t (c);"}, drv.executed)
+
+	// Invalid directive arguments.
+	require.NoError(t, d.WriteFile("3_fixtures.sql", []byte("-- atlas:seed envs=dev\nINSERT INTO t VALUES (2);\n")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	ex, err = migrate.NewExecutor(&mockDriver{}, d, &mockRevisionReadWriter{}, migrate.WithTarget(&migrate.Target{Env: "dev"}))
+	require.NoError(t, err)
+	require.EqualError(t, ex.ExecuteN(context.Background(), 0), `sql/migrate: execute: seed file "3_fixtures.sql": sql/migrate: unknown atlas:only key "envs"`)
+}
+
+func TestChecksum_Seeds(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c int);\n")))
+	require.NoError(t, d.WriteFile("2_values.seed.sql", []byte("INSERT INTO t VALUES (1);\n")))
+	require.NoError(t, d.WriteFile("3_index.sql", []byte("CREATE INDEX i ON t (c);\n")))
+	before, err := d.Checksum()
+	require.NoError(t, err)
+	require.Len(t, before, 3)
+
+	// Changing a seed file changes only its own hash.
+	require.NoError(t, d.WriteFile("2_values.seed.sql", []byte("INSERT INTO t VALUES (2);\n")))
+	after, err := d.Checksum()
+	require.NoError(t, err)
+	require.Equal(t, before[0], after[0])
+	require.NotEqual(t, before[1], after[1])
+	require.Equal(t, before[2], after[2])
+
+	// Changing a structural file changes the hashes of the files that follow it.
+	require.NoError(t, d.WriteFile("1_init.sql", []byte("CREATE TABLE t (c bigint);\n")))
+	after, err = d.Checksum()
+	require.NoError(t, err)
+	require.NotEqual(t, before[0], after[0])
+	require.NotEqual(t, before[2], after[2])
+}
+
 func TestExecutor_Baseline(t *testing.T) {
 	var (
 		rrw mockRevisionReadWriter
@@ -373,6 +913,15 @@ func TestExecutor_Baseline(t *testing.T) {
 	require.Equal(t, "description", rrw[0].Description)
 	require.Equal(t, migrate.RevisionTypeBaseline, rrw[0].Type)
 
+	// Drivers that return the NotCleanError as a value.
+	rrw = mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(&valueCleanDriver{drv}, dir, &rrw, migrate.WithLogger(log), migrate.WithBaselineVersion("2.10.x-20"))
+	require.NoError(t, err)
+	files, err = ex.Pending(context.Background())
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Len(t, rrw, 1)
+
 	rrw = mockRevisionReadWriter{}
 	ex, err = migrate.NewExecutor(drv, dir, &rrw, migrate.WithLogger(log), migrate.WithBaselineVersion("3"))
 	require.NoError(t, err)
@@ -421,6 +970,226 @@ func TestExecutor_FromVersion(t *testing.T) {
 	require.Nil(t, files)
 }
 
+func TestExecutor_ConstraintError(t *testing.T) {
+	var (
+		drv = &constraintDriver{mockDriver: &mockDriver{}}
+		rrw = &mockRevisionReadWriter{}
+		log = &mockLogger{}
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_users.sql", []byte("CREATE TABLE users(name text);\n\n-- Add index.\nCREATE UNIQUE INDEX name ON users(name);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	ex, err := migrate.NewExecutor(drv, d, rrw, migrate.WithLogger(log))
+	require.NoError(t, err)
+	drv.failOn(2, errors.New("UNIQUE constraint failed: users.name"))
+	err = ex.ExecuteN(ctx, 0)
+	var cerr *migrate.ConstraintError
+	require.ErrorAs(t, err, &cerr)
+	require.Equal(t, "users", cerr.Table)
+	require.Equal(t, "1_users.sql", cerr.File)
+	require.Equal(t, 2, cerr.Stmt)
+	require.Equal(t, 4, cerr.Line)
+	require.EqualError(t, cerr, `UNIQUE constraint failed: users.name [unique constraint, table "users", columns (name), statement 2 (line 4) of "1_users.sql"]`)
+	// The details are logged and recorded in the revision as well.
+	require.Equal(t, migrate.LogError{Error: cerr}, (*log)[len(*log)-1])
+	require.Contains(t, (*rrw)[0].Error, `statement 2 (line 4) of "1_users.sql"`)
+
+	// Other errors are returned as is.
+	rrw.clean()
+	drv.failOn(1, errors.New("no such table: users"))
+	err = ex.ExecuteN(ctx, 0)
+	require.False(t, errors.As(err, &cerr))
+}
+
+func TestExecutor_PosError(t *testing.T) {
+	var (
+		drv = &posDriver{mockDriver: &mockDriver{}}
+		rrw = &mockRevisionReadWriter{}
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_users.sql", []byte("CREATE TABLE users(name text);\n\n-- Seed.\nINSERT INTO users VALUES ('ü')\n  FORM x;\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	ex, err := migrate.NewExecutor(drv, d, rrw)
+	require.NoError(t, err)
+	drv.failOn(2, errors.New(`syntax error at or near "FORM"`))
+	err = ex.ExecuteN(ctx, 0)
+	var perr *migrate.PosError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, &migrate.PosError{File: "1_users.sql", Stmt: 2, Offset: 75, Line: 5, Column: 3, Err: perr.Err}, perr)
+	require.EqualError(t, perr, `syntax error at or near "FORM" [line 5, column 3 of "1_users.sql"]`)
+	require.Contains(t, (*rrw)[0].Error, `[line 5, column 3 of "1_users.sql"]`)
+
+	// Errors without position are returned as is.
+	rrw.clean()
+	drv.failOn(1, errors.New(`relation "users" already exists`))
+	err = ex.ExecuteN(ctx, 0)
+	require.False(t, errors.As(err, &perr))
+}
+
+func TestExecutor_Warnings(t *testing.T) {
+	var (
+		drv = &warnDriver{mockDriver: &mockDriver{}}
+		rrw = &mockRevisionReadWriter{}
+		log = &mockLogger{}
+		ctx = context.Background()
+		w   = migrate.Warning{Level: "warning", Code: "1265", Message: "Data truncated for column 'name' at row 1"}
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_users.sql", []byte("CREATE TABLE users(name text);\nALTER TABLE users MODIFY name varchar(1);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	ex, err := migrate.NewExecutor(drv, d, rrw, migrate.WithLogger(log))
+	require.NoError(t, err)
+	drv.warnOn = map[string][]migrate.Warning{"ALTER TABLE users MODIFY name varchar(1);": {w}}
+	require.NoError(t, ex.ExecuteN(ctx, 0))
+	require.Contains(t, *log, migrate.LogWarning{SQL: "ALTER TABLE users MODIFY name varchar(1);", Warning: w})
+
+	// Fail on configured warning classes.
+	rrw.clean()
+	*log = (*log)[:0]
+	ex, err = migrate.NewExecutor(drv, d, rrw, migrate.WithLogger(log), migrate.WithFailOnWarnings("note", "12"))
+	require.NoError(t, err)
+	err = ex.ExecuteN(ctx, 0)
+	var werr *migrate.WarningError
+	require.ErrorAs(t, err, &werr)
+	require.Equal(t, &migrate.WarningError{File: "1_users.sql", Stmt: 2, Warnings: []migrate.Warning{w}}, werr)
+	require.EqualError(t, werr, `statement 2 of "1_users.sql" reported warnings: warning 1265: Data truncated for column 'name' at row 1`)
+	require.Contains(t, (*rrw)[0].Error, "reported warnings")
+}
+
+func TestIsCheckpoint(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		want    bool
+	}{
+		{content: "CREATE TABLE t(c int);\n"},
+		{content: "-- atlas:checkpoint\nCREATE TABLE t(c int);\n", want: true},
+		{content: "-- atlas:delimiter \\n\\n\n-- atlas:checkpoint\n\nCREATE TABLE t(c int);\n", want: true},
+		// Directives after the header are ignored.
+		{content: "CREATE TABLE t(c int);\n-- atlas:checkpoint\n"},
+	} {
+		require.Equal(t, tt.want, migrate.IsCheckpoint(migrate.NewLocalFile("1.sql", []byte(tt.content))))
+	}
+}
+
+func TestHeaderDirectives(t *testing.T) {
+	f := migrate.NewLocalFile("1.sql", []byte("-- atlas:import users.sql\n#atlas:import tags.sql\n-- atlas:checkpoint\n\nCREATE TABLE t(c int);\n-- atlas:import posts.sql\n"))
+	require.Equal(t, []string{"users.sql", "tags.sql"}, migrate.HeaderDirectives(f, "import"))
+	require.Equal(t, []string{""}, migrate.HeaderDirectives(f, "checkpoint"))
+	require.Empty(t, migrate.HeaderDirectives(f, "nolint"))
+}
+
+func TestPlanner_Checkpoint(t *testing.T) {
+	var (
+		drv = &mockDriver{}
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_t1.sql", []byte("CREATE TABLE t1(c int);\n")))
+	require.NoError(t, d.WriteFile("2_t2.sql", []byte("CREATE TABLE t2(c int);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	// Empty state.
+	pl := migrate.NewPlanner(drv, d, migrate.PlanWithVersionScheme(&migrate.SequenceScheme{}))
+	_, err = pl.Checkpoint(ctx, "checkpoint")
+	require.ErrorIs(t, err, migrate.ErrNoPlan)
+	require.Equal(t, []string{"CREATE TABLE t1(c int);", "CREATE TABLE t2(c int);"}, drv.executed)
+
+	drv.changes = []schema.Change{
+		&schema.AddTable{T: schema.NewTable("t1").AddColumns(schema.NewIntColumn("c", "int"))},
+		&schema.AddTable{T: schema.NewTable("t2").AddColumns(schema.NewIntColumn("c", "int"))},
+	}
+	drv.plan = &migrate.Plan{
+		Name: "checkpoint",
+		Changes: []*migrate.Change{
+			{Cmd: "CREATE TABLE t1(c int)"},
+			{Cmd: "CREATE TABLE t2(c int)"},
+		},
+	}
+	plan, err := pl.Checkpoint(ctx, "checkpoint")
+	require.NoError(t, err)
+	require.NoError(t, pl.WriteCheckpoint(plan))
+	requireFileEqual(t, d, "3_checkpoint.sql", "-- atlas:checkpoint\nCREATE TABLE t1(c int);\nCREATE TABLE t2(c int);\n")
+	require.NoError(t, migrate.Validate(d))
+	plan.Reverse = &migrate.Plan{}
+	require.EqualError(t, pl.WriteCheckpoint(plan), "sql/migrate: checkpoint plans cannot have a reverse plan")
+
+	// Replaying the directory on new databases starts from the checkpoint.
+	drv.executed = nil
+	_, err = pl.Checkpoint(ctx, "checkpoint")
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE t1(c int);", "CREATE TABLE t2(c int);"}, drv.executed)
+}
+
+func TestExecutor_PendingCheckpoint(t *testing.T) {
+	var (
+		drv = &mockDriver{}
+		rrw = &mockRevisionReadWriter{}
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_t1.sql", []byte("CREATE TABLE t1(c int);\n")))
+	require.NoError(t, d.WriteFile("2_t2.sql", []byte("CREATE TABLE t2(c int);\n")))
+	require.NoError(t, d.WriteFile("3_checkpoint.sql", []byte("-- atlas:checkpoint\nCREATE TABLE t1(c int);\nCREATE TABLE t2(c int);\n")))
+	require.NoError(t, d.WriteFile("4_t3.sql", []byte("CREATE TABLE t3(c int);\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	versions := func(files []migrate.File) []string {
+		var vs []string
+		for _, f := range files {
+			vs = append(vs, f.Version())
+		}
+		return vs
+	}
+	ex, err := migrate.NewExecutor(drv, d, rrw)
+	require.NoError(t, err)
+
+	// New databases start from the checkpoint.
+	files, err := ex.Pending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"3", "4"}, versions(files))
+
+	// Existing databases skip it.
+	*rrw = []*migrate.Revision{{Version: "1", Applied: 1, Total: 1}}
+	files, err = ex.Pending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"2", "4"}, versions(files))
+	*rrw = []*migrate.Revision{{Version: "1", Applied: 1, Total: 1}, {Version: "2", Applied: 1, Total: 1}}
+	files, err = ex.Pending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"4"}, versions(files))
+
+	// Partially applied checkpoint is continued.
+	*rrw = []*migrate.Revision{{Version: "3", Applied: 1, Total: 2}}
+	files, err = ex.Pending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"3", "4"}, versions(files))
+
+	// Baseline databases skip it as well.
+	rrw.clean()
+	drv.dirty = true
+	ex, err = migrate.NewExecutor(drv, d, rrw, migrate.WithBaselineVersion("1"))
+	require.NoError(t, err)
+	files, err = ex.Pending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"2", "4"}, versions(files))
+}
+
 type (
 	mockDriver struct {
 		migrate.Driver
@@ -435,6 +1204,55 @@ type (
 	}
 )
 
+// constraintDriver is a mockDriver that reports constraint violations.
+type constraintDriver struct {
+	*mockDriver
+}
+
+func (*constraintDriver) ConstraintError(err error) (*migrate.ConstraintError, bool) {
+	if !strings.Contains(err.Error(), "constraint failed") {
+		return nil, false
+	}
+	return &migrate.ConstraintError{Kind: "unique", Table: "users", Columns: []string{"name"}}, true
+}
+
+// valueCleanDriver is a dirty mockDriver that returns the NotCleanError as a value.
+type valueCleanDriver struct {
+	*mockDriver
+}
+
+func (*valueCleanDriver) CheckClean(context.Context, *migrate.TableIdent) error {
+	return migrate.NotCleanError{Reason: "found table"}
+}
+
+// posDriver is a mockDriver that reports the position of syntax errors.
+type posDriver struct {
+	*mockDriver
+}
+
+func (*posDriver) ErrorPos(stmt string, err error) (int, bool) {
+	if !strings.HasPrefix(err.Error(), "syntax error") {
+		return 0, false
+	}
+	return strings.Index(stmt, "FORM"), true
+}
+
+// warnDriver is a mockDriver that reports warnings for the executed statements.
+type warnDriver struct {
+	*mockDriver
+	last   string
+	warnOn map[string][]migrate.Warning
+}
+
+func (m *warnDriver) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	m.last = query
+	return m.mockDriver.ExecContext(ctx, query, args...)
+}
+
+func (m *warnDriver) Warnings(context.Context) ([]migrate.Warning, error) {
+	return m.warnOn[m.last], nil
+}
+
 // the nth call to ExecContext will fail with the given error.
 func (m *mockDriver) failOn(n int, err error) {
 	m.failCounter = n
@@ -0,0 +1,65 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// atlas:phase directive.
+const directivePhase = "phase"
+
+// Phases of the expand/contract (also known as parallel change) pattern.
+// Expand files add the new schema objects alongside the old ones, and are
+// shipped together with the application code that writes to both. Contract
+// files remove the old objects, and are shipped only after all application
+// instances stopped using them (i.e. in a later release).
+const (
+	PhaseExpand   = "expand"
+	PhaseContract = "contract"
+)
+
+// FilePhase returns the phase of the migration file, as set by the atlas:phase
+// directive in its header. An empty string is returned for files without it.
+//
+//	-- atlas:phase contract
+//	ALTER TABLE `users` DROP COLUMN `name`;
+func FilePhase(f File) (string, error) {
+	for _, line := range strings.Split(text(f.Bytes()), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// The header ends at the first non-comment line.
+		if !strings.HasPrefix(line, "--") && !strings.HasPrefix(line, "#") {
+			return "", nil
+		}
+		for _, p := range []string{"#", "--", "-- "} {
+			if d, ok := directive(line, directivePhase, p); ok {
+				return ValidPhase(d)
+			}
+		}
+	}
+	return "", nil
+}
+
+// ValidPhase validates the given phase name, and returns it normalized.
+func ValidPhase(phase string) (string, error) {
+	switch p := strings.ToLower(strings.TrimSpace(phase)); p {
+	case PhaseExpand, PhaseContract:
+		return p, nil
+	default:
+		return "", fmt.Errorf("sql/migrate: unknown phase %q, expect %q or %q", phase, PhaseExpand, PhaseContract)
+	}
+}
+
+// PlanWithPhase tags the migration files written by the Planner with the given
+// phase, using the atlas:phase directive. An empty phase leaves them untagged.
+func PlanWithPhase(phase string) PlannerOption {
+	return func(p *Planner) {
+		p.phase = phase
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"errors"
+	"fmt"
+)
+
+type (
+	// PosError is returned by the Executor in case the database reported the position of
+	// an error within the failed statement (e.g. a syntax error), and the driver was able to
+	// map it. The position is mapped to the migration file, to avoid searching for it in large
+	// files.
+	PosError struct {
+		File   string // Name of the migration file.
+		Stmt   int    // Position (1-based) of the statement in the file.
+		Offset int    // Byte offset (0-based) of the error in the file.
+		Line   int    // Line (1-based) of the error in the file.
+		Column int    // Column (1-based, in characters) of the error in the file.
+		Err    error  // Underlying error.
+	}
+
+	// ErrorPositioner is the interface implemented by drivers that can extract
+	// the position of an error within the failed statement from database errors.
+	ErrorPositioner interface {
+		// ErrorPos returns the byte offset (0-based) of the error within the
+		// given statement, or false if the database did not report it.
+		ErrorPos(stmt string, err error) (int, bool)
+	}
+)
+
+// Error implements the error interface.
+func (e *PosError) Error() string {
+	return fmt.Sprintf("%v [line %d, column %d of %q]", e.Err, e.Line, e.Column, e.File)
+}
+
+// Unwrap returns the underlying error.
+func (e *PosError) Unwrap() error {
+	return e.Err
+}
+
+// posErr wraps the given error of the executed statement with its position in
+// the migration file, in case the driver is able to extract it from the error.
+// Statements that were rendered or modified by hooks are not mapped, as their
+// text does not match the file.
+func (e *Executor) posErr(m File, i int, stmt string, err error) error {
+	p, ok := e.drv.(ErrorPositioner)
+	if herr := (*hookError)(nil); !ok || errors.As(err, &herr) {
+		return err
+	}
+	decls, derr := fileStmtDecls(m)
+	if derr != nil || i >= len(decls) || decls[i].Text != stmt {
+		return err
+	}
+	off, ok := p.ErrorPos(stmt, err)
+	if !ok || off < 0 || off > len(stmt) {
+		return err
+	}
+	b := m.Bytes()
+	if off += decls[i].Pos; off > len(b) {
+		return err
+	}
+	pos := PositionOf(b, off)
+	return &PosError{
+		File:   m.Name(),
+		Stmt:   i + 1,
+		Offset: pos.Offset,
+		Line:   pos.Line,
+		Column: pos.Column,
+		Err:    err,
+	}
+}
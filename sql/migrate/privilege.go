@@ -0,0 +1,71 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A Privilege describes a privilege on a database object that
+	// is required for applying a change. For example:
+	//
+	//	Privilege{Name: "CREATE", Object: `schema "app"`, Reason: `create table "users"`}
+	Privilege struct {
+		Name   string `json:"privilege"` // Name of the privilege, e.g. CREATE or OWNER.
+		Object string `json:"object"`    // The object the privilege is required on.
+		Reason string `json:"reason"`    // The change that requires the privilege.
+	}
+
+	// PrivilegeChecker is the interface implemented by drivers that can
+	// check, before applying changes, which of the privileges they require
+	// are not held by the role (user) of the connection.
+	PrivilegeChecker interface {
+		// MissingPrivileges returns the privileges required for applying
+		// the given changes that are not held by the connected role.
+		MissingPrivileges(context.Context, []schema.Change) ([]*Privilege, error)
+	}
+
+	// MissingPrivilegesError is returned by CheckPrivileges in case
+	// the connected role is missing privileges for applying changes.
+	MissingPrivilegesError struct {
+		Privileges []*Privilege
+	}
+)
+
+// String returns the privilege in a human-readable format.
+func (p *Privilege) String() string {
+	return fmt.Sprintf("%s on %s (%s)", p.Name, p.Object, p.Reason)
+}
+
+// Error implements the error interface.
+func (e *MissingPrivilegesError) Error() string {
+	names := make([]string, len(e.Privileges))
+	for i, p := range e.Privileges {
+		names[i] = fmt.Sprintf("%s on %s", p.Name, p.Object)
+	}
+	return fmt.Sprintf("sql/migrate: connected role is missing %d privileges: %s", len(names), strings.Join(names, ", "))
+}
+
+// CheckPrivileges checks if the connected role of the given driver holds the privileges
+// required for applying the given changes, and returns a MissingPrivilegesError if not.
+func CheckPrivileges(ctx context.Context, drv Driver, changes []schema.Change) error {
+	c, ok := drv.(PrivilegeChecker)
+	if !ok {
+		return fmt.Errorf("sql/migrate: driver %T does not support checking privileges", drv)
+	}
+	missing, err := c.MissingPrivileges(ctx, changes)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return &MissingPrivilegesError{Privileges: missing}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+const (
+	// atlas:reverse directive marks files that hold the reverse of a migration
+	// file. These files are skipped by LocalDir.Files, as they are not executed.
+	directiveReverse = "reverse"
+	// downSuffix is the suffix of reverse files.
+	downSuffix = ".down.sql"
+)
+
+// ReverseFile returns the reverse file of the given migration file, written by a Planner
+// configured with PlanWithReverse. An error wrapping fs.ErrNotExist is returned in case
+// the migration file has no reverse file in the directory.
+func ReverseFile(dir Dir, f File) (File, error) {
+	name := strings.TrimSuffix(f.Name(), ".sql") + downSuffix
+	b, err := fs.ReadFile(dir, name)
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: read reverse file of %q: %w", f.Name(), err)
+	}
+	switch up, ok := directive(text(b), directiveReverse, directivePrefixSQL); {
+	// A file with the down suffix, but without the directive, is a migration file.
+	case !ok:
+		return nil, fmt.Errorf("sql/migrate: reverse file of %q: %w", f.Name(), fs.ErrNotExist)
+	case up != f.Name():
+		return nil, fmt.Errorf("sql/migrate: reverse file %q belongs to %q, not to %q", name, up, f.Name())
+	}
+	return NewLocalFile(name, b), nil
+}
+
+// PlanReverse plans the changes that revert the given changes (e.g. AddTable is reverted
+// by DropTable). Changes whose reversal does not restore the state of the database completely,
+// such as dropped tables or columns that are re-created without their data, are annotated
+// as irreversible in the comments of the plan, and the plan is marked as non-reversible.
+func PlanReverse(ctx context.Context, pl PlanApplier, name string, changes []schema.Change, opts ...PlanOption) (*Plan, error) {
+	r := &reverser{notes: make(map[string][]string)}
+	rev, err := r.changes(changes)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := pl.PlanChanges(ctx, name, rev, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.notes) == 0 {
+		return plan, nil
+	}
+	plan.Reversible = false
+	for _, c := range plan.Changes {
+		k := noteKey(c.Source)
+		if ns, ok := r.notes[k]; ok && k != "" {
+			c.Comment = annotate(c.Comment, ns)
+			delete(r.notes, k)
+		}
+	}
+	// Notes that were not attached to any statement are attached to the first one.
+	if len(r.notes) > 0 && len(plan.Changes) > 0 {
+		var ns []string
+		for _, k := range r.order {
+			ns = append(ns, r.notes[k]...)
+		}
+		plan.Changes[0].Comment = annotate(plan.Changes[0].Comment, ns)
+	}
+	return plan, nil
+}
+
+// reverser reverts schema changes and records the reverted changes
+// that are irreversible, keyed by the schema or the table they modify.
+type reverser struct {
+	notes map[string][]string
+	order []string
+}
+
+// changes returns the changes that revert the given changes, in reverse order.
+func (r *reverser) changes(changes []schema.Change) ([]schema.Change, error) {
+	rev := make([]schema.Change, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		c, err := r.change(changes[i])
+		if err != nil {
+			return nil, err
+		}
+		rev = append(rev, c...)
+	}
+	return rev, nil
+}
+
+func (r *reverser) change(c schema.Change) ([]schema.Change, error) {
+	switch c := c.(type) {
+	case *schema.AddSchema:
+		return []schema.Change{&schema.DropSchema{S: c.S}}, nil
+	case *schema.DropSchema:
+		r.note(c.S, nil, fmt.Sprintf("the data of the dropped schema %q is not restored", c.S.Name))
+		rev := []schema.Change{&schema.AddSchema{S: c.S}}
+		for _, t := range c.S.Tables {
+			rev = append(rev, &schema.AddTable{T: t})
+		}
+		return rev, nil
+	case *schema.ModifySchema:
+		rev, err := r.changes(c.Changes)
+		if err != nil {
+			return nil, err
+		}
+		return []schema.Change{&schema.ModifySchema{S: c.S, Changes: rev}}, nil
+	case *schema.ModifyRealm:
+		rev, err := r.changes(c.Changes)
+		if err != nil {
+			return nil, err
+		}
+		return []schema.Change{&schema.ModifyRealm{R: c.R, Changes: rev}}, nil
+	case *schema.AddTable:
+		return []schema.Change{&schema.DropTable{T: c.T}}, nil
+	case *schema.DropTable:
+		r.note(nil, c.T, fmt.Sprintf("the data of the dropped table %q is not restored", c.T.Name))
+		return []schema.Change{&schema.AddTable{T: c.T}}, nil
+	case *schema.RenameTable:
+		return []schema.Change{&schema.RenameTable{From: c.To, To: c.From}}, nil
+	case *schema.ModifyTable:
+		rev := make([]schema.Change, 0, len(c.Changes))
+		for i := len(c.Changes) - 1; i >= 0; i-- {
+			x, err := r.tableChange(c.T, c.Changes[i])
+			if err != nil {
+				return nil, err
+			}
+			rev = append(rev, x)
+		}
+		return []schema.Change{&schema.ModifyTable{T: c.T, Changes: rev}}, nil
+	case *schema.AddView:
+		return []schema.Change{&schema.DropView{V: c.V}}, nil
+	case *schema.DropView:
+		return []schema.Change{&schema.AddView{V: c.V}}, nil
+	case *schema.ModifyView:
+		return []schema.Change{&schema.ModifyView{From: c.To, To: c.From}}, nil
+	case *schema.AddFunc:
+		return []schema.Change{&schema.DropFunc{F: c.F}}, nil
+	case *schema.DropFunc:
+		return []schema.Change{&schema.AddFunc{F: c.F}}, nil
+	case *schema.ModifyFunc:
+		return []schema.Change{&schema.ModifyFunc{From: c.To, To: c.From}}, nil
+	case *schema.AddProc:
+		return []schema.Change{&schema.DropProc{P: c.P}}, nil
+	case *schema.DropProc:
+		return []schema.Change{&schema.AddProc{P: c.P}}, nil
+	case *schema.ModifyProc:
+		return []schema.Change{&schema.ModifyProc{From: c.To, To: c.From}}, nil
+	case *schema.AddSequence:
+		return []schema.Change{&schema.DropSequence{S: c.S}}, nil
+	case *schema.DropSequence:
+		return []schema.Change{&schema.AddSequence{S: c.S}}, nil
+	case *schema.ModifySequence:
+		return []schema.Change{&schema.ModifySequence{From: c.To, To: c.From}}, nil
+	case *schema.AddTrigger:
+		return []schema.Change{&schema.DropTrigger{T: c.T}}, nil
+	case *schema.DropTrigger:
+		return []schema.Change{&schema.AddTrigger{T: c.T}}, nil
+	case *schema.ModifyTrigger:
+		return []schema.Change{&schema.ModifyTrigger{From: c.To, To: c.From}}, nil
+	case *schema.AddAttr:
+		return []schema.Change{&schema.DropAttr{A: c.A}}, nil
+	case *schema.DropAttr:
+		return []schema.Change{&schema.AddAttr{A: c.A}}, nil
+	case *schema.ModifyAttr:
+		return []schema.Change{&schema.ModifyAttr{From: c.To, To: c.From}}, nil
+	default:
+		return nil, fmt.Errorf("sql/migrate: cannot reverse change %T", c)
+	}
+}
+
+// tableChange returns the change that reverts the given change of the table.
+func (r *reverser) tableChange(t *schema.Table, c schema.Change) (schema.Change, error) {
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		return &schema.DropColumn{C: c.C}, nil
+	case *schema.DropColumn:
+		r.note(nil, t, fmt.Sprintf("the data of the dropped column %q is not restored", c.C.Name))
+		return &schema.AddColumn{C: c.C}, nil
+	case *schema.ModifyColumn:
+		if c.Change.Is(schema.ChangeType) {
+			r.note(nil, t, fmt.Sprintf("the values of column %q may not convert back to their original type", c.From.Name))
+		}
+		return &schema.ModifyColumn{From: c.To, To: c.From, Change: c.Change}, nil
+	case *schema.RenameColumn:
+		return &schema.RenameColumn{From: c.To, To: c.From}, nil
+	case *schema.AddIndex:
+		return &schema.DropIndex{I: c.I}, nil
+	case *schema.DropIndex:
+		return &schema.AddIndex{I: c.I}, nil
+	case *schema.ModifyIndex:
+		return &schema.ModifyIndex{From: c.To, To: c.From, Change: c.Change}, nil
+	case *schema.RenameIndex:
+		return &schema.RenameIndex{From: c.To, To: c.From}, nil
+	case *schema.AddForeignKey:
+		return &schema.DropForeignKey{F: c.F}, nil
+	case *schema.DropForeignKey:
+		return &schema.AddForeignKey{F: c.F}, nil
+	case *schema.ModifyForeignKey:
+		return &schema.ModifyForeignKey{From: c.To, To: c.From, Change: c.Change}, nil
+	case *schema.RenameForeignKey:
+		return &schema.RenameForeignKey{From: c.To, To: c.From}, nil
+	case *schema.AddCheck:
+		return &schema.DropCheck{C: c.C}, nil
+	case *schema.DropCheck:
+		return &schema.AddCheck{C: c.C}, nil
+	case *schema.ModifyCheck:
+		return &schema.ModifyCheck{From: c.To, To: c.From, Change: c.Change}, nil
+	case *schema.RenameCheck:
+		return &schema.RenameCheck{From: c.To, To: c.From}, nil
+	case *schema.AddAttr:
+		return &schema.DropAttr{A: c.A}, nil
+	case *schema.DropAttr:
+		return &schema.AddAttr{A: c.A}, nil
+	case *schema.ModifyAttr:
+		return &schema.ModifyAttr{From: c.To, To: c.From}, nil
+	default:
+		return nil, fmt.Errorf("sql/migrate: cannot reverse change %T of table %q", c, t.Name)
+	}
+}
+
+// note records an irreversible change of the given schema or table.
+func (r *reverser) note(s *schema.Schema, t *schema.Table, note string) {
+	k := noteKey(&schema.AddSchema{S: s})
+	if t != nil {
+		k = noteKey(&schema.AddTable{T: t})
+	}
+	if _, ok := r.notes[k]; !ok {
+		r.order = append(r.order, k)
+	}
+	r.notes[k] = append(r.notes[k], note)
+}
+
+// noteKey returns the key of the schema or the table the change modifies.
+func noteKey(c schema.Change) string {
+	var t *schema.Table
+	switch c := c.(type) {
+	case *schema.AddSchema:
+		if c.S != nil {
+			return c.S.Name
+		}
+		return ""
+	case *schema.AddTable:
+		t = c.T
+	case *schema.ModifyTable:
+		t = c.T
+	default:
+		return ""
+	}
+	if t.Schema != nil {
+		return t.Schema.Name + "." + t.Name
+	}
+	return "." + t.Name
+}
+
+// annotate appends the irreversibility notes to the given comment.
+func annotate(comment string, notes []string) string {
+	n := "irreversible: " + strings.Join(notes, ", ")
+	if comment == "" {
+		return n
+	}
+	return comment + " (" + n + ")"
+}
@@ -0,0 +1,158 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanReverse(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		pl    = &reversePlanner{}
+		s     = schema.New("public")
+		users = schema.NewTable("users").SetSchema(s)
+		pets  = schema.NewTable("pets").SetSchema(s)
+		id    = schema.NewIntColumn("id", "int")
+		name  = schema.NewStringColumn("name", "text")
+		idx   = schema.NewIndex("name").AddColumns(name)
+	)
+	plan, err := migrate.PlanReverse(ctx, pl, "init", []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{
+			&schema.AddColumn{C: id},
+			&schema.RenameColumn{From: schema.NewColumn("a"), To: schema.NewColumn("b")},
+			&schema.AddIndex{I: idx},
+		}},
+	})
+	require.NoError(t, err)
+	require.True(t, plan.Reversible)
+	require.Equal(t, []string{"ModifyTable pets: DropIndex name, RenameColumn a, DropColumn id", "DropTable users"}, pl.cmds(plan))
+
+	plan, err = migrate.PlanReverse(ctx, pl, "drop", []schema.Change{
+		&schema.DropTable{T: users},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{
+			&schema.DropColumn{C: name},
+			&schema.ModifyColumn{From: id, To: schema.NewStringColumn("id", "text"), Change: schema.ChangeType},
+		}},
+	})
+	require.NoError(t, err)
+	require.False(t, plan.Reversible)
+	require.Equal(t, []string{"ModifyTable pets: ModifyColumn id *schema.IntegerType, AddColumn name", "AddTable users"}, pl.cmds(plan))
+	require.Equal(t, `modify "pets" table (irreversible: the values of column "id" may not convert back to their original type, the data of the dropped column "name" is not restored)`, plan.Changes[0].Comment)
+	require.Equal(t, `create "users" table (irreversible: the data of the dropped table "users" is not restored)`, plan.Changes[1].Comment)
+
+	_, err = migrate.PlanReverse(ctx, pl, "unknown", []schema.Change{&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddTable{T: users}}}})
+	require.EqualError(t, err, `sql/migrate: cannot reverse change *schema.AddTable of table "pets"`)
+}
+
+func TestPlanner_WritePlanReverse(t *testing.T) {
+	var (
+		drv = &mockDriver{}
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	drv.changes = []schema.Change{&schema.AddTable{T: schema.NewTable("t1")}}
+	drv.plan = &migrate.Plan{Name: "add_t1", Changes: []*migrate.Change{{Cmd: "CREATE TABLE t1(c int)", Source: drv.changes[0]}}}
+	pl := migrate.NewPlanner(drv, d, migrate.PlanWithReverse(true), migrate.PlanWithVersionScheme(&migrate.SequenceScheme{}))
+	plan, err := pl.Plan(ctx, "add_t1", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.NotNil(t, plan.Reverse)
+	plan.Reverse = &migrate.Plan{Changes: []*migrate.Change{{Cmd: "DROP TABLE t1"}}}
+	require.NoError(t, pl.WritePlan(plan))
+	requireFileEqual(t, d, "1_add_t1.sql", "CREATE TABLE t1(c int);\n")
+	requireFileEqual(t, d, "1_add_t1.down.sql", "-- atlas:reverse 1_add_t1.sql\nDROP TABLE t1;\n")
+
+	// Reverse files are not part of the directory.
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "1_add_t1.sql", files[0].Name())
+	require.NoError(t, migrate.Validate(d))
+
+	rev, err := migrate.ReverseFile(d, files[0])
+	require.NoError(t, err)
+	require.Equal(t, "1_add_t1.down.sql", rev.Name())
+	stmts, err := rev.Stmts()
+	require.NoError(t, err)
+	require.Equal(t, []string{"DROP TABLE t1;"}, stmts)
+	_, err = migrate.ReverseFile(d, migrate.NewLocalFile("2_add_t2.sql", nil))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	// Migration files with the down suffix are not reverse files.
+	require.NoError(t, d.WriteFile("3_add_t3.down.sql", []byte("CREATE TABLE t3(c int);\n")))
+	_, err = migrate.ReverseFile(d, migrate.NewLocalFile("3_add_t3.sql", nil))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.NoError(t, d.WriteFile("4_add_t4.down.sql", []byte("-- atlas:reverse 1_add_t1.sql\nDROP TABLE t1;\n")))
+	_, err = migrate.ReverseFile(d, migrate.NewLocalFile("4_add_t4.sql", nil))
+	require.EqualError(t, err, `sql/migrate: reverse file "4_add_t4.down.sql" belongs to "1_add_t1.sql", not to "4_add_t4.sql"`)
+
+	pl = migrate.NewPlanner(drv, d, migrate.PlanWithSplitter(migrate.SplitBySafety))
+	require.EqualError(t, pl.WritePlan(plan), "sql/migrate: plans with a reverse plan cannot be split")
+}
+
+// reversePlanner plans each change as a single statement that describes it.
+type reversePlanner struct{}
+
+func (*reversePlanner) PlanChanges(_ context.Context, name string, changes []schema.Change, _ ...migrate.PlanOption) (*migrate.Plan, error) {
+	plan := &migrate.Plan{Name: name, Reversible: true}
+	for _, c := range changes {
+		var comment string
+		switch c := c.(type) {
+		case *schema.AddTable:
+			comment = fmt.Sprintf("create %q table", c.T.Name)
+		case *schema.DropTable:
+			comment = fmt.Sprintf("drop %q table", c.T.Name)
+		case *schema.ModifyTable:
+			comment = fmt.Sprintf("modify %q table", c.T.Name)
+		}
+		plan.Changes = append(plan.Changes, &migrate.Change{Source: c, Comment: comment})
+	}
+	return plan, nil
+}
+
+func (*reversePlanner) ApplyChanges(context.Context, []schema.Change, ...migrate.PlanOption) error {
+	return nil
+}
+
+// cmds returns a description of each change in the plan.
+func (*reversePlanner) cmds(plan *migrate.Plan) []string {
+	cmds := make([]string, len(plan.Changes))
+	for i, c := range plan.Changes {
+		switch s := c.Source.(type) {
+		case *schema.AddTable:
+			cmds[i] = "AddTable " + s.T.Name
+		case *schema.DropTable:
+			cmds[i] = "DropTable " + s.T.Name
+		case *schema.ModifyTable:
+			var parts []string
+			for _, c := range s.Changes {
+				switch c := c.(type) {
+				case *schema.AddColumn:
+					parts = append(parts, "AddColumn "+c.C.Name)
+				case *schema.DropColumn:
+					parts = append(parts, "DropColumn "+c.C.Name)
+				case *schema.RenameColumn:
+					parts = append(parts, "RenameColumn "+c.To.Name)
+				case *schema.ModifyColumn:
+					parts = append(parts, fmt.Sprintf("ModifyColumn %s %T", c.To.Name, c.To.Type.Type))
+				case *schema.DropIndex:
+					parts = append(parts, "DropIndex "+c.I.Name)
+				}
+			}
+			cmds[i] = "ModifyTable " + s.T.Name + ": " + strings.Join(parts, ", ")
+		}
+	}
+	return cmds
+}
@@ -0,0 +1,90 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import "strings"
+
+const (
+	// atlas:seed directive.
+	directiveSeed = "seed"
+	// SeedFileSuffix is the suffix of seed files. Files with this suffix
+	// are seed files, even if they are not marked with the atlas:seed directive.
+	SeedFileSuffix = ".seed.sql"
+)
+
+// IsSeed reports whether the migration file is a seed file. Seed files populate
+// data (e.g. lookup tables or fixtures) instead of changing the schema. A file is
+// a seed file if its name ends with ".seed.sql", or if it is marked with the
+// atlas:seed directive in its header.
+//
+//	-- atlas:seed
+//	INSERT INTO `roles` (`name`) VALUES ('admin'), ('member');
+//
+// Seed files are executed like the rest of the migration files, but unlike them:
+//
+//   - They are not executed when the directory is replayed for computing its
+//     schema (e.g. by 'migrate diff' or 'migrate lint').
+//   - Their content is excluded from the chained hashes of the atlas.sum file.
+//     Hence, changing a seed file changes only its own hash, and not the hashes
+//     of the files that follow it.
+//   - They can be scoped to environments or dialects using the arguments of the
+//     atlas:only directive. Seed files that do not match the Target of the
+//     Executor are recorded as applied, but are not executed.
+//
+// For example:
+//
+//	-- atlas:seed env=dev,test
+//	INSERT INTO `users` (`name`) VALUES ('a8m');
+func IsSeed(f File) bool {
+	if strings.HasSuffix(f.Name(), SeedFileSuffix) {
+		return true
+	}
+	_, ok := seedArgs(f)
+	return ok
+}
+
+// seedArgs returns the arguments of the atlas:seed directive, if the
+// file is marked with it. Note, the header ends at the first non-comment line.
+func seedArgs(f File) (string, bool) {
+	for _, line := range strings.Split(text(f.Bytes()), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") && !strings.HasPrefix(line, "#") {
+			return "", false
+		}
+		for _, p := range []string{"#", "--", "-- "} {
+			if d, ok := directive(line, directiveSeed, p); ok {
+				return strings.TrimSpace(d), true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchSeed reports whether the seed file should be executed on the given target.
+// Seed files without arguments are executed on all targets, and a nil target
+// matches only them.
+func matchSeed(t *Target, f File) (bool, error) {
+	args, _ := seedArgs(f)
+	if args == "" {
+		return true, nil
+	}
+	if t == nil {
+		t = &Target{}
+	}
+	return t.matchOnly(args)
+}
+
+// WithSkipSeeds configures the Executor to skip the execution of seed files. Like
+// statements that do not match the Target, skipped seed files are recorded as applied
+// in the revisions table. Seed files are always skipped by Executor.Replay.
+func WithSkipSeeds(b bool) ExecutorOption {
+	return func(ex *Executor) error {
+		ex.skipSeeds = b
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+type (
+	// SourceMap maps the statements of a migration file to their line and column ranges,
+	// allowing reports and errors to point at "20230101_users.sql:14:3" instead of raw
+	// byte offsets.
+	SourceMap struct {
+		name  string
+		text  []byte
+		lines []int // offsets of the first byte of each line
+		stmts []*StmtSource
+	}
+
+	// StmtSource describes the location of a statement in its file. The comments
+	// attached to the statement are accessible using the embedded Stmt.
+	StmtSource struct {
+		*Stmt
+		Start, End Position // End is exclusive
+	}
+
+	// Position describes a location in a file. Line and Column are 1-based,
+	// and columns are counted in characters (runes), not bytes.
+	Position struct {
+		Offset int
+		Line   int
+		Column int
+	}
+)
+
+// NewSourceMap returns the SourceMap of the given file.
+func NewSourceMap(f File) (*SourceMap, error) {
+	stmts, err := fileStmtDecls(f)
+	if err != nil {
+		return nil, err
+	}
+	m := &SourceMap{name: f.Name(), text: f.Bytes(), lines: []int{0}}
+	for i, b := range m.text {
+		if b == '\n' {
+			m.lines = append(m.lines, i+1)
+		}
+	}
+	m.stmts = make([]*StmtSource, len(stmts))
+	for i, s := range stmts {
+		m.stmts[i] = &StmtSource{Stmt: s, Start: m.Position(s.Pos), End: m.Position(s.Pos + len(s.Text))}
+	}
+	return m, nil
+}
+
+// Stmts returns the statements of the file along with their locations.
+func (m *SourceMap) Stmts() []*StmtSource {
+	return m.stmts
+}
+
+// StmtAt returns the statement that contains the given offset, or nil if
+// the offset points to a comment or whitespace between statements.
+func (m *SourceMap) StmtAt(off int) *StmtSource {
+	i := sort.Search(len(m.stmts), func(i int) bool {
+		return m.stmts[i].End.Offset > off
+	})
+	if i == len(m.stmts) || m.stmts[i].Start.Offset > off {
+		return nil
+	}
+	return m.stmts[i]
+}
+
+// Position returns the position of the given offset in the file.
+func (m *SourceMap) Position(off int) Position {
+	off = clampOffset(off, len(m.text))
+	// Index of the last line that starts at or before the offset.
+	l := sort.Search(len(m.lines), func(i int) bool { return m.lines[i] > off }) - 1
+	return Position{
+		Offset: off,
+		Line:   l + 1,
+		Column: utf8.RuneCount(m.text[m.lines[l]:off]) + 1,
+	}
+}
+
+// Location returns the location of the given offset in the "file:line:column" format.
+func (m *SourceMap) Location(off int) string {
+	return fmt.Sprintf("%s:%s", m.name, m.Position(off))
+}
+
+// PositionOf returns the position of the given offset in b. Callers that resolve
+// the positions of multiple offsets in the same file should use SourceMap instead.
+func PositionOf(b []byte, off int) Position {
+	off = clampOffset(off, len(b))
+	start := bytes.LastIndexByte(b[:off], '\n') + 1
+	return Position{
+		Offset: off,
+		Line:   bytes.Count(b[:off], []byte("\n")) + 1,
+		Column: utf8.RuneCount(b[start:off]) + 1,
+	}
+}
+
+// String implements fmt.Stringer.
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// clampOffset limits the given offset to the [0, n] range.
+func clampOffset(off, n int) int {
+	switch {
+	case off < 0:
+		return 0
+	case off > n:
+		return n
+	}
+	return off
+}
@@ -0,0 +1,44 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceMap(t *testing.T) {
+	f := migrate.NewLocalFile("20230101_users.sql", []byte(`-- create "users" table
+CREATE TABLE users (
+  id int
+);
+
+-- add "name" column
+  ALTER TABLE users ADD COLUMN name text DEFAULT 'é'; ALTER TABLE users ADD COLUMN age int;
+`))
+	m, err := migrate.NewSourceMap(f)
+	require.NoError(t, err)
+	stmts := m.Stmts()
+	require.Len(t, stmts, 3)
+
+	require.Equal(t, migrate.Position{Offset: 24, Line: 2, Column: 1}, stmts[0].Start)
+	require.Equal(t, "4:3", stmts[0].End.String())
+	require.Equal(t, []string{"-- create \"users\" table\n"}, stmts[0].Comments)
+
+	require.Equal(t, "7:3", stmts[1].Start.String())
+	require.Equal(t, []string{"-- add \"name\" column\n"}, stmts[1].Comments)
+	// Columns are counted in characters.
+	require.Equal(t, "7:55", stmts[2].Start.String())
+	require.Equal(t, "20230101_users.sql:7:55", m.Location(stmts[2].Pos))
+	require.Equal(t, migrate.PositionOf(f.Bytes(), stmts[2].Pos), stmts[2].Start)
+
+	require.Equal(t, stmts[0], m.StmtAt(stmts[0].Pos+10))
+	require.Equal(t, stmts[2], m.StmtAt(stmts[2].End.Offset-1))
+	require.Nil(t, m.StmtAt(0))
+	require.Nil(t, m.StmtAt(stmts[2].End.Offset))
+}
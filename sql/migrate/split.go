@@ -0,0 +1,243 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"ariga.io/atlas/sql/schema"
+)
+
+// A PlanSplitter splits a Plan into multiple plans. Each of the returned
+// plans is written by the Planner to its own migration file(s), in order.
+type PlanSplitter func(*Plan) ([]*Plan, error)
+
+var (
+	// SplitByResource is a PlanSplitter that starts a new plan each time the
+	// changed resource (a table, or a schema) changes. Consecutive changes of
+	// the same resource are kept together, and the order of changes is kept
+	// as is. Plans are named after their resources, e.g. "<name>_users".
+	SplitByResource PlanSplitter = splitByResource
+
+	// SplitBySafety is a PlanSplitter that splits a plan into two: the first
+	// holds the non-destructive changes, and the second holds the destructive
+	// ones (dropping schemas, tables or columns), named "<name>_destructive".
+	// The order of changes within each plan is kept as is.
+	SplitBySafety PlanSplitter = splitBySafety
+)
+
+// SplitByScope returns a PlanSplitter that starts a new plan each time the
+// ownership scope of the changed resource changes, in order to emit a plan
+// per team. Plans are named after their scopes, e.g. "<name>_billing", and
+// changes of resources that are not owned by any of the scopes are kept in
+// plans without a suffix. The order of changes is kept as is.
+func SplitByScope(scopes ...*schema.Scope) PlanSplitter {
+	return func(p *Plan) ([]*Plan, error) {
+		var (
+			plans []*Plan
+			last  string
+		)
+		for _, c := range p.Changes {
+			s, ok := scopeOf(c.Source, scopes)
+			// Changes without a source are attached to the previous change.
+			if len(plans) == 0 || ok && s != last {
+				plans = append(plans, subPlan(p, s))
+				last = s
+			}
+			plans[len(plans)-1].Changes = append(plans[len(plans)-1].Changes, c)
+		}
+		if len(plans) < 2 {
+			return []*Plan{p}, nil
+		}
+		return plans, nil
+	}
+}
+
+// scopeOf returns the name of the first scope owning the resource
+// changed by the given change, or an empty string if there is none.
+func scopeOf(c schema.Change, scopes []*schema.Scope) (string, bool) {
+	var owns func(*schema.Scope) bool
+	switch c := c.(type) {
+	case *schema.AddSchema:
+		owns = func(s *schema.Scope) bool { return s.OwnsSchema(c.S) }
+	case *schema.DropSchema:
+		owns = func(s *schema.Scope) bool { return s.OwnsSchema(c.S) }
+	case *schema.ModifySchema:
+		owns = func(s *schema.Scope) bool { return s.OwnsSchema(c.S) }
+	case *schema.AddTable:
+		owns = func(s *schema.Scope) bool { return s.OwnsTable(c.T) }
+	case *schema.DropTable:
+		owns = func(s *schema.Scope) bool { return s.OwnsTable(c.T) }
+	case *schema.ModifyTable:
+		owns = func(s *schema.Scope) bool { return s.OwnsTable(c.T) }
+	case *schema.RenameTable:
+		owns = func(s *schema.Scope) bool { return s.OwnsTable(c.To) }
+	default:
+		return "", false
+	}
+	for _, s := range scopes {
+		if owns(s) {
+			return s.Name, true
+		}
+	}
+	return "", true
+}
+
+func splitByResource(p *Plan) ([]*Plan, error) {
+	var (
+		plans []*Plan
+		last  string
+	)
+	for _, c := range p.Changes {
+		r, ok := resourceName(c.Source)
+		// Changes without a source are attached to the previous change.
+		if len(plans) == 0 || ok && r != last {
+			plans = append(plans, subPlan(p, r))
+			last = r
+		}
+		plans[len(plans)-1].Changes = append(plans[len(plans)-1].Changes, c)
+	}
+	if len(plans) < 2 {
+		return []*Plan{p}, nil
+	}
+	return plans, nil
+}
+
+func splitBySafety(p *Plan) ([]*Plan, error) {
+	safe, destructive := subPlan(p, ""), subPlan(p, "destructive")
+	for _, c := range p.Changes {
+		if c.Source != nil && isDestructive(c.Source) {
+			destructive.Changes = append(destructive.Changes, c)
+		} else {
+			safe.Changes = append(safe.Changes, c)
+		}
+	}
+	if len(safe.Changes) == 0 || len(destructive.Changes) == 0 {
+		return []*Plan{p}, nil
+	}
+	return []*Plan{safe, destructive}, nil
+}
+
+// splitByTx splits the plan into groups of consecutive changes that can be executed
+// in a transaction, and changes that cannot (see Change.NoTx). The latter are named
+// "<name>_notx". The order of changes is kept as is.
+func splitByTx(p *Plan) []*Plan {
+	var plans []*Plan
+	for i, c := range p.Changes {
+		if i == 0 || c.NoTx != p.Changes[i-1].NoTx {
+			suffix := ""
+			if c.NoTx {
+				suffix = "notx"
+			}
+			plans = append(plans, subPlan(p, suffix))
+		}
+		plans[len(plans)-1].Changes = append(plans[len(plans)-1].Changes, c)
+	}
+	if len(plans) < 2 {
+		return []*Plan{p}
+	}
+	return plans
+}
+
+// isNoTx reports if the plan holds changes that cannot be executed in a transaction.
+func isNoTx(p *Plan) bool {
+	for _, c := range p.Changes {
+		if c.NoTx {
+			return true
+		}
+	}
+	return false
+}
+
+// subPlan returns an empty plan that inherits the attributes of the given
+// one, and its name is suffixed with the given suffix, if not empty.
+func subPlan(p *Plan, suffix string) *Plan {
+	sub := &Plan{
+		Name:          p.Name,
+		Version:       p.Version,
+		Reversible:    p.Reversible,
+		Transactional: p.Transactional,
+	}
+	switch {
+	case suffix == "":
+	case sub.Name == "":
+		sub.Name = suffix
+	default:
+		sub.Name += "_" + suffix
+	}
+	return sub
+}
+
+// resourceName returns the name of the resource changed by the given change.
+func resourceName(c schema.Change) (string, bool) {
+	switch c := c.(type) {
+	case *schema.AddSchema:
+		return c.S.Name, true
+	case *schema.DropSchema:
+		return c.S.Name, true
+	case *schema.ModifySchema:
+		return c.S.Name, true
+	case *schema.AddTable:
+		return c.T.Name, true
+	case *schema.DropTable:
+		return c.T.Name, true
+	case *schema.ModifyTable:
+		return c.T.Name, true
+	case *schema.RenameTable:
+		return c.To.Name, true
+	case *schema.AddView:
+		return c.V.Name, true
+	case *schema.DropView:
+		return c.V.Name, true
+	case *schema.ModifyView:
+		return c.To.Name, true
+	case *schema.AddFunc:
+		return c.F.Name, true
+	case *schema.DropFunc:
+		return c.F.Name, true
+	case *schema.ModifyFunc:
+		return c.To.Name, true
+	case *schema.AddProc:
+		return c.P.Name, true
+	case *schema.DropProc:
+		return c.P.Name, true
+	case *schema.ModifyProc:
+		return c.To.Name, true
+	case *schema.AddSequence:
+		return c.S.Name, true
+	case *schema.DropSequence:
+		return c.S.Name, true
+	case *schema.ModifySequence:
+		return c.To.Name, true
+	// Triggers are grouped with the tables they are defined on.
+	case *schema.AddTrigger:
+		return c.T.Table.Name, true
+	case *schema.DropTrigger:
+		return c.T.Table.Name, true
+	case *schema.ModifyTrigger:
+		return c.To.Table.Name, true
+	default:
+		return "", false
+	}
+}
+
+// isDestructive reports if the given change may cause data loss.
+func isDestructive(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.DropSchema, *schema.DropTable, *schema.DropColumn:
+		return true
+	case *schema.ModifySchema:
+		for _, c := range c.Changes {
+			if isDestructive(c) {
+				return true
+			}
+		}
+	case *schema.ModifyTable:
+		for _, c := range c.Changes {
+			if isDestructive(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
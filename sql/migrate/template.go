@@ -0,0 +1,36 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import "strings"
+
+// atlas:template directive.
+const directiveTemplate = "template"
+
+// IsTemplate reports whether the migration file is marked with the atlas:template
+// directive in its header. The statements of such files are rendered as templates
+// even if no template variables were given, which fails on undefined variables
+// instead of executing them as is.
+//
+//	-- atlas:template
+//	DELETE FROM `events` WHERE `created_at` < NOW() - INTERVAL {{ var "retention_days" }} DAY;
+func IsTemplate(f File) bool {
+	for _, line := range strings.Split(text(f.Bytes()), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// The header ends at the first non-comment line.
+		if !strings.HasPrefix(line, "--") && !strings.HasPrefix(line, "#") {
+			return false
+		}
+		for _, p := range []string{"#", "--", "-- "} {
+			if _, ok := directive(line, directiveTemplate, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+CREATE FUNCTION add(a integer, b integer) RETURNS integer AS $$
+BEGIN
+    RETURN a + b;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $body$ BEGIN EXECUTE format('ALTER DATABASE %I SET timezone = ''UTC''', current_database()); END $body$;
+
+SELECT a$b, $1 FROM t;
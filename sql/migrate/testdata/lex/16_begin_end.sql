@@ -0,0 +1,27 @@
+BEGIN;
+CREATE TABLE t (c int, `begin` int, end_at int);
+
+CREATE PROCEDURE p(IN n int)
+BEGIN
+    DECLARE i int DEFAULT 0;
+    label: WHILE i < n DO
+        IF i % 2 = 0 THEN
+            INSERT INTO t (c) VALUES (i);
+        END IF;
+        SET i = i + 1;
+    END WHILE label;
+    CASE n
+        WHEN 0 THEN SELECT 'BEGIN;';
+        ELSE BEGIN SELECT CASE WHEN n > 0 THEN 1 ELSE 0 END; END;
+    END CASE;
+END;
+
+CREATE TRIGGER t_insert AFTER INSERT ON t
+FOR EACH ROW
+BEGIN
+    -- END;
+    UPDATE t SET end_at = 1 WHERE c = NEW.c;
+END;
+
+CREATE DEFINER = `root`@`%` FUNCTION f(x int) RETURNS int DETERMINISTIC RETURN CASE WHEN x > 0 THEN 1 ELSE 0 END;
+COMMIT;
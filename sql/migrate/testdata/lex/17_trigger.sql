@@ -0,0 +1,15 @@
+CREATE TABLE users (id int, name text, updated_at int);
+
+CREATE TRIGGER IF NOT EXISTS users_update AFTER UPDATE OF name ON users
+FOR EACH ROW
+WHEN CASE WHEN NEW.name IS NULL THEN 0 ELSE 1 END = 1
+BEGIN
+    UPDATE users SET updated_at = 1 WHERE id = NEW.id;
+    INSERT INTO logs (msg) VALUES ('users; updated');
+END;
+
+CREATE TEMP TRIGGER users_delete BEFORE DELETE ON users BEGIN SELECT RAISE(ABORT, 'end;'); END;
+
+CREATE OR REPLACE TRIGGER users_audit AFTER INSERT OR UPDATE ON users FOR EACH STATEMENT EXECUTE FUNCTION audit();
+
+CREATE CONSTRAINT TRIGGER users_check AFTER INSERT ON users DEFERRABLE FOR EACH ROW EXECUTE FUNCTION check_users();
@@ -0,0 +1,40 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import "strings"
+
+// atlas:txmode directive.
+const directiveTxMode = "txmode"
+
+// TxModeNone is the transaction mode of files that must not be wrapped in a
+// transaction, as their statements cannot be executed inside a transaction
+// block (e.g. CREATE INDEX CONCURRENTLY in PostgreSQL).
+const TxModeNone = "none"
+
+// FileTxMode returns the transaction mode of the migration file, as set by the
+// atlas:txmode directive in its header. An empty string is returned for files
+// without it.
+//
+//	-- atlas:txmode none
+//	CREATE INDEX CONCURRENTLY "users_name" ON "users" ("name");
+func FileTxMode(f File) string {
+	for _, line := range strings.Split(text(f.Bytes()), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// The header ends at the first non-comment line.
+		if !strings.HasPrefix(line, "--") && !strings.HasPrefix(line, "#") {
+			return ""
+		}
+		for _, p := range []string{"#", "--", "-- "} {
+			if d, ok := directive(line, directiveTxMode, p); ok {
+				return strings.ToLower(strings.TrimSpace(d))
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,279 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// A VersionScheme describes how the versions of migration files
+	// are generated, and how they are parsed from the file names.
+	VersionScheme interface {
+		// Next returns the version of a new migration file, given the
+		// latest version in the directory ("" if there are no files).
+		Next(last string) (string, error)
+		// Split splits the given file name into its version and description.
+		// It reports false if the name does not conform to the scheme.
+		Split(name string) (version, desc string, ok bool)
+		// Less reports whether version v1 comes before version v2.
+		Less(v1, v2 string) bool
+	}
+
+	// A VersionedDir is implemented by directories that version their files using
+	// a VersionScheme, such as SchemeDir and a MultiDir that composes them.
+	VersionedDir interface {
+		Dir
+		// Scheme returns the VersionScheme of the directory, or nil if the
+		// directory does not use one.
+		Scheme() VersionScheme
+	}
+
+	// TimestampScheme is a VersionScheme that uses the current UTC time
+	// as the version, e.g. 20220101120000, optionally with a prefix.
+	TimestampScheme struct {
+		// Prefix is prepended to all versions, e.g. "billing-".
+		Prefix string
+		// Precision of the timestamp. One of time.Second (the default),
+		// time.Millisecond, time.Microsecond or time.Nanosecond.
+		Precision time.Duration
+	}
+
+	// SequenceScheme is a VersionScheme that uses sequential integers
+	// as the version, e.g. 0001, 0002, optionally with a prefix.
+	SequenceScheme struct {
+		// Prefix is prepended to all versions, e.g. "billing-".
+		Prefix string
+		// Width is the minimum number of digits. Shorter
+		// numbers are padded with leading zeros.
+		Width int
+	}
+)
+
+var (
+	_ VersionScheme = (*TimestampScheme)(nil)
+	_ VersionScheme = (*SequenceScheme)(nil)
+)
+
+// Next implements VersionScheme.Next.
+func (s *TimestampScheme) Next(last string) (string, error) {
+	layout, err := s.layout()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	if last != "" {
+		v := strings.TrimPrefix(last, s.Prefix)
+		if i := strings.IndexByte(layout, '.'); i > 0 && len(v) > i {
+			v = v[:i] + "." + v[i:]
+		}
+		t, err := time.Parse(layout, v)
+		if err != nil {
+			return "", fmt.Errorf("sql/migrate: invalid timestamp version %q", last)
+		}
+		// Versions must be increasing. In case the latest version was created
+		// in the same time unit (or clocks are skewed), the next unit is used.
+		if !now.Truncate(s.unit()).After(t) {
+			now = t.Add(s.unit())
+		}
+	}
+	return s.Prefix + strings.Replace(now.Format(layout), ".", "", 1), nil
+}
+
+// Split implements VersionScheme.Split.
+func (s *TimestampScheme) Split(name string) (string, string, bool) {
+	layout, err := s.layout()
+	if err != nil {
+		return "", "", false
+	}
+	// The layout contains a dot that is removed from the version.
+	return splitVersion(name, s.Prefix, len(layout)-strings.Count(layout, "."))
+}
+
+// Less implements VersionScheme.Less. Timestamps
+// have a fixed width and are compared lexicographically.
+func (s *TimestampScheme) Less(v1, v2 string) bool {
+	return v1 < v2
+}
+
+// unit returns the time unit of the versions.
+func (s *TimestampScheme) unit() time.Duration {
+	if s.Precision == 0 {
+		return time.Second
+	}
+	return s.Precision
+}
+
+// layout returns the time layout for the configured precision.
+func (s *TimestampScheme) layout() (string, error) {
+	switch s.Precision {
+	case 0, time.Second:
+		return "20060102150405", nil
+	case time.Millisecond:
+		return "20060102150405.000", nil
+	case time.Microsecond:
+		return "20060102150405.000000", nil
+	case time.Nanosecond:
+		return "20060102150405.000000000", nil
+	default:
+		return "", fmt.Errorf("sql/migrate: unsupported timestamp precision %s", s.Precision)
+	}
+}
+
+// Next implements VersionScheme.Next.
+func (s *SequenceScheme) Next(last string) (string, error) {
+	var n uint64
+	if last != "" {
+		var err error
+		if n, err = strconv.ParseUint(strings.TrimPrefix(last, s.Prefix), 10, 64); err != nil {
+			return "", fmt.Errorf("sql/migrate: invalid sequential version %q", last)
+		}
+	}
+	return fmt.Sprintf("%s%0*d", s.Prefix, s.Width, n+1), nil
+}
+
+// Split implements VersionScheme.Split.
+func (s *SequenceScheme) Split(name string) (string, string, bool) {
+	return splitVersion(name, s.Prefix, 0)
+}
+
+// Less implements VersionScheme.Less. Versions are compared by
+// their numeric value, regardless of their padding.
+func (s *SequenceScheme) Less(v1, v2 string) bool {
+	n1, err1 := strconv.ParseUint(strings.TrimPrefix(v1, s.Prefix), 10, 64)
+	n2, err2 := strconv.ParseUint(strings.TrimPrefix(v2, s.Prefix), 10, 64)
+	if err1 != nil || err2 != nil || n1 == n2 {
+		return v1 < v2
+	}
+	return n1 < n2
+}
+
+// SortRevisions sorts the given revisions by the VersionScheme of the directory,
+// if it uses one. Revisions are stored (and read) ordered by their versions as
+// strings, which does not match the order of schemes without a fixed width,
+// e.g. unpadded sequential versions where "10" comes before "9".
+func SortRevisions(dir Dir, revs []*Revision) {
+	vs, ok := dir.(VersionedDir)
+	if !ok || vs.Scheme() == nil {
+		return
+	}
+	s := vs.Scheme()
+	sort.SliceStable(revs, func(i, j int) bool {
+		return s.Less(revs[i].Version, revs[j].Version)
+	})
+}
+
+// splitVersion splits a file name of the form <prefix><digits>[_<desc>].sql.
+// If width is not zero, the version must contain exactly width digits.
+func splitVersion(name, prefix string, width int) (string, string, bool) {
+	name = strings.TrimSuffix(name, ".sql")
+	if !strings.HasPrefix(name, prefix) {
+		return "", "", false
+	}
+	n := len(prefix)
+	for n < len(name) && name[n] >= '0' && name[n] <= '9' {
+		n++
+	}
+	switch digits := n - len(prefix); {
+	case digits == 0, width > 0 && digits != width:
+		return "", "", false
+	case n == len(name):
+		return name, "", true
+	case name[n] == '_':
+		return name[:n], name[n+1:], true
+	default:
+		return "", "", false
+	}
+}
+
+// SchemeDir wraps a Dir and uses a VersionScheme for parsing the versions
+// and descriptions of its files, and for ordering them.
+type SchemeDir struct {
+	Dir
+	scheme VersionScheme
+}
+
+var _ VersionedDir = (*SchemeDir)(nil)
+
+// Lock implements DirLocker by locking the underlying Dir, if it is supported.
+func (d *SchemeDir) Lock(ctx context.Context) (func() error, error) {
+	return LockDir(ctx, d.Dir)
+}
+
+// NewSchemeDir returns a new SchemeDir for the given Dir and VersionScheme.
+func NewSchemeDir(dir Dir, s VersionScheme) *SchemeDir {
+	return &SchemeDir{Dir: dir, scheme: s}
+}
+
+// Scheme returns the VersionScheme of the directory.
+func (d *SchemeDir) Scheme() VersionScheme {
+	return d.scheme
+}
+
+// Files implements Dir.Files. It returns an error in case
+// a file name does not conform to the version scheme.
+func (d *SchemeDir) Files() ([]File, error) {
+	files, err := d.Dir.Files()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]File, len(files))
+	for i, f := range files {
+		v, desc, ok := d.scheme.Split(f.Name())
+		if !ok {
+			return nil, fmt.Errorf("sql/migrate: file %q does not match the version scheme", f.Name())
+		}
+		ret[i] = &schemeFile{File: f, version: v, desc: desc}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return d.scheme.Less(ret[i].Version(), ret[j].Version())
+	})
+	return ret, nil
+}
+
+// Checksum implements Dir.Checksum.
+func (d *SchemeDir) Checksum() (HashFile, error) {
+	files, err := d.Files()
+	if err != nil {
+		return nil, err
+	}
+	return checksum(files)
+}
+
+// schemeFile wraps a File with the version and
+// description parsed by the VersionScheme.
+type schemeFile struct {
+	File
+	version, desc string
+}
+
+func (f *schemeFile) Version() string { return f.version }
+func (f *schemeFile) Desc() string    { return f.desc }
+
+// StmtDecls returns the statement declarations of the wrapped file.
+func (f *schemeFile) StmtDecls() ([]*Stmt, error) {
+	return fileStmtDecls(f.File)
+}
+
+// nextVersion returns the next version of the given directory.
+func nextVersion(dir Dir, s VersionScheme) (string, error) {
+	files, err := dir.Files()
+	if err != nil {
+		return "", err
+	}
+	var last string
+	for _, f := range files {
+		// Files that do not conform to the scheme are ignored.
+		if v, _, ok := s.Split(f.Name()); ok && (last == "" || s.Less(last, v)) {
+			last = v
+		}
+	}
+	return s.Next(last)
+}
@@ -0,0 +1,154 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A Violation describes existing rows that violate a constraint added by
+	// a change, and therefore, will fail the change once it is applied. For example:
+	//
+	//	Violation{Table: "pets", Constraint: "owner_id", Kind: "foreign key", Rows: 2, Columns: []string{"owner_id"}, Sample: [][]string{{"1"}, {"3"}}}
+	Violation struct {
+		Table      string     `json:"table"`      // Table of the constraint.
+		Constraint string     `json:"constraint"` // Name of the constraint, if named.
+		Kind       string     `json:"kind"`       // Kind of the constraint, e.g. "foreign key" or "unique".
+		Columns    []string   `json:"columns"`    // Columns of the constraint.
+		Rows       int64      `json:"rows"`       // Number of offending rows.
+		Sample     [][]string `json:"sample"`     // Values of the offending rows, up to the limit.
+	}
+
+	// DataValidator is the interface implemented by drivers that can check, before
+	// applying changes, if the existing rows of the database violate the constraints
+	// (e.g. foreign keys and unique indexes) added by them. The check is read-only.
+	DataValidator interface {
+		// Violations returns the constraint violations of the existing rows, with
+		// up to limit offending rows (or duplicate values) sampled for each of them.
+		Violations(ctx context.Context, changes []schema.Change, limit int) ([]*Violation, error)
+	}
+
+	// ViolationsError is returned by CheckData in case the existing
+	// rows violate the constraints added by the changes.
+	ViolationsError struct {
+		Violations []*Violation
+	}
+)
+
+// String returns the violation in a human-readable format.
+func (v *Violation) String() string {
+	name := v.Kind
+	if v.Constraint != "" {
+		name = fmt.Sprintf("%s %q", v.Kind, v.Constraint)
+	}
+	return fmt.Sprintf("%s of table %q (%s): %d offending rows", name, v.Table, strings.Join(v.Columns, ", "), v.Rows)
+}
+
+// Error implements the error interface.
+func (e *ViolationsError) Error() string {
+	names := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		names[i] = v.String()
+	}
+	return fmt.Sprintf("sql/migrate: existing rows violate %d constraints: %s", len(names), strings.Join(names, ", "))
+}
+
+// CheckData checks if the existing rows of the database violate the constraints added
+// by the given changes, and returns a ViolationsError with a sample of the offending
+// rows if they do. The check executes read-only queries on the database.
+func CheckData(ctx context.Context, drv Driver, changes []schema.Change, limit int) error {
+	c, ok := drv.(DataValidator)
+	if !ok {
+		return fmt.Errorf("sql/migrate: driver %T does not support checking data", drv)
+	}
+	if limit < 1 {
+		return fmt.Errorf("sql/migrate: sample limit must be a positive number, got %d", limit)
+	}
+	vs, err := c.Violations(ctx, changes, limit)
+	if err != nil {
+		return err
+	}
+	if len(vs) > 0 {
+		return &ViolationsError{Violations: vs}
+	}
+	return nil
+}
+
+type (
+	// ConstraintError is returned by the Executor in case a migration statement failed due to
+	// a constraint violation, and the driver was able to extract its details from the database
+	// error. Fields that are not reported by the database are left empty.
+	ConstraintError struct {
+		Kind       string   // Kind of the constraint, e.g. "unique", "foreign key", "check" or "not null".
+		Table      string   // Table of the constraint.
+		Constraint string   // Name of the constraint.
+		Columns    []string // Columns of the violating key.
+		Values     []string // Values of the violating key.
+		File       string   // Name of the migration file.
+		Stmt       int      // Position (1-based) of the statement in the file.
+		Line       int      // Line (1-based) of the statement in the file.
+		Err        error    // Underlying database error.
+	}
+
+	// ConstraintErrorParser is the interface implemented by drivers that can
+	// extract the details of constraint violations from database errors.
+	ConstraintErrorParser interface {
+		// ConstraintError returns the details of the violated constraint,
+		// or false if the error was not caused by a constraint violation.
+		ConstraintError(error) (*ConstraintError, bool)
+	}
+)
+
+// Error implements the error interface.
+func (e *ConstraintError) Error() string {
+	var (
+		b    strings.Builder
+		desc []string
+	)
+	b.WriteString(e.Err.Error())
+	switch {
+	case e.Kind != "" && e.Constraint != "":
+		desc = append(desc, fmt.Sprintf("%s constraint %q", e.Kind, e.Constraint))
+	case e.Kind != "":
+		desc = append(desc, e.Kind+" constraint")
+	case e.Constraint != "":
+		desc = append(desc, fmt.Sprintf("constraint %q", e.Constraint))
+	}
+	if e.Table != "" {
+		desc = append(desc, fmt.Sprintf("table %q", e.Table))
+	}
+	switch cols, vals := strings.Join(e.Columns, ", "), strings.Join(e.Values, ", "); {
+	case cols != "" && vals != "":
+		desc = append(desc, fmt.Sprintf("key (%s)=(%s)", cols, vals))
+	case cols != "":
+		desc = append(desc, fmt.Sprintf("columns (%s)", cols))
+	case vals != "":
+		desc = append(desc, fmt.Sprintf("value (%s)", vals))
+	}
+	if e.Stmt > 0 {
+		pos := fmt.Sprintf("statement %d", e.Stmt)
+		if e.Line > 0 {
+			pos += fmt.Sprintf(" (line %d)", e.Line)
+		}
+		if e.File != "" {
+			pos += fmt.Sprintf(" of %q", e.File)
+		}
+		desc = append(desc, pos)
+	}
+	if len(desc) > 0 {
+		fmt.Fprintf(&b, " [%s]", strings.Join(desc, ", "))
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying database error.
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
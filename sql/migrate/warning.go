@@ -0,0 +1,101 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type (
+	// A Warning is a non-fatal message reported by the database for an executed
+	// statement. For example, MySQL warnings or PostgreSQL notices.
+	Warning struct {
+		Level   string // Severity level, e.g. "warning" or "notice".
+		Code    string // Database specific code, e.g. MySQL error code or PostgreSQL SQLSTATE.
+		Message string // Warning message.
+	}
+
+	// Warner is the interface implemented by drivers that can report the
+	// warnings the database issued for the executed statements.
+	Warner interface {
+		// Warnings returns the warnings reported since the last call.
+		Warnings(context.Context) ([]Warning, error)
+	}
+
+	// WarningError is returned by the Executor in case a statement reported
+	// warnings that match the classes configured by WithFailOnWarnings.
+	WarningError struct {
+		File     string    // Name of the migration file.
+		Stmt     int       // Position (1-based) of the statement in the file.
+		Warnings []Warning // Matched warnings.
+	}
+
+	// LogWarning is sent if the database reported a warning for an executed statement.
+	LogWarning struct {
+		SQL     string
+		Warning Warning
+	}
+)
+
+func (LogWarning) logEntry() {}
+
+// String implements fmt.Stringer.
+func (w Warning) String() string {
+	return fmt.Sprintf("%s %s: %s", w.Level, w.Code, w.Message)
+}
+
+// Match reports if the warning belongs to the given class. A class matches the
+// level of the warning (e.g. "warning"), or a prefix of its code. For example,
+// "01" matches all warnings of the SQLSTATE class 01 in PostgreSQL.
+func (w Warning) Match(class string) bool {
+	return strings.EqualFold(w.Level, class) || class != "" && strings.HasPrefix(w.Code, class)
+}
+
+// Error implements the error interface.
+func (e *WarningError) Error() string {
+	ws := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		ws[i] = w.String()
+	}
+	return fmt.Sprintf("statement %d of %q reported warnings: %s", e.Stmt, e.File, strings.Join(ws, "; "))
+}
+
+// WithFailOnWarnings configures the Executor to fail the execution of a statement in case
+// the database reported warnings that match one of the given classes. See Warning.Match.
+func WithFailOnWarnings(classes ...string) ExecutorOption {
+	return func(ex *Executor) error {
+		ex.failOn = classes
+		return nil
+	}
+}
+
+// warnings logs the warnings reported by the database for the
+// executed statement, and fails in case one of them is matched.
+func (e *Executor) warnings(ctx context.Context, hs *HookStmt, stmt string) error {
+	w, ok := e.drv.(Warner)
+	if !ok {
+		return nil
+	}
+	ws, err := w.Warnings(ctx)
+	if err != nil {
+		return fmt.Errorf("sql/migrate: execute: reading warnings of statement %q: %w", stmt, err)
+	}
+	var matched []Warning
+	for _, w := range ws {
+		e.log.Log(LogWarning{SQL: stmt, Warning: w})
+		for _, c := range e.failOn {
+			if w.Match(c) {
+				matched = append(matched, w)
+				break
+			}
+		}
+	}
+	if len(matched) > 0 {
+		return &WarningError{File: hs.File.Name(), Stmt: hs.Index + 1, Warnings: matched}
+	}
+	return nil
+}
@@ -16,6 +16,26 @@ import (
 	"ariga.io/atlas/sql/schema"
 )
 
+// IdentCase returns the identifier case options that match the given value of
+// the lower_case_table_names system variable. Schema and table names are compared
+// according to its mode, and column and index names are always case-insensitive.
+//
+//	0	names are stored and compared as-is (default on Linux).
+//	1	names are stored in lower case (default on Windows).
+//	2	names are stored as-is, and compared in lower case (default on macOS).
+//
+// See: https://dev.mysql.com/doc/refman/8.0/en/identifier-case-sensitivity.html
+func IdentCase(lowerCaseTableNames int) *schema.IdentCaseOptions {
+	opts := &schema.IdentCaseOptions{Columns: schema.IdentCaseInsensitive}
+	switch lowerCaseTableNames {
+	case 1:
+		opts.Schemas, opts.Tables = schema.IdentCaseLower, schema.IdentCaseLower
+	case 2:
+		opts.Schemas, opts.Tables = schema.IdentCaseInsensitive, schema.IdentCaseInsensitive
+	}
+	return opts
+}
+
 // A diff provides a MySQL implementation for sqlx.DiffDriver.
 type diff struct {
 	conn
@@ -63,6 +83,9 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if change := d.collationChange(from.Attrs, from.Schema.Attrs, to.Attrs); change != noChange {
 		changes = append(changes, change)
 	}
+	if change := partitionChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
 	if !d.SupportsCheck() && sqlx.Has(to.Attrs, &schema.Check{}) {
 		return nil, fmt.Errorf("version %q does not support CHECK constraints", d.V)
 	}
@@ -125,6 +148,9 @@ func (d *diff) ColumnChange(fromT *schema.Table, from, to *schema.Column) (schem
 	if changed {
 		change |= schema.ChangeCollate
 	}
+	if sqlx.Has(from.Attrs, &Invisible{}) != sqlx.Has(to.Attrs, &Invisible{}) {
+		change |= schema.ChangeAttr
+	}
 	return change, nil
 }
 
@@ -158,7 +184,7 @@ func (d *diff) IsGeneratedIndexName(_ *schema.Table, idx *schema.Index) bool {
 
 // IndexAttrChanged reports if the index attributes were changed.
 func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
-	return indexType(from).T != indexType(to).T
+	return indexType(from).T != indexType(to).T || sqlx.Has(from, &Invisible{}) != sqlx.Has(to, &Invisible{})
 }
 
 // IndexPartAttrChanged reports if the index-part attributes (collation or prefix) were changed.
@@ -326,6 +352,57 @@ func (*diff) autoIncChange(from, to []schema.Attr) schema.Change {
 	return noChange
 }
 
+// partitionChange returns the schema change for changing the partitioning of a table.
+func partitionChange(from, to []schema.Attr) schema.Change {
+	fromP, fromHas := partitionOf(from)
+	toP, toHas := partitionOf(to)
+	switch {
+	case !fromHas && !toHas:
+	case !fromHas:
+		return &schema.AddAttr{A: toP}
+	case !toHas:
+		return &schema.DropAttr{A: fromP}
+	case !partitionEqual(fromP, toP):
+		return &schema.ModifyAttr{From: fromP, To: toP}
+	}
+	return noChange
+}
+
+// partitionEqual reports if the two partitioning definitions are equal.
+func partitionEqual(p1, p2 *Partition) bool {
+	if !partitionKeyEqual(p1, p2) || len(p1.Partitions) != len(p2.Partitions) {
+		return false
+	}
+	for i := range p1.Partitions {
+		if p1.Partitions[i].Name != p2.Partitions[i].Name || !partitionExprEqual(p1.Partitions[i].Bound, p2.Partitions[i].Bound) {
+			return false
+		}
+	}
+	return true
+}
+
+// partitionKeyEqual reports if the two partitioning definitions have the same
+// partitioning type and expression, and the same sub-partitioning.
+func partitionKeyEqual(p1, p2 *Partition) bool {
+	switch {
+	case !partitionExprEqual(p1.T, p2.T) || !partitionExprEqual(p1.Expr, p2.Expr):
+		return false
+	case p1.Sub == nil || p2.Sub == nil:
+		return p1.Sub == p2.Sub
+	default:
+		return partitionExprEqual(p1.Sub.T, p2.Sub.T) && partitionExprEqual(p1.Sub.Expr, p2.Sub.Expr) && p1.Sub.N == p2.Sub.N
+	}
+}
+
+// partitionExprEqual reports if the two partitioning clauses are equal,
+// ignoring identifier quotes, whitespaces and case.
+func partitionExprEqual(x1, x2 string) bool {
+	norm := func(x string) string {
+		return strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(x, "`", "")), ""))
+	}
+	return norm(x1) == norm(x2)
+}
+
 // indexType returns the index type from its attribute.
 // The default type is BTREE if no type was specified.
 func indexType(attr []schema.Attr) *IndexType {
@@ -60,6 +60,38 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "add partitioning",
+			from: &schema.Table{Name: "logs", Schema: &schema.Schema{Name: "public"}},
+			to:   &schema.Table{Name: "logs", Attrs: []schema.Attr{&Partition{T: PartitionTypeKey}}},
+			wantChanges: []schema.Change{
+				&schema.AddAttr{A: &Partition{T: PartitionTypeKey}},
+			},
+		},
+		{
+			name: "drop partitioning",
+			from: &schema.Table{Name: "logs", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Partition{T: PartitionTypeKey}}},
+			to:   &schema.Table{Name: "logs"},
+			wantChanges: []schema.Change{
+				&schema.DropAttr{A: &Partition{T: PartitionTypeKey}},
+			},
+		},
+		{
+			name: "no partitioning changes",
+			from: &schema.Table{Name: "logs", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Partition{T: "range", Expr: "`id`", Partitions: []*TablePartition{{Name: "p0", Bound: "VALUES LESS THAN (10)"}}}}},
+			to:   &schema.Table{Name: "logs", Attrs: []schema.Attr{&Partition{T: PartitionTypeRange, Expr: "id", Partitions: []*TablePartition{{Name: "p0", Bound: "values less than ( 10 )"}}}}},
+		},
+		{
+			name: "modify partitioning",
+			from: &schema.Table{Name: "logs", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Partition{T: PartitionTypeRange, Expr: "`id`", Partitions: []*TablePartition{{Name: "p0", Bound: "VALUES LESS THAN (10)"}}}}},
+			to:   &schema.Table{Name: "logs", Attrs: []schema.Attr{&Partition{T: PartitionTypeRange, Expr: "`id`", Partitions: []*TablePartition{{Name: "p0", Bound: "VALUES LESS THAN (20)"}}}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &Partition{T: PartitionTypeRange, Expr: "`id`", Partitions: []*TablePartition{{Name: "p0", Bound: "VALUES LESS THAN (10)"}}},
+					To:   &Partition{T: PartitionTypeRange, Expr: "`id`", Partitions: []*TablePartition{{Name: "p0", Bound: "VALUES LESS THAN (20)"}}},
+				},
+			},
+		},
 		{
 			name: "add collation",
 			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&schema.Charset{V: "latin1"}}},
@@ -623,3 +655,44 @@ func TestDiff_RealmDiff(t *testing.T) {
 		&schema.AddTable{T: to.Schemas[1].Tables[0]},
 	}, changes)
 }
+
+func TestDiff_RealmSettings(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.19")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	from := &schema.Realm{
+		Attrs: []schema.Attr{
+			&schema.Setting{Name: "sql_mode", V: "STRICT_TRANS_TABLES"},
+			&schema.Setting{Name: "time_zone", V: "SYSTEM"},
+		},
+	}
+	to := &schema.Realm{
+		Attrs: []schema.Attr{
+			// Settings are matched case-insensitively.
+			&schema.Setting{Name: "SQL_MODE", V: "STRICT_TRANS_TABLES"},
+			&schema.Setting{Name: "time_zone", V: "+00:00"},
+			&schema.Setting{Name: "max_connections", V: "500"},
+		},
+	}
+	changes, err := drv.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.ModifyRealm{R: to, Changes: []schema.Change{
+			&schema.ModifyAttr{From: from.Attrs[1], To: to.Attrs[1]},
+			&schema.AddAttr{A: to.Attrs[2]},
+		}},
+	}, changes)
+
+	// Settings that are not declared in the desired state are ignored.
+	changes, err = drv.RealmDiff(from, &schema.Realm{})
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestIdentCase(t *testing.T) {
+	require.Equal(t, &schema.IdentCaseOptions{Columns: schema.IdentCaseInsensitive}, IdentCase(0))
+	require.Equal(t, &schema.IdentCaseOptions{Schemas: schema.IdentCaseLower, Tables: schema.IdentCaseLower, Columns: schema.IdentCaseInsensitive}, IdentCase(1))
+	require.Equal(t, &schema.IdentCaseOptions{Schemas: schema.IdentCaseInsensitive, Tables: schema.IdentCaseInsensitive, Columns: schema.IdentCaseInsensitive}, IdentCase(2))
+}
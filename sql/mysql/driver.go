@@ -8,6 +8,8 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,6 +40,24 @@ type (
 	}
 )
 
+var (
+	// noConn holds the connection information used for
+	// planning changes without a database connection.
+	noConn = conn{ExecQuerier: sqlx.NoRows, V: "8.0.32"}
+
+	// DefaultDiff provides basic diffing capabilities for MySQL dialects.
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// Differ when a database connection is available.
+	DefaultDiff schema.Differ = &sqlx.Diff{DiffDriver: &diff{conn: noConn}}
+
+	// DefaultPlan provides basic planning capabilities for MySQL dialects, for example,
+	// for compiling a schema to SQL statements without a database connection. The changes
+	// are planned for MySQL 8, unless a target version is given (see migrate.PlanOptions).
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// migrate.PlanApplier when a database connection is available.
+	DefaultPlan migrate.PlanApplier = &planApply{noConn}
+)
+
 // DriverName holds the name used for registration.
 const DriverName = "mysql"
 
@@ -91,6 +111,91 @@ func (d *Driver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*schema
 	return d.dev().NormalizeSchema(ctx, s)
 }
 
+// IdentCase returns the identifier case options that match the
+// lower_case_table_names system variable of the connected server.
+func (d *Driver) IdentCase(ctx context.Context) (*schema.IdentCaseOptions, error) {
+	rows, err := d.QueryContext(ctx, "SELECT @@lower_case_table_names")
+	if err != nil {
+		return nil, fmt.Errorf("mysql: query lower_case_table_names: %w", err)
+	}
+	var n int
+	if err := sqlx.ScanOne(rows, &n); err != nil {
+		return nil, fmt.Errorf("mysql: scan lower_case_table_names: %w", err)
+	}
+	return IdentCase(n), nil
+}
+
+// Fingerprints returns a fingerprint for each table in the given schema that changes when
+// the table, or one of its child objects, is changed. It is computed from the checksums of
+// the INFORMATION_SCHEMA rows that describe the table, and therefore, it is cheap to compute
+// and does not require inspecting the tables.
+func (d *Driver) Fingerprints(ctx context.Context, name string) (map[string]string, error) {
+	var gen, checks string
+	if d.SupportsGeneratedColumns() {
+		gen = ", c.GENERATION_EXPRESSION"
+	}
+	if d.SupportsCheck() {
+		enforced := "tc.ENFORCED"
+		if d.Maria() {
+			enforced = "'YES'"
+		}
+		checks = fmt.Sprintf(fingerprintChecks, enforced)
+	}
+	rows, err := d.QueryContext(ctx, fmt.Sprintf(fingerprintsQuery, gen, checks), name)
+	if err != nil {
+		return nil, fmt.Errorf("mysql: querying schema %q fingerprints: %w", name, err)
+	}
+	defer rows.Close()
+	prints := make(map[string]string)
+	for rows.Next() {
+		var t, f string
+		if err := rows.Scan(&t, &f); err != nil {
+			return nil, fmt.Errorf("mysql: scanning schema %q fingerprints: %w", name, err)
+		}
+		prints[t] = f
+	}
+	return prints, rows.Err()
+}
+
+// Capabilities implements the migrate.CapabilitiesReporter interface.
+func (d *Driver) Capabilities(version string) (*migrate.Capabilities, error) {
+	c := d.conn
+	if version != "" {
+		if c.V = mysqlversion.V(version); !c.V.Valid() {
+			return nil, fmt.Errorf("mysql: malformed version: %s", version)
+		}
+	}
+	return c.capabilities(), nil
+}
+
+// capabilities returns the capabilities of the connection version.
+func (c *conn) capabilities() *migrate.Capabilities {
+	name := DriverName
+	switch {
+	case c.Maria():
+		name = "mariadb"
+	case c.TiDB():
+		name = "tidb"
+	}
+	return &migrate.Capabilities{
+		Driver:  name,
+		Version: string(c.V),
+		Supports: map[migrate.Capability]bool{
+			// All DDL statements cause an implicit commit.
+			migrate.CapTransactionalDDL: false,
+			migrate.CapConcurrentIndex:  false,
+			migrate.CapInstantAddColumn: c.SupportsInstantAddColumn(),
+			migrate.CapRenameColumn:     c.SupportsRenameColumn(),
+			migrate.CapCheckConstraint:  c.SupportsCheck(),
+			migrate.CapIndexExpr:        c.SupportsIndexExpr(),
+			migrate.CapGeneratedColumn:  c.SupportsGeneratedColumns(),
+			migrate.CapInvisibleIndex:   c.SupportsInvisibleIndex(),
+			migrate.CapIndexInclude:     false,
+			migrate.CapNullsNotDistinct: false,
+		},
+	}
+}
+
 // Lock implements the schema.Locker interface.
 func (d *Driver) Lock(ctx context.Context, name string, timeout time.Duration) (schema.UnlockFunc, error) {
 	conn, err := sqlx.SingleConn(ctx, d.ExecQuerier)
@@ -162,6 +267,120 @@ func (d *Driver) Snapshot(ctx context.Context) (migrate.RestoreFunc, error) {
 	}, nil
 }
 
+// Violations implements migrate.DataValidator.
+func (d *Driver) Violations(ctx context.Context, changes []schema.Change, limit int) ([]*migrate.Violation, error) {
+	checks := sqlx.ViolationChecks(changes, '`', limit)
+	vs, err := sqlx.CheckViolations(ctx, d, checks)
+	if err != nil {
+		return nil, fmt.Errorf("mysql: %w", err)
+	}
+	return vs, nil
+}
+
+var (
+	reDupEntry  = regexp.MustCompile(`Duplicate entry '(.*)' for key '(.+)'`)
+	reFKFails   = regexp.MustCompile("a foreign key constraint fails \\((.+?), CONSTRAINT `([^`]+)` FOREIGN KEY \\((.+?)\\) REFERENCES")
+	reCheckFail = regexp.MustCompile(`Check constraint '(.+)' is violated`)
+	reMariaFail = regexp.MustCompile("CONSTRAINT `([^`]+)` failed for (.+)")
+	reNotNull   = regexp.MustCompile(`Column '(.+)' cannot be null`)
+)
+
+// ConstraintError implements migrate.ConstraintErrorParser.
+func (*Driver) ConstraintError(err error) (*migrate.ConstraintError, bool) {
+	msg := err.Error()
+	switch {
+	case reDupEntry.MatchString(msg):
+		m := reDupEntry.FindStringSubmatch(msg)
+		cerr := &migrate.ConstraintError{Kind: "unique", Constraint: m[2], Values: []string{m[1]}}
+		// Since MySQL 8.0.19, the key name is qualified with its table name.
+		if i := strings.LastIndexByte(m[2], '.'); i > 0 {
+			cerr.Table, cerr.Constraint = m[2][:i], m[2][i+1:]
+		}
+		return cerr, true
+	case reFKFails.MatchString(msg):
+		m := reFKFails.FindStringSubmatch(msg)
+		return &migrate.ConstraintError{Kind: "foreign key", Table: unquoteName(m[1]), Constraint: m[2], Columns: unquoteNames(m[3])}, true
+	case reCheckFail.MatchString(msg):
+		return &migrate.ConstraintError{Kind: "check", Constraint: reCheckFail.FindStringSubmatch(msg)[1]}, true
+	case reMariaFail.MatchString(msg):
+		m := reMariaFail.FindStringSubmatch(msg)
+		return &migrate.ConstraintError{Kind: "check", Table: unquoteName(m[2]), Constraint: m[1]}, true
+	case reNotNull.MatchString(msg):
+		return &migrate.ConstraintError{Kind: "not null", Columns: []string{reNotNull.FindStringSubmatch(msg)[1]}}, true
+	}
+	return nil, false
+}
+
+// reNearLine matches the position of syntax errors, e.g. "... near 'FROM t' at line 2".
+var reNearLine = regexp.MustCompile(`(?s)near '(.*)' at line (\d+)$`)
+
+// ErrorPos implements migrate.ErrorPositioner. MySQL reports the line of the error within the
+// statement, and the statement text that follows it (possibly truncated), which is used to
+// locate the error within the line.
+func (*Driver) ErrorPos(stmt string, err error) (int, bool) {
+	m := reNearLine.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, false
+	}
+	line, err := strconv.Atoi(m[2])
+	if err != nil || line < 1 {
+		return 0, false
+	}
+	var off int
+	for ; line > 1; line-- {
+		i := strings.IndexByte(stmt[off:], '\n')
+		if i == -1 {
+			return 0, false
+		}
+		off += i + 1
+	}
+	switch near := m[1]; {
+	// An empty text means the error is at the end of the statement.
+	case near == "":
+		return len(strings.TrimRight(stmt, " \t\n;")), true
+	case strings.Contains(stmt[off:], near):
+		return off + strings.Index(stmt[off:], near), true
+	default:
+		return off, true
+	}
+}
+
+// Warnings implements migrate.Warner. MySQL keeps the warnings of the last executed
+// statement in the session, and reports them using the SHOW WARNINGS statement. Hence,
+// the driver should be bound to a single connection (e.g. a transaction or sql.Conn).
+func (d *Driver) Warnings(ctx context.Context) ([]migrate.Warning, error) {
+	rows, err := d.QueryContext(ctx, "SHOW WARNINGS")
+	if err != nil {
+		return nil, fmt.Errorf("mysql: querying warnings: %w", err)
+	}
+	defer rows.Close()
+	var ws []migrate.Warning
+	for rows.Next() {
+		var w migrate.Warning
+		if err := rows.Scan(&w.Level, &w.Code, &w.Message); err != nil {
+			return nil, fmt.Errorf("mysql: scanning warnings: %w", err)
+		}
+		w.Level = strings.ToLower(w.Level)
+		ws = append(ws, w)
+	}
+	return ws, rows.Err()
+}
+
+// unquoteName returns the unqualified name of the given (optionally qualified) identifier.
+func unquoteName(s string) string {
+	parts := strings.Split(s, ".")
+	return strings.Trim(parts[len(parts)-1], "`")
+}
+
+// unquoteNames returns the names of the given comma-separated identifiers.
+func unquoteNames(s string) []string {
+	names := strings.Split(s, ",")
+	for i := range names {
+		names[i] = strings.Trim(strings.TrimSpace(names[i]), "`")
+	}
+	return names
+}
+
 // CheckClean implements migrate.CleanChecker.
 func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error {
 	s, err := d.InspectSchema(ctx, "", nil)
@@ -336,6 +555,11 @@ const (
 	IndexTypeFullText = "FULLTEXT"
 	IndexTypeSpatial  = "SPATIAL"
 
+	PartitionTypeRange = "RANGE"
+	PartitionTypeList  = "LIST"
+	PartitionTypeHash  = "HASH"
+	PartitionTypeKey   = "KEY"
+
 	currentTS     = "current_timestamp"
 	defaultGen    = "default_generated"
 	autoIncrement = "auto_increment"
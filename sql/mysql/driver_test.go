@@ -7,12 +7,15 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/url"
 	"testing"
 	"time"
 
 	"ariga.io/atlas/sql/internal/sqltest"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql/internal/mysqlversion"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -197,3 +200,186 @@ func (m *mockOpener) Conn(ctx context.Context) (*sql.Conn, error) {
 	m.opened++
 	return m.DB.Conn(ctx)
 }
+
+func TestDriver_Capabilities(t *testing.T) {
+	drv := &Driver{conn: conn{V: "5.7.40"}}
+	caps, err := drv.Capabilities("")
+	require.NoError(t, err)
+	require.Equal(t, "mysql", caps.Driver)
+	require.Equal(t, "5.7.40", caps.Version)
+	require.False(t, caps.Is(migrate.CapTransactionalDDL))
+	require.False(t, caps.Is(migrate.CapInstantAddColumn))
+	require.False(t, caps.Is(migrate.CapIndexExpr))
+	require.True(t, caps.Is(migrate.CapGeneratedColumn))
+
+	caps, err = drv.Capabilities("8.0.31")
+	require.NoError(t, err)
+	require.True(t, caps.Is(migrate.CapInstantAddColumn))
+	require.True(t, caps.Is(migrate.CapIndexExpr))
+	require.True(t, caps.Is(migrate.CapInvisibleIndex))
+
+	caps, err = drv.Capabilities("10.6.11-MariaDB")
+	require.NoError(t, err)
+	require.Equal(t, "mariadb", caps.Driver)
+	require.True(t, caps.Is(migrate.CapInstantAddColumn))
+	require.False(t, caps.Is(migrate.CapInvisibleIndex))
+
+	_, err = drv.Capabilities("latest")
+	require.EqualError(t, err, "mysql: malformed version: latest")
+}
+
+func TestDriver_Fingerprints(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	for v, args := range map[string][]any{
+		"5.6.35":          {"", ""},
+		"8.0.31":          {", c.GENERATION_EXPRESSION", fmt.Sprintf(fingerprintChecks, "tc.ENFORCED")},
+		"10.6.11-MariaDB": {", c.GENERATION_EXPRESSION", fmt.Sprintf(fingerprintChecks, "'YES'")},
+	} {
+		drv := &Driver{conn: conn{ExecQuerier: db, V: mysqlversion.V(v)}}
+		m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fingerprintsQuery, args...))).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "fingerprint"}).AddRow("users", "a1"))
+		prints, err := drv.Fingerprints(context.Background(), "app")
+		require.NoError(t, err, v)
+		require.Equal(t, map[string]string{"users": "a1"}, prints, v)
+	}
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_IdentCase(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	drv := &Driver{conn: conn{ExecQuerier: db}}
+	m.ExpectQuery(sqltest.Escape("SELECT @@lower_case_table_names")).
+		WillReturnRows(sqlmock.NewRows([]string{"@@lower_case_table_names"}).AddRow(1))
+	opts, err := drv.IdentCase(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, IdentCase(1), opts)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_MissingPrivileges(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	drv := &Driver{conn: conn{ExecQuerier: db}}
+	var (
+		app      = schema.New("app")
+		accounts = schema.NewTable("accounts").SetSchema(app)
+		users    = schema.NewTable("users").SetSchema(app)
+		posts    = schema.NewTable("posts").SetSchema(app)
+	)
+	users.AddForeignKeys(schema.NewForeignKey("account").SetRefTable(accounts))
+	changes := []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.DropTable{T: posts},
+		&schema.ModifyTable{T: accounts, Changes: []schema.Change{&schema.AddIndex{I: schema.NewIndex("name")}}},
+		&schema.RenameTable{From: posts, To: schema.NewTable("articles").SetSchema(app)},
+	}
+	m.ExpectQuery(sqltest.Escape(currentUserQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"user", "db"}).AddRow("'deployer'@'%'", "app"))
+	m.ExpectQuery(sqltest.Escape(grantsQuery)).
+		WithArgs("'deployer'@'%'", "'deployer'@'%'", "'deployer'@'%'").
+		WillReturnRows(sqlmock.NewRows([]string{"scope", "schema", "table", "privilege"}).
+			AddRow("global", "", "", "SELECT").
+			AddRow("schema", "ap_", "", "CREATE").
+			AddRow("schema", "other", "", "DROP").
+			AddRow("table", "app", "posts", "DROP").
+			AddRow("table", "app", "accounts", "INDEX"))
+	missing, err := drv.MissingPrivileges(context.Background(), changes)
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []*migrate.Privilege{
+		{Name: "REFERENCES", Object: `table "app"."accounts"`, Reason: `create table "users"`},
+		{Name: "ALTER", Object: `table "app"."posts"`, Reason: `rename table "posts" to "articles"`},
+		{Name: "INSERT", Object: `table "app"."articles"`, Reason: `rename table "posts" to "articles"`},
+	}, missing)
+	require.True(t, likeMatch("app", "app"))
+	require.True(t, likeMatch("app\\_%", "app_1"))
+	require.False(t, likeMatch("app\\_%", "app1"))
+	require.True(t, likeMatch("a_p", "app"))
+
+	// No changes, no queries.
+	missing, err = drv.MissingPrivileges(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, missing)
+}
+
+func TestDriver_ConstraintError(t *testing.T) {
+	for _, tt := range []struct {
+		err  string
+		want *migrate.ConstraintError
+	}{
+		{
+			err:  "Error 1062 (23000): Duplicate entry '1-a' for key 'users.name'",
+			want: &migrate.ConstraintError{Kind: "unique", Table: "users", Constraint: "name", Values: []string{"1-a"}},
+		},
+		{
+			err:  "Error 1062: Duplicate entry 'a' for key 'PRIMARY'",
+			want: &migrate.ConstraintError{Kind: "unique", Constraint: "PRIMARY", Values: []string{"a"}},
+		},
+		{
+			err:  "Error 1452 (23000): Cannot add or update a child row: a foreign key constraint fails (`test`.`pets`, CONSTRAINT `owner_id` FOREIGN KEY (`owner_id`, `kind`) REFERENCES `users` (`id`, `kind`))",
+			want: &migrate.ConstraintError{Kind: "foreign key", Table: "pets", Constraint: "owner_id", Columns: []string{"owner_id", "kind"}},
+		},
+		{
+			err:  "Error 3819 (HY000): Check constraint 'positive_age' is violated.",
+			want: &migrate.ConstraintError{Kind: "check", Constraint: "positive_age"},
+		},
+		{
+			err:  "Error 4025 (23000): CONSTRAINT `positive_age` failed for `test`.`users`",
+			want: &migrate.ConstraintError{Kind: "check", Table: "users", Constraint: "positive_age"},
+		},
+		{
+			err:  "Error 1048 (23000): Column 'name' cannot be null",
+			want: &migrate.ConstraintError{Kind: "not null", Columns: []string{"name"}},
+		},
+		{
+			err: "Error 1146 (42S02): Table 'test.users' doesn't exist",
+		},
+	} {
+		got, ok := (&Driver{}).ConstraintError(errors.New(tt.err))
+		require.Equal(t, tt.want != nil, ok, tt.err)
+		require.Equal(t, tt.want, got, tt.err)
+	}
+}
+
+func TestDriver_ErrorPos(t *testing.T) {
+	stmt := "SELECT *\nFORM t\nWHERE c = 1;"
+	for _, tt := range []struct {
+		err  string
+		pos  int
+		want bool
+	}{
+		{err: "Error 1064 (42000): You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near 't\nWHERE c = 1' at line 2", pos: 14, want: true},
+		{err: "Error 1064 (42000): You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near 'unknown' at line 3", pos: 16, want: true},
+		{err: "Error 1064 (42000): You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near '' at line 3", pos: 27, want: true},
+		{err: "Error 1064 (42000): You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near 'x' at line 4"},
+		{err: "Error 1146 (42S02): Table 'test.t' doesn't exist"},
+	} {
+		pos, ok := (&Driver{}).ErrorPos(stmt, errors.New(tt.err))
+		require.Equal(t, tt.want, ok, tt.err)
+		require.Equal(t, tt.pos, pos, tt.err)
+	}
+}
+
+func TestDriver_Warnings(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape("SHOW WARNINGS")).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"Level", "Code", "Message"}).
+				AddRow("Warning", 1265, "Data truncated for column 'c' at row 1").
+				AddRow("Note", 1050, "Table 't' already exists"),
+		).
+		RowsWillBeClosed()
+	d := &Driver{}
+	d.ExecQuerier = db
+	ws, err := d.Warnings(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []migrate.Warning{
+		{Level: "warning", Code: "1265", Message: "Data truncated for column 'c' at row 1"},
+		{Level: "note", Code: "1050", Message: "Table 't' already exists"},
+	}, ws)
+	require.NoError(t, m.ExpectationsWereMet())
+}
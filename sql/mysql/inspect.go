@@ -31,16 +31,148 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 		opts = &schema.InspectRealmOption{}
 	}
 	r := schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate)
+	if sqlx.ModeInspectRealm(opts).Is(schema.InspectSettings) {
+		if err := i.inspectSettings(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	var triggers []*schema.Trigger
+	if mode := sqlx.ModeInspectRealm(opts); len(schemas) > 0 && mode.Is(schema.InspectObjects|schema.InspectViews|schema.InspectTriggers) {
+		if triggers, err = i.unmanaged(ctx, r, mode); err != nil {
+			return nil, err
+		}
+	}
+	if len(schemas) > 0 && sqlx.ModeInspectRealm(opts).Is(schema.InspectFuncs) {
+		if err := i.funcs(ctx, r); err != nil {
+			return nil, err
+		}
+	}
 	if len(schemas) == 0 || !sqlx.ModeInspectRealm(opts).Is(schema.InspectTables) {
 		return r, nil
 	}
-	if err := i.inspectTables(ctx, r, nil); err != nil {
+	if err := i.inspectTables(ctx, r, nil, opts.Workers); err != nil {
 		return nil, err
 	}
 	sqlx.LinkSchemaTables(schemas)
+	sqlx.LinkTriggers(triggers)
 	return sqlx.ExcludeRealm(r, opts.Exclude)
 }
 
+// inspectSettings adds the global settings of the server to the realm.
+func (i *inspect) inspectSettings(ctx context.Context, r *schema.Realm) error {
+	rows, err := i.QueryContext(ctx, settingsQuery)
+	if err != nil {
+		return fmt.Errorf("mysql: querying settings: %w", err)
+	}
+	var mode, tz string
+	if err := sqlx.ScanOne(rows, &mode, &tz); err != nil {
+		return fmt.Errorf("mysql: scanning settings: %w", err)
+	}
+	r.Attrs = append(r.Attrs, &schema.Setting{Name: "sql_mode", V: mode}, &schema.Setting{Name: "time_zone", V: tz})
+	return nil
+}
+
+// unmanaged inspects the views and triggers of the realm schemas. Views are
+// added to their schemas, and triggers are returned to be linked to their tables,
+// if they were requested by the inspection mode. Otherwise, triggers are kept as
+// raw statements.
+func (i *inspect) unmanaged(ctx context.Context, r *schema.Realm, mode schema.InspectMode) ([]*schema.Trigger, error) {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	// Schema names are passed twice, once for each of the unioned queries.
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(unmanagedQuery, nArgs(len(r.Schemas))), append(args, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("mysql: querying unmanaged objects: %w", err)
+	}
+	defer rows.Close()
+	var triggers []*schema.Trigger
+	for rows.Next() {
+		var (
+			ns, typ, name, def string
+			timing, event, tbl sql.NullString
+		)
+		if err := rows.Scan(&ns, &typ, &name, &def, &timing, &event, &tbl); err != nil {
+			return nil, fmt.Errorf("mysql: scanning unmanaged object: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return nil, fmt.Errorf("mysql: schema %q was not found in realm", ns)
+		}
+		ident := (&sqlx.Builder{QuoteChar: '`'}).Table(&schema.Table{Name: name, Schema: s}).String()
+		var create string
+		switch typ {
+		case "VIEW":
+			if mode.Is(schema.InspectViews) {
+				s.AddViews(schema.NewView(name, def))
+			}
+			continue
+		case "TRIGGER":
+			if mode.Is(schema.InspectTriggers) {
+				tr := schema.NewTrigger(name, timing.String, []string{event.String}, def)
+				tr.Table = &schema.Table{Name: tbl.String, Schema: s}
+				triggers = append(triggers, tr)
+				continue
+			}
+			if !mode.Is(schema.InspectObjects) {
+				continue
+			}
+			on := (&sqlx.Builder{QuoteChar: '`'}).Table(&schema.Table{Name: tbl.String, Schema: s}).String()
+			create = fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s FOR EACH ROW %s", ident, timing.String, event.String, on, def)
+		default:
+			return nil, fmt.Errorf("mysql: unexpected unmanaged object type %q", typ)
+		}
+		s.AddAttrs(schema.NewUnmanaged(typ, name, create).SetDrop(fmt.Sprintf("DROP %s %s", typ, ident)))
+	}
+	return triggers, rows.Err()
+}
+
+// funcs inspects the functions and procedures of the realm schemas. Characteristics
+// of routines that are not the default (e.g. DETERMINISTIC) are kept as part of their
+// bodies, as they are written before the body in the CREATE statement.
+func (i *inspect) funcs(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(funcsQuery, nArgs(len(r.Schemas))), args...)
+	if err != nil {
+		return fmt.Errorf("mysql: querying functions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, typ, name, def, deterministic, access, security string
+			ret, fargs                                          sql.NullString
+		)
+		if err := rows.Scan(&ns, &typ, &name, &ret, &fargs, &def, &deterministic, &access, &security); err != nil {
+			return fmt.Errorf("mysql: scanning functions: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("mysql: schema %q was not found in realm", ns)
+		}
+		var body []string
+		if deterministic == "YES" {
+			body = append(body, "DETERMINISTIC")
+		}
+		if access != "CONTAINS SQL" {
+			body = append(body, access)
+		}
+		if security != "DEFINER" {
+			body = append(body, "SQL SECURITY "+security)
+		}
+		body = append(body, def)
+		if typ == "PROCEDURE" {
+			s.AddProcs(schema.NewProc(name, fargs.String, strings.Join(body, " ")))
+		} else {
+			s.AddFuncs(schema.NewFunc(name, fargs.String, ret.String, strings.Join(body, " ")))
+		}
+	}
+	return rows.Err()
+}
+
 // InspectSchema returns schema descriptions of the tables in the given schema.
 // If the schema name is empty, the result will be the attached schema.
 func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
@@ -58,23 +190,34 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 		opts = &schema.InspectOptions{}
 	}
 	r := schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate)
+	var triggers []*schema.Trigger
+	if mode := sqlx.ModeInspectSchema(opts); mode.Is(schema.InspectObjects | schema.InspectViews | schema.InspectTriggers) {
+		if triggers, err = i.unmanaged(ctx, r, mode); err != nil {
+			return nil, err
+		}
+	}
+	if sqlx.ModeInspectSchema(opts).Is(schema.InspectFuncs) {
+		if err := i.funcs(ctx, r); err != nil {
+			return nil, err
+		}
+	}
 	if sqlx.ModeInspectSchema(opts).Is(schema.InspectTables) {
-		if err := i.inspectTables(ctx, r, opts); err != nil {
+		if err := i.inspectTables(ctx, r, opts, 1); err != nil {
 			return nil, err
 		}
 		sqlx.LinkSchemaTables(schemas)
+		sqlx.LinkTriggers(triggers)
 	}
 	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
 }
 
-func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
+// inspectTables inspects the tables of the realm schemas. Schemas are
+// inspected concurrently if workers is greater than 1.
+func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions, workers int) error {
 	if err := i.tables(ctx, r, opts); err != nil {
 		return err
 	}
-	for _, s := range r.Schemas {
-		if len(s.Tables) == 0 {
-			continue
-		}
+	return sqlx.InspectSchemas(ctx, i.ExecQuerier, workers, r.Schemas, func(ctx context.Context, s *schema.Schema) error {
 		if err := i.columns(ctx, s); err != nil {
 			return err
 		}
@@ -87,11 +230,11 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 		if err := i.checks(ctx, s); err != nil {
 			return err
 		}
-		if err := i.showCreate(ctx, s); err != nil {
+		if err := i.partitions(ctx, s); err != nil {
 			return err
 		}
-	}
-	return nil
+		return i.showCreate(ctx, s)
+	})
 }
 
 // schemas returns the list of the schemas in the database.
@@ -192,9 +335,17 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 			})
 		}
 		if sqlx.ValidString(options) {
-			t.Attrs = append(t.Attrs, &CreateOptions{
-				V: options.String,
-			})
+			// Partitioned tables are marked in the create options, and
+			// their partitioning is loaded by the partitions method.
+			if opts, ok := cutPartitioned(options.String); ok {
+				t.Attrs = append(t.Attrs, &Partition{})
+				options.String = opts
+			}
+			if options.String != "" {
+				t.Attrs = append(t.Attrs, &CreateOptions{
+					V: options.String,
+				})
+			}
 		}
 		if autoinc.Valid {
 			t.Attrs = append(t.Attrs, &AutoIncrement{
@@ -263,6 +414,9 @@ func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) error {
 	if attr.onUpdate != "" {
 		c.Attrs = append(c.Attrs, &OnUpdate{A: attr.onUpdate})
 	}
+	if attr.invisible {
+		c.Attrs = append(c.Attrs, &Invisible{})
+	}
 	if x := expr.String; x != "" {
 		if !i.Maria() {
 			x = unescape(x)
@@ -319,12 +473,12 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 	hasPK := make(map[*schema.Table]bool)
 	for rows.Next() {
 		var (
-			seqno                          int
-			table, name, indexType         string
-			nonuniq, desc                  sql.NullBool
-			column, subPart, expr, comment sql.NullString
+			seqno                                   int
+			table, name, indexType                  string
+			nonuniq, desc                           sql.NullBool
+			column, subPart, expr, comment, visible sql.NullString
 		)
-		if err := rows.Scan(&table, &name, &column, &nonuniq, &seqno, &indexType, &desc, &comment, &subPart, &expr); err != nil {
+		if err := rows.Scan(&table, &name, &column, &nonuniq, &seqno, &indexType, &desc, &comment, &subPart, &expr, &visible); err != nil {
 			return fmt.Errorf("mysql: scanning indexes for schema %q: %w", s.Name, err)
 		}
 		t, ok := s.Table(table)
@@ -351,6 +505,9 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 					Text: comment.String,
 				})
 			}
+			if visible.String == "NO" {
+				idx.Attrs = append(idx.Attrs, &Invisible{})
+			}
 			t.Indexes = append(t.Indexes, idx)
 		}
 		// Rows are ordered by SEQ_IN_INDEX that specifies the
@@ -400,6 +557,98 @@ func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
 	return rows.Err()
 }
 
+// cutPartitioned removes the "partitioned" option from the given table
+// create options, and reports if the option was found.
+func cutPartitioned(opts string) (string, bool) {
+	var (
+		found bool
+		keep  []string
+	)
+	for _, o := range strings.Fields(opts) {
+		if strings.EqualFold(o, "partitioned") {
+			found = true
+		} else {
+			keep = append(keep, o)
+		}
+	}
+	return strings.Join(keep, " "), found
+}
+
+// partitions queries and sets the partitioning of the partitioned tables in the schema.
+func (i *inspect) partitions(ctx context.Context, s *schema.Schema) error {
+	var (
+		args  = []any{s.Name}
+		names = make(map[string]*Partition)
+	)
+	for _, t := range s.Tables {
+		if p, ok := partitionOf(t.Attrs); ok {
+			names[t.Name] = p
+			args = append(args, t.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(partitionsQuery, nArgs(len(names))), args...)
+	if err != nil {
+		return fmt.Errorf("mysql: query schema %q partitions: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			table, name, method                  string
+			sub, subMethod, expr, subExpr, bound sql.NullString
+		)
+		if err := rows.Scan(&table, &name, &sub, &method, &subMethod, &expr, &subExpr, &bound); err != nil {
+			return fmt.Errorf("mysql: scan partition information: %w", err)
+		}
+		p, ok := names[table]
+		if !ok {
+			return fmt.Errorf("mysql: partitioned table %q was not found in schema", table)
+		}
+		p.T, p.Expr = method, expr.String
+		if sqlx.ValidString(subMethod) && p.Sub == nil {
+			p.Sub = &SubPartition{T: subMethod.String, Expr: subExpr.String}
+		}
+		// Rows of sub-partitions repeat their partition, and the
+		// number of sub-partitions is counted on the first one.
+		if n := len(p.Partitions); n == 0 || p.Partitions[n-1].Name != name {
+			p.Partitions = append(p.Partitions, &TablePartition{Name: name, Bound: partitionBound(method, bound.String)})
+		}
+		if sqlx.ValidString(sub) && len(p.Partitions) == 1 {
+			p.Sub.N++
+		}
+	}
+	return rows.Close()
+}
+
+// partitionOf returns the partitioning attribute of the table, if exists.
+func partitionOf(attrs []schema.Attr) (*Partition, bool) {
+	for _, a := range attrs {
+		if p, ok := a.(*Partition); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// partitionBound returns the VALUES clause of a partition from its description
+// in the INFORMATION_SCHEMA. HASH and KEY partitions have no description.
+func partitionBound(method, desc string) string {
+	switch t := strings.ToUpper(method); {
+	case desc == "":
+		return ""
+	case t == PartitionTypeRange && desc == "MAXVALUE":
+		return "VALUES LESS THAN MAXVALUE"
+	case strings.HasPrefix(t, PartitionTypeRange):
+		return fmt.Sprintf("VALUES LESS THAN (%s)", desc)
+	case strings.HasPrefix(t, PartitionTypeList):
+		return fmt.Sprintf("VALUES IN (%s)", desc)
+	default:
+		return ""
+	}
+}
+
 // checks queries and appends the check constraints of the given table.
 func (i *inspect) checks(ctx context.Context, s *schema.Schema) error {
 	query, ok := i.supportsCheck()
@@ -464,6 +713,9 @@ func (i *inspect) indexQuery() string {
 	if i.SupportsIndexComment() {
 		query = indexesQuery
 	}
+	if i.SupportsInvisibleIndex() {
+		query = indexesVisibleQuery
+	}
 	if i.SupportsIndexExpr() {
 		query = indexesExprQuery
 	}
@@ -472,6 +724,7 @@ func (i *inspect) indexQuery() string {
 
 // extraAttr is a parsed version of the information_schema EXTRA column.
 type extraAttr struct {
+	invisible        bool
 	autoinc          bool
 	onUpdate         string
 	generatedType    string
@@ -487,6 +740,12 @@ var (
 // from the INFORMATION_SCHEMA.COLUMNS table.
 func parseExtra(extra string) (*extraAttr, error) {
 	attr := &extraAttr{}
+	// The INVISIBLE keyword is combined with the other
+	// attributes. For example, "auto_increment INVISIBLE".
+	if fields := strings.Fields(extra); len(fields) > 0 && strings.EqualFold(fields[len(fields)-1], "invisible") {
+		attr.invisible = true
+		extra = strings.Join(fields[:len(fields)-1], " ")
+	}
 	switch el := strings.ToLower(extra); {
 	case el == "", el == "null":
 	case el == defaultGen:
@@ -678,6 +937,67 @@ const (
 	// Query to list system variables.
 	variablesQuery = "SELECT @@version, @@collation_server, @@character_set_server"
 
+	// Query to list the global settings that are managed as realm attributes.
+	settingsQuery = "SELECT @@GLOBAL.sql_mode, @@GLOBAL.time_zone"
+
+	// Query to list the views and triggers of the schemas.
+	unmanagedQuery = `
+SELECT
+	TABLE_SCHEMA,
+	'VIEW' AS TYPE,
+	TABLE_NAME AS NAME,
+	VIEW_DEFINITION AS DEFINITION,
+	NULL AS ACTION_TIMING,
+	NULL AS EVENT_MANIPULATION,
+	NULL AS EVENT_OBJECT_TABLE
+FROM
+	INFORMATION_SCHEMA.VIEWS
+WHERE
+	TABLE_SCHEMA IN (%[1]s)
+UNION ALL
+SELECT
+	TRIGGER_SCHEMA,
+	'TRIGGER' AS TYPE,
+	TRIGGER_NAME AS NAME,
+	ACTION_STATEMENT AS DEFINITION,
+	ACTION_TIMING,
+	EVENT_MANIPULATION,
+	EVENT_OBJECT_TABLE
+FROM
+	INFORMATION_SCHEMA.TRIGGERS
+WHERE
+	TRIGGER_SCHEMA IN (%[1]s)
+ORDER BY
+	1, 2, 3
+`
+
+	// Query to list the functions and procedures of the schemas, with their arguments.
+	funcsQuery = `
+SELECT
+	r.ROUTINE_SCHEMA,
+	r.ROUTINE_TYPE,
+	r.ROUTINE_NAME,
+	IF(r.ROUTINE_TYPE = 'FUNCTION', r.DTD_IDENTIFIER, NULL) AS RETURNS,
+	(
+		SELECT
+			GROUP_CONCAT(CONCAT_WS(' ', p.PARAMETER_MODE, p.PARAMETER_NAME, p.DTD_IDENTIFIER) ORDER BY p.ORDINAL_POSITION SEPARATOR ', ')
+		FROM
+			INFORMATION_SCHEMA.PARAMETERS AS p
+		WHERE
+			p.SPECIFIC_SCHEMA = r.ROUTINE_SCHEMA AND p.SPECIFIC_NAME = r.SPECIFIC_NAME AND p.ORDINAL_POSITION > 0
+	) AS ARGS,
+	r.ROUTINE_DEFINITION,
+	r.IS_DETERMINISTIC,
+	r.SQL_DATA_ACCESS,
+	r.SECURITY_TYPE
+FROM
+	INFORMATION_SCHEMA.ROUTINES AS r
+WHERE
+	r.ROUTINE_SCHEMA IN (%s)
+ORDER BY
+	1, 2, 3
+`
+
 	// Query to list database schemas.
 	schemasQuery = "SELECT `SCHEMA_NAME`, `DEFAULT_CHARACTER_SET_NAME`, `DEFAULT_COLLATION_NAME` from `INFORMATION_SCHEMA`.`SCHEMATA` WHERE `SCHEMA_NAME` NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys') ORDER BY `SCHEMA_NAME`"
 
@@ -689,9 +1009,10 @@ const (
 	columnsExprQuery = "SELECT `TABLE_NAME`, `COLUMN_NAME`, `COLUMN_TYPE`, `COLUMN_COMMENT`, `IS_NULLABLE`, `COLUMN_KEY`, `COLUMN_DEFAULT`, `EXTRA`, `CHARACTER_SET_NAME`, `COLLATION_NAME`, `GENERATION_EXPRESSION` FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `ORDINAL_POSITION`"
 
 	// Query to list table indexes.
-	indexesQuery          = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, `INDEX_COMMENT`, `SUB_PART`, NULL AS `EXPRESSION` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
-	indexesExprQuery      = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, `INDEX_COMMENT`, `SUB_PART`, `EXPRESSION` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
-	indexesNoCommentQuery = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, NULL AS `INDEX_COMMENT`, `SUB_PART`, NULL AS `EXPRESSION` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
+	indexesQuery          = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, `INDEX_COMMENT`, `SUB_PART`, NULL AS `EXPRESSION`, 'YES' AS `IS_VISIBLE` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
+	indexesVisibleQuery   = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, `INDEX_COMMENT`, `SUB_PART`, NULL AS `EXPRESSION`, `IS_VISIBLE` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
+	indexesExprQuery      = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, `INDEX_COMMENT`, `SUB_PART`, `EXPRESSION`, `IS_VISIBLE` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
+	indexesNoCommentQuery = "SELECT `TABLE_NAME`, `INDEX_NAME`, `COLUMN_NAME`, `NON_UNIQUE`, `SEQ_IN_INDEX`, `INDEX_TYPE`, UPPER(`COLLATION`) = 'D' AS `DESC`, NULL AS `INDEX_COMMENT`, `SUB_PART`, NULL AS `EXPRESSION`, 'YES' AS `IS_VISIBLE` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `index_name`, `seq_in_index`"
 
 	tablesQuery = `
 SELECT
@@ -732,6 +1053,27 @@ ORDER BY
 	TABLE_SCHEMA, TABLE_NAME
 `
 
+	// Query to list the partitions of partitioned tables.
+	partitionsQuery = `
+SELECT
+	TABLE_NAME,
+	PARTITION_NAME,
+	SUBPARTITION_NAME,
+	PARTITION_METHOD,
+	SUBPARTITION_METHOD,
+	PARTITION_EXPRESSION,
+	SUBPARTITION_EXPRESSION,
+	PARTITION_DESCRIPTION
+FROM
+	INFORMATION_SCHEMA.PARTITIONS
+WHERE
+	TABLE_SCHEMA = ?
+	AND TABLE_NAME IN (%s)
+	AND PARTITION_NAME IS NOT NULL
+ORDER BY
+	TABLE_NAME, PARTITION_ORDINAL_POSITION, SUBPARTITION_ORDINAL_POSITION
+`
+
 	// Query to list table check constraints.
 	myChecksQuery  = `SELECT t1.TABLE_NAME, t1.CONSTRAINT_NAME, t2.CHECK_CLAUSE, t1.ENFORCED` + checksQuery
 	marChecksQuery = `SELECT t1.TABLE_NAME, t1.CONSTRAINT_NAME, t2.CHECK_CLAUSE, "YES" AS ENFORCED` + checksQuery
@@ -775,6 +1117,36 @@ WHERE
 ORDER BY
 	t1.CONSTRAINT_NAME,
 	t1.ORDINAL_POSITION`
+
+	// Query to compute the fingerprints of the tables in a schema. Rows of child objects
+	// are hashed separately and summed, to avoid the length limit of GROUP_CONCAT.
+	fingerprintsQuery = `
+SELECT
+	t.TABLE_NAME,
+	MD5(CONCAT_WS('|',
+		QUOTE(t.CREATE_TIME),
+		QUOTE(t.TABLE_COLLATION),
+		QUOTE(t.AUTO_INCREMENT),
+		QUOTE(t.CREATE_OPTIONS),
+		QUOTE(t.TABLE_COMMENT),
+		(SELECT SUM(CONV(LEFT(MD5(CONCAT_WS('|', c.COLUMN_NAME, c.ORDINAL_POSITION, c.COLUMN_TYPE, c.IS_NULLABLE, QUOTE(c.COLUMN_DEFAULT), c.EXTRA, QUOTE(c.COLLATION_NAME), c.COLUMN_COMMENT%s)), 15), 16, 10)) FROM INFORMATION_SCHEMA.COLUMNS AS c WHERE c.TABLE_SCHEMA = t.TABLE_SCHEMA AND c.TABLE_NAME = t.TABLE_NAME),
+		(SELECT SUM(CONV(LEFT(MD5(CONCAT_WS('|', st.INDEX_NAME, st.SEQ_IN_INDEX, QUOTE(st.COLUMN_NAME), st.NON_UNIQUE, st.INDEX_TYPE, QUOTE(st.COLLATION), QUOTE(st.SUB_PART), st.INDEX_COMMENT)), 15), 16, 10)) FROM INFORMATION_SCHEMA.STATISTICS AS st WHERE st.TABLE_SCHEMA = t.TABLE_SCHEMA AND st.TABLE_NAME = t.TABLE_NAME),
+		(SELECT SUM(CONV(LEFT(MD5(CONCAT_WS('|', k.CONSTRAINT_NAME, k.ORDINAL_POSITION, k.COLUMN_NAME, k.REFERENCED_TABLE_SCHEMA, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME)), 15), 16, 10)) FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE AS k WHERE k.TABLE_SCHEMA = t.TABLE_SCHEMA AND k.TABLE_NAME = t.TABLE_NAME),
+		(SELECT SUM(CONV(LEFT(MD5(CONCAT_WS('|', r.CONSTRAINT_NAME, r.UPDATE_RULE, r.DELETE_RULE)), 15), 16, 10)) FROM INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS AS r WHERE r.CONSTRAINT_SCHEMA = t.TABLE_SCHEMA AND r.TABLE_NAME = t.TABLE_NAME),
+		(SELECT SUM(CONV(LEFT(MD5(CONCAT_WS('|', tr.TRIGGER_NAME, tr.ACTION_TIMING, tr.EVENT_MANIPULATION, tr.ACTION_ORDER, tr.ACTION_STATEMENT)), 15), 16, 10)) FROM INFORMATION_SCHEMA.TRIGGERS AS tr WHERE tr.EVENT_OBJECT_SCHEMA = t.TABLE_SCHEMA AND tr.EVENT_OBJECT_TABLE = t.TABLE_NAME)%s
+	))
+FROM
+	INFORMATION_SCHEMA.TABLES AS t
+WHERE
+	t.TABLE_SCHEMA = ?
+ORDER BY
+	t.TABLE_NAME
+`
+
+	// The checksum of the table check constraints, added to
+	// the fingerprints query if check constraints are supported.
+	fingerprintChecks = `,
+		(SELECT SUM(CONV(LEFT(MD5(CONCAT_WS('|', tc.CONSTRAINT_NAME, cc.CHECK_CLAUSE, %s)), 15), 16, 10)) FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS AS tc JOIN INFORMATION_SCHEMA.CHECK_CONSTRAINTS AS cc ON tc.CONSTRAINT_SCHEMA = cc.CONSTRAINT_SCHEMA AND tc.CONSTRAINT_NAME = cc.CONSTRAINT_NAME WHERE tc.CONSTRAINT_TYPE = 'CHECK' AND tc.TABLE_SCHEMA = t.TABLE_SCHEMA AND tc.TABLE_NAME = t.TABLE_NAME)`
 )
 
 type (
@@ -791,6 +1163,39 @@ type (
 		V string
 	}
 
+	// Partition describes the partitioning of a table (i.e. the PARTITION BY clause).
+	Partition struct {
+		schema.Attr
+		// T is the partitioning type: RANGE, LIST, HASH or KEY. HASH and KEY can
+		// be prefixed with LINEAR, and RANGE and LIST suffixed with COLUMNS.
+		T string
+		// Expr is the partitioning expression, or the comma-separated
+		// list of columns for the COLUMNS and KEY partitioning types.
+		Expr string
+		// Sub describes the sub-partitioning of the table, if exists.
+		Sub *SubPartition
+		// Partitions holds the partition definitions of the table.
+		Partitions []*TablePartition
+	}
+
+	// SubPartition describes the sub-partitioning of a partitioned table.
+	SubPartition struct {
+		// T is the sub-partitioning type: HASH or KEY, optionally prefixed with LINEAR.
+		T    string
+		Expr string
+		// N is the number of sub-partitions in each partition.
+		N int
+	}
+
+	// TablePartition describes a partition definition of a partitioned table.
+	TablePartition struct {
+		Name string
+		// Bound holds the VALUES clause of RANGE and LIST partitions, for example,
+		// "VALUES LESS THAN (10)" or "VALUES IN (1, 2)". HASH and KEY partitions
+		// have no bound.
+		Bound string
+	}
+
 	// CreateStmt describes the SQL statement used to create a table.
 	CreateStmt struct {
 		schema.Attr
@@ -828,6 +1233,13 @@ type (
 		A string
 	}
 
+	// Invisible attribute marks a column or an index as INVISIBLE. Invisible
+	// columns are hidden from "SELECT *" queries, and invisible indexes are
+	// maintained, but not used by the optimizer.
+	Invisible struct {
+		schema.Attr
+	}
+
 	// IndexType represents an index type.
 	IndexType struct {
 		schema.Attr
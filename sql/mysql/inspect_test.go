@@ -28,6 +28,7 @@ var (
 	queryIndexesExpr      = sqltest.Escape(fmt.Sprintf(indexesExprQuery, "?"))
 	queryMyChecks         = sqltest.Escape(fmt.Sprintf(myChecksQuery, "?"))
 	queryMarChecks        = sqltest.Escape(fmt.Sprintf(marChecksQuery, "?"))
+	queryPartitions       = sqltest.Escape(fmt.Sprintf(partitionsQuery, "?"))
 )
 
 func TestDriver_InspectTable(t *testing.T) {
@@ -61,11 +62,11 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(queryIndexesExpr).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-+--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+
-| TABLE_NAME         | INDEX_NAME   | COLUMN_NAME | NON_UNIQUE | SEQ_IN_INDEX | INDEX_TYPE   | DESC     | COMMENT      | SUB_PART   | EXPRESSION       |
-+--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+
-| users              | PRIMARY      | id          |          0 |            1 | BTREE        | 0        |              |       NULL |      NULL        |
-+--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+
++--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+------------+
+| TABLE_NAME         | INDEX_NAME   | COLUMN_NAME | NON_UNIQUE | SEQ_IN_INDEX | INDEX_TYPE   | DESC     | COMMENT      | SUB_PART   | EXPRESSION       | IS_VISIBLE |
++--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+------------+
+| users              | PRIMARY      | id          |          0 |            1 | BTREE        | 0        |              |       NULL |      NULL        | YES        |
++--------------------+--------------+-------------+------------+--------------+--------------+----------+--------------+------------+------------------+------------+
 `))
 				m.noFKs()
 				m.ExpectQuery(sqltest.Escape("SHOW CREATE TABLE `public`.`users`")).
@@ -95,6 +96,60 @@ func TestDriver_InspectTable(t *testing.T) {
 				}, t.Columns)
 			},
 		},
+		{
+			name: "partitioned table",
+			before: func(m mock) {
+				m.ExpectQuery(queryTable).
+					WithArgs("public").
+					WillReturnRows(sqltest.Rows(`
++--------------+--------------+--------------------+--------------------+----------------+---------------+----------------+
+| TABLE_SCHEMA | TABLE_NAME   | CHARACTER_SET_NAME | TABLE_COLLATION    | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS |
++--------------+--------------+--------------------+--------------------+----------------+---------------+----------------+
+| public       | logs         | utf8mb4            | utf8mb4_0900_ai_ci | nil            |               | partitioned    |
++--------------+--------------+--------------------+--------------------+----------------+---------------+----------------+
+`))
+				m.ExpectQuery(queryColumns).
+					WithArgs("public", "logs").
+					WillReturnRows(sqltest.Rows(`
++--------------------+--------------------+----------------------+----------------------+-------------+------------+----------------+----------------+--------------------+----------------+---------------------------+
+| table_name         | column_name        | column_type          | column_comment       | is_nullable | column_key | column_default | extra          | character_set_name | collation_name | generation_expression     |
++--------------------+--------------------+----------------------+----------------------+-------------+------------+----------------+----------------+--------------------+----------------+---------------------------+
+| logs               | id                 | int                  |                      | NO          |            | NULL           |                | NULL               | NULL           | NULL                      |
++--------------------+--------------------+----------------------+----------------------+-------------+------------+----------------+----------------+--------------------+----------------+---------------------------+
+`))
+				m.noIndexes()
+				m.noFKs()
+				m.ExpectQuery(queryPartitions).
+					WithArgs("public", "logs").
+					WillReturnRows(sqltest.Rows(`
++------------+----------------+-------------------+------------------+---------------------+----------------------+-------------------------+-----------------------+
+| TABLE_NAME | PARTITION_NAME | SUBPARTITION_NAME | PARTITION_METHOD | SUBPARTITION_METHOD | PARTITION_EXPRESSION | SUBPARTITION_EXPRESSION | PARTITION_DESCRIPTION |
++------------+----------------+-------------------+------------------+---------------------+----------------------+-------------------------+-----------------------+
+| logs       | p0             | p0sp0             | RANGE            | HASH                | ` + "`id`" + `                 | ` + "`id`" + `                    | 10                    |
+| logs       | p0             | p0sp1             | RANGE            | HASH                | ` + "`id`" + `                 | ` + "`id`" + `                    | 10                    |
+| logs       | p1             | p1sp0             | RANGE            | HASH                | ` + "`id`" + `                 | ` + "`id`" + `                    | MAXVALUE              |
+| logs       | p1             | p1sp1             | RANGE            | HASH                | ` + "`id`" + `                 | ` + "`id`" + `                    | MAXVALUE              |
++------------+----------------+-------------------+------------------+---------------------+----------------------+-------------------------+-----------------------+
+`))
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				require.Equal("logs", t.Name)
+				require.EqualValues([]schema.Attr{
+					&schema.Charset{V: "utf8mb4"},
+					&schema.Collation{V: "utf8mb4_0900_ai_ci"},
+					&Partition{
+						T:    PartitionTypeRange,
+						Expr: "`id`",
+						Sub:  &SubPartition{T: PartitionTypeHash, Expr: "`id`", N: 2},
+						Partitions: []*TablePartition{
+							{Name: "p0", Bound: "VALUES LESS THAN (10)"},
+							{Name: "p1", Bound: "VALUES LESS THAN MAXVALUE"},
+						},
+					},
+				}, t.Attrs)
+			},
+		},
 		{
 			name: "int types",
 			before: func(m mock) {
@@ -171,7 +226,7 @@ func TestDriver_InspectTable(t *testing.T) {
 +------------+----------------+------------------------------+----------------------+-------------+------------+----------------+----------------+--------------------+----------------+---------------------------+
 `))
 				m.ExpectQuery(queryIndexes).
-					WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression"}))
+					WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression", "is_visible"}))
 				m.noFKs()
 				m.ExpectQuery(queryMarChecks).
 					WithArgs("public", "users").
@@ -536,17 +591,17 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(queryIndexesExpr).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-+--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+
-| TABLE_NAME   | INDEX_NAME   | COLUMN_NAME | NON_UNIQUE | SEQ_IN_INDEX | INDEX_TYPE   | DESC    | COMMENT      | SUB_PART   | EXPRESSION       |
-+--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+
-| users        | nickname     | nickname    |          0 |            1 | BTREE        | nil     |              |        255 |      NULL        |
-| users        | lower_nick   | NULL        |          1 |            1 | HASH         | 0       |              |       NULL | lower(nickname)  |
-| users        | non_unique   | oid         |          1 |            1 | BTREE        | 0       |              |       NULL |      NULL        |
-| users        | non_unique   | uid         |          1 |            2 | BTREE        | 0       |              |       NULL |      NULL        |
-| users        | PRIMARY      | id          |          0 |            1 | BTREE        | 0       |              |       NULL |      NULL        |
-| users        | unique_index | uid         |          0 |            1 | BTREE        | 1       |              |       NULL |      NULL        |
-| users        | unique_index | oid         |          0 |            2 | BTREE        | 1       |              |       NULL |      NULL        |
-+--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+
++--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+------------+
+| TABLE_NAME   | INDEX_NAME   | COLUMN_NAME | NON_UNIQUE | SEQ_IN_INDEX | INDEX_TYPE   | DESC    | COMMENT      | SUB_PART   | EXPRESSION       | IS_VISIBLE |
++--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+------------+
+| users        | nickname     | nickname    |          0 |            1 | BTREE        | nil     |              |        255 |      NULL        | YES        |
+| users        | lower_nick   | NULL        |          1 |            1 | HASH         | 0       |              |       NULL | lower(nickname)  | YES        |
+| users        | non_unique   | oid         |          1 |            1 | BTREE        | 0       |              |       NULL |      NULL        | YES        |
+| users        | non_unique   | uid         |          1 |            2 | BTREE        | 0       |              |       NULL |      NULL        | YES        |
+| users        | PRIMARY      | id          |          0 |            1 | BTREE        | 0       |              |       NULL |      NULL        | YES        |
+| users        | unique_index | uid         |          0 |            1 | BTREE        | 1       |              |       NULL |      NULL        | YES        |
+| users        | unique_index | oid         |          0 |            2 | BTREE        | 1       |              |       NULL |      NULL        | YES        |
++--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+------------+
 `))
 				m.noFKs()
 				m.ExpectQuery(sqltest.Escape("SHOW CREATE TABLE `public`.`users`")).
@@ -613,11 +668,11 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(queryIndexesNoComment).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-+--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+
-| TABLE_NAME   | INDEX_NAME   | COLUMN_NAME | NON_UNIQUE | SEQ_IN_INDEX | INDEX_TYPE   | DESC    | COMMENT      | SUB_PART   | EXPRESSION       |
-+--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+
-| users        | PRIMARY      | id          |          0 |            1 | BTREE        | 0       | NULL         |       NULL |      NULL        |
-+--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+
++--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+------------+
+| TABLE_NAME   | INDEX_NAME   | COLUMN_NAME | NON_UNIQUE | SEQ_IN_INDEX | INDEX_TYPE   | DESC    | COMMENT      | SUB_PART   | EXPRESSION       | IS_VISIBLE |
++--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+------------+
+| users        | PRIMARY      | id          |          0 |            1 | BTREE        | 0       | NULL         |       NULL |      NULL        | YES        |
++--------------+--------------+-------------+------------+--------------+--------------+---------+--------------+------------+------------------+------------+
 `))
 				m.noFKs()
 			},
@@ -731,6 +786,54 @@ func TestDriver_InspectTable(t *testing.T) {
 				}, t.Attrs)
 			},
 		},
+		{
+			name:    "invisible columns and indexes",
+			version: "8.0.23",
+			before: func(m mock) {
+				m.ExpectQuery(queryTable).
+					WithArgs("public").
+					WillReturnRows(sqltest.Rows(`
++--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+
+| TABLE_SCHEMA | TABLE_NAME   | CHARACTER_SET_NAME | TABLE_COLLATION    | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS     |
++--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+
+| public       | users        | utf8mb4            | utf8mb4_0900_ai_ci | nil            |               |                    |
++--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+
+`))
+				m.ExpectQuery(queryColumns).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
++------------+-------------+-------------+----------------+-------------+------------+----------------+-----------------------------+--------------------+----------------+-----------------------+
+| TABLE_NAME | COLUMN_NAME | COLUMN_TYPE | COLUMN_COMMENT | IS_NULLABLE | COLUMN_KEY | COLUMN_DEFAULT | EXTRA                       | CHARACTER_SET_NAME | COLLATION_NAME | GENERATION_EXPRESSION |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-----------------------------+--------------------+----------------+-----------------------+
+| users      | id          | int         |                | NO          | PRI        | NULL           |                             | NULL               | NULL           | NULL                  |
+| users      | a           | int         |                | YES         | MUL        | NULL           | INVISIBLE                   | NULL               | NULL           | NULL                  |
+| users      | b           | int         |                | YES         |            | NULL           | VIRTUAL GENERATED INVISIBLE | NULL               | NULL           | a + 1                 |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-----------------------------+--------------------+----------------+-----------------------+
+`))
+				m.ExpectQuery(queryIndexesExpr).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
++------------+------------+-------------+------------+--------------+------------+------+---------+----------+------------+------------+
+| TABLE_NAME | INDEX_NAME | COLUMN_NAME | NON_UNIQUE | SEQ_IN_INDEX | INDEX_TYPE | DESC | COMMENT | SUB_PART | EXPRESSION | IS_VISIBLE |
++------------+------------+-------------+------------+--------------+------------+------+---------+----------+------------+------------+
+| users      | PRIMARY    | id          |          0 |            1 | BTREE      | 0    |         |     NULL |       NULL | YES        |
+| users      | a          | a           |          1 |            1 | BTREE      | 0    |         |     NULL |       NULL | NO         |
++------------+------------+-------------+------------+--------------+------------+------+---------+----------+------------+------------+
+`))
+				m.noFKs()
+				m.ExpectQuery(queryMyChecks).
+					WithArgs("public", "users").
+					WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"}))
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				require.Empty(t.Columns[0].Attrs)
+				require.Equal([]schema.Attr{&Invisible{}}, t.Columns[1].Attrs)
+				require.Equal([]schema.Attr{&Invisible{}, &schema.GeneratedExpr{Expr: "a + 1", Type: "VIRTUAL"}}, t.Columns[2].Attrs)
+				require.Len(t.Indexes, 1)
+				require.Equal([]schema.Attr{&IndexType{T: "BTREE"}, &Invisible{}}, t.Indexes[0].Attrs)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -838,7 +941,7 @@ func TestDriver_InspectSchema(t *testing.T) {
 +-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+---------------------------+
 				`))
 				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesExprQuery, "?, ?"))).
-					WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression"}))
+					WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression", "is_visible"}))
 				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "?, ?"))).
 					WithArgs("public", "users", "pets").
 					WillReturnRows(sqltest.Rows(`
@@ -1031,6 +1134,72 @@ func TestInspectMode_InspectRealm(t *testing.T) {
 	}(), realm)
 }
 
+func TestInspectMode_InspectSettings(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("8.0.13")
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "default_character_set_name", "default_collation_name"}))
+	mk.ExpectQuery(sqltest.Escape(settingsQuery)).
+		WillReturnRows(sqltest.Rows(`
++---------------------+--------------------+
+| @@GLOBAL.sql_mode   | @@GLOBAL.time_zone |
++---------------------+--------------------+
+| STRICT_TRANS_TABLES | +00:00             |
++---------------------+--------------------+
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSettings})
+	require.NoError(t, err)
+	require.Empty(t, realm.Schemas)
+	require.EqualValues(t, []schema.Attr{
+		&schema.Charset{V: "utf8"},
+		&schema.Collation{V: "utf8_general_ci"},
+		&schema.Setting{Name: "sql_mode", V: "STRICT_TRANS_TABLES"},
+		&schema.Setting{Name: "time_zone", V: "+00:00"},
+	}, realm.Attrs)
+}
+
+func TestInspectMode_InspectFuncs(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("8.0.13")
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| test        | utf8                       | utf8_general_ci        |
++-------------+----------------------------+------------------------+
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(funcsQuery, "?"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
++----------------+--------------+--------------+---------+-------------------+------------------------+------------------+-----------------+---------------+
+| ROUTINE_SCHEMA | ROUTINE_TYPE | ROUTINE_NAME | RETURNS | ARGS              | ROUTINE_DEFINITION     | IS_DETERMINISTIC | SQL_DATA_ACCESS | SECURITY_TYPE |
++----------------+--------------+--------------+---------+-------------------+------------------------+------------------+-----------------+---------------+
+| test           | FUNCTION     | add          | int     | a int, b int      | RETURN a + b           | YES              | NO SQL          | DEFINER       |
+| test           | FUNCTION     | one          | int     | NULL              | RETURN 1               | NO               | CONTAINS SQL    | DEFINER       |
+| test           | PROCEDURE    | archive      | NULL    | IN id int         | BEGIN SELECT id; END   | NO               | READS SQL DATA  | INVOKER       |
++----------------+--------------+--------------+---------+-------------------+------------------------+------------------+-----------------+---------------+
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectFuncs})
+	require.NoError(t, err)
+	s := realm.Schemas[0]
+	require.Equal(t, []*schema.Func{
+		schema.NewFunc("add", "a int, b int", "int", "DETERMINISTIC NO SQL RETURN a + b").SetSchema(s),
+		schema.NewFunc("one", "", "int", "RETURN 1").SetSchema(s),
+	}, s.Funcs)
+	require.Equal(t, []*schema.Proc{
+		schema.NewProc("archive", "IN id int", "READS SQL DATA SQL SECURITY INVOKER BEGIN SELECT id; END").SetSchema(s),
+	}, s.Procs)
+}
+
 type mock struct {
 	sqlmock.Sqlmock
 }
@@ -1048,7 +1217,7 @@ func (m mock) version(version string) {
 
 func (m mock) noIndexes() {
 	m.ExpectQuery(queryIndexesExpr).
-		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression"}))
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression", "is_visible"}))
 }
 
 func (m mock) noFKs() {
@@ -84,6 +84,32 @@ func (v V) SupportsIndexComment() bool {
 	return v.Maria() || v.GTE("5.5.3")
 }
 
+// SupportsInvisibleColumn reports if the version supports
+// the INVISIBLE attribute on column definitions.
+func (v V) SupportsInvisibleColumn() bool {
+	u := "8.0.23"
+	if v.Maria() {
+		u = "10.3.3"
+	}
+	return v.GTE(u)
+}
+
+// SupportsInvisibleIndex reports if the version supports invisible indexes.
+// MariaDB provides a similar functionality using IGNORED indexes.
+func (v V) SupportsInvisibleIndex() bool {
+	return !v.Maria() && v.GTE("8.0.0")
+}
+
+// SupportsInstantAddColumn reports if the version supports
+// adding columns using the INSTANT algorithm (metadata-only).
+func (v V) SupportsInstantAddColumn() bool {
+	u := "8.0.12"
+	if v.Maria() {
+		u = "10.3.2"
+	}
+	return v.GTE(u)
+}
+
 // CharsetToCollate returns the mapping from charset to its default collation.
 func (v V) CharsetToCollate() (map[string]string, error) {
 	name := "is/charset2collate"
@@ -115,6 +141,17 @@ func (v V) TiDB() bool {
 // Compare returns an integer comparing two versions according to
 // semantic version precedence.
 func (v V) Compare(w string) int {
+	return semver.Compare(v.semver(), "v"+w)
+}
+
+// Valid reports if the version is a valid (semantic) version.
+// For example, "8.0.31" or "10.6.11-MariaDB".
+func (v V) Valid() bool {
+	return semver.IsValid(v.semver())
+}
+
+// semver returns the version in its semver form, without the flavor suffix.
+func (v V) semver() string {
 	u := string(v)
 	switch {
 	case v.Maria():
@@ -122,7 +159,7 @@ func (v V) Compare(w string) int {
 	case v.TiDB():
 		u = u[:strings.Index(u, "TiDB")-1]
 	}
-	return semver.Compare("v"+u, "v"+w)
+	return "v" + u
 }
 
 // GTE reports if the version is >= w.
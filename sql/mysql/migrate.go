@@ -13,6 +13,7 @@ import (
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql/internal/mysqlversion"
 	"ariga.io/atlas/sql/schema"
 )
 
@@ -28,14 +29,22 @@ func (p *planApply) PlanChanges(_ context.Context, name string, changes []schema
 			// A plan is reversible, if all
 			// its changes are reversible.
 			Reversible: true,
-			// All statements generated by state will cause implicit commit.
-			// https://dev.mysql.com/doc/refman/8.0/en/implicit-commit.html
-			Transactional: false,
 		},
 	}
 	for _, o := range opts {
 		o(&s.PlanOptions)
 	}
+	// Plan the changes for the target version, instead of the connected one.
+	if s.TargetVersion != "" {
+		v := mysqlversion.V(s.TargetVersion)
+		if !v.Valid() {
+			return nil, fmt.Errorf("mysql: malformed target version: %s", s.TargetVersion)
+		}
+		s.V = v
+	}
+	// All statements generated by state will cause implicit commit.
+	// https://dev.mysql.com/doc/refman/8.0/en/implicit-commit.html
+	s.Transactional = s.capabilities().Is(migrate.CapTransactionalDDL)
 	if err := s.plan(changes); err != nil {
 		return nil, err
 	}
@@ -62,6 +71,8 @@ type state struct {
 	conn
 	migrate.Plan
 	migrate.PlanOptions
+	// Changes that are planned after all table changes.
+	deferred []*migrate.Change
 }
 
 // plan builds the migration plan for applying the
@@ -72,6 +83,14 @@ func (s *state) plan(changes []schema.Change) error {
 			return err
 		}
 	}
+	dropT, changes, addT := sqlx.SplitTriggers(changes)
+	if err := s.dropTriggers(dropT); err != nil {
+		return err
+	}
+	drop, changes, add := sqlx.SplitViews(changes)
+	s.views(drop)
+	dropF, changes, addF := sqlx.SplitFuncs(changes)
+	s.funcs(dropF)
 	planned, err := s.topLevel(changes)
 	if err != nil {
 		return err
@@ -97,9 +116,186 @@ func (s *state) plan(changes []schema.Change) error {
 			return err
 		}
 	}
+	s.Changes = append(s.Changes, s.deferred...)
+	// Functions are created before views, as they are
+	// commonly used in view definitions.
+	s.funcs(addF)
+	s.views(add)
+	return s.addTriggers(addT)
+}
+
+// views plans the given view changes. Modified views are
+// replaced in place, as they may be referenced by other views.
+func (s *state) views(changes []schema.Change) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddView:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("CREATE VIEW").View(c.V).P("AS", c.V.Def).String(),
+				Source:  c,
+				Reverse: s.Build("DROP VIEW").View(c.V).String(),
+				Comment: fmt.Sprintf("create %q view", c.V.Name),
+			})
+		case *schema.DropView:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("DROP VIEW").View(c.V).String(),
+				Source:  c,
+				Reverse: s.Build("CREATE VIEW").View(c.V).P("AS", c.V.Def).String(),
+				Comment: fmt.Sprintf("drop %q view", c.V.Name),
+			})
+		case *schema.ModifyView:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("CREATE OR REPLACE VIEW").View(c.To).P("AS", c.To.Def).String(),
+				Source:  c,
+				Reverse: s.Build("CREATE OR REPLACE VIEW").View(c.From).P("AS", c.From.Def).String(),
+				Comment: fmt.Sprintf("modify %q view", c.To.Name),
+			})
+		}
+	}
+}
+
+// funcs plans the given function and procedure changes. MySQL does not support
+// replacing routines in place, and therefore, modified routines are dropped and
+// created again.
+func (s *state) funcs(changes []schema.Change) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddFunc:
+			s.append(&migrate.Change{
+				Cmd:     s.createFunc(c.F),
+				Source:  c,
+				Reverse: s.Build("DROP FUNCTION").Func(c.F).String(),
+				Comment: fmt.Sprintf("create %q function", c.F.Name),
+			})
+		case *schema.DropFunc:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("DROP FUNCTION").Func(c.F).String(),
+				Source:  c,
+				Reverse: s.createFunc(c.F),
+				Comment: fmt.Sprintf("drop %q function", c.F.Name),
+			})
+		case *schema.ModifyFunc:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("DROP FUNCTION").Func(c.From).String(),
+				Source:  c,
+				Reverse: s.createFunc(c.From),
+				Comment: fmt.Sprintf("drop %q function", c.From.Name),
+			})
+			s.append(&migrate.Change{
+				Cmd:     s.createFunc(c.To),
+				Source:  c,
+				Reverse: s.Build("DROP FUNCTION").Func(c.To).String(),
+				Comment: fmt.Sprintf("create %q function", c.To.Name),
+			})
+		case *schema.AddProc:
+			s.append(&migrate.Change{
+				Cmd:     s.createProc(c.P),
+				Source:  c,
+				Reverse: s.Build("DROP PROCEDURE").Proc(c.P).String(),
+				Comment: fmt.Sprintf("create %q procedure", c.P.Name),
+			})
+		case *schema.DropProc:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("DROP PROCEDURE").Proc(c.P).String(),
+				Source:  c,
+				Reverse: s.createProc(c.P),
+				Comment: fmt.Sprintf("drop %q procedure", c.P.Name),
+			})
+		case *schema.ModifyProc:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("DROP PROCEDURE").Proc(c.From).String(),
+				Source:  c,
+				Reverse: s.createProc(c.From),
+				Comment: fmt.Sprintf("drop %q procedure", c.From.Name),
+			})
+			s.append(&migrate.Change{
+				Cmd:     s.createProc(c.To),
+				Source:  c,
+				Reverse: s.Build("DROP PROCEDURE").Proc(c.To).String(),
+				Comment: fmt.Sprintf("create %q procedure", c.To.Name),
+			})
+		}
+	}
+}
+
+// createFunc returns the statement for creating the given function.
+func (s *state) createFunc(f *schema.Func) string {
+	return fmt.Sprintf("%s(%s) %s", s.Build("CREATE FUNCTION").Func(f).String(), f.Args, s.Build("RETURNS", f.Ret, f.Body).String())
+}
+
+// createProc returns the statement for creating the given procedure.
+func (s *state) createProc(p *schema.Proc) string {
+	return fmt.Sprintf("%s(%s) %s", s.Build("CREATE PROCEDURE").Proc(p).String(), p.Args, p.Body)
+}
+
+// dropTriggers plans the given trigger changes that should be executed before
+// all other changes. Modified triggers are dropped, and created again later.
+func (s *state) dropTriggers(changes []schema.Change) error {
+	for _, c := range changes {
+		var t *schema.Trigger
+		switch c := c.(type) {
+		case *schema.DropTrigger:
+			t = c.T
+		case *schema.ModifyTrigger:
+			t = c.From
+		default:
+			return fmt.Errorf("unexpected trigger change %T", c)
+		}
+		create, err := s.createTrigger(t)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.Build("DROP TRIGGER").Trigger(t).String(),
+			Source:  c,
+			Reverse: create,
+			Comment: fmt.Sprintf("drop %q trigger", t.Name),
+		})
+	}
 	return nil
 }
 
+// addTriggers plans the given trigger changes that
+// should be executed after all other changes.
+func (s *state) addTriggers(changes []schema.Change) error {
+	for _, c := range changes {
+		var t *schema.Trigger
+		switch c := c.(type) {
+		case *schema.AddTrigger:
+			t = c.T
+		case *schema.ModifyTrigger:
+			t = c.To
+		default:
+			return fmt.Errorf("unexpected trigger change %T", c)
+		}
+		create, err := s.createTrigger(t)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     create,
+			Source:  c,
+			Reverse: s.Build("DROP TRIGGER").Trigger(t).String(),
+			Comment: fmt.Sprintf("create %q trigger", t.Name),
+		})
+	}
+	return nil
+}
+
+// createTrigger returns the statement for creating the given trigger. MySQL
+// supports only row-level triggers with one event, and without a condition.
+func (s *state) createTrigger(t *schema.Trigger) (string, error) {
+	switch {
+	case len(t.Events) != 1:
+		return "", fmt.Errorf("mysql: trigger %q must have exactly one event, got %d", t.Name, len(t.Events))
+	case !t.ForEachRow:
+		return "", fmt.Errorf("mysql: statement-level trigger %q is not supported", t.Name)
+	case t.When != "":
+		return "", fmt.Errorf("mysql: condition of trigger %q is not supported", t.Name)
+	}
+	return s.Build("CREATE TRIGGER").Trigger(t).P(t.ActionTime, t.Events[0], "ON").Table(t.Table).P("FOR EACH ROW", t.Body).String(), nil
+}
+
 // topLevel appends first the changes for creating or dropping schemas (top-level schema elements).
 func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 	planned := make([]schema.Change, 0, len(changes))
@@ -125,6 +321,11 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 				Reverse: s.Build("DROP DATABASE").Ident(c.S.Name).String(),
 				Comment: fmt.Sprintf("add new schema named %q", c.S.Name),
 			})
+			_, unmanaged, err := sqlx.UnmanagedChanges(c)
+			if err != nil {
+				return nil, err
+			}
+			s.deferred = append(s.deferred, unmanaged...)
 		case *schema.DropSchema:
 			b := s.Build("DROP DATABASE")
 			if sqlx.Has(c.Extra, &schema.IfExists{}) {
@@ -137,9 +338,21 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 				Comment: fmt.Sprintf("drop schema named %q", c.S.Name),
 			})
 		case *schema.ModifySchema:
+			rest, unmanaged, err := sqlx.UnmanagedChanges(c)
+			if err != nil {
+				return nil, err
+			}
+			if len(unmanaged) > 0 {
+				c = &schema.ModifySchema{S: c.S, Changes: rest}
+			}
 			if err := s.modifySchema(c); err != nil {
 				return nil, err
 			}
+			s.deferred = append(s.deferred, unmanaged...)
+		case *schema.ModifyRealm:
+			if err := s.modifyRealm(c); err != nil {
+				return nil, err
+			}
 		default:
 			planned = append(planned, c)
 		}
@@ -147,6 +360,35 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 	return planned, nil
 }
 
+// modifyRealm builds and appends the migrate.Changes for bringing
+// the global settings of the server into their modified state.
+func (s *state) modifyRealm(modify *schema.ModifyRealm) error {
+	for _, change := range modify.Changes {
+		to, from, err := sqlx.SettingChange(change)
+		if err != nil {
+			return err
+		}
+		v, err := sqlx.SingleQuote(to.V)
+		if err != nil {
+			return err
+		}
+		// Settings that were not set before are reverted to their default values.
+		r := "DEFAULT"
+		if from != nil {
+			if r, err = sqlx.SingleQuote(from.V); err != nil {
+				return err
+			}
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.Build("SET GLOBAL").P(to.Name, "=", v).String(),
+			Reverse: s.Build("SET GLOBAL").P(to.Name, "=", r).String(),
+			Source:  modify,
+			Comment: fmt.Sprintf("set %q setting", to.Name),
+		})
+	}
+	return nil
+}
+
 // modifySchema builds and appends the migrate.Changes for bringing
 // the schema into its modified state.
 func (s *state) modifySchema(modify *schema.ModifySchema) error {
@@ -237,6 +479,9 @@ func (s *state) addTable(add *schema.AddTable) error {
 		}
 		b.MapComma(add.T.Indexes, func(i int, b *sqlx.Builder) {
 			idx := add.T.Indexes[i]
+			if err := s.checkIndex(idx); err != nil {
+				errs = append(errs, err.Error())
+			}
 			index(b, idx)
 		})
 		if len(add.T.ForeignKeys) > 0 {
@@ -256,6 +501,9 @@ func (s *state) addTable(add *schema.AddTable) error {
 		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
 	}
 	s.tableAttr(b, add, add.T.Attrs...)
+	if p, ok := partitionOf(add.T.Attrs); ok {
+		partitionBy(b, p)
+	}
 	s.append(&migrate.Change{
 		Cmd:     b.String(),
 		Source:  add,
@@ -283,7 +531,10 @@ func (s *state) dropTable(drop *schema.DropTable) {
 // modifyTable builds and appends the migration changes for
 // bringing the table into its modified state.
 func (s *state) modifyTable(modify *schema.ModifyTable) error {
-	var changes [2][]schema.Change
+	var (
+		changes [2][]schema.Change
+		parts   []schema.Change
+	)
 	if len(modify.T.Columns) == 0 {
 		return fmt.Errorf("table %q has no columns; drop the table instead", modify.T.Name)
 	}
@@ -309,16 +560,30 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			changes[1] = append(changes[1], &schema.AddForeignKey{
 				F: change.To,
 			})
-		// Index modification requires rebuilding the index.
+		// Changing the index visibility does not require rebuilding it.
 		case *schema.ModifyIndex:
+			if visibilityChanged(change) {
+				changes[1] = append(changes[1], change)
+				continue
+			}
+			// Other index modifications require rebuilding the index.
 			changes[0] = append(changes[0], &schema.DropIndex{
 				I: change.From,
 			})
 			changes[1] = append(changes[1], &schema.AddIndex{
 				I: change.To,
 			})
-		case *schema.DropAttr:
-			return fmt.Errorf("unsupported change type: %v", change.A)
+		// Partitioning changes cannot be mixed with other
+		// changes, and are executed in separate statements.
+		case *schema.AddAttr, *schema.DropAttr, *schema.ModifyAttr:
+			if isPartitionChange(change) {
+				parts = append(parts, change)
+				continue
+			}
+			if d, ok := change.(*schema.DropAttr); ok {
+				return fmt.Errorf("unsupported change type: %v", d.A)
+			}
+			changes[1] = append(changes[1], change)
 		default:
 			changes[1] = append(changes[1], change)
 		}
@@ -330,9 +595,167 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			}
 		}
 	}
+	for _, c := range parts {
+		s.partitionChange(modify.T, c)
+	}
 	return nil
 }
 
+// isPartitionChange reports if the given change modifies the table partitioning.
+func isPartitionChange(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		_, ok := c.A.(*Partition)
+		return ok
+	case *schema.DropAttr:
+		_, ok := c.A.(*Partition)
+		return ok
+	case *schema.ModifyAttr:
+		_, ok := c.To.(*Partition)
+		return ok
+	}
+	return false
+}
+
+// partitionChange builds and appends the migrate.Change(s) for
+// changing the partitioning of a table.
+func (s *state) partitionChange(t *schema.Table, c schema.Change) {
+	repartition := func(p *Partition) string {
+		b := s.Build("ALTER TABLE").Table(t)
+		partitionBy(b, p)
+		return b.String()
+	}
+	remove := s.Build("ALTER TABLE").Table(t).P("REMOVE PARTITIONING").String()
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		s.append(&migrate.Change{
+			Cmd:     repartition(c.A.(*Partition)),
+			Source:  c,
+			Reverse: remove,
+			Comment: fmt.Sprintf("partition %q table", t.Name),
+		})
+	case *schema.DropAttr:
+		s.append(&migrate.Change{
+			Cmd:     remove,
+			Source:  c,
+			Reverse: repartition(c.A.(*Partition)),
+			Comment: fmt.Sprintf("remove partitioning from %q table", t.Name),
+		})
+	case *schema.ModifyAttr:
+		from, to := c.From.(*Partition), c.To.(*Partition)
+		drop, add, ok := partitionsDiff(from, to)
+		if !ok {
+			s.append(&migrate.Change{
+				Cmd:     repartition(to),
+				Source:  c,
+				Reverse: repartition(from),
+				Comment: fmt.Sprintf("repartition %q table", t.Name),
+			})
+			return
+		}
+		if len(drop) > 0 {
+			b := s.Build("ALTER TABLE").Table(t).P("DROP PARTITION")
+			b.MapComma(drop, func(i int, b *sqlx.Builder) {
+				b.Ident(drop[i].Name)
+			})
+			s.append(&migrate.Change{
+				Cmd:     b.String(),
+				Source:  c,
+				Reverse: repartition(from),
+				Comment: fmt.Sprintf("drop partitions from %q table", t.Name),
+			})
+		}
+		if len(add) > 0 {
+			b, r := s.Build("ALTER TABLE").Table(t).P("ADD PARTITION"), s.Build("ALTER TABLE").Table(t).P("DROP PARTITION")
+			b.Wrap(func(b *sqlx.Builder) {
+				b.MapComma(add, func(i int, b *sqlx.Builder) {
+					partition(b, add[i])
+				})
+			})
+			r.MapComma(add, func(i int, b *sqlx.Builder) {
+				b.Ident(add[i].Name)
+			})
+			s.append(&migrate.Change{
+				Cmd:     b.String(),
+				Source:  c,
+				Reverse: r.String(),
+				Comment: fmt.Sprintf("add partitions to %q table", t.Name),
+			})
+		}
+	}
+}
+
+// partitionsDiff returns the partitions that can be dropped from and added to a
+// RANGE or LIST partitioned table, without repartitioning it. It reports false
+// in case the partitioning key was changed, a bound of an existing partition
+// was changed, or a RANGE partition was added before an existing one.
+func partitionsDiff(from, to *Partition) (drop, add []*TablePartition, ok bool) {
+	t := strings.ToUpper(from.T)
+	if !partitionKeyEqual(from, to) || !strings.HasPrefix(t, PartitionTypeRange) && !strings.HasPrefix(t, PartitionTypeList) {
+		return nil, nil, false
+	}
+	bounds := make(map[string]string, len(to.Partitions))
+	for _, p := range to.Partitions {
+		bounds[p.Name] = p.Bound
+	}
+	var kept []string
+	for _, p := range from.Partitions {
+		b, ok := bounds[p.Name]
+		switch {
+		case !ok:
+			drop = append(drop, p)
+		case !partitionExprEqual(p.Bound, b):
+			return nil, nil, false
+		default:
+			kept = append(kept, p.Name)
+		}
+	}
+	// New partitions can be added only after the existing ones.
+	for i, p := range to.Partitions {
+		switch {
+		case i < len(kept) && kept[i] == p.Name:
+		case i < len(kept):
+			return nil, nil, false
+		default:
+			add = append(add, p)
+		}
+	}
+	return drop, add, len(drop) > 0 || len(add) > 0
+}
+
+// partitionBy writes the PARTITION BY clause of the given partitioning.
+func partitionBy(b *sqlx.Builder, p *Partition) {
+	b.P("PARTITION BY", p.T).Wrap(func(b *sqlx.Builder) {
+		b.P(p.Expr)
+	})
+	if p.Sub != nil {
+		b.P("SUBPARTITION BY", p.Sub.T).Wrap(func(b *sqlx.Builder) {
+			b.P(p.Sub.Expr)
+		})
+		if p.Sub.N > 0 {
+			b.P("SUBPARTITIONS", strconv.Itoa(p.Sub.N))
+		}
+	}
+	if len(p.Partitions) > 0 {
+		// Wrap does not separate the list from the preceding parentheses.
+		parts := &sqlx.Builder{QuoteChar: b.QuoteChar}
+		parts.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(p.Partitions, func(i int, b *sqlx.Builder) {
+				partition(b, p.Partitions[i])
+			})
+		})
+		b.P(parts.String())
+	}
+}
+
+// partition writes the definition of the given partition.
+func partition(b *sqlx.Builder, p *TablePartition) {
+	b.P("PARTITION").Ident(p.Name)
+	if p.Bound != "" {
+		b.P(p.Bound)
+	}
+}
+
 // alterTable modifies the given table by executing on it a list of
 // changes in one SQL statement.
 func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
@@ -377,6 +800,9 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				b.P("DROP COLUMN").Ident(change.C.Name)
 				reverse = append(reverse, &schema.AddColumn{C: change.C})
 			case *schema.AddIndex:
+				if err := s.checkIndex(change.I); err != nil {
+					return err
+				}
 				b.P("ADD")
 				index(b, change.I)
 				reverse = append(reverse, &schema.DropIndex{I: change.I})
@@ -386,6 +812,17 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 			case *schema.DropIndex:
 				b.P("DROP INDEX").Ident(change.I.Name)
 				reverse = append(reverse, &schema.AddIndex{I: change.I})
+			case *schema.ModifyIndex:
+				if err := s.checkIndex(change.To); err != nil {
+					return err
+				}
+				b.P("ALTER INDEX").Ident(change.To.Name)
+				if sqlx.Has(change.To.Attrs, &Invisible{}) {
+					b.P("INVISIBLE")
+				} else {
+					b.P("VISIBLE")
+				}
+				reverse = append(reverse, &schema.ModifyIndex{From: change.To, To: change.From, Change: change.Change})
 			case *schema.AddForeignKey:
 				b.P("ADD")
 				if err := s.fks(b, change.F); err != nil {
@@ -500,6 +937,9 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 		asX = sqlx.Has(c.Attrs, &x)
 	)
 	if asX {
+		if !s.SupportsGeneratedColumns() {
+			return s.unsupported(fmt.Sprintf("generated column %q", c.Name))
+		}
 		b.P("AS", sqlx.MayWrap(x.Expr), x.Type)
 	}
 	// MariaDB does not accept [NOT NULL | NULL]
@@ -533,6 +973,11 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 			}
 		case *OnUpdate:
 			b.P("ON UPDATE", a.A)
+		case *Invisible:
+			if !s.SupportsInvisibleColumn() {
+				return s.unsupported(fmt.Sprintf("invisible column %q", c.Name))
+			}
+			b.P("INVISIBLE")
 		case *AutoIncrement:
 			b.P("AUTO_INCREMENT")
 			// Auto increment with value should be configured on table options.
@@ -546,6 +991,26 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 	return nil
 }
 
+// checkIndex checks that the index does not use
+// features that are not supported by the target version.
+func (s *state) checkIndex(idx *schema.Index) error {
+	if sqlx.Has(idx.Attrs, &Invisible{}) && !s.SupportsInvisibleIndex() {
+		return s.unsupported(fmt.Sprintf("invisible index %q", idx.Name))
+	}
+	for _, p := range idx.Parts {
+		if p.X != nil && !s.SupportsIndexExpr() {
+			return s.unsupported(fmt.Sprintf("expression of index %q", idx.Name))
+		}
+	}
+	return nil
+}
+
+// unsupported returns an error for a feature that is not
+// supported by the version the changes are planned for.
+func (s *state) unsupported(feature string) error {
+	return fmt.Errorf("%s is not supported by the target version %s", feature, s.V)
+}
+
 func index(b *sqlx.Builder, idx *schema.Index) {
 	var t IndexType
 	if sqlx.Has(idx.Attrs, &t) {
@@ -566,6 +1031,17 @@ func index(b *sqlx.Builder, idx *schema.Index) {
 	if c := (schema.Comment{}); sqlx.Has(idx.Attrs, &c) {
 		b.P("COMMENT", quote(c.Text))
 	}
+	if sqlx.Has(idx.Attrs, &Invisible{}) {
+		b.P("INVISIBLE")
+	}
+}
+
+// visibilityChanged reports if the index modification
+// is limited to a change in the index visibility.
+func visibilityChanged(m *schema.ModifyIndex) bool {
+	return m.Change == schema.ChangeAttr &&
+		indexType(m.From.Attrs).T == indexType(m.To.Attrs).T &&
+		sqlx.Has(m.From.Attrs, &Invisible{}) != sqlx.Has(m.To.Attrs, &Invisible{})
 }
 
 func indexParts(b *sqlx.Builder, parts []*schema.IndexPart) {
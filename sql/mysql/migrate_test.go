@@ -211,6 +211,30 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyRealm{
+					R: schema.NewRealm(),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{From: &schema.Setting{Name: "sql_mode", V: "STRICT_TRANS_TABLES"}, To: &schema.Setting{Name: "sql_mode", V: "ANSI_QUOTES,STRICT_TRANS_TABLES"}},
+						&schema.AddAttr{A: &schema.Setting{Name: "time_zone", V: "+00:00"}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "SET GLOBAL sql_mode = 'ANSI_QUOTES,STRICT_TRANS_TABLES'",
+						Reverse: "SET GLOBAL sql_mode = 'STRICT_TRANS_TABLES'",
+					},
+					{
+						Cmd:     "SET GLOBAL time_zone = '+00:00'",
+						Reverse: "SET GLOBAL time_zone = DEFAULT",
+					},
+				},
+			},
+		},
 		{
 			changes: []schema.Change{
 				func() schema.Change {
@@ -445,6 +469,78 @@ func TestPlanChanges(t *testing.T) {
 				Changes:    []*migrate.Change{{Cmd: "CREATE TABLE `posts` (`id` bigint NOT NULL AUTO_INCREMENT, `text` text NULL, PRIMARY KEY (`id`)) AUTO_INCREMENT 10", Reverse: "DROP TABLE `posts`"}},
 			},
 		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("logs").
+						AddColumns(schema.NewIntColumn("id", "int")).
+						AddAttrs(&Partition{
+							T:    PartitionTypeRange,
+							Expr: "`id`",
+							Sub:  &SubPartition{T: PartitionTypeHash, Expr: "`id`", N: 2},
+							Partitions: []*TablePartition{
+								{Name: "p0", Bound: "VALUES LESS THAN (10)"},
+								{Name: "p1", Bound: "VALUES LESS THAN MAXVALUE"},
+							},
+						}),
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "CREATE TABLE `logs` (`id` int NOT NULL) PARTITION BY RANGE (`id`) SUBPARTITION BY HASH (`id`) SUBPARTITIONS 2 (PARTITION `p0` VALUES LESS THAN (10), PARTITION `p1` VALUES LESS THAN MAXVALUE)", Reverse: "DROP TABLE `logs`"}},
+			},
+		},
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					p := func(parts ...*TablePartition) *Partition {
+						return &Partition{T: PartitionTypeRange, Expr: "`id`", Partitions: parts}
+					}
+					return &schema.ModifyTable{
+						T: schema.NewTable("logs").AddColumns(schema.NewIntColumn("id", "int")),
+						Changes: []schema.Change{
+							&schema.AddColumn{C: schema.NewIntColumn("c", "int")},
+							&schema.ModifyAttr{
+								From: p(&TablePartition{Name: "p0", Bound: "VALUES LESS THAN (10)"}, &TablePartition{Name: "p1", Bound: "VALUES LESS THAN (20)"}),
+								To:   p(&TablePartition{Name: "p1", Bound: "VALUES LESS THAN (20)"}, &TablePartition{Name: "p2", Bound: "VALUES LESS THAN (30)"}),
+							},
+						},
+					}
+				}(),
+				&schema.ModifyTable{
+					T: schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &Partition{T: PartitionTypeRange, Expr: "`id`", Partitions: []*TablePartition{{Name: "p0", Bound: "VALUES LESS THAN (10)"}}},
+							To:   &Partition{T: PartitionTypeHash, Expr: "`id`", Partitions: []*TablePartition{{Name: "p0"}, {Name: "p1"}}},
+						},
+					},
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("tags").AddColumns(schema.NewIntColumn("id", "int")),
+					Changes: []schema.Change{
+						&schema.DropAttr{A: &Partition{T: PartitionTypeKey, Expr: "`id`"}},
+					},
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int")),
+					Changes: []schema.Change{
+						&schema.AddAttr{A: &Partition{T: PartitionTypeKey}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "ALTER TABLE `logs` ADD COLUMN `c` int NOT NULL", Reverse: "ALTER TABLE `logs` DROP COLUMN `c`"},
+					{Cmd: "ALTER TABLE `logs` DROP PARTITION `p0`", Reverse: "ALTER TABLE `logs` PARTITION BY RANGE (`id`) (PARTITION `p0` VALUES LESS THAN (10), PARTITION `p1` VALUES LESS THAN (20))"},
+					{Cmd: "ALTER TABLE `logs` ADD PARTITION (PARTITION `p2` VALUES LESS THAN (30))", Reverse: "ALTER TABLE `logs` DROP PARTITION `p2`"},
+					{Cmd: "ALTER TABLE `users` PARTITION BY HASH (`id`) (PARTITION `p0`, PARTITION `p1`)", Reverse: "ALTER TABLE `users` PARTITION BY RANGE (`id`) (PARTITION `p0` VALUES LESS THAN (10))"},
+					{Cmd: "ALTER TABLE `tags` REMOVE PARTITIONING", Reverse: "ALTER TABLE `tags` PARTITION BY KEY (`id`)"},
+					{Cmd: "ALTER TABLE `pets` PARTITION BY KEY ()", Reverse: "ALTER TABLE `pets` REMOVE PARTITIONING"},
+				},
+			},
+		},
 		{
 			changes: []schema.Change{
 				&schema.DropTable{T: &schema.Table{Name: "posts"}},
@@ -932,6 +1028,129 @@ func TestPlanChanges(t *testing.T) {
 				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
 			},
 			wantErr: true,
+		}, // Invisible columns and indexes.
+		{
+			changes: func() []schema.Change {
+				t := schema.NewTable("t").SetSchema(schema.New("d")).AddColumns(schema.NewIntColumn("a", "int"))
+				c := schema.NewIntColumn("b", "int").AddAttrs(&Invisible{})
+				idx := schema.NewIndex("a").AddColumns(t.Columns[0])
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: t,
+						Changes: []schema.Change{
+							&schema.AddColumn{C: c},
+							&schema.ModifyIndex{From: idx, To: schema.NewIndex("a").AddColumns(t.Columns[0]).AddAttrs(&Invisible{}), Change: schema.ChangeAttr},
+							&schema.AddIndex{I: schema.NewIndex("b").AddColumns(c).AddAttrs(&Invisible{})},
+						},
+					},
+				}
+			}(),
+			version: "8.0.23",
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `d`.`t` ADD COLUMN `b` int NOT NULL INVISIBLE, ALTER INDEX `a` INVISIBLE, ADD INDEX `b` (`b`) INVISIBLE",
+						Reverse: "ALTER TABLE `d`.`t` DROP INDEX `b`, ALTER INDEX `a` VISIBLE, DROP COLUMN `b`",
+					},
+				},
+			},
+		},
+		// Invisible columns are not supported by the connected version.
+		{
+			changes: func() []schema.Change {
+				t := schema.NewTable("t").SetSchema(schema.New("d")).AddColumns(schema.NewIntColumn("a", "int"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T:       t,
+						Changes: []schema.Change{&schema.AddColumn{C: schema.NewIntColumn("b", "int").AddAttrs(&Invisible{})}},
+					},
+				}
+			}(),
+			wantErr: true,
+		},
+		// Index expressions are not supported by the target version.
+		{
+			changes: func() []schema.Change {
+				t := schema.NewTable("t").SetSchema(schema.New("d")).AddColumns(schema.NewIntColumn("a", "int"))
+				t.AddIndexes(schema.NewIndex("a").AddExprs(&schema.RawExpr{X: "(a * 2)"}))
+				return []schema.Change{&schema.AddTable{T: t}}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.TargetVersion = "5.7.40" },
+			},
+			wantErr: true,
+		},
+		{
+			changes: func() []schema.Change {
+				t := schema.NewTable("t").SetSchema(schema.New("d")).AddColumns(schema.NewIntColumn("a", "int"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T:       t,
+						Changes: []schema.Change{&schema.AddColumn{C: schema.NewIntColumn("b", "int").AddAttrs(&Invisible{})}},
+					},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.TargetVersion = "8.0.31" },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `d`.`t` ADD COLUMN `b` int NOT NULL INVISIBLE",
+						Reverse: "ALTER TABLE `d`.`t` DROP COLUMN `b`",
+					},
+				},
+			},
+		},
+		// Routines are dropped and created again on modification.
+		{
+			changes: func() []schema.Change {
+				s := schema.New("d").
+					AddFuncs(
+						schema.NewFunc("add", "a int, b int", "int", "DETERMINISTIC RETURN a + b"),
+						schema.NewFunc("add", "a int, b int", "bigint", "DETERMINISTIC RETURN a + b"),
+					).
+					AddProcs(
+						schema.NewProc("archive", "IN id int", "BEGIN SELECT id; END"),
+						schema.NewProc("purge", "", "BEGIN DELETE FROM logs; END"),
+					)
+				return []schema.Change{
+					&schema.DropProc{P: s.Procs[1]},
+					&schema.ModifyFunc{From: s.Funcs[0], To: s.Funcs[1]},
+					&schema.AddProc{P: s.Procs[0]},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "DROP PROCEDURE `d`.`purge`",
+						Reverse: "CREATE PROCEDURE `d`.`purge`() BEGIN DELETE FROM logs; END",
+					},
+					{
+						Cmd:     "DROP FUNCTION `d`.`add`",
+						Reverse: "CREATE FUNCTION `d`.`add`(a int, b int) RETURNS int DETERMINISTIC RETURN a + b",
+					},
+					{
+						Cmd:     "CREATE FUNCTION `d`.`add`(a int, b int) RETURNS bigint DETERMINISTIC RETURN a + b",
+						Reverse: "DROP FUNCTION `d`.`add`",
+					},
+					{
+						Cmd:     "CREATE PROCEDURE `d`.`archive`(IN id int) BEGIN SELECT id; END",
+						Reverse: "DROP PROCEDURE `d`.`archive`",
+					},
+				},
+			},
+		},
+		// Malformed target version.
+		{
+			changes: []schema.Change{&schema.DropTable{T: schema.NewTable("t").SetSchema(schema.New("d"))}},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.TargetVersion = "eight" },
+			},
+			wantErr: true,
 		},
 	}
 	for i, tt := range tests {
@@ -5,20 +5,29 @@
 package mysqlcheck
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/mysql"
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/blocking"
 	"ariga.io/atlas/sql/sqlcheck/datadepend"
 	"ariga.io/atlas/sql/sqlcheck/destructive"
 )
 
-// codeImplicitUpdate is a MySQL specific code for reporting implicit update.
-var codeImplicitUpdate = sqlcheck.Code("MY101")
+// List of MySQL specific codes.
+var (
+	codeImplicitUpdate = sqlcheck.Code("MY101")
+	codeDropVisibleI   = sqlcheck.Code("MY102")
+)
 
 func addNotNull(p *datadepend.ColumnPass) (diags []sqlcheck.Diagnostic, err error) {
 	// Two types of reporting, implicit rows update and
@@ -128,6 +137,138 @@ func addNotNull(p *datadepend.ColumnPass) (diags []sqlcheck.Diagnostic, err erro
 	return
 }
 
+// InvisibleIndex suggests making indexes invisible before dropping them. An invisible
+// index is maintained, but not used by the optimizer. Hence, it can be dropped safely in
+// a later migration, or made visible again in case queries were affected by the change.
+type InvisibleIndex struct {
+	sqlcheck.Options
+}
+
+// NewInvisibleIndex creates a new InvisibleIndex analyzer with the given options.
+func NewInvisibleIndex(r *schemahcl.Resource) (*InvisibleIndex, error) {
+	az := &InvisibleIndex{}
+	if r, ok := r.Resource(az.Name()); ok {
+		if err := r.As(&az.Options); err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: parsing invisible_index check options: %w", err)
+		}
+	}
+	return az, nil
+}
+
+// Name of the analyzer. Implements the sqlcheck.NamedAnalyzer interface.
+func (*InvisibleIndex) Name() string {
+	return "invisible_index"
+}
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *InvisibleIndex) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	caps, err := migrate.DriverCapabilities(p.Dev.Driver, "")
+	if err != nil {
+		return err
+	}
+	// MariaDB and MySQL versions prior to 8 do not support invisible indexes.
+	if !caps.Is(migrate.CapInvisibleIndex) {
+		return nil
+	}
+	var diags []sqlcheck.Diagnostic
+	for _, sc := range p.File.Changes {
+		for _, c := range sc.Changes {
+			m, ok := c.(*schema.ModifyTable)
+			if !ok {
+				continue
+			}
+			for _, c := range m.Changes {
+				d, ok := c.(*schema.DropIndex)
+				if !ok || sqlx.Has(d.I.Attrs, &mysql.Invisible{}) || p.File.IndexSpan(m.T, d.I) == sqlcheck.SpanTemporary {
+					continue
+				}
+				diags = append(diags, sqlcheck.Diagnostic{
+					Code: codeDropVisibleI,
+					Pos:  sc.Stmt.Pos,
+					Text: fmt.Sprintf("Dropping visible index %q of table %q. Consider making it invisible before dropping it in a later migration", d.I.Name, m.T.Name),
+				})
+			}
+		}
+	}
+	if len(diags) > 0 {
+		const reportText = "visible indexes dropped"
+		p.Reporter.WriteReport(sqlcheck.Report{Text: reportText, Diagnostics: diags})
+		if sqlx.V(a.Error) {
+			return errors.New(reportText)
+		}
+	}
+	return nil
+}
+
+// reOnlineAlter matches statements that explicitly use an online
+// algorithm. MySQL fails these statements if the algorithm is not
+// supported for the change, instead of copying the table.
+var reOnlineAlter = regexp.MustCompile(`(?i)\bALGORITHM\s*=?\s*(INPLACE|INSTANT)\b`)
+
+// onlineFix suggests executing changes that copy the table using an online schema change tool.
+var onlineFix = sqlcheck.SuggestedFix{
+	Message: "Use an online schema change tool (e.g. gh-ost or pt-online-schema-change) for large tables",
+}
+
+func modifyBlocking(p *blocking.ColumnPass) ([]sqlcheck.Diagnostic, error) {
+	from, err := mysql.FormatType(p.From.Type.Type)
+	if err != nil {
+		return nil, err
+	}
+	to, err := mysql.FormatType(p.Column.Type.Type)
+	if err != nil {
+		return nil, err
+	}
+	if from == to || varcharExtended(p.From, p.Column) {
+		return nil, nil
+	}
+	return []sqlcheck.Diagnostic{
+		{
+			Pos:            p.Change.Stmt.Pos,
+			Text:           fmt.Sprintf("Changing the type of column %q of table %q from %q to %q copies the table and blocks writes until it is done", p.Column.Name, p.Table.Name, from, to),
+			SuggestedFixes: []sqlcheck.SuggestedFix{onlineFix},
+		},
+	}, nil
+}
+
+// varcharExtended reports if the column size was extended in-place. MySQL
+// supports it as long as the number of length bytes remains the same.
+func varcharExtended(from, to *schema.Column) bool {
+	f, ok1 := from.Type.Type.(*schema.StringType)
+	t, ok2 := to.Type.Type.(*schema.StringType)
+	return ok1 && ok2 && f.T == mysql.TypeVarchar && t.T == mysql.TypeVarchar &&
+		f.Size <= t.Size && (f.Size < 256) == (t.Size < 256)
+}
+
+func addColumnBlocking(p *blocking.ColumnPass) ([]sqlcheck.Diagnostic, error) {
+	if x := (schema.GeneratedExpr{}); !sqlx.Has(p.Column.Attrs, &x) || strings.ToUpper(x.Type) != "STORED" {
+		return nil, nil
+	}
+	return []sqlcheck.Diagnostic{
+		{
+			Pos:  p.Change.Stmt.Pos,
+			Text: fmt.Sprintf("Adding stored generated column %q to table %q copies the table and blocks writes until it is done", p.Column.Name, p.Table.Name),
+			SuggestedFixes: []sqlcheck.SuggestedFix{
+				{Message: "Add the column as a VIRTUAL generated column, or use an online schema change tool for large tables"},
+			},
+		},
+	}, nil
+}
+
+func addIndexBlocking(p *blocking.IndexPass) ([]sqlcheck.Diagnostic, error) {
+	t := &mysql.IndexType{}
+	if !sqlx.Has(p.Index.Attrs, t) || (t.T != mysql.IndexTypeFullText && t.T != mysql.IndexTypeSpatial) {
+		return nil, nil
+	}
+	return []sqlcheck.Diagnostic{
+		{
+			Pos:            p.Change.Stmt.Pos,
+			Text:           fmt.Sprintf("Adding %s index %q on table %q blocks writes to the table until it is built", t.T, p.Index.Name, p.Table.Name),
+			SuggestedFixes: []sqlcheck.SuggestedFix{onlineFix},
+		},
+	}, nil
+}
+
 func init() {
 	sqlcheck.Register(mysql.DriverName, func(r *schemahcl.Resource) ([]sqlcheck.Analyzer, error) {
 		ds, err := destructive.New(r)
@@ -140,6 +281,19 @@ func init() {
 		if err != nil {
 			return nil, err
 		}
-		return []sqlcheck.Analyzer{ds, dd}, nil
+		ii, err := NewInvisibleIndex(r)
+		if err != nil {
+			return nil, err
+		}
+		bc, err := blocking.New(r, blocking.Handler{
+			Online:       reOnlineAlter.MatchString,
+			AddColumn:    addColumnBlocking,
+			ModifyColumn: modifyBlocking,
+			AddIndex:     addIndexBlocking,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []sqlcheck.Analyzer{ds, dd, ii, bc}, nil
 	})
 }
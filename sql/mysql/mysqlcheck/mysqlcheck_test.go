@@ -231,6 +231,159 @@ func TestDataDepend_Maria_ImplicitUpdate(t *testing.T) {
 
 }
 
+func TestInvisibleIndex(t *testing.T) {
+	drv := &mysql.Driver{}
+	drv.V = "8.0.19"
+	var (
+		report *sqlcheck.Report
+		users  = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(
+				schema.NewIntColumn("a", mysql.TypeInt),
+				schema.NewIntColumn("b", mysql.TypeInt),
+			)
+		pass = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{
+				Name:   "mysql",
+				Driver: drv,
+			},
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: &migrate.Stmt{
+							Text: "ALTER TABLE users",
+						},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: []schema.Change{
+									&schema.DropIndex{I: schema.NewIndex("a").AddColumns(users.Columns[0])},
+									&schema.DropIndex{I: schema.NewIndex("b").AddColumns(users.Columns[1]).AddAttrs(&mysql.Invisible{})},
+								},
+							},
+						},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	azs, err := sqlcheck.AnalyzerFor(mysql.DriverName, nil)
+	require.NoError(t, err)
+	require.NoError(t, sqlcheck.Analyzers(azs).Analyze(context.Background(), pass))
+	require.Equal(t, "visible indexes dropped", report.Text)
+	require.Len(t, report.Diagnostics, 1)
+	require.Equal(t, "MY102", report.Diagnostics[0].Code)
+	require.Equal(t, `Dropping visible index "a" of table "users". Consider making it invisible before dropping it in a later migration`, report.Diagnostics[0].Text)
+
+	// Invisible indexes are not supported by MariaDB.
+	report = nil
+	drv.V = "10.7.1-MariaDB"
+	require.NoError(t, sqlcheck.Analyzers(azs).Analyze(context.Background(), pass))
+	require.Nil(t, report)
+}
+
+func TestBlocking(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		users  = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(
+				schema.NewIntColumn("id", mysql.TypeInt),
+				schema.NewStringColumn("name", mysql.TypeVarchar, schema.StringSize(100)),
+			)
+		pass = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{Name: "mysql", Driver: &mysql.Driver{}},
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE users MODIFY COLUMN id bigint, MODIFY COLUMN name varchar(200)", Pos: 1},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: []schema.Change{
+									&schema.ModifyColumn{
+										From:   schema.NewIntColumn("id", mysql.TypeInt),
+										To:     schema.NewIntColumn("id", mysql.TypeBigInt),
+										Change: schema.ChangeType,
+									},
+									// Extending a VARCHAR column is done in-place.
+									&schema.ModifyColumn{
+										From:   schema.NewStringColumn("name", mysql.TypeVarchar, schema.StringSize(100)),
+										To:     schema.NewStringColumn("name", mysql.TypeVarchar, schema.StringSize(200)),
+										Change: schema.ChangeType,
+									},
+								},
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE users ADD COLUMN c int AS (id * 2) STORED, ADD FULLTEXT INDEX ft (name)", Pos: 2},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: []schema.Change{
+									&schema.AddColumn{C: schema.NewIntColumn("c", mysql.TypeInt).SetGeneratedExpr(&schema.GeneratedExpr{Expr: "id * 2", Type: "STORED"})},
+									&schema.AddIndex{I: schema.NewIndex("ft").AddColumns(users.Columns[1]).AddAttrs(&mysql.IndexType{T: mysql.IndexTypeFullText})},
+								},
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE users MODIFY COLUMN id bigint, ALGORITHM=INPLACE", Pos: 3},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: []schema.Change{
+									&schema.ModifyColumn{
+										From:   schema.NewIntColumn("id", mysql.TypeInt),
+										To:     schema.NewIntColumn("id", mysql.TypeBigInt),
+										Change: schema.ChangeType,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	azs, err := sqlcheck.AnalyzerFor(mysql.DriverName, nil)
+	require.NoError(t, err)
+	require.NoError(t, azs[3].Analyze(context.Background(), pass))
+	require.Equal(t, "blocking changes detected", report.Text)
+	fix := sqlcheck.SuggestedFix{Message: "Use an online schema change tool (e.g. gh-ost or pt-online-schema-change) for large tables"}
+	require.Equal(t, []sqlcheck.Diagnostic{
+		{
+			Pos:            1,
+			Code:           "BC101",
+			Text:           `Changing the type of column "id" of table "users" from "int" to "bigint" copies the table and blocks writes until it is done`,
+			SuggestedFixes: []sqlcheck.SuggestedFix{fix},
+		},
+		{
+			Pos:  2,
+			Code: "BC102",
+			Text: `Adding stored generated column "c" to table "users" copies the table and blocks writes until it is done`,
+			SuggestedFixes: []sqlcheck.SuggestedFix{
+				{Message: "Add the column as a VIRTUAL generated column, or use an online schema change tool for large tables"},
+			},
+		},
+		{
+			Pos:            2,
+			Code:           "BC103",
+			Text:           `Adding FULLTEXT index "ft" on table "users" blocks writes to the table until it is built`,
+			SuggestedFixes: []sqlcheck.SuggestedFix{fix},
+		},
+	}, report.Diagnostics)
+}
+
 type testFile struct {
 	name string
 	migrate.File
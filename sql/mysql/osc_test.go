@@ -0,0 +1,144 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlter(t *testing.T) {
+	for _, tt := range []struct {
+		stmt  string
+		alter *alterStmt
+	}{
+		{stmt: "CREATE TABLE t (c int);"},
+		{stmt: "ALTER TABLE t RENAME TO t2;"},
+		{stmt: "ALTER TABLE t RENAME `t2`;"},
+		{stmt: "ALTER TABLE t ADD COLUMN c int, RENAME AS t2;"},
+		{
+			stmt:  "ALTER TABLE `users` ADD COLUMN `c` int NOT NULL;",
+			alter: &alterStmt{table: "users", spec: "ADD COLUMN `c` int NOT NULL"},
+		},
+		{
+			stmt:  "alter table `test`.`users`\n  DROP INDEX `i`, RENAME COLUMN `a` TO `b`",
+			alter: &alterStmt{schema: "test", table: "users", spec: "DROP INDEX `i`, RENAME COLUMN `a` TO `b`"},
+		},
+	} {
+		t.Run(tt.stmt, func(t *testing.T) {
+			a, ok := parseAlter(tt.stmt)
+			require.Equal(t, tt.alter != nil, ok)
+			require.Equal(t, tt.alter, a)
+		})
+	}
+}
+
+func TestOnlineSchemaChange(t *testing.T) {
+	var (
+		ran []string
+		ctx = context.Background()
+		f   = migrate.NewLocalFile("1.sql", nil)
+		u   = &url.URL{Scheme: "mysql", User: url.UserPassword("root", "pass"), Host: "localhost:3306", Path: "/test"}
+	)
+	// conf replaces the path of the defaults file with a
+	// placeholder, after checking the password it holds.
+	conf := func(a, prefix string) string {
+		if !strings.HasPrefix(a, prefix) {
+			return a
+		}
+		b, err := os.ReadFile(strings.TrimPrefix(a, prefix))
+		require.NoError(t, err)
+		require.Equal(t, "[client]\npassword=\"pass\"\n", string(b))
+		return prefix + "<conf>"
+	}
+	run := func(_ context.Context, name string, args ...string) error {
+		for i, a := range args {
+			// The DSN of pt-online-schema-change.
+			if strings.HasPrefix(a, "h=") {
+				parts := strings.Split(a, ",")
+				for j := range parts {
+					parts[j] = conf(parts[j], "F=")
+				}
+				args[i] = strings.Join(parts, ",")
+			}
+			args[i] = conf(args[i], "--conf=")
+		}
+		ran = append(ran, name+" "+strings.Join(args, " "))
+		return nil
+	}
+	osc := &OnlineSchemaChange{Tool: GhOst, URL: u, CutOver: "two-step", Tables: []string{"users"}, run: run}
+	stmts, err := osc.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: "ALTER TABLE `users` ADD COLUMN `c` int;"})
+	require.NoError(t, err)
+	require.Empty(t, stmts)
+	require.Equal(t, []string{"gh-ost --host=localhost --port=3306 --user=root --conf=<conf> --database=test --table=users --alter=ADD COLUMN `c` int --cut-over=two-step --execute"}, ran)
+
+	// Not a qualifying table or statement.
+	ran = nil
+	for _, s := range []string{"ALTER TABLE `pets` ADD COLUMN `c` int;", "CREATE TABLE `users` (`c` int);"} {
+		stmts, err = osc.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: s})
+		require.NoError(t, err)
+		require.Equal(t, []string{s}, stmts)
+	}
+	require.Empty(t, ran)
+
+	// pt-online-schema-change.
+	osc = &OnlineSchemaChange{Tool: PTOnlineSchemaChange, Path: "/usr/bin/pt-online-schema-change", URL: u, CutOver: "none", Args: []string{"--chunk-time=1"}, run: run}
+	_, err = osc.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: "ALTER TABLE `other`.`users` DROP COLUMN `c`;"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/usr/bin/pt-online-schema-change --alter=DROP COLUMN `c` --no-swap-tables --no-drop-new-table --no-drop-triggers --chunk-time=1 --execute h=localhost,P=3306,u=root,F=<conf>,D=other,t=users"}, ran)
+
+	// Tool failures abort the execution.
+	osc.run = func(context.Context, string, ...string) error { return errors.New("exit status 1") }
+	_, err = osc.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: "ALTER TABLE `users` DROP COLUMN `c`;"})
+	require.EqualError(t, err, `sql/mysql: pt-online-schema-change: alter table "users": exit status 1`)
+
+	osc = &OnlineSchemaChange{Tool: "unknown", URL: u, run: run}
+	_, err = osc.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: "ALTER TABLE `users` DROP COLUMN `c`;"})
+	require.EqualError(t, err, `sql/mysql: unsupported online schema change tool "unknown"`)
+}
+
+func TestOnlineSchemaChange_MinTableSize(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	var (
+		ran int
+		ctx = context.Background()
+		f   = migrate.NewLocalFile("1.sql", nil)
+		osc = &OnlineSchemaChange{
+			Tool:         GhOst,
+			URL:          &url.URL{Scheme: "mysql", Host: "localhost", Path: "/test"},
+			Conn:         db,
+			MinTableSize: 1 << 30,
+			run: func(context.Context, string, ...string) error {
+				ran++
+				return nil
+			},
+		}
+	)
+	m.ExpectQuery(sqltest.Escape(tableSizeQuery)).
+		WithArgs("test", "small").
+		WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(1 << 20))
+	m.ExpectQuery(sqltest.Escape(tableSizeQuery)).
+		WithArgs("test", "large").
+		WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(1 << 40))
+	stmts, err := osc.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: "ALTER TABLE `small` ADD COLUMN `c` int;"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"ALTER TABLE `small` ADD COLUMN `c` int;"}, stmts)
+	stmts, err = osc.BeforeExec(ctx, &migrate.HookStmt{File: f, Stmt: "ALTER TABLE `large` ADD COLUMN `c` int;"})
+	require.NoError(t, err)
+	require.Empty(t, stmts)
+	require.Equal(t, 1, ran)
+	require.NoError(t, m.ExpectationsWereMet())
+}
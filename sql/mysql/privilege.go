@@ -0,0 +1,155 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+const (
+	// Query the current user in the format of the GRANTEE column of the
+	// information schema (e.g. 'user'@'host'), and the connected schema.
+	currentUserQuery = "SELECT CONCAT('''', SUBSTRING_INDEX(CURRENT_USER(), '@', 1), '''@''', SUBSTRING_INDEX(CURRENT_USER(), '@', -1), ''''), IFNULL(DATABASE(), '')"
+
+	// Query the global, schema and table privileges granted to the given user.
+	grantsQuery = `
+SELECT 'global', '', '', PRIVILEGE_TYPE FROM INFORMATION_SCHEMA.USER_PRIVILEGES WHERE GRANTEE = ?
+UNION ALL
+SELECT 'schema', TABLE_SCHEMA, '', PRIVILEGE_TYPE FROM INFORMATION_SCHEMA.SCHEMA_PRIVILEGES WHERE GRANTEE = ?
+UNION ALL
+SELECT 'table', TABLE_SCHEMA, TABLE_NAME, PRIVILEGE_TYPE FROM INFORMATION_SCHEMA.TABLE_PRIVILEGES WHERE GRANTEE = ?
+`
+)
+
+// A grant is a privilege granted to the connected user, globally,
+// on a schema (or a schema pattern), or on a table.
+type grant struct {
+	scope, schema, table, priv string
+}
+
+// MissingPrivileges implements migrate.PrivilegeChecker. The privileges of the connected user
+// are read from the information schema, and privileges granted using roles are not considered.
+func (d *Driver) MissingPrivileges(ctx context.Context, changes []schema.Change) ([]*migrate.Privilege, error) {
+	acts := sqlx.PrivActions(changes)
+	if len(acts) == 0 {
+		return nil, nil
+	}
+	rows, err := d.QueryContext(ctx, currentUserQuery)
+	if err != nil {
+		return nil, fmt.Errorf("mysql: querying current user: %w", err)
+	}
+	var user, current string
+	if err := sqlx.ScanOne(rows, &user, &current); err != nil {
+		return nil, fmt.Errorf("mysql: scanning current user: %w", err)
+	}
+	if rows, err = d.QueryContext(ctx, grantsQuery, user, user, user); err != nil {
+		return nil, fmt.Errorf("mysql: querying privileges: %w", err)
+	}
+	defer rows.Close()
+	var grants []*grant
+	for rows.Next() {
+		g := &grant{}
+		if err := rows.Scan(&g.scope, &g.schema, &g.table, &g.priv); err != nil {
+			return nil, fmt.Errorf("mysql: scanning privileges: %w", err)
+		}
+		grants = append(grants, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var (
+		missing []*migrate.Privilege
+		seen    = make(map[string]bool)
+	)
+	for _, a := range acts {
+		s := a.Schema
+		if s == "" {
+			s = current
+		}
+		for _, name := range privNames(a.Kind) {
+			p := &migrate.Privilege{Name: name, Object: fmt.Sprintf("schema %q", s), Reason: a.Reason}
+			if a.Table != "" && a.Kind != sqlx.PrivCreateTable {
+				p.Object = fmt.Sprintf("table %q.%q", s, a.Table)
+			}
+			if key := p.Name + p.Object; !seen[key] && !granted(grants, name, s, a.Table) {
+				seen[key] = true
+				missing = append(missing, p)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// privNames returns the MySQL privileges required for the action kind.
+func privNames(k sqlx.PrivActionKind) []string {
+	switch k {
+	case sqlx.PrivCreateSchema, sqlx.PrivCreateTable:
+		return []string{"CREATE"}
+	case sqlx.PrivDropSchema, sqlx.PrivDropTable:
+		return []string{"DROP"}
+	case sqlx.PrivAlterSchema, sqlx.PrivAlterTable:
+		return []string{"ALTER"}
+	case sqlx.PrivIndex:
+		return []string{"INDEX"}
+	case sqlx.PrivReferences:
+		return []string{"REFERENCES"}
+	case sqlx.PrivRenameFrom:
+		return []string{"ALTER", "DROP"}
+	case sqlx.PrivRenameTo:
+		return []string{"CREATE", "INSERT"}
+	default:
+		return nil
+	}
+}
+
+// granted reports if the privilege is granted on the given schema or table.
+func granted(grants []*grant, priv, s, t string) bool {
+	for _, g := range grants {
+		if g.priv != priv {
+			continue
+		}
+		switch g.scope {
+		case "global":
+			return true
+		case "schema":
+			if likeMatch(g.schema, s) {
+				return true
+			}
+		case "table":
+			if t != "" && g.schema == s && g.table == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// likeMatch reports if the name matches the schema pattern of a
+// grant, which may contain the LIKE wildcards "_" and "%".
+func likeMatch(pattern, name string) bool {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case c == '_':
+			b.WriteString(".")
+		case c == '%':
+			b.WriteString(".*")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	match, err := regexp.MatchString("^"+b.String()+"$", name)
+	return err == nil && match
+}
@@ -19,8 +19,14 @@ import (
 )
 
 type doc struct {
-	Tables  []*sqlspec.Table  `spec:"table"`
-	Schemas []*sqlspec.Schema `spec:"schema"`
+	Tables    []*sqlspec.Table     `spec:"table"`
+	Views     []*sqlspec.View      `spec:"view"`
+	Triggers  []*sqlspec.Trigger   `spec:"trigger"`
+	Funcs     []*sqlspec.Func      `spec:"function"`
+	Procs     []*sqlspec.Proc      `spec:"procedure"`
+	Schemas   []*sqlspec.Schema    `spec:"schema"`
+	Settings  []*sqlspec.Setting   `spec:"setting"`
+	Unmanaged []*sqlspec.Unmanaged `spec:"unmanaged"`
 }
 
 // evalSpec evaluates an Atlas DDL document into v using the input.
@@ -44,6 +50,22 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]string) error {
 				return err
 			}
 		}
+		if err := specutil.Views(v, d.Views); err != nil {
+			return err
+		}
+		if err := specutil.Funcs(v, d.Funcs); err != nil {
+			return err
+		}
+		if err := specutil.Procs(v, d.Procs); err != nil {
+			return err
+		}
+		if err := specutil.Triggers(v, d.Triggers); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(v, d.Unmanaged); err != nil {
+			return err
+		}
+		v.Attrs = append(v.Attrs, specutil.Settings(d.Settings)...)
 	case *schema.Schema:
 		if len(d.Schemas) != 1 {
 			return fmt.Errorf("mysql: expecting document to contain a single schema, got %d", len(d.Schemas))
@@ -55,6 +77,21 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]string) error {
 		if err := convertCharset(d.Schemas[0], &r.Schemas[0].Attrs); err != nil {
 			return err
 		}
+		if err := specutil.Views(&r, d.Views); err != nil {
+			return err
+		}
+		if err := specutil.Funcs(&r, d.Funcs); err != nil {
+			return err
+		}
+		if err := specutil.Procs(&r, d.Procs); err != nil {
+			return err
+		}
+		if err := specutil.Triggers(&r, d.Triggers); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(&r, d.Unmanaged); err != nil {
+			return err
+		}
 		r.Schemas[0].Realm = nil
 		*v = *r.Schemas[0]
 	default:
@@ -73,6 +110,8 @@ var (
 		schemahcl.WithTypes(TypeRegistry.Specs()),
 		schemahcl.WithScopedEnums("table.index.type", IndexTypeBTree, IndexTypeHash, IndexTypeFullText, IndexTypeSpatial),
 		schemahcl.WithScopedEnums("table.column.as.type", stored, persistent, virtual),
+		schemahcl.WithScopedEnums("table.partition.type", PartitionTypeRange, PartitionTypeList, PartitionTypeHash, PartitionTypeKey),
+		schemahcl.WithScopedEnums("table.partition.subpartition.type", PartitionTypeHash, PartitionTypeKey),
 		schemahcl.WithScopedEnums("table.foreign_key.on_update", specutil.ReferenceVars...),
 		schemahcl.WithScopedEnums("table.foreign_key.on_delete", specutil.ReferenceVars...),
 	)
@@ -108,9 +147,60 @@ func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, er
 		}
 		t.AddAttrs(&AutoIncrement{V: v})
 	}
+	if r, ok := spec.Extra.Resource("partition"); ok {
+		p, err := convertPartition(r, t.Name+".partition")
+		if err != nil {
+			return nil, err
+		}
+		t.AddAttrs(p)
+	}
 	return t, err
 }
 
+// convertPartition converts the partition block into the partitioning of the table.
+func convertPartition(r *schemahcl.Resource, path string) (*Partition, error) {
+	var spec struct {
+		Type string `spec:"type"`
+		Expr string `spec:"expr"`
+		Sub  *struct {
+			Type  string `spec:"type"`
+			Expr  string `spec:"expr"`
+			Count int    `spec:"count"`
+		} `spec:"subpartition"`
+	}
+	if err := r.As(&spec); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if spec.Type == "" {
+		return nil, fmt.Errorf("missing attribute %s.type", path)
+	}
+	// Only KEY partitioning can be defined without
+	// expression, and then the primary key is used.
+	if t := strings.ToUpper(spec.Type); spec.Expr == "" && !strings.HasSuffix(t, PartitionTypeKey) {
+		return nil, fmt.Errorf("missing attribute %s.expr", path)
+	}
+	p := &Partition{T: spec.Type, Expr: spec.Expr}
+	if spec.Sub != nil {
+		if spec.Sub.Type == "" {
+			return nil, fmt.Errorf("missing attribute %s.subpartition.type", path)
+		}
+		p.Sub = &SubPartition{T: spec.Sub.Type, Expr: spec.Sub.Expr, N: spec.Sub.Count}
+	}
+	for _, c := range r.Children {
+		if c.Type != "part" {
+			continue
+		}
+		var part struct {
+			Bound string `spec:"bound"`
+		}
+		if err := c.As(&part); err != nil {
+			return nil, fmt.Errorf("parsing %s.part.%s: %w", path, c.Name, err)
+		}
+		p.Partitions = append(p.Partitions, &TablePartition{Name: c.Name, Bound: part.Bound})
+	}
+	return p, nil
+}
+
 // convertIndex converts a sqlspec.Index into a schema.Index.
 func convertIndex(spec *sqlspec.Index, parent *schema.Table) (*schema.Index, error) {
 	idx, err := specutil.Index(spec, parent, convertPart)
@@ -124,6 +214,9 @@ func convertIndex(spec *sqlspec.Index, parent *schema.Table) (*schema.Index, err
 		}
 		idx.AddAttrs(&IndexType{T: t})
 	}
+	if err := convertInvisible(spec, &idx.Attrs); err != nil {
+		return nil, err
+	}
 	return idx, nil
 }
 
@@ -182,6 +275,9 @@ func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error
 			c.AddAttrs(&AutoIncrement{})
 		}
 	}
+	if err := convertInvisible(spec, &c.Attrs); err != nil {
+		return nil, err
+	}
 	if err := specutil.ConvertGenExpr(spec.Remain(), c, storedOrVirtual); err != nil {
 		return nil, err
 	}
@@ -227,9 +323,53 @@ func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 	if c, ok := hasCollate(t.Attrs, t.Schema.Attrs); ok {
 		ts.Extra.Attrs = append(ts.Extra.Attrs, specutil.StrAttr("collate", c))
 	}
+	if p, ok := partitionOf(t.Attrs); ok {
+		ts.Extra.Children = append(ts.Extra.Children, partitionSpec(p))
+	}
 	return ts, nil
 }
 
+// partitionSpec returns the resource spec for representing the partitioning of a table.
+func partitionSpec(p *Partition) *schemahcl.Resource {
+	spec := &schemahcl.Resource{
+		Type:  "partition",
+		Attrs: []*schemahcl.Attr{partitionType(p.T)},
+	}
+	if p.Expr != "" {
+		spec.Attrs = append(spec.Attrs, specutil.StrAttr("expr", p.Expr))
+	}
+	if p.Sub != nil {
+		sub := &schemahcl.Resource{
+			Type:  "subpartition",
+			Attrs: []*schemahcl.Attr{partitionType(p.Sub.T)},
+		}
+		if p.Sub.Expr != "" {
+			sub.Attrs = append(sub.Attrs, specutil.StrAttr("expr", p.Sub.Expr))
+		}
+		if p.Sub.N > 0 {
+			sub.Attrs = append(sub.Attrs, specutil.IntAttr("count", p.Sub.N))
+		}
+		spec.Children = append(spec.Children, sub)
+	}
+	for _, tp := range p.Partitions {
+		part := &schemahcl.Resource{Type: "part", Name: tp.Name}
+		if tp.Bound != "" {
+			part.Attrs = append(part.Attrs, specutil.StrAttr("bound", tp.Bound))
+		}
+		spec.Children = append(spec.Children, part)
+	}
+	return spec
+}
+
+// partitionType returns the type attribute of a partition block. Types that
+// are composed of multiple words (e.g. "RANGE COLUMNS") are written as strings.
+func partitionType(t string) *schemahcl.Attr {
+	if t = strings.ToUpper(t); strings.Contains(t, " ") {
+		return specutil.StrAttr("type", t)
+	}
+	return specutil.VarAttr("type", t)
+}
+
 func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 	spec, err := specutil.FromIndex(idx, partAttr)
 	if err != nil {
@@ -239,6 +379,9 @@ func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 	if i := (IndexType{}); sqlx.Has(idx.Attrs, &i) && i.T != IndexTypeBTree {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("type", strings.ToUpper(i.T)))
 	}
+	if sqlx.Has(idx.Attrs, &Invisible{}) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("invisible", true))
+	}
 	return spec, nil
 }
 
@@ -266,6 +409,9 @@ func columnSpec(c *schema.Column, t *schema.Table) (*sqlspec.Column, error) {
 	if sqlx.Has(c.Attrs, &AutoIncrement{}) {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("auto_increment", true))
 	}
+	if sqlx.Has(c.Attrs, &Invisible{}) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("invisible", true))
+	}
 	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
 		spec.Extra.Children = append(spec.Extra.Children, specutil.FromGenExpr(x, storedOrVirtual))
 	}
@@ -313,6 +459,22 @@ func columnTypeSpec(t schema.Type) (*sqlspec.Column, error) {
 
 // convertCharset converts spec charset/collation
 // attributes to schema element attributes.
+// convertInvisible converts the "invisible" attribute of columns and indexes.
+func convertInvisible(spec specutil.Attrer, attrs *[]schema.Attr) error {
+	attr, ok := spec.Attr("invisible")
+	if !ok {
+		return nil
+	}
+	b, err := attr.Bool()
+	if err != nil {
+		return err
+	}
+	if b {
+		*attrs = append(*attrs, &Invisible{})
+	}
+	return nil
+}
+
 func convertCharset(spec specutil.Attrer, attrs *[]schema.Attr) error {
 	if attr, ok := spec.Attr("charset"); ok {
 		s, err := attr.String()
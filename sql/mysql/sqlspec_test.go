@@ -502,6 +502,45 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestMarshalSpec_Invisible(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(
+					schema.NewIntColumn("id", TypeInt),
+					schema.NewIntColumn("age", TypeInt).AddAttrs(&Invisible{}),
+				),
+		)
+	s.Tables[0].AddIndexes(schema.NewIndex("age").AddColumns(s.Tables[0].Columns[1]).AddAttrs(&Invisible{}))
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  column "age" {
+    null      = false
+    type      = int
+    invisible = true
+  }
+  index "age" {
+    columns   = [column.age]
+    invisible = true
+  }
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, []schema.Attr{&Invisible{}}, got.Tables[0].Columns[1].Attrs)
+	require.Equal(t, []schema.Attr{&Invisible{}}, got.Tables[0].Indexes[0].Attrs)
+}
+
 func TestMarshalSpec_Check(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -541,6 +580,102 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestMarshalSpec_Partition(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("logs").
+				AddColumns(
+					schema.NewIntColumn("id", TypeInt),
+					schema.NewStringColumn("region", TypeVarchar, schema.StringSize(8)),
+				).
+				AddAttrs(&Partition{
+					T:    "RANGE",
+					Expr: "`id`",
+					Sub:  &SubPartition{T: "HASH", Expr: "`id`", N: 2},
+					Partitions: []*TablePartition{
+						{Name: "p0", Bound: "VALUES LESS THAN (10)"},
+						{Name: "p1", Bound: "VALUES LESS THAN MAXVALUE"},
+					},
+				}),
+			schema.NewTable("users").
+				AddColumns(
+					schema.NewStringColumn("region", TypeVarchar, schema.StringSize(8)),
+				).
+				AddAttrs(&Partition{
+					T:    "LIST COLUMNS",
+					Expr: "`region`",
+					Partitions: []*TablePartition{
+						{Name: "eu", Bound: "VALUES IN ('eu','uk')"},
+					},
+				}),
+		)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "logs" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  column "region" {
+    null = false
+    type = varchar(8)
+  }
+  partition {
+    type = RANGE
+    expr = "` + "`id`" + `"
+    subpartition {
+      type  = HASH
+      expr  = "` + "`id`" + `"
+      count = 2
+    }
+    part "p0" {
+      bound = "VALUES LESS THAN (10)"
+    }
+    part "p1" {
+      bound = "VALUES LESS THAN MAXVALUE"
+    }
+  }
+}
+table "users" {
+  schema = schema.test
+  column "region" {
+    null = false
+    type = varchar(8)
+  }
+  partition {
+    type = "LIST COLUMNS"
+    expr = "` + "`region`" + `"
+    part "eu" {
+      bound = "VALUES IN ('eu','uk')"
+    }
+  }
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, s.Tables[0].Attrs, got.Tables[0].Attrs)
+	require.Equal(t, s.Tables[1].Attrs, got.Tables[1].Attrs)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "logs" {
+  schema = schema.test
+  column "id" {
+    type = int
+  }
+  partition {
+    type = HASH
+  }
+}
+`), &got, nil)
+	require.EqualError(t, err, "missing attribute logs.partition.expr")
+}
+
 func TestUnmarshalSpec_IndexParts(t *testing.T) {
 	var (
 		s schema.Schema
@@ -1368,3 +1503,25 @@ schema "s2" {
 `,
 		string(got))
 }
+
+func TestRealm_Settings(t *testing.T) {
+	f := `
+schema "s1" {
+}
+setting "sql_mode" {
+  value = "STRICT_TRANS_TABLES"
+}
+setting "time_zone" {
+  value = "+00:00"
+}
+`
+	var r schema.Realm
+	require.NoError(t, EvalHCLBytes([]byte(f), &r, nil))
+	require.Equal(t, []schema.Attr{
+		&schema.Setting{Name: "sql_mode", V: "STRICT_TRANS_TABLES"},
+		&schema.Setting{Name: "time_zone", V: "+00:00"},
+	}, r.Attrs)
+	got, err := MarshalHCL.MarshalSpec(&r)
+	require.NoError(t, err)
+	require.Equal(t, f[1:], string(got))
+}
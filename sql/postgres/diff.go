@@ -8,7 +8,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
@@ -17,13 +19,57 @@ import (
 	"ariga.io/atlas/sql/schema"
 )
 
+// IdentCase returns the identifier case options of PostgreSQL. Identifiers are
+// case-sensitive, as Atlas quotes them. If fold is true, the identifiers of the
+// desired state are folded to lower case, as if they were written unquoted.
+func IdentCase(fold bool) *schema.IdentCaseOptions {
+	if !fold {
+		return &schema.IdentCaseOptions{}
+	}
+	return &schema.IdentCaseOptions{
+		Schemas: schema.IdentCaseLower,
+		Tables:  schema.IdentCaseLower,
+		Columns: schema.IdentCaseLower,
+	}
+}
+
 // A diff provides a PostgreSQL implementation for sqlx.DiffDriver.
 type diff struct{ conn }
 
 // SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
-func (d *diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
-	// No special schema attribute diffing for PostgreSQL.
-	return nil
+func (d *diff) SchemaAttrDiff(from, to *schema.Schema) []schema.Change {
+	var changes []schema.Change
+	for _, a := range from.Attrs {
+		o1, ok := a.(*Object)
+		if !ok {
+			continue
+		}
+		switch o2, ok := schemaObject(to, o1); {
+		// Objects that were not created by Atlas are not dropped.
+		case !ok && o1.Hash != "":
+			changes = append(changes, &schema.DropAttr{A: o1})
+		case ok && o1.Hash != o2.Hash:
+			changes = append(changes, &schema.ModifyAttr{From: o1, To: o2})
+		}
+	}
+	for _, a := range to.Attrs {
+		if o2, ok := a.(*Object); ok {
+			if _, ok := schemaObject(from, o2); !ok {
+				changes = append(changes, &schema.AddAttr{A: o2})
+			}
+		}
+	}
+	return append(changes, grantsDiff(from.Attrs, to.Attrs, schemaPrivileges)...)
+}
+
+// schemaObject returns the object in the schema that matches the given object.
+func schemaObject(s *schema.Schema, o *Object) (*Object, bool) {
+	for _, a := range s.Attrs {
+		if o1, ok := a.(*Object); ok && sameObject(o1, o) {
+			return o1, true
+		}
+	}
+	return nil, false
 }
 
 // TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
@@ -32,14 +78,228 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
 		changes = append(changes, change)
 	}
-	if err := d.partitionChanged(from, to); err != nil {
+	parts, err := d.partitionChanged(from, to)
+	if err != nil {
 		return nil, err
 	}
+	changes = append(changes, parts...)
+	if change := rowSecurityChange(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	changes = append(changes, policiesDiff(from.Attrs, to.Attrs)...)
+	changes = append(changes, grantsDiff(from.Attrs, to.Attrs, tablePrivileges)...)
+	// Retention policies are not inspected from the database. Hence, they are
+	// compared only if the current state was loaded from a desired state.
+	if sqlx.Has(from.Attrs, &schema.Retention{}) {
+		if change := sqlx.RetentionDiff(from.Attrs, to.Attrs); change != nil {
+			changes = append(changes, change)
+		}
+	}
 	return append(changes, sqlx.CheckDiff(from, to, func(c1, c2 *schema.Check) bool {
 		return sqlx.Has(c1.Attrs, &NoInherit{}) == sqlx.Has(c2.Attrs, &NoInherit{})
 	})...), nil
 }
 
+// rowSecurityChange returns the change for the row-level security settings of a table, if they were changed.
+func rowSecurityChange(from, to []schema.Attr) schema.Change {
+	var r1, r2 RowSecurity
+	sqlx.Has(from, &r1)
+	sqlx.Has(to, &r2)
+	if r1 == r2 {
+		return nil
+	}
+	return &schema.ModifyAttr{From: &r1, To: &r2}
+}
+
+// policiesDiff returns the changes for the row-level security policies of a table.
+func policiesDiff(from, to []schema.Attr) []schema.Change {
+	var changes []schema.Change
+	for _, p1 := range policies(from) {
+		switch p2, ok := findPolicy(to, p1.Name); {
+		case !ok:
+			changes = append(changes, &schema.DropAttr{A: p1})
+		case !policyEqual(p1, p2):
+			changes = append(changes, &schema.ModifyAttr{From: p1, To: p2})
+		}
+	}
+	for _, p2 := range policies(to) {
+		if _, ok := findPolicy(from, p2.Name); !ok {
+			changes = append(changes, &schema.AddAttr{A: p2})
+		}
+	}
+	return changes
+}
+
+// policies returns the row-level security policies in the given attributes.
+func policies(attrs []schema.Attr) []*Policy {
+	var ps []*Policy
+	for _, a := range attrs {
+		if p, ok := a.(*Policy); ok {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// findPolicy returns the policy with the given name from the attributes.
+func findPolicy(attrs []schema.Attr, name string) (*Policy, bool) {
+	for _, p := range policies(attrs) {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// policyEqual reports if the two policies are equal.
+func policyEqual(p1, p2 *Policy) bool {
+	or := func(s, d string) string {
+		if s == "" {
+			return d
+		}
+		return strings.ToUpper(s)
+	}
+	return or(p1.As, PolicyPermissive) == or(p2.As, PolicyPermissive) &&
+		or(p1.Command, "ALL") == or(p2.Command, "ALL") &&
+		sameSet(policyRoles(p1), policyRoles(p2)) &&
+		policyExprEqual(p1.Using, p2.Using) &&
+		policyExprEqual(p1.Check, p2.Check)
+}
+
+// policyRoles returns the roles of the policy, where PUBLIC is represented by an empty list.
+func policyRoles(p *Policy) []string {
+	if len(p.Roles) == 1 && strings.EqualFold(p.Roles[0], GranteePublic) {
+		return nil
+	}
+	return p.Roles
+}
+
+// reCast matches type casts, such as '::text' or '::character varying',
+// that are added by the database to the expressions of the policies.
+var reCast = regexp.MustCompile(`(?i)::\s*[a-z_][a-z0-9_]*(\s+(varying|precision|with(out)?\s+time\s+zone))?(\[\])?`)
+
+// policyExprEqual reports if the two policy expressions are equal. Since the
+// database stores the expressions in their normal form, the comparison ignores
+// type casts, parentheses, identifier quotes, whitespaces and case.
+func policyExprEqual(x1, x2 string) bool {
+	norm := func(x string) string {
+		x = reCast.ReplaceAllString(x, "")
+		x = strings.NewReplacer("(", "", ")", "", `"`, "").Replace(x)
+		return strings.ToLower(strings.Join(strings.Fields(x), ""))
+	}
+	return norm(x1) == norm(x2)
+}
+
+// Privileges that are granted by the ALL keyword on tables and schemas.
+var (
+	tablePrivileges  = []string{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"}
+	schemaPrivileges = []string{"USAGE", "CREATE"}
+)
+
+// grantsDiff returns the changes for the privileges granted on
+// a table or a schema. Privileges are compared by grantee.
+func grantsDiff(from, to []schema.Attr, all []string) []schema.Change {
+	var changes []schema.Change
+	for _, g1 := range grants(from) {
+		switch g2, ok := findGrant(to, g1.Grantee); {
+		case !ok:
+			changes = append(changes, &schema.DropAttr{A: g1})
+		case !sameSet(privileges(g1, all, g2), privileges(g2, all, nil)):
+			changes = append(changes, &schema.ModifyAttr{From: g1, To: g2})
+		}
+	}
+	for _, g2 := range grants(to) {
+		if _, ok := findGrant(from, g2.Grantee); !ok {
+			changes = append(changes, &schema.AddAttr{A: g2})
+		}
+	}
+	return changes
+}
+
+// grants returns the grants in the given attributes.
+func grants(attrs []schema.Attr) []*Grant {
+	var gs []*Grant
+	for _, a := range attrs {
+		if g, ok := a.(*Grant); ok {
+			gs = append(gs, g)
+		}
+	}
+	return gs
+}
+
+// findGrant returns the grant of the given grantee from the attributes.
+func findGrant(attrs []schema.Attr, grantee string) (*Grant, bool) {
+	for _, g := range grants(attrs) {
+		if g.Grantee == grantee || strings.EqualFold(g.Grantee, GranteePublic) && strings.EqualFold(grantee, GranteePublic) {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+// privileges returns the privileges of the grant in upper case, with the ALL keyword expanded.
+// If the other grant uses the ALL keyword, privileges that are not covered by it in this list
+// (e.g. MAINTAIN, which was added in PostgreSQL 17) are ignored.
+func privileges(g *Grant, all []string, other *Grant) []string {
+	var ps []string
+	for _, p := range g.Privileges {
+		switch p = strings.ToUpper(p); {
+		case isAllPrivileges(p):
+			ps = append(ps, all...)
+		case other != nil && hasAllPrivileges(other) && !contains(all, p):
+		default:
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// isAllPrivileges reports if the privilege is the ALL keyword.
+func isAllPrivileges(p string) bool {
+	p = strings.ToUpper(p)
+	return p == "ALL" || p == "ALL PRIVILEGES"
+}
+
+// hasAllPrivileges reports if the grant uses the ALL keyword.
+func hasAllPrivileges(g *Grant) bool {
+	for _, p := range g.Privileges {
+		if isAllPrivileges(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports if the list contains the given value.
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// sameSet reports if the two lists hold the same set of values.
+func sameSet(s1, s2 []string) bool {
+	m1, m2 := make(map[string]bool, len(s1)), make(map[string]bool, len(s2))
+	for _, s := range s1 {
+		m1[s] = true
+	}
+	for _, s := range s2 {
+		m2[s] = true
+	}
+	if len(m1) != len(m2) {
+		return false
+	}
+	for s := range m1 {
+		if !m2[s] {
+			return false
+		}
+	}
+	return true
+}
+
 // ColumnChange returns the schema changes (if any) for migrating one column to the other.
 func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column) (schema.ChangeKind, error) {
 	change := sqlx.CommentChange(from.Attrs, to.Attrs)
@@ -104,27 +364,98 @@ func (*diff) generatedChanged(from, to *schema.Column) (bool, error) {
 }
 
 // partitionChanged checks and returns an error if the partition key of a table was changed.
-func (*diff) partitionChanged(from, to *schema.Table) error {
+// Otherwise, it returns the changes for adding, dropping and modifying its partitions.
+func (*diff) partitionChanged(from, to *schema.Table) ([]schema.Change, error) {
 	var fromP, toP Partition
 	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromP), sqlx.Has(to.Attrs, &toP); {
 	case fromHas && !toHas:
-		return fmt.Errorf("partition key cannot be dropped from %q (drop and add is required)", from.Name)
+		return nil, fmt.Errorf("partition key cannot be dropped from %q (drop and add is required)", from.Name)
 	case !fromHas && toHas:
-		return fmt.Errorf("partition key cannot be added to %q (drop and add is required)", to.Name)
+		return nil, fmt.Errorf("partition key cannot be added to %q (drop and add is required)", to.Name)
 	case fromHas && toHas:
-		s1, err := formatPartition(fromP)
-		if err != nil {
-			return err
+		if err := keyChanged(to.Name, &fromP, &toP); err != nil {
+			return nil, err
+		}
+		return partitionsDiff(fromP.Partitions, toP.Partitions)
+	}
+	return nil, nil
+}
+
+// keyChanged returns an error if the partition key of the named table was changed.
+func keyChanged(name string, from, to *Partition) error {
+	s1, err := formatPartition(*from)
+	if err != nil {
+		return err
+	}
+	s2, err := formatPartition(*to)
+	if err != nil {
+		return err
+	}
+	if s1 != s2 {
+		return fmt.Errorf("partition key of table %q cannot be changed from %s to %s (drop and add is required)", name, s1, s2)
+	}
+	return nil
+}
+
+// partitionsDiff returns the changes for migrating the partitions of a table from one
+// state to the other. Partitions are matched by their names, and partitions whose bound
+// or sub-partitions were changed are returned as schema.ModifyAttr changes.
+func partitionsDiff(from, to []*TablePartition) ([]schema.Change, error) {
+	var changes []schema.Change
+	for _, p1 := range from {
+		p2, ok := findPartition(to, p1.Name)
+		if !ok {
+			changes = append(changes, &schema.DropAttr{A: p1})
+			continue
 		}
-		s2, err := formatPartition(toP)
+		switch changed, err := partitionModified(p1, p2); {
+		case err != nil:
+			return nil, err
+		case changed:
+			changes = append(changes, &schema.ModifyAttr{From: p1, To: p2})
+		}
+	}
+	for _, p2 := range to {
+		if _, ok := findPartition(from, p2.Name); !ok {
+			changes = append(changes, &schema.AddAttr{A: p2})
+		}
+	}
+	return changes, nil
+}
+
+// partitionModified reports if the bound or the sub-partitions of the partition were changed.
+func partitionModified(from, to *TablePartition) (bool, error) {
+	switch {
+	case from.Sub == nil && to.Sub != nil || from.Sub != nil && to.Sub == nil:
+		return false, fmt.Errorf("partition key of partition %q cannot be changed (drop and add is required)", to.Name)
+	case from.Sub != nil:
+		if err := keyChanged(to.Name, from.Sub, to.Sub); err != nil {
+			return false, err
+		}
+		changes, err := partitionsDiff(from.Sub.Partitions, to.Sub.Partitions)
 		if err != nil {
-			return err
+			return false, err
 		}
-		if s1 != s2 {
-			return fmt.Errorf("partition key of table %q cannot be changed from %s to %s (drop and add is required)", to.Name, s1, s2)
+		if len(changes) > 0 {
+			return true, nil
 		}
 	}
-	return nil
+	return !boundEqual(from.Bound, to.Bound), nil
+}
+
+// boundEqual reports if the two partition bounds are equal, ignoring whitespace and case.
+func boundEqual(b1, b2 string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(b1), " "), strings.Join(strings.Fields(b2), " "))
+}
+
+// findPartition returns the partition with the given name.
+func findPartition(parts []*TablePartition, name string) (*TablePartition, bool) {
+	for _, p := range parts {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
 }
 
 // IsGeneratedIndexName reports if the index name was generated by the database.
@@ -164,7 +495,7 @@ func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
 	if sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || (p1.P != p2.P && p1.P != sqlx.MayWrap(p2.P)) {
 		return true
 	}
-	if indexIncludeChanged(from, to) {
+	if indexIncludeChanged(from, to) || nullsDistinct(from) != nullsDistinct(to) {
 		return true
 	}
 	s1, ok1 := indexStorageParams(from)
@@ -315,6 +646,62 @@ func identity(attrs []schema.Attr) (*Identity, bool) {
 	return i, true
 }
 
+// SequenceChanged reports if the options of the sequence were changed.
+// Options that were not set are compared using their PostgreSQL defaults.
+func (*diff) SequenceChanged(from, to *schema.Sequence) bool {
+	s1, s2 := sequenceDefaults(from), sequenceDefaults(to)
+	return s1.Type != s2.Type || s1.Start != s2.Start || s1.Increment != s2.Increment || *s1.Min != *s2.Min ||
+		*s1.Max != *s2.Max || s1.Cache != s2.Cache || s1.Cycle != s2.Cycle || sqlx.SequenceOwnerChanged(s1.Owner, s2.Owner)
+}
+
+// sequenceDefaults returns a copy of the sequence, with its unset options
+// filled with the defaults of PostgreSQL for its type and direction.
+func sequenceDefaults(s *schema.Sequence) *schema.Sequence {
+	c := *s
+	switch strings.ToLower(c.Type) {
+	case "", TypeBigInt, TypeInt8:
+		c.Type = TypeBigInt
+	case TypeInteger, TypeInt4, TypeInt:
+		c.Type = TypeInteger
+	case TypeSmallInt, TypeInt2:
+		c.Type = TypeSmallInt
+	}
+	if c.Increment == 0 {
+		c.Increment = defaultSeqIncrement
+	}
+	if c.Cache == 0 {
+		c.Cache = 1
+	}
+	upper := int64(math.MaxInt64)
+	switch c.Type {
+	case TypeInteger:
+		upper = math.MaxInt32
+	case TypeSmallInt:
+		upper = math.MaxInt16
+	}
+	if c.Min == nil {
+		v := int64(1)
+		if c.Increment < 0 {
+			v = -upper - 1
+		}
+		c.Min = &v
+	}
+	if c.Max == nil {
+		v := upper
+		if c.Increment < 0 {
+			v = -1
+		}
+		c.Max = &v
+	}
+	if c.Start == 0 {
+		c.Start = *c.Min
+		if c.Increment < 0 {
+			c.Start = *c.Max
+		}
+	}
+	return &c
+}
+
 // formatPartition returns the string representation of the
 // partition key according to the PostgreSQL format/grammar.
 func formatPartition(p Partition) (string, error) {
@@ -355,6 +742,14 @@ func indexStorageParams(attrs []schema.Attr) (*IndexStorageParams, bool) {
 	return s, true
 }
 
+// nullsDistinct reports if NULL values are considered distinct by the index,
+// which is the default if the NULLS [NOT] DISTINCT clause was not specified.
+func nullsDistinct(attrs []schema.Attr) bool {
+	n := &IndexNullsDistinct{V: true}
+	sqlx.Has(attrs, n)
+	return n.V
+}
+
 // indexIncludeChanged reports if the INCLUDE attribute clause was changed.
 func indexIncludeChanged(from, to []schema.Attr) bool {
 	var fromI, toI IndexInclude
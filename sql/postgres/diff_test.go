@@ -5,6 +5,7 @@
 package postgres
 
 import (
+	"math"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -117,6 +118,138 @@ func TestDiff_TableDiff(t *testing.T) {
 				}),
 			wantErr: true,
 		},
+		{
+			name: "change partitions",
+			from: schema.NewTable("logs").
+				AddAttrs(&Partition{
+					T:     PartitionTypeList,
+					Parts: []*PartitionPart{{C: schema.NewColumn("c")}},
+					Partitions: []*TablePartition{
+						{Name: "logs_a", Bound: "FOR VALUES IN ('a')"},
+						{Name: "logs_b", Bound: "FOR VALUES IN ('b')"},
+						{Name: "logs_c", Bound: "FOR VALUES IN ('c')"},
+					},
+				}),
+			to: schema.NewTable("logs").
+				AddAttrs(&Partition{
+					T:     PartitionTypeList,
+					Parts: []*PartitionPart{{C: schema.NewColumn("c")}},
+					Partitions: []*TablePartition{
+						{Name: "logs_a", Bound: "for values in ('a')"},
+						{Name: "logs_b", Bound: "FOR VALUES IN ('b', 'bb')"},
+						{Name: "logs_d", Bound: "DEFAULT"},
+					},
+				}),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &TablePartition{Name: "logs_b", Bound: "FOR VALUES IN ('b')"},
+					To:   &TablePartition{Name: "logs_b", Bound: "FOR VALUES IN ('b', 'bb')"},
+				},
+				&schema.DropAttr{A: &TablePartition{Name: "logs_c", Bound: "FOR VALUES IN ('c')"}},
+				&schema.AddAttr{A: &TablePartition{Name: "logs_d", Bound: "DEFAULT"}},
+			},
+		},
+		{
+			name: "change sub-partitions",
+			from: schema.NewTable("logs").
+				AddAttrs(&Partition{
+					T:     PartitionTypeList,
+					Parts: []*PartitionPart{{C: schema.NewColumn("c")}},
+					Partitions: []*TablePartition{
+						{Name: "logs_a", Bound: "FOR VALUES IN ('a')", Sub: &Partition{T: PartitionTypeHash, Parts: []*PartitionPart{{C: schema.NewColumn("id")}}}},
+					},
+				}),
+			to: schema.NewTable("logs").
+				AddAttrs(&Partition{
+					T:     PartitionTypeList,
+					Parts: []*PartitionPart{{C: schema.NewColumn("c")}},
+					Partitions: []*TablePartition{
+						{Name: "logs_a", Bound: "FOR VALUES IN ('a')", Sub: &Partition{T: PartitionTypeHash, Parts: []*PartitionPart{{C: schema.NewColumn("id")}}, Partitions: []*TablePartition{
+							{Name: "logs_a0", Bound: "FOR VALUES WITH (MODULUS 1, REMAINDER 0)"},
+						}}},
+					},
+				}),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &TablePartition{Name: "logs_a", Bound: "FOR VALUES IN ('a')", Sub: &Partition{T: PartitionTypeHash, Parts: []*PartitionPart{{C: schema.NewColumn("id")}}}},
+					To: &TablePartition{Name: "logs_a", Bound: "FOR VALUES IN ('a')", Sub: &Partition{T: PartitionTypeHash, Parts: []*PartitionPart{{C: schema.NewColumn("id")}}, Partitions: []*TablePartition{
+						{Name: "logs_a0", Bound: "FOR VALUES WITH (MODULUS 1, REMAINDER 0)"},
+					}}},
+				},
+			},
+		},
+		{
+			name: "change sub-partition key",
+			from: schema.NewTable("logs").
+				AddAttrs(&Partition{
+					T:          PartitionTypeList,
+					Parts:      []*PartitionPart{{C: schema.NewColumn("c")}},
+					Partitions: []*TablePartition{{Name: "logs_a", Bound: "FOR VALUES IN ('a')"}},
+				}),
+			to: schema.NewTable("logs").
+				AddAttrs(&Partition{
+					T:     PartitionTypeList,
+					Parts: []*PartitionPart{{C: schema.NewColumn("c")}},
+					Partitions: []*TablePartition{
+						{Name: "logs_a", Bound: "FOR VALUES IN ('a')", Sub: &Partition{T: PartitionTypeHash, Parts: []*PartitionPart{{C: schema.NewColumn("id")}}}},
+					},
+				}),
+			wantErr: true,
+		},
+		{
+			name: "change row security",
+			from: schema.NewTable("users"),
+			to:   schema.NewTable("users").AddAttrs(&RowSecurity{Enabled: true}),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{From: &RowSecurity{}, To: &RowSecurity{Enabled: true}},
+			},
+		},
+		{
+			name: "change policies",
+			from: schema.NewTable("users").
+				AddAttrs(
+					&Policy{Name: "isolation", As: PolicyPermissive, Command: "ALL", Using: "(tenant = (current_setting('app.tenant'::text))::integer)"},
+					&Policy{Name: "readers", As: PolicyPermissive, Command: "SELECT", Roles: []string{"app"}, Using: "true"},
+					&Policy{Name: "writers", As: PolicyPermissive, Command: "INSERT", Check: "true"},
+				),
+			to: schema.NewTable("users").
+				AddAttrs(
+					&Policy{Name: "isolation", Using: `"tenant" = current_setting('app.tenant')::int`},
+					&Policy{Name: "readers", As: PolicyRestrictive, Command: "select", Roles: []string{"app"}, Using: "true"},
+					&Policy{Name: "owners", Roles: []string{"PUBLIC"}, Using: "true"},
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &Policy{Name: "readers", As: PolicyPermissive, Command: "SELECT", Roles: []string{"app"}, Using: "true"},
+					To:   &Policy{Name: "readers", As: PolicyRestrictive, Command: "select", Roles: []string{"app"}, Using: "true"},
+				},
+				&schema.DropAttr{A: &Policy{Name: "writers", As: PolicyPermissive, Command: "INSERT", Check: "true"}},
+				&schema.AddAttr{A: &Policy{Name: "owners", Roles: []string{"PUBLIC"}, Using: "true"}},
+			},
+		},
+		{
+			name: "change grants",
+			from: schema.NewTable("users").
+				AddAttrs(
+					&Grant{Grantee: "admin", Privileges: []string{"DELETE", "INSERT", "MAINTAIN", "REFERENCES", "SELECT", "TRIGGER", "TRUNCATE", "UPDATE"}},
+					&Grant{Grantee: "app", Privileges: []string{"INSERT", "SELECT"}},
+					&Grant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}},
+				),
+			to: schema.NewTable("users").
+				AddAttrs(
+					&Grant{Grantee: "admin", Privileges: []string{"ALL"}},
+					&Grant{Grantee: "app", Privileges: []string{"select", "update"}},
+					&Grant{Grantee: "reporting", Privileges: []string{"SELECT"}},
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &Grant{Grantee: "app", Privileges: []string{"INSERT", "SELECT"}},
+					To:   &Grant{Grantee: "app", Privileges: []string{"select", "update"}},
+				},
+				&schema.DropAttr{A: &Grant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}}},
+				&schema.AddAttr{A: &Grant{Grantee: "reporting", Privileges: []string{"SELECT"}}},
+			},
+		},
 		{
 			name: "add check",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}},
@@ -473,3 +606,126 @@ func TestDiff_SchemaDiff(t *testing.T) {
 		&schema.AddTable{T: to.Tables[1]},
 	}, changes)
 }
+
+func TestDiff_SchemaObjects(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	from := schema.New("public").AddAttrs(
+		&Object{Kind: ObjectAggregate, Name: "agg", Signature: "integer", Hash: "b1c560cac0649cd3"},
+		&Object{Kind: ObjectOperator, Name: "===", Signature: "text, text", Hash: "0000000000000000"},
+		&Object{Kind: ObjectOperatorClass, Name: "old_ops", Signature: "gin", Hash: "0000000000000000"},
+		// Objects that were not created by Atlas.
+		&Object{Kind: ObjectOperatorClass, Name: "manual_ops", Signature: "gin"},
+		&Object{Kind: ObjectAggregate, Name: "sum_all", Signature: "integer"},
+	)
+	to := schema.New("public").AddAttrs(
+		(&Object{Kind: ObjectAggregate, Name: "agg", Signature: "integer"}).SetDef("SFUNC = int4pl,  STYPE = integer"),
+		(&Object{Kind: ObjectOperator, Name: "===", Signature: "text, text"}).SetDef("LEFTARG = text, RIGHTARG = text, FUNCTION = texteq"),
+		(&Object{Kind: ObjectAggregate, Name: "sum_all", Signature: "integer"}).SetDef("SFUNC = int4pl, STYPE = integer"),
+		(&Object{Kind: ObjectAggregate, Name: "agg", Signature: "bigint"}).SetDef("SFUNC = int8pl, STYPE = bigint"),
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.ModifySchema{S: to, Changes: []schema.Change{
+			&schema.ModifyAttr{From: from.Attrs[1], To: to.Attrs[1]},
+			&schema.DropAttr{A: from.Attrs[2]},
+			&schema.ModifyAttr{From: from.Attrs[4], To: to.Attrs[2]},
+			&schema.AddAttr{A: to.Attrs[3]},
+		}},
+	}, changes)
+}
+
+func TestDiff_SchemaGrants(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	from := schema.New("public").AddAttrs(
+		&Grant{Grantee: "app", Privileges: []string{"USAGE"}},
+		&Grant{Grantee: "admin", Privileges: []string{"CREATE", "USAGE"}},
+	)
+	to := schema.New("public").AddAttrs(
+		&Grant{Grantee: "admin", Privileges: []string{"ALL PRIVILEGES"}},
+		&Grant{Grantee: "reporting", Privileges: []string{"USAGE"}},
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.ModifySchema{S: to, Changes: []schema.Change{
+			&schema.DropAttr{A: from.Attrs[0]},
+			&schema.AddAttr{A: to.Attrs[1]},
+		}},
+	}, changes)
+}
+
+func TestDiff_SchemaSequences(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		from = schema.New("public").
+			AddSequences(
+				// Inspected with the defaults of PostgreSQL.
+				schema.NewSequence("ids").SetType("bigint").SetStart(1).SetIncrement(1).SetMin(1).SetMax(math.MaxInt64).SetCache(1),
+				schema.NewSequence("down").SetType("integer").SetStart(-1).SetIncrement(-1).SetMin(math.MinInt32).SetMax(-1).SetCache(1),
+				schema.NewSequence("counter").SetType("integer").SetStart(1).SetIncrement(1).SetMin(1).SetMax(math.MaxInt32).SetCache(1),
+				schema.NewSequence("legacy"),
+			)
+		to = schema.New("public").
+			AddSequences(
+				schema.NewSequence("ids"),
+				schema.NewSequence("down").SetType("int4").SetIncrement(-1),
+				schema.NewSequence("counter").SetType("int").SetIncrement(5),
+				schema.NewSequence("orders"),
+			)
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.DropSequence{S: from.Sequences[3]},
+		&schema.ModifySequence{From: from.Sequences[2], To: to.Sequences[2]},
+		&schema.AddSequence{S: to.Sequences[3]},
+	}, changes)
+}
+
+func TestDiff_SchemaFuncs(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		from = schema.New("public").
+			AddFuncs(
+				schema.NewFunc("add", "a integer, b integer", "integer", "SELECT a + b").SetLang("sql"),
+				schema.NewFunc("inc", "i integer", "integer", "SELECT i + 1").SetLang("sql"),
+			).
+			AddProcs(
+				schema.NewProc("archive", "id integer", "BEGIN NULL; END").SetLang("plpgsql"),
+				schema.NewProc("purge", "", "BEGIN NULL; END").SetLang("plpgsql"),
+			)
+		to = schema.New("public").
+			AddFuncs(
+				// Overloaded functions are matched by their arguments.
+				schema.NewFunc("add", "a text, b text", "text", "SELECT a || b").SetLang("sql"),
+				schema.NewFunc("add", "a integer,  b integer", "integer", "SELECT  a + b;").SetLang("SQL"),
+				schema.NewFunc("inc", "i integer", "bigint", "SELECT i + 1").SetLang("sql"),
+			).
+			AddProcs(schema.NewProc("archive", "id integer", "BEGIN DELETE FROM users; END").SetLang("plpgsql"))
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.DropProc{P: from.Procs[1]},
+		&schema.AddFunc{F: to.Funcs[0]},
+		&schema.ModifyFunc{From: from.Funcs[1], To: to.Funcs[2]},
+		&schema.ModifyProc{From: from.Procs[0], To: to.Procs[0]},
+	}, changes)
+}
@@ -7,11 +7,15 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"hash/fnv"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"ariga.io/atlas/sql/internal/sqlx"
@@ -29,6 +33,9 @@ type (
 		schema.Inspector
 		migrate.PlanApplier
 		schema string // the schema given in the `search_path` parameter (if given)
+		txPool bool   // connected through a transaction-pooling proxy (e.g. PgBouncer).
+		// Notices sent by the server, if they are collected by the connector.
+		notices *notices
 	}
 
 	// database connection and its information.
@@ -42,9 +49,39 @@ type (
 	}
 )
 
+var (
+	// noConn holds the connection information used for
+	// planning changes without a database connection.
+	noConn = conn{ExecQuerier: sqlx.NoRows, version: 15_00_00}
+
+	// DefaultDiff provides basic diffing capabilities for PostgreSQL dialects.
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// Differ when a database connection is available.
+	DefaultDiff schema.Differ = &sqlx.Diff{DiffDriver: &diff{noConn}}
+
+	// DefaultPlan provides basic planning capabilities for PostgreSQL dialects, for
+	// example, for compiling a schema to SQL statements without a database connection.
+	// Objects are assumed not to exist in the database, and the changes are planned for
+	// PostgreSQL 15, unless a target version is given (see migrate.PlanOptions).
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// migrate.PlanApplier when a database connection is available.
+	DefaultPlan migrate.PlanApplier = &planApply{noConn}
+)
+
 // DriverName holds the name used for registration.
 const DriverName = "postgres"
 
+// The pool_mode URL parameter describes how the connections are pooled by
+// a proxy that sits in front of the database (e.g. PgBouncer). In transaction
+// mode, the server connection is returned to the pool after every transaction
+// and session-level features (startup parameters, advisory locks and prepared
+// statements spanning multiple requests) are avoided.
+const (
+	poolModeParam       = "pool_mode"
+	PoolModeSession     = "session"
+	PoolModeTransaction = "transaction"
+)
+
 func init() {
 	sqlclient.Register(
 		DriverName,
@@ -57,10 +94,33 @@ func init() {
 }
 
 func opener(_ context.Context, u *url.URL) (*sqlclient.Client, error) {
-	ur := parser{}.ParseURL(u)
-	db, err := sql.Open(DriverName, ur.DSN)
-	if err != nil {
-		return nil, err
+	q := u.Query()
+	switch m := q.Get(poolModeParam); {
+	case m != "" && m != PoolModeSession && m != PoolModeTransaction:
+		return nil, fmt.Errorf("sql/postgres: unknown %s %q, expect %q or %q", poolModeParam, m, PoolModeSession, PoolModeTransaction)
+	// The search_path cannot be set on the shared server connections. Hence, unqualified
+	// statements would run against the default schema of the role, instead of the schema
+	// that is inspected and planned.
+	case m == PoolModeTransaction && q.Get("search_path") != "":
+		return nil, fmt.Errorf("sql/postgres: search_path cannot be used with %s=%s", poolModeParam, PoolModeTransaction)
+	}
+	var (
+		db *sql.DB
+		ns *notices
+		ur = parser{}.ParseURL(u)
+	)
+	if noticeConnector != nil {
+		ns = &notices{}
+		c, err := (&noticeDriver{ns: ns}).OpenConnector(ur.DSN)
+		if err != nil {
+			return nil, err
+		}
+		db = sql.OpenDB(c)
+	} else {
+		var err error
+		if db, err = sql.Open(DriverName, ur.DSN); err != nil {
+			return nil, err
+		}
 	}
 	drv, err := Open(db)
 	if err != nil {
@@ -70,6 +130,8 @@ func opener(_ context.Context, u *url.URL) (*sqlclient.Client, error) {
 		return nil, err
 	}
 	drv.(*Driver).schema = ur.Schema
+	drv.(*Driver).txPool = u.Query().Get(poolModeParam) == PoolModeTransaction
+	drv.(*Driver).notices = ns
 	return &sqlclient.Client{
 		Name:   DriverName,
 		DB:     db,
@@ -78,6 +140,89 @@ func opener(_ context.Context, u *url.URL) (*sqlclient.Client, error) {
 	}, nil
 }
 
+// NoticeConnector creates a connector for the given DSN that passes
+// the notices sent by the server to the given handler.
+type NoticeConnector func(dsn string, handler func(migrate.Warning)) (driver.Connector, error)
+
+// noticeConnector is used by the client opener, if registered.
+var noticeConnector NoticeConnector
+
+// RegisterNoticeConnector registers the connector used for opening clients that collect
+// the notices sent by the server (e.g. "relation already exists, skipping"). Since the
+// database/sql package does not expose notices, they are collected by the underlying
+// driver, and can be read by the migrate.Warner interface implemented by the Driver.
+func RegisterNoticeConnector(c NoticeConnector) {
+	noticeConnector = c
+}
+
+type (
+	// noticeConn is a driver.Connector that passes the notices sent by the server to
+	// the queue of its driver. Its driver opens connectors that report to the same
+	// queue, to keep collecting notices when the client replaces its connections
+	// (e.g. for executing session statements).
+	noticeConn struct {
+		driver.Connector
+		ns *notices
+	}
+
+	// noticeDriver opens the connectors of noticeConn.
+	noticeDriver struct {
+		driver.Driver
+		ns *notices
+	}
+)
+
+// Driver implements driver.Connector.
+func (c *noticeConn) Driver() driver.Driver {
+	return &noticeDriver{Driver: c.Connector.Driver(), ns: c.ns}
+}
+
+// OpenConnector implements driver.DriverContext.
+func (d *noticeDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	c, err := noticeConnector(dsn, d.ns.add)
+	if err != nil {
+		return nil, err
+	}
+	return &noticeConn{Connector: c, ns: d.ns}, nil
+}
+
+// notices is a concurrent-safe queue of server notices.
+type notices struct {
+	mu sync.Mutex
+	ws []migrate.Warning
+}
+
+func (n *notices) add(w migrate.Warning) {
+	n.mu.Lock()
+	n.ws = append(n.ws, w)
+	n.mu.Unlock()
+}
+
+func (n *notices) drain() []migrate.Warning {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ws := n.ws
+	n.ws = nil
+	return ws
+}
+
+// Warnings returns the notices sent by the server since the last call.
+// It implements the migrate.Warner interface.
+func (d *Driver) Warnings(context.Context) ([]migrate.Warning, error) {
+	if d.notices == nil {
+		return nil, nil
+	}
+	return d.notices.drain(), nil
+}
+
+// ShareTx shares the notices queue and the pool mode with the driver opened for
+// a transaction, or for the connections that replace the ones of the client.
+func (d *Driver) ShareTx(drv migrate.Driver) {
+	if tx, ok := drv.(*Driver); ok {
+		tx.notices, tx.txPool = d.notices, d.txPool
+	}
+}
+
 // Open opens a new PostgreSQL driver.
 func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 	c := conn{ExecQuerier: db}
@@ -125,21 +270,143 @@ func (d *Driver) dev() *sqlx.DevDriver {
 				e.Schema = s
 			}
 		},
+		// Access control objects may refer to roles that do not
+		// exist in the dev database, and therefore are kept as-is.
+		KeepAttr: func(a schema.Attr) bool {
+			switch a.(type) {
+			case *RowSecurity, *Policy, *Grant:
+				return true
+			}
+			return false
+		},
 	}
 }
 
 // NormalizeRealm returns the normal representation of the given database.
 func (d *Driver) NormalizeRealm(ctx context.Context, r *schema.Realm) (*schema.Realm, error) {
-	return d.dev().NormalizeRealm(ctx, r)
+	objects := make(map[string][]schema.Attr, len(r.Schemas))
+	for _, s := range r.Schemas {
+		o, restore := detachObjects(s)
+		defer restore()
+		objects[s.Name] = o
+	}
+	nr, err := d.dev().NormalizeRealm(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range nr.Schemas {
+		s.Attrs = append(s.Attrs, objects[s.Name]...)
+	}
+	return nr, nil
 }
 
 // NormalizeSchema returns the normal representation of the given database.
 func (d *Driver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*schema.Schema, error) {
-	return d.dev().NormalizeSchema(ctx, s)
+	objects, restore := detachObjects(s)
+	defer restore()
+	ns, err := d.dev().NormalizeSchema(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	ns.Attrs = append(ns.Attrs, objects...)
+	return ns, nil
+}
+
+// IdentCase returns the identifier case options of the connected database.
+// Identifiers are compared as-is, as they are quoted by Atlas.
+func (d *Driver) IdentCase(context.Context) (*schema.IdentCaseOptions, error) {
+	return IdentCase(false), nil
+}
+
+// Fingerprints returns a fingerprint for each table in the given schema that changes when
+// the table, or one of its child objects, is changed. It is computed from the transaction
+// IDs (xmin) of the catalog rows that describe the table, and therefore, it is cheap to
+// compute and does not require inspecting the tables. CockroachDB does not expose these
+// IDs, and a nil map is returned in this case to indicate that tables must be inspected.
+func (d *Driver) Fingerprints(ctx context.Context, name string) (map[string]string, error) {
+	if d.crdb {
+		return nil, nil
+	}
+	rows, err := d.QueryContext(ctx, fingerprintsQuery, name)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying schema %q fingerprints: %w", name, err)
+	}
+	defer rows.Close()
+	prints := make(map[string]string)
+	for rows.Next() {
+		var t, f string
+		if err := rows.Scan(&t, &f); err != nil {
+			return nil, fmt.Errorf("postgres: scanning schema %q fingerprints: %w", name, err)
+		}
+		prints[t] = f
+	}
+	return prints, rows.Err()
+}
+
+// detachObjects removes the objects from the schema attributes, and returns a
+// function for restoring them. Objects are opaque, and therefore they are kept
+// as-is on normalization instead of being created in the dev database.
+func detachObjects(s *schema.Schema) ([]schema.Attr, func()) {
+	var (
+		attrs   = s.Attrs
+		objects []schema.Attr
+	)
+	s.Attrs = make([]schema.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if _, ok := a.(*Object); ok {
+			objects = append(objects, a)
+		} else {
+			s.Attrs = append(s.Attrs, a)
+		}
+	}
+	return objects, func() { s.Attrs = attrs }
+}
+
+// Capabilities implements the migrate.CapabilitiesReporter interface.
+func (d *Driver) Capabilities(version string) (*migrate.Capabilities, error) {
+	c := d.conn
+	if version != "" {
+		v, err := parseVersion(version)
+		if err != nil {
+			return nil, err
+		}
+		c.version = v
+	}
+	return c.capabilities(), nil
+}
+
+// capabilities returns the capabilities of the connection version.
+func (c *conn) capabilities() *migrate.Capabilities {
+	name := DriverName
+	if c.crdb {
+		name = "cockroach"
+	}
+	return &migrate.Capabilities{
+		Driver:  name,
+		Version: fmt.Sprintf("%d.%d", c.version/10000, c.version%10000),
+		Supports: map[migrate.Capability]bool{
+			migrate.CapTransactionalDDL: true,
+			migrate.CapConcurrentIndex:  !c.crdb,
+			// Adding columns with non-volatile defaults does not rewrite the table since v11.
+			migrate.CapInstantAddColumn: !c.crdb && c.version >= 11_00_00,
+			migrate.CapRenameColumn:     true,
+			migrate.CapCheckConstraint:  true,
+			migrate.CapIndexExpr:        true,
+			migrate.CapGeneratedColumn:  c.supportsGeneratedColumns(),
+			migrate.CapInvisibleIndex:   false,
+			migrate.CapIndexInclude:     c.supportsIndexInclude(),
+			migrate.CapNullsNotDistinct: c.supportsNullsDistinct(),
+		},
+	}
 }
 
 // Lock implements the schema.Locker interface.
 func (d *Driver) Lock(ctx context.Context, name string, timeout time.Duration) (schema.UnlockFunc, error) {
+	// Advisory locks are held by the server session, which is shared
+	// by different clients when pooled in transaction mode.
+	if d.txPool {
+		return nil, fmt.Errorf("sql/postgres: %w: advisory locks cannot be used with %s=%s", schema.ErrLockUnsupported, poolModeParam, PoolModeTransaction)
+	}
 	conn, err := sqlx.SingleConn(ctx, d.ExecQuerier)
 	if err != nil {
 		return nil, err
@@ -220,6 +487,79 @@ func (d *Driver) Snapshot(ctx context.Context) (migrate.RestoreFunc, error) {
 	return nil, migrate.NotCleanError{Reason: fmt.Sprintf("found schema %q", realm.Schemas[0].Name)}
 }
 
+// Violations implements migrate.DataValidator. Partial unique indexes are not checked.
+func (d *Driver) Violations(ctx context.Context, changes []schema.Change, limit int) ([]*migrate.Violation, error) {
+	var checks []*sqlx.ViolationCheck
+	for _, c := range sqlx.ViolationChecks(changes, '"', limit) {
+		// Rows that are excluded by the predicate of a partial index are not checked by it.
+		if c.Index == nil || !sqlx.Has(c.Index.Attrs, &IndexPredicate{}) {
+			checks = append(checks, c)
+		}
+	}
+	vs, err := sqlx.CheckViolations(ctx, d, checks)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	return vs, nil
+}
+
+var (
+	reViolates = regexp.MustCompile(`violates (unique|foreign key|check|not-null|exclusion) constraint(?: "([^"]+)")?`)
+	reRelation = regexp.MustCompile(`(?:on table|relation) "([^"]+)"`)
+	reInColumn = regexp.MustCompile(`in column "([^"]+)"`)
+	reKeyValue = regexp.MustCompile(`^Key \((.+)\)=\((.*)\) `)
+)
+
+// ConstraintError implements migrate.ConstraintErrorParser. The table, constraint and key values are
+// taken from the error fields, if the error exposes them (e.g. lib/pq), and from its message otherwise.
+func (*Driver) ConstraintError(err error) (*migrate.ConstraintError, bool) {
+	msg := err.Error()
+	m := reViolates.FindStringSubmatch(msg)
+	if m == nil {
+		return nil, false
+	}
+	cerr := &migrate.ConstraintError{Kind: strings.ReplaceAll(m[1], "-", " "), Constraint: m[2]}
+	if m := reRelation.FindStringSubmatch(msg); m != nil {
+		cerr.Table = m[1]
+	}
+	if m := reInColumn.FindStringSubmatch(msg); m != nil {
+		cerr.Columns = []string{m[1]}
+	}
+	var fields interface{ Get(byte) string }
+	if errors.As(err, &fields) {
+		if t := fields.Get('t'); t != "" {
+			cerr.Table = t
+		}
+		if n := fields.Get('n'); n != "" {
+			cerr.Constraint = n
+		}
+		if m := reKeyValue.FindStringSubmatch(fields.Get('D')); m != nil {
+			cerr.Columns, cerr.Values = strings.Split(m[1], ", "), strings.Split(m[2], ", ")
+		}
+	}
+	return cerr, true
+}
+
+// ErrorPos implements migrate.ErrorPositioner. The position is taken from the error
+// fields, if the error exposes them (e.g. lib/pq), and it is reported by PostgreSQL as
+// a 1-based character index.
+func (*Driver) ErrorPos(stmt string, err error) (int, bool) {
+	var fields interface{ Get(byte) string }
+	if !errors.As(err, &fields) {
+		return 0, false
+	}
+	pos, err := strconv.Atoi(fields.Get('P'))
+	if err != nil || pos < 1 {
+		return 0, false
+	}
+	for i := range stmt {
+		if pos--; pos == 0 {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
 // CheckClean implements migrate.CleanChecker.
 func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error {
 	if d.schema != "" {
@@ -285,16 +625,76 @@ func acquire(ctx context.Context, conn schema.ExecQuerier, id uint32, timeout ti
 	}
 }
 
+// parseVersion parses a PostgreSQL version given in its numeric form
+// (e.g. 150002), or as a major and optional minor version (e.g. 15.2).
+func parseVersion(s string) (int, error) {
+	major, minor, _ := strings.Cut(s, ".")
+	v, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: malformed version: %s", s)
+	}
+	// Versions in their numeric form.
+	if minor == "" && v >= 10000 {
+		if v < 10_00_00 {
+			return 0, fmt.Errorf("postgres: unsupported postgres version: %s", s)
+		}
+		return v, nil
+	}
+	v *= 10000
+	if minor != "" {
+		m, err := strconv.Atoi(minor)
+		if err != nil || m >= 10000 {
+			return 0, fmt.Errorf("postgres: malformed version: %s", s)
+		}
+		v += m
+	}
+	if v < 10_00_00 {
+		return 0, fmt.Errorf("postgres: unsupported postgres version: %s", s)
+	}
+	return v, nil
+}
+
 // supportsIndexInclude reports if the server supports the INCLUDE clause.
 func (c *conn) supportsIndexInclude() bool {
 	return c.version >= 11_00_00
 }
 
+// supportsGeneratedColumns reports if the server supports generated columns.
+func (c *conn) supportsGeneratedColumns() bool {
+	return c.version >= 12_00_00
+}
+
+// supportsHashPartition reports if the server supports hash partitioning.
+func (c *conn) supportsHashPartition() bool {
+	return c.version >= 11_00_00
+}
+
+// supportsNullsDistinct reports if the server supports
+// the NULLS [NOT] DISTINCT clause on unique indexes.
+func (c *conn) supportsNullsDistinct() bool {
+	return c.version >= 15_00_00
+}
+
 type parser struct{}
 
 // ParseURL implements the sqlclient.URLParser interface.
 func (parser) ParseURL(u *url.URL) *sqlclient.URL {
-	return &sqlclient.URL{URL: u, DSN: u.String(), Schema: u.Query().Get("search_path")}
+	q := u.Query()
+	ur := &sqlclient.URL{URL: u, DSN: u.String(), Schema: q.Get("search_path")}
+	if !q.Has(poolModeParam) {
+		return ur
+	}
+	dsn := *u
+	if q.Get(poolModeParam) == PoolModeTransaction {
+		// Send the parameters of queries along with them to avoid preparing unnamed
+		// statements in separate requests, as they might be executed on different
+		// server connections.
+		q.Set("binary_parameters", "yes")
+	}
+	q.Del(poolModeParam)
+	dsn.RawQuery = q.Encode()
+	ur.DSN = dsn.String()
+	return ur
 }
 
 // ChangeSchema implements the sqlclient.SchemaChanger interface.
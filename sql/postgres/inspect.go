@@ -6,11 +6,14 @@ package postgres
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
@@ -32,16 +35,403 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 	}
 	r := schema.NewRealm(schemas...).SetCollation(i.collate)
 	r.Attrs = append(r.Attrs, &CType{V: i.ctype})
+	if sqlx.ModeInspectRealm(opts).Is(schema.InspectSettings) {
+		if err := i.inspectSettings(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	var triggers []*schema.Trigger
+	if mode := sqlx.ModeInspectRealm(opts); len(schemas) > 0 && mode.Is(schema.InspectObjects|schema.InspectViews|schema.InspectTriggers|schema.InspectFuncs) {
+		if mode.Is(schema.InspectObjects) {
+			if err := i.objects(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if triggers, err = i.unmanaged(ctx, r, mode); err != nil {
+			return nil, err
+		}
+	}
 	if len(schemas) == 0 || !sqlx.ModeInspectRealm(opts).Is(schema.InspectTables) {
+		if len(schemas) > 0 && sqlx.ModeInspectRealm(opts).Is(schema.InspectSequences) {
+			if err := i.sequences(ctx, r); err != nil {
+				return nil, err
+			}
+		}
 		return sqlx.ExcludeRealm(r, opts.Exclude)
 	}
-	if err := i.inspectTables(ctx, r, nil); err != nil {
+	if err := i.inspectTables(ctx, r, nil, opts.Workers); err != nil {
 		return nil, err
 	}
+	if sqlx.ModeInspectRealm(opts).Is(schema.InspectPermissions) {
+		if err := i.permissions(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if sqlx.ModeInspectRealm(opts).Is(schema.InspectSequences) {
+		if err := i.sequences(ctx, r); err != nil {
+			return nil, err
+		}
+	}
 	sqlx.LinkSchemaTables(schemas)
+	sqlx.LinkTriggers(triggers)
 	return sqlx.ExcludeRealm(r, opts.Exclude)
 }
 
+// inspectSettings adds the settings that were configured
+// for the connected database (ALTER DATABASE ... SET) to the realm.
+func (i *inspect) inspectSettings(ctx context.Context, r *schema.Realm) error {
+	rows, err := i.QueryContext(ctx, settingsQuery)
+	if err != nil {
+		return fmt.Errorf("postgres: querying settings: %w", err)
+	}
+	configs, err := sqlx.ScanStrings(rows)
+	if err != nil {
+		return fmt.Errorf("postgres: scanning settings: %w", err)
+	}
+	for _, c := range configs {
+		if name, v, ok := strings.Cut(c, "="); ok {
+			r.Attrs = append(r.Attrs, &schema.Setting{Name: name, V: v})
+		}
+	}
+	return nil
+}
+
+// objects adds the user-defined aggregates, operators and operator classes to
+// their schemas. Objects that are members of extensions are not inspected, as
+// they are managed by their extensions.
+func (i *inspect) objects(ctx context.Context, r *schema.Realm) error {
+	if i.crdb {
+		return nil
+	}
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(objectsQuery, nArgs(0, len(r.Schemas))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying objects: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, kind, name, sig string
+			comment             sql.NullString
+		)
+		if err := rows.Scan(&ns, &kind, &name, &sig, &comment); err != nil {
+			return fmt.Errorf("postgres: scanning object: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		o := &Object{Kind: kind, Name: name, Signature: sig}
+		if strings.HasPrefix(comment.String, objectHashPrefix) {
+			o.Hash = strings.TrimPrefix(comment.String, objectHashPrefix)
+		}
+		s.Attrs = append(s.Attrs, o)
+	}
+	return rows.Err()
+}
+
+// permissions adds the row-level security settings and policies of the tables,
+// and the privileges granted on the tables and schemas of the realm. Privileges
+// that are held by the owners of the objects are not inspected.
+func (i *inspect) permissions(ctx context.Context, r *schema.Realm) error {
+	if i.crdb || len(r.Schemas) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	// Tables that were not inspected (e.g. excluded) are skipped.
+	table := func(ns, name string) (*schema.Table, bool) {
+		if s, ok := r.Schema(ns); ok {
+			return s.Table(name)
+		}
+		return nil, false
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(rowSecurityQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying row-level security: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name        string
+			enabled, forced bool
+		)
+		if err := rows.Scan(&ns, &name, &enabled, &forced); err != nil {
+			return fmt.Errorf("postgres: scanning row-level security: %w", err)
+		}
+		if t, ok := table(ns, name); ok {
+			t.AddAttrs(&RowSecurity{Enabled: enabled, Forced: forced})
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if rows, err = i.QueryContext(ctx, fmt.Sprintf(policiesQuery, nArgs(0, len(args))), args...); err != nil {
+		return fmt.Errorf("postgres: querying policies: %w", err)
+	}
+	for rows.Next() {
+		var (
+			ns, name, policy, as, roles, cmd string
+			using, check                     sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &policy, &as, &roles, &cmd, &using, &check); err != nil {
+			return fmt.Errorf("postgres: scanning policies: %w", err)
+		}
+		t, ok := table(ns, name)
+		if !ok {
+			continue
+		}
+		p := &Policy{Name: policy, As: as, Command: cmd, Using: using.String, Check: check.String}
+		// Policies that apply to all roles are granted to PUBLIC.
+		if roles != "public" {
+			p.Roles = strings.Split(roles, ",")
+		}
+		t.AddAttrs(p)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if rows, err = i.QueryContext(ctx, fmt.Sprintf(grantsQuery, nArgs(0, len(args))), args...); err != nil {
+		return fmt.Errorf("postgres: querying privileges: %w", err)
+	}
+	var last *Grant
+	for rows.Next() {
+		var (
+			ns, grantee, priv string
+			name              sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &grantee, &priv); err != nil {
+			return fmt.Errorf("postgres: scanning privileges: %w", err)
+		}
+		var attrs *[]schema.Attr
+		switch s, ok := r.Schema(ns); {
+		case !ok:
+			continue
+		case !name.Valid:
+			attrs = &s.Attrs
+		default:
+			t, ok := s.Table(name.String)
+			if !ok {
+				continue
+			}
+			attrs = &t.Attrs
+		}
+		// Privileges are ordered by object and grantee,
+		// and grouped into one grant for each grantee.
+		if n := len(*attrs); n > 0 && (*attrs)[n-1] == last && last.Grantee == grantee {
+			last.Privileges = append(last.Privileges, priv)
+			continue
+		}
+		last = &Grant{Grantee: grantee, Privileges: []string{priv}}
+		*attrs = append(*attrs, last)
+	}
+	return rows.Close()
+}
+
+// sequences adds the sequences that are not backing serial or identity columns,
+// and are not members of extensions, to their schemas. Sequences that are owned
+// by serial columns are skipped, as they are managed by their columns.
+func (i *inspect) sequences(ctx context.Context, r *schema.Realm) error {
+	if i.crdb {
+		return nil
+	}
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(sequencesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying sequences: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, typ               string
+			start, inc, min, max, cache int64
+			cycle                       bool
+			table, column               sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &typ, &start, &inc, &min, &max, &cache, &cycle, &table, &column); err != nil {
+			return fmt.Errorf("postgres: scanning sequence: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		seq := schema.NewSequence(name).
+			SetType(typ).
+			SetStart(start).
+			SetIncrement(inc).
+			SetMin(min).
+			SetMax(max).
+			SetCache(cache).
+			SetCycle(cycle)
+		if table.Valid && column.Valid {
+			t, ok := s.Table(table.String)
+			if !ok {
+				// Owner tables that were not inspected (e.g. excluded) are skipped.
+				continue
+			}
+			c, ok := t.Column(column.String)
+			if !ok {
+				continue
+			}
+			if st, ok := c.Type.Type.(*SerialType); ok && st.sequence(t, c) == name {
+				continue
+			}
+			seq.SetOwner(t, c)
+		}
+		s.AddSequences(seq)
+	}
+	return rows.Close()
+}
+
+// unmanaged inspects the views, functions and triggers that are not members of
+// extensions. Views, functions and triggers are added to their schemas if they were
+// requested by the inspection mode, and the other objects, which are not modeled by
+// Atlas, are kept as raw statements.
+func (i *inspect) unmanaged(ctx context.Context, r *schema.Realm, mode schema.InspectMode) ([]*schema.Trigger, error) {
+	if i.crdb {
+		return nil, nil
+	}
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	var funcs map[string]bool
+	if mode.Is(schema.InspectFuncs) {
+		var err error
+		if funcs, err = i.funcs(ctx, r, args); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(unmanagedQuery, nArgs(0, len(r.Schemas))), args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying unmanaged objects: %w", err)
+	}
+	defer rows.Close()
+	var triggers []*schema.Trigger
+	for rows.Next() {
+		var (
+			ns, typ, name, def string
+			extra              sql.NullString
+		)
+		if err := rows.Scan(&ns, &typ, &name, &def, &extra); err != nil {
+			return nil, fmt.Errorf("postgres: scanning unmanaged object: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return nil, fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		var (
+			u *schema.Unmanaged
+			b = &sqlx.Builder{QuoteChar: '"'}
+		)
+		switch {
+		case typ == "VIEW":
+			if mode.Is(schema.InspectViews) {
+				s.AddViews(schema.NewView(name, strings.TrimSuffix(strings.TrimSpace(def), ";")))
+			}
+			continue
+		case typ == "TRIGGER" && mode.Is(schema.InspectTriggers):
+			// Constraint triggers and triggers with transition
+			// tables are not modeled, and kept as raw statements.
+			if tr, ok := triggerFromDef(name, def); ok {
+				tr.Table = &schema.Table{Name: extra.String, Schema: s}
+				triggers = append(triggers, tr)
+				continue
+			}
+		case typ == "FUNCTION" && funcs[fmt.Sprintf("%s.%s(%s)", ns, name, extra.String)]:
+			continue
+		}
+		if !mode.Is(schema.InspectObjects) {
+			continue
+		}
+		switch typ {
+		case "MATERIALIZED VIEW":
+			ident := b.Table(&schema.Table{Name: name, Schema: s}).String()
+			def = strings.TrimSuffix(strings.TrimSpace(def), ";")
+			u = schema.NewUnmanaged(typ, name, fmt.Sprintf("CREATE %s %s AS %s", typ, ident, def)).
+				SetDrop(fmt.Sprintf("DROP %s %s", typ, ident))
+		case "FUNCTION":
+			// Functions can be overloaded, and therefore their
+			// arguments are part of their identifier.
+			if strings.HasPrefix(def, "CREATE OR REPLACE PROCEDURE") {
+				typ = "PROCEDURE"
+			}
+			ident := fmt.Sprintf("%s(%s)", b.Table(&schema.Table{Name: name, Schema: s}).String(), extra.String)
+			u = schema.NewUnmanaged(typ, fmt.Sprintf("%s(%s)", name, extra.String), strings.TrimSpace(def)).
+				SetDrop(fmt.Sprintf("DROP %s %s", typ, ident))
+		case "TRIGGER":
+			// Trigger names are unique per table.
+			on := b.Table(&schema.Table{Name: extra.String, Schema: s}).String()
+			u = schema.NewUnmanaged(typ, extra.String+"."+name, def).
+				SetDrop(fmt.Sprintf("DROP TRIGGER %q ON %s", name, on))
+		default:
+			return nil, fmt.Errorf("postgres: unexpected unmanaged object type %q", typ)
+		}
+		s.AddAttrs(u)
+	}
+	return triggers, rows.Err()
+}
+
+// funcs inspects the functions and procedures of the realm schemas, and returns the
+// identifiers of the inspected routines. Routines that are defined with options that
+// are not modeled by Atlas (e.g. IMMUTABLE or SECURITY DEFINER) are skipped, and are
+// inspected as unmanaged objects.
+func (i *inspect) funcs(ctx context.Context, r *schema.Realm, args []any) (map[string]bool, error) {
+	kind, cond := "p.prokind", "p.prokind IN ('f', 'p')"
+	// Procedures were added in PostgreSQL 11.
+	if i.version < 11_00_00 {
+		kind, cond = "'f'", "NOT p.proisagg AND NOT p.proiswindow"
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(funcsQuery, nArgs(0, len(r.Schemas)), kind, cond), args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying functions: %w", err)
+	}
+	defer rows.Close()
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var (
+			ns, name, kind, fargs, id, lang, body string
+			ret                                   sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &kind, &fargs, &id, &ret, &lang, &body); err != nil {
+			return nil, fmt.Errorf("postgres: scanning functions: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return nil, fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		if kind == "p" {
+			s.AddProcs(schema.NewProc(name, fargs, body).SetLang(lang))
+		} else {
+			s.AddFuncs(schema.NewFunc(name, fargs, ret.String, body).SetLang(lang))
+		}
+		ids[fmt.Sprintf("%s.%s(%s)", ns, name, id)] = true
+	}
+	return ids, rows.Err()
+}
+
+// reTriggerDef matches the definition of plain triggers, as returned by pg_get_triggerdef.
+var reTriggerDef = regexp.MustCompile(`(?s)^CREATE TRIGGER .+? (BEFORE|AFTER|INSTEAD OF) (.+?) ON (?:"(?:[^"]|"")*"|[^\s"])+ FOR EACH (ROW|STATEMENT) (?:WHEN \((.+)\) )?(EXECUTE (?:FUNCTION|PROCEDURE) .+)$`)
+
+// triggerFromDef returns the trigger described by the given definition.
+func triggerFromDef(name, def string) (*schema.Trigger, bool) {
+	m := reTriggerDef.FindStringSubmatch(strings.TrimSpace(def))
+	if m == nil {
+		return nil, false
+	}
+	return schema.NewTrigger(name, m[1], strings.Split(m[2], " OR "), m[5]).
+		SetForEachRow(m[3] == "ROW").
+		SetWhen(m[4]), true
+}
+
 // InspectSchema returns schema descriptions of the tables in the given schema.
 // If the schema name is empty, the result will be the attached schema.
 func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (s *schema.Schema, err error) {
@@ -60,23 +450,44 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 	}
 	r := schema.NewRealm(schemas...).SetCollation(i.collate)
 	r.Attrs = append(r.Attrs, &CType{V: i.ctype})
+	var triggers []*schema.Trigger
+	if mode := sqlx.ModeInspectSchema(opts); mode.Is(schema.InspectObjects | schema.InspectViews | schema.InspectTriggers | schema.InspectFuncs) {
+		if mode.Is(schema.InspectObjects) {
+			if err := i.objects(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if triggers, err = i.unmanaged(ctx, r, mode); err != nil {
+			return nil, err
+		}
+	}
 	if sqlx.ModeInspectSchema(opts).Is(schema.InspectTables) {
-		if err := i.inspectTables(ctx, r, opts); err != nil {
+		if err := i.inspectTables(ctx, r, opts, 1); err != nil {
 			return nil, err
 		}
 		sqlx.LinkSchemaTables(schemas)
+		sqlx.LinkTriggers(triggers)
+	}
+	if sqlx.ModeInspectSchema(opts).Is(schema.InspectPermissions) {
+		if err := i.permissions(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if sqlx.ModeInspectSchema(opts).Is(schema.InspectSequences) {
+		if err := i.sequences(ctx, r); err != nil {
+			return nil, err
+		}
 	}
 	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
 }
 
-func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
+// inspectTables inspects the tables of the realm schemas. Schemas are
+// inspected concurrently if workers is greater than 1.
+func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions, workers int) error {
 	if err := i.tables(ctx, r, opts); err != nil {
 		return err
 	}
-	for _, s := range r.Schemas {
-		if len(s.Tables) == 0 {
-			continue
-		}
+	return sqlx.InspectSchemas(ctx, i.ExecQuerier, workers, r.Schemas, func(ctx context.Context, s *schema.Schema) error {
 		if err := i.columns(ctx, s); err != nil {
 			return err
 		}
@@ -86,14 +497,14 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 		if err := i.partitions(s); err != nil {
 			return err
 		}
-		if err := i.fks(ctx, s); err != nil {
+		if err := i.tablePartitions(ctx, s); err != nil {
 			return err
 		}
-		if err := i.checks(ctx, s); err != nil {
+		if err := i.fks(ctx, s); err != nil {
 			return err
 		}
-	}
-	return nil
+		return i.checks(ctx, s)
+	})
 }
 
 // table returns the table from the database, or a NotExistError if the table was not found.
@@ -301,6 +712,8 @@ func (i *inspect) indexes(ctx context.Context, s *schema.Schema) error {
 		return i.crdbIndexes(ctx, s)
 	case !i.conn.supportsIndexInclude():
 		query = indexesQueryNoInclude
+	case i.conn.supportsNullsDistinct():
+		query = indexesQueryNullsDistinct
 	}
 	rows, err := i.querySchema(ctx, query, s)
 	if err != nil {
@@ -320,10 +733,10 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 		var (
 			uniq, primary, included                       bool
 			table, name, typ                              string
-			desc, nullsfirst, nullslast                   sql.NullBool
+			desc, nullsfirst, nullslast, nullsNotDistinct sql.NullBool
 			column, contype, pred, expr, comment, options sql.NullString
 		)
-		if err := rows.Scan(&table, &name, &typ, &column, &included, &primary, &uniq, &contype, &pred, &expr, &desc, &nullsfirst, &nullslast, &comment, &options); err != nil {
+		if err := rows.Scan(&table, &name, &typ, &column, &included, &primary, &uniq, &contype, &pred, &expr, &desc, &nullsfirst, &nullslast, &comment, &options, &nullsNotDistinct); err != nil {
 			return fmt.Errorf("postgres: scanning indexes for schema %q: %w", s.Name, err)
 		}
 		t, ok := s.Table(table)
@@ -356,6 +769,9 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 				}
 				idx.Attrs = append(idx.Attrs, p)
 			}
+			if nullsNotDistinct.Bool {
+				idx.Attrs = append(idx.Attrs, &IndexNullsDistinct{V: false})
+			}
 			names[name] = idx
 			if primary {
 				t.PrimaryKey = idx
@@ -449,6 +865,158 @@ func (i *inspect) partitions(s *schema.Schema) error {
 	return nil
 }
 
+// tablePartitions queries and appends the partitions of the partitioned tables in the
+// schema. Partitions that are partitioned themselves hold their sub-partitions.
+func (i *inspect) tablePartitions(ctx context.Context, s *schema.Schema) error {
+	var keys int
+	for _, t := range s.Tables {
+		if _, ok := partitionKey(t.Attrs); ok {
+			keys++
+		}
+	}
+	if keys == 0 || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, partitionsQuery, s.Name)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q partitions: %w", s.Name, err)
+	}
+	defer rows.Close()
+	children := make(map[string][]*TablePartition)
+	for rows.Next() {
+		var (
+			parent, name, bound string
+			key                 sql.NullString
+		)
+		if err := rows.Scan(&parent, &name, &bound, &key); err != nil {
+			return fmt.Errorf("postgres: scan partition information: %w", err)
+		}
+		p := &TablePartition{Name: name, Bound: bound}
+		if sqlx.ValidString(key) {
+			if p.Sub, err = parsePartitionKey(key.String); err != nil {
+				return err
+			}
+		}
+		children[parent] = append(children[parent], p)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	var link func(string, *Partition)
+	link = func(name string, key *Partition) {
+		key.Partitions = children[name]
+		for _, p := range key.Partitions {
+			if p.Sub != nil {
+				link(p.Name, p.Sub)
+			}
+		}
+	}
+	for _, t := range s.Tables {
+		if p, ok := partitionKey(t.Attrs); ok {
+			link(t.Name, p)
+			// Sub-partition keys reference the columns of the partitioned table.
+			if err := partitionColumns(t, p.Partitions); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// partitionKey returns the partition key attribute, if exists.
+func partitionKey(attrs []schema.Attr) (*Partition, bool) {
+	for _, a := range attrs {
+		if p, ok := a.(*Partition); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// parsePartitionKey parses the partition key returned by pg_get_partkeydef.
+// For example, "RANGE (created_at)" or "LIST (lower(name), region)". Columns
+// are linked to the table columns after the key is parsed.
+func parsePartitionKey(def string) (*Partition, error) {
+	t, list, ok := strings.Cut(def, " ")
+	list = strings.TrimSpace(list)
+	if !ok || !strings.HasPrefix(list, "(") || !strings.HasSuffix(list, ")") {
+		return nil, fmt.Errorf("postgres: unexpected partition key: %q", def)
+	}
+	key := &Partition{T: strings.ToUpper(t)}
+	for _, x := range splitExprs(list[1 : len(list)-1]) {
+		if name, ok := partitionColumn(x); ok {
+			key.Parts = append(key.Parts, &PartitionPart{C: schema.NewColumn(name)})
+		} else {
+			key.Parts = append(key.Parts, &PartitionPart{X: &schema.RawExpr{X: x}})
+		}
+	}
+	return key, nil
+}
+
+// partitionColumns links the columns of the sub-partition keys to the table columns.
+func partitionColumns(t *schema.Table, parts []*TablePartition) error {
+	for _, p := range parts {
+		if p.Sub == nil {
+			continue
+		}
+		for _, k := range p.Sub.Parts {
+			if k.C == nil {
+				continue
+			}
+			c, ok := t.Column(k.C.Name)
+			if !ok {
+				return fmt.Errorf("postgres: column %q of partition %q was not found in table %q", k.C.Name, p.Name, t.Name)
+			}
+			k.C = c
+		}
+		if err := partitionColumns(t, p.Sub.Partitions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partitionColumn reports if the partition key element is a column name.
+func partitionColumn(x string) (string, bool) {
+	if len(x) > 1 && x[0] == '"' && x[len(x)-1] == '"' {
+		return strings.ReplaceAll(x[1:len(x)-1], `""`, `"`), true
+	}
+	for _, r := range x {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "", false
+		}
+	}
+	return x, x != ""
+}
+
+// splitExprs splits the comma-separated list of expressions,
+// ignoring commas in parentheses and quoted strings.
+func splitExprs(s string) []string {
+	var (
+		exprs        []string
+		depth, start int
+		quote        rune
+	)
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			exprs = append(exprs, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(exprs, strings.TrimSpace(s[start:]))
+}
+
 // fks queries and appends the foreign keys of the given table.
 func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
 	rows, err := i.querySchema(ctx, fksQuery, s)
@@ -628,6 +1196,56 @@ type (
 		V string
 	}
 
+	// Object describes a user-defined aggregate, operator or operator class.
+	// Objects are managed opaquely: their definitions are not inspected from
+	// the database, but identified by a hash that is stored in the object
+	// comment when it is created.
+	Object struct {
+		schema.Attr
+		Kind string // ObjectAggregate, ObjectOperator or ObjectOperatorClass.
+		Name string
+		// Signature holds the argument types of aggregates and operators
+		// (e.g. "text, text"), or the index method of operator classes
+		// (e.g. "gin"). Together with the kind and the name, it identifies
+		// the object in its schema.
+		Signature string
+		// Def holds the definition of the object, that is written after its
+		// name in the CREATE statement (e.g. "SFUNC = int4pl, STYPE = integer").
+		// It is empty for inspected objects.
+		Def  string
+		Hash string
+	}
+
+	// RowSecurity describes the row-level security settings of a table.
+	RowSecurity struct {
+		schema.Attr
+		Enabled bool // ENABLE ROW LEVEL SECURITY.
+		Forced  bool // FORCE ROW LEVEL SECURITY, policies apply to the table owner as well.
+	}
+
+	// Policy describes a row-level security policy of a table.
+	Policy struct {
+		schema.Attr
+		Name string
+		// As is PolicyPermissive (the default) or PolicyRestrictive.
+		As string
+		// Command is the command the policy applies to: ALL (the
+		// default), SELECT, INSERT, UPDATE or DELETE.
+		Command string
+		// Roles the policy applies to. Empty means PUBLIC.
+		Roles []string
+		// Using and Check hold the USING and WITH CHECK expressions.
+		Using, Check string
+	}
+
+	// Grant describes the privileges granted on a table or
+	// a schema to a role, for example, SELECT and INSERT.
+	Grant struct {
+		schema.Attr
+		Grantee    string // Role name or PUBLIC.
+		Privileges []string
+	}
+
 	// UserDefinedType defines a user-defined type attribute.
 	UserDefinedType struct {
 		schema.Type
@@ -772,6 +1390,15 @@ type (
 		Columns []*schema.Column
 	}
 
+	// IndexNullsDistinct describes the NULLS [NOT] DISTINCT clause of unique
+	// indexes and constraints. If V is false, NULL values are not considered
+	// distinct, and therefore, a unique index allows only one NULL value.
+	// https://www.postgresql.org/docs/15/sql-createindex.html
+	IndexNullsDistinct struct {
+		schema.Attr
+		V bool
+	}
+
 	// NoInherit attribute defines the NO INHERIT flag for CHECK constraint.
 	// https://postgresql.org/docs/current/catalog-pg-constraint.html
 	NoInherit struct {
@@ -796,10 +1423,25 @@ type (
 		// on each part can be used to control collation.
 		Parts []*PartitionPart
 
+		// Partitions holds the partitions of the table.
+		Partitions []*TablePartition
+
 		// Internal info returned from pg_partitioned_table.
 		start, attrs, exprs string
 	}
 
+	// A TablePartition represents a partition (a child table) of a partitioned table.
+	TablePartition struct {
+		schema.Attr
+		Name string
+		// Bound holds the partition bound specification, for example,
+		// "FOR VALUES FROM (1) TO (10)", "FOR VALUES IN ('eu')" or "DEFAULT".
+		Bound string
+		// Sub holds the partition key and the partitions
+		// of a partition that is partitioned itself.
+		Sub *Partition
+	}
+
 	// An PartitionPart represents an index part that
 	// can be either an expression or a column.
 	PartitionPart struct {
@@ -809,6 +1451,37 @@ type (
 	}
 )
 
+// Object kinds.
+const (
+	ObjectAggregate     = "aggregate"
+	ObjectOperator      = "operator"
+	ObjectOperatorClass = "operator_class"
+)
+
+// Policy types and grantees.
+const (
+	PolicyPermissive  = "PERMISSIVE"
+	PolicyRestrictive = "RESTRICTIVE"
+	GranteePublic     = "PUBLIC"
+)
+
+// objectHashPrefix prefixes the definition hash that is
+// stored in the comment of objects created by Atlas.
+const objectHashPrefix = "atlas:"
+
+// SetDef sets the definition of the object, and its hash.
+func (o *Object) SetDef(def string) *Object {
+	o.Def = def
+	h := sha256.Sum256([]byte(strings.Join(strings.Fields(def), " ")))
+	o.Hash = hex.EncodeToString(h[:])[:16]
+	return o
+}
+
+// sameObject reports if the two objects describe the same object.
+func sameObject(o1, o2 *Object) bool {
+	return o1.Kind == o2.Kind && o1.Name == o2.Name && o1.Signature == o2.Signature
+}
+
 // IsUnique reports if the type is unique constraint.
 func (c ConType) IsUnique() bool { return strings.ToLower(c.T) == "u" }
 
@@ -899,6 +1572,9 @@ const (
 	// Query to list runtime parameters.
 	paramsQuery = `SELECT setting FROM pg_settings WHERE name IN ('lc_collate', 'lc_ctype', 'server_version_num', 'crdb_version') ORDER BY name DESC`
 
+	// Query to list the settings of the connected database.
+	settingsQuery = `SELECT unnest(setconfig) FROM pg_catalog.pg_db_role_setting WHERE setrole = 0 AND setdatabase = (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database())`
+
 	// Query to list database schemas.
 	schemasQuery = "SELECT schema_name FROM information_schema.schemata WHERE schema_name NOT IN ('information_schema', 'pg_catalog', 'pg_toast', 'crdb_internal', 'pg_extension') AND schema_name NOT LIKE 'pg_%temp_%' ORDER BY schema_name"
 
@@ -925,6 +1601,25 @@ WHERE
 	AND t1.table_schema IN (%s)
 ORDER BY
 	t1.table_schema, t1.table_name
+`
+	// Query to list the partitions of the partitioned tables in a schema.
+	partitionsQuery = `
+SELECT
+	p.relname AS parent_name,
+	c.relname AS partition_name,
+	pg_catalog.pg_get_expr(c.relpartbound, c.oid) AS partition_bound,
+	pg_catalog.pg_get_partkeydef(c.oid) AS partition_key
+FROM
+	pg_catalog.pg_inherits AS i
+	JOIN pg_catalog.pg_class AS c ON c.oid = i.inhrelid
+	JOIN pg_catalog.pg_class AS p ON p.oid = i.inhparent
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+WHERE
+	c.relispartition
+	AND c.relnamespace = p.relnamespace
+	AND n.nspname = $1
+ORDER BY
+	p.relname, c.relname
 `
 	tablesQueryArgs = `
 SELECT
@@ -1041,12 +1736,236 @@ WHERE
 ORDER BY
 	t1.conname, array_position(t1.conkey, t2.attnum)
 `
+
+	// Query to list the tables with row-level security settings.
+	rowSecurityQuery = `
+SELECT
+	n.nspname AS schema_name,
+	c.relname AS table_name,
+	c.relrowsecurity,
+	c.relforcerowsecurity
+FROM
+	pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE
+	n.nspname IN (%s)
+	AND c.relkind IN ('r', 'p')
+	AND (c.relrowsecurity OR c.relforcerowsecurity)
+ORDER BY
+	n.nspname, c.relname
+`
+
+	// Query to list the row-level security policies of tables.
+	policiesQuery = `
+SELECT
+	schemaname,
+	tablename,
+	policyname,
+	permissive,
+	array_to_string(roles, ','),
+	cmd,
+	qual,
+	with_check
+FROM
+	pg_catalog.pg_policies
+WHERE
+	schemaname IN (%s)
+ORDER BY
+	schemaname, tablename, policyname
+`
+
+	// Query to list the privileges granted on tables and schemas to roles other than their owners.
+	grantsQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(a.grantee) END AS grantee,
+	a.privilege_type
+FROM
+	pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace,
+	LATERAL pg_catalog.aclexplode(c.relacl) a
+WHERE
+	n.nspname IN (%[1]s)
+	AND c.relkind IN ('r', 'p')
+	AND a.grantee <> c.relowner
+UNION ALL
+SELECT
+	n.nspname,
+	NULL,
+	CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(a.grantee) END AS grantee,
+	a.privilege_type
+FROM
+	pg_catalog.pg_namespace n,
+	LATERAL pg_catalog.aclexplode(n.nspacl) a
+WHERE
+	n.nspname IN (%[1]s)
+	AND a.grantee <> n.nspowner
+ORDER BY
+	1, 2, 3, 4
+`
+
+	// Query to list the sequences that are not members of extensions or backing identity columns.
+	sequencesQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	pg_catalog.format_type(s.seqtypid, NULL),
+	s.seqstart,
+	s.seqincrement,
+	s.seqmin,
+	s.seqmax,
+	s.seqcache,
+	s.seqcycle,
+	t.relname AS owner_table,
+	a.attname AS owner_column
+FROM
+	pg_catalog.pg_sequence s
+	JOIN pg_catalog.pg_class c ON c.oid = s.seqrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_depend d ON d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = c.oid AND d.refclassid = 'pg_catalog.pg_class'::regclass AND d.deptype IN ('a', 'i')
+	LEFT JOIN pg_catalog.pg_class t ON t.oid = d.refobjid
+	LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+WHERE
+	n.nspname IN (%s)
+	AND (d.deptype IS NULL OR d.deptype = 'a')
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend e WHERE e.classid = 'pg_catalog.pg_class'::regclass AND e.objid = c.oid AND e.deptype = 'e')
+ORDER BY
+	n.nspname, c.relname
+`
+
+	// Query to list the aggregates, operators and operator classes that are not members of extensions.
+	objectsQuery = `
+SELECT
+	n.nspname AS schema_name,
+	'aggregate' AS kind,
+	p.proname AS name,
+	pg_catalog.pg_get_function_identity_arguments(p.oid) AS signature,
+	pg_catalog.obj_description(p.oid, 'pg_proc') AS comment
+FROM
+	pg_catalog.pg_proc p
+	JOIN pg_catalog.pg_aggregate a ON a.aggfnoid = p.oid
+	JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+WHERE
+	n.nspname IN (%[1]s)
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')
+UNION ALL
+SELECT
+	n.nspname AS schema_name,
+	'operator' AS kind,
+	o.oprname AS name,
+	CASE WHEN o.oprleft = 0 THEN 'NONE' ELSE pg_catalog.format_type(o.oprleft, NULL) END || ', ' || pg_catalog.format_type(o.oprright, NULL) AS signature,
+	pg_catalog.obj_description(o.oid, 'pg_operator') AS comment
+FROM
+	pg_catalog.pg_operator o
+	JOIN pg_catalog.pg_namespace n ON n.oid = o.oprnamespace
+WHERE
+	n.nspname IN (%[1]s)
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_operator'::regclass AND d.objid = o.oid AND d.deptype = 'e')
+UNION ALL
+SELECT
+	n.nspname AS schema_name,
+	'operator_class' AS kind,
+	c.opcname AS name,
+	m.amname AS signature,
+	pg_catalog.obj_description(c.oid, 'pg_opclass') AS comment
+FROM
+	pg_catalog.pg_opclass c
+	JOIN pg_catalog.pg_am m ON m.oid = c.opcmethod
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.opcnamespace
+WHERE
+	n.nspname IN (%[1]s)
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_opclass'::regclass AND d.objid = c.oid AND d.deptype = 'e')
+ORDER BY
+	1, 2, 3, 4
+`
+
+	// Query to list the functions and procedures that are not members of extensions, and
+	// are defined only by their arguments, return type, language and body.
+	funcsQuery = `
+SELECT
+	n.nspname AS schema_name,
+	p.proname AS name,
+	%[2]s AS kind,
+	pg_catalog.pg_get_function_arguments(p.oid) AS args,
+	pg_catalog.pg_get_function_identity_arguments(p.oid) AS identity,
+	pg_catalog.pg_get_function_result(p.oid) AS result,
+	l.lanname AS lang,
+	p.prosrc AS body
+FROM
+	pg_catalog.pg_proc p
+	JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+	JOIN pg_catalog.pg_language l ON l.oid = p.prolang
+WHERE
+	n.nspname IN (%[1]s)
+	AND %[3]s
+	AND l.lanname NOT IN ('c', 'internal')
+	AND p.prosrc <> ''
+	AND p.provolatile = 'v'
+	AND p.proparallel = 'u'
+	AND NOT p.proisstrict
+	AND NOT p.prosecdef
+	AND NOT p.proleakproof
+	AND p.proconfig IS NULL
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')
+ORDER BY
+	1, 2, 4
+`
+
+	// Query to list the views, functions and triggers that are not members of extensions.
+	unmanagedQuery = `
+SELECT
+	n.nspname AS schema_name,
+	CASE c.relkind WHEN 'm' THEN 'MATERIALIZED VIEW' ELSE 'VIEW' END AS type,
+	c.relname AS name,
+	pg_catalog.pg_get_viewdef(c.oid) AS definition,
+	NULL AS extra
+FROM
+	pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE
+	n.nspname IN (%[1]s)
+	AND c.relkind IN ('v', 'm')
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = c.oid AND d.deptype = 'e')
+UNION ALL
+SELECT
+	n.nspname AS schema_name,
+	'FUNCTION' AS type,
+	p.proname AS name,
+	pg_catalog.pg_get_functiondef(p.oid) AS definition,
+	pg_catalog.pg_get_function_identity_arguments(p.oid) AS extra
+FROM
+	pg_catalog.pg_proc p
+	JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+WHERE
+	n.nspname IN (%[1]s)
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_aggregate a WHERE a.aggfnoid = p.oid)
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')
+UNION ALL
+SELECT
+	n.nspname AS schema_name,
+	'TRIGGER' AS type,
+	t.tgname AS name,
+	pg_catalog.pg_get_triggerdef(t.oid) AS definition,
+	c.relname AS extra
+FROM
+	pg_catalog.pg_trigger t
+	JOIN pg_catalog.pg_class c ON c.oid = t.tgrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE
+	n.nspname IN (%[1]s)
+	AND NOT t.tgisinternal
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = c.oid AND d.deptype = 'e')
+ORDER BY
+	1, 2, 3
+`
 )
 
 var (
-	indexesQuery          = fmt.Sprintf(indexesQueryTmpl, "(a.attname <> '' AND idx.indnatts > idx.indnkeyatts AND idx.ord > idx.indnkeyatts)", "%s")
-	indexesQueryNoInclude = fmt.Sprintf(indexesQueryTmpl, "false", "%s")
-	indexesQueryTmpl      = `
+	indexesQuery              = fmt.Sprintf(indexesQueryTmpl, "(a.attname <> '' AND idx.indnatts > idx.indnkeyatts AND idx.ord > idx.indnkeyatts)", "false", "%s")
+	indexesQueryNoInclude     = fmt.Sprintf(indexesQueryTmpl, "false", "false", "%s")
+	indexesQueryNullsDistinct = fmt.Sprintf(indexesQueryTmpl, "(a.attname <> '' AND idx.indnatts > idx.indnkeyatts AND idx.ord > idx.indnkeyatts)", "idx.indnullsnotdistinct", "%s")
+	indexesQueryTmpl          = `
 SELECT
 	t.relname AS table_name,
 	i.relname AS index_name,
@@ -1062,7 +1981,8 @@ SELECT
 	pg_index_column_has_property(idx.indexrelid, idx.ord, 'nulls_first') AS nulls_first,
 	pg_index_column_has_property(idx.indexrelid, idx.ord, 'nulls_last') AS nulls_last,
 	obj_description(i.oid, 'pg_class') AS comment,
-	i.reloptions AS options
+	i.reloptions AS options,
+	%s AS nulls_not_distinct
 FROM
 	(
 		select
@@ -1084,4 +2004,32 @@ WHERE
 ORDER BY
 	table_name, index_name, idx.ord
 `
+
+	// Query to compute the fingerprints of the tables in a schema. A fingerprint
+	// changes whenever one of the catalog rows that describe the table changes.
+	fingerprintsQuery = `
+SELECT
+	c.relname AS table_name,
+	md5(concat_ws('|',
+		c.oid::text,
+		c.xmin::text,
+		(SELECT string_agg(a.attnum::text || ':' || a.xmin::text, ',' ORDER BY a.attnum) FROM pg_catalog.pg_attribute AS a WHERE a.attrelid = c.oid),
+		(SELECT string_agg(ad.xmin::text, ',' ORDER BY ad.adnum) FROM pg_catalog.pg_attrdef AS ad WHERE ad.adrelid = c.oid),
+		(SELECT string_agg(i.indexrelid::text || ':' || i.xmin::text || ':' || ic.xmin::text, ',' ORDER BY i.indexrelid) FROM pg_catalog.pg_index AS i JOIN pg_catalog.pg_class AS ic ON ic.oid = i.indexrelid WHERE i.indrelid = c.oid),
+		(SELECT string_agg(co.oid::text || ':' || co.xmin::text, ',' ORDER BY co.oid) FROM pg_catalog.pg_constraint AS co WHERE co.conrelid = c.oid),
+		(SELECT string_agg(tg.oid::text || ':' || tg.xmin::text, ',' ORDER BY tg.oid) FROM pg_catalog.pg_trigger AS tg WHERE tg.tgrelid = c.oid),
+		(SELECT string_agg(d.objsubid::text || ':' || d.xmin::text, ',' ORDER BY d.objsubid) FROM pg_catalog.pg_description AS d WHERE d.objoid = c.oid AND d.classoid = 'pg_catalog.pg_class'::regclass),
+		(SELECT string_agg(ih.inhrelid::text || ':' || ih.xmin::text, ',' ORDER BY ih.inhrelid) FROM pg_catalog.pg_inherits AS ih WHERE ih.inhparent = c.oid),
+		(SELECT string_agg(e.oid::text || ':' || e.xmin::text, ',' ORDER BY e.oid) FROM pg_catalog.pg_attribute AS a JOIN pg_catalog.pg_enum AS e ON e.enumtypid = a.atttypid WHERE a.attrelid = c.oid)
+	)) AS fingerprint
+FROM
+	pg_catalog.pg_class AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+WHERE
+	n.nspname = $1
+	AND c.relkind IN ('r', 'p')
+	AND NOT COALESCE(c.relispartition, false)
+ORDER BY
+	c.relname
+`
 )
@@ -153,8 +153,8 @@ users      | parent_id   | bigint              | int8      |  YES         |
 				m.ExpectQuery(queryIndexes).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-   table_name   |    index_name   | index_type  | column_name | included | primary | unique | constraint_type | predicate             |   expression              | desc | nulls_first | nulls_last | comment   | options
-----------------+-----------------+-------------+-------------+----------+---------+--------+-----------------+-----------------------+---------------------------+------+-------------+------------+-----------+-----------
+   table_name   |    index_name   | index_type  | column_name | included | primary | unique | constraint_type | predicate             |   expression              | desc | nulls_first | nulls_last | comment   | options                                | nulls_not_distinct
+----------------+-----------------+-------------+-------------+----------+---------+--------+-----------------+-----------------------+---------------------------+------+-------------+------------+-----------+----------------------------------------+--------------------
 users           | idx             | hash        |             | f        | f       | f      |                 |                       | "left"((c11)::text, 100)  | t    | t           | f          | boring    |
 users           | idx1            | btree       |             | f        | f       | f      |                 | (id <> NULL::integer) | "left"((c11)::text, 100)  | t    | t           | f          |           |
 users           | t1_c1_key       | btree       | c1          | f        | f       | t      | u               |                       | c1                        | t    | t           | f          |           |
@@ -325,6 +325,48 @@ users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1
 	}
 }
 
+func TestDriver_InspectNullsNotDistinct(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("150000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+    schema_name
+--------------------
+ public
+`))
+	mk.tableExists("public", "users", true)
+	mk.ExpectQuery(queryColumns).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+table_name | column_name |      data_type      | formatted |  is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp |  oid
+-----------+-------------+---------------------+-----------+--------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-------
+users      | c1          | bigint              | int8      |  YES         |                                 |                          |                64 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |    20
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQueryNullsDistinct, "$2"))).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+   table_name   |    index_name   | index_type  | column_name | included | primary | unique | constraint_type | predicate | expression | desc | nulls_first | nulls_last | comment | options | nulls_not_distinct
+----------------+-----------------+-------------+-------------+----------+---------+--------+-----------------+-----------+------------+------+-------------+------------+---------+---------+--------------------
+users           | users_c1_key    | btree       | c1          | f        | f       | t      | u               |           | c1         | f    | f           | t          |         |         | t
+users           | idx             | btree       | c1          | f        | f       | t      |                 |           | c1         | f    | f           | t          |         |         | f
+`))
+	mk.noFKs()
+	mk.noChecks()
+	s, err := drv.InspectSchema(context.Background(), "public", nil)
+	require.NoError(t, err)
+	idx, ok := s.Tables[0].Index("users_c1_key")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&IndexType{T: "btree"}, &ConType{T: "u"}, &IndexNullsDistinct{V: false}}, idx.Attrs)
+	idx, ok = s.Tables[0].Index("idx")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&IndexType{T: "btree"}}, idx.Attrs)
+}
+
 func TestDriver_InspectPartitionedTable(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -361,6 +403,16 @@ logs3      | c5         | integer   | integer   | NO          |                |
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression"}))
+	m.ExpectQuery(sqltest.Escape(partitionsQuery)).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ parent_name | partition_name |             partition_bound              | partition_key
+-------------+----------------+------------------------------------------+---------------
+ logs2       | logs2_a        | FOR VALUES FROM (1) TO (10)              |
+ logs2       | logs2_b        | FOR VALUES FROM (10) TO (20)             | HASH (c3)
+ logs2_b     | logs2_b0       | FOR VALUES WITH (modulus 2, remainder 0) |
+ logs3       | logs3_default  | DEFAULT                                  |
+`))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2, $3, $4"))).
@@ -380,6 +432,12 @@ logs3      | c5         | integer   | integer   | NO          |                |
 	require.Equal(t, []*PartitionPart{
 		{C: &schema.Column{Name: "c2", Type: &schema.ColumnType{Raw: "integer", Type: &schema.IntegerType{T: "integer"}}}},
 	}, key.Parts)
+	require.Len(t, key.Partitions, 2)
+	require.Equal(t, &TablePartition{Name: "logs2_a", Bound: "FOR VALUES FROM (1) TO (10)"}, key.Partitions[0])
+	require.Equal(t, "logs2_b", key.Partitions[1].Name)
+	require.Equal(t, PartitionTypeHash, key.Partitions[1].Sub.T)
+	require.Equal(t, []*PartitionPart{{C: t2.Columns[1]}}, key.Partitions[1].Sub.Parts)
+	require.Equal(t, []*TablePartition{{Name: "logs2_b0", Bound: "FOR VALUES WITH (modulus 2, remainder 0)"}}, key.Partitions[1].Sub.Partitions)
 
 	t3, ok := s.Table("logs3")
 	require.True(t, ok)
@@ -391,6 +449,133 @@ logs3      | c5         | integer   | integer   | NO          |                |
 		{X: &schema.RawExpr{X: "(a + b)"}},
 		{X: &schema.RawExpr{X: "(a + (b * 2))"}},
 	}, key.Parts)
+	require.Equal(t, []*TablePartition{{Name: "logs3_default", Bound: "DEFAULT"}}, key.Partitions)
+}
+
+func TestDriver_InspectPermissions(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= CURRENT_SCHEMA()"))).
+		WillReturnRows(sqltest.Rows(`
+   schema_name
+--------------------
+public
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ table_schema | table_name  | comment | partition_attrs | partition_strategy | partition_exprs
+--------------+-------------+---------+-----------------+--------------------+-----------------
+ public       | users       |         |                 |                    |
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "$2"))).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp | oid
+-----------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-----
+users      | tenant     | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  23
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "$2"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "$2"))).
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(rowSecurityQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | table_name | relrowsecurity | relforcerowsecurity
+-------------+------------+----------------+---------------------
+ public      | users      | t              | f
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(policiesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schemaname | tablename | policyname | permissive  | array_to_string |  cmd   |                          qual                         | with_check
+------------+-----------+------------+-------------+-----------------+--------+-------------------------------------------------------+------------
+ public     | users     | isolation  | PERMISSIVE  | public          | ALL    | (tenant = (current_setting('app.tenant'::text))::integer) |
+ public     | users     | readers    | RESTRICTIVE | app,reporting   | SELECT | true                                                  |
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(grantsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname | grantee | privilege_type
+---------+---------+---------+----------------
+ public  |         | app     | USAGE
+ public  | users   | app     | INSERT
+ public  | users   | app     | SELECT
+ public  | users   | PUBLIC  | SELECT
+`))
+	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{Mode: schema.InspectTables | schema.InspectPermissions})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&Grant{Grantee: "app", Privileges: []string{"USAGE"}}}, s.Attrs)
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{
+		&RowSecurity{Enabled: true},
+		&Policy{Name: "isolation", As: PolicyPermissive, Command: "ALL", Using: "(tenant = (current_setting('app.tenant'::text))::integer)"},
+		&Policy{Name: "readers", As: PolicyRestrictive, Command: "SELECT", Roles: []string{"app", "reporting"}, Using: "true"},
+		&Grant{Grantee: "app", Privileges: []string{"INSERT", "SELECT"}},
+		&Grant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}},
+	}, users.Attrs)
+}
+
+func TestDriver_InspectSequences(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= CURRENT_SCHEMA()"))).
+		WillReturnRows(sqltest.Rows(`
+   schema_name
+--------------------
+public
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ table_schema | table_name  | comment | partition_attrs | partition_strategy | partition_exprs
+--------------+-------------+---------+-----------------+--------------------+-----------------
+ public       | users       |         |                 |                    |
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "$2"))).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+table_name |column_name | data_type | formatted | is_nullable |          column_default           | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp | oid
+-----------+------------+-----------+-----------+-------------+-----------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-----
+users      | id         | integer   | integer   | NO          | nextval('users_id_seq'::regclass) |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  23
+users      | tenant     | integer   | integer   | NO          |                                   |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  23
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "$2"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "$2"))).
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequencesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname |   relname    | format_type | seqstart | seqincrement | seqmin | seqmax     | seqcache | seqcycle | owner_table | owner_column
+---------+--------------+-------------+----------+--------------+--------+------------+----------+----------+-------------+--------------
+ public  | counter      | integer     | 10       | 5            | 1      | 2147483647 | 1        | t        |             |
+ public  | tenant_seq   | bigint      | 1        | 1            | 1      | 1000       | 20       | f        | users       | tenant
+ public  | users_id_seq | integer     | 1        | 1            | 1      | 2147483647 | 1        | f        | users       | id
+`))
+	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{Mode: schema.InspectTables | schema.InspectSequences})
+	require.NoError(t, err)
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	// Sequences that back serial columns are skipped.
+	require.Equal(t, []*schema.Sequence{
+		schema.NewSequence("counter").SetSchema(s).SetType("integer").SetStart(10).SetIncrement(5).SetMin(1).SetMax(2147483647).SetCache(1).SetCycle(true),
+		schema.NewSequence("tenant_seq").SetSchema(s).SetType("bigint").SetStart(1).SetIncrement(1).SetMin(1).SetMax(1000).SetCache(20).SetOwner(users, users.Columns[1]),
+	}, s.Sequences)
 }
 
 func TestDriver_InspectCRDBSchema(t *testing.T) {
@@ -663,6 +848,101 @@ public
 	}(), realm)
 }
 
+func TestInspectMode_InspectObjects(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+   schema_name
+--------------------
+public
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(objectsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name |      kind      |   name    | signature  |         comment
+-------------+----------------+-----------+------------+-------------------------
+ public      | aggregate      | agg       | integer    | atlas:b1c560cac0649cd3
+ public      | operator       | ===       | text, text | NULL
+ public      | operator_class | text_ops  | gin        | managed by hand
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(unmanagedQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name |   type   |   name   |                                definition                                 | extra
+-------------+----------+----------+---------------------------------------------------------------------------+---------
+ public      | FUNCTION | inc      | CREATE OR REPLACE FUNCTION public.inc(i integer) RETURNS integer AS 'x'   | integer
+ public      | TRIGGER  | audit    | CREATE TRIGGER audit AFTER INSERT ON public.users EXECUTE FUNCTION log()  | users
+ public      | VIEW     | active   |  SELECT users.id FROM users;                                              | NULL
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectObjects | schema.InspectViews})
+	require.NoError(t, err)
+	require.Len(t, realm.Schemas, 1)
+	require.EqualValues(t, []schema.Attr{
+		&Object{Kind: ObjectAggregate, Name: "agg", Signature: "integer", Hash: "b1c560cac0649cd3"},
+		&Object{Kind: ObjectOperator, Name: "===", Signature: "text, text"},
+		&Object{Kind: ObjectOperatorClass, Name: "text_ops", Signature: "gin"},
+		schema.NewUnmanaged("FUNCTION", "inc(integer)", "CREATE OR REPLACE FUNCTION public.inc(i integer) RETURNS integer AS 'x'").
+			SetDrop(`DROP FUNCTION "public"."inc"(integer)`),
+		schema.NewUnmanaged("TRIGGER", "users.audit", "CREATE TRIGGER audit AFTER INSERT ON public.users EXECUTE FUNCTION log()").
+			SetDrop(`DROP TRIGGER "audit" ON "public"."users"`),
+	}, realm.Schemas[0].Attrs)
+	require.Len(t, realm.Schemas[0].Views, 1)
+	require.Equal(t, "active", realm.Schemas[0].Views[0].Name)
+	require.Equal(t, "SELECT users.id FROM users", realm.Schemas[0].Views[0].Def)
+	require.Equal(t, realm.Schemas[0], realm.Schemas[0].Views[0].Schema)
+}
+
+func TestInspectMode_InspectFuncs(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+   schema_name
+--------------------
+public
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(objectsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "kind", "name", "signature", "comment"}))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(funcsQuery, "$1", "p.prokind", "p.prokind IN ('f', 'p')"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name |  name   | kind |           args            |   identity   | result  |  lang   |       body
+-------------+---------+------+---------------------------+--------------+---------+---------+------------------
+ public      | add     | f    | a integer, b integer = 1  | a integer, b integer | integer | sql     | SELECT a + b
+ public      | archive | p    | id integer                | id integer   | NULL    | plpgsql | BEGIN NULL; END
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(unmanagedQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name |   type   |   name   |                                definition                                        | extra
+-------------+----------+----------+----------------------------------------------------------------------------------+---------
+ public      | FUNCTION | add      | CREATE OR REPLACE FUNCTION public.add(a integer, b integer) RETURNS integer      | a integer, b integer
+ public      | FUNCTION | archive  | CREATE OR REPLACE PROCEDURE public.archive(id integer)                           | id integer
+ public      | FUNCTION | inc      | CREATE OR REPLACE FUNCTION public.inc(i integer) RETURNS integer IMMUTABLE       | i integer
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectObjects | schema.InspectFuncs})
+	require.NoError(t, err)
+	require.Len(t, realm.Schemas, 1)
+	s := realm.Schemas[0]
+	require.Equal(t, []*schema.Func{schema.NewFunc("add", "a integer, b integer = 1", "integer", "SELECT a + b").SetLang("sql").SetSchema(s)}, s.Funcs)
+	require.Equal(t, []*schema.Proc{schema.NewProc("archive", "id integer", "BEGIN NULL; END").SetLang("plpgsql").SetSchema(s)}, s.Procs)
+	// Functions with options that are not modeled are kept as unmanaged objects.
+	require.EqualValues(t, []schema.Attr{
+		schema.NewUnmanaged("FUNCTION", "inc(i integer)", "CREATE OR REPLACE FUNCTION public.inc(i integer) RETURNS integer IMMUTABLE").
+			SetDrop(`DROP FUNCTION "public"."inc"(i integer)`),
+	}, s.Attrs)
+}
+
 type mock struct {
 	sqlmock.Sqlmock
 }
@@ -702,3 +982,30 @@ func (m mock) noChecks() {
 	m.ExpectQuery(queryChecks).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
 }
+
+func TestTriggerFromDef(t *testing.T) {
+	tests := []struct {
+		def  string
+		want *schema.Trigger
+	}{
+		{
+			def:  "CREATE TRIGGER t BEFORE INSERT OR UPDATE OF name ON public.users FOR EACH ROW EXECUTE FUNCTION audit()",
+			want: schema.NewTrigger("t", "BEFORE", []string{"INSERT", "UPDATE OF name"}, "EXECUTE FUNCTION audit()"),
+		},
+		{
+			def:  `CREATE TRIGGER "my t" AFTER DELETE ON "my schema"."my ""table""" FOR EACH STATEMENT WHEN (pg_trigger_depth() = 0) EXECUTE PROCEDURE audit('a')`,
+			want: schema.NewTrigger("t", "AFTER", []string{"DELETE"}, "EXECUTE PROCEDURE audit('a')").SetForEachRow(false).SetWhen("pg_trigger_depth() = 0"),
+		},
+		{def: "CREATE CONSTRAINT TRIGGER t AFTER INSERT ON public.users DEFERRABLE INITIALLY DEFERRED FOR EACH ROW EXECUTE FUNCTION check()"},
+		{def: "CREATE TRIGGER t AFTER INSERT ON public.users REFERENCING NEW TABLE AS n FOR EACH STATEMENT EXECUTE FUNCTION audit()"},
+	}
+	for _, tt := range tests {
+		tr, ok := triggerFromDef("t", tt.def)
+		if tt.want == nil {
+			require.False(t, ok)
+			continue
+		}
+		require.True(t, ok)
+		require.Equal(t, tt.want, tr)
+	}
+}
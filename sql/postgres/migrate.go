@@ -6,8 +6,11 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -24,9 +27,8 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 	s := &state{
 		conn: p.conn,
 		Plan: migrate.Plan{
-			Name:          name,
-			Reversible:    true,
-			Transactional: true,
+			Name:       name,
+			Reversible: true,
 		},
 		created: make(map[string]*schema.EnumType),
 		altered: make(map[string]*schema.EnumType),
@@ -35,6 +37,15 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 	for _, o := range opts {
 		o(&s.PlanOptions)
 	}
+	// Plan the changes for the target version, instead of the connected one.
+	if s.TargetVersion != "" {
+		v, err := parseVersion(s.TargetVersion)
+		if err != nil {
+			return nil, err
+		}
+		s.version = v
+	}
+	s.Transactional = s.capabilities().Is(migrate.CapTransactionalDDL)
 	if err := s.plan(ctx, changes); err != nil {
 		return nil, err
 	}
@@ -63,6 +74,11 @@ type state struct {
 	// Track the enums that were created, altered and
 	// dropped, in this phase to avoid duplicate updates.
 	created, altered, dropped map[string]*schema.EnumType
+	// Changes that are planned after all table changes.
+	deferred []*migrate.Change
+	// Access control changes that are planned after functions
+	// and views, as policies may use them in their expressions.
+	access []*migrate.Change
 }
 
 // Exec executes the changes on the database. An error is returned
@@ -73,8 +89,23 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) error {
 			return err
 		}
 	}
-	planned := s.topLevel(changes)
-	planned, err := sqlx.DetachCycles(planned)
+	dropT, changes, addT := sqlx.SplitTriggers(changes)
+	if err := s.dropTriggers(dropT); err != nil {
+		return err
+	}
+	drop, changes, add := sqlx.SplitViews(changes)
+	s.views(drop)
+	dropF, changes, addF := sqlx.SplitFuncs(changes)
+	s.funcs(dropF)
+	addS, changes, modifyS := sqlx.SplitSequences(changes)
+	planned, err := s.topLevel(changes)
+	if err != nil {
+		return err
+	}
+	// Sequences are created before the tables, as they may be used by column
+	// defaults, and their owners are set after the tables are created.
+	s.sequences(addS, changes)
+	planned, err = sqlx.DetachCycles(planned)
 	if err != nil {
 		return err
 	}
@@ -95,11 +126,411 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) error {
 			return err
 		}
 	}
+	s.append(s.deferred...)
+	s.sequences(modifyS, changes)
+	// Functions are created before views, as they are
+	// commonly used in view definitions.
+	s.funcs(addF)
+	s.views(add)
+	s.append(s.access...)
+	return s.addTriggers(addT)
+}
+
+// views plans the given view changes. Modified views are
+// replaced in place, as they may be referenced by other views.
+func (s *state) views(changes []schema.Change) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddView:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("CREATE VIEW").View(c.V).P("AS", c.V.Def).String(),
+				Source:  c,
+				Reverse: s.Build("DROP VIEW").View(c.V).String(),
+				Comment: fmt.Sprintf("create %q view", c.V.Name),
+			})
+		case *schema.DropView:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("DROP VIEW").View(c.V).String(),
+				Source:  c,
+				Reverse: s.Build("CREATE VIEW").View(c.V).P("AS", c.V.Def).String(),
+				Comment: fmt.Sprintf("drop %q view", c.V.Name),
+			})
+		case *schema.ModifyView:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("CREATE OR REPLACE VIEW").View(c.To).P("AS", c.To.Def).String(),
+				Source:  c,
+				Reverse: s.Build("CREATE OR REPLACE VIEW").View(c.From).P("AS", c.From.Def).String(),
+				Comment: fmt.Sprintf("modify %q view", c.To.Name),
+			})
+		}
+	}
+}
+
+// sequences plans the given sequence changes. Sequences that are owned by
+// tables or columns that are dropped by the other changes are dropped by
+// the database, and therefore skipped.
+func (s *state) sequences(changes, others []schema.Change) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSequence:
+			s.append(&migrate.Change{
+				Cmd:     s.createSequence(c.S),
+				Source:  c,
+				Reverse: s.Build("DROP SEQUENCE").Sequence(c.S).String(),
+				Comment: fmt.Sprintf("create %q sequence", c.S.Name),
+			})
+			if o := c.S.Owner; o != nil && o.T != nil && o.C != nil {
+				s.deferred = append(s.deferred, &migrate.Change{
+					Cmd:     s.Build("ALTER SEQUENCE").Sequence(c.S).P("OWNED BY", s.sequenceOwner(o)).String(),
+					Source:  c,
+					Reverse: s.Build("ALTER SEQUENCE").Sequence(c.S).P("OWNED BY NONE").String(),
+					Comment: fmt.Sprintf("set %q sequence owner", c.S.Name),
+				})
+			}
+		case *schema.DropSequence:
+			if ownerDropped(c.S.Owner, others) {
+				continue
+			}
+			s.append(&migrate.Change{
+				Cmd:     s.Build("DROP SEQUENCE").Sequence(c.S).String(),
+				Source:  c,
+				Reverse: s.createSequence(c.S),
+				Comment: fmt.Sprintf("drop %q sequence", c.S.Name),
+			})
+		case *schema.ModifySequence:
+			if cmd := s.alterSequence(c.From, c.To); cmd != "" {
+				s.append(&migrate.Change{
+					Cmd:     cmd,
+					Source:  c,
+					Reverse: s.alterSequence(c.To, c.From),
+					Comment: fmt.Sprintf("modify %q sequence", c.To.Name),
+				})
+			}
+		}
+	}
+}
+
+// createSequence returns the statement for creating the given sequence.
+// The owner of the sequence is set separately, after its table is created.
+func (s *state) createSequence(seq *schema.Sequence) string {
+	b := s.Build("CREATE SEQUENCE").Sequence(seq)
+	if seq.Type != "" {
+		b.P("AS", seq.Type)
+	}
+	if seq.Increment != 0 {
+		b.P("INCREMENT BY", strconv.FormatInt(seq.Increment, 10))
+	}
+	if seq.Min != nil {
+		b.P("MINVALUE", strconv.FormatInt(*seq.Min, 10))
+	}
+	if seq.Max != nil {
+		b.P("MAXVALUE", strconv.FormatInt(*seq.Max, 10))
+	}
+	if seq.Start != 0 {
+		b.P("START WITH", strconv.FormatInt(seq.Start, 10))
+	}
+	if seq.Cache != 0 {
+		b.P("CACHE", strconv.FormatInt(seq.Cache, 10))
+	}
+	if seq.Cycle {
+		b.P("CYCLE")
+	}
+	return b.String()
+}
+
+// alterSequence returns the statement for changing the options of the
+// sequence from its current state to the desired one, or an empty string
+// if there is nothing to change.
+func (s *state) alterSequence(from, to *schema.Sequence) string {
+	var (
+		b      = s.Build("ALTER SEQUENCE").Sequence(to)
+		s1, s2 = sequenceDefaults(from), sequenceDefaults(to)
+		n      = b.Len()
+	)
+	if s1.Type != s2.Type {
+		b.P("AS", s2.Type)
+	}
+	if s1.Increment != s2.Increment {
+		b.P("INCREMENT BY", strconv.FormatInt(s2.Increment, 10))
+	}
+	switch {
+	case *s1.Min == *s2.Min:
+	case to.Min == nil:
+		b.P("NO MINVALUE")
+	default:
+		b.P("MINVALUE", strconv.FormatInt(*to.Min, 10))
+	}
+	switch {
+	case *s1.Max == *s2.Max:
+	case to.Max == nil:
+		b.P("NO MAXVALUE")
+	default:
+		b.P("MAXVALUE", strconv.FormatInt(*to.Max, 10))
+	}
+	if s1.Start != s2.Start {
+		b.P("START WITH", strconv.FormatInt(s2.Start, 10))
+	}
+	if s1.Cache != s2.Cache {
+		b.P("CACHE", strconv.FormatInt(s2.Cache, 10))
+	}
+	if s1.Cycle != s2.Cycle {
+		b.P(map[bool]string{true: "CYCLE", false: "NO CYCLE"}[s2.Cycle])
+	}
+	if sqlx.SequenceOwnerChanged(from.Owner, to.Owner) {
+		if o := to.Owner; o != nil && o.T != nil && o.C != nil {
+			b.P("OWNED BY", s.sequenceOwner(o))
+		} else {
+			b.P("OWNED BY NONE")
+		}
+	}
+	if b.Len() == n {
+		return ""
+	}
+	return b.String()
+}
+
+// sequenceOwner returns the qualified column name of the sequence owner.
+func (s *state) sequenceOwner(o *schema.SequenceOwner) string {
+	return s.Build().Table(o.T).String() + "." + s.Build().Ident(o.C.Name).String()
+}
+
+// ownerDropped reports if the owner table or column
+// of the sequence is dropped by the given changes.
+func ownerDropped(o *schema.SequenceOwner, changes []schema.Change) bool {
+	if o == nil || o.T == nil || o.C == nil {
+		return false
+	}
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.DropTable:
+			if sameTable(c.T, o.T) {
+				return true
+			}
+		case *schema.ModifyTable:
+			if !sameTable(c.T, o.T) {
+				continue
+			}
+			for _, tc := range c.Changes {
+				if d, ok := tc.(*schema.DropColumn); ok && d.C.Name == o.C.Name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// sameTable reports if the two tables have the same qualified name.
+func sameTable(t1, t2 *schema.Table) bool {
+	if t1.Name != t2.Name {
+		return false
+	}
+	if t1.Schema == nil || t2.Schema == nil {
+		return true
+	}
+	return t1.Schema.Name == t2.Schema.Name
+}
+
+// funcs plans the given function and procedure changes. Modified routines are
+// replaced in place, unless the return type of the function was changed.
+func (s *state) funcs(changes []schema.Change) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddFunc:
+			s.append(&migrate.Change{
+				Cmd:     s.createFunc("CREATE FUNCTION", c.F),
+				Source:  c,
+				Reverse: s.dropFunc(c.F),
+				Comment: fmt.Sprintf("create %q function", c.F.Name),
+			})
+		case *schema.DropFunc:
+			s.append(&migrate.Change{
+				Cmd:     s.dropFunc(c.F),
+				Source:  c,
+				Reverse: s.createFunc("CREATE FUNCTION", c.F),
+				Comment: fmt.Sprintf("drop %q function", c.F.Name),
+			})
+		case *schema.ModifyFunc:
+			// The return type of a function cannot be changed with CREATE OR REPLACE.
+			if sqlx.ViewDef(c.From.Ret) != sqlx.ViewDef(c.To.Ret) {
+				s.append(&migrate.Change{
+					Cmd:     s.dropFunc(c.From),
+					Source:  c,
+					Reverse: s.createFunc("CREATE FUNCTION", c.From),
+					Comment: fmt.Sprintf("drop %q function", c.From.Name),
+				}, &migrate.Change{
+					Cmd:     s.createFunc("CREATE FUNCTION", c.To),
+					Source:  c,
+					Reverse: s.dropFunc(c.To),
+					Comment: fmt.Sprintf("create %q function", c.To.Name),
+				})
+				continue
+			}
+			s.append(&migrate.Change{
+				Cmd:     s.createFunc("CREATE OR REPLACE FUNCTION", c.To),
+				Source:  c,
+				Reverse: s.createFunc("CREATE OR REPLACE FUNCTION", c.From),
+				Comment: fmt.Sprintf("modify %q function", c.To.Name),
+			})
+		case *schema.AddProc:
+			s.append(&migrate.Change{
+				Cmd:     s.createProc("CREATE PROCEDURE", c.P),
+				Source:  c,
+				Reverse: s.dropProc(c.P),
+				Comment: fmt.Sprintf("create %q procedure", c.P.Name),
+			})
+		case *schema.DropProc:
+			s.append(&migrate.Change{
+				Cmd:     s.dropProc(c.P),
+				Source:  c,
+				Reverse: s.createProc("CREATE PROCEDURE", c.P),
+				Comment: fmt.Sprintf("drop %q procedure", c.P.Name),
+			})
+		case *schema.ModifyProc:
+			s.append(&migrate.Change{
+				Cmd:     s.createProc("CREATE OR REPLACE PROCEDURE", c.To),
+				Source:  c,
+				Reverse: s.createProc("CREATE OR REPLACE PROCEDURE", c.From),
+				Comment: fmt.Sprintf("modify %q procedure", c.To.Name),
+			})
+		}
+	}
+}
+
+// createFunc returns the statement for creating the given function.
+func (s *state) createFunc(verb string, f *schema.Func) string {
+	b := s.Build(verb).Func(f)
+	return fmt.Sprintf("%s(%s) RETURNS %s %s", b.String(), f.Args, f.Ret, s.Build().P(lang(f.Lang), "AS", dollarQuote(f.Body)).String())
+}
+
+// dropFunc returns the statement for dropping the given function.
+func (s *state) dropFunc(f *schema.Func) string {
+	return fmt.Sprintf("%s(%s)", s.Build("DROP FUNCTION").Func(f).String(), funcIdentity(f.Args))
+}
+
+// createProc returns the statement for creating the given procedure.
+func (s *state) createProc(verb string, p *schema.Proc) string {
+	b := s.Build(verb).Proc(p)
+	return fmt.Sprintf("%s(%s) %s", b.String(), p.Args, s.Build().P(lang(p.Lang), "AS", dollarQuote(p.Body)).String())
+}
+
+// dropProc returns the statement for dropping the given procedure.
+func (s *state) dropProc(p *schema.Proc) string {
+	return fmt.Sprintf("%s(%s)", s.Build("DROP PROCEDURE").Proc(p).String(), funcIdentity(p.Args))
+}
+
+// lang returns the LANGUAGE clause of a routine, if its language is set.
+func lang(l string) string {
+	if l == "" {
+		return ""
+	}
+	return "LANGUAGE " + l
+}
+
+// dollarQuote returns the given routine body as a dollar-quoted
+// string, with a tag that does not appear in the body.
+func dollarQuote(body string) string {
+	tag := "$$"
+	for i := 0; strings.Contains(body, tag); i++ {
+		tag = fmt.Sprintf("$body%d$", i)
+	}
+	return tag + body + tag
+}
+
+// funcIdentity returns the arguments of a routine without their default values,
+// as expected by the DROP FUNCTION and DROP PROCEDURE statements.
+func funcIdentity(args string) string {
+	var (
+		parts       []string
+		depth, last int
+		quoted      bool
+	)
+	for i := 0; i <= len(args); i++ {
+		switch {
+		case i == len(args) || !quoted && depth == 0 && args[i] == ',':
+			parts = append(parts, strings.TrimSpace(args[last:i]))
+			last = i + 1
+		case args[i] == '\'':
+			quoted = !quoted
+		case !quoted && args[i] == '(':
+			depth++
+		case !quoted && args[i] == ')':
+			depth--
+		}
+	}
+	for i, p := range parts {
+		if j := reFuncDefault.FindStringIndex(p); j != nil {
+			parts[i] = strings.TrimSpace(p[:j[0]])
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reFuncDefault matches the beginning of a default value of a routine argument.
+var reFuncDefault = regexp.MustCompile(`(?i)\s+(DEFAULT\s|=)`)
+
+// dropTriggers plans the given trigger changes that should be executed before
+// all other changes. Modified triggers are dropped, and created again later.
+func (s *state) dropTriggers(changes []schema.Change) error {
+	for _, c := range changes {
+		var t *schema.Trigger
+		switch c := c.(type) {
+		case *schema.DropTrigger:
+			t = c.T
+		case *schema.ModifyTrigger:
+			t = c.From
+		default:
+			return fmt.Errorf("unexpected trigger change %T", c)
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.Build("DROP TRIGGER").Ident(t.Name).P("ON").Table(t.Table).String(),
+			Source:  c,
+			Reverse: s.createTrigger(t),
+			Comment: fmt.Sprintf("drop %q trigger", t.Name),
+		})
+	}
+	return nil
+}
+
+// addTriggers plans the given trigger changes that
+// should be executed after all other changes.
+func (s *state) addTriggers(changes []schema.Change) error {
+	for _, c := range changes {
+		var t *schema.Trigger
+		switch c := c.(type) {
+		case *schema.AddTrigger:
+			t = c.T
+		case *schema.ModifyTrigger:
+			t = c.To
+		default:
+			return fmt.Errorf("unexpected trigger change %T", c)
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.createTrigger(t),
+			Source:  c,
+			Reverse: s.Build("DROP TRIGGER").Ident(t.Name).P("ON").Table(t.Table).String(),
+			Comment: fmt.Sprintf("create %q trigger", t.Name),
+		})
+	}
 	return nil
 }
 
+// createTrigger returns the statement for creating the given trigger.
+func (s *state) createTrigger(t *schema.Trigger) string {
+	b := s.Build("CREATE TRIGGER").Ident(t.Name).P(t.ActionTime, strings.Join(t.Events, " OR "), "ON").Table(t.Table).P("FOR EACH")
+	if t.ForEachRow {
+		b.P("ROW")
+	} else {
+		b.P("STATEMENT")
+	}
+	if t.When != "" {
+		b.P("WHEN", sqlx.MayWrap(t.When))
+	}
+	return b.P(t.Body).String()
+}
+
 // topLevel executes first the changes for creating or dropping schemas (top-level schema elements).
-func (s *state) topLevel(changes []schema.Change) []schema.Change {
+func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 	planned := make([]schema.Change, 0, len(changes))
 	for _, c := range changes {
 		switch c := c.(type) {
@@ -115,6 +546,21 @@ func (s *state) topLevel(changes []schema.Change) []schema.Change {
 				Reverse: s.Build("DROP SCHEMA").Ident(c.S.Name).P("CASCADE").String(),
 				Comment: fmt.Sprintf("Add new schema named %q", c.S.Name),
 			})
+			var objects []schema.Change
+			for _, a := range c.S.Attrs {
+				switch a := a.(type) {
+				case *Object, *Grant:
+					objects = append(objects, &schema.AddAttr{A: a})
+				}
+			}
+			if err := s.modifySchema(&schema.ModifySchema{S: c.S, Changes: objects}); err != nil {
+				return nil, err
+			}
+			_, unmanaged, err := sqlx.UnmanagedChanges(c)
+			if err != nil {
+				return nil, err
+			}
+			s.deferred = append(s.deferred, unmanaged...)
 		case *schema.DropSchema:
 			b := s.Build("DROP SCHEMA")
 			if sqlx.Has(c.Extra, &schema.IfExists{}) {
@@ -126,11 +572,222 @@ func (s *state) topLevel(changes []schema.Change) []schema.Change {
 				Source:  c,
 				Comment: fmt.Sprintf("Drop schema named %q", c.S.Name),
 			})
+		case *schema.ModifyRealm:
+			if err := s.modifyRealm(c); err != nil {
+				return nil, err
+			}
+		case *schema.ModifySchema:
+			rest, unmanaged, err := sqlx.UnmanagedChanges(c)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.modifySchema(&schema.ModifySchema{S: c.S, Changes: rest}); err != nil {
+				return nil, err
+			}
+			s.deferred = append(s.deferred, unmanaged...)
 		default:
 			planned = append(planned, c)
 		}
 	}
-	return planned
+	return planned, nil
+}
+
+// modifyRealm builds and appends the migrate.Changes for bringing the
+// settings of the connected database into their modified state. Since
+// the database name is not known at planning time (e.g. when planning on
+// a dev database), the changes are executed using dynamic SQL.
+func (s *state) modifyRealm(modify *schema.ModifyRealm) error {
+	for _, change := range modify.Changes {
+		to, from, err := sqlx.SettingChange(change)
+		if err != nil {
+			return err
+		}
+		cmd, err := alterDatabase(fmt.Sprintf("SET %s = %s", to.Name, settingValue(to.V)))
+		if err != nil {
+			return err
+		}
+		// Settings that were not set before are reset.
+		r := "RESET " + to.Name
+		if from != nil {
+			r = fmt.Sprintf("SET %s = %s", to.Name, settingValue(from.V))
+		}
+		if r, err = alterDatabase(r); err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     cmd,
+			Reverse: r,
+			Source:  modify,
+			Comment: fmt.Sprintf("set %q setting", to.Name),
+		})
+	}
+	return nil
+}
+
+// alterDatabase returns a statement that executes the given
+// ALTER DATABASE clause on the current database.
+func alterDatabase(clause string) (string, error) {
+	if strings.Contains(clause, "$$") {
+		return "", fmt.Errorf("unexpected $$ in setting clause: %s", clause)
+	}
+	clause = strings.NewReplacer("'", "''", "%", "%%").Replace(clause)
+	return fmt.Sprintf("DO $$ BEGIN EXECUTE format('ALTER DATABASE %%I %s', current_database()); END $$", clause), nil
+}
+
+// reSettingList matches setting values that are written as-is,
+// such as names or lists of names. e.g. '"$user", public'.
+var reSettingList = regexp.MustCompile(`^[\w$"]+(\s*,\s*[\w$"]+)*$`)
+
+// settingValue returns the SQL representation of a setting value.
+func settingValue(v string) string {
+	if reSettingList.MatchString(v) {
+		return v
+	}
+	return quote(v)
+}
+
+// objectOrder defines the order in which objects are created,
+// as operator classes may use aggregates and operators.
+var objectOrder = map[string]int{
+	ObjectAggregate:     1,
+	ObjectOperator:      2,
+	ObjectOperatorClass: 3,
+}
+
+// modifySchema builds and appends the migrate.Changes for creating, replacing
+// and dropping the objects of the schema. Objects are created before the tables
+// and dropped after them, as tables may depend on them (e.g. an index that uses
+// an operator class).
+func (s *state) modifySchema(modify *schema.ModifySchema) error {
+	var add, replace, drop []*Object
+	for _, c := range modify.Changes {
+		if isAccessChange(c) {
+			if err := s.grantChange(c, "SCHEMA", s.Build().Ident(modify.S.Name).String()); err != nil {
+				return err
+			}
+			continue
+		}
+		switch c := c.(type) {
+		case *schema.AddAttr:
+			o, ok := c.A.(*Object)
+			if !ok {
+				return fmt.Errorf("unsupported schema attribute %T", c.A)
+			}
+			add = append(add, o)
+		case *schema.ModifyAttr:
+			from, ok1 := c.From.(*Object)
+			to, ok2 := c.To.(*Object)
+			if !ok1 || !ok2 {
+				return fmt.Errorf("unsupported schema attribute %T", c.To)
+			}
+			// Objects that were not created by Atlas are adopted
+			// by setting their hash, instead of being replaced.
+			if from.Hash == "" {
+				s.append(s.objectComment(modify.S, to))
+				continue
+			}
+			replace = append(replace, from)
+			add = append(add, to)
+		case *schema.DropAttr:
+			o, ok := c.A.(*Object)
+			if !ok {
+				return fmt.Errorf("unsupported schema attribute %T", c.A)
+			}
+			drop = append(drop, o)
+		default:
+			return fmt.Errorf("unsupported schema change %T", c)
+		}
+	}
+	for _, objects := range [][]*Object{add, replace, drop} {
+		sort.SliceStable(objects, func(i, j int) bool {
+			return objectOrder[objects[i].Kind] < objectOrder[objects[j].Kind]
+		})
+	}
+	// Objects are dropped in reverse order of their creation.
+	for i := len(replace) - 1; i >= 0; i-- {
+		s.append(s.dropObject(modify.S, replace[i]))
+	}
+	for i := len(drop) - 1; i >= 0; i-- {
+		s.deferred = append(s.deferred, s.dropObject(modify.S, drop[i]))
+	}
+	for _, o := range add {
+		if o.Def == "" {
+			return fmt.Errorf("missing definition for %s %q", objectType(o), o.Name)
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.createObject(modify.S, o),
+			Reverse: s.dropObject(modify.S, o).Cmd,
+			Comment: fmt.Sprintf("create %s %q", objectType(o), o.Name),
+		}, s.objectComment(modify.S, o))
+	}
+	return nil
+}
+
+// dropObject returns the change for dropping the object. Inspected objects
+// have no definition, and therefore their drop cannot be reversed.
+func (s *state) dropObject(ns *schema.Schema, o *Object) *migrate.Change {
+	c := &migrate.Change{
+		Cmd:     s.Build("DROP", objectType(o), s.objectIdent(ns, o)).String(),
+		Comment: fmt.Sprintf("drop %s %q", objectType(o), o.Name),
+	}
+	if o.Def != "" {
+		c.Reverse = s.createObject(ns, o)
+	}
+	return c
+}
+
+// createObject returns the statement for creating the object.
+func (s *state) createObject(ns *schema.Schema, o *Object) string {
+	b := s.Build("CREATE", objectType(o), s.objectName(ns, o))
+	switch o.Kind {
+	case ObjectAggregate:
+		b.P(fmt.Sprintf("(%s) (%s)", o.Signature, o.Def))
+	case ObjectOperator:
+		b.P(fmt.Sprintf("(%s)", o.Def))
+	default:
+		b.P(o.Def)
+	}
+	return b.String()
+}
+
+// objectComment returns the change for storing the hash of the object in its comment.
+func (s *state) objectComment(ns *schema.Schema, o *Object) *migrate.Change {
+	return &migrate.Change{
+		Cmd:     s.Build("COMMENT ON", objectType(o), s.objectIdent(ns, o), "IS", quote(objectHashPrefix+o.Hash)).String(),
+		Reverse: s.Build("COMMENT ON", objectType(o), s.objectIdent(ns, o), "IS NULL").String(),
+		Comment: fmt.Sprintf("set hash of %s %q", objectType(o), o.Name),
+	}
+}
+
+// objectIdent returns the identifier of the object in DROP and COMMENT statements.
+func (s *state) objectIdent(ns *schema.Schema, o *Object) string {
+	if o.Kind == ObjectOperatorClass {
+		return fmt.Sprintf("%s USING %s", s.objectName(ns, o), o.Signature)
+	}
+	return fmt.Sprintf("%s (%s)", s.objectName(ns, o), o.Signature)
+}
+
+// objectName returns the qualified name of the object.
+func (s *state) objectName(ns *schema.Schema, o *Object) string {
+	name := strconv.Quote(o.Name)
+	// Operator names are not quoted.
+	if o.Kind == ObjectOperator {
+		name = o.Name
+	}
+	switch {
+	case s.SchemaQualifier != nil:
+		if *s.SchemaQualifier != "" {
+			name = fmt.Sprintf("%q.%s", *s.SchemaQualifier, name)
+		}
+	case ns != nil && ns.Name != "":
+		name = fmt.Sprintf("%q.%s", ns.Name, name)
+	}
+	return name
+}
+
+// objectType returns the SQL type of the object. e.g. OPERATOR CLASS.
+func objectType(o *Object) string {
+	return strings.ToUpper(strings.ReplaceAll(o.Kind, "_", " "))
 }
 
 // addTable builds and executes the query for creating a table in a schema.
@@ -169,12 +826,20 @@ func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 		}
 	})
 	if p := (Partition{}); sqlx.Has(add.T.Attrs, &p) {
+		if strings.ToUpper(p.T) == PartitionTypeHash && !s.supportsHashPartition() {
+			errs = append(errs, s.unsupported("hash partitioning", 11_00_00).Error())
+		}
 		s, err := formatPartition(p)
 		if err != nil {
 			errs = append(errs, err.Error())
 		}
 		b.P(s)
 	}
+	for _, idx := range add.T.Indexes {
+		if err := s.checkIndex(idx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
 	if len(errs) > 0 {
 		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
 	}
@@ -186,6 +851,32 @@ func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 	})
 	s.addIndexes(add.T, add.T.Indexes...)
 	s.addComments(add.T)
+	var access []schema.Change
+	for _, a := range add.T.Attrs {
+		switch a := a.(type) {
+		case *RowSecurity:
+			access = append(access, &schema.ModifyAttr{From: &RowSecurity{}, To: a})
+		case *Policy, *Grant:
+			access = append(access, &schema.AddAttr{A: a})
+		}
+	}
+	if err := s.tableAccess(add.T, access); err != nil {
+		return err
+	}
+	if p, ok := partitionKey(add.T.Attrs); ok {
+		for _, c := range p.Partitions {
+			if err := s.createPartition(add, add.T, c); err != nil {
+				return err
+			}
+		}
+	}
+	if r := (schema.Retention{}); sqlx.Has(add.T.Attrs, &r) {
+		changes, err := s.retention(add.T, &schema.AddAttr{A: &r})
+		if err != nil {
+			return err
+		}
+		s.append(changes...)
+	}
 	return nil
 }
 
@@ -208,9 +899,29 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 	var (
 		alter       []schema.Change
 		addI, dropI []*schema.Index
+		addF        []*schema.ForeignKey
+		notNull     []*schema.Column
 		changes     []*migrate.Change
+		parts       []schema.Change
+		access      []schema.Change
 	)
 	for _, change := range skipAutoChanges(modify.Changes) {
+		if isPartitionChange(change) {
+			parts = append(parts, change)
+			continue
+		}
+		if isAccessChange(change) {
+			access = append(access, change)
+			continue
+		}
+		if isRetentionChange(change) {
+			c, err := s.retention(modify.T, change)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, c...)
+			continue
+		}
 		switch change := change.(type) {
 		case *schema.AddAttr, *schema.ModifyAttr:
 			from, to, err := commentChange(change)
@@ -256,14 +967,39 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 				Cmd:     s.Build("ALTER INDEX").Ident(change.From.Name).P("RENAME TO").Ident(change.To.Name).String(),
 				Reverse: s.Build("ALTER INDEX").Ident(change.To.Name).P("RENAME TO").Ident(change.From.Name).String(),
 			})
+		case *schema.RenameForeignKey:
+			changes = append(changes, &migrate.Change{
+				Source:  change,
+				Comment: fmt.Sprintf("rename a foreign-key from %q to %q", change.From.Symbol, change.To.Symbol),
+				Cmd:     s.Build("ALTER TABLE").Table(modify.T).P("RENAME CONSTRAINT").Ident(change.From.Symbol).P("TO").Ident(change.To.Symbol).String(),
+				Reverse: s.Build("ALTER TABLE").Table(modify.T).P("RENAME CONSTRAINT").Ident(change.To.Symbol).P("TO").Ident(change.From.Symbol).String(),
+			})
+		case *schema.RenameCheck:
+			changes = append(changes, &migrate.Change{
+				Source:  change,
+				Comment: fmt.Sprintf("rename a check constraint from %q to %q", change.From.Name, change.To.Name),
+				Cmd:     s.Build("ALTER TABLE").Table(modify.T).P("RENAME CONSTRAINT").Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
+				Reverse: s.Build("ALTER TABLE").Table(modify.T).P("RENAME CONSTRAINT").Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
+			})
 		case *schema.ModifyForeignKey:
 			// Foreign-key modification is translated into 2 steps.
 			// Dropping the current foreign key and creating a new one.
 			alter = append(alter, &schema.DropForeignKey{
 				F: change.From,
-			}, &schema.AddForeignKey{
-				F: change.To,
 			})
+			if s.ZeroDowntime {
+				addF = append(addF, change.To)
+			} else {
+				alter = append(alter, &schema.AddForeignKey{
+					F: change.To,
+				})
+			}
+		case *schema.AddForeignKey:
+			if s.ZeroDowntime {
+				addF = append(addF, change.F)
+			} else {
+				alter = append(alter, change)
+			}
 		case *schema.AddColumn:
 			if err := s.mayAddEnums(ctx, modify.T, change.C); err != nil {
 				return err
@@ -285,6 +1021,13 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 					continue
 				}
 			}
+			// Setting the NOT NULL constraint is executed in separate steps.
+			if s.ZeroDowntime && k.Is(schema.ChangeNull) && !change.To.Type.Null {
+				notNull = append(notNull, change.To)
+				if k &= ^schema.ChangeNull; k.Is(schema.NoChange) {
+					continue
+				}
+			}
 			from, ok1 := hasEnumType(change.From)
 			to, ok2 := hasEnumType(change.To)
 			switch {
@@ -320,17 +1063,624 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 			alter = append(alter, change)
 		}
 	}
-	s.dropIndexes(modify.T, dropI...)
+	for _, idx := range addI {
+		if err := s.checkIndex(idx); err != nil {
+			return fmt.Errorf("modify table %q: %w", modify.T.Name, err)
+		}
+	}
+	if s.ZeroDowntime {
+		// Indexes that back UNIQUE constraints cannot be dropped using DROP INDEX,
+		// and therefore, they are dropped with their constraints in the ALTER TABLE.
+		concurrent := make([]*schema.Index, 0, len(dropI))
+		for _, idx := range dropI {
+			if isUniqueConstraint(idx) {
+				alter = append(alter, &schema.DropIndex{I: idx})
+			} else {
+				concurrent = append(concurrent, idx)
+			}
+		}
+		s.dropIndexesConcurrently(modify.T, concurrent...)
+	} else {
+		s.dropIndexes(modify.T, dropI...)
+	}
 	if len(alter) > 0 {
 		if err := s.alterTable(modify.T, alter); err != nil {
 			return err
 		}
 	}
-	s.addIndexes(modify.T, addI...)
+	if s.ZeroDowntime {
+		s.addIndexesConcurrently(modify.T, addI...)
+		s.addForeignKeysNotValid(modify.T, addF...)
+		s.setNotNull(modify.T, notNull...)
+	} else {
+		s.addIndexes(modify.T, addI...)
+	}
 	s.append(changes...)
+	if err := s.tableAccess(modify.T, access); err != nil {
+		return err
+	}
+	return s.partitions(modify.T, parts)
+}
+
+// isPartitionChange reports if the change adds, drops or modifies a table partition.
+func isPartitionChange(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		_, ok := c.A.(*TablePartition)
+		return ok
+	case *schema.DropAttr:
+		_, ok := c.A.(*TablePartition)
+		return ok
+	case *schema.ModifyAttr:
+		_, ok := c.To.(*TablePartition)
+		return ok
+	}
+	return false
+}
+
+// isRetentionChange reports if the change adds, drops or modifies the retention policy of a table.
+func isRetentionChange(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		_, ok := c.A.(*schema.Retention)
+		return ok
+	case *schema.DropAttr:
+		_, ok := c.A.(*schema.Retention)
+		return ok
+	case *schema.ModifyAttr:
+		_, ok := c.To.(*schema.Retention)
+		return ok
+	}
+	return false
+}
+
+// retention returns the statements that migrate the retention policy of the given table.
+// CockroachDB expires rows using row-level TTL. PostgreSQL drops expired partitions of
+// tables that are partitioned by RANGE on the retention column, using pg_partman.
+func (s *state) retention(t *schema.Table, c schema.Change) ([]*migrate.Change, error) {
+	var from, to *schema.Retention
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		to = c.A.(*schema.Retention)
+	case *schema.DropAttr:
+		from = c.A.(*schema.Retention)
+	case *schema.ModifyAttr:
+		from, _ = c.From.(*schema.Retention)
+		to = c.To.(*schema.Retention)
+	}
+	for _, r := range []*schema.Retention{from, to} {
+		if r != nil && r.C == nil {
+			return nil, fmt.Errorf("missing column for retention policy of table %q", t.Name)
+		}
+	}
+	if s.crdb {
+		return []*migrate.Change{s.rowTTL(t, c, from, to)}, nil
+	}
+	var changes []*migrate.Change
+	// The table is registered in pg_partman the
+	// first time a retention policy is defined.
+	if from == nil {
+		p, ok := partitionKey(t.Attrs)
+		if !ok || strings.ToUpper(p.T) != PartitionTypeRange || len(p.Parts) != 1 || p.Parts[0].C == nil || p.Parts[0].C.Name != to.C.Name {
+			return nil, fmt.Errorf("retention policy of table %q requires RANGE partitioning by column %q", t.Name, to.C.Name)
+		}
+		changes = append(changes, &migrate.Change{
+			Source:  c,
+			Comment: fmt.Sprintf("manage the partitions of table %q with pg_partman", t.Name),
+			Cmd: fmt.Sprintf(
+				"SELECT partman.create_parent(p_parent_table => %s, p_control => %s, p_interval => %s)",
+				quote(partmanName(t)), quote(to.C.Name), quote("1 "+strings.ToLower(to.Unit)),
+			),
+			Reverse: fmt.Sprintf("DELETE FROM partman.part_config WHERE parent_table = %s", quote(partmanName(t))),
+		})
+	}
+	setRetention := func(r *schema.Retention) string {
+		v := "NULL"
+		if r != nil {
+			v = quote(interval(r))
+		}
+		return fmt.Sprintf("UPDATE partman.part_config SET retention = %s, retention_keep_table = false WHERE parent_table = %s", v, quote(partmanName(t)))
+	}
+	return append(changes, &migrate.Change{
+		Source:  c,
+		Comment: fmt.Sprintf("set retention policy of table %q", t.Name),
+		Cmd:     setRetention(to),
+		Reverse: setRetention(from),
+	}), nil
+}
+
+// rowTTL returns the statement that migrates the row-level TTL of a CockroachDB table.
+func (s *state) rowTTL(t *schema.Table, c schema.Change, from, to *schema.Retention) *migrate.Change {
+	b := s.Build("ALTER TABLE").Table(t)
+	setTTL := func(r *schema.Retention) string {
+		if r == nil {
+			return b.Clone().P("RESET (ttl)").String()
+		}
+		x := fmt.Sprintf("(%s + INTERVAL %s)", s.Build().Ident(r.C.Name).String(), quote(interval(r)))
+		return b.Clone().P("SET (ttl_expiration_expression =", quote(x)+")").String()
+	}
+	return &migrate.Change{
+		Source:  c,
+		Comment: fmt.Sprintf("set retention policy of table %q", t.Name),
+		Cmd:     setTTL(to),
+		Reverse: setTTL(from),
+	}
+}
+
+// partmanName returns the name of the given table as registered in pg_partman.
+func partmanName(t *schema.Table) string {
+	if t.Schema != nil && t.Schema.Name != "" {
+		return t.Schema.Name + "." + t.Name
+	}
+	return t.Name
+}
+
+// interval returns the interval literal of the retention period. e.g. "30 days".
+func interval(r *schema.Retention) string {
+	return fmt.Sprintf("%d %ss", r.Period, strings.ToLower(r.Unit))
+}
+
+// partitions builds the statements that migrate the partitions of the given table.
+// Partitions are dropped first, and then modified and created, as their bounds must
+// not overlap.
+func (s *state) partitions(t *schema.Table, changes []schema.Change) error {
+	rank := func(c schema.Change) int {
+		switch c.(type) {
+		case *schema.DropAttr:
+			return 0
+		case *schema.ModifyAttr:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return rank(changes[i]) < rank(changes[j])
+	})
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.DropAttr:
+			p := c.A.(*TablePartition)
+			change := &migrate.Change{
+				Cmd:     s.Build("DROP TABLE").Table(partitionTable(t, p)).String(),
+				Source:  c,
+				Comment: fmt.Sprintf("drop %q partition of %q table", p.Name, t.Name),
+			}
+			if p.Bound != "" && p.Sub == nil {
+				change.Reverse = s.Build("CREATE TABLE").Table(partitionTable(t, p)).P("PARTITION OF").Table(t).P(p.Bound).String()
+			}
+			s.append(change)
+		case *schema.ModifyAttr:
+			from, to := c.From.(*TablePartition), c.To.(*TablePartition)
+			if !boundEqual(from.Bound, to.Bound) {
+				if to.Bound == "" {
+					return fmt.Errorf("missing bound for partition %q of table %q", to.Name, t.Name)
+				}
+				detach := s.Build("ALTER TABLE").Table(t).P("DETACH PARTITION").Table(partitionTable(t, to)).String()
+				attach := func(bound string) string {
+					return s.Build("ALTER TABLE").Table(t).P("ATTACH PARTITION").Table(partitionTable(t, to)).P(bound).String()
+				}
+				s.append(
+					&migrate.Change{
+						Cmd:     detach,
+						Source:  c,
+						Comment: fmt.Sprintf("detach %q partition from %q table", to.Name, t.Name),
+						Reverse: attach(from.Bound),
+					},
+					&migrate.Change{
+						Cmd:     attach(to.Bound),
+						Source:  c,
+						Comment: fmt.Sprintf("attach %q partition to %q table", to.Name, t.Name),
+						Reverse: detach,
+					},
+				)
+			}
+			if from.Sub != nil && to.Sub != nil {
+				changes, err := partitionsDiff(from.Sub.Partitions, to.Sub.Partitions)
+				if err != nil {
+					return err
+				}
+				if err := s.partitions(partitionTable(t, to), changes); err != nil {
+					return err
+				}
+			}
+		case *schema.AddAttr:
+			if err := s.createPartition(c, t, c.A.(*TablePartition)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createPartition appends the changes for creating the partition
+// of the given table, and the sub-partitions of the partition.
+func (s *state) createPartition(source schema.Change, t *schema.Table, p *TablePartition) error {
+	if p.Bound == "" {
+		return fmt.Errorf("missing bound for partition %q of table %q", p.Name, t.Name)
+	}
+	b := s.Build("CREATE TABLE").Table(partitionTable(t, p)).P("PARTITION OF").Table(t).P(p.Bound)
+	if p.Sub != nil {
+		key, err := formatPartition(*p.Sub)
+		if err != nil {
+			return fmt.Errorf("partition %q of table %q: %w", p.Name, t.Name, err)
+		}
+		b.P(key)
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  source,
+		Comment: fmt.Sprintf("create %q partition of %q table", p.Name, t.Name),
+		Reverse: s.Build("DROP TABLE").Table(partitionTable(t, p)).String(),
+	})
+	if p.Sub != nil {
+		for _, sp := range p.Sub.Partitions {
+			if err := s.createPartition(source, partitionTable(t, p), sp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isAccessChange reports if the change adds, drops or modifies
+// a row-level security setting, a policy or a grant.
+func isAccessChange(c schema.Change) bool {
+	var a schema.Attr
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		a = c.A
+	case *schema.DropAttr:
+		a = c.A
+	case *schema.ModifyAttr:
+		a = c.To
+	}
+	switch a.(type) {
+	case *RowSecurity, *Policy, *Grant:
+		return true
+	}
+	return false
+}
+
+// tableAccess builds the statements that migrate the row-level security settings,
+// the policies and the grants of the given table. Policies are created after the
+// row-level security settings, and they are planned after functions and views as
+// their expressions may use them.
+func (s *state) tableAccess(t *schema.Table, changes []schema.Change) error {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.ModifyAttr:
+			switch to := c.To.(type) {
+			case *RowSecurity:
+				from, _ := c.From.(*RowSecurity)
+				if from == nil {
+					from = &RowSecurity{}
+				}
+				s.rowSecurity(c, t, from, to)
+			case *Policy:
+				from := c.From.(*Policy)
+				s.access = append(s.access, &migrate.Change{
+					Cmd:     s.dropPolicy(t, from),
+					Source:  c,
+					Comment: fmt.Sprintf("drop %q policy from %q table", from.Name, t.Name),
+					Reverse: s.createPolicy(t, from),
+				}, &migrate.Change{
+					Cmd:     s.createPolicy(t, to),
+					Source:  c,
+					Comment: fmt.Sprintf("create %q policy on %q table", to.Name, t.Name),
+					Reverse: s.dropPolicy(t, to),
+				})
+			case *Grant:
+				if err := s.grantChange(c, "TABLE", s.Build().Table(t).String()); err != nil {
+					return err
+				}
+			}
+		case *schema.AddAttr:
+			switch a := c.A.(type) {
+			case *Policy:
+				s.access = append(s.access, &migrate.Change{
+					Cmd:     s.createPolicy(t, a),
+					Source:  c,
+					Comment: fmt.Sprintf("create %q policy on %q table", a.Name, t.Name),
+					Reverse: s.dropPolicy(t, a),
+				})
+			case *Grant:
+				if err := s.grantChange(c, "TABLE", s.Build().Table(t).String()); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unsupported table attribute %T", a)
+			}
+		case *schema.DropAttr:
+			switch a := c.A.(type) {
+			case *Policy:
+				s.access = append(s.access, &migrate.Change{
+					Cmd:     s.dropPolicy(t, a),
+					Source:  c,
+					Comment: fmt.Sprintf("drop %q policy from %q table", a.Name, t.Name),
+					Reverse: s.createPolicy(t, a),
+				})
+			case *Grant:
+				if err := s.grantChange(c, "TABLE", s.Build().Table(t).String()); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unsupported table attribute %T", a)
+			}
+		}
+	}
 	return nil
 }
 
+// rowSecurity appends the change for enabling, disabling or forcing
+// the row-level security of the table.
+func (s *state) rowSecurity(source schema.Change, t *schema.Table, from, to *RowSecurity) {
+	clauses := func(from, to *RowSecurity) []string {
+		var cs []string
+		switch {
+		case !from.Enabled && to.Enabled:
+			cs = append(cs, "ENABLE ROW LEVEL SECURITY")
+		case from.Enabled && !to.Enabled:
+			cs = append(cs, "DISABLE ROW LEVEL SECURITY")
+		}
+		switch {
+		case !from.Forced && to.Forced:
+			cs = append(cs, "FORCE ROW LEVEL SECURITY")
+		case from.Forced && !to.Forced:
+			cs = append(cs, "NO FORCE ROW LEVEL SECURITY")
+		}
+		return cs
+	}
+	cmd := clauses(from, to)
+	if len(cmd) == 0 {
+		return
+	}
+	b := s.Build("ALTER TABLE").Table(t)
+	s.access = append(s.access, &migrate.Change{
+		Cmd:     b.Clone().P(strings.Join(cmd, ", ")).String(),
+		Source:  source,
+		Comment: fmt.Sprintf("set row-level security of %q table", t.Name),
+		Reverse: b.Clone().P(strings.Join(clauses(to, from), ", ")).String(),
+	})
+}
+
+// createPolicy returns the statement for creating the policy on the table.
+func (s *state) createPolicy(t *schema.Table, p *Policy) string {
+	b := s.Build("CREATE POLICY").Ident(p.Name).P("ON").Table(t)
+	if strings.EqualFold(p.As, PolicyRestrictive) {
+		b.P("AS", PolicyRestrictive)
+	}
+	if p.Command != "" && !strings.EqualFold(p.Command, "ALL") {
+		b.P("FOR", strings.ToUpper(p.Command))
+	}
+	if len(p.Roles) > 0 {
+		b.P("TO")
+		b.MapComma(p.Roles, func(i int, b *sqlx.Builder) {
+			s.role(b, p.Roles[i])
+		})
+	}
+	if p.Using != "" {
+		b.P("USING").Wrap(func(b *sqlx.Builder) {
+			b.P(p.Using)
+		})
+	}
+	if p.Check != "" {
+		b.P("WITH CHECK").Wrap(func(b *sqlx.Builder) {
+			b.P(p.Check)
+		})
+	}
+	return b.String()
+}
+
+// dropPolicy returns the statement for dropping the policy from the table.
+func (s *state) dropPolicy(t *schema.Table, p *Policy) string {
+	return s.Build("DROP POLICY").Ident(p.Name).P("ON").Table(t).String()
+}
+
+// grantChange appends the changes for granting or revoking privileges on
+// the given object. e.g. GRANT SELECT, INSERT ON TABLE "users" TO "app".
+func (s *state) grantChange(c schema.Change, kind, object string) error {
+	stmt := func(verb string, g *Grant, ps []string) string {
+		b := s.Build(verb, strings.Join(ps, ", "), "ON", kind, object)
+		if verb == "GRANT" {
+			b.P("TO")
+		} else {
+			b.P("FROM")
+		}
+		return s.role(b, g.Grantee).String()
+	}
+	all := tablePrivileges
+	if kind == "SCHEMA" {
+		all = schemaPrivileges
+	}
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		g, ok := c.A.(*Grant)
+		if !ok {
+			return fmt.Errorf("unsupported attribute %T", c.A)
+		}
+		ps := upperPrivileges(g)
+		if len(ps) == 0 {
+			return fmt.Errorf("missing privileges for grantee %q on %s %s", g.Grantee, strings.ToLower(kind), object)
+		}
+		s.access = append(s.access, &migrate.Change{
+			Cmd:     stmt("GRANT", g, ps),
+			Source:  c,
+			Comment: fmt.Sprintf("grant privileges on %s %s to %q", strings.ToLower(kind), object, g.Grantee),
+			Reverse: stmt("REVOKE", g, ps),
+		})
+	case *schema.DropAttr:
+		g, ok := c.A.(*Grant)
+		if !ok {
+			return fmt.Errorf("unsupported attribute %T", c.A)
+		}
+		ps := upperPrivileges(g)
+		s.access = append(s.access, &migrate.Change{
+			Cmd:     stmt("REVOKE", g, ps),
+			Source:  c,
+			Comment: fmt.Sprintf("revoke privileges on %s %s from %q", strings.ToLower(kind), object, g.Grantee),
+			Reverse: stmt("GRANT", g, ps),
+		})
+	case *schema.ModifyAttr:
+		from, ok1 := c.From.(*Grant)
+		to, ok2 := c.To.(*Grant)
+		if !ok1 || !ok2 {
+			return fmt.Errorf("unsupported attribute %T", c.To)
+		}
+		var (
+			fromP, toP      = privileges(from, all, to), privileges(to, all, nil)
+			revoke, granted []string
+		)
+		for _, p := range fromP {
+			if !contains(toP, p) && !contains(revoke, p) {
+				revoke = append(revoke, p)
+			}
+		}
+		for _, p := range toP {
+			if !contains(fromP, p) && !contains(granted, p) {
+				granted = append(granted, p)
+			}
+		}
+		if len(revoke) > 0 {
+			s.access = append(s.access, &migrate.Change{
+				Cmd:     stmt("REVOKE", from, revoke),
+				Source:  c,
+				Comment: fmt.Sprintf("revoke privileges on %s %s from %q", strings.ToLower(kind), object, from.Grantee),
+				Reverse: stmt("GRANT", from, revoke),
+			})
+		}
+		if len(granted) > 0 {
+			s.access = append(s.access, &migrate.Change{
+				Cmd:     stmt("GRANT", to, granted),
+				Source:  c,
+				Comment: fmt.Sprintf("grant privileges on %s %s to %q", strings.ToLower(kind), object, to.Grantee),
+				Reverse: stmt("REVOKE", to, granted),
+			})
+		}
+	}
+	return nil
+}
+
+// upperPrivileges returns the privileges of the grant in upper case.
+func upperPrivileges(g *Grant) []string {
+	ps := make([]string, len(g.Privileges))
+	for i, p := range g.Privileges {
+		ps[i] = strings.ToUpper(p)
+	}
+	return ps
+}
+
+// role writes the given role name to the builder. Special role
+// names, such as PUBLIC or CURRENT_USER, are written as-is.
+func (s *state) role(b *sqlx.Builder, name string) *sqlx.Builder {
+	switch u := strings.ToUpper(name); u {
+	case GranteePublic, "CURRENT_USER", "CURRENT_ROLE", "SESSION_USER":
+		return b.P(u)
+	default:
+		return b.Ident(name)
+	}
+}
+
+// partitionTable returns the table that represents the partition.
+func partitionTable(t *schema.Table, p *TablePartition) *schema.Table {
+	return &schema.Table{Name: p.Name, Schema: t.Schema}
+}
+
+// addIndexesConcurrently creates the given indexes without locking the table against
+// writes. Note that CREATE INDEX CONCURRENTLY cannot be executed inside a transaction.
+func (s *state) addIndexesConcurrently(t *schema.Table, indexes ...*schema.Index) {
+	if len(indexes) == 0 {
+		return
+	}
+	s.Transactional = false
+	for _, idx := range indexes {
+		c := s.createIndex(t, idx, true)
+		c.NoTx = true
+		s.append(c)
+	}
+}
+
+// dropIndexesConcurrently is the DROP INDEX CONCURRENTLY version of dropIndexes.
+func (s *state) dropIndexesConcurrently(t *schema.Table, indexes ...*schema.Index) {
+	if len(indexes) == 0 {
+		return
+	}
+	s.Transactional = false
+	for _, idx := range indexes {
+		c := s.createIndex(t, idx, true)
+		s.append(&migrate.Change{
+			Cmd:     c.Reverse,
+			Comment: fmt.Sprintf("drop index %q from table: %q", idx.Name, t.Name),
+			Reverse: c.Cmd,
+			NoTx:    true,
+		})
+	}
+}
+
+// addForeignKeysNotValid creates the given foreign keys without validating the existing rows,
+// and validates them in a separate step that does not block writes on both tables.
+func (s *state) addForeignKeysNotValid(t *schema.Table, fks ...*schema.ForeignKey) {
+	for _, fk := range fks {
+		b := s.Build("ALTER TABLE").Table(t).P("ADD")
+		s.fks(b, fk)
+		s.append(&migrate.Change{
+			Cmd:     b.P("NOT VALID").String(),
+			Comment: fmt.Sprintf("add foreign key %q to table %q without validating existing rows", fk.Symbol, t.Name),
+			Reverse: s.Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(fk.Symbol).String(),
+		}, &migrate.Change{
+			Cmd:     s.Build("ALTER TABLE").Table(t).P("VALIDATE CONSTRAINT").Ident(fk.Symbol).String(),
+			Comment: fmt.Sprintf("validate foreign key %q of table %q", fk.Symbol, t.Name),
+		})
+	}
+}
+
+// setNotNull sets the NOT NULL constraint on the given columns without holding an ACCESS EXCLUSIVE
+// lock during the table scan. A NOT VALID check constraint is added and validated first, which
+// allows PostgreSQL (12 and above) to skip the full table scan when setting the column NOT NULL.
+func (s *state) setNotNull(t *schema.Table, columns ...*schema.Column) {
+	for _, c := range columns {
+		name := notNullCheckName(t, c)
+		var (
+			drop = s.Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(name).String()
+			add  = s.Build("ALTER TABLE").Table(t).P("ADD CONSTRAINT").Ident(name).P("CHECK").Wrap(func(b *sqlx.Builder) {
+				b.Ident(c.Name).P("IS NOT NULL")
+			}).P("NOT VALID").String()
+		)
+		s.append(
+			&migrate.Change{
+				Cmd:     add,
+				Comment: fmt.Sprintf("add a not valid NOT NULL check to column %q of table %q", c.Name, t.Name),
+				Reverse: drop,
+			},
+			&migrate.Change{
+				Cmd:     s.Build("ALTER TABLE").Table(t).P("VALIDATE CONSTRAINT").Ident(name).String(),
+				Comment: fmt.Sprintf("validate the NOT NULL check of column %q of table %q", c.Name, t.Name),
+			},
+			&migrate.Change{
+				Cmd:     s.Build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.Name).P("SET NOT NULL").String(),
+				Comment: fmt.Sprintf("set column %q of table %q to NOT NULL", c.Name, t.Name),
+				Reverse: s.Build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.Name).P("DROP NOT NULL").String(),
+			},
+			&migrate.Change{
+				Cmd:     drop,
+				Comment: fmt.Sprintf("drop the NOT NULL check of column %q of table %q", c.Name, t.Name),
+				Reverse: add,
+			},
+		)
+	}
+}
+
+// notNullCheckName returns the name of the temporary check
+// constraint used for setting a column to NOT NULL.
+func notNullCheckName(t *schema.Table, c *schema.Column) string {
+	name := fmt.Sprintf("%s_%s_not_null", t.Name, c.Name)
+	// Identifiers are truncated by PostgreSQL to 63 bytes.
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
 // alterTable modifies the given table by executing on it a list of changes in one SQL statement.
 func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	var (
@@ -377,6 +1727,9 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				}
 			case *schema.AddIndex:
 				b.P("ADD CONSTRAINT").Ident(change.I.Name).P("UNIQUE")
+				if !nullsDistinct(change.I.Attrs) {
+					b.P("NULLS NOT DISTINCT")
+				}
 				s.indexParts(b, change.I.Parts)
 				// Skip reversing this operation as it is the inverse of
 				// the operation below and should not be used besides this.
@@ -740,6 +2093,10 @@ func (s *state) enumExists(ctx context.Context, ns *schema.Schema, e *schema.Enu
 		args = append(args, es)
 	}
 	rows, err := s.QueryContext(ctx, query, args...)
+	// Planning without a database connection.
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
 	if err != nil {
 		return false, fmt.Errorf("check enum existence: %w", err)
 	}
@@ -782,32 +2139,44 @@ func (s *state) mayDropEnum(alter *alterChange, ns *schema.Schema, e *schema.Enu
 
 func (s *state) addIndexes(t *schema.Table, indexes ...*schema.Index) {
 	for _, idx := range indexes {
-		b := s.Build("CREATE")
-		if idx.Unique {
-			b.P("UNIQUE")
-		}
-		b.P("INDEX")
-		if idx.Name != "" {
+		s.append(s.createIndex(t, idx, false))
+	}
+}
+
+// createIndex returns the change for creating the given index, and
+// dropping it on reverse, optionally using the CONCURRENTLY option.
+func (s *state) createIndex(t *schema.Table, idx *schema.Index, concurrently bool) *migrate.Change {
+	b := s.Build("CREATE")
+	if idx.Unique {
+		b.P("UNIQUE")
+	}
+	b.P("INDEX")
+	if concurrently {
+		b.P("CONCURRENTLY")
+	}
+	if idx.Name != "" {
+		b.Ident(idx.Name)
+	}
+	b.P("ON").Table(t)
+	s.index(b, idx)
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
+		Reverse: func() string {
+			b := s.Build("DROP INDEX")
+			if concurrently {
+				b.P("CONCURRENTLY")
+			}
+			// Unlike MySQL, the DROP command is not attached to ALTER TABLE.
+			// Therefore, we print indexes with their qualified name, because
+			// the connection that executes the statements may not be attached
+			// to this schema.
+			if t.Schema != nil {
+				b.WriteString(s.schemaPrefix(t.Schema))
+			}
 			b.Ident(idx.Name)
-		}
-		b.P("ON").Table(t)
-		s.index(b, idx)
-		s.append(&migrate.Change{
-			Cmd:     b.String(),
-			Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
-			Reverse: func() string {
-				b := s.Build("DROP INDEX")
-				// Unlike MySQL, the DROP command is not attached to ALTER TABLE.
-				// Therefore, we print indexes with their qualified name, because
-				// the connection that executes the statements may not be attached
-				// to this schema.
-				if t.Schema != nil {
-					b.WriteString(s.schemaPrefix(t.Schema))
-				}
-				b.Ident(idx.Name)
-				return b.String()
-			}(),
-		})
+			return b.String()
+		}(),
 	}
 }
 
@@ -852,6 +2221,9 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 			})
 		}
 	case hasX:
+		if !s.supportsGeneratedColumns() {
+			return s.unsupported(fmt.Sprintf("generated column %q", c.Name), 12_00_00)
+		}
 		x := &schema.GeneratedExpr{}
 		sqlx.Has(c.Attrs, x)
 		b.P("GENERATED ALWAYS AS", sqlx.MayWrap(x.Expr), "STORED")
@@ -931,6 +2303,9 @@ func (s *state) index(b *sqlx.Builder, idx *schema.Index) {
 			})
 		})
 	}
+	if !nullsDistinct(idx.Attrs) {
+		b.P("NULLS NOT DISTINCT")
+	}
 	if p, ok := indexStorageParams(idx.Attrs); ok {
 		b.P("WITH")
 		b.Wrap(func(b *sqlx.Builder) {
@@ -949,7 +2324,7 @@ func (s *state) index(b *sqlx.Builder, idx *schema.Index) {
 	}
 	for _, attr := range idx.Attrs {
 		switch attr.(type) {
-		case *schema.Comment, *ConType, *IndexType, *IndexInclude, *IndexPredicate, *IndexStorageParams:
+		case *schema.Comment, *ConType, *IndexType, *IndexInclude, *IndexPredicate, *IndexStorageParams, *IndexNullsDistinct:
 		default:
 			panic(fmt.Sprintf("unexpected index attribute: %T", attr))
 		}
@@ -1066,6 +2441,24 @@ func check(b *sqlx.Builder, c *schema.Check) {
 	}
 }
 
+// checkIndex checks that the index does not use
+// features that are not supported by the target version.
+func (s *state) checkIndex(idx *schema.Index) error {
+	switch {
+	case sqlx.Has(idx.Attrs, &IndexInclude{}) && !s.supportsIndexInclude():
+		return s.unsupported(fmt.Sprintf("INCLUDE clause of index %q", idx.Name), 11_00_00)
+	case !nullsDistinct(idx.Attrs) && !s.supportsNullsDistinct():
+		return s.unsupported(fmt.Sprintf("NULLS NOT DISTINCT of index %q", idx.Name), 15_00_00)
+	}
+	return nil
+}
+
+// unsupported returns an error for a feature that is not
+// supported by the version the changes are planned for.
+func (s *state) unsupported(feature string, since int) error {
+	return fmt.Errorf("%s requires PostgreSQL %d or above, but the target version is %d.%d", feature, since/10000, s.version/10000, s.version%10000)
+}
+
 // isUniqueConstraint reports if the index is a valid UNIQUE constraint.
 func isUniqueConstraint(i *schema.Index) bool {
 	if c := (ConType{}); !sqlx.Has(i.Attrs, &c) || !c.IsUnique() || !i.Unique {
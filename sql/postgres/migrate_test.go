@@ -21,6 +21,7 @@ func TestPlanChanges(t *testing.T) {
 	tests := []struct {
 		changes  []schema.Change
 		options  []migrate.PlanOption
+		version  string
 		mock     func(mock)
 		wantPlan *migrate.Plan
 		wantErr  bool
@@ -48,6 +49,122 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyRealm{
+					R: schema.NewRealm(),
+					Changes: []schema.Change{
+						&schema.AddAttr{A: &schema.Setting{Name: "search_path", V: `"$user", public`}},
+						&schema.ModifyAttr{From: &schema.Setting{Name: "timezone", V: "UTC"}, To: &schema.Setting{Name: "timezone", V: "Europe/Berlin"}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DO $$ BEGIN EXECUTE format('ALTER DATABASE %I SET search_path = "$user", public', current_database()); END $$`,
+						Reverse: `DO $$ BEGIN EXECUTE format('ALTER DATABASE %I RESET search_path', current_database()); END $$`,
+					},
+					{
+						Cmd:     `DO $$ BEGIN EXECUTE format('ALTER DATABASE %I SET timezone = ''Europe/Berlin''', current_database()); END $$`,
+						Reverse: `DO $$ BEGIN EXECUTE format('ALTER DATABASE %I SET timezone = UTC', current_database()); END $$`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifySchema{
+					S: schema.New("public"),
+					Changes: []schema.Change{
+						&schema.DropAttr{A: &Object{Kind: ObjectOperatorClass, Name: "old_ops", Signature: "gin", Hash: "e3b0c44298fc1c14"}},
+						&schema.AddAttr{A: (&Object{Kind: ObjectOperatorClass, Name: "text_ops", Signature: "btree"}).SetDef("FOR TYPE text USING btree AS OPERATOR 1 <")},
+						&schema.ModifyAttr{
+							From: &Object{Kind: ObjectOperator, Name: "===", Signature: "text, text", Hash: "e3b0c44298fc1c14"},
+							To:   (&Object{Kind: ObjectOperator, Name: "===", Signature: "text, text"}).SetDef("LEFTARG = text, RIGHTARG = text, FUNCTION = texteq"),
+						},
+						// Objects that were not created by Atlas are adopted.
+						&schema.ModifyAttr{
+							From: &Object{Kind: ObjectAggregate, Name: "agg", Signature: "integer"},
+							To:   (&Object{Kind: ObjectAggregate, Name: "agg", Signature: "integer"}).SetDef("SFUNC = int4pl, STYPE = integer"),
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `COMMENT ON AGGREGATE "public"."agg" (integer) IS 'atlas:b1c560cac0649cd3'`,
+						Reverse: `COMMENT ON AGGREGATE "public"."agg" (integer) IS NULL`,
+					},
+					{
+						Cmd: `DROP OPERATOR "public".=== (text, text)`,
+					},
+					{
+						Cmd:     `CREATE OPERATOR "public".=== (LEFTARG = text, RIGHTARG = text, FUNCTION = texteq)`,
+						Reverse: `DROP OPERATOR "public".=== (text, text)`,
+					},
+					{
+						Cmd:     `COMMENT ON OPERATOR "public".=== (text, text) IS 'atlas:ef9854dece5c6ba3'`,
+						Reverse: `COMMENT ON OPERATOR "public".=== (text, text) IS NULL`,
+					},
+					{
+						Cmd:     `CREATE OPERATOR CLASS "public"."text_ops" FOR TYPE text USING btree AS OPERATOR 1 <`,
+						Reverse: `DROP OPERATOR CLASS "public"."text_ops" USING btree`,
+					},
+					{
+						Cmd:     `COMMENT ON OPERATOR CLASS "public"."text_ops" USING btree IS 'atlas:1578f039b8dc7023'`,
+						Reverse: `COMMENT ON OPERATOR CLASS "public"."text_ops" USING btree IS NULL`,
+					},
+					// Objects are dropped after the table changes.
+					{
+						Cmd: `DROP OPERATOR CLASS "public"."old_ops" USING gin`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddSchema{
+					S: schema.New("search").AddAttrs(
+						(&Object{Kind: ObjectAggregate, Name: "array_cat_agg", Signature: "anycompatiblearray"}).SetDef("SFUNC = array_cat, STYPE = anycompatiblearray"),
+					),
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE SCHEMA "search"`,
+						Reverse: `DROP SCHEMA "search" CASCADE`,
+					},
+					{
+						Cmd:     `CREATE AGGREGATE "search"."array_cat_agg" (anycompatiblearray) (SFUNC = array_cat, STYPE = anycompatiblearray)`,
+						Reverse: `DROP AGGREGATE "search"."array_cat_agg" (anycompatiblearray)`,
+					},
+					{
+						Cmd:     `COMMENT ON AGGREGATE "search"."array_cat_agg" (anycompatiblearray) IS 'atlas:0921f8a1ae41a557'`,
+						Reverse: `COMMENT ON AGGREGATE "search"."array_cat_agg" (anycompatiblearray) IS NULL`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifySchema{
+					S: schema.New("public"),
+					Changes: []schema.Change{
+						&schema.AddAttr{A: &Object{Kind: ObjectAggregate, Name: "agg", Signature: "integer", Hash: "e3b0c44298fc1c14"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			changes: []schema.Change{
 				func() schema.Change {
@@ -218,6 +335,131 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: func() []schema.Change {
+				c := schema.NewIntColumn("id", "int")
+				r := schema.NewStringColumn("region", "text")
+				return []schema.Change{
+					&schema.AddTable{
+						T: schema.NewTable("logs").SetSchema(schema.New("public")).AddColumns(c, r).AddAttrs(&Partition{
+							T:     PartitionTypeList,
+							Parts: []*PartitionPart{{C: r}},
+							Partitions: []*TablePartition{
+								{Name: "logs_eu", Bound: "FOR VALUES IN ('eu')", Sub: &Partition{
+									T:          PartitionTypeHash,
+									Parts:      []*PartitionPart{{C: c}},
+									Partitions: []*TablePartition{{Name: "logs_eu_0", Bound: "FOR VALUES WITH (MODULUS 1, REMAINDER 0)"}},
+								}},
+								{Name: "logs_default", Bound: "DEFAULT"},
+							},
+						}),
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE TABLE "public"."logs" ("id" integer NOT NULL, "region" text NOT NULL) PARTITION BY LIST ("region")`, Reverse: `DROP TABLE "public"."logs"`},
+					{Cmd: `CREATE TABLE "public"."logs_eu" PARTITION OF "public"."logs" FOR VALUES IN ('eu') PARTITION BY HASH ("id")`, Reverse: `DROP TABLE "public"."logs_eu"`},
+					{Cmd: `CREATE TABLE "public"."logs_eu_0" PARTITION OF "public"."logs_eu" FOR VALUES WITH (MODULUS 1, REMAINDER 0)`, Reverse: `DROP TABLE "public"."logs_eu_0"`},
+					{Cmd: `CREATE TABLE "public"."logs_default" PARTITION OF "public"."logs" DEFAULT`, Reverse: `DROP TABLE "public"."logs_default"`},
+				},
+			},
+		},
+		{
+			changes: func() []schema.Change {
+				c := schema.NewTimeColumn("created_at", "timestamptz")
+				return []schema.Change{
+					&schema.AddTable{
+						T: schema.NewTable("events").SetSchema(schema.New("public")).AddColumns(c).AddAttrs(
+							&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: c}}},
+							&schema.Retention{C: c, Period: 30, Unit: schema.RetentionDay},
+						),
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE TABLE "public"."events" ("created_at" timestamptz NOT NULL) PARTITION BY RANGE ("created_at")`, Reverse: `DROP TABLE "public"."events"`},
+					{Cmd: `SELECT partman.create_parent(p_parent_table => 'public.events', p_control => 'created_at', p_interval => '1 day')`, Reverse: `DELETE FROM partman.part_config WHERE parent_table = 'public.events'`},
+					{Cmd: `UPDATE partman.part_config SET retention = '30 days', retention_keep_table = false WHERE parent_table = 'public.events'`, Reverse: `UPDATE partman.part_config SET retention = NULL, retention_keep_table = false WHERE parent_table = 'public.events'`},
+				},
+			},
+		},
+		// Retention policies require partitioning by their column.
+		{
+			changes: func() []schema.Change {
+				c := schema.NewTimeColumn("created_at", "timestamptz")
+				return []schema.Change{
+					&schema.AddTable{
+						T: schema.NewTable("events").SetSchema(schema.New("public")).AddColumns(c).AddAttrs(
+							&schema.Retention{C: c, Period: 30, Unit: schema.RetentionDay},
+						),
+					},
+				}
+			}(),
+			wantErr: true,
+		},
+		{
+			changes: func() []schema.Change {
+				c := schema.NewTimeColumn("created_at", "timestamptz")
+				t := schema.NewTable("events").SetSchema(schema.New("public")).AddColumns(c)
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: t,
+						Changes: []schema.Change{
+							&schema.ModifyAttr{
+								From: &schema.Retention{C: c, Period: 30, Unit: schema.RetentionDay},
+								To:   &schema.Retention{C: c, Period: 1, Unit: schema.RetentionYear},
+							},
+						},
+					},
+					&schema.ModifyTable{
+						T:       t,
+						Changes: []schema.Change{&schema.DropAttr{A: &schema.Retention{C: c, Period: 1, Unit: schema.RetentionYear}}},
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `UPDATE partman.part_config SET retention = '1 years', retention_keep_table = false WHERE parent_table = 'public.events'`, Reverse: `UPDATE partman.part_config SET retention = '30 days', retention_keep_table = false WHERE parent_table = 'public.events'`},
+					{Cmd: `UPDATE partman.part_config SET retention = NULL, retention_keep_table = false WHERE parent_table = 'public.events'`, Reverse: `UPDATE partman.part_config SET retention = '1 years', retention_keep_table = false WHERE parent_table = 'public.events'`},
+				},
+			},
+		},
+		{
+			changes: func() []schema.Change {
+				t := schema.NewTable("logs").SetSchema(schema.New("public"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: t,
+						Changes: []schema.Change{
+							&schema.AddAttr{A: &TablePartition{Name: "logs_d", Bound: "FOR VALUES IN ('d')"}},
+							&schema.ModifyAttr{
+								From: &TablePartition{Name: "logs_b", Bound: "FOR VALUES IN ('b')"},
+								To:   &TablePartition{Name: "logs_b", Bound: "FOR VALUES IN ('b', 'c')"},
+							},
+							&schema.DropAttr{A: &TablePartition{Name: "logs_c", Bound: "FOR VALUES IN ('c')"}},
+						},
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `DROP TABLE "public"."logs_c"`, Reverse: `CREATE TABLE "public"."logs_c" PARTITION OF "public"."logs" FOR VALUES IN ('c')`},
+					{Cmd: `ALTER TABLE "public"."logs" DETACH PARTITION "public"."logs_b"`, Reverse: `ALTER TABLE "public"."logs" ATTACH PARTITION "public"."logs_b" FOR VALUES IN ('b')`},
+					{Cmd: `ALTER TABLE "public"."logs" ATTACH PARTITION "public"."logs_b" FOR VALUES IN ('b', 'c')`, Reverse: `ALTER TABLE "public"."logs" DETACH PARTITION "public"."logs_b"`},
+					{Cmd: `CREATE TABLE "public"."logs_d" PARTITION OF "public"."logs" FOR VALUES IN ('d')`, Reverse: `DROP TABLE "public"."logs_d"`},
+				},
+			},
+		},
 		{
 			changes: []schema.Change{
 				&schema.AddTable{
@@ -890,6 +1132,52 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("t1").SetSchema(schema.New("s1")),
+					Changes: []schema.Change{
+						&schema.RenameForeignKey{
+							From: &schema.ForeignKey{Symbol: "a"},
+							To:   &schema.ForeignKey{Symbol: "b"},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "s1"."t1" RENAME CONSTRAINT "a" TO "b"`,
+						Reverse: `ALTER TABLE "s1"."t1" RENAME CONSTRAINT "b" TO "a"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("t1").SetSchema(schema.New("s1")),
+					Changes: []schema.Change{
+						&schema.RenameCheck{
+							From: &schema.Check{Name: "a", Expr: "c > 0"},
+							To:   &schema.Check{Name: "b", Expr: "c > 0"},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "s1"."t1" RENAME CONSTRAINT "a" TO "b"`,
+						Reverse: `ALTER TABLE "s1"."t1" RENAME CONSTRAINT "b" TO "a"`,
+					},
+				},
+			},
+		},
 		// Invalid serial type.
 		{
 			changes: []schema.Change{
@@ -1125,6 +1413,94 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		// NULLS NOT DISTINCT requires PostgreSQL 15.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").AddColumns(schema.NewNullIntColumn("c", "int"))
+				users.AddIndexes(schema.NewUniqueIndex("c_key").AddColumns(users.Columns[0]).AddAttrs(&IndexNullsDistinct{V: false}))
+				return []schema.Change{&schema.AddTable{T: users}}
+			}(),
+			wantErr: true,
+		},
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").AddColumns(schema.NewNullIntColumn("c", "int"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddIndex{I: schema.NewUniqueIndex("c_key").AddColumns(users.Columns[0]).AddAttrs(&IndexNullsDistinct{V: false})},
+						},
+					},
+				}
+			}(),
+			version: "150000",
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE UNIQUE INDEX "c_key" ON "users" ("c") NULLS NOT DISTINCT`,
+						Reverse: `DROP INDEX "c_key"`,
+					},
+				},
+			},
+		},
+		// Features that are not supported by the target version.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"))
+				users.AddIndexes(schema.NewIndex("a_idx").AddColumns(users.Columns[0]).AddAttrs(&IndexInclude{Columns: users.Columns[1:]}))
+				return []schema.Change{&schema.AddTable{T: users}}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.TargetVersion = "10.4" },
+			},
+			wantErr: true,
+		},
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").AddColumns(schema.NewIntColumn("a", "int"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddColumn{C: schema.NewIntColumn("b", "int").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "a * 2"})},
+						},
+					},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.TargetVersion = "11" },
+			},
+			wantErr: true,
+		},
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").AddColumns(schema.NewNullIntColumn("c", "int"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddIndex{I: schema.NewUniqueIndex("c_key").AddColumns(users.Columns[0]).AddAttrs(&IndexNullsDistinct{V: false})},
+						},
+					},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.TargetVersion = "15" },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE UNIQUE INDEX "c_key" ON "users" ("c") NULLS NOT DISTINCT`,
+						Reverse: `DROP INDEX "c_key"`,
+					},
+				},
+			},
+		},
 		// Empty qualifier in multi-schema mode should fail.
 		{
 			changes: []schema.Change{
@@ -1136,13 +1512,390 @@ func TestPlanChanges(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		// Zero-downtime mode.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("name", "text"))
+				pets := schema.NewTable("pets").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewIntColumn("owner_id", "int"))
+				fk := schema.NewForeignKey("owner_fk").SetTable(pets).AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: pets,
+						Changes: []schema.Change{
+							&schema.AddForeignKey{F: fk},
+							&schema.AddIndex{I: schema.NewIndex("owner_idx").AddColumns(pets.Columns[0])},
+							&schema.DropIndex{I: schema.NewIndex("old_idx").AddColumns(pets.Columns[0])},
+							&schema.ModifyColumn{
+								From:   schema.NewNullIntColumn("owner_id", "int"),
+								To:     pets.Columns[0],
+								Change: schema.ChangeNull,
+							},
+						},
+					},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.ZeroDowntime = true },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: false,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP INDEX CONCURRENTLY "public"."old_idx"`,
+						Reverse: `CREATE INDEX CONCURRENTLY "old_idx" ON "public"."pets" ("owner_id")`,
+						NoTx:    true,
+					},
+					{
+						Cmd:     `CREATE INDEX CONCURRENTLY "owner_idx" ON "public"."pets" ("owner_id")`,
+						Reverse: `DROP INDEX CONCURRENTLY "public"."owner_idx"`,
+						NoTx:    true,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."pets" ADD CONSTRAINT "owner_fk" FOREIGN KEY ("owner_id") REFERENCES "public"."users" ("id") NOT VALID`,
+						Reverse: `ALTER TABLE "public"."pets" DROP CONSTRAINT "owner_fk"`,
+					},
+					{
+						Cmd: `ALTER TABLE "public"."pets" VALIDATE CONSTRAINT "owner_fk"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."pets" ADD CONSTRAINT "pets_owner_id_not_null" CHECK ("owner_id" IS NOT NULL) NOT VALID`,
+						Reverse: `ALTER TABLE "public"."pets" DROP CONSTRAINT "pets_owner_id_not_null"`,
+					},
+					{
+						Cmd: `ALTER TABLE "public"."pets" VALIDATE CONSTRAINT "pets_owner_id_not_null"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."pets" ALTER COLUMN "owner_id" SET NOT NULL`,
+						Reverse: `ALTER TABLE "public"."pets" ALTER COLUMN "owner_id" DROP NOT NULL`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."pets" DROP CONSTRAINT "pets_owner_id_not_null"`,
+						Reverse: `ALTER TABLE "public"."pets" ADD CONSTRAINT "pets_owner_id_not_null" CHECK ("owner_id" IS NOT NULL) NOT VALID`,
+					},
+				},
+			},
+		},
+		// Zero-downtime mode with indexes that back UNIQUE constraints.
+		{
+			changes: func() []schema.Change {
+				pets := schema.NewTable("pets").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewIntColumn("owner_id", "int"), schema.NewIntColumn("name", "text"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: pets,
+						Changes: []schema.Change{
+							&schema.ModifyIndex{
+								From:   schema.NewUniqueIndex("owner_key").AddColumns(pets.Columns[0]).AddAttrs(&ConType{T: "u"}),
+								To:     schema.NewUniqueIndex("owner_key").AddColumns(pets.Columns...),
+								Change: schema.ChangeParts,
+							},
+						},
+					},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.ZeroDowntime = true },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: false,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "public"."pets" DROP CONSTRAINT "owner_key"`,
+						Reverse: `ALTER TABLE "public"."pets" ADD CONSTRAINT "owner_key" UNIQUE ("owner_id")`,
+					},
+					{
+						Cmd:     `CREATE UNIQUE INDEX CONCURRENTLY "owner_key" ON "public"."pets" ("owner_id", "name")`,
+						Reverse: `DROP INDEX CONCURRENTLY "public"."owner_key"`,
+						NoTx:    true,
+					},
+				},
+			},
+		},
+		// Zero-downtime mode with transactional changes.
+		{
+			changes: func() []schema.Change {
+				pets := schema.NewTable("pets").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewIntColumn("owner_id", "int"), schema.NewIntColumn("name", "text"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: pets,
+						Changes: []schema.Change{
+							&schema.AddIndex{I: schema.NewUniqueIndex("owner_idx").AddColumns(pets.Columns[0])},
+							&schema.DropIndex{I: schema.NewIndex("old_idx").AddColumns(pets.Columns[0])},
+							&schema.AddColumn{C: schema.NewNullIntColumn("age", "int")},
+						},
+					},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.ZeroDowntime = true },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: false,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP INDEX CONCURRENTLY "public"."old_idx"`,
+						Reverse: `CREATE INDEX CONCURRENTLY "old_idx" ON "public"."pets" ("owner_id")`,
+						NoTx:    true,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."pets" ADD COLUMN "age" integer NULL`,
+						Reverse: `ALTER TABLE "public"."pets" DROP COLUMN "age"`,
+					},
+					{
+						Cmd:     `CREATE UNIQUE INDEX CONCURRENTLY "owner_idx" ON "public"."pets" ("owner_id")`,
+						Reverse: `DROP INDEX CONCURRENTLY "public"."owner_idx"`,
+						NoTx:    true,
+					},
+				},
+			},
+		},
+		// Triggers are dropped first, and created last.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").SetSchema(schema.New("public"))
+				users.AddTriggers(
+					schema.NewTrigger("audit", "AFTER", []string{"INSERT", "UPDATE"}, "EXECUTE FUNCTION audit()").SetForEachRow(false),
+					schema.NewTrigger("check", "BEFORE", []string{"INSERT"}, "EXECUTE FUNCTION check()").SetWhen("NEW.id > 0"),
+				)
+				old := schema.NewTable("users").SetSchema(schema.New("public")).
+					AddTriggers(schema.NewTrigger("check", "BEFORE", []string{"INSERT"}, "EXECUTE FUNCTION check()"))
+				return []schema.Change{
+					&schema.AddTrigger{T: users.Triggers[0]},
+					&schema.ModifyTrigger{From: old.Triggers[0], To: users.Triggers[1]},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP TRIGGER "check" ON "public"."users"`,
+						Reverse: `CREATE TRIGGER "check" BEFORE INSERT ON "public"."users" FOR EACH ROW EXECUTE FUNCTION check()`,
+					},
+					{
+						Cmd:     `CREATE TRIGGER "audit" AFTER INSERT OR UPDATE ON "public"."users" FOR EACH STATEMENT EXECUTE FUNCTION audit()`,
+						Reverse: `DROP TRIGGER "audit" ON "public"."users"`,
+					},
+					{
+						Cmd:     `CREATE TRIGGER "check" BEFORE INSERT ON "public"."users" FOR EACH ROW WHEN (NEW.id > 0) EXECUTE FUNCTION check()`,
+						Reverse: `DROP TRIGGER "check" ON "public"."users"`,
+					},
+				},
+			},
+		},
+		// Functions are replaced in place, unless their return type was changed.
+		{
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				s.AddFuncs(
+					schema.NewFunc("add", "a integer, b integer DEFAULT 1", "integer", "SELECT a + b").SetLang("sql"),
+					schema.NewFunc("add", "a integer, b integer DEFAULT 1", "bigint", "SELECT a + b").SetLang("sql"),
+					schema.NewFunc("dollar", "", "text", "SELECT '$$'").SetLang("sql"),
+				)
+				s.AddProcs(
+					schema.NewProc("archive", "id integer", "BEGIN NULL; END").SetLang("plpgsql"),
+					schema.NewProc("archive", "id integer", "BEGIN DELETE FROM users WHERE users.id = id; END").SetLang("plpgsql"),
+				)
+				return []schema.Change{
+					&schema.DropProc{P: s.Procs[0]},
+					&schema.ModifyFunc{From: s.Funcs[0], To: s.Funcs[1]},
+					&schema.AddFunc{F: s.Funcs[2]},
+					&schema.ModifyProc{From: s.Procs[0], To: s.Procs[1]},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP PROCEDURE "public"."archive"(id integer)`,
+						Reverse: `CREATE PROCEDURE "public"."archive"(id integer) LANGUAGE plpgsql AS $$BEGIN NULL; END$$`,
+					},
+					{
+						Cmd:     `DROP FUNCTION "public"."add"(a integer, b integer)`,
+						Reverse: `CREATE FUNCTION "public"."add"(a integer, b integer DEFAULT 1) RETURNS integer LANGUAGE sql AS $$SELECT a + b$$`,
+					},
+					{
+						Cmd:     `CREATE FUNCTION "public"."add"(a integer, b integer DEFAULT 1) RETURNS bigint LANGUAGE sql AS $$SELECT a + b$$`,
+						Reverse: `DROP FUNCTION "public"."add"(a integer, b integer)`,
+					},
+					{
+						Cmd:     `CREATE FUNCTION "public"."dollar"() RETURNS text LANGUAGE sql AS $body0$SELECT '$$'$body0$`,
+						Reverse: `DROP FUNCTION "public"."dollar"()`,
+					},
+					{
+						Cmd:     `CREATE OR REPLACE PROCEDURE "public"."archive"(id integer) LANGUAGE plpgsql AS $$BEGIN DELETE FROM users WHERE users.id = id; END$$`,
+						Reverse: `CREATE OR REPLACE PROCEDURE "public"."archive"(id integer) LANGUAGE plpgsql AS $$BEGIN NULL; END$$`,
+					},
+				},
+			},
+		},
+		// Row-level security, policies and grants.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("users").
+						SetSchema(schema.New("public")).
+						AddColumns(schema.NewIntColumn("tenant", "integer")).
+						AddAttrs(
+							&RowSecurity{Enabled: true, Forced: true},
+							&Policy{Name: "isolation", As: PolicyRestrictive, Command: "SELECT", Roles: []string{"app"}, Using: "tenant = 1"},
+							&Grant{Grantee: "app", Privileges: []string{"SELECT", "INSERT"}},
+						),
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "public"."users" ("tenant" integer NOT NULL)`,
+						Reverse: `DROP TABLE "public"."users"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."users" ENABLE ROW LEVEL SECURITY, FORCE ROW LEVEL SECURITY`,
+						Reverse: `ALTER TABLE "public"."users" DISABLE ROW LEVEL SECURITY, NO FORCE ROW LEVEL SECURITY`,
+					},
+					{
+						Cmd:     `CREATE POLICY "isolation" ON "public"."users" AS RESTRICTIVE FOR SELECT TO "app" USING (tenant = 1)`,
+						Reverse: `DROP POLICY "isolation" ON "public"."users"`,
+					},
+					{
+						Cmd:     `GRANT SELECT, INSERT ON TABLE "public"."users" TO "app"`,
+						Reverse: `REVOKE SELECT, INSERT ON TABLE "public"."users" FROM "app"`,
+					},
+				},
+			},
+		},
+		{
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				t := schema.NewTable("users").SetSchema(s)
+				return []schema.Change{
+					&schema.ModifySchema{S: s, Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &Grant{Grantee: "app", Privileges: []string{"USAGE"}},
+							To:   &Grant{Grantee: "app", Privileges: []string{"USAGE", "CREATE"}},
+						},
+					}},
+					&schema.ModifyTable{T: t, Changes: []schema.Change{
+						&schema.ModifyAttr{From: &RowSecurity{Enabled: true}, To: &RowSecurity{}},
+						&schema.ModifyAttr{
+							From: &Policy{Name: "readers", As: PolicyPermissive, Command: "SELECT", Roles: []string{"app"}, Using: "true"},
+							To:   &Policy{Name: "readers", As: PolicyRestrictive, Command: "SELECT", Roles: []string{"app"}, Using: "true"},
+						},
+						&schema.DropAttr{A: &Grant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}}},
+						&schema.ModifyAttr{
+							From: &Grant{Grantee: "app", Privileges: []string{"INSERT", "SELECT"}},
+							To:   &Grant{Grantee: "app", Privileges: []string{"select", "update"}},
+						},
+					}},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `GRANT CREATE ON SCHEMA "public" TO "app"`,
+						Reverse: `REVOKE CREATE ON SCHEMA "public" FROM "app"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."users" DISABLE ROW LEVEL SECURITY`,
+						Reverse: `ALTER TABLE "public"."users" ENABLE ROW LEVEL SECURITY`,
+					},
+					{
+						Cmd:     `DROP POLICY "readers" ON "public"."users"`,
+						Reverse: `CREATE POLICY "readers" ON "public"."users" FOR SELECT TO "app" USING (true)`,
+					},
+					{
+						Cmd:     `CREATE POLICY "readers" ON "public"."users" AS RESTRICTIVE FOR SELECT TO "app" USING (true)`,
+						Reverse: `DROP POLICY "readers" ON "public"."users"`,
+					},
+					{
+						Cmd:     `REVOKE SELECT ON TABLE "public"."users" FROM PUBLIC`,
+						Reverse: `GRANT SELECT ON TABLE "public"."users" TO PUBLIC`,
+					},
+					{
+						Cmd:     `REVOKE INSERT ON TABLE "public"."users" FROM "app"`,
+						Reverse: `GRANT INSERT ON TABLE "public"."users" TO "app"`,
+					},
+					{
+						Cmd:     `GRANT UPDATE ON TABLE "public"."users" TO "app"`,
+						Reverse: `REVOKE UPDATE ON TABLE "public"."users" FROM "app"`,
+					},
+				},
+			},
+		},
+		{
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				users := schema.NewTable("users").SetSchema(s).AddColumns(schema.NewIntColumn("id", "bigint"))
+				old := schema.NewTable("old").SetSchema(s).AddColumns(schema.NewIntColumn("id", "bigint"))
+				return []schema.Change{
+					&schema.AddSequence{S: schema.NewSequence("orders").SetSchema(s).SetType("integer").SetIncrement(2).SetMin(0).SetStart(10).SetOwner(users, users.Columns[0])},
+					&schema.AddTable{T: users},
+					&schema.ModifyTable{T: old, Changes: []schema.Change{&schema.DropColumn{C: old.Columns[0]}}},
+					&schema.ModifySequence{
+						From: schema.NewSequence("counter").SetSchema(s),
+						To:   schema.NewSequence("counter").SetSchema(s).SetMax(100).SetCycle(true),
+					},
+					&schema.DropSequence{S: schema.NewSequence("legacy").SetSchema(s).SetCache(10)},
+					// Dropped along with its owner column.
+					&schema.DropSequence{S: schema.NewSequence("old_ids").SetSchema(s).SetOwner(old, old.Columns[0])},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE SEQUENCE "public"."orders" AS integer INCREMENT BY 2 MINVALUE 0 START WITH 10`,
+						Reverse: `DROP SEQUENCE "public"."orders"`,
+					},
+					{
+						Cmd:     `CREATE TABLE "public"."users" ("id" bigint NOT NULL)`,
+						Reverse: `DROP TABLE "public"."users"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."old" DROP COLUMN "id"`,
+						Reverse: `ALTER TABLE "public"."old" ADD COLUMN "id" bigint NOT NULL`,
+					},
+					{
+						Cmd:     `ALTER SEQUENCE "public"."orders" OWNED BY "public"."users"."id"`,
+						Reverse: `ALTER SEQUENCE "public"."orders" OWNED BY NONE`,
+					},
+					{
+						Cmd:     `ALTER SEQUENCE "public"."counter" MAXVALUE 100 CYCLE`,
+						Reverse: `ALTER SEQUENCE "public"."counter" NO MAXVALUE NO CYCLE`,
+					},
+					{
+						Cmd:     `DROP SEQUENCE "public"."legacy"`,
+						Reverse: `CREATE SEQUENCE "public"."legacy" CACHE 10`,
+					},
+				},
+			},
+		},
 	}
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			db, mk, err := sqlmock.New()
 			require.NoError(t, err)
 			m := mock{mk}
-			m.version("130000")
+			if tt.version == "" {
+				tt.version = "130000"
+			}
+			m.version(tt.version)
 			if tt.mock != nil {
 				tt.mock(m)
 			}
@@ -1156,10 +1909,61 @@ func TestPlanChanges(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, tt.wantPlan.Reversible, plan.Reversible)
 			require.Equal(t, tt.wantPlan.Transactional, plan.Transactional)
+			require.Len(t, plan.Changes, len(tt.wantPlan.Changes))
 			for i, c := range plan.Changes {
 				require.Equal(t, tt.wantPlan.Changes[i].Cmd, c.Cmd)
 				require.Equal(t, tt.wantPlan.Changes[i].Reverse, c.Reverse)
+				require.Equal(t, tt.wantPlan.Changes[i].NoTx, c.NoTx)
 			}
 		})
 	}
 }
+
+func TestPlanChanges_RowTTL(t *testing.T) {
+	c := schema.NewTimeColumn("created_at", "timestamptz")
+	tbl := schema.NewTable("events").SetSchema(schema.New("public")).AddColumns(c)
+	plan, err := (&planApply{conn: conn{crdb: true}}).PlanChanges(context.Background(), "", []schema.Change{
+		&schema.ModifyTable{
+			T:       tbl,
+			Changes: []schema.Change{&schema.AddAttr{A: &schema.Retention{C: c, Period: 90, Unit: schema.RetentionDay}}},
+		},
+		&schema.ModifyTable{
+			T: tbl,
+			Changes: []schema.Change{
+				&schema.ModifyAttr{
+					From: &schema.Retention{C: c, Period: 90, Unit: schema.RetentionDay},
+					To:   &schema.Retention{C: c, Period: 6, Unit: schema.RetentionMonth},
+				},
+			},
+		},
+		&schema.ModifyTable{
+			T:       tbl,
+			Changes: []schema.Change{&schema.DropAttr{A: &schema.Retention{C: c, Period: 6, Unit: schema.RetentionMonth}}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `ALTER TABLE "public"."events" SET (ttl_expiration_expression = '("created_at" + INTERVAL ''90 days'')')`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."events" RESET (ttl)`, plan.Changes[0].Reverse)
+	require.Equal(t, `ALTER TABLE "public"."events" SET (ttl_expiration_expression = '("created_at" + INTERVAL ''6 months'')')`, plan.Changes[1].Cmd)
+	require.Equal(t, plan.Changes[0].Cmd, plan.Changes[1].Reverse)
+	require.Equal(t, `ALTER TABLE "public"."events" RESET (ttl)`, plan.Changes[2].Cmd)
+	require.Equal(t, plan.Changes[1].Cmd, plan.Changes[2].Reverse)
+}
+
+func TestDefaultPlan(t *testing.T) {
+	s := schema.New("public")
+	users := schema.NewTable("users").
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off")),
+		)
+	s.AddTables(users)
+	changes, err := DefaultDiff.SchemaDiff(schema.New("public"), s)
+	require.NoError(t, err)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "default", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TYPE "public"."state" AS ENUM ('on', 'off')`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL, "state" "public"."state" NOT NULL)`, plan.Changes[1].Cmd)
+}
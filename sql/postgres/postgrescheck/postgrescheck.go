@@ -5,11 +5,17 @@
 package postgrescheck
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"regexp"
 
 	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/blocking"
 	"ariga.io/atlas/sql/sqlcheck/datadepend"
 	"ariga.io/atlas/sql/sqlcheck/destructive"
 )
@@ -30,6 +36,222 @@ func addNotNull(p *datadepend.ColumnPass) (diags []sqlcheck.Diagnostic, err erro
 	}, nil
 }
 
+// List of PostgreSQL specific codes.
+var (
+	codeReplicaIdentity = sqlcheck.Code("PG101")
+	codeVolatileDefault = sqlcheck.Code("PG102")
+	codeNoPrimaryKey    = sqlcheck.Code("PG103")
+	codeRenameObject    = sqlcheck.Code("PG104")
+	codeChangeType      = sqlcheck.Code("PG105")
+)
+
+// LogicalReplication checks for changes that are unsupported or dangerous when the
+// database is replicated using logical replication (e.g. pglogical or AWS DMS). DDL
+// is not replicated in this mode, and it is applied separately on the publisher and
+// the subscriber. Hence, the analyzer is enabled only if it is configured explicitly
+// in the lint configuration.
+type LogicalReplication struct {
+	sqlcheck.Options
+}
+
+// NewLogicalReplication creates a new LogicalReplication analyzer with the given options.
+func NewLogicalReplication(r *schemahcl.Resource) (*LogicalReplication, error) {
+	az := &LogicalReplication{}
+	az.Error = sqlx.P(true)
+	if r, ok := r.Resource(az.Name()); ok {
+		if err := r.As(&az.Options); err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: parsing logical_replication check options: %w", err)
+		}
+	}
+	return az, nil
+}
+
+// Name of the analyzer. Implements the sqlcheck.NamedAnalyzer interface.
+func (*LogicalReplication) Name() string {
+	return "logical_replication"
+}
+
+// reReplicaIdentity matches statements that change the replica identity of a table,
+// as it is not part of the schema representation of tables.
+var reReplicaIdentity = regexp.MustCompile(`(?i)\bREPLICA\s+IDENTITY\b`)
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *LogicalReplication) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	var diags []sqlcheck.Diagnostic
+	for _, sc := range p.File.Changes {
+		if reReplicaIdentity.MatchString(sc.Stmt.Text) {
+			diags = append(diags, sqlcheck.Diagnostic{
+				Code: codeReplicaIdentity,
+				Pos:  sc.Stmt.Pos,
+				Text: "Changing the replica identity of a table changes the data that is published for updated and deleted rows",
+			})
+		}
+		for _, c := range sc.Changes {
+			switch c := c.(type) {
+			case *schema.AddTable:
+				if c.T.PrimaryKey == nil {
+					diags = append(diags, sqlcheck.Diagnostic{
+						Code: codeNoPrimaryKey,
+						Pos:  sc.Stmt.Pos,
+						Text: fmt.Sprintf("Table %q has no primary key. Updates and deletes cannot be replicated without a replica identity", c.T.Name),
+					})
+				}
+			case *schema.RenameTable:
+				diags = append(diags, sqlcheck.Diagnostic{
+					Code: codeRenameObject,
+					Pos:  sc.Stmt.Pos,
+					Text: fmt.Sprintf("Renaming table %q to %q breaks the replication of the table, as subscribers match tables by name", c.From.Name, c.To.Name),
+				})
+			case *schema.ModifyTable:
+				for _, tc := range c.Changes {
+					switch tc := tc.(type) {
+					case *schema.AddColumn:
+						if x, ok := volatileDefault(tc.C); ok && p.File.TableSpan(c.T)&sqlcheck.SpanAdded == 0 {
+							diags = append(diags, sqlcheck.Diagnostic{
+								Code: codeVolatileDefault,
+								Pos:  sc.Stmt.Pos,
+								Text: fmt.Sprintf("Adding column %q with a non-deterministic default (%s) to table %q sets different values on the publisher and the subscriber", tc.C.Name, x, c.T.Name),
+							})
+						}
+					case *schema.RenameColumn:
+						diags = append(diags, sqlcheck.Diagnostic{
+							Code: codeRenameObject,
+							Pos:  sc.Stmt.Pos,
+							Text: fmt.Sprintf("Renaming column %q to %q of table %q breaks the replication of the table, as subscribers match columns by name", tc.From.Name, tc.To.Name, c.T.Name),
+						})
+					case *schema.ModifyColumn:
+						if tc.Change.Is(schema.ChangeType) {
+							diags = append(diags, sqlcheck.Diagnostic{
+								Code: codeChangeType,
+								Pos:  sc.Stmt.Pos,
+								Text: fmt.Sprintf("Changing the type of column %q of table %q fails the replication of the table if published values cannot be converted to the subscriber type", tc.To.Name, c.T.Name),
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+	if len(diags) > 0 {
+		const reportText = "changes unsafe for logical replication detected"
+		p.Reporter.WriteReport(sqlcheck.Report{Text: reportText, Diagnostics: diags})
+		if sqlx.V(a.Error) {
+			return errors.New(reportText)
+		}
+	}
+	return nil
+}
+
+// reVolatileFunc matches calls to functions that return a different value
+// on every call, or on every transaction (i.e. non-immutable functions).
+var reVolatileFunc = regexp.MustCompile(`(?i)\b(random|gen_random_uuid|uuid_generate_v1mc|uuid_generate_v1|uuid_generate_v4|clock_timestamp|statement_timestamp|transaction_timestamp|timeofday|now|nextval|txid_current)\s*\(|\b(current_timestamp|current_date|current_time|localtimestamp|localtime)\b`)
+
+// volatileDefault reports if the column has a default value that is
+// computed separately on each database, and returns its expression.
+func volatileDefault(c *schema.Column) (string, bool) {
+	if _, ok := c.Type.Type.(*postgres.SerialType); ok {
+		return "nextval", true
+	}
+	x, ok := c.Default.(*schema.RawExpr)
+	if !ok || !reVolatileFunc.MatchString(x.X) {
+		return "", false
+	}
+	return x.X, true
+}
+
+// reCreateIndex matches the beginning of CREATE INDEX statements, up to the index name.
+var reCreateIndex = regexp.MustCompile(`(?i)^\s*CREATE\s+(?:UNIQUE\s+)?INDEX\s+`)
+
+// reConcurrently matches statements that build indexes concurrently.
+var reConcurrently = regexp.MustCompile(`(?i)^\s*CREATE\s+(?:UNIQUE\s+)?INDEX\s+CONCURRENTLY\b`)
+
+// reRewriteFunc matches calls to volatile functions. Unlike stable functions
+// (e.g. now()), a column default that uses them rewrites the table.
+var reRewriteFunc = regexp.MustCompile(`(?i)\b(random|gen_random_uuid|uuid_generate_v1mc|uuid_generate_v1|uuid_generate_v4|clock_timestamp|timeofday|nextval)\s*\(`)
+
+func modifyBlocking(p *blocking.ColumnPass) ([]sqlcheck.Diagnostic, error) {
+	from, err := postgres.FormatType(p.From.Type.Type)
+	if err != nil {
+		return nil, err
+	}
+	to, err := postgres.FormatType(p.Column.Type.Type)
+	if err != nil {
+		return nil, err
+	}
+	if from == to || binaryCoercible(p.From, p.Column) {
+		return nil, nil
+	}
+	return []sqlcheck.Diagnostic{
+		{
+			Pos:  p.Change.Stmt.Pos,
+			Text: fmt.Sprintf("Changing the type of column %q of table %q from %q to %q rewrites the table and blocks reads and writes until it is done", p.Column.Name, p.Table.Name, from, to),
+			SuggestedFixes: []sqlcheck.SuggestedFix{
+				{Message: "Add a new column with the new type, backfill it in batches, and switch the application to use it"},
+			},
+		},
+	}, nil
+}
+
+// binaryCoercible reports if the column type was changed to a binary
+// coercible type that does not require a rewrite (e.g. varchar to text).
+func binaryCoercible(from, to *schema.Column) bool {
+	f, ok1 := from.Type.Type.(*schema.StringType)
+	t, ok2 := to.Type.Type.(*schema.StringType)
+	switch {
+	case !ok1 || !ok2 || f.T != postgres.TypeVarChar && f.T != postgres.TypeCharVar:
+		return false
+	case t.T == postgres.TypeText:
+		return true
+	case t.T == postgres.TypeVarChar || t.T == postgres.TypeCharVar:
+		// Removing the size limit or extending it.
+		return t.Size == 0 || f.Size != 0 && f.Size <= t.Size
+	}
+	return false
+}
+
+func addColumnBlocking(p *blocking.ColumnPass) ([]sqlcheck.Diagnostic, error) {
+	var (
+		x         string
+		_, serial = p.Column.Type.Type.(*postgres.SerialType)
+	)
+	switch d, ok := p.Column.Default.(*schema.RawExpr); {
+	case serial:
+		x = "nextval"
+	case ok && reRewriteFunc.MatchString(d.X):
+		x = d.X
+	default:
+		return nil, nil
+	}
+	return []sqlcheck.Diagnostic{
+		{
+			Pos:  p.Change.Stmt.Pos,
+			Text: fmt.Sprintf("Adding column %q with a volatile default (%s) rewrites table %q and blocks reads and writes until it is done", p.Column.Name, x, p.Table.Name),
+			SuggestedFixes: []sqlcheck.SuggestedFix{
+				{Message: "Add the column without a default, set the default in a separate statement, and backfill the existing rows in batches"},
+			},
+		},
+	}, nil
+}
+
+func addIndexBlocking(p *blocking.IndexPass) ([]sqlcheck.Diagnostic, error) {
+	d := sqlcheck.Diagnostic{
+		Pos:  p.Change.Stmt.Pos,
+		Text: fmt.Sprintf("Creating index %q on table %q non-concurrently blocks writes to the table until it is built", p.Index.Name, p.Table.Name),
+	}
+	if loc := reCreateIndex.FindStringIndex(p.Change.Stmt.Text); loc != nil {
+		pos := p.Change.Stmt.Pos + loc[1]
+		d.SuggestedFixes = append(d.SuggestedFixes, sqlcheck.SuggestedFix{
+			Message:  "Create the index concurrently. Note that CREATE INDEX CONCURRENTLY cannot run inside a transaction",
+			TextEdit: &sqlcheck.TextEdit{Pos: pos, End: pos, NewText: "CONCURRENTLY "},
+		})
+	} else {
+		d.SuggestedFixes = append(d.SuggestedFixes, sqlcheck.SuggestedFix{
+			Message: "Create a unique index concurrently, and attach it to the table using ADD CONSTRAINT ... USING INDEX",
+		})
+	}
+	return []sqlcheck.Diagnostic{d}, nil
+}
+
 func init() {
 	sqlcheck.Register(postgres.DriverName, func(r *schemahcl.Resource) ([]sqlcheck.Analyzer, error) {
 		ds, err := destructive.New(r)
@@ -39,6 +261,27 @@ func init() {
 		dd, err := datadepend.New(r, datadepend.Handler{
 			AddNotNull: addNotNull,
 		})
-		return []sqlcheck.Analyzer{ds, dd}, nil
+		if err != nil {
+			return nil, err
+		}
+		lr, err := NewLogicalReplication(r)
+		if err != nil {
+			return nil, err
+		}
+		bc, err := blocking.New(r, blocking.Handler{
+			Online:       reConcurrently.MatchString,
+			AddColumn:    addColumnBlocking,
+			ModifyColumn: modifyBlocking,
+			AddIndex:     addIndexBlocking,
+		})
+		if err != nil {
+			return nil, err
+		}
+		azs := []sqlcheck.Analyzer{ds, dd}
+		// Logical replication checks are opt-in.
+		if _, ok := r.Resource(lr.Name()); ok {
+			azs = append(azs, lr)
+		}
+		return append(azs, bc), nil
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"context"
 	"testing"
 
+	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/postgres"
 	_ "ariga.io/atlas/sql/postgres/postgrescheck"
@@ -55,6 +56,221 @@ func TestDataDepend_MightFail(t *testing.T) {
 	require.Equal(t, report.Diagnostics[0].Text, `Adding a non-nullable "int" column "b" will fail in case table "users" is not empty`)
 }
 
+func TestLogicalReplication(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		s      = schema.New("public")
+		users  = schema.NewTable("users").
+			SetSchema(s).
+			AddColumns(schema.NewIntColumn("id", postgres.TypeInt))
+		logs = schema.NewTable("logs").
+			SetSchema(s).
+			AddColumns(schema.NewIntColumn("id", postgres.TypeInt))
+		pass = &sqlcheck.Pass{
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE users ADD COLUMN created_at timestamp DEFAULT now()", Pos: 1},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: []schema.Change{
+									&schema.AddColumn{C: schema.NewTimeColumn("created_at", postgres.TypeTimestamp).SetDefault(&schema.RawExpr{X: "now()"})},
+									&schema.AddColumn{C: schema.NewIntColumn("rank", postgres.TypeInt).SetDefault(&schema.Literal{V: "1"})},
+								},
+							},
+						},
+					},
+					{
+						Stmt:    &migrate.Stmt{Text: "CREATE TABLE logs (id int)", Pos: 2},
+						Changes: schema.Changes{&schema.AddTable{T: logs}},
+					},
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE logs ADD COLUMN uid uuid DEFAULT gen_random_uuid()", Pos: 3},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: logs,
+								Changes: []schema.Change{
+									&schema.AddColumn{C: schema.NewColumn("uid").SetType(&postgres.UUIDType{T: postgres.TypeUUID}).SetDefault(&schema.RawExpr{X: "gen_random_uuid()"})},
+								},
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE users RENAME COLUMN id TO uid", Pos: 4},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: []schema.Change{
+									&schema.RenameColumn{From: schema.NewIntColumn("id", postgres.TypeInt), To: schema.NewIntColumn("uid", postgres.TypeInt)},
+									&schema.ModifyColumn{
+										From:   schema.NewIntColumn("uid", postgres.TypeInt),
+										To:     schema.NewIntColumn("uid", postgres.TypeBigInt),
+										Change: schema.ChangeType,
+									},
+								},
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE users REPLICA IDENTITY FULL", Pos: 5},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	// Disabled by default.
+	azs, err := sqlcheck.AnalyzerFor(postgres.DriverName, nil)
+	require.NoError(t, err)
+	require.Len(t, azs, 3)
+
+	azs, err = sqlcheck.AnalyzerFor(postgres.DriverName, &schemahcl.Resource{
+		Children: []*schemahcl.Resource{{Type: "logical_replication"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, azs, 4)
+	err = azs[2].Analyze(context.Background(), pass)
+	require.EqualError(t, err, "changes unsafe for logical replication detected")
+	require.Equal(t, "changes unsafe for logical replication detected", report.Text)
+	require.Len(t, report.Diagnostics, 5)
+	require.Equal(t, sqlcheck.Diagnostic{Pos: 1, Code: "PG102", Text: `Adding column "created_at" with a non-deterministic default (now()) to table "users" sets different values on the publisher and the subscriber`}, report.Diagnostics[0])
+	require.Equal(t, sqlcheck.Diagnostic{Pos: 2, Code: "PG103", Text: `Table "logs" has no primary key. Updates and deletes cannot be replicated without a replica identity`}, report.Diagnostics[1])
+	require.Equal(t, sqlcheck.Diagnostic{Pos: 4, Code: "PG104", Text: `Renaming column "id" to "uid" of table "users" breaks the replication of the table, as subscribers match columns by name`}, report.Diagnostics[2])
+	require.Equal(t, sqlcheck.Diagnostic{Pos: 4, Code: "PG105", Text: `Changing the type of column "uid" of table "users" fails the replication of the table if published values cannot be converted to the subscriber type`}, report.Diagnostics[3])
+	require.Equal(t, sqlcheck.Diagnostic{Pos: 5, Code: "PG101", Text: "Changing the replica identity of a table changes the data that is published for updated and deleted rows"}, report.Diagnostics[4])
+
+	// Reported without failing.
+	azs, err = sqlcheck.AnalyzerFor(postgres.DriverName, &schemahcl.Resource{
+		Children: []*schemahcl.Resource{{
+			Type:  "logical_replication",
+			Attrs: []*schemahcl.Attr{schemahcl.LitAttr("error", "false")},
+		}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, azs[2].Analyze(context.Background(), pass))
+}
+
+func TestBlocking(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		s      = schema.New("public")
+		users  = schema.NewTable("users").
+			SetSchema(s).
+			AddColumns(
+				schema.NewIntColumn("id", postgres.TypeInt),
+				schema.NewStringColumn("name", postgres.TypeVarChar, schema.StringSize(100)),
+			)
+		pass = &sqlcheck.Pass{
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE users ALTER COLUMN id TYPE bigint, ALTER COLUMN name TYPE text", Pos: 1},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: []schema.Change{
+									&schema.ModifyColumn{
+										From:   schema.NewIntColumn("id", postgres.TypeInt),
+										To:     schema.NewIntColumn("id", postgres.TypeBigInt),
+										Change: schema.ChangeType,
+									},
+									&schema.ModifyColumn{
+										From:   schema.NewStringColumn("name", postgres.TypeVarChar, schema.StringSize(100)),
+										To:     schema.NewStringColumn("name", postgres.TypeText),
+										Change: schema.ChangeType,
+									},
+								},
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE users ADD COLUMN uid uuid DEFAULT gen_random_uuid(), ADD COLUMN created_at timestamp DEFAULT now()", Pos: 2},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: []schema.Change{
+									&schema.AddColumn{C: schema.NewColumn("uid").SetType(&postgres.UUIDType{T: postgres.TypeUUID}).SetDefault(&schema.RawExpr{X: "gen_random_uuid()"})},
+									&schema.AddColumn{C: schema.NewTimeColumn("created_at", postgres.TypeTimestamp).SetDefault(&schema.RawExpr{X: "now()"})},
+								},
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{Text: "CREATE INDEX users_name ON users (name)", Pos: 3},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T:       users,
+								Changes: []schema.Change{&schema.AddIndex{I: schema.NewIndex("users_name").AddColumns(users.Columns[1])}},
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{Text: "CREATE INDEX CONCURRENTLY users_id ON users (id)", Pos: 4},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T:       users,
+								Changes: []schema.Change{&schema.AddIndex{I: schema.NewIndex("users_id").AddColumns(users.Columns[0])}},
+							},
+						},
+					},
+					// Indexes planned with the concurrent indexes mode.
+					{
+						Stmt: &migrate.Stmt{Text: `CREATE UNIQUE INDEX CONCURRENTLY "users_uid" ON "public"."users" ("id")`, Pos: 5},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T:       users,
+								Changes: []schema.Change{&schema.AddIndex{I: schema.NewUniqueIndex("users_uid").AddColumns(users.Columns[0])}},
+							},
+						},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	azs, err := sqlcheck.AnalyzerFor(postgres.DriverName, nil)
+	require.NoError(t, err)
+	// Reported without failing.
+	require.NoError(t, azs[2].Analyze(context.Background(), pass))
+	require.Equal(t, "blocking changes detected", report.Text)
+	require.Equal(t, []sqlcheck.Diagnostic{
+		{
+			Pos:  1,
+			Code: "BC101",
+			Text: `Changing the type of column "id" of table "users" from "integer" to "bigint" rewrites the table and blocks reads and writes until it is done`,
+			SuggestedFixes: []sqlcheck.SuggestedFix{
+				{Message: "Add a new column with the new type, backfill it in batches, and switch the application to use it"},
+			},
+		},
+		{
+			Pos:  2,
+			Code: "BC102",
+			Text: `Adding column "uid" with a volatile default (gen_random_uuid()) rewrites table "users" and blocks reads and writes until it is done`,
+			SuggestedFixes: []sqlcheck.SuggestedFix{
+				{Message: "Add the column without a default, set the default in a separate statement, and backfill the existing rows in batches"},
+			},
+		},
+		{
+			Pos:  3,
+			Code: "BC103",
+			Text: `Creating index "users_name" on table "users" non-concurrently blocks writes to the table until it is built`,
+			SuggestedFixes: []sqlcheck.SuggestedFix{
+				{
+					Message:  "Create the index concurrently. Note that CREATE INDEX CONCURRENTLY cannot run inside a transaction",
+					TextEdit: &sqlcheck.TextEdit{Pos: 16, End: 16, NewText: "CONCURRENTLY "},
+				},
+			},
+		},
+	}, report.Diagnostics)
+}
+
 type testFile struct {
 	name string
 	migrate.File
@@ -0,0 +1,89 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+const (
+	// Query if the current role can create schemas in the database.
+	createSchemaPrivQuery = "SELECT has_database_privilege(current_database(), 'CREATE')"
+
+	// Query if the current role can create tables in the schema. Schemas
+	// that do not exist are created by the role, and therefore owned by it.
+	createTablePrivQuery = "SELECT COALESCE((SELECT has_schema_privilege(oid, 'CREATE') FROM pg_catalog.pg_namespace WHERE nspname = COALESCE(NULLIF($1, ''), current_schema())), true)"
+
+	// Query if the current role is a member of the role owning the schema.
+	schemaOwnerQuery = "SELECT COALESCE((SELECT pg_has_role(nspowner, 'USAGE') FROM pg_catalog.pg_namespace WHERE nspname = COALESCE(NULLIF($1, ''), current_schema())), true)"
+
+	// Query if the current role is a member of the role owning the table.
+	tableOwnerQuery = `SELECT COALESCE((SELECT pg_has_role(c.relowner, 'USAGE') FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relname = $2), true)`
+
+	// Query if the current role can reference the table in foreign keys.
+	referencesPrivQuery = `SELECT COALESCE((SELECT has_table_privilege(c.oid, 'REFERENCES') FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relname = $2), true)`
+)
+
+// MissingPrivileges implements migrate.PrivilegeChecker. Note, objects that do not exist in
+// the database are expected to be created by the changes, and therefore owned by the role.
+func (d *Driver) MissingPrivileges(ctx context.Context, changes []schema.Change) ([]*migrate.Privilege, error) {
+	var (
+		missing []*migrate.Privilege
+		checked = make(map[string]bool)
+	)
+	for _, a := range sqlx.PrivActions(changes) {
+		s := a.Schema
+		if s == "" {
+			s = d.schema
+		}
+		var (
+			query string
+			args  = []any{s}
+			p     = &migrate.Privilege{Reason: a.Reason}
+		)
+		switch a.Kind {
+		case sqlx.PrivCreateSchema:
+			query, args = createSchemaPrivQuery, nil
+			p.Name, p.Object = "CREATE", "database"
+		case sqlx.PrivDropSchema, sqlx.PrivAlterSchema:
+			query = schemaOwnerQuery
+			p.Name, p.Object = "OWNER", fmt.Sprintf("schema %q", s)
+		case sqlx.PrivCreateTable:
+			query = createTablePrivQuery
+			p.Name, p.Object = "CREATE", fmt.Sprintf("schema %q", s)
+		case sqlx.PrivDropTable, sqlx.PrivAlterTable, sqlx.PrivIndex, sqlx.PrivRenameFrom:
+			query, args = tableOwnerQuery, append(args, a.Table)
+			p.Name, p.Object = "OWNER", fmt.Sprintf("table %q.%q", s, a.Table)
+		case sqlx.PrivReferences:
+			query, args = referencesPrivQuery, append(args, a.Table)
+			p.Name, p.Object = "REFERENCES", fmt.Sprintf("table %q.%q", s, a.Table)
+		default:
+			// Renaming a table within its schema requires only its ownership.
+			continue
+		}
+		key := p.Name + p.Object
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+		rows, err := d.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: checking %s privilege on %s: %w", p.Name, p.Object, err)
+		}
+		held, err := sqlx.ScanNullBool(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scanning %s privilege on %s: %w", p.Name, p.Object, err)
+		}
+		if !held.Bool {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
@@ -20,9 +20,19 @@ import (
 
 type (
 	doc struct {
-		Tables  []*sqlspec.Table  `spec:"table"`
-		Enums   []*Enum           `spec:"enum"`
-		Schemas []*sqlspec.Schema `spec:"schema"`
+		Tables          []*sqlspec.Table     `spec:"table"`
+		Views           []*sqlspec.View      `spec:"view"`
+		Triggers        []*sqlspec.Trigger   `spec:"trigger"`
+		Funcs           []*sqlspec.Func      `spec:"function"`
+		Procs           []*sqlspec.Proc      `spec:"procedure"`
+		Sequences       []*sqlspec.Sequence  `spec:"sequence"`
+		Enums           []*Enum              `spec:"enum"`
+		Aggregates      []*ObjectSpec        `spec:"aggregate"`
+		Operators       []*ObjectSpec        `spec:"operator"`
+		OperatorClasses []*ObjectSpec        `spec:"operator_class"`
+		Schemas         []*sqlspec.Schema    `spec:"schema"`
+		Settings        []*sqlspec.Setting   `spec:"setting"`
+		Unmanaged       []*sqlspec.Unmanaged `spec:"unmanaged"`
 	}
 	// Enum holds a specification for an enum, that can be referenced as a column type.
 	Enum struct {
@@ -31,6 +41,21 @@ type (
 		Values []string       `spec:"values"`
 		schemahcl.DefaultExtension
 	}
+	// ObjectSpec holds a specification for an aggregate, operator or operator class.
+	// The definition is written as-is after the object name in its CREATE statement.
+	ObjectSpec struct {
+		Name   string         `spec:",name"`
+		Schema *schemahcl.Ref `spec:"schema"`
+		// Args holds the argument types of aggregates and operators.
+		Args string `spec:"args,omitempty"`
+		// Using holds the index method of operator classes.
+		Using string `spec:"using,omitempty"`
+		As    string `spec:"as,omitempty"`
+		// Hash identifies the definition of objects that were
+		// inspected from the database, as their definition is not.
+		Hash string `spec:"hash,omitempty"`
+		schemahcl.DefaultExtension
+	}
 )
 
 func init() {
@@ -53,6 +78,28 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]string) error {
 				return err
 			}
 		}
+		if err := convertObjects(&d, v); err != nil {
+			return err
+		}
+		if err := convertSchemaGrants(&d, v); err != nil {
+			return err
+		}
+		if err := specutil.Views(v, d.Views); err != nil {
+			return err
+		}
+		if err := convertFuncs(&d, v); err != nil {
+			return err
+		}
+		if err := specutil.Triggers(v, d.Triggers); err != nil {
+			return err
+		}
+		if err := specutil.Sequences(v, d.Sequences); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(v, d.Unmanaged); err != nil {
+			return err
+		}
+		v.Attrs = append(v.Attrs, specutil.Settings(d.Settings)...)
 	case *schema.Schema:
 		if len(d.Schemas) != 1 {
 			return fmt.Errorf("specutil: expecting document to contain a single schema, got %d", len(d.Schemas))
@@ -64,6 +111,27 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]string) error {
 		if err := convertEnums(d.Tables, d.Enums, r); err != nil {
 			return err
 		}
+		if err := convertObjects(&d, r); err != nil {
+			return err
+		}
+		if err := convertSchemaGrants(&d, r); err != nil {
+			return err
+		}
+		if err := specutil.Views(r, d.Views); err != nil {
+			return err
+		}
+		if err := convertFuncs(&d, r); err != nil {
+			return err
+		}
+		if err := specutil.Triggers(r, d.Triggers); err != nil {
+			return err
+		}
+		if err := specutil.Sequences(r, d.Sequences); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(r, d.Unmanaged); err != nil {
+			return err
+		}
 		*v = *r.Schemas[0]
 	default:
 		return fmt.Errorf("specutil: failed unmarshaling spec. %T is not supported", v)
@@ -84,6 +152,13 @@ func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 		d.Tables = doc.Tables
 		d.Schemas = doc.Schemas
 		d.Enums = doc.Enums
+		d.Views = specutil.FromViews(s)
+		d.Funcs = specutil.FromFuncs(s)
+		d.Procs = specutil.FromProcs(s)
+		d.Triggers = specutil.FromTriggers(s, doc.Tables)
+		d.Sequences = specutil.FromSequences(s, doc.Tables)
+		d.Unmanaged = specutil.FromUnmanaged(s)
+		d.objects(s)
 	case *schema.Realm:
 		for _, s := range s.Schemas {
 			doc, err := schemaSpec(s)
@@ -93,6 +168,11 @@ func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 			d.Tables = append(d.Tables, doc.Tables...)
 			d.Schemas = append(d.Schemas, doc.Schemas...)
 			d.Enums = append(d.Enums, doc.Enums...)
+			d.Views = append(d.Views, specutil.FromViews(s)...)
+			d.Funcs = append(d.Funcs, specutil.FromFuncs(s)...)
+			d.Procs = append(d.Procs, specutil.FromProcs(s)...)
+			d.Unmanaged = append(d.Unmanaged, specutil.FromUnmanaged(s)...)
+			d.objects(s)
 		}
 		if err := specutil.QualifyDuplicates(d.Tables); err != nil {
 			return nil, err
@@ -100,6 +180,12 @@ func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 		if err := specutil.QualifyReferences(d.Tables, s); err != nil {
 			return nil, err
 		}
+		// Triggers and sequences are converted after the tables were qualified.
+		for _, s := range s.Schemas {
+			d.Triggers = append(d.Triggers, specutil.FromTriggers(s, d.Tables)...)
+			d.Sequences = append(d.Sequences, specutil.FromSequences(s, d.Tables)...)
+		}
+		d.Settings = specutil.FromSettings(s.Attrs)
 	default:
 		return nil, fmt.Errorf("specutil: failed marshaling spec. %T is not supported", v)
 	}
@@ -111,10 +197,16 @@ var (
 		schemahcl.WithTypes(TypeRegistry.Specs()),
 		schemahcl.WithScopedEnums("table.index.type", IndexTypeBTree, IndexTypeHash, IndexTypeGIN, IndexTypeGiST, IndexTypeBRIN),
 		schemahcl.WithScopedEnums("table.partition.type", PartitionTypeRange, PartitionTypeList, PartitionTypeHash),
+		schemahcl.WithScopedEnums("table.partition.part.partition.type", PartitionTypeRange, PartitionTypeList, PartitionTypeHash),
+		schemahcl.WithScopedEnums("table.partition.part.partition.part.partition.type", PartitionTypeRange, PartitionTypeList, PartitionTypeHash),
+		schemahcl.WithScopedEnums("table.policy.as", PolicyPermissive, PolicyRestrictive),
+		schemahcl.WithScopedEnums("table.policy.command", "ALL", "SELECT", "INSERT", "UPDATE", "DELETE"),
+		schemahcl.WithScopedEnums("sequence.type", TypeSmallInt, TypeInteger, TypeBigInt),
 		schemahcl.WithScopedEnums("table.column.identity.generated", GeneratedTypeAlways, GeneratedTypeByDefault),
 		schemahcl.WithScopedEnums("table.column.as.type", "STORED"),
 		schemahcl.WithScopedEnums("table.foreign_key.on_update", specutil.ReferenceVars...),
 		schemahcl.WithScopedEnums("table.foreign_key.on_delete", specutil.ReferenceVars...),
+		schemahcl.WithScopedEnums("table.retention.unit", specutil.RetentionUnits...),
 	)
 	// MarshalHCL marshals v into an Atlas HCL DDL document.
 	MarshalHCL = schemahcl.MarshalerFunc(func(v any) ([]byte, error) {
@@ -139,15 +231,167 @@ func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, er
 	if err := convertPartition(spec.Extra, t); err != nil {
 		return nil, err
 	}
+	if err := convertAccess(spec.Extra, t); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
+// convertAccess converts the row_security, policy and grant blocks
+// of the table and appends them to its attributes.
+func convertAccess(spec schemahcl.Resource, t *schema.Table) error {
+	// Blocks are converted by their type, as the order of
+	// the children of different types is not preserved.
+	var children []*schemahcl.Resource
+	for _, typ := range []string{"row_security", "policy", "grant"} {
+		for _, r := range spec.Children {
+			if r.Type == typ {
+				children = append(children, r)
+			}
+		}
+	}
+	for _, r := range children {
+		switch r.Type {
+		case "row_security":
+			var rs RowSecurity
+			if err := r.As(&struct {
+				Enabled *bool `spec:"enabled"`
+				Forced  *bool `spec:"forced"`
+			}{Enabled: &rs.Enabled, Forced: &rs.Forced}); err != nil {
+				return fmt.Errorf("parsing %s.row_security: %w", t.Name, err)
+			}
+			t.AddAttrs(&rs)
+		case "policy":
+			var p struct {
+				As      string   `spec:"as"`
+				Command string   `spec:"command"`
+				To      []string `spec:"to"`
+				Using   string   `spec:"using"`
+				Check   string   `spec:"check"`
+			}
+			if err := r.As(&p); err != nil {
+				return fmt.Errorf("parsing %s.policy.%s: %w", t.Name, r.Name, err)
+			}
+			t.AddAttrs(&Policy{Name: r.Name, As: p.As, Command: p.Command, Roles: p.To, Using: p.Using, Check: p.Check})
+		case "grant":
+			g, err := convertGrant(r, t.Name)
+			if err != nil {
+				return err
+			}
+			t.AddAttrs(g)
+		}
+	}
+	return nil
+}
+
+// convertGrant converts the grant block of a table or a schema.
+func convertGrant(r *schemahcl.Resource, path string) (*Grant, error) {
+	var g struct {
+		Privileges []string `spec:"privileges"`
+	}
+	if err := r.As(&g); err != nil {
+		return nil, fmt.Errorf("parsing %s.grant.%s: %w", path, r.Name, err)
+	}
+	if len(g.Privileges) == 0 {
+		return nil, fmt.Errorf("missing attribute %s.grant.%s.privileges", path, r.Name)
+	}
+	return &Grant{Grantee: r.Name, Privileges: g.Privileges}, nil
+}
+
+// convertSchemaGrants converts the grant blocks of the schemas
+// in the document and appends them to the schema attributes.
+func convertSchemaGrants(d *doc, r *schema.Realm) error {
+	for _, spec := range d.Schemas {
+		s, ok := r.Schema(spec.Name)
+		if !ok {
+			continue
+		}
+		for _, c := range spec.Extra.Children {
+			if c.Type != "grant" {
+				continue
+			}
+			g, err := convertGrant(c, spec.Name)
+			if err != nil {
+				return err
+			}
+			s.AddAttrs(g)
+		}
+	}
+	return nil
+}
+
+// fromAccess appends the row_security, policy and grant blocks of the table to its spec.
+func fromAccess(spec *sqlspec.Table, t *schema.Table) {
+	for _, a := range t.Attrs {
+		switch a := a.(type) {
+		case *RowSecurity:
+			if !a.Enabled && !a.Forced {
+				continue
+			}
+			r := &schemahcl.Resource{Type: "row_security", Attrs: []*schemahcl.Attr{specutil.BoolAttr("enabled", a.Enabled)}}
+			if a.Forced {
+				r.Attrs = append(r.Attrs, specutil.BoolAttr("forced", true))
+			}
+			spec.Extra.Children = append(spec.Extra.Children, r)
+		case *Policy:
+			r := &schemahcl.Resource{Type: "policy", Name: a.Name}
+			if strings.EqualFold(a.As, PolicyRestrictive) {
+				r.Attrs = append(r.Attrs, specutil.VarAttr("as", PolicyRestrictive))
+			}
+			if a.Command != "" && !strings.EqualFold(a.Command, "ALL") {
+				r.Attrs = append(r.Attrs, specutil.VarAttr("command", strings.ToUpper(a.Command)))
+			}
+			if len(a.Roles) > 0 {
+				r.Attrs = append(r.Attrs, strListAttr("to", a.Roles))
+			}
+			if a.Using != "" {
+				r.Attrs = append(r.Attrs, specutil.StrAttr("using", a.Using))
+			}
+			if a.Check != "" {
+				r.Attrs = append(r.Attrs, specutil.StrAttr("check", a.Check))
+			}
+			spec.Extra.Children = append(spec.Extra.Children, r)
+		case *Grant:
+			spec.Extra.Children = append(spec.Extra.Children, fromGrant(a))
+		}
+	}
+}
+
+// strListAttr returns an attribute that holds the given list of strings.
+func strListAttr(k string, vs []string) *schemahcl.Attr {
+	quoted := make([]string, len(vs))
+	for i, v := range vs {
+		quoted[i] = strconv.Quote(v)
+	}
+	return specutil.ListAttr(k, quoted...)
+}
+
+// fromGrant returns the resource spec for representing the grant block.
+func fromGrant(g *Grant) *schemahcl.Resource {
+	return &schemahcl.Resource{
+		Type:  "grant",
+		Name:  g.Grantee,
+		Attrs: []*schemahcl.Attr{strListAttr("privileges", g.Privileges)},
+	}
+}
+
 // convertPartition converts and appends the partition block into the table attributes if exists.
 func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 	r, ok := spec.Resource("partition")
 	if !ok {
 		return nil
 	}
+	key, err := partitionKeySpec(r, table, table.Name+".partition")
+	if err != nil {
+		return err
+	}
+	table.AddAttrs(key)
+	return nil
+}
+
+// partitionKeySpec converts the partition block in the given path into a partition key,
+// and its part blocks into the partitions of the table.
+func partitionKeySpec(r *schemahcl.Resource, table *schema.Table, path string) (*Partition, error) {
 	var p struct {
 		Type    string           `spec:"type"`
 		Columns []*schemahcl.Ref `spec:"columns"`
@@ -157,22 +401,22 @@ func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 		} `spec:"by"`
 	}
 	if err := r.As(&p); err != nil {
-		return fmt.Errorf("parsing %s.partition: %w", table.Name, err)
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
 	if p.Type == "" {
-		return fmt.Errorf("missing attribute %s.partition.type", table.Name)
+		return nil, fmt.Errorf("missing attribute %s.type", path)
 	}
 	key := &Partition{T: p.Type}
 	switch n, m := len(p.Columns), len(p.Parts); {
 	case n == 0 && m == 0:
-		return fmt.Errorf("missing columns or expressions for %s.partition", table.Name)
+		return nil, fmt.Errorf("missing columns or expressions for %s", path)
 	case n > 0 && m > 0:
-		return fmt.Errorf(`multiple definitions for %s.partition, use "columns" or "by"`, table.Name)
+		return nil, fmt.Errorf(`multiple definitions for %s, use "columns" or "by"`, path)
 	case n > 0:
 		for _, r := range p.Columns {
 			c, err := specutil.ColumnByRef(table, r)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			key.Parts = append(key.Parts, &PartitionPart{C: c})
 		}
@@ -180,13 +424,13 @@ func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 		for i, p := range p.Parts {
 			switch {
 			case p.Column == nil && p.Expr == "":
-				return fmt.Errorf("missing column or expression for %s.partition.by at position %d", table.Name, i)
+				return nil, fmt.Errorf("missing column or expression for %s.by at position %d", path, i)
 			case p.Column != nil && p.Expr != "":
-				return fmt.Errorf("multiple definitions for  %s.partition.by at position %d", table.Name, i)
+				return nil, fmt.Errorf("multiple definitions for  %s.by at position %d", path, i)
 			case p.Column != nil:
 				c, err := specutil.ColumnByRef(table, p.Column)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				key.Parts = append(key.Parts, &PartitionPart{C: c})
 			case p.Expr != "":
@@ -194,8 +438,29 @@ func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 			}
 		}
 	}
-	table.AddAttrs(key)
-	return nil
+	for _, c := range r.Children {
+		if c.Type != "part" {
+			continue
+		}
+		var ps struct {
+			Bound string `spec:"bound"`
+		}
+		if err := c.As(&ps); err != nil {
+			return nil, fmt.Errorf("parsing %s.part.%s: %w", path, c.Name, err)
+		}
+		if ps.Bound == "" {
+			return nil, fmt.Errorf("missing attribute %s.part.%s.bound", path, c.Name)
+		}
+		part := &TablePartition{Name: c.Name, Bound: ps.Bound}
+		if sub, ok := c.Resource("partition"); ok {
+			var err error
+			if part.Sub, err = partitionKeySpec(sub, table, path+".part."+c.Name+".partition"); err != nil {
+				return nil, err
+			}
+		}
+		key.Partitions = append(key.Partitions, part)
+	}
+	return key, nil
 }
 
 // fromPartition returns the resource spec for representing the partition block.
@@ -218,7 +483,7 @@ func fromPartition(p Partition) *schemahcl.Resource {
 	}()
 	if ok {
 		key.Attrs = append(key.Attrs, &schemahcl.Attr{K: "columns", V: columns})
-		return key
+		return fromPartitions(key, p)
 	}
 	for _, p := range p.Parts {
 		part := &schemahcl.Resource{Type: "by"}
@@ -230,6 +495,22 @@ func fromPartition(p Partition) *schemahcl.Resource {
 		}
 		key.Children = append(key.Children, part)
 	}
+	return fromPartitions(key, p)
+}
+
+// fromPartitions appends the part blocks of the partitions to the partition block.
+func fromPartitions(key *schemahcl.Resource, p Partition) *schemahcl.Resource {
+	for _, tp := range p.Partitions {
+		part := &schemahcl.Resource{
+			Type:  "part",
+			Name:  tp.Name,
+			Attrs: []*schemahcl.Attr{specutil.StrAttr("bound", tp.Bound)},
+		}
+		if tp.Sub != nil {
+			part.Children = append(part.Children, fromPartition(*tp.Sub))
+		}
+		key.Children = append(key.Children, part)
+	}
 	return key
 }
 
@@ -334,6 +615,13 @@ func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
 		}
 		idx.Attrs = append(idx.Attrs, &IndexInclude{Columns: include})
 	}
+	if attr, ok := spec.Attr("nulls_distinct"); ok {
+		v, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		idx.Attrs = append(idx.Attrs, &IndexNullsDistinct{V: v})
+	}
 	return idx, nil
 }
 
@@ -428,6 +716,93 @@ func convertEnums(tables []*sqlspec.Table, enums []*Enum, r *schema.Realm) error
 	return nil
 }
 
+// convertFuncs converts the functions and procedures of the document, and adds them to their
+// schemas. The language of routines defaults to SQL, as it is required by PostgreSQL.
+func convertFuncs(d *doc, r *schema.Realm) error {
+	for _, spec := range d.Funcs {
+		if spec.Lang == "" {
+			spec.Lang = "sql"
+		}
+	}
+	for _, spec := range d.Procs {
+		if spec.Lang == "" {
+			spec.Lang = "sql"
+		}
+	}
+	if err := specutil.Funcs(r, d.Funcs); err != nil {
+		return err
+	}
+	return specutil.Procs(r, d.Procs)
+}
+
+// convertObjects converts the aggregates, operators and operator
+// classes of the document, and adds them to their schemas.
+func convertObjects(d *doc, r *schema.Realm) error {
+	for _, k := range []struct {
+		kind  string
+		specs []*ObjectSpec
+	}{
+		{ObjectAggregate, d.Aggregates},
+		{ObjectOperator, d.Operators},
+		{ObjectOperatorClass, d.OperatorClasses},
+	} {
+		kind := k.kind
+		for _, spec := range k.specs {
+			name, err := specutil.SchemaName(spec.Schema)
+			if err != nil {
+				return fmt.Errorf("%s %q: %w", kind, spec.Name, err)
+			}
+			s, ok := r.Schema(name)
+			if !ok {
+				return fmt.Errorf("schema %q not found in realm for %s %q", name, kind, spec.Name)
+			}
+			o := &Object{Kind: kind, Name: spec.Name, Signature: spec.Args}
+			if kind == ObjectOperatorClass {
+				o.Signature = spec.Using
+			}
+			switch {
+			case o.Signature == "" && kind == ObjectOperatorClass:
+				return fmt.Errorf("missing attribute %s.%s.using", kind, spec.Name)
+			case o.Signature == "":
+				return fmt.Errorf("missing attribute %s.%s.args", kind, spec.Name)
+			case spec.As == "":
+				o.Hash = spec.Hash
+			default:
+				if o.SetDef(spec.As); spec.Hash != "" && spec.Hash != o.Hash {
+					return fmt.Errorf("hash of %s %q does not match its definition", kind, spec.Name)
+				}
+			}
+			s.AddAttrs(o)
+		}
+	}
+	return nil
+}
+
+// objects converts the objects of the schema to their specs.
+func (d *doc) objects(s *schema.Schema) {
+	for _, a := range s.Attrs {
+		o, ok := a.(*Object)
+		if !ok {
+			continue
+		}
+		spec := &ObjectSpec{Name: o.Name, Schema: specutil.SchemaRef(s.Name), As: o.Def}
+		if o.Def == "" {
+			spec.Hash = o.Hash
+		}
+		switch o.Kind {
+		case ObjectAggregate:
+			spec.Args = o.Signature
+			d.Aggregates = append(d.Aggregates, spec)
+		case ObjectOperator:
+			spec.Args = o.Signature
+			d.Operators = append(d.Operators, spec)
+		case ObjectOperatorClass:
+			spec.Using = o.Signature
+			d.OperatorClasses = append(d.OperatorClasses, spec)
+		}
+	}
+}
+
 // enumName extracts the name of the referenced Enum from the reference string.
 func enumName(ref *schemahcl.Type) (string, error) {
 	s := strings.Split(ref.T, "$enum.")
@@ -450,6 +825,11 @@ func schemaSpec(schem *schema.Schema) (*doc, error) {
 	if err != nil {
 		return nil, err
 	}
+	for _, a := range schem.Attrs {
+		if g, ok := a.(*Grant); ok {
+			s.Extra.Children = append(s.Extra.Children, fromGrant(g))
+		}
+	}
 	d := &doc{
 		Tables:  tbls,
 		Schemas: []*sqlspec.Schema{s},
@@ -486,6 +866,7 @@ func tableSpec(table *schema.Table) (*sqlspec.Table, error) {
 	if p := (Partition{}); sqlx.Has(table.Attrs, &p) {
 		spec.Extra.Children = append(spec.Extra.Children, fromPartition(p))
 	}
+	fromAccess(spec, table)
 	return spec, nil
 }
 
@@ -514,6 +895,9 @@ func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 	if p, ok := indexStorageParams(idx.Attrs); ok {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.Int64Attr("page_per_range", p.PagesPerRange))
 	}
+	if !nullsDistinct(idx.Attrs) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("nulls_distinct", false))
+	}
 	return spec, nil
 }
 
@@ -376,6 +376,72 @@ table "logs" {
 		require.Equal(t, expected, s)
 	})
 
+	t.Run("Partitions", func(t *testing.T) {
+		var (
+			s = &schema.Schema{}
+			f = `
+schema "test" {}
+table "logs" {
+	schema = schema.test
+	column "id" {
+		type = int
+	}
+	column "region" {
+		type = text
+	}
+	partition {
+		type = LIST
+		columns = [column.region]
+		part "logs_eu" {
+			bound = "FOR VALUES IN ('eu')"
+			partition {
+				type = HASH
+				columns = [column.id]
+				part "logs_eu_0" {
+					bound = "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"
+				}
+				part "logs_eu_1" {
+					bound = "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"
+				}
+			}
+		}
+		part "logs_default" {
+			bound = "DEFAULT"
+		}
+	}
+}
+`
+		)
+		err := EvalHCLBytes([]byte(f), s, nil)
+		require.NoError(t, err)
+		var (
+			id     = schema.NewIntColumn("id", "int")
+			region = schema.NewStringColumn("region", "text")
+		)
+		expected := schema.New("test").
+			AddTables(schema.NewTable("logs").AddColumns(id, region).AddAttrs(&Partition{
+				T:     PartitionTypeList,
+				Parts: []*PartitionPart{{C: region}},
+				Partitions: []*TablePartition{
+					{
+						Name:  "logs_eu",
+						Bound: "FOR VALUES IN ('eu')",
+						Sub: &Partition{
+							T:     PartitionTypeHash,
+							Parts: []*PartitionPart{{C: id}},
+							Partitions: []*TablePartition{
+								{Name: "logs_eu_0", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"},
+								{Name: "logs_eu_1", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"},
+							},
+						},
+					},
+					{Name: "logs_default", Bound: "DEFAULT"},
+				},
+			}))
+		expected.SetRealm(schema.NewRealm(expected))
+		require.Equal(t, expected, s)
+	})
+
 	t.Run("Invalid", func(t *testing.T) {
 		err := EvalHCLBytes([]byte(`
 			schema "test" {}
@@ -414,6 +480,20 @@ table "logs" {
 			}
 		`), &schema.Schema{}, nil)
 		require.EqualError(t, err, `multiple definitions for logs.partition, use "columns" or "by"`)
+
+		err = EvalHCLBytes([]byte(`
+			schema "test" {}
+			table "logs" {
+				schema = schema.test
+				column "name" { type = text }
+				partition {
+					type = LIST
+					columns = [column.name]
+					part "logs_a" {}
+				}
+			}
+		`), &schema.Schema{}, nil)
+		require.EqualError(t, err, `missing attribute logs.partition.part.logs_a.bound`)
 	})
 }
 
@@ -466,6 +546,207 @@ schema "test" {
 }
 `, string(buf))
 	})
+
+	t.Run("Partitions", func(t *testing.T) {
+		var (
+			id     = schema.NewIntColumn("id", "int")
+			region = schema.NewStringColumn("region", "text")
+		)
+		s := schema.New("test").
+			AddTables(schema.NewTable("logs").AddColumns(id, region).AddAttrs(&Partition{
+				T:     PartitionTypeList,
+				Parts: []*PartitionPart{{C: region}},
+				Partitions: []*TablePartition{
+					{
+						Name:  "logs_eu",
+						Bound: "FOR VALUES IN ('eu')",
+						Sub: &Partition{
+							T:          PartitionTypeHash,
+							Parts:      []*PartitionPart{{C: id}},
+							Partitions: []*TablePartition{{Name: "logs_eu_0", Bound: "FOR VALUES WITH (modulus 1, remainder 0)"}},
+						},
+					},
+					{Name: "logs_default", Bound: "DEFAULT"},
+				},
+			}))
+		buf, err := MarshalHCL(s)
+		require.NoError(t, err)
+		require.Equal(t, `table "logs" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  column "region" {
+    null = false
+    type = text
+  }
+  partition {
+    type    = LIST
+    columns = [column.region]
+    part "logs_eu" {
+      bound = "FOR VALUES IN ('eu')"
+      partition {
+        type    = HASH
+        columns = [column.id]
+        part "logs_eu_0" {
+          bound = "FOR VALUES WITH (modulus 1, remainder 0)"
+        }
+      }
+    }
+    part "logs_default" {
+      bound = "DEFAULT"
+    }
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	})
+}
+
+func TestMarshalSpec_Permissions(t *testing.T) {
+	s := schema.New("public").
+		AddAttrs(&Grant{Grantee: "app", Privileges: []string{"USAGE"}}).
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("tenant", "int")).
+				AddAttrs(
+					&RowSecurity{Enabled: true, Forced: true},
+					&Policy{Name: "isolation", As: PolicyPermissive, Command: "ALL", Using: "tenant = current_setting('app.tenant')::int"},
+					&Policy{Name: "readers", As: PolicyRestrictive, Command: "SELECT", Roles: []string{"app", "reporting"}, Using: "true"},
+					&Grant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}},
+				),
+		)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.public
+  column "tenant" {
+    null = false
+    type = int
+  }
+  row_security {
+    enabled = true
+    forced  = true
+  }
+  policy "isolation" {
+    using = "tenant = current_setting('app.tenant')::int"
+  }
+  policy "readers" {
+    as      = RESTRICTIVE
+    command = SELECT
+    to      = ["app", "reporting"]
+    using   = "true"
+  }
+  grant "PUBLIC" {
+    privileges = ["SELECT"]
+  }
+}
+schema "public" {
+  grant "app" {
+    privileges = ["USAGE"]
+  }
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Schemas, 1)
+	require.Equal(t, []schema.Attr{&Grant{Grantee: "app", Privileges: []string{"USAGE"}}}, got.Schemas[0].Attrs)
+	users, ok := got.Schemas[0].Table("users")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{
+		&RowSecurity{Enabled: true, Forced: true},
+		&Policy{Name: "isolation", Using: "tenant = current_setting('app.tenant')::int"},
+		&Policy{Name: "readers", As: PolicyRestrictive, Command: "SELECT", Roles: []string{"app", "reporting"}, Using: "true"},
+		&Grant{Grantee: "PUBLIC", Privileges: []string{"SELECT"}},
+	}, users.Attrs)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "tenant" {
+    type = int
+  }
+  grant "app" {}
+}
+`), &got, nil)
+	require.EqualError(t, err, "specutil: failed converting to *schema.Realm: missing attribute users.grant.app.privileges")
+}
+
+func TestMarshalSpec_Sequences(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint"))
+	s := schema.New("public").
+		AddTables(users).
+		AddSequences(
+			schema.NewSequence("counter").SetType("integer").SetIncrement(-2).SetMin(-100).SetMax(0).SetStart(-1).SetCycle(true),
+			schema.NewSequence("users_ids").SetCache(10).SetOwner(users, users.Columns[0]),
+		)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = bigint
+  }
+}
+sequence "counter" {
+  schema    = schema.public
+  type      = integer
+  start     = -1
+  increment = -2
+  min_value = -100
+  max_value = 0
+  cycle     = true
+}
+sequence "users_ids" {
+  schema = schema.public
+  owner  = table.users.column.id
+  cache  = 10
+}
+schema "public" {
+}
+`, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Schemas, 1)
+	require.Len(t, got.Schemas[0].Sequences, 2)
+	counter, ok := got.Schemas[0].Sequence("counter")
+	require.True(t, ok)
+	require.Equal(t, "integer", counter.Type)
+	require.EqualValues(t, -1, counter.Start)
+	require.EqualValues(t, -2, counter.Increment)
+	require.EqualValues(t, -100, *counter.Min)
+	require.EqualValues(t, 0, *counter.Max)
+	require.True(t, counter.Cycle)
+	require.Nil(t, counter.Owner)
+	ids, ok := got.Schemas[0].Sequence("users_ids")
+	require.True(t, ok)
+	require.Empty(t, ids.Type)
+	require.EqualValues(t, 10, ids.Cache)
+	require.Nil(t, ids.Min)
+	require.Equal(t, got.Schemas[0].Tables[0], ids.Owner.T)
+	require.Equal(t, got.Schemas[0].Tables[0].Columns[0], ids.Owner.C)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+schema "other" {}
+table "users" {
+  schema = schema.other
+  column "id" {
+    type = int
+  }
+}
+sequence "s" {
+  schema = schema.public
+  owner  = table.users.column.id
+}
+`), &schema.Realm{}, nil)
+	require.EqualError(t, err, `sequence "s": owner: sqlspec: table "users" not found`)
 }
 
 func TestMarshalSpec_IndexPredicate(t *testing.T) {
@@ -696,6 +977,32 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestMarshalSpec_IndexNullsDistinct(t *testing.T) {
+	f := `table "users" {
+  schema = schema.test
+  column "c" {
+    null = true
+    type = integer
+  }
+  index "c_key" {
+    unique         = true
+    columns        = [column.c]
+    nulls_distinct = false
+  }
+}
+schema "test" {
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	idx, ok := s.Tables[0].Index("c_key")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&IndexNullsDistinct{V: false}}, idx.Attrs)
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+}
+
 func TestMarshalSpec_GeneratedColumn(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -1463,3 +1770,233 @@ schema "s2" {
 `,
 		string(got))
 }
+
+func TestMarshalRealm_Triggers(t *testing.T) {
+	r := schema.NewRealm(
+		schema.New("s1").AddTables(
+			schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("id", "int")).
+				AddTriggers(schema.NewTrigger("audit", "AFTER", []string{"INSERT", "UPDATE"}, "EXECUTE FUNCTION audit()").SetForEachRow(false)),
+			schema.NewTable("logs").
+				AddColumns(schema.NewIntColumn("id", "int")).
+				AddTriggers(schema.NewTrigger("logs_check", "BEFORE", []string{"INSERT"}, "EXECUTE FUNCTION check()").SetWhen("NEW.id > 0")),
+		),
+		schema.New("s2").AddTables(
+			schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("id", "int")).
+				AddTriggers(schema.NewTrigger("audit", "AFTER", []string{"DELETE"}, "EXECUTE FUNCTION audit()")),
+		),
+	)
+	got, err := MarshalHCL.MarshalSpec(r)
+	require.NoError(t, err)
+	require.Contains(t, string(got), `trigger "audit" {
+  on     = table.s1.users
+  timing = "AFTER"
+  events = ["INSERT", "UPDATE"]
+  level  = "STATEMENT"
+  as     = "EXECUTE FUNCTION audit()"
+}
+trigger "logs_check" {
+  on     = table.logs
+  timing = "BEFORE"
+  events = ["INSERT"]
+  level  = "ROW"
+  when   = "NEW.id > 0"
+  as     = "EXECUTE FUNCTION check()"
+}
+trigger "audit" {
+  on     = table.s2.users
+  timing = "AFTER"
+  events = ["DELETE"]
+  level  = "ROW"
+  as     = "EXECUTE FUNCTION audit()"
+}
+`)
+	var r1 schema.Realm
+	require.NoError(t, EvalHCLBytes(got, &r1, nil))
+	for i, s := range r.Schemas {
+		for j, t1 := range s.Tables {
+			t2 := r1.Schemas[i].Tables[j]
+			require.Len(t, t2.Triggers, len(t1.Triggers))
+			for k, tr := range t1.Triggers {
+				require.Equal(t, t2, t2.Triggers[k].Table)
+				require.Equal(t, tr.Name, t2.Triggers[k].Name)
+				require.Equal(t, tr.Events, t2.Triggers[k].Events)
+				require.Equal(t, tr.ForEachRow, t2.Triggers[k].ForEachRow)
+				require.Equal(t, tr.When, t2.Triggers[k].When)
+				require.Equal(t, tr.Body, t2.Triggers[k].Body)
+			}
+		}
+	}
+}
+
+func TestMarshalRealm_Funcs(t *testing.T) {
+	r := schema.NewRealm(
+		schema.New("public").
+			AddFuncs(
+				schema.NewFunc("add", "a integer, b integer", "integer", "SELECT a + b").SetLang("sql"),
+				schema.NewFunc("add", "a text, b text", "text", "SELECT a || b").SetLang("sql"),
+			).
+			AddProcs(schema.NewProc("archive", "id integer", "BEGIN NULL; END").SetLang("plpgsql")),
+	)
+	got, err := MarshalHCL.MarshalSpec(r)
+	require.NoError(t, err)
+	require.Contains(t, string(got), `function "add" {
+  schema  = schema.public
+  args    = "a integer, b integer"
+  returns = "integer"
+  lang    = "sql"
+  as      = "SELECT a + b"
+}
+function "add" {
+  schema  = schema.public
+  args    = "a text, b text"
+  returns = "text"
+  lang    = "sql"
+  as      = "SELECT a || b"
+}
+procedure "archive" {
+  schema = schema.public
+  args   = "id integer"
+  lang   = "plpgsql"
+  as     = "BEGIN NULL; END"
+}
+`)
+	var r1 schema.Realm
+	require.NoError(t, EvalHCLBytes(got, &r1, nil))
+	require.Len(t, r1.Schemas, 1)
+	for i, f := range r.Schemas[0].Funcs {
+		f.Schema = r1.Schemas[0]
+		require.Equal(t, f, r1.Schemas[0].Funcs[i])
+	}
+	r.Schemas[0].Procs[0].Schema = r1.Schemas[0]
+	require.Equal(t, r.Schemas[0].Procs, r1.Schemas[0].Procs)
+
+	// The language of routines defaults to SQL.
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "public" {}
+function "one" {
+  schema  = schema.public
+  args    = ""
+  returns = "integer"
+  as      = "SELECT 1"
+}
+`), &s, nil))
+	require.Len(t, s.Funcs, 1)
+	require.Equal(t, "sql", s.Funcs[0].Lang)
+	require.Equal(t, "", s.Funcs[0].Args)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+function "one" {
+  schema = schema.public
+  args   = ""
+  as     = "SELECT 1"
+}
+`), &s, nil)
+	require.EqualError(t, err, "missing attribute function.one.returns")
+}
+
+func TestRealm_Objects(t *testing.T) {
+	f := `aggregate "agg" {
+  schema = schema.public
+  args   = "integer"
+  as     = "SFUNC = int4pl, STYPE = integer"
+}
+operator "===" {
+  schema = schema.public
+  args   = "text, text"
+  hash   = "ef9854dece5c6ba3"
+}
+operator_class "text_ops" {
+  schema = schema.public
+  using  = "gin"
+}
+schema "public" {
+}
+`
+	var r schema.Realm
+	require.NoError(t, EvalHCLBytes([]byte(f), &r, nil))
+	require.Equal(t, []schema.Attr{
+		&Object{Kind: ObjectAggregate, Name: "agg", Signature: "integer", Def: "SFUNC = int4pl, STYPE = integer", Hash: "b1c560cac0649cd3"},
+		&Object{Kind: ObjectOperator, Name: "===", Signature: "text, text", Hash: "ef9854dece5c6ba3"},
+		&Object{Kind: ObjectOperatorClass, Name: "text_ops", Signature: "gin"},
+	}, r.Schemas[0].Attrs)
+	got, err := MarshalHCL.MarshalSpec(&r)
+	require.NoError(t, err)
+	require.Equal(t, f, string(got))
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+operator_class "text_ops" {
+  schema = schema.public
+}
+`), &r, nil)
+	require.EqualError(t, err, "missing attribute operator_class.text_ops.using")
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+aggregate "agg" {
+  schema = schema.public
+  args   = "integer"
+  as     = "SFUNC = int4pl, STYPE = integer"
+  hash   = "ef9854dece5c6ba3"
+}
+`), &r, nil)
+	require.EqualError(t, err, `hash of aggregate "agg" does not match its definition`)
+}
+
+func TestRetentionSpec(t *testing.T) {
+	f := `
+schema "test" {}
+table "events" {
+  schema = schema.test
+  column "created_at" {
+    null = false
+    type = timestamptz
+  }
+  partition {
+    type    = RANGE
+    columns = [column.created_at]
+  }
+  retention {
+    column = column.created_at
+    period = 6
+    unit   = MONTH
+  }
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	tbl, ok := s.Table("events")
+	require.True(t, ok)
+	var r schema.Retention
+	require.True(t, sqlx.Has(tbl.Attrs, &r))
+	require.Equal(t, schema.Retention{C: tbl.Columns[0], Period: 6, Unit: schema.RetentionMonth}, r)
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  retention {
+    column = column.created_at
+    period = 6
+    unit   = MONTH
+  }
+`)
+
+	// The period must be positive, and the unit must be known.
+	for _, r := range []string{"period = 0", "period = 1\n    unit = DECADE"} {
+		err := EvalHCLBytes([]byte(`
+schema "test" {}
+table "events" {
+  schema = schema.test
+  column "created_at" {
+    type = timestamptz
+  }
+  retention {
+    column = column.created_at
+    `+r+`
+  }
+}
+`), &schema.Schema{}, nil)
+		require.Error(t, err, r)
+	}
+}
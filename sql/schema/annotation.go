@@ -0,0 +1,54 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// annotationsPrefix marks the last line of a comment that carries annotations.
+const annotationsPrefix = "atlas:annotations="
+
+// AnnotatedComment returns a comment with the given text that carries the given
+// key-value annotations (e.g. PII classification or retention class) of a table
+// or a column. Annotations are stored in the comment of the element, and therefore
+// round-trip through inspection in databases that support comments.
+//
+//	AnnotatedComment("user emails", map[string]string{"pii": "email"})
+//	// user emails
+//	// atlas:annotations={"pii":"email"}
+func AnnotatedComment(text string, annotations map[string]string) *Comment {
+	if len(annotations) == 0 {
+		return &Comment{Text: text}
+	}
+	// Map keys are sorted by the encoder, and
+	// therefore, the encoding is deterministic.
+	b, err := json.Marshal(annotations)
+	if err != nil {
+		return &Comment{Text: text}
+	}
+	if text != "" {
+		text += "\n"
+	}
+	return &Comment{Text: text + annotationsPrefix + string(b)}
+}
+
+// Split returns the text of the comment and the annotations it carries, if any.
+// See AnnotatedComment for more info.
+func (c *Comment) Split() (string, map[string]string) {
+	text, line := "", c.Text
+	if i := strings.LastIndexByte(c.Text, '\n'); i != -1 {
+		text, line = c.Text[:i], c.Text[i+1:]
+	}
+	if !strings.HasPrefix(line, annotationsPrefix) {
+		return c.Text, nil
+	}
+	var annotations map[string]string
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, annotationsPrefix)), &annotations); err != nil {
+		return c.Text, nil
+	}
+	return text, annotations
+}
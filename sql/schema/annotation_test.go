@@ -0,0 +1,38 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotatedComment(t *testing.T) {
+	c := AnnotatedComment("user emails", map[string]string{"retention": "30d", "pii": "email"})
+	require.Equal(t, "user emails\natlas:annotations={\"pii\":\"email\",\"retention\":\"30d\"}", c.Text)
+	text, a := c.Split()
+	require.Equal(t, "user emails", text)
+	require.Equal(t, map[string]string{"pii": "email", "retention": "30d"}, a)
+
+	c = AnnotatedComment("", map[string]string{"pii": "email"})
+	require.Equal(t, `atlas:annotations={"pii":"email"}`, c.Text)
+	text, a = c.Split()
+	require.Empty(t, text)
+	require.Equal(t, map[string]string{"pii": "email"}, a)
+
+	// Comments without annotations are kept as-is.
+	c = AnnotatedComment("multi\nline", nil)
+	require.Equal(t, "multi\nline", c.Text)
+	text, a = c.Split()
+	require.Equal(t, "multi\nline", text)
+	require.Nil(t, a)
+
+	// Malformed annotations are part of the text.
+	c = &Comment{Text: "text\natlas:annotations={"}
+	text, a = c.Split()
+	require.Equal(t, c.Text, text)
+	require.Nil(t, a)
+}
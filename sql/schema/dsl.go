@@ -5,7 +5,10 @@
 package schema
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"reflect"
+	"strings"
 )
 
 // The functions and methods below provide a DSL for creating schema resources using
@@ -70,6 +73,42 @@ func (s *Schema) AddTables(tables ...*Table) *Schema {
 	return s
 }
 
+// AddViews adds and links the given views to the schema.
+func (s *Schema) AddViews(views ...*View) *Schema {
+	for _, v := range views {
+		v.SetSchema(s)
+	}
+	s.Views = append(s.Views, views...)
+	return s
+}
+
+// AddFuncs adds and links the given functions to the schema.
+func (s *Schema) AddFuncs(funcs ...*Func) *Schema {
+	for _, f := range funcs {
+		f.SetSchema(s)
+	}
+	s.Funcs = append(s.Funcs, funcs...)
+	return s
+}
+
+// AddSequences adds and links the given sequences to the schema.
+func (s *Schema) AddSequences(seqs ...*Sequence) *Schema {
+	for _, sq := range seqs {
+		sq.SetSchema(s)
+	}
+	s.Sequences = append(s.Sequences, seqs...)
+	return s
+}
+
+// AddProcs adds and links the given procedures to the schema.
+func (s *Schema) AddProcs(procs ...*Proc) *Schema {
+	for _, p := range procs {
+		p.SetSchema(s)
+	}
+	s.Procs = append(s.Procs, procs...)
+	return s
+}
+
 // NewRealm creates a new Realm.
 func NewRealm(schemas ...*Schema) *Realm {
 	r := &Realm{Schemas: schemas}
@@ -205,12 +244,167 @@ func (t *Table) AddForeignKeys(fks ...*ForeignKey) *Table {
 	return t
 }
 
+// AddTriggers appends the given triggers to the table trigger list.
+func (t *Table) AddTriggers(triggers ...*Trigger) *Table {
+	for _, tr := range triggers {
+		tr.Table = t
+	}
+	t.Triggers = append(t.Triggers, triggers...)
+	return t
+}
+
 // AddAttrs adds and additional attributes to the table.
 func (t *Table) AddAttrs(attrs ...Attr) *Table {
 	t.Attrs = append(t.Attrs, attrs...)
 	return t
 }
 
+// NewView creates a new View with the given name and definition.
+func NewView(name, def string) *View {
+	return &View{Name: name, Def: def}
+}
+
+// SetSchema sets the schema (named-database) of the view.
+func (v *View) SetSchema(s *Schema) *View {
+	v.Schema = s
+	return v
+}
+
+// AddAttrs adds additional attributes to the view.
+func (v *View) AddAttrs(attrs ...Attr) *View {
+	v.Attrs = append(v.Attrs, attrs...)
+	return v
+}
+
+// NewFunc creates a new Func with the given name, arguments, return type and body.
+func NewFunc(name, args, ret, body string) *Func {
+	return &Func{Name: name, Args: args, Ret: ret, Body: body}
+}
+
+// SetSchema sets the schema (named-database) of the function.
+func (f *Func) SetSchema(s *Schema) *Func {
+	f.Schema = s
+	return f
+}
+
+// SetLang sets the language of the function body.
+func (f *Func) SetLang(lang string) *Func {
+	f.Lang = lang
+	return f
+}
+
+// AddAttrs adds additional attributes to the function.
+func (f *Func) AddAttrs(attrs ...Attr) *Func {
+	f.Attrs = append(f.Attrs, attrs...)
+	return f
+}
+
+// NewSequence creates a new Sequence with the given name.
+func NewSequence(name string) *Sequence {
+	return &Sequence{Name: name}
+}
+
+// SetSchema sets the schema (named-database) of the sequence.
+func (s *Sequence) SetSchema(ns *Schema) *Sequence {
+	s.Schema = ns
+	return s
+}
+
+// SetType sets the data type of the sequence.
+func (s *Sequence) SetType(t string) *Sequence {
+	s.Type = t
+	return s
+}
+
+// SetStart sets the first value of the sequence.
+func (s *Sequence) SetStart(v int64) *Sequence {
+	s.Start = v
+	return s
+}
+
+// SetIncrement sets the increment of the sequence.
+func (s *Sequence) SetIncrement(v int64) *Sequence {
+	s.Increment = v
+	return s
+}
+
+// SetMin sets the minimum value of the sequence.
+func (s *Sequence) SetMin(v int64) *Sequence {
+	s.Min = &v
+	return s
+}
+
+// SetMax sets the maximum value of the sequence.
+func (s *Sequence) SetMax(v int64) *Sequence {
+	s.Max = &v
+	return s
+}
+
+// SetCache sets the number of values that are preallocated by the sequence.
+func (s *Sequence) SetCache(v int64) *Sequence {
+	s.Cache = v
+	return s
+}
+
+// SetCycle sets whether the sequence wraps around when it reaches its bound.
+func (s *Sequence) SetCycle(b bool) *Sequence {
+	s.Cycle = b
+	return s
+}
+
+// SetOwner sets the column that owns the sequence.
+func (s *Sequence) SetOwner(t *Table, c *Column) *Sequence {
+	s.Owner = &SequenceOwner{T: t, C: c}
+	return s
+}
+
+// AddAttrs adds additional attributes to the sequence.
+func (s *Sequence) AddAttrs(attrs ...Attr) *Sequence {
+	s.Attrs = append(s.Attrs, attrs...)
+	return s
+}
+
+// NewProc creates a new Proc with the given name, arguments and body.
+func NewProc(name, args, body string) *Proc {
+	return &Proc{Name: name, Args: args, Body: body}
+}
+
+// SetSchema sets the schema (named-database) of the procedure.
+func (p *Proc) SetSchema(s *Schema) *Proc {
+	p.Schema = s
+	return p
+}
+
+// SetLang sets the language of the procedure body.
+func (p *Proc) SetLang(lang string) *Proc {
+	p.Lang = lang
+	return p
+}
+
+// AddAttrs adds additional attributes to the procedure.
+func (p *Proc) AddAttrs(attrs ...Attr) *Proc {
+	p.Attrs = append(p.Attrs, attrs...)
+	return p
+}
+
+// NewTrigger creates a new Trigger with the given name, timing, events and body.
+// Triggers are created as row-level triggers, use SetForEachRow to change it.
+func NewTrigger(name, actionTime string, events []string, body string) *Trigger {
+	return &Trigger{Name: name, ActionTime: actionTime, Events: events, ForEachRow: true, Body: body}
+}
+
+// SetForEachRow configures the trigger to be a row-level or statement-level trigger.
+func (t *Trigger) SetForEachRow(b bool) *Trigger {
+	t.ForEachRow = b
+	return t
+}
+
+// SetWhen sets the condition for executing the trigger.
+func (t *Trigger) SetWhen(cond string) *Trigger {
+	t.When = cond
+	return t
+}
+
 // NewColumn creates a new column with the given name.
 func NewColumn(name string) *Column {
 	return &Column{Name: name}
@@ -737,6 +931,27 @@ func (f *ForeignKey) SetOnDelete(o ReferenceOption) *ForeignKey {
 	return f
 }
 
+// NewUnmanaged creates a new Unmanaged object from its CREATE statement.
+func NewUnmanaged(typ, name, create string) *Unmanaged {
+	u := &Unmanaged{Type: strings.ToUpper(typ), Name: name}
+	return u.SetCreate(create)
+}
+
+// SetCreate sets the CREATE statement of the object, and its fingerprint.
+// The fingerprint is not affected by whitespace differences.
+func (u *Unmanaged) SetCreate(stmt string) *Unmanaged {
+	u.Create = stmt
+	h := sha256.Sum256([]byte(strings.Join(strings.Fields(stmt), " ")))
+	u.Fingerprint = hex.EncodeToString(h[:])[:16]
+	return u
+}
+
+// SetDrop sets the DROP statement of the object.
+func (u *Unmanaged) SetDrop(stmt string) *Unmanaged {
+	u.Drop = stmt
+	return u
+}
+
 // replaceOrAppend searches an attribute of the same type as v in
 // the list and replaces it. Otherwise, v is appended to the list.
 func replaceOrAppend(attrs *[]Attr, v Attr) {
@@ -0,0 +1,304 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// IdentCase defines how the differ compares the identifiers of database objects.
+type IdentCase uint8
+
+// List of identifier case modes.
+const (
+	// IdentCaseSensitive compares identifiers as-is. It is the default mode.
+	IdentCaseSensitive IdentCase = iota
+
+	// IdentCaseInsensitive compares identifiers case-insensitively. Objects of the
+	// desired state that match an object of the current state are renamed to the
+	// current name, and new objects keep their names. For example, MySQL servers that
+	// run with lower_case_table_names=2, or SQL Server with a case-insensitive collation.
+	IdentCaseInsensitive
+
+	// IdentCaseLower folds the identifiers of the desired state to lower case. For example,
+	// MySQL servers that run with lower_case_table_names=1 store schema and table names in
+	// lower case, and PostgreSQL folds unquoted identifiers to lower case.
+	IdentCaseLower
+)
+
+// String implements the fmt.Stringer interface.
+func (c IdentCase) String() string {
+	switch c {
+	case IdentCaseInsensitive:
+		return "insensitive"
+	case IdentCaseLower:
+		return "lower"
+	default:
+		return "sensitive"
+	}
+}
+
+// ParseIdentCase parses the string representation of an IdentCase.
+func ParseIdentCase(s string) (IdentCase, error) {
+	switch strings.ToLower(s) {
+	case "", "sensitive":
+		return IdentCaseSensitive, nil
+	case "insensitive":
+		return IdentCaseInsensitive, nil
+	case "lower":
+		return IdentCaseLower, nil
+	default:
+		return 0, fmt.Errorf("sql/schema: unknown identifier case %q", s)
+	}
+}
+
+// IdentCaseOptions configures how the differ compares identifiers, by the kind
+// of the objects they name. Drivers provide the options that match the settings
+// of their servers. For example, mysql.IdentCase or sqlserver.IdentCase, or resolve
+// them from the connected database using the IdentCaser interface.
+//
+// The desired state is modified in place before it is diffed. Hence, differences
+// that are only in the case of identifiers are not reported as changes, and are
+// not proposed as renames by the rename detection.
+type IdentCaseOptions struct {
+	Schemas IdentCase // schema names
+	Tables  IdentCase // table and view names
+	Columns IdentCase // column, index, foreign key and check names
+}
+
+// IdentCaser is the interface implemented by the database drivers that resolve
+// the identifier case options from the settings of the connected database.
+type IdentCaser interface {
+	IdentCase(context.Context) (*IdentCaseOptions, error)
+}
+
+// DiffWithIdentCase configures the identifier case handling of the diffing process.
+func DiffWithIdentCase(opts *IdentCaseOptions) DiffOption {
+	return func(o *DiffOptions) {
+		o.IdentCase = opts
+	}
+}
+
+// realm aligns the identifiers of the desired realm with the current one.
+func (o *IdentCaseOptions) realm(from, to *Realm) {
+	for _, s2 := range to.Schemas {
+		s2.Name = identName(o.Schemas, s2.Name, func(f func(string) bool) (string, bool) {
+			for _, s1 := range from.Schemas {
+				if f(s1.Name) {
+					return s1.Name, true
+				}
+			}
+			return "", false
+		})
+		if s1, ok := from.Schema(s2.Name); ok {
+			o.schema(s1, s2)
+		} else {
+			o.schema(&Schema{}, s2)
+		}
+	}
+}
+
+// schema aligns the identifiers of the desired schema with the current one.
+func (o *IdentCaseOptions) schema(from, to *Schema) {
+	// References in view definitions are aligned with
+	// the renamed tables, views and columns.
+	renames := make(map[string]string)
+	for _, t2 := range to.Tables {
+		name := t2.Name
+		t2.Name = identName(o.Tables, t2.Name, func(f func(string) bool) (string, bool) {
+			for _, t1 := range from.Tables {
+				if f(t1.Name) {
+					return t1.Name, true
+				}
+			}
+			return "", false
+		})
+		addRename(renames, name, t2.Name)
+		t1, ok := from.Table(t2.Name)
+		if !ok {
+			t1 = &Table{}
+		}
+		for k, v := range o.table(t1, t2) {
+			addRename(renames, k, v)
+		}
+	}
+	for _, v2 := range to.Views {
+		name := v2.Name
+		v2.Name = identName(o.Tables, v2.Name, func(f func(string) bool) (string, bool) {
+			for _, v1 := range from.Views {
+				if f(v1.Name) {
+					return v1.Name, true
+				}
+			}
+			return "", false
+		})
+		addRename(renames, name, v2.Name)
+	}
+	for _, v2 := range to.Views {
+		v2.Def = renameIdents(v2.Def, renames)
+	}
+}
+
+// table aligns the identifiers of the desired table with the current one,
+// and returns the renamed columns.
+func (o *IdentCaseOptions) table(from, to *Table) map[string]string {
+	renames := make(map[string]string)
+	for _, c2 := range to.Columns {
+		name := c2.Name
+		c2.Name = identName(o.Columns, c2.Name, func(f func(string) bool) (string, bool) {
+			for _, c1 := range from.Columns {
+				if f(c1.Name) {
+					return c1.Name, true
+				}
+			}
+			return "", false
+		})
+		addRename(renames, name, c2.Name)
+	}
+	for _, idx2 := range to.Indexes {
+		idx2.Name = identName(o.Columns, idx2.Name, func(f func(string) bool) (string, bool) {
+			for _, idx1 := range from.Indexes {
+				if f(idx1.Name) {
+					return idx1.Name, true
+				}
+			}
+			return "", false
+		})
+	}
+	for _, fk2 := range to.ForeignKeys {
+		fk2.Symbol = identName(o.Columns, fk2.Symbol, func(f func(string) bool) (string, bool) {
+			for _, fk1 := range from.ForeignKeys {
+				if f(fk1.Symbol) {
+					return fk1.Symbol, true
+				}
+			}
+			return "", false
+		})
+	}
+	attrs := [][]Attr{to.Attrs}
+	for _, c2 := range to.Columns {
+		attrs = append(attrs, c2.Attrs)
+	}
+	for _, as := range attrs {
+		for _, a := range as {
+			ck2, ok := a.(*Check)
+			if !ok {
+				continue
+			}
+			ck2.Expr = renameIdents(ck2.Expr, renames)
+			if ck2.Name == "" {
+				continue
+			}
+			ck2.Name = identName(o.Columns, ck2.Name, func(f func(string) bool) (string, bool) {
+				for _, a := range from.Attrs {
+					if ck1, ok := a.(*Check); ok && f(ck1.Name) {
+						return ck1.Name, true
+					}
+				}
+				for _, c1 := range from.Columns {
+					for _, a := range c1.Attrs {
+						if ck1, ok := a.(*Check); ok && f(ck1.Name) {
+							return ck1.Name, true
+						}
+					}
+				}
+				return "", false
+			})
+		}
+	}
+	return renames
+}
+
+// addRename records that the given identifier was renamed. Identifiers
+// that were renamed to different names (e.g. columns of different tables)
+// are ambiguous, and therefore, are not renamed in expressions.
+func addRename(renames map[string]string, from, to string) {
+	if r, ok := renames[from]; ok && r != to {
+		to = ""
+	}
+	renames[from] = to
+}
+
+// renameIdents replaces the identifiers of the given expression that were renamed.
+// Bare and quoted identifiers are replaced, and string literals are left as-is.
+func renameIdents(expr string, renames map[string]string) string {
+	var (
+		b     strings.Builder
+		write = func(name string) {
+			if r := renames[name]; r != "" {
+				name = r
+			}
+			b.WriteString(name)
+		}
+	)
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == '\'':
+			j := strings.IndexByte(expr[i+1:], c)
+			if j == -1 {
+				b.WriteString(expr[i:])
+				return b.String()
+			}
+			b.WriteString(expr[i : i+j+2])
+			i += j + 2
+		case c == '"' || c == '`' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			j := strings.IndexByte(expr[i+1:], end)
+			if j == -1 {
+				b.WriteString(expr[i:])
+				return b.String()
+			}
+			b.WriteByte(c)
+			write(expr[i+1 : i+1+j])
+			b.WriteByte(end)
+			i += j + 2
+		case isIdentByte(c):
+			j := i
+			for j < len(expr) && isIdentByte(expr[j]) {
+				j++
+			}
+			// Numeric literals are not identifiers.
+			if '0' <= c && c <= '9' {
+				b.WriteString(expr[i:j])
+			} else {
+				write(expr[i:j])
+			}
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// identName returns the name of the desired object according to the given mode.
+// The find function returns the name of the current object that matches the
+// predicate, if there is one.
+func identName(c IdentCase, name string, find func(func(string) bool) (string, bool)) string {
+	switch c {
+	case IdentCaseInsensitive:
+		// Exact matches take precedence over case-insensitive ones.
+		if _, ok := find(func(s string) bool { return s == name }); ok {
+			return name
+		}
+		if s, ok := find(func(s string) bool { return strings.EqualFold(s, name) }); ok {
+			return s
+		}
+	case IdentCaseLower:
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// isIdentByte reports if the given byte can be part of a bare identifier.
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || 'a' <= c|0x20 && c|0x20 <= 'z' || '0' <= c && c <= '9' || c >= 0x80
+}
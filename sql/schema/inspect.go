@@ -43,6 +43,37 @@ const (
 	// InspectTables enables schema tables inspection including
 	// all its child resources (e.g. columns or indexes).
 	InspectTables
+
+	// InspectSettings enables the inspection of the database-level
+	// settings (e.g. the time zone). Unlike the other modes, settings
+	// are not inspected by default, and must be requested explicitly.
+	InspectSettings
+
+	// InspectObjects enables the inspection of driver-specific schema
+	// objects that are not tables, such as PostgreSQL aggregates and
+	// operators. Like settings, they must be requested explicitly.
+	InspectObjects
+
+	// InspectViews enables the inspection of schema views.
+	// Like objects, they must be requested explicitly.
+	InspectViews
+
+	// InspectTriggers enables the inspection of table triggers.
+	// Like views, they must be requested explicitly.
+	InspectTriggers
+
+	// InspectFuncs enables the inspection of schema functions and
+	// stored procedures. Like views, they must be requested explicitly.
+	InspectFuncs
+
+	// InspectPermissions enables the inspection of access control objects,
+	// such as PostgreSQL row-level security policies and privileges granted
+	// on tables and schemas. Like settings, they must be requested explicitly.
+	InspectPermissions
+
+	// InspectSequences enables the inspection of standalone sequences.
+	// Like functions, they must be requested explicitly.
+	InspectSequences
 )
 
 // Is reports whether the given mode is enabled.
@@ -93,6 +124,11 @@ type (
 		//	*.*.* // the last item defines the filtering; all resources are excluded in all tables.
 		//
 		Exclude []string
+
+		// Workers defines the maximum number of schemas that are inspected concurrently,
+		// using separate connections of the pool. If zero or one, schemas are inspected
+		// serially. Drivers that do not support concurrent inspection ignore this option.
+		Workers int
 	}
 
 	// Inspector is the interface implemented by the different database
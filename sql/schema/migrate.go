@@ -7,6 +7,9 @@ package schema
 import (
 	"context"
 	"errors"
+	"fmt"
+	"path"
+	"strings"
 	"time"
 )
 
@@ -43,6 +46,13 @@ type (
 		clause()
 	}
 
+	// ModifyRealm describes a modification change for realm (database-level)
+	// attributes. For example, changing the settings of the database.
+	ModifyRealm struct {
+		R       *Realm
+		Changes []Change
+	}
+
 	// AddSchema describes a schema (named database) creation change.
 	// Unlike table creation, schemas and their elements are described
 	// with separate changes. For example, "AddSchema" and "AddTable"
@@ -86,6 +96,96 @@ type (
 		From, To *Table
 	}
 
+	// AddView describes a view creation change.
+	AddView struct {
+		V     *View
+		Extra []Clause // Extra clauses and options.
+	}
+
+	// DropView describes a view removal change.
+	DropView struct {
+		V     *View
+		Extra []Clause // Extra clauses.
+	}
+
+	// ModifyView describes a view modification change,
+	// such as a change in the definition of the view.
+	ModifyView struct {
+		From, To *View
+	}
+
+	// AddFunc describes a function creation change.
+	AddFunc struct {
+		F     *Func
+		Extra []Clause // Extra clauses and options.
+	}
+
+	// DropFunc describes a function removal change.
+	DropFunc struct {
+		F     *Func
+		Extra []Clause // Extra clauses.
+	}
+
+	// ModifyFunc describes a function modification change,
+	// such as a change in the body or the return type of the function.
+	ModifyFunc struct {
+		From, To *Func
+	}
+
+	// AddProc describes a procedure creation change.
+	AddProc struct {
+		P     *Proc
+		Extra []Clause // Extra clauses and options.
+	}
+
+	// DropProc describes a procedure removal change.
+	DropProc struct {
+		P     *Proc
+		Extra []Clause // Extra clauses.
+	}
+
+	// ModifyProc describes a procedure modification change,
+	// such as a change in the body of the procedure.
+	ModifyProc struct {
+		From, To *Proc
+	}
+
+	// AddSequence describes a sequence creation change.
+	AddSequence struct {
+		S     *Sequence
+		Extra []Clause // Extra clauses and options.
+	}
+
+	// DropSequence describes a sequence removal change.
+	DropSequence struct {
+		S     *Sequence
+		Extra []Clause // Extra clauses.
+	}
+
+	// ModifySequence describes a sequence modification change,
+	// such as a change in the increment or the bounds of the sequence.
+	ModifySequence struct {
+		From, To *Sequence
+	}
+
+	// AddTrigger describes a trigger creation change.
+	AddTrigger struct {
+		T     *Trigger
+		Extra []Clause // Extra clauses and options.
+	}
+
+	// DropTrigger describes a trigger removal change.
+	DropTrigger struct {
+		T     *Trigger
+		Extra []Clause // Extra clauses.
+	}
+
+	// ModifyTrigger describes a trigger modification change.
+	// Triggers are modified by dropping and creating them.
+	ModifyTrigger struct {
+		From, To *Trigger
+	}
+
 	// AddColumn describes a column creation change.
 	AddColumn struct {
 		C *Column
@@ -144,6 +244,11 @@ type (
 		Change   ChangeKind
 	}
 
+	// RenameForeignKey describes a foreign-key rename change.
+	RenameForeignKey struct {
+		From, To *ForeignKey
+	}
+
 	// AddCheck describes a CHECK constraint creation change.
 	AddCheck struct {
 		C *Check
@@ -160,6 +265,11 @@ type (
 		Change   ChangeKind
 	}
 
+	// RenameCheck describes a CHECK constraint rename change.
+	RenameCheck struct {
+		From, To *Check
+	}
+
 	// AddAttr describes an attribute addition.
 	AddAttr struct {
 		A Attr
@@ -263,9 +373,427 @@ type Differ interface {
 	TableDiff(from, to *Table) ([]Change, error)
 }
 
+type (
+	// DiffOptions defines the standard and per-driver configuration
+	// for the schema diffing process.
+	DiffOptions struct {
+		// Hooks are called in order on the changes computed by the
+		// differ, before they are returned to the caller.
+		Hooks []DiffHook
+
+		// Renames enables the rename detection of the differ, if not
+		// nil. Renames are detected before the hooks are called.
+		Renames *RenameOptions
+
+		// IdentCase configures how identifiers are compared, if not nil.
+		// The desired state is aligned before the diff is computed.
+		IdentCase *IdentCaseOptions
+	}
+
+	// DiffOption allows configuring the DiffOptions using functional options.
+	DiffOption func(*DiffOptions)
+
+	// DiffHook allows filtering, transforming or annotating the changes
+	// computed by a Differ before they are passed to the planner. For
+	// example, a hook can drop all column removals from the changes,
+	// or replace them with a soft-deprecation comment.
+	DiffHook func([]Change) ([]Change, error)
+)
+
+// NewDiffOptions creates a new DiffOptions from the given configuration.
+func NewDiffOptions(opts ...DiffOption) *DiffOptions {
+	o := &DiffOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// NewDiffer returns a Differ that wraps d, detects renames (if enabled)
+// and runs the configured hooks on the changes it computes, before they
+// are returned.
+func NewDiffer(d Differ, opts ...DiffOption) Differ {
+	o := NewDiffOptions(opts...)
+	if len(o.Hooks) == 0 && o.Renames == nil && o.IdentCase == nil {
+		return d
+	}
+	return &hookDiffer{Differ: d, opts: o}
+}
+
+// hookDiffer wraps a Differ with diff hooks.
+type hookDiffer struct {
+	Differ
+	opts *DiffOptions
+}
+
+// RealmDiff calls the underlying Differ and runs the hooks on its result.
+func (d *hookDiffer) RealmDiff(from, to *Realm) ([]Change, error) {
+	if d.opts.IdentCase != nil {
+		d.opts.IdentCase.realm(from, to)
+	}
+	changes, err := d.Differ.RealmDiff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if d.opts.Renames != nil {
+		r := &renamer{Differ: d.Differ, opts: d.opts.Renames}
+		if changes, err = r.schemaChanges(changes, func(t *Table) (*Table, bool) {
+			if t.Schema == nil {
+				return nil, false
+			}
+			s, ok := from.Schema(t.Schema.Name)
+			if !ok {
+				return nil, false
+			}
+			return s.Table(t.Name)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return d.opts.RunHooks(changes)
+}
+
+// SchemaDiff calls the underlying Differ and runs the hooks on its result.
+func (d *hookDiffer) SchemaDiff(from, to *Schema) ([]Change, error) {
+	if d.opts.IdentCase != nil {
+		d.opts.IdentCase.realm(&Realm{Schemas: []*Schema{from}}, &Realm{Schemas: []*Schema{to}})
+	}
+	changes, err := d.Differ.SchemaDiff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if d.opts.Renames != nil {
+		r := &renamer{Differ: d.Differ, opts: d.opts.Renames}
+		if changes, err = r.schemaChanges(changes, func(t *Table) (*Table, bool) {
+			return from.Table(t.Name)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return d.opts.RunHooks(changes)
+}
+
+// TableDiff calls the underlying Differ and runs the hooks on its result.
+func (d *hookDiffer) TableDiff(from, to *Table) ([]Change, error) {
+	if d.opts.IdentCase != nil {
+		d.opts.IdentCase.table(from, to)
+	}
+	changes, err := d.Differ.TableDiff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if d.opts.Renames != nil {
+		r := &renamer{Differ: d.Differ, opts: d.opts.Renames}
+		if changes, err = r.tableChanges(from, to, changes); err != nil {
+			return nil, err
+		}
+	}
+	return d.opts.RunHooks(changes)
+}
+
+// DiffWithHooks appends the given hooks to the diffing process.
+func DiffWithHooks(hooks ...DiffHook) DiffOption {
+	return func(o *DiffOptions) {
+		o.Hooks = append(o.Hooks, hooks...)
+	}
+}
+
+// RunHooks runs the configured hooks on the given changes.
+func (o *DiffOptions) RunHooks(changes []Change) ([]Change, error) {
+	for _, h := range o.Hooks {
+		var err error
+		if changes, err = h(changes); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// SkipChanges returns a DiffHook that removes all changes (including nested ones
+// in ModifyRealm, ModifySchema and ModifyTable) that match the given predicate.
+// For example, the following hook ensures columns are never dropped:
+//
+//	schema.SkipChanges(func(c schema.Change) bool {
+//		_, ok := c.(*schema.DropColumn)
+//		return ok
+//	})
+//
+// Modify changes that are left empty after filtering are removed as well.
+func SkipChanges(skip func(Change) bool) DiffHook {
+	return MapChanges(func(c Change) (Change, error) {
+		if skip(c) {
+			return nil, nil
+		}
+		return c, nil
+	})
+}
+
+// MapChanges returns a DiffHook that replaces each change (including nested ones
+// in ModifyRealm, ModifySchema and ModifyTable) with the change returned by f. Returning a nil
+// change removes it from the list. Modify changes are passed to f after their
+// nested changes were mapped.
+func MapChanges(f func(Change) (Change, error)) DiffHook {
+	var mapc func([]Change) ([]Change, error)
+	mapc = func(changes []Change) ([]Change, error) {
+		mapped := make([]Change, 0, len(changes))
+		for _, c := range changes {
+			var (
+				err    error
+				nested *[]Change
+			)
+			switch m := c.(type) {
+			case *ModifyRealm:
+				cp := *m
+				c, nested = &cp, &cp.Changes
+			case *ModifySchema:
+				cp := *m
+				c, nested = &cp, &cp.Changes
+			case *ModifyTable:
+				cp := *m
+				c, nested = &cp, &cp.Changes
+			}
+			if nested != nil {
+				n := len(*nested)
+				if *nested, err = mapc(*nested); err != nil {
+					return nil, err
+				}
+				// All nested changes were removed.
+				if n > 0 && len(*nested) == 0 {
+					continue
+				}
+			}
+			if c, err = f(c); err != nil {
+				return nil, err
+			}
+			if c != nil {
+				mapped = append(mapped, c)
+			}
+		}
+		return mapped, nil
+	}
+	return mapc
+}
+
+// DiffPolicy defines a policy for the changes computed by a Differ. For
+// example, the following policy ensures tables and columns are never
+// dropped, and that the types of existing columns are not changed:
+//
+//	schema.NewDiffer(drv, schema.DiffWithPolicy(schema.DiffPolicy{
+//		SkipDropTable:        true,
+//		SkipDropColumn:       true,
+//		DenyColumnTypeChange: true,
+//	}))
+type DiffPolicy struct {
+	// SkipDropSchema, SkipDropTable, SkipDropColumn, SkipDropIndex and
+	// SkipDropForeignKey remove the respective changes (including nested
+	// ones in ModifySchema and ModifyTable) from the computed changes.
+	SkipDropSchema, SkipDropTable, SkipDropColumn, SkipDropIndex, SkipDropForeignKey bool
+
+	// DenyColumnTypeChange fails the diffing process in
+	// case the type of an existing column is changed.
+	DenyColumnTypeChange bool
+}
+
+// DiffWithPolicy appends a hook that applies the given policy
+// on the computed changes to the diffing process. Note, the hook
+// is appended to the hooks list, and it runs after the hooks
+// that were configured before it.
+func DiffWithPolicy(p DiffPolicy) DiffOption {
+	return DiffWithHooks(p.Hook)
+}
+
+// Hook implements the DiffHook signature.
+func (p DiffPolicy) Hook(changes []Change) ([]Change, error) {
+	if p.DenyColumnTypeChange {
+		if err := denyColumnTypeChange(changes); err != nil {
+			return nil, err
+		}
+	}
+	return SkipChanges(p.skip)(changes)
+}
+
+// skip reports if the given change should be skipped by the policy.
+func (p DiffPolicy) skip(c Change) bool {
+	switch c.(type) {
+	case *DropSchema:
+		return p.SkipDropSchema
+	case *DropTable:
+		return p.SkipDropTable
+	case *DropColumn:
+		return p.SkipDropColumn
+	case *DropIndex:
+		return p.SkipDropIndex
+	case *DropForeignKey:
+		return p.SkipDropForeignKey
+	default:
+		return false
+	}
+}
+
+// denyColumnTypeChange returns an error in case
+// the changes contain a column type change.
+func denyColumnTypeChange(changes []Change) error {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *ModifySchema:
+			if err := denyColumnTypeChange(c.Changes); err != nil {
+				return err
+			}
+		case *ModifyTable:
+			for _, tc := range c.Changes {
+				if m, ok := tc.(*ModifyColumn); ok && m.Change.Is(ChangeType) {
+					return fmt.Errorf("sql/schema: diff policy: changing the type of column %q in table %q is not allowed", m.From.Name, c.T.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// UnmanagedObject describes an object that exists in the current state
+// (e.g. the database), but is absent from the desired state, and would
+// be dropped by the computed changes.
+type UnmanagedObject struct {
+	Type   string // object type, e.g. "table" or "column"
+	Name   string // qualified name, e.g. "public.users.name"
+	Change Change // the change that drops the object
+}
+
+// ProtectUnmanaged returns a DiffHook that protects unmanaged objects from being
+// dropped. That is, the changes dropping schemas, tables, columns, indexes, foreign
+// keys and checks are removed from the computed changes, and the objects they drop
+// are passed to report, unless their qualified names (e.g. "public.audit_log") match
+// one of the allow glob patterns. An error returned by report fails the diffing
+// process. For example, the following hook blocks dropping unknown objects, and
+// ignores the tables that are managed by an external tool:
+//
+//	schema.ProtectUnmanaged([]string{"*.flyway_*"}, func(objs []*schema.UnmanagedObject) error {
+//		if len(objs) > 0 {
+//			return fmt.Errorf("found %d unmanaged objects", len(objs))
+//		}
+//		return nil
+//	})
+func ProtectUnmanaged(allow []string, report func([]*UnmanagedObject) error) DiffHook {
+	return func(changes []Change) ([]Change, error) {
+		for _, p := range allow {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("sql/schema: invalid unmanaged allow pattern %q: %w", p, err)
+			}
+		}
+		var objs []*UnmanagedObject
+		changes = protectUnmanaged(changes, "", allow, &objs)
+		if len(objs) > 0 && report != nil {
+			if err := report(objs); err != nil {
+				return nil, err
+			}
+		}
+		return changes, nil
+	}
+}
+
+// protectUnmanaged removes the drop changes from the given list, and appends
+// the objects they drop to objs, unless they are allowed. The qualifier is
+// the qualified name of the parent object (schema or table), if exists.
+func protectUnmanaged(changes []Change, qualifier string, allow []string, objs *[]*UnmanagedObject) []Change {
+	protected := make([]Change, 0, len(changes))
+	for _, c := range changes {
+		var typ, name string
+		switch c := c.(type) {
+		case *DropSchema:
+			typ, name = "schema", c.S.Name
+		case *DropTable:
+			typ, name = "table", tableName(c.T)
+		case *DropView:
+			typ, name = "view", viewName(c.V)
+		case *DropFunc:
+			typ, name = "function", tableName(&Table{Name: c.F.Name, Schema: c.F.Schema})
+		case *DropProc:
+			typ, name = "procedure", tableName(&Table{Name: c.P.Name, Schema: c.P.Schema})
+		case *DropSequence:
+			typ, name = "sequence", tableName(&Table{Name: c.S.Name, Schema: c.S.Schema})
+		case *DropTrigger:
+			typ, name = "trigger", qualify(tableName(c.T.Table), c.T.Name)
+		case *DropColumn:
+			typ, name = "column", qualify(qualifier, c.C.Name)
+		case *DropIndex:
+			typ, name = "index", qualify(qualifier, c.I.Name)
+		case *DropForeignKey:
+			typ, name = "foreign key", qualify(qualifier, c.F.Symbol)
+		case *DropCheck:
+			typ, name = "check", qualify(qualifier, c.C.Name)
+		case *ModifySchema:
+			n := len(c.Changes)
+			cp := *c
+			if cp.Changes = protectUnmanaged(c.Changes, c.S.Name, allow, objs); n > 0 && len(cp.Changes) == 0 {
+				continue
+			}
+			protected = append(protected, &cp)
+			continue
+		case *ModifyTable:
+			n := len(c.Changes)
+			cp := *c
+			if cp.Changes = protectUnmanaged(c.Changes, tableName(c.T), allow, objs); n > 0 && len(cp.Changes) == 0 {
+				continue
+			}
+			protected = append(protected, &cp)
+			continue
+		default:
+			protected = append(protected, c)
+			continue
+		}
+		if !allowed(allow, name) {
+			*objs = append(*objs, &UnmanagedObject{Type: typ, Name: name, Change: c})
+		}
+	}
+	return protected
+}
+
+// allowed reports if the qualified name, or its
+// unqualified form, matches one of the patterns.
+func allowed(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+		if i := strings.IndexByte(name, '.'); i != -1 {
+			if ok, _ := path.Match(p, name[i+1:]); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tableName returns the qualified name of the table.
+func tableName(t *Table) string {
+	if t.Schema != nil && t.Schema.Name != "" {
+		return t.Schema.Name + "." + t.Name
+	}
+	return t.Name
+}
+
+// viewName returns the qualified name of the view.
+func viewName(v *View) string {
+	if v.Schema != nil && v.Schema.Name != "" {
+		return v.Schema.Name + "." + v.Name
+	}
+	return v.Name
+}
+
+func qualify(qualifier, name string) string {
+	if qualifier == "" {
+		return name
+	}
+	return qualifier + "." + name
+}
+
 // ErrLocked is returned on Lock calls which have failed to obtain the lock.
 var ErrLocked = errors.New("sql/schema: lock is held by other session")
 
+// ErrLockUnsupported is returned on Lock calls when the connection cannot hold
+// an "advisory lock". e.g., when it is pooled by a transaction-pooling proxy.
+var ErrLockUnsupported = errors.New("sql/schema: lock is not supported by the connection")
+
 type (
 	// UnlockFunc is returned by the Locker to explicitly
 	// release the named "advisory lock".
@@ -340,6 +868,24 @@ func (c Changes) IndexDropIndex(name string) int {
 	})
 }
 
+// IndexAddForeignKey returns the index of the first AddForeignKey in the changes with
+// the given name, or -1 if there is no such change in the Changes.
+func (c Changes) IndexAddForeignKey(name string) int {
+	return c.search(func(c Change) bool {
+		a, ok := c.(*AddForeignKey)
+		return ok && a.F.Symbol == name
+	})
+}
+
+// IndexDropForeignKey returns the index of the first DropForeignKey in the changes with
+// the given name, or -1 if there is no such change in the Changes.
+func (c Changes) IndexDropForeignKey(name string) int {
+	return c.search(func(c Change) bool {
+		d, ok := c.(*DropForeignKey)
+		return ok && d.F.Symbol == name
+	})
+}
+
 // RemoveIndex removes elements in the given indexes from the Changes.
 func (c *Changes) RemoveIndex(indexes ...int) {
 	changes := make([]Change, 0, len(*c)-len(indexes))
@@ -355,6 +901,134 @@ Loop:
 	*c = changes
 }
 
+// Independent partitions the changes into groups that do not depend on each other,
+// and can be applied concurrently. Changes are grouped by the schema they modify, and
+// schemas that reference each other (e.g. using foreign keys, or by moving tables) are
+// placed in the same group. The order of the changes within a group is preserved.
+//
+// Changes that must be applied serially are returned separately. The "before" changes
+// should be applied before the groups, and they include the changes that are not bound
+// to a schema, like ModifyRealm, changes that are not known to this package, and the
+// drops of views, functions, procedures and triggers. Since their definitions are not
+// parsed, and they may reference objects in other schemas, the rest of their changes
+// are returned in "after", and should be applied once all groups were applied.
+func (c Changes) Independent() (before Changes, groups []Changes, after Changes) {
+	var (
+		deps   = make([][]string, len(c))
+		parent = make(map[string]string)
+		find   func(string) string
+	)
+	find = func(s string) string {
+		if p, ok := parent[s]; ok && p != s {
+			parent[s] = find(p)
+			return parent[s]
+		}
+		parent[s] = s
+		return s
+	}
+	for i := range c {
+		deps[i] = changeSchemas(c[i])
+		for _, s := range deps[i] {
+			parent[find(s)] = find(deps[i][0])
+		}
+	}
+	index := make(map[string]int)
+	for i := range c {
+		if isObjectChange(c[i]) {
+			switch c[i].(type) {
+			case *DropView, *DropFunc, *DropProc, *DropTrigger:
+				before = append(before, c[i])
+			default:
+				after = append(after, c[i])
+			}
+			continue
+		}
+		if len(deps[i]) == 0 {
+			before = append(before, c[i])
+			continue
+		}
+		root := find(deps[i][0])
+		idx, ok := index[root]
+		if !ok {
+			idx = len(groups)
+			index[root] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], c[i])
+	}
+	return before, groups, after
+}
+
+// isObjectChange reports if the change modifies a view, a function, a procedure or
+// a trigger. The schemas these objects reference are unknown, as their definitions
+// are not parsed.
+func isObjectChange(c Change) bool {
+	switch c.(type) {
+	case *AddView, *DropView, *ModifyView, *AddFunc, *DropFunc, *ModifyFunc,
+		*AddProc, *DropProc, *ModifyProc, *AddTrigger, *DropTrigger, *ModifyTrigger:
+		return true
+	}
+	return false
+}
+
+// changeSchemas returns the names of the schemas the change modifies or references,
+// or nil if the change is not bound to a schema, or it is not known to this package.
+func changeSchemas(c Change) []string {
+	switch c := c.(type) {
+	case *AddSchema:
+		return []string{c.S.Name}
+	case *DropSchema:
+		return []string{c.S.Name}
+	case *ModifySchema:
+		return []string{c.S.Name}
+	case *AddTable:
+		return append([]string{tableSchema(c.T)}, fkSchemas(c.T.ForeignKeys...)...)
+	case *DropTable:
+		return append([]string{tableSchema(c.T)}, fkSchemas(c.T.ForeignKeys...)...)
+	case *RenameTable:
+		return []string{tableSchema(c.From), tableSchema(c.To)}
+	case *AddSequence:
+		return []string{tableSchema(&Table{Schema: c.S.Schema})}
+	case *DropSequence:
+		return []string{tableSchema(&Table{Schema: c.S.Schema})}
+	case *ModifySequence:
+		return []string{tableSchema(&Table{Schema: c.To.Schema})}
+	case *ModifyTable:
+		names := []string{tableSchema(c.T)}
+		for _, tc := range c.Changes {
+			switch tc := tc.(type) {
+			case *AddForeignKey:
+				names = append(names, fkSchemas(tc.F)...)
+			case *DropForeignKey:
+				names = append(names, fkSchemas(tc.F)...)
+			case *ModifyForeignKey:
+				names = append(names, fkSchemas(tc.From, tc.To)...)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// fkSchemas returns the schema names of the tables referenced by the given foreign keys.
+func fkSchemas(fks ...*ForeignKey) []string {
+	names := make([]string, 0, len(fks))
+	for _, fk := range fks {
+		if fk != nil && fk.RefTable != nil {
+			names = append(names, tableSchema(fk.RefTable))
+		}
+	}
+	return names
+}
+
+// tableSchema returns the schema name of the table, if it is attached to one.
+func tableSchema(t *Table) string {
+	if t.Schema != nil {
+		return t.Schema.Name
+	}
+	return ""
+}
+
 // search returns the index of the first call to f that returns true, or -1.
 func (c Changes) search(f func(Change) bool) int {
 	for i := range c {
@@ -369,6 +1043,7 @@ func (c Changes) search(f func(Change) bool) int {
 func (*AddAttr) change()          {}
 func (*DropAttr) change()         {}
 func (*ModifyAttr) change()       {}
+func (*ModifyRealm) change()      {}
 func (*AddSchema) change()        {}
 func (*DropSchema) change()       {}
 func (*ModifySchema) change()     {}
@@ -376,6 +1051,21 @@ func (*AddTable) change()         {}
 func (*DropTable) change()        {}
 func (*ModifyTable) change()      {}
 func (*RenameTable) change()      {}
+func (*AddView) change()          {}
+func (*DropView) change()         {}
+func (*ModifyView) change()       {}
+func (*AddFunc) change()          {}
+func (*DropFunc) change()         {}
+func (*ModifyFunc) change()       {}
+func (*AddProc) change()          {}
+func (*DropProc) change()         {}
+func (*ModifyProc) change()       {}
+func (*AddSequence) change()      {}
+func (*DropSequence) change()     {}
+func (*ModifySequence) change()   {}
+func (*AddTrigger) change()       {}
+func (*DropTrigger) change()      {}
+func (*ModifyTrigger) change()    {}
 func (*AddIndex) change()         {}
 func (*DropIndex) change()        {}
 func (*ModifyIndex) change()      {}
@@ -383,6 +1073,7 @@ func (*RenameIndex) change()      {}
 func (*AddCheck) change()         {}
 func (*DropCheck) change()        {}
 func (*ModifyCheck) change()      {}
+func (*RenameCheck) change()      {}
 func (*AddColumn) change()        {}
 func (*DropColumn) change()       {}
 func (*ModifyColumn) change()     {}
@@ -390,6 +1081,7 @@ func (*RenameColumn) change()     {}
 func (*AddForeignKey) change()    {}
 func (*DropForeignKey) change()   {}
 func (*ModifyForeignKey) change() {}
+func (*RenameForeignKey) change() {}
 
 // clauses.
 func (*IfExists) clause()    {}
@@ -7,6 +7,7 @@ package schema_test
 import (
 	"fmt"
 	"log"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -76,6 +77,18 @@ func TestChanges_IndexDropIndex(t *testing.T) {
 	require.Equal(t, -1, changes.IndexDropIndex("age"))
 }
 
+func TestChanges_IndexForeignKey(t *testing.T) {
+	changes := schema.Changes{
+		&schema.AddForeignKey{F: schema.NewForeignKey("owner")},
+		&schema.DropForeignKey{F: schema.NewForeignKey("author")},
+		&schema.DropForeignKey{F: schema.NewForeignKey("owner")},
+	}
+	require.Equal(t, 0, changes.IndexAddForeignKey("owner"))
+	require.Equal(t, -1, changes.IndexAddForeignKey("author"))
+	require.Equal(t, 2, changes.IndexDropForeignKey("owner"))
+	require.Equal(t, -1, changes.IndexDropForeignKey("editor"))
+}
+
 func TestChanges_RemoveIndex(t *testing.T) {
 	changes := make(schema.Changes, 0, 5)
 	for i := 0; i < 5; i++ {
@@ -91,6 +104,69 @@ func TestChanges_RemoveIndex(t *testing.T) {
 	require.Equal(t, "2", changes[0].(*schema.AddColumn).C.Name)
 }
 
+func TestChanges_Independent(t *testing.T) {
+	var (
+		s1, s2, s3, s4 = schema.New("s1"), schema.New("s2"), schema.New("s3"), schema.New("s4")
+		t1             = schema.NewTable("t1").SetSchema(s1)
+		t2             = schema.NewTable("t2").SetSchema(s2)
+		t3             = schema.NewTable("t3").SetSchema(s3)
+		t4             = schema.NewTable("t4").SetSchema(s4)
+		fk             = schema.NewForeignKey("fk").SetTable(t3).SetRefTable(t1)
+	)
+	changes := schema.Changes{
+		&schema.ModifyRealm{R: schema.NewRealm()},
+		&schema.AddTable{T: t1},
+		&schema.AddTable{T: t2},
+		&schema.ModifyTable{T: t3, Changes: []schema.Change{&schema.AddForeignKey{F: fk}}},
+		&schema.AddSchema{S: s4},
+		&schema.AddTable{T: t4},
+		&schema.AddColumn{C: schema.NewColumn("c")},
+	}
+	before, groups, after := changes.Independent()
+	require.Equal(t, schema.Changes{changes[0], changes[6]}, before)
+	require.Empty(t, after)
+	require.Equal(t, []schema.Changes{
+		{changes[1], changes[3]},
+		{changes[2]},
+		{changes[4], changes[5]},
+	}, groups)
+
+	// Renaming a table across schemas makes them dependent.
+	changes = append(changes, &schema.RenameTable{From: t2, To: schema.NewTable("t2").SetSchema(s4)})
+	before, groups, _ = changes.Independent()
+	require.Len(t, before, 2)
+	require.Equal(t, []schema.Changes{
+		{changes[1], changes[3]},
+		{changes[2], changes[4], changes[5], changes[7]},
+	}, groups)
+
+	// Views, functions, procedures and triggers may reference any schema, and
+	// are applied serially. Drops come first, and the rest come last.
+	var (
+		v  = schema.NewView("v", "SELECT * FROM s2.t2").SetSchema(s1)
+		f  = &schema.Func{Name: "f", Schema: s3}
+		tr = &schema.Trigger{Name: "tr", Table: t4}
+	)
+	changes = schema.Changes{
+		&schema.AddTable{T: t1},
+		&schema.DropView{V: v},
+		&schema.AddTable{T: t2},
+		&schema.AddView{V: v},
+		&schema.ModifyFunc{From: f, To: f},
+		&schema.DropTrigger{T: tr},
+		&schema.AddTrigger{T: tr},
+	}
+	before, groups, after = changes.Independent()
+	require.Equal(t, schema.Changes{changes[1], changes[5]}, before)
+	require.Equal(t, []schema.Changes{{changes[0]}, {changes[2]}}, groups)
+	require.Equal(t, schema.Changes{changes[3], changes[4], changes[6]}, after)
+
+	before, groups, after = schema.Changes{}.Independent()
+	require.Empty(t, before)
+	require.Empty(t, groups)
+	require.Empty(t, after)
+}
+
 func ExampleChanges_Replace() {
 	changes := schema.Changes{
 		&schema.AddIndex{I: schema.NewIndex("id")},
@@ -124,3 +200,439 @@ func ExampleChanges_Replace() {
 	// *schema.AddColumn(created_at)
 	// *schema.RenameColumn(old_name -> new_name)
 }
+
+func TestDiffOptions_RunHooks(t *testing.T) {
+	var (
+		users = schema.NewTable("users")
+		posts = schema.NewTable("posts")
+		opts  = schema.NewDiffOptions(
+			schema.DiffWithHooks(
+				schema.SkipChanges(func(c schema.Change) bool {
+					_, ok := c.(*schema.DropColumn)
+					return ok
+				}),
+				schema.MapChanges(func(c schema.Change) (schema.Change, error) {
+					if d, ok := c.(*schema.DropTable); ok {
+						return &schema.ModifyTable{T: d.T, Changes: []schema.Change{&schema.AddAttr{A: &schema.Comment{Text: "deprecated"}}}}, nil
+					}
+					return c, nil
+				}),
+			),
+		)
+	)
+	changes, err := opts.RunHooks([]schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropColumn{C: schema.NewColumn("id")}}},
+		&schema.ModifyTable{T: posts, Changes: []schema.Change{&schema.DropColumn{C: schema.NewColumn("id")}, &schema.AddColumn{C: schema.NewColumn("name")}}},
+		&schema.DropTable{T: users},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyTable{T: posts, Changes: []schema.Change{&schema.AddColumn{C: schema.NewColumn("name")}}},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddAttr{A: &schema.Comment{Text: "deprecated"}}}},
+	}, changes)
+
+	opts = schema.NewDiffOptions(schema.DiffWithHooks(func([]schema.Change) ([]schema.Change, error) {
+		return nil, fmt.Errorf("vetoed")
+	}))
+	_, err = opts.RunHooks([]schema.Change{&schema.DropTable{T: users}})
+	require.EqualError(t, err, "vetoed")
+}
+
+type mockDiffer struct {
+	schema.Differ
+	changes []schema.Change
+}
+
+func (d mockDiffer) TableDiff(_, _ *schema.Table) ([]schema.Change, error) {
+	return d.changes, nil
+}
+
+func TestNewDiffer(t *testing.T) {
+	d := mockDiffer{
+		changes: []schema.Change{
+			&schema.DropColumn{C: schema.NewColumn("id")},
+			&schema.AddColumn{C: schema.NewColumn("name")},
+		},
+	}
+	require.Equal(t, d, schema.NewDiffer(d), "no hooks")
+	changes, err := schema.NewDiffer(d, schema.DiffWithHooks(schema.SkipChanges(func(c schema.Change) bool {
+		_, ok := c.(*schema.DropColumn)
+		return ok
+	}))).TableDiff(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.AddColumn{C: schema.NewColumn("name")}}, changes)
+}
+
+func TestDiffPolicy(t *testing.T) {
+	users := schema.NewTable("users")
+	d := mockDiffer{
+		changes: []schema.Change{
+			&schema.DropTable{T: schema.NewTable("posts")},
+			&schema.ModifyTable{
+				T: users,
+				Changes: []schema.Change{
+					&schema.DropColumn{C: schema.NewColumn("id")},
+					&schema.DropIndex{I: schema.NewIndex("idx")},
+				},
+			},
+			&schema.ModifyTable{
+				T: schema.NewTable("pets"),
+				Changes: []schema.Change{
+					&schema.DropColumn{C: schema.NewColumn("id")},
+				},
+			},
+		},
+	}
+	changes, err := schema.NewDiffer(d, schema.DiffWithPolicy(schema.DiffPolicy{SkipDropTable: true, SkipDropColumn: true})).TableDiff(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyTable{
+			T:       users,
+			Changes: []schema.Change{&schema.DropIndex{I: schema.NewIndex("idx")}},
+		},
+	}, changes)
+
+	d.changes = append(d.changes, &schema.ModifyTable{
+		T: users,
+		Changes: []schema.Change{
+			&schema.ModifyColumn{From: schema.NewIntColumn("age", "int"), To: schema.NewStringColumn("age", "text"), Change: schema.ChangeType},
+		},
+	})
+	changes, err = schema.NewDiffer(d, schema.DiffWithPolicy(schema.DiffPolicy{SkipDropTable: true})).TableDiff(nil, nil)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	_, err = schema.NewDiffer(d, schema.DiffWithPolicy(schema.DiffPolicy{DenyColumnTypeChange: true})).TableDiff(nil, nil)
+	require.EqualError(t, err, `sql/schema: diff policy: changing the type of column "age" in table "users" is not allowed`)
+}
+
+func TestProtectUnmanaged(t *testing.T) {
+	var (
+		public = schema.New("public")
+		users  = schema.NewTable("users").SetSchema(public)
+		d      = mockDiffer{
+			changes: []schema.Change{
+				&schema.DropTable{T: schema.NewTable("flyway_history").SetSchema(public)},
+				&schema.DropTable{T: schema.NewTable("posts").SetSchema(public)},
+				&schema.AddTable{T: schema.NewTable("pets").SetSchema(public)},
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.DropColumn{C: schema.NewColumn("legacy")},
+						&schema.DropIndex{I: schema.NewIndex("idx")},
+						&schema.AddColumn{C: schema.NewColumn("name")},
+					},
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("pets").SetSchema(public),
+					Changes: []schema.Change{
+						&schema.DropCheck{C: &schema.Check{Name: "ck"}},
+					},
+				},
+			},
+		}
+		reported []*schema.UnmanagedObject
+	)
+	report := func(objs []*schema.UnmanagedObject) error {
+		reported = objs
+		return nil
+	}
+	changes, err := schema.NewDiffer(d, schema.DiffWithHooks(schema.ProtectUnmanaged([]string{"flyway_*", "public.users.idx"}, report))).TableDiff(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.AddTable{T: schema.NewTable("pets").SetSchema(public)},
+		&schema.ModifyTable{
+			T:       users,
+			Changes: []schema.Change{&schema.AddColumn{C: schema.NewColumn("name")}},
+		},
+	}, changes)
+	require.Len(t, reported, 3)
+	for i, n := range []string{"public.posts", "public.users.legacy", "public.pets.ck"} {
+		require.Equal(t, n, reported[i].Name)
+	}
+	require.Equal(t, "table", reported[0].Type)
+	require.Equal(t, "column", reported[1].Type)
+	require.Equal(t, "check", reported[2].Type)
+
+	_, err = schema.NewDiffer(d, schema.DiffWithHooks(schema.ProtectUnmanaged(nil, func(objs []*schema.UnmanagedObject) error {
+		return fmt.Errorf("found %d unmanaged objects", len(objs))
+	}))).TableDiff(nil, nil)
+	require.EqualError(t, err, "found 5 unmanaged objects")
+
+	_, err = schema.NewDiffer(d, schema.DiffWithHooks(schema.ProtectUnmanaged([]string{"["}, nil))).TableDiff(nil, nil)
+	require.EqualError(t, err, `sql/schema: invalid unmanaged allow pattern "[": syntax error in pattern`)
+}
+
+func TestScope_Check(t *testing.T) {
+	var (
+		public  = schema.New("public")
+		billing = schema.New("billing")
+		scope   = &schema.Scope{Name: "billing", Schemas: []string{"billing"}, Tables: []string{"billing_*", "public.invoices"}}
+	)
+	require.NoError(t, scope.Check([]schema.Change{
+		&schema.AddTable{T: schema.NewTable("billing_accounts").SetSchema(public)},
+		&schema.ModifyTable{T: schema.NewTable("invoices").SetSchema(public)},
+		&schema.AddSchema{S: billing},
+		&schema.DropTable{T: schema.NewTable("users").SetSchema(billing)},
+		&schema.ModifySchema{S: public, Changes: []schema.Change{
+			&schema.DropTable{T: schema.NewTable("billing_old").SetSchema(public)},
+		}},
+	}))
+	for _, tt := range []struct {
+		change schema.Change
+		err    string
+	}{
+		{
+			change: &schema.DropTable{T: schema.NewTable("users").SetSchema(public)},
+			err:    `sql/schema: table "public.users" is outside of the "billing" ownership scope`,
+		},
+		{
+			change: &schema.RenameTable{From: schema.NewTable("billing_users").SetSchema(public), To: schema.NewTable("users").SetSchema(public)},
+			err:    `sql/schema: table "public.users" is outside of the "billing" ownership scope`,
+		},
+		{
+			change: &schema.ModifySchema{S: public, Changes: []schema.Change{&schema.ModifyAttr{}}},
+			err:    `sql/schema: schema "public" is outside of the "billing" ownership scope`,
+		},
+		{
+			change: &schema.DropSchema{S: public},
+			err:    `sql/schema: schema "public" is outside of the "billing" ownership scope`,
+		},
+	} {
+		err := scope.Check([]schema.Change{tt.change})
+		require.EqualError(t, err, tt.err)
+		var serr *schema.ScopeError
+		require.ErrorAs(t, err, &serr)
+		require.Equal(t, tt.change, serr.Change)
+	}
+	_, err := schema.RestrictToScope(&schema.Scope{Name: "invalid", Tables: []string{"["}})(nil)
+	require.EqualError(t, err, `sql/schema: invalid pattern "[" in scope "invalid": syntax error in pattern`)
+}
+
+// renameDiffer is a naive differ that compares columns by their types.
+type renameDiffer struct {
+	schema.Differ
+}
+
+func (d renameDiffer) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
+	var changes []schema.Change
+	for _, t1 := range from.Tables {
+		t2, ok := to.Table(t1.Name)
+		if !ok {
+			changes = append(changes, &schema.DropTable{T: t1})
+			continue
+		}
+		if tc, _ := d.TableDiff(t1, t2); len(tc) > 0 {
+			changes = append(changes, &schema.ModifyTable{T: t2, Changes: tc})
+		}
+	}
+	for _, t2 := range to.Tables {
+		if _, ok := from.Table(t2.Name); !ok {
+			changes = append(changes, &schema.AddTable{T: t2})
+		}
+	}
+	return changes, nil
+}
+
+func (d renameDiffer) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
+	var changes []schema.Change
+	for _, c1 := range from.Columns {
+		c2, ok := to.Column(c1.Name)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropColumn{C: c1})
+		case !reflect.DeepEqual(c1.Type, c2.Type):
+			changes = append(changes, &schema.ModifyColumn{From: c1, To: c2, Change: schema.ChangeType})
+		}
+	}
+	for _, c2 := range to.Columns {
+		if _, ok := from.Column(c2.Name); !ok {
+			changes = append(changes, &schema.AddColumn{C: c2})
+		}
+	}
+	return changes, nil
+}
+
+func TestDiffWithRenames(t *testing.T) {
+	var (
+		from = schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewStringColumn("user_name", "varchar"),
+				schema.NewStringColumn("email", "varchar"),
+			),
+			schema.NewTable("posts").AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewStringColumn("title", "varchar"),
+			),
+		)
+		to = schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewStringColumn("username", "varchar"),
+				schema.NewIntColumn("mail", "int"),
+			),
+			schema.NewTable("articles").AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewStringColumn("title", "varchar"),
+			),
+		)
+		fromU, _ = from.Table("users")
+		toU, _   = to.Table("users")
+		fromP, _ = from.Table("posts")
+		toA, _   = to.Table("articles")
+	)
+	changes, err := schema.NewDiffer(renameDiffer{}).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3, "renames are disabled by default")
+
+	changes, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithRenames(nil)).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyTable{
+			T: toU,
+			Changes: []schema.Change{
+				&schema.RenameColumn{From: fromU.Columns[1], To: toU.Columns[1]},
+				// Columns with different types are not renamed.
+				&schema.DropColumn{C: fromU.Columns[2]},
+				&schema.AddColumn{C: toU.Columns[2]},
+			},
+		},
+		&schema.RenameTable{From: fromP, To: toA},
+	}, changes)
+
+	// Rejected proposals are kept as is.
+	var proposed []schema.Change
+	changes, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithRenames(&schema.RenameOptions{
+		Confirm: func(c schema.Change) (bool, error) {
+			proposed = append(proposed, c)
+			_, ok := c.(*schema.RenameTable)
+			return ok, nil
+		},
+	})).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, proposed, 2)
+	require.Equal(t, &schema.RenameTable{From: fromP, To: toA}, changes[1])
+	require.Len(t, changes[0].(*schema.ModifyTable).Changes, 4)
+
+	// High thresholds disable the heuristics.
+	changes, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithRenames(&schema.RenameOptions{Threshold: 1})).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	// Explicit renames.
+	toU.Columns[2].SetType(&schema.StringType{T: "varchar"}).AddAttrs(&schema.PrevName{Name: "email"})
+	toA.AddColumns(schema.NewStringColumn("body", "text"))
+	toA.AddAttrs(&schema.PrevName{Name: "posts"})
+	changes, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithRenames(&schema.RenameOptions{
+		Threshold: 1,
+		Confirm: func(schema.Change) (bool, error) {
+			return false, nil
+		},
+	})).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, []schema.Change{
+		&schema.DropColumn{C: fromU.Columns[1]},
+		&schema.RenameColumn{From: fromU.Columns[2], To: toU.Columns[2]},
+		&schema.AddColumn{C: toU.Columns[1]},
+	}, changes[0].(*schema.ModifyTable).Changes)
+	require.Equal(t, &schema.RenameTable{From: fromP, To: toA}, changes[1])
+	require.Equal(t, &schema.ModifyTable{
+		T:       toA,
+		Changes: []schema.Change{&schema.AddColumn{C: toA.Columns[2]}},
+	}, changes[2])
+
+	// Heuristics are disabled.
+	changes, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithRenames(&schema.RenameOptions{ExplicitOnly: true})).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.IsType(t, &schema.RenameColumn{}, changes[0].(*schema.ModifyTable).Changes[1])
+	require.IsType(t, &schema.RenameTable{}, changes[1])
+
+	// Explicit renames must keep the column definition.
+	toU.Columns[2].SetType(&schema.IntegerType{T: "int"})
+	_, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithRenames(nil)).SchemaDiff(from, to)
+	require.EqualError(t, err, `sql/schema: column "mail" cannot be renamed from "email" as its definition was changed`)
+}
+
+func TestDiffWithIdentCase(t *testing.T) {
+	state := func() (*schema.Schema, *schema.Schema) {
+		from := schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "varchar")),
+		)
+		to := schema.New("public").AddTables(
+			schema.NewTable("Users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("Name", "varchar")),
+			schema.NewTable("Posts").AddColumns(schema.NewIntColumn("ID", "int")),
+		)
+		return from, to
+	}
+	// Case-sensitive identifiers are proposed as renames.
+	from, to := state()
+	changes, err := schema.NewDiffer(renameDiffer{}, schema.DiffWithRenames(nil)).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.IsType(t, &schema.RenameTable{}, changes[0], "phantom rename")
+	require.IsType(t, &schema.RenameColumn{}, changes[1].(*schema.ModifyTable).Changes[0], "phantom rename")
+
+	// Matched objects keep their current names, and new objects keep their names.
+	from, to = state()
+	changes, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithRenames(nil), schema.DiffWithIdentCase(&schema.IdentCaseOptions{
+		Tables:  schema.IdentCaseInsensitive,
+		Columns: schema.IdentCaseInsensitive,
+	})).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "Posts", changes[0].(*schema.AddTable).T.Name)
+	require.Equal(t, "ID", changes[0].(*schema.AddTable).T.Columns[0].Name)
+	require.Equal(t, "users", to.Tables[0].Name)
+	require.Equal(t, "name", to.Tables[0].Columns[1].Name)
+
+	// Identifiers of the desired state are folded to lower case.
+	from, to = state()
+	changes, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithRenames(nil), schema.DiffWithIdentCase(&schema.IdentCaseOptions{
+		Tables:  schema.IdentCaseLower,
+		Columns: schema.IdentCaseLower,
+	})).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "posts", changes[0].(*schema.AddTable).T.Name)
+	require.Equal(t, "id", changes[0].(*schema.AddTable).T.Columns[0].Name)
+
+	// Exact matches take precedence.
+	from = schema.New("public").AddTables(schema.NewTable("t").AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("A", "int")))
+	to = schema.New("public").AddTables(schema.NewTable("t").AddColumns(schema.NewIntColumn("A", "int")))
+	changes, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithIdentCase(&schema.IdentCaseOptions{Columns: schema.IdentCaseInsensitive})).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "a", changes[0].(*schema.ModifyTable).Changes[0].(*schema.DropColumn).C.Name)
+
+	// Check constraints and references in checks and views are aligned.
+	from = schema.New("public").AddTables(
+		schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("age", "int")).
+			AddChecks(schema.NewCheck().SetName("users_age_check").SetExpr("age > 0")),
+	)
+	from.AddViews(schema.NewView("adults", "SELECT age FROM users WHERE age >= 18"))
+	to = schema.New("public").AddTables(
+		schema.NewTable("Users").
+			AddColumns(schema.NewIntColumn("Age", "int")).
+			AddChecks(schema.NewCheck().SetName("Users_Age_Check").SetExpr(`"Age" > 0 AND 'Age' <> ''`)),
+	)
+	to.AddViews(schema.NewView("Adults", "SELECT Age FROM `Users` WHERE Age >= 18"))
+	_, err = schema.NewDiffer(renameDiffer{}, schema.DiffWithIdentCase(&schema.IdentCaseOptions{
+		Tables:  schema.IdentCaseInsensitive,
+		Columns: schema.IdentCaseInsensitive,
+	})).SchemaDiff(from, to)
+	require.NoError(t, err)
+	ck := to.Tables[0].Attrs[0].(*schema.Check)
+	require.Equal(t, "users_age_check", ck.Name)
+	require.Equal(t, `"age" > 0 AND 'Age' <> ''`, ck.Expr)
+	require.Equal(t, "adults", to.Views[0].Name)
+	require.Equal(t, "SELECT age FROM `users` WHERE age >= 18", to.Views[0].Def)
+
+	c, err := schema.ParseIdentCase("Insensitive")
+	require.NoError(t, err)
+	require.Equal(t, schema.IdentCaseInsensitive, c)
+	require.Equal(t, "insensitive", c.String())
+	_, err = schema.ParseIdentCase("upper")
+	require.EqualError(t, err, `sql/schema: unknown identifier case "upper"`)
+}
@@ -0,0 +1,223 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Object kinds that can be named by a Namer.
+const (
+	NameIndex      = "index"
+	NameUnique     = "unique"
+	NameForeignKey = "foreign_key"
+	NameCheck      = "check"
+)
+
+type (
+	// A Namer names the indexes, foreign keys and checks that were not given an explicit
+	// name, instead of leaving it to the database. Unlike names that are generated by the
+	// database, the names are derived only from the object definition, and therefore are
+	// identical in all environments, regardless of when (or in what order) they were created.
+	Namer struct {
+		templates map[string]*template.Template
+		maxLen    int
+	}
+
+	// NamerOption allows configuring a Namer using functional arguments.
+	NamerOption func(*Namer) error
+
+	// NameData is the data passed to the naming templates.
+	NameData struct {
+		Kind       string   // object kind, e.g. "index"
+		Table      string   // table name
+		Columns    []string // columns (or expressions) of the object
+		RefTable   string   // referenced table, for foreign keys
+		RefColumns []string // referenced columns, for foreign keys
+		Expr       string   // expression, for checks
+		Hash       string   // short hash of the fields above
+	}
+)
+
+// DefaultNameTemplates holds the default naming templates. The names are composed of a
+// prefix, the table name and a hash of the object definition. For example, "idx_users_3b4c1a2f".
+var DefaultNameTemplates = map[string]string{
+	NameIndex:      `idx_{{ .Table }}_{{ .Hash }}`,
+	NameUnique:     `uq_{{ .Table }}_{{ .Hash }}`,
+	NameForeignKey: `fk_{{ .Table }}_{{ .Hash }}`,
+	NameCheck:      `ck_{{ .Table }}_{{ .Hash }}`,
+}
+
+// NewNamer creates a new Namer that uses the DefaultNameTemplates,
+// unless they are overridden using the WithNameTemplate option.
+func NewNamer(opts ...NamerOption) (*Namer, error) {
+	n := &Namer{templates: make(map[string]*template.Template)}
+	for k, v := range DefaultNameTemplates {
+		if err := WithNameTemplate(k, v)(n); err != nil {
+			return nil, err
+		}
+	}
+	for _, opt := range opts {
+		if err := opt(n); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// WithNameTemplate sets the template used for naming objects of the given kind.
+// The template is executed with NameData, and the "join" function is available.
+// For example:
+//
+//	WithNameTemplate(NameIndex, `{{ .Table }}_{{ join .Columns "_" }}_idx`)
+func WithNameTemplate(kind, text string) NamerOption {
+	return func(n *Namer) error {
+		if _, ok := DefaultNameTemplates[kind]; !ok {
+			return fmt.Errorf("sql/schema: unknown naming kind %q", kind)
+		}
+		t, err := template.New(kind).Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+		if err != nil {
+			return fmt.Errorf("sql/schema: parsing %s naming template: %w", kind, err)
+		}
+		n.templates[kind] = t
+		return nil
+	}
+}
+
+// WithNameMaxLen limits the length of the generated names. Longer names are
+// truncated, and suffixed with the hash of the object to keep them unique.
+func WithNameMaxLen(l int) NamerOption {
+	return func(n *Namer) error {
+		if l < 16 {
+			return fmt.Errorf("sql/schema: name max length must be at least 16, got %d", l)
+		}
+		n.maxLen = l
+		return nil
+	}
+}
+
+// NameRealm names the unnamed objects of all tables in the realm.
+func (n *Namer) NameRealm(r *Realm) error {
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			if err := n.NameTable(t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NameTable names the unnamed indexes, foreign keys and checks of the table.
+func (n *Namer) NameTable(t *Table) error {
+	used := make(map[string]bool)
+	for _, idx := range t.Indexes {
+		used[idx.Name] = true
+	}
+	for _, fk := range t.ForeignKeys {
+		used[fk.Symbol] = true
+	}
+	for _, a := range t.Attrs {
+		if c, ok := a.(*Check); ok {
+			used[c.Name] = true
+		}
+	}
+	for _, idx := range t.Indexes {
+		if idx.Name != "" {
+			continue
+		}
+		d := &NameData{Kind: NameIndex, Table: t.Name}
+		if idx.Unique {
+			d.Kind = NameUnique
+		}
+		for _, p := range idx.Parts {
+			switch {
+			case p.C != nil:
+				d.Columns = append(d.Columns, p.C.Name)
+			case p.X != nil:
+				if x, ok := p.X.(*RawExpr); ok {
+					d.Columns = append(d.Columns, x.X)
+				}
+			}
+		}
+		name, err := n.name(d, used)
+		if err != nil {
+			return err
+		}
+		idx.Name = name
+	}
+	for _, fk := range t.ForeignKeys {
+		if fk.Symbol != "" {
+			continue
+		}
+		d := &NameData{Kind: NameForeignKey, Table: t.Name}
+		for _, c := range fk.Columns {
+			d.Columns = append(d.Columns, c.Name)
+		}
+		if fk.RefTable != nil {
+			d.RefTable = fk.RefTable.Name
+		}
+		for _, c := range fk.RefColumns {
+			d.RefColumns = append(d.RefColumns, c.Name)
+		}
+		name, err := n.name(d, used)
+		if err != nil {
+			return err
+		}
+		fk.Symbol = name
+	}
+	for _, a := range t.Attrs {
+		c, ok := a.(*Check)
+		if !ok || c.Name != "" {
+			continue
+		}
+		name, err := n.name(&NameData{Kind: NameCheck, Table: t.Name, Expr: c.Expr}, used)
+		if err != nil {
+			return err
+		}
+		c.Name = name
+	}
+	return nil
+}
+
+// name executes the template of the object kind, and returns a name
+// that is not used by other objects in the table.
+func (n *Namer) name(d *NameData, used map[string]bool) (string, error) {
+	h := sha256.New()
+	for _, s := range [][]string{{d.Kind, d.Table, d.RefTable, d.Expr}, d.Columns, d.RefColumns} {
+		h.Write([]byte(strings.Join(s, "\x00")))
+		h.Write([]byte{0})
+	}
+	d.Hash = hex.EncodeToString(h.Sum(nil))[:8]
+	var b strings.Builder
+	if err := n.templates[d.Kind].Execute(&b, d); err != nil {
+		return "", fmt.Errorf("sql/schema: executing %s naming template: %w", d.Kind, err)
+	}
+	name := b.String()
+	if name == "" {
+		return "", fmt.Errorf("sql/schema: %s naming template returned an empty name for table %q", d.Kind, d.Table)
+	}
+	// Objects with identical definitions are suffixed with a counter.
+	for i := 1; ; i++ {
+		var suffix string
+		if i > 1 {
+			suffix = "_" + strconv.Itoa(i)
+		}
+		c := name + suffix
+		if n.maxLen > 0 && len(c) > n.maxLen {
+			suffix = "_" + d.Hash + suffix
+			c = name[:n.maxLen-len(suffix)] + suffix
+		}
+		if !used[c] {
+			used[c] = true
+			return c, nil
+		}
+	}
+}
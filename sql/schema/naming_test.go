@@ -0,0 +1,91 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamer(t *testing.T) {
+	_, err := schema.NewNamer(schema.WithNameTemplate("view", "v"))
+	require.EqualError(t, err, `sql/schema: unknown naming kind "view"`)
+	_, err = schema.NewNamer(schema.WithNameTemplate(schema.NameIndex, "{{ .Table "))
+	require.Error(t, err)
+	_, err = schema.NewNamer(schema.WithNameMaxLen(8))
+	require.EqualError(t, err, "sql/schema: name max length must be at least 16, got 8")
+
+	newTable := func() *schema.Table {
+		var (
+			id    = schema.NewIntColumn("id", "int")
+			email = schema.NewStringColumn("email", "text")
+			users = schema.NewTable("users").AddColumns(id, email)
+			posts = schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("author_id", "int"))
+		)
+		posts.AddIndexes(
+			schema.NewIndex("").AddColumns(posts.Columns[1]),
+			schema.NewIndex("").AddColumns(posts.Columns[1]),
+			schema.NewUniqueIndex("").AddColumns(posts.Columns[0]),
+			schema.NewIndex("named").AddColumns(posts.Columns[0]),
+		)
+		posts.AddForeignKeys(schema.NewForeignKey("").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(id))
+		posts.AddChecks(schema.NewCheck().SetExpr("id > 0"), schema.NewCheck().SetName("named_check").SetExpr("id < 10"))
+		schema.New("public").AddTables(users, posts)
+		return posts
+	}
+	names := func(tt *schema.Table) []string {
+		var names []string
+		for _, idx := range tt.Indexes {
+			names = append(names, idx.Name)
+		}
+		for _, fk := range tt.ForeignKeys {
+			names = append(names, fk.Symbol)
+		}
+		for _, a := range tt.Attrs {
+			if c, ok := a.(*schema.Check); ok {
+				names = append(names, c.Name)
+			}
+		}
+		return names
+	}
+
+	n, err := schema.NewNamer()
+	require.NoError(t, err)
+	posts := newTable()
+	require.NoError(t, n.NameRealm(schema.NewRealm(posts.Schema)))
+	got := names(posts)
+	require.Len(t, got, 7)
+	require.Regexp(t, `^idx_posts_[0-9a-f]{8}$`, got[0])
+	require.Equal(t, got[0]+"_2", got[1], "identical indexes are suffixed")
+	require.Regexp(t, `^uq_posts_[0-9a-f]{8}$`, got[2])
+	require.Equal(t, "named", got[3])
+	require.Regexp(t, `^fk_posts_[0-9a-f]{8}$`, got[4])
+	require.Regexp(t, `^ck_posts_[0-9a-f]{8}$`, got[5])
+	require.Equal(t, "named_check", got[6])
+
+	// Names are stable.
+	other := newTable()
+	require.NoError(t, n.NameTable(other))
+	require.Equal(t, got, names(other))
+
+	// Custom templates and max length.
+	n, err = schema.NewNamer(
+		schema.WithNameTemplate(schema.NameIndex, `{{ .Table }}_{{ join .Columns "_" }}_idx`),
+		schema.WithNameTemplate(schema.NameForeignKey, `{{ .Table }}_{{ join .Columns "_" }}_{{ .RefTable }}_{{ join .RefColumns "_" }}_fkey_with_a_very_long_name`),
+		schema.WithNameMaxLen(32),
+	)
+	require.NoError(t, err)
+	posts = newTable()
+	require.NoError(t, n.NameTable(posts))
+	got = names(posts)
+	require.Equal(t, "posts_author_id_idx", got[0])
+	require.Equal(t, "posts_author_id_idx_2", got[1])
+	require.Len(t, got[4], 32)
+	require.True(t, strings.HasPrefix(got[4], "posts_author_id_users_"), got[4])
+}
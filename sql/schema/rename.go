@@ -0,0 +1,391 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultRenameThreshold is the similarity threshold used
+// by the rename detection if no other threshold was set.
+const DefaultRenameThreshold = 0.6
+
+// RenameOptions configures the rename detection of the differ. When enabled,
+// the differ looks for pairs of dropped and added tables (or columns) that are
+// similar enough, and proposes a RenameTable (or RenameColumn) instead of
+// dropping one object and creating the other.
+//
+// Columns are candidates for a rename only if their definitions are identical,
+// and their similarity is computed from the distance between their names and
+// their positions in the table. Tables are candidates only if they reside in
+// the same schema, and their similarity is computed from their columns and the
+// distance between their names.
+//
+// Renames can also be declared explicitly by setting the PrevName attribute on
+// the desired table or column (e.g. renamed_from in HCL). Explicit renames are
+// not subject to the threshold, and do not require confirmation.
+type RenameOptions struct {
+	// ExplicitOnly disables the heuristics, and limits the
+	// detection to renames that were declared explicitly.
+	ExplicitOnly bool
+
+	// Threshold is the minimum similarity score, between 0 and 1, for a pair of
+	// dropped and added objects to be proposed as a rename. If zero, the
+	// DefaultRenameThreshold is used.
+	Threshold float64
+
+	// Confirm is called for each RenameTable or RenameColumn proposed by the
+	// heuristics, and reports whether the rename should be applied. Rejected
+	// proposals are kept as drop and add changes. A nil Confirm accepts all
+	// proposals.
+	Confirm func(Change) (bool, error)
+}
+
+// DiffWithRenames enables the rename detection of the diffing process.
+func DiffWithRenames(opts *RenameOptions) DiffOption {
+	return func(o *DiffOptions) {
+		if opts == nil {
+			opts = &RenameOptions{}
+		}
+		o.Renames = opts
+	}
+}
+
+// renamer detects renames in the changes computed by a Differ.
+type renamer struct {
+	Differ
+	opts *RenameOptions
+}
+
+// rename is a candidate for a rename. drop and add are
+// the indexes of the replaced changes in the change list.
+type rename struct {
+	drop, add int
+	score     float64
+	explicit  bool
+	change    Change
+}
+
+// schemaChanges detects table renames in the given changes, and column renames
+// in the modified tables. The from function returns the current state of the
+// given (desired) table.
+func (r *renamer) schemaChanges(changes []Change, from func(*Table) (*Table, bool)) ([]Change, error) {
+	var (
+		drops, adds []int
+		renames     []*rename
+	)
+	for i, c := range changes {
+		switch c.(type) {
+		case *DropTable:
+			drops = append(drops, i)
+		case *AddTable:
+			adds = append(adds, i)
+		}
+	}
+	for _, i := range drops {
+		t1 := changes[i].(*DropTable).T
+		for _, j := range adds {
+			t2 := changes[j].(*AddTable).T
+			if !sameSchema(t1.Schema, t2.Schema) {
+				continue
+			}
+			rn := &rename{drop: i, add: j, change: &RenameTable{From: t1, To: t2}}
+			if prev, ok := prevName(t2.Attrs); ok {
+				if rn.explicit = prev == t1.Name; !rn.explicit {
+					continue
+				}
+			} else if r.opts.ExplicitOnly {
+				continue
+			} else {
+				score, err := r.tableScore(t1, t2)
+				if err != nil {
+					return nil, err
+				}
+				rn.score = score
+			}
+			renames = append(renames, rn)
+		}
+	}
+	accepted, err := r.resolve(renames)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Change, 0, len(changes))
+	for i, c := range changes {
+		switch c := c.(type) {
+		case *DropTable:
+			rn, ok := accepted[i]
+			if !ok {
+				result = append(result, c)
+				continue
+			}
+			rt := rn.change.(*RenameTable)
+			// Tables are renamed before their other changes are
+			// computed, and therefore, the previous state of the
+			// table is diffed using its new name.
+			t1 := *rt.From
+			t1.Name = rt.To.Name
+			tc, err := r.Differ.TableDiff(&t1, rt.To)
+			if err != nil {
+				return nil, err
+			}
+			if tc, err = r.tableChanges(&t1, rt.To, tc); err != nil {
+				return nil, err
+			}
+			result = append(result, rt)
+			if len(tc) > 0 {
+				result = append(result, &ModifyTable{T: rt.To, Changes: tc})
+			}
+		case *AddTable:
+			if _, ok := accepted[i]; !ok {
+				result = append(result, c)
+			}
+		case *ModifyTable:
+			if t1, ok := from(c.T); ok {
+				tc, err := r.tableChanges(t1, c.T, c.Changes)
+				if err != nil {
+					return nil, err
+				}
+				m := *c
+				m.Changes = tc
+				c = &m
+			}
+			result = append(result, c)
+		default:
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// tableChanges detects column renames in the changes
+// computed for migrating table "from" to table "to".
+func (r *renamer) tableChanges(from, to *Table, changes []Change) ([]Change, error) {
+	var (
+		drops, adds []int
+		renames     []*rename
+	)
+	for i, c := range changes {
+		switch c.(type) {
+		case *DropColumn:
+			drops = append(drops, i)
+		case *AddColumn:
+			adds = append(adds, i)
+		}
+	}
+	for _, i := range drops {
+		c1 := changes[i].(*DropColumn).C
+		for _, j := range adds {
+			c2 := changes[j].(*AddColumn).C
+			prev, explicit := prevName(c2.Attrs)
+			if explicit && prev != c1.Name || !explicit && r.opts.ExplicitOnly {
+				continue
+			}
+			same, err := r.sameColumn(to, c1, c2)
+			switch {
+			case err != nil:
+				return nil, err
+			case !same && explicit:
+				return nil, fmt.Errorf("sql/schema: column %q cannot be renamed from %q as its definition was changed", c2.Name, c1.Name)
+			case !same:
+				continue
+			}
+			rn := &rename{drop: i, add: j, explicit: explicit, change: &RenameColumn{From: c1, To: c2}}
+			if !explicit {
+				rn.score = 0.6*similarity(c1.Name, c2.Name) + 0.4*positionScore(from, to, c1, c2)
+			}
+			renames = append(renames, rn)
+		}
+	}
+	accepted, err := r.resolve(renames)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Change, 0, len(changes))
+	for i, c := range changes {
+		switch c.(type) {
+		case *DropColumn:
+			if rn, ok := accepted[i]; ok {
+				c = rn.change
+			}
+		case *AddColumn:
+			if _, ok := accepted[i]; ok {
+				continue
+			}
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// resolve selects the renames to apply from the given candidates. Explicit
+// renames are selected first, and the others are selected by their scores and
+// the user confirmation. The returned map holds the selected renames by the
+// indexes of their drop and add changes.
+func (r *renamer) resolve(renames []*rename) (map[int]*rename, error) {
+	threshold := r.opts.Threshold
+	if threshold == 0 {
+		threshold = DefaultRenameThreshold
+	}
+	sort.SliceStable(renames, func(i, j int) bool {
+		if renames[i].explicit != renames[j].explicit {
+			return renames[i].explicit
+		}
+		return renames[i].score > renames[j].score
+	})
+	accepted := make(map[int]*rename)
+	for _, rn := range renames {
+		if _, ok := accepted[rn.drop]; ok {
+			continue
+		}
+		if _, ok := accepted[rn.add]; ok {
+			continue
+		}
+		if !rn.explicit {
+			if rn.score < threshold {
+				continue
+			}
+			if r.opts.Confirm != nil {
+				ok, err := r.opts.Confirm(rn.change)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+		}
+		accepted[rn.drop], accepted[rn.add] = rn, rn
+	}
+	return accepted, nil
+}
+
+// tableScore returns the similarity score of the two tables. It is based on
+// the ratio of identical columns in both tables, and the distance between the
+// table names.
+func (r *renamer) tableScore(t1, t2 *Table) (float64, error) {
+	if len(t1.Columns)+len(t2.Columns) == 0 {
+		return 0, nil
+	}
+	var n int
+	for _, c1 := range t1.Columns {
+		c2, ok := t2.Column(c1.Name)
+		if !ok {
+			continue
+		}
+		same, err := r.sameColumn(t2, c1, c2)
+		if err != nil {
+			return 0, err
+		}
+		if same {
+			n++
+		}
+	}
+	columns := float64(2*n) / float64(len(t1.Columns)+len(t2.Columns))
+	return 0.7*columns + 0.3*similarity(t1.Name, t2.Name), nil
+}
+
+// sameColumn reports if the two columns have identical definitions, regardless
+// of their names. The columns are compared by the underlying Differ, in the
+// context of table t.
+func (r *renamer) sameColumn(t *Table, c1, c2 *Column) (bool, error) {
+	cp := *c1
+	cp.Name = c2.Name
+	t1, t2 := *t, *t
+	t1.Columns, t2.Columns = []*Column{&cp}, []*Column{c2}
+	t1.PrimaryKey, t2.PrimaryKey = nil, nil
+	t1.Indexes, t2.Indexes = nil, nil
+	t1.ForeignKeys, t2.ForeignKeys = nil, nil
+	changes, err := r.Differ.TableDiff(&t1, &t2)
+	if err != nil {
+		return false, err
+	}
+	return len(changes) == 0, nil
+}
+
+// positionScore returns the similarity of the columns positions
+// in their tables. 1 means both columns have the same position.
+func positionScore(t1, t2 *Table, c1, c2 *Column) float64 {
+	i, j := columnIndex(t1, c1), columnIndex(t2, c2)
+	if i == -1 || j == -1 {
+		return 0
+	}
+	n := len(t1.Columns)
+	if len(t2.Columns) > n {
+		n = len(t2.Columns)
+	}
+	d := i - j
+	if d < 0 {
+		d = -d
+	}
+	return 1 - float64(d)/float64(n)
+}
+
+func columnIndex(t *Table, c *Column) int {
+	for i := range t.Columns {
+		if t.Columns[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// similarity returns the similarity of the two names based on their
+// edit distance. 1 means the names are equal (case-insensitive).
+func similarity(a, b string) float64 {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(a, b))/float64(n)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cur[j] = prev[j-1]
+			if a[i-1] != b[j-1] {
+				cur[j]++
+			}
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func sameSchema(s1, s2 *Schema) bool {
+	if s1 == nil || s2 == nil {
+		return s1 == s2
+	}
+	return s1.Name == s2.Name
+}
+
+// prevName returns the previous name of an object, if it was set.
+func prevName(attrs []Attr) (string, bool) {
+	for _, a := range attrs {
+		if p, ok := a.(*PrevName); ok {
+			return p.Name, true
+		}
+	}
+	return "", false
+}
@@ -17,7 +17,13 @@ type (
 		Name   string
 		Realm  *Realm
 		Tables []*Table
-		Attrs  []Attr // Attrs and options.
+		Views  []*View
+		Funcs  []*Func
+		Procs  []*Proc
+		// Sequences holds the standalone sequences of the schema, and not the
+		// ones that are created implicitly for columns (e.g. SERIAL or IDENTITY).
+		Sequences []*Sequence
+		Attrs     []Attr // Attrs and options.
 	}
 
 	// A Table represents a table definition.
@@ -28,9 +34,73 @@ type (
 		Indexes     []*Index
 		PrimaryKey  *Index
 		ForeignKeys []*ForeignKey
+		Triggers    []*Trigger
 		Attrs       []Attr // Attrs, constraints and options.
 	}
 
+	// A View represents a view definition.
+	View struct {
+		Name   string
+		Schema *Schema
+		Def    string // The query that defines the view (i.e. its SELECT statement).
+		Attrs  []Attr // Attrs and options.
+	}
+
+	// A Func represents a function definition.
+	Func struct {
+		Name   string
+		Schema *Schema
+		Args   string // The arguments of the function. e.g. "a integer, b text".
+		Ret    string // The return type of the function.
+		Lang   string // The language of the function body (e.g. "plpgsql"), if supported by the dialect.
+		Body   string // The function body.
+		Attrs  []Attr // Attrs and options.
+	}
+
+	// A Proc represents a stored procedure definition.
+	Proc struct {
+		Name   string
+		Schema *Schema
+		Args   string // The arguments of the procedure. e.g. "IN a int, OUT b int".
+		Lang   string // The language of the procedure body, if supported by the dialect.
+		Body   string // The procedure body.
+		Attrs  []Attr // Attrs and options.
+	}
+
+	// A Sequence represents a standalone sequence definition. Zero values
+	// of its options stand for the defaults of the database.
+	Sequence struct {
+		Name      string
+		Schema    *Schema
+		Type      string // The data type of the sequence (e.g. bigint), if supported by the dialect.
+		Start     int64  // The first value of the sequence.
+		Increment int64  // The value added to the current value to get the next one.
+		Min, Max  *int64 // The bounds of the sequence.
+		Cache     int64  // The number of values that are preallocated.
+		Cycle     bool   // Whether the sequence wraps around when it reaches its bound.
+		Owner     *SequenceOwner
+		Attrs     []Attr // Attrs and options.
+	}
+
+	// A SequenceOwner describes the column that owns a sequence (i.e. OWNED BY),
+	// which means the sequence is dropped along with the column or its table.
+	SequenceOwner struct {
+		T *Table
+		C *Column
+	}
+
+	// A Trigger represents a trigger definition.
+	Trigger struct {
+		Name       string
+		Table      *Table
+		ActionTime string   // BEFORE, AFTER or INSTEAD OF.
+		Events     []string // INSERT, UPDATE, DELETE or TRUNCATE. e.g. "UPDATE OF c".
+		ForEachRow bool     // Row-level or statement-level trigger.
+		When       string   // Optional condition for executing the trigger.
+		Body       string   // The trigger action. e.g. "EXECUTE FUNCTION f()" or "BEGIN ... END".
+		Attrs      []Attr   // Attrs and options.
+	}
+
 	// A Column represents a column definition.
 	Column struct {
 		Name    string
@@ -105,6 +175,56 @@ func (s *Schema) Table(name string) (*Table, bool) {
 	return nil, false
 }
 
+// View returns the first view that matched the given name.
+func (s *Schema) View(name string) (*View, bool) {
+	for _, v := range s.Views {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Func returns the first function that matched the given name.
+func (s *Schema) Func(name string) (*Func, bool) {
+	for _, f := range s.Funcs {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// Proc returns the first procedure that matched the given name.
+func (s *Schema) Proc(name string) (*Proc, bool) {
+	for _, p := range s.Procs {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Sequence returns the first sequence that matched the given name.
+func (s *Schema) Sequence(name string) (*Sequence, bool) {
+	for _, sq := range s.Sequences {
+		if sq.Name == name {
+			return sq, true
+		}
+	}
+	return nil, false
+}
+
+// Trigger returns the first trigger that matched the given name.
+func (t *Table) Trigger(name string) (*Trigger, bool) {
+	for _, tr := range t.Triggers {
+		if tr.Name == name {
+			return tr, true
+		}
+	}
+	return nil, false
+}
+
 // Column returns the first column that matched the given name.
 func (t *Table) Column(name string) (*Column, bool) {
 	for _, c := range t.Columns {
@@ -168,6 +288,22 @@ const (
 	SetDefault ReferenceOption = "SET DEFAULT"
 )
 
+// Units of the retention period of a table.
+const (
+	RetentionHour  = "HOUR"
+	RetentionDay   = "DAY"
+	RetentionWeek  = "WEEK"
+	RetentionMonth = "MONTH"
+	RetentionYear  = "YEAR"
+)
+
+// Modes for comparing the definition of a view with its inspected definition.
+const (
+	ViewCompareExact      = "exact"      // Definitions are compared as-is.
+	ViewCompareNormalized = "normalized" // Desired definitions are normalized by the dev database.
+	ViewCompareIgnore     = "ignore"     // Changes to the definition are ignored.
+)
+
 type (
 	// A Type represents a database type. The types below implements this
 	// interface and can be used for describing schemas.
@@ -306,6 +442,50 @@ type (
 		Expr string
 		Type string // Optional type. e.g. STORED or VIRTUAL.
 	}
+
+	// Setting describes a database-level setting, such as the
+	// default time zone or the SQL mode, and is attached to
+	// the realm.
+	Setting struct {
+		Name string
+		V    string
+	}
+
+	// PrevName describes the previous name of a table or a column, and
+	// is used by the differ for detecting renames explicitly.
+	PrevName struct {
+		Name string
+	}
+
+	// Retention describes the data retention (TTL) policy of a table. Rows whose
+	// time column is older than the retention period are expired by the database,
+	// using its native mechanism. e.g. TTL in ClickHouse and CockroachDB, or dropping
+	// old partitions using pg_partman in PostgreSQL.
+	Retention struct {
+		C      *Column // Time column.
+		Period int     // Number of units rows are retained.
+		Unit   string  // Period unit. e.g. DAY or MONTH.
+	}
+
+	// Unmanaged describes a schema object that is not modeled by Atlas,
+	// such as a view or a trigger. The object is kept as its raw DDL, and
+	// identified by a fingerprint of its CREATE statement, so that it is
+	// not lost when an inspected schema is applied on another database.
+	Unmanaged struct {
+		Type        string // Object type, e.g. VIEW or TRIGGER.
+		Name        string
+		Create      string // Statement for creating the object.
+		Drop        string // Optional statement for dropping the object.
+		Fingerprint string
+	}
+
+	// ViewCompare describes how the definition of a view is compared with its
+	// inspected definition, and allows tolerating definitions that the database
+	// rewrites (e.g. by the query planner). If absent, definitions are compared
+	// after their whitespace is normalized.
+	ViewCompare struct {
+		Mode string // ViewCompareExact, ViewCompareNormalized or ViewCompareIgnore.
+	}
 )
 
 // expressions.
@@ -331,3 +511,8 @@ func (*Comment) attr()       {}
 func (*Charset) attr()       {}
 func (*Collation) attr()     {}
 func (*GeneratedExpr) attr() {}
+func (*PrevName) attr()      {}
+func (*Retention) attr()     {}
+func (*Setting) attr()       {}
+func (*Unmanaged) attr()     {}
+func (*ViewCompare) attr()   {}
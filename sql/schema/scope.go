@@ -0,0 +1,128 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"fmt"
+	"path"
+)
+
+// A Scope describes an ownership boundary of a schema, e.g. the set of
+// tables owned by a team in a monorepo. Objects are matched against the
+// glob patterns of the scope using their qualified names (e.g. "public.users"),
+// or their unqualified ones (e.g. "users"). Owning a schema implies owning
+// all of its tables.
+type Scope struct {
+	Name    string   // name of the scope, e.g. "billing"
+	Schemas []string // patterns of the owned schemas
+	Tables  []string // patterns of the owned tables, e.g. "billing_*"
+}
+
+// ScopeError is returned by Scope.Check in case
+// a change touches an object outside the scope.
+type ScopeError struct {
+	Scope  string // scope name
+	Type   string // object type, "schema" or "table"
+	Name   string // qualified object name
+	Change Change // the change touching the object
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("sql/schema: %s %q is outside of the %q ownership scope", e.Type, e.Name, e.Scope)
+}
+
+// Validate validates the patterns of the scope.
+func (s *Scope) Validate() error {
+	for _, p := range append(s.Schemas, s.Tables...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("sql/schema: invalid pattern %q in scope %q: %w", p, s.Name, err)
+		}
+	}
+	return nil
+}
+
+// OwnsSchema reports if the scope owns the given schema.
+func (s *Scope) OwnsSchema(sc *Schema) bool {
+	return sc != nil && allowed(s.Schemas, sc.Name)
+}
+
+// OwnsTable reports if the scope owns the given table.
+func (s *Scope) OwnsTable(t *Table) bool {
+	return s.OwnsSchema(t.Schema) || allowed(s.Tables, tableName(t))
+}
+
+// Check returns a ScopeError in case one of the changes (including
+// nested ones) touches an object that is not owned by the scope.
+func (s *Scope) Check(changes []Change) error {
+	for _, c := range changes {
+		if err := s.check(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scope) check(c Change) error {
+	switch c := c.(type) {
+	case *AddSchema:
+		return s.checkSchema(c, c.S)
+	case *DropSchema:
+		return s.checkSchema(c, c.S)
+	case *ModifySchema:
+		for _, mc := range c.Changes {
+			switch mc.(type) {
+			case *AddTable, *DropTable, *ModifyTable, *RenameTable:
+				if err := s.check(mc); err != nil {
+					return err
+				}
+			default:
+				// Schema attributes.
+				if err := s.checkSchema(c, c.S); err != nil {
+					return err
+				}
+			}
+		}
+	case *AddTable:
+		return s.checkTable(c, c.T)
+	case *DropTable:
+		return s.checkTable(c, c.T)
+	case *ModifyTable:
+		return s.checkTable(c, c.T)
+	case *RenameTable:
+		if err := s.checkTable(c, c.From); err != nil {
+			return err
+		}
+		return s.checkTable(c, c.To)
+	}
+	return nil
+}
+
+func (s *Scope) checkSchema(c Change, sc *Schema) error {
+	if s.OwnsSchema(sc) {
+		return nil
+	}
+	return &ScopeError{Scope: s.Name, Type: "schema", Name: sc.Name, Change: c}
+}
+
+func (s *Scope) checkTable(c Change, t *Table) error {
+	if s.OwnsTable(t) {
+		return nil
+	}
+	return &ScopeError{Scope: s.Name, Type: "table", Name: tableName(t), Change: c}
+}
+
+// RestrictToScope returns a DiffHook that fails the diffing process
+// in case the computed changes touch objects outside the given scope.
+func RestrictToScope(s *Scope) DiffHook {
+	return func(changes []Change) ([]Change, error) {
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+		if err := s.Check(changes); err != nil {
+			return nil, err
+		}
+		return changes, nil
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package schemainspect provides an inspector that caches the inspected tables between
+// executions, and re-inspects only the tables that were changed in the database since the
+// last inspection. Changes are detected by the database driver using a cheap query on its
+// catalog (e.g. pg_class in PostgreSQL or INFORMATION_SCHEMA in MySQL).
+package schemainspect
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+type (
+	// Fingerprinter is the interface implemented by drivers that support cheap detection of
+	// table changes. Fingerprints returns a value for each table in the given schema that
+	// changes whenever the table or one of its child resources (e.g. columns, indexes or
+	// triggers) is changed. A nil map indicates that change detection is not supported by
+	// the connected database, and in this case, all tables are inspected.
+	Fingerprinter interface {
+		Fingerprints(ctx context.Context, schema string) (map[string]string, error)
+	}
+
+	// Codec encodes and decodes the cached tables. sqlclient.Client
+	// and the HCL state of the drivers implement this interface.
+	Codec interface {
+		schemahcl.Marshaler
+		schemahcl.Evaluator
+	}
+
+	// Entry is the cached state of a schema.
+	Entry struct {
+		// Version of the entry format. Entries
+		// of other versions are ignored.
+		Version int `json:"version"`
+		// Fingerprints of the tables at the time they were inspected.
+		Fingerprints map[string]string `json:"fingerprints"`
+		// Data holds the encoded tables of the schema.
+		Data []byte `json:"data"`
+	}
+
+	// Cache stores the inspected state of schemas. A nil entry
+	// and a nil error are returned by Get in case of a cache miss.
+	Cache interface {
+		Get(ctx context.Context, schema string) (*Entry, error)
+		Put(ctx context.Context, schema string, e *Entry) error
+	}
+
+	// Inspector wraps a schema.Inspector and inspects only the tables that
+	// were changed since the last inspection. The rest are loaded from its cache.
+	Inspector struct {
+		schema.Inspector
+		codec Codec
+		cache Cache
+	}
+
+	// Option configures an Inspector.
+	Option func(*Inspector)
+)
+
+// entryVersion is the version of the Entry format.
+const entryVersion = 1
+
+// New returns a new Inspector that wraps the given inspector and uses the
+// codec to encode and decode the cached tables. If no cache was configured,
+// or the inspector does not implement Fingerprinter, the Inspector passes
+// all calls to the underlying inspector.
+func New(insp schema.Inspector, codec Codec, opts ...Option) *Inspector {
+	i := &Inspector{Inspector: insp, codec: codec}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// WithCache configures the cache used by the Inspector.
+func WithCache(c Cache) Option {
+	return func(i *Inspector) {
+		i.cache = c
+	}
+}
+
+// InspectSchema implements the schema.Inspector interface.
+func (i *Inspector) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	if opts == nil {
+		opts = &schema.InspectOptions{}
+	}
+	fp, mode, ok := i.cached(sqlx.ModeInspectSchema(opts))
+	if !ok || len(opts.Tables) > 0 {
+		return i.Inspector.InspectSchema(ctx, name, opts)
+	}
+	s, err := i.Inspector.InspectSchema(ctx, name, &schema.InspectOptions{Mode: mode &^ schema.InspectTables})
+	if err != nil {
+		return nil, err
+	}
+	if err := i.tables(ctx, fp, s, mode); err != nil {
+		return nil, err
+	}
+	return sqlx.ExcludeSchema(s, opts.Exclude)
+}
+
+// InspectRealm implements the schema.Inspector interface.
+func (i *Inspector) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	if opts == nil {
+		opts = &schema.InspectRealmOption{}
+	}
+	fp, mode, ok := i.cached(sqlx.ModeInspectRealm(opts))
+	if !ok {
+		return i.Inspector.InspectRealm(ctx, opts)
+	}
+	r, err := i.Inspector.InspectRealm(ctx, &schema.InspectRealmOption{
+		Mode:    mode &^ schema.InspectTables,
+		Schemas: opts.Schemas,
+		Workers: opts.Workers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range r.Schemas {
+		if err := i.tables(ctx, fp, s, mode); err != nil {
+			return nil, err
+		}
+	}
+	// Link foreign keys that reference tables in other schemas.
+	sqlx.LinkSchemaTables(r.Schemas)
+	return sqlx.ExcludeRealm(r, opts.Exclude)
+}
+
+// cached reports if the given inspection mode can be served from the cache.
+// Permissions are attached to the inspected tables, and therefore, modes
+// that include them are passed to the underlying inspector.
+func (i *Inspector) cached(mode schema.InspectMode) (Fingerprinter, schema.InspectMode, bool) {
+	fp, ok := i.Inspector.(Fingerprinter)
+	if !ok || i.cache == nil || i.codec == nil || !mode.Is(schema.InspectTables) || mode.Is(schema.InspectPermissions) {
+		return nil, 0, false
+	}
+	return fp, mode, true
+}
+
+// tables adds the tables of the schema to s. Tables that were not changed since the
+// last inspection are loaded from the cache, and the rest are inspected from the database.
+func (i *Inspector) tables(ctx context.Context, fp Fingerprinter, s *schema.Schema, mode schema.InspectMode) error {
+	prints, err := fp.Fingerprints(ctx, s.Name)
+	if err != nil {
+		return err
+	}
+	if prints == nil {
+		return i.inspectTables(ctx, s, mode, nil)
+	}
+	names := make([]string, 0, len(prints))
+	for n := range prints {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var (
+		changed []string
+		cached  = i.load(ctx, s.Name, prints)
+	)
+	for _, n := range names {
+		if _, ok := cached[n]; !ok {
+			changed = append(changed, n)
+		}
+	}
+	// Tables that were not changed are added without re-inspection.
+	for _, t := range cached {
+		s.AddTables(t)
+	}
+	switch {
+	case len(changed) == len(names):
+		// All tables were changed (or the cache is empty).
+		if err := i.inspectTables(ctx, s, mode, nil); err != nil {
+			return err
+		}
+	case len(changed) > 0:
+		if err := i.inspectTables(ctx, s, mode, changed); err != nil {
+			return err
+		}
+	}
+	sort.Slice(s.Tables, func(i, j int) bool { return s.Tables[i].Name < s.Tables[j].Name })
+	sqlx.LinkSchemaTables([]*schema.Schema{s})
+	return i.store(ctx, s, prints)
+}
+
+// inspectTables inspects the given tables of s from the database and adds them to it.
+// If no tables were given, all tables of the schema are inspected.
+func (i *Inspector) inspectTables(ctx context.Context, s *schema.Schema, mode schema.InspectMode, tables []string) error {
+	is, err := i.Inspector.InspectSchema(ctx, s.Name, &schema.InspectOptions{
+		Mode:   schema.InspectSchemas | schema.InspectTables | mode&schema.InspectTriggers,
+		Tables: tables,
+	})
+	if err != nil {
+		return err
+	}
+	s.AddTables(is.Tables...)
+	return nil
+}
+
+// load returns the cached tables of the schema whose fingerprints were not changed.
+// Errors are not returned, as an unreadable cache entry is treated as a cache miss.
+func (i *Inspector) load(ctx context.Context, name string, prints map[string]string) map[string]*schema.Table {
+	e, err := i.cache.Get(ctx, name)
+	if err != nil || e == nil || e.Version != entryVersion || len(e.Data) == 0 {
+		return nil
+	}
+	p := hclparse.NewParser()
+	if _, diag := p.ParseHCL(e.Data, name+".hcl"); diag.HasErrors() {
+		return nil
+	}
+	var s schema.Schema
+	if err := i.codec.Eval(p, &s, nil); err != nil {
+		return nil
+	}
+	tables := make(map[string]*schema.Table)
+	for _, t := range s.Tables {
+		if v, ok := prints[t.Name]; ok && v != "" && e.Fingerprints[t.Name] == v {
+			tables[t.Name] = t
+		}
+	}
+	return tables
+}
+
+// store writes the tables of the schema and their fingerprints to the cache. Schemas
+// whose tables cannot be encoded standalone (e.g. tables with foreign keys to other
+// schemas) are not cached, and their tables are inspected on every execution.
+func (i *Inspector) store(ctx context.Context, s *schema.Schema, prints map[string]string) error {
+	e := &Entry{Version: entryVersion, Fingerprints: prints}
+	if len(s.Tables) > 0 {
+		data, err := i.codec.MarshalSpec(&schema.Schema{Name: s.Name, Realm: s.Realm, Tables: s.Tables})
+		if err != nil {
+			return nil
+		}
+		e.Data = data
+	}
+	if err := i.cache.Put(ctx, s.Name, e); err != nil {
+		return fmt.Errorf("schemainspect: writing cache entry of schema %q: %w", s.Name, err)
+	}
+	return nil
+}
+
+// DirCache is a Cache that stores its entries as JSON files in a local directory.
+type DirCache struct {
+	path string
+}
+
+// NewDirCache returns a new DirCache stored in the given directory.
+// The directory is created on first write if it does not exist.
+func NewDirCache(path string) *DirCache {
+	return &DirCache{path: path}
+}
+
+// Get implements the Cache interface.
+func (c *DirCache) Get(_ context.Context, name string) (*Entry, error) {
+	b, err := os.ReadFile(c.file(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e := &Entry{}
+	if err := json.Unmarshal(b, e); err != nil {
+		return nil, fmt.Errorf("schemainspect: decoding cache entry of schema %q: %w", name, err)
+	}
+	return e, nil
+}
+
+// Put implements the Cache interface.
+func (c *DirCache) Put(_ context.Context, name string, e *Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.path, os.ModePerm); err != nil {
+		return err
+	}
+	// Write to a temporary file first, to avoid leaving
+	// a partial entry in case the process is interrupted.
+	f, err := os.CreateTemp(c.path, ".entry.*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), c.file(name)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// file returns the path of the cache file of the schema. Names are hashed,
+// as schema names are not guaranteed to be valid file names.
+func (c *DirCache) file(name string) string {
+	h := sha256.Sum256([]byte(name))
+	return filepath.Join(c.path, hex.EncodeToString(h[:8])+".json")
+}
+
+var (
+	_ schema.Inspector = (*Inspector)(nil)
+	_ Cache            = (*DirCache)(nil)
+)
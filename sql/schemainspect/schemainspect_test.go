@@ -0,0 +1,166 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schemainspect_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/schemainspect"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspector_InspectSchema(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		insp  = &mockInspector{prints: map[string]string{"users": "1", "posts": "1"}}
+		cache = schemainspect.NewDirCache(t.TempDir())
+		i     = schemainspect.New(insp, codec, schemainspect.WithCache(cache))
+	)
+	// Empty cache.
+	s, err := i.InspectSchema(ctx, "public", nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{nil, {}}, insp.calls)
+	requireTables(t, s)
+	e, err := cache.Get(ctx, "public")
+	require.NoError(t, err)
+	require.Equal(t, insp.prints, e.Fingerprints)
+
+	// No changes.
+	insp.calls = nil
+	s, err = i.InspectSchema(ctx, "public", nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{nil}, insp.calls, "tables should be loaded from cache")
+	requireTables(t, s)
+
+	// Changed table.
+	insp.calls = nil
+	insp.prints["posts"] = "2"
+	s, err = i.InspectSchema(ctx, "public", nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{nil, {"posts"}}, insp.calls)
+	requireTables(t, s)
+
+	// Tables filter is passed to the underlying inspector.
+	insp.calls = nil
+	_, err = i.InspectSchema(ctx, "public", &schema.InspectOptions{Tables: []string{"users"}})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"users"}}, insp.calls)
+
+	// Corrupted entries are treated as cache misses.
+	insp.calls = nil
+	require.NoError(t, cache.Put(ctx, "public", &schemainspect.Entry{Version: 1, Fingerprints: insp.prints, Data: []byte("table {")}))
+	s, err = i.InspectSchema(ctx, "public", nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{nil, {}}, insp.calls)
+	requireTables(t, s)
+
+	// Change detection is not supported.
+	insp.calls, insp.prints = nil, nil
+	s, err = i.InspectSchema(ctx, "public", nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{nil, {}}, insp.calls)
+	requireTables(t, s)
+}
+
+func TestInspector_NoCache(t *testing.T) {
+	insp := &mockInspector{prints: map[string]string{"users": "1", "posts": "1"}}
+	s, err := schemainspect.New(insp, codec).InspectSchema(context.Background(), "public", nil)
+	require.NoError(t, err)
+	require.Len(t, insp.calls, 1)
+	requireTables(t, s)
+}
+
+func TestDirCache(t *testing.T) {
+	var (
+		ctx = context.Background()
+		dir = filepath.Join(t.TempDir(), "cache")
+		c   = schemainspect.NewDirCache(dir)
+	)
+	e, err := c.Get(ctx, "public")
+	require.NoError(t, err)
+	require.Nil(t, e)
+	want := &schemainspect.Entry{Version: 1, Fingerprints: map[string]string{"t": "1"}, Data: []byte(`table "t" {}`)}
+	require.NoError(t, c.Put(ctx, "public", want))
+	e, err = c.Get(ctx, "public")
+	require.NoError(t, err)
+	require.Equal(t, want, e)
+	e, err = c.Get(ctx, "other")
+	require.NoError(t, err)
+	require.Nil(t, e)
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1, "temporary files should be removed")
+}
+
+var codec = struct {
+	schemahcl.Marshaler
+	schemahcl.Evaluator
+}{postgres.MarshalHCL, postgres.EvalHCL}
+
+// requireTables checks the tables returned by mockInspector, and
+// that foreign keys were linked to the tables of the returned schema.
+func requireTables(t *testing.T, s *schema.Schema) {
+	require.Len(t, s.Tables, 2)
+	require.Equal(t, "posts", s.Tables[0].Name)
+	require.Equal(t, "users", s.Tables[1].Name)
+	require.Equal(t, s, s.Tables[0].Schema)
+	require.Len(t, s.Tables[0].ForeignKeys, 1)
+	require.True(t, s.Tables[0].ForeignKeys[0].RefTable == s.Tables[1], "foreign key should reference the schema table")
+}
+
+// mockInspector returns a schema with the "users" and "posts" tables, and records
+// the tables filter of the calls that inspect tables (an empty slice means all).
+type mockInspector struct {
+	schema.Inspector
+	prints map[string]string
+	calls  [][]string
+}
+
+func (m *mockInspector) Fingerprints(context.Context, string) (map[string]string, error) {
+	return m.prints, nil
+}
+
+func (m *mockInspector) InspectSchema(_ context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	s := schema.New(name)
+	if opts == nil {
+		opts = &schema.InspectOptions{}
+	}
+	if opts.Mode != 0 && !opts.Mode.Is(schema.InspectTables) {
+		m.calls = append(m.calls, nil)
+		return s, nil
+	}
+	m.calls = append(m.calls, append([]string{}, opts.Tables...))
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint"))
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns...))
+	posts := schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewIntColumn("user_id", "bigint"))
+	// Referenced tables that were not inspected are returned as stubs.
+	ref := users
+	if len(opts.Tables) > 0 && !contains(opts.Tables, "users") {
+		ref = schema.NewTable("users").SetSchema(s).AddColumns(schema.NewIntColumn("id", "bigint"))
+	}
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(ref).AddRefColumns(ref.Columns...))
+	for _, t := range []*schema.Table{posts, users} {
+		if len(opts.Tables) == 0 || contains(opts.Tables, t.Name) {
+			s.AddTables(t)
+		}
+	}
+	return s, nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
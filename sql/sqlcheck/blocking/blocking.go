@@ -0,0 +1,165 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package blocking provides an analyzer for changes that rewrite existing tables or
+// indexes, or hold locks that block writes to the table until they are done. Executing
+// such changes on large tables might cause downtime, and should be done online.
+package blocking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+)
+
+type (
+	// Analyzer checks for changes that block writes to existing tables.
+	Analyzer struct {
+		sqlcheck.Options
+		Handler
+	}
+
+	// Handler holds the underlying driver handlers.
+	Handler struct {
+		// Online reports if the given statement is executed online, without
+		// blocking writes, for example, by specifying the algorithm and lock
+		// options in MySQL. It is used in case the parser attached to the file
+		// cannot classify statements.
+		Online func(stmt string) bool
+
+		// AddColumn is applied when a column was added to an existing table.
+		AddColumn ColumnHandler
+
+		// ModifyColumn is applied when a column of an existing table was modified.
+		ModifyColumn ColumnHandler
+
+		// AddIndex is applied when an index was added to an existing table.
+		AddIndex IndexHandler
+	}
+
+	// ColumnPass wraps the information needed
+	// by the handler below to diagnose columns.
+	ColumnPass struct {
+		*sqlcheck.Pass
+		Change *sqlcheck.Change // Change context (statement).
+		Table  *schema.Table    // The table this column belongs to.
+		From   *schema.Column   // The column before the change. Nil for added columns.
+		Column *schema.Column   // The diagnosed column.
+	}
+
+	// ColumnHandler allows provide custom diagnostic for specific column rules.
+	ColumnHandler func(*ColumnPass) ([]sqlcheck.Diagnostic, error)
+
+	// IndexPass wraps the information needed
+	// by the handler below to diagnose indexes.
+	IndexPass struct {
+		*sqlcheck.Pass
+		Change *sqlcheck.Change // Change context (statement).
+		Table  *schema.Table    // The table this index belongs to.
+		Index  *schema.Index    // The diagnosed index.
+	}
+
+	// IndexHandler allows provide custom diagnostic for specific index rules.
+	IndexHandler func(*IndexPass) ([]sqlcheck.Diagnostic, error)
+)
+
+// New creates a new blocking changes Analyzer with the given options.
+func New(r *schemahcl.Resource, h Handler) (*Analyzer, error) {
+	az := &Analyzer{Handler: h}
+	if r, ok := r.Resource(az.Name()); ok {
+		if err := r.As(&az.Options); err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: parsing blocking check options: %w", err)
+		}
+	}
+	return az, nil
+}
+
+// List of codes.
+var (
+	codeRewriteC  = sqlcheck.Code("BC101")
+	codeAddC      = sqlcheck.Code("BC102")
+	codeBlockingI = sqlcheck.Code("BC103")
+)
+
+// Name of the analyzer. Implements the sqlcheck.NamedAnalyzer interface.
+func (*Analyzer) Name() string {
+	return "blocking"
+}
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	var diags []sqlcheck.Diagnostic
+	for _, sc := range p.File.Changes {
+		if a.online(p.File, sc) {
+			continue
+		}
+		for _, c := range sc.Changes {
+			m, ok := c.(*schema.ModifyTable)
+			// Tables that were added in this file are empty.
+			if !ok || p.File.TableSpan(m.T)&sqlcheck.SpanAdded != 0 {
+				continue
+			}
+			for _, c := range m.Changes {
+				var (
+					d    []sqlcheck.Diagnostic
+					err  error
+					code string
+				)
+				switch c := c.(type) {
+				case *schema.AddColumn:
+					if a.AddColumn != nil {
+						code = codeAddC
+						d, err = a.AddColumn(&ColumnPass{Pass: p, Change: sc, Table: m.T, Column: c.C})
+					}
+				case *schema.ModifyColumn:
+					if a.ModifyColumn != nil && p.File.ColumnSpan(m.T, c.To)&sqlcheck.SpanAdded == 0 {
+						code = codeRewriteC
+						d, err = a.ModifyColumn(&ColumnPass{Pass: p, Change: sc, Table: m.T, From: c.From, Column: c.To})
+					}
+				case *schema.AddIndex:
+					if a.AddIndex != nil {
+						code = codeBlockingI
+						d, err = a.AddIndex(&IndexPass{Pass: p, Change: sc, Table: m.T, Index: c.I})
+					}
+				}
+				if err != nil {
+					return err
+				}
+				for i := range d {
+					// In case there is no driver-specific code.
+					if d[i].Code == "" {
+						d[i].Code = code
+					}
+				}
+				diags = append(diags, d...)
+			}
+		}
+	}
+	if len(diags) > 0 {
+		const reportText = "blocking changes detected"
+		p.Reporter.WriteReport(sqlcheck.Report{Text: reportText, Diagnostics: diags})
+		if sqlx.V(a.Error) {
+			return errors.New(reportText)
+		}
+	}
+	return nil
+}
+
+// online reports if the statement of the change is executed online.
+func (a *Analyzer) online(f *sqlcheck.File, c *sqlcheck.Change) bool {
+	// The parser used for parsing this file can classify the statement.
+	if p, ok := f.Parser.(interface {
+		OnlineStmt(string) (bool, error)
+	}); ok {
+		if online, err := p.OnlineStmt(c.Stmt.Text); err == nil {
+			return online
+		}
+	}
+	return a.Online != nil && a.Online(c.Stmt.Text)
+}
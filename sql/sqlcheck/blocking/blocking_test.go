@@ -0,0 +1,105 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package blocking_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/blocking"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		users  = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(schema.NewIntColumn("id", "int"))
+		pets = schema.NewTable("pets").
+			SetSchema(schema.New("test")).
+			AddColumns(schema.NewIntColumn("id", "int"))
+		addIndex = func(t *schema.Table, name string) *schema.ModifyTable {
+			return &schema.ModifyTable{T: t, Changes: []schema.Change{&schema.AddIndex{I: schema.NewIndex(name).AddColumns(t.Columns[0])}}}
+		}
+		pass = &sqlcheck.Pass{
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt:    &migrate.Stmt{Text: "CREATE INDEX i1 ON users (id)", Pos: 1},
+						Changes: schema.Changes{addIndex(users, "i1")},
+					},
+					{
+						Stmt:    &migrate.Stmt{Text: "CREATE INDEX ONLINE i2 ON users (id)", Pos: 2},
+						Changes: schema.Changes{addIndex(users, "i2")},
+					},
+					// Indexes of tables that were created in this file are skipped.
+					{
+						Stmt:    &migrate.Stmt{Text: "CREATE TABLE pets (id int)", Pos: 3},
+						Changes: schema.Changes{&schema.AddTable{T: pets}},
+					},
+					{
+						Stmt:    &migrate.Stmt{Text: "CREATE INDEX i3 ON pets (id)", Pos: 4},
+						Changes: schema.Changes{addIndex(pets, "i3")},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	az, err := blocking.New(nil, blocking.Handler{
+		Online: func(s string) bool {
+			return strings.Contains(s, "ONLINE")
+		},
+		AddIndex: func(p *blocking.IndexPass) ([]sqlcheck.Diagnostic, error) {
+			return []sqlcheck.Diagnostic{{Pos: p.Change.Stmt.Pos, Text: p.Index.Name}}, nil
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, az.Analyze(context.Background(), pass))
+	require.Equal(t, "blocking changes detected", report.Text)
+	require.Equal(t, []sqlcheck.Diagnostic{{Pos: 1, Code: "BC103", Text: "i1"}}, report.Diagnostics)
+
+	// The file parser classifies statements.
+	report = nil
+	pass.File.Parser = onlineParser{}
+	require.NoError(t, az.Analyze(context.Background(), pass))
+	require.Nil(t, report)
+
+	// Fail on diagnostics.
+	pass.File.Parser = nil
+	az, err = blocking.New(&schemahcl.Resource{
+		Children: []*schemahcl.Resource{{
+			Type:  "blocking",
+			Attrs: []*schemahcl.Attr{schemahcl.LitAttr("error", "true")},
+		}},
+	}, az.Handler)
+	require.NoError(t, err)
+	require.EqualError(t, az.Analyze(context.Background(), pass), "blocking changes detected")
+}
+
+type onlineParser struct{}
+
+func (onlineParser) OnlineStmt(string) (bool, error) {
+	return true, nil
+}
+
+type testFile struct {
+	name string
+	migrate.File
+}
+
+func (t testFile) Name() string {
+	return t.name
+}
@@ -63,17 +63,19 @@ func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
 						text = fmt.Sprintf("Dropping non-empty schema %q with %d tables", c.S.Name, n)
 					}
 					diags = append(diags, sqlcheck.Diagnostic{
-						Code: codeDropS,
-						Pos:  sc.Stmt.Pos,
-						Text: text,
+						Code:           codeDropS,
+						Pos:            sc.Stmt.Pos,
+						Text:           text,
+						SuggestedFixes: []sqlcheck.SuggestedFix{sqlcheck.NoLintFix(sc.Stmt.Pos, codeDropS)},
 					})
 				}
 			case *schema.DropTable:
 				if p.File.SchemaSpan(c.T.Schema) != sqlcheck.SpanDropped && p.File.TableSpan(c.T) != sqlcheck.SpanTemporary {
 					diags = append(diags, sqlcheck.Diagnostic{
-						Code: codeDropT,
-						Pos:  sc.Stmt.Pos,
-						Text: fmt.Sprintf("Dropping table %q", c.T.Name),
+						Code:           codeDropT,
+						Pos:            sc.Stmt.Pos,
+						Text:           fmt.Sprintf("Dropping table %q", c.T.Name),
+						SuggestedFixes: []sqlcheck.SuggestedFix{sqlcheck.NoLintFix(sc.Stmt.Pos, codeDropT)},
 					})
 				}
 			case *schema.ModifyTable:
@@ -84,9 +86,10 @@ func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
 					}
 					if g := (schema.GeneratedExpr{}); !sqlx.Has(d.C.Attrs, &g) || strings.ToUpper(g.Type) != "VIRTUAL" {
 						diags = append(diags, sqlcheck.Diagnostic{
-							Code: codeDropC,
-							Pos:  sc.Stmt.Pos,
-							Text: fmt.Sprintf("Dropping non-virtual column %q", d.C.Name),
+							Code:           codeDropC,
+							Pos:            sc.Stmt.Pos,
+							Text:           fmt.Sprintf("Dropping non-virtual column %q", d.C.Name),
+							SuggestedFixes: []sqlcheck.SuggestedFix{sqlcheck.NoLintFix(sc.Stmt.Pos, codeDropC)},
 						})
 					}
 				}
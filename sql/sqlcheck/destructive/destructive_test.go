@@ -75,6 +75,12 @@ func TestAnalyzer_DropTable(t *testing.T) {
 	require.Len(t, report.Diagnostics, 2)
 	require.Equal(t, `Dropping table "users"`, report.Diagnostics[0].Text)
 	require.Equal(t, `Dropping table "posts"`, report.Diagnostics[1].Text)
+	require.Equal(t, []sqlcheck.SuggestedFix{
+		{
+			Message:  "Add the atlas:nolint directive to ignore DS102",
+			TextEdit: &sqlcheck.TextEdit{NewText: "-- atlas:nolint DS102\n"},
+		},
+	}, report.Diagnostics[0].SuggestedFixes)
 }
 
 func TestAnalyzer_SkipTemporaryTable(t *testing.T) {
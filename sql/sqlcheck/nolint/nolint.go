@@ -0,0 +1,170 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package nolint provides an analyzer that enforces a policy on the atlas:nolint directives
+// of migration files, requiring each suppression to be justified and optionally to reference
+// a ticket, and a function for listing all suppressions that exist in a migration directory.
+package nolint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlcheck"
+)
+
+type (
+	// Analyzer checks that the atlas:nolint directives of the file are justified. For example:
+	//
+	//	-- atlas:nolint DS103 -- column is unused since v2 (OPS-123)
+	//
+	// The analyzer is enabled only if its block is defined in the lint configuration.
+	Analyzer struct {
+		sqlcheck.Options
+
+		// Ticket is an optional regular expression the justification must
+		// match, to reference an issue or a ticket. e.g. "[A-Z]+-[0-9]+".
+		Ticket string
+
+		enabled bool
+		ticket  *regexp.Regexp
+	}
+
+	// A Suppression describes an atlas:nolint directive of a statement.
+	Suppression struct {
+		File   string   `json:"File"`             // Name of the file.
+		Pos    int      `json:"Pos"`              // Position of the statement in the file.
+		Line   int      `json:"Line"`             // Line of the statement in the file.
+		Rules  []string `json:"Rules,omitempty"`  // Suppressed analyzers or codes. Empty means all.
+		Reason string   `json:"Reason,omitempty"` // Justification of the suppression.
+	}
+)
+
+// New creates a new nolint Analyzer with the given options.
+func New(r *schemahcl.Resource) (*Analyzer, error) {
+	az := &Analyzer{}
+	r, ok := r.Resource(az.Name())
+	if !ok {
+		return az, nil
+	}
+	if err := r.As(&az.Options); err != nil {
+		return nil, fmt.Errorf("sql/sqlcheck: parsing nolint check options: %w", err)
+	}
+	if a, ok := r.Attr("ticket"); ok {
+		t, err := a.String()
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: parsing nolint ticket option: %w", err)
+		}
+		az.Ticket = t
+	}
+	if az.Ticket != "" {
+		re, err := regexp.Compile(az.Ticket)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: compiling nolint ticket pattern: %w", err)
+		}
+		az.ticket = re
+	}
+	az.enabled = true
+	return az, nil
+}
+
+func init() {
+	sqlcheck.RegisterAnalyzer("nolint", func(r *schemahcl.Resource) (sqlcheck.Analyzer, error) {
+		return New(r)
+	})
+}
+
+// List of codes.
+var (
+	codeNoReason = sqlcheck.Code("NL101")
+	codeNoTicket = sqlcheck.Code("NL102")
+)
+
+// Name of the analyzer. Implements the sqlcheck.NamedAnalyzer interface.
+func (*Analyzer) Name() string {
+	return "nolint"
+}
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	if !a.enabled {
+		return nil
+	}
+	var diags []sqlcheck.Diagnostic
+	for _, c := range p.File.Changes {
+		for _, d := range c.Stmt.Directive("nolint") {
+			switch nl := sqlcheck.ParseNoLint(d); {
+			case nl.Reason == "":
+				diags = append(diags, sqlcheck.Diagnostic{
+					Pos:  c.Stmt.Pos,
+					Text: fmt.Sprintf("Directive %q is missing a justification", directive(nl)),
+					Code: codeNoReason,
+				})
+			case a.ticket != nil && !a.ticket.MatchString(nl.Reason):
+				diags = append(diags, sqlcheck.Diagnostic{
+					Pos:  c.Stmt.Pos,
+					Text: fmt.Sprintf("Justification of directive %q does not reference a ticket", directive(nl)),
+					Code: codeNoTicket,
+				})
+			}
+		}
+	}
+	if len(diags) > 0 {
+		const reportText = "unjustified lint suppressions detected"
+		p.Reporter.WriteReport(sqlcheck.Report{Text: reportText, Diagnostics: diags})
+		if sqlx.V(a.Error) {
+			return errors.New(reportText)
+		}
+	}
+	return nil
+}
+
+// Suppressions returns the atlas:nolint directives of all files in the directory.
+func Suppressions(dir migrate.Dir) ([]*Suppression, error) {
+	files, err := dir.Files()
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlcheck: reading migration directory: %w", err)
+	}
+	var ss []*Suppression
+	for _, f := range files {
+		stmts, err := fileStmts(f)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: scanning statements of file %q: %w", f.Name(), err)
+		}
+		for _, s := range stmts {
+			for _, d := range s.Directive("nolint") {
+				nl := sqlcheck.ParseNoLint(d)
+				ss = append(ss, &Suppression{
+					File:   f.Name(),
+					Pos:    s.Pos,
+					Line:   migrate.PositionOf(f.Bytes(), s.Pos).Line,
+					Rules:  nl.Rules,
+					Reason: nl.Reason,
+				})
+			}
+		}
+	}
+	return ss, nil
+}
+
+// fileStmts returns the statement declarations of the given file.
+func fileStmts(f migrate.File) ([]*migrate.Stmt, error) {
+	if s, ok := f.(interface {
+		StmtDecls() ([]*migrate.Stmt, error)
+	}); ok {
+		return s.StmtDecls()
+	}
+	return migrate.Stmts(string(f.Bytes()))
+}
+
+// directive returns the directive in its comment format, without the justification.
+func directive(nl *sqlcheck.NoLint) string {
+	return strings.TrimSpace("atlas:nolint " + strings.Join(nl.Rules, " "))
+}
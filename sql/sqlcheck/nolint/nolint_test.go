@@ -0,0 +1,108 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package nolint_test
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/specutil"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/nolint"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	stmts, err := migrate.Stmts(`-- atlas:nolint DS103
+ALTER TABLE users DROP COLUMN c;
+-- atlas:nolint -- table is recreated below
+DROP TABLE pets;
+-- atlas:nolint DS102 -- unused since v2 (OPS-123)
+DROP TABLE posts;
+CREATE TABLE pets;
+`)
+	require.NoError(t, err)
+	var (
+		reports []sqlcheck.Report
+		pass    = &sqlcheck.Pass{
+			Dev:  &sqlclient.Client{},
+			File: &sqlcheck.File{File: migrate.NewLocalFile("1.sql", nil)},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				reports = append(reports, r)
+			}),
+		}
+	)
+	for _, s := range stmts {
+		pass.File.Changes = append(pass.File.Changes, &sqlcheck.Change{Stmt: s})
+	}
+
+	// Analyzer is disabled by default.
+	az, err := nolint.New(&schemahcl.Resource{})
+	require.NoError(t, err)
+	require.NoError(t, az.Analyze(context.Background(), pass))
+	require.Empty(t, reports)
+
+	az, err = nolint.New(&schemahcl.Resource{
+		Children: []*schemahcl.Resource{
+			{Type: "nolint"},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, az.Analyze(context.Background(), pass))
+	require.Len(t, reports, 1)
+	require.Equal(t, "unjustified lint suppressions detected", reports[0].Text)
+	require.Equal(t, []sqlcheck.Diagnostic{
+		{Pos: stmts[0].Pos, Code: "NL101", Text: `Directive "atlas:nolint DS103" is missing a justification`},
+	}, reports[0].Diagnostics)
+
+	reports = nil
+	az, err = nolint.New(&schemahcl.Resource{
+		Children: []*schemahcl.Resource{
+			{
+				Type: "nolint",
+				Attrs: []*schemahcl.Attr{
+					specutil.StrAttr("ticket", "[A-Z]+-[0-9]+"),
+					specutil.BoolAttr("error", true),
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.EqualError(t, az.Analyze(context.Background(), pass), "unjustified lint suppressions detected")
+	require.Len(t, reports, 1)
+	require.Equal(t, []sqlcheck.Diagnostic{
+		{Pos: stmts[0].Pos, Code: "NL101", Text: `Directive "atlas:nolint DS103" is missing a justification`},
+		{Pos: stmts[1].Pos, Code: "NL102", Text: `Justification of directive "atlas:nolint" does not reference a ticket`},
+	}, reports[0].Diagnostics)
+
+	_, err = nolint.New(&schemahcl.Resource{
+		Children: []*schemahcl.Resource{
+			{
+				Type: "nolint",
+				Attrs: []*schemahcl.Attr{
+					specutil.StrAttr("ticket", "["),
+				},
+			},
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestSuppressions(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1.sql", []byte("CREATE TABLE users;\n-- atlas:nolint\nDROP TABLE pets;\n")))
+	require.NoError(t, d.WriteFile("2.sql", []byte("DROP TABLE users;\n\n-- atlas:nolint DS102 BC101 -- unused since v2 (OPS-123)\nDROP TABLE posts;\n")))
+	ss, err := nolint.Suppressions(d)
+	require.NoError(t, err)
+	require.Equal(t, []*nolint.Suppression{
+		{File: "1.sql", Pos: 36, Line: 3, Rules: []string{}},
+		{File: "2.sql", Pos: 76, Line: 4, Rules: []string{"DS102", "BC101"}, Reason: "unused since v2 (OPS-123)"},
+	}, ss)
+}
@@ -0,0 +1,162 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package pii provides an analyzer that gates destructive changes to columns holding
+// personally identifiable information (PII). A column holds PII if it carries the "pii"
+// annotation in its comment (see schema.AnnotatedComment), or if its name matches one
+// of the patterns configured in the lint block:
+//
+//	lint {
+//	  pii {
+//	    patterns = ["(?i)email", "(?i)^ssn$"]
+//	  }
+//	}
+//
+// Unlike other analyzers, diagnostics of this analyzer cannot be suppressed by the
+// atlas:nolint directive, and always fail the analysis, unless the changes were
+// explicitly approved (e.g. using the --approve-pii flag of 'atlas migrate lint').
+package pii
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+)
+
+// Analyzer checks for destructive changes to PII columns.
+type Analyzer struct {
+	// Patterns are optional regular expressions that classify
+	// the columns whose names match them as PII columns.
+	Patterns []string
+
+	// Approved indicates the destructive changes were explicitly approved.
+	// Diagnostics are still reported, but the analysis does not fail.
+	Approved bool
+
+	patterns []*regexp.Regexp
+}
+
+// New creates a new PII Analyzer with the given options.
+func New(r *schemahcl.Resource) (*Analyzer, error) {
+	az := &Analyzer{}
+	if r, ok := r.Resource(az.Name()); ok {
+		if a, ok := r.Attr("patterns"); ok {
+			ps, err := a.Strings()
+			if err != nil {
+				return nil, fmt.Errorf("sql/sqlcheck: parsing pii patterns option: %w", err)
+			}
+			az.Patterns = ps
+		}
+	}
+	for _, p := range az.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: compiling pii pattern %q: %w", p, err)
+		}
+		az.patterns = append(az.patterns, re)
+	}
+	return az, nil
+}
+
+func init() {
+	sqlcheck.RegisterAnalyzer("pii", func(r *schemahcl.Resource) (sqlcheck.Analyzer, error) {
+		return New(r)
+	})
+}
+
+// List of codes.
+var (
+	codeDropT   = sqlcheck.Code("PI101")
+	codeDropC   = sqlcheck.Code("PI102")
+	codeModifyC = sqlcheck.Code("PI103")
+)
+
+// Name of the analyzer. Implements the sqlcheck.NamedAnalyzer interface.
+func (*Analyzer) Name() string {
+	return "pii"
+}
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	var diags []sqlcheck.Diagnostic
+	for _, sc := range p.File.Changes {
+		for _, c := range sc.Changes {
+			switch c := c.(type) {
+			case *schema.DropTable:
+				if p.File.TableSpan(c.T) == sqlcheck.SpanTemporary {
+					continue
+				}
+				var names []string
+				for _, col := range c.T.Columns {
+					if a.isPII(col) {
+						names = append(names, strconv.Quote(col.Name))
+					}
+				}
+				if len(names) > 0 {
+					diags = append(diags, sqlcheck.Diagnostic{
+						Code: codeDropT,
+						Pos:  sc.Stmt.Pos,
+						Text: fmt.Sprintf("Dropping table %q with PII columns %s", c.T.Name, strings.Join(names, ", ")),
+					})
+				}
+			case *schema.ModifyTable:
+				for i := range c.Changes {
+					switch cc := c.Changes[i].(type) {
+					case *schema.DropColumn:
+						if a.isPII(cc.C) && p.File.ColumnSpan(c.T, cc.C) != sqlcheck.SpanTemporary {
+							diags = append(diags, sqlcheck.Diagnostic{
+								Code: codeDropC,
+								Pos:  sc.Stmt.Pos,
+								Text: fmt.Sprintf("Dropping PII column %q", cc.C.Name),
+							})
+						}
+					case *schema.ModifyColumn:
+						if a.isPII(cc.From) || a.isPII(cc.To) {
+							diags = append(diags, sqlcheck.Diagnostic{
+								Code: codeModifyC,
+								Pos:  sc.Stmt.Pos,
+								Text: fmt.Sprintf("Modifying PII column %q", cc.From.Name),
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+	if len(diags) == 0 {
+		return nil
+	}
+	if a.Approved {
+		p.Reporter.WriteReport(sqlcheck.Report{Text: "approved destructive changes to PII columns", Diagnostics: diags})
+		return nil
+	}
+	const reportText = "destructive changes to PII columns require explicit approval"
+	p.Reporter.WriteReport(sqlcheck.Report{Text: reportText, Diagnostics: diags})
+	return errors.New(reportText)
+}
+
+// isPII reports if the given column holds PII.
+func (a *Analyzer) isPII(c *schema.Column) bool {
+	var cm schema.Comment
+	if sqlx.Has(c.Attrs, &cm) {
+		// The annotation value can be a boolean, or a classification of the data (e.g. "email").
+		if _, annotations := cm.Split(); annotations["pii"] != "" && annotations["pii"] != "false" {
+			return true
+		}
+	}
+	for _, re := range a.patterns {
+		if re.MatchString(c.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package pii_test
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/specutil"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/pii"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewStringColumn("name", "text").AddAttrs(schema.AnnotatedComment("full name", map[string]string{"pii": "true"})),
+				schema.NewStringColumn("email", "text"),
+				schema.NewStringColumn("nickname", "text").AddAttrs(schema.AnnotatedComment("", map[string]string{"pii": "false"})),
+			)
+		pets = schema.NewTable("pets").
+			SetSchema(schema.New("test")).
+			AddColumns(schema.NewIntColumn("id", "int"))
+		reports []sqlcheck.Report
+		pass    = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{},
+			File: &sqlcheck.File{
+				File: migrate.NewLocalFile("1.sql", nil),
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: &migrate.Stmt{Pos: 1},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: schema.Changes{
+									&schema.DropColumn{C: users.Columns[1]},
+									&schema.DropColumn{C: users.Columns[3]},
+									&schema.ModifyColumn{From: users.Columns[2], To: schema.NewNullStringColumn("email", "text"), Change: schema.ChangeNull},
+								},
+							},
+						},
+					},
+					{
+						Stmt:    &migrate.Stmt{Pos: 2},
+						Changes: schema.Changes{&schema.DropTable{T: pets}},
+					},
+					{
+						Stmt:    &migrate.Stmt{Pos: 3},
+						Changes: schema.Changes{&schema.DropTable{T: users}},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				reports = append(reports, r)
+			}),
+		}
+	)
+
+	// Annotated columns only.
+	az, err := pii.New(&schemahcl.Resource{})
+	require.NoError(t, err)
+	require.EqualError(t, az.Analyze(context.Background(), pass), "destructive changes to PII columns require explicit approval")
+	require.Len(t, reports, 1)
+	require.Equal(t, []sqlcheck.Diagnostic{
+		{Pos: 1, Code: "PI102", Text: `Dropping PII column "name"`},
+		{Pos: 3, Code: "PI101", Text: `Dropping table "users" with PII columns "name"`},
+	}, reports[0].Diagnostics)
+
+	// Annotated columns and name patterns.
+	reports = nil
+	az, err = pii.New(&schemahcl.Resource{
+		Children: []*schemahcl.Resource{
+			{
+				Type:  "pii",
+				Attrs: []*schemahcl.Attr{specutil.ListAttr("patterns", `"(?i)e-?mail"`)},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Error(t, az.Analyze(context.Background(), pass))
+	require.Len(t, reports, 1)
+	require.Equal(t, []sqlcheck.Diagnostic{
+		{Pos: 1, Code: "PI102", Text: `Dropping PII column "name"`},
+		{Pos: 1, Code: "PI103", Text: `Modifying PII column "email"`},
+		{Pos: 3, Code: "PI101", Text: `Dropping table "users" with PII columns "name", "email"`},
+	}, reports[0].Diagnostics)
+
+	// Approved changes are reported, but do not fail the analysis.
+	reports = nil
+	az.Approved = true
+	require.NoError(t, az.Analyze(context.Background(), pass))
+	require.Len(t, reports, 1)
+	require.Equal(t, "approved destructive changes to PII columns", reports[0].Text)
+	require.Len(t, reports[0].Diagnostics, 3)
+
+	_, err = pii.New(&schemahcl.Resource{
+		Children: []*schemahcl.Resource{
+			{
+				Type:  "pii",
+				Attrs: []*schemahcl.Attr{specutil.ListAttr("patterns", `"("`)},
+			},
+		},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,347 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+type (
+	// RunOptions configures the analysis executed by Run.
+	RunOptions struct {
+		// Analyzers to run on the new files. If empty, the analyzers registered
+		// for the dev driver are used. Note that drivers register their analyzers
+		// on import. e.g. import _ "ariga.io/atlas/sql/mysql/mysqlcheck".
+		Analyzers []Analyzer
+
+		// Latest is the number of latest files in the directory that are considered
+		// new and analyzed. The rest of the files are executed on the dev database
+		// to bring it to the base point. If zero, all files are analyzed.
+		Latest int
+
+		// Target is used for evaluating the conditional directives of statements
+		// (e.g. atlas:only env=prod). Statements that do not match it are skipped.
+		// If nil, all statements are executed.
+		Target *migrate.Target
+
+		// Parser is attached to the analyzed files. See File.Parser for more info.
+		Parser any
+
+		// OnReport, if set, is called with each report as soon as it is written by an
+		// analyzer, allowing callers to stream diagnostics instead of waiting for Run to
+		// return. The FileReport describes the analyzed file, and its reports are still
+		// being populated when the function is called.
+		OnReport func(*FileReport, Report)
+	}
+
+	// A FileReport describes the analysis reports of a migration file.
+	FileReport struct {
+		Name    string   // Name of the file.
+		Text    string   // Contents of the file.
+		Reports []Report // Reports of the analyzers.
+		Error   string   // Errors returned by the analyzers, if any.
+	}
+)
+
+// Run analyzes the new migration files of the given directory using the dev database. The base
+// files are executed on the dev database, and each statement of the new files is executed and
+// inspected to compute the changes it describes, before running the analyzers on the files.
+// Diagnostics that were suppressed by the atlas:nolint directive are omitted from the reports.
+// The dev database is restored to its original state once the analysis is done. Reports can be
+// streamed while files are analyzed using RunOptions.OnReport.
+//
+//	reports, err := sqlcheck.Run(ctx, dir, dev, &sqlcheck.RunOptions{Latest: 1})
+//	for _, r := range reports {
+//		for _, d := range r.Diagnostics() {
+//			fmt.Println(r.Name, r.Position(d.Pos), d.Code, d.Text)
+//		}
+//	}
+//
+// Or, to stream the diagnostics as they are reported:
+//
+//	_, err := sqlcheck.Run(ctx, dir, dev, &sqlcheck.RunOptions{
+//		OnReport: func(f *sqlcheck.FileReport, r sqlcheck.Report) {
+//			for _, d := range r.Diagnostics {
+//				fmt.Println(f.Name, f.Position(d.Pos), d.Code, d.Text)
+//			}
+//		},
+//	})
+func Run(ctx context.Context, dir migrate.Dir, dev *sqlclient.Client, opts *RunOptions) ([]*FileReport, error) {
+	if opts == nil {
+		opts = &RunOptions{}
+	}
+	switch err := migrate.Validate(dir); {
+	case errors.Is(err, migrate.ErrChecksumNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("sql/sqlcheck: validating migration directory: %w", err)
+	}
+	azs := opts.Analyzers
+	if len(azs) == 0 {
+		var err error
+		if azs, err = AnalyzerFor(dev.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	files, err := dir.Files()
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlcheck: reading migration directory: %w", err)
+	}
+	var base []migrate.File
+	if opts.Latest > 0 && opts.Latest < len(files) {
+		base, files = files[:len(files)-opts.Latest], files[len(files)-opts.Latest:]
+	}
+	l := &loader{dev: dev, opts: opts}
+	checked, err := l.load(ctx, base, files)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]*FileReport, 0, len(checked))
+	for _, f := range checked {
+		// Stop between files if the caller stopped listening.
+		if err := ctx.Err(); err != nil {
+			return reports, err
+		}
+		var (
+			es []string
+			nl = nolintRules(f)
+			fr = &FileReport{Name: f.Name(), Text: string(f.Bytes())}
+			rw = ReportWriter(fr)
+		)
+		if opts.OnReport != nil {
+			rw = ReportWriterFunc(func(r Report) {
+				fr.WriteReport(r)
+				opts.OnReport(fr, r)
+			})
+		}
+		for _, az := range azs {
+			if err := az.Analyze(ctx, &Pass{
+				File:     f,
+				Dev:      dev,
+				Reporter: nl.reporterFor(rw, az),
+			}); err != nil && !nl.skipped {
+				es = append(es, err.Error())
+			}
+		}
+		fr.Error = strings.Join(es, "; ")
+		reports = append(reports, fr)
+	}
+	return reports, nil
+}
+
+// WriteReport implements ReportWriter.
+func (f *FileReport) WriteReport(r Report) {
+	f.Reports = append(f.Reports, r)
+}
+
+// Diagnostics returns the diagnostics of all reports of the file.
+func (f *FileReport) Diagnostics() []Diagnostic {
+	var ds []Diagnostic
+	for _, r := range f.Reports {
+		ds = append(ds, r.Diagnostics...)
+	}
+	return ds
+}
+
+// Position returns the position of the given byte offset (e.g. Diagnostic.Pos) in the file.
+func (f *FileReport) Position(off int) migrate.Position {
+	return migrate.PositionOf([]byte(f.Text), off)
+}
+
+// loader loads the changes of migration files using a dev database.
+type loader struct {
+	dev  *sqlclient.Client
+	opts *RunOptions
+}
+
+// load executes the base files on the dev database, and computes the changes
+// of each statement of the given files. The dev database is restored at the end.
+func (l *loader) load(ctx context.Context, base, files []migrate.File) (_ []*File, err error) {
+	if lk, ok := l.dev.Driver.(schema.Locker); ok {
+		name := "atlas_lint"
+		if l.dev.URL != nil && l.dev.URL.Schema != "" {
+			name = fmt.Sprintf("%s_%s", name, l.dev.URL.Schema)
+		}
+		unlock, err := lk.Lock(ctx, name, 0)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: acquiring database lock: %w", err)
+		}
+		defer unlock()
+	}
+	snap, ok := l.dev.Driver.(migrate.Snapshoter)
+	if !ok {
+		return nil, errors.New("sql/sqlcheck: driver does not implement migrate.Snapshoter")
+	}
+	restore, err := snap.Snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlcheck: taking database snapshot: %w", err)
+	}
+	defer func() {
+		if err2 := restore(ctx); err2 != nil {
+			if err != nil {
+				err2 = fmt.Errorf("%w: %v", err, err2)
+			}
+			err = err2
+		}
+	}()
+	// Checkpoint files are executed only on new databases, and
+	// represent the state of all files that precede them.
+	fresh := len(base) == 0
+	for _, f := range migrate.FilesFromCheckpoint(base) {
+		stmts, err := l.stmts(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range stmts {
+			if _, err := l.dev.ExecContext(ctx, s.Text); err != nil {
+				return nil, fmt.Errorf("sql/sqlcheck: executing statement %q of file %q: %w", s.Text, f.Name(), err)
+			}
+		}
+	}
+	current, err := l.inspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checked := make([]*File, len(files))
+	for i, f := range files {
+		checked[i] = &File{File: f, Parser: l.opts.Parser}
+		if migrate.IsCheckpoint(f) && (!fresh || i > 0) {
+			continue
+		}
+		stmts, err := l.stmts(f)
+		if err != nil {
+			return nil, err
+		}
+		start := current
+		for _, s := range stmts {
+			if _, err := l.dev.ExecContext(ctx, s.Text); err != nil {
+				return nil, fmt.Errorf("sql/sqlcheck: executing statement %q of file %q: %w", s.Text, f.Name(), err)
+			}
+			target, err := l.inspect(ctx)
+			if err != nil {
+				return nil, err
+			}
+			changes, err := l.dev.RealmDiff(current, target)
+			if err != nil {
+				return nil, err
+			}
+			current = target
+			checked[i].Changes = append(checked[i].Changes, &Change{Stmt: s, Changes: changes})
+		}
+		if checked[i].Sum, err = l.dev.RealmDiff(start, current); err != nil {
+			return nil, err
+		}
+	}
+	return checked, nil
+}
+
+// stmts returns the statements of the given file that should be executed.
+func (l *loader) stmts(f migrate.File) ([]*migrate.Stmt, error) {
+	var (
+		stmts []*migrate.Stmt
+		err   error
+	)
+	if s, ok := f.(interface {
+		StmtDecls() ([]*migrate.Stmt, error)
+	}); ok {
+		stmts, err = s.StmtDecls()
+	} else {
+		stmts, err = migrate.Stmts(string(f.Bytes()))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlcheck: scanning statements of file %q: %w", f.Name(), err)
+	}
+	if l.opts.Target == nil {
+		return stmts, nil
+	}
+	matched := make([]*migrate.Stmt, 0, len(stmts))
+	for _, s := range stmts {
+		ok, err := l.opts.Target.Match(s)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: file %q: %w", f.Name(), err)
+		}
+		if ok {
+			matched = append(matched, s)
+		}
+	}
+	return matched, nil
+}
+
+// inspect the realm and filter by schema if we are connected to one.
+func (l *loader) inspect(ctx context.Context) (*schema.Realm, error) {
+	opts := &schema.InspectRealmOption{}
+	if l.dev.URL != nil && l.dev.URL.Schema != "" {
+		opts.Schemas = append(opts.Schemas, l.dev.URL.Schema)
+	}
+	return l.dev.InspectRealm(ctx, opts)
+}
+
+// nolintRules returns the atlas:nolint directives of the file statements.
+func nolintRules(f *File) *skipRules {
+	s := &skipRules{pos2rules: make(map[int][]string)}
+	for _, c := range f.Changes {
+		for _, d := range c.Stmt.Directive("nolint") {
+			rules := ParseNoLint(d).Rules
+			if len(rules) == 0 {
+				rules = []string{""}
+			}
+			s.pos2rules[c.Stmt.Pos] = append(s.pos2rules[c.Stmt.Pos], rules...)
+		}
+	}
+	return s
+}
+
+type skipRules struct {
+	pos2rules map[int][]string // statement positions to rules
+	skipped   bool             // last one skipped
+}
+
+func (s *skipRules) reporterFor(rw ReportWriter, az Analyzer) ReportWriter {
+	return ReportWriterFunc(func(r Report) {
+		var (
+			ds     = make([]Diagnostic, 0, len(r.Diagnostics))
+			az, ok = az.(NamedAnalyzer)
+		)
+		for _, d := range r.Diagnostics {
+			switch rules := s.pos2rules[d.Pos]; {
+			// Diagnostics of the nolint analyzer report on the directives
+			// themselves, and therefore cannot be suppressed by them.
+			case ok && az.Name() == "nolint":
+				ds = append(ds, d)
+			// Changes to PII columns can be approved only explicitly.
+			case ok && az.Name() == "pii":
+				ds = append(ds, d)
+			case
+				// A directive without specific classes/codes
+				// (e.g. atlas:nolint) ignore all diagnostics.
+				len(rules) == 1 && rules[0] == "",
+				// Match a specific code/diagnostic. e.g. atlas:nolint DS101.
+				contains(rules, d.Code),
+				// Skip the entire analyzer (class of changes).
+				ok && contains(rules, az.Name()):
+			default:
+				ds = append(ds, d)
+			}
+		}
+		if s.skipped = len(ds) == 0; !s.skipped {
+			rw.WriteReport(Report{Text: r.Text, Diagnostics: ds})
+		}
+	})
+}
+
+func contains(s []string, v string) bool {
+	for i := range s {
+		if s[i] == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlcheck_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/destructive"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1.sql", []byte("CREATE TABLE users;\nCREATE TABLE pets;\n")))
+	require.NoError(t, d.WriteFile("2.sql", []byte("DROP TABLE users;\n\n-- atlas:nolint DS102\nDROP TABLE pets;\n")))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+
+	var (
+		drv = &mockDriver{}
+		dev = &sqlclient.Client{Name: "mock", Driver: drv}
+		ds  = destructive.Analyzer{}
+	)
+	reports, err := sqlcheck.Run(context.Background(), d, dev, &sqlcheck.RunOptions{
+		Analyzers: []sqlcheck.Analyzer{&ds},
+		Latest:    1,
+	})
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Equal(t, "2.sql", reports[0].Name)
+	require.Empty(t, reports[0].Error)
+	// The second DROP statement is ignored by the nolint directive.
+	diags := reports[0].Diagnostics()
+	require.Equal(t, []sqlcheck.Diagnostic{
+		{
+			Pos:  0,
+			Code: "DS102",
+			Text: `Dropping table "users"`,
+			SuggestedFixes: []sqlcheck.SuggestedFix{
+				{
+					Message:  "Add the atlas:nolint directive to ignore DS102",
+					TextEdit: &sqlcheck.TextEdit{NewText: "-- atlas:nolint DS102\n"},
+				},
+			},
+		},
+	}, diags)
+	require.Equal(t, migrate.Position{Offset: 0, Line: 1, Column: 1}, reports[0].Position(diags[0].Pos))
+	require.Equal(t, migrate.Position{Offset: 41, Line: 4, Column: 1}, reports[0].Position(41))
+	// Dev database is restored.
+	require.True(t, drv.restored)
+	require.Empty(t, drv.tables)
+	require.Equal(t, []string{"CREATE TABLE users;", "CREATE TABLE pets;", "DROP TABLE users;", "DROP TABLE pets;"}, drv.executed)
+
+	// Analyze all files.
+	drv.executed = nil
+	reports, err = sqlcheck.Run(context.Background(), d, dev, &sqlcheck.RunOptions{
+		Analyzers: []sqlcheck.Analyzer{&ds},
+	})
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	require.Empty(t, reports[0].Reports)
+	require.Len(t, reports[1].Diagnostics(), 1)
+
+	// Reports are streamed while files are analyzed.
+	var streamed []string
+	reports, err = sqlcheck.Run(context.Background(), d, dev, &sqlcheck.RunOptions{
+		Analyzers: []sqlcheck.Analyzer{&ds},
+		OnReport: func(f *sqlcheck.FileReport, r sqlcheck.Report) {
+			require.Len(t, f.Reports, 1)
+			for _, d := range r.Diagnostics {
+				streamed = append(streamed, fmt.Sprintf("%s:%s: %s", f.Name, f.Position(d.Pos), d.Code))
+			}
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"2.sql:1:1: DS102"}, streamed)
+	require.Len(t, reports[1].Diagnostics(), 1)
+
+	// Analysis stops when the context is canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = sqlcheck.Run(ctx, d, dev, &sqlcheck.RunOptions{
+		Analyzers: []sqlcheck.Analyzer{&ds},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Statement failures are reported.
+	require.NoError(t, d.WriteFile("3.sql", []byte("DROP TABLE users;\n")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	_, err = sqlcheck.Run(context.Background(), d, dev, &sqlcheck.RunOptions{
+		Analyzers: []sqlcheck.Analyzer{&ds},
+		Latest:    1,
+	})
+	require.EqualError(t, err, `sql/sqlcheck: executing statement "DROP TABLE users;" of file "3.sql": table "users" does not exist`)
+	require.Empty(t, drv.tables)
+
+	// Directory integrity is checked.
+	require.NoError(t, d.WriteFile("4.sql", []byte("CREATE TABLE users;\n")))
+	_, err = sqlcheck.Run(context.Background(), d, dev, nil)
+	require.ErrorIs(t, err, migrate.ErrChecksumMismatch)
+}
+
+// mockDriver is a migrate.Driver that manages a list of tables.
+type mockDriver struct {
+	migrate.Driver
+	tables   []string
+	executed []string
+	restored bool
+}
+
+func (d *mockDriver) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	d.executed = append(d.executed, query)
+	query = strings.TrimSuffix(query, ";")
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE "):
+		d.tables = append(d.tables, strings.TrimPrefix(query, "CREATE TABLE "))
+	case strings.HasPrefix(query, "DROP TABLE "):
+		name := strings.TrimPrefix(query, "DROP TABLE ")
+		for i := range d.tables {
+			if d.tables[i] == name {
+				d.tables = append(d.tables[:i], d.tables[i+1:]...)
+				return nil, nil
+			}
+		}
+		return nil, fmt.Errorf("table %q does not exist", name)
+	}
+	return nil, nil
+}
+
+func (d *mockDriver) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
+	s := schema.New("main")
+	for _, t := range d.tables {
+		s.AddTables(schema.NewTable(t))
+	}
+	return schema.NewRealm(s), nil
+}
+
+func (d *mockDriver) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
+	var changes []schema.Change
+	for _, t := range from.Schemas[0].Tables {
+		if _, ok := to.Schemas[0].Table(t.Name); !ok {
+			changes = append(changes, &schema.DropTable{T: t})
+		}
+	}
+	for _, t := range to.Schemas[0].Tables {
+		if _, ok := from.Schemas[0].Table(t.Name); !ok {
+			changes = append(changes, &schema.AddTable{T: t})
+		}
+	}
+	return changes, nil
+}
+
+func (d *mockDriver) Snapshot(context.Context) (migrate.RestoreFunc, error) {
+	d.restored = false
+	return func(context.Context) error {
+		d.tables, d.restored = nil, true
+		return nil
+	}, nil
+}
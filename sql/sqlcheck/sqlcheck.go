@@ -12,6 +12,9 @@ package sqlcheck
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"ariga.io/atlas/schemahcl"
@@ -83,9 +86,25 @@ type (
 
 	// A Diagnostic is a text associated with a specific position of a statement in a file.
 	Diagnostic struct {
-		Pos  int    // Diagnostic position.
-		Text string // Diagnostic text.
-		Code string // Code describes the check. For example, DS101
+		Pos            int            // Diagnostic position.
+		Text           string         // Diagnostic text.
+		Code           string         // Code describes the check. For example, DS101
+		SuggestedFixes []SuggestedFix `json:",omitempty"` // Fixes for this diagnostic.
+	}
+
+	// A SuggestedFix is a change that can be applied to
+	// the migration file to resolve a diagnostic.
+	SuggestedFix struct {
+		Message  string    // Message describing the fix.
+		TextEdit *TextEdit `json:",omitempty"` // Optional text edit.
+	}
+
+	// A TextEdit replaces the text of the file in the range [Pos, End)
+	// with NewText. An empty range (Pos == End) inserts the text at Pos.
+	TextEdit struct {
+		Pos     int    // Start position of the edit.
+		End     int    // End position of the edit.
+		NewText string // Text to replace the range with.
 	}
 
 	// ReportWriter represents a writer for analysis reports.
@@ -249,6 +268,37 @@ func (f *File) tableSpan(t *schema.Table) *tableSpan {
 	return f.spans[t.Schema.Name].tables[t.Name]
 }
 
+// NoLintFix returns a SuggestedFix that suppresses the diagnostics with the given
+// code, by adding the atlas:nolint directive above the statement at pos.
+func NoLintFix(pos int, code string) SuggestedFix {
+	return SuggestedFix{
+		Message: fmt.Sprintf("Add the atlas:nolint directive to ignore %s", code),
+		TextEdit: &TextEdit{
+			Pos:     pos,
+			End:     pos,
+			NewText: fmt.Sprintf("-- atlas:nolint %s\n", code),
+		},
+	}
+}
+
+// A NoLint describes the arguments of an atlas:nolint directive.
+type NoLint struct {
+	Rules  []string // Suppressed analyzers or codes. Empty means all diagnostics.
+	Reason string   // Justification of the suppression, if provided.
+}
+
+// ParseNoLint parses the arguments of an atlas:nolint directive. The suppressed
+// rules can be followed by a justification, separated from them by "--":
+//
+//	-- atlas:nolint DS103 -- column is unused since v2 (OPS-123)
+func ParseNoLint(args string) *NoLint {
+	rules, reason, _ := strings.Cut(args, "--")
+	return &NoLint{
+		Rules:  strings.Fields(rules),
+		Reason: strings.TrimSpace(reason),
+	}
+}
+
 // codes registry
 var codes sync.Map
 
@@ -270,12 +320,54 @@ func Register(name string, f func(*schemahcl.Resource) ([]Analyzer, error)) {
 	drivers.Store(name, f)
 }
 
+// custom analyzers, registered by name.
+var custom sync.Map
+
+// RegisterAnalyzer allows registering a constructor function for a custom analyzer,
+// such as an organization's naming rules, that runs for all drivers after the analyzers
+// of the driver. Similar to database drivers, custom analyzers are compiled into the
+// program (e.g. a custom build of the Atlas CLI) by importing the package that registers
+// them in its init function:
+//
+//	func init() {
+//		sqlcheck.RegisterAnalyzer("naming", func(r *schemahcl.Resource) (sqlcheck.Analyzer, error) {
+//			return naming.New(r)
+//		})
+//	}
+//
+// The constructor is called with the lint resource (e.g. the "lint" block of the project
+// file), and the options of the analyzer are expected to be defined in its nested block
+// with the same name. RegisterAnalyzer panics if it is called twice for the same name.
+func RegisterAnalyzer(name string, f func(*schemahcl.Resource) (Analyzer, error)) {
+	if _, loaded := custom.LoadOrStore(name, f); loaded {
+		panic("sqlcheck: RegisterAnalyzer called twice for " + name)
+	}
+}
+
 // AnalyzerFor instantiates a new Analyzer from the given HCL resource
-// based on the registered constructor function.
+// based on the registered constructor function. Custom analyzers are
+// appended to the driver analyzers, ordered by their name.
 func AnalyzerFor(name string, r *schemahcl.Resource) ([]Analyzer, error) {
-	f, ok := drivers.Load(name)
-	if ok {
-		return f.(func(*schemahcl.Resource) ([]Analyzer, error))(r)
+	var azs []Analyzer
+	if f, ok := drivers.Load(name); ok {
+		var err error
+		if azs, err = f.(func(*schemahcl.Resource) ([]Analyzer, error))(r); err != nil {
+			return nil, err
+		}
+	}
+	var names []string
+	custom.Range(func(k, _ any) bool {
+		names = append(names, k.(string))
+		return true
+	})
+	sort.Strings(names)
+	for _, n := range names {
+		f, _ := custom.Load(n)
+		az, err := f.(func(*schemahcl.Resource) (Analyzer, error))(r)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: creating %q analyzer: %w", n, err)
+		}
+		azs = append(azs, az)
 	}
-	return nil, nil
+	return azs, nil
 }
@@ -0,0 +1,69 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlcheck_test
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/sqlcheck"
+
+	"github.com/stretchr/testify/require"
+)
+
+type namingAnalyzer struct {
+	Prefix string `spec:"prefix"`
+}
+
+func (*namingAnalyzer) Analyze(context.Context, *sqlcheck.Pass) error { return nil }
+
+func TestRegisterAnalyzer(t *testing.T) {
+	sqlcheck.RegisterAnalyzer("naming", func(r *schemahcl.Resource) (sqlcheck.Analyzer, error) {
+		az := &namingAnalyzer{Prefix: "tbl_"}
+		if r, ok := r.Resource("naming"); ok {
+			if err := r.As(az); err != nil {
+				return nil, err
+			}
+		}
+		return az, nil
+	})
+	require.PanicsWithValue(t, "sqlcheck: RegisterAnalyzer called twice for naming", func() {
+		sqlcheck.RegisterAnalyzer("naming", nil)
+	})
+
+	// Custom analyzers are used for all drivers.
+	azs, err := sqlcheck.AnalyzerFor("unknown", nil)
+	require.NoError(t, err)
+	require.Equal(t, []sqlcheck.Analyzer{&namingAnalyzer{Prefix: "tbl_"}}, azs)
+
+	lint := &schemahcl.Resource{
+		Children: []*schemahcl.Resource{
+			{Type: "naming", Attrs: []*schemahcl.Attr{{K: "prefix", V: &schemahcl.LiteralValue{V: `"t_"`}}}},
+		},
+	}
+	azs, err = sqlcheck.AnalyzerFor("unknown", lint)
+	require.NoError(t, err)
+	require.Equal(t, []sqlcheck.Analyzer{&namingAnalyzer{Prefix: "t_"}}, azs)
+
+	// Custom analyzers are appended to the driver analyzers.
+	sqlcheck.Register("custom_test", func(*schemahcl.Resource) ([]sqlcheck.Analyzer, error) {
+		return []sqlcheck.Analyzer{sqlcheck.Analyzers{}}, nil
+	})
+	azs, err = sqlcheck.AnalyzerFor("custom_test", nil)
+	require.NoError(t, err)
+	require.Equal(t, []sqlcheck.Analyzer{sqlcheck.Analyzers{}, &namingAnalyzer{Prefix: "tbl_"}}, azs)
+
+	lint.Children[0].Attrs[0] = &schemahcl.Attr{K: "prefix", V: &schemahcl.ListValue{}}
+	_, err = sqlcheck.AnalyzerFor("unknown", lint)
+	require.ErrorContains(t, err, `sql/sqlcheck: creating "naming" analyzer:`)
+}
+
+func TestParseNoLint(t *testing.T) {
+	require.Equal(t, &sqlcheck.NoLint{Rules: []string{}}, sqlcheck.ParseNoLint(""))
+	require.Equal(t, &sqlcheck.NoLint{Rules: []string{"DS101", "destructive"}}, sqlcheck.ParseNoLint("DS101  destructive"))
+	require.Equal(t, &sqlcheck.NoLint{Rules: []string{}, Reason: "recreated below"}, sqlcheck.ParseNoLint("-- recreated below"))
+	require.Equal(t, &sqlcheck.NoLint{Rules: []string{"DS103"}, Reason: "unused since v2 -- OPS-123"}, sqlcheck.ParseNoLint("DS103 -- unused since v2 -- OPS-123"))
+}
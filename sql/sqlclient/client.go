@@ -55,6 +55,15 @@ type (
 		Tx *sql.Tx
 	}
 
+	// ConnClient is returned by calling Client.Conn. It behaves the same as Client,
+	// but executes all operations on a single database connection.
+	ConnClient struct {
+		*Client
+
+		// The connection this Client is bound to.
+		Conn *sql.Conn
+	}
+
 	// URL extends the standard url.URL with additional
 	// connection information attached by the Opener (if any).
 	URL struct {
@@ -81,11 +90,47 @@ func (c *Client) Tx(ctx context.Context, opts *sql.TxOptions) (*TxClient, error)
 	if err != nil {
 		return nil, fmt.Errorf("sql/sqlclient: opening atlas driver: %w", err)
 	}
+	// Drivers that hold connection state (e.g. collected server notices)
+	// share it with the driver opened for the transaction.
+	if s, ok := c.Driver.(interface{ ShareTx(migrate.Driver) }); ok {
+		s.ShareTx(drv)
+	}
 	ic := *c
 	ic.Driver = drv
 	return &TxClient{Client: &ic, Tx: tx}, nil
 }
 
+// Conn returns a client that is bound to a single connection. It is useful for
+// executing statements that rely on the session state of the connection, such as
+// reading the warnings of the last executed statement.
+func (c *Client) Conn(ctx context.Context) (*ConnClient, error) {
+	if c.openDriver == nil {
+		return nil, errors.New("sql/sqlclient: unexpected driver opener: <nil>")
+	}
+	conn, err := c.DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlclient: obtaining connection: %w", err)
+	}
+	drv, err := c.openDriver(conn)
+	if err != nil {
+		if cerr := conn.Close(); cerr != nil {
+			err = fmt.Errorf("%w: %v", err, cerr)
+		}
+		return nil, fmt.Errorf("sql/sqlclient: opening atlas driver: %w", err)
+	}
+	if s, ok := c.Driver.(interface{ ShareTx(migrate.Driver) }); ok {
+		s.ShareTx(drv)
+	}
+	ic := *c
+	ic.Driver = drv
+	return &ConnClient{Client: &ic, Conn: conn}, nil
+}
+
+// Close returns the connection to the pool of the client.
+func (c *ConnClient) Close() error {
+	return c.Conn.Close()
+}
+
 // Commit the transaction.
 func (c *TxClient) Commit() error {
 	return c.Tx.Commit()
@@ -159,7 +204,9 @@ var drivers sync.Map
 type (
 	// openOptions holds additional configuration values for opening a Client.
 	openOptions struct {
-		schema *string
+		schema  *string
+		session []string
+		hooks   []ExecHook
 	}
 
 	// OpenOption allows to configure a openOptions using functional arguments.
@@ -205,6 +252,14 @@ func OpenURL(ctx context.Context, u *url.URL, opts ...OpenOption) (*Client, erro
 	if client.URL == nil {
 		client.URL = v.(*driver).parser.ParseURL(u)
 	}
+	if len(cfg.session) > 0 || len(cfg.hooks) > 0 {
+		if err := client.wrapConn(ctx, cfg); err != nil {
+			if cerr := client.Close(); cerr != nil {
+				err = fmt.Errorf("%w: %v", err, cerr)
+			}
+			return nil, err
+		}
+	}
 	return client, nil
 }
 
@@ -217,6 +272,28 @@ func OpenSchema(s string) OpenOption {
 	}
 }
 
+// OpenSession configures the client to execute the given statements on every
+// connection it opens, before it is used. For example, to set the role or the
+// search path of the session. Note, statements are executed on each connection
+// of the pool, and their effect lasts as long as the connection is open.
+func OpenSession(stmts ...string) OpenOption {
+	return func(c *openOptions) error {
+		c.session = append(c.session, stmts...)
+		return nil
+	}
+}
+
+// OpenExecHook configures the client to call the given hook after every statement
+// executed on its connections using ExecContext, including statements executed in
+// transactions and session statements. Queries (e.g. inspection queries) are not
+// passed to the hook. An error returned by the hook fails the execution.
+func OpenExecHook(h ExecHook) OpenOption {
+	return func(c *openOptions) error {
+		c.hooks = append(c.hooks, h)
+		return nil
+	}
+}
+
 type (
 	registerOptions struct {
 		openDriver func(schema.ExecQuerier) (migrate.Driver, error)
@@ -7,6 +7,7 @@ package sqlclient_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"net/url"
 	"testing"
 
@@ -118,6 +119,37 @@ func TestClient_Tx(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestClient_Conn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectExec("SET @a = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var bound []schema.ExecQuerier
+	sqlclient.Register(
+		"conn",
+		sqlclient.OpenerFunc(func(context.Context, *url.URL) (*sqlclient.Client, error) {
+			return &sqlclient.Client{Name: "conn", DB: db, Driver: &mockDriver{db: db}}, nil
+		}),
+		sqlclient.RegisterDriverOpener(func(db schema.ExecQuerier) (migrate.Driver, error) {
+			bound = append(bound, db)
+			return &mockDriver{db: db}, nil
+		}),
+	)
+	c, err := sqlclient.Open(context.Background(), "conn://")
+	require.NoError(t, err)
+	conn, err := c.Conn(context.Background())
+	require.NoError(t, err)
+	require.Len(t, bound, 1)
+	require.Equal(t, conn.Conn, bound[0], "driver is bound to the connection")
+	_, err = conn.ExecContext(context.Background(), "SET @a = 1")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	// Closing the connection does not close the client.
+	require.NoError(t, c.DB.PingContext(context.Background()))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 type mockDriver struct {
 	migrate.Driver
 	db schema.ExecQuerier
@@ -126,3 +158,93 @@ type mockDriver struct {
 func (m *mockDriver) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	return m.db.ExecContext(ctx, query, args...)
 }
+
+func TestOpenSession(t *testing.T) {
+	db, mock, err := sqlmock.NewWithDSN("session")
+	require.NoError(t, err)
+	sqlclient.Register(
+		"session",
+		sqlclient.OpenerFunc(func(context.Context, *url.URL) (*sqlclient.Client, error) {
+			return &sqlclient.Client{Name: "session", DB: db, URL: &sqlclient.URL{DSN: "session"}, Driver: &mockDriver{db: db}}, nil
+		}),
+		sqlclient.RegisterDriverOpener(func(db schema.ExecQuerier) (migrate.Driver, error) {
+			return &mockDriver{db: db}, nil
+		}),
+	)
+	mock.ExpectExec("SET ROLE migrator").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET search_path TO app").WillReturnResult(sqlmock.NewResult(0, 0))
+	// The connection opened by the driver is closed.
+	mock.ExpectClose()
+	mock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	c, err := sqlclient.Open(context.Background(), "session://", sqlclient.OpenSession("SET ROLE migrator", "SET search_path TO app"))
+	require.NoError(t, err)
+	require.False(t, c.DB == db, "connection should be replaced")
+	_, err = c.ExecContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// Statement errors are reported on open.
+	mock.ExpectExec("SET ROLE unknown").WillReturnError(errors.New("role does not exist"))
+	mock.ExpectClose()
+	_, err = sqlclient.Open(context.Background(), "session://", sqlclient.OpenSession("SET ROLE unknown"))
+	require.EqualError(t, err, `sql/sqlclient: executing session statement "SET ROLE unknown": role does not exist`)
+	mock.ExpectClose()
+	require.NoError(t, c.Close())
+}
+
+func TestOpenExecHook(t *testing.T) {
+	db, mock, err := sqlmock.NewWithDSN("hook")
+	require.NoError(t, err)
+	sqlclient.Register(
+		"hook",
+		sqlclient.OpenerFunc(func(context.Context, *url.URL) (*sqlclient.Client, error) {
+			return &sqlclient.Client{Name: "hook", DB: db, URL: &sqlclient.URL{DSN: "hook"}, Driver: &mockDriver{db: db}}, nil
+		}),
+		sqlclient.RegisterDriverOpener(func(db schema.ExecQuerier) (migrate.Driver, error) {
+			return &mockDriver{db: db}, nil
+		}),
+	)
+	var events []*sqlclient.ExecEvent
+	hook := sqlclient.OpenExecHook(func(_ context.Context, e *sqlclient.ExecEvent) error {
+		events = append(events, e)
+		if e.Stmt == "DROP TABLE `audit`" {
+			return errors.New("audit is required")
+		}
+		return nil
+	})
+	mock.ExpectExec("SET ROLE migrator").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectClose()
+	c, err := sqlclient.Open(context.Background(), "hook://", sqlclient.OpenSession("SET ROLE migrator"), hook)
+	require.NoError(t, err)
+
+	mock.ExpectExec("CREATE TABLE `t` \\(`id` int\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `t` VALUES \\(\\?\\)").WithArgs(1).WillReturnError(errors.New("duplicate key"))
+	mock.ExpectRollback()
+	mock.ExpectExec("DROP TABLE `audit`").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = c.ExecContext(context.Background(), "CREATE TABLE `t` (`id` int)")
+	require.NoError(t, err)
+	// Queries are not passed to the hooks.
+	rows, err := c.DB.QueryContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	tx, err := c.Tx(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(context.Background(), "INSERT INTO `t` VALUES (?)", 1)
+	require.EqualError(t, err, "duplicate key")
+	require.NoError(t, tx.Rollback())
+	_, err = c.ExecContext(context.Background(), "DROP TABLE `audit`")
+	require.EqualError(t, err, "sql/sqlclient: exec hook: audit is required")
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Len(t, events, 4)
+	require.Equal(t, "SET ROLE migrator", events[0].Stmt)
+	require.Equal(t, "CREATE TABLE `t` (`id` int)", events[1].Stmt)
+	require.NoError(t, events[1].Err)
+	require.False(t, events[1].Start.IsZero())
+	require.Equal(t, "INSERT INTO `t` VALUES (?)", events[2].Stmt)
+	require.Equal(t, []any{int64(1)}, events[2].Args)
+	require.EqualError(t, events[2].Err, "duplicate key")
+}
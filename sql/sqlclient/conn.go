@@ -0,0 +1,232 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+type (
+	// ExecHook is called after a statement was executed on a connection of the client.
+	// See OpenExecHook for more info.
+	ExecHook func(context.Context, *ExecEvent) error
+
+	// ExecEvent describes a statement execution passed to an ExecHook.
+	ExecEvent struct {
+		Stmt     string        // Executed statement.
+		Args     []any         // Arguments of the statement, if any.
+		Start    time.Time     // Start time of the execution.
+		Duration time.Duration // Duration of the execution.
+		Err      error         // Execution error, if any.
+	}
+)
+
+// wrapConn replaces the database connection of the client with a connection
+// that executes the session statements when connecting, and calls the exec
+// hooks after executing statements.
+func (c *Client) wrapConn(ctx context.Context, cfg *openOptions) error {
+	if c.openDriver == nil {
+		return errors.New("sql/sqlclient: driver does not support session statements and exec hooks")
+	}
+	var (
+		conn sqldriver.Connector = dsnConnector{dsn: c.URL.DSN, driver: c.DB.Driver()}
+		err  error
+	)
+	if dc, ok := c.DB.Driver().(sqldriver.DriverContext); ok {
+		if conn, err = dc.OpenConnector(c.URL.DSN); err != nil {
+			return fmt.Errorf("sql/sqlclient: opening connector: %w", err)
+		}
+	}
+	db := sql.OpenDB(&hookConnector{Connector: conn, session: cfg.session, hooks: cfg.hooks})
+	// Connect once, to report invalid statements early.
+	if err := db.PingContext(ctx); err != nil {
+		if cerr := db.Close(); cerr != nil {
+			err = fmt.Errorf("%w: %v", err, cerr)
+		}
+		return err
+	}
+	drv, err := c.openDriver(db)
+	if err != nil {
+		if cerr := db.Close(); cerr != nil {
+			err = fmt.Errorf("%w: %v", err, cerr)
+		}
+		return fmt.Errorf("sql/sqlclient: opening atlas driver: %w", err)
+	}
+	// Connection state (e.g. collected server notices) is shared with the new driver.
+	if s, ok := c.Driver.(interface{ ShareTx(migrate.Driver) }); ok {
+		s.ShareTx(drv)
+	}
+	if err := c.DB.Close(); err != nil {
+		return err
+	}
+	c.DB, c.Driver = db, drv
+	return nil
+}
+
+type (
+	// dsnConnector is a driver.Connector for drivers
+	// that do not implement the driver.DriverContext.
+	dsnConnector struct {
+		dsn    string
+		driver sqldriver.Driver
+	}
+
+	// hookConnector wraps a driver.Connector, executes the session
+	// statements on every new connection, and wraps the connections
+	// to call the exec hooks.
+	hookConnector struct {
+		sqldriver.Connector
+		session []string
+		hooks   []ExecHook
+	}
+
+	// hookConn wraps a driver.Conn and calls the exec hooks after
+	// executing statements. The optional interfaces of the driver
+	// are delegated to the underlying connection.
+	hookConn struct {
+		sqldriver.Conn
+		hooks []ExecHook
+	}
+)
+
+// Connect implements driver.Connector.
+func (c dsnConnector) Connect(context.Context) (sqldriver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver implements driver.Connector.
+func (c dsnConnector) Driver() sqldriver.Driver {
+	return c.driver
+}
+
+// Connect implements driver.Connector.
+func (c *hookConnector) Connect(ctx context.Context) (sqldriver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.hooks) > 0 {
+		conn = &hookConn{Conn: conn, hooks: c.hooks}
+	}
+	for _, s := range c.session {
+		if _, err := execConn(ctx, conn, s, nil); err != nil {
+			if cerr := conn.Close(); cerr != nil {
+				err = fmt.Errorf("%w: %v", err, cerr)
+			}
+			return nil, fmt.Errorf("sql/sqlclient: executing session statement %q: %w", s, err)
+		}
+	}
+	return conn, nil
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *hookConn) ExecContext(ctx context.Context, query string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+	e := &ExecEvent{Stmt: query, Start: time.Now()}
+	for _, a := range args {
+		e.Args = append(e.Args, a.Value)
+	}
+	r, err := execConn(ctx, c.Conn, query, args)
+	e.Duration, e.Err = time.Since(e.Start), err
+	for _, h := range c.hooks {
+		if herr := h(ctx, e); herr != nil {
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", err, herr)
+			}
+			return nil, fmt.Errorf("sql/sqlclient: exec hook: %w", herr)
+		}
+	}
+	return r, err
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *hookConn) QueryContext(ctx context.Context, query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	if q, ok := c.Conn.(sqldriver.QueryerContext); ok {
+		return q.QueryContext(ctx, query, args)
+	}
+	return nil, sqldriver.ErrSkip
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *hookConn) PrepareContext(ctx context.Context, query string) (sqldriver.Stmt, error) {
+	if p, ok := c.Conn.(sqldriver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *hookConn) BeginTx(ctx context.Context, opts sqldriver.TxOptions) (sqldriver.Tx, error) {
+	if b, ok := c.Conn.(sqldriver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.ReadOnly || opts.Isolation != sqldriver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql/sqlclient: driver does not support transaction options")
+	}
+	//nolint:staticcheck
+	return c.Conn.Begin()
+}
+
+// Ping implements driver.Pinger.
+func (c *hookConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(sqldriver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *hookConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(sqldriver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements driver.Validator.
+func (c *hookConn) IsValid() bool {
+	if v, ok := c.Conn.(sqldriver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *hookConn) CheckNamedValue(v *sqldriver.NamedValue) error {
+	if nc, ok := c.Conn.(sqldriver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(v)
+	}
+	return sqldriver.ErrSkip
+}
+
+// execConn executes a statement on the given connection.
+func execConn(ctx context.Context, conn sqldriver.Conn, s string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+	if ex, ok := conn.(sqldriver.ExecerContext); ok {
+		r, err := ex.ExecContext(ctx, s, args)
+		if err != sqldriver.ErrSkip {
+			return r, err
+		}
+	}
+	stmt, err := conn.Prepare(s)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	if sc, ok := stmt.(sqldriver.StmtExecContext); ok {
+		return sc.ExecContext(ctx, args)
+	}
+	values := make([]sqldriver.Value, len(args))
+	for i := range args {
+		values[i] = args[i].Value
+	}
+	//nolint:staticcheck
+	return stmt.Exec(values)
+}
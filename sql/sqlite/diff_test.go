@@ -329,3 +329,82 @@ func TestDiff_SchemaDiff(t *testing.T) {
 		&schema.AddTable{T: to.Tables[1]},
 	}, changes)
 }
+
+func TestDiff_SchemaViews(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	from := schema.New("main").AddViews(
+		schema.NewView("active", "SELECT id FROM users WHERE active"),
+		schema.NewView("admins", "SELECT id FROM users WHERE admin"),
+		schema.NewView("old", "SELECT 1"),
+	)
+	to := schema.New("main").AddViews(
+		schema.NewView("active", "SELECT id\n  FROM users\n  WHERE active;"),
+		schema.NewView("admins", "SELECT id FROM users WHERE admin AND active"),
+		schema.NewView("new", "SELECT 2"),
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.DropView{V: from.Views[2]},
+		&schema.ModifyView{From: from.Views[1], To: to.Views[1]},
+		&schema.AddView{V: to.Views[2]},
+	}, changes)
+
+	// Views can be compared exactly, or their definition changes can be ignored.
+	from = schema.New("main").AddViews(
+		schema.NewView("exact", "SELECT id FROM users"),
+		schema.NewView("ignored", "select `users`.`id` AS `id` from `users`"),
+	)
+	to = schema.New("main").AddViews(
+		schema.NewView("exact", "SELECT id  FROM users").AddAttrs(&schema.ViewCompare{Mode: schema.ViewCompareExact}),
+		schema.NewView("ignored", "SELECT id FROM users").AddAttrs(&schema.ViewCompare{Mode: schema.ViewCompareIgnore}),
+	)
+	changes, err = drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.ModifyView{From: from.Views[0], To: to.Views[0]},
+	}, changes)
+}
+
+func TestDiff_SchemaTriggers(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		from = schema.New("main").AddTables(
+			schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("id", "int")).
+				AddTriggers(
+					schema.NewTrigger("users_insert", "AFTER", []string{"INSERT"}, "BEGIN SELECT 1; END"),
+					schema.NewTrigger("users_update", "AFTER", []string{"UPDATE"}, "BEGIN SELECT 1; END"),
+					schema.NewTrigger("users_delete", "BEFORE", []string{"DELETE"}, "BEGIN SELECT 1; END"),
+				),
+			schema.NewTable("logs").
+				AddColumns(schema.NewIntColumn("id", "int")).
+				AddTriggers(schema.NewTrigger("logs_insert", "AFTER", []string{"INSERT"}, "BEGIN SELECT 1; END")),
+		)
+		to = schema.New("main").AddTables(
+			schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("id", "int")).
+				AddTriggers(
+					schema.NewTrigger("users_insert", "after", []string{"insert"}, "BEGIN\n  SELECT 1;\nEND"),
+					schema.NewTrigger("users_update", "AFTER", []string{"UPDATE"}, "BEGIN SELECT 1; END").SetWhen("NEW.id > 0"),
+					schema.NewTrigger("users_new", "BEFORE", []string{"INSERT"}, "BEGIN SELECT 2; END"),
+				),
+		)
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.DropTable{T: from.Tables[1]},
+		&schema.DropTrigger{T: from.Tables[0].Triggers[2]},
+		&schema.ModifyTrigger{From: from.Tables[0].Triggers[1], To: to.Tables[0].Triggers[1]},
+		&schema.AddTrigger{T: to.Tables[0].Triggers[2]},
+	}, changes)
+}
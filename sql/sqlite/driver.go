@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +20,8 @@ import (
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlclient"
+
+	"golang.org/x/mod/semver"
 )
 
 type (
@@ -41,6 +44,20 @@ type (
 	}
 )
 
+var (
+	// DefaultDiff provides basic diffing capabilities for SQLite dialects.
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// Differ when a database connection is available.
+	DefaultDiff schema.Differ = &sqlx.Diff{DiffDriver: &Diff{}}
+
+	// DefaultPlan provides basic planning capabilities for SQLite dialects, for example,
+	// for compiling a schema to SQL statements without a database connection. The changes
+	// are planned for SQLite 3.39, unless a target version is given (see migrate.PlanOptions).
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// migrate.PlanApplier when a database connection is available.
+	DefaultPlan migrate.PlanApplier = &planApply{conn{ExecQuerier: sqlx.NoRows, version: "3.39.0"}}
+)
+
 // DriverName holds the name used for registration.
 const DriverName = "sqlite3"
 
@@ -100,7 +117,7 @@ func (d *Driver) Snapshot(ctx context.Context) (migrate.RestoreFunc, error) {
 	return func(ctx context.Context) error {
 		for _, stmt := range []string{
 			"PRAGMA writable_schema = 1;",
-			"DELETE FROM sqlite_master WHERE type IN ('table', 'index', 'trigger');",
+			"DELETE FROM sqlite_master WHERE type IN ('table', 'index', 'trigger', 'view');",
 			"PRAGMA writable_schema = 0;",
 			"VACUUM;",
 		} {
@@ -112,6 +129,48 @@ func (d *Driver) Snapshot(ctx context.Context) (migrate.RestoreFunc, error) {
 	}, nil
 }
 
+// Violations implements migrate.DataValidator. Partial unique indexes are not checked.
+func (d *Driver) Violations(ctx context.Context, changes []schema.Change, limit int) ([]*migrate.Violation, error) {
+	var checks []*sqlx.ViolationCheck
+	for _, c := range sqlx.ViolationChecks(changes, '`', limit) {
+		// Rows that are excluded by the predicate of a partial index are not checked by it.
+		if c.Index == nil || !sqlx.Has(c.Index.Attrs, &IndexPredicate{}) {
+			checks = append(checks, c)
+		}
+	}
+	vs, err := sqlx.CheckViolations(ctx, d, checks)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: %w", err)
+	}
+	return vs, nil
+}
+
+var reFailed = regexp.MustCompile(`(UNIQUE|NOT NULL|CHECK|FOREIGN KEY) constraint failed(?:: (.+))?`)
+
+// ConstraintError implements migrate.ConstraintErrorParser. Note, SQLite does not
+// report the values of the violating key, nor the violated foreign key.
+func (*Driver) ConstraintError(err error) (*migrate.ConstraintError, bool) {
+	m := reFailed.FindStringSubmatch(err.Error())
+	if m == nil {
+		return nil, false
+	}
+	cerr := &migrate.ConstraintError{Kind: strings.ToLower(m[1])}
+	switch {
+	case m[2] == "":
+	case m[1] == "CHECK":
+		cerr.Constraint = m[2]
+	case m[1] == "UNIQUE" || m[1] == "NOT NULL":
+		// Columns are reported qualified with their table name, e.g. "t.a, t.b".
+		for _, c := range strings.Split(m[2], ", ") {
+			if i := strings.IndexByte(c, '.'); i > 0 {
+				cerr.Table, c = c[:i], c[i+1:]
+			}
+			cerr.Columns = append(cerr.Columns, c)
+		}
+	}
+	return cerr, true
+}
+
 // CheckClean implements migrate.CleanChecker.
 func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error {
 	r, err := d.InspectRealm(ctx, nil)
@@ -131,6 +190,39 @@ func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error
 	return nil
 }
 
+// Capabilities implements the migrate.CapabilitiesReporter interface.
+func (d *Driver) Capabilities(version string) (*migrate.Capabilities, error) {
+	c := d.conn
+	if version != "" {
+		if !semver.IsValid("v" + version) {
+			return nil, fmt.Errorf("sqlite: malformed version: %s", version)
+		}
+		c.version = version
+	}
+	return c.capabilities(), nil
+}
+
+// capabilities returns the capabilities of the connection version.
+func (c *conn) capabilities() *migrate.Capabilities {
+	return &migrate.Capabilities{
+		Driver:  DriverName,
+		Version: c.version,
+		Supports: map[migrate.Capability]bool{
+			migrate.CapTransactionalDDL: true,
+			migrate.CapConcurrentIndex:  false,
+			// ADD COLUMN modifies only the table definition in sqlite_schema.
+			migrate.CapInstantAddColumn: true,
+			migrate.CapRenameColumn:     c.supportsRenameColumn(),
+			migrate.CapCheckConstraint:  true,
+			migrate.CapIndexExpr:        true,
+			migrate.CapGeneratedColumn:  c.supportsGeneratedColumns(),
+			migrate.CapInvisibleIndex:   false,
+			migrate.CapIndexInclude:     false,
+			migrate.CapNullsNotDistinct: false,
+		},
+	}
+}
+
 // Lock implements the schema.Locker interface.
 func (d *Driver) Lock(_ context.Context, name string, timeout time.Duration) (schema.UnlockFunc, error) {
 	path := filepath.Join(os.TempDir(), name+".lock")
@@ -164,6 +256,16 @@ func acquireLock(path string, timeout time.Duration) (schema.UnlockFunc, error)
 	return func() error { return os.Remove(path) }, nil
 }
 
+// supportsRenameColumn reports if the database supports the RENAME COLUMN clause.
+func (c *conn) supportsRenameColumn() bool {
+	return semver.Compare("v"+c.version, "v3.25.0") >= 0
+}
+
+// supportsGeneratedColumns reports if the database supports generated columns.
+func (c *conn) supportsGeneratedColumns() bool {
+	return semver.Compare("v"+c.version, "v3.31.0") >= 0
+}
+
 // SQLite standard data types as defined in its codebase and documentation.
 // https://www.sqlite.org/datatype3.html
 // https://github.com/sqlite/sqlite/blob/master/src/global.c
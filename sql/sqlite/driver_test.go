@@ -6,6 +6,7 @@ package sqlite
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -84,3 +85,50 @@ type mockInspector struct {
 func (m *mockInspector) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
 	return m.realm, nil
 }
+
+func TestDriver_Capabilities(t *testing.T) {
+	drv := &Driver{conn: conn{version: "3.36.0"}}
+	caps, err := drv.Capabilities("")
+	require.NoError(t, err)
+	require.Equal(t, "sqlite3", caps.Driver)
+	require.True(t, caps.Is(migrate.CapTransactionalDDL))
+	require.True(t, caps.Is(migrate.CapRenameColumn))
+	require.True(t, caps.Is(migrate.CapGeneratedColumn))
+
+	caps, err = drv.Capabilities("3.24.0")
+	require.NoError(t, err)
+	require.False(t, caps.Is(migrate.CapRenameColumn))
+	require.False(t, caps.Is(migrate.CapGeneratedColumn))
+	require.False(t, caps.Is(migrate.CapConcurrentIndex))
+}
+
+func TestDriver_ConstraintError(t *testing.T) {
+	for _, tt := range []struct {
+		err  string
+		want *migrate.ConstraintError
+	}{
+		{
+			err:  "UNIQUE constraint failed: users.name, users.kind",
+			want: &migrate.ConstraintError{Kind: "unique", Table: "users", Columns: []string{"name", "kind"}},
+		},
+		{
+			err:  "NOT NULL constraint failed: users.name",
+			want: &migrate.ConstraintError{Kind: "not null", Table: "users", Columns: []string{"name"}},
+		},
+		{
+			err:  "CHECK constraint failed: positive_age",
+			want: &migrate.ConstraintError{Kind: "check", Constraint: "positive_age"},
+		},
+		{
+			err:  "FOREIGN KEY constraint failed",
+			want: &migrate.ConstraintError{Kind: "foreign key"},
+		},
+		{
+			err: "no such table: users",
+		},
+	} {
+		got, ok := (&Driver{}).ConstraintError(errors.New(tt.err))
+		require.Equal(t, tt.want != nil, ok, tt.err)
+		require.Equal(t, tt.want, got, tt.err)
+	}
+}
@@ -35,6 +35,16 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 		opts = &schema.InspectRealmOption{}
 	}
 	r := schema.NewRealm(schemas...)
+	var triggers []*schema.Trigger
+	if mode := sqlx.ModeInspectRealm(opts); mode.Is(schema.InspectObjects | schema.InspectViews | schema.InspectTriggers) {
+		for _, s := range schemas {
+			ts, err := i.unmanaged(ctx, s, mode)
+			if err != nil {
+				return nil, err
+			}
+			triggers = append(triggers, ts...)
+		}
+	}
 	if !sqlx.ModeInspectRealm(opts).Is(schema.InspectTables) {
 		return sqlx.ExcludeRealm(r, opts.Exclude)
 	}
@@ -51,6 +61,7 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 		}
 	}
 	sqlx.LinkSchemaTables(r.Schemas)
+	sqlx.LinkTriggers(triggers)
 	return sqlx.ExcludeRealm(r, opts.Exclude)
 }
 
@@ -75,6 +86,12 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 		opts = &schema.InspectOptions{}
 	}
 	r := schema.NewRealm(schemas...)
+	var triggers []*schema.Trigger
+	if mode := sqlx.ModeInspectSchema(opts); mode.Is(schema.InspectObjects | schema.InspectViews | schema.InspectTriggers) {
+		if triggers, err = i.unmanaged(ctx, r.Schemas[0], mode); err != nil {
+			return nil, err
+		}
+	}
 	if !sqlx.ModeInspectSchema(opts).Is(schema.InspectTables) {
 		return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
 	}
@@ -89,9 +106,68 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 		}
 	}
 	sqlx.LinkSchemaTables(schemas)
+	sqlx.LinkTriggers(triggers)
 	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
 }
 
+// unmanaged inspects the views and triggers of the schema. Views and triggers
+// are added to the schema if they were requested by the inspection mode. Triggers
+// are returned, and should be linked to their tables after they were inspected.
+// Objects that cannot be modeled by Atlas (e.g. views that are defined with a
+// column list) are kept as raw statements.
+func (i *inspect) unmanaged(ctx context.Context, s *schema.Schema, mode schema.InspectMode) ([]*schema.Trigger, error) {
+	rows, err := i.QueryContext(ctx, unmanagedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: querying unmanaged objects: %w", err)
+	}
+	defer rows.Close()
+	var triggers []*schema.Trigger
+	for rows.Next() {
+		var typ, name, tbl, stmt string
+		if err := rows.Scan(&typ, &name, &tbl, &stmt); err != nil {
+			return nil, fmt.Errorf("sqlite: scanning unmanaged object: %w", err)
+		}
+		if m := reViewDef.FindStringSubmatch(stmt); typ == "view" && m != nil {
+			if mode.Is(schema.InspectViews) {
+				s.AddViews(schema.NewView(name, strings.TrimSuffix(strings.TrimSpace(m[1]), ";")))
+			}
+			continue
+		}
+		if tr, ok := triggerFromDef(name, stmt); typ == "trigger" && ok {
+			if mode.Is(schema.InspectTriggers) {
+				tr.Table = &schema.Table{Name: tbl, Schema: s}
+				triggers = append(triggers, tr)
+			}
+			continue
+		}
+		if !mode.Is(schema.InspectObjects) {
+			continue
+		}
+		u := schema.NewUnmanaged(typ, name, stmt)
+		b := &sqlx.Builder{QuoteChar: '`'}
+		s.AddAttrs(u.SetDrop(b.P("DROP", u.Type).Ident(name).String()))
+	}
+	return triggers, rows.Err()
+}
+
+// reTriggerDef matches the definition of a trigger, as stored in the sqlite_master table.
+var reTriggerDef = regexp.MustCompile("(?is)^\\s*CREATE\\s+(?:TEMP\\s+|TEMPORARY\\s+)?TRIGGER\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?(?:(?:\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\]|\\w+)\\.)?(?:\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\]|\\w+)\\s+(?:(BEFORE|AFTER|INSTEAD\\s+OF)\\s+)?(DELETE|INSERT|UPDATE(?:\\s+OF\\s+.+?)?)\\s+ON\\s+(?:\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\]|\\w+)\\s+(?:FOR\\s+EACH\\s+ROW\\s+)?(?:WHEN\\s+(.+?)\\s+)?(BEGIN\\s.+END)\\s*;?\\s*$")
+
+// triggerFromDef returns the trigger described by the given definition. Triggers
+// in SQLite are always row-level, and fire before the event if timing is omitted.
+func triggerFromDef(name, def string) (*schema.Trigger, bool) {
+	m := reTriggerDef.FindStringSubmatch(def)
+	if m == nil {
+		return nil, false
+	}
+	timing := "BEFORE"
+	if m[1] != "" {
+		timing = strings.ToUpper(strings.Join(strings.Fields(m[1]), " "))
+	}
+	return schema.NewTrigger(name, timing, []string{strings.Join(strings.Fields(m[2]), " ")}, m[4]).
+		SetWhen(strings.TrimSpace(m[3])), true
+}
+
 func (i *inspect) inspectTable(ctx context.Context, t *schema.Table) error {
 	if err := i.columns(ctx, t); err != nil {
 		return err
@@ -227,6 +303,9 @@ func (i *inspect) addIndexes(t *schema.Table, rows *sql.Rows) error {
 	return nil
 }
 
+// A regexp to extract the query of a view from its CREATE statement.
+var reViewDef = regexp.MustCompile("(?is)^\\s*CREATE\\s+(?:TEMP\\s+|TEMPORARY\\s+)?VIEW\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?(?:(?:\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\]|\\w+)\\.)?(?:\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\]|\\w+)\\s+AS\\s+(.+)$")
+
 // A regexp to extract index parts.
 var reIdxParts = regexp.MustCompile("(?i)ON\\s+[\"`]*(?:\\w+)[\"`]*\\s*\\((.+)\\)")
 
@@ -708,6 +787,8 @@ const (
 	databasesQueryArgs = "SELECT `name`, `file` FROM pragma_database_list() WHERE `name` IN (%s)"
 	// Query to list database tables.
 	tablesQuery = "SELECT `name`, `sql` FROM sqlite_master WHERE `type` = 'table' AND `name` NOT LIKE 'sqlite_%'"
+	// Query to list views and triggers.
+	unmanagedQuery = "SELECT `type`, `name`, `tbl_name`, `sql` FROM sqlite_master WHERE `type` IN ('view', 'trigger') AND `sql` IS NOT NULL ORDER BY `type` DESC, `name`"
 	// Query to list table information.
 	columnsQuery = "SELECT `name`, `type`, (not `notnull`) AS `nullable`, `dflt_value`, (`pk` <> 0) AS `pk`, `hidden` FROM pragma_table_xinfo('%s') ORDER BY `pk`, `cid`"
 	// Query to list table indexes.
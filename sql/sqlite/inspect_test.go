@@ -550,3 +550,54 @@ func (m mock) noFKs(table string) {
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, table))).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "from", "to", "table", "on_update", "on_delete"}))
 }
+
+func TestRegex_ViewDef(t *testing.T) {
+	tests := []struct {
+		input string
+		def   string
+	}{
+		{input: "CREATE VIEW v AS SELECT 1", def: "SELECT 1"},
+		{input: "CREATE VIEW `v` AS\nSELECT id FROM users;", def: "SELECT id FROM users;"},
+		{input: "CREATE TEMP VIEW IF NOT EXISTS \"main\".\"v\" as select a from t", def: "select a from t"},
+		{input: "CREATE VIEW v(a, b) AS SELECT 1, 2"},
+	}
+	for _, tt := range tests {
+		m := reViewDef.FindStringSubmatch(tt.input)
+		if tt.def == "" {
+			require.Nil(t, m)
+			continue
+		}
+		require.Len(t, m, 2)
+		require.Equal(t, tt.def, m[1])
+	}
+}
+
+func TestTriggerFromDef(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *schema.Trigger
+	}{
+		{
+			input: "CREATE TRIGGER t AFTER INSERT ON users BEGIN SELECT 1; END",
+			want:  schema.NewTrigger("t", "AFTER", []string{"INSERT"}, "BEGIN SELECT 1; END"),
+		},
+		{
+			input: "CREATE TRIGGER `t` DELETE ON `users` FOR EACH ROW BEGIN SELECT 1; END",
+			want:  schema.NewTrigger("t", "BEFORE", []string{"DELETE"}, "BEGIN SELECT 1; END"),
+		},
+		{
+			input: "CREATE TEMP TRIGGER IF NOT EXISTS main.t instead  of update of name, id on \"users\"\nFOR EACH ROW\nWHEN NEW.id > 0\nBEGIN\n  UPDATE logs SET c = 1;\nEND;",
+			want:  schema.NewTrigger("t", "INSTEAD OF", []string{"update of name, id"}, "BEGIN\n  UPDATE logs SET c = 1;\nEND").SetWhen("NEW.id > 0"),
+		},
+		{input: "CREATE TRIGGER t AFTER INSERT ON users"},
+	}
+	for _, tt := range tests {
+		tr, ok := triggerFromDef("t", tt.input)
+		if tt.want == nil {
+			require.False(t, ok)
+			continue
+		}
+		require.True(t, ok)
+		require.Equal(t, tt.want, tr)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+
+	"golang.org/x/mod/semver"
 )
 
 // A planApply provides migration capabilities for schema elements.
@@ -22,9 +24,8 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 	s := &state{
 		conn: p.conn,
 		Plan: migrate.Plan{
-			Name:          name,
-			Reversible:    true,
-			Transactional: true,
+			Name:       name,
+			Reversible: true,
 		},
 		PlanOptions: migrate.PlanOptions{
 			// Currently, the driver does not support attached
@@ -35,6 +36,14 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 	for _, o := range opts {
 		o(&s.PlanOptions)
 	}
+	// Plan the changes for the target version, instead of the connected one.
+	if s.TargetVersion != "" {
+		if !semver.IsValid("v" + s.TargetVersion) {
+			return nil, fmt.Errorf("sqlite: malformed target version: %s", s.TargetVersion)
+		}
+		s.version = s.TargetVersion
+	}
+	s.Transactional = s.capabilities().Is(migrate.CapTransactionalDDL)
 	if err := s.plan(ctx, changes); err != nil {
 		return nil, err
 	}
@@ -61,11 +70,27 @@ type state struct {
 	migrate.Plan
 	migrate.PlanOptions
 	skipFKs bool
+	// Changes that are planned after all table changes.
+	deferred []*migrate.Change
+	// Tables that were copied to new tables, and their
+	// triggers were dropped along with the old tables.
+	copied []*schema.Table
 }
 
 // Exec executes the changes on the database. An error is returned
 // if one of the operations fail, or a change is not supported.
 func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
+	dropT, changes, addT := sqlx.SplitTriggers(changes)
+	if err := s.dropTriggers(dropT); err != nil {
+		return err
+	}
+	drop, changes, add := sqlx.SplitViews(changes)
+	s.views(drop)
+	// Tables are created before the tables that reference them, as SQLite
+	// does not support adding foreign keys to existing tables.
+	if changes, err = sqlx.SortChanges(changes); err != nil {
+		return err
+	}
 	for _, c := range changes {
 		switch c := c.(type) {
 		case *schema.AddTable:
@@ -76,6 +101,8 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 			err = s.modifyTable(ctx, c)
 		case *schema.RenameTable:
 			s.renameTable(c)
+		case *schema.ModifySchema:
+			err = s.modifySchema(c)
 		default:
 			err = fmt.Errorf("unsupported change %T", c)
 		}
@@ -83,6 +110,11 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 			return err
 		}
 	}
+	s.Changes = append(s.Changes, s.deferred...)
+	s.views(add)
+	if err := s.addTriggers(s.recreateTriggers(addT)); err != nil {
+		return err
+	}
 	// Disable foreign-keys enforcement if it is required
 	// by one of the changes in the plan.
 	if s.skipFKs && s.conn.fkEnabled {
@@ -94,6 +126,161 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 	return nil
 }
 
+// views plans the given view changes. SQLite does not support
+// replacing views, and therefore, modified views are recreated.
+func (s *state) views(changes []schema.Change) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddView:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("CREATE VIEW").View(c.V).P("AS", c.V.Def).String(),
+				Source:  c,
+				Reverse: s.Build("DROP VIEW").View(c.V).String(),
+				Comment: fmt.Sprintf("create %q view", c.V.Name),
+			})
+		case *schema.DropView:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("DROP VIEW").View(c.V).String(),
+				Source:  c,
+				Reverse: s.Build("CREATE VIEW").View(c.V).P("AS", c.V.Def).String(),
+				Comment: fmt.Sprintf("drop %q view", c.V.Name),
+			})
+		case *schema.ModifyView:
+			s.append(&migrate.Change{
+				Cmd:     s.Build("DROP VIEW").View(c.From).String(),
+				Source:  c,
+				Reverse: s.Build("CREATE VIEW").View(c.From).P("AS", c.From.Def).String(),
+				Comment: fmt.Sprintf("drop %q view", c.From.Name),
+			})
+			s.append(&migrate.Change{
+				Cmd:     s.Build("CREATE VIEW").View(c.To).P("AS", c.To.Def).String(),
+				Source:  c,
+				Reverse: s.Build("DROP VIEW").View(c.To).String(),
+				Comment: fmt.Sprintf("create %q view", c.To.Name),
+			})
+		}
+	}
+}
+
+// dropTriggers plans the given trigger changes that should be executed before
+// all other changes. Modified triggers are dropped, and created again later.
+func (s *state) dropTriggers(changes []schema.Change) error {
+	for _, c := range changes {
+		var t *schema.Trigger
+		switch c := c.(type) {
+		case *schema.DropTrigger:
+			t = c.T
+		case *schema.ModifyTrigger:
+			t = c.From
+		default:
+			return fmt.Errorf("unexpected trigger change %T", c)
+		}
+		create, err := s.createTrigger(t)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.Build("DROP TRIGGER").Trigger(t).String(),
+			Source:  c,
+			Reverse: create,
+			Comment: fmt.Sprintf("drop %q trigger", t.Name),
+		})
+	}
+	return nil
+}
+
+// addTriggers plans the given trigger changes that
+// should be executed after all other changes.
+func (s *state) addTriggers(changes []schema.Change) error {
+	for _, c := range changes {
+		var t *schema.Trigger
+		switch c := c.(type) {
+		case *schema.AddTrigger:
+			t = c.T
+		case *schema.ModifyTrigger:
+			t = c.To
+		default:
+			return fmt.Errorf("unexpected trigger change %T", c)
+		}
+		create, err := s.createTrigger(t)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     create,
+			Source:  c,
+			Reverse: s.Build("DROP TRIGGER").Trigger(t).String(),
+			Comment: fmt.Sprintf("create %q trigger", t.Name),
+		})
+	}
+	return nil
+}
+
+// recreateTriggers returns the trigger changes that should be planned after all other
+// changes. Triggers of tables that were copied are dropped along with the old tables,
+// and therefore, all triggers of these tables are created again.
+func (s *state) recreateTriggers(changes []schema.Change) []schema.Change {
+	if len(s.copied) == 0 {
+		return changes
+	}
+	copied := make(map[string]bool, len(s.copied))
+	for _, t := range s.copied {
+		copied[t.Name] = true
+	}
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTrigger:
+			if copied[c.T.Table.Name] {
+				continue
+			}
+		case *schema.ModifyTrigger:
+			if copied[c.To.Table.Name] {
+				continue
+			}
+		}
+		planned = append(planned, c)
+	}
+	for _, t := range s.copied {
+		for _, tr := range t.Triggers {
+			planned = append(planned, &schema.AddTrigger{T: tr})
+		}
+	}
+	return planned
+}
+
+// createTrigger returns the statement for creating the given trigger.
+// SQLite supports only row-level triggers with one event.
+func (s *state) createTrigger(t *schema.Trigger) (string, error) {
+	switch {
+	case len(t.Events) != 1:
+		return "", fmt.Errorf("sqlite: trigger %q must have exactly one event, got %d", t.Name, len(t.Events))
+	case !t.ForEachRow:
+		return "", fmt.Errorf("sqlite: statement-level trigger %q is not supported", t.Name)
+	}
+	// The table of a trigger cannot be qualified, as
+	// it must be in the same database of the trigger.
+	b := s.Build("CREATE TRIGGER").Trigger(t).P(t.ActionTime, t.Events[0], "ON").Ident(t.Table.Name).P("FOR EACH ROW")
+	if t.When != "" {
+		b.P("WHEN", t.When)
+	}
+	return b.P(t.Body).String(), nil
+}
+
+// modifySchema plans the changes of the unmanaged objects, which
+// are the only schema attributes that can be modified in SQLite.
+func (s *state) modifySchema(modify *schema.ModifySchema) error {
+	rest, unmanaged, err := sqlx.UnmanagedChanges(modify)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unsupported ModifySchema change %T", rest[0])
+	}
+	s.deferred = append(s.deferred, unmanaged...)
+	return nil
+}
+
 // addTable builds and executes the query for creating a table in a schema.
 func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 	var (
@@ -195,6 +382,7 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 		Source:  modify,
 		Comment: fmt.Sprintf("rename temporary table %q to %q", newT.Name, modify.T.Name),
 	})
+	s.copied = append(s.copied, modify.T)
 	return s.addIndexes(modify.T, indexes...)
 }
 
@@ -230,6 +418,9 @@ func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
 	case hasA:
 		b.P("PRIMARY KEY AUTOINCREMENT")
 	case hasX:
+		if !s.supportsGeneratedColumns() {
+			return fmt.Errorf("generated column %q requires SQLite 3.31.0 or above, but the target version is %s", c.Name, s.version)
+		}
 		x := &schema.GeneratedExpr{}
 		sqlx.Has(c.Attrs, x)
 		b.P("AS", sqlx.MayWrap(x.Expr), x.Type)
@@ -367,6 +558,14 @@ func (s *state) copyRows(from *schema.Table, to *schema.Table, changes []schema.
 					return false, fmt.Errorf("duplicate changes for column: %q: %T, %T", column.Name, change, c)
 				}
 				change = changes[i]
+			case *schema.RenameColumn:
+				if c.To.Name != column.Name {
+					break
+				}
+				if change != nil {
+					return false, fmt.Errorf("duplicate changes for column: %q: %T, %T", column.Name, change, c)
+				}
+				change = changes[i]
 			case *schema.DropColumn:
 				if c.C.Name == column.Name {
 					return false, fmt.Errorf("unexpected drop column: %q", column.Name)
@@ -374,6 +573,10 @@ func (s *state) copyRows(from *schema.Table, to *schema.Table, changes []schema.
 			}
 		}
 		switch change := change.(type) {
+		// Renamed columns are copied from their previous names.
+		case *schema.RenameColumn:
+			toC = append(toC, column.Name)
+			fromC = append(fromC, change.From.Name)
 		// We expect that new columns are added with DEFAULT/GENERATED
 		// values or defined as nullable if the table is not empty.
 		case *schema.AddColumn:
@@ -270,6 +270,43 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		// Renamed columns are copied from their previous names.
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int"}}},
+							{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}},
+						},
+					}
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.RenameColumn{
+								From: &schema.Column{Name: "nick", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}},
+								To:   users.Columns[1],
+							},
+							&schema.DropColumn{
+								C: &schema.Column{Name: "age", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int"}}},
+							},
+						},
+					}
+				}(),
+			},
+			plan: &migrate.Plan{
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: "PRAGMA foreign_keys = off"},
+					{Cmd: "CREATE TABLE `new_users` (`id` int NOT NULL, `name` text NOT NULL)", Reverse: "DROP TABLE `new_users`"},
+					{Cmd: "INSERT INTO `new_users` (`id`, `name`) SELECT `id`, `nick` FROM `users`"},
+					{Cmd: "DROP TABLE `users`"},
+					{Cmd: "ALTER TABLE `new_users` RENAME TO `users`"},
+					{Cmd: "PRAGMA foreign_keys = on"},
+				},
+			},
+		},
 		{
 			changes: []schema.Change{
 				&schema.RenameTable{
@@ -376,6 +413,100 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+				return []schema.Change{
+					&schema.AddView{V: schema.NewView("active", "SELECT id FROM users")},
+					&schema.AddTable{T: users},
+					&schema.ModifyView{From: schema.NewView("admins", "SELECT 1"), To: schema.NewView("admins", "SELECT 2")},
+					&schema.DropView{V: schema.NewView("old", "SELECT 1")},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: "DROP VIEW `old`", Reverse: "CREATE VIEW `old` AS SELECT 1"},
+					{Cmd: "CREATE TABLE `users` (`id` int NOT NULL)", Reverse: "DROP TABLE `users`"},
+					{Cmd: "CREATE VIEW `active` AS SELECT id FROM users", Reverse: "DROP VIEW `active`"},
+					{Cmd: "DROP VIEW `admins`", Reverse: "CREATE VIEW `admins` AS SELECT 1"},
+					{Cmd: "CREATE VIEW `admins` AS SELECT 2", Reverse: "DROP VIEW `admins`"},
+				},
+			},
+		},
+		// Triggers are dropped first, and created last.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+				users.AddTriggers(
+					schema.NewTrigger("users_insert", "AFTER", []string{"INSERT"}, "BEGIN SELECT 1; END").SetWhen("NEW.id > 0"),
+					schema.NewTrigger("users_update", "BEFORE", []string{"UPDATE"}, "BEGIN SELECT 2; END"),
+				)
+				old := schema.NewTable("users").AddTriggers(
+					schema.NewTrigger("users_update", "BEFORE", []string{"UPDATE"}, "BEGIN SELECT 1; END"),
+					schema.NewTrigger("users_delete", "BEFORE", []string{"DELETE"}, "BEGIN SELECT 1; END"),
+				)
+				return []schema.Change{
+					&schema.AddTrigger{T: users.Triggers[0]},
+					&schema.AddTable{T: users},
+					&schema.ModifyTrigger{From: old.Triggers[0], To: users.Triggers[1]},
+					&schema.DropTrigger{T: old.Triggers[1]},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: "DROP TRIGGER `users_update`", Reverse: "CREATE TRIGGER `users_update` BEFORE UPDATE ON `users` FOR EACH ROW BEGIN SELECT 1; END"},
+					{Cmd: "DROP TRIGGER `users_delete`", Reverse: "CREATE TRIGGER `users_delete` BEFORE DELETE ON `users` FOR EACH ROW BEGIN SELECT 1; END"},
+					{Cmd: "CREATE TABLE `users` (`id` int NOT NULL)", Reverse: "DROP TABLE `users`"},
+					{Cmd: "CREATE TRIGGER `users_insert` AFTER INSERT ON `users` FOR EACH ROW WHEN NEW.id > 0 BEGIN SELECT 1; END", Reverse: "DROP TRIGGER `users_insert`"},
+					{Cmd: "CREATE TRIGGER `users_update` BEFORE UPDATE ON `users` FOR EACH ROW BEGIN SELECT 2; END", Reverse: "DROP TRIGGER `users_update`"},
+				},
+			},
+		},
+		// Triggers of copied tables are created again.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").
+					AddColumns(
+						schema.NewIntColumn("id", "bigint"),
+						schema.NewIntColumn("nid", "bigint").
+							SetGeneratedExpr(&schema.GeneratedExpr{Expr: "1", Type: "STORED"}),
+					)
+				users.AddTriggers(
+					schema.NewTrigger("users_insert", "AFTER", []string{"INSERT"}, "BEGIN SELECT 1; END"),
+					schema.NewTrigger("users_update", "AFTER", []string{"UPDATE"}, "BEGIN SELECT 2; END"),
+				)
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddColumn{C: users.Columns[1]},
+						},
+					},
+					&schema.ModifyTrigger{
+						From: schema.NewTable("users").AddTriggers(schema.NewTrigger("users_update", "AFTER", []string{"UPDATE"}, "BEGIN SELECT 1; END")).Triggers[0],
+						To:   users.Triggers[1],
+					},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: "PRAGMA foreign_keys = off"},
+					{Cmd: "DROP TRIGGER `users_update`", Reverse: "CREATE TRIGGER `users_update` AFTER UPDATE ON `users` FOR EACH ROW BEGIN SELECT 1; END"},
+					{Cmd: "CREATE TABLE `new_users` (`id` bigint NOT NULL, `nid` bigint NOT NULL AS (1) STORED)", Reverse: "DROP TABLE `new_users`"},
+					{Cmd: "INSERT INTO `new_users` (`id`) SELECT `id` FROM `users`"},
+					{Cmd: "DROP TABLE `users`"},
+					{Cmd: "ALTER TABLE `new_users` RENAME TO `users`"},
+					{Cmd: "CREATE TRIGGER `users_insert` AFTER INSERT ON `users` FOR EACH ROW BEGIN SELECT 1; END", Reverse: "DROP TRIGGER `users_insert`"},
+					{Cmd: "CREATE TRIGGER `users_update` AFTER UPDATE ON `users` FOR EACH ROW BEGIN SELECT 2; END", Reverse: "DROP TRIGGER `users_update`"},
+					{Cmd: "PRAGMA foreign_keys = on"},
+				},
+			},
+		},
 	}
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -399,3 +530,22 @@ func TestPlanChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanChanges_TargetVersion(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	tbl := schema.NewTable("t").AddColumns(
+		schema.NewIntColumn("a", "int"),
+		schema.NewIntColumn("b", "int").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "a * 2"}),
+	)
+	changes := []schema.Change{&schema.AddTable{T: tbl}}
+	_, err = drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	_, err = drv.PlanChanges(context.Background(), "plan", changes, func(o *migrate.PlanOptions) { o.TargetVersion = "3.30.1" })
+	require.EqualError(t, err, `create table "t": generated column "b" requires SQLite 3.31.0 or above, but the target version is 3.30.1`)
+	_, err = drv.PlanChanges(context.Background(), "plan", changes, func(o *migrate.PlanOptions) { o.TargetVersion = "three" })
+	require.EqualError(t, err, "sqlite: malformed target version: three")
+}
@@ -30,6 +30,15 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]string) error {
 		if err != nil {
 			return fmt.Errorf("specutil: failed converting to *schema.Realm: %w", err)
 		}
+		if err := specutil.Views(v, d.Views); err != nil {
+			return err
+		}
+		if err := specutil.Triggers(v, d.Triggers); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(v, d.Unmanaged); err != nil {
+			return err
+		}
 	case *schema.Schema:
 		if len(d.Schemas) != 1 {
 			return fmt.Errorf("specutil: expecting document to contain a single schema, got %d", len(d.Schemas))
@@ -38,6 +47,15 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]string) error {
 		if err := specutil.Scan(&r, d.Schemas, d.Tables, convertTable); err != nil {
 			return err
 		}
+		if err := specutil.Views(&r, d.Views); err != nil {
+			return err
+		}
+		if err := specutil.Triggers(&r, d.Triggers); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(&r, d.Unmanaged); err != nil {
+			return err
+		}
 		r.Schemas[0].Realm = nil
 		*v = *r.Schemas[0]
 	default:
@@ -219,6 +237,9 @@ func storedOrVirtual(s string) string {
 }
 
 type doc struct {
-	Tables  []*sqlspec.Table  `spec:"table"`
-	Schemas []*sqlspec.Schema `spec:"schema"`
+	Tables    []*sqlspec.Table     `spec:"table"`
+	Views     []*sqlspec.View      `spec:"view"`
+	Triggers  []*sqlspec.Trigger   `spec:"trigger"`
+	Schemas   []*sqlspec.Schema    `spec:"schema"`
+	Unmanaged []*sqlspec.Unmanaged `spec:"unmanaged"`
 }
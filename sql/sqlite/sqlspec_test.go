@@ -214,6 +214,129 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestMarshalSpec_Views(t *testing.T) {
+	s := schema.New("main").
+		AddTables(schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))).
+		AddViews(schema.NewView("active", "SELECT id FROM users WHERE id > 0"))
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.main
+  column "id" {
+    null = false
+    type = int
+  }
+}
+view "active" {
+  schema = schema.main
+  as     = "SELECT id FROM users WHERE id > 0"
+}
+schema "main" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Views, 1)
+	require.Equal(t, "active", got.Views[0].Name)
+	require.Equal(t, "SELECT id FROM users WHERE id > 0", got.Views[0].Def)
+	require.Equal(t, "main", got.Views[0].Schema.Name)
+
+	err = EvalHCLBytes([]byte(`
+schema "main" {}
+view "v" {
+  schema = schema.main
+}
+`), &got, nil)
+	require.EqualError(t, err, "missing attribute view.v.as")
+
+	// Comparison modes.
+	s = schema.New("main").AddViews(
+		schema.NewView("active", "SELECT 1").AddAttrs(&schema.ViewCompare{Mode: schema.ViewCompareIgnore}),
+	)
+	buf, err = MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	require.EqualValues(t, `view "active" {
+  schema  = schema.main
+  as      = "SELECT 1"
+  compare = "ignore"
+}
+schema "main" {
+}
+`, string(buf))
+	got = schema.Schema{}
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, []schema.Attr{&schema.ViewCompare{Mode: schema.ViewCompareIgnore}}, got.Views[0].Attrs)
+	err = EvalHCLBytes([]byte(`
+schema "main" {}
+view "v" {
+  schema  = schema.main
+  as      = "SELECT 1"
+  compare = "loose"
+}
+`), &got, nil)
+	require.EqualError(t, err, `unexpected view.v.compare "loose", expect "exact", "normalized" or "ignore"`)
+}
+
+func TestMarshalSpec_Triggers(t *testing.T) {
+	s := schema.New("main").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("id", "int")).
+				AddTriggers(schema.NewTrigger("users_insert", "AFTER", []string{"INSERT"}, "BEGIN\n  SELECT 1;\nEND").SetWhen("NEW.id > 0")),
+		)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.main
+  column "id" {
+    null = false
+    type = int
+  }
+}
+trigger "users_insert" {
+  on     = table.users
+  timing = "AFTER"
+  events = ["INSERT"]
+  level  = "ROW"
+  when   = "NEW.id > 0"
+  as     = "BEGIN\n  SELECT 1;\nEND"
+}
+schema "main" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Tables, 1)
+	require.Len(t, got.Tables[0].Triggers, 1)
+	tr := got.Tables[0].Triggers[0]
+	require.Equal(t, "users_insert", tr.Name)
+	require.Equal(t, got.Tables[0], tr.Table)
+	require.Equal(t, []string{"INSERT"}, tr.Events)
+	require.True(t, tr.ForEachRow)
+	require.Equal(t, "NEW.id > 0", tr.When)
+	require.Equal(t, "BEGIN\n  SELECT 1;\nEND", tr.Body)
+
+	err = EvalHCLBytes([]byte(`
+schema "main" {}
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+}
+trigger "t" {
+  on     = table.users
+  timing = "AFTER"
+  events = ["INSERT"]
+  level  = "EACH"
+  as     = "BEGIN SELECT 1; END"
+}
+`), &got, nil)
+	require.EqualError(t, err, `unexpected trigger.t.level "EACH", expect "ROW" or "STATEMENT"`)
+}
+
 func TestMarshalSpec_IndexPredicate(t *testing.T) {
 	s := &schema.Schema{
 		Name: "test",
@@ -0,0 +1,227 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlserver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Default attributes of types, when they are declared without a length or precision.
+const (
+	defaultSize             = 1
+	defaultDecimalPrecision = 18
+	defaultTimePrecision    = 7
+	defaultFloatPrecision   = 53
+)
+
+// FormatType converts schema type to its column form in the database.
+// An error is returned if the type cannot be recognized.
+func FormatType(t schema.Type) (string, error) {
+	var f string
+	switch t := t.(type) {
+	case *schema.BoolType:
+		f = strings.ToLower(t.T)
+	case *schema.BinaryType:
+		switch f = strings.ToLower(t.T); f {
+		case TypeBinary, TypeVarBinary:
+			n := defaultSize
+			if t.Size != nil {
+				n = *t.Size
+			}
+			f = fmt.Sprintf("%s(%d)", f, n)
+		case TypeVarBinaryMax, TypeImage:
+		default:
+			return "", fmt.Errorf("sqlserver: unexpected binary type: %q", t.T)
+		}
+	case *schema.IntegerType:
+		f = strings.ToLower(t.T)
+	case *schema.DecimalType:
+		switch f = strings.ToLower(t.T); f {
+		case TypeDecimal, TypeNumeric:
+		default:
+			return "", fmt.Errorf("sqlserver: unexpected decimal type: %q", t.T)
+		}
+		p := t.Precision
+		if p == 0 {
+			p = defaultDecimalPrecision
+		}
+		if t.Scale < 0 || t.Scale > p {
+			return "", fmt.Errorf("sqlserver: decimal type must have scale between 0 and its precision: %d", t.Scale)
+		}
+		f = fmt.Sprintf("%s(%d,%d)", f, p, t.Scale)
+	case *schema.FloatType:
+		switch f = strings.ToLower(t.T); f {
+		case TypeReal:
+		// FLOAT(n) with n between 1 and 24 is stored as REAL.
+		case TypeFloat:
+			switch p := t.Precision; {
+			case p > 0 && p <= 24:
+				f = TypeReal
+			case p == 0 || p > 24 && p <= defaultFloatPrecision:
+			default:
+				return "", fmt.Errorf("sqlserver: precision for type float must be between 1 and 53: %d", p)
+			}
+		default:
+			return "", fmt.Errorf("sqlserver: unexpected float type: %q", t.T)
+		}
+	case *schema.StringType:
+		switch f = strings.ToLower(t.T); f {
+		case TypeChar, TypeVarchar, TypeNChar, TypeNVarchar:
+			n := t.Size
+			if n == 0 {
+				n = defaultSize
+			}
+			f = fmt.Sprintf("%s(%d)", f, n)
+		case TypeVarcharMax, TypeNVarcharMax, TypeText, TypeNText:
+		default:
+			return "", fmt.Errorf("sqlserver: unexpected string type: %q", t.T)
+		}
+	case *schema.TimeType:
+		switch f = strings.ToLower(t.T); f {
+		case TypeDate, TypeDateTime, TypeSmallDateTime:
+		case TypeTime, TypeDateTime2, TypeDateTimeOffset:
+			if p := t.Precision; p != nil && *p != defaultTimePrecision {
+				f = fmt.Sprintf("%s(%d)", f, *p)
+			}
+		default:
+			return "", fmt.Errorf("sqlserver: unexpected time type: %q", t.T)
+		}
+	case *schema.SpatialType:
+		f = strings.ToLower(t.T)
+	case *MoneyType:
+		f = strings.ToLower(t.T)
+	case *UUIDType:
+		f = strings.ToLower(t.T)
+	case *XMLType:
+		f = strings.ToLower(t.T)
+	case *RowVersionType:
+		f = strings.ToLower(t.T)
+	case *UserDefinedType:
+		f = t.T
+	case *schema.UnsupportedType:
+		return "", fmt.Errorf("sqlserver: unsupported type: %q", t.T)
+	default:
+		return "", fmt.Errorf("sqlserver: invalid schema type: %T", t)
+	}
+	return f, nil
+}
+
+// ParseType returns the schema.Type value represented by the given raw type.
+// The raw value is expected to follow the format of the CREATE TABLE statement,
+// e.g. "nvarchar(255)", "nvarchar(max)" or "decimal(10,2)".
+func ParseType(raw string) (schema.Type, error) {
+	t, args, err := parseColumn(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case TypeBit:
+		return &schema.BoolType{T: t}, nil
+	case TypeTinyInt, TypeSmallInt, TypeInt, TypeBigInt:
+		return &schema.IntegerType{T: t}, nil
+	case TypeDecimal, TypeNumeric:
+		dt := &schema.DecimalType{T: t, Precision: defaultDecimalPrecision}
+		if len(args) > 0 {
+			if dt.Precision, err = strconv.Atoi(args[0]); err != nil {
+				return nil, fmt.Errorf("sqlserver: parse precision %q: %w", args[0], err)
+			}
+		}
+		if len(args) > 1 {
+			if dt.Scale, err = strconv.Atoi(args[1]); err != nil {
+				return nil, fmt.Errorf("sqlserver: parse scale %q: %w", args[1], err)
+			}
+		}
+		return dt, nil
+	case TypeFloat:
+		ft := &schema.FloatType{T: t, Precision: defaultFloatPrecision}
+		if len(args) > 0 {
+			if ft.Precision, err = strconv.Atoi(args[0]); err != nil {
+				return nil, fmt.Errorf("sqlserver: parse precision %q: %w", args[0], err)
+			}
+		}
+		if ft.Precision <= 24 {
+			return &schema.FloatType{T: TypeReal, Precision: 24}, nil
+		}
+		return ft, nil
+	case TypeReal:
+		return &schema.FloatType{T: t, Precision: 24}, nil
+	case TypeMoney, TypeSmallMoney:
+		return &MoneyType{T: t}, nil
+	case TypeChar, TypeVarchar, TypeNChar, TypeNVarchar:
+		st := &schema.StringType{T: t, Size: defaultSize}
+		switch {
+		case len(args) == 0:
+		case strings.EqualFold(args[0], "max") && (t == TypeVarchar || t == TypeNVarchar):
+			return &schema.StringType{T: t + "(max)"}, nil
+		default:
+			if st.Size, err = strconv.Atoi(args[0]); err != nil {
+				return nil, fmt.Errorf("sqlserver: parse size %q: %w", args[0], err)
+			}
+		}
+		return st, nil
+	case TypeText, TypeNText:
+		return &schema.StringType{T: t}, nil
+	case TypeBinary, TypeVarBinary:
+		n := defaultSize
+		switch {
+		case len(args) == 0:
+		case strings.EqualFold(args[0], "max") && t == TypeVarBinary:
+			return &schema.BinaryType{T: TypeVarBinaryMax}, nil
+		default:
+			if n, err = strconv.Atoi(args[0]); err != nil {
+				return nil, fmt.Errorf("sqlserver: parse size %q: %w", args[0], err)
+			}
+		}
+		return &schema.BinaryType{T: t, Size: &n}, nil
+	case TypeImage:
+		return &schema.BinaryType{T: t}, nil
+	case TypeDate, TypeDateTime, TypeSmallDateTime:
+		return &schema.TimeType{T: t}, nil
+	case TypeTime, TypeDateTime2, TypeDateTimeOffset:
+		p := defaultTimePrecision
+		if len(args) > 0 {
+			if p, err = strconv.Atoi(args[0]); err != nil {
+				return nil, fmt.Errorf("sqlserver: parse precision %q: %w", args[0], err)
+			}
+		}
+		return &schema.TimeType{T: t, Precision: &p}, nil
+	case TypeUniqueIdentifier:
+		return &UUIDType{T: t}, nil
+	case TypeXML:
+		return &XMLType{T: t}, nil
+	// TIMESTAMP is a deprecated synonym of ROWVERSION.
+	case TypeRowVersion, "timestamp":
+		return &RowVersionType{T: TypeRowVersion}, nil
+	case TypeGeometry, TypeGeography:
+		return &schema.SpatialType{T: t}, nil
+	default:
+		// Types such as "sql_variant", "hierarchyid", or user-defined alias types.
+		return &UserDefinedType{T: t}, nil
+	}
+}
+
+// parseColumn splits the raw type into its lowered name and arguments.
+func parseColumn(raw string) (string, []string, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if s == "" {
+		return "", nil, fmt.Errorf("sqlserver: missing type")
+	}
+	i := strings.IndexByte(s, '(')
+	if i == -1 {
+		return s, nil, nil
+	}
+	if !strings.HasSuffix(s, ")") {
+		return "", nil, fmt.Errorf("sqlserver: malformed type %q", raw)
+	}
+	args := strings.Split(s[i+1:len(s)-1], ",")
+	for j := range args {
+		args[j] = strings.TrimSpace(args[j])
+	}
+	return strings.TrimSpace(s[:i]), args, nil
+}
@@ -0,0 +1,293 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlserver
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// IdentCase returns the identifier case options that match the given database
+// collation. Identifiers are case-insensitive in databases with case-insensitive
+// collations (e.g. SQL_Latin1_General_CP1_CI_AS), which is the default.
+func IdentCase(collation string) *schema.IdentCaseOptions {
+	for _, p := range strings.Split(strings.ToUpper(collation), "_") {
+		if p == "CS" || p == "BIN" || p == "BIN2" {
+			return &schema.IdentCaseOptions{}
+		}
+	}
+	return &schema.IdentCaseOptions{
+		Schemas: schema.IdentCaseInsensitive,
+		Tables:  schema.IdentCaseInsensitive,
+		Columns: schema.IdentCaseInsensitive,
+	}
+}
+
+// A diff provides a SQL Server implementation for sqlx.DiffDriver.
+type diff struct{ conn }
+
+// SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
+func (*diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
+	// Schemas in SQL Server have no attributes that can be altered.
+	return nil
+}
+
+// TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
+func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
+	if fileGroup(from.Attrs) != fileGroup(to.Attrs) {
+		return nil, fmt.Errorf("sqlserver: changing the filegroup of table %q is not supported", to.Name)
+	}
+	return sqlx.CheckDiff(from, to, func(c1, c2 *schema.Check) bool {
+		return unwrap(c1.Expr) == unwrap(c2.Expr)
+	}), nil
+}
+
+// ColumnChange returns the schema changes (if any) for migrating one column to the other.
+func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column) (schema.ChangeKind, error) {
+	var change schema.ChangeKind
+	if from.Type.Null != to.Type.Null {
+		change |= schema.ChangeNull
+	}
+	changed, err := d.typeChanged(from, to)
+	if err != nil {
+		return schema.NoChange, err
+	}
+	if changed {
+		change |= schema.ChangeType
+	}
+	if d.defaultChanged(from, to) {
+		change |= schema.ChangeDefault
+	}
+	if d.generatedChanged(from, to) {
+		change |= schema.ChangeGenerated
+	}
+	if d.collateChanged(from, to) {
+		change |= schema.ChangeCollate
+	}
+	if identityChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeAttr
+	}
+	return change, nil
+}
+
+// typeChanged reports if the column type was changed.
+func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
+	fromT, toT := from.Type.Type, to.Type.Type
+	if fromT == nil || toT == nil {
+		return false, fmt.Errorf("sqlserver: missing type information for column %q", from.Name)
+	}
+	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
+		return true, nil
+	}
+	ft, err := FormatType(fromT)
+	if err != nil {
+		return false, err
+	}
+	tt, err := FormatType(toT)
+	if err != nil {
+		return false, err
+	}
+	return !strings.EqualFold(ft, tt), nil
+}
+
+// defaultChanged reports if the default value of a column was changed.
+func (d *diff) defaultChanged(from, to *schema.Column) bool {
+	d1, ok1 := sqlx.DefaultValue(from)
+	d2, ok2 := sqlx.DefaultValue(to)
+	if ok1 != ok2 {
+		return true
+	}
+	if !ok1 {
+		return false
+	}
+	// Default definitions are stored wrapped with parentheses, e.g. "((0))".
+	d1, d2 = unwrap(d1), unwrap(d2)
+	if d1 == d2 {
+		return false
+	}
+	x1, err1 := sqlx.Unquote(strings.TrimPrefix(d1, "N"))
+	x2, err2 := sqlx.Unquote(strings.TrimPrefix(d2, "N"))
+	return err1 != nil || err2 != nil || x1 != x2
+}
+
+// generatedChanged reports if the generated expression of a column was changed.
+func (*diff) generatedChanged(from, to *schema.Column) bool {
+	var (
+		fromX, toX     schema.GeneratedExpr
+		fromHas, toHas = sqlx.Has(from.Attrs, &fromX), sqlx.Has(to.Attrs, &toX)
+	)
+	return fromHas != toHas || fromHas && (unwrap(fromX.Expr) != unwrap(toX.Expr) || persistedOrVirtual(fromX.Type) != persistedOrVirtual(toX.Type))
+}
+
+// collateChanged reports if the column collation was changed. Columns
+// that use the database default collation are not marked by inspection.
+func (d *diff) collateChanged(from, to *schema.Column) bool {
+	var c1, c2 schema.Collation
+	sqlx.Has(from.Attrs, &c1)
+	sqlx.Has(to.Attrs, &c2)
+	if c1.V == "" {
+		c1.V = d.collate
+	}
+	if c2.V == "" {
+		c2.V = d.collate
+	}
+	return !strings.EqualFold(c1.V, c2.V)
+}
+
+// IsGeneratedIndexName reports if the index name was generated by the database.
+// Unnamed UNIQUE constraints are named as "UQ__<table>__<hash>".
+func (*diff) IsGeneratedIndexName(t *schema.Table, idx *schema.Index) bool {
+	p := "UQ__" + t.Name
+	if len(p) > 12 {
+		p = p[:12]
+	}
+	return strings.HasPrefix(idx.Name, p) && strings.Contains(idx.Name[len(p):], "__")
+}
+
+// IndexAttrChanged reports if the index attributes were changed.
+func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
+	var p1, p2 IndexPredicate
+	if sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || unwrap(p1.P) != unwrap(p2.P) {
+		return true
+	}
+	var t1, t2 IndexType
+	sqlx.Has(from, &t1)
+	sqlx.Has(to, &t2)
+	return !strings.EqualFold(t1.T, t2.T) || fileGroup(from) != fileGroup(to)
+}
+
+// IndexPartAttrChanged reports if the index-part attributes were changed.
+func (*diff) IndexPartAttrChanged(_, _ *schema.IndexPart) bool {
+	return false
+}
+
+// ReferenceChanged reports if the foreign key referential action was changed.
+func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
+	// According to SQL Server, if an action is not explicitly
+	// specified, it defaults to "NO ACTION".
+	if from == "" {
+		from = schema.NoAction
+	}
+	if to == "" {
+		to = schema.NoAction
+	}
+	return from != to
+}
+
+// Normalize implements the sqlx.Normalizer interface.
+func (*diff) Normalize(from, to *schema.Table) error {
+	for _, t := range []*schema.Table{from, to} {
+		if t.PrimaryKey != nil {
+			t.PrimaryKey.Attrs = dropDefaultType(t.PrimaryKey.Attrs, IndexTypeClustered)
+		}
+		for _, idx := range t.Indexes {
+			idx.Attrs = dropDefaultType(idx.Attrs, IndexTypeNonClustered)
+		}
+	}
+	return nil
+}
+
+// dropDefaultType removes the IndexType attribute from the
+// given attributes in case it is the default (def) type.
+func dropDefaultType(attrs []schema.Attr, def string) []schema.Attr {
+	for i := range attrs {
+		if t, ok := attrs[i].(*IndexType); ok && strings.EqualFold(t.T, def) {
+			return append(attrs[:i:i], attrs[i+1:]...)
+		}
+	}
+	return attrs
+}
+
+// identityChanged reports if the identity of a column was changed.
+func identityChanged(from, to []schema.Attr) bool {
+	var i1, i2 Identity
+	if sqlx.Has(from, &i1) != sqlx.Has(to, &i2) {
+		return true
+	}
+	return identitySeed(&i1) != identitySeed(&i2) || identityIncrement(&i1) != identityIncrement(&i2)
+}
+
+// identitySeed returns the seed of the identity, or 1 if it is not set.
+func identitySeed(i *Identity) int64 {
+	if i.Seed == 0 {
+		return 1
+	}
+	return i.Seed
+}
+
+// identityIncrement returns the increment of the identity, or 1 if it is not set.
+func identityIncrement(i *Identity) int64 {
+	if i.Increment == 0 {
+		return 1
+	}
+	return i.Increment
+}
+
+// fileGroup returns the filegroup of the given attributes,
+// or an empty string in case it is stored in the default one.
+func fileGroup(attrs []schema.Attr) string {
+	var fg FileGroup
+	sqlx.Has(attrs, &fg)
+	return fg.V
+}
+
+// persistedOrVirtual returns a PERSISTED or VIRTUAL generated type option
+// based on the given string. Computed columns are not persisted by default.
+func persistedOrVirtual(s string) string {
+	if s = strings.ToUpper(s); s == persisted || s == "STORED" {
+		return persisted
+	}
+	return virtual
+}
+
+// reNumParens matches numeric literals that are wrapped with parentheses.
+var reNumParens = regexp.MustCompile(`\((-?\d+(?:\.\d+)?)\)`)
+
+// unwrap returns the normal form of the given expression for comparison, as
+// SQL Server stores the definitions of defaults, checks and computed columns
+// with brackets, spaces and parentheses removed or added, e.g. "([a]>(0))".
+func unwrap(x string) string {
+	x = strings.NewReplacer("[", "", "]", "", " ", "").Replace(trimParens(x))
+	return trimParens(reNumParens.ReplaceAllString(x, "$1"))
+}
+
+// trimParens removes the outer parentheses that wrap the whole expression.
+func trimParens(x string) string {
+	for x = strings.TrimSpace(x); wrapped(x); x = strings.TrimSpace(x[1 : len(x)-1]) {
+	}
+	return x
+}
+
+// wrapped reports if the opening parenthesis at the start of the
+// expression is closed by the one at its end, e.g. "(a + b)", but
+// not "(a) + (b)". Parentheses in string literals are ignored.
+func wrapped(x string) bool {
+	if len(x) < 2 || x[0] != '(' || x[len(x)-1] != ')' {
+		return false
+	}
+	var (
+		depth  int
+		quoted bool
+	)
+	for i := 0; i < len(x); i++ {
+		switch c := x[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 && i < len(x)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
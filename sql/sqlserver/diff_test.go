@@ -0,0 +1,151 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlserver
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_TableDiff(t *testing.T) {
+	type testcase struct {
+		name        string
+		from, to    *schema.Table
+		wantChanges []schema.Change
+		wantErr     bool
+	}
+	tests := []testcase{
+		{
+			name: "no changes",
+			from: schema.NewTable("users").SetSchema(schema.New("dbo")),
+			to:   schema.NewTable("users"),
+		},
+		{
+			name:    "change filegroup",
+			from:    schema.NewTable("users").AddAttrs(&FileGroup{V: "FG1"}),
+			to:      schema.NewTable("users"),
+			wantErr: true,
+		},
+		func() testcase {
+			var (
+				from = schema.NewTable("users").
+					AddColumns(
+						schema.NewIntColumn("id", TypeInt).AddAttrs(&Identity{Seed: 1, Increment: 1}),
+						schema.NewStringColumn("name", TypeNVarchar, schema.StringSize(255)),
+						schema.NewDecimalColumn("price", TypeDecimal, schema.DecimalPrecision(10), schema.DecimalScale(2)).
+							SetDefault(&schema.Literal{V: "0"}).
+							AddAttrs(&DefaultConstraint{Name: "DF__users__price__3B75D760"}),
+						schema.NewNullDecimalColumn("total", TypeDecimal, schema.DecimalPrecision(11), schema.DecimalScale(2)).
+							SetGeneratedExpr(&schema.GeneratedExpr{Expr: "([price]*(2))", Type: persisted}),
+						schema.NewTimeColumn("created_at", TypeDateTime2, schema.TimePrecision(7)).
+							SetDefault(&schema.RawExpr{X: "getdate()"}),
+					).
+					AddChecks(schema.NewCheck().SetName("ck_price").SetExpr("([price]>=(0))"))
+				to = schema.NewTable("users").
+					AddColumns(
+						schema.NewIntColumn("id", TypeInt).AddAttrs(&Identity{}),
+						schema.NewStringColumn("name", TypeNVarchar, schema.StringSize(255)),
+						schema.NewDecimalColumn("price", TypeDecimal, schema.DecimalPrecision(10), schema.DecimalScale(2)).
+							SetDefault(&schema.Literal{V: "0"}),
+						schema.NewNullDecimalColumn("total", TypeDecimal, schema.DecimalPrecision(11), schema.DecimalScale(2)).
+							SetGeneratedExpr(&schema.GeneratedExpr{Expr: "price * 2", Type: "STORED"}),
+						schema.NewTimeColumn("created_at", TypeDateTime2).
+							SetDefault(&schema.RawExpr{X: "(getdate())"}),
+					).
+					AddChecks(schema.NewCheck().SetName("ck_price").SetExpr("price >= 0"))
+			)
+			return testcase{
+				name: "no normalized changes",
+				from: from,
+				to:   to,
+			}
+		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("users").
+					AddColumns(
+						schema.NewIntColumn("id", TypeInt).AddAttrs(&Identity{Seed: 1, Increment: 1}),
+						schema.NewStringColumn("name", TypeNVarchar, schema.StringSize(255)).
+							SetDefault(&schema.Literal{V: "N'a'"}),
+						schema.NewNullStringColumn("bio", TypeNVarcharMax).
+							SetCollation("Latin1_General_BIN"),
+					)
+				to = schema.NewTable("users").
+					AddColumns(
+						schema.NewIntColumn("id", TypeInt).AddAttrs(&Identity{Seed: 100}),
+						schema.NewStringColumn("name", TypeNVarchar, schema.StringSize(100)).
+							SetDefault(&schema.Literal{V: "b"}),
+						schema.NewNullStringColumn("bio", TypeNVarcharMax),
+					)
+			)
+			return testcase{
+				name: "modify columns",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeAttr},
+					&schema.ModifyColumn{From: from.Columns[1], To: to.Columns[1], Change: schema.ChangeType | schema.ChangeDefault},
+					&schema.ModifyColumn{From: from.Columns[2], To: to.Columns[2], Change: schema.ChangeCollate},
+				},
+			}
+		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", TypeInt))
+				to   = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", TypeInt))
+			)
+			from.SetPrimaryKey(schema.NewPrimaryKey(from.Columns...).SetName("PK_users"))
+			from.AddIndexes(
+				schema.NewIndex("ix_1").AddColumns(from.Columns...),
+				schema.NewIndex("ix_2").AddColumns(from.Columns...).AddAttrs(&IndexPredicate{P: "([id]>(0))"}),
+				schema.NewIndex("ix_3").AddColumns(from.Columns...),
+				schema.NewUniqueIndex("UQ__users__3213E83E1DB4E4A6").AddColumns(from.Columns...).AddAttrs(&UniqueConstraint{}),
+			)
+			to.SetPrimaryKey(schema.NewPrimaryKey(to.Columns...).AddAttrs(&IndexType{T: IndexTypeClustered}))
+			to.AddIndexes(
+				schema.NewIndex("ix_1").AddColumns(to.Columns...).AddAttrs(&IndexType{T: IndexTypeNonClustered}),
+				schema.NewIndex("ix_2").AddColumns(to.Columns...).AddAttrs(&IndexPredicate{P: "id > 0"}),
+				schema.NewIndex("ix_3").AddColumns(to.Columns...).AddAttrs(&IndexType{T: IndexTypeClustered}),
+				schema.NewUniqueIndex("").AddColumns(to.Columns...),
+			)
+			return testcase{
+				name: "indexes",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyIndex{From: from.Indexes[2], To: to.Indexes[2], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := DefaultDiff.TableDiff(tt.from, tt.to)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.EqualValues(t, tt.wantChanges, changes)
+		})
+	}
+}
+
+func TestDiff_ReferenceChanged(t *testing.T) {
+	d := &diff{}
+	require.False(t, d.ReferenceChanged("", schema.NoAction))
+	require.True(t, d.ReferenceChanged(schema.Cascade, schema.NoAction))
+}
+
+func TestIdentCase(t *testing.T) {
+	ci := &schema.IdentCaseOptions{Schemas: schema.IdentCaseInsensitive, Tables: schema.IdentCaseInsensitive, Columns: schema.IdentCaseInsensitive}
+	require.Equal(t, ci, IdentCase("SQL_Latin1_General_CP1_CI_AS"))
+	require.Equal(t, ci, IdentCase(""))
+	require.Equal(t, &schema.IdentCaseOptions{}, IdentCase("Latin1_General_100_CS_AS"))
+	require.Equal(t, &schema.IdentCaseOptions{}, IdentCase("Latin1_General_BIN2"))
+	opts, err := (&Driver{conn: conn{collate: "Latin1_General_100_CS_AS"}}).IdentCase(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &schema.IdentCaseOptions{}, opts)
+}
@@ -0,0 +1,366 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqlserver implements the Atlas driver for Microsoft SQL Server. Note, the
+// package does not register a database/sql driver. Programs that open connections
+// using the "sqlserver://" scheme should import one, e.g. github.com/microsoft/go-mssqldb.
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+type (
+	// Driver represents a SQL Server driver for introspecting database schemas,
+	// generating diff between schema elements and apply migrations changes.
+	Driver struct {
+		conn
+		schema.Differ
+		schema.Inspector
+		migrate.PlanApplier
+	}
+
+	// database connection and its information.
+	conn struct {
+		schema.ExecQuerier
+		// System variables that are set on `Open`.
+		version string
+		schema  string
+		collate string
+	}
+)
+
+var (
+	// DefaultDiff provides basic diffing capabilities for SQL Server dialects.
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// Differ when a database connection is available.
+	DefaultDiff schema.Differ = &sqlx.Diff{DiffDriver: &diff{}}
+
+	// DefaultPlan provides basic planning capabilities for SQL Server dialects, for example,
+	// for compiling a schema to SQL statements without a database connection. The changes
+	// are planned for SQL Server 2019, unless a target version is given (see migrate.PlanOptions).
+	// Note, it is recommended to call Open, create a new Driver and use its
+	// migrate.PlanApplier when a database connection is available.
+	DefaultPlan migrate.PlanApplier = &planApply{conn{ExecQuerier: sqlx.NoRows, version: "15.0.2000.5", schema: defaultSchema}}
+)
+
+// DriverName holds the name used for registration.
+const DriverName = "sqlserver"
+
+func init() {
+	sqlclient.Register(
+		DriverName,
+		sqlclient.DriverOpener(Open),
+		sqlclient.RegisterCodec(MarshalHCL, EvalHCL),
+		sqlclient.RegisterFlavours("mssql"),
+		sqlclient.RegisterURLParser(parser{}),
+	)
+}
+
+// Open opens a new SQL Server driver.
+func Open(db schema.ExecQuerier) (migrate.Driver, error) {
+	c := conn{ExecQuerier: db}
+	rows, err := db.QueryContext(context.Background(), variablesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("sqlserver: query system variables: %w", err)
+	}
+	if err := sqlx.ScanOne(rows, &c.version, &c.schema, &c.collate); err != nil {
+		return nil, fmt.Errorf("sqlserver: scan system variables: %w", err)
+	}
+	return &Driver{
+		conn:        c,
+		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
+		Inspector:   &inspect{c},
+		PlanApplier: &planApply{c},
+	}, nil
+}
+
+func (d *Driver) dev() *sqlx.DevDriver {
+	return &sqlx.DevDriver{Driver: d, MaxNameLen: 128}
+}
+
+// NormalizeRealm returns the normal representation of the given database.
+func (d *Driver) NormalizeRealm(ctx context.Context, r *schema.Realm) (*schema.Realm, error) {
+	return d.dev().NormalizeRealm(ctx, r)
+}
+
+// NormalizeSchema returns the normal representation of the given database.
+func (d *Driver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*schema.Schema, error) {
+	return d.dev().NormalizeSchema(ctx, s)
+}
+
+// IdentCase returns the identifier case options that match the
+// collation of the connected database.
+func (d *Driver) IdentCase(context.Context) (*schema.IdentCaseOptions, error) {
+	return IdentCase(d.collate), nil
+}
+
+// Capabilities implements the migrate.CapabilitiesReporter interface.
+func (d *Driver) Capabilities(version string) (*migrate.Capabilities, error) {
+	c := d.conn
+	if version != "" {
+		if !validVersion(version) {
+			return nil, fmt.Errorf("sqlserver: malformed version: %s", version)
+		}
+		c.version = version
+	}
+	return c.capabilities(), nil
+}
+
+// capabilities returns the capabilities of the connection version.
+func (c *conn) capabilities() *migrate.Capabilities {
+	return &migrate.Capabilities{
+		Driver:  DriverName,
+		Version: c.version,
+		Supports: map[migrate.Capability]bool{
+			migrate.CapTransactionalDDL: true,
+			// ONLINE index operations are limited to the Enterprise edition.
+			migrate.CapConcurrentIndex: false,
+			// Nullable columns (and columns with runtime-constant
+			// defaults) are added as metadata-only operations.
+			migrate.CapInstantAddColumn: true,
+			// Columns are renamed using sp_rename.
+			migrate.CapRenameColumn:     true,
+			migrate.CapCheckConstraint:  true,
+			migrate.CapIndexExpr:        false,
+			migrate.CapGeneratedColumn:  true,
+			migrate.CapInvisibleIndex:   false,
+			migrate.CapIndexInclude:     false,
+			migrate.CapNullsNotDistinct: false,
+		},
+	}
+}
+
+// Lock implements the schema.Locker interface.
+func (d *Driver) Lock(ctx context.Context, name string, timeout time.Duration) (schema.UnlockFunc, error) {
+	conn, err := sqlx.SingleConn(ctx, d.ExecQuerier)
+	if err != nil {
+		return nil, err
+	}
+	if err := acquire(ctx, conn, name, timeout); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return func() error {
+		defer conn.Close()
+		rows, err := conn.QueryContext(ctx, releaseLockQuery, name)
+		if err != nil {
+			return err
+		}
+		var status int
+		switch err := sqlx.ScanOne(rows, &status); {
+		case err != nil:
+			return err
+		case status < 0:
+			return fmt.Errorf("sql/sqlserver: failed releasing an application lock %q", name)
+		}
+		return nil
+	}, nil
+}
+
+// acquire acquires a session-owned application lock. A status >= 0
+// indicates success, -1 indicates a timeout and the rest are errors.
+// See: https://learn.microsoft.com/sql/relational-databases/system-stored-procedures/sp-getapplock-transact-sql.
+func acquire(ctx context.Context, conn schema.ExecQuerier, name string, timeout time.Duration) error {
+	rows, err := conn.QueryContext(ctx, acquireLockQuery, name, timeout.Milliseconds())
+	if err != nil {
+		return err
+	}
+	var status int
+	switch err := sqlx.ScanOne(rows, &status); {
+	case err != nil:
+		return err
+	case status == -1:
+		return schema.ErrLocked
+	case status < 0:
+		return fmt.Errorf("sql/sqlserver: unexpected status %d on Lock(%q, %s)", status, name, timeout)
+	}
+	return nil
+}
+
+// Snapshot implements migrate.Snapshoter.
+func (d *Driver) Snapshot(ctx context.Context) (migrate.RestoreFunc, error) {
+	// The connection is always bound to a schema (the default schema of the user),
+	// and we can restore the state if the schema has no tables.
+	s, err := d.InspectSchema(ctx, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Tables) > 0 {
+		return nil, migrate.NotCleanError{Reason: fmt.Sprintf("found table %q in schema %q", s.Tables[0].Name, s.Name)}
+	}
+	return func(ctx context.Context) error {
+		current, err := d.InspectSchema(ctx, s.Name, nil)
+		if err != nil {
+			return err
+		}
+		changes, err := d.SchemaDiff(current, s)
+		if err != nil {
+			return err
+		}
+		return d.ApplyChanges(ctx, changes)
+	}, nil
+}
+
+// CheckClean implements migrate.CleanChecker.
+func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error {
+	s, err := d.InspectSchema(ctx, "", nil)
+	if err != nil {
+		return err
+	}
+	if len(s.Tables) == 0 || ((revT.Schema == "" || s.Name == revT.Schema) && len(s.Tables) == 1 && s.Tables[0].Name == revT.Name) {
+		return nil
+	}
+	return &migrate.NotCleanError{Reason: fmt.Sprintf("found table %q in schema %q", s.Tables[0].Name, s.Name)}
+}
+
+var (
+	reDupKey    = regexp.MustCompile(`(?:UNIQUE KEY|PRIMARY KEY) constraint '(.+?)'\. Cannot insert duplicate key in object '(.+?)'\. The duplicate key value is \((.*)\)`)
+	reDupIndex  = regexp.MustCompile(`Cannot insert duplicate key row in object '(.+?)' with unique index '(.+?)'\. The duplicate key value is \((.*)\)`)
+	reConflict  = regexp.MustCompile(`statement conflicted with the (FOREIGN KEY|REFERENCE|CHECK) constraint "(.+?)"\. The conflict occurred in database ".+?", table "(.+?)"(?:, column '(.+?)')?`)
+	reNullValue = regexp.MustCompile(`Cannot insert the value NULL into column '(.+?)', table '(.+?)'`)
+)
+
+// ConstraintError implements migrate.ConstraintErrorParser.
+func (*Driver) ConstraintError(err error) (*migrate.ConstraintError, bool) {
+	msg := err.Error()
+	switch {
+	case reDupKey.MatchString(msg):
+		m := reDupKey.FindStringSubmatch(msg)
+		return &migrate.ConstraintError{Kind: "unique", Table: unqualify(m[2]), Constraint: m[1], Values: []string{m[3]}}, true
+	case reDupIndex.MatchString(msg):
+		m := reDupIndex.FindStringSubmatch(msg)
+		return &migrate.ConstraintError{Kind: "unique", Table: unqualify(m[1]), Constraint: m[2], Values: []string{m[3]}}, true
+	case reConflict.MatchString(msg):
+		m := reConflict.FindStringSubmatch(msg)
+		cerr := &migrate.ConstraintError{Kind: "check", Table: unqualify(m[3]), Constraint: m[2]}
+		if m[1] != "CHECK" {
+			cerr.Kind = "foreign key"
+		}
+		if m[4] != "" {
+			cerr.Columns = []string{m[4]}
+		}
+		return cerr, true
+	case reNullValue.MatchString(msg):
+		m := reNullValue.FindStringSubmatch(msg)
+		return &migrate.ConstraintError{Kind: "not null", Table: unqualify(m[2]), Columns: []string{m[1]}}, true
+	}
+	return nil, false
+}
+
+// unqualify returns the unqualified name of the given
+// identifier, e.g. "db.dbo.users" is returned as "users".
+func unqualify(s string) string {
+	parts := strings.Split(s, ".")
+	return strings.Trim(parts[len(parts)-1], "[]")
+}
+
+// parser implements the sqlclient.URLParser and the sqlclient.SchemaChanger
+// interfaces. The connected schema is configured using the "schema" query
+// parameter, as SQL Server connections are bound to a database, and not
+// to a schema.
+type parser struct{}
+
+// ParseURL implements the sqlclient.URLParser interface.
+func (parser) ParseURL(u *url.URL) *sqlclient.URL {
+	v := u.Query()
+	s := v.Get("schema")
+	v.Del("schema")
+	du := *u
+	du.RawQuery = v.Encode()
+	return &sqlclient.URL{URL: u, DSN: du.String(), Schema: s}
+}
+
+// ChangeSchema implements the sqlclient.SchemaChanger interface.
+func (parser) ChangeSchema(u *url.URL, s string) *url.URL {
+	nu := *u
+	v := nu.Query()
+	v.Set("schema", s)
+	nu.RawQuery = v.Encode()
+	return &nu
+}
+
+// validVersion reports if the given product version is valid, e.g. "16.0.1000.6".
+func validVersion(v string) bool {
+	parts := strings.Split(v, ".")
+	if len(parts) > 4 {
+		return false
+	}
+	for _, p := range parts {
+		if _, err := strconv.ParseUint(p, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// The default schema of SQL Server databases.
+const defaultSchema = "dbo"
+
+// SQL Server data types. See: https://learn.microsoft.com/sql/t-sql/data-types/data-types-transact-sql.
+const (
+	TypeBit              = "bit"
+	TypeTinyInt          = "tinyint"
+	TypeSmallInt         = "smallint"
+	TypeInt              = "int"
+	TypeBigInt           = "bigint"
+	TypeDecimal          = "decimal"
+	TypeNumeric          = "numeric"
+	TypeMoney            = "money"
+	TypeSmallMoney       = "smallmoney"
+	TypeFloat            = "float"
+	TypeReal             = "real"
+	TypeDate             = "date"
+	TypeTime             = "time"
+	TypeDateTime         = "datetime"
+	TypeDateTime2        = "datetime2"
+	TypeDateTimeOffset   = "datetimeoffset"
+	TypeSmallDateTime    = "smalldatetime"
+	TypeChar             = "char"
+	TypeVarchar          = "varchar"
+	TypeText             = "text"
+	TypeNChar            = "nchar"
+	TypeNVarchar         = "nvarchar"
+	TypeNText            = "ntext"
+	TypeBinary           = "binary"
+	TypeVarBinary        = "varbinary"
+	TypeImage            = "image"
+	TypeUniqueIdentifier = "uniqueidentifier"
+	TypeXML              = "xml"
+	TypeRowVersion       = "rowversion"
+	TypeSQLVariant       = "sql_variant"
+	TypeHierarchyID      = "hierarchyid"
+	TypeGeometry         = "geometry"
+	TypeGeography        = "geography"
+)
+
+// Variable-length types that are declared with the MAX size specifier.
+const (
+	TypeVarcharMax   = "varchar(max)"
+	TypeNVarcharMax  = "nvarchar(max)"
+	TypeVarBinaryMax = "varbinary(max)"
+)
+
+// Index types. See: https://learn.microsoft.com/sql/relational-databases/indexes/clustered-and-nonclustered-indexes-described.
+const (
+	IndexTypeClustered    = "CLUSTERED"
+	IndexTypeNonClustered = "NONCLUSTERED"
+)
+
+// Computed columns types.
+const (
+	persisted = "PERSISTED"
+	virtual   = "VIRTUAL"
+)
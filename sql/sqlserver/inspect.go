@@ -0,0 +1,627 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// An inspect provides a SQL Server implementation for schema.Inspector.
+type inspect struct{ conn }
+
+var _ schema.Inspector = (*inspect)(nil)
+
+// InspectRealm returns schema descriptions of all resources in the given realm.
+func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	schemas, err := i.schemas(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &schema.InspectRealmOption{}
+	}
+	r := schema.NewRealm(schemas...).SetCollation(i.collate)
+	if len(schemas) == 0 || !sqlx.ModeInspectRealm(opts).Is(schema.InspectTables) {
+		return sqlx.ExcludeRealm(r, opts.Exclude)
+	}
+	if err := i.inspectTables(ctx, r, nil); err != nil {
+		return nil, err
+	}
+	sqlx.LinkSchemaTables(schemas)
+	return sqlx.ExcludeRealm(r, opts.Exclude)
+}
+
+// InspectSchema returns schema descriptions of the tables in the given schema.
+// If the schema name is empty, the result will be the default schema of the user.
+func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (s *schema.Schema, err error) {
+	schemas, err := i.schemas(ctx, &schema.InspectRealmOption{Schemas: []string{name}})
+	if err != nil {
+		return nil, err
+	}
+	switch n := len(schemas); {
+	case n == 0:
+		return nil, &schema.NotExistError{Err: fmt.Errorf("sqlserver: schema %q was not found", name)}
+	case n > 1:
+		return nil, fmt.Errorf("sqlserver: %d schemas were found for %q", n, name)
+	}
+	if opts == nil {
+		opts = &schema.InspectOptions{}
+	}
+	r := schema.NewRealm(schemas...).SetCollation(i.collate)
+	if sqlx.ModeInspectSchema(opts).Is(schema.InspectTables) {
+		if err := i.inspectTables(ctx, r, opts); err != nil {
+			return nil, err
+		}
+		sqlx.LinkSchemaTables(schemas)
+	}
+	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
+}
+
+func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
+	if err := i.tables(ctx, r, opts); err != nil {
+		return err
+	}
+	for _, s := range r.Schemas {
+		if len(s.Tables) == 0 {
+			continue
+		}
+		if err := i.columns(ctx, s); err != nil {
+			return err
+		}
+		if err := i.indexes(ctx, s); err != nil {
+			return err
+		}
+		if err := i.fks(ctx, s); err != nil {
+			return err
+		}
+		if err := i.checks(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemas returns the list of the schemas in the database.
+func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
+	var (
+		args  []any
+		query = schemasQuery
+	)
+	if opts != nil {
+		switch n := len(opts.Schemas); {
+		case n == 1 && opts.Schemas[0] == "":
+			query = fmt.Sprintf(schemasQueryArgs, "= SCHEMA_NAME()")
+		case n == 1 && opts.Schemas[0] != "":
+			query = fmt.Sprintf(schemasQueryArgs, "= @p1")
+			args = append(args, opts.Schemas[0])
+		case n > 0:
+			query = fmt.Sprintf(schemasQueryArgs, "IN ("+nArgs(0, len(opts.Schemas))+")")
+			for _, s := range opts.Schemas {
+				args = append(args, s)
+			}
+		}
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlserver: querying schemas: %w", err)
+	}
+	names, err := sqlx.ScanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("sqlserver: scanning schemas: %w", err)
+	}
+	schemas := make([]*schema.Schema, 0, len(names))
+	for _, name := range names {
+		schemas = append(schemas, schema.New(name))
+	}
+	return schemas, nil
+}
+
+// tables queries and adds the tables of the realm schemas.
+func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.InspectOptions) error {
+	var (
+		args  []any
+		query = fmt.Sprintf(tablesQuery, nArgs(0, len(realm.Schemas)))
+	)
+	for _, s := range realm.Schemas {
+		args = append(args, s.Name)
+	}
+	if opts != nil && len(opts.Tables) > 0 {
+		for _, t := range opts.Tables {
+			args = append(args, t)
+		}
+		query = fmt.Sprintf(tablesQueryArgs, nArgs(0, len(realm.Schemas)), nArgs(len(realm.Schemas), len(opts.Tables)))
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("sqlserver: querying tables: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			isDefault         bool
+			tSchema, name, fg string
+		)
+		if err := rows.Scan(&tSchema, &name, &fg, &isDefault); err != nil {
+			return fmt.Errorf("sqlserver: scan table information: %w", err)
+		}
+		s, ok := realm.Schema(tSchema)
+		if !ok {
+			return fmt.Errorf("sqlserver: schema %q was not found in realm", tSchema)
+		}
+		t := schema.NewTable(name)
+		s.AddTables(t)
+		// Tables that are stored in the default filegroup are not marked.
+		if !isDefault {
+			t.AddAttrs(&FileGroup{V: fg})
+		}
+	}
+	return rows.Close()
+}
+
+// columns queries and appends the columns of the given table.
+func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, columnsQuery, s)
+	if err != nil {
+		return fmt.Errorf("sqlserver: query schema %q columns: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := i.addColumn(s, rows); err != nil {
+			return fmt.Errorf("sqlserver: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// addColumn scans the current row and adds a new column from it to the table.
+func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) error {
+	var (
+		nullable, identity, stored        bool
+		size, precision, scale            int64
+		seed, increment                   sql.NullInt64
+		table, name, typ                  string
+		expr, defName, defExpr, collation sql.NullString
+	)
+	if err := rows.Scan(&table, &name, &typ, &size, &precision, &scale, &nullable, &identity, &seed, &increment, &expr, &stored, &defName, &defExpr, &collation); err != nil {
+		return err
+	}
+	t, ok := s.Table(table)
+	if !ok {
+		return fmt.Errorf("table %q was not found in schema", table)
+	}
+	c := &schema.Column{
+		Name: name,
+		Type: &schema.ColumnType{
+			Raw:  rawType(typ, size, precision, scale),
+			Null: nullable,
+		},
+	}
+	ct, err := ParseType(c.Type.Raw)
+	if err != nil {
+		return err
+	}
+	c.Type.Type = ct
+	if identity {
+		c.Attrs = append(c.Attrs, &Identity{Seed: seed.Int64, Increment: increment.Int64})
+	}
+	if sqlx.ValidString(expr) {
+		x := &schema.GeneratedExpr{Expr: expr.String, Type: virtual}
+		if stored {
+			x.Type = persisted
+		}
+		c.SetGeneratedExpr(x)
+	}
+	if sqlx.ValidString(defExpr) {
+		c.Default = defaultExpr(defExpr.String)
+		c.Attrs = append(c.Attrs, &DefaultConstraint{Name: defName.String})
+	}
+	if sqlx.ValidString(collation) && collation.String != i.collate {
+		c.SetCollation(collation.String)
+	}
+	t.AddColumns(c)
+	return nil
+}
+
+// rawType returns the column type in its CREATE TABLE form
+// from its description in the system catalog.
+func rawType(typ string, size, precision, scale int64) string {
+	switch typ = strings.ToLower(typ); typ {
+	case TypeChar, TypeVarchar, TypeBinary, TypeVarBinary:
+		if size == -1 {
+			return typ + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", typ, size)
+	// Sizes of unicode strings are reported in bytes.
+	case TypeNChar, TypeNVarchar:
+		if size == -1 {
+			return typ + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", typ, size/2)
+	case TypeDecimal, TypeNumeric:
+		return fmt.Sprintf("%s(%d,%d)", typ, precision, scale)
+	case TypeFloat:
+		return fmt.Sprintf("%s(%d)", typ, precision)
+	case TypeTime, TypeDateTime2, TypeDateTimeOffset:
+		return fmt.Sprintf("%s(%d)", typ, scale)
+	default:
+		return typ
+	}
+}
+
+// defaultExpr returns the default value of a column from its definition in
+// the system catalog. Definitions are wrapped with parentheses, e.g. "((0))".
+func defaultExpr(x string) schema.Expr {
+	x = trimParens(x)
+	switch {
+	case sqlx.IsLiteralNumber(x), sqlx.IsQuoted(x, '\''), strings.HasPrefix(x, "N'") && sqlx.IsQuoted(x[1:], '\''):
+		return &schema.Literal{V: x}
+	default:
+		return &schema.RawExpr{X: x}
+	}
+}
+
+// indexes queries and appends the indexes of the given table.
+func (i *inspect) indexes(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, indexesQuery, s)
+	if err != nil {
+		return fmt.Errorf("sqlserver: query schema %q indexes: %w", s.Name, err)
+	}
+	defer rows.Close()
+	if err := i.addIndexes(s, rows); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// addIndexes scans the rows and adds the indexes to the table.
+func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
+	for rows.Next() {
+		var (
+			seqno                                      int
+			table, name, typ, column, fg               string
+			unique, primary, constraint, desc, isDefFG bool
+			pred                                       sql.NullString
+		)
+		if err := rows.Scan(&table, &name, &typ, &unique, &primary, &constraint, &seqno, &column, &desc, &fg, &isDefFG, &pred); err != nil {
+			return fmt.Errorf("sqlserver: scanning indexes for schema %q: %w", s.Name, err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("sqlserver: table %q was not found in schema", table)
+		}
+		idx, ok := t.Index(name)
+		if primary && t.PrimaryKey != nil {
+			idx, ok = t.PrimaryKey, true
+		}
+		if !ok {
+			idx = &schema.Index{Name: name, Unique: unique, Table: t}
+			// Only types that are not the default are kept, i.e.
+			// clustered indexes and non-clustered primary keys.
+			if typ == IndexTypeClustered != primary {
+				idx.AddAttrs(&IndexType{T: typ})
+			}
+			if !isDefFG {
+				idx.AddAttrs(&FileGroup{V: fg})
+			}
+			if sqlx.ValidString(pred) {
+				idx.AddAttrs(&IndexPredicate{P: pred.String})
+			}
+			if constraint {
+				idx.AddAttrs(&UniqueConstraint{})
+			}
+			if primary {
+				t.SetPrimaryKey(idx)
+			} else {
+				t.AddIndexes(idx)
+			}
+		}
+		part := &schema.IndexPart{SeqNo: seqno, Desc: desc}
+		if part.C, ok = t.Column(column); !ok {
+			return fmt.Errorf("sqlserver: column %q was not found for index %q", column, idx.Name)
+		}
+		part.C.Indexes = append(part.C.Indexes, idx)
+		idx.Parts = append(idx.Parts, part)
+	}
+	return nil
+}
+
+// fks queries and appends the foreign keys of the given table.
+func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, fksQuery, s)
+	if err != nil {
+		return fmt.Errorf("sqlserver: querying schema %q foreign keys: %w", s.Name, err)
+	}
+	defer rows.Close()
+	if err := sqlx.SchemaFKs(s, rows); err != nil {
+		return fmt.Errorf("sqlserver: %w", err)
+	}
+	return rows.Err()
+}
+
+// checks queries and appends the check constraints of the given table.
+func (i *inspect) checks(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, checksQuery, s)
+	if err != nil {
+		return fmt.Errorf("sqlserver: querying schema %q check constraints: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, name, expr string
+		if err := rows.Scan(&table, &name, &expr); err != nil {
+			return fmt.Errorf("sqlserver: scanning check: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("sqlserver: table %q was not found in schema", table)
+		}
+		t.AddChecks(&schema.Check{Name: name, Expr: expr})
+	}
+	return rows.Err()
+}
+
+// querySchema queries the rows of the given schema tables. The schema name
+// is passed as the first argument, followed by the table names.
+func (i *inspect) querySchema(ctx context.Context, query string, s *schema.Schema) (*sql.Rows, error) {
+	args := []any{s.Name}
+	for _, t := range s.Tables {
+		args = append(args, t.Name)
+	}
+	return i.QueryContext(ctx, fmt.Sprintf(query, nArgs(1, len(s.Tables))), args...)
+}
+
+// nArgs returns n placeholders starting after the given offset, e.g. "@p2, @p3".
+func nArgs(offset, n int) string {
+	ps := make([]string, n)
+	for i := range ps {
+		ps[i] = "@p" + strconv.Itoa(offset+i+1)
+	}
+	return strings.Join(ps, ", ")
+}
+
+const (
+	// Query to list system variables.
+	variablesQuery = "SELECT CAST(SERVERPROPERTY('ProductVersion') AS nvarchar(128)), SCHEMA_NAME(), CAST(DATABASEPROPERTYEX(DB_NAME(), 'Collation') AS nvarchar(128))"
+
+	// Queries to acquire and release session-owned application locks.
+	acquireLockQuery = "DECLARE @r int; EXEC @r = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = @p2; SELECT @r"
+	releaseLockQuery = "DECLARE @r int; EXEC @r = sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'; SELECT @r"
+
+	// Query to list database schemas. Fixed database roles and system schemas are excluded.
+	schemasQuery = "SELECT [name] FROM [sys].[schemas] WHERE [schema_id] < 16384 AND [name] NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest') ORDER BY [name]"
+
+	// Query to list specific database schemas.
+	schemasQueryArgs = "SELECT [name] FROM [sys].[schemas] WHERE [schema_id] < 16384 AND [name] NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest') AND [name] %s ORDER BY [name]"
+
+	// Query to list schema tables, and the filegroup they are stored in.
+	tablesQuery = `
+SELECT
+	s.name AS table_schema,
+	t.name AS table_name,
+	ds.name AS filegroup,
+	ds.is_default
+FROM
+	sys.tables AS t
+	JOIN sys.schemas AS s ON s.schema_id = t.schema_id
+	JOIN sys.indexes AS i ON i.object_id = t.object_id AND i.index_id IN (0, 1)
+	JOIN sys.data_spaces AS ds ON ds.data_space_id = i.data_space_id
+WHERE
+	t.is_ms_shipped = 0
+	AND s.name IN (%s)
+ORDER BY
+	s.name, t.name
+`
+
+	// Query to list specific schema tables.
+	tablesQueryArgs = `
+SELECT
+	s.name AS table_schema,
+	t.name AS table_name,
+	ds.name AS filegroup,
+	ds.is_default
+FROM
+	sys.tables AS t
+	JOIN sys.schemas AS s ON s.schema_id = t.schema_id
+	JOIN sys.indexes AS i ON i.object_id = t.object_id AND i.index_id IN (0, 1)
+	JOIN sys.data_spaces AS ds ON ds.data_space_id = i.data_space_id
+WHERE
+	t.is_ms_shipped = 0
+	AND s.name IN (%s)
+	AND t.name IN (%s)
+ORDER BY
+	s.name, t.name
+`
+
+	// Query to list table columns.
+	columnsQuery = `
+SELECT
+	t.name AS table_name,
+	c.name AS column_name,
+	ty.name AS data_type,
+	c.max_length,
+	c.precision,
+	c.scale,
+	c.is_nullable,
+	c.is_identity,
+	CAST(ic.seed_value AS bigint) AS seed_value,
+	CAST(ic.increment_value AS bigint) AS increment_value,
+	cc.definition AS computed_definition,
+	COALESCE(cc.is_persisted, 0) AS is_persisted,
+	dc.name AS default_name,
+	dc.definition AS default_definition,
+	c.collation_name
+FROM
+	sys.columns AS c
+	JOIN sys.tables AS t ON t.object_id = c.object_id
+	JOIN sys.schemas AS s ON s.schema_id = t.schema_id
+	JOIN sys.types AS ty ON ty.user_type_id = c.user_type_id
+	LEFT JOIN sys.identity_columns AS ic ON ic.object_id = c.object_id AND ic.column_id = c.column_id
+	LEFT JOIN sys.computed_columns AS cc ON cc.object_id = c.object_id AND cc.column_id = c.column_id
+	LEFT JOIN sys.default_constraints AS dc ON dc.parent_object_id = c.object_id AND dc.parent_column_id = c.column_id
+WHERE
+	s.name = @p1
+	AND t.name IN (%s)
+ORDER BY
+	t.name, c.column_id
+`
+
+	// Query to list table indexes and primary keys. Heaps, columnstore, XML and spatial
+	// indexes are skipped, as well as the included (non-key) columns of the indexes.
+	indexesQuery = `
+SELECT
+	t.name AS table_name,
+	i.name AS index_name,
+	i.type_desc,
+	i.is_unique,
+	i.is_primary_key,
+	i.is_unique_constraint,
+	ic.key_ordinal,
+	c.name AS column_name,
+	ic.is_descending_key,
+	ds.name AS filegroup,
+	ds.is_default,
+	i.filter_definition
+FROM
+	sys.indexes AS i
+	JOIN sys.tables AS t ON t.object_id = i.object_id
+	JOIN sys.schemas AS s ON s.schema_id = t.schema_id
+	JOIN sys.index_columns AS ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id AND ic.is_included_column = 0
+	JOIN sys.columns AS c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+	JOIN sys.data_spaces AS ds ON ds.data_space_id = i.data_space_id
+WHERE
+	s.name = @p1
+	AND t.name IN (%s)
+	AND i.type IN (1, 2)
+ORDER BY
+	t.name, i.name, ic.key_ordinal
+`
+
+	// Query to list table foreign keys. Referential actions are
+	// reported with underscores, e.g. "SET_NULL" or "NO_ACTION".
+	fksQuery = `
+SELECT
+	fk.name AS constraint_name,
+	t.name AS table_name,
+	c.name AS column_name,
+	s.name AS table_schema,
+	rt.name AS referenced_table_name,
+	rc.name AS referenced_column_name,
+	rs.name AS referenced_schema_name,
+	REPLACE(fk.update_referential_action_desc, '_', ' ') AS update_rule,
+	REPLACE(fk.delete_referential_action_desc, '_', ' ') AS delete_rule
+FROM
+	sys.foreign_keys AS fk
+	JOIN sys.foreign_key_columns AS fkc ON fkc.constraint_object_id = fk.object_id
+	JOIN sys.tables AS t ON t.object_id = fk.parent_object_id
+	JOIN sys.schemas AS s ON s.schema_id = t.schema_id
+	JOIN sys.columns AS c ON c.object_id = fkc.parent_object_id AND c.column_id = fkc.parent_column_id
+	JOIN sys.tables AS rt ON rt.object_id = fk.referenced_object_id
+	JOIN sys.schemas AS rs ON rs.schema_id = rt.schema_id
+	JOIN sys.columns AS rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+WHERE
+	s.name = @p1
+	AND t.name IN (%s)
+ORDER BY
+	fk.name, fkc.constraint_column_id
+`
+
+	// Query to list table check constraints.
+	checksQuery = `
+SELECT
+	t.name AS table_name,
+	cc.name AS constraint_name,
+	cc.definition
+FROM
+	sys.check_constraints AS cc
+	JOIN sys.tables AS t ON t.object_id = cc.parent_object_id
+	JOIN sys.schemas AS s ON s.schema_id = t.schema_id
+WHERE
+	s.name = @p1
+	AND t.name IN (%s)
+ORDER BY
+	t.name, cc.name
+`
+)
+
+type (
+	// Identity defines an identity column. Seed and Increment
+	// default to 1 in case they are not set (i.e. zero).
+	// https://learn.microsoft.com/sql/t-sql/statements/create-table-transact-sql-identity-property
+	Identity struct {
+		schema.Attr
+		Seed, Increment int64
+	}
+
+	// DefaultConstraint holds the name of the DEFAULT constraint of
+	// a column. It is used for dropping or replacing the default value.
+	DefaultConstraint struct {
+		schema.Attr
+		Name string
+	}
+
+	// IndexType represents the type of index, i.e. CLUSTERED or NONCLUSTERED.
+	// Primary keys are CLUSTERED by default, and other indexes are NONCLUSTERED.
+	IndexType struct {
+		schema.Attr
+		T string
+	}
+
+	// IndexPredicate describes the filter of a filtered index.
+	// https://learn.microsoft.com/sql/relational-databases/indexes/create-filtered-indexes
+	IndexPredicate struct {
+		schema.Attr
+		P string
+	}
+
+	// UniqueConstraint marks unique indexes that
+	// were created using a UNIQUE constraint.
+	UniqueConstraint struct {
+		schema.Attr
+	}
+
+	// FileGroup describes the filegroup a table or an index is stored in.
+	// Objects that are stored in the default filegroup are not marked.
+	FileGroup struct {
+		schema.Attr
+		V string
+	}
+
+	// MoneyType represents the MONEY and SMALLMONEY types.
+	MoneyType struct {
+		schema.Type
+		T string
+	}
+
+	// UUIDType represents the UNIQUEIDENTIFIER type.
+	UUIDType struct {
+		schema.Type
+		T string
+	}
+
+	// XMLType represents the XML type.
+	XMLType struct {
+		schema.Type
+		T string
+	}
+
+	// RowVersionType represents the ROWVERSION type.
+	RowVersionType struct {
+		schema.Type
+		T string
+	}
+
+	// UserDefinedType represents a type that is not known to the driver,
+	// such as SQL_VARIANT, HIERARCHYID or user-defined alias types.
+	UserDefinedType struct {
+		schema.Type
+		T string
+	}
+)
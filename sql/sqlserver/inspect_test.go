@@ -0,0 +1,249 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectTable(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("16.0.1000.6")
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= @p1"))).
+		WithArgs("dbo").
+		WillReturnRows(sqltest.Rows(`
++------+
+| name |
++------+
+| dbo  |
++------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQueryArgs, "@p1", "@p2"))).
+		WithArgs("dbo", "users").
+		WillReturnRows(sqltest.Rows(`
++--------------+------------+-----------+------------+
+| table_schema | table_name | filegroup | is_default |
++--------------+------------+-----------+------------+
+| dbo          | users      | PRIMARY   | 1          |
++--------------+------------+-----------+------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "@p2"))).
+		WithArgs("dbo", "users").
+		WillReturnRows(sqltest.Rows(`
++------------+-------------+-----------+------------+-----------+-------+-------------+-------------+------------+-----------------+---------------------+--------------+-------------------+--------------------+------------------------------+
+| table_name | column_name | data_type | max_length | precision | scale | is_nullable | is_identity | seed_value | increment_value | computed_definition | is_persisted | default_name      | default_definition | collation_name               |
++------------+-------------+-----------+------------+-----------+-------+-------------+-------------+------------+-----------------+---------------------+--------------+-------------------+--------------------+------------------------------+
+| users      | id          | bigint    | 8          | 19        | 0     | 0           | 1           | 100        | 1               | NULL                | 0            | NULL              | NULL               | NULL                         |
+| users      | name        | nvarchar  | 510        | 0         | 0     | 0           | 0           | NULL       | NULL            | NULL                | 0            | NULL              | NULL               | SQL_Latin1_General_CP1_CI_AS |
+| users      | bio         | nvarchar  | -1         | 0         | 0     | 1           | 0           | NULL       | NULL            | NULL                | 0            | NULL              | NULL               | Latin1_General_BIN           |
+| users      | price       | decimal   | 9          | 10        | 2     | 0           | 0           | NULL       | NULL            | NULL                | 0            | DF_users_price    | ((0))              | NULL                         |
+| users      | created_at  | datetime2 | 8          | 27        | 7     | 0           | 0           | NULL       | NULL            | NULL                | 0            | DF__users__creat | (getdate())        | NULL                         |
+| users      | total       | decimal   | 9          | 11        | 2     | 1           | 0           | NULL       | NULL            | ([price]*(2))       | 1            | NULL              | NULL               | NULL                         |
++------------+-------------+-----------+------------+-----------+-------+-------------+-------------+------------+-----------------+---------------------+--------------+-------------------+--------------------+------------------------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "@p2"))).
+		WithArgs("dbo", "users").
+		WillReturnRows(sqltest.Rows(`
++------------+---------------+--------------+-----------+----------------+----------------------+-------------+-------------+-------------------+-----------+------------+-------------------+
+| table_name | index_name    | type_desc    | is_unique | is_primary_key | is_unique_constraint | key_ordinal | column_name | is_descending_key | filegroup | is_default | filter_definition |
++------------+---------------+--------------+-----------+----------------+----------------------+-------------+-------------+-------------------+-----------+------------+-------------------+
+| users      | PK_users      | CLUSTERED    | 1         | 1              | 0                    | 1           | id          | 0                 | PRIMARY   | 1          | NULL              |
+| users      | ix_created    | NONCLUSTERED | 0         | 0              | 0                    | 1           | created_at  | 1                 | INDEXES   | 0          | ([bio] IS NULL)   |
+| users      | uq_name_price | NONCLUSTERED | 1         | 0              | 1                    | 1           | name        | 0                 | PRIMARY   | 1          | NULL              |
+| users      | uq_name_price | NONCLUSTERED | 1         | 0              | 1                    | 2           | price       | 0                 | PRIMARY   | 1          | NULL              |
++------------+---------------+--------------+-----------+----------------+----------------------+-------------+-------------+-------------------+-----------+------------+-------------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "@p2"))).
+		WithArgs("dbo", "users").
+		WillReturnRows(sqltest.Rows(`
++-----------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+
+| constraint_name | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | update_rule | delete_rule |
++-----------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "@p2"))).
+		WithArgs("dbo", "users").
+		WillReturnRows(sqltest.Rows(`
++------------+-----------------+------------------+
+| table_name | constraint_name | definition       |
++------------+-----------------+------------------+
+| users      | ck_price        | ([price]>=(0))   |
++------------+-----------------+------------------+
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "dbo", &schema.InspectOptions{Tables: []string{"users"}})
+	require.NoError(t, err)
+	tbl, ok := s.Table("users")
+	require.True(t, ok)
+	p := func(i int) *int { return &i }
+	require.EqualValues(t, []*schema.Column{
+		{Name: "id", Type: &schema.ColumnType{Raw: "bigint", Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Seed: 100, Increment: 1}}},
+		{Name: "name", Type: &schema.ColumnType{Raw: "nvarchar(255)", Type: &schema.StringType{T: "nvarchar", Size: 255}}},
+		{Name: "bio", Type: &schema.ColumnType{Raw: "nvarchar(max)", Type: &schema.StringType{T: "nvarchar(max)"}, Null: true}, Attrs: []schema.Attr{&schema.Collation{V: "Latin1_General_BIN"}}},
+		{Name: "price", Type: &schema.ColumnType{Raw: "decimal(10,2)", Type: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}}, Default: &schema.Literal{V: "0"}, Attrs: []schema.Attr{&DefaultConstraint{Name: "DF_users_price"}}},
+		{Name: "created_at", Type: &schema.ColumnType{Raw: "datetime2(7)", Type: &schema.TimeType{T: "datetime2", Precision: p(7)}}, Default: &schema.RawExpr{X: "getdate()"}, Attrs: []schema.Attr{&DefaultConstraint{Name: "DF__users__creat"}}},
+		{Name: "total", Type: &schema.ColumnType{Raw: "decimal(11,2)", Type: &schema.DecimalType{T: "decimal", Precision: 11, Scale: 2}, Null: true}, Attrs: []schema.Attr{&schema.GeneratedExpr{Expr: "([price]*(2))", Type: "PERSISTED"}}},
+	}, columnsOnly(tbl.Columns))
+	require.Equal(t, "PK_users", tbl.PrimaryKey.Name)
+	require.Empty(t, tbl.PrimaryKey.Attrs)
+	require.Len(t, tbl.Indexes, 2)
+	require.Equal(t, "ix_created", tbl.Indexes[0].Name)
+	require.True(t, tbl.Indexes[0].Parts[0].Desc)
+	require.Equal(t, []schema.Attr{&FileGroup{V: "INDEXES"}, &IndexPredicate{P: "([bio] IS NULL)"}}, tbl.Indexes[0].Attrs)
+	require.Equal(t, "uq_name_price", tbl.Indexes[1].Name)
+	require.True(t, tbl.Indexes[1].Unique)
+	require.Len(t, tbl.Indexes[1].Parts, 2)
+	require.Equal(t, []schema.Attr{&UniqueConstraint{}}, tbl.Indexes[1].Attrs)
+	require.Equal(t, []schema.Attr{&schema.Check{Name: "ck_price", Expr: "([price]>=(0))"}}, tbl.Attrs)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_InspectSchema(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("16.0.1000.6")
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= SCHEMA_NAME()"))).
+		WillReturnRows(sqltest.Rows(`
++------+
+| name |
++------+
+| dbo  |
++------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "@p1"))).
+		WithArgs("dbo").
+		WillReturnRows(sqltest.Rows(`
++--------------+------------+-----------+------------+
+| table_schema | table_name | filegroup | is_default |
++--------------+------------+-----------+------------+
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, func() *schema.Schema {
+		r := &schema.Realm{
+			Schemas: []*schema.Schema{
+				{
+					Name: "dbo",
+				},
+			},
+			Attrs: []schema.Attr{
+				&schema.Collation{V: "SQL_Latin1_General_CP1_CI_AS"},
+			},
+		}
+		r.Schemas[0].Realm = r
+		return r.Schemas[0]
+	}(), s)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_InspectRealm(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("16.0.1000.6")
+	m.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
++-------+
+| name  |
++-------+
+| dbo   |
+| sales |
++-------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "@p1, @p2"))).
+		WithArgs("dbo", "sales").
+		WillReturnRows(sqltest.Rows(`
++--------------+------------+-----------+------------+
+| table_schema | table_name | filegroup | is_default |
++--------------+------------+-----------+------------+
+| sales        | orders     | FG1       | 0          |
++--------------+------------+-----------+------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "@p2"))).
+		WithArgs("sales", "orders").
+		WillReturnRows(sqltest.Rows(`
++------------+-------------+-----------+------------+-----------+-------+-------------+-------------+------------+-----------------+---------------------+--------------+--------------+--------------------+----------------+
+| table_name | column_name | data_type | max_length | precision | scale | is_nullable | is_identity | seed_value | increment_value | computed_definition | is_persisted | default_name | default_definition | collation_name |
++------------+-------------+-----------+------------+-----------+-------+-------------+-------------+------------+-----------------+---------------------+--------------+--------------+--------------------+----------------+
+| orders     | id          | int       | 4          | 10        | 0     | 0           | 0           | NULL       | NULL            | NULL                | 0            | NULL         | NULL               | NULL           |
++------------+-------------+-----------+------------+-----------+-------+-------------+-------------+------------+-----------------+---------------------+--------------+--------------+--------------------+----------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "@p2"))).
+		WithArgs("sales", "orders").
+		WillReturnRows(sqltest.Rows(`
++------------+------------+--------------+-----------+----------------+----------------------+-------------+-------------+-------------------+-----------+------------+-------------------+
+| table_name | index_name | type_desc    | is_unique | is_primary_key | is_unique_constraint | key_ordinal | column_name | is_descending_key | filegroup | is_default | filter_definition |
++------------+------------+--------------+-----------+----------------+----------------------+-------------+-------------+-------------------+-----------+------------+-------------------+
+| orders     | PK_orders  | NONCLUSTERED | 1         | 1              | 0                    | 1           | id          | 0                 | FG1       | 0          | NULL              |
++------------+------------+--------------+-----------+----------------+----------------------+-------------+-------------+-------------------+-----------+------------+-------------------+
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "@p2"))).
+		WithArgs("sales", "orders").
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "table_schema", "referenced_table_name", "referenced_column_name", "referenced_schema_name", "update_rule", "delete_rule"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "@p2"))).
+		WithArgs("sales", "orders").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "definition"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	r, err := drv.InspectRealm(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 2)
+	require.Empty(t, r.Schemas[0].Tables)
+	tbl, ok := r.Schemas[1].Table("orders")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&FileGroup{V: "FG1"}}, tbl.Attrs)
+	require.Equal(t, []schema.Attr{&IndexType{T: "NONCLUSTERED"}, &FileGroup{V: "FG1"}}, tbl.PrimaryKey.Attrs)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDefaultExpr(t *testing.T) {
+	for x, want := range map[string]schema.Expr{
+		"((0))":        &schema.Literal{V: "0"},
+		"((-1.5))":     &schema.Literal{V: "-1.5"},
+		"('a')":        &schema.Literal{V: "'a'"},
+		"(N'(a)')":     &schema.Literal{V: "N'(a)'"},
+		"(getdate())":  &schema.RawExpr{X: "getdate()"},
+		"((1)+(2))":    &schema.RawExpr{X: "(1)+(2)"},
+		"(newid())":    &schema.RawExpr{X: "newid()"},
+		"(CONVERT(1))": &schema.RawExpr{X: "CONVERT(1)"},
+	} {
+		require.Equal(t, want, defaultExpr(x), x)
+	}
+}
+
+type mock struct {
+	sqlmock.Sqlmock
+}
+
+func (m mock) version(version string) {
+	m.ExpectQuery(sqltest.Escape(variablesQuery)).
+		WillReturnRows(sqltest.Rows(`
++-----------------+-------------+------------------------------+
+| version         | schema_name | collation                    |
++-----------------+-------------+------------------------------+
+| ` + version + ` | dbo         | SQL_Latin1_General_CP1_CI_AS |
++-----------------+-------------+------------------------------+
+`))
+}
+
+func columnsOnly(columns []*schema.Column) []*schema.Column {
+	for _, c := range columns {
+		c.Indexes = nil
+	}
+	return columns
+}
@@ -0,0 +1,726 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A planApply provides migration capabilities for schema elements.
+type planApply struct{ conn }
+
+// PlanChanges returns a migration plan for the given schema changes.
+func (p *planApply) PlanChanges(_ context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
+	s := &state{
+		conn: p.conn,
+		Plan: migrate.Plan{
+			Name: name,
+			// A plan is reversible, if all
+			// its changes are reversible.
+			Reversible: true,
+		},
+	}
+	for _, o := range opts {
+		o(&s.PlanOptions)
+	}
+	// Plan the changes for the target version, instead of the connected one.
+	if s.TargetVersion != "" {
+		if !validVersion(s.TargetVersion) {
+			return nil, fmt.Errorf("sqlserver: malformed target version: %s", s.TargetVersion)
+		}
+		s.version = s.TargetVersion
+	}
+	s.Transactional = s.capabilities().Is(migrate.CapTransactionalDDL)
+	if err := s.plan(changes); err != nil {
+		return nil, err
+	}
+	for _, c := range s.Changes {
+		if c.Reverse == "" {
+			s.Reversible = false
+			break
+		}
+	}
+	return &s.Plan, nil
+}
+
+// ApplyChanges applies the changes on the database. An error is returned
+// if the driver is unable to produce a plan to it, or one of the statements
+// is failed or unsupported.
+func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change, opts ...migrate.PlanOption) error {
+	return sqlx.ApplyChanges(ctx, changes, p, opts...)
+}
+
+// state represents the state of a planning. It is not part of
+// planApply so that multiple planning/applying can be called
+// in parallel.
+type state struct {
+	conn
+	migrate.Plan
+	migrate.PlanOptions
+	// Changes that are planned after all table changes.
+	deferred []*migrate.Change
+}
+
+// plan builds the migration plan for applying the
+// given changes on the attached connection.
+func (s *state) plan(changes []schema.Change) error {
+	if s.SchemaQualifier != nil {
+		if err := sqlx.CheckChangesScope(changes); err != nil {
+			return err
+		}
+	}
+	planned, err := s.topLevel(changes)
+	if err != nil {
+		return err
+	}
+	planned, err = sqlx.DetachCycles(planned)
+	if err != nil {
+		return err
+	}
+	for _, c := range planned {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			err = s.addTable(c)
+		case *schema.DropTable:
+			s.dropTable(c)
+		case *schema.ModifyTable:
+			err = s.modifyTable(c)
+		case *schema.RenameTable:
+			s.renameTable(c)
+		default:
+			err = fmt.Errorf("unsupported change %T", c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	s.Changes = append(s.Changes, s.deferred...)
+	return nil
+}
+
+// topLevel appends first the changes for creating or dropping schemas (top-level schema elements).
+func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			// CREATE SCHEMA does not support the IF NOT EXISTS clause,
+			// and must be the only statement in the batch.
+			s.append(&migrate.Change{
+				Cmd:     s.Build("CREATE SCHEMA").Ident(c.S.Name).String(),
+				Source:  c,
+				Reverse: s.Build("DROP SCHEMA").Ident(c.S.Name).String(),
+				Comment: fmt.Sprintf("add new schema named %q", c.S.Name),
+			})
+			_, unmanaged, err := sqlx.UnmanagedChanges(c)
+			if err != nil {
+				return nil, err
+			}
+			s.deferred = append(s.deferred, unmanaged...)
+		case *schema.DropSchema:
+			b := s.Build("DROP SCHEMA")
+			if sqlx.Has(c.Extra, &schema.IfExists{}) {
+				b.P("IF EXISTS")
+			}
+			b.Ident(c.S.Name)
+			s.append(&migrate.Change{
+				Cmd:     b.String(),
+				Source:  c,
+				Comment: fmt.Sprintf("drop schema named %q", c.S.Name),
+			})
+		case *schema.ModifySchema:
+			rest, unmanaged, err := sqlx.UnmanagedChanges(c)
+			if err != nil {
+				return nil, err
+			}
+			// Schemas in SQL Server have no attributes that can be altered.
+			if len(rest) > 0 {
+				return nil, fmt.Errorf("unsupported schema change %T", rest[0])
+			}
+			s.deferred = append(s.deferred, unmanaged...)
+		default:
+			planned = append(planned, c)
+		}
+	}
+	return planned, nil
+}
+
+// addTable builds and appends a migration change
+// for creating a table in a schema.
+func (s *state) addTable(add *schema.AddTable) error {
+	var (
+		errs []string
+		b    = s.Build("CREATE TABLE").Table(add.T)
+	)
+	if len(add.T.Columns) == 0 {
+		return fmt.Errorf("table %q has no columns", add.T.Name)
+	}
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
+			if err := s.column(b, add.T, add.T.Columns[i]); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+		if pk := add.T.PrimaryKey; pk != nil {
+			b.Comma()
+			if pk.Name != "" {
+				b.P("CONSTRAINT").Ident(pk.Name)
+			}
+			b.P("PRIMARY KEY")
+			if t := indexType(pk.Attrs); t != "" && !strings.EqualFold(t, IndexTypeClustered) {
+				b.P(t)
+			}
+			indexParts(b, pk.Parts)
+		}
+		// Indexes that were defined as UNIQUE constraints are created
+		// inline, and the rest are created using CREATE INDEX.
+		for _, idx := range add.T.Indexes {
+			if sqlx.Has(idx.Attrs, &UniqueConstraint{}) {
+				b.Comma()
+				uniqueConstraint(b, idx)
+			}
+		}
+		if len(add.T.ForeignKeys) > 0 {
+			b.Comma()
+			if err := s.fks(b, add.T.ForeignKeys...); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		for _, attr := range add.T.Attrs {
+			if c, ok := attr.(*schema.Check); ok {
+				b.Comma()
+				check(b, c)
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
+	}
+	if fg := fileGroup(add.T.Attrs); fg != "" {
+		b.P("ON").Ident(fg)
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  add,
+		Reverse: s.Build("DROP TABLE").Table(add.T).String(),
+		Comment: fmt.Sprintf("create %q table", add.T.Name),
+	})
+	for _, idx := range add.T.Indexes {
+		if !sqlx.Has(idx.Attrs, &UniqueConstraint{}) {
+			s.addIndex(add, add.T, idx)
+		}
+	}
+	return nil
+}
+
+// dropTable builds and appends the migrate.Change
+// for dropping a table from a schema.
+func (s *state) dropTable(drop *schema.DropTable) {
+	b := s.Build("DROP TABLE")
+	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+		b.P("IF EXISTS")
+	}
+	b.Table(drop.T)
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  drop,
+		Comment: fmt.Sprintf("drop %q table", drop.T.Name),
+	})
+}
+
+// modifyTable builds and appends the migration changes for
+// bringing the table into its modified state. Unlike other
+// databases, SQL Server does not allow mixing different kinds
+// of changes in one ALTER TABLE statement, and therefore, each
+// change is planned as a separate statement.
+func (s *state) modifyTable(modify *schema.ModifyTable) error {
+	if len(modify.T.Columns) == 0 {
+		return fmt.Errorf("table %q has no columns; drop the table instead", modify.T.Name)
+	}
+	var changes [2][]schema.Change
+	for _, change := range modify.Changes {
+		switch change := change.(type) {
+		// Constraint and index modifications are translated into 2 steps.
+		// Dropping the current object and creating a new one.
+		case *schema.ModifyForeignKey:
+			changes[0] = append(changes[0], &schema.DropForeignKey{F: change.From})
+			changes[1] = append(changes[1], &schema.AddForeignKey{F: change.To})
+		case *schema.ModifyIndex:
+			changes[0] = append(changes[0], &schema.DropIndex{I: change.From})
+			changes[1] = append(changes[1], &schema.AddIndex{I: change.To})
+		case *schema.ModifyCheck:
+			if change.From.Name == "" {
+				return fmt.Errorf("alter table %q: cannot modify unnamed check constraint", modify.T.Name)
+			}
+			changes[0] = append(changes[0], &schema.DropCheck{C: change.From})
+			changes[1] = append(changes[1], &schema.AddCheck{C: change.To})
+		case *schema.DropForeignKey, *schema.DropIndex, *schema.DropCheck:
+			changes[0] = append(changes[0], change)
+		default:
+			changes[1] = append(changes[1], change)
+		}
+	}
+	for _, c := range append(changes[0], changes[1]...) {
+		if err := s.alterTable(modify, c); err != nil {
+			return fmt.Errorf("alter table %q: %w", modify.T.Name, err)
+		}
+	}
+	return nil
+}
+
+// alterTable builds and appends the migration change(s) for the given table change.
+func (s *state) alterTable(modify *schema.ModifyTable, change schema.Change) error {
+	t := modify.T
+	switch change := change.(type) {
+	case *schema.AddColumn:
+		b := s.Build("ALTER TABLE").Table(t).P("ADD")
+		if err := s.column(b, t, change.C); err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     b.String(),
+			Source:  modify,
+			Reverse: s.dropColumn(t, change.C),
+			Comment: fmt.Sprintf("add column %q to table %q", change.C.Name, t.Name),
+		})
+	case *schema.DropColumn:
+		b := s.Build("ALTER TABLE").Table(t).P("ADD")
+		if err := s.column(b, t, change.C); err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.dropColumn(t, change.C),
+			Source:  modify,
+			Reverse: b.String(),
+			Comment: fmt.Sprintf("drop column %q from table %q", change.C.Name, t.Name),
+		})
+	case *schema.ModifyColumn:
+		return s.modifyColumn(modify, change)
+	case *schema.RenameColumn:
+		s.append(&migrate.Change{
+			Cmd:     s.rename(t, change.From.Name, change.To.Name, "COLUMN"),
+			Source:  modify,
+			Reverse: s.rename(t, change.To.Name, change.From.Name, "COLUMN"),
+			Comment: fmt.Sprintf("rename column %q of table %q to %q", change.From.Name, t.Name, change.To.Name),
+		})
+	case *schema.AddIndex:
+		s.addIndex(modify, t, change.I)
+	case *schema.DropIndex:
+		s.append(&migrate.Change{
+			Cmd:     s.dropIndex(t, change.I),
+			Source:  modify,
+			Reverse: s.createIndex(t, change.I),
+			Comment: fmt.Sprintf("drop index %q from table %q", change.I.Name, t.Name),
+		})
+	case *schema.RenameIndex:
+		s.append(&migrate.Change{
+			Cmd:     s.rename(t, change.From.Name, change.To.Name, "INDEX"),
+			Source:  modify,
+			Reverse: s.rename(t, change.To.Name, change.From.Name, "INDEX"),
+			Comment: fmt.Sprintf("rename index %q of table %q to %q", change.From.Name, t.Name, change.To.Name),
+		})
+	case *schema.AddForeignKey:
+		b := s.Build("ALTER TABLE").Table(t).P("ADD")
+		if err := s.fks(b, change.F); err != nil {
+			return err
+		}
+		c := &migrate.Change{
+			Cmd:     b.String(),
+			Source:  modify,
+			Comment: fmt.Sprintf("add foreign key %q to table %q", change.F.Symbol, t.Name),
+		}
+		// Reverse operation is supported if
+		// the constraint name is not generated.
+		if change.F.Symbol != "" {
+			c.Reverse = s.dropConstraint(t, change.F.Symbol)
+		}
+		s.append(c)
+	case *schema.DropForeignKey:
+		b := s.Build("ALTER TABLE").Table(t).P("ADD")
+		if err := s.fks(b, change.F); err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.dropConstraint(t, change.F.Symbol),
+			Source:  modify,
+			Reverse: b.String(),
+			Comment: fmt.Sprintf("drop foreign key %q from table %q", change.F.Symbol, t.Name),
+		})
+	case *schema.RenameForeignKey:
+		s.append(&migrate.Change{
+			Cmd:     s.rename(t, change.From.Symbol, change.To.Symbol, "OBJECT"),
+			Source:  modify,
+			Reverse: s.rename(t, change.To.Symbol, change.From.Symbol, "OBJECT"),
+			Comment: fmt.Sprintf("rename foreign key %q of table %q to %q", change.From.Symbol, t.Name, change.To.Symbol),
+		})
+	case *schema.AddCheck:
+		b := s.Build("ALTER TABLE").Table(t).P("ADD")
+		check(b, change.C)
+		c := &migrate.Change{
+			Cmd:     b.String(),
+			Source:  modify,
+			Comment: fmt.Sprintf("add check constraint %q to table %q", change.C.Name, t.Name),
+		}
+		// Reverse operation is supported if
+		// the constraint name is not generated.
+		if change.C.Name != "" {
+			c.Reverse = s.dropConstraint(t, change.C.Name)
+		}
+		s.append(c)
+	case *schema.DropCheck:
+		b := s.Build("ALTER TABLE").Table(t).P("ADD")
+		check(b, change.C)
+		s.append(&migrate.Change{
+			Cmd:     s.dropConstraint(t, change.C.Name),
+			Source:  modify,
+			Reverse: b.String(),
+			Comment: fmt.Sprintf("drop check constraint %q from table %q", change.C.Name, t.Name),
+		})
+	case *schema.RenameCheck:
+		s.append(&migrate.Change{
+			Cmd:     s.rename(t, change.From.Name, change.To.Name, "OBJECT"),
+			Source:  modify,
+			Reverse: s.rename(t, change.To.Name, change.From.Name, "OBJECT"),
+			Comment: fmt.Sprintf("rename check constraint %q of table %q to %q", change.From.Name, t.Name, change.To.Name),
+		})
+	default:
+		return fmt.Errorf("unsupported change %T", change)
+	}
+	return nil
+}
+
+// modifyColumn builds and appends the migration changes for modifying a column.
+// Default values are defined as constraints in SQL Server, and therefore, they
+// are dropped before the column is altered, and added back afterwards.
+func (s *state) modifyColumn(modify *schema.ModifyTable, change *schema.ModifyColumn) error {
+	from, to, t := change.From, change.To, modify.T
+	switch {
+	case change.Change.Is(schema.ChangeGenerated):
+		return fmt.Errorf("changing the computed definition of column %q is not supported (drop and add is required)", from.Name)
+	case change.Change.Is(schema.ChangeAttr) && identityChanged(from.Attrs, to.Attrs):
+		return fmt.Errorf("changing the identity of column %q is not supported (drop and add is required)", from.Name)
+	}
+	if change.Change.Is(schema.ChangeDefault) && from.Default != nil {
+		s.append(&migrate.Change{
+			Cmd:     s.dropConstraint(t, defaultName(t, from)),
+			Source:  modify,
+			Reverse: s.addDefault(t, from),
+			Comment: fmt.Sprintf("drop default value of column %q of table %q", from.Name, t.Name),
+		})
+	}
+	if change.Change.Is(schema.ChangeType | schema.ChangeNull | schema.ChangeCollate) {
+		cmd, err := s.alterColumn(t, to)
+		if err != nil {
+			return err
+		}
+		reverse, err := s.alterColumn(t, from)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     cmd,
+			Source:  modify,
+			Reverse: reverse,
+			Comment: fmt.Sprintf("modify column %q of table %q", to.Name, t.Name),
+		})
+	}
+	if change.Change.Is(schema.ChangeDefault) && to.Default != nil {
+		s.append(&migrate.Change{
+			Cmd:     s.addDefault(t, to),
+			Source:  modify,
+			Reverse: s.dropConstraint(t, defaultName(t, to)),
+			Comment: fmt.Sprintf("set default value of column %q of table %q", to.Name, t.Name),
+		})
+	}
+	return nil
+}
+
+// alterColumn returns the ALTER COLUMN statement for bringing the column to the given state.
+func (s *state) alterColumn(t *schema.Table, c *schema.Column) (string, error) {
+	typ, err := FormatType(c.Type.Type)
+	if err != nil {
+		return "", fmt.Errorf("format type for column %q: %w", c.Name, err)
+	}
+	b := s.Build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.Name).P(typ)
+	if a := (schema.Collation{}); sqlx.Has(c.Attrs, &a) {
+		b.P("COLLATE", a.V)
+	}
+	if !c.Type.Null {
+		b.P("NOT")
+	}
+	return b.P("NULL").String(), nil
+}
+
+// dropColumn returns the statement for dropping the given column. In case the
+// column has a default value, its constraint is dropped in the same statement.
+func (s *state) dropColumn(t *schema.Table, c *schema.Column) string {
+	b := s.Build("ALTER TABLE").Table(t).P("DROP")
+	if c.Default != nil {
+		b.P("CONSTRAINT").Ident(defaultName(t, c)).Comma()
+	}
+	return b.P("COLUMN").Ident(c.Name).String()
+}
+
+// addDefault returns the statement for adding a default constraint to the given column.
+func (s *state) addDefault(t *schema.Table, c *schema.Column) string {
+	b := s.Build("ALTER TABLE").Table(t).P("ADD CONSTRAINT").Ident(defaultName(t, c))
+	columnDefault(b, c)
+	return b.P("FOR").Ident(c.Name).String()
+}
+
+// dropConstraint returns the statement for dropping the named table constraint.
+func (s *state) dropConstraint(t *schema.Table, name string) string {
+	return s.Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(name).String()
+}
+
+// addIndex appends the migration change for creating the given index.
+func (s *state) addIndex(source schema.Change, t *schema.Table, idx *schema.Index) {
+	s.append(&migrate.Change{
+		Cmd:     s.createIndex(t, idx),
+		Source:  source,
+		Reverse: s.dropIndex(t, idx),
+		Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
+	})
+}
+
+// createIndex returns the statement for creating the given index. Indexes that
+// were defined as UNIQUE constraints are added using the ALTER TABLE command.
+func (s *state) createIndex(t *schema.Table, idx *schema.Index) string {
+	if sqlx.Has(idx.Attrs, &UniqueConstraint{}) {
+		b := s.Build("ALTER TABLE").Table(t).P("ADD")
+		uniqueConstraint(b, idx)
+		return b.String()
+	}
+	b := s.Build("CREATE")
+	if idx.Unique {
+		b.P("UNIQUE")
+	}
+	if t := indexType(idx.Attrs); t != "" {
+		b.P(strings.ToUpper(t))
+	}
+	b.P("INDEX").Ident(idx.Name).P("ON").Table(t)
+	indexParts(b, idx.Parts)
+	if p := (IndexPredicate{}); sqlx.Has(idx.Attrs, &p) {
+		b.P("WHERE", p.P)
+	}
+	if fg := fileGroup(idx.Attrs); fg != "" {
+		b.P("ON").Ident(fg)
+	}
+	return b.String()
+}
+
+// dropIndex returns the statement for dropping the given index.
+func (s *state) dropIndex(t *schema.Table, idx *schema.Index) string {
+	if sqlx.Has(idx.Attrs, &UniqueConstraint{}) {
+		return s.dropConstraint(t, idx.Name)
+	}
+	return s.Build("DROP INDEX").Ident(idx.Name).P("ON").Table(t).String()
+}
+
+// renameTable builds and appends the migration change for renaming a table.
+func (s *state) renameTable(c *schema.RenameTable) {
+	s.append(&migrate.Change{
+		Source:  c,
+		Comment: fmt.Sprintf("rename a table from %q to %q", c.From.Name, c.To.Name),
+		Cmd:     s.rename(c.From, "", c.To.Name, ""),
+		Reverse: s.rename(c.To, "", c.From.Name, ""),
+	})
+}
+
+// rename returns the sp_rename call for renaming a table or one of
+// its objects. An empty name indicates the table itself is renamed.
+// See: https://learn.microsoft.com/sql/relational-databases/system-stored-procedures/sp-rename-transact-sql.
+func (s *state) rename(t *schema.Table, name, newName, typ string) string {
+	var parts []string
+	switch {
+	case s.SchemaQualifier != nil && *s.SchemaQualifier != "":
+		parts = append(parts, *s.SchemaQualifier)
+	case s.SchemaQualifier == nil && t.Schema != nil && t.Schema.Name != "":
+		parts = append(parts, t.Schema.Name)
+	}
+	// Constraints are schema-scoped objects.
+	if name == "" || typ != "OBJECT" {
+		parts = append(parts, t.Name)
+	}
+	if name != "" {
+		parts = append(parts, name)
+	}
+	b := s.Build("EXEC sp_rename").P(quote(strings.Join(parts, "."))).Comma().P(quote(newName))
+	if typ != "" {
+		b.Comma().P(quote(typ))
+	}
+	return b.String()
+}
+
+// column writes the column definition to the builder.
+func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error {
+	b.Ident(c.Name)
+	// Computed columns are defined without a type.
+	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
+		b.P("AS", sqlx.MayWrap(x.Expr))
+		if persistedOrVirtual(x.Type) == persisted {
+			b.P(persisted)
+			if !c.Type.Null {
+				b.P("NOT NULL")
+			}
+		}
+		return nil
+	}
+	typ, err := FormatType(c.Type.Type)
+	if err != nil {
+		return fmt.Errorf("format type for column %q: %w", c.Name, err)
+	}
+	b.P(typ)
+	if a := (schema.Collation{}); sqlx.Has(c.Attrs, &a) {
+		b.P("COLLATE", a.V)
+	}
+	if i := (Identity{}); sqlx.Has(c.Attrs, &i) {
+		b.P(fmt.Sprintf("IDENTITY(%d, %d)", identitySeed(&i), identityIncrement(&i)))
+	}
+	if !c.Type.Null {
+		b.P("NOT")
+	}
+	b.P("NULL")
+	if c.Default != nil {
+		b.P("CONSTRAINT").Ident(defaultName(t, c))
+		columnDefault(b, c)
+	}
+	return nil
+}
+
+// fks writes the foreign key constraints to the builder.
+func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) error {
+	return b.MapCommaErr(fks, func(i int, b *sqlx.Builder) error {
+		fk := fks[i]
+		if fk.Symbol != "" {
+			b.P("CONSTRAINT").Ident(fk.Symbol)
+		}
+		b.P("FOREIGN KEY")
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(fk.Columns, func(i int, b *sqlx.Builder) {
+				b.Ident(fk.Columns[i].Name)
+			})
+		})
+		b.P("REFERENCES").Table(fk.RefTable)
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(fk.RefColumns, func(i int, b *sqlx.Builder) {
+				b.Ident(fk.RefColumns[i].Name)
+			})
+		})
+		for _, a := range []struct {
+			clause string
+			action schema.ReferenceOption
+		}{{"ON UPDATE", fk.OnUpdate}, {"ON DELETE", fk.OnDelete}} {
+			switch a.action {
+			case "", schema.NoAction:
+			case schema.Restrict:
+				return fmt.Errorf("foreign key %q: RESTRICT is not supported, use NO ACTION instead", fk.Symbol)
+			default:
+				b.P(a.clause, string(a.action))
+			}
+		}
+		if fk.OnUpdate == schema.SetNull || fk.OnDelete == schema.SetNull {
+			for _, c := range fk.Columns {
+				if !c.Type.Null {
+					return fmt.Errorf("foreign key constraint was %[1]q SET NULL, but column %[1]q is NOT NULL", c.Name)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (s *state) append(c *migrate.Change) {
+	s.Changes = append(s.Changes, c)
+}
+
+// Build instantiates a new builder and writes the given phrase to it.
+func (s *state) Build(phrases ...string) *sqlx.Builder {
+	b := &sqlx.Builder{QuoteChar: '[', Schema: s.SchemaQualifier}
+	return b.P(phrases...)
+}
+
+// uniqueConstraint writes the UNIQUE constraint definition of the index to the builder.
+func uniqueConstraint(b *sqlx.Builder, idx *schema.Index) {
+	if idx.Name != "" {
+		b.P("CONSTRAINT").Ident(idx.Name)
+	}
+	b.P("UNIQUE")
+	if t := indexType(idx.Attrs); t != "" {
+		b.P(strings.ToUpper(t))
+	}
+	indexParts(b, idx.Parts)
+}
+
+func indexParts(b *sqlx.Builder, parts []*schema.IndexPart) {
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(parts, func(i int, b *sqlx.Builder) {
+			switch part := parts[i]; {
+			case part.C != nil:
+				b.Ident(part.C.Name)
+			case part.X != nil:
+				b.WriteString(sqlx.MayWrap(part.X.(*schema.RawExpr).X))
+			}
+			// Ignore default collation (i.e. "ASC")
+			if parts[i].Desc {
+				b.P("DESC")
+			}
+		})
+	})
+}
+
+// check writes the CHECK constraint to the builder.
+func check(b *sqlx.Builder, c *schema.Check) {
+	if c.Name != "" {
+		b.P("CONSTRAINT").Ident(c.Name)
+	}
+	b.P("CHECK", sqlx.MayWrap(c.Expr))
+}
+
+// columnDefault writes the default value of column to the builder.
+func columnDefault(b *sqlx.Builder, c *schema.Column) {
+	switch x := c.Default.(type) {
+	case *schema.Literal:
+		v := x.V
+		switch c.Type.Type.(type) {
+		case *schema.StringType, *schema.TimeType, *UUIDType, *XMLType:
+			v = quote(v)
+		}
+		b.P("DEFAULT", v)
+	case *schema.RawExpr:
+		b.P("DEFAULT", x.X)
+	}
+}
+
+// indexType returns the type of the index, if it was defined.
+func indexType(attrs []schema.Attr) string {
+	var t IndexType
+	sqlx.Has(attrs, &t)
+	return t.T
+}
+
+// defaultName returns the name of the default constraint of the column. Columns
+// without a named constraint are given a name, to allow dropping them later.
+func defaultName(t *schema.Table, c *schema.Column) string {
+	if d := (DefaultConstraint{}); sqlx.Has(c.Attrs, &d) && d.Name != "" {
+		return d.Name
+	}
+	return "DF_" + t.Name + "_" + c.Name
+}
+
+// quote returns the given string as a single-quoted literal,
+// unless it is already quoted (e.g. N'text' or 'text').
+func quote(s string) string {
+	if sqlx.IsQuoted(s, '\'') || strings.HasPrefix(s, "N'") && sqlx.IsQuoted(s[1:], '\'') {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,254 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlserver
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges(t *testing.T) {
+	var (
+		dbo   = schema.New("dbo")
+		users = func() *schema.Table {
+			t := schema.NewTable("users").
+				SetSchema(dbo).
+				AddColumns(
+					schema.NewIntColumn("id", TypeBigInt).AddAttrs(&Identity{Seed: 100}),
+					schema.NewStringColumn("name", TypeNVarchar, schema.StringSize(255)),
+					schema.NewNullColumn("bio").SetType(&schema.StringType{T: TypeNVarcharMax}),
+					schema.NewDecimalColumn("price", TypeDecimal, schema.DecimalPrecision(10), schema.DecimalScale(2)).
+						SetDefault(&schema.Literal{V: "0"}),
+				)
+			t.SetPrimaryKey(schema.NewPrimaryKey(t.Columns[0]).SetName("PK_users"))
+			return t
+		}()
+		pets = func() *schema.Table {
+			t := schema.NewTable("pets").
+				SetSchema(dbo).
+				AddColumns(
+					schema.NewIntColumn("id", TypeInt),
+					schema.NewNullIntColumn("owner_id", TypeBigInt),
+				)
+			t.SetPrimaryKey(schema.NewPrimaryKey(t.Columns[0]).AddAttrs(&IndexType{T: IndexTypeNonClustered}))
+			t.AddForeignKeys(schema.NewForeignKey("owner").AddColumns(t.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]).SetOnDelete(schema.SetNull))
+			t.AddIndexes(
+				schema.NewIndex("ix_owner").AddColumns(t.Columns[1]).AddAttrs(&IndexType{T: IndexTypeClustered}, &IndexPredicate{P: "[owner_id] IS NOT NULL"}),
+				schema.NewUniqueIndex("uq_owner").AddColumns(t.Columns[1]).AddAttrs(&UniqueConstraint{}),
+			)
+			t.AddChecks(schema.NewCheck().SetName("ck_id").SetExpr("id > 0"))
+			t.AddAttrs(&FileGroup{V: "FG1"})
+			return t
+		}()
+	)
+	tests := []struct {
+		changes []schema.Change
+		options []migrate.PlanOption
+		wantErr bool
+		plan    *migrate.Plan
+	}{
+		{
+			changes: []schema.Change{&schema.AddSchema{S: schema.New("sales")}},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: "CREATE SCHEMA [sales]", Reverse: "DROP SCHEMA [sales]"}},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.DropSchema{S: schema.New("sales"), Extra: []schema.Clause{&schema.IfExists{}}}},
+			plan: &migrate.Plan{
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: "DROP SCHEMA IF EXISTS [sales]"}},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: users}},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "CREATE TABLE [dbo].[users] ([id] bigint IDENTITY(100, 1) NOT NULL, [name] nvarchar(255) NOT NULL, [bio] nvarchar(max) NULL, [price] decimal(10,2) NOT NULL CONSTRAINT [DF_users_price] DEFAULT 0, CONSTRAINT [PK_users] PRIMARY KEY ([id]))",
+						Reverse: "DROP TABLE [dbo].[users]",
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: pets}},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "CREATE TABLE [dbo].[pets] ([id] int NOT NULL, [owner_id] bigint NULL, PRIMARY KEY NONCLUSTERED ([id]), CONSTRAINT [uq_owner] UNIQUE ([owner_id]), CONSTRAINT [owner] FOREIGN KEY ([owner_id]) REFERENCES [dbo].[users] ([id]) ON DELETE SET NULL, CONSTRAINT [ck_id] CHECK (id > 0)) ON [FG1]",
+						Reverse: "DROP TABLE [dbo].[pets]",
+					},
+					{
+						Cmd:     "CREATE CLUSTERED INDEX [ix_owner] ON [dbo].[pets] ([owner_id]) WHERE [owner_id] IS NOT NULL",
+						Reverse: "DROP INDEX [ix_owner] ON [dbo].[pets]",
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.RenameTable{From: users, To: schema.NewTable("people").SetSchema(dbo)}},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: "EXEC sp_rename 'dbo.users', 'people'", Reverse: "EXEC sp_rename 'dbo.people', 'users'"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewNullStringColumn("email", TypeVarchar, schema.StringSize(100))},
+						&schema.DropColumn{C: users.Columns[3]},
+						&schema.RenameColumn{From: users.Columns[2], To: schema.NewColumn("about")},
+						&schema.AddIndex{I: schema.NewIndex("ix_name").AddColumns(users.Columns[1]).AddAttrs(&FileGroup{V: "INDEXES"})},
+						&schema.RenameIndex{From: schema.NewIndex("a"), To: schema.NewIndex("b")},
+						&schema.AddCheck{C: schema.NewCheck().SetName("ck_name").SetExpr("len(name) > 0")},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: "ALTER TABLE [dbo].[users] ADD [email] varchar(100) NULL", Reverse: "ALTER TABLE [dbo].[users] DROP COLUMN [email]"},
+					{Cmd: "ALTER TABLE [dbo].[users] DROP CONSTRAINT [DF_users_price], COLUMN [price]", Reverse: "ALTER TABLE [dbo].[users] ADD [price] decimal(10,2) NOT NULL CONSTRAINT [DF_users_price] DEFAULT 0"},
+					{Cmd: "EXEC sp_rename 'dbo.users.bio', 'about', 'COLUMN'", Reverse: "EXEC sp_rename 'dbo.users.about', 'bio', 'COLUMN'"},
+					{Cmd: "CREATE INDEX [ix_name] ON [dbo].[users] ([name]) ON [INDEXES]", Reverse: "DROP INDEX [ix_name] ON [dbo].[users]"},
+					{Cmd: "EXEC sp_rename 'dbo.users.a', 'b', 'INDEX'", Reverse: "EXEC sp_rename 'dbo.users.b', 'a', 'INDEX'"},
+					{Cmd: "ALTER TABLE [dbo].[users] ADD CONSTRAINT [ck_name] CHECK (len(name) > 0)", Reverse: "ALTER TABLE [dbo].[users] DROP CONSTRAINT [ck_name]"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   users.Columns[3],
+							To:     schema.NewNullDecimalColumn("price", TypeDecimal, schema.DecimalPrecision(12), schema.DecimalScale(2)).SetDefault(&schema.Literal{V: "1"}),
+							Change: schema.ChangeType | schema.ChangeNull | schema.ChangeDefault,
+						},
+						&schema.ModifyIndex{
+							From:   schema.NewIndex("ix_name").AddColumns(users.Columns[1]),
+							To:     schema.NewUniqueIndex("ix_name").AddColumns(users.Columns[1]),
+							Change: schema.ChangeUnique,
+						},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: "DROP INDEX [ix_name] ON [dbo].[users]", Reverse: "CREATE INDEX [ix_name] ON [dbo].[users] ([name])"},
+					{Cmd: "ALTER TABLE [dbo].[users] DROP CONSTRAINT [DF_users_price]", Reverse: "ALTER TABLE [dbo].[users] ADD CONSTRAINT [DF_users_price] DEFAULT 0 FOR [price]"},
+					{Cmd: "ALTER TABLE [dbo].[users] ALTER COLUMN [price] decimal(12,2) NULL", Reverse: "ALTER TABLE [dbo].[users] ALTER COLUMN [price] decimal(10,2) NOT NULL"},
+					{Cmd: "ALTER TABLE [dbo].[users] ADD CONSTRAINT [DF_users_price] DEFAULT 1 FOR [price]", Reverse: "ALTER TABLE [dbo].[users] DROP CONSTRAINT [DF_users_price]"},
+					{Cmd: "CREATE UNIQUE INDEX [ix_name] ON [dbo].[users] ([name])", Reverse: "DROP INDEX [ix_name] ON [dbo].[users]"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: pets,
+					Changes: []schema.Change{
+						&schema.DropIndex{I: pets.Indexes[1]},
+						&schema.DropForeignKey{F: pets.ForeignKeys[0]},
+						&schema.DropCheck{C: schema.NewCheck().SetName("ck_id").SetExpr("id > 0")},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: "ALTER TABLE [dbo].[pets] DROP CONSTRAINT [uq_owner]", Reverse: "ALTER TABLE [dbo].[pets] ADD CONSTRAINT [uq_owner] UNIQUE ([owner_id])"},
+					{Cmd: "ALTER TABLE [dbo].[pets] DROP CONSTRAINT [owner]", Reverse: "ALTER TABLE [dbo].[pets] ADD CONSTRAINT [owner] FOREIGN KEY ([owner_id]) REFERENCES [dbo].[users] ([id]) ON DELETE SET NULL"},
+					{Cmd: "ALTER TABLE [dbo].[pets] DROP CONSTRAINT [ck_id]", Reverse: "ALTER TABLE [dbo].[pets] ADD CONSTRAINT [ck_id] CHECK (id > 0)"},
+				},
+			},
+		},
+		// Identity changes require rebuilding the column.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   users.Columns[0],
+							To:     schema.NewIntColumn("id", TypeBigInt),
+							Change: schema.ChangeAttr,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		// Custom schema qualifier.
+		{
+			changes: []schema.Change{&schema.RenameTable{From: users, To: schema.NewTable("people").SetSchema(dbo)}},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: "EXEC sp_rename 'users', 'people'", Reverse: "EXEC sp_rename 'people', 'users'"},
+				},
+			},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			db, mk, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{mk}.version("16.0.1000.6")
+			drv, err := Open(db)
+			require.NoError(t, err)
+			plan, err := drv.PlanChanges(context.Background(), "plan", tt.changes, tt.options...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.plan.Reversible, plan.Reversible)
+			require.Equal(t, tt.plan.Transactional, plan.Transactional)
+			require.Len(t, plan.Changes, len(tt.plan.Changes))
+			for i, c := range plan.Changes {
+				require.Equal(t, tt.plan.Changes[i].Cmd, c.Cmd)
+				require.Equal(t, tt.plan.Changes[i].Reverse, c.Reverse)
+			}
+		})
+	}
+}
+
+func TestDefaultPlan(t *testing.T) {
+	changes, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: schema.NewTable("t").AddColumns(schema.NewIntColumn("a", TypeInt))},
+	})
+	require.NoError(t, err)
+	require.Len(t, changes.Changes, 1)
+	require.Equal(t, "CREATE TABLE [t] ([a] int NOT NULL)", changes.Changes[0].Cmd)
+}
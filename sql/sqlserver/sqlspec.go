@@ -0,0 +1,327 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlserver
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/specutil"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlspec"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// evalSpec evaluates an Atlas DDL document into v using the input.
+func evalSpec(p *hclparse.Parser, v any, input map[string]string) error {
+	var d doc
+	if err := hclState.Eval(p, &d, input); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *schema.Realm:
+		if err := specutil.Scan(v, d.Schemas, d.Tables, convertTable); err != nil {
+			return fmt.Errorf("specutil: failed converting to *schema.Realm: %w", err)
+		}
+		if err := specutil.Unmanaged(v, d.Unmanaged); err != nil {
+			return err
+		}
+	case *schema.Schema:
+		if len(d.Schemas) != 1 {
+			return fmt.Errorf("specutil: expecting document to contain a single schema, got %d", len(d.Schemas))
+		}
+		var r schema.Realm
+		if err := specutil.Scan(&r, d.Schemas, d.Tables, convertTable); err != nil {
+			return err
+		}
+		if err := specutil.Unmanaged(&r, d.Unmanaged); err != nil {
+			return err
+		}
+		r.Schemas[0].Realm = nil
+		*v = *r.Schemas[0]
+	default:
+		return fmt.Errorf("specutil: failed unmarshaling spec. %T is not supported", v)
+	}
+	return nil
+}
+
+// MarshalSpec marshals v into an Atlas DDL document using a schemahcl.Marshaler.
+func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
+	return specutil.Marshal(v, marshaler, schemaSpec)
+}
+
+// convertTable converts a sqlspec.Table to a schema.Table. Table conversion is done without converting
+// ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
+// at this point. Instead, the linking is done by the convertSchema function.
+func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, error) {
+	t, err := specutil.Table(spec, parent, convertColumn, convertPK, convertIndex, specutil.Check)
+	if err != nil {
+		return nil, err
+	}
+	if err := convertFileGroup(spec.Extra, &t.Attrs); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// convertPK converts a sqlspec.PrimaryKey into a schema.Index.
+func convertPK(spec *sqlspec.PrimaryKey, parent *schema.Table) (*schema.Index, error) {
+	pk, err := specutil.PrimaryKey(spec, parent)
+	if err != nil {
+		return nil, err
+	}
+	if err := convertIndexType(spec.Extra, &pk.Attrs); err != nil {
+		return nil, err
+	}
+	return pk, nil
+}
+
+// convertIndex converts a sqlspec.Index into a schema.Index.
+func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
+	idx, err := specutil.Index(spec, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := convertIndexType(spec.Extra, &idx.Attrs); err != nil {
+		return nil, err
+	}
+	if attr, ok := spec.Attr("where"); ok {
+		p, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		idx.Attrs = append(idx.Attrs, &IndexPredicate{P: p})
+	}
+	if err := convertFileGroup(spec.Extra, &idx.Attrs); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// convertIndexType converts the "type" attribute of an index or a primary key.
+func convertIndexType(spec schemahcl.Resource, attrs *[]schema.Attr) error {
+	if attr, ok := spec.Attr("type"); ok {
+		t, err := attr.String()
+		if err != nil {
+			return err
+		}
+		*attrs = append(*attrs, &IndexType{T: strings.ToUpper(t)})
+	}
+	return nil
+}
+
+// convertFileGroup converts the "filegroup" attribute of a table or an index.
+func convertFileGroup(spec schemahcl.Resource, attrs *[]schema.Attr) error {
+	if attr, ok := spec.Attr("filegroup"); ok {
+		fg, err := attr.String()
+		if err != nil {
+			return err
+		}
+		*attrs = append(*attrs, &FileGroup{V: fg})
+	}
+	return nil
+}
+
+// convertColumn converts a sqlspec.Column into a schema.Column.
+func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error) {
+	c, err := specutil.Column(spec, convertColumnType)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := spec.Extra.Resource("identity"); ok {
+		id, err := convertIdentity(r)
+		if err != nil {
+			return nil, err
+		}
+		c.Attrs = append(c.Attrs, id)
+	}
+	if err := specutil.ConvertGenExpr(spec.Remain(), c, persistedOrVirtual); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func convertIdentity(r *schemahcl.Resource) (*Identity, error) {
+	var spec struct {
+		Seed      int64 `spec:"seed"`
+		Increment int64 `spec:"increment"`
+	}
+	if err := r.As(&spec); err != nil {
+		return nil, err
+	}
+	return &Identity{Seed: spec.Seed, Increment: spec.Increment}, nil
+}
+
+// convertColumnType converts a sqlspec.Column into a concrete SQL Server schema.Type.
+func convertColumnType(spec *sqlspec.Column) (schema.Type, error) {
+	return TypeRegistry.Type(spec.Type, spec.Extra.Attrs)
+}
+
+// schemaSpec converts from a concrete SQL Server schema to Atlas specification.
+func schemaSpec(s *schema.Schema) (*sqlspec.Schema, []*sqlspec.Table, error) {
+	return specutil.FromSchema(s, tableSpec)
+}
+
+// tableSpec converts from a concrete SQL Server sqlspec.Table to a schema.Table.
+func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
+	spec, err := specutil.FromTable(
+		t,
+		columnSpec,
+		pkSpec,
+		indexSpec,
+		specutil.FromForeignKey,
+		specutil.FromCheck,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if fg := fileGroup(t.Attrs); fg != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.StrAttr("filegroup", fg))
+	}
+	return spec, nil
+}
+
+// pkSpec converts from a concrete SQL Server primary key into a sqlspec.PrimaryKey.
+func pkSpec(idx *schema.Index) (*sqlspec.PrimaryKey, error) {
+	spec, err := specutil.FromPrimaryKey(idx)
+	if err != nil {
+		return nil, err
+	}
+	// Avoid printing the index type if it is the default.
+	if t := indexType(idx.Attrs); t != "" && !strings.EqualFold(t, IndexTypeClustered) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("type", strings.ToUpper(t)))
+	}
+	return spec, nil
+}
+
+func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
+	spec, err := specutil.FromIndex(idx)
+	if err != nil {
+		return nil, err
+	}
+	// Avoid printing the index type if it is the default.
+	if t := indexType(idx.Attrs); t != "" && !strings.EqualFold(t, IndexTypeNonClustered) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("type", strings.ToUpper(t)))
+	}
+	if i := (IndexPredicate{}); sqlx.Has(idx.Attrs, &i) && i.P != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("where", strconv.Quote(i.P)))
+	}
+	if fg := fileGroup(idx.Attrs); fg != "" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.StrAttr("filegroup", fg))
+	}
+	return spec, nil
+}
+
+// columnSpec converts from a concrete SQL Server schema.Column into a sqlspec.Column.
+func columnSpec(c *schema.Column, _ *schema.Table) (*sqlspec.Column, error) {
+	s, err := specutil.FromColumn(c, columnTypeSpec)
+	if err != nil {
+		return nil, err
+	}
+	if i := (&Identity{}); sqlx.Has(c.Attrs, i) {
+		s.Extra.Children = append(s.Extra.Children, fromIdentity(i))
+	}
+	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
+		s.Extra.Children = append(s.Extra.Children, specutil.FromGenExpr(x, persistedOrVirtual))
+	}
+	return s, nil
+}
+
+// fromIdentity returns the resource spec for representing the identity attributes.
+func fromIdentity(i *Identity) *schemahcl.Resource {
+	id := &schemahcl.Resource{Type: "identity"}
+	if s := identitySeed(i); s != 1 {
+		id.Attrs = append(id.Attrs, specutil.Int64Attr("seed", s))
+	}
+	if inc := identityIncrement(i); inc != 1 {
+		id.Attrs = append(id.Attrs, specutil.Int64Attr("increment", inc))
+	}
+	return id
+}
+
+// columnTypeSpec converts from a concrete SQL Server schema.Type into sqlspec.Column Type.
+func columnTypeSpec(t schema.Type) (*sqlspec.Column, error) {
+	st, err := TypeRegistry.Convert(t)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlspec.Column{Type: st}, nil
+}
+
+// TypeRegistry contains the supported TypeSpecs for the sqlserver driver.
+var TypeRegistry = schemahcl.NewRegistry(
+	schemahcl.WithFormatter(FormatType),
+	schemahcl.WithParser(ParseType),
+	schemahcl.WithSpecs(
+		schemahcl.NewTypeSpec(TypeBit),
+		schemahcl.NewTypeSpec(TypeTinyInt),
+		schemahcl.NewTypeSpec(TypeSmallInt),
+		schemahcl.NewTypeSpec(TypeInt),
+		schemahcl.NewTypeSpec(TypeBigInt),
+		schemahcl.NewTypeSpec(TypeDecimal, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
+		schemahcl.NewTypeSpec(TypeNumeric, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false}, &schemahcl.TypeAttr{Name: "scale", Kind: reflect.Int, Required: false})),
+		schemahcl.NewTypeSpec(TypeMoney),
+		schemahcl.NewTypeSpec(TypeSmallMoney),
+		schemahcl.NewTypeSpec(TypeFloat, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
+		schemahcl.NewTypeSpec(TypeReal),
+		schemahcl.NewTypeSpec(TypeDate),
+		schemahcl.NewTypeSpec(TypeTime, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
+		schemahcl.NewTypeSpec(TypeDateTime),
+		schemahcl.NewTypeSpec(TypeDateTime2, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
+		schemahcl.NewTypeSpec(TypeDateTimeOffset, schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "precision", Kind: reflect.Int, Required: false})),
+		schemahcl.NewTypeSpec(TypeSmallDateTime),
+		schemahcl.NewTypeSpec(TypeChar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+		schemahcl.NewTypeSpec(TypeVarchar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+		schemahcl.AliasTypeSpec("varchar_max", TypeVarcharMax),
+		schemahcl.NewTypeSpec(TypeText),
+		schemahcl.NewTypeSpec(TypeNChar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+		schemahcl.NewTypeSpec(TypeNVarchar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+		schemahcl.AliasTypeSpec("nvarchar_max", TypeNVarcharMax),
+		schemahcl.NewTypeSpec(TypeNText),
+		schemahcl.NewTypeSpec(TypeBinary, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+		schemahcl.NewTypeSpec(TypeVarBinary, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+		schemahcl.AliasTypeSpec("varbinary_max", TypeVarBinaryMax),
+		schemahcl.NewTypeSpec(TypeImage),
+		schemahcl.NewTypeSpec(TypeUniqueIdentifier),
+		schemahcl.NewTypeSpec(TypeXML),
+		schemahcl.NewTypeSpec(TypeRowVersion),
+		schemahcl.NewTypeSpec(TypeSQLVariant),
+		schemahcl.NewTypeSpec(TypeHierarchyID),
+		schemahcl.NewTypeSpec(TypeGeometry),
+		schemahcl.NewTypeSpec(TypeGeography),
+	),
+)
+
+var (
+	hclState = schemahcl.New(
+		schemahcl.WithTypes(TypeRegistry.Specs()),
+		schemahcl.WithScopedEnums("table.index.type", IndexTypeClustered, IndexTypeNonClustered),
+		schemahcl.WithScopedEnums("table.primary_key.type", IndexTypeClustered, IndexTypeNonClustered),
+		schemahcl.WithScopedEnums("table.column.as.type", persisted, virtual),
+		schemahcl.WithScopedEnums("table.foreign_key.on_update", specutil.ReferenceVars...),
+		schemahcl.WithScopedEnums("table.foreign_key.on_delete", specutil.ReferenceVars...),
+	)
+	// MarshalHCL marshals v into an Atlas HCL DDL document.
+	MarshalHCL = schemahcl.MarshalerFunc(func(v any) ([]byte, error) {
+		return MarshalSpec(v, hclState)
+	})
+	// EvalHCL implements the schemahcl.Evaluator interface.
+	EvalHCL = schemahcl.EvalFunc(evalSpec)
+
+	// EvalHCLBytes is a helper that evaluates an HCL document from a byte slice instead
+	// of from an hclparse.Parser instance.
+	EvalHCLBytes = specutil.HCLBytesFunc(EvalHCL)
+)
+
+type doc struct {
+	Tables    []*sqlspec.Table     `spec:"table"`
+	Schemas   []*sqlspec.Schema    `spec:"schema"`
+	Unmanaged []*sqlspec.Unmanaged `spec:"unmanaged"`
+}
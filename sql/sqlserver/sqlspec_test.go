@@ -0,0 +1,180 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlserver
+
+import (
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/spectest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLSpec(t *testing.T) {
+	f := `
+schema "dbo" {
+}
+
+table "users" {
+	schema = schema.dbo
+	column "id" {
+		type = bigint
+		identity {
+			seed = 100
+		}
+	}
+	column "name" {
+		type = nvarchar(255)
+	}
+	column "bio" {
+		type = nvarchar_max
+		null = true
+	}
+	column "total" {
+		type = decimal(10,2)
+		null = true
+		as {
+			expr = "[price] * 2"
+			type = PERSISTED
+		}
+	}
+	primary_key {
+		columns = [column.id]
+		type = NONCLUSTERED
+	}
+	index "ix_name" {
+		columns = [column.name]
+		type = CLUSTERED
+		where = "[bio] IS NULL"
+		filegroup = "INDEXES"
+	}
+	filegroup = "FG1"
+}
+`
+	var s schema.Schema
+	err := EvalHCLBytes([]byte(f), &s, nil)
+	require.NoError(t, err)
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&FileGroup{V: "FG1"}}, users.Attrs)
+	require.Equal(t, []schema.Attr{&Identity{Seed: 100}}, users.Columns[0].Attrs)
+	require.Equal(t, &schema.StringType{T: TypeNVarchar, Size: 255}, users.Columns[1].Type.Type)
+	require.Equal(t, &schema.StringType{T: TypeNVarcharMax}, users.Columns[2].Type.Type)
+	require.Equal(t, []schema.Attr{&schema.GeneratedExpr{Expr: "[price] * 2", Type: "PERSISTED"}}, users.Columns[3].Attrs)
+	require.Equal(t, []schema.Attr{&IndexType{T: IndexTypeNonClustered}}, users.PrimaryKey.Attrs)
+	require.Equal(t, []schema.Attr{&IndexType{T: IndexTypeClustered}, &IndexPredicate{P: "[bio] IS NULL"}, &FileGroup{V: "INDEXES"}}, users.Indexes[0].Attrs)
+
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	var after schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &after, nil))
+	require.EqualValues(t, s.Tables[0].Attrs, after.Tables[0].Attrs)
+	require.EqualValues(t, s.Tables[0].PrimaryKey.Attrs, after.Tables[0].PrimaryKey.Attrs)
+	require.EqualValues(t, s.Tables[0].Indexes[0].Attrs, after.Tables[0].Indexes[0].Attrs)
+	for i, c := range s.Tables[0].Columns {
+		require.EqualValues(t, c.Type.Type, after.Tables[0].Columns[i].Type.Type)
+		require.EqualValues(t, c.Attrs, after.Tables[0].Columns[i].Attrs)
+	}
+}
+
+func TestMarshalSpec_Identity(t *testing.T) {
+	s := schema.New("dbo").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(
+					schema.NewIntColumn("id", TypeInt).AddAttrs(&Identity{Seed: 1, Increment: 1}),
+					schema.NewIntColumn("rank", TypeInt).AddAttrs(&Identity{Seed: 10, Increment: 5}),
+				),
+		)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.dbo
+  column "id" {
+    null = false
+    type = int
+    identity {
+    }
+  }
+  column "rank" {
+    null = false
+    type = int
+    identity {
+      seed      = 10
+      increment = 5
+    }
+  }
+}
+schema "dbo" {
+}
+`, string(buf))
+}
+
+func TestTypes(t *testing.T) {
+	p := func(i int) *int { return &i }
+	for _, tt := range []struct {
+		typeExpr string
+		expected schema.Type
+	}{
+		{typeExpr: "bit", expected: &schema.BoolType{T: TypeBit}},
+		{typeExpr: "tinyint", expected: &schema.IntegerType{T: TypeTinyInt}},
+		{typeExpr: "int", expected: &schema.IntegerType{T: TypeInt}},
+		{typeExpr: "bigint", expected: &schema.IntegerType{T: TypeBigInt}},
+		{typeExpr: "decimal(10,2)", expected: &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}},
+		{typeExpr: "numeric", expected: &schema.DecimalType{T: TypeNumeric, Precision: 18}},
+		{typeExpr: "money", expected: &MoneyType{T: TypeMoney}},
+		{typeExpr: "float", expected: &schema.FloatType{T: TypeFloat, Precision: 53}},
+		{typeExpr: "float(10)", expected: &schema.FloatType{T: TypeReal, Precision: 24}},
+		{typeExpr: "real", expected: &schema.FloatType{T: TypeReal, Precision: 24}},
+		{typeExpr: "date", expected: &schema.TimeType{T: TypeDate}},
+		{typeExpr: "datetime", expected: &schema.TimeType{T: TypeDateTime}},
+		{typeExpr: "datetime2", expected: &schema.TimeType{T: TypeDateTime2, Precision: p(7)}},
+		{typeExpr: "datetime2(3)", expected: &schema.TimeType{T: TypeDateTime2, Precision: p(3)}},
+		{typeExpr: "datetimeoffset", expected: &schema.TimeType{T: TypeDateTimeOffset, Precision: p(7)}},
+		{typeExpr: "char(10)", expected: &schema.StringType{T: TypeChar, Size: 10}},
+		{typeExpr: "varchar(255)", expected: &schema.StringType{T: TypeVarchar, Size: 255}},
+		{typeExpr: "varchar_max", expected: &schema.StringType{T: TypeVarcharMax}},
+		{typeExpr: "nvarchar_max", expected: &schema.StringType{T: TypeNVarcharMax}},
+		{typeExpr: "ntext", expected: &schema.StringType{T: TypeNText}},
+		{typeExpr: "binary(16)", expected: &schema.BinaryType{T: TypeBinary, Size: p(16)}},
+		{typeExpr: "varbinary_max", expected: &schema.BinaryType{T: TypeVarBinaryMax}},
+		{typeExpr: "uniqueidentifier", expected: &UUIDType{T: TypeUniqueIdentifier}},
+		{typeExpr: "xml", expected: &XMLType{T: TypeXML}},
+		{typeExpr: "rowversion", expected: &RowVersionType{T: TypeRowVersion}},
+		{typeExpr: "geography", expected: &schema.SpatialType{T: TypeGeography}},
+		{typeExpr: "sql_variant", expected: &UserDefinedType{T: TypeSQLVariant}},
+		{typeExpr: `sql("custom_type")`, expected: &UserDefinedType{T: "custom_type"}},
+	} {
+		t.Run(tt.typeExpr, func(t *testing.T) {
+			var test schema.Schema
+			doc := fmt.Sprintf(`table "test" {
+	schema = schema.test
+	column "test" {
+		null = false
+		type = %s
+	}
+}
+schema "test" {
+}
+`, tt.typeExpr)
+			err := EvalHCLBytes([]byte(doc), &test, nil)
+			require.NoError(t, err)
+			colspec := test.Tables[0].Columns[0]
+			require.EqualValues(t, tt.expected, colspec.Type.Type)
+			spec, err := MarshalSpec(&test, hclState)
+			require.NoError(t, err)
+			var after schema.Schema
+			err = EvalHCLBytes(spec, &after, nil)
+			require.NoError(t, err)
+			require.EqualValues(t, tt.expected, after.Tables[0].Columns[0].Type.Type)
+		})
+	}
+}
+
+func TestInputVars(t *testing.T) {
+	spectest.TestInputVars(t, EvalHCL)
+}
@@ -28,6 +28,61 @@ type (
 		schemahcl.DefaultExtension
 	}
 
+	// View holds a specification for an SQL view.
+	View struct {
+		Name      string         `spec:",name"`
+		Qualifier string         `spec:",qualifier"`
+		Schema    *schemahcl.Ref `spec:"schema"`
+		As        string         `spec:"as"`
+		Compare   string         `spec:"compare,omitempty"`
+		schemahcl.DefaultExtension
+	}
+
+	// Func holds a specification for an SQL function.
+	Func struct {
+		Name    string         `spec:",name"`
+		Schema  *schemahcl.Ref `spec:"schema"`
+		Args    string         `spec:"args"`
+		Returns string         `spec:"returns"`
+		Lang    string         `spec:"lang,omitempty"`
+		As      string         `spec:"as"`
+		schemahcl.DefaultExtension
+	}
+
+	// Proc holds a specification for an SQL stored procedure.
+	Proc struct {
+		Name   string         `spec:",name"`
+		Schema *schemahcl.Ref `spec:"schema"`
+		Args   string         `spec:"args"`
+		Lang   string         `spec:"lang,omitempty"`
+		As     string         `spec:"as"`
+		schemahcl.DefaultExtension
+	}
+
+	// Trigger holds a specification for an SQL trigger.
+	Trigger struct {
+		Name   string         `spec:",name"`
+		On     *schemahcl.Ref `spec:"on"`
+		Timing string         `spec:"timing"`
+		Events []string       `spec:"events"`
+		Level  string         `spec:"level"`
+		When   string         `spec:"when,omitempty"`
+		As     string         `spec:"as"`
+		schemahcl.DefaultExtension
+	}
+
+	// Sequence holds a specification for a standalone sequence. The numeric
+	// options of the sequence (start, increment, min_value, max_value and cache)
+	// are kept in its extension, as they are optional and may be negative.
+	Sequence struct {
+		Name   string         `spec:",name"`
+		Schema *schemahcl.Ref `spec:"schema"`
+		Type   string         `spec:"type,omitempty"`
+		Cycle  bool           `spec:"cycle,omitempty"`
+		Owner  *schemahcl.Ref `spec:"owner"`
+		schemahcl.DefaultExtension
+	}
+
 	// Column holds a specification for a column in an SQL table.
 	Column struct {
 		Name    string          `spec:",name"`
@@ -77,11 +132,37 @@ type (
 		schemahcl.DefaultExtension
 	}
 
+	// Setting holds a specification for a database-level setting.
+	Setting struct {
+		Name  string `spec:",name"`
+		Value string `spec:"value"`
+		schemahcl.DefaultExtension
+	}
+
+	// Unmanaged holds a specification for a schema object that is not
+	// modeled by Atlas, and is kept as its raw DDL statements.
+	Unmanaged struct {
+		Name        string         `spec:",name"`
+		Schema      *schemahcl.Ref `spec:"schema"`
+		Type        string         `spec:"type"`
+		Create      string         `spec:"create"`
+		Drop        string         `spec:"drop,omitempty"`
+		Fingerprint string         `spec:"fingerprint,omitempty"`
+		schemahcl.DefaultExtension
+	}
+
 	// Type represents a database agnostic column type.
 	Type string
 )
 
 func init() {
 	schemahcl.Register("table", &Table{})
+	schemahcl.Register("view", &View{})
+	schemahcl.Register("trigger", &Trigger{})
+	schemahcl.Register("function", &Func{})
+	schemahcl.Register("procedure", &Proc{})
+	schemahcl.Register("sequence", &Sequence{})
 	schemahcl.Register("schema", &Schema{})
+	schemahcl.Register("setting", &Setting{})
+	schemahcl.Register("unmanaged", &Unmanaged{})
 }
@@ -0,0 +1,163 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqltest_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+	"ariga.io/atlas/sql/sqlite"
+	"ariga.io/atlas/sql/sqltest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirMatches(t *testing.T) {
+	dev := &sqlclient.Client{
+		Name:      "mock",
+		URL:       &sqlclient.URL{Schema: "main"},
+		Driver:    &mockDriver{},
+		Evaluator: sqlite.EvalHCL,
+	}
+	dir, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	write := func(name, content string) {
+		require.NoError(t, dir.WriteFile(name, []byte(content)))
+		sum, err := dir.Checksum()
+		require.NoError(t, err)
+		require.NoError(t, migrate.WriteSumFile(dir, sum))
+	}
+	write("1.sql", "CREATE TABLE users;\nCREATE TABLE pets;\n")
+	p := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(p, "schema.hcl"), []byte(`
+schema "main" {}
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+}
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(p, "schema.sql"), []byte("CREATE TABLE users;\n"), 0600))
+
+	for _, desired := range []migrate.StateReader{sqltest.HCLPaths(dev, p), sqltest.SQLPaths(dev, p)} {
+		stmts, err := sqltest.Diff(context.Background(), dev, dir, desired)
+		require.NoError(t, err)
+		require.Equal(t, []string{"DROP TABLE pets"}, stmts)
+		m := &mockT{}
+		sqltest.DirMatches(m, dev, dir, desired)
+		require.Equal(t, "sql/sqltest: migration directory is not in sync with the desired state. Missing statements:\n\n\tDROP TABLE pets\n", m.msg)
+	}
+	// The dev database is restored after reading the SQL schema.
+	require.Empty(t, dev.Driver.(*mockDriver).tables)
+
+	write("2.sql", "DROP TABLE pets;\n")
+	for _, desired := range []migrate.StateReader{sqltest.HCLPaths(dev, p), sqltest.SQLPaths(dev, filepath.Join(p, "schema.sql"))} {
+		m := &mockT{}
+		sqltest.DirMatches(m, dev, dir, desired)
+		require.Empty(t, m.msg)
+	}
+
+	m := &mockT{}
+	sqltest.DirMatches(m, dev, dir, sqltest.HCLPaths(dev, t.TempDir()))
+	require.Contains(t, m.msg, "sql/sqltest: diffing migration directory: sql/sqltest: no schema files found in:")
+}
+
+type mockT struct {
+	msg string
+}
+
+func (*mockT) Helper() {}
+
+func (m *mockT) Fatalf(format string, args ...any) {
+	m.msg = fmt.Sprintf(format, args...)
+}
+
+// mockDriver is a minimal driver that supports creating and dropping tables.
+type mockDriver struct {
+	migrate.Driver
+	tables []string
+}
+
+func (m *mockDriver) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	switch f := strings.Fields(strings.TrimSuffix(query, ";")); {
+	case len(f) == 3 && f[0] == "CREATE":
+		m.tables = append(m.tables, f[2])
+	case len(f) == 3 && f[0] == "DROP":
+		for i := range m.tables {
+			if m.tables[i] == f[2] {
+				m.tables = append(m.tables[:i], m.tables[i+1:]...)
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected statement: %q", query)
+	}
+	return nil, nil
+}
+
+func (m *mockDriver) InspectSchema(context.Context, string, *schema.InspectOptions) (*schema.Schema, error) {
+	s := schema.New("main")
+	for _, t := range m.tables {
+		s.AddTables(schema.NewTable(t))
+	}
+	return s, nil
+}
+
+func (m *mockDriver) InspectRealm(ctx context.Context, _ *schema.InspectRealmOption) (*schema.Realm, error) {
+	s, err := m.InspectSchema(ctx, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return schema.NewRealm(s), nil
+}
+
+func (m *mockDriver) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
+	var changes []schema.Change
+	for _, t := range from.Tables {
+		if _, ok := to.Table(t.Name); !ok {
+			changes = append(changes, &schema.DropTable{T: t})
+		}
+	}
+	for _, t := range to.Tables {
+		if _, ok := from.Table(t.Name); !ok {
+			changes = append(changes, &schema.AddTable{T: t})
+		}
+	}
+	return changes, nil
+}
+
+func (m *mockDriver) PlanChanges(_ context.Context, name string, changes []schema.Change, _ ...migrate.PlanOption) (*migrate.Plan, error) {
+	plan := &migrate.Plan{Name: name}
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			plan.Changes = append(plan.Changes, &migrate.Change{Cmd: "CREATE TABLE " + c.T.Name})
+		case *schema.DropTable:
+			plan.Changes = append(plan.Changes, &migrate.Change{Cmd: "DROP TABLE " + c.T.Name})
+		}
+	}
+	return plan, nil
+}
+
+func (m *mockDriver) CheckClean(context.Context, *migrate.TableIdent) error {
+	return nil
+}
+
+func (m *mockDriver) Snapshot(context.Context) (migrate.RestoreFunc, error) {
+	tables := append([]string(nil), m.tables...)
+	return func(context.Context) error {
+		m.tables = tables
+		return nil
+	}, nil
+}